@@ -23,9 +23,11 @@ var (
 	_ DDLNode = &CreateDatabaseStmt{}
 	_ DDLNode = &CreateIndexStmt{}
 	_ DDLNode = &CreateTableStmt{}
+	_ DDLNode = &CreateTriggerStmt{}
 	_ DDLNode = &DropDatabaseStmt{}
 	_ DDLNode = &DropIndexStmt{}
 	_ DDLNode = &DropTableStmt{}
+	_ DDLNode = &DropTriggerStmt{}
 	_ DDLNode = &RenameTableStmt{}
 	_ DDLNode = &TruncateTableStmt{}
 
@@ -105,6 +107,11 @@ type IndexColName struct {
 
 	Column *ColumnName
 	Length int
+	// MultiValued is true for the key part `CAST(json_path AS type ARRAY)` of a multi-valued index,
+	// Path is the JSON path of Column and Tp is the type of the array elements.
+	MultiValued bool
+	Path        string
+	Tp          *types.FieldType
 }
 
 // Accept implements Node Accept interface.
@@ -243,6 +250,7 @@ const (
 	ColumnOptionOnUpdate // For Timestamp and Datetime only.
 	ColumnOptionFulltext
 	ColumnOptionComment
+	ColumnOptionAutoRandom // For BIGINT primary key only.
 )
 
 // ColumnOption is used for parsing column constraint info from SQL.
@@ -251,6 +259,8 @@ type ColumnOption struct {
 
 	Tp   ColumnOptionType
 	Expr ExprNode // The value For Default or On Update.
+	// AutoRandomBits is the number of the shard bits for AUTO_RANDOM, it's types.UnspecifiedLength if not specified.
+	AutoRandomBits int
 }
 
 // Accept implements Node Accept interface.
@@ -271,10 +281,12 @@ func (n *ColumnOption) Accept(v Visitor) (Node, bool) {
 }
 
 // IndexOption is the index options.
-//    KEY_BLOCK_SIZE [=] value
-//  | index_type
-//  | WITH PARSER parser_name
-//  | COMMENT 'string'
+//
+//	  KEY_BLOCK_SIZE [=] value
+//	| index_type
+//	| WITH PARSER parser_name
+//	| COMMENT 'string'
+//
 // See http://dev.mysql.com/doc/refman/5.7/en/create-table.html
 type IndexOption struct {
 	node
@@ -548,6 +560,60 @@ func (n *DropIndexStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// CreateTriggerStmt is a statement to create a row-level trigger.
+// The trigger body is either a list of assignments to NEW columns or a single DML statement.
+// See https://dev.mysql.com/doc/refman/5.7/en/create-trigger.html
+type CreateTriggerStmt struct {
+	ddlNode
+
+	Name   string
+	Timing model.TriggerTiming
+	Event  model.TriggerEvent
+	Table  *TableName
+	// Assignments is set for the body `SET NEW.col = expr, ...`.
+	Assignments []*Assignment
+	// Body is set for the body which is an INSERT, REPLACE, UPDATE or DELETE statement.
+	Body DMLNode
+	// BodyText is the original text of the trigger body.
+	BodyText string
+}
+
+// Accept implements Node Accept interface.
+// The trigger body is not visited, it refers to OLD and NEW rows and is resolved when the trigger fires.
+func (n *CreateTriggerStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateTriggerStmt)
+	node, ok := n.Table.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Table = node.(*TableName)
+	return v.Leave(n)
+}
+
+// DropTriggerStmt is a statement to drop a trigger.
+// See https://dev.mysql.com/doc/refman/5.7/en/drop-trigger.html
+type DropTriggerStmt struct {
+	ddlNode
+
+	IfExists bool
+	Schema   model.CIStr
+	Name     model.CIStr
+}
+
+// Accept implements Node Accept interface.
+func (n *DropTriggerStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropTriggerStmt)
+	return v.Leave(n)
+}
+
 // TableOptionType is the type for TableOption
 type TableOptionType int
 
@@ -570,6 +636,7 @@ const (
 	TableOptionDelayKeyWrite
 	TableOptionRowFormat
 	TableOptionStatsPersistent
+	TableOptionAutoIDCache
 )
 
 // RowFormat types
@@ -25,6 +25,7 @@ var (
 	_ DMLNode = &SelectStmt{}
 	_ DMLNode = &ShowStmt{}
 	_ DMLNode = &LoadDataStmt{}
+	_ DMLNode = &SplitRegionStmt{}
 
 	_ Node = &Assignment{}
 	_ Node = &ByItem{}
@@ -68,6 +69,10 @@ type Join struct {
 	Tp JoinType
 	// On represents join on condition.
 	On *OnCondition
+	// Using represents join using clause.
+	Using []*ColumnName
+	// NaturalJoin represents join is natural join.
+	NaturalJoin bool
 }
 
 // Accept implements Node Accept interface.
@@ -208,6 +213,9 @@ type TableSource struct {
 
 	// AsName is the alias name of the table source.
 	AsName model.CIStr
+
+	// Lateral means the derived table can refer to columns of preceding tables in the from clause.
+	Lateral bool
 }
 
 // Accept implements Node Accept interface.
@@ -473,6 +481,15 @@ func (n *SelectStmt) Accept(v Visitor) (Node, bool) {
 	}
 
 	n = newNode.(*SelectStmt)
+	if n.From != nil {
+		node, ok := n.From.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.From = node.(*TableRefsClause)
+	}
+
+	// The hints are visited after the from clause, the expressions in them refer to the tables.
 	if n.TableHints != nil && len(n.TableHints) != 0 {
 		newHints := make([]*TableOptimizerHint, len(n.TableHints))
 		for i, hint := range n.TableHints {
@@ -485,14 +502,6 @@ func (n *SelectStmt) Accept(v Visitor) (Node, bool) {
 		n.TableHints = newHints
 	}
 
-	if n.From != nil {
-		node, ok := n.From.Accept(v)
-		if !ok {
-			return n, false
-		}
-		n.From = node.(*TableRefsClause)
-	}
-
 	if n.Where != nil {
 		node, ok := n.Where.Accept(v)
 		if !ok {
@@ -686,6 +695,46 @@ func (n *LoadDataStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// SplitRegionStmt is a statement to split the regions of a table evenly between the lower and upper values,
+// so the writes to the table can be distributed to multiple regions in the beginning.
+type SplitRegionStmt struct {
+	dmlNode
+
+	Table *TableName
+	Lower []ExprNode
+	Upper []ExprNode
+	Num   uint64
+}
+
+// Accept implements Node Accept interface.
+func (n *SplitRegionStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*SplitRegionStmt)
+	node, ok := n.Table.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Table = node.(*TableName)
+	for i, val := range n.Lower {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Lower[i] = node.(ExprNode)
+	}
+	for i, val := range n.Upper {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Upper[i] = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
+
 // FieldsClause represents fields references clause in load data statement.
 type FieldsClause struct {
 	Terminated string
@@ -948,6 +997,10 @@ const (
 	ShowProcessList
 	ShowCreateDatabase
 	ShowEvents
+	ShowRegions
+	ShowHotspots
+	ShowMasterStatus
+	ShowErrors
 )
 
 // ShowStmt is a statement to provide information about databases, tables, columns and so on.
@@ -963,6 +1016,8 @@ type ShowStmt struct {
 	Flag   int         // Some flag parsed from sql, such as FULL.
 	Full   bool
 	User   string // Used for show grants.
+	// CountWarningsOrErrors is used for show count(*) warnings and show count(*) errors.
+	CountWarningsOrErrors bool
 
 	// GlobalScope is used by show variables
 	GlobalScope bool
@@ -78,6 +78,8 @@ func (f *flagSetter) Leave(in Node) (Node, bool) {
 	case *ValueExpr:
 	case *ValuesExpr:
 		x.SetFlag(FlagHasReference)
+	case *WindowFuncExpr:
+		f.windowFunc(x)
 	case *VariableExpr:
 		if x.Value == nil {
 			x.SetFlag(FlagHasVariable)
@@ -154,3 +156,17 @@ func (f *flagSetter) aggregateFunc(x *AggregateFuncExpr) {
 	}
 	x.SetFlag(flag)
 }
+
+func (f *flagSetter) windowFunc(x *WindowFuncExpr) {
+	flag := FlagHasWindowFunc
+	for _, val := range x.Args {
+		flag |= val.GetFlag()
+	}
+	for _, item := range x.Spec.PartitionBy {
+		flag |= item.Expr.GetFlag()
+	}
+	for _, item := range x.Spec.OrderBy {
+		flag |= item.Expr.GetFlag()
+	}
+	x.SetFlag(flag)
+}
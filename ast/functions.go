@@ -22,6 +22,7 @@ var (
 	_ FuncNode = &AggregateFuncExpr{}
 	_ FuncNode = &FuncCallExpr{}
 	_ FuncNode = &FuncCastExpr{}
+	_ FuncNode = &WindowFuncExpr{}
 )
 
 // List scalar function names.
@@ -201,6 +202,7 @@ const (
 	Substr         = "substr"
 	SubstringIndex = "substring_index"
 	ToBase64       = "to_base64"
+	WeightString   = "weight_string"
 	Trim           = "trim"
 	Upper          = "upper"
 	Ucase          = "ucase"
@@ -253,6 +255,8 @@ const (
 	Sleep           = "sleep"
 	UUID            = "uuid"
 	UUIDShort       = "uuid_short"
+	UUIDToBin       = "uuid_to_bin"
+	BinToUUID       = "bin_to_uuid"
 	// get_lock() and release_lock() is parsed but do nothing.
 	// It is used for preventing error in Ruby's activerecord migrations.
 	GetLock     = "get_lock"
@@ -277,6 +281,24 @@ const (
 	Uncompress               = "uncompress"
 	UncompressedLength       = "uncompressed_length"
 	ValidatePasswordStrength = "validate_password_strength"
+
+	// json functions
+	JSONExtract  = "json_extract"
+	JSONUnquote  = "json_unquote"
+	JSONContains = "json_contains"
+	JSONSet      = "json_set"
+	JSONObject   = "json_object"
+	JSONArray    = "json_array"
+	// JSONMemberOf is the function of `value MEMBER OF(json_array)`.
+	JSONMemberOf = "json_memberof"
+
+	// TiDB internal functions
+	TiDBDigest     = "tidb_digest"
+	TiDBNormalize  = "tidb_normalize"
+	TiDBDecodeKey  = "tidb_decode_key"
+	TiDBVersion    = "tidb_version"
+	TiDBCurrentTSO = "tidb_current_tso"
+	TiDBIsDDLOwner = "tidb_is_ddl_owner"
 )
 
 // FuncCallExpr is for function expression.
@@ -385,6 +407,10 @@ const (
 	AggFuncMin = "min"
 	// AggFuncGroupConcat is the name of group_concat function.
 	AggFuncGroupConcat = "group_concat"
+	// AggFuncJSONArrayagg is the name of json_arrayagg function.
+	AggFuncJSONArrayagg = "json_arrayagg"
+	// AggFuncJSONObjectagg is the name of json_objectagg function.
+	AggFuncJSONObjectagg = "json_objectagg"
 )
 
 // AggregateFuncExpr represents aggregate function expression.
@@ -416,3 +442,61 @@ func (n *AggregateFuncExpr) Accept(v Visitor) (Node, bool) {
 	}
 	return v.Leave(n)
 }
+
+const (
+	// WindowFuncRowNumber is the name of row_number function.
+	WindowFuncRowNumber = "row_number"
+	// WindowFuncRank is the name of rank function.
+	WindowFuncRank = "rank"
+)
+
+// WindowSpec is the window of a window function, i.e. the OVER clause.
+type WindowSpec struct {
+	// PartitionBy divides the rows into partitions, the function is evaluated in every partition independently.
+	PartitionBy []*ByItem
+	// OrderBy orders the rows in a partition.
+	OrderBy []*ByItem
+}
+
+// WindowFuncExpr represents window function expression, it's evaluated over the rows in the window of every row.
+// F is either a window function name like row_number or an aggregate function name like sum.
+type WindowFuncExpr struct {
+	funcNode
+	// F is the function name.
+	F string
+	// Args is the function args.
+	Args []ExprNode
+	// Spec is the window of the function.
+	Spec WindowSpec
+}
+
+// Accept implements Node Accept interface.
+func (n *WindowFuncExpr) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*WindowFuncExpr)
+	for i, val := range n.Args {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Args[i] = node.(ExprNode)
+	}
+	for i, val := range n.Spec.PartitionBy {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Spec.PartitionBy[i] = node.(*ByItem)
+	}
+	for i, val := range n.Spec.OrderBy {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Spec.OrderBy[i] = node.(*ByItem)
+	}
+	return v.Leave(n)
+}
@@ -23,15 +23,19 @@ import (
 
 var (
 	_ StmtNode = &AdminStmt{}
+	_ StmtNode = &AlterEventStmt{}
 	_ StmtNode = &AlterUserStmt{}
 	_ StmtNode = &BeginStmt{}
 	_ StmtNode = &BinlogStmt{}
 	_ StmtNode = &CommitStmt{}
+	_ StmtNode = &CreateEventStmt{}
 	_ StmtNode = &CreateUserStmt{}
 	_ StmtNode = &DeallocateStmt{}
 	_ StmtNode = &DoStmt{}
+	_ StmtNode = &DropEventStmt{}
 	_ StmtNode = &ExecuteStmt{}
 	_ StmtNode = &ExplainStmt{}
+	_ StmtNode = &TraceStmt{}
 	_ StmtNode = &GrantStmt{}
 	_ StmtNode = &PrepareStmt{}
 	_ StmtNode = &RollbackStmt{}
@@ -42,6 +46,7 @@ var (
 	_ StmtNode = &FlushStmt{}
 	_ StmtNode = &KillStmt{}
 
+	_ Node = &EventSchedule{}
 	_ Node = &PrivElem{}
 	_ Node = &VariableAssignment{}
 )
@@ -75,6 +80,8 @@ type ExplainStmt struct {
 	stmtNode
 
 	Stmt StmtNode
+	// Analyze is true for 'explain analyze', the statement is executed to collect the runtime statistics.
+	Analyze bool
 }
 
 // Accept implements Node Accept interface.
@@ -92,6 +99,48 @@ func (n *ExplainStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// ExplainForStmt is a statement to provide information about how is the SQL statement
+// currently running in the connection executed.
+// See https://dev.mysql.com/doc/refman/5.7/en/explain-for-connection.html
+type ExplainForStmt struct {
+	stmtNode
+
+	ConnectionID uint64
+}
+
+// Accept implements Node Accept interface.
+func (n *ExplainForStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*ExplainForStmt)
+	return v.Leave(n)
+}
+
+// TraceStmt is a statement to execute a statement and show the time spent in its phases, like
+// optimizing, executing and the coprocessor requests, as a tree of spans.
+type TraceStmt struct {
+	stmtNode
+
+	Stmt StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *TraceStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*TraceStmt)
+	node, ok := n.Stmt.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Stmt = node.(DMLNode)
+	return v.Leave(n)
+}
+
 // PrepareStmt is a statement to prepares a SQL statement which contains placeholders,
 // and it is executed with ExecuteStmt and released with DeallocateStmt.
 // See https://dev.mysql.com/doc/refman/5.7/en/prepare.html
@@ -472,6 +521,163 @@ func (n *DropUserStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// EventSchedule is the schedule of an event.
+// It is either `AT timestamp` or `EVERY interval [STARTS timestamp] [ENDS timestamp]`.
+type EventSchedule struct {
+	node
+
+	// At is set for an event which executes once.
+	At ExprNode
+	// Every and Unit are set for a recurring event.
+	Every  ExprNode
+	Unit   string
+	Starts ExprNode
+	Ends   ExprNode
+}
+
+// Accept implements Node Accept interface.
+func (n *EventSchedule) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*EventSchedule)
+	if n.At != nil {
+		node, ok := n.At.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.At = node.(ExprNode)
+	}
+	if n.Every != nil {
+		node, ok := n.Every.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Every = node.(ExprNode)
+	}
+	if n.Starts != nil {
+		node, ok := n.Starts.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Starts = node.(ExprNode)
+	}
+	if n.Ends != nil {
+		node, ok := n.Ends.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Ends = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
+
+// EventCompletion is the ON COMPLETION option of an event.
+type EventCompletion int
+
+// Event completion options, EventCompletionDefault means the option is not specified.
+const (
+	EventCompletionDefault EventCompletion = iota
+	EventCompletionNotPreserve
+	EventCompletionPreserve
+)
+
+// EventStatus is the ENABLE or DISABLE option of an event.
+type EventStatus int
+
+// Event status options, EventStatusDefault means the option is not specified.
+const (
+	EventStatusDefault EventStatus = iota
+	EventStatusEnable
+	EventStatusDisable
+)
+
+// CreateEventStmt is a statement to create an event.
+// See https://dev.mysql.com/doc/refman/5.7/en/create-event.html
+type CreateEventStmt struct {
+	stmtNode
+
+	IfNotExists  bool
+	Schema       model.CIStr
+	Name         model.CIStr
+	Schedule     *EventSchedule
+	OnCompletion EventCompletion
+	Status       EventStatus
+	Comment      string
+	// Body is the statement executed by the event, it is executed by the event scheduler
+	// with the original text BodyText, so it is not visited.
+	Body     StmtNode
+	BodyText string
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateEventStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateEventStmt)
+	node, ok := n.Schedule.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Schedule = node.(*EventSchedule)
+	return v.Leave(n)
+}
+
+// AlterEventStmt is a statement to change an event.
+// The fields which are not specified in the statement are nil or default.
+// See https://dev.mysql.com/doc/refman/5.7/en/alter-event.html
+type AlterEventStmt struct {
+	stmtNode
+
+	Schema       model.CIStr
+	Name         model.CIStr
+	Schedule     *EventSchedule
+	OnCompletion EventCompletion
+	Status       EventStatus
+	Body         StmtNode
+	BodyText     string
+}
+
+// Accept implements Node Accept interface.
+func (n *AlterEventStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*AlterEventStmt)
+	if n.Schedule != nil {
+		node, ok := n.Schedule.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Schedule = node.(*EventSchedule)
+	}
+	return v.Leave(n)
+}
+
+// DropEventStmt is a statement to drop an event.
+// See https://dev.mysql.com/doc/refman/5.7/en/drop-event.html
+type DropEventStmt struct {
+	stmtNode
+
+	IfExists bool
+	Schema   model.CIStr
+	Name     model.CIStr
+}
+
+// Accept implements Node Accept interface.
+func (n *DropEventStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropEventStmt)
+	return v.Leave(n)
+}
+
 // DoStmt is the struct for DO statement.
 type DoStmt struct {
 	stmtNode
@@ -503,14 +709,52 @@ type AdminStmtType int
 const (
 	AdminShowDDL = iota + 1
 	AdminCheckTable
+	AdminReloadExprPushdownBlacklist
+	AdminShowSlow
+	AdminReloadResourceGroups
+	AdminReloadConfig
+	AdminShowDDLOwner
+	AdminTransferDDLOwner
 )
 
+// ShowSlowType defines the type of the 'admin show slow' statement.
+type ShowSlowType int
+
+// Show slow types.
+const (
+	ShowSlowTop ShowSlowType = iota
+	ShowSlowRecent
+)
+
+// ShowSlowKind defines the kind of the queries shown by the 'admin show slow top' statement.
+type ShowSlowKind int
+
+// Show slow kinds, ShowSlowKindDefault shows the queries of the users only.
+const (
+	ShowSlowKindDefault ShowSlowKind = iota
+	ShowSlowKindInternal
+	ShowSlowKindAll
+)
+
+// ShowSlow is used for the following command:
+//
+//	admin show slow top [ internal | all] N
+//	admin show slow recent N
+type ShowSlow struct {
+	Tp    ShowSlowType
+	Count uint64
+	Kind  ShowSlowKind
+}
+
 // AdminStmt is the struct for Admin statement.
 type AdminStmt struct {
 	stmtNode
 
-	Tp     AdminStmtType
-	Tables []*TableName
+	Tp       AdminStmtType
+	Tables   []*TableName
+	ShowSlow *ShowSlow
+	// DDLOwner is the ID of the server the DDL owner is transferred to, it's empty if the owner resigns.
+	DDLOwner string
 }
 
 // Accept implements Node Accpet interface.
@@ -709,6 +953,10 @@ type TableOptimizerHint struct {
 	// It allows only table name or alias (if table has an alias)
 	HintName model.CIStr
 	Tables   []model.CIStr
+	// Expr is the predicate of the SELECTIVITY hint, and Selectivity is the fraction of the rows
+	// estimated to satisfy it.
+	Expr        ExprNode
+	Selectivity float64
 }
 
 // Accept implements Node Accept interface.
@@ -718,5 +966,12 @@ func (n *TableOptimizerHint) Accept(v Visitor) (Node, bool) {
 		return v.Leave(newNode)
 	}
 	n = newNode.(*TableOptimizerHint)
+	if n.Expr != nil {
+		node, ok := n.Expr.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Expr = node.(ExprNode)
+	}
 	return v.Leave(n)
 }
@@ -155,10 +155,77 @@ const (
 		lower_bound blob ,
 		unique index tbl(table_id, is_index, hist_id, bucket_id)
 	);`
+
+	// CreateEventTable stores the definitions of events, the time values are in UTC.
+	CreateEventTable = `CREATE TABLE if not exists mysql.event (
+		db varchar(64) NOT NULL,
+		name varchar(64) NOT NULL,
+		body text NOT NULL,
+		definer varchar(77) NOT NULL DEFAULT '',
+		execute_at datetime DEFAULT NULL,
+		interval_value bigint(64) DEFAULT NULL,
+		interval_field varchar(16) DEFAULT NULL,
+		created datetime NOT NULL,
+		modified datetime NOT NULL,
+		last_executed datetime DEFAULT NULL,
+		starts datetime DEFAULT NULL,
+		ends datetime DEFAULT NULL,
+		status enum('ENABLED','DISABLED') NOT NULL DEFAULT 'ENABLED',
+		on_completion enum('DROP','PRESERVE') NOT NULL DEFAULT 'DROP',
+		time_zone varchar(64) NOT NULL DEFAULT 'SYSTEM',
+		comment varchar(64) NOT NULL DEFAULT '',
+		PRIMARY KEY (db, name)
+	);`
+
+	// CreateExprPushdownBlacklistTable stores the functions that can't be pushed down to the storage.
+	CreateExprPushdownBlacklistTable = `CREATE TABLE if not exists mysql.expr_pushdown_blacklist (
+		name char(100) NOT NULL
+	);`
+
+	// CreateResourceGroupTable stores the limits of the resource groups, the zero limits mean unlimited.
+	CreateResourceGroupTable = `CREATE TABLE if not exists mysql.resource_group (
+		name varchar(64) NOT NULL,
+		max_concurrent_stmts bigint(64) NOT NULL DEFAULT 0,
+		mem_quota bigint(64) NOT NULL DEFAULT 0,
+		cop_request_rate double NOT NULL DEFAULT 0,
+		PRIMARY KEY (name)
+	);`
+
+	// CreateUserResourceGroupTable stores the resource groups the users are assigned to.
+	CreateUserResourceGroupTable = `CREATE TABLE if not exists mysql.user_resource_group (
+		user char(16) NOT NULL,
+		resource_group varchar(64) NOT NULL,
+		PRIMARY KEY (user)
+	);`
+
+	// CreatePrivilegeHistoryTable stores the history of the privilege-changing statements, one row for
+	// each affected account, the time values are in UTC. The IDs are allocated in the order of the changes.
+	CreatePrivilegeHistoryTable = `CREATE TABLE if not exists mysql.privilege_history (
+		id bigint(64) NOT NULL AUTO_INCREMENT,
+		time datetime NOT NULL,
+		user varchar(77) NOT NULL DEFAULT '',
+		account varchar(77) NOT NULL,
+		statement text NOT NULL,
+		PRIMARY KEY (id),
+		INDEX account (account)
+	) AUTO_ID_CACHE=1;`
+
+	// CreateBootstrapHistoryTable stores the history of the bootstrap and upgrade steps applied to the store,
+	// the time values are in UTC. A fresh bootstrap is recorded as a single step of the bootstrap version.
+	CreateBootstrapHistoryTable = `CREATE TABLE if not exists mysql.bootstrap_history (
+		id bigint(64) NOT NULL AUTO_INCREMENT,
+		version bigint(64) NOT NULL,
+		description varchar(256) NOT NULL DEFAULT '',
+		start_time datetime NOT NULL,
+		end_time datetime NOT NULL,
+		PRIMARY KEY (id),
+		INDEX version (version)
+	) AUTO_ID_CACHE=1;`
 )
 
 // bootstrap initiates system DB for a store.
 func bootstrap(s Session) {
+	start := time.Now()
 	b, err := checkBootstrapped(s)
 	if err != nil {
 		log.Fatal(err)
@@ -167,7 +234,7 @@ func bootstrap(s Session) {
 		upgrade(s)
 	}
 	doDDLWorks(s)
-	doDMLWorks(s)
+	doDMLWorks(s, start)
 }
 
 const (
@@ -190,6 +257,11 @@ const (
 	version8  = 8
 	version9  = 9
 	version10 = 10
+	version11 = 11
+	version12 = 12
+	version13 = 13
+	version14 = 14
+	version15 = 15
 )
 
 func checkBootstrapped(s Session) (bool, error) {
@@ -237,8 +309,55 @@ func getTiDBVar(s Session, name string) (types.Datum, error) {
 	return row.Data[0], nil
 }
 
+// upgradeStep is a versioned step of upgrading the system tables, the steps must be reentrant because the
+// server may be killed before the version is recorded.
+type upgradeStep struct {
+	version     int64
+	description string
+	do          func(Session)
+}
+
+// upgradeSteps are the upgrade steps sorted by version, the last version is currentBootstrapVersion.
+var upgradeSteps = []upgradeStep{
+	{version2, "add the tidb_distsql_scan_concurrency global variable", upgradeToVer2},
+	{version3, "fix the value of the tx_read_only global variable", upgradeToVer3},
+	{version4, "create the mysql.stats_meta table", upgradeToVer4},
+	{version5, "create the mysql.stats_histograms, mysql.stats_buckets and mysql.event tables", upgradeToVer5},
+	{version6, "add the Super_priv column to mysql.user", upgradeToVer6},
+	{version7, "add the Process_priv column to mysql.user", upgradeToVer7},
+	{version8, "check the Process_priv column of mysql.user", upgradeToVer8},
+	{version9, "add the Trigger_priv column to mysql.user", upgradeToVer9},
+	{version10, "change the columns of the statistics tables", upgradeToVer10},
+	{version11, "create the mysql.event table", upgradeToVer11},
+	{version12, "create the mysql.expr_pushdown_blacklist table", upgradeToVer12},
+	{version13, "create the mysql.resource_group and mysql.user_resource_group tables", upgradeToVer13},
+	{version14, "create the mysql.privilege_history table", upgradeToVer14},
+	{version15, "create the mysql.bootstrap_history table", upgradeToVer15},
+}
+
+// BootstrapStep is a step BootstrapSession applies to the store.
+type BootstrapStep struct {
+	Version     int64
+	Description string
+}
+
+// freshBootstrapDescription is the description of the fresh bootstrap in mysql.bootstrap_history.
+const freshBootstrapDescription = "bootstrap the system tables"
+
+// pendingUpgradeSteps returns the upgrade steps newer than the version.
+func pendingUpgradeSteps(ver int64) []upgradeStep {
+	for i, step := range upgradeSteps {
+		if step.version > ver {
+			return upgradeSteps[i:]
+		}
+	}
+	return nil
+}
+
 // upgrade function  will do some upgrade works, when the system is boostrapped by low version TiDB server
 // For example, add new system variables into mysql.global_variables table.
+// The bootstrap version is recorded after every step, so an interrupted upgrade resumes from the last
+// finished step, and every step is recorded in mysql.bootstrap_history.
 func upgrade(s Session) {
 	ver, err := getBootstrapVersion(s)
 	if err != nil {
@@ -248,58 +367,34 @@ func upgrade(s Session) {
 		// It is already bootstrapped/upgraded by a higher version TiDB server.
 		return
 	}
-	// Do upgrade works then update bootstrap version.
-	if ver < version2 {
-		upgradeToVer2(s)
-		ver = version2
-	}
-	if ver < version3 {
-		upgradeToVer3(s)
-	}
-	if ver < version4 {
-		upgradeToVer4(s)
-	}
-
-	if ver < version5 {
-		upgradeToVer5(s)
-	}
-
-	if ver < version6 {
-		upgradeToVer6(s)
-	}
-
-	if ver < version7 {
-		upgradeToVer7(s)
-	}
-
-	if ver < version8 {
-		upgradeToVer8(s)
-	}
-
-	if ver < version9 {
-		upgradeToVer9(s)
-	}
-
-	if ver < version10 {
-		upgradeToVer10(s)
-	}
-
-	updateBootstrapVer(s)
-	_, err = s.Execute("COMMIT")
-
-	if err != nil {
-		time.Sleep(1 * time.Second)
-		// Check if TiDB is already upgraded.
-		v, err1 := getBootstrapVersion(s)
-		if err1 != nil {
-			log.Fatal(err1)
+	// The history table is created first so the steps before version15 can be recorded too.
+	mustExecute(s, CreateBootstrapHistoryTable)
+	for _, step := range pendingUpgradeSteps(ver) {
+		if step.version <= ver {
+			// It's done by another TiDB server.
+			continue
 		}
-		if v >= currentBootstrapVersion {
-			// It is already bootstrapped/upgraded by a higher version TiDB server.
-			return
+		log.Infof("[Upgrade] upgrade from %d to %d: %s", ver, step.version, step.description)
+		start := time.Now()
+		step.do(s)
+		updateBootstrapVer(s, step.version)
+		addBootstrapHistory(s, step.version, step.description, start)
+		_, err = s.Execute("COMMIT")
+		if err != nil {
+			time.Sleep(1 * time.Second)
+			// Check if the step is done by another TiDB server.
+			v, err1 := getBootstrapVersion(s)
+			if err1 != nil {
+				log.Fatal(err1)
+			}
+			if v < step.version {
+				log.Errorf("[Upgrade] upgrade from %d to %d error", ver, step.version)
+				log.Fatal(err)
+			}
+			ver = v
+			continue
 		}
-		log.Errorf("[Upgrade] upgrade from %d to %d error", ver, currentBootstrapVersion)
-		log.Fatal(err)
+		ver = step.version
 	}
 	return
 }
@@ -336,6 +431,8 @@ func upgradeToVer4(s Session) {
 func upgradeToVer5(s Session) {
 	mustExecute(s, CreateStatsColsTable)
 	mustExecute(s, CreateStatsBucketsTable)
+	// Create event table.
+	mustExecute(s, CreateEventTable)
 }
 
 func upgradeToVer6(s Session) {
@@ -384,11 +481,41 @@ func upgradeToVer10(s Session) {
 	doReentrantDDL(s, "ALTER TABLE mysql.stats_histograms DROP COLUMN use_count_to_estimate", ddl.ErrCantDropFieldOrKey)
 }
 
+func upgradeToVer11(s Session) {
+	mustExecute(s, CreateEventTable)
+}
+
+func upgradeToVer12(s Session) {
+	mustExecute(s, CreateExprPushdownBlacklistTable)
+}
+
+func upgradeToVer13(s Session) {
+	mustExecute(s, CreateResourceGroupTable)
+	mustExecute(s, CreateUserResourceGroupTable)
+}
+
+func upgradeToVer14(s Session) {
+	mustExecute(s, CreatePrivilegeHistoryTable)
+}
+
+func upgradeToVer15(s Session) {
+	mustExecute(s, CreateBootstrapHistoryTable)
+}
+
 // updateBootstrapVer updates bootstrap version variable in mysql.TiDB table.
-func updateBootstrapVer(s Session) {
+func updateBootstrapVer(s Session, ver int64) {
 	// Update bootstrap version.
 	sql := fmt.Sprintf(`INSERT INTO %s.%s VALUES ("%s", "%d", "TiDB bootstrap version.") ON DUPLICATE KEY UPDATE VARIABLE_VALUE="%d"`,
-		mysql.SystemDB, mysql.TiDBTable, tidbServerVersionVar, currentBootstrapVersion, currentBootstrapVersion)
+		mysql.SystemDB, mysql.TiDBTable, tidbServerVersionVar, ver, ver)
+	mustExecute(s, sql)
+}
+
+// addBootstrapHistory records the step started at the time in mysql.bootstrap_history.
+func addBootstrapHistory(s Session, ver int64, description string, start time.Time) {
+	const layout = "2006-01-02 15:04:05"
+	sql := fmt.Sprintf(`INSERT INTO %s.%s (version, description, start_time, end_time) VALUES (%d, "%s", "%s", "%s")`,
+		mysql.SystemDB, mysql.BootstrapHistoryTable, ver, description,
+		start.UTC().Format(layout), time.Now().UTC().Format(layout))
 	mustExecute(s, sql)
 }
 
@@ -428,11 +555,22 @@ func doDDLWorks(s Session) {
 	mustExecute(s, CreateStatsColsTable)
 	// Create stats_buckets table.
 	mustExecute(s, CreateStatsBucketsTable)
+	// Create event table.
+	mustExecute(s, CreateEventTable)
+	// Create expr_pushdown_blacklist table.
+	mustExecute(s, CreateExprPushdownBlacklistTable)
+	// Create resource_group and user_resource_group tables.
+	mustExecute(s, CreateResourceGroupTable)
+	mustExecute(s, CreateUserResourceGroupTable)
+	// Create privilege_history table.
+	mustExecute(s, CreatePrivilegeHistoryTable)
+	// Create bootstrap_history table.
+	mustExecute(s, CreateBootstrapHistoryTable)
 }
 
 // doDMLWorks executes DML statements in bootstrap stage.
-// All the statements run in a single transaction.
-func doDMLWorks(s Session) {
+// All the statements run in a single transaction, start is the time the bootstrap is started.
+func doDMLWorks(s Session, start time.Time) {
 	mustExecute(s, "BEGIN")
 
 	// Insert a default user with empty password.
@@ -461,6 +599,8 @@ func doDMLWorks(s Session) {
 		mysql.SystemDB, mysql.TiDBTable, tidbServerVersionVar, currentBootstrapVersion)
 	mustExecute(s, sql)
 
+	addBootstrapHistory(s, currentBootstrapVersion, freshBootstrapDescription, start)
+
 	_, err := s.Execute("COMMIT")
 	if err != nil {
 		time.Sleep(1 * time.Second)
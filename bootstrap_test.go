@@ -15,6 +15,7 @@ package tidb
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/context"
@@ -63,6 +64,15 @@ func (s *testBootstrapSuite) TestBootstrap(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(v.Data[0].GetInt64(), Equals, globalVarsCount())
 
+	// Check the bootstrap is recorded.
+	r = mustExecSQL(c, se, "SELECT version, description FROM mysql.bootstrap_history")
+	v, err = r.Next()
+	c.Assert(err, IsNil)
+	match(c, v.Data, currentBootstrapVersion, []byte(freshBootstrapDescription))
+	steps, err := PendingBootstrapSteps(store)
+	c.Assert(err, IsNil)
+	c.Assert(steps, HasLen, 0)
+
 	// Check a storage operations are default autocommit after the second start.
 	mustExecSQL(c, se, "USE test;")
 	mustExecSQL(c, se, "drop table if exists t")
@@ -89,7 +99,7 @@ func (s *testBootstrapSuite) TestBootstrap(c *C) {
 	store = newStore(c, s.dbName)
 	se, err = CreateSession(store)
 	c.Assert(err, IsNil)
-	doDMLWorks(se)
+	doDMLWorks(se, time.Now())
 
 	err = store.Close()
 	c.Assert(err, IsNil)
@@ -222,3 +232,49 @@ func (s *testBootstrapSuite) TestUpgrade(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(ver, Equals, int64(currentBootstrapVersion))
 }
+
+// TestUpgradeResume tests resuming an upgrade interrupted after some steps.
+func (s *testBootstrapSuite) TestUpgradeResume(c *C) {
+	defer testleak.AfterTest(c)()
+	store := newStore(c, "test_upgrade_resume")
+	steps, err := PendingBootstrapSteps(store)
+	c.Assert(err, IsNil)
+	c.Assert(steps, DeepEquals, []BootstrapStep{{Version: currentBootstrapVersion, Description: freshBootstrapDescription}})
+	BootstrapSession(store)
+	se := newSession(c, store, s.dbName)
+
+	// The upgrade to currentBootstrapVersion is interrupted after version12 is recorded.
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	err = meta.NewMeta(txn).FinishBootstrap(int64(1))
+	c.Assert(err, IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	mustExecSQL(c, se, fmt.Sprintf(`UPDATE mysql.TiDB SET VARIABLE_VALUE="%d" WHERE VARIABLE_NAME="tidb_server_version"`, version12))
+	mustExecSQL(c, se, "DROP TABLE mysql.bootstrap_history")
+	delete(storeBootstrapped, store.UUID())
+
+	steps, err = PendingBootstrapSteps(store)
+	c.Assert(err, IsNil)
+	c.Assert(steps, HasLen, currentBootstrapVersion-version12)
+	for i, step := range steps {
+		c.Assert(step.Version, Equals, int64(version12+i+1))
+		c.Assert(step.Description, Not(Equals), "")
+	}
+
+	BootstrapSession(store)
+	se = newSession(c, store, s.dbName)
+	r := mustExecSQL(c, se, "SELECT version FROM mysql.bootstrap_history ORDER BY id")
+	rows, err := GetRows(r)
+	c.Assert(err, IsNil)
+	c.Assert(rows, HasLen, len(steps))
+	for i, row := range rows {
+		c.Assert(row[0].GetInt64(), Equals, steps[i].Version)
+	}
+	ver, err := getBootstrapVersion(se)
+	c.Assert(err, IsNil)
+	c.Assert(ver, Equals, int64(currentBootstrapVersion))
+	steps, err = PendingBootstrapSteps(store)
+	c.Assert(err, IsNil)
+	c.Assert(steps, HasLen, 0)
+	c.Assert(store.Close(), IsNil)
+}
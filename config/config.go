@@ -0,0 +1,248 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config loads the configuration file of tidb-server and reloads it at runtime. The configuration
+// file is a JSON object mapping the names of the command line flags to their values, e.g.
+//
+//	{"L": "warn", "slow-threshold": 500, "read-only": true}
+//
+// The flags given on the command line take precedence over the file. Some flags can be reloaded without
+// restarting the server, the changes of the other flags are reported and take effect after a restart.
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+)
+
+// Error codes.
+const (
+	codeNoConfigFile terror.ErrCode = 1
+	codeUnknownKey   terror.ErrCode = 2
+	codeInvalidValue terror.ErrCode = 3
+)
+
+var (
+	// ErrNoConfigFile is returned when reloading the configuration of a server started without a configuration file.
+	ErrNoConfigFile = terror.ClassConfig.New(codeNoConfigFile, "The server is not started with a configuration file")
+	// ErrUnknownKey is returned when the configuration file has a key which isn't a flag of the server.
+	ErrUnknownKey = terror.ClassConfig.New(codeUnknownKey, "Unknown configuration key '%s'")
+	// ErrInvalidValue is returned when the value of a key in the configuration file can't be parsed.
+	ErrInvalidValue = terror.ClassConfig.New(codeInvalidValue, "Invalid value '%v' of configuration key '%s'")
+)
+
+func init() {
+	terror.ErrClassToMySQLCodes[terror.ClassConfig] = map[terror.ErrCode]uint16{
+		codeNoConfigFile: mysql.ErrUnknown,
+		codeUnknownKey:   mysql.ErrUnknown,
+		codeInvalidValue: mysql.ErrWrongValueForVar,
+	}
+}
+
+// Change is a key whose value in the configuration file differs from the value used by the server.
+type Change struct {
+	Key      string
+	OldValue string
+	NewValue string
+	// Reloaded is false if the key can't be hot reloaded, the new value takes effect after the server restarts.
+	Reloaded bool
+}
+
+// Config is the configuration file of the flags in a flag set.
+type Config struct {
+	mu    sync.Mutex
+	path  string
+	flags *flag.FlagSet
+	// hotReload maps the names of the hot reloadable flags to the functions applying their new values.
+	hotReload map[string]func()
+	// cmdLine is the names of the flags given on the command line, they're ignored in the file.
+	cmdLine map[string]bool
+}
+
+// New creates a Config of the file for the flags, the flags must be parsed before it's loaded.
+func New(path string, flags *flag.FlagSet) *Config {
+	return &Config{
+		path:      path,
+		flags:     flags,
+		hotReload: make(map[string]func()),
+	}
+}
+
+// HotReload makes the flag reloadable without restarting the server, apply is called after the value of the
+// flag is changed by Reload.
+func (c *Config) HotReload(name string, apply func()) {
+	c.hotReload[name] = apply
+}
+
+// Load sets the flags not given on the command line to their values in the configuration file, it's called
+// once when the server starts.
+func (c *Config) Load() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cmdLine = make(map[string]bool)
+	c.flags.Visit(func(f *flag.Flag) {
+		c.cmdLine[f.Name] = true
+	})
+	values, err := c.read()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, key := range sortedKeys(values) {
+		if c.cmdLine[key] {
+			continue
+		}
+		if err = c.flags.Set(key, values[key]); err != nil {
+			return ErrInvalidValue.GenByArgs(values[key], key)
+		}
+	}
+	return nil
+}
+
+// Reload reads the configuration file again and applies the new values of the hot reloadable flags. It
+// returns the changed keys sorted by name, nothing is applied if the file has an invalid key or value.
+// The keys removed from the file keep their current values.
+func (c *Config) Reload() ([]Change, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	values, err := c.read()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var changes []Change
+	for _, key := range sortedKeys(values) {
+		if c.cmdLine[key] {
+			continue
+		}
+		f := c.flags.Lookup(key)
+		var newValue string
+		newValue, err = normalize(f, values[key])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		oldValue := f.Value.String()
+		if newValue == oldValue {
+			continue
+		}
+		changes = append(changes, Change{
+			Key:      key,
+			OldValue: oldValue,
+			NewValue: newValue,
+			Reloaded: c.hotReload[key] != nil,
+		})
+	}
+	for _, change := range changes {
+		if !change.Reloaded {
+			continue
+		}
+		// The value is checked by normalize, so it doesn't fail.
+		if err = c.flags.Set(change.Key, change.NewValue); err != nil {
+			return nil, errors.Trace(err)
+		}
+		c.hotReload[change.Key]()
+	}
+	return changes, nil
+}
+
+// read reads the configuration file, the values are formatted like the command line arguments.
+func (c *Config) read() (map[string]string, error) {
+	file, err := os.Open(c.path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer file.Close()
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	if err = decoder.Decode(&raw); err != nil {
+		return nil, errors.Annotatef(err, "invalid configuration file %s", c.path)
+	}
+	values := make(map[string]string, len(raw))
+	for key, v := range raw {
+		if c.flags.Lookup(key) == nil {
+			return nil, ErrUnknownKey.GenByArgs(key)
+		}
+		switch x := v.(type) {
+		case string:
+			values[key] = x
+		case json.Number:
+			values[key] = x.String()
+		case bool:
+			values[key] = fmt.Sprint(x)
+		default:
+			return nil, ErrInvalidValue.GenByArgs(v, key)
+		}
+	}
+	return values, nil
+}
+
+// normalize parses the value like the flag without changing the flag, and formats it like the flag does,
+// so "1" and "true" are the same value of a bool flag.
+func normalize(f *flag.Flag, value string) (string, error) {
+	tp := reflect.TypeOf(f.Value)
+	if tp.Kind() != reflect.Ptr {
+		return value, nil
+	}
+	v, ok := reflect.New(tp.Elem()).Interface().(flag.Value)
+	if !ok {
+		return value, nil
+	}
+	if err := v.Set(value); err != nil {
+		return "", ErrInvalidValue.GenByArgs(value, f.Name)
+	}
+	return v.String(), nil
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// server is the configuration of the running tidb-server, it's nil if the server is started without a
+// configuration file.
+var server struct {
+	sync.RWMutex
+	config *Config
+}
+
+// SetServerConfig sets the configuration reloaded by ReloadServerConfig.
+func SetServerConfig(c *Config) {
+	server.Lock()
+	server.config = c
+	server.Unlock()
+}
+
+// ReloadServerConfig reloads the configuration of the running tidb-server, it's used by the SIGHUP handler
+// and the 'admin reload config' statement.
+func ReloadServerConfig() ([]Change, error) {
+	server.RLock()
+	c := server.config
+	server.RUnlock()
+	if c == nil {
+		return nil, ErrNoConfigFile
+	}
+	changes, err := c.Reload()
+	return changes, errors.Trace(err)
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testConfigSuite{})
+
+type testConfigSuite struct{}
+
+func (s *testConfigSuite) TestReload(c *C) {
+	defer testleak.AfterTest(c)()
+	file, err := ioutil.TempFile("", "tidb-config")
+	c.Assert(err, IsNil)
+	c.Assert(file.Close(), IsNil)
+	defer os.Remove(file.Name())
+	writeConfig := func(content string) {
+		c.Assert(ioutil.WriteFile(file.Name(), []byte(content), 0644), IsNil)
+	}
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	level := flags.String("L", "info", "")
+	port := flags.String("P", "4000", "")
+	readOnly := flags.Bool("read-only", false, "")
+	lease := flags.Duration("lease", time.Second, "")
+	limit := flags.Int("retry-limit", 10, "")
+	c.Assert(flags.Parse([]string{"-P", "4001"}), IsNil)
+	conf := New(file.Name(), flags)
+	applied := make(map[string]int)
+	for _, name := range []string{"L", "read-only", "retry-limit"} {
+		name := name
+		conf.HotReload(name, func() { applied[name]++ })
+	}
+
+	// The flags given on the command line take precedence.
+	writeConfig(`{"L": "warn", "P": 4002, "lease": "2s"}`)
+	c.Assert(conf.Load(), IsNil)
+	c.Assert(*level, Equals, "warn")
+	c.Assert(*port, Equals, "4001")
+	c.Assert(*lease, Equals, 2*time.Second)
+	c.Assert(applied, HasLen, 0)
+
+	// The equal values aren't changes.
+	writeConfig(`{"L": "warn", "P": 4003, "lease": "2000ms", "read-only": false, "retry-limit": 10}`)
+	changes, err := conf.Reload()
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 0)
+
+	writeConfig(`{"L": "error", "lease": "3s", "read-only": 1, "retry-limit": 5}`)
+	changes, err = conf.Reload()
+	c.Assert(err, IsNil)
+	c.Assert(changes, DeepEquals, []Change{
+		{Key: "L", OldValue: "warn", NewValue: "error", Reloaded: true},
+		{Key: "lease", OldValue: "2s", NewValue: "3s", Reloaded: false},
+		{Key: "read-only", OldValue: "false", NewValue: "true", Reloaded: true},
+		{Key: "retry-limit", OldValue: "10", NewValue: "5", Reloaded: true},
+	})
+	c.Assert(*level, Equals, "error")
+	c.Assert(*lease, Equals, 2*time.Second)
+	c.Assert(*readOnly, IsTrue)
+	c.Assert(*limit, Equals, 5)
+	c.Assert(applied, DeepEquals, map[string]int{"L": 1, "read-only": 1, "retry-limit": 1})
+
+	// Nothing is applied if the file is invalid.
+	for _, content := range []string{`{"L": "debug", "retry-limit": "x"}`, `{"L": "debug", "retry-limit": [1]}`} {
+		writeConfig(content)
+		_, err = conf.Reload()
+		c.Assert(terror.ErrorEqual(err, ErrInvalidValue), IsTrue, Commentf("%s", content))
+	}
+	writeConfig(`{"L": "debug", "unknown": 1}`)
+	_, err = conf.Reload()
+	c.Assert(terror.ErrorEqual(err, ErrUnknownKey), IsTrue)
+	writeConfig(`{"L": "debug"`)
+	_, err = conf.Reload()
+	c.Assert(err, NotNil)
+	c.Assert(*level, Equals, "error")
+	c.Assert(applied["L"], Equals, 1)
+}
+
+func (s *testConfigSuite) TestServerConfig(c *C) {
+	defer testleak.AfterTest(c)()
+	_, err := ReloadServerConfig()
+	c.Assert(terror.ErrorEqual(err, ErrNoConfigFile), IsTrue)
+
+	file, err := ioutil.TempFile("", "tidb-config")
+	c.Assert(err, IsNil)
+	c.Assert(file.Close(), IsNil)
+	defer os.Remove(file.Name())
+	c.Assert(ioutil.WriteFile(file.Name(), []byte(`{"L": "warn"}`), 0644), IsNil)
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("L", "info", "")
+	conf := New(file.Name(), flags)
+	c.Assert(conf.Load(), IsNil)
+	SetServerConfig(conf)
+	defer SetServerConfig(nil)
+	changes, err := ReloadServerConfig()
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 0)
+}
@@ -141,6 +141,13 @@ func (d *ddl) onAddColumn(t *meta.Meta, job *model.Job) error {
 		columnInfo.State = model.StateWriteOnly
 		_, err = updateTableInfo(t, job, tblInfo, originalState)
 	case model.StateWriteOnly:
+		if !columnInfo.DefaultIsExpr {
+			// write only -> public
+			// The rows without the column use the origin default value when they are read,
+			// so the column with a constant default value is added without the reorganization.
+			err = d.finishAddColumn(t, job, tblInfo, columnInfo, offset, originalState)
+			break
+		}
 		// write only -> reorganization
 		job.SchemaState = model.StateWriteReorganization
 		columnInfo.State = model.StateWriteReorganization
@@ -157,20 +164,25 @@ func (d *ddl) onAddColumn(t *meta.Meta, job *model.Job) error {
 			return errors.Trace(err)
 		}
 
-		// Adjust column offset.
-		d.adjustColumnOffset(tblInfo.Columns, tblInfo.Indices, offset, true)
-		columnInfo.State = model.StatePublic
-		job.SchemaState = model.StatePublic
-		ver, err := updateTableInfo(t, job, tblInfo, originalState)
-		if err != nil {
-			return errors.Trace(err)
-		}
+		if columnInfo.DefaultIsExpr {
+			var tbl table.Table
+			tbl, err = d.getTable(schemaID, tblInfo)
+			if err != nil {
+				return errors.Trace(err)
+			}
 
-		// Finish this job.
-		job.State = model.JobDone
-		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+			// The default value expression is evaluated for every existing row.
+			err = d.runReorgJob(job, func() error {
+				return d.addTableColumn(tbl, columnInfo, reorgInfo, job)
+			})
+			if err != nil {
+				// If the timeout happens, we should return.
+				// Then check for the owner and re-wait job to finish.
+				return errors.Trace(filterError(err, errWaitReorgTimeout))
+			}
+		}
 
-		d.asyncNotifyEvent(&Event{Tp: model.ActionAddColumn, TableInfo: tblInfo, ColumnInfo: columnInfo})
+		err = d.finishAddColumn(t, job, tblInfo, columnInfo, offset, originalState)
 	default:
 		err = ErrInvalidColumnState.Gen("invalid column state %v", columnInfo.State)
 	}
@@ -178,6 +190,26 @@ func (d *ddl) onAddColumn(t *meta.Meta, job *model.Job) error {
 	return errors.Trace(err)
 }
 
+// finishAddColumn makes the added column public and finishes the job.
+func (d *ddl) finishAddColumn(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, columnInfo *model.ColumnInfo,
+	offset int, originalState model.SchemaState) error {
+	// Adjust column offset.
+	d.adjustColumnOffset(tblInfo.Columns, tblInfo.Indices, offset, true)
+	columnInfo.State = model.StatePublic
+	job.SchemaState = model.StatePublic
+	ver, err := updateTableInfo(t, job, tblInfo, originalState)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// Finish this job.
+	job.State = model.JobDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+
+	d.asyncNotifyEvent(&Event{Tp: model.ActionAddColumn, TableInfo: tblInfo, ColumnInfo: columnInfo})
+	return nil
+}
+
 func (d *ddl) onDropColumn(t *meta.Meta, job *model.Job) error {
 	schemaID := job.SchemaID
 	tblInfo, err := getTableInfo(t, job, schemaID)
@@ -265,8 +297,7 @@ func (d *ddl) onDropColumn(t *meta.Meta, job *model.Job) error {
 	return errors.Trace(err)
 }
 
-// addTableColumn adds a column to the table.
-// TODO: Use it when updating the column type or remove it.
+// addTableColumn adds a column to the table, it's used when the default value of the column is an expression.
 // How to backfill column data in reorganization state?
 //  1. Generate a snapshot with special version.
 //  2. Traverse the snapshot, get every row in the table.
@@ -285,7 +316,9 @@ func (d *ddl) addTableColumn(t table.Table, columnInfo *model.ColumnInfo, reorgI
 	handles := make([]int64, 0, defaultBatchCnt)
 	// Get column default value.
 	var err error
-	if columnInfo.DefaultValue != nil {
+	if columnInfo.DefaultIsExpr {
+		colMeta.defaultExpr = columnInfo
+	} else if columnInfo.DefaultValue != nil {
 		colMeta.defaultVal, err = table.GetColDefaultValue(ctx, columnInfo)
 		if err != nil {
 			job.State = model.JobCancelled
@@ -333,7 +366,7 @@ func (d *ddl) addTableColumn(t table.Table, columnInfo *model.ColumnInfo, reorgI
 
 // backfillColumnInTxn deals with a part of backfilling column data in a Transaction.
 // This part of the column data rows is defaultSmallBatchCnt.
-func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []int64, txn kv.Transaction) (int64, error) {
+func (d *ddl) backfillColumnInTxn(ctx context.Context, t table.Table, colMeta *columnMeta, handles []int64, txn kv.Transaction) (int64, error) {
 	nextHandle := handles[0]
 	for _, handle := range handles {
 		log.Debug("[ddl] backfill column...", handle)
@@ -362,8 +395,15 @@ func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []
 			newColumnIDs = append(newColumnIDs, colID)
 			newRow = append(newRow, val)
 		}
+		defaultVal := colMeta.defaultVal
+		if colMeta.defaultExpr != nil {
+			defaultVal, err = table.GetColDefaultValue(ctx, colMeta.defaultExpr)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+		}
 		newColumnIDs = append(newColumnIDs, colMeta.colID)
-		newRow = append(newRow, colMeta.defaultVal)
+		newRow = append(newRow, defaultVal)
 		newRowVal, err := tablecodec.EncodeRow(newRow, newColumnIDs, time.UTC)
 		if err != nil {
 			return 0, errors.Trace(err)
@@ -380,7 +420,9 @@ func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []
 type columnMeta struct {
 	colID      int64
 	defaultVal types.Datum
-	oldColMap  map[int64]*types.FieldType
+	// defaultExpr is the column whose default value expression is evaluated for every row.
+	defaultExpr *model.ColumnInfo
+	oldColMap   map[int64]*types.FieldType
 }
 
 func (d *ddl) backfillColumn(ctx context.Context, t table.Table, colMeta *columnMeta, handles []int64, reorgInfo *reorgInfo) error {
@@ -397,7 +439,7 @@ func (d *ddl) backfillColumn(ctx context.Context, t table.Table, colMeta *column
 				return errors.Trace(err)
 			}
 
-			nextHandle, err1 := d.backfillColumnInTxn(t, colMeta, handles[:endIdx], txn)
+			nextHandle, err1 := d.backfillColumnInTxn(ctx, t, colMeta, handles[:endIdx], txn)
 			if err1 != nil {
 				return errors.Trace(err1)
 			}
@@ -779,6 +779,11 @@ func (s *testColumnSuite) TestAddColumn(c *C) {
 		if newCol == nil {
 			return
 		}
+		if newCol.State == model.StateWriteReorganization {
+			// The column with a constant default value is added without the reorganization.
+			hookErr = errors.Errorf("column %s shouldn't be in the reorganization state", newColName)
+			return
+		}
 
 		err1 = s.checkAddColumn(newCol.State, d, tblInfo, handle, newCol, oldRow, defaultColValue)
 		if err1 != nil {
@@ -913,6 +918,12 @@ func (s *testColumnSuite) TestModifyColumn(c *C) {
 		{"varchar(10)", "varchar(8)", errUnsupportedModifyColumn.GenByArgs("length 8 is less than origin 10")},
 		{"varchar(10)", "varchar(11)", nil},
 		{"varchar(10) character set utf8 collate utf8_bin", "varchar(10) character set utf8", nil},
+		{"enum('a', 'b')", "enum('a', 'b', 'c')", nil},
+		{"enum('a', 'b')", "enum('a')", errUnsupportedModifyColumn.GenByArgs("the number of elements 1 is less than origin 2")},
+		{"enum('a', 'b')", "enum('b', 'a', 'c')", errUnsupportedModifyColumn.GenByArgs("element a is changed to b")},
+		{"set('a', 'b')", "set('a', 'b', 'c')", nil},
+		{"set('a', 'b')", "set('a', 'c')", errUnsupportedModifyColumn.GenByArgs("element b is changed to c")},
+		{"enum('a', 'b')", "set('a', 'b')", errUnsupportedModifyColumn.GenByArgs("type 248 not match origin 247")},
 	}
 	for _, tt := range tests {
 		ftA := s.colDefStrToFieldType(c, tt.origin)
@@ -49,6 +49,7 @@ var (
 	errRunMultiSchemaChanges = terror.ClassDDL.New(codeRunMultiSchemaChanges, "can't run multi schema change")
 	errWaitReorgTimeout      = terror.ClassDDL.New(codeWaitReorgTimeout, "wait for reorganization timeout")
 	errInvalidStoreVer       = terror.ClassDDL.New(codeInvalidStoreVer, "invalid storage current version")
+	errTransferOwner         = terror.ClassDDL.New(codeTransferOwner, "can't transfer the owner elected by etcd")
 
 	// We don't support dropping column with index covered now.
 	errCantDropColWithIndex    = terror.ClassDDL.New(codeCantDropColWithIndex, "can't drop column with index")
@@ -57,6 +58,10 @@ var (
 	errUnsupportedPKHandle     = terror.ClassDDL.New(codeUnsupportedDropPKHandle,
 		"unsupported drop integer primary key")
 	errUnsupportedCharset = terror.ClassDDL.New(codeUnsupportedCharset, "unsupported charset %s collate %s")
+	errInvalidAutoRandom  = terror.ClassDDL.New(codeInvalidAutoRandom, "Invalid auto random: %s")
+
+	errInvalidMultiValuedIndex = terror.ClassDDL.New(codeInvalidMultiValuedIndex, "Invalid multi-valued index: %s")
+	errInvalidAutoIDCache      = terror.ClassDDL.New(codeInvalidAutoIDCache, "Invalid auto id cache: %s")
 
 	errBlobKeyWithoutLength = terror.ClassDDL.New(codeBlobKeyWithoutLength, "index for BLOB/TEXT column must specificate a key length")
 	errIncorrectPrefixKey   = terror.ClassDDL.New(codeIncorrectPrefixKey, "Incorrect prefix key; the used key part isn't a string, the used length is longer than the key part, or the storage engine doesn't support unique prefix keys")
@@ -73,6 +78,8 @@ var (
 	errBadField              = terror.ClassDDL.New(codeBadField, "Unknown column '%s' in '%s'")
 	errInvalidDefault        = terror.ClassDDL.New(codeInvalidDefault, "Invalid default value for '%s'")
 	errInvalidUseOfNull      = terror.ClassDDL.New(codeInvalidUseOfNull, "Invalid use of NULL value")
+	errTooBigFieldLength     = terror.ClassDDL.New(codeTooBigFieldLength, "Column length too big for column '%s' (max = %d); use BLOB or TEXT instead")
+	errTooBigPrecision       = terror.ClassDDL.New(codeTooBigPrecision, "Too big precision %d specified for column '%s'. Maximum is %d.")
 
 	// ErrInvalidDBState returns for invalid database state.
 	ErrInvalidDBState = terror.ClassDDL.New(codeInvalidDBState, "invalid database state")
@@ -84,6 +91,8 @@ var (
 	ErrInvalidIndexState = terror.ClassDDL.New(codeInvalidIndexState, "invalid index state")
 	// ErrInvalidForeignKeyState returns for invalid foreign key state.
 	ErrInvalidForeignKeyState = terror.ClassDDL.New(codeInvalidForeignKeyState, "invalid foreign key state")
+	// ErrInvalidTriggerState returns for invalid trigger state.
+	ErrInvalidTriggerState = terror.ClassDDL.New(codeInvalidTriggerState, "invalid trigger state")
 	// ErrUnsupportedModifyPrimaryKey returns an error when add or drop the primary key.
 	// It's exported for testing.
 	ErrUnsupportedModifyPrimaryKey = terror.ClassDDL.New(codeUnsupportedModifyPrimaryKey, "unsupported %s primary key")
@@ -115,6 +124,8 @@ type DDL interface {
 	AlterTable(ctx context.Context, tableIdent ast.Ident, spec []*ast.AlterTableSpec) error
 	TruncateTable(ctx context.Context, tableIdent ast.Ident) error
 	RenameTable(ctx context.Context, oldTableIdent, newTableIdent ast.Ident) error
+	CreateTrigger(ctx context.Context, tableIdent ast.Ident, trigger *model.TriggerInfo) error
+	DropTrigger(ctx context.Context, schema, triggerName model.CIStr) error
 	// SetLease will reset the lease time for online DDL change,
 	// it's a very dangerous function and you must guarantee that all servers have the same lease time.
 	SetLease(lease time.Duration)
@@ -128,6 +139,11 @@ type DDL interface {
 	Stop() error
 	// RegisterEventCh registers event channel for ddl.
 	RegisterEventCh(chan<- *Event)
+	// OwnerInfo returns the owners of the DDL jobs and the background jobs.
+	OwnerInfo() ([]*OwnerInfo, error)
+	// TransferOwner transfers the ownership of the DDL jobs and the background jobs to the server with the ID,
+	// an empty ID means the current owner resigns and the ownership is taken by another server.
+	TransferOwner(target string) error
 }
 
 // Event is an event that a ddl operation happened.
@@ -466,12 +482,14 @@ const (
 	codeInvalidStoreVer                      = 8
 	codeUnknownTypeLength                    = 9
 	codeUnknownFractionLength                = 10
+	codeTransferOwner                        = 11
 
 	codeInvalidDBState         = 100
 	codeInvalidTableState      = 101
 	codeInvalidColumnState     = 102
 	codeInvalidIndexState      = 103
 	codeInvalidForeignKeyState = 104
+	codeInvalidTriggerState    = 105
 
 	codeCantDropColWithIndex        = 201
 	codeUnsupportedAddColumn        = 202
@@ -479,6 +497,9 @@ const (
 	codeUnsupportedDropPKHandle     = 204
 	codeUnsupportedCharset          = 205
 	codeUnsupportedModifyPrimaryKey = 206
+	codeInvalidAutoRandom           = 207
+	codeInvalidMultiValuedIndex     = 208
+	codeInvalidAutoIDCache          = 209
 
 	codeFileNotFound          = 1017
 	codeErrorOnRename         = 1025
@@ -488,6 +509,7 @@ const (
 	codeDupKeyName            = 1061
 	codeInvalidDefault        = 1067
 	codeTooLongKey            = 1071
+	codeTooBigFieldLength     = 1074
 	codeKeyColumnDoesNotExits = 1072
 	codeIncorrectPrefixKey    = 1089
 	codeCantRemoveAllFields   = 1090
@@ -497,6 +519,7 @@ const (
 	codeInvalidUseOfNull      = 1138
 	codeBlobKeyWithoutLength  = 1170
 	codeInvalidOnUpdate       = 1294
+	codeTooBigPrecision       = 1426
 )
 
 func init() {
@@ -518,6 +541,8 @@ func init() {
 		codeBadField:              mysql.ErrBadField,
 		codeInvalidDefault:        mysql.ErrInvalidDefault,
 		codeInvalidUseOfNull:      mysql.ErrInvalidUseOfNull,
+		codeTooBigFieldLength:     mysql.ErrTooBigFieldlength,
+		codeTooBigPrecision:       mysql.ErrTooBigPrecision,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassDDL] = ddlMySQLErrCodes
 }
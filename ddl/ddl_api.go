@@ -211,12 +211,42 @@ func setCharsetCollationFlenDecimal(tp *types.FieldType) error {
 	return nil
 }
 
+// checkColumnFieldLength checks the byte length of a VARCHAR column doesn't exceed the max
+// row size of MySQL, the byte length depends on the charset.
+func checkColumnFieldLength(colName string, tp *types.FieldType) error {
+	if tp.Tp != mysql.TypeVarchar {
+		return nil
+	}
+	maxLen := charset.GetMaxLen(tp.Charset)
+	if tp.Flen*maxLen > mysql.MaxFieldVarCharLength {
+		return errTooBigFieldLength.GenByArgs(colName, mysql.MaxFieldVarCharLength/maxLen)
+	}
+	return nil
+}
+
+// checkColumnFsp checks the fractional seconds precision of a temporal column is in [0, 6].
+func checkColumnFsp(colName string, tp *types.FieldType) error {
+	switch tp.Tp {
+	case mysql.TypeDatetime, mysql.TypeTimestamp, mysql.TypeDuration:
+		if tp.Decimal > types.MaxFsp {
+			return errTooBigPrecision.GenByArgs(tp.Decimal, colName, types.MaxFsp)
+		}
+	}
+	return nil
+}
+
 func buildColumnAndConstraint(ctx context.Context, offset int,
 	colDef *ast.ColumnDef) (*table.Column, []*ast.Constraint, error) {
 	err := setCharsetCollationFlenDecimal(colDef.Tp)
 	if err != nil {
 		return nil, nil, errors.Trace(err)
 	}
+	if err = checkColumnFieldLength(colDef.Name.Name.O, colDef.Tp); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if err = checkColumnFsp(colDef.Name.Name.O, colDef.Tp); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
 	col, cts, err := columnDefToCol(ctx, offset, colDef)
 	if err != nil {
 		return nil, nil, errors.Trace(err)
@@ -288,16 +318,22 @@ func columnDefToCol(ctx context.Context, offset int, colDef *ast.ColumnDef) (*ta
 				constraints = append(constraints, constraint)
 				col.Flag |= mysql.UniqueKeyFlag
 			case ast.ColumnOptionDefaultValue:
-				value, err := getDefaultValue(ctx, v, colDef.Tp.Tp, colDef.Tp.Decimal)
-				if err != nil {
-					return nil, nil, ErrColumnBadNull.Gen("invalid default value - %s", err)
+				if expr, ok := getDefaultValueExpr(v); ok {
+					if err := setDefaultValueExpr(col, expr); err != nil {
+						return nil, nil, errors.Trace(err)
+					}
+				} else {
+					value, err := getDefaultValue(ctx, v, colDef.Tp.Tp, colDef.Tp.Decimal)
+					if err != nil {
+						return nil, nil, ErrColumnBadNull.Gen("invalid default value - %s", err)
+					}
+					col.DefaultValue = value
 				}
-				col.DefaultValue = value
 				hasDefaultValue = true
 				removeOnUpdateNowFlag(col)
 			case ast.ColumnOptionOnUpdate:
 				// TODO: Support other time functions.
-				if !expression.IsCurrentTimeExpr(v.Expr) {
+				if !expression.IsValidCurrentTimeExpr(v.Expr, colDef.Tp.Decimal) {
 					return nil, nil, ErrInvalidOnUpdate.Gen("invalid ON UPDATE for - %s", col.Name)
 				}
 
@@ -310,6 +346,8 @@ func columnDefToCol(ctx context.Context, offset int, colDef *ast.ColumnDef) (*ta
 				}
 			case ast.ColumnOptionFulltext:
 				// TODO: Support this type.
+			case ast.ColumnOptionAutoRandom:
+				// It's checked and set by setAutoRandomBits after the table info is built.
 			}
 		}
 	}
@@ -330,9 +368,62 @@ func columnDefToCol(ctx context.Context, offset int, colDef *ast.ColumnDef) (*ta
 	return col, constraints, nil
 }
 
+// getDefaultValueExpr returns the expression of the default value if it's not a constant,
+// the expression is enclosed in parentheses like `DEFAULT (UUID())`.
+func getDefaultValueExpr(c *ast.ColumnOption) (ast.ExprNode, bool) {
+	p, ok := c.Expr.(*ast.ParenthesesExpr)
+	if !ok {
+		return nil, false
+	}
+	if _, ok = p.Expr.(*ast.ValueExpr); ok {
+		return nil, false
+	}
+	return p.Expr, true
+}
+
+// setDefaultValueExpr sets the default value of the column to the text of the expression.
+func setDefaultValueExpr(col *table.Column, expr ast.ExprNode) error {
+	checker := &defaultValueExprChecker{name: col.Name.O}
+	expr.Accept(checker)
+	if checker.err != nil {
+		return errors.Trace(checker.err)
+	}
+	col.DefaultValue = expr.Text()
+	col.DefaultIsExpr = true
+	return nil
+}
+
+// defaultValueExprChecker checks whether the expression can be used as the default value. Like MySQL,
+// the expression can't refer to the columns, the variables, the parameters or the subqueries.
+type defaultValueExprChecker struct {
+	name string
+	err  error
+}
+
+// Enter implements ast.Visitor interface.
+func (c *defaultValueExprChecker) Enter(in ast.Node) (ast.Node, bool) {
+	switch in.(type) {
+	case *ast.ColumnNameExpr, *ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.VariableExpr,
+		*ast.ParamMarkerExpr, *ast.DefaultExpr, *ast.ValuesExpr, *ast.AggregateFuncExpr:
+		c.err = errInvalidDefault.GenByArgs(c.name)
+		return in, true
+	}
+	return in, false
+}
+
+// Leave implements ast.Visitor interface.
+func (c *defaultValueExprChecker) Leave(in ast.Node) (ast.Node, bool) {
+	return in, c.err == nil
+}
+
 func getDefaultValue(ctx context.Context, c *ast.ColumnOption, tp byte, fsp int) (interface{}, error) {
+	expr := c.Expr
+	if p, ok := expr.(*ast.ParenthesesExpr); ok {
+		// It's a constant like `DEFAULT (1)`.
+		expr = p.Expr
+	}
 	if tp == mysql.TypeTimestamp || tp == mysql.TypeDatetime {
-		vd, err := expression.GetTimeValue(ctx, c.Expr, tp, fsp)
+		vd, err := expression.GetTimeValue(ctx, expr, tp, fsp)
 		value := vd.GetValue()
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -350,7 +441,7 @@ func getDefaultValue(ctx context.Context, c *ast.ColumnOption, tp byte, fsp int)
 
 		return value, nil
 	}
-	v, err := expression.EvalAstExpr(c.Expr, ctx)
+	v, err := expression.EvalAstExpr(expr, ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -582,6 +673,9 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 		case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
 			idxInfo.Unique = true
 		}
+		if err = checkMultiValuedIndexUnique(idxInfo.Unique, constr.Keys); err != nil {
+			return nil, errors.Trace(err)
+		}
 		// set index type.
 		if constr.Option != nil {
 			idxInfo.Comment = constr.Option.Comment
@@ -596,6 +690,47 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 	return
 }
 
+// setClusteredIndex makes the primary key of the table clustered if it's not the handle. The primary
+// keys with prefix columns aren't clustered, their entries can't tell the rows by the whole key values.
+func setClusteredIndex(tbInfo *model.TableInfo) {
+	for _, idx := range tbInfo.Indices {
+		if idx.Primary && !idx.HasPrefixIndex() && !idx.IsMultiValued() {
+			idx.Clustered = true
+		}
+	}
+}
+
+// setAutoRandomBits sets the shard bits of the AUTO_RANDOM column, which must be the BIGINT
+// primary key handle of the table without AUTO_INCREMENT or default value.
+func setAutoRandomBits(tbInfo *model.TableInfo, cols []*table.Column, colDefs []*ast.ColumnDef) error {
+	for _, colDef := range colDefs {
+		for _, op := range colDef.Options {
+			if op.Tp != ast.ColumnOptionAutoRandom {
+				continue
+			}
+			col := table.FindCol(cols, colDef.Name.Name.L)
+			if col.Tp != mysql.TypeLonglong || !col.IsPKHandleColumn(tbInfo) {
+				return errInvalidAutoRandom.GenByArgs(fmt.Sprintf("column %s isn't the BIGINT primary key", col.Name))
+			}
+			if mysql.HasAutoIncrementFlag(col.Flag) {
+				return errInvalidAutoRandom.GenByArgs("AUTO_RANDOM can't be used with AUTO_INCREMENT")
+			}
+			if col.DefaultValue != nil {
+				return errInvalidAutoRandom.GenByArgs("AUTO_RANDOM column can't have a default value")
+			}
+			bits := op.AutoRandomBits
+			if bits == types.UnspecifiedLength {
+				bits = autoid.DefaultAutoRandomBits
+			}
+			if bits < 1 || bits > autoid.MaxAutoRandomBits {
+				return errInvalidAutoRandom.GenByArgs(fmt.Sprintf("the shard bits must be in [1, %d]", autoid.MaxAutoRandomBits))
+			}
+			tbInfo.AutoRandomBits = uint64(bits)
+		}
+	}
+	return nil
+}
+
 func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ident) error {
 	is := d.GetInformationSchema()
 	_, ok := is.SchemaByName(referIdent.Schema)
@@ -669,6 +804,12 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if err = setAutoRandomBits(tbInfo, cols, colDefs); err != nil {
+		return errors.Trace(err)
+	}
+	if ctx.GetSessionVars().EnableClusteredIndex {
+		setClusteredIndex(tbInfo)
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -678,7 +819,9 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 		Args:       []interface{}{tbInfo},
 	}
 
-	handleTableOptions(options, tbInfo)
+	if err = handleTableOptions(options, tbInfo); err != nil {
+		return errors.Trace(err)
+	}
 	err = d.doDDLJob(ctx, job)
 	if err == nil {
 		if tbInfo.AutoIncID > 1 {
@@ -697,7 +840,7 @@ func (d *ddl) handleAutoIncID(tbInfo *model.TableInfo, schemaID int64) error {
 	if tbInfo.OldSchemaID != 0 {
 		schemaID = tbInfo.OldSchemaID
 	}
-	alloc := autoid.NewAllocator(d.store, schemaID)
+	alloc := autoid.NewAllocatorFromTableInfo(d.store, schemaID, tbInfo)
 
 	tbInfo.State = model.StatePublic
 	tb, err := table.TableFromMeta(alloc, tbInfo)
@@ -714,11 +857,16 @@ func (d *ddl) handleAutoIncID(tbInfo *model.TableInfo, schemaID int64) error {
 }
 
 // handleTableOptions updates tableInfo according to table options.
-func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) {
+func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) error {
 	for _, op := range options {
 		switch op.Tp {
 		case ast.TableOptionAutoIncrement:
 			tbInfo.AutoIncID = int64(op.UintValue)
+		case ast.TableOptionAutoIDCache:
+			if op.UintValue < 1 || op.UintValue > uint64(autoid.MaxStep) {
+				return errInvalidAutoIDCache.GenByArgs(fmt.Sprintf("the cache size must be in [1, %d]", autoid.MaxStep))
+			}
+			tbInfo.AutoIDCache = int64(op.UintValue)
 		case ast.TableOptionComment:
 			tbInfo.Comment = op.StrValue
 		case ast.TableOptionCharset:
@@ -727,6 +875,7 @@ func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) {
 			tbInfo.Collate = op.StrValue
 		}
 	}
+	return nil
 }
 
 func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.AlterTableSpec) (err error) {
@@ -795,7 +944,8 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 func checkColumnConstraint(constraints []*ast.ColumnOption) error {
 	for _, constraint := range constraints {
 		switch constraint.Tp {
-		case ast.ColumnOptionAutoIncrement, ast.ColumnOptionPrimaryKey, ast.ColumnOptionUniq, ast.ColumnOptionUniqKey:
+		case ast.ColumnOptionAutoIncrement, ast.ColumnOptionPrimaryKey, ast.ColumnOptionUniq, ast.ColumnOptionUniqKey,
+			ast.ColumnOptionAutoRandom:
 			return errUnsupportedAddColumn.Gen("unsupported add column constraint - %v", constraint.Tp)
 		}
 	}
@@ -839,7 +989,11 @@ func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableS
 	if err != nil {
 		return errors.Trace(err)
 	}
-	col.OriginDefaultValue = col.DefaultValue
+	// The rows without the column use the origin default value when they are read. The default value
+	// expression is evaluated for every existing row when the column is added, so it isn't the origin one.
+	if !col.DefaultIsExpr {
+		col.OriginDefaultValue = col.DefaultValue
+	}
 	if col.OriginDefaultValue == nil && mysql.HasNotNullFlag(col.Flag) {
 		zeroVal := table.GetZeroValue(col.ToInfo())
 		col.OriginDefaultValue, err = zeroVal.ToString()
@@ -948,6 +1102,10 @@ func modifiable(origin *types.FieldType, to *types.FieldType) error {
 		case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
 			return nil
 		}
+	case mysql.TypeEnum, mysql.TypeSet:
+		if origin.Tp == to.Tp {
+			return errors.Trace(checkModifyElems(origin.Elems, to.Elems))
+		}
 	default:
 		if origin.Tp == to.Tp {
 			return nil
@@ -957,12 +1115,36 @@ func modifiable(origin *types.FieldType, to *types.FieldType) error {
 	return errUnsupportedModifyColumn.GenByArgs(msg)
 }
 
+// checkModifyElems checks whether the elements of an enum or set column can be changed
+// without rewriting the data. The stored values are the indexes of the elements, so
+// new elements can only be appended to the end of the origin ones.
+func checkModifyElems(origin []string, to []string) error {
+	if len(to) < len(origin) {
+		msg := fmt.Sprintf("the number of elements %d is less than origin %d", len(to), len(origin))
+		return errUnsupportedModifyColumn.GenByArgs(msg)
+	}
+	for i, elem := range origin {
+		if to[i] != elem {
+			msg := fmt.Sprintf("element %s is changed to %s", elem, to[i])
+			return errUnsupportedModifyColumn.GenByArgs(msg)
+		}
+	}
+	return nil
+}
+
 func setDefaultValue(ctx context.Context, col *table.Column, option *ast.ColumnOption) error {
+	if expr, ok := getDefaultValueExpr(option); ok {
+		if err := setDefaultValueExpr(col, expr); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(checkDefaultValue(ctx, col, true))
+	}
 	value, err := getDefaultValue(ctx, option, col.Tp, col.Decimal)
 	if err != nil {
 		return ErrColumnBadNull.Gen("invalid default value - %s", err)
 	}
 	col.DefaultValue = value
+	col.DefaultIsExpr = false
 	return errors.Trace(checkDefaultValue(ctx, col, true))
 }
 
@@ -984,11 +1166,17 @@ func setDefaultAndComment(ctx context.Context, col *table.Column, options []*ast
 	for _, opt := range options {
 		switch opt.Tp {
 		case ast.ColumnOptionDefaultValue:
-			value, err := getDefaultValue(ctx, opt, col.Tp, col.Decimal)
-			if err != nil {
-				return ErrColumnBadNull.Gen("invalid default value - %s", err)
+			if expr, ok := getDefaultValueExpr(opt); ok {
+				if err := setDefaultValueExpr(col, expr); err != nil {
+					return errors.Trace(err)
+				}
+			} else {
+				value, err := getDefaultValue(ctx, opt, col.Tp, col.Decimal)
+				if err != nil {
+					return ErrColumnBadNull.Gen("invalid default value - %s", err)
+				}
+				col.DefaultValue = value
 			}
-			col.DefaultValue = value
 			hasDefaultValue = true
 		case ast.ColumnOptionComment:
 			err := setColumnComment(ctx, col, opt)
@@ -1001,7 +1189,7 @@ func setDefaultAndComment(ctx context.Context, col *table.Column, options []*ast
 			col.Flag &= ^uint(mysql.NotNullFlag)
 		case ast.ColumnOptionOnUpdate:
 			// TODO: Support other time functions.
-			if !expression.IsCurrentTimeExpr(opt.Expr) {
+			if !expression.IsValidCurrentTimeExpr(opt.Expr, col.Decimal) {
 				return ErrInvalidOnUpdate.Gen("invalid ON UPDATE for - %s", col.Name)
 			}
 
@@ -1054,10 +1242,19 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if err = checkColumnFieldLength(spec.NewColumn.Name.Name.O, &newCol.FieldType); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = checkColumnFsp(spec.NewColumn.Name.Name.O, &newCol.FieldType); err != nil {
+		return nil, errors.Trace(err)
+	}
 	err = modifiable(&col.FieldType, &newCol.FieldType)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if t.Meta().AutoRandomBits > 0 && col.IsPKHandleColumn(t.Meta()) && newCol.Tp != mysql.TypeLonglong {
+		return nil, errUnsupportedModifyColumn.GenByArgs("type of the AUTO_RANDOM column")
+	}
 	if err := setDefaultAndComment(ctx, newCol, spec.NewColumn.Options); err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -1145,6 +1342,7 @@ func (d *ddl) AlterColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterT
 
 	if len(spec.NewColumn.Options) == 0 {
 		col.DefaultValue = nil
+		col.DefaultIsExpr = false
 	} else {
 		err := setDefaultValue(ctx, col, spec.NewColumn.Options[0])
 		if err != nil {
@@ -1272,6 +1470,10 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 		return errors.Trace(infoschema.ErrTableNotExists)
 	}
 
+	if err = checkMultiValuedIndexUnique(unique, idxColNames); err != nil {
+		return errors.Trace(err)
+	}
+
 	// Deal with anonymous index.
 	if len(indexName.L) == 0 {
 		indexName = getAnonymousIndex(t, idxColNames[0].Column.Name)
@@ -130,6 +130,14 @@ func (s *testDBSuite) TestMySQLErrorCode(c *C) {
 	s.testErrorCode(c, sql, tmysql.ErrKeyColumnDoesNotExits)
 	sql = "create table test_error_code1 (c1 int not null default '')"
 	s.testErrorCode(c, sql, tmysql.ErrInvalidDefault)
+	sql = "create table test_error_code1 (c1 varchar(21846) charset utf8)"
+	s.testErrorCode(c, sql, tmysql.ErrTooBigFieldlength)
+	sql = "create table test_error_code1 (c1 varchar(32768) charset gbk)"
+	s.testErrorCode(c, sql, tmysql.ErrTooBigFieldlength)
+	sql = "create table test_error_code1 (c1 datetime(7))"
+	s.testErrorCode(c, sql, tmysql.ErrTooBigPrecision)
+	sql = "create table test_error_code1 (c1 time(10))"
+	s.testErrorCode(c, sql, tmysql.ErrTooBigPrecision)
 	// add column
 	sql = "alter table test_error_code_succ add column c1 int"
 	s.testErrorCode(c, sql, tmysql.ErrDupFieldName)
@@ -1097,6 +1105,41 @@ out:
 	s.tk.MustQuery("select c2, c3 from tnn where c1 = 99").Check(testkit.Rows(expected))
 }
 
+func (s *testDBSuite) TestAddColumnWithExprDefault(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use test_db")
+	s.tk.MustExec("create table t_expr_def (c1 int primary key, c2 int)")
+	s.tk.MustExec("insert t_expr_def values (1, 1), (2, 2), (3, 3)")
+
+	// The default value expression is evaluated for every existing row.
+	s.tk.MustExec("alter table t_expr_def add column c3 varchar(36) default (uuid())")
+	s.tk.MustQuery("select count(distinct c3), count(c3) from t_expr_def").Check(testkit.Rows("3 3"))
+	s.tk.MustExec("insert t_expr_def (c1, c2) values (4, 4)")
+	s.tk.MustQuery("select count(distinct c3), count(c3) from t_expr_def").Check(testkit.Rows("4 4"))
+	s.tk.MustExec("alter table t_expr_def add column c4 int not null default (length('ab')) after c2")
+	s.tk.MustQuery("select c4 from t_expr_def").Check(testkit.Rows("2", "2", "2", "2"))
+	s.tk.MustExec("alter table t_expr_def add column c5 int default (1 + 2)")
+	s.tk.MustExec("insert t_expr_def (c1) values (5)")
+	s.tk.MustQuery("select c5 from t_expr_def").Check(testkit.Rows("3", "3", "3", "3", "3"))
+	// The parenthesized constant is a constant default value.
+	s.tk.MustExec("alter table t_expr_def add column c6 int default (6)")
+	s.tk.MustQuery("select c6 from t_expr_def where c1 = 1").Check(testkit.Rows("6"))
+
+	r := s.tk.MustQuery("show create table t_expr_def")
+	createSQL := r.Rows()[0][1].(string)
+	c.Assert(strings.Contains(createSQL, "`c3` varchar(36) DEFAULT (uuid())"), IsTrue, Commentf("%s", createSQL))
+	c.Assert(strings.Contains(createSQL, "`c5` int(11) DEFAULT (1 + 2)"), IsTrue, Commentf("%s", createSQL))
+	c.Assert(strings.Contains(createSQL, "`c6` int(11) DEFAULT '6'"), IsTrue, Commentf("%s", createSQL))
+
+	_, err := s.tk.Exec("alter table t_expr_def add column c7 int default (c100 + 1)")
+	c.Assert(err, NotNil)
+	s.tk.MustExec("alter table t_expr_def alter column c2 set default (1 + 1)")
+	s.tk.MustExec("insert t_expr_def (c1) values (6)")
+	s.tk.MustQuery("select c2, c5 from t_expr_def where c1 = 6").Check(testkit.Rows("2 3"))
+	s.tk.MustExec("drop table t_expr_def")
+}
+
 func (s *testDBSuite) TestIssue2858And2717(c *C) {
 	defer testleak.AfterTest(c)()
 	s.tk = testkit.NewTestKit(c, s.store)
@@ -126,9 +126,12 @@ func (d *ddl) checkOwner(t *meta.Meta, flag JobType) (*model.Owner, error) {
 	now := time.Now().UnixNano()
 	maxTimeout := d.getCheckOwnerTimeout(flag)
 	sub := now - owner.LastUpdateTS
-	if owner.OwnerID == d.uuid || sub > maxTimeout {
+	// The ownership released by the resigned owner is taken by the other servers immediately.
+	released := owner.OwnerID == "" && owner.ResignedID != d.uuid
+	if owner.OwnerID == d.uuid || sub > maxTimeout || released {
 		owner.OwnerID = d.uuid
 		owner.LastUpdateTS = now
+		owner.ResignedID = ""
 		// update status.
 		switch flag {
 		case ddlJobFlag:
@@ -393,6 +396,10 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) {
 		err = d.onRenameTable(t, job)
 	case model.ActionSetDefaultValue:
 		err = d.onSetDefaultValue(t, job)
+	case model.ActionCreateTrigger:
+		err = d.onCreateTrigger(t, job)
+	case model.ActionDropTrigger:
+		err = d.onDropTrigger(t, job)
 	default:
 		// Invalid job, cancel it.
 		job.State = model.JobCancelled
@@ -83,6 +83,55 @@ func (s *testDDLSuite) TestCheckOwner(c *C) {
 	c.Assert(d2.GetLease(), Equals, 2*time.Second)
 }
 
+func (s *testDDLSuite) TestTransferOwner(c *C) {
+	defer testleak.AfterTest(c)()
+	store := testCreateStore(c, "test_transfer_owner")
+	defer store.Close()
+
+	d1 := newDDL(store, nil, nil, testLease)
+	defer d1.Stop()
+	time.Sleep(testLease)
+	testCheckOwner(c, d1, true, ddlJobFlag)
+	testCheckOwner(c, d1, true, bgJobFlag)
+	d2 := newDDL(store, nil, nil, testLease)
+	defer d2.Stop()
+
+	infos, err := d2.OwnerInfo()
+	c.Assert(err, IsNil)
+	c.Assert(infos, HasLen, 2)
+	for i, tp := range []string{"ddl", "background"} {
+		c.Assert(infos[i].Type, Equals, tp)
+		c.Assert(infos[i].OwnerID, Equals, d1.uuid)
+		c.Assert(infos[i].ServerID, Equals, d2.uuid)
+		c.Assert(infos[i].Expired, IsFalse)
+		c.Assert(infos[i].QueueLen, Equals, int64(0))
+	}
+
+	c.Assert(d1.TransferOwner(d2.uuid), IsNil)
+	for _, flag := range []JobType{ddlJobFlag, bgJobFlag} {
+		testCheckOwner(c, d1, false, flag)
+		testCheckOwner(c, d2, true, flag)
+	}
+
+	// The resigned owner can't take the ownership until it times out.
+	c.Assert(d1.TransferOwner(""), IsNil)
+	for _, flag := range []JobType{ddlJobFlag, bgJobFlag} {
+		testCheckOwner(c, d2, false, flag)
+		testCheckOwner(c, d1, true, flag)
+	}
+
+	// The unknown owner isn't taken until it times out.
+	c.Assert(d2.TransferOwner("unknown"), IsNil)
+	for _, flag := range []JobType{ddlJobFlag, bgJobFlag} {
+		testCheckOwner(c, d1, false, flag)
+		testCheckOwner(c, d2, false, flag)
+	}
+	infos, err = d1.OwnerInfo()
+	c.Assert(err, IsNil)
+	c.Assert(infos[0].OwnerID, Equals, "unknown")
+	c.Assert(infos[0].Expired, IsFalse)
+}
+
 func (s *testDDLSuite) TestSchemaError(c *C) {
 	defer testleak.AfterTest(c)()
 	store := testCreateStore(c, "test_schema_error")
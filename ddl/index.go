@@ -14,6 +14,7 @@
 package ddl
 
 import (
+	"fmt"
 	"math"
 	"sort"
 	"sync"
@@ -32,6 +33,7 @@ import (
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/types/json"
 )
 
 const maxPrefixLength = 3072
@@ -49,6 +51,20 @@ func buildIndexColumns(columns []*model.ColumnInfo, idxColNames []*ast.IndexColN
 			return nil, errKeyColumnDoesNotExits.Gen("column does not exist: %s", ic.Column.Name)
 		}
 
+		if ic.MultiValued {
+			mv, err := buildMultiValuedKeyPart(col, ic, len(idxColNames))
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			idxColumns = append(idxColumns, &model.IndexColumn{
+				Name:        col.Name,
+				Offset:      col.Offset,
+				Length:      types.UnspecifiedLength,
+				MultiValued: mv,
+			})
+			continue
+		}
+
 		// Length must be specified for BLOB and TEXT column indexes.
 		if types.IsTypeBlob(col.FieldType.Tp) && ic.Length == types.UnspecifiedLength {
 			return nil, errors.Trace(errBlobKeyWithoutLength)
@@ -116,6 +132,47 @@ func buildIndexColumns(columns []*model.ColumnInfo, idxColNames []*ast.IndexColN
 	return idxColumns, nil
 }
 
+// buildMultiValuedKeyPart builds the key part `CAST(json_path AS type ARRAY)` of a multi-valued index,
+// which must be the only key part of the index on a JSON column.
+func buildMultiValuedKeyPart(col *model.ColumnInfo, ic *ast.IndexColName, keyParts int) (*model.MultiValuedKeyPart, error) {
+	if keyParts != 1 {
+		return nil, errInvalidMultiValuedIndex.GenByArgs("the multi-valued key part must be the only key part")
+	}
+	if col.Tp != mysql.TypeJSON {
+		return nil, errInvalidMultiValuedIndex.GenByArgs(fmt.Sprintf("column %s isn't a JSON column", col.Name))
+	}
+	if _, err := json.ParseJSONPathExpr(ic.Path); err != nil {
+		return nil, errors.Trace(err)
+	}
+	tp := *ic.Tp
+	switch tp.Tp {
+	case mysql.TypeLonglong:
+	case mysql.TypeString:
+		if tp.Flen == types.UnspecifiedLength {
+			return nil, errInvalidMultiValuedIndex.GenByArgs("the length of the CHAR or BINARY array must be specified")
+		}
+		if tp.Flen > maxPrefixLength {
+			return nil, errors.Trace(errTooLongKey)
+		}
+	default:
+		return nil, errInvalidMultiValuedIndex.GenByArgs("only SIGNED, UNSIGNED, CHAR(N) and BINARY(N) arrays are supported")
+	}
+	return &model.MultiValuedKeyPart{Path: ic.Path, Tp: tp}, nil
+}
+
+// checkMultiValuedIndexUnique checks that the multi-valued index isn't unique, as a row has many entries.
+func checkMultiValuedIndexUnique(unique bool, idxColNames []*ast.IndexColName) error {
+	if !unique {
+		return nil
+	}
+	for _, ic := range idxColNames {
+		if ic.MultiValued {
+			return errInvalidMultiValuedIndex.GenByArgs("a multi-valued index can't be unique")
+		}
+	}
+	return nil
+}
+
 func buildIndexInfo(tblInfo *model.TableInfo, indexName model.CIStr, idxColNames []*ast.IndexColName, state model.SchemaState) (*model.IndexInfo, error) {
 	idxColumns, err := buildIndexColumns(tblInfo.Columns, idxColNames)
 	if err != nil {
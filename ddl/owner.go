@@ -22,6 +22,9 @@ import (
 	"github.com/coreos/etcd/mvcc/mvccpb"
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
 	goctx "golang.org/x/net/context"
 )
 
@@ -174,3 +177,108 @@ func (w *worker) watchOwner(ctx goctx.Context, key string) {
 		}
 	}
 }
+
+// OwnerInfo is the owner of the DDL jobs or the background jobs.
+type OwnerInfo struct {
+	// Type is "ddl" or "background".
+	Type string
+	// OwnerID is the ID of the owner, it's empty if there is no owner.
+	OwnerID string
+	// ServerID is the ID of this server.
+	ServerID string
+	// LastUpdate is the last time the owner updated its status, it's zero if there is no owner.
+	LastUpdate time.Time
+	// Expired is true if the owner doesn't update its status in time, so the other servers can take the ownership.
+	Expired bool
+	// QueueLen is the number of the queued jobs.
+	QueueLen int64
+}
+
+// OwnerInfo implements DDL OwnerInfo interface.
+func (d *ddl) OwnerInfo() ([]*OwnerInfo, error) {
+	var infos []*OwnerInfo
+	err := kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		infos = infos[:0]
+		t := meta.NewMeta(txn)
+		for _, flag := range []JobType{ddlJobFlag, bgJobFlag} {
+			info, err := d.ownerInfo(t, flag)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			infos = append(infos, info)
+		}
+		return nil
+	})
+	return infos, errors.Trace(err)
+}
+
+func (d *ddl) ownerInfo(t *meta.Meta, flag JobType) (*OwnerInfo, error) {
+	info := &OwnerInfo{Type: flag.String(), ServerID: d.uuid}
+	var err error
+	if flag == ddlJobFlag {
+		info.QueueLen, err = t.DDLJobQueueLen()
+	} else {
+		info.QueueLen, err = t.BgJobQueueLen()
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if ChangeOwnerInNewWay {
+		// The owner elected by etcd isn't stored in the meta, we only know whether this server is the owner.
+		if (flag == ddlJobFlag && d.worker.isOwner()) || (flag == bgJobFlag && d.worker.isBgOwner()) {
+			info.OwnerID = d.uuid
+		}
+		return info, nil
+	}
+	owner, err := d.getJobOwner(t, flag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if owner == nil || owner.OwnerID == "" {
+		info.Expired = true
+		return info, nil
+	}
+	info.OwnerID = owner.OwnerID
+	info.LastUpdate = time.Unix(0, owner.LastUpdateTS)
+	info.Expired = time.Now().UnixNano()-owner.LastUpdateTS > d.getCheckOwnerTimeout(flag)
+	return info, nil
+}
+
+// TransferOwner implements DDL TransferOwner interface.
+// The target server takes the ownership when it checks the owner next time, if the target server doesn't
+// exist, the ownership is taken by another server after the owner times out.
+func (d *ddl) TransferOwner(target string) error {
+	if ChangeOwnerInNewWay {
+		return errors.Trace(errTransferOwner)
+	}
+	err := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		for _, flag := range []JobType{ddlJobFlag, bgJobFlag} {
+			owner, err := d.getJobOwner(t, flag)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			newOwner := &model.Owner{OwnerID: target, LastUpdateTS: time.Now().UnixNano()}
+			if target == "" && owner != nil {
+				newOwner.ResignedID = owner.OwnerID
+			}
+			if flag == ddlJobFlag {
+				err = t.SetDDLJobOwner(newOwner)
+			} else {
+				err = t.SetBgJobOwner(newOwner)
+			}
+			if err != nil {
+				return errors.Trace(err)
+			}
+			log.Infof("[ddl] transfer %s job owner from %v to %s", flag, owner, target)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The servers check the owner periodically, let this server check it now.
+	asyncNotify(d.ddlJobCh)
+	asyncNotify(d.bgJobCh)
+	return nil
+}
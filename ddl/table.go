@@ -146,7 +146,7 @@ func (d *ddl) getTable(schemaID int64, tblInfo *model.TableInfo) (table.Table, e
 	if tblInfo.OldSchemaID != 0 {
 		schemaID = tblInfo.OldSchemaID
 	}
-	alloc := autoid.NewAllocator(d.store, schemaID)
+	alloc := autoid.NewAllocatorFromTableInfo(d.store, schemaID, tblInfo)
 	tbl, err := table.TableFromMeta(alloc, tblInfo)
 	return tbl, errors.Trace(err)
 }
@@ -0,0 +1,180 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/table"
+)
+
+func (d *ddl) CreateTrigger(ctx context.Context, ti ast.Ident, trigger *model.TriggerInfo) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+	}
+
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+	}
+
+	// Trigger names share a namespace in the whole schema.
+	if findTrigger(is.SchemaTables(ti.Schema), trigger.Name) != nil {
+		return errors.Trace(infoschema.ErrTriggerExists)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionCreateTrigger,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{trigger},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+func (d *ddl) DropTrigger(ctx context.Context, schemaName, triggerName model.CIStr) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(schemaName)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(schemaName)
+	}
+
+	tblInfo := findTrigger(is.SchemaTables(schemaName), triggerName)
+	if tblInfo == nil {
+		return errors.Trace(infoschema.ErrTriggerNotExists)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		Type:       model.ActionDropTrigger,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{triggerName},
+	}
+
+	err := d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// findTrigger returns the meta of the table which owns the trigger with the name, or nil if no table has it.
+func findTrigger(tables []table.Table, name model.CIStr) *model.TableInfo {
+	for _, t := range tables {
+		for _, trigger := range t.Meta().Triggers {
+			if trigger.Name.L == name.L {
+				return t.Meta()
+			}
+		}
+	}
+	return nil
+}
+
+func (d *ddl) onCreateTrigger(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var trigger model.TriggerInfo
+	err = job.DecodeArgs(&trigger)
+	if err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+	for _, tr := range tblInfo.Triggers {
+		if tr.Name.L == trigger.Name.L {
+			job.State = model.JobCancelled
+			return infoschema.ErrTriggerExists
+		}
+	}
+	tblInfo.Triggers = append(tblInfo.Triggers, &trigger)
+
+	originalState := trigger.State
+	switch trigger.State {
+	case model.StateNone:
+		// A trigger does not change any stored data, so we make it public directly.
+		// none -> public
+		job.SchemaState = model.StatePublic
+		trigger.State = model.StatePublic
+		ver, err := updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// Finish this job.
+		job.State = model.JobDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+		return nil
+	default:
+		return ErrInvalidTriggerState.Gen("invalid trigger state %v", trigger.State)
+	}
+}
+
+func (d *ddl) onDropTrigger(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var name model.CIStr
+	err = job.DecodeArgs(&name)
+	if err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	var trigger *model.TriggerInfo
+	triggers := make([]*model.TriggerInfo, 0, len(tblInfo.Triggers))
+	for _, tr := range tblInfo.Triggers {
+		if tr.Name.L == name.L {
+			trigger = tr
+			continue
+		}
+		triggers = append(triggers, tr)
+	}
+	if trigger == nil {
+		job.State = model.JobCancelled
+		return infoschema.ErrTriggerNotExists
+	}
+	tblInfo.Triggers = triggers
+
+	originalState := trigger.State
+	switch trigger.State {
+	case model.StatePublic:
+		// public -> none
+		job.SchemaState = model.StateNone
+		trigger.State = model.StateNone
+		ver, err := updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// Finish this job.
+		job.State = model.JobDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+		return nil
+	default:
+		return ErrInvalidTriggerState.Gen("invalid trigger state %v", trigger.State)
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distsql
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+	goctx "golang.org/x/net/context"
+)
+
+// ChecksumColumn is a column of the index that is checked by a checksum request.
+type ChecksumColumn struct {
+	ID       int64           `json:"id"`
+	Tp       types.FieldType `json:"type"`
+	Length   int             `json:"length"`
+	PKHandle bool            `json:"pk_handle"`
+}
+
+// ChecksumRequest is the request of kv.ReqTypeChecksum. The storage scans the row or the index entries in
+// the key ranges, and computes a digest of (handle, index values) for every handle range of BucketWidth.
+type ChecksumRequest struct {
+	StartTs        uint64           `json:"start_ts"`
+	ScanIndex      bool             `json:"scan_index"`
+	Columns        []ChecksumColumn `json:"columns"`
+	TimeZoneOffset int64            `json:"time_zone_offset"`
+	BucketWidth    int64            `json:"bucket_width"`
+	// Buckets is not empty if the entries in these buckets, instead of the digests, should be returned.
+	Buckets []int64 `json:"buckets,omitempty"`
+}
+
+// ChecksumDigest is the digest of the entries whose handles are in the same bucket.
+type ChecksumDigest struct {
+	Bucket   int64  `json:"bucket"`
+	Count    int64  `json:"count"`
+	Checksum uint64 `json:"checksum"`
+}
+
+// ChecksumEntry is a row or an index entry. Values is the memcomparable encoding of the index values.
+type ChecksumEntry struct {
+	Handle int64  `json:"handle"`
+	Values []byte `json:"values"`
+}
+
+// ChecksumResponse is the response of kv.ReqTypeChecksum.
+type ChecksumResponse struct {
+	Digests []ChecksumDigest `json:"digests,omitempty"`
+	Entries []ChecksumEntry  `json:"entries,omitempty"`
+}
+
+// NewChecksumRequest creates a checksum request for the index idx of table t.
+func NewChecksumRequest(t *model.TableInfo, idx *model.IndexInfo, startTS uint64, scanIndex bool, bucketWidth int64) *ChecksumRequest {
+	req := &ChecksumRequest{
+		StartTs:     startTS,
+		ScanIndex:   scanIndex,
+		Columns:     make([]ChecksumColumn, 0, len(idx.Columns)),
+		BucketWidth: bucketWidth,
+	}
+	for _, ic := range idx.Columns {
+		col := t.Columns[ic.Offset]
+		req.Columns = append(req.Columns, ChecksumColumn{
+			ID:       col.ID,
+			Tp:       col.FieldType,
+			Length:   ic.Length,
+			PKHandle: t.PKIsHandle && mysql.HasPriKeyFlag(col.Flag),
+		})
+	}
+	return req
+}
+
+// Checksum sends a checksum request and merges the responses of all the regions.
+func Checksum(client kv.Client, ctx goctx.Context, req *ChecksumRequest, keyRanges []kv.KeyRange, concurrency int) (_ *ChecksumResponse, err error) {
+	kvReq := &kv.Request{
+		Tp:          kv.ReqTypeChecksum,
+		Concurrency: concurrency,
+		KeyRanges:   keyRanges,
+	}
+	kvReq.Data, err = json.Marshal(req)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	resp := client.Send(ctx, kvReq)
+	if resp == nil {
+		return nil, errors.New("client returns nil response")
+	}
+	defer func() {
+		if closeErr := resp.Close(); err == nil {
+			err = errors.Trace(closeErr)
+		}
+	}()
+
+	digests := make(map[int64]*ChecksumDigest)
+	result := &ChecksumResponse{}
+	for {
+		var data []byte
+		data, err = resp.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if data == nil {
+			break
+		}
+		partial := &ChecksumResponse{}
+		if err = json.Unmarshal(data, partial); err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, d := range partial.Digests {
+			if merged, ok := digests[d.Bucket]; ok {
+				merged.Count += d.Count
+				merged.Checksum += d.Checksum
+			} else {
+				digest := d
+				digests[d.Bucket] = &digest
+			}
+		}
+		result.Entries = append(result.Entries, partial.Entries...)
+	}
+	for _, d := range digests {
+		result.Digests = append(result.Digests, *d)
+	}
+	sort.Slice(result.Digests, func(i, j int) bool { return result.Digests[i].Bucket < result.Digests[j].Bucket })
+	sort.Slice(result.Entries, func(i, j int) bool {
+		if result.Entries[i].Handle != result.Entries[j].Handle {
+			return result.Entries[i].Handle < result.Entries[j].Handle
+		}
+		return string(result.Entries[i].Values) < string(result.Entries[j].Values)
+	})
+	return result, nil
+}
+
+// ChecksumBuilder builds the response of a checksum request from the key-value pairs scanned by the storage.
+type ChecksumBuilder struct {
+	req     *ChecksumRequest
+	colTps  map[int64]*types.FieldType
+	loc     *time.Location
+	buckets map[int64]struct{}
+	digests map[int64]*ChecksumDigest
+	entries []ChecksumEntry
+}
+
+// NewChecksumBuilder creates a ChecksumBuilder for req.
+func NewChecksumBuilder(req *ChecksumRequest) *ChecksumBuilder {
+	b := &ChecksumBuilder{
+		req:     req,
+		colTps:  make(map[int64]*types.FieldType, len(req.Columns)),
+		loc:     time.FixedZone("UTC", int(req.TimeZoneOffset)),
+		digests: make(map[int64]*ChecksumDigest),
+	}
+	for i := range req.Columns {
+		col := &req.Columns[i]
+		if !col.PKHandle {
+			b.colTps[col.ID] = &col.Tp
+		}
+	}
+	if len(req.Buckets) > 0 {
+		b.buckets = make(map[int64]struct{}, len(req.Buckets))
+		for _, bucket := range req.Buckets {
+			b.buckets[bucket] = struct{}{}
+		}
+	}
+	return b
+}
+
+// ScanIndex returns whether the key-value pairs should be the index entries.
+func (b *ChecksumBuilder) ScanIndex() bool {
+	return b.req.ScanIndex
+}
+
+// AddRow adds a row. The value should have been resolved if the row is split into chunks.
+func (b *ChecksumBuilder) AddRow(handle int64, value []byte) error {
+	bucket := b.bucket(handle)
+	if !b.wanted(bucket) {
+		return nil
+	}
+	row, err := tablecodec.DecodeRow(value, b.colTps, b.loc)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	datums := make([]types.Datum, len(b.req.Columns))
+	for i, col := range b.req.Columns {
+		if col.PKHandle {
+			if mysql.HasUnsignedFlag(col.Tp.Flag) {
+				datums[i].SetUint64(uint64(handle))
+			} else {
+				datums[i].SetInt64(handle)
+			}
+			continue
+		}
+		// A missing column is treated as NULL, as the index backfilling does.
+		datums[i] = row[col.ID]
+		// Truncate the value of the prefix index column.
+		if datums[i].Kind() == types.KindString || datums[i].Kind() == types.KindBytes {
+			if col.Length != types.UnspecifiedLength && len(datums[i].GetBytes()) > col.Length {
+				datums[i].SetBytes(datums[i].GetBytes()[:col.Length])
+			}
+		}
+	}
+	values, err := codec.EncodeKey(nil, datums...)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	b.add(bucket, handle, values)
+	return nil
+}
+
+// AddIndex adds an index entry.
+func (b *ChecksumBuilder) AddIndex(key, value []byte) error {
+	values, rest, err := tablecodec.CutIndexKeyNew(key, len(b.req.Columns))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var handle int64
+	if len(rest) > 0 {
+		var d types.Datum
+		_, d, err = codec.DecodeOne(rest)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		handle = d.GetInt64()
+	} else {
+		if len(value) < 8 {
+			return errors.Errorf("invalid handle of the index key %q", key)
+		}
+		handle = int64(binary.BigEndian.Uint64(value))
+	}
+	bucket := b.bucket(handle)
+	if !b.wanted(bucket) {
+		return nil
+	}
+	var encoded []byte
+	for _, v := range values {
+		encoded = append(encoded, v...)
+	}
+	b.add(bucket, handle, encoded)
+	return nil
+}
+
+// Response returns the checksum response of the pairs added so far.
+func (b *ChecksumBuilder) Response() *ChecksumResponse {
+	resp := &ChecksumResponse{Entries: b.entries}
+	for _, d := range b.digests {
+		resp.Digests = append(resp.Digests, *d)
+	}
+	return resp
+}
+
+func (b *ChecksumBuilder) bucket(handle int64) int64 {
+	width := b.req.BucketWidth
+	if width <= 0 {
+		return 0
+	}
+	bucket := handle / width
+	if handle%width < 0 {
+		bucket--
+	}
+	return bucket
+}
+
+func (b *ChecksumBuilder) wanted(bucket int64) bool {
+	if b.buckets == nil {
+		return true
+	}
+	_, ok := b.buckets[bucket]
+	return ok
+}
+
+func (b *ChecksumBuilder) add(bucket, handle int64, values []byte) {
+	if b.buckets != nil {
+		b.entries = append(b.entries, ChecksumEntry{Handle: handle, Values: values})
+		return
+	}
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(handle))
+	h.Write(buf[:])
+	h.Write(values)
+	d, ok := b.digests[bucket]
+	if !ok {
+		d = &ChecksumDigest{Bucket: bucket}
+		b.digests[bucket] = d
+	}
+	d.Count++
+	d.Checksum += h.Sum64()
+}
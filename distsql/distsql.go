@@ -14,6 +14,7 @@
 package distsql
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/juju/errors"
@@ -21,6 +22,8 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/pingcap/tidb/util/resourcegroup"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 	goctx "golang.org/x/net/context"
@@ -44,6 +47,8 @@ type SelectResult interface {
 	// Fetch fetches partial results from client.
 	// The caller should call SetFields() before call Fetch().
 	Fetch(ctx goctx.Context)
+	// ExecDetails returns the runtime statistics of the coprocessor requests fetched so far.
+	ExecDetails() execdetails.ExecDetails
 }
 
 // PartialResult is the result from a single region server.
@@ -63,6 +68,11 @@ type selectResult struct {
 
 	results chan resultWithErr
 	closed  chan struct{}
+
+	// rowCount is the number of rows fetched, it's accessed atomically.
+	rowCount int64
+	// quota limits the bytes of the fetched responses which are not consumed yet and the rows fetched.
+	quota *resourcegroup.StmtQuota
 }
 
 type resultWithErr struct {
@@ -90,8 +100,19 @@ func (r *selectResult) fetch(ctx goctx.Context) {
 		if resultSubset == nil {
 			return
 		}
-		pr := &partialResult{}
+		if err = r.quota.Consume(int64(len(resultSubset))); err != nil {
+			r.results <- resultWithErr{err: errors.Trace(err)}
+			return
+		}
+		pr := &partialResult{quota: r.quota, size: int64(len(resultSubset))}
 		pr.unmarshal(resultSubset)
+		rowCount := pr.rowCount()
+		atomic.AddInt64(&r.rowCount, rowCount)
+		if err = r.quota.AddExaminedRows(rowCount); err != nil {
+			pr.Close()
+			r.results <- resultWithErr{err: errors.Trace(err)}
+			return
+		}
 
 		select {
 		case r.results <- resultWithErr{result: pr}:
@@ -110,6 +131,16 @@ func (r *selectResult) Next() (PartialResult, error) {
 	return re.result, errors.Trace(re.err)
 }
 
+// ExecDetails implements the SelectResult interface.
+func (r *selectResult) ExecDetails() execdetails.ExecDetails {
+	var details execdetails.ExecDetails
+	if resp, ok := r.resp.(kv.ExecDetailsResponse); ok {
+		details = resp.ExecDetails()
+	}
+	details.TotalKeys = atomic.LoadInt64(&r.rowCount)
+	return details
+}
+
 // Close closes SelectResult.
 func (r *selectResult) Close() error {
 	// close this channel tell fetch goroutine to exit
@@ -123,6 +154,10 @@ type partialResult struct {
 	chunkIdx   int
 	cursor     int
 	dataOffset int64
+
+	// quota is released by size when the partial result is closed.
+	quota *resourcegroup.StmtQuota
+	size  int64
 }
 
 func (pr *partialResult) unmarshal(resultSubset []byte) error {
@@ -141,6 +176,17 @@ func (pr *partialResult) unmarshal(resultSubset []byte) error {
 
 var zeroLenData = make([]byte, 0)
 
+func (pr *partialResult) rowCount() int64 {
+	if pr.resp == nil {
+		return 0
+	}
+	var count int64
+	for _, chunk := range pr.resp.Chunks {
+		count += int64(len(chunk.RowsMeta))
+	}
+	return count
+}
+
 // Next returns the next row of the sub result.
 // If no more row to return, data would be nil.
 func (pr *partialResult) Next() (handle int64, data []byte, err error) {
@@ -177,6 +223,8 @@ func (pr *partialResult) getChunk() *tipb.Chunk {
 
 // Close closes the sub result.
 func (pr *partialResult) Close() error {
+	pr.quota.Release(pr.size)
+	pr.size = 0
 	return nil
 }
 
@@ -211,6 +259,7 @@ func Select(client kv.Client, ctx goctx.Context, req *tipb.SelectRequest, keyRan
 		resp:    resp,
 		results: make(chan resultWithErr, 5),
 		closed:  make(chan struct{}),
+		quota:   resourcegroup.StmtQuotaFromContext(ctx),
 	}
 	// If Aggregates is not nil, we should set result fields latter.
 	if len(req.Aggregates) == 0 && len(req.GroupBy) == 0 {
@@ -262,6 +311,7 @@ func SelectDAG(client kv.Client, ctx goctx.Context, dag *tipb.DAGRequest, keyRan
 		resp:    resp,
 		results: make(chan resultWithErr, concurrency),
 		closed:  make(chan struct{}),
+		quota:   resourcegroup.StmtQuotaFromContext(ctx),
 	}
 	return result, nil
 }
@@ -278,7 +328,9 @@ func composeRequest(req *tipb.SelectRequest, keyRanges []kv.KeyRange, concurrenc
 	} else {
 		kvReq.Tp = kv.ReqTypeSelect
 	}
-	if req.OrderBy != nil {
+	// An order-by item without an expression means the ranges are scanned in the order, the items with
+	// expressions are the TopN pushed down, which doesn't decide the scan direction.
+	if len(req.OrderBy) > 0 && req.OrderBy[0].Expr == nil {
 		kvReq.Desc = req.OrderBy[0].Desc
 	}
 	var err error
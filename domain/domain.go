@@ -22,6 +22,7 @@ import (
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl"
+	"github.com/pingcap/tidb/event"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
@@ -31,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/userlock"
 	// TODO: It's used fo update vendor. It will be removed.
 	_ "github.com/coreos/etcd/clientv3/concurrency"
 	_ "github.com/coreos/etcd/mvcc/mvccpb"
@@ -50,28 +52,36 @@ type Domain struct {
 	sysSessionPool  *sync.Pool
 	exit            chan struct{}
 	etcdClient      *clientv3.Client
+	slowQuery       *slowQueryBuffer
+	schemaCache     *schemaCache
+	userLocks       *userlock.Manager
 
 	MockReloadFailed MockFailure // It mocks reload failed.
 }
 
+const initialVersion = 0
+
 // loadInfoSchema loads infoschema at startTS into handle, usedSchemaVersion is the currently used
 // infoschema version, if it is the same as the schema version at startTS, we don't need to reload again.
-// It returns the latest schema version and an error.
-func (do *Domain) loadInfoSchema(handle *infoschema.Handle, usedSchemaVersion int64, startTS uint64) (int64, error) {
+// It returns the latest schema version, the IDs of the tables changed since usedSchemaVersion, and an error.
+// The changed table IDs are nil if they are unknown because of a full load.
+func (do *Domain) loadInfoSchema(handle *infoschema.Handle, usedSchemaVersion int64, startTS uint64) (int64, []int64, error) {
 	snapshot, err := do.store.GetSnapshot(kv.NewVersion(startTS))
 	if err != nil {
-		return 0, errors.Trace(err)
+		return 0, nil, errors.Trace(err)
 	}
 	m := meta.NewSnapshotMeta(snapshot)
 	latestSchemaVersion, err := m.GetSchemaVersion()
 	if err != nil {
-		return 0, errors.Trace(err)
+		return 0, nil, errors.Trace(err)
 	}
-	if usedSchemaVersion != 0 && usedSchemaVersion == latestSchemaVersion {
-		return latestSchemaVersion, nil
+	if usedSchemaVersion != initialVersion && usedSchemaVersion == latestSchemaVersion {
+		// The tables of the InfoSchema are loaded lazily, load them from the latest snapshot.
+		handle.UpdateSnapshotTS(latestSchemaVersion, startTS)
+		return latestSchemaVersion, []int64{}, nil
 	}
 	startTime := time.Now()
-	ok, err := do.tryLoadSchemaDiffs(m, usedSchemaVersion, latestSchemaVersion)
+	ok, changedTableIDs, err := do.tryLoadSchemaDiffs(handle, m, latestSchemaVersion, startTS)
 	if err != nil {
 		// We can fall back to full load, don't need to return the error.
 		log.Errorf("[ddl] failed to load schema diff err %v", err)
@@ -79,22 +89,24 @@ func (do *Domain) loadInfoSchema(handle *infoschema.Handle, usedSchemaVersion in
 	if ok {
 		log.Infof("[ddl] diff load InfoSchema from version %d to %d, in %v",
 			usedSchemaVersion, latestSchemaVersion, time.Since(startTime))
-		return latestSchemaVersion, nil
+		do.schemaCache.insert(handle.Get())
+		return latestSchemaVersion, changedTableIDs, nil
 	}
 
 	schemas, err := do.fetchAllSchemasWithTables(m)
 	if err != nil {
-		return 0, errors.Trace(err)
+		return 0, nil, errors.Trace(err)
 	}
 
-	newISBuilder, err := infoschema.NewBuilder(handle).InitWithDBInfos(schemas, latestSchemaVersion)
+	newISBuilder, err := infoschema.NewBuilder(handle).SetSnapshotTS(startTS).InitWithDBInfos(schemas, latestSchemaVersion)
 	if err != nil {
-		return 0, errors.Trace(err)
+		return 0, nil, errors.Trace(err)
 	}
 	log.Infof("[ddl] full load InfoSchema from version %d to %d, in %v",
 		usedSchemaVersion, latestSchemaVersion, time.Since(startTime))
 	newISBuilder.Build()
-	return latestSchemaVersion, nil
+	do.schemaCache.insert(handle.Get())
+	return latestSchemaVersion, nil, nil
 }
 
 func (do *Domain) fetchAllSchemasWithTables(m *meta.Meta) ([]*model.DBInfo, error) {
@@ -155,46 +167,63 @@ func (do *Domain) fetchSchemasWithTables(schemas []*model.DBInfo, m *meta.Meta,
 	done <- nil
 }
 
-const (
-	initialVersion         = 0
-	maxNumberOfDiffsToLoad = 100
-)
+// maxNumberOfDiffsToLoad is the least max number of diffs to load, it grows with the number of tables,
+// since loading the diffs is cheaper than loading all the tables unless there are as many diffs as tables.
+const maxNumberOfDiffsToLoad = 100
 
-// tryLoadSchemaDiffs tries to only load latest schema changes.
-// Returns true if the schema is loaded successfully.
+// tryLoadSchemaDiffs tries to only load latest schema changes on the nearest cached InfoSchema.
+// Returns true and the IDs of the changed tables if the schema is loaded successfully.
 // Returns false if the schema can not be loaded by schema diff, then we need to do full load.
-func (do *Domain) tryLoadSchemaDiffs(m *meta.Meta, usedVersion, newVersion int64) (bool, error) {
-	if usedVersion == initialVersion || newVersion-usedVersion > maxNumberOfDiffsToLoad {
-		// If there isn't any used version, or used version is too old, we do full load.
-		return false, nil
-	}
-	if usedVersion > newVersion {
-		// When user use History Read feature, history schema will be loaded.
-		// usedVersion may be larger than newVersion, full load is needed.
-		return false, nil
+func (do *Domain) tryLoadSchemaDiffs(handle *infoschema.Handle, m *meta.Meta, newVersion int64, startTS uint64) (bool, []int64, error) {
+	oldSchema := do.schemaCache.nearest(newVersion)
+	if oldSchema == nil {
+		// If there isn't any cached InfoSchema old enough, like at startup or the history read of an old
+		// schema, we do full load.
+		return false, nil, nil
+	}
+	usedVersion := oldSchema.SchemaMetaVersion()
+	if newVersion-usedVersion > maxDiffsToLoad(oldSchema) {
+		// If the cached InfoSchema is too old, we do full load.
+		return false, nil, nil
 	}
 	var diffs []*model.SchemaDiff
 	for usedVersion < newVersion {
 		usedVersion++
 		diff, err := m.GetSchemaDiff(usedVersion)
 		if err != nil {
-			return false, errors.Trace(err)
+			return false, nil, errors.Trace(err)
 		}
 		if diff == nil {
 			// If diff is missing for any version between used and new version, we fall back to full reload.
-			return false, nil
+			return false, nil, nil
 		}
 		diffs = append(diffs, diff)
 	}
-	builder := infoschema.NewBuilder(do.infoHandle).InitWithOldInfoSchema()
+	// The builder doesn't modify the old InfoSchema, so it's still safe to use if any diff fails to apply.
+	builder := infoschema.NewBuilder(handle).SetSnapshotTS(startTS).InitWithOldInfoSchema(oldSchema)
+	changedTableIDs := make([]int64, 0, len(diffs))
 	for _, diff := range diffs {
 		err := builder.ApplyDiff(m, diff)
 		if err != nil {
-			return false, errors.Trace(err)
+			return false, nil, errors.Trace(err)
+		}
+		if diff.TableID != 0 {
+			changedTableIDs = append(changedTableIDs, diff.TableID)
+		}
+		if diff.OldTableID != 0 {
+			changedTableIDs = append(changedTableIDs, diff.OldTableID)
 		}
 	}
 	builder.Build()
-	return true, nil
+	return true, changedTableIDs, nil
+}
+
+func maxDiffsToLoad(is infoschema.InfoSchema) int64 {
+	tableCnt := int64(is.TableCount())
+	if tableCnt < maxNumberOfDiffsToLoad {
+		return maxNumberOfDiffsToLoad
+	}
+	return tableCnt
 }
 
 // InfoSchema gets information schema from domain.
@@ -205,7 +234,7 @@ func (do *Domain) InfoSchema() infoschema.InfoSchema {
 // GetSnapshotInfoSchema gets a snapshot information schema.
 func (do *Domain) GetSnapshotInfoSchema(snapshotTS uint64) (infoschema.InfoSchema, error) {
 	snapHandle := do.infoHandle.EmptyClone()
-	_, err := do.loadInfoSchema(snapHandle, do.infoHandle.Get().SchemaMetaVersion(), snapshotTS)
+	_, _, err := do.loadInfoSchema(snapHandle, initialVersion, snapshotTS)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -217,6 +246,11 @@ func (do *Domain) PerfSchema() perfschema.PerfSchema {
 	return do.infoHandle.GetPerfHandle()
 }
 
+// UserLocks gets the manager of the user-level locks acquired by GET_LOCK() on this server.
+func (do *Domain) UserLocks() *userlock.Manager {
+	return do.userLocks
+}
+
 // DDL gets DDL from domain.
 func (do *Domain) DDL() ddl.DDL {
 	return do.ddl
@@ -253,6 +287,7 @@ func (do *Domain) Reload() error {
 
 	var err error
 	var latestSchemaVersion int64
+	var changedTableIDs []int64
 
 	ver, err := do.store.CurrentVersion()
 	if err != nil {
@@ -265,7 +300,7 @@ func (do *Domain) Reload() error {
 		schemaVersion = oldInfoSchema.SchemaMetaVersion()
 	}
 
-	latestSchemaVersion, err = do.loadInfoSchema(do.infoHandle, schemaVersion, ver.Ver)
+	latestSchemaVersion, changedTableIDs, err = do.loadInfoSchema(do.infoHandle, schemaVersion, ver.Ver)
 	loadSchemaDuration.Observe(time.Since(startTime).Seconds())
 	if err != nil {
 		loadSchemaCounter.WithLabelValues("failed").Inc()
@@ -273,7 +308,7 @@ func (do *Domain) Reload() error {
 	}
 	loadSchemaCounter.WithLabelValues("succ").Inc()
 
-	do.SchemaValidator.Update(ver.Ver, latestSchemaVersion)
+	do.SchemaValidator.Update(ver.Ver, schemaVersion, latestSchemaVersion, changedTableIDs)
 
 	lease := do.DDL().GetLease()
 	sub := time.Since(startTime)
@@ -362,6 +397,9 @@ func NewDomain(store kv.Storage, lease time.Duration) (d *Domain, err error) {
 		SchemaValidator: newSchemaValidator(lease),
 		exit:            make(chan struct{}),
 		sysSessionPool:  &sync.Pool{},
+		slowQuery:       newSlowQueryBuffer(slowQueryCapacity),
+		schemaCache:     newSchemaCache(schemaCacheCapacity),
+		userLocks:       userlock.NewManager(),
 	}
 
 	if ebd, ok := store.(etcdBackend); ok {
@@ -382,6 +420,7 @@ func NewDomain(store kv.Storage, lease time.Duration) (d *Domain, err error) {
 		return nil, errors.Trace(err)
 	}
 	d.ddl = ddl.NewDDL(d.store, d.infoHandle, &ddlCallback{do: d}, lease)
+	// The first reload does full load and preloads the schema cache, the later ones only load the schema diffs.
 	if err = d.Reload(); err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -492,6 +531,86 @@ func (do *Domain) UpdateTableStatsLoop(ctx context.Context) error {
 	return nil
 }
 
+// EventSchedulerLoop creates a goroutine which executes the events on schedule with ctx.
+// The events are checked every second, it should be called after LoadPrivilegeLoop.
+func (do *Domain) EventSchedulerLoop(ctx context.Context) {
+	if do.DDL().GetLease() <= 0 {
+		return
+	}
+	scheduler := event.NewScheduler(ctx, do.privHandle)
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				err := scheduler.Tick(now)
+				if err != nil {
+					log.Error("[event scheduler] tick fail:", errors.ErrorStack(err))
+				}
+			case <-do.exit:
+				return
+			}
+		}
+	}()
+}
+
+// LoadReadOnlyLoop loads the global read_only and super_read_only variables with ctx, and creates a goroutine
+// which reloads them when any server notifies the update, or periodically if etcd is not available.
+func (do *Domain) LoadReadOnlyLoop(ctx context.Context) error {
+	err := loadReadOnly(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var watchCh clientv3.WatchChan
+	duration := 10 * time.Second
+	if do.etcdClient != nil {
+		watchCh = do.etcdClient.Watch(goctx.Background(), readOnlyKey)
+		duration = time.Minute
+	}
+
+	go func() {
+		for {
+			select {
+			case <-do.exit:
+				return
+			case <-watchCh:
+			case <-time.After(duration):
+			}
+			err := loadReadOnly(ctx)
+			if err != nil {
+				log.Error("load read only variables fail:", errors.ErrorStack(err))
+			}
+		}
+	}()
+	return nil
+}
+
+func loadReadOnly(ctx context.Context) error {
+	for _, name := range []string{variable.ReadOnly, variable.SuperReadOnly} {
+		value, err := ctx.GetSessionVars().GlobalVarsAccessor.GetGlobalSysVar(name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		variable.SetReadOnly(name, value)
+	}
+	return nil
+}
+
+const readOnlyKey = "/tidb/read_only"
+
+// NotifyUpdateReadOnly updates read only key in etcd, TiDB client that watches
+// the key will reload the read_only and super_read_only variables.
+func (do *Domain) NotifyUpdateReadOnly() {
+	if do.etcdClient != nil {
+		_, err := do.etcdClient.KV.Put(goctx.Background(), readOnlyKey, "")
+		if err != nil {
+			log.Warn("notify update read only failed:", err)
+		}
+	}
+}
+
 const privilegeKey = "/tidb/privilege"
 
 // NotifyUpdatePrivilege updates privilege key in etcd, TiDB client that watches
@@ -67,28 +67,28 @@ func (*testSuite) TestT(c *C) {
 	c.Assert(err, IsNil)
 	ts := ver.Ver
 
-	succ := dom.SchemaValidator.Check(ts, schemaVer)
+	succ := dom.SchemaValidator.Check(ts, schemaVer, nil)
 	c.Assert(succ, IsTrue)
 	dom.MockReloadFailed.SetValue(true)
 	err = dom.Reload()
 	c.Assert(err, NotNil)
-	succ = dom.SchemaValidator.Check(ts, schemaVer)
+	succ = dom.SchemaValidator.Check(ts, schemaVer, nil)
 	c.Assert(succ, IsTrue)
 	time.Sleep(lease)
 
 	ver, err = store.CurrentVersion()
 	c.Assert(err, IsNil)
 	ts = ver.Ver
-	succ = dom.SchemaValidator.Check(ts, schemaVer)
+	succ = dom.SchemaValidator.Check(ts, schemaVer, nil)
 	c.Assert(succ, IsFalse)
 	dom.MockReloadFailed.SetValue(false)
 	err = dom.Reload()
 	c.Assert(err, IsNil)
-	succ = dom.SchemaValidator.Check(ts, schemaVer)
+	succ = dom.SchemaValidator.Check(ts, schemaVer, nil)
 	c.Assert(succ, IsTrue)
 	ver, err = store.CurrentVersion()
 	c.Assert(err, IsNil)
-	succ = dom.SchemaValidator.Check(ver.Ver, schemaVer)
+	succ = dom.SchemaValidator.Check(ver.Ver, schemaVer, nil)
 	c.Assert(succ, IsTrue)
 
 	err = store.Close()
@@ -34,9 +34,18 @@ var (
 			Help:      "Bucketed histogram of processing time (s) in load schema.",
 			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
 		})
+
+	schemaLeaseErrorCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "schema_lease_error_counter",
+			Help:      "Counter of schema lease error",
+		}, []string{"type"})
 )
 
 func init() {
 	prometheus.MustRegister(loadSchemaDuration)
 	prometheus.MustRegister(loadSchemaCounter)
+	prometheus.MustRegister(schemaLeaseErrorCounter)
 }
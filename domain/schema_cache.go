@@ -0,0 +1,74 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pingcap/tidb/infoschema"
+)
+
+// schemaCacheCapacity is the number of the most recent InfoSchema versions kept in memory.
+const schemaCacheCapacity = 16
+
+// schemaCache keeps the recent InfoSchemas, they are the bases to apply the schema diffs on, so
+// neither the reload nor the history read needs to load all the table metadata again.
+// The InfoSchemas share the unchanged schemas and tables, so it doesn't cost much memory.
+type schemaCache struct {
+	mu sync.RWMutex
+	// schemas are sorted by the schema version, the latest first.
+	schemas []infoschema.InfoSchema
+}
+
+func newSchemaCache(capacity int) *schemaCache {
+	return &schemaCache{schemas: make([]infoschema.InfoSchema, 0, capacity)}
+}
+
+// insert keeps the InfoSchema, the oldest one is evicted if the cache is full.
+func (c *schemaCache) insert(is infoschema.InfoSchema) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	version := is.SchemaMetaVersion()
+	idx := sort.Search(len(c.schemas), func(i int) bool {
+		return c.schemas[i].SchemaMetaVersion() <= version
+	})
+	if idx < len(c.schemas) && c.schemas[idx].SchemaMetaVersion() == version {
+		c.schemas[idx] = is
+		return
+	}
+	if len(c.schemas) == cap(c.schemas) {
+		if idx == len(c.schemas) {
+			// It's older than all the cached ones.
+			return
+		}
+		c.schemas = c.schemas[:len(c.schemas)-1]
+	}
+	c.schemas = append(c.schemas, nil)
+	copy(c.schemas[idx+1:], c.schemas[idx:])
+	c.schemas[idx] = is
+}
+
+// nearest returns the latest InfoSchema whose version isn't larger than version, or nil if there is none.
+func (c *schemaCache) nearest(version int64) infoschema.InfoSchema {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	idx := sort.Search(len(c.schemas), func(i int) bool {
+		return c.schemas[i].SchemaMetaVersion() <= version
+	})
+	if idx == len(c.schemas) {
+		return nil
+	}
+	return c.schemas[idx]
+}
@@ -0,0 +1,71 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+type mockVersionedSchema struct {
+	infoschema.InfoSchema
+	version int64
+}
+
+func (s *mockVersionedSchema) SchemaMetaVersion() int64 {
+	return s.version
+}
+
+func (*testSuite) TestSchemaCache(c *C) {
+	defer testleak.AfterTest(c)()
+	cache := newSchemaCache(3)
+	c.Assert(cache.nearest(10), IsNil)
+
+	for _, v := range []int64{5, 2, 8, 3} {
+		cache.insert(&mockVersionedSchema{version: v})
+	}
+	// Only the latest 3 versions are kept.
+	c.Assert(cache.nearest(1), IsNil)
+	c.Assert(cache.nearest(2), IsNil)
+	c.Assert(cache.nearest(4).SchemaMetaVersion(), Equals, int64(3))
+	c.Assert(cache.nearest(5).SchemaMetaVersion(), Equals, int64(5))
+	c.Assert(cache.nearest(7).SchemaMetaVersion(), Equals, int64(5))
+	c.Assert(cache.nearest(100).SchemaMetaVersion(), Equals, int64(8))
+
+	// An older version than all the cached ones isn't kept when the cache is full.
+	cache.insert(&mockVersionedSchema{version: 1})
+	c.Assert(cache.nearest(1), IsNil)
+	// The same version is replaced.
+	is := &mockVersionedSchema{version: 5}
+	cache.insert(is)
+	c.Assert(cache.nearest(6), Equals, is)
+	cache.insert(&mockVersionedSchema{version: 9})
+	c.Assert(cache.nearest(4), IsNil)
+}
+
+func (*testSuite) TestMaxDiffsToLoad(c *C) {
+	defer testleak.AfterTest(c)()
+	tables := make([]*model.TableInfo, maxNumberOfDiffsToLoad*2)
+	for i := range tables {
+		tables[i] = &model.TableInfo{ID: int64(i + 1), Name: model.NewCIStr(fmt.Sprintf("t%d", i)), State: model.StatePublic}
+	}
+	is := infoschema.MockInfoSchema(tables[:1])
+	c.Assert(maxDiffsToLoad(is), Equals, int64(maxNumberOfDiffsToLoad))
+	is = infoschema.MockInfoSchema(tables)
+	c.Assert(maxDiffsToLoad(is), Equals, int64(len(tables)))
+}
@@ -0,0 +1,73 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// SchemaChecker is used for checking the validity of the schema version of a transaction when it's
+// committed, it's set as the kv.SchemaLeaseChecker option of the transaction.
+type SchemaChecker struct {
+	SchemaValidator
+	schemaVer       int64
+	relatedTableIDs []int64
+}
+
+const (
+	schemaOutOfDateRetryInterval = 500 * time.Millisecond
+	schemaOutOfDateRetryTimes    = 10
+)
+
+// NewSchemaChecker creates a SchemaChecker for the transaction using schemaVer, which writes the tables
+// of relatedTableIDs.
+func NewSchemaChecker(do *Domain, schemaVer int64, relatedTableIDs []int64) *SchemaChecker {
+	return &SchemaChecker{
+		SchemaValidator: do.SchemaValidator,
+		schemaVer:       schemaVer,
+		relatedTableIDs: relatedTableIDs,
+	}
+}
+
+// Check checks the validity of the schema version at txnTS. It waits for the schema to be reloaded if
+// the schema version is out of date, and returns ErrInfoSchemaChanged if any related table is changed.
+func (s *SchemaChecker) Check(txnTS uint64) error {
+	for i := 0; i < schemaOutOfDateRetryTimes; i++ {
+		err := s.checkOnce(txnTS)
+		switch err {
+		case nil:
+			return nil
+		case ErrInfoSchemaChanged:
+			schemaLeaseErrorCounter.WithLabelValues("changed").Inc()
+			return errors.Trace(err)
+		default:
+			schemaLeaseErrorCounter.WithLabelValues("outdated").Inc()
+			time.Sleep(schemaOutOfDateRetryInterval)
+		}
+	}
+	return ErrInfoSchemaExpired
+}
+
+func (s *SchemaChecker) checkOnce(txnTS uint64) error {
+	succ := s.SchemaValidator.Check(txnTS, s.schemaVer, s.relatedTableIDs)
+	if !succ {
+		if s.SchemaValidator.Latest() > s.schemaVer {
+			return ErrInfoSchemaChanged
+		}
+		return ErrInfoSchemaExpired
+	}
+	return nil
+}
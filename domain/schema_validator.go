@@ -21,19 +21,31 @@ import (
 // SchemaValidator is the interface for checking the validity of schema version.
 type SchemaValidator interface {
 	// Update the schema validator, add a new item, delete the expired items.
-	// The schemaVer is valid within leaseGrantTime plus lease duration.
-	Update(leaseGrantTime uint64, schemaVer int64)
-	// Check is it valid for a transaction to use schemaVer, at timestamp txnTS.
-	Check(txnTS uint64, schemaVer int64) bool
+	// The currVer is valid within leaseGrantTime plus lease duration. The changedTableIDs are the tables
+	// changed from oldVer to currVer, they are nil if unknown.
+	Update(leaseGrantTime uint64, oldVer, currVer int64, changedTableIDs []int64)
+	// Check is it valid for a transaction to use schemaVer and write the related tables, at timestamp txnTS.
+	Check(txnTS uint64, schemaVer int64, relatedTableIDs []int64) bool
 	// Latest returns the latest schema version it knows, but not necessary a valid one.
 	Latest() int64
 }
 
+// deltaSchemaInfo is the tables changed from oldVer to currVer.
+type deltaSchemaInfo struct {
+	oldVer          int64
+	currVer         int64
+	changedTableIDs []int64
+}
+
+// maxDeltaSchemaCount is the max number of the deltaSchemaInfos kept by the schema validator.
+const maxDeltaSchemaCount = 1024
+
 type schemaValidator struct {
 	mux             sync.RWMutex
 	lease           time.Duration
 	items           map[int64]time.Time
 	latestSchemaVer int64
+	deltaSchemas    []deltaSchemaInfo
 }
 
 func newSchemaValidator(lease time.Duration) SchemaValidator {
@@ -43,15 +55,30 @@ func newSchemaValidator(lease time.Duration) SchemaValidator {
 	}
 }
 
-func (s *schemaValidator) Update(leaseGrantTS uint64, schemaVer int64) {
+func (s *schemaValidator) Update(leaseGrantTS uint64, oldVer, currVer int64, changedTableIDs []int64) {
 	s.mux.Lock()
 
-	s.latestSchemaVer = schemaVer
+	if currVer > oldVer {
+		if changedTableIDs == nil {
+			// The changed tables are unknown, the older schema versions can't be checked by the tables.
+			s.deltaSchemas = s.deltaSchemas[:0]
+		} else {
+			if len(s.deltaSchemas) >= maxDeltaSchemaCount {
+				s.deltaSchemas = append(s.deltaSchemas[:0], s.deltaSchemas[1:]...)
+			}
+			s.deltaSchemas = append(s.deltaSchemas, deltaSchemaInfo{
+				oldVer:          oldVer,
+				currVer:         currVer,
+				changedTableIDs: changedTableIDs,
+			})
+		}
+	}
+	s.latestSchemaVer = currVer
 	leaseGrantTime := extractPhysicalTime(leaseGrantTS)
 	leaseExpire := leaseGrantTime.Add(s.lease - time.Millisecond)
 
 	// Renewal lease.
-	s.items[schemaVer] = leaseExpire
+	s.items[currVer] = leaseExpire
 
 	// Delete expired items, leaseGrantTime is server current time, actually.
 	for k, expire := range s.items {
@@ -64,7 +91,9 @@ func (s *schemaValidator) Update(leaseGrantTS uint64, schemaVer int64) {
 }
 
 // Check checks schema validity, returns true if use schemaVer at txnTS is legal.
-func (s *schemaValidator) Check(txnTS uint64, schemaVer int64) bool {
+// If schemaVer is expired, it's still legal when the latest schema version is valid and the related
+// tables are not changed since schemaVer.
+func (s *schemaValidator) Check(txnTS uint64, schemaVer int64, relatedTableIDs []int64) bool {
 	s.mux.RLock()
 	defer s.mux.RUnlock()
 
@@ -72,18 +101,38 @@ func (s *schemaValidator) Check(txnTS uint64, schemaVer int64) bool {
 		return true
 	}
 
-	expire, ok := s.items[schemaVer]
-	if !ok {
-		// Can't find schema version means it's already expired.
-		return false
+	t := extractPhysicalTime(txnTS)
+	if expire, ok := s.items[schemaVer]; ok && !t.After(expire) {
+		return true
 	}
 
-	t := extractPhysicalTime(txnTS)
-	if t.After(expire) {
+	// Can't find schema version means it's already expired.
+	expire, ok := s.items[s.latestSchemaVer]
+	if !ok || t.After(expire) {
 		return false
 	}
+	return !s.isRelatedTablesChanged(schemaVer, relatedTableIDs)
+}
 
-	return true
+// isRelatedTablesChanged checks whether any related table is changed from schemaVer to the latest
+// schema version. It returns true if the changed tables of any version between them are unknown.
+func (s *schemaValidator) isRelatedTablesChanged(schemaVer int64, relatedTableIDs []int64) bool {
+	ver := s.latestSchemaVer
+	for i := len(s.deltaSchemas) - 1; i >= 0 && ver > schemaVer; i-- {
+		delta := s.deltaSchemas[i]
+		if delta.currVer != ver {
+			return true
+		}
+		for _, changedID := range delta.changedTableIDs {
+			for _, relatedID := range relatedTableIDs {
+				if changedID == relatedID {
+					return true
+				}
+			}
+		}
+		ver = delta.oldVer
+	}
+	return ver > schemaVer
 }
 
 // Latest returns the latest schema version it knows.
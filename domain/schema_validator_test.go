@@ -45,18 +45,18 @@ func (*testSuite) TestSchemaValidator(c *C) {
 
 	// Take a lease, check it's valid.
 	item := <-leaseGrantCh
-	validator.Update(item.leaseGrantTS, item.schemaVer)
-	valid := validator.Check(item.leaseGrantTS, item.schemaVer)
+	validator.Update(item.leaseGrantTS, validator.Latest(), item.schemaVer, nil)
+	valid := validator.Check(item.leaseGrantTS, item.schemaVer, nil)
 	c.Assert(valid, IsTrue)
 
 	// Sleep for a long time, check schema is invalid.
 	time.Sleep(lease)
 	ts := <-oracleCh
-	valid = validator.Check(ts, item.schemaVer)
+	valid = validator.Check(ts, item.schemaVer, nil)
 	c.Assert(valid, IsFalse)
 
 	reload(validator, leaseGrantCh)
-	valid = validator.Check(ts, item.schemaVer)
+	valid = validator.Check(ts, item.schemaVer, nil)
 	c.Assert(valid, IsFalse)
 
 	// Check the latest schema version must changed.
@@ -65,9 +65,43 @@ func (*testSuite) TestSchemaValidator(c *C) {
 	exit <- struct{}{}
 }
 
+func (*testSuite) TestSchemaValidatorRelatedTables(c *C) {
+	defer testleak.AfterTest(c)()
+	lease := 50 * time.Millisecond
+	validator := newSchemaValidator(lease)
+	// Table 1 is changed by version 11, table 2 by version 12, version 13 changes nothing.
+	validator.Update(oracleTS(time.Now()), 0, 10, nil)
+	validator.Update(oracleTS(time.Now()), 10, 11, []int64{1})
+	validator.Update(oracleTS(time.Now()), 11, 12, []int64{2})
+	// The versions before 13 are expired.
+	time.Sleep(lease)
+	validator.Update(oracleTS(time.Now()), 12, 13, []int64{})
+	ts := oracleTS(time.Now())
+	c.Assert(validator.Check(ts, 10, nil), IsTrue)
+	c.Assert(validator.Check(ts, 10, []int64{3}), IsTrue)
+	c.Assert(validator.Check(ts, 10, []int64{1}), IsFalse)
+	c.Assert(validator.Check(ts, 11, []int64{1, 3}), IsTrue)
+	c.Assert(validator.Check(ts, 11, []int64{2}), IsFalse)
+	c.Assert(validator.Check(ts, 12, []int64{1, 2}), IsTrue)
+
+	// The changed tables are unknown after a full load.
+	validator.Update(oracleTS(time.Now()), 13, 14, nil)
+	ts = oracleTS(time.Now())
+	c.Assert(validator.Check(ts, 12, []int64{3}), IsFalse)
+	c.Assert(validator.Check(ts, 14, []int64{3}), IsTrue)
+
+	// The latest schema version is expired.
+	time.Sleep(lease)
+	c.Assert(validator.Check(oracleTS(time.Now()), 14, nil), IsFalse)
+}
+
+func oracleTS(t time.Time) uint64 {
+	return uint64(t.UnixNano()/int64(time.Millisecond)) << 18
+}
+
 func reload(validator SchemaValidator, leaseGrantCh chan leaseGrantItem) {
 	item := <-leaseGrantCh
-	validator.Update(item.leaseGrantTS, item.schemaVer)
+	validator.Update(item.leaseGrantTS, validator.Latest(), item.schemaVer, nil)
 }
 
 // serverFunc plays the role as a remote server, runs in a separate goroutine.
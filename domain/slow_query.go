@@ -0,0 +1,125 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb/ast"
+)
+
+// slowQueryCapacity is the number of the most recent slow queries kept in memory.
+const slowQueryCapacity = 1024
+
+// SlowQueryInfo is the information of a slow query.
+type SlowQueryInfo struct {
+	SQL      string
+	Start    time.Time
+	Duration time.Duration
+	Succ     bool
+	ConnID   uint64
+	TxnTS    uint64
+	User     string
+	DB       string
+	// Internal is true for the queries executed by TiDB itself, like loading the privileges.
+	Internal bool
+}
+
+// slowQueryBuffer is a ring buffer of the most recent slow queries.
+type slowQueryBuffer struct {
+	mu    sync.RWMutex
+	data  []*SlowQueryInfo
+	start int
+}
+
+func newSlowQueryBuffer(capacity int) *slowQueryBuffer {
+	return &slowQueryBuffer{data: make([]*SlowQueryInfo, 0, capacity)}
+}
+
+func (b *slowQueryBuffer) push(info *SlowQueryInfo) {
+	b.mu.Lock()
+	if len(b.data) < cap(b.data) {
+		b.data = append(b.data, info)
+	} else {
+		// Overwrite the oldest one.
+		b.data[b.start] = info
+		b.start = (b.start + 1) % len(b.data)
+	}
+	b.mu.Unlock()
+}
+
+// recent returns at most count slow queries, the latest first.
+func (b *slowQueryBuffer) recent(count int) []*SlowQueryInfo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if count > len(b.data) {
+		count = len(b.data)
+	}
+	ret := make([]*SlowQueryInfo, 0, count)
+	for i := 0; i < count; i++ {
+		idx := (b.start + len(b.data) - 1 - i) % len(b.data)
+		ret = append(ret, b.data[idx])
+	}
+	return ret
+}
+
+// top returns at most count slowest queries of the kind, the slowest first.
+func (b *slowQueryBuffer) top(count int, kind ast.ShowSlowKind) []*SlowQueryInfo {
+	b.mu.RLock()
+	ret := make([]*SlowQueryInfo, 0, len(b.data))
+	for _, info := range b.data {
+		switch kind {
+		case ast.ShowSlowKindDefault:
+			if info.Internal {
+				continue
+			}
+		case ast.ShowSlowKindInternal:
+			if !info.Internal {
+				continue
+			}
+		}
+		ret = append(ret, info)
+	}
+	b.mu.RUnlock()
+	sort.Stable(byDuration(ret))
+	if count < len(ret) {
+		ret = ret[:count]
+	}
+	return ret
+}
+
+type byDuration []*SlowQueryInfo
+
+func (s byDuration) Len() int           { return len(s) }
+func (s byDuration) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byDuration) Less(i, j int) bool { return s[i].Duration > s[j].Duration }
+
+// LogSlowQuery keeps the slow query in memory, only the most recent ones are kept.
+func (do *Domain) LogSlowQuery(info *SlowQueryInfo) {
+	do.slowQuery.push(info)
+}
+
+// ShowSlowQuery returns the slow queries kept in memory for the 'admin show slow' statement.
+func (do *Domain) ShowSlowQuery(showSlow *ast.ShowSlow) []*SlowQueryInfo {
+	count := slowQueryCapacity
+	if showSlow.Count < uint64(count) {
+		count = int(showSlow.Count)
+	}
+	if showSlow.Tp == ast.ShowSlowRecent {
+		return do.slowQuery.recent(count)
+	}
+	return do.slowQuery.top(count, showSlow.Kind)
+}
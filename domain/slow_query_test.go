@@ -0,0 +1,55 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (*testSuite) TestSlowQueryBuffer(c *C) {
+	defer testleak.AfterTest(c)()
+	b := newSlowQueryBuffer(3)
+	c.Assert(b.recent(2), HasLen, 0)
+	c.Assert(b.top(2, ast.ShowSlowKindAll), HasLen, 0)
+
+	durations := []time.Duration{5, 1, 4, 3, 2}
+	for i, d := range durations {
+		b.push(&SlowQueryInfo{ConnID: uint64(i), Duration: d * time.Second, Internal: i == 3})
+	}
+	// Only the last 3 queries are kept.
+	recent := b.recent(5)
+	c.Assert(recent, HasLen, 3)
+	c.Assert(recent[0].ConnID, Equals, uint64(4))
+	c.Assert(recent[1].ConnID, Equals, uint64(3))
+	c.Assert(recent[2].ConnID, Equals, uint64(2))
+	recent = b.recent(1)
+	c.Assert(recent, HasLen, 1)
+	c.Assert(recent[0].ConnID, Equals, uint64(4))
+
+	top := b.top(2, ast.ShowSlowKindAll)
+	c.Assert(top, HasLen, 2)
+	c.Assert(top[0].ConnID, Equals, uint64(2))
+	c.Assert(top[1].ConnID, Equals, uint64(3))
+	top = b.top(5, ast.ShowSlowKindDefault)
+	c.Assert(top, HasLen, 2)
+	c.Assert(top[0].ConnID, Equals, uint64(2))
+	c.Assert(top[1].ConnID, Equals, uint64(4))
+	top = b.top(5, ast.ShowSlowKindInternal)
+	c.Assert(top, HasLen, 1)
+	c.Assert(top[0].ConnID, Equals, uint64(3))
+}
@@ -0,0 +1,203 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// TimeFormat is the format of the time values in mysql.event table, they are all in UTC.
+const TimeFormat = "2006-01-02 15:04:05"
+
+// QuoteString escapes s so that it can be put in a single quoted string literal of a SQL.
+func QuoteString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return strings.Replace(s, `'`, `\'`, -1)
+}
+
+// maxFixedInterval is the maximum interval of a recurring event which is not measured in months.
+const maxFixedInterval = 100 * 365 * 24 * time.Hour
+
+// maxMonthInterval is the maximum interval in months of a recurring event.
+const maxMonthInterval = 100 * 12
+
+var fixedUnits = map[string]time.Duration{
+	"SECOND": time.Second,
+	"MINUTE": time.Minute,
+	"HOUR":   time.Hour,
+	"DAY":    24 * time.Hour,
+	"WEEK":   7 * 24 * time.Hour,
+}
+
+var monthUnits = map[string]int64{
+	"MONTH":   1,
+	"QUARTER": 3,
+	"YEAR":    12,
+}
+
+// IsSupportedUnit checks whether the unit can be used by the interval of a recurring event.
+func IsSupportedUnit(unit string) bool {
+	unit = strings.ToUpper(unit)
+	_, ok := fixedUnits[unit]
+	if !ok {
+		_, ok = monthUnits[unit]
+	}
+	return ok
+}
+
+// IsValidInterval checks whether the interval is positive and not too big.
+func IsValidInterval(n int64, unit string) bool {
+	if n <= 0 {
+		return false
+	}
+	unit = strings.ToUpper(unit)
+	if d, ok := fixedUnits[unit]; ok {
+		return n <= int64(maxFixedInterval/d)
+	}
+	return n*monthUnits[unit] <= maxMonthInterval
+}
+
+// AddInterval adds n intervals of the unit to t.
+// Adding months keeps the day of month unless the month is shorter, like MySQL DATE_ADD.
+func AddInterval(t time.Time, n int64, unit string) time.Time {
+	unit = strings.ToUpper(unit)
+	if d, ok := fixedUnits[unit]; ok {
+		return t.Add(time.Duration(n) * d)
+	}
+	return addMonths(t, int(n*monthUnits[unit]))
+}
+
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// Event is an event definition in mysql.event table.
+// The zero time value means the column is NULL.
+type Event struct {
+	DB      string
+	Name    string
+	Body    string
+	Definer string
+	// ExecuteAt is set for an event which executes once.
+	ExecuteAt time.Time
+	// IntervalValue and IntervalField are set for a recurring event.
+	IntervalValue int64
+	IntervalField string
+	Starts        time.Time
+	Ends          time.Time
+	LastExecuted  time.Time
+	// Preserve is true if the event is kept after it will never execute again.
+	Preserve bool
+}
+
+// IsRecurring returns true if the event has an EVERY schedule.
+func (e *Event) IsRecurring() bool {
+	return e.IntervalField != ""
+}
+
+// NextRun returns the next time to execute the event after its last execution,
+// the bool result is false if the event will never execute again.
+func (e *Event) NextRun() (time.Time, bool) {
+	if !e.IsRecurring() {
+		return e.ExecuteAt, e.LastExecuted.IsZero()
+	}
+	next := e.Starts
+	if !e.LastExecuted.IsZero() && !next.After(e.LastExecuted) {
+		next = e.nextAfter(e.LastExecuted)
+	}
+	if !e.Ends.IsZero() && next.After(e.Ends) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// nextAfter returns the first time of the schedule which is after t, t must not be before Starts.
+func (e *Event) nextAfter(t time.Time) time.Time {
+	unit := strings.ToUpper(e.IntervalField)
+	if d, ok := fixedUnits[unit]; ok {
+		step := time.Duration(e.IntervalValue) * d
+		return e.Starts.Add((t.Sub(e.Starts)/step + 1) * step)
+	}
+	step := e.IntervalValue * monthUnits[unit]
+	elapsed := int64(t.Year()-e.Starts.Year())*12 + int64(t.Month()-e.Starts.Month())
+	// Start from one step before the estimation, the day of month may make the estimation too large.
+	k := elapsed/step - 1
+	if k < 0 {
+		k = 0
+	}
+	next := addMonths(e.Starts, int(k*step))
+	for !next.After(t) {
+		k++
+		next = addMonths(e.Starts, int(k*step))
+	}
+	return next
+}
+
+const loadEnabledSQL = `SELECT db, name, body, definer, execute_at, interval_value, interval_field,
+	starts, ends, last_executed, on_completion FROM %s.%s WHERE status = 'ENABLED'`
+
+// LoadEnabled loads all the enabled events.
+func LoadEnabled(ctx context.Context) ([]*Event, error) {
+	sql := fmt.Sprintf(loadEnabledSQL, mysql.SystemDB, mysql.EventTable)
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	events := make([]*Event, 0, len(rows))
+	for _, row := range rows {
+		d := row.Data
+		e := &Event{
+			DB:            d[0].GetString(),
+			Name:          d[1].GetString(),
+			Body:          d[2].GetString(),
+			Definer:       d[3].GetString(),
+			ExecuteAt:     datumToTime(d[4]),
+			IntervalValue: d[5].GetInt64(),
+			Starts:        datumToTime(d[7]),
+			Ends:          datumToTime(d[8]),
+			LastExecuted:  datumToTime(d[9]),
+			Preserve:      d[10].GetMysqlEnum().String() == "PRESERVE",
+		}
+		if !d[6].IsNull() {
+			e.IntervalField = d[6].GetString()
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func datumToTime(d types.Datum) time.Time {
+	if d.IsNull() {
+		return time.Time{}
+	}
+	t, err := d.GetMysqlTime().Time.GoTime(time.UTC)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,123 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testEventSuite{})
+
+type testEventSuite struct{}
+
+func parseTime(c *C, s string) time.Time {
+	t, err := time.Parse(TimeFormat, s)
+	c.Assert(err, IsNil)
+	return t
+}
+
+func (s *testEventSuite) TestInterval(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(IsSupportedUnit("day"), IsTrue)
+	c.Assert(IsSupportedUnit("QUARTER"), IsTrue)
+	c.Assert(IsSupportedUnit("MICROSECOND"), IsFalse)
+	c.Assert(IsSupportedUnit("DAY_HOUR"), IsFalse)
+
+	c.Assert(IsValidInterval(1, "SECOND"), IsTrue)
+	c.Assert(IsValidInterval(0, "SECOND"), IsFalse)
+	c.Assert(IsValidInterval(-1, "HOUR"), IsFalse)
+	c.Assert(IsValidInterval(100, "YEAR"), IsTrue)
+	c.Assert(IsValidInterval(101, "YEAR"), IsFalse)
+	c.Assert(IsValidInterval(1<<62, "WEEK"), IsFalse)
+
+	tbl := []struct {
+		t      string
+		n      int64
+		unit   string
+		expect string
+	}{
+		{"2017-01-31 10:00:00", 90, "MINUTE", "2017-01-31 11:30:00"},
+		{"2017-01-31 10:00:00", 1, "week", "2017-02-07 10:00:00"},
+		{"2017-01-31 10:00:00", 1, "MONTH", "2017-02-28 10:00:00"},
+		{"2016-01-31 10:00:00", 1, "MONTH", "2016-02-29 10:00:00"},
+		{"2017-11-30 10:00:00", 1, "QUARTER", "2018-02-28 10:00:00"},
+		{"2016-02-29 10:00:00", 1, "YEAR", "2017-02-28 10:00:00"},
+	}
+	for _, t := range tbl {
+		c.Assert(AddInterval(parseTime(c, t.t), t.n, t.unit), DeepEquals, parseTime(c, t.expect), Commentf("%v", t))
+	}
+}
+
+func (s *testEventSuite) TestNextRun(c *C) {
+	defer testleak.AfterTest(c)()
+	e := &Event{ExecuteAt: parseTime(c, "2017-01-01 00:00:00")}
+	next, ok := e.NextRun()
+	c.Assert(ok, IsTrue)
+	c.Assert(next, DeepEquals, e.ExecuteAt)
+	e.LastExecuted = parseTime(c, "2017-01-01 00:00:01")
+	_, ok = e.NextRun()
+	c.Assert(ok, IsFalse)
+
+	tbl := []struct {
+		n            int64
+		unit         string
+		lastExecuted string
+		ends         string
+		expect       string
+	}{
+		{10, "MINUTE", "", "", "2017-01-31 10:00:00"},
+		{10, "MINUTE", "2017-01-30 00:00:00", "", "2017-01-31 10:00:00"},
+		{10, "MINUTE", "2017-01-31 10:00:00", "", "2017-01-31 10:10:00"},
+		{10, "MINUTE", "2017-01-31 10:25:03", "", "2017-01-31 10:30:00"},
+		{10, "MINUTE", "2017-01-31 10:25:03", "2017-01-31 10:30:00", "2017-01-31 10:30:00"},
+		{10, "MINUTE", "2017-01-31 10:25:03", "2017-01-31 10:29:59", ""},
+		// The day of month is clamped in short months, but the schedule keeps the day of STARTS.
+		{1, "MONTH", "2017-01-31 10:00:00", "", "2017-02-28 10:00:00"},
+		{1, "MONTH", "2017-02-28 10:00:00", "", "2017-03-31 10:00:00"},
+		{2, "MONTH", "2017-05-01 00:00:00", "", "2017-05-31 10:00:00"},
+		{1, "YEAR", "2017-01-31 10:00:00", "", "2018-01-31 10:00:00"},
+	}
+	for _, t := range tbl {
+		e := &Event{IntervalValue: t.n, IntervalField: t.unit, Starts: parseTime(c, "2017-01-31 10:00:00")}
+		if t.lastExecuted != "" {
+			e.LastExecuted = parseTime(c, t.lastExecuted)
+		}
+		if t.ends != "" {
+			e.Ends = parseTime(c, t.ends)
+		}
+		next, ok := e.NextRun()
+		if t.expect == "" {
+			c.Assert(ok, IsFalse, Commentf("%v", t))
+			continue
+		}
+		c.Assert(ok, IsTrue, Commentf("%v", t))
+		c.Assert(next, DeepEquals, parseTime(c, t.expect), Commentf("%v", t))
+	}
+}
+
+func (s *testEventSuite) TestQuoteString(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(QuoteString("e1"), Equals, "e1")
+	c.Assert(QuoteString(`it's`), Equals, `it\'s`)
+	c.Assert(QuoteString(`a\' or 1`), Equals, `a\\\' or 1`)
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	schedulerCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "event",
+			Name:      "scheduler_total",
+			Help:      "Counter of event scheduler actions.",
+		}, []string{"type"})
+
+	executeCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "event",
+			Name:      "execute_total",
+			Help:      "Counter of event executions.",
+		}, []string{"result"})
+
+	executeDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "event",
+			Name:      "execute_duration_seconds",
+			Help:      "Bucketed histogram of processing time (s) of event executions.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 20),
+		})
+)
+
+func init() {
+	prometheus.MustRegister(schedulerCounter)
+	prometheus.MustRegister(executeCounter)
+	prometheus.MustRegister(executeDuration)
+}
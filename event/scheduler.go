@@ -0,0 +1,263 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/privilege/privileges"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+const (
+	// SchedulerVar is the global system variable which turns the event scheduler on or off.
+	SchedulerVar = "event_scheduler"
+
+	leaderUUIDKey  = "event_scheduler_leader_uuid"
+	leaderLeaseKey = "event_scheduler_leader_lease"
+	leaderLease    = 30 * time.Second
+	leaseFormat    = "20060102-15:04:05 -0700 MST"
+)
+
+var leaderComments = map[string]string{
+	leaderUUIDKey:  "Current event scheduler leader UUID. (DO NOT EDIT)",
+	leaderLeaseKey: "Current event scheduler leader lease. (DO NOT EDIT)",
+}
+
+// Scheduler executes the events on schedule.
+// Every TiDB server runs a Scheduler, but only the leader elected by a lease in mysql.tidb table
+// executes the events, so an event is not executed by more than one server.
+type Scheduler struct {
+	ctx        context.Context
+	privHandle *privileges.Handle
+	uuid       string
+	// leaseExpire is the time the lease of the leader expires, it is zero if the scheduler is not the leader.
+	leaseExpire time.Time
+}
+
+// NewScheduler creates a Scheduler, ctx is used exclusively by the Scheduler to execute events,
+// privHandle is used to check the privileges of the event definers.
+func NewScheduler(ctx context.Context, privHandle *privileges.Handle) *Scheduler {
+	hostName, err := os.Hostname()
+	if err != nil {
+		hostName = "unknown"
+	}
+	return &Scheduler{
+		ctx:        ctx,
+		privHandle: privHandle,
+		uuid:       fmt.Sprintf("%s:%d:%d", hostName, os.Getpid(), time.Now().UnixNano()),
+	}
+}
+
+// Tick executes the events which are due at now, if the event scheduler is on and this server is the leader.
+func (s *Scheduler) Tick(now time.Time) error {
+	on, err := s.isOn()
+	if err != nil || !on {
+		return errors.Trace(err)
+	}
+	isLeader, err := s.checkLeader(now)
+	if err != nil || !isLeader {
+		return errors.Trace(err)
+	}
+	events, err := LoadEnabled(s.ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, e := range events {
+		next, ok := e.NextRun()
+		if ok && next.After(now) {
+			continue
+		}
+		if ok {
+			s.execute(e)
+			e.LastExecuted = now
+			err = s.updateLastExecuted(e)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			_, ok = e.NextRun()
+		}
+		if !ok {
+			err = s.complete(e)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) isOn() (bool, error) {
+	val, err := s.ctx.GetSessionVars().GlobalVarsAccessor.GetGlobalSysVar(SchedulerVar)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return strings.EqualFold(val, "ON") || val == "1", nil
+}
+
+// execute executes the event body as the definer in the event's database.
+// The error of the event body is logged, it doesn't stop the scheduler.
+func (s *Scheduler) execute(e *Event) {
+	vars := s.ctx.GetSessionVars()
+	vars.User = e.Definer
+	vars.CurrentDB = e.DB
+	if idx := strings.LastIndex(e.Definer, "@"); idx >= 0 && s.privHandle != nil {
+		pm := privileges.NewUserPrivileges(s.privHandle, e.Definer[:idx], e.Definer[idx+1:])
+		privilege.BindPrivilegeManager(s.ctx, pm)
+	}
+	defer func() {
+		privilege.BindPrivilegeManager(s.ctx, nil)
+		vars.User = ""
+		vars.CurrentDB = ""
+	}()
+
+	startTime := time.Now()
+	err := s.execSQL(e.Body)
+	executeDuration.Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		executeCounter.WithLabelValues("error").Inc()
+		log.Errorf("[event scheduler] execute event %s.%s error: %v", e.DB, e.Name, errors.ErrorStack(err))
+		return
+	}
+	executeCounter.WithLabelValues("ok").Inc()
+	log.Infof("[event scheduler] execute event %s.%s, cost time: %s", e.DB, e.Name, time.Since(startTime))
+}
+
+func (s *Scheduler) updateLastExecuted(e *Event) error {
+	sql := fmt.Sprintf(`UPDATE %s.%s SET last_executed = '%s' WHERE db = '%s' AND name = '%s'`,
+		mysql.SystemDB, mysql.EventTable, e.LastExecuted.UTC().Format(TimeFormat),
+		QuoteString(e.DB), QuoteString(e.Name))
+	_, _, err := s.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(s.ctx, sql)
+	return errors.Trace(err)
+}
+
+// complete drops or disables the event which will never execute again, according to its ON COMPLETION option.
+func (s *Scheduler) complete(e *Event) error {
+	db, name := QuoteString(e.DB), QuoteString(e.Name)
+	sql := fmt.Sprintf(`DELETE FROM %s.%s WHERE db = '%s' AND name = '%s'`, mysql.SystemDB, mysql.EventTable, db, name)
+	if e.Preserve {
+		sql = fmt.Sprintf(`UPDATE %s.%s SET status = 'DISABLED' WHERE db = '%s' AND name = '%s'`, mysql.SystemDB, mysql.EventTable, db, name)
+	}
+	_, _, err := s.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(s.ctx, sql)
+	if err == nil {
+		schedulerCounter.WithLabelValues("complete").Inc()
+	}
+	return errors.Trace(err)
+}
+
+// checkLeader checks whether the scheduler is the leader, it tries to become the leader if the lease
+// of the leader is expired. The leader renews its lease when half of the lease is passed.
+func (s *Scheduler) checkLeader(now time.Time) (bool, error) {
+	if now.Before(s.leaseExpire.Add(-leaderLease / 2)) {
+		return true, nil
+	}
+	schedulerCounter.WithLabelValues("check_leader").Inc()
+	isLeader, err := s.campaign(now)
+	if err != nil || !isLeader {
+		s.leaseExpire = time.Time{}
+		return false, errors.Trace(err)
+	}
+	s.leaseExpire = now.Add(leaderLease)
+	return true, nil
+}
+
+func (s *Scheduler) campaign(now time.Time) (bool, error) {
+	err := s.execSQL("BEGIN")
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	leader, err := s.loadValue(leaderUUIDKey)
+	if err != nil {
+		s.execSQL("ROLLBACK")
+		return false, errors.Trace(err)
+	}
+	if leader != s.uuid {
+		lease, err1 := s.loadValue(leaderLeaseKey)
+		if err1 != nil {
+			s.execSQL("ROLLBACK")
+			return false, errors.Trace(err1)
+		}
+		expire, err1 := time.Parse(leaseFormat, lease)
+		if err1 == nil && now.Before(expire) {
+			s.execSQL("ROLLBACK")
+			return false, nil
+		}
+		log.Infof("[event scheduler] register %s as leader", s.uuid)
+		schedulerCounter.WithLabelValues("register_leader").Inc()
+		err = s.saveValue(leaderUUIDKey, s.uuid)
+		if err != nil {
+			s.execSQL("ROLLBACK")
+			return false, errors.Trace(err)
+		}
+	}
+	err = s.saveValue(leaderLeaseKey, now.Add(leaderLease).Format(leaseFormat))
+	if err != nil {
+		s.execSQL("ROLLBACK")
+		return false, errors.Trace(err)
+	}
+	err = s.execSQL("COMMIT")
+	return err == nil, errors.Trace(err)
+}
+
+func (s *Scheduler) loadValue(key string) (string, error) {
+	sql := fmt.Sprintf(`SELECT variable_value FROM %s.%s WHERE variable_name = '%s' FOR UPDATE`, mysql.SystemDB, mysql.TiDBTable, key)
+	rss, err := s.ctx.(sqlexec.SQLExecutor).Execute(sql)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer rss[0].Close()
+	row, err := rss[0].Next()
+	if err != nil || row == nil {
+		return "", errors.Trace(err)
+	}
+	return row.Data[0].GetString(), nil
+}
+
+func (s *Scheduler) saveValue(key, value string) error {
+	sql := fmt.Sprintf(`INSERT INTO %[1]s.%[2]s VALUES ('%[3]s', '%[4]s', '%[5]s') ON DUPLICATE KEY UPDATE variable_value = '%[4]s'`,
+		mysql.SystemDB, mysql.TiDBTable, key, value, leaderComments[key])
+	return errors.Trace(s.execSQL(sql))
+}
+
+// execSQL executes the sql in the scheduler's session and drains the results.
+func (s *Scheduler) execSQL(sql string) error {
+	rss, err := s.ctx.(sqlexec.SQLExecutor).Execute(sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, rs := range rss {
+		for {
+			row, err := rs.Next()
+			if err != nil {
+				rs.Close()
+				return errors.Trace(err)
+			}
+			if row == nil {
+				break
+			}
+		}
+		if err = rs.Close(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
@@ -16,19 +16,23 @@ package executor
 import (
 	"fmt"
 	"math"
+	"sync/atomic"
 	"time"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/util/resourcegroup"
 )
 
 type processinfoSetter interface {
-	SetProcessInfo(string)
+	SetProcessInfo(string, plan.Plan)
 }
 
 // recordSet wraps an executor, implements ast.RecordSet interface
@@ -61,6 +65,7 @@ func (a *recordSet) Fields() ([]*ast.ResultField, error) {
 func (a *recordSet) Next() (*ast.Row, error) {
 	row, err := a.executor.Next()
 	if err != nil {
+		a.err = err
 		return nil, errors.Trace(err)
 	}
 	if row == nil {
@@ -71,16 +76,22 @@ func (a *recordSet) Next() (*ast.Row, error) {
 	}
 
 	if a.stmt != nil {
-		a.stmt.ctx.GetSessionVars().StmtCtx.AddFoundRows(1)
+		sc := a.stmt.ctx.GetSessionVars().StmtCtx
+		sc.AddFoundRows(1)
+		if err = sc.ResourceQuota.AddReturnedRows(1); err != nil {
+			a.err = err
+			return nil, errors.Trace(err)
+		}
 	}
 	return &ast.Row{Data: row.Data}, nil
 }
 
 func (a *recordSet) Close() error {
 	err := a.executor.Close()
-	a.stmt.logSlowQuery()
+	a.stmt.logSlowQuery(a.err == nil)
+	a.stmt.leaveResourceGroup()
 	if a.processinfo != nil {
-		a.processinfo.SetProcessInfo("")
+		a.processinfo.SetProcessInfo("", nil)
 	}
 	return errors.Trace(err)
 }
@@ -91,9 +102,14 @@ type statement struct {
 
 	ctx            context.Context
 	text           string
+	label          string // label of the statement used in metrics.
 	plan           plan.Plan
 	startTime      time.Time
 	isPreparedStmt bool
+	// execPlan is the plan of the EXECUTE statement if it's a prepared statement.
+	execPlan *plan.Execute
+	// resourceGroup is the resource group entered by the statement, it's nil if the statement isn't limited.
+	resourceGroup *resourcegroup.Group
 }
 
 func (a *statement) OriginText() string {
@@ -108,13 +124,21 @@ func (a *statement) IsPrepared() bool {
 // This function builds an Executor from a plan. If the Executor doesn't return result,
 // like the INSERT, UPDATE statements, it executes in this function, if the Executor returns
 // result, execution is done after this function returns, in the returned ast.RecordSet Next method.
-func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
+func (a *statement) Exec(ctx context.Context) (rs ast.RecordSet, err error) {
 	a.startTime = time.Now()
 	a.ctx = ctx
+	if err = a.enterResourceGroup(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer func() {
+		// The statement which returns result leaves the resource group when the record set is closed.
+		if rs == nil {
+			a.leaveResourceGroup()
+		}
+	}()
 	if _, ok := a.plan.(*plan.Execute); !ok {
 		// Do not sync transaction for Execute statement, because the real optimization work is done in
 		// "ExecuteExec.Build".
-		var err error
 		if IsPointGetWithPKOrUniqueKeyByAutoCommit(ctx, a.plan) {
 			log.Debugf("[%d][InitTxnWithStartTS] %s", ctx.GetSessionVars().ConnectionID, a.text)
 			err = ctx.InitTxnWithStartTS(math.MaxUint64)
@@ -141,20 +165,27 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 		}
 		a.text = executorExec.Stmt.Text()
 		a.isPreparedStmt = true
+		a.execPlan, _ = a.plan.(*plan.Execute)
 		a.plan = executorExec.Plan
+		a.label = executorExec.stmtLabel
 		e = executorExec.StmtExec
 	}
 
-	err := e.Open()
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-
+	// The processinfo is updated before Open, because 'explain analyze' executes the statement
+	// in Open, its plan is shown by 'explain for connection' meanwhile.
 	var pi processinfoSetter
 	if raw, ok := ctx.(processinfoSetter); ok {
 		pi = raw
 		// Update processinfo, ShowProcess() will use it.
-		pi.SetProcessInfo(a.OriginText())
+		pi.SetProcessInfo(a.OriginText(), a.plan)
+	}
+
+	err = e.Open()
+	if err != nil {
+		if pi != nil {
+			pi.SetProcessInfo("", nil)
+		}
+		return nil, errors.Trace(err)
 	}
 
 	// Fields or Schema are only used for statements that return result set.
@@ -167,14 +198,19 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 			if snapshotTS != 0 {
 				return nil, errors.New("can not execute write statement when 'tidb_snapshot' is set")
 			}
+			// The statement out of explicit transactions is begun at the low resolution timestamp, which may miss the
+			// latest data it's going to overwrite.
+			if ctx.GetSessionVars().LowResolutionTSO && !ctx.GetSessionVars().InTxn() {
+				return nil, errors.New("can not execute write statement when 'tidb_low_resolution_tso' is set")
+			}
 		}
 
 		defer func() {
 			if pi != nil {
-				pi.SetProcessInfo("")
+				pi.SetProcessInfo("", nil)
 			}
 			e.Close()
-			a.logSlowQuery()
+			a.logSlowQuery(err == nil)
 		}()
 		for {
 			row, err := e.Next()
@@ -198,23 +234,83 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 	}, nil
 }
 
+// enterResourceGroup enters the resource group of the user and sets the quota of the statement by the
+// group and the limits of the session, the internal statements are not limited.
+func (a *statement) enterResourceGroup() error {
+	sessVars := a.ctx.GetSessionVars()
+	if sessVars.InRestrictedSQL {
+		return nil
+	}
+	group := resourcegroup.ForUser(sessVars.User)
+	if group != nil {
+		if err := group.Enter(); err != nil {
+			return errors.Trace(err)
+		}
+		a.resourceGroup = group
+	}
+	sessVars.StmtCtx.ResourceQuota = resourcegroup.NewStmtQuota(group, sessVars.StmtLimits)
+	return nil
+}
+
+func (a *statement) leaveResourceGroup() {
+	if a.resourceGroup != nil {
+		a.resourceGroup.Leave()
+		a.resourceGroup = nil
+	}
+}
+
 const (
 	queryLogMaxLen = 2048
-	slowThreshold  = 300 * time.Millisecond
+	// DefSlowThreshold is the default threshold of the slow queries.
+	DefSlowThreshold = 300 * time.Millisecond
 )
 
-func (a *statement) logSlowQuery() {
+// slowThreshold is accessed atomically, the queries running longer than it are logged as slow queries.
+var slowThreshold = int64(DefSlowThreshold)
+
+// SetSlowThreshold sets the threshold of the slow queries.
+func SetSlowThreshold(threshold time.Duration) {
+	atomic.StoreInt64(&slowThreshold, int64(threshold))
+}
+
+func (a *statement) logSlowQuery(succ bool) {
 	costTime := time.Since(a.startTime)
+	if a.label != "" && a.label != IGNORE {
+		stmtDurationHistogram.WithLabelValues(a.label).Observe(costTime.Seconds())
+	}
 	sql := a.text
 	if len(sql) > queryLogMaxLen {
 		sql = sql[:queryLogMaxLen] + fmt.Sprintf("(len:%d)", len(sql))
 	}
-	connID := a.ctx.GetSessionVars().ConnectionID
-	if costTime < slowThreshold {
+	sessVars := a.ctx.GetSessionVars()
+	connID := sessVars.ConnectionID
+	if costTime < time.Duration(atomic.LoadInt64(&slowThreshold)) {
 		log.Debugf("[%d][TIME_QUERY] %v %s", connID, costTime, sql)
+		return
+	}
+	// The runtime statistics of the coprocessor requests help to find out whether the storage is the bottleneck.
+	if execDetails := sessVars.StmtCtx.TotalExecDetails().String(); execDetails != "" {
+		log.Warnf("[%d][TIME_QUERY] %v %s %s", connID, costTime, execDetails, sql)
 	} else {
 		log.Warnf("[%d][TIME_QUERY] %v %s", connID, costTime, sql)
 	}
+	if dom := sessionctx.GetDomain(a.ctx); dom != nil {
+		var txnTS uint64
+		if txn := a.ctx.Txn(); txn != nil {
+			txnTS = txn.StartTS()
+		}
+		dom.LogSlowQuery(&domain.SlowQueryInfo{
+			SQL:      sql,
+			Start:    a.startTime,
+			Duration: costTime,
+			Succ:     succ,
+			ConnID:   connID,
+			TxnTS:    txnTS,
+			User:     sessVars.User,
+			DB:       sessVars.CurrentDB,
+			Internal: sessVars.InRestrictedSQL,
+		})
+	}
 }
 
 // IsPointGetWithPKOrUniqueKeyByAutoCommit returns true when meets following conditions:
@@ -241,6 +337,8 @@ func IsPointGetWithPKOrUniqueKeyByAutoCommit(ctx context.Context, p plan.Plan) b
 	}
 
 	switch v := p.(type) {
+	case *plan.PointGet:
+		return v.Index != nil
 	case *plan.PhysicalIndexScan:
 		return v.IsPointGetByUniqueKey(ctx.GetSessionVars().StmtCtx)
 	case *plan.PhysicalIndexReader:
@@ -135,7 +135,10 @@ func (e *HashAggExec) innerNext() (ret bool, err error) {
 		e.groupMap.Put(groupKey, []byte{})
 	}
 	for _, af := range e.AggFuncs {
-		af.Update(srcRow.Data, groupKey, e.sc)
+		err = af.Update(srcRow.Data, groupKey, e.sc)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
 	}
 	return true, nil
 }
@@ -160,6 +163,7 @@ type StreamAggExec struct {
 func (e *StreamAggExec) Open() error {
 	e.executed = false
 	e.hasData = false
+	e.curGroupKey = nil
 	for _, agg := range e.AggFuncs {
 		agg.Reset()
 	}
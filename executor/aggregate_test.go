@@ -19,8 +19,10 @@ import (
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types/json"
 )
 
 type MockExec struct {
@@ -278,7 +280,7 @@ func (s *testSuite) TestAggregation(c *C) {
 
 	result = tk.MustQuery("select count(*) from information_schema.columns")
 	// When adding new memory table in information_schema, please update this variable.
-	columnCountOfAllInformationSchemaTables := "716"
+	columnCountOfAllInformationSchemaTables := "845"
 	result.Check(testkit.Rows(columnCountOfAllInformationSchemaTables))
 
 	tk.MustExec("drop table if exists t1")
@@ -328,6 +330,32 @@ func (s *testSuite) TestAggPrune(c *C) {
 	tk.MustQuery("select count(distinct b, c, d) from t group by id").Check(testkit.Rows("0", "0", "0", "0", "0", "0", "0", "1"))
 }
 
+func (s *testSuite) TestJSONAggregation(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(id int primary key, g int, k varchar(10), v int, j json)")
+	tk.MustExec(`insert into t values (1, 1, 'a', 1, '{"x": 1}'), (2, 1, 'b', NULL, '[1, 2]'), (3, 2, 'c', 3, NULL), (4, 2, 'c', 4, NULL)`)
+	tk.MustQuery("select g, json_arrayagg(v), json_objectagg(k, v) from t group by g order by g").Check(testkit.Rows(
+		`1 [1,null] {"a":1,"b":null}`,
+		`2 [3,4] {"c":4}`,
+	))
+	tk.MustQuery("select json_arrayagg(j), json_objectagg(k, j) from t where g = 1").Check(testkit.Rows(
+		`[{"x":1},[1,2]] {"a":{"x":1},"b":[1,2]}`,
+	))
+	tk.MustQuery("select json_objectagg(id, k) from t").Check(testkit.Rows(`{"1":"a","2":"b","3":"c","4":"c"}`))
+	tk.MustQuery("select json_arrayagg(v), json_objectagg(k, v) from t where id > 10").Check(testkit.Rows("<nil> <nil>"))
+	rs, err := tk.Exec("select json_objectagg(j, v) from t")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+	c.Assert(terror.ErrorEqual(err, json.ErrJSONDocumentNULLKey), IsTrue, Commentf("err %v", err))
+}
+
 func (s *testSuite) TestSelectDistinct(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -344,6 +372,127 @@ func (s *testSuite) TestSelectDistinct(c *C) {
 
 }
 
+func (s *testSuite) TestGroupByNameResolution(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, pk")
+	tk.MustExec("create table t (a int, b int, c int)")
+	tk.MustExec("insert t values (1, 2, 3), (1, 3, 4), (2, 2, 5)")
+	tk.MustExec("create table pk (id int primary key, v int)")
+	tk.MustExec("insert pk values (1, 10), (2, 20)")
+
+	tk.MustQuery("select a as x, count(*) from t group by x order by 2").Check(testkit.Rows("2 1", "1 2"))
+	tk.MustQuery("select a, count(*) c from t group by 1 having c > 1").Check(testkit.Rows("1 2"))
+	tk.MustQuery("select a + 1 x, sum(c) s from t group by x having x > 2 order by s").Check(testkit.Rows("3 5"))
+	// A column of the FROM clause takes precedence over a select field alias in GROUP BY.
+	tk.MustQuery("select a as b, sum(c) from t group by b order by 2").Check(testkit.Rows("1 4", "1 8"))
+
+	tests := []struct {
+		sql string
+		err *terror.Error
+		msg string
+	}{
+		{"select a from t group by 2", plan.ErrUnknownColumn, "Unknown column '2' in 'group statement'"},
+		{"select a from t order by 0", plan.ErrUnknownColumn, "Unknown column '0' in 'order clause'"},
+		{"select a x from t group by t.x", plan.ErrUnknownColumn, "Unknown column 't.x' in 'group statement'"},
+		{"select count(*) from t group by a having b > 1", plan.ErrUnknownColumn, "Unknown column 'b' in 'having clause'"},
+		{"select a x, b x from t group by x", plan.ErrAmbiguous, "Column 'x' in group statement is ambiguous"},
+		{"select d from t", plan.ErrUnknownColumn, "Unknown column 'd' in 'field list'"},
+	}
+	for _, tt := range tests {
+		_, err := tk.Exec(tt.sql)
+		c.Assert(terror.ErrorEqual(err, tt.err), IsTrue, Commentf("%s %v", tt.sql, err))
+		c.Assert(err.Error(), Matches, ".*"+tt.msg, Commentf("%s", tt.sql))
+	}
+
+	tk.MustExec("set sql_mode = 'ONLY_FULL_GROUP_BY'")
+	tk.MustQuery("select a + 1, count(*) from t group by a + 1").Check(testkit.Rows("2 2", "3 1"))
+	tk.MustQuery("select a x, count(*) from t group by x order by x desc").Check(testkit.Rows("2 1", "1 2"))
+	tk.MustQuery("select a, b + 1 from t group by a, b order by a, b").Check(testkit.Rows("1 3", "1 4", "2 3"))
+	tk.MustQuery("select 1, count(*) from t").Check(testkit.Rows("1 3"))
+	// The columns of a table are determined by its primary key.
+	tk.MustQuery("select id, v from pk group by id").Check(testkit.Rows("1 10", "2 20"))
+	_, err := tk.Exec("select a, b from t group by a")
+	c.Assert(terror.ErrorEqual(err, plan.ErrFieldNotInGroupBy), IsTrue)
+	_, err = tk.Exec("select a, count(*) from t group by a order by b")
+	c.Assert(terror.ErrorEqual(err, plan.ErrFieldNotInGroupBy), IsTrue)
+	_, err = tk.Exec("select a, count(*) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrMixOfGroupFuncAndFields), IsTrue)
+	tk.MustExec("set sql_mode = ''")
+	tk.MustQuery("select a, b from t group by a").Check(testkit.Rows("1 2", "2 2"))
+	tk.MustExec("drop table t, pk")
+}
+
+func (s *testSuite) TestOuterAggregation(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, s")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("create table s (x int, y int)")
+	tk.MustExec("insert t values (1, 2), (1, 3), (2, 4)")
+	tk.MustExec("insert s values (1, 5), (2, 7), (3, 9)")
+
+	// The aggregate functions that only refer to the outer columns are aggregated in the outer query.
+	tk.MustQuery("select (select count(t.a) from s limit 1) from t").Check(testkit.Rows("3"))
+	tk.MustQuery("select (select sum(t.b) from s limit 1) from t").Check(testkit.Rows("9"))
+	tk.MustQuery("select a, (select sum(t.b) from s limit 1) from t group by a").Check(testkit.Rows("1 5", "2 4"))
+	tk.MustQuery("select a, (select max(s.y) + sum(t.b) from s) from t group by a").Check(testkit.Rows("1 14", "2 13"))
+	tk.MustQuery("select a, (select count(*) from s where s.x <= count(t.b)) from t group by a").Check(testkit.Rows("1 2", "2 1"))
+	tk.MustQuery("select (select count(*) from s where s.x < sum(t.a)) from t").Check(testkit.Rows("3"))
+	tk.MustQuery("select a, (select (select sum(t.b) from s limit 1) from s limit 1) from t group by a").Check(testkit.Rows("1 5", "2 4"))
+	tk.MustQuery("select a from t group by a having exists (select 1 from s where s.y > sum(t.b))").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select a from t group by a having (select count(*) from s where s.x <= count(t.b)) > 1").Check(testkit.Rows("1"))
+	tk.MustQuery("select a from t group by a order by (select sum(t.b) from s limit 1)").Check(testkit.Rows("2", "1"))
+	// The aggregate functions that refer to the inner columns are aggregated in the subquery.
+	tk.MustQuery("select a, (select sum(t.b + s.y) from s) from t group by a").Check(testkit.Rows("1 27", "2 33"))
+
+	_, err := tk.Exec("select a from t where a in (select count(t.b) from s)")
+	c.Assert(terror.ErrorEqual(err, plan.ErrInvalidGroupFuncUse), IsTrue)
+	tk.MustExec("drop table t, s")
+}
+
+func (s *testSuite) TestWindowFunc(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int, c int)")
+	tk.MustQuery("select row_number() over (), sum(a) over (partition by b) from t").Check(testkit.Rows())
+	tk.MustExec("insert t values (1, 2, 30), (2, 3, null), (1, 1, 10), (3, 1, 7), (1, 2, 20), (2, 1, 5)")
+
+	tk.MustQuery("select a, b, c, row_number() over (partition by a order by b, c), rank() over (partition by a order by b) from t order by a, b, c").Check(testkit.Rows(
+		"1 1 10 1 1", "1 2 20 2 2", "1 2 30 3 2", "2 1 5 1 1", "2 3 <nil> 2 2", "3 1 7 1 1"))
+	tk.MustQuery("select a, rank() over (order by a desc) as r from t order by r, a").Check(testkit.Rows(
+		"3 1", "2 2", "2 2", "1 4", "1 4", "1 4"))
+	tk.MustQuery("select a, b, sum(c) over (partition by a), sum(c) over (partition by a order by b), count(c) over (order by a) from t order by a, b, c").Check(testkit.Rows(
+		"1 1 60 10 3", "1 2 60 60 3", "1 2 60 60 3", "2 1 5 5 4", "2 3 5 5 4", "3 1 7 7 5"))
+	tk.MustQuery("select b, max(c) over (partition by a % 2), min(c) over (partition by a % 2 order by b desc) from t order by a, b, c").Check(testkit.Rows(
+		"1 30 7", "2 30 20", "2 30 20", "1 5 5", "3 5 <nil>", "1 30 7"))
+	tk.MustQuery("select avg(c) over () = (select avg(c) from t) from t limit 1").Check(testkit.Rows("1"))
+	tk.MustQuery("select count(*) from (select row_number() over () as rn from t) x where rn > 4").Check(testkit.Rows("2"))
+
+	// The window functions are computed after the aggregation.
+	tk.MustQuery("select a, sum(b), rank() over (order by sum(b) desc), sum(sum(b)) over (order by a) from t group by a order by a").Check(testkit.Rows(
+		"1 5 1 5", "2 4 2 9", "3 1 3 10"))
+
+	_, err := tk.Exec("select a from t where row_number() over () > 1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrInvalidWindowFuncUse), IsTrue)
+	_, err = tk.Exec("select a from t group by rank() over (order by a)")
+	c.Assert(terror.ErrorEqual(err, plan.ErrInvalidWindowFuncUse), IsTrue)
+	tk.MustExec("drop table t")
+}
+
 func (s *testSuite) TestAggPushDown(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -359,3 +508,29 @@ func (s *testSuite) TestAggPushDown(c *C) {
 	tk.MustQuery("select max(a.b), max(b.b) from t a join tt b on a.a = b.a group by a.c").Check(testkit.Rows("1 2"))
 	tk.MustQuery("select a, count(b) from (select * from t union all select * from tt) k group by a").Check(testkit.Rows("1 2", "2 1"))
 }
+
+func (s *testSuite) TestStreamAggregation(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int primary key, b int, c int, d int, key idx_bcd(b, c, d))")
+	tk.MustExec("insert into t values (1, 1, 1, 1), (2, 1, 1, 1), (3, 1, 2, 2), (4, 2, 1, 1), (5, null, 1, 3), (6, null, 2, 3), (7, 2, null, 4)")
+	result := tk.MustQuery("select b, count(distinct d) from t group by b")
+	result.Check(testkit.Rows("<nil> 1", "1 2", "2 2"))
+	result = tk.MustQuery("select b, c, count(distinct d), sum(distinct d) from t group by b, c")
+	result.Check(testkit.Rows("<nil> 1 1 3", "<nil> 2 1 3", "1 1 1 1", "1 2 1 2", "2 <nil> 1 4", "2 1 1 1"))
+	result = tk.MustQuery("select c, count(distinct d) from t where b = 1 group by c")
+	result.Check(testkit.Rows("1 1", "2 1"))
+	result = tk.MustQuery("select b, count(distinct d) from t group by b order by b desc")
+	result.Check(testkit.Rows("2 2", "1 2", "<nil> 1"))
+	// The stream aggregation is reopened for every outer row.
+	result = tk.MustQuery("select a, (select count(distinct t2.d) from t t2 where t2.b = t1.b group by t2.b) from t t1")
+	result.Check(testkit.Rows("1 2", "2 2", "3 2", "4 2", "5 <nil>", "6 <nil>", "7 2"))
+	tk.MustExec("truncate table t")
+	result = tk.MustQuery("select b, count(distinct d) from t group by b")
+	result.Check(nil)
+}
@@ -0,0 +1,122 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+)
+
+// applyPrefetchTask is a batch of outer rows and the inner rows read for each of them.
+type applyPrefetchTask struct {
+	bigRows []*Row
+	matched []bool
+	// innerRows[i] are the inner rows read with the correlated columns set to bigRows[i].
+	innerRows [][]*Row
+	err       error
+}
+
+// applyPrefetcher reads the outer rows and looks up the inner rows of an index nested loop apply in a background
+// goroutine. The tasks are sent through an unbuffered channel, so the lookups of the next outer batch are issued
+// while the current batch is being joined, which overlaps the latency of the inner requests with the computation.
+type applyPrefetcher struct {
+	taskCh  chan *applyPrefetchTask
+	closeCh chan struct{}
+	task    *applyPrefetchTask
+	idx     int
+}
+
+// joinPrefetchedRow joins the next prefetched outer row with its inner rows, finished is true if there are no
+// more outer rows.
+func (e *ApplyJoinExec) joinPrefetchedRow() (rows []*Row, finished bool, err error) {
+	join := e.join.(*NestedLoopJoinExec)
+	if e.prefetcher == nil {
+		e.prefetcher = &applyPrefetcher{
+			taskCh:  make(chan *applyPrefetchTask),
+			closeCh: make(chan struct{}),
+		}
+		go e.prefetchInnerRows(join, e.prefetcher)
+	}
+	p := e.prefetcher
+	if p.task == nil || p.idx >= len(p.task.bigRows) {
+		task, ok := <-p.taskCh
+		if !ok {
+			return nil, true, nil
+		}
+		if task.err != nil {
+			return nil, false, errors.Trace(task.err)
+		}
+		p.task, p.idx = task, 0
+	}
+	i := p.idx
+	p.idx++
+	rows, err = join.joinInnerRows(p.task.bigRows[i], p.task.matched[i], p.task.innerRows[i])
+	return rows, false, errors.Trace(err)
+}
+
+// prefetchInnerRows reads the outer rows batch by batch, and reads the inner rows of every matched outer row
+// with the correlated columns set to it. The outer and inner executors are only used by this goroutine until
+// it exits.
+func (e *ApplyJoinExec) prefetchInnerRows(join *NestedLoopJoinExec, p *applyPrefetcher) {
+	defer close(p.taskCh)
+	for {
+		task := &applyPrefetchTask{}
+		for len(task.bigRows) < batchSize && task.err == nil {
+			select {
+			case <-p.closeCh:
+				return
+			default:
+			}
+			bigRow, match, err := join.fetchBigRow()
+			if err != nil {
+				task.err = errors.Trace(err)
+				break
+			}
+			if bigRow == nil {
+				break
+			}
+			var innerRows []*Row
+			if match {
+				for _, col := range e.outerSchema {
+					*col.Data = bigRow.Data[col.Index]
+				}
+				innerRows, task.err = join.fetchInnerRows(nil)
+			}
+			task.bigRows = append(task.bigRows, bigRow)
+			task.matched = append(task.matched, match)
+			task.innerRows = append(task.innerRows, innerRows)
+		}
+		if len(task.bigRows) == 0 && task.err == nil {
+			return
+		}
+		select {
+		case p.taskCh <- task:
+		case <-p.closeCh:
+			return
+		}
+		if task.err != nil || len(task.bigRows) < batchSize {
+			return
+		}
+	}
+}
+
+// stopPrefetch stops the prefetching goroutine and waits for it to exit.
+func (e *ApplyJoinExec) stopPrefetch() {
+	if e.prefetcher == nil {
+		return
+	}
+	close(e.prefetcher.closeCh)
+	for range e.prefetcher.taskCh {
+	}
+	e.prefetcher = nil
+}
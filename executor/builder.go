@@ -23,9 +23,9 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/inspectkv"
-	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 )
@@ -37,6 +37,11 @@ type executorBuilder struct {
 	is  infoschema.InfoSchema
 	// err is set when there is error happened during Executor building process.
 	err error
+	// sharedScans are the scans shared by the identical table readers, keyed by sharedScanKey.
+	sharedScans map[string]*sharedTableScan
+	// applyDepth is the number of the apply executors and the inner children of the index lookup joins being
+	// built, the table readers under them are opened once for every outer row or batch, so they don't share scans.
+	applyDepth int
 }
 
 func newExecutorBuilder(ctx context.Context, is infoschema.InfoSchema) *executorBuilder {
@@ -62,10 +67,14 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildExecute(v)
 	case *plan.Explain:
 		return b.buildExplain(v)
+	case *plan.Trace:
+		return b.buildTrace(v)
 	case *plan.Insert:
 		return b.buildInsert(v)
 	case *plan.LoadData:
 		return b.buildLoadData(v)
+	case *plan.SplitRegion:
+		return b.buildSplitRegion(v)
 	case *plan.Limit:
 		return b.buildLimit(v)
 	case *plan.Prepare:
@@ -74,6 +83,18 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildSelectLock(v)
 	case *plan.ShowDDL:
 		return b.buildShowDDL(v)
+	case *plan.ShowDDLOwner:
+		return b.buildShowDDLOwner(v)
+	case *plan.TransferDDLOwner:
+		return b.buildTransferDDLOwner(v)
+	case *plan.ShowSlow:
+		return b.buildShowSlow(v)
+	case *plan.ReloadExprPushdownBlacklist:
+		return b.buildReloadExprPushdownBlacklist(v)
+	case *plan.ReloadResourceGroups:
+		return b.buildReloadResourceGroups(v)
+	case *plan.ReloadConfig:
+		return b.buildReloadConfig(v)
 	case *plan.Show:
 		return b.buildShow(v)
 	case *plan.Simple:
@@ -94,18 +115,24 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildHashJoin(v)
 	case *plan.PhysicalMergeJoin:
 		return b.buildMergeJoin(v)
+	case *plan.PhysicalIndexJoin:
+		return b.buildIndexLookUpJoin(v)
 	case *plan.PhysicalHashSemiJoin:
 		return b.buildSemiJoin(v)
 	case *plan.Selection:
 		return b.buildSelection(v)
 	case *plan.PhysicalAggregation:
 		return b.buildAggregation(v)
+	case *plan.PhysicalWindow:
+		return b.buildWindow(v)
 	case *plan.Projection:
 		return b.buildProjection(v)
 	case *plan.PhysicalMemTable:
 		return b.buildMemTable(v)
 	case *plan.PhysicalTableScan:
 		return b.buildTableScan(v)
+	case *plan.PointGet:
+		return b.buildPointGet(v)
 	case *plan.PhysicalIndexScan:
 		return b.buildIndexScan(v)
 	case *plan.TableDual:
@@ -154,6 +181,26 @@ func (b *executorBuilder) buildShowDDL(v *plan.ShowDDL) Executor {
 	return e
 }
 
+func (b *executorBuilder) buildShowDDLOwner(v *plan.ShowDDLOwner) Executor {
+	return &ShowDDLOwnerExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+	}
+}
+
+func (b *executorBuilder) buildTransferDDLOwner(v *plan.TransferDDLOwner) Executor {
+	return &TransferDDLOwnerExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		Target:       v.Target,
+	}
+}
+
+func (b *executorBuilder) buildShowSlow(v *plan.ShowSlow) Executor {
+	return &ShowSlowExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		ShowSlow:     v.ShowSlow,
+	}
+}
+
 func (b *executorBuilder) buildCheckTable(v *plan.CheckTable) Executor {
 	return &CheckTableExec{
 		tables: v.Tables,
@@ -162,6 +209,24 @@ func (b *executorBuilder) buildCheckTable(v *plan.CheckTable) Executor {
 	}
 }
 
+func (b *executorBuilder) buildReloadExprPushdownBlacklist(v *plan.ReloadExprPushdownBlacklist) Executor {
+	return &ReloadExprPushdownBlacklistExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+	}
+}
+
+func (b *executorBuilder) buildReloadResourceGroups(v *plan.ReloadResourceGroups) Executor {
+	return &ReloadResourceGroupsExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+	}
+}
+
+func (b *executorBuilder) buildReloadConfig(v *plan.ReloadConfig) Executor {
+	return &ReloadConfigExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+	}
+}
+
 func (b *executorBuilder) buildDeallocate(v *plan.Deallocate) Executor {
 	return &DeallocateExec{
 		ctx:  b.ctx,
@@ -225,10 +290,15 @@ func (b *executorBuilder) buildShow(v *plan.Show) Executor {
 		Full:         v.Full,
 		GlobalScope:  v.GlobalScope,
 		is:           b.is,
+
+		CountWarningsOrErrors: v.CountWarningsOrErrors,
 	}
 	if e.Tp == ast.ShowGrants && len(e.User) == 0 {
 		e.User = e.ctx.GetSessionVars().User
 	}
+	if e.Tp == ast.ShowMasterStatus {
+		e.startTS = b.getStartTS()
+	}
 	return e
 }
 
@@ -255,10 +325,11 @@ func (b *executorBuilder) buildSet(v *plan.Set) Executor {
 
 func (b *executorBuilder) buildInsert(v *plan.Insert) Executor {
 	ivs := &InsertValues{
-		ctx:     b.ctx,
-		Columns: v.Columns,
-		Lists:   v.Lists,
-		Setlist: v.Setlist,
+		ctx:          b.ctx,
+		Columns:      v.Columns,
+		Lists:        v.Lists,
+		Setlist:      v.Setlist,
+		DefaultExprs: v.DefaultExprs,
 	}
 	if len(v.Children()) > 0 {
 		ivs.SelectExec = b.build(v.Children()[0])
@@ -305,6 +376,16 @@ func (b *executorBuilder) buildLoadData(v *plan.LoadData) Executor {
 	}
 }
 
+func (b *executorBuilder) buildSplitRegion(v *plan.SplitRegion) Executor {
+	return &SplitRegionExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		table:        v.Table,
+		lower:        v.Lower,
+		upper:        v.Upper,
+		num:          v.Num,
+	}
+}
+
 func (b *executorBuilder) buildReplace(vals *InsertValues) Executor {
 	return &ReplaceExec{
 		InsertValues: vals,
@@ -319,6 +400,7 @@ func (b *executorBuilder) buildGrant(grant *ast.GrantStmt) Executor {
 		Level:      grant.Level,
 		Users:      grant.Users,
 		WithGrant:  grant.WithGrant,
+		Text:       grant.Text(),
 		is:         b.is,
 	}
 }
@@ -330,6 +412,7 @@ func (b *executorBuilder) buildRevoke(revoke *ast.RevokeStmt) Executor {
 		ObjectType: revoke.ObjectType,
 		Level:      revoke.Level,
 		Users:      revoke.Users,
+		Text:       revoke.Text(),
 		is:         b.is,
 	}
 }
@@ -339,10 +422,31 @@ func (b *executorBuilder) buildDDL(v *plan.DDL) Executor {
 }
 
 func (b *executorBuilder) buildExplain(v *plan.Explain) Executor {
-	return &ExplainExec{
+	e := &ExplainExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
 		StmtPlan:     v.StmtPlan,
 	}
+	if v.ExecDetailsCtx != nil {
+		// The statement is running in another connection, it's not executed here.
+		e.execDetailsCtx = v.ExecDetailsCtx
+		return e
+	}
+	if v.Analyze {
+		e.analyzeExec = b.build(v.StmtPlan)
+		if b.err != nil {
+			return nil
+		}
+		e.execDetailsCtx = b.ctx.GetSessionVars().StmtCtx
+	}
+	return e
+}
+
+func (b *executorBuilder) buildTrace(v *plan.Trace) Executor {
+	return &TraceExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		stmtNode:     v.StmtNode,
+		is:           b.is,
+	}
 }
 
 func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor {
@@ -364,6 +468,12 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 		us.conditions = v.Conditions
 		us.columns = x.columns
 		us.buildAndSortAddedRows(x.table, x.asName)
+	case *SharedTableReaderExecutor:
+		us.desc = x.reader.desc
+		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.reader.table.Meta().ID)
+		us.conditions = v.Conditions
+		us.columns = x.reader.columns
+		us.buildAndSortAddedRows(x.reader.table, x.reader.asName)
 	case *XSelectIndexExec:
 		us.desc = x.desc
 		for _, ic := range x.index.Columns {
@@ -498,6 +608,37 @@ func (b *executorBuilder) buildHashJoin(v *plan.PhysicalHashJoin) Executor {
 	return e
 }
 
+func (b *executorBuilder) buildIndexLookUpJoin(v *plan.PhysicalIndexJoin) Executor {
+	outerExec := b.build(v.Children()[0])
+	if b.err != nil {
+		return nil
+	}
+	b.applyDepth++
+	innerExec := b.build(v.Children()[1])
+	b.applyDepth--
+	if b.err != nil {
+		return nil
+	}
+	targetTypes := make([]*types.FieldType, 0, len(v.OuterJoinKeys))
+	for i, outerKey := range v.OuterJoinKeys {
+		innerKey := v.InnerJoinKeys[i]
+		targetTypes = append(targetTypes, types.NewFieldType(types.MergeFieldType(outerKey.GetType().Tp, innerKey.GetType().Tp)))
+	}
+	return &IndexLookUpJoin{
+		baseExecutor:  newBaseExecutor(v.Schema(), b.ctx, outerExec),
+		innerExec:     innerExec,
+		outerKeys:     v.OuterJoinKeys,
+		innerKeys:     v.InnerJoinKeys,
+		outerFilter:   v.LeftConditions,
+		innerFilter:   v.RightConditions,
+		otherFilter:   v.OtherConditions,
+		outer:         v.Outer,
+		outerIsRight:  v.OuterIndex == 1,
+		defaultValues: v.DefaultValues,
+		targetTypes:   targetTypes,
+	}
+}
+
 func (b *executorBuilder) buildSemiJoin(v *plan.PhysicalHashSemiJoin) *HashSemiJoinExec {
 	var leftHashKey, rightHashKey []*expression.Column
 	var targetTypes []*types.FieldType
@@ -508,20 +649,31 @@ func (b *executorBuilder) buildSemiJoin(v *plan.PhysicalHashSemiJoin) *HashSemiJ
 		rightHashKey = append(rightHashKey, rn)
 		targetTypes = append(targetTypes, types.NewFieldType(types.MergeFieldType(ln.GetType().Tp, rn.GetType().Tp)))
 	}
+	var bigNAKey, smallNAKey []expression.Expression
+	var naTargetTypes []*types.FieldType
+	for _, eqCond := range v.NAEQConditions {
+		ln, rn := eqCond.GetArgs()[0], eqCond.GetArgs()[1]
+		bigNAKey = append(bigNAKey, ln)
+		smallNAKey = append(smallNAKey, rn)
+		naTargetTypes = append(naTargetTypes, types.NewFieldType(types.MergeFieldType(ln.GetType().Tp, rn.GetType().Tp)))
+	}
 	e := &HashSemiJoinExec{
-		schema:       v.Schema(),
-		otherFilter:  v.OtherConditions,
-		bigFilter:    v.LeftConditions,
-		smallFilter:  v.RightConditions,
-		bigExec:      b.build(v.Children()[0]),
-		smallExec:    b.build(v.Children()[1]),
-		prepared:     false,
-		ctx:          b.ctx,
-		bigHashKey:   leftHashKey,
-		smallHashKey: rightHashKey,
-		auxMode:      v.WithAux,
-		anti:         v.Anti,
-		targetTypes:  targetTypes,
+		schema:        v.Schema(),
+		otherFilter:   v.OtherConditions,
+		bigFilter:     v.LeftConditions,
+		smallFilter:   v.RightConditions,
+		bigExec:       b.build(v.Children()[0]),
+		smallExec:     b.build(v.Children()[1]),
+		prepared:      false,
+		ctx:           b.ctx,
+		bigHashKey:    leftHashKey,
+		smallHashKey:  rightHashKey,
+		auxMode:       v.WithAux,
+		anti:          v.Anti,
+		targetTypes:   targetTypes,
+		bigNAKey:      bigNAKey,
+		smallNAKey:    smallNAKey,
+		naTargetTypes: naTargetTypes,
 	}
 	return e
 }
@@ -545,6 +697,23 @@ func (b *executorBuilder) buildAggregation(v *plan.PhysicalAggregation) Executor
 	}
 }
 
+func (b *executorBuilder) buildWindow(v *plan.PhysicalWindow) Executor {
+	e := &WindowExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
+		sc:           b.ctx.GetSessionVars().StmtCtx,
+		WindowFuncs:  v.WindowFuncs,
+		PartitionBy:  v.PartitionBy,
+		OrderBy:      v.OrderBy,
+		aggFuncs:     make([]expression.AggregationFunction, len(v.WindowFuncs)),
+	}
+	for i, fun := range v.WindowFuncs {
+		if fun.IsAggregate() {
+			e.aggFuncs[i] = fun.NewAggFunction()
+		}
+	}
+	return e
+}
+
 func (b *executorBuilder) buildSelection(v *plan.Selection) Executor {
 	exec := &SelectionExec{
 		baseExecutor:   newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
@@ -597,13 +766,11 @@ func (b *executorBuilder) buildTableScan(v *plan.PhysicalTableScan) Executor {
 		return nil
 	}
 	table, _ := b.is.TableByID(v.Table.ID)
-	client := b.ctx.GetClient()
-	supportDesc := client.IsRequestTypeSupported(kv.ReqTypeSelect, kv.ReqSubTypeDesc)
 	e := &XSelectTableExec{
+		planID:      v.ID(),
 		tableInfo:   v.Table,
 		ctx:         b.ctx,
 		startTS:     startTS,
-		supportDesc: supportDesc,
 		asName:      v.TableAsName,
 		table:       table,
 		schema:      v.Schema(),
@@ -621,18 +788,41 @@ func (b *executorBuilder) buildTableScan(v *plan.PhysicalTableScan) Executor {
 	return e
 }
 
+func (b *executorBuilder) buildPointGet(v *plan.PointGet) Executor {
+	tbl, _ := b.is.TableByID(v.Table.ID)
+	columns := make([]*table.Column, 0, len(v.Columns))
+	for _, col := range v.Columns {
+		columns = append(columns, table.ToColumn(col))
+	}
+	e := &PointGetExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		table:        tbl,
+		asName:       v.TableAsName,
+		columns:      columns,
+		handles:      v.Handles,
+	}
+	if v.Index != nil {
+		for _, idx := range tbl.Indices() {
+			if idx.Meta().ID == v.Index.ID {
+				e.index = idx
+				break
+			}
+		}
+		e.indexValues = v.IndexValues
+	}
+	return e
+}
+
 func (b *executorBuilder) buildIndexScan(v *plan.PhysicalIndexScan) Executor {
 	startTS := b.getStartTS()
 	if b.err != nil {
 		return nil
 	}
 	table, _ := b.is.TableByID(v.Table.ID)
-	client := b.ctx.GetClient()
-	supportDesc := client.IsRequestTypeSupported(kv.ReqTypeIndex, kv.ReqSubTypeDesc)
 	e := &XSelectIndexExec{
+		planID:               v.ID(),
 		tableInfo:            v.Table,
 		ctx:                  b.ctx,
-		supportDesc:          supportDesc,
 		asName:               v.TableAsName,
 		table:                table,
 		singleReadMode:       !v.DoubleRead,
@@ -680,6 +870,7 @@ func (b *executorBuilder) buildSort(v *plan.Sort) Executor {
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
 		ByItems:      v.ByItems,
 		schema:       v.Schema(),
+		memQuota:     b.ctx.GetSessionVars().SortMemQuota,
 	}
 	if v.ExecLimit != nil {
 		return &TopnExec{
@@ -735,6 +926,8 @@ func (b *executorBuilder) buildNestedLoopJoin(v *plan.PhysicalHashJoin) *NestedL
 }
 
 func (b *executorBuilder) buildApply(v *plan.PhysicalApply) Executor {
+	b.applyDepth++
+	defer func() { b.applyDepth-- }()
 	var join joinExec
 	switch x := v.PhysicalJoin.(type) {
 	case *plan.PhysicalHashSemiJoin:
@@ -753,6 +946,16 @@ func (b *executorBuilder) buildApply(v *plan.PhysicalApply) Executor {
 		outerSchema: v.OuterSchema,
 		schema:      v.Schema(),
 	}
+	// The prefetched inner rows are read in another goroutine which changes the correlated columns, so we don't
+	// prefetch an apply inside another apply, or an apply whose join conditions depend on the correlated columns.
+	if nlj, ok := join.(*NestedLoopJoinExec); ok && b.applyDepth == 1 {
+		apply.prefetch = true
+		for _, cond := range nlj.OtherFilter {
+			if cond.IsCorrelated() {
+				apply.prefetch = false
+			}
+		}
+	}
 	return apply
 }
 
@@ -776,6 +979,7 @@ func (b *executorBuilder) buildUnion(v *plan.Union) Executor {
 	}
 	e := &UnionExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, srcs...),
+		concurrency:  b.ctx.GetSessionVars().UnionConcurrency,
 	}
 	return e
 }
@@ -898,6 +1102,7 @@ func (b *executorBuilder) buildTableReader(v *plan.PhysicalTableReader) Executor
 	ts := v.TablePlans[0].(*plan.PhysicalTableScan)
 	table, _ := b.is.TableByID(ts.Table.ID)
 	e := &TableReaderExecutor{
+		planID:    v.ID(),
 		ctx:       b.ctx,
 		schema:    v.Schema(),
 		dagPB:     dagReq,
@@ -913,8 +1118,32 @@ func (b *executorBuilder) buildTableReader(v *plan.PhysicalTableReader) Executor
 	for i := range v.Schema().Columns {
 		dagReq.OutputOffsets = append(dagReq.OutputOffsets, uint32(i))
 	}
+	if b.applyDepth > 0 {
+		return e
+	}
+	return b.shareTableReader(e)
+}
 
-	return e
+// shareTableReader wraps the table reader so that it shares the scan with the identical table readers in the plan.
+func (b *executorBuilder) shareTableReader(e *TableReaderExecutor) Executor {
+	key, err := sharedScanKey(e)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	if b.sharedScans == nil {
+		b.sharedScans = make(map[string]*sharedTableScan)
+	}
+	shared, ok := b.sharedScans[key]
+	if !ok {
+		shared = newSharedTableScan(e)
+		b.sharedScans[key] = shared
+	}
+	return &SharedTableReaderExecutor{
+		shared: shared,
+		idx:    shared.register(),
+		reader: e,
+	}
 }
 
 func (b *executorBuilder) buildIndexReader(v *plan.PhysicalIndexReader) Executor {
@@ -925,6 +1154,7 @@ func (b *executorBuilder) buildIndexReader(v *plan.PhysicalIndexReader) Executor
 	is := v.IndexPlans[0].(*plan.PhysicalIndexScan)
 	table, _ := b.is.TableByID(is.Table.ID)
 	e := &IndexReaderExecutor{
+		planID:    v.ID(),
 		ctx:       b.ctx,
 		schema:    v.Schema(),
 		dagPB:     dagReq,
@@ -962,6 +1192,7 @@ func (b *executorBuilder) buildIndexLookUpReader(v *plan.PhysicalIndexLookUpRead
 	}
 
 	e := &IndexLookUpExecutor{
+		planID:       v.ID(),
 		ctx:          b.ctx,
 		schema:       v.Schema(),
 		dagPB:        indexReq,
@@ -67,6 +67,8 @@ func (e *DDLExec) Next() (*Row, error) {
 		needWait = true
 	case *ast.CreateIndexStmt:
 		err = e.executeCreateIndex(x)
+	case *ast.CreateTriggerStmt:
+		err = e.executeCreateTrigger(x)
 	case *ast.DropDatabaseStmt:
 		err = e.executeDropDatabase(x)
 		needWait = true
@@ -75,6 +77,8 @@ func (e *DDLExec) Next() (*Row, error) {
 		needWait = true
 	case *ast.DropIndexStmt:
 		err = e.executeDropIndex(x)
+	case *ast.DropTriggerStmt:
+		err = e.executeDropTrigger(x)
 	case *ast.AlterTableStmt:
 		err = e.executeAlterTable(x)
 	case *ast.RenameTableStmt:
@@ -238,6 +242,43 @@ func (e *DDLExec) executeDropIndex(s *ast.DropIndexStmt) error {
 	return errors.Trace(err)
 }
 
+func (e *DDLExec) executeCreateTrigger(s *ast.CreateTriggerStmt) error {
+	if e.ctx.GetSessionVars().IgnoreTrigger {
+		return nil
+	}
+	t, err := e.is.TableByName(s.Table.Schema, s.Table.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// Check the OLD and NEW row references before storing the trigger,
+	// other names in the body are resolved when it fires.
+	_, err = resolveTrigger(s, t, s.Table.Schema)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	trigger := &model.TriggerInfo{
+		Name:      model.NewCIStr(s.Name),
+		Timing:    s.Timing,
+		Event:     s.Event,
+		Statement: s.Text(),
+		Body:      s.BodyText,
+	}
+	ti := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
+	err = sessionctx.GetDomain(e.ctx).DDL().CreateTrigger(e.ctx, ti, trigger)
+	return errors.Trace(err)
+}
+
+func (e *DDLExec) executeDropTrigger(s *ast.DropTriggerStmt) error {
+	if e.ctx.GetSessionVars().IgnoreTrigger {
+		return nil
+	}
+	err := sessionctx.GetDomain(e.ctx).DDL().DropTrigger(e.ctx, s.Schema, s.Name)
+	if infoschema.ErrTriggerNotExists.Equal(err) && s.IfExists {
+		err = nil
+	}
+	return errors.Trace(err)
+}
+
 func (e *DDLExec) executeAlterTable(s *ast.AlterTableStmt) error {
 	ti := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	err := sessionctx.GetDomain(e.ctx).DDL().AlterTable(e.ctx, ti, s.Specs)
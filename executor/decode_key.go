@@ -0,0 +1,133 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func init() {
+	// The expression package can't import the infoschema package because of the dependency cycle.
+	expression.DecodeKey = decodeKey
+}
+
+// decodedKey is the result of TIDB_DECODE_KEY. The names are empty if the table or the index doesn't
+// exist in the current schema.
+type decodedKey struct {
+	TableID   int64             `json:"table_id"`
+	DBName    string            `json:"db_name,omitempty"`
+	TableName string            `json:"table_name,omitempty"`
+	IndexID   int64             `json:"index_id,omitempty"`
+	IndexName string            `json:"index_name,omitempty"`
+	IndexVals map[string]string `json:"index_vals,omitempty"`
+	Handle    *int64            `json:"handle,omitempty"`
+}
+
+// decodeKey decodes a row key or an index key to JSON. The key is either the raw key written by the
+// transactions, or the memcomparable-encoded key used as the boundaries of the regions. The prefixes
+// of the keys, which are usually the boundaries of the regions, are decoded as well.
+func decodeKey(ctx context.Context, key []byte) (string, error) {
+	if remain, rawKey, err := codec.DecodeBytes(key); err == nil && len(remain) == 0 {
+		key = rawKey
+	}
+	tableID := tablecodec.DecodeTableID(key)
+	if tableID == 0 {
+		return "", errors.Errorf("invalid key %q", key)
+	}
+	result := &decodedKey{TableID: tableID}
+	is := GetInfoSchema(ctx)
+	var tblInfo *model.TableInfo
+	if tbl, ok := is.TableByID(tableID); ok {
+		tblInfo = tbl.Meta()
+		result.TableName = tblInfo.Name.O
+		if db, ok := is.SchemaByTableID(tableID); ok {
+			result.DBName = db.Name.O
+		}
+	}
+	if !bytes.Equal(key, tablecodec.EncodeTablePrefix(tableID)) {
+		_, indexID, isRecordKey, err := tablecodec.DecodeKeyHead(key)
+		if err != nil {
+			return "", errors.Trace(err)
+		}
+		if isRecordKey {
+			if !bytes.Equal(key, tablecodec.GenTableRecordPrefix(tableID)) {
+				handle, err := tablecodec.DecodeRowKey(key)
+				if err != nil {
+					return "", errors.Trace(err)
+				}
+				result.Handle = &handle
+			}
+		} else {
+			result.IndexID = indexID
+			if tblInfo != nil {
+				decodeIndexKey(ctx, key, tblInfo, result)
+			}
+		}
+	}
+	b, err := json.Marshal(result)
+	return string(b), errors.Trace(err)
+}
+
+// decodeIndexKey sets the index name and decodes the column values and the handle of the index key.
+// The values are omitted if the key is only a prefix of the index key.
+func decodeIndexKey(ctx context.Context, key []byte, tblInfo *model.TableInfo, result *decodedKey) {
+	var idxInfo *model.IndexInfo
+	for _, idx := range tblInfo.Indices {
+		if idx.ID == result.IndexID {
+			idxInfo = idx
+			break
+		}
+	}
+	if idxInfo == nil {
+		return
+	}
+	result.IndexName = idxInfo.Name.O
+	fts := make([]*types.FieldType, 0, len(idxInfo.Columns))
+	for _, ic := range idxInfo.Columns {
+		if ic.MultiValued != nil {
+			// The key contains an element of the JSON array.
+			fts = append(fts, &ic.MultiValued.Tp)
+			continue
+		}
+		fts = append(fts, &tblInfo.Columns[ic.Offset].FieldType)
+	}
+	values, handle, hasHandle, err := tablecodec.DecodeIndexKeyValues(key, fts, ctx.GetSessionVars().GetTimeZone())
+	if err != nil {
+		return
+	}
+	vals := make(map[string]string, len(values))
+	for i, v := range values {
+		str := "NULL"
+		if !v.IsNull() {
+			str, err = v.ToString()
+			if err != nil {
+				return
+			}
+		}
+		vals[idxInfo.Columns[i].Name.O] = str
+	}
+	result.IndexVals = vals
+	if hasHandle {
+		result.Handle = &handle
+	}
+}
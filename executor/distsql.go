@@ -32,6 +32,9 @@ import (
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/indexusage"
+	"github.com/pingcap/tidb/util/resourcegroup"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 	goctx "golang.org/x/net/context"
@@ -185,6 +188,20 @@ func indexRangesToKVRanges(sc *variable.StatementContext, tid, idxID int64, rang
 	return krs, nil
 }
 
+// indexColumnTypes returns the types of the index values, which are the types of the columns, or the
+// types of the array elements for the multi-valued key parts.
+func indexColumnTypes(t table.Table, index *model.IndexInfo) []*types.FieldType {
+	fieldTypes := make([]*types.FieldType, len(index.Columns))
+	for i, v := range index.Columns {
+		if v.MultiValued != nil {
+			fieldTypes[i] = &v.MultiValued.Tp
+			continue
+		}
+		fieldTypes[i] = &(t.Cols()[v.Offset].FieldType)
+	}
+	return fieldTypes
+}
+
 func convertIndexRangeTypes(sc *variable.StatementContext, ran *types.IndexRange, fieldTypes []*types.FieldType) error {
 	for i := range ran.LowVal {
 		if ran.LowVal[i].Kind() == types.KindMinNotNull || ran.LowVal[i].Kind() == types.KindMaxValue {
@@ -306,6 +323,22 @@ func closeAll(objs ...Closeable) error {
 	return errors.Trace(err)
 }
 
+// recordExecDetails adds the runtime statistics of the coprocessor requests sent by the plan
+// to the statement context, they are shown in the slow log and 'explain analyze'.
+func recordExecDetails(ctx context.Context, planID string, result distsql.SelectResult) {
+	if result == nil {
+		return
+	}
+	ctx.GetSessionVars().StmtCtx.MergeExecDetails(planID, result.ExecDetails())
+}
+
+// withStmtContext returns a copy of goCtx which carries the quota and the trace span of the statement to
+// the coprocessor requests, so they are limited by the resource group of the user and the session and traced by TRACE.
+func withStmtContext(ctx context.Context, goCtx goctx.Context) goctx.Context {
+	sc := ctx.GetSessionVars().StmtCtx
+	return tracing.ContextWithSpan(resourcegroup.WithStmtQuota(goCtx, sc.ResourceQuota), sc.TraceSpan)
+}
+
 // XSelectIndexExec represents the DistSQL select index executor.
 // There are two execution modes. One is single-read, in which case we only need to read index keys.
 // The other one is double-read, in which case we first do index request to get handles, we use each
@@ -329,11 +362,12 @@ func closeAll(objs ...Closeable) error {
 // After finishing the task, the workers send the task to a taskChan. At the outer most Executor.Next method,
 // we receive the finished task through taskChan, and return each row in that task until no more tasks to receive.
 type XSelectIndexExec struct {
+	// planID is used to record the runtime statistics of the coprocessor requests.
+	planID         string
 	tableInfo      *model.TableInfo
 	table          table.Table
 	asName         *model.CIStr
 	ctx            context.Context
-	supportDesc    bool
 	isMemDB        bool
 	singleReadMode bool
 
@@ -377,12 +411,15 @@ type XSelectIndexExec struct {
 	scanConcurrency int
 	execStart       time.Time
 	partialCount    int
+	// readRows is the number of the rows read by the index, it's recorded in the index usage.
+	readRows int64
 }
 
 // Open implements the Executor Open interface.
 func (e *XSelectIndexExec) Open() error {
 	e.returnedRows = 0
 	e.partialCount = 0
+	e.readRows = 0
 	return nil
 }
 
@@ -393,6 +430,8 @@ func (e *XSelectIndexExec) Schema() *expression.Schema {
 
 // Close implements Exec Close interface.
 func (e *XSelectIndexExec) Close() error {
+	recordExecDetails(e.ctx, e.planID, e.result)
+	indexusage.Record(e.tableInfo.ID, e.index.ID, e.readRows)
 	err := closeAll(e.result, e.partialResult)
 	e.result = nil
 	e.partialResult = nil
@@ -413,10 +452,17 @@ func (e *XSelectIndexExec) Next() (*Row, error) {
 		return nil, nil
 	}
 	e.returnedRows++
+	var row *Row
+	var err error
 	if e.singleReadMode {
-		return e.nextForSingleRead()
+		row, err = e.nextForSingleRead()
+	} else {
+		row, err = e.nextForDoubleRead()
 	}
-	return e.nextForDoubleRead()
+	if row != nil {
+		e.readRows++
+	}
+	return row, errors.Trace(err)
 }
 
 func (e *XSelectIndexExec) nextForSingleRead() (*Row, error) {
@@ -577,6 +623,7 @@ func (e *XSelectIndexExec) fetchHandles(idxResult distsql.SelectResult, ch chan<
 	defer func() {
 		close(ch)
 		close(workCh)
+		recordExecDetails(e.ctx, e.planID, idxResult)
 		idxResult.Close()
 	}()
 
@@ -636,16 +683,13 @@ func (e *XSelectIndexExec) doIndexRequest() (distsql.SelectResult, error) {
 		selIdxReq.Aggregates = e.aggFuncs
 		selIdxReq.GroupBy = e.byItems
 	}
-	fieldTypes := make([]*types.FieldType, len(e.index.Columns))
-	for i, v := range e.index.Columns {
-		fieldTypes[i] = &(e.table.Cols()[v.Offset].FieldType)
-	}
+	fieldTypes := indexColumnTypes(e.table, e.index)
 	sc := e.ctx.GetSessionVars().StmtCtx
 	keyRanges, err := indexRangesToKVRanges(sc, e.table.Meta().ID, e.index.ID, e.ranges, fieldTypes)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	return distsql.Select(e.ctx.GetClient(), e.ctx.GoCtx(), selIdxReq, keyRanges, e.scanConcurrency, !e.outOfOrder)
+	return distsql.Select(e.ctx.GetClient(), withStmtContext(e.ctx, e.ctx.GoCtx()), selIdxReq, keyRanges, e.scanConcurrency, !e.outOfOrder)
 }
 
 func (e *XSelectIndexExec) buildTableTasks(handles []int64) []*lookupTableTask {
@@ -704,17 +748,13 @@ func (e *XSelectIndexExec) executeTask(task *lookupTableTask) error {
 		return errors.Trace(err)
 	}
 	task.rows, err = e.extractRowsFromTableResult(e.table, tblResult)
+	recordExecDetails(e.ctx, e.planID, tblResult)
 	if err != nil {
 		return errors.Trace(err)
 	}
 	if !e.outOfOrder {
 		// Restore the index order.
-		sorter := &rowsSorter{order: task.indexOrder, rows: task.rows}
-		if e.desc && !e.supportDesc {
-			sort.Sort(sort.Reverse(sorter))
-		} else {
-			sort.Sort(sorter)
-		}
+		sort.Sort(&rowsSorter{order: task.indexOrder, rows: task.rows})
 	}
 	return nil
 }
@@ -792,7 +832,7 @@ func (e *XSelectIndexExec) doTableRequest(handles []int64) (distsql.SelectResult
 	keyRanges := tableHandlesToKVRanges(e.table.Meta().ID, handles)
 	// Use the table scan concurrency variable to do table request.
 	concurrency := e.ctx.GetSessionVars().DistSQLScanConcurrency
-	resp, err := distsql.Select(e.ctx.GetClient(), goctx.Background(), selTableReq, keyRanges, concurrency, false)
+	resp, err := distsql.Select(e.ctx.GetClient(), withStmtContext(e.ctx, goctx.Background()), selTableReq, keyRanges, concurrency, false)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -803,12 +843,13 @@ func (e *XSelectIndexExec) doTableRequest(handles []int64) (distsql.SelectResult
 // XSelectTableExec represents the DistSQL select table executor.
 // Its execution is pushed down to KV layer.
 type XSelectTableExec struct {
-	tableInfo   *model.TableInfo
-	table       table.Table
-	asName      *model.CIStr
-	ctx         context.Context
-	supportDesc bool
-	isMemDB     bool
+	// planID is used to record the runtime statistics of the coprocessor requests.
+	planID    string
+	tableInfo *model.TableInfo
+	table     table.Table
+	asName    *model.CIStr
+	ctx       context.Context
+	isMemDB   bool
 
 	// result returns one or more distsql.PartialResult and each PartialResult is returned by one region.
 	result        distsql.SelectResult
@@ -863,7 +904,7 @@ func (e *XSelectTableExec) doRequest() error {
 	}
 	if len(e.orderByList) > 0 {
 		selReq.OrderBy = e.orderByList
-	} else if e.supportDesc && e.desc {
+	} else if e.desc {
 		selReq.OrderBy = []*tipb.ByItem{{Desc: e.desc}}
 	}
 	selReq.Limit = e.limitCount
@@ -872,7 +913,7 @@ func (e *XSelectTableExec) doRequest() error {
 	selReq.GroupBy = e.byItems
 
 	kvRanges := tableRangesToKVRanges(e.table.Meta().ID, e.ranges)
-	e.result, err = distsql.Select(e.ctx.GetClient(), goctx.Background(), selReq, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder)
+	e.result, err = distsql.Select(e.ctx.GetClient(), withStmtContext(e.ctx, goctx.Background()), selReq, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -882,6 +923,7 @@ func (e *XSelectTableExec) doRequest() error {
 
 // Close implements the Executor Close interface.
 func (e *XSelectTableExec) Close() error {
+	recordExecDetails(e.ctx, e.planID, e.result)
 	err := closeAll(e.result, e.partialResult)
 	if err != nil {
 		return errors.Trace(err)
@@ -0,0 +1,278 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/event"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// eventSchedule is an evaluated ast.EventSchedule, all the times are in UTC.
+type eventSchedule struct {
+	executeAt     time.Time
+	intervalValue int64
+	intervalField string
+	starts        time.Time
+	ends          time.Time
+}
+
+// inThePast checks whether the event will never execute after now.
+func (s *eventSchedule) inThePast(now time.Time) bool {
+	if s.intervalField == "" {
+		return s.executeAt.Before(now)
+	}
+	return !s.ends.IsZero() && s.ends.Before(now)
+}
+
+func (s *eventSchedule) assignments() string {
+	if s.intervalField == "" {
+		return fmt.Sprintf("execute_at = '%s', interval_value = NULL, interval_field = NULL, starts = NULL, ends = NULL",
+			s.executeAt.Format(event.TimeFormat))
+	}
+	ends := "NULL"
+	if !s.ends.IsZero() {
+		ends = "'" + s.ends.Format(event.TimeFormat) + "'"
+	}
+	return fmt.Sprintf("execute_at = NULL, interval_value = %d, interval_field = '%s', starts = '%s', ends = %s",
+		s.intervalValue, s.intervalField, s.starts.Format(event.TimeFormat), ends)
+}
+
+func (e *SimpleExec) executeCreateEvent(s *ast.CreateEventStmt) error {
+	dbName, err := e.eventSchema(s.Schema)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	exists, err := eventExists(e.ctx, dbName, s.Name.L)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if exists {
+		err = ErrEventExists.GenByArgs(s.Name.O)
+		if s.IfNotExists {
+			e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
+			return nil
+		}
+		return err
+	}
+	now := time.Now().UTC()
+	schedule, err := e.evalEventSchedule(s.Schedule, now)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	status := "ENABLED"
+	if s.Status == ast.EventStatusDisable {
+		status = "DISABLED"
+	}
+	onCompletion := "DROP"
+	if s.OnCompletion == ast.EventCompletionPreserve {
+		onCompletion = "PRESERVE"
+	}
+	if schedule.inThePast(now) {
+		// Like MySQL, an event which will never execute is dropped at once, or disabled if it is preserved.
+		if onCompletion == "DROP" {
+			e.ctx.GetSessionVars().StmtCtx.AppendWarning(ErrEventCreateInPast)
+			return nil
+		}
+		e.ctx.GetSessionVars().StmtCtx.AppendWarning(ErrEventExecTimeInPast)
+		status = "DISABLED"
+	}
+	vars := e.ctx.GetSessionVars()
+	sql := fmt.Sprintf(`INSERT INTO %s.%s SET db = '%s', name = '%s', body = '%s', definer = '%s', %s,
+		created = '%s', modified = '%s', status = '%s', on_completion = '%s', time_zone = '%s', comment = '%s'`,
+		mysql.SystemDB, mysql.EventTable, event.QuoteString(dbName), event.QuoteString(s.Name.L),
+		event.QuoteString(s.BodyText), event.QuoteString(vars.User),
+		schedule.assignments(), now.Format(event.TimeFormat), now.Format(event.TimeFormat), status, onCompletion,
+		event.QuoteString(vars.Systems["time_zone"]), event.QuoteString(s.Comment))
+	_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+	return errors.Trace(err)
+}
+
+func (e *SimpleExec) executeAlterEvent(s *ast.AlterEventStmt) error {
+	dbName, err := e.eventSchema(s.Schema)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	exists, err := eventExists(e.ctx, dbName, s.Name.L)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !exists {
+		return ErrEventNotExists.GenByArgs(s.Name.O)
+	}
+	now := time.Now().UTC()
+	vars := e.ctx.GetSessionVars()
+	// ALTER EVENT makes the current user the definer, like MySQL.
+	sets := []string{
+		fmt.Sprintf("modified = '%s'", now.Format(event.TimeFormat)),
+		fmt.Sprintf("definer = '%s'", event.QuoteString(vars.User)),
+	}
+	if s.Schedule != nil {
+		schedule, err1 := e.evalEventSchedule(s.Schedule, now)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		// The new schedule starts over.
+		sets = append(sets, schedule.assignments(), "last_executed = NULL",
+			fmt.Sprintf("time_zone = '%s'", event.QuoteString(vars.Systems["time_zone"])))
+		if schedule.inThePast(now) {
+			vars.StmtCtx.AppendWarning(ErrEventExecTimeInPast)
+			s.Status = ast.EventStatusDisable
+		}
+	}
+	switch s.Status {
+	case ast.EventStatusEnable:
+		sets = append(sets, "status = 'ENABLED'")
+	case ast.EventStatusDisable:
+		sets = append(sets, "status = 'DISABLED'")
+	}
+	switch s.OnCompletion {
+	case ast.EventCompletionPreserve:
+		sets = append(sets, "on_completion = 'PRESERVE'")
+	case ast.EventCompletionNotPreserve:
+		sets = append(sets, "on_completion = 'DROP'")
+	}
+	if s.Body != nil {
+		sets = append(sets, fmt.Sprintf("body = '%s'", event.QuoteString(s.BodyText)))
+	}
+	sql := fmt.Sprintf(`UPDATE %s.%s SET %s WHERE db = '%s' AND name = '%s'`,
+		mysql.SystemDB, mysql.EventTable, strings.Join(sets, ", "), event.QuoteString(dbName), event.QuoteString(s.Name.L))
+	_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+	return errors.Trace(err)
+}
+
+func (e *SimpleExec) executeDropEvent(s *ast.DropEventStmt) error {
+	dbName, err := e.eventSchema(s.Schema)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	exists, err := eventExists(e.ctx, dbName, s.Name.L)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !exists {
+		err = ErrEventNotExists.GenByArgs(s.Name.O)
+		if s.IfExists {
+			e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
+			return nil
+		}
+		return err
+	}
+	sql := fmt.Sprintf(`DELETE FROM %s.%s WHERE db = '%s' AND name = '%s'`,
+		mysql.SystemDB, mysql.EventTable, event.QuoteString(dbName), event.QuoteString(s.Name.L))
+	_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+	return errors.Trace(err)
+}
+
+// eventSchema returns the lower case name of the schema of an event, which is the current database by default.
+func (e *SimpleExec) eventSchema(schema model.CIStr) (string, error) {
+	if schema.O == "" {
+		schema = model.NewCIStr(e.ctx.GetSessionVars().CurrentDB)
+	}
+	if schema.O == "" {
+		return "", ErrNoDB
+	}
+	if !e.is.SchemaExists(schema) {
+		return "", infoschema.ErrDatabaseNotExists.GenByArgs(schema)
+	}
+	return schema.L, nil
+}
+
+func (e *SimpleExec) evalEventSchedule(s *ast.EventSchedule, now time.Time) (*eventSchedule, error) {
+	schedule := &eventSchedule{}
+	var err error
+	if s.At != nil {
+		schedule.executeAt, err = e.evalEventTime(s.At, "AT")
+		return schedule, errors.Trace(err)
+	}
+	unit := strings.ToUpper(s.Unit)
+	if !event.IsSupportedUnit(unit) {
+		return nil, ErrNotSupportedYet.GenByArgs(unit)
+	}
+	d, err := expression.EvalAstExpr(s.Every, e.ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if d.IsNull() {
+		return nil, ErrWrongValue.GenByArgs("INTERVAL", "NULL")
+	}
+	n, err := d.ToInt64(e.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !event.IsValidInterval(n, unit) {
+		return nil, ErrEventInterval
+	}
+	schedule.intervalValue, schedule.intervalField = n, unit
+	// A recurring event starts at once if STARTS is not specified.
+	schedule.starts = now.Truncate(time.Second)
+	if s.Starts != nil {
+		schedule.starts, err = e.evalEventTime(s.Starts, "STARTS")
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if s.Ends != nil {
+		schedule.ends, err = e.evalEventTime(s.Ends, "ENDS")
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if schedule.ends.Before(schedule.starts) {
+			return nil, ErrEventEndsBeforeStarts
+		}
+	}
+	return schedule, nil
+}
+
+// evalEventTime evaluates a time of the schedule in the session time zone and converts it to UTC.
+func (e *SimpleExec) evalEventTime(expr ast.ExprNode, name string) (time.Time, error) {
+	d, err := expression.EvalAstExpr(expr, e.ctx)
+	if err != nil {
+		return time.Time{}, errors.Trace(err)
+	}
+	if d.IsNull() {
+		return time.Time{}, ErrWrongValue.GenByArgs(name, "NULL")
+	}
+	vars := e.ctx.GetSessionVars()
+	d, err = d.ConvertTo(vars.StmtCtx, types.NewFieldType(mysql.TypeDatetime))
+	if err != nil {
+		return time.Time{}, errors.Trace(err)
+	}
+	t, err := d.GetMysqlTime().Time.GoTime(vars.GetTimeZone())
+	if err != nil || d.GetMysqlTime().IsZero() {
+		return time.Time{}, ErrWrongValue.GenByArgs(name, d.GetMysqlTime().String())
+	}
+	return t.UTC(), nil
+}
+
+func eventExists(ctx context.Context, dbName, name string) (bool, error) {
+	sql := fmt.Sprintf(`SELECT name FROM %s.%s WHERE db = '%s' AND name = '%s'`,
+		mysql.SystemDB, mysql.EventTable, event.QuoteString(dbName), event.QuoteString(name))
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return len(rows) > 0, nil
+}
@@ -14,12 +14,17 @@
 package executor
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/ddl"
+	"github.com/pingcap/tidb/distsql"
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/inspectkv"
@@ -27,15 +32,21 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/ranger"
+	"github.com/pingcap/tidb/util/resourcegroup"
+	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/types"
 )
 
 var (
 	_ Executor = &CheckTableExec{}
+	_ Executor = &ReloadExprPushdownBlacklistExec{}
+	_ Executor = &ReloadResourceGroupsExec{}
+	_ Executor = &ReloadConfigExec{}
 	_ Executor = &DummyScanExec{}
 	_ Executor = &ExistsExec{}
 	_ Executor = &HashAggExec{}
@@ -45,6 +56,9 @@ var (
 	_ Executor = &SelectionExec{}
 	_ Executor = &SelectLockExec{}
 	_ Executor = &ShowDDLExec{}
+	_ Executor = &ShowDDLOwnerExec{}
+	_ Executor = &TransferDDLOwnerExec{}
+	_ Executor = &ShowSlowExec{}
 	_ Executor = &SortExec{}
 	_ Executor = &StreamAggExec{}
 	_ Executor = &TableDualExec{}
@@ -55,35 +69,65 @@ var (
 
 // Error instances.
 var (
-	ErrUnknownPlan          = terror.ClassExecutor.New(codeUnknownPlan, "Unknown plan")
-	ErrPrepareMulti         = terror.ClassExecutor.New(codePrepareMulti, "Can not prepare multiple statements")
-	ErrStmtNotFound         = terror.ClassExecutor.New(codeStmtNotFound, "Prepared statement not found")
-	ErrSchemaChanged        = terror.ClassExecutor.New(codeSchemaChanged, "Schema has changed")
-	ErrWrongParamCount      = terror.ClassExecutor.New(codeWrongParamCount, "Wrong parameter count")
-	ErrRowKeyCount          = terror.ClassExecutor.New(codeRowKeyCount, "Wrong row key entry count")
-	ErrPrepareDDL           = terror.ClassExecutor.New(codePrepareDDL, "Can not prepare DDL statements")
-	ErrPasswordNoMatch      = terror.ClassExecutor.New(CodePasswordNoMatch, "Can't find any matching row in the user table")
-	ErrResultIsEmpty        = terror.ClassExecutor.New(codeResultIsEmpty, "result is empty")
-	ErrBuildExecutor        = terror.ClassExecutor.New(codeErrBuildExec, "Failed to build executor")
-	ErrBatchInsertFail      = terror.ClassExecutor.New(codeBatchInsertFail, "Batch insert failed, please clean the table and try again.")
-	ErrWrongValueCountOnRow = terror.ClassExecutor.New(codeWrongValueCountOnRow, "Column count doesn't match value count at row %d")
+	ErrUnknownPlan           = terror.ClassExecutor.New(codeUnknownPlan, "Unknown plan")
+	ErrPrepareMulti          = terror.ClassExecutor.New(codePrepareMulti, "Can not prepare multiple statements")
+	ErrStmtNotFound          = terror.ClassExecutor.New(codeStmtNotFound, "Prepared statement not found")
+	ErrSchemaChanged         = terror.ClassExecutor.New(codeSchemaChanged, "Schema has changed")
+	ErrWrongParamCount       = terror.ClassExecutor.New(codeWrongParamCount, "Wrong parameter count")
+	ErrRowKeyCount           = terror.ClassExecutor.New(codeRowKeyCount, "Wrong row key entry count")
+	ErrPrepareDDL            = terror.ClassExecutor.New(codePrepareDDL, "Can not prepare DDL statements")
+	ErrPasswordNoMatch       = terror.ClassExecutor.New(CodePasswordNoMatch, "Can't find any matching row in the user table")
+	ErrResultIsEmpty         = terror.ClassExecutor.New(codeResultIsEmpty, "result is empty")
+	ErrBuildExecutor         = terror.ClassExecutor.New(codeErrBuildExec, "Failed to build executor")
+	ErrBatchInsertFail       = terror.ClassExecutor.New(codeBatchInsertFail, "Batch insert failed, please clean the table and try again.")
+	ErrWrongValueCountOnRow  = terror.ClassExecutor.New(codeWrongValueCountOnRow, "Column count doesn't match value count at row %d")
+	ErrTrgCantChangeRow      = terror.ClassExecutor.New(codeTrgCantChangeRow, "Updating of %s row is not allowed in %strigger")
+	ErrTrgNoSuchRow          = terror.ClassExecutor.New(codeTrgNoSuchRow, "There is no %s row in %s trigger")
+	ErrCantUpdateUsedTable   = terror.ClassExecutor.New(codeCantUpdateUsedTable, "Can't update table '%s' in stored function/trigger because it is already used by statement which invoked this stored function/trigger.")
+	ErrNoDB                  = terror.ClassExecutor.New(codeNoDB, "No database selected")
+	ErrWrongValue            = terror.ClassExecutor.New(codeWrongValue, "Incorrect %s value: '%s'")
+	ErrNotSupportedYet       = terror.ClassExecutor.New(codeNotSupportedYet, "This version of TiDB doesn't yet support '%s'")
+	ErrEventExists           = terror.ClassExecutor.New(codeEventExists, "Event '%s' already exists")
+	ErrEventNotExists        = terror.ClassExecutor.New(codeEventNotExists, "Unknown event '%s'")
+	ErrEventInterval         = terror.ClassExecutor.New(codeEventInterval, "INTERVAL is either not positive or too big")
+	ErrEventEndsBeforeStarts = terror.ClassExecutor.New(codeEventEndsBeforeStarts, "ENDS is either invalid or before STARTS")
+	ErrEventExecTimeInPast   = terror.ClassExecutor.New(codeEventExecTimeInPast, "Event execution time is in the past. Event has been disabled")
+	ErrEventCreateInPast     = terror.ClassExecutor.New(codeEventCreateInPast, "Event execution time is in the past and ON COMPLETION NOT PRESERVE is set. The event was dropped immediately after creation.")
+	ErrRowIsReferenced       = terror.ClassExecutor.New(codeRowIsReferenced, mysql.MySQLErrName[mysql.ErrRowIsReferenced2])
+	ErrFKDepthExceeded       = terror.ClassExecutor.New(codeFKDepthExceeded, mysql.MySQLErrName[mysql.ErrFkDepthExceeded])
+	ErrWarnTooFewRecords     = terror.ClassExecutor.New(codeWarnTooFewRecords, "Row %d doesn't contain data for all columns")
 )
 
 // Error codes.
 const (
-	codeUnknownPlan          terror.ErrCode = 1
-	codePrepareMulti         terror.ErrCode = 2
-	codeStmtNotFound         terror.ErrCode = 3
-	codeSchemaChanged        terror.ErrCode = 4
-	codeWrongParamCount      terror.ErrCode = 5
-	codeRowKeyCount          terror.ErrCode = 6
-	codePrepareDDL           terror.ErrCode = 7
-	codeResultIsEmpty        terror.ErrCode = 8
-	codeErrBuildExec         terror.ErrCode = 9
-	codeBatchInsertFail      terror.ErrCode = 10
-	CodePasswordNoMatch      terror.ErrCode = 1133 // MySQL error code
-	CodeCannotUser           terror.ErrCode = 1396 // MySQL error code
-	codeWrongValueCountOnRow terror.ErrCode = 1136 // MySQL error code
+	codeUnknownPlan           terror.ErrCode = 1
+	codePrepareMulti          terror.ErrCode = 2
+	codeStmtNotFound          terror.ErrCode = 3
+	codeSchemaChanged         terror.ErrCode = 4
+	codeWrongParamCount       terror.ErrCode = 5
+	codeRowKeyCount           terror.ErrCode = 6
+	codePrepareDDL            terror.ErrCode = 7
+	codeResultIsEmpty         terror.ErrCode = 8
+	codeErrBuildExec          terror.ErrCode = 9
+	codeBatchInsertFail       terror.ErrCode = 10
+	CodePasswordNoMatch       terror.ErrCode = 1133 // MySQL error code
+	CodeCannotUser            terror.ErrCode = 1396 // MySQL error code
+	codeWrongValueCountOnRow  terror.ErrCode = 1136 // MySQL error code
+	codeTrgCantChangeRow      terror.ErrCode = 1362 // MySQL error code
+	codeTrgNoSuchRow          terror.ErrCode = 1363 // MySQL error code
+	codeCantUpdateUsedTable   terror.ErrCode = 1442 // MySQL error code
+	codeNoDB                  terror.ErrCode = 1046 // MySQL error code
+	codeWrongValue            terror.ErrCode = 1525 // MySQL error code
+	codeNotSupportedYet       terror.ErrCode = 1235 // MySQL error code
+	codeEventExists           terror.ErrCode = 1537 // MySQL error code
+	codeEventNotExists        terror.ErrCode = 1539 // MySQL error code
+	codeEventInterval         terror.ErrCode = 1542 // MySQL error code
+	codeEventEndsBeforeStarts terror.ErrCode = 1543 // MySQL error code
+	codeEventExecTimeInPast   terror.ErrCode = 1544 // MySQL error code
+	codeEventCreateInPast     terror.ErrCode = 1588 // MySQL error code
+	codeRowIsReferenced       terror.ErrCode = 1451 // MySQL error code
+	codeFKDepthExceeded       terror.ErrCode = 3008 // MySQL error code
+	codeWarnTooFewRecords     terror.ErrCode = 1261 // MySQL error code
 )
 
 // Row represents a result set row, it may be returned from a table, a join, or a projection.
@@ -201,6 +245,128 @@ func (e *ShowDDLExec) Next() (*Row, error) {
 	return row, nil
 }
 
+// ShowDDLOwnerExec represents the executor showing the owners of the DDL jobs and the background jobs.
+// It is built from the "admin show ddl owner" statement.
+type ShowDDLOwnerExec struct {
+	baseExecutor
+
+	infos  []*ddl.OwnerInfo
+	cursor int
+	done   bool
+}
+
+// Next implements the Executor Next interface.
+func (e *ShowDDLOwnerExec) Next() (*Row, error) {
+	if !e.done {
+		e.done = true
+		infos, err := sessionctx.GetDomain(e.ctx).DDL().OwnerInfo()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		e.infos = infos
+	}
+	if e.cursor >= len(e.infos) {
+		return nil, nil
+	}
+	info := e.infos[e.cursor]
+	e.cursor++
+	var lastUpdate interface{}
+	if !info.LastUpdate.IsZero() {
+		lastUpdate = types.Time{
+			Time: types.FromGoTime(info.LastUpdate.In(e.ctx.GetSessionVars().GetTimeZone())),
+			Type: mysql.TypeDatetime,
+			Fsp:  types.MaxFsp,
+		}
+	}
+	row := &Row{Data: types.MakeDatums(
+		info.Type,
+		info.OwnerID,
+		info.ServerID,
+		lastUpdate,
+		info.Expired,
+		info.QueueLen,
+	)}
+	return row, nil
+}
+
+// isDDLOwner checks whether the server is the owner of the DDL jobs and the ownership isn't expired,
+// it's the implementation of TIDB_IS_DDL_OWNER.
+func isDDLOwner(ctx context.Context) (bool, error) {
+	infos, err := sessionctx.GetDomain(ctx).DDL().OwnerInfo()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	for _, info := range infos {
+		if info.Type == "ddl" {
+			return info.OwnerID == info.ServerID && !info.Expired, nil
+		}
+	}
+	return false, nil
+}
+
+// TransferDDLOwnerExec represents the executor transferring the ownership of the DDL jobs and the background jobs.
+// It is built from the "admin transfer ddl owner" and "admin resign ddl owner" statements.
+type TransferDDLOwnerExec struct {
+	baseExecutor
+
+	Target string
+	done   bool
+}
+
+// Next implements the Executor Next interface.
+func (e *TransferDDLOwnerExec) Next() (*Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	return nil, errors.Trace(sessionctx.GetDomain(e.ctx).DDL().TransferOwner(e.Target))
+}
+
+// ShowSlowExec represents the executor showing the slow queries kept in memory.
+// It is built from the "admin show slow" statement.
+type ShowSlowExec struct {
+	baseExecutor
+
+	ShowSlow *ast.ShowSlow
+	result   []*domain.SlowQueryInfo
+	cursor   int
+	done     bool
+}
+
+// Next implements the Executor Next interface.
+func (e *ShowSlowExec) Next() (*Row, error) {
+	if !e.done {
+		e.result = sessionctx.GetDomain(e.ctx).ShowSlowQuery(e.ShowSlow)
+		e.done = true
+	}
+	if e.cursor >= len(e.result) {
+		return nil, nil
+	}
+	slow := e.result[e.cursor]
+	e.cursor++
+	start := types.Time{
+		Time: types.FromGoTime(slow.Start.In(e.ctx.GetSessionVars().GetTimeZone())),
+		Type: mysql.TypeTimestamp,
+		Fsp:  types.MaxFsp,
+	}
+	row := &Row{Data: types.MakeDatums(
+		slow.SQL,
+		start,
+		types.Duration{Duration: slow.Duration, Fsp: types.MaxFsp},
+		slow.Succ,
+		slow.ConnID,
+		slow.TxnTS,
+		slow.User,
+		slow.DB,
+		slow.Internal,
+	)}
+	return row, nil
+}
+
+// checkTableBucketWidth is the width of the handle ranges whose digests are compared by the pushed down
+// consistency check. Only the entries in the mismatching ranges are read back.
+var checkTableBucketWidth int64 = 1 << 16
+
 // CheckTableExec represents a check table executor.
 // It is built from the "admin check table" statement, and it checks if the
 // index matches the records in the table.
@@ -227,11 +393,20 @@ func (e *CheckTableExec) Next() (*Row, error) {
 			return nil, errors.Trace(err)
 		}
 		for _, idx := range tb.Indices() {
-			txn := e.ctx.Txn()
-			err = inspectkv.CompareIndexData(txn, tb, idx)
+			if idx.Meta().IsMultiValued() {
+				// The entries of a multi-valued index are the elements of the JSON values, which can't be
+				// compared with the records.
+				continue
+			}
+			err = e.checkIndex(tb, idx)
 			if err != nil {
 				return nil, errors.Errorf("%v err:%v", t.Name, err)
 			}
+			if idx.Meta().Clustered {
+				if err = inspectkv.CompareClusteredRows(e.ctx, tb, idx); err != nil {
+					return nil, errors.Errorf("%v err:%v", t.Name, err)
+				}
+			}
 		}
 	}
 	e.done = true
@@ -239,11 +414,131 @@ func (e *CheckTableExec) Next() (*Row, error) {
 	return nil, nil
 }
 
+// checkIndex checks if the index matches the records. If the storage supports the checksum request, the
+// consistency check is pushed down, unless the table is written by the current transaction.
+func (e *CheckTableExec) checkIndex(t table.Table, idx table.Index) error {
+	txn := e.ctx.Txn()
+	client := e.ctx.GetClient()
+	sessVars := e.ctx.GetSessionVars()
+	if !client.IsRequestTypeSupported(kv.ReqTypeChecksum, kv.ReqSubTypeBasic) || sessVars.TxnCtx.HasWrittenTable(t.Meta().ID) {
+		return inspectkv.CompareIndexData(txn, t, idx)
+	}
+	req := distsql.NewChecksumRequest(t.Meta(), idx.Meta(), txn.StartTS(), false, checkTableBucketWidth)
+	req.TimeZoneOffset = timeZoneOffset(e.ctx)
+	err := inspectkv.CompareIndexDataByChecksum(e.ctx.GoCtx(), client, t, idx, req, sessVars.DistSQLScanConcurrency)
+	return errors.Trace(err)
+}
+
 // Close implements plan.Plan Close interface.
 func (e *CheckTableExec) Close() error {
 	return nil
 }
 
+// ReloadExprPushdownBlacklistExec represents a reload expression pushdown blacklist executor.
+// It is built from the "admin reload expr_pushdown_blacklist" statement.
+type ReloadExprPushdownBlacklistExec struct {
+	baseExecutor
+
+	done bool
+}
+
+// Next implements the Executor Next interface.
+func (e *ReloadExprPushdownBlacklistExec) Next() (*Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	return nil, errors.Trace(LoadExprPushdownBlacklist(e.ctx))
+}
+
+// LoadExprPushdownBlacklist loads the names of the functions that can't be pushed down to the storage
+// from the system table, and replaces the blacklist used in building the coprocessor requests.
+func LoadExprPushdownBlacklist(ctx context.Context) error {
+	sql := fmt.Sprintf("SELECT name FROM %s.%s", mysql.SystemDB, mysql.ExprPushdownBlacklistTable)
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Data[0].GetString())
+	}
+	expression.SetExprPushdownBlacklist(names)
+	return nil
+}
+
+// ReloadResourceGroupsExec represents a reload resource groups executor.
+// It is built from the "admin reload resource_groups" statement.
+type ReloadResourceGroupsExec struct {
+	baseExecutor
+
+	done bool
+}
+
+// Next implements the Executor Next interface.
+func (e *ReloadResourceGroupsExec) Next() (*Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	return nil, errors.Trace(LoadResourceGroups(e.ctx))
+}
+
+// LoadResourceGroups loads the resource groups and the assignments of the users from the system tables,
+// and replaces the resource groups used in limiting the statements.
+func LoadResourceGroups(ctx context.Context) error {
+	sql := fmt.Sprintf("SELECT name, max_concurrent_stmts, mem_quota, cop_request_rate FROM %s.%s",
+		mysql.SystemDB, mysql.ResourceGroupTable)
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	groups := make([]*resourcegroup.Group, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, resourcegroup.NewGroup(row.Data[0].GetString(), row.Data[1].GetInt64(),
+			row.Data[2].GetInt64(), row.Data[3].GetFloat64()))
+	}
+	sql = fmt.Sprintf("SELECT user, resource_group FROM %s.%s", mysql.SystemDB, mysql.UserResourceGroupTable)
+	rows, _, err = ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	users := make(map[string]string, len(rows))
+	for _, row := range rows {
+		users[row.Data[0].GetString()] = row.Data[1].GetString()
+	}
+	resourcegroup.Set(groups, users)
+	return nil
+}
+
+// ReloadConfigExec represents a reload config executor, it returns the keys changed in the configuration file.
+// It is built from the "admin reload config" statement.
+type ReloadConfigExec struct {
+	baseExecutor
+
+	changes []config.Change
+	cursor  int
+	done    bool
+}
+
+// Next implements the Executor Next interface.
+func (e *ReloadConfigExec) Next() (*Row, error) {
+	if !e.done {
+		e.done = true
+		changes, err := config.ReloadServerConfig()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		e.changes = changes
+	}
+	if e.cursor >= len(e.changes) {
+		return nil, nil
+	}
+	change := e.changes[e.cursor]
+	e.cursor++
+	return &Row{Data: types.MakeDatums(change.Key, change.OldValue, change.NewValue, change.Reloaded)}, nil
+}
+
 // SelectLockExec represents a select lock executor.
 // It is built from the "SELECT .. FOR UPDATE" or the "SELECT .. LOCK IN SHARE MODE" statement.
 // For "SELECT .. FOR UPDATE" statement, it locks every row key from source Executor.
@@ -322,6 +617,8 @@ func (e *LimitExec) Open() error {
 }
 
 func init() {
+	// The expression package can't import the domain package because of the dependency cycle.
+	expression.IsDDLOwner = isDDLOwner
 	// While doing optimization in the plan package, we need to execute uncorrelated subquery,
 	// but the plan package cannot import the executor package because of the dependency cycle.
 	// So we assign a function implemented in the executor package to the plan package to avoid the dependency cycle.
@@ -351,9 +648,24 @@ func init() {
 		}
 	}
 	tableMySQLErrCodes := map[terror.ErrCode]uint16{
-		CodeCannotUser:           mysql.ErrCannotUser,
-		CodePasswordNoMatch:      mysql.ErrPasswordNoMatch,
-		codeWrongValueCountOnRow: mysql.ErrWrongValueCountOnRow,
+		CodeCannotUser:            mysql.ErrCannotUser,
+		CodePasswordNoMatch:       mysql.ErrPasswordNoMatch,
+		codeWrongValueCountOnRow:  mysql.ErrWrongValueCountOnRow,
+		codeTrgCantChangeRow:      mysql.ErrTrgCantChangeRow,
+		codeTrgNoSuchRow:          mysql.ErrTrgNoSuchRowInTrg,
+		codeCantUpdateUsedTable:   mysql.ErrCantUpdateUsedTableInSfOrTrg,
+		codeNoDB:                  mysql.ErrNoDB,
+		codeWrongValue:            mysql.ErrWrongValue,
+		codeNotSupportedYet:       mysql.ErrNotSupportedYet,
+		codeEventExists:           mysql.ErrEventAlreadyExists,
+		codeEventNotExists:        mysql.ErrEventDoesNotExist,
+		codeEventInterval:         mysql.ErrEventIntervalNotPositiveOrTooBig,
+		codeEventEndsBeforeStarts: mysql.ErrEventEndsBeforeStarts,
+		codeEventExecTimeInPast:   mysql.ErrEventExecTimeInThePast,
+		codeEventCreateInPast:     mysql.ErrEventCannotCreateInThePast,
+		codeRowIsReferenced:       mysql.ErrRowIsReferenced2,
+		codeFKDepthExceeded:       mysql.ErrFkDepthExceeded,
+		codeWarnTooFewRecords:     mysql.ErrWarnTooFewRecords,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExecutor] = tableMySQLErrCodes
 }
@@ -705,12 +1017,17 @@ func (e *MaxOneRowExec) Next() (*Row, error) {
 }
 
 // UnionExec represents union executor.
-// UnionExec has multiple source Executors, it executes them sequentially, and do conversion to the same type
-// as source Executors may has different field type, we need to do conversion.
+// UnionExec has multiple source Executors, it executes them in parallel by a pool of workers, and do conversion
+// to the same type as source Executors may has different field type, we need to do conversion.
 type UnionExec struct {
 	baseExecutor
 
+	// concurrency is the max number of the workers, each worker executes the sources one by one.
+	concurrency int
+
 	finished atomic.Value
+	// childCh holds the indexes of the sources not executed yet.
+	childCh  chan int
 	resultCh chan *execResult
 	rows     []*Row
 	cursor   int
@@ -734,8 +1051,17 @@ func (e *UnionExec) waitAllFinished() {
 	close(e.closedCh)
 }
 
-func (e *UnionExec) fetchData(idx int) {
+func (e *UnionExec) runWorker() {
 	defer e.wg.Done()
+	for idx := range e.childCh {
+		if e.finished.Load().(bool) {
+			return
+		}
+		e.fetchData(idx)
+	}
+}
+
+func (e *UnionExec) fetchData(idx int) {
 	for {
 		result := &execResult{
 			rows: make([]*Row, 0, batchSize),
@@ -758,7 +1084,7 @@ func (e *UnionExec) fetchData(idx int) {
 				}
 				return
 			}
-			// TODO: Add cast function in plan building phase.
+			// The columns of the branches are cast in plan building phase except the null ones, convert them here.
 			for j := range row.Data {
 				col := e.schema.Columns[j]
 				val, err := row.Data[j].ConvertTo(e.ctx.GetSessionVars().StmtCtx, col.RetType)
@@ -779,6 +1105,7 @@ func (e *UnionExec) fetchData(idx int) {
 // Open implements the Executor Open interface.
 func (e *UnionExec) Open() error {
 	e.finished.Store(false)
+	e.childCh = make(chan int, len(e.children))
 	e.resultCh = make(chan *execResult, len(e.children))
 	e.closedCh = make(chan struct{})
 	e.cursor = 0
@@ -788,8 +1115,19 @@ func (e *UnionExec) Open() error {
 		if err != nil {
 			break
 		}
+		e.childCh <- i
+	}
+	close(e.childCh)
+	concurrency := e.concurrency
+	if concurrency > len(e.children) {
+		concurrency = len(e.children)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	for i := 0; i < concurrency; i++ {
 		e.wg.Add(1)
-		go e.fetchData(i)
+		go e.runWorker()
 	}
 	go e.waitAllFinished()
 	return errors.Trace(err)
@@ -819,6 +1157,9 @@ func (e *UnionExec) Next() (*Row, error) {
 // Close implements the Executor Close interface.
 func (e *UnionExec) Close() error {
 	e.finished.Store(true)
+	// Drain the results so the workers blocked on sending them can exit.
+	for range e.resultCh {
+	}
 	<-e.closedCh
 	e.rows = nil
 	return errors.Trace(e.baseExecutor.Close())
@@ -16,27 +16,47 @@ package executor_test
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/inspectkv"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/store/tikv/oracle"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/indexusage"
+	"github.com/pingcap/tidb/util/printer"
+	"github.com/pingcap/tidb/util/resourcegroup"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
+	"github.com/pingcap/tidb/util/txnconflict"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/types/json"
+	"github.com/pingcap/tidb/util/userlock"
 )
 
 func TestT(t *testing.T) {
@@ -87,6 +107,306 @@ func (s *testSuite) cleanEnv(c *C) {
 	}
 }
 
+func (s *testSuite) TestAdminReloadExprPushdownBlacklist(c *C) {
+	defer func() {
+		expression.SetExprPushdownBlacklist(nil)
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(10))")
+	tk.MustExec("insert t values (1, 'a'), (2, 'b')")
+	tk.MustExec("insert mysql.expr_pushdown_blacklist values ('lt'), ('Like')")
+	c.Assert(expression.IsPushdownBlacklisted("lt"), IsFalse)
+	tk.MustExec("admin reload expr_pushdown_blacklist")
+	c.Assert(expression.IsPushdownBlacklisted("lt"), IsTrue)
+	c.Assert(expression.IsPushdownBlacklisted("like"), IsTrue)
+	c.Assert(expression.IsPushdownBlacklisted("gt"), IsFalse)
+	tk.MustQuery("select a from t where a < 2 and b like 'a%'").Check(testkit.Rows("1"))
+
+	tk.MustExec("delete from mysql.expr_pushdown_blacklist")
+	tk.MustExec("admin reload expr_pushdown_blacklist")
+	c.Assert(expression.IsPushdownBlacklisted("lt"), IsFalse)
+}
+
+func (s *testSuite) TestAdminReloadResourceGroups(c *C) {
+	defer func() {
+		resourcegroup.Set(nil, nil)
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int)")
+	tk.MustExec("insert t values (1), (2)")
+	tk.MustExec("insert mysql.resource_group values ('report', 1, 0, 0), ('tiny', 0, 1, 0)")
+	tk.MustExec("insert mysql.user_resource_group values ('reporter', 'Report'), ('root', 'none')")
+	c.Assert(resourcegroup.ForUser("reporter"), IsNil)
+	tk.MustExec("admin reload resource_groups")
+	c.Assert(resourcegroup.ForUser("root"), IsNil)
+	group := resourcegroup.ForUser("reporter@%")
+	c.Assert(group, NotNil)
+	c.Assert(group.MaxConcurrentStmts, Equals, int64(1))
+
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk1.Se.GetSessionVars().User = "reporter@localhost"
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustExec("use test")
+	tk2.Se.GetSessionVars().User = "reporter@127.0.0.1"
+	rs, err := tk1.Exec("select a from t")
+	c.Assert(err, IsNil)
+	_, err = tk2.Exec("select a from t")
+	c.Assert(terror.ErrorEqual(err, resourcegroup.ErrTooManyStatements), IsTrue, Commentf("err %v", err))
+	// The other users are not limited.
+	tk.MustQuery("select a from t").Check(testkit.Rows("1", "2"))
+	c.Assert(rs.Close(), IsNil)
+	c.Assert(group.Running(), Equals, int64(0))
+	tk2.MustQuery("select a from t").Check(testkit.Rows("1", "2"))
+	tk2.MustExec("insert t values (3)")
+	c.Assert(group.Running(), Equals, int64(0))
+
+	// The coprocessor responses exceed the memory quota of the group.
+	tk.MustExec("update mysql.user_resource_group set resource_group = 'tiny' where user = 'reporter'")
+	tk.MustExec("admin reload resource_groups")
+	rs, err = tk1.Exec("select a from t")
+	c.Assert(err, IsNil)
+	for err == nil {
+		var row *ast.Row
+		row, err = rs.Next()
+		if row == nil {
+			break
+		}
+	}
+	c.Assert(terror.ErrorEqual(err, resourcegroup.ErrMemQuotaExceeded), IsTrue, Commentf("err %v", err))
+	c.Assert(rs.Close(), IsNil)
+
+	tk.MustExec("delete from mysql.resource_group")
+	tk.MustExec("delete from mysql.user_resource_group")
+	tk.MustExec("admin reload resource_groups")
+	c.Assert(resourcegroup.ForUser("reporter"), IsNil)
+	tk1.MustQuery("select a from t").Check(testkit.Rows("1", "2", "3"))
+}
+
+func (s *testSuite) TestStmtLimits(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustExec("insert t values (1, 1), (2, 1), (3, 2), (4, 2), (5, 3)")
+	queryErr := func(sql string) error {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil)
+		for err == nil {
+			var row *ast.Row
+			row, err = rs.Next()
+			if row == nil {
+				break
+			}
+		}
+		c.Assert(rs.Close(), IsNil)
+		return err
+	}
+
+	tk.MustExec("set @@tidb_stmt_max_examined_rows = 4")
+	err := queryErr("select t1.a from t t1, t t2 where t1.a = t2.b")
+	c.Assert(terror.ErrorEqual(err, resourcegroup.ErrExaminedRowsExceeded), IsTrue, Commentf("err %v", err))
+	c.Assert(errors.Cause(err).(*terror.Error).ToSQLError().Code, Equals, uint16(mysql.ErrTooBigSelect))
+	tk.MustQuery("select a from t where a < 3").Check(testkit.Rows("1", "2"))
+	// The rows filtered by the coprocessor are not counted.
+	tk.MustQuery("select a from t where b = 3").Check(testkit.Rows("5"))
+	tk.MustExec("set @@tidb_stmt_max_examined_rows = 0")
+
+	tk.MustExec("set @@tidb_stmt_max_returned_rows = 2")
+	err = queryErr("select a from t")
+	c.Assert(terror.ErrorEqual(err, resourcegroup.ErrReturnedRowsExceeded), IsTrue, Commentf("err %v", err))
+	// The rows examined but not returned are not limited.
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("5"))
+	tk.MustQuery("select distinct b from t where b < 3 order by b").Check(testkit.Rows("1", "2"))
+	tk.MustExec("set @@tidb_stmt_max_returned_rows = 0")
+
+	tk.MustExec("set @@tidb_stmt_mem_quota = 1")
+	err = queryErr("select a from t")
+	c.Assert(terror.ErrorEqual(err, resourcegroup.ErrStmtMemQuotaExceeded), IsTrue, Commentf("err %v", err))
+	tk.MustExec("set @@tidb_stmt_mem_quota = 0")
+	tk.MustQuery("select a from t where b = 3").Check(testkit.Rows("5"))
+}
+
+func (s *testSuite) TestAdminReloadConfig(c *C) {
+	defer func() {
+		config.SetServerConfig(nil)
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	reload := func() error {
+		rs, err := tk.Exec("admin reload config")
+		c.Assert(err, IsNil)
+		_, err = rs.Next()
+		c.Assert(rs.Close(), IsNil)
+		return err
+	}
+	c.Assert(terror.ErrorEqual(reload(), config.ErrNoConfigFile), IsTrue)
+
+	file, err := ioutil.TempFile("", "tidb-config")
+	c.Assert(err, IsNil)
+	c.Assert(file.Close(), IsNil)
+	defer os.Remove(file.Name())
+	writeConfig := func(content string) {
+		c.Assert(ioutil.WriteFile(file.Name(), []byte(content), 0644), IsNil)
+	}
+	flags := flag.NewFlagSet("tidb-server", flag.ContinueOnError)
+	level := flags.String("L", "info", "")
+	retryLimit := flags.Int("retry-limit", 10, "")
+	flags.String("store", "goleveldb", "")
+	c.Assert(flags.Parse(nil), IsNil)
+	conf := config.New(file.Name(), flags)
+	var reloaded []string
+	conf.HotReload("L", func() { reloaded = append(reloaded, *level) })
+	conf.HotReload("retry-limit", func() { reloaded = append(reloaded, fmt.Sprint(*retryLimit)) })
+	writeConfig(`{"L": "warn", "store": "tikv"}`)
+	c.Assert(conf.Load(), IsNil)
+	config.SetServerConfig(conf)
+	c.Assert(*level, Equals, "warn")
+
+	tk.MustQuery("admin reload config").Check(testkit.Rows())
+	writeConfig(`{"L": "error", "store": "mocktikv", "retry-limit": 5}`)
+	tk.MustQuery("admin reload config").Check(testkit.Rows(
+		"L warn error 1", "retry-limit 10 5 1", "store tikv mocktikv 0"))
+	c.Assert(reloaded, DeepEquals, []string{"error", "5"})
+	c.Assert(*retryLimit, Equals, 5)
+
+	// Nothing is applied if the file is invalid.
+	writeConfig(`{"L": "debug", "retry-limit": "x"}`)
+	c.Assert(terror.ErrorEqual(reload(), config.ErrInvalidValue), IsTrue)
+	c.Assert(*level, Equals, "error")
+	writeConfig(`{"L": "debug", "unknown": 1}`)
+	c.Assert(terror.ErrorEqual(reload(), config.ErrUnknownKey), IsTrue)
+	c.Assert(*level, Equals, "error")
+}
+
+func (s *testSuite) TestUserLock(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk2.MustExec("use test")
+	tk1.Se.GetSessionVars().ConnectionID = 1
+	tk2.Se.GetSessionVars().ConnectionID = 2
+
+	tk1.MustQuery("select get_lock('l1', 0), get_lock('L1', 0), get_lock('l2', 0)").Check(testkit.Rows("1 1 1"))
+	tk2.MustQuery("select get_lock('l1', 0), is_free_lock('l1'), is_used_lock('l1'), is_used_lock('l3')").Check(testkit.Rows("0 0 1 <nil>"))
+	tk2.MustQuery("select release_lock('l1'), release_lock('l3')").Check(testkit.Rows("0 <nil>"))
+
+	// The waiting session gets the lock after it's released.
+	ch := make(chan struct{})
+	go func() {
+		tk2.MustQuery("select get_lock('l1', 10)").Check(testkit.Rows("1"))
+		close(ch)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	tk1.MustQuery("select release_lock('l1'), release_lock('l1')").Check(testkit.Rows("1 1"))
+	<-ch
+	tk1.MustQuery("select is_used_lock('l1')").Check(testkit.Rows("2"))
+
+	// tk2 waits for tk1, so tk1 can't wait for tk2.
+	ch = make(chan struct{})
+	go func() {
+		tk2.MustQuery("select get_lock('l2', 10)").Check(testkit.Rows("1"))
+		close(ch)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	rs, err := tk1.Exec("select get_lock('l1', -1)")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(terror.ErrorEqual(err, userlock.ErrDeadlock), IsTrue, Commentf("err %v", err))
+	c.Assert(rs.Close(), IsNil)
+	tk1.MustQuery("select release_all_locks()").Check(testkit.Rows("1"))
+	<-ch
+	tk2.MustQuery("select release_all_locks()").Check(testkit.Rows("2"))
+
+	// The locks are released when the session is closed.
+	tk2.MustQuery("select get_lock('l3', 0)").Check(testkit.Rows("1"))
+	tk2.Se.Close()
+	tk1.MustQuery("select is_free_lock('l3'), get_lock('l3', 0), release_all_locks()").Check(testkit.Rows("1 1 1"))
+}
+
+func (s *testSuite) TestAdminShowSlow(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustQuery("select sleep(0.4), 'admin_show_slow'")
+	_, err := tk.Exec("select sleep(0.4), 'admin_show_slow_fail' from admin_show_slow_no_table")
+	c.Assert(err, NotNil)
+	tk.MustExec("do sleep(0.4)")
+
+	// The failed statement is not logged because it fails before the execution.
+	rows := tk.MustQuery("admin show slow recent 2").Rows()
+	c.Assert(rows, HasLen, 2)
+	c.Assert(rows[0][0], Equals, "do sleep(0.4)")
+	c.Assert(rows[0][3], Equals, "1")
+	c.Assert(rows[0][7], Equals, "test")
+	c.Assert(rows[0][8], Equals, "0")
+	c.Assert(rows[1][0], Equals, "select sleep(0.4), 'admin_show_slow'")
+	c.Assert(rows[1][3], Equals, "1")
+	c.Assert(rows[1][4], Equals, rows[0][4])
+	c.Assert(tk.MustQuery("admin show slow recent 1").Rows(), HasLen, 1)
+
+	for _, row := range tk.MustQuery("admin show slow top 20").Rows() {
+		c.Assert(row[8], Equals, "0")
+	}
+	for _, row := range tk.MustQuery("admin show slow top internal 20").Rows() {
+		c.Assert(row[8], Equals, "1")
+	}
+	c.Assert(len(tk.MustQuery("admin show slow top all 2").Rows()), LessEqual, 2)
+}
+
+func (s *testSuite) TestAdminDDLOwner(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	rows := tk.MustQuery("admin show ddl owner").Rows()
+	c.Assert(rows, HasLen, 2)
+	serverID := rows[0][2].(string)
+	for i, tp := range []string{"ddl", "background"} {
+		c.Assert(rows[i][0], Equals, tp)
+		c.Assert(rows[i][1], Equals, serverID)
+		c.Assert(rows[i][2], Equals, serverID)
+		c.Assert(rows[i][3], Not(Equals), "<nil>")
+		c.Assert(rows[i][4], Equals, "0")
+		c.Assert(rows[i][5], Equals, "0")
+	}
+
+	// The ownership is transferred back before the unknown owner times out, so the DDL isn't blocked.
+	tk.MustExec("admin transfer ddl owner to 'unknown'")
+	rows = tk.MustQuery("admin show ddl owner").Rows()
+	c.Assert(rows[0][1], Equals, "unknown")
+	c.Assert(rows[1][1], Equals, "unknown")
+	c.Assert(rows[0][4], Equals, "0")
+	tk.MustExec(fmt.Sprintf("admin transfer ddl owner to '%s'", serverID))
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int)")
+	rows = tk.MustQuery("admin show ddl owner").Rows()
+	c.Assert(rows[0][1], Equals, serverID)
+	c.Assert(rows[0][4], Equals, "0")
+}
+
 func (s *testSuite) TestAdmin(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -146,6 +466,69 @@ func (s *testSuite) TestAdmin(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testSuite) TestAdminCheckTableByChecksum(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec(`create table t (a int primary key, b varchar(20), c timestamp, d int,
+		index idx_b(b(3)), unique index idx_c(c), index idx_ad(a, d))`)
+	tk.MustExec(`insert t values (1, "abcdef", "2017-01-01 00:00:00", 1), (2, "x", "2017-01-02 00:00:00", NULL),
+		(3, NULL, NULL, 3)`)
+	tk.MustExec("alter table t add column e int default 5")
+	tk.MustExec("alter table t add index idx_e(e)")
+	tk.MustExec("admin check table t")
+
+	ctx := tk.Se.(context.Context)
+	is := sessionctx.GetDomain(ctx).InfoSchema()
+	tb, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	idx := tb.Indices()[0]
+	c.Assert(idx.Meta().Name.L, Equals, "idx_b")
+
+	// The index entry of a row is missing.
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	err = idx.Delete(txn, types.MakeDatums("x"), 2)
+	c.Assert(err, IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	_, err = tk.Exec("admin check table t")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*index:<nil> != record:&{2 .*")
+
+	// The index entry has a different value.
+	txn, err = s.store.Begin()
+	c.Assert(err, IsNil)
+	_, err = idx.Create(txn, types.MakeDatums("y"), 2)
+	c.Assert(err, IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	_, err = tk.Exec("admin check table t")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*index:&{2 .*} != record:&{2 .*")
+
+	// The row of an index entry is missing.
+	txn, err = s.store.Begin()
+	c.Assert(err, IsNil)
+	c.Assert(idx.Delete(txn, types.MakeDatums("y"), 2), IsNil)
+	_, err = idx.Create(txn, types.MakeDatums("x"), 2)
+	c.Assert(err, IsNil)
+	_, err = idx.Create(txn, types.MakeDatums("z"), 100)
+	c.Assert(err, IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	_, err = tk.Exec("admin check table t")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*index:&{100 .*} != record:<nil>.*")
+
+	txn, err = s.store.Begin()
+	c.Assert(err, IsNil)
+	c.Assert(idx.Delete(txn, types.MakeDatums("z"), 100), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	tk.MustExec("admin check table t")
+}
+
 func (s *testSuite) fillData(tk *testkit.TestKit, table string) {
 	tk.MustExec("use test")
 	tk.MustExec(fmt.Sprintf("create table %s(id int not null default 1, name varchar(255), PRIMARY KEY(id));", table))
@@ -335,6 +718,45 @@ func (s *testSuite) TestSelectOrderBy(c *C) {
 	r.Check(testkit.Rows("2"))
 }
 
+func (s *testSuite) TestSortSpill(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b varchar(20), c datetime, d decimal(10, 2))")
+	for i := 0; i < 200; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, 'b%d', '2017-01-%02d 10:00:00', %d.5)", i, i%17, i%28+1, i%13))
+	}
+	tk.MustExec("insert into t values (200, null, null, null)")
+
+	queries := []string{
+		"select * from t order by b, a",
+		"select * from t order by c desc, d, a",
+		"select b, count(*) from t group by b order by 2 desc, b",
+		"select a, d from t where a > 50 order by d + a, a desc",
+	}
+	expected := make([][][]interface{}, 0, len(queries))
+	tk.MustExec("set @@tidb_sort_mem_quota = 0")
+	for _, q := range queries {
+		expected = append(expected, tk.MustQuery(q).Rows())
+	}
+	// Every row is spilled as a run with the quota 1, and a few rows are spilled together with the quota 2048.
+	for _, quota := range []string{"1", "2048"} {
+		tk.MustExec("set @@tidb_sort_mem_quota = " + quota)
+		for i, q := range queries {
+			tk.MustQuery(q).Check(expected[i])
+		}
+	}
+
+	// The handles of the spilled rows are kept for updating.
+	tk.MustExec("update t set d = d + 1 order by c, a")
+	tk.MustExec("set @@tidb_sort_mem_quota = 0")
+	tk.MustQuery("select sum(d), count(d) from t").Check(testkit.Rows("1480.00 200"))
+}
+
 func (s *testSuite) TestSelectErrorRow(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -530,6 +952,116 @@ func (s *testSuite) TestUnion(c *C) {
 	r = tk.MustQuery("select b from (SELECT * FROM t UNION ALL SELECT a, b FROM t order by a) t")
 }
 
+func (s *testSuite) TestSharedTableScan(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int)")
+	for i := 1; i <= 300; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, %d)", i, i%10))
+	}
+	tk.MustQuery("select count(*), sum(t1.a), sum(t2.a) from t t1 join t t2 on t1.b = t2.b").Check(testkit.Rows("9000 1354500 1354500"))
+	tk.MustQuery("select count(*) from t t1 join t t2 on t1.a = t2.a + 1 where t1.b = 3").Check(testkit.Rows("30"))
+	tk.MustQuery("select count(*), sum(a) from (select * from t union all select * from t union all select * from t) x").Check(testkit.Rows("900 135450"))
+	tk.MustQuery("select t1.a, t2.a from t t1 left join t t2 on t1.a = t2.a - 299 order by t1.a limit 2").Check(testkit.Rows("1 300", "2 <nil>"))
+	tk.MustQuery("select a from t t1 where a > (select max(a) - 2 from t t2 where t2.b = t1.b) order by a").Check(testkit.Rows("291", "292", "293", "294", "295", "296", "297", "298", "299", "300"))
+
+	// The union scans of the readers sharing the scan see the rows written by the transaction.
+	tk.MustExec("begin")
+	tk.MustExec("insert into t values (301, 1)")
+	tk.MustExec("delete from t where a = 1")
+	tk.MustQuery("select count(*), sum(t1.a) from t t1 join t t2 on t1.a = t2.a").Check(testkit.Rows("300 45450"))
+	tk.MustQuery("select count(*), sum(a) from (select * from t union all select * from t) x").Check(testkit.Rows("600 90900"))
+	tk.MustExec("rollback")
+}
+
+func (s *testSuite) TestUnionConcurrency(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int)")
+	for i := 1; i <= 300; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, %d)", i, i%10))
+	}
+	sql := "select count(*), sum(a) from (select a from t union all select a from t where b = 1 union all select a from t where b = 2 union all select a + 1 from t union all select 1) x"
+	for _, concurrency := range []string{"1", "2", "4", "16"} {
+		tk.MustExec("set @@tidb_union_concurrency = " + concurrency)
+		tk.MustQuery(sql).Check(testkit.Rows("661 99391"))
+	}
+
+	// The results not read yet are discarded when the union is closed.
+	tk.MustExec("set @@tidb_stmt_max_returned_rows = 1")
+	rs, err := tk.Exec("select a from t union all select a from t union all select a from t")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(terror.ErrorEqual(err, resourcegroup.ErrReturnedRowsExceeded), IsTrue, Commentf("err %v", err))
+	c.Assert(rs.Close(), IsNil)
+}
+
+func (s *testSuite) TestUnionFieldType(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(20), c decimal(5,2), d datetime, e int unsigned not null, f varbinary(8))")
+	tk.MustExec("insert t values (1, 'abc', 1.25, '2017-01-01 10:00:00', 3, 'x')")
+
+	tests := []struct {
+		sql     string
+		tp      byte
+		flen    int
+		decimal int
+		charset string
+		rows    []string
+	}{
+		{"select a from t union select b from t", mysql.TypeVarchar, 20, types.UnspecifiedLength, charset.CharsetUTF8, []string{"1", "abc"}},
+		{"select c from t union select a from t", mysql.TypeNewDecimal, 13, 2, charset.CharsetBin, []string{"1.00", "1.25"}},
+		{"select d from t union select 'x'", mysql.TypeVarchar, types.UnspecifiedLength, types.UnspecifiedLength, charset.CharsetUTF8, []string{"2017-01-01 10:00:00", "x"}},
+		{"select null union select b from t", mysql.TypeVarchar, 20, types.UnspecifiedLength, charset.CharsetUTF8, []string{"<nil>", "abc"}},
+		{"select b from t union select f from t", mysql.TypeVarchar, 20, types.UnspecifiedLength, charset.CharsetBin, []string{"abc", "x"}},
+		{"select c from t union select 123.456", mysql.TypeNewDecimal, types.UnspecifiedLength, types.UnspecifiedLength, charset.CharsetBin, []string{"1.25", "123.456"}},
+	}
+	for _, tt := range tests {
+		rs, err := tk.Exec(tt.sql)
+		c.Assert(err, IsNil)
+		fields, err := rs.Fields()
+		c.Assert(err, IsNil)
+		tp := fields[0].Column.FieldType
+		c.Assert(tp.Tp, Equals, tt.tp, Commentf("%s", tt.sql))
+		c.Assert(tp.Flen, Equals, tt.flen, Commentf("%s", tt.sql))
+		c.Assert(tp.Decimal, Equals, tt.decimal, Commentf("%s", tt.sql))
+		c.Assert(tp.Charset, Equals, tt.charset, Commentf("%s", tt.sql))
+		tk.MustQuery(tt.sql).Sort().Check(testkit.Rows(tt.rows...))
+	}
+
+	rs, err := tk.Exec("select e from t union select e from t")
+	c.Assert(err, IsNil)
+	fields, err := rs.Fields()
+	c.Assert(err, IsNil)
+	c.Assert(mysql.HasUnsignedFlag(fields[0].Column.Flag), IsTrue)
+	c.Assert(mysql.HasNotNullFlag(fields[0].Column.Flag), IsTrue)
+	rs, err = tk.Exec("select e from t union select a from t")
+	c.Assert(err, IsNil)
+	fields, err = rs.Fields()
+	c.Assert(err, IsNil)
+	c.Assert(mysql.HasUnsignedFlag(fields[0].Column.Flag), IsFalse)
+	c.Assert(mysql.HasNotNullFlag(fields[0].Column.Flag), IsFalse)
+	tk.MustExec("drop table t")
+}
+
 func (s *testSuite) TestIn(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -730,6 +1262,40 @@ func (s *testSuite) TestTableReverseOrder(c *C) {
 	result.Check(testkit.Rows("7", "6", "2", "1"))
 }
 
+func (s *testSuite) TestForcedScanDirection(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int, c int, index idx (b))")
+	tk.MustExec("insert t values (1, 10, 1), (2, 30, 2), (3, 20, 3), (4, 50, 4), (5, 40, 5), (6, null, 6)")
+
+	for _, direction := range []string{"desc", "asc", "auto"} {
+		tk.MustExec("set @@tidb_index_scan_direction = '" + direction + "'")
+		tk.MustQuery("select a from t order by a desc limit 2").Check(testkit.Rows("6", "5"))
+		tk.MustQuery("select a from t where a < 5 order by a desc").Check(testkit.Rows("4", "3", "2", "1"))
+		tk.MustQuery("select b from t order by b desc limit 3").Check(testkit.Rows("50", "40", "30"))
+		tk.MustQuery("select c from t where b > 10 order by b desc").Check(testkit.Rows("4", "5", "2", "3"))
+		tk.MustQuery("select c from t order by b desc limit 2, 2").Check(testkit.Rows("2", "3"))
+	}
+	tk.MustQuery("select @@tidb_index_scan_direction").Check(testkit.Rows("AUTO"))
+
+	tk.MustQuery("select /*+ INDEX_DESC(t) */ a from t order by a desc limit 2").Check(testkit.Rows("6", "5"))
+	tk.MustQuery("select /*+ INDEX_DESC(t1) */ c from t t1 where b < 50 order by b desc").Check(testkit.Rows("5", "2", "3", "1"))
+	tk.MustQuery("select /*+ INDEX_ASC(t1) */ c from t t1 order by b desc limit 1").Check(testkit.Rows("4"))
+
+	// The dirty rows of the transaction are merged in the order.
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (7, 45, 7)")
+	tk.MustExec("delete from t where a = 4")
+	tk.MustQuery("select /*+ INDEX_DESC(t) */ c from t order by b desc limit 2").Check(testkit.Rows("7", "5"))
+	tk.MustQuery("select /*+ INDEX_DESC(t) */ a from t order by a desc limit 3").Check(testkit.Rows("7", "6", "5"))
+	tk.MustExec("rollback")
+}
+
 func (s *testSuite) TestDefaultNull(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -948,6 +1514,116 @@ func (s *testSuite) TestBuiltin(c *C) {
 	tk.MustQuery("select count(*) from t") // Test ProjectionExec
 	result = tk.MustQuery("select found_rows()")
 	result.Check(testkit.Rows("1"))
+
+	// for tidb_normalize and tidb_digest
+	tk.MustQuery("select tidb_normalize('SELECT * FROM t WHERE a IN (1, 2)')").Check(testkit.Rows("select * from t where a in (...)"))
+	tk.MustQuery("select tidb_digest('select a from t where b = 1') = tidb_digest('SELECT a FROM t WHERE b = 2')").Check(testkit.Rows("1"))
+	tk.MustQuery("select tidb_digest(null), tidb_normalize(null)").Check(testkit.Rows("<nil> <nil>"))
+
+	// for weight_string
+	tk.MustQuery("select hex(weight_string('ab')), hex(weight_string('ab' as char(3))), hex(weight_string('ab' as binary(3))), weight_string(null)").
+		Check(testkit.Rows("6162 616220 616200 <nil>"))
+
+	// for str_to_date
+	tk.MustQuery("select str_to_date('2017-1-1 12:34:56.5', '%Y-%m-%d %H:%i:%s.%f'), str_to_date('Thu, 7th Jan 10', '%a, %D %b %y')").
+		Check(testkit.Rows("2017-01-01 12:34:56.500000 2010-01-07 00:00:00"))
+	tk.MustQuery("select str_to_date('2012 51 Fri 1:05 pm', '%X %V %a %l:%i %p')").Check(testkit.Rows("2012-12-21 13:05:00"))
+	tk.MustQuery("select str_to_date('2016 367', '%Y %j')").Check(testkit.Rows("<nil>"))
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|", "Warning|1411|Incorrect datetime value: '2016 367' for function str_to_date"))
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a datetime)")
+	tk.MustExec("set sql_mode = 'STRICT_TRANS_TABLES'")
+	_, err := tk.Exec("insert t values (str_to_date('2016 367', '%Y %j'))")
+	c.Assert(err, NotNil)
+	tk.MustExec("set sql_mode = ''")
+	tk.MustExec("insert t values (str_to_date('2016 367', '%Y %j'))")
+	tk.MustQuery("select a from t").Check(testkit.Rows("<nil>"))
+
+	// for date arithmetic
+	tk.MustQuery("select '2016-01-31' + interval 1 month, '2016-03-01 00:00:00' - interval '1 1' day_hour, date_add('2016-02-29', interval '-1-1' year_month)").
+		Check(testkit.Rows("2016-02-29 2016-02-28 23:00:00 2015-01-29"))
+	tk.MustQuery("select date_add('9999-12-31 23:59:59', interval 1 second), date_sub('0001-01-01', interval 2 year)").Check(testkit.Rows("<nil> <nil>"))
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|",
+		"Warning|1441|Datetime function: datetime field overflow",
+		"Warning|1441|Datetime function: datetime field overflow"))
+	tk.MustExec("set sql_mode = 'STRICT_TRANS_TABLES'")
+	_, err = tk.Exec("insert t values ('9999-12-31' + interval 1 day)")
+	c.Assert(err, NotNil)
+	tk.MustExec("set sql_mode = ''")
+}
+
+func (s *testSuite) TestTiDBDecodeKey(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b varchar(10), c datetime, index idx_bc (b, c), unique index idx_c (c))")
+	tbl, err := sessionctx.GetDomain(tk.Se).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	tblID := tbl.Meta().ID
+	decode := func(key []byte) string {
+		rows := tk.MustQuery(fmt.Sprintf("select tidb_decode_key('%X')", key)).Rows()
+		return rows[0][0].(string)
+	}
+
+	rowKey := tablecodec.EncodeRowKeyWithHandle(tblID, 5)
+	c.Assert(decode(rowKey), Equals, fmt.Sprintf(`{"table_id":%d,"db_name":"test","table_name":"t","handle":5}`, tblID))
+	// The memcomparable-encoded keys of the regions are decoded too.
+	c.Assert(decode(codec.EncodeBytes(nil, rowKey)), Equals, decode(rowKey))
+	c.Assert(decode(tablecodec.EncodeTablePrefix(tblID)), Equals,
+		fmt.Sprintf(`{"table_id":%d,"db_name":"test","table_name":"t"}`, tblID))
+
+	dt, err := types.ParseDatetime("2017-10-18 12:00:00")
+	c.Assert(err, IsNil)
+	for _, idx := range tbl.Indices() {
+		var vals []types.Datum
+		var expected string
+		if idx.Meta().Name.L == "idx_bc" {
+			vals = types.MakeDatums("abc", dt)
+			expected = fmt.Sprintf(`{"table_id":%d,"db_name":"test","table_name":"t","index_id":%d,"index_name":"idx_bc",`+
+				`"index_vals":{"b":"abc","c":"2017-10-18 12:00:00"},"handle":5}`, tblID, idx.Meta().ID)
+		} else {
+			vals = []types.Datum{types.NewDatum(dt)}
+			expected = fmt.Sprintf(`{"table_id":%d,"db_name":"test","table_name":"t","index_id":%d,"index_name":"idx_c",`+
+				`"index_vals":{"c":"2017-10-18 12:00:00"}}`, tblID, idx.Meta().ID)
+		}
+		key, _, err := idx.GenIndexKey(vals, 5)
+		c.Assert(err, IsNil)
+		c.Assert(decode(key), Equals, expected)
+	}
+
+	// The dropped table is decoded without the names.
+	tk.MustExec("drop table t")
+	c.Assert(decode(rowKey), Equals, fmt.Sprintf(`{"table_id":%d,"handle":5}`, tblID))
+	// The invalid keys are returned with a warning.
+	tk.MustQuery("select tidb_decode_key('abcdefg'), tidb_decode_key('6162'), tidb_decode_key(null)").Check(
+		testkit.Rows("abcdefg 6162 <nil>"))
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(2))
+}
+
+func (s *testSuite) TestTiDBInfoFunctions(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	rows := tk.MustQuery("select tidb_version()").Rows()
+	c.Assert(rows[0][0], Equals, printer.GetTiDBInfo())
+
+	// The read timestamp is the start timestamp of the transaction.
+	tk.MustExec("begin")
+	startTS := tk.Se.(context.Context).Txn().StartTS()
+	tk.MustQuery("select tidb_current_tso()").Check(testkit.Rows(fmt.Sprintf("%d", startTS)))
+	tk.MustQuery("select tidb_current_tso() = tidb_current_tso()").Check(testkit.Rows("1"))
+	tk.MustExec("commit")
+	rows = tk.MustQuery("select tidb_current_tso()").Rows()
+	c.Assert(rows[0][0], Not(Equals), fmt.Sprintf("%d", startTS))
+
+	// The only server of the store is the DDL owner.
+	tk.MustQuery("select tidb_is_ddl_owner()").Check(testkit.Rows("1"))
 }
 
 func (s *testSuite) TestJSON(c *C) {
@@ -972,6 +1648,121 @@ func (s *testSuite) TestJSON(c *C) {
 	result.Check(testkit.Rows(`{"a":[1,"2",{"aa":"bb"},4],"b":true}`, "null", "<nil>", "true", "3", `"string"`))
 }
 
+func (s *testSuite) TestJSONBuiltin(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+
+	tk.MustQuery(`select json_extract('{"a":[1,{"b":"c"}]}', '$.a[1].b'), json_extract('[1,2]', '$[2]'), json_extract('[1,2]', '$[0]', '$[1]')`).
+		Check(testkit.Rows(`"c" <nil> [1,2]`))
+	tk.MustQuery(`select json_contains('[1,2,[3]]', '[1,3]'), json_contains('{"a":1,"b":2}', '{"a":1}'), json_contains('[1,2]', '"1"'), json_contains('{"a":[1,2]}', '2', '$.a')`).
+		Check(testkit.Rows("1 1 0 1"))
+	tk.MustQuery(`select 1 member of('[1,"2"]'), '2' member of('[1,"2"]'), 2 member of('[1,"2"]'), 'a' member of('"a"'), null member of('[1]')`).
+		Check(testkit.Rows("1 1 0 1 <nil>"))
+	tk.MustQuery(`select json_set('{"a":1}', '$.a', 2, '$.b', 'x', '$.c[1]', 3), json_set('[1,2]', '$[5]', json_array()), json_set('{"a":1}', '$.b.c', 2), json_set(null, '$', 1)`).
+		Check(testkit.Rows(`{"a":2,"b":"x"} [1,2,[]] {"a":1} <nil>`))
+	tk.MustQuery(`select json_object(), json_object('a', 1, 'b', null, 'c', json_array(1, '2', 3.5)), json_array(), json_array(json_object('k', 'v'), null)`).
+		Check(testkit.Rows(`{} {"a":1,"b":null,"c":[1,"2",3.5]} [] [{"k":"v"},null]`))
+	tk.MustQuery(`select json_unquote('"a\\tb"'), json_unquote('abc'), json_unquote(json_extract('{"a":"x"}', '$.a')), json_unquote(json_array(1)), json_unquote(null)`).
+		Check(testkit.Rows("a\tb abc x [1] <nil>"))
+	tk.MustQuery(`select cast('{"a": [1, true]}' as json), cast(1 as json), cast(json_array('a') as json), cast(cast('[1, "x"]' as json) as char), cast(null as json)`).
+		Check(testkit.Rows(`{"a":[1,true]} 1 ["a"] [1,"x"] <nil>`))
+
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int, a json)")
+	tk.MustExec(`insert into t values (1, '{"a":1}'), (2, json_object('b', json_array(1, 2)))`)
+	tk.MustExec(`update t set a = json_set(a, '$.c', id)`)
+	tk.MustQuery(`select json_extract(a, '$.c'), json_unquote(json_extract(a, '$.b')) from t order by id`).
+		Check(testkit.Rows("1 <nil>", "2 [1,2]"))
+
+	_, err := tk.Exec(`select json_object('a')`)
+	c.Assert(err, NotNil)
+	_, err = tk.Exec(`select json_set('{}', '$.a')`)
+	c.Assert(err, NotNil)
+	rs, err := tk.Exec(`select json_object(null, 1)`)
+	if err == nil {
+		_, err = rs.Next()
+		c.Assert(rs.Close(), IsNil)
+	}
+	c.Assert(json.ErrJSONDocumentNULLKey.Equal(err), IsTrue)
+	rs, err = tk.Exec(`select json_extract('[1]', 'a')`)
+	if err == nil {
+		_, err = rs.Next()
+		c.Assert(rs.Close(), IsNil)
+	}
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestMultiValuedIndex(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a json, b json, index ia ((cast(a as unsigned array))), index ib ((cast(json_extract(b, '$.tags') as char(5) array))))")
+	tk.MustExec(`insert t values (1, '[1,2,2]', '{"tags":["x","y"]}'), (2, '[2,3]', '{"tags":"x"}'), (3, '4', '{}'), (4, null, null), (5, '[]', '{"tags":[]}')`)
+	_, err := tk.Exec(`insert t values (6, '[-1]', null)`)
+	c.Assert(table.ErrInvalidMultiValuedValue.Equal(err), IsTrue)
+	_, err = tk.Exec(`insert t values (6, null, '{"tags":["abcdef"]}')`)
+	c.Assert(table.ErrInvalidMultiValuedValue.Equal(err), IsTrue)
+
+	checkQuery := func(sql string, rows ...string) {
+		explain := fmt.Sprintf("%v", tk.MustQuery("explain "+sql).Rows())
+		c.Assert(strings.Contains(explain, "IndexLookUp") || strings.Contains(explain, "IndexScan"), IsTrue, Commentf("for %s: %s", sql, explain))
+		tk.MustQuery(sql).Check(testkit.Rows(rows...))
+	}
+	checkQuery("select id from t where 2 member of(a) order by id", "1", "2")
+	checkQuery("select id from t where 4 member of(a)", "3")
+	checkQuery("select id from t where json_contains(a, '[2,1]')", "1")
+	checkQuery(`select id from t where 'x' member of(json_extract(b, '$.tags')) order by id`, "1", "2")
+	tk.MustQuery(`select id from t where json_contains(json_extract(b, '$.tags'), '"y"') and id > 0`).Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t where 5 member of(a)").Check(nil)
+	tk.MustQuery("select id from t where -1 member of(a)").Check(nil)
+
+	// The index entries are maintained by the writes.
+	tk.MustExec("update t set a = '[5,2]' where id = 1")
+	tk.MustExec("delete from t where id = 2")
+	tk.MustQuery("select id from t where 2 member of(a)").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t where 1 member of(a)").Check(nil)
+	tk.MustQuery("select id from t where 5 member of(a)").Check(testkit.Rows("1"))
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (7, '[2]', null)")
+	tk.MustQuery("select id from t where 2 member of(a) order by id").Check(testkit.Rows("1", "7"))
+	tk.MustExec("rollback")
+	tk.MustExec("admin check table t")
+
+	// The index is backfilled by ADD INDEX.
+	tk.MustExec("alter table t add index ia2 ((cast(a as signed array)))")
+	tk.MustQuery("select id from t use index(ia2) where 5 member of(a)").Check(testkit.Rows("1"))
+	tk.MustExec("analyze table t")
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` int(11) NOT NULL,\n" +
+		"  `a` json DEFAULT NULL,\n" +
+		"  `b` json DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `ia` ((CAST(`a` AS UNSIGNED ARRAY))),\n" +
+		"  KEY `ib` ((CAST(JSON_EXTRACT(`b`, '$.tags') AS CHAR(5) ARRAY))),\n" +
+		"  KEY `ia2` ((CAST(`a` AS SIGNED ARRAY)))\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+
+	tk.MustExec("drop table if exists t1")
+	for _, sql := range []string{
+		"create table t1 (a json, unique index ((cast(a as unsigned array))))",
+		"create table t1 (a int, index ((cast(a as unsigned array))))",
+		"create table t1 (a json, b int, index ((cast(a as unsigned array)), b))",
+		"create table t1 (a json, index ((cast(a as decimal array))))",
+		"create table t1 (a json, index ((cast(json_extract(a, 'x') as signed array))))",
+	} {
+		_, err = tk.Exec(sql)
+		c.Assert(err, NotNil, Commentf("for %s", sql))
+	}
+}
+
 func (s *testSuite) TestToPBExpr(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -1024,6 +1815,64 @@ func (s *testSuite) TestToPBExpr(c *C) {
 	result.Check(testkit.Rows("1", "2"))
 }
 
+func (s *testSuite) TestLikeIndexRange(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a varchar(20), b int, index idx_a (a), index idx_a_prefix (a(2)))")
+	tk.MustExec(`insert t values ('abc', 1), ('ABC', 2), ('abd', 3), ('aBx', 4), ('ab', 5), ('a%b', 6), ('a%', 7), ('2017-01', 8), ('2017-1', 9), ('2018', 10)`)
+	tests := []struct {
+		pattern string
+		result  []string
+	}{
+		// LIKE matches the letters case insensitively.
+		{`ab%`, []string{"1", "2", "3", "4", "5"}},
+		{`abc`, []string{"1", "2"}},
+		{`ab_`, []string{"1", "2", "3", "4"}},
+		{`ab%%`, []string{"1", "2", "3", "4", "5"}},
+		{`a\\%%`, []string{"6", "7"}},
+		{`a|%_`, []string{"6"}},
+		{`2017-%`, []string{"8", "9"}},
+		{`2017-0%`, []string{"8"}},
+		{`%b%`, []string{"1", "2", "3", "4", "5", "6"}},
+	}
+	for _, tt := range tests {
+		escape := ""
+		if strings.Contains(tt.pattern, "|") {
+			escape = " escape '|'"
+		}
+		for _, hint := range []string{"use index(idx_a)", "use index(idx_a_prefix)", "ignore index(idx_a, idx_a_prefix)"} {
+			sql := fmt.Sprintf("select b from t %s where a like '%s'%s order by b", hint, tt.pattern, escape)
+			tk.MustQuery(sql).Check(testkit.Rows(tt.result...))
+		}
+	}
+
+	// The strings are compared without padding, the trailing spaces are significant in the ranges as in LIKE.
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("create table t1 (a char(10), b int, index idx_a (a))")
+	tk.MustExec(`insert t1 values ('ab', 1), ('ab ', 2), ('ab  ', 3), ('ab c', 4), ('abc', 5)`)
+	tests = []struct {
+		pattern string
+		result  []string
+	}{
+		{`ab`, []string{"1"}},
+		{`ab `, []string{"2"}},
+		{`ab %`, []string{"2", "3", "4"}},
+		{`ab _`, []string{"3", "4"}},
+		{`ab%`, []string{"1", "2", "3", "4", "5"}},
+	}
+	for _, tt := range tests {
+		for _, hint := range []string{"use index(idx_a)", "ignore index(idx_a)"} {
+			sql := fmt.Sprintf("select b from t1 %s where a like '%s' order by b", hint, tt.pattern)
+			tk.MustQuery(sql).Check(testkit.Rows(tt.result...))
+		}
+	}
+}
+
 func (s *testSuite) TestDatumXAPI(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -1048,9 +1897,9 @@ func (s *testSuite) TestDatumXAPI(c *C) {
 	tk.MustExec("insert t values ('11:11:12', '11:11:12')")
 	tk.MustExec("insert t values ('11:11:13', '11:11:13')")
 	result = tk.MustQuery("select * from t where a > '11:11:11.5'")
-	result.Check(testkit.Rows("11:11:12 11:11:12", "11:11:13 11:11:13"))
+	result.Check(testkit.Rows("11:11:12.000 11:11:12", "11:11:13.000 11:11:13"))
 	result = tk.MustQuery("select * from t where b > '11:11:11.5'")
-	result.Check(testkit.Rows("11:11:12 11:11:12", "11:11:13 11:11:13"))
+	result.Check(testkit.Rows("11:11:12.000 11:11:12", "11:11:13.000 11:11:13"))
 }
 
 func (s *testSuite) TestSQLMode(c *C) {
@@ -1129,6 +1978,95 @@ func (s *testSuite) TestTableScan(c *C) {
 	result.Check(testkit.Rows(rowStr1, rowStr2))
 }
 
+type mockSessionManager struct {
+	processInfos []util.ProcessInfo
+}
+
+func (sm *mockSessionManager) ShowProcessList() []util.ProcessInfo {
+	return sm.processInfos
+}
+
+func (sm *mockSessionManager) Kill(connectionID uint64, query bool) {}
+
+func (s *testSuite) TestInfoschemaLiveTables(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a bigint, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3)")
+	tk.MustExec("analyze table t")
+	tk.MustQuery("select table_rows, avg_row_length, data_length from information_schema.tables where table_schema = 'test' and table_name = 't'").Check(testkit.Rows("3 12 36"))
+	tk.MustExec("drop table t")
+
+	tk.MustQuery("select variable_value from information_schema.global_variables where variable_name = 'autocommit'").Check(testkit.Rows("ON"))
+	tk.MustQuery("select count(*) from information_schema.global_variables where variable_name = 'tidb_snapshot'").Check(testkit.Rows("0"))
+
+	tk.MustQuery("select * from information_schema.processlist").Check(testkit.Rows())
+	sm := &mockSessionManager{
+		processInfos: []util.ProcessInfo{
+			{ID: 1, User: "root", Host: "127.0.0.1", DB: "test", Command: "Query", Time: time.Now(), State: 2, Info: "select 1"},
+			{ID: 2, User: "root", Host: "127.0.0.1", Command: "Sleep", Time: time.Now(), State: 2},
+			{ID: 3, User: "root", Host: "127.0.0.1", Command: "Query", Time: time.Now().Add(-time.Minute), State: 2,
+				TxnStartTS: oracle.ComposeTS(oracle.GetPhysical(time.Now().Add(-time.Hour)), 0)},
+		},
+	}
+	tk.Se.SetSessionManager(sm)
+	tk.MustQuery("select id, user, db, command, state, info from information_schema.processlist order by id").Check(
+		testkit.Rows("1 root test Query 2 select 1", "2 root  Sleep 2 <nil>", "3 root  Query 2 <nil>"))
+	tk.MustQuery("select id, txn_time >= 3600, idle_time >= 60 from information_schema.processlist where txn_start_ts is not null").Check(
+		testkit.Rows("3 1 1"))
+}
+
+func (s *testSuite) TestWriteConflicts(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_conflict")
+	tk.MustExec("create table t_conflict (a int primary key, b int)")
+	tk.MustExec("insert t_conflict values (1, 1)")
+	txnconflict.Reset()
+
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk.MustExec("begin")
+	tk.MustExec("update t_conflict set b = 2 where a = 1")
+	tk1.MustExec("update t_conflict set b = 3 where a = 1")
+	// The transaction is retried after the conflict.
+	tk.MustExec("commit")
+	tk.MustQuery("select b from t_conflict").Check(testkit.Rows("2"))
+	tk.MustQuery("select db_name, table_name, index_name, handle, index_values, conflict_commit_ts > start_ts from information_schema.tidb_write_conflicts").Check(
+		testkit.Rows("test t_conflict <nil> 1 <nil> 1"))
+	tk.MustExec("drop table t_conflict")
+	tk.MustQuery("select count(*) from information_schema.tidb_write_conflicts").Check(testkit.Rows("0"))
+}
+
+func (s *testSuite) TestIndexUsage(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_usage")
+	tk.MustExec("create table t_usage (a int primary key, b int, c int, index idx_b (b), index idx_c (c), index idx_unused (b, c))")
+	tk.MustExec("insert t_usage values (1, 1, 1), (2, 2, 2), (3, 2, 3)")
+	indexusage.Reset()
+
+	// Index only read and double read.
+	tk.MustQuery("select b from t_usage use index (idx_b) where b = 2").Check(testkit.Rows("2", "2"))
+	tk.MustQuery("select a from t_usage use index (idx_c) where c > 1 order by a").Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select c from t_usage use index (idx_c) where c = 1").Check(testkit.Rows("1"))
+	tk.MustQuery("select a from t_usage where a = 1").Check(testkit.Rows("1"))
+	tk.MustQuery("select index_name, read_count, rows_read, last_used_at is not null from information_schema.tidb_index_usage where table_name = 't_usage'").Check(
+		testkit.Rows("idx_b 1 2 1", "idx_c 2 3 1", "idx_unused 0 0 0"))
+	tk.MustQuery("select index_name from information_schema.tidb_index_usage where db_name = 'test' and table_name = 't_usage' and read_count = 0").Check(
+		testkit.Rows("idx_unused"))
+
+	tk.MustExec("alter table t_usage drop index idx_unused")
+	tk.MustQuery("select count(*) from information_schema.tidb_index_usage where table_name = 't_usage'").Check(testkit.Rows("2"))
+}
+
 func (s *testSuite) TestAdapterStatement(c *C) {
 	defer testleak.AfterTest(c)()
 	se, err := tidb.CreateSession(s.store)
@@ -1179,6 +2117,85 @@ func (s *testSuite) TestPointGet(c *C) {
 
 }
 
+func (s *testSuite) TestClusteredIndex(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("set @@tidb_enable_clustered_index = 1")
+	tk.MustExec("create table t (a varchar(10), b int, c int, primary key (a, b), index idx_c (c))")
+	tk.MustExec("set @@tidb_enable_clustered_index = 0")
+	tk.MustExec("insert t values ('x', 1, 10), ('x', 2, 20), ('y', 1, 30)")
+
+	checkQuery := func(sql string, pointGet bool, rows ...string) {
+		explain := fmt.Sprintf("%v", tk.MustQuery("explain "+sql).Rows())
+		c.Assert(strings.Contains(explain, "PointGet"), Equals, pointGet, Commentf("for %s: %s", sql, explain))
+		tk.MustQuery(sql).Check(testkit.Rows(rows...))
+	}
+	checkQuery("select * from t where a = 'x' and b = 2", true, "x 2 20")
+	checkQuery("select c, a from t where b = 1 and a = 'y'", true, "30 y")
+	checkQuery("select * from t where a = 'x' and b = 3", true)
+	checkQuery("select * from t where a = 'x' and b = '1'", true, "x 1 10")
+	checkQuery("select * from t where a = 'x'", false, "x 1 10", "x 2 20")
+	checkQuery("select * from t where a = 'x' and b = 1 and c = 10", false, "x 1 10")
+	checkQuery("select * from t where a = 'x' and b = 1.5", false)
+	checkQuery("select count(*) from t where a = 'x' and b = 1", false, "1")
+
+	tk.MustExec("update t set c = 11 where a = 'x' and b = 1")
+	checkQuery("select * from t where a = 'x' and b = 1", true, "x 1 11")
+	tk.MustExec("update t set a = 'z' where a = 'x' and b = 2")
+	checkQuery("select * from t where a = 'x' and b = 2", true)
+	checkQuery("select * from t where a = 'z' and b = 2", true, "z 2 20")
+	tk.MustExec("delete from t where a = 'y' and b = 1")
+	checkQuery("select * from t where a = 'y' and b = 1", true)
+
+	tk.MustExec("prepare stmt from 'select c from t where a = ? and b = ?'")
+	tk.MustExec("set @a = 'x', @b = 1")
+	tk.MustQuery("execute stmt using @a, @b").Check(testkit.Rows("11"))
+	tk.MustExec("set @a = 'z', @b = 2")
+	tk.MustQuery("execute stmt using @a, @b").Check(testkit.Rows("20"))
+
+	// The rows written in the transaction are read.
+	tk.MustExec("begin")
+	tk.MustExec("insert t values ('w', 1, 40)")
+	checkQuery("select * from t where a = 'w' and b = 1", true, "w 1 40")
+	tk.MustExec("rollback")
+	checkQuery("select * from t where a = 'w' and b = 1", true)
+
+	// The columns added later have the default values in the rows written before.
+	tk.MustExec("alter table t add column d int default 5")
+	checkQuery("select * from t where a = 'x' and b = 1", true, "x 1 11 5")
+	tk.MustExec("alter table t drop index idx_c")
+	tk.MustExec("alter table t drop column c")
+	checkQuery("select * from t where a = 'z' and b = 2", true, "z 2 5")
+	tk.MustExec("admin check table t")
+
+	// The row in the primary key entry differs from the record.
+	ctx := tk.Se.(context.Context)
+	tb, err := sessionctx.GetDomain(ctx).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	pk := tb.Indices()[0]
+	c.Assert(pk.Meta().Clustered, IsTrue)
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	key1, _, err := pk.GenIndexKey(types.MakeDatums("x", 1), 0)
+	c.Assert(err, IsNil)
+	key2, _, err := pk.GenIndexKey(types.MakeDatums("z", 2), 0)
+	c.Assert(err, IsNil)
+	value1, err := txn.Get(key1)
+	c.Assert(err, IsNil)
+	value2, err := txn.Get(key2)
+	c.Assert(err, IsNil)
+	c.Assert(txn.Set(key1, append(value1[:8:8], value2[8:]...)), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	_, err = tk.Exec("admin check table t")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*index:&{1 .*} != record:&{1 .*")
+}
+
 func (s *testSuite) TestRow(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -1300,6 +2317,51 @@ func (s *testSuite) TestHistoryRead(c *C) {
 	tk.MustQuery("select * from history_read order by a").Check(testkit.Rows("2 <nil>", "4 <nil>", "8 8", "9 9"))
 }
 
+type lowResolutionTSStore struct {
+	kv.Storage
+	ts uint64
+}
+
+func (s *lowResolutionTSStore) LowResolutionTS() (uint64, error) {
+	return s.ts, nil
+}
+
+func (s *testSuite) TestLowResolutionTSO(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	if !*mockTikv {
+		// The local store ignores the start timestamps of the transactions.
+		return
+	}
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists low_resolution_tso")
+	tk.MustExec("create table low_resolution_tso (a int)")
+	tk.MustExec("insert low_resolution_tso values (1)")
+	ver, err := s.store.CurrentVersion()
+	c.Assert(err, IsNil)
+	tk.MustExec("insert low_resolution_tso values (2)")
+
+	// The store always returns the timestamp before the second row is inserted.
+	tk1 := testkit.NewTestKit(c, &lowResolutionTSStore{Storage: s.store, ts: ver.Ver})
+	tk1.MustExec("use test")
+	tk1.MustQuery("select * from low_resolution_tso").Check(testkit.Rows("1", "2"))
+	tk1.MustExec("set @@tidb_low_resolution_tso = 1")
+	tk1.MustQuery("select @@tidb_low_resolution_tso").Check(testkit.Rows("1"))
+	tk1.MustQuery("select * from low_resolution_tso").Check(testkit.Rows("1"))
+	_, err = tk1.Exec("insert low_resolution_tso values (3)")
+	c.Assert(err, NotNil)
+	// The explicit transactions are not affected.
+	tk1.MustExec("begin")
+	tk1.MustQuery("select * from low_resolution_tso").Check(testkit.Rows("1", "2"))
+	tk1.MustExec("insert low_resolution_tso values (3)")
+	tk1.MustExec("commit")
+	tk1.MustExec("set @@tidb_low_resolution_tso = 0")
+	tk1.MustQuery("select * from low_resolution_tso").Check(testkit.Rows("1", "2", "3"))
+}
+
 func (s *testSuite) TestScanControlSelection(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -1404,3 +2466,179 @@ func (s *testSuite) TestTimestampTimeZone(c *C) {
 		tk.MustQuery(fmt.Sprintf("select * from t where ts = '%s'", tt.expect)).Check(testkit.Rows(tt.expect))
 	}
 }
+
+func (s *testSuite) TestBitType(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a bit(4), b bit(64), index idx(a))")
+	tk.MustExec("insert t values (b'1010', 5), (9, 18446744073709551615), ('', b'')")
+	_, err := tk.Exec("insert t values (16, 1)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("insert t values ('ab', 1)")
+	c.Assert(err, NotNil)
+
+	tk.MustQuery("select a+0, b+0, hex(a), oct(a), bin(b), bit_count(b) from t order by a").Check(testkit.Rows(
+		"0 0 0 0 0 0",
+		"9 18446744073709551615 9 11 1111111111111111111111111111111111111111111111111111111111111111 64",
+		"10 5 A 12 101 2",
+	))
+	tk.MustQuery("select a+0 from t where a = b'00001010'").Check(testkit.Rows("10"))
+	tk.MustQuery("select a+0 from t where a > 9").Check(testkit.Rows("10"))
+	tk.MustQuery("select b+0 from t where b = 18446744073709551615").Check(testkit.Rows("18446744073709551615"))
+	tk.MustQuery("select a | 1, b & 4, b ^ 1, ~a, -a from t where a = 10").Check(testkit.Rows("11 4 4 18446744073709551605 -10"))
+	tk.MustQuery("select b'01100001' = 'a', b'' + 0, b'1010' + 0").Check(testkit.Rows("1 0 10"))
+	tk.MustExec("drop table t")
+}
+
+func (s *testSuite) TestYearType(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int, y year, index idx(y))")
+	tk.MustExec("insert t values (1, 0), (2, '0'), (3, '0000'), (4, 69), (5, '70'), (6, 2155)")
+	tk.MustQuery("select y from t order by id").Check(testkit.Rows("0", "2000", "0", "2069", "1970", "2155"))
+	for _, v := range []string{"1900", "2156", "-1", "100"} {
+		_, err := tk.Exec("insert t values (7, " + v + ")")
+		c.Assert(terror.ErrorEqual(err, types.ErrOverflow), IsTrue, Commentf("%s %v", v, err))
+	}
+	tk.MustExec("set sql_mode = ''")
+	tk.MustExec("insert t values (7, 3000)")
+	tk.MustQuery("select y from t where id = 7").Check(testkit.Rows("0"))
+
+	// The constants compared with the year column are converted to year.
+	tk.MustQuery("select id from t where y = 69").Check(testkit.Rows("4"))
+	tk.MustQuery("select id from t where y = '70'").Check(testkit.Rows("5"))
+	tk.MustQuery("select id from t where 69 <= y order by id").Check(testkit.Rows("4", "6"))
+	tk.MustQuery("select id from t where y = 0 order by id").Check(testkit.Rows("1", "3", "7"))
+	tk.MustExec("drop table t")
+}
+
+func (s *testSuite) TestTimeFsp(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a datetime(3), b timestamp(6) null, c time(2), d datetime, e time)")
+	tk.MustExec("insert t values ('2017-01-01 10:10:10.123456', '2017-01-01 10:10:10.1234567', '10:10:10.125', '2017-01-01 10:10:10.5', '10:10:10.5')")
+	tk.MustExec("insert t values ('2017-12-31 23:59:59.9999', null, '-10:10:10.999', '2017-12-31 23:59:59.4', '10:10:10.4')")
+	tk.MustQuery("select * from t").Check(testkit.Rows(
+		"2017-01-01 10:10:10.123 2017-01-01 10:10:10.123457 10:10:10.13 2017-01-01 10:10:11 10:10:11",
+		"2018-01-01 00:00:00.000 <nil> -10:10:11.00 2017-12-31 23:59:59 10:10:10"))
+	tk.MustQuery("select c + 0 from t").Check(testkit.Rows("101010.13", "-101011.00"))
+
+	result := tk.MustQuery("show create table t")
+	c.Assert(result.Rows()[0][1], Equals, "CREATE TABLE `t` (\n"+
+		"  `a` datetime(3) DEFAULT NULL,\n"+
+		"  `b` timestamp(6) NULL DEFAULT NULL,\n"+
+		"  `c` time(2) DEFAULT NULL,\n"+
+		"  `d` datetime DEFAULT NULL,\n"+
+		"  `e` time DEFAULT NULL\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin")
+	tk.MustExec("drop table t")
+
+	// The fsp of the CURRENT_TIMESTAMP default value must be the same as the column.
+	_, err := tk.Exec("create table t (a datetime default current_timestamp(3))")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("create table t (a timestamp(2) default current_timestamp(2) on update current_timestamp)")
+	c.Assert(err, NotNil)
+	tk.MustExec("create table t (a datetime(3) default current_timestamp(3) on update current_timestamp(3), b int)")
+	tk.MustExec("insert t (b) values (1)")
+	tk.MustQuery("select length(a) from t").Check(testkit.Rows("23"))
+	result = tk.MustQuery("show create table t")
+	c.Assert(result.Rows()[0][1], Equals, "CREATE TABLE `t` (\n"+
+		"  `a` datetime(3) DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),\n"+
+		"  `b` int(11) DEFAULT NULL\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin")
+	tk.MustExec("drop table t")
+}
+
+func (s *testSuite) TestEnumSet(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int, e enum('c','a','b'), s set('z','y','x'), index ie(e), index is_(s))")
+	tk.MustExec("insert into t values (1,'a','x'),(2,'b','y,z'),(3,'c','z'),(4,2,3),(5,null,'')")
+
+	// Enum values are sorted by the element indexes.
+	tk.MustQuery("select id from t order by e, id").Check(testkit.Rows("5", "3", "1", "4", "2"))
+	tk.MustQuery("select id from t use index(ie) where e >= 'a' order by id").Check(testkit.Rows("1", "2", "3", "4"))
+	// Compared with strings, enum and set values are compared as strings, otherwise as numbers.
+	for _, idx := range []string{"use index(ie, is_)", "ignore index(ie, is_)"} {
+		tk.MustQuery("select id from t " + idx + " where e = 'a' order by id").Check(testkit.Rows("1", "4"))
+		tk.MustQuery("select id from t " + idx + " where 'b' = e").Check(testkit.Rows("2"))
+		tk.MustQuery("select id from t " + idx + " where e = 'A'").Check(testkit.Rows())
+		tk.MustQuery("select id from t " + idx + " where e = 'x'").Check(testkit.Rows())
+		tk.MustQuery("select id from t " + idx + " where e = 3").Check(testkit.Rows("2"))
+		tk.MustQuery("select id from t " + idx + " where e < 'b' order by id").Check(testkit.Rows("1", "4"))
+		tk.MustQuery("select id from t " + idx + " where e < 3 order by id").Check(testkit.Rows("1", "3", "4"))
+		tk.MustQuery("select id from t " + idx + " where e in ('b', 1) order by id").Check(testkit.Rows("2", "3"))
+		tk.MustQuery("select id from t " + idx + " where s = 'z,y' order by id").Check(testkit.Rows("2", "4"))
+		tk.MustQuery("select id from t " + idx + " where s = 'y,z'").Check(testkit.Rows())
+		tk.MustQuery("select id from t " + idx + " where s = 4").Check(testkit.Rows("1"))
+		tk.MustQuery("select id from t " + idx + " where s = ''").Check(testkit.Rows("5"))
+	}
+	// MAX and MIN compare enum and set values as strings.
+	tk.MustQuery("select max(e), min(e), max(s), min(s) from t").Check(testkit.Rows("c a z,y "))
+
+	// New elements can be appended without rewriting the data.
+	tk.MustExec("alter table t modify e enum('c','a','b','d')")
+	tk.MustExec("alter table t modify s set('z','y','x','w')")
+	tk.MustExec("insert into t values (6,'d','w,x')")
+	tk.MustQuery("select e, s from t where id in (1, 6) order by id").Check(testkit.Rows("a x", "d x,w"))
+	_, err := tk.Exec("alter table t modify e enum('c','b','a','d')")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("alter table t modify s set('z','y')")
+	c.Assert(err, NotNil)
+	tk.MustExec("drop table t")
+}
+
+func (s *testSuite) TestTimeZone(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int, a timestamp null, b datetime)")
+
+	// Timestamp values are stored in UTC and converted to the session time zone, datetime values are not.
+	tk.MustExec("set time_zone = '+00:00'")
+	tk.MustExec("insert t values (1, '2017-01-01 00:00:00', '2017-01-01 00:00:00')")
+	tk.MustExec("set time_zone = '+08:00'")
+	tk.MustExec("insert t values (2, '2017-01-01 00:00:00', '2017-01-01 00:00:00')")
+	tk.MustQuery("select a, b, unix_timestamp(a) from t order by id").Check(testkit.Rows(
+		"2017-01-01 08:00:00 2017-01-01 00:00:00 1483228800",
+		"2017-01-01 00:00:00 2017-01-01 00:00:00 1483200000"))
+	tk.MustQuery("select id from t where a = '2017-01-01 08:00:00'").Check(testkit.Rows("1"))
+	tk.MustExec("set time_zone = 'Europe/Helsinki'")
+	tk.MustQuery("select a from t order by id").Check(testkit.Rows("2017-01-01 02:00:00", "2016-12-31 18:00:00"))
+	tk.MustQuery("select from_unixtime(0)").Check(testkit.Rows("1970-01-01 02:00:00"))
+	tk.MustExec("set time_zone = 'system'")
+	tk.MustQuery("select @@time_zone").Check(testkit.Rows("system"))
+
+	_, err := tk.Exec("set time_zone = '+13:01'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownTimeZone), IsTrue)
+	_, err = tk.Exec("set global time_zone = 'abc'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownTimeZone), IsTrue)
+
+	tk.MustQuery("select convert_tz('2004-01-01 12:00:00', '+00:00', 'Asia/Shanghai'), convert_tz('2004-01-01 12:00:00', 'abc', '+00:00')").
+		Check(testkit.Rows("2004-01-01 20:00:00 <nil>"))
+	tk.MustExec("drop table t")
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -30,6 +31,11 @@ type ExplainExec struct {
 	StmtPlan plan.Plan
 	rows     []*Row
 	cursor   int
+	// analyzeExec executes the statement for 'explain analyze', it's nil for 'explain'.
+	analyzeExec Executor
+	// execDetailsCtx is the statement context where the runtime statistics are collected, the
+	// ExecInfo column is shown if it's not nil.
+	execDetailsCtx *variable.StatementContext
 }
 
 // Schema implements the Executor Schema interface.
@@ -55,13 +61,53 @@ func (e *ExplainExec) prepareExplainInfo(p plan.Plan, parent plan.Plan) error {
 	row := &Row{
 		Data: types.MakeDatums(p.ID(), string(explain), parentStr),
 	}
+	if e.execDetailsCtx != nil {
+		var execInfo string
+		if details, ok := e.execDetailsCtx.GetExecDetails(p.ID()); ok {
+			execInfo = details.String()
+		}
+		row.Data = append(row.Data, types.NewStringDatum(execInfo))
+	}
 	e.rows = append(e.rows, row)
 	return nil
 }
 
+// Open implements the Executor Open interface.
+func (e *ExplainExec) Open() error {
+	if e.analyzeExec == nil {
+		return nil
+	}
+	// The statement is executed here rather than in Next, because the transaction of an
+	// auto-commit statement is committed once the record set is returned.
+	return errors.Trace(e.runAnalyzeExec())
+}
+
+// runAnalyzeExec executes the statement to the end, the runtime statistics are collected
+// in the statement context.
+func (e *ExplainExec) runAnalyzeExec() (err error) {
+	if err = e.analyzeExec.Open(); err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		if closeErr := e.analyzeExec.Close(); err == nil {
+			err = errors.Trace(closeErr)
+		}
+	}()
+	for {
+		row, err := e.analyzeExec.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			return nil
+		}
+	}
+}
+
 // Next implements Execution Next interface.
 func (e *ExplainExec) Next() (*Row, error) {
-	if e.cursor == 0 {
+	if e.cursor == 0 && e.StmtPlan != nil {
+		// StmtPlan is nil for 'explain for connection' if the connection is not running a statement.
 		err := e.prepareExplainInfo(e.StmtPlan, nil)
 		if err != nil {
 			return nil, errors.Trace(err)
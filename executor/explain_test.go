@@ -14,7 +14,13 @@
 package executor_test
 
 import (
+	"fmt"
+	"strings"
+
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -686,3 +692,72 @@ func (s *testSuite) TestExplain(c *C) {
 		result.Check(testkit.Rows(resultList...))
 	}
 }
+
+func (s *testSuite) TestExplainAnalyze(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3)")
+
+	rows := tk.MustQuery("explain analyze select * from t").Rows()
+	var execInfos []string
+	for _, row := range rows {
+		c.Assert(row, HasLen, 4)
+		if execInfo := row[3].(string); execInfo != "" {
+			execInfos = append(execInfos, execInfo)
+		}
+	}
+	// Only the operator reading the table sends coprocessor requests.
+	c.Assert(execInfos, HasLen, 1)
+	c.Assert(strings.Contains(execInfos[0], "request_count:1"), IsTrue, Commentf("%s", execInfos[0]))
+	c.Assert(strings.Contains(execInfos[0], "total_keys:3"), IsTrue, Commentf("%s", execInfos[0]))
+
+	// The statement is executed.
+	tk.MustQuery("explain analyze insert into t values (4, 4)")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("4"))
+}
+
+func (s *testSuite) TestExplainForConnection(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3)")
+
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	sql := fmt.Sprintf("explain for connection %d", tk1.Se.ShowProcess().ID)
+	sm := &mockSessionManager{}
+	tk.Se.SetSessionManager(sm)
+
+	// The connection is not running a statement.
+	sm.processInfos = []util.ProcessInfo{tk1.Se.ShowProcess()}
+	tk.MustQuery(sql).Check(testkit.Rows())
+
+	// The record set is not closed, the statement is still running.
+	rs, err := tk1.Exec("select * from t where b > 1")
+	c.Assert(err, IsNil)
+	sm.processInfos = []util.ProcessInfo{tk1.Se.ShowProcess()}
+	expected := tk.MustQuery("explain select * from t where b > 1").Rows()
+	rows := tk.MustQuery(sql).Rows()
+	c.Assert(rows, HasLen, len(expected))
+	for i, row := range rows {
+		c.Assert(row, HasLen, 3)
+		c.Assert(row[0], Equals, expected[i][0])
+	}
+	c.Assert(rs.Close(), IsNil)
+	sm.processInfos = []util.ProcessInfo{tk1.Se.ShowProcess()}
+	tk.MustQuery(sql).Check(testkit.Rows())
+
+	_, err = tk.Exec(fmt.Sprintf("explain for connection %d", tk1.Se.ShowProcess().ID+1))
+	c.Assert(terror.ErrorEqual(err, plan.ErrNoSuchThread), IsTrue, Commentf("err %v", err))
+}
@@ -0,0 +1,278 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// maxFKCascadeDepth is the max depth of the cascading foreign key actions, it's the same as MySQL.
+const maxFKCascadeDepth = 15
+
+// fkCascadeKeyType is a dummy type to avoid naming collision in context.
+type fkCascadeKeyType int
+
+// String defines a Stringer function for debugging and pretty printing.
+func (k fkCascadeKeyType) String() string {
+	return "fk_cascade"
+}
+
+// fkCascadeKey is the key of the cascading foreign key actions running in the session.
+const fkCascadeKey fkCascadeKeyType = 0
+
+// fkCascadeState is the state of the cascading foreign key actions started by a row change.
+type fkCascadeState struct {
+	// depth is the number of the nested cascading actions.
+	depth int
+	// updatedTables counts the statements updating a table, including the top statement and the cascading actions.
+	// Like InnoDB, a cascading update fails if it updates a row of a table being updated, which prevents infinite loops.
+	updatedTables map[int64]int
+	// fk is the description of the foreign key of the running cascading action.
+	fk string
+}
+
+// childFK is a foreign key which references a parent table with a CASCADE or SET NULL action.
+type childFK struct {
+	schema model.CIStr
+	table  *model.TableInfo
+	info   *model.FKInfo
+	// refCols are the referenced columns of the parent table.
+	refCols []*table.Column
+}
+
+// fkCache caches the foreign keys referencing the tables written by an executor.
+// The zero value is ready to use.
+type fkCache struct {
+	children map[int64][]*childFK
+	// cascaded is set once a cascading action is executed, so the rows fetched by the executor may be stale.
+	cascaded bool
+}
+
+// cascade runs the ON DELETE or ON UPDATE actions of the foreign keys referencing t for a row change.
+// The actions are executed as internal DELETE or UPDATE statements on the child tables in the same transaction.
+// newRow is nil if the row is deleted.
+func (c *fkCache) cascade(ctx context.Context, t table.Table, oldRow, newRow []types.Datum) error {
+	state, ok := ctx.Value(fkCascadeKey).(*fkCascadeState)
+	if ok {
+		// The row is changed by a cascading action.
+		if state.depth > maxFKCascadeDepth {
+			return ErrFKDepthExceeded.GenByArgs(maxFKCascadeDepth)
+		}
+		if newRow != nil && state.updatedTables[t.Meta().ID] > 1 {
+			return ErrRowIsReferenced.GenByArgs(state.fk)
+		}
+	}
+	is := GetInfoSchema(ctx)
+	children, err := c.get(is, t)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(children) == 0 {
+		return nil
+	}
+	if !ok {
+		state = &fkCascadeState{updatedTables: make(map[int64]int)}
+		if newRow != nil {
+			state.updatedTables[t.Meta().ID] = 1
+		}
+		ctx.SetValue(fkCascadeKey, state)
+		defer ctx.ClearValue(fkCascadeKey)
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	for _, child := range children {
+		opt := ast.ReferOptionType(child.info.OnDelete)
+		if newRow != nil {
+			opt = ast.ReferOptionType(child.info.OnUpdate)
+		}
+		if opt != ast.ReferOptionCascade && opt != ast.ReferOptionSetNull {
+			continue
+		}
+		oldVals := make([]types.Datum, 0, len(child.refCols))
+		var newVals []types.Datum
+		changed := newRow == nil
+		for _, col := range child.refCols {
+			oldVals = append(oldVals, oldRow[col.Offset])
+			if newRow == nil {
+				continue
+			}
+			newVals = append(newVals, newRow[col.Offset])
+			cmp, err := newRow[col.Offset].CompareDatum(sc, oldRow[col.Offset])
+			if err != nil {
+				return errors.Trace(err)
+			}
+			changed = changed || cmp != 0
+		}
+		if !changed || hasNullDatum(oldVals) {
+			// No child row references a NULL key.
+			continue
+		}
+		stmt := child.buildStmt(opt, oldVals, newVals)
+		if err = plan.PrepareStmt(is, ctx, stmt); err != nil {
+			return errors.Trace(err)
+		}
+		_, isUpdate := stmt.(*ast.UpdateStmt)
+		if isUpdate {
+			state.updatedTables[child.table.ID]++
+		}
+		fk := state.fk
+		state.fk = child.String(t.Meta().Name, opt, newRow == nil)
+		state.depth++
+		c.cascaded = true
+		err = execInternalDML(ctx, is, stmt)
+		state.depth--
+		state.fk = fk
+		if isUpdate {
+			state.updatedTables[child.table.ID]--
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// get gets the foreign keys referencing t. The child tables are in the same schema as t.
+func (c *fkCache) get(is infoschema.InfoSchema, t table.Table) ([]*childFK, error) {
+	tblInfo := t.Meta()
+	if children, ok := c.children[tblInfo.ID]; ok {
+		return children, nil
+	}
+	schema, ok := schemaNameByTableID(is, tblInfo.ID)
+	if !ok {
+		return nil, errors.Trace(infoschema.ErrTableNotExists.GenByArgs("", tblInfo.Name))
+	}
+	var children []*childFK
+	for _, tbl := range is.SchemaTables(schema) {
+		for _, fk := range tbl.Meta().ForeignKeys {
+			if fk.State != model.StatePublic || fk.RefTable.L != tblInfo.Name.L || len(fk.RefCols) != len(fk.Cols) {
+				continue
+			}
+			refCols := make([]*table.Column, 0, len(fk.RefCols))
+			for _, name := range fk.RefCols {
+				col := table.FindCol(t.Cols(), name.L)
+				if col == nil {
+					break
+				}
+				refCols = append(refCols, col)
+			}
+			if len(refCols) != len(fk.RefCols) {
+				// The referenced columns aren't checked when the foreign key is created.
+				continue
+			}
+			children = append(children, &childFK{schema: schema, table: tbl.Meta(), info: fk, refCols: refCols})
+		}
+	}
+	if c.children == nil {
+		c.children = make(map[int64][]*childFK)
+	}
+	c.children[tblInfo.ID] = children
+	return children, nil
+}
+
+// buildStmt builds the statement of the action on the child rows referencing oldVals.
+// ON DELETE CASCADE deletes the child rows, ON UPDATE CASCADE sets the foreign key columns to newVals,
+// and SET NULL sets them to NULL.
+func (child *childFK) buildStmt(opt ast.ReferOptionType, oldVals, newVals []types.Datum) ast.DMLNode {
+	tableRefs := &ast.TableRefsClause{TableRefs: &ast.Join{
+		Left: &ast.TableSource{Source: &ast.TableName{Schema: child.schema, Name: child.table.Name}},
+	}}
+	var where ast.ExprNode
+	for i, name := range child.info.Cols {
+		cond := &ast.BinaryOperationExpr{
+			Op: opcode.EQ,
+			L:  &ast.ColumnNameExpr{Name: &ast.ColumnName{Name: name}},
+			R:  datumValueExpr(oldVals[i], &child.refCols[i].FieldType),
+		}
+		if where == nil {
+			where = cond
+		} else {
+			where = &ast.BinaryOperationExpr{Op: opcode.AndAnd, L: where, R: cond}
+		}
+	}
+	if opt == ast.ReferOptionCascade && newVals == nil {
+		return &ast.DeleteStmt{TableRefs: tableRefs, Where: where}
+	}
+	list := make([]*ast.Assignment, 0, len(child.info.Cols))
+	for i, name := range child.info.Cols {
+		var val types.Datum
+		if opt == ast.ReferOptionCascade {
+			val = newVals[i]
+		}
+		list = append(list, &ast.Assignment{
+			Column: &ast.ColumnName{Name: name},
+			Expr:   datumValueExpr(val, &child.refCols[i].FieldType),
+		})
+	}
+	return &ast.UpdateStmt{TableRefs: tableRefs, List: list, Where: where}
+}
+
+// String returns the description of the foreign key in error messages.
+func (child *childFK) String(parent model.CIStr, opt ast.ReferOptionType, onDelete bool) string {
+	cols := make([]string, 0, len(child.info.Cols))
+	for _, col := range child.info.Cols {
+		cols = append(cols, col.O)
+	}
+	refCols := make([]string, 0, len(child.info.RefCols))
+	for _, col := range child.info.RefCols {
+		refCols = append(refCols, col.O)
+	}
+	event := "UPDATE"
+	if onDelete {
+		event = "DELETE"
+	}
+	return fmt.Sprintf("`%s`.`%s`, CONSTRAINT `%s` FOREIGN KEY (`%s`) REFERENCES `%s` (`%s`) ON %s %s",
+		child.schema.O, child.table.Name.O, child.info.Name.O, strings.Join(cols, "`, `"), parent.O,
+		strings.Join(refCols, "`, `"), event, opt)
+}
+
+func datumValueExpr(d types.Datum, ft *types.FieldType) *ast.ValueExpr {
+	expr := &ast.ValueExpr{}
+	expr.SetDatum(d)
+	expr.SetType(ft)
+	return expr
+}
+
+func hasNullDatum(vals []types.Datum) bool {
+	for _, val := range vals {
+		if val.IsNull() {
+			return true
+		}
+	}
+	return false
+}
+
+// latestRow returns the latest data of the row h read by an executor before the cascading actions are executed.
+// It returns false if the row has been deleted by a cascading action.
+func (c *fkCache) latestRow(ctx context.Context, t table.Table, h int64, data []types.Datum) ([]types.Datum, bool) {
+	if !c.cascaded {
+		return data, true
+	}
+	dt := getDirtyDB(ctx).getDirtyTable(t.Meta().ID)
+	if row, ok := dt.addedRows[h]; ok {
+		return row, true
+	}
+	_, deleted := dt.deletedRows[h]
+	return data, !deleted
+}
@@ -46,6 +46,7 @@ type GrantExec struct {
 	Level      *ast.GrantLevel
 	Users      []*ast.UserSpec
 	WithGrant  bool
+	Text       string
 
 	ctx  context.Context
 	is   infoschema.InfoSchema
@@ -128,6 +129,10 @@ func (e *GrantExec) Next() (*Row, error) {
 			}
 		}
 	}
+	err := addPrivilegeHistory(e.ctx, e.Text, userSpecAccounts(e.Users))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	e.done = true
 	sessionctx.GetDomain(e.ctx).NotifyUpdatePrivilege(e.ctx)
 	return nil, nil
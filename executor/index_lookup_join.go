@@ -0,0 +1,334 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var _ Executor = &IndexLookUpJoin{}
+
+// IndexLookUpJoin implements the index nested loop join. It reads a batch of the outer rows, then reads the inner rows
+// by the index or the handle with the join keys of the batch, and joins them by a hash table built from the inner rows.
+type IndexLookUpJoin struct {
+	baseExecutor
+
+	// innerExec is opened for every batch with the ranges of the join keys, it's a table reader, an index reader or
+	// an index lookup reader, which may be wrapped by a union scan.
+	innerExec   Executor
+	outerKeys   []*expression.Column
+	innerKeys   []*expression.Column
+	outerFilter expression.CNFExprs
+	innerFilter expression.CNFExprs
+	otherFilter expression.CNFExprs
+	outer       bool
+	// outerIsRight means the outer child is the right child of the join, the output rows are in the order of the join.
+	outerIsRight  bool
+	defaultValues []types.Datum
+	// targetTypes are the types that both the outer keys and the inner keys are converted to for the hash keys.
+	targetTypes []*types.FieldType
+
+	exhausted  bool
+	resultRows []*Row
+	cursor     int
+}
+
+// Open implements the Executor Open interface.
+func (e *IndexLookUpJoin) Open() error {
+	e.exhausted = false
+	e.resultRows = nil
+	e.cursor = 0
+	return errors.Trace(e.baseExecutor.Open())
+}
+
+// Close implements the Executor Close interface.
+func (e *IndexLookUpJoin) Close() error {
+	e.resultRows = nil
+	return errors.Trace(e.baseExecutor.Close())
+}
+
+// Next implements the Executor Next interface.
+func (e *IndexLookUpJoin) Next() (*Row, error) {
+	for e.cursor >= len(e.resultRows) {
+		if e.exhausted {
+			return nil, nil
+		}
+		if err := e.joinNextBatch(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	row := e.resultRows[e.cursor]
+	e.cursor++
+	return row, nil
+}
+
+// joinNextBatch reads a batch of the outer rows and joins them with the inner rows.
+func (e *IndexLookUpJoin) joinNextBatch() error {
+	e.resultRows = e.resultRows[:0]
+	e.cursor = 0
+	outerRows, err := e.fetchOuterRows()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(outerRows) == 0 {
+		return nil
+	}
+	sc := e.ctx.GetSessionVars().StmtCtx
+	// hashKeys[i] is nil if the i-th outer row can't match any inner row.
+	hashKeys := make([][]byte, len(outerRows))
+	lookUpKeys := make([][]types.Datum, 0, len(outerRows))
+	lookUpKeySet := make(map[string]struct{}, len(outerRows))
+	vals := make([]types.Datum, len(e.outerKeys))
+	for i, row := range outerRows {
+		matched, err := expression.EvalBool(e.outerFilter, row.Data, e.ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !matched {
+			continue
+		}
+		hasNull, hashKey, err := getJoinKey(sc, e.outerKeys, row, e.targetTypes, vals, nil)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if hasNull {
+			continue
+		}
+		lookUpKey, ok := e.getLookUpKey(row)
+		if !ok {
+			continue
+		}
+		hashKeys[i] = hashKey
+		encoded, err := codec.EncodeKey(nil, lookUpKey...)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if _, ok := lookUpKeySet[string(encoded)]; !ok {
+			lookUpKeySet[string(encoded)] = struct{}{}
+			lookUpKeys = append(lookUpKeys, lookUpKey)
+		}
+	}
+	var innerRows map[string][]*Row
+	if len(lookUpKeys) > 0 {
+		innerRows, err = e.fetchInnerRows(lookUpKeys)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for i, outerRow := range outerRows {
+		matched := false
+		if hashKeys[i] != nil {
+			for _, innerRow := range innerRows[string(hashKeys[i])] {
+				joinedRow := makeJoinRow(outerRow, innerRow)
+				ok, err := expression.EvalBool(e.otherFilter, joinedRow.Data, e.ctx)
+				if err != nil {
+					return errors.Trace(err)
+				}
+				if !ok {
+					continue
+				}
+				matched = true
+				if e.outerIsRight {
+					joinedRow = makeJoinRow(innerRow, outerRow)
+				}
+				e.resultRows = append(e.resultRows, joinedRow)
+			}
+		}
+		if !matched && e.outer {
+			e.resultRows = append(e.resultRows, e.fillRowWithDefaultValues(outerRow))
+		}
+	}
+	return nil
+}
+
+// fetchOuterRows reads a batch of the outer rows, e.exhausted is set if the outer rows run out.
+func (e *IndexLookUpJoin) fetchOuterRows() ([]*Row, error) {
+	size := e.ctx.GetSessionVars().IndexJoinBatchSize
+	rows := make([]*Row, 0, size)
+	for len(rows) < size {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			e.exhausted = true
+			break
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// getLookUpKey converts the join keys of the outer row to the types of the inner keys. It returns false if the
+// converted values are not equal to the original ones, so there can't be any matched inner row.
+func (e *IndexLookUpJoin) getLookUpKey(row *Row) ([]types.Datum, bool) {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	key := make([]types.Datum, len(e.outerKeys))
+	for i, col := range e.outerKeys {
+		val, err := col.Eval(row.Data)
+		if err != nil {
+			return nil, false
+		}
+		key[i], err = val.ConvertTo(sc, e.innerKeys[i].GetType())
+		if err != nil {
+			return nil, false
+		}
+		cmp, err := key[i].CompareDatum(sc, val)
+		if err != nil || cmp != 0 {
+			return nil, false
+		}
+	}
+	return key, true
+}
+
+// fetchInnerRows reads the inner rows matching lookUpKeys and returns them grouped by the hash keys.
+func (e *IndexLookUpJoin) fetchInnerRows(lookUpKeys [][]types.Datum) (map[string][]*Row, error) {
+	err := e.setInnerRanges(lookUpKeys)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = e.innerExec.Open(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	rows, err := e.readInnerRows()
+	closeErr := e.innerExec.Close()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return rows, errors.Trace(closeErr)
+}
+
+func (e *IndexLookUpJoin) readInnerRows() (map[string][]*Row, error) {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	rows := make(map[string][]*Row)
+	vals := make([]types.Datum, len(e.innerKeys))
+	for {
+		row, err := e.innerExec.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			return rows, nil
+		}
+		matched, err := expression.EvalBool(e.innerFilter, row.Data, e.ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !matched {
+			continue
+		}
+		hasNull, hashKey, err := getJoinKey(sc, e.innerKeys, row, e.targetTypes, vals, nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if hasNull {
+			continue
+		}
+		rows[string(hashKey)] = append(rows[string(hashKey)], row)
+	}
+}
+
+// setInnerRanges sets the ranges of the inner reader to the point ranges of lookUpKeys, which are sorted.
+func (e *IndexLookUpJoin) setInnerRanges(lookUpKeys [][]types.Datum) error {
+	reader := e.innerExec
+	if us, ok := reader.(*UnionScanExec); ok {
+		reader = us.children[0]
+	}
+	switch x := reader.(type) {
+	case *TableReaderExecutor:
+		handles := make([]int64, 0, len(lookUpKeys))
+		for _, key := range lookUpKeys {
+			if key[0].Kind() == types.KindUint64 {
+				handles = append(handles, int64(key[0].GetUint64()))
+			} else {
+				handles = append(handles, key[0].GetInt64())
+			}
+		}
+		sort.Sort(int64Slice(handles))
+		x.ranges = make([]types.IntColumnRange, 0, len(handles))
+		for _, h := range handles {
+			x.ranges = append(x.ranges, types.IntColumnRange{LowVal: h, HighVal: h})
+		}
+	case *IndexReaderExecutor:
+		ranges, err := indexJoinRanges(lookUpKeys)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		x.ranges = ranges
+	case *IndexLookUpExecutor:
+		ranges, err := indexJoinRanges(lookUpKeys)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		x.ranges = ranges
+	default:
+		return errors.Errorf("unsupported inner executor %T of index lookup join", reader)
+	}
+	return nil
+}
+
+// indexJoinRanges builds the point ranges of the index values, which are sorted by the encoded values.
+func indexJoinRanges(lookUpKeys [][]types.Datum) ([]*types.IndexRange, error) {
+	encoded := make([][]byte, len(lookUpKeys))
+	for i, key := range lookUpKeys {
+		var err error
+		encoded[i], err = codec.EncodeKey(nil, key...)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	sort.Sort(&indexJoinKeySorter{keys: lookUpKeys, encoded: encoded})
+	ranges := make([]*types.IndexRange, 0, len(lookUpKeys))
+	for _, key := range lookUpKeys {
+		ranges = append(ranges, &types.IndexRange{LowVal: key, HighVal: key})
+	}
+	return ranges, nil
+}
+
+type indexJoinKeySorter struct {
+	keys    [][]types.Datum
+	encoded [][]byte
+}
+
+func (s *indexJoinKeySorter) Len() int {
+	return len(s.keys)
+}
+
+func (s *indexJoinKeySorter) Less(i, j int) bool {
+	return bytes.Compare(s.encoded[i], s.encoded[j]) < 0
+}
+
+func (s *indexJoinKeySorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.encoded[i], s.encoded[j] = s.encoded[j], s.encoded[i]
+}
+
+// fillRowWithDefaultValues creates a result row filled with default values from an outer row.
+// It is used for outer join, when a row from outer table doesn't have any matching rows.
+func (e *IndexLookUpJoin) fillRowWithDefaultValues(outerRow *Row) *Row {
+	innerRow := &Row{
+		Data: make([]types.Datum, e.innerExec.Schema().Len()),
+	}
+	copy(innerRow.Data, e.defaultValues)
+	if e.outerIsRight {
+		return makeJoinRow(innerRow, outerRow)
+	}
+	return makeJoinRow(outerRow, innerRow)
+}
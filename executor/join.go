@@ -16,6 +16,7 @@ package executor
 import (
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
@@ -72,6 +73,13 @@ type HashJoinExec struct {
 	// rowKeyCache is used to store the table and table name from a row.
 	// Because every row has the same table name and table, we can use a single row key cache.
 	rowKeyCache []*RowKeyEntry
+
+	// spilledSmall and spilledBig are the partitions of both sides written to the temporary files after the hash
+	// table exceeds the memory quota, they are nil if the hash join is done in memory.
+	spilledSmall      *spillPartitions
+	spilledBig        *spillPartitions
+	bigRowKeyCache    []*RowKeyEntry
+	hashTableMemUsage int
 }
 
 // hashJoinCtx holds the variables needed to do a hash join in one of many concurrent goroutines.
@@ -81,6 +89,23 @@ type hashJoinCtx struct {
 	// datumBuffer is used for encode hash keys.
 	datumBuffer   []types.Datum
 	hashKeyBuffer []byte
+	// bigRowsBuffer and bigMatchedBuffer are used for evaluating the big filter over a batch of big table rows.
+	bigRowsBuffer    [][]types.Datum
+	bigMatchedBuffer []bool
+}
+
+// filterBigRows evaluates the big filter over a batch of big table rows, and returns whether each of them matches.
+func (ctx *hashJoinCtx) filterBigRows(rows []*Row, sctx context.Context) ([]bool, error) {
+	ctx.bigRowsBuffer = ctx.bigRowsBuffer[:0]
+	for _, row := range rows {
+		ctx.bigRowsBuffer = append(ctx.bigRowsBuffer, row.Data)
+	}
+	if cap(ctx.bigMatchedBuffer) < len(rows) {
+		ctx.bigMatchedBuffer = make([]bool, len(rows))
+	}
+	matched := ctx.bigMatchedBuffer[:len(rows)]
+	err := expression.VecEvalBool(ctx.bigFilter, ctx.bigRowsBuffer, sctx, matched)
+	return matched, errors.Trace(err)
 }
 
 // Close implements the Executor Close interface.
@@ -91,6 +116,7 @@ func (e *HashJoinExec) Close() error {
 		}
 		<-e.closeCh
 	}
+	e.closeSpilledPartitions()
 	e.rows = nil
 	return nil
 }
@@ -219,14 +245,23 @@ func (e *HashJoinExec) fetchBigExec() {
 
 // prepare runs the first time when 'Next' is called, it starts one worker goroutine to fetch rows from the big table,
 // and reads all data from the small table to build a hash table, then starts multiple join worker goroutines.
-func (e *HashJoinExec) prepare() error {
+// If the hash table exceeds the memory quota, the small table is partitioned to the disk instead, and a single
+// worker joins the partitions one by one.
+func (e *HashJoinExec) prepare() (err error) {
+	defer func() {
+		if err != nil {
+			e.closeSpilledPartitions()
+		}
+	}()
 	// Start a worker to fetch big table rows.
 	e.wg.Add(1)
 	go e.fetchBigExec()
 
 	e.hashTable = mvmap.NewMVMap()
+	e.hashTableMemUsage = 0
 	e.cursor = 0
 	sc := e.ctx.GetSessionVars().StmtCtx
+	memQuota := e.ctx.GetSessionVars().HashJoinMemQuota
 	var buffer []byte
 	for {
 		row, err := e.smallExec.Next()
@@ -257,14 +292,31 @@ func (e *HashJoinExec) prepare() error {
 		if err != nil {
 			return errors.Trace(err)
 		}
+		if e.spilledSmall != nil {
+			if err = e.spilledSmall.put(joinKey, buffer); err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
 		e.hashTable.Put(joinKey, buffer)
+		e.hashTableMemUsage += len(joinKey) + len(buffer)
+		if memQuota > 0 && e.hashTableMemUsage > memQuota {
+			if err = e.spillHashTable(); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	}
 
 	e.resultCh = make(chan *execResult, e.concurrency)
 
-	for i := 0; i < e.concurrency; i++ {
+	if e.spilledSmall != nil {
 		e.wg.Add(1)
-		go e.runJoinWorker(i)
+		go e.runSpilledJoin()
+	} else {
+		for i := 0; i < e.concurrency; i++ {
+			e.wg.Add(1)
+			go e.runJoinWorker(i)
+		}
 	}
 	go e.waitJoinWorkersAndCloseResultChan()
 
@@ -273,21 +325,31 @@ func (e *HashJoinExec) prepare() error {
 }
 
 func (e *HashJoinExec) encodeRow(b []byte, row *Row) ([]byte, error) {
+	return encodeJoinRow(b, row, &e.rowKeyCache, e.ctx.GetSessionVars().GetTimeZone())
+}
+
+func (e *HashJoinExec) decodeRow(data []byte) (*Row, error) {
+	return decodeJoinRow(data, e.rowKeyCache, e.smallExec.Schema(), e.ctx.GetSessionVars().GetTimeZone())
+}
+
+// encodeJoinRow encodes the handles and the values of a row, the tables of the row keys are kept in rowKeyCache
+// because every row from the same executor has the same tables.
+func encodeJoinRow(b []byte, row *Row, rowKeyCache *[]*RowKeyEntry, loc *time.Location) ([]byte, error) {
 	numRowKeys := int64(len(row.RowKeys))
 	b = codec.EncodeVarint(b, numRowKeys)
 	for _, rowKey := range row.RowKeys {
 		b = codec.EncodeVarint(b, rowKey.Handle)
 	}
-	if numRowKeys > 0 && e.rowKeyCache == nil {
-		e.rowKeyCache = make([]*RowKeyEntry, len(row.RowKeys))
+	if numRowKeys > 0 && *rowKeyCache == nil {
+		cache := make([]*RowKeyEntry, len(row.RowKeys))
 		for i := 0; i < len(row.RowKeys); i++ {
 			rk := new(RowKeyEntry)
 			rk.Tbl = row.RowKeys[i].Tbl
 			rk.TableName = row.RowKeys[i].TableName
-			e.rowKeyCache[i] = rk
+			cache[i] = rk
 		}
+		*rowKeyCache = cache
 	}
-	loc := e.ctx.GetSessionVars().GetTimeZone()
 	for _, datum := range row.Data {
 		tmp, err := tablecodec.EncodeValue(datum, loc)
 		if err != nil {
@@ -298,7 +360,8 @@ func (e *HashJoinExec) encodeRow(b []byte, row *Row) ([]byte, error) {
 	return b, nil
 }
 
-func (e *HashJoinExec) decodeRow(data []byte) (*Row, error) {
+// decodeJoinRow decodes a row encoded by encodeJoinRow.
+func decodeJoinRow(data []byte, rowKeyCache []*RowKeyEntry, schema *expression.Schema, loc *time.Location) (*Row, error) {
 	row := new(Row)
 	data, entryLen, err := codec.DecodeVarint(data)
 	if err != nil {
@@ -310,16 +373,16 @@ func (e *HashJoinExec) decodeRow(data []byte) (*Row, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		entry.Tbl = e.rowKeyCache[i].Tbl
-		entry.TableName = e.rowKeyCache[i].TableName
+		entry.Tbl = rowKeyCache[i].Tbl
+		entry.TableName = rowKeyCache[i].TableName
 		row.RowKeys = append(row.RowKeys, entry)
 	}
-	values := make([]types.Datum, e.smallExec.Schema().Len())
+	values := make([]types.Datum, schema.Len())
 	err = codec.SetRawValues(data, values)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	err = decodeRawValues(values, e.smallExec.Schema(), e.ctx.GetSessionVars().GetTimeZone())
+	err = decodeRawValues(values, schema, loc)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -359,8 +422,14 @@ func (e *HashJoinExec) runJoinWorker(idx int) {
 			e.resultCh <- &execResult{err: errors.Trace(bigTableResult.err)}
 			break
 		}
-		for _, bigRow := range bigTableResult.rows {
-			succ := e.joinOneBigRow(e.hashJoinContexts[idx], bigRow, result)
+		ctx := e.hashJoinContexts[idx]
+		bigMatched, err := ctx.filterBigRows(bigTableResult.rows, e.ctx)
+		if err != nil {
+			result.err = errors.Trace(err)
+			continue
+		}
+		for i, bigRow := range bigTableResult.rows {
+			succ := e.joinOneBigRow(ctx, bigRow, bigMatched[i], result)
 			if !succ {
 				break
 			}
@@ -379,17 +448,11 @@ func (e *HashJoinExec) runJoinWorker(idx int) {
 // joinOneBigRow creates result rows from a row in a big table and sends them to resultRows channel.
 // Every matching row generates a result row.
 // If there are no matching rows and it is outer join, a null filled result row is created.
-func (e *HashJoinExec) joinOneBigRow(ctx *hashJoinCtx, bigRow *Row, result *execResult) bool {
+func (e *HashJoinExec) joinOneBigRow(ctx *hashJoinCtx, bigRow *Row, bigMatched bool, result *execResult) bool {
 	var (
 		matchedRows []*Row
 		err         error
 	)
-	bigMatched := true
-	bigMatched, err = expression.EvalBool(ctx.bigFilter, bigRow.Data, e.ctx)
-	if err != nil {
-		result.err = errors.Trace(err)
-		return false
-	}
 	if bigMatched {
 		matchedRows, err = e.constructMatchedRows(ctx, bigRow)
 		if err != nil {
@@ -418,6 +481,11 @@ func (e *HashJoinExec) constructMatchedRows(ctx *hashJoinCtx, bigRow *Row) (matc
 	if hasNull {
 		return
 	}
+	return e.matchRowsByKey(ctx, bigRow, joinKey)
+}
+
+// matchRowsByKey creates matching result rows from a row in the big table and its join key.
+func (e *HashJoinExec) matchRowsByKey(ctx *hashJoinCtx, bigRow *Row, joinKey []byte) (matchedRows []*Row, err error) {
 	values := e.hashTable.Get(joinKey)
 	if len(values) == 0 {
 		return
@@ -572,28 +640,34 @@ func (e *NestedLoopJoinExec) fetchBigRow() (*Row, bool, error) {
 // prepare runs the first time when 'Next' is called and it reads all data from the small table and stores
 // them in a slice.
 func (e *NestedLoopJoinExec) prepare() error {
+	var err error
+	e.innerRows, err = e.fetchInnerRows(e.innerRows[:0])
+	e.prepared = true
+	return errors.Trace(err)
+}
+
+// fetchInnerRows reads the small table rows passing the small filter and appends them to rows.
+func (e *NestedLoopJoinExec) fetchInnerRows(rows []*Row) ([]*Row, error) {
 	err := e.SmallExec.Open()
 	if err != nil {
-		return errors.Trace(err)
+		return rows, errors.Trace(err)
 	}
 	defer e.SmallExec.Close()
-	e.innerRows = e.innerRows[:0]
-	e.prepared = true
 	for {
 		row, err := e.SmallExec.Next()
 		if err != nil {
-			return errors.Trace(err)
+			return rows, errors.Trace(err)
 		}
 		if row == nil {
-			return nil
+			return rows, nil
 		}
 
 		matched, err := expression.EvalBool(e.SmallFilter, row.Data, e.Ctx)
 		if err != nil {
-			return errors.Trace(err)
+			return rows, errors.Trace(err)
 		}
 		if matched {
-			e.innerRows = append(e.innerRows, row)
+			rows = append(rows, row)
 		}
 	}
 }
@@ -612,13 +686,18 @@ func (e *NestedLoopJoinExec) fillRowWithDefaultValue(bigRow *Row) (returnRow *Ro
 }
 
 func (e *NestedLoopJoinExec) doJoin(bigRow *Row, match bool) ([]*Row, error) {
+	return e.joinInnerRows(bigRow, match, e.innerRows)
+}
+
+// joinInnerRows joins the big row with the small table rows.
+func (e *NestedLoopJoinExec) joinInnerRows(bigRow *Row, match bool, innerRows []*Row) ([]*Row, error) {
 	e.resultRows = e.resultRows[0:0]
 	if !match && e.outer {
 		row := e.fillRowWithDefaultValue(bigRow)
 		e.resultRows = append(e.resultRows, row)
 		return e.resultRows, nil
 	}
-	for _, row := range e.innerRows {
+	for _, row := range innerRows {
 		var mergedRow *Row
 		if e.leftSmall {
 			mergedRow = makeJoinRow(row, bigRow)
@@ -682,16 +761,27 @@ type HashSemiJoinExec struct {
 	resultRows   []*Row
 	// auxMode is a mode that the result row always returns with an extra column which stores a boolean
 	// or NULL value to indicate if this row is matched.
-	auxMode           bool
-	targetTypes       []*types.FieldType
-	smallTableHasNull bool
+	auxMode     bool
+	targetTypes []*types.FieldType
 	// anti is true, semi join only output the unmatched row.
 	anti bool
+
+	// smallNAKey and bigNAKey are the arguments of the null-aware equal conditions. When they exist, the hash
+	// table is keyed by both the hash keys and the null-aware keys. A NULL null-aware key makes the comparison
+	// unknown, so the small rows that may compare to NULL are also indexed by the hash keys only:
+	// naNullTable holds the small rows having NULL null-aware keys, and naAllTable holds all the small rows.
+	smallNAKey    []expression.Expression
+	bigNAKey      []expression.Expression
+	naTargetTypes []*types.FieldType
+	naNullTable   map[string][]*Row
+	naAllTable    map[string][]*Row
 }
 
 // Close implements the Executor Close interface.
 func (e *HashSemiJoinExec) Close() error {
 	e.hashTable = nil
+	e.naNullTable = nil
+	e.naAllTable = nil
 	e.resultRows = nil
 	return e.bigExec.Close()
 }
@@ -699,7 +789,6 @@ func (e *HashSemiJoinExec) Close() error {
 // Open implements the Executor Open interface.
 func (e *HashSemiJoinExec) Open() error {
 	e.prepared = false
-	e.smallTableHasNull = false
 	e.hashTable = make(map[string][]*Row)
 	e.resultRows = make([]*Row, 1)
 	return errors.Trace(e.bigExec.Open())
@@ -710,6 +799,29 @@ func (e *HashSemiJoinExec) Schema() *expression.Schema {
 	return e.schema
 }
 
+// getNAKey evaluates the null-aware keys and converts them to the target types.
+// It returns true if any of the keys is NULL.
+func getNAKey(sc *variable.StatementContext, exprs []expression.Expression, row *Row, targetTypes []*types.FieldType,
+	vals []types.Datum) (bool, error) {
+	hasNull := false
+	for i, expr := range exprs {
+		val, err := expr.Eval(row.Data)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if val.IsNull() {
+			vals[i] = val
+			hasNull = true
+			continue
+		}
+		vals[i], err = val.ConvertTo(sc, targetTypes[i])
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+	}
+	return hasNull, nil
+}
+
 // prepare runs the first time when 'Next' is called and it reads all data from the small table and stores
 // them in a hash table.
 func (e *HashSemiJoinExec) prepare() error {
@@ -719,9 +831,12 @@ func (e *HashSemiJoinExec) prepare() error {
 	}
 	defer e.smallExec.Close()
 	e.hashTable = make(map[string][]*Row)
+	e.naNullTable = make(map[string][]*Row)
+	e.naAllTable = make(map[string][]*Row)
 	sc := e.ctx.GetSessionVars().StmtCtx
 	e.resultRows = make([]*Row, 1)
 	e.prepared = true
+	naVals := make([]types.Datum, len(e.smallNAKey))
 	for {
 		row, err := e.smallExec.Next()
 		if err != nil {
@@ -742,43 +857,112 @@ func (e *HashSemiJoinExec) prepare() error {
 		if err != nil {
 			return errors.Trace(err)
 		}
+		// A NULL hash key never equals to the key of a big row, so the row can't be a match.
 		if hasNull {
-			e.smallTableHasNull = true
 			continue
 		}
-		if rows, ok := e.hashTable[string(hashcode)]; !ok {
-			e.hashTable[string(hashcode)] = []*Row{row}
-		} else {
-			e.hashTable[string(hashcode)] = append(rows, row)
+		if len(e.smallNAKey) > 0 {
+			naHasNull, err := getNAKey(sc, e.smallNAKey, row, e.naTargetTypes, naVals)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			e.naAllTable[string(hashcode)] = append(e.naAllTable[string(hashcode)], row)
+			if naHasNull {
+				e.naNullTable[string(hashcode)] = append(e.naNullTable[string(hashcode)], row)
+				continue
+			}
+			hashcode, err = codec.EncodeValue(hashcode, naVals...)
+			if err != nil {
+				return errors.Trace(err)
+			}
 		}
+		e.hashTable[string(hashcode)] = append(e.hashTable[string(hashcode)], row)
 	}
 }
 
+// rowIsMatched checks if the big row has a matched small row. If no small row is matched but the null-aware
+// equal conditions are unknown for some small rows, hasNull will be true.
 func (e *HashSemiJoinExec) rowIsMatched(bigRow *Row) (matched bool, hasNull bool, err error) {
 	sc := e.ctx.GetSessionVars().StmtCtx
-	hasNull, hashcode, err := getJoinKey(sc, e.bigHashKey, bigRow, e.targetTypes, make([]types.Datum, len(e.smallHashKey)), nil)
+	keyHasNull, hashcode, err := getJoinKey(sc, e.bigHashKey, bigRow, e.targetTypes, make([]types.Datum, len(e.bigHashKey)), nil)
 	if err != nil {
 		return false, false, errors.Trace(err)
 	}
-	if hasNull {
-		return false, true, nil
+	if keyHasNull {
+		return false, false, nil
 	}
-	rows, ok := e.hashTable[string(hashcode)]
-	if !ok {
-		return
+	eqKey := string(hashcode)
+	var naVals []types.Datum
+	if len(e.bigNAKey) > 0 {
+		naVals = make([]types.Datum, len(e.bigNAKey))
+		naHasNull, err := getNAKey(sc, e.bigNAKey, bigRow, e.naTargetTypes, naVals)
+		if err != nil {
+			return false, false, errors.Trace(err)
+		}
+		if naHasNull {
+			// The null-aware equal conditions can't be true, check if they are unknown for any small row.
+			hasNull, err = e.naRowsMayMatch(bigRow, naVals, e.naAllTable[eqKey])
+			return false, hasNull, errors.Trace(err)
+		}
+		hashcode, err = codec.EncodeValue(hashcode, naVals...)
+		if err != nil {
+			return false, false, errors.Trace(err)
+		}
 	}
 	// match eq condition
-	for _, smallRow := range rows {
+	for _, smallRow := range e.hashTable[string(hashcode)] {
 		matchedRow := makeJoinRow(bigRow, smallRow)
 		matched, err = expression.EvalBool(e.otherFilter, matchedRow.Data, e.ctx)
 		if err != nil {
 			return false, false, errors.Trace(err)
 		}
 		if matched {
-			return
+			return true, false, nil
+		}
+	}
+	if len(e.bigNAKey) > 0 {
+		hasNull, err = e.naRowsMayMatch(bigRow, naVals, e.naNullTable[eqKey])
+		return false, hasNull, errors.Trace(err)
+	}
+	return false, false, nil
+}
+
+// naRowsMayMatch checks if the null-aware equal conditions are unknown for any of the small rows, that is,
+// the other conditions are true and every pair of the non-NULL null-aware keys is equal.
+func (e *HashSemiJoinExec) naRowsMayMatch(bigRow *Row, bigVals []types.Datum, smallRows []*Row) (bool, error) {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	smallVals := make([]types.Datum, len(e.smallNAKey))
+	for _, smallRow := range smallRows {
+		_, err := getNAKey(sc, e.smallNAKey, smallRow, e.naTargetTypes, smallVals)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		mayMatch := true
+		for i := range bigVals {
+			if bigVals[i].IsNull() || smallVals[i].IsNull() {
+				continue
+			}
+			cmp, err := bigVals[i].CompareDatum(sc, smallVals[i])
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			if cmp != 0 {
+				mayMatch = false
+				break
+			}
+		}
+		if !mayMatch {
+			continue
+		}
+		matched, err := expression.EvalBool(e.otherFilter, makeJoinRow(bigRow, smallRow).Data, e.ctx)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if matched {
+			return true, nil
 		}
 	}
-	return
+	return false, nil
 }
 
 func (e *HashSemiJoinExec) fetchBigRow() (*Row, bool, error) {
@@ -813,9 +997,6 @@ func (e *HashSemiJoinExec) doJoin(bigRow *Row, match bool) ([]*Row, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	if !matched && e.smallTableHasNull {
-		isNull = true
-	}
 	if e.anti && !isNull {
 		matched = !matched
 	}
@@ -866,6 +1047,11 @@ type ApplyJoinExec struct {
 	cursor      int
 	resultRows  []*Row
 	schema      *expression.Schema
+
+	// prefetch means the inner rows of the next outer batch are read in a background goroutine while
+	// the current batch is being joined, see applyPrefetcher.
+	prefetch   bool
+	prefetcher *applyPrefetcher
 }
 
 // Schema implements the Executor interface.
@@ -875,11 +1061,13 @@ func (e *ApplyJoinExec) Schema() *expression.Schema {
 
 // Close implements the Executor interface.
 func (e *ApplyJoinExec) Close() error {
+	e.stopPrefetch()
 	return nil
 }
 
 // Open implements the Executor interface.
 func (e *ApplyJoinExec) Open() error {
+	e.stopPrefetch()
 	e.cursor = 0
 	e.resultRows = nil
 	return errors.Trace(e.join.Open())
@@ -893,6 +1081,18 @@ func (e *ApplyJoinExec) Next() (*Row, error) {
 			e.cursor++
 			return row, nil
 		}
+		if e.prefetch {
+			var (
+				finished bool
+				err      error
+			)
+			e.resultRows, finished, err = e.joinPrefetchedRow()
+			if finished || err != nil {
+				return nil, errors.Trace(err)
+			}
+			e.cursor = 0
+			continue
+		}
 		bigRow, match, err := e.join.fetchBigRow()
 		if bigRow == nil || err != nil {
 			return nil, errors.Trace(err)
@@ -0,0 +1,297 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/util/mvmap"
+)
+
+// spillFanout is the number of the partitions of a spilled hash join. Rows with the same join key are always in the
+// same partition, so every partition is joined independently with a hash table built from its small table rows.
+const spillFanout = 32
+
+// spillPartitions is a set of temporary files, each of them holds the (join key, encoded row) records of a partition.
+type spillPartitions struct {
+	files   []*os.File
+	writers []*bufio.Writer
+	lenBuf  [binary.MaxVarintLen64]byte
+}
+
+func newSpillPartitions(fanout int) (*spillPartitions, error) {
+	p := &spillPartitions{
+		files:   make([]*os.File, 0, fanout),
+		writers: make([]*bufio.Writer, 0, fanout),
+	}
+	for i := 0; i < fanout; i++ {
+		f, err := ioutil.TempFile("", "tidb-hash-join-")
+		if err != nil {
+			p.close()
+			return nil, errors.Trace(err)
+		}
+		p.files = append(p.files, f)
+		p.writers = append(p.writers, bufio.NewWriter(f))
+	}
+	return p, nil
+}
+
+// put appends a record to the partition chosen by the join key.
+func (p *spillPartitions) put(key, row []byte) error {
+	h := fnv.New32a()
+	h.Write(key)
+	w := p.writers[h.Sum32()%uint32(len(p.writers))]
+	for _, b := range [][]byte{key, row} {
+		n := binary.PutUvarint(p.lenBuf[:], uint64(len(b)))
+		if _, err := w.Write(p.lenBuf[:n]); err != nil {
+			return errors.Trace(err)
+		}
+		if _, err := w.Write(b); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// finish flushes the written records and rewinds the files for reading.
+func (p *spillPartitions) finish() error {
+	for i, w := range p.writers {
+		if err := w.Flush(); err != nil {
+			return errors.Trace(err)
+		}
+		if _, err := p.files[i].Seek(0, io.SeekStart); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (p *spillPartitions) reader(idx int) *bufio.Reader {
+	return bufio.NewReader(p.files[idx])
+}
+
+// close closes and removes the temporary files.
+func (p *spillPartitions) close() {
+	for _, f := range p.files {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	p.files = nil
+	p.writers = nil
+}
+
+// readSpilledRecord reads a record written by spillPartitions.put, it returns io.EOF if there are no more records.
+func readSpilledRecord(r *bufio.Reader) (key, row []byte, err error) {
+	key, err = readSpilledBytes(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	row, err = readSpilledBytes(r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return key, row, errors.Trace(err)
+}
+
+func readSpilledBytes(r *bufio.Reader) ([]byte, error) {
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, l)
+	_, err = io.ReadFull(r, b)
+	return b, errors.Trace(err)
+}
+
+// spillHashTable moves the rows in the hash table to the small table partitions, the following small table rows
+// are written to the partitions directly.
+func (e *HashJoinExec) spillHashTable() error {
+	var err error
+	e.spilledSmall, err = newSpillPartitions(spillFanout)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	it := e.hashTable.NewIterator()
+	for key, value := it.Next(); key != nil; key, value = it.Next() {
+		if err = e.spilledSmall.put(key, value); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	e.hashTable = nil
+	e.hashTableMemUsage = 0
+	return nil
+}
+
+func (e *HashJoinExec) closeSpilledPartitions() {
+	if e.spilledSmall != nil {
+		e.spilledSmall.close()
+		e.spilledSmall = nil
+	}
+	if e.spilledBig != nil {
+		e.spilledBig.close()
+		e.spilledBig = nil
+	}
+}
+
+// runSpilledJoin partitions the big table rows in the same way as the small table rows,
+// then joins the partitions one by one.
+func (e *HashJoinExec) runSpilledJoin() {
+	defer func() {
+		e.closeSpilledPartitions()
+		e.wg.Done()
+	}()
+	exit, err := e.partitionBigTable()
+	if err == nil && !exit {
+		err = e.joinSpilledPartitions()
+	}
+	if err != nil {
+		e.resultCh <- &execResult{err: errors.Trace(err)}
+	}
+}
+
+// partitionBigTable writes the big table rows to the partitions. The rows that can't match any small table row,
+// i.e. the rows filtered out by the big filter or having null join keys, are not written. They are sent to the
+// result channel directly for outer joins.
+func (e *HashJoinExec) partitionBigTable() (exit bool, err error) {
+	if err = e.spilledSmall.finish(); err != nil {
+		return false, errors.Trace(err)
+	}
+	e.spilledBig, err = newSpillPartitions(spillFanout)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	ctx := e.hashJoinContexts[0]
+	sc := e.ctx.GetSessionVars().StmtCtx
+	loc := e.ctx.GetSessionVars().GetTimeZone()
+	txnCtx := e.ctx.GoCtx()
+	result := &execResult{}
+	var buffer []byte
+	// fetchBigExec sends the batches to the channels in turn, so we read them in the same order.
+	for idx := 0; ; idx = (idx + 1) % e.concurrency {
+		var bigTableResult *execResult
+		select {
+		case <-txnCtx.Done():
+			return true, nil
+		case tmp, ok := <-e.bigTableResultCh[idx]:
+			if !ok {
+				if len(result.rows) > 0 {
+					e.resultCh <- result
+				}
+				return false, errors.Trace(e.spilledBig.finish())
+			}
+			bigTableResult = tmp
+		}
+		if e.finished.Load().(bool) {
+			return true, nil
+		}
+		if bigTableResult.err != nil {
+			return false, errors.Trace(bigTableResult.err)
+		}
+		for _, bigRow := range bigTableResult.rows {
+			matched, err := expression.EvalBool(ctx.bigFilter, bigRow.Data, e.ctx)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			var (
+				hasNull bool
+				joinKey []byte
+			)
+			if matched {
+				hasNull, joinKey, err = getJoinKey(sc, e.bigHashKey, bigRow, e.targetTypes, ctx.datumBuffer, ctx.hashKeyBuffer[0:0:cap(ctx.hashKeyBuffer)])
+				if err != nil {
+					return false, errors.Trace(err)
+				}
+			}
+			if !matched || hasNull {
+				if e.outer {
+					result.rows = append(result.rows, e.fillRowWithDefaultValues(bigRow))
+					if len(result.rows) >= batchSize {
+						e.resultCh <- result
+						result = &execResult{}
+					}
+				}
+				continue
+			}
+			buffer, err = encodeJoinRow(buffer[:0], bigRow, &e.bigRowKeyCache, loc)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			if err = e.spilledBig.put(joinKey, buffer); err != nil {
+				return false, errors.Trace(err)
+			}
+		}
+	}
+}
+
+// joinSpilledPartitions builds a hash table from the small table rows of every partition, and probes it with
+// the big table rows of the same partition. A partition is assumed to fit in the memory.
+func (e *HashJoinExec) joinSpilledPartitions() error {
+	ctx := e.hashJoinContexts[0]
+	loc := e.ctx.GetSessionVars().GetTimeZone()
+	result := &execResult{}
+	for i := 0; i < spillFanout; i++ {
+		if e.finished.Load().(bool) {
+			return nil
+		}
+		e.hashTable = mvmap.NewMVMap()
+		r := e.spilledSmall.reader(i)
+		for {
+			key, row, err := readSpilledRecord(r)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return errors.Trace(err)
+			}
+			e.hashTable.Put(key, row)
+		}
+		r = e.spilledBig.reader(i)
+		for {
+			key, row, err := readSpilledRecord(r)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return errors.Trace(err)
+			}
+			bigRow, err := decodeJoinRow(row, e.bigRowKeyCache, e.bigExec.Schema(), loc)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			matchedRows, err := e.matchRowsByKey(ctx, bigRow, key)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			result.rows = append(result.rows, matchedRows...)
+			if len(matchedRows) == 0 && e.outer {
+				result.rows = append(result.rows, e.fillRowWithDefaultValues(bigRow))
+			}
+			if len(result.rows) >= batchSize {
+				e.resultCh <- result
+				result = &execResult{}
+			}
+		}
+	}
+	if len(result.rows) > 0 {
+		e.resultCh <- result
+	}
+	return nil
+}
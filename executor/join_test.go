@@ -218,17 +218,15 @@ func (s *testSuite) TestJoin(c *C) {
 	tk.MustExec("create table t1(a int, b int)")
 	tk.MustExec("insert into t values(1, 1), (2, 2), (3, 3)")
 	tk.MustExec("insert into t1 values(1, 2), (1, 3), (3, 4), (4, 5)")
-	// TODO: Open this after refactoring index join.
 	// The physical plans of the two sql are tested at physical_plan_test.go
-	//tk.MustQuery("select /*+ TIDB_INLJ(t, t1) */ * from t join t1 on t.a=t1.a").Check(testkit.Rows("1 1 1 2", "1 1 1 3", "3 3 3 4"))
-	//tk.MustQuery("select /*+ TIDB_INLJ(t1) */ * from t1 join t on t.a=t1.a and t.a < t1.b").Check(testkit.Rows("1 2 1 1", "1 3 1 1", "3 4 3 3"))
-	//tk.MustQuery("select /*+ TIDB_INLJ(t, t1) */ * from t right outer join t1 on t.a=t1.a").Check(testkit.Rows("1 1 1 2", "1 1 1 3", "3 3 3 4", "<nil> <nil> 4 5"))
-	//tk.MustQuery("select /*+ TIDB_INLJ(t, t1) */ avg(t.b) from t right outer join t1 on t.a=t1.a").Check(testkit.Rows("1.6667"))
+	tk.MustQuery("select /*+ TIDB_INLJ(t, t1) */ * from t join t1 on t.a=t1.a").Check(testkit.Rows("1 1 1 2", "1 1 1 3", "3 3 3 4"))
+	tk.MustQuery("select /*+ TIDB_INLJ(t1) */ * from t1 join t on t.a=t1.a and t.a < t1.b").Check(testkit.Rows("1 2 1 1", "1 3 1 1", "3 4 3 3"))
+	tk.MustQuery("select /*+ TIDB_INLJ(t, t1) */ * from t right outer join t1 on t.a=t1.a").Check(testkit.Rows("1 1 1 2", "1 1 1 3", "3 3 3 4", "<nil> <nil> 4 5"))
+	tk.MustQuery("select /*+ TIDB_INLJ(t, t1) */ avg(t.b) from t right outer join t1 on t.a=t1.a").Check(testkit.Rows("1.6667"))
 
 	// Test that two conflict hints will return error
-	//_, err = tk.Exec("select /*+ TIDB_INLJ(t) TIDB_SMJ(t) */ * from t join t1 on t.a=t1.a")
-	//c.Assert(err, NotNil)
-
+	_, err = tk.Exec("select /*+ TIDB_INLJ(t) TIDB_SMJ(t) */ * from t join t1 on t.a=t1.a")
+	c.Assert(err, NotNil)
 }
 
 func (s *testSuite) TestMultiJoin(c *C) {
@@ -327,6 +325,118 @@ AND b44=a42`)
 	result.Check(testkit.Rows("7 7 7 7 7 7 7 7 7 7 7 7 7 7 7 7 7 7 7 7 7"))
 }
 
+func (s *testSuite) TestUsingAndNaturalJoin(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2, t3")
+	tk.MustExec("create table t1 (a int, b int)")
+	tk.MustExec("create table t2 (c int, b int)")
+	tk.MustExec("create table t3 (b int, a int, d int)")
+	tk.MustExec("insert t1 values (1, 1), (2, 2)")
+	tk.MustExec("insert t2 values (2, 2), (3, 3)")
+	tk.MustExec("insert t3 values (1, 1, 1), (2, 1, 2)")
+
+	checkColumns := func(sql string, names ...string) {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil)
+		fields, err := rs.Fields()
+		c.Assert(err, IsNil)
+		c.Assert(len(fields), Equals, len(names), Commentf("sql: %s", sql))
+		for i, name := range names {
+			c.Assert(fields[i].Column.Name.L, Equals, name, Commentf("sql: %s", sql))
+		}
+		c.Assert(rs.Close(), IsNil)
+	}
+
+	// The common columns are coalesced and come first.
+	tk.MustQuery("select * from t1 join t2 using (b)").Check(testkit.Rows("2 2 2"))
+	checkColumns("select * from t1 join t2 using (b)", "b", "a", "c")
+	tk.MustQuery("select * from t1 natural join t2").Check(testkit.Rows("2 2 2"))
+	tk.MustQuery("select * from t1 natural left join t2 order by b").Check(testkit.Rows("1 1 <nil>", "2 2 2"))
+	tk.MustQuery("select * from t1 left join t2 using (b) order by b").Check(testkit.Rows("1 1 <nil>", "2 2 2"))
+	// The first table of right join is the right one.
+	tk.MustQuery("select * from t1 natural right join t2 order by b").Check(testkit.Rows("2 2 2", "3 3 <nil>"))
+	checkColumns("select * from t1 natural right join t2", "b", "c", "a")
+	tk.MustQuery("select * from t1 right join t2 using (b) order by b").Check(testkit.Rows("2 2 2", "3 3 <nil>"))
+
+	// Unqualified common columns refer to the coalesced column, the redundant copy is still available by qualified name.
+	tk.MustQuery("select b, t1.b, t2.b from t1 left join t2 using (b) order by b").Check(testkit.Rows("1 1 <nil>", "2 2 2"))
+	tk.MustQuery("select b, t1.b, t2.b from t1 right join t2 using (b) order by b").Check(testkit.Rows("2 2 2", "3 <nil> 3"))
+	tk.MustQuery("select b from t1 natural left join t2 where b > 1").Check(testkit.Rows("2"))
+	tk.MustQuery("select count(*) from t1 natural join t2 group by b").Check(testkit.Rows("1"))
+	tk.MustQuery("select t2.* from t1 natural join t2").Check(testkit.Rows("2 2"))
+	tk.MustQuery("select t1.*, t2.* from t1 natural left join t2 order by a").Check(testkit.Rows("1 1 <nil> <nil>", "2 2 2 2"))
+
+	// Natural join on multiple columns and nested joins.
+	tk.MustQuery("select * from t1 natural join t3").Check(testkit.Rows("1 1 1"))
+	checkColumns("select * from t1 natural join t3", "a", "b", "d")
+	tk.MustQuery("select * from t1 join t3 using (a) order by d").Check(testkit.Rows("1 1 1 1", "1 1 2 2"))
+	checkColumns("select * from t1 join t3 using (a)", "a", "b", "b", "d")
+	tk.MustQuery("select * from t1 natural join t2 natural join t3").Check(testkit.Rows())
+	tk.MustQuery("select * from t1 natural left join t2 natural left join t3 order by b").Check(testkit.Rows("1 1 <nil> 1", "2 2 2 <nil>"))
+	checkColumns("select * from t1 natural left join t2 natural left join t3", "b", "a", "c", "d")
+	tk.MustQuery("select * from (select * from t1 natural join t2) t").Check(testkit.Rows("2 2 2"))
+
+	// Ambiguous or unknown common columns.
+	_, err := tk.Exec("select * from t1 join t2 using (d)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("select * from t1 join t2 on t1.b = t2.b natural join t3")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("select b from t1 join t2 on t1.b = t2.b")
+	c.Assert(err, NotNil)
+
+	// Update and delete keep the columns of each table in place.
+	tk.MustExec("update t1 join t2 using (b) set a = a + 10, c = c + 10")
+	tk.MustQuery("select * from t1").Check(testkit.Rows("1 1", "12 2"))
+	tk.MustQuery("select * from t2").Check(testkit.Rows("12 2", "3 3"))
+	tk.MustExec("delete t2 from t1 natural join t2")
+	tk.MustQuery("select * from t2").Check(testkit.Rows("3 3"))
+	tk.MustExec("drop table t1, t2, t3")
+}
+
+func (s *testSuite) TestLateralDerivedTable(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int)")
+	tk.MustExec("create table t2 (a int, b int)")
+	tk.MustExec("insert t1 values (1), (2), (3)")
+	tk.MustExec("insert t2 values (1, 1), (1, 2), (1, 3), (2, 4)")
+
+	// Top-N per group.
+	tk.MustQuery("select t1.a, x.b from t1, lateral (select b from t2 where t2.a = t1.a order by b desc limit 2) x order by t1.a, x.b").
+		Check(testkit.Rows("1 2", "1 3", "2 4"))
+	tk.MustQuery("select t1.a, x.b from t1 join lateral (select b from t2 where t2.a = t1.a order by b limit 1) as x on x.b > 1").
+		Check(testkit.Rows("2 4"))
+	tk.MustQuery("select t1.a, x.b from t1 left join lateral (select b from t2 where t2.a = t1.a order by b limit 1) x on true order by t1.a").
+		Check(testkit.Rows("1 1", "2 4", "3 <nil>"))
+	tk.MustQuery("select t1.a, x.cnt, x.s from t1, lateral (select count(*) cnt, sum(b) s from t2 where t2.a = t1.a) x order by t1.a").
+		Check(testkit.Rows("1 3 6", "2 1 4", "3 0 <nil>"))
+	// A lateral derived table can refer to a preceding lateral derived table.
+	tk.MustQuery("select t1.a, x.m, y.c from t1, lateral (select max(b) m from t2 where t2.a = t1.a) x, lateral (select count(*) c from t2 where t2.b < x.m) y order by t1.a").
+		Check(testkit.Rows("1 3 2", "2 4 3", "3 <nil> 0"))
+	// The outer plan of apply is an eliminated projection.
+	tk.MustQuery("select x.m, (select count(*) from t2 where t2.b < x.m) from (select max(b) m from t2) x").Check(testkit.Rows("4 3"))
+	tk.MustQuery("select * from t1, lateral (select t1.a + 1 b) x where t1.a > 1 order by t1.a").
+		Check(testkit.Rows("2 3", "3 4"))
+
+	_, err := tk.Exec("select * from t1 right join lateral (select b from t2 where t2.a = t1.a) x on true")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("select * from t1, (select b from t2 where t2.a = t1.a) x")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("select * from lateral (select b from t2 where t2.a = t1.a) x, t1")
+	c.Assert(err, NotNil)
+	tk.MustExec("drop table t1, t2")
+}
+
 func (s *testSuite) TestSubquerySameTable(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -528,6 +638,46 @@ func (s *testSuite) TestInSubquery(c *C) {
 	result.Check(testkit.Rows("1", "2"))
 }
 
+func (s *testSuite) TestNullAwareAntiSemiJoin(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, s, e")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("create table s (a int, b int)")
+	tk.MustExec("create table e (a int, b int)")
+	tk.MustExec("insert t values (1, 1), (2, 2), (null, 3)")
+	tk.MustExec("insert s values (1, 1), (null, 2)")
+
+	tests := []struct {
+		sql    string
+		result []string
+	}{
+		{"select b from t where a not in (select a from s)", nil},
+		{"select b, a not in (select a from s) from t", []string{"1 0", "2 <nil>", "3 <nil>"}},
+		{"select b, a not in (select a from s where a is not null) from t", []string{"1 0", "2 1", "3 <nil>"}},
+		{"select b from t where a not in (select a from e)", []string{"1", "2", "3"}},
+		{"select b, a not in (select a from e) from t", []string{"1 1", "2 1", "3 1"}},
+		{"select b from t where a not in (select a from s where s.b = t.b)", []string{"3"}},
+		{"select b, a not in (select a from s where s.b = t.b) from t", []string{"1 0", "2 <nil>", "3 1"}},
+		{"select b, a in (select a from s) from t", []string{"1 1", "2 <nil>", "3 <nil>"}},
+		{"select b, (a, b) not in (select a, b from s) from t", []string{"1 0", "2 <nil>", "3 1"}},
+		{"select b, (a, b) not in (select a, 1 from s) from t", []string{"1 0", "2 1", "3 1"}},
+		{"select b, 1 not in (select a from s) from t", []string{"1 0", "2 0", "3 0"}},
+		{"select b, a + 1 not in (select a from s) from t", []string{"1 <nil>", "2 <nil>", "3 <nil>"}},
+		{"select b, a not in (select s.a + 1 from s where s.b = t.b) from t", []string{"1 1", "2 <nil>", "3 1"}},
+		{"select b, a not in (select t.b from s) from t", []string{"1 0", "2 0", "3 <nil>"}},
+		{"select b, a != all (select a from s) from t", []string{"1 0", "2 <nil>", "3 <nil>"}},
+		{"select b, exists (select 1 from s where s.a = t.a) from t", []string{"1 1", "2 0", "3 0"}},
+	}
+	for _, tt := range tests {
+		tk.MustQuery(tt.sql).Check(testkit.Rows(tt.result...))
+	}
+}
+
 func (s *testSuite) TestJoinLeak(c *C) {
 	savedConcurrency := plan.JoinConcurrency
 	plan.JoinConcurrency = 1
@@ -565,3 +715,140 @@ func (s *testSuite) TestHashJoinExecEncodeDecodeRow(c *C) {
 	result := tk.MustQuery("select ts from t1 inner join t2 where t2.name = 'xxx'")
 	result.Check(testkit.Rows("2003-06-09 10:51:26"))
 }
+
+func (s *testSuite) TestHashJoinSpill(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int, b varchar(20), c datetime)")
+	tk.MustExec("create table t2 (a int, b varchar(20))")
+	for i := 0; i < 100; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t1 values (%d, 'b%d', '2017-01-%02d 10:00:00')", i%30, i, i%28+1))
+		tk.MustExec(fmt.Sprintf("insert into t2 values (%d, 'b%d')", i%40, i%7))
+	}
+	tk.MustExec("insert into t1 values (null, 'null', null)")
+	tk.MustExec("insert into t2 values (null, 'null')")
+
+	queries := []string{
+		"select * from t1 join t2 on t1.a = t2.a order by 1, 2, 4, 5",
+		"select * from t1 left join t2 on t1.a = t2.a order by 1, 2, 4, 5",
+		"select * from t1 right join t2 on t1.a = t2.a order by 4, 5, 1, 2",
+		"select * from t1 left join t2 on t1.a = t2.a and t1.b > t2.b order by 1, 2, 4, 5",
+		"select * from t1 left join t2 on t1.a = t2.a and t1.a > 10 order by 1, 2, 4, 5",
+		"select * from t1 join t2 on t1.a = t2.a and t1.b = concat('b', t2.a) where t2.b < 'b5' order by 1, 2, 4, 5",
+		"select t1.a, count(*) from t1 join t2 on t1.a = t2.a group by t1.a order by 1",
+	}
+	expected := make([][][]interface{}, 0, len(queries))
+	tk.MustExec("set @@tidb_hash_join_mem_quota = 0")
+	for _, q := range queries {
+		expected = append(expected, tk.MustQuery(q).Rows())
+	}
+	tk.MustExec("set @@tidb_hash_join_mem_quota = 1")
+	for i, q := range queries {
+		tk.MustQuery(q).Check(expected[i])
+	}
+	tk.MustQuery("select count(*) from t1 join t2 on t1.a = t2.a").Check(testkit.Rows("270"))
+}
+
+func (s *testSuite) TestIndexLookupJoin(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, s")
+	tk.MustExec("create table t(a int primary key, b int, c int, key idx_b(b))")
+	tk.MustExec("create table s(a int, b int, c varchar(10), unique key idx_ab(a, b))")
+	tk.MustExec("insert into t values (1, 1, 1), (2, 2, 2), (3, 2, 3), (4, null, 4), (5, 5, 5)")
+	tk.MustExec("insert into s values (1, 1, 'a'), (1, 2, 'b'), (2, 2, 'c'), (3, null, 'd'), (null, 1, 'e'), (6, 6, 'f')")
+
+	type testCase struct {
+		sql    string
+		result [][]interface{}
+	}
+	cases := []testCase{
+		// The inner table is read by the handle.
+		{"select /*+ TIDB_INLJ(s) */ * from s join t on s.a = t.a order by s.a, s.b",
+			testkit.Rows("1 1 a 1 1 1", "1 2 b 1 1 1", "2 2 c 2 2 2", "3 <nil> d 3 2 3")},
+		{"select /*+ TIDB_INLJ(s) */ * from s left join t on s.a = t.a and t.c > 1 order by s.a, s.b",
+			testkit.Rows("<nil> 1 e <nil> <nil> <nil>", "1 1 a <nil> <nil> <nil>", "1 2 b <nil> <nil> <nil>", "2 2 c 2 2 2", "3 <nil> d 3 2 3", "6 6 f <nil> <nil> <nil>")},
+		{"select /*+ TIDB_INLJ(s) */ * from t right join s on s.a = t.a and s.c > 'a' order by s.a, s.b",
+			testkit.Rows("<nil> <nil> <nil> <nil> 1 e", "<nil> <nil> <nil> 1 1 a", "1 1 1 1 2 b", "2 2 2 2 2 c", "3 2 3 3 <nil> d", "<nil> <nil> <nil> 6 6 f")},
+		// The inner table is read by the index, the matched rows are duplicated.
+		{"select /*+ TIDB_INLJ(s) */ * from s join t on s.b = t.b order by s.a, s.b, t.a",
+			testkit.Rows("<nil> 1 e 1 1 1", "1 1 a 1 1 1", "1 2 b 2 2 2", "1 2 b 3 2 3", "2 2 c 2 2 2", "2 2 c 3 2 3")},
+		{"select /*+ TIDB_INLJ(s) */ s.b, t.b from s join t on s.b = t.b order by s.b",
+			testkit.Rows("1 1", "1 1", "2 2", "2 2", "2 2", "2 2")},
+		{"select /*+ TIDB_INLJ(s) */ * from s left join t on s.b = t.b where s.a > 0 order by s.a, s.b, t.a",
+			testkit.Rows("1 1 a 1 1 1", "1 2 b 2 2 2", "1 2 b 3 2 3", "2 2 c 2 2 2", "2 2 c 3 2 3", "3 <nil> d <nil> <nil> <nil>", "6 6 f <nil> <nil> <nil>")},
+		// The prefix of the index is used.
+		{"select /*+ TIDB_INLJ(t) */ * from t join s on t.a = s.a order by s.a, s.b",
+			testkit.Rows("1 1 1 1 1 a", "1 1 1 1 2 b", "2 2 2 2 2 c", "3 2 3 3 <nil> d")},
+		{"select /*+ TIDB_INLJ(t) */ * from t join s on t.a = s.a and t.b = s.b order by t.a",
+			testkit.Rows("1 1 1 1 1 a", "2 2 2 2 2 c")},
+		{"select /*+ TIDB_INLJ(t) */ * from t left join s on t.a = s.a and t.b < s.b order by t.a",
+			testkit.Rows("1 1 1 1 2 b", "2 2 2 <nil> <nil> <nil>", "3 2 3 <nil> <nil> <nil>", "4 <nil> 4 <nil> <nil> <nil>", "5 5 5 <nil> <nil> <nil>")},
+		{"select /*+ TIDB_INLJ(t) */ t.a, s.a, s.b from t left join s on t.b = s.a order by t.a, s.b",
+			testkit.Rows("1 1 1", "1 1 2", "2 2 2", "3 2 2", "4 <nil> <nil>", "5 <nil> <nil>")},
+	}
+	for _, ca := range cases {
+		tk.MustQuery(ca.sql).Check(ca.result)
+	}
+	// Every batch has a single outer row.
+	tk.MustExec("set @@tidb_index_join_batch_size = 1")
+	for _, ca := range cases {
+		tk.MustQuery(ca.sql).Check(ca.result)
+	}
+	tk.MustExec("set @@tidb_index_join_batch_size = 2")
+	for _, ca := range cases {
+		tk.MustQuery(ca.sql).Check(ca.result)
+	}
+
+	// The inner rows changed in the transaction are read by the union scan.
+	tk.MustExec("begin")
+	tk.MustExec("insert into t values (6, 6, 6)")
+	tk.MustExec("delete from t where a = 1")
+	tk.MustExec("update t set b = 1 where a = 2")
+	tk.MustQuery("select /*+ TIDB_INLJ(s) */ s.a, s.b, t.a from s join t on s.a = t.a order by s.a").Check(testkit.Rows("2 2 2", "3 <nil> 3", "6 6 6"))
+	tk.MustQuery("select /*+ TIDB_INLJ(s) */ s.a, s.b, t.a from s join t on s.b = t.b order by s.a, s.b").Check(testkit.Rows("<nil> 1 2", "1 1 2", "1 2 3", "2 2 3", "6 6 6"))
+	tk.MustExec("rollback")
+
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1(a bigint unsigned primary key)")
+	tk.MustExec("create table t2(a bigint)")
+	tk.MustExec("insert into t1 values (1), (18446744073709551615)")
+	tk.MustExec("insert into t2 values (1), (-1)")
+	tk.MustQuery("select /*+ TIDB_INLJ(t2) */ * from t2 join t1 on t1.a = t2.a").Check(testkit.Rows("1 1"))
+}
+
+func (s *testSuite) TestApplyPrefetch(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (id int primary key, a int, b int)")
+	tk.MustExec("create table t2 (a int, b int, index idx(a))")
+	tk.MustExec("begin")
+	for i := 0; i < 300; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t1 values (%d, %d, %d)", i, i%50, i%7))
+		tk.MustExec(fmt.Sprintf("insert into t2 values (%d, %d)", i%60, i%5))
+	}
+	tk.MustExec("commit")
+
+	// The outer rows are more than a prefetch batch.
+	expected := tk.MustQuery("select t1.id, count(t2.a) from t1 left join t2 on t2.a = t1.a and t2.b > t1.b group by t1.id order by t1.id").Rows()
+	tk.MustQuery("select t1.id, (select count(*) from t2 where t2.a = t1.a and t2.b > t1.b) from t1 order by t1.id").Check(expected)
+	tk.MustQuery("select count(*) from t1 where t1.b < (select count(*) from t2 where t2.a = t1.a and t2.b > t1.b)").Check(testkit.Rows("84"))
+
+	// The prefetching goroutine exits when the apply is closed before all the rows are read.
+	rs, err := tk.Se.Execute("select t1.id, (select count(*) from t2 where t2.a = t1.a and t2.b > t1.b) from t1")
+	c.Assert(err, IsNil)
+	row, err := rs[0].Next()
+	c.Assert(err, IsNil)
+	c.Assert(row, NotNil)
+	c.Assert(rs[0].Close(), IsNil)
+}
@@ -40,17 +40,29 @@ var (
 			Name:      "expensive_query_total",
 			Help:      "Counter of expensive query.",
 		}, []string{"type"})
+	stmtDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "statement_duration_seconds",
+			Help:      "Bucketed histogram of processing time (s) of statements.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 22),
+		}, []string{"type"})
 )
 
 func init() {
 	prometheus.MustRegister(stmtNodeCounter)
 	prometheus.MustRegister(expensiveQueryCounter)
+	prometheus.MustRegister(stmtDurationHistogram)
 }
 
-func stmtCount(node ast.StmtNode, p plan.Plan) {
-	if stmtLabel := StatementLabel(node, p); stmtLabel != IGNORE {
+// stmtCount counts the statement by its label, and returns the label.
+func stmtCount(node ast.StmtNode, p plan.Plan) string {
+	stmtLabel := StatementLabel(node, p)
+	if stmtLabel != IGNORE {
 		stmtNodeCounter.WithLabelValues(stmtLabel).Inc()
 	}
+	return stmtLabel
 }
 
 const (
@@ -60,6 +72,8 @@ const (
 	Select = "Select"
 	// AlterTable represents alter table statements.
 	AlterTable = "AlterTable"
+	// AlterEvent represents alter event statements.
+	AlterEvent = "AlterEvent"
 	// AnalyzeTable represents analyze table statements.
 	AnalyzeTable = "AnalyzeTable"
 	// Begin represents begin statements.
@@ -68,20 +82,28 @@ const (
 	Commit = "Commit"
 	// CreateDatabase represents create database statements.
 	CreateDatabase = "CreateDatabase"
+	// CreateEvent represents create event statements.
+	CreateEvent = "CreateEvent"
 	// CreateIndex represents create index statements.
 	CreateIndex = "CreateIndex"
 	// CreateTable represents create table statements.
 	CreateTable = "CreateTable"
+	// CreateTrigger represents create trigger statements.
+	CreateTrigger = "CreateTrigger"
 	// CreateUser represents create user statements.
 	CreateUser = "CreateUser"
 	// Delete represents delete statements.
 	Delete = "Delete"
 	// DropDatabase represents drop database statements.
 	DropDatabase = "DropDatabase"
+	// DropEvent represents drop event statements.
+	DropEvent = "DropEvent"
 	// DropIndex represents drop index statements.
 	DropIndex = "DropIndex"
 	// DropTable represents drop table statements.
 	DropTable = "DropTable"
+	// DropTrigger represents drop trigger statements.
+	DropTrigger = "DropTrigger"
 	// Explain represents explain statements.
 	Explain = "Explain"
 	// Replace represents replace statements.
@@ -96,6 +118,10 @@ const (
 	Set = "Set"
 	// Show represents show statements.
 	Show = "Show"
+	// SplitRegion represents split table region statements.
+	SplitRegion = "SplitRegion"
+	// Trace represents trace statements.
+	Trace = "Trace"
 	// TruncateTable represents truncate table statements.
 	TruncateTable = "TruncateTable"
 	// Update represents update statements.
@@ -111,6 +137,8 @@ func StatementLabel(node ast.StmtNode, p plan.Plan) string {
 	switch x := node.(type) {
 	case *ast.AlterTableStmt:
 		return AlterTable
+	case *ast.AlterEventStmt:
+		return AlterEvent
 	case *ast.AnalyzeTableStmt:
 		return AnalyzeTable
 	case *ast.BeginStmt:
@@ -119,21 +147,29 @@ func StatementLabel(node ast.StmtNode, p plan.Plan) string {
 		return Commit
 	case *ast.CreateDatabaseStmt:
 		return CreateDatabase
+	case *ast.CreateEventStmt:
+		return CreateEvent
 	case *ast.CreateIndexStmt:
 		return CreateIndex
 	case *ast.CreateTableStmt:
 		return CreateTable
+	case *ast.CreateTriggerStmt:
+		return CreateTrigger
 	case *ast.CreateUserStmt:
 		return CreateUser
 	case *ast.DeleteStmt:
 		return getDeleteStmtLabel(x, p)
 	case *ast.DropDatabaseStmt:
 		return DropDatabase
+	case *ast.DropEventStmt:
+		return DropEvent
 	case *ast.DropIndexStmt:
 		return DropIndex
 	case *ast.DropTableStmt:
 		return DropTable
-	case *ast.ExplainStmt:
+	case *ast.DropTriggerStmt:
+		return DropTrigger
+	case *ast.ExplainStmt, *ast.ExplainForStmt:
 		return Explain
 	case *ast.InsertStmt:
 		if x.IsReplace {
@@ -150,6 +186,10 @@ func StatementLabel(node ast.StmtNode, p plan.Plan) string {
 		return Set
 	case *ast.ShowStmt:
 		return Show
+	case *ast.SplitRegionStmt:
+		return SplitRegion
+	case *ast.TraceStmt:
+		return Trace
 	case *ast.TruncateTableStmt:
 		return TruncateTable
 	case *ast.UpdateStmt:
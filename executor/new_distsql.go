@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/indexusage"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 	goctx "golang.org/x/net/context"
@@ -34,6 +35,8 @@ var (
 
 // TableReaderExecutor sends dag request and reads table data from kv layer.
 type TableReaderExecutor struct {
+	// planID is used to record the runtime statistics of the coprocessor requests.
+	planID    string
 	asName    *model.CIStr
 	table     table.Table
 	tableID   int64
@@ -58,6 +61,7 @@ func (e *TableReaderExecutor) Schema() *expression.Schema {
 
 // Close implements the Executor Close interface.
 func (e *TableReaderExecutor) Close() error {
+	recordExecDetails(e.ctx, e.planID, e.result)
 	err := closeAll(e.result, e.partialResult)
 	e.result = nil
 	e.partialResult = nil
@@ -107,7 +111,7 @@ func (e *TableReaderExecutor) Next() (*Row, error) {
 func (e *TableReaderExecutor) Open() error {
 	kvRanges := tableRangesToKVRanges(e.tableID, e.ranges)
 	var err error
-	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), goctx.Background(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc)
+	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), withStmtContext(e.ctx, goctx.Background()), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -119,7 +123,7 @@ func (e *TableReaderExecutor) Open() error {
 func (e *TableReaderExecutor) doRequestForHandles(handles []int64, goCtx goctx.Context) error {
 	kvRanges := tableHandlesToKVRanges(e.tableID, handles)
 	var err error
-	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), goCtx, e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc)
+	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), withStmtContext(e.ctx, goCtx), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -129,6 +133,8 @@ func (e *TableReaderExecutor) doRequestForHandles(handles []int64, goCtx goctx.C
 
 // IndexReaderExecutor sends dag request and reads index data from kv layer.
 type IndexReaderExecutor struct {
+	// planID is used to record the runtime statistics of the coprocessor requests.
+	planID    string
 	asName    *model.CIStr
 	table     table.Table
 	index     *model.IndexInfo
@@ -145,6 +151,8 @@ type IndexReaderExecutor struct {
 	partialResult distsql.PartialResult
 	// columns are only required by union scan.
 	columns []*model.ColumnInfo
+	// readRows is the number of the rows read from the index, it's recorded in the index usage.
+	readRows int64
 }
 
 // Schema implements the Executor Schema interface.
@@ -154,6 +162,8 @@ func (e *IndexReaderExecutor) Schema() *expression.Schema {
 
 // Close implements the Executor Close interface.
 func (e *IndexReaderExecutor) Close() error {
+	recordExecDetails(e.ctx, e.planID, e.result)
+	indexusage.Record(e.tableID, e.index.ID, e.readRows)
 	err := closeAll(e.result, e.partialResult)
 	e.result = nil
 	e.partialResult = nil
@@ -195,21 +205,20 @@ func (e *IndexReaderExecutor) Next() (*Row, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		e.readRows++
 		return resultRowToRow(e.table, h, values, e.asName), nil
 	}
 }
 
 // Open implements the Executor Open interface.
 func (e *IndexReaderExecutor) Open() error {
-	fieldTypes := make([]*types.FieldType, len(e.index.Columns))
-	for i, v := range e.index.Columns {
-		fieldTypes[i] = &(e.table.Cols()[v.Offset].FieldType)
-	}
+	e.readRows = 0
+	fieldTypes := indexColumnTypes(e.table, e.index)
 	kvRanges, err := indexRangesToKVRanges(e.ctx.GetSessionVars().StmtCtx, e.tableID, e.index.ID, e.ranges, fieldTypes)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), e.ctx.GoCtx(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc)
+	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), withStmtContext(e.ctx, e.ctx.GoCtx()), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -219,6 +228,8 @@ func (e *IndexReaderExecutor) Open() error {
 
 // IndexLookUpExecutor implements double read for index scan.
 type IndexLookUpExecutor struct {
+	// planID is used to record the runtime statistics of the coprocessor requests.
+	planID    string
 	asName    *model.CIStr
 	table     table.Table
 	index     *model.IndexInfo
@@ -240,19 +251,19 @@ type IndexLookUpExecutor struct {
 	tableRequest *tipb.DAGRequest
 	// columns are only required by union scan.
 	columns []*model.ColumnInfo
+	// readRows is the number of the rows read by the index, it's recorded in the index usage.
+	readRows int64
 }
 
 // Open implements the Executor Open interface.
 func (e *IndexLookUpExecutor) Open() error {
-	fieldTypes := make([]*types.FieldType, len(e.index.Columns))
-	for i, v := range e.index.Columns {
-		fieldTypes[i] = &(e.table.Cols()[v.Offset].FieldType)
-	}
+	e.readRows = 0
+	fieldTypes := indexColumnTypes(e.table, e.index)
 	kvRanges, err := indexRangesToKVRanges(e.ctx.GetSessionVars().StmtCtx, e.tableID, e.index.ID, e.ranges, fieldTypes)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), e.ctx.GoCtx(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc)
+	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), withStmtContext(e.ctx, e.ctx.GoCtx()), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -285,6 +296,7 @@ func (e *IndexLookUpExecutor) executeTask(task *lookupTableTask, goCtx goctx.Con
 	if err != nil {
 		return
 	}
+	defer recordExecDetails(e.ctx, e.planID, tableReader.result)
 	for {
 		var row *Row
 		row, err = tableReader.Next()
@@ -388,6 +400,8 @@ func (e *IndexLookUpExecutor) Close() error {
 	for range e.taskChan {
 	}
 	e.taskChan = nil
+	recordExecDetails(e.ctx, e.planID, e.result)
+	indexusage.Record(e.tableID, e.index.ID, e.readRows)
 	err := e.result.Close()
 	e.result = nil
 	return errors.Trace(err)
@@ -408,6 +422,7 @@ func (e *IndexLookUpExecutor) Next() (*Row, error) {
 			return nil, errors.Trace(err)
 		}
 		if row != nil {
+			e.readRows++
 			return row, nil
 		}
 		e.taskCurr = nil
@@ -0,0 +1,199 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/kvcache"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// The status variables of the prepared plan caches.
+const (
+	planCacheSize      = "Prepared_plan_cache_size"
+	planCacheHits      = "Prepared_plan_cache_hits"
+	planCacheMisses    = "Prepared_plan_cache_misses"
+	planCacheHitRatio  = "Prepared_plan_cache_hit_ratio"
+	planCacheEvictions = "Prepared_plan_cache_evictions"
+	planCacheMemUsage  = "Prepared_plan_cache_memory_usage"
+)
+
+// estimatedPlanNodeSize is the estimated memory used by a node of a cached plan.
+const estimatedPlanNodeSize = 1024
+
+type planCacheConfig struct {
+	capacity    int
+	memCapacity int64
+	policy      kvcache.Policy
+}
+
+var (
+	// planCacheCfg is the *planCacheConfig, the plan cache is disabled if it's nil.
+	planCacheCfg atomic.Value
+	// planCacheStats are the statistics of the plan caches of all the sessions.
+	planCacheStats = &kvcache.Stats{}
+)
+
+func init() {
+	variable.RegisterStatistics(planCacheStatistics{})
+}
+
+// SetPreparedPlanCache sets the capacity and the eviction policy of the prepared plan cache of each
+// session, the sessions which have used the cache keep their settings. capacity is the max number of
+// plans, memCapacity is the max memory used by the plans in bytes, zero means no limit. The cache is
+// disabled if both capacity and memCapacity are zero.
+func SetPreparedPlanCache(capacity int, memCapacity int64, policy kvcache.Policy) {
+	if capacity <= 0 && memCapacity <= 0 {
+		planCacheCfg.Store((*planCacheConfig)(nil))
+		return
+	}
+	planCacheCfg.Store(&planCacheConfig{capacity: capacity, memCapacity: memCapacity, policy: policy})
+}
+
+// preparedPlanCache gets the plan cache of the session, it returns nil if the cache is disabled.
+func preparedPlanCache(vars *variable.SessionVars) *kvcache.Cache {
+	if vars.PreparedPlanCache != nil {
+		return vars.PreparedPlanCache
+	}
+	cfg, _ := planCacheCfg.Load().(*planCacheConfig)
+	if cfg == nil {
+		return nil
+	}
+	vars.PreparedPlanCache = kvcache.NewCache(cfg.capacity, cfg.memCapacity, cfg.policy, planCacheStats)
+	return vars.PreparedPlanCache
+}
+
+// cachedPlan is a cached plan with the access info of the statement, the privileges are checked by the
+// access info before the plan is reused.
+type cachedPlan struct {
+	plan   plan.Plan
+	access plan.AccessInfo
+}
+
+// planCacheKey identifies a plan of a prepared statement. The parameters and the session variables
+// which affect the plan are parts of the key, as they are folded into the plan. The versions of the
+// expression pushdown blacklist and the stats of the tables are parts of the key too, so the plans
+// built before they are changed are not reused.
+type planCacheKey struct {
+	stmtID uint32
+	hash   []byte
+}
+
+func newPlanCacheKey(vars *variable.SessionVars, stmtID uint32, schemaVersion int64, statsVersions []uint64, params []*ast.ParamMarkerExpr) (*planCacheKey, error) {
+	datums := make([]types.Datum, 0, len(params)+len(statsVersions)+11)
+	datums = append(datums,
+		types.NewUintDatum(uint64(stmtID)),
+		types.NewIntDatum(schemaVersion),
+		types.NewUintDatum(uint64(vars.SQLMode)),
+		types.NewStringDatum(vars.CurrentDB),
+		types.NewStringDatum(vars.GetTimeZone().String()),
+		types.NewIntDatum(int64(vars.IndexScanDirection)),
+		types.NewIntDatum(expression.PushdownBlacklistVersion()),
+		types.NewUintDatum(vars.SnapshotTS),
+		types.NewIntDatum(boolToInt64(vars.AllowAggPushDown)),
+		types.NewIntDatum(boolToInt64(vars.AllowInSubqueryUnFolding)),
+		types.NewIntDatum(int64(vars.MaxRowCountForINLJ)),
+	)
+	for _, version := range statsVersions {
+		datums = append(datums, types.NewUintDatum(version))
+	}
+	for _, param := range params {
+		datums = append(datums, param.Datum)
+	}
+	hash, err := codec.EncodeValue(nil, datums...)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &planCacheKey{stmtID: stmtID, hash: hash}, nil
+}
+
+func boolToInt64(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// relatedStatsVersions returns the stats versions of the tables the prepared statement depends on, ordered
+// by the table IDs.
+func relatedStatsVersions(ctx context.Context, prepared *Prepared) []uint64 {
+	handle := sessionctx.GetDomain(ctx).StatsHandle()
+	if handle == nil {
+		return nil
+	}
+	ids := make([]int64, 0, len(prepared.RelatedTables))
+	for id := range prepared.RelatedTables {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	versions := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		versions = append(versions, handle.GetTableStats(id).Version)
+	}
+	return versions
+}
+
+// Hash implements the kvcache.Key interface.
+func (k *planCacheKey) Hash() []byte {
+	return k.hash
+}
+
+// estimatePlanMemUsage estimates the memory used by a cached plan.
+func estimatePlanMemUsage(p plan.Plan) int64 {
+	usage := int64(estimatedPlanNodeSize)
+	for _, child := range p.Children() {
+		usage += estimatePlanMemUsage(child)
+	}
+	return usage
+}
+
+// DeletePreparedPlanCache deletes the cached plans of the prepared statement.
+func DeletePreparedPlanCache(vars *variable.SessionVars, stmtID uint32) {
+	if vars.PreparedPlanCache == nil {
+		return
+	}
+	vars.PreparedPlanCache.DeleteIf(func(key kvcache.Key) bool {
+		return key.(*planCacheKey).stmtID == stmtID
+	})
+}
+
+type planCacheStatistics struct{}
+
+// GetScope implements the variable.Statistics interface.
+func (planCacheStatistics) GetScope(status string) variable.ScopeFlag {
+	// Now plan cache status variables scope are all default scope.
+	return variable.DefaultScopeFlag
+}
+
+// Stats implements the variable.Statistics interface.
+func (planCacheStatistics) Stats() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		planCacheSize:      atomic.LoadInt64(&planCacheStats.Size),
+		planCacheHits:      atomic.LoadInt64(&planCacheStats.Hits),
+		planCacheMisses:    atomic.LoadInt64(&planCacheStats.Misses),
+		planCacheHitRatio:  planCacheStats.HitRatio(),
+		planCacheEvictions: atomic.LoadInt64(&planCacheStats.Evictions),
+		planCacheMemUsage:  atomic.LoadInt64(&planCacheStats.MemUsage),
+	}, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// PointGetExec reads the rows of a table by their handles from the transaction directly,
+// so the rows being updated or deleted don't need to be scanned by the coprocessor.
+// If index is set, it reads the row by the values of the clustered primary key instead, in Open,
+// because the transaction of an auto-commit SELECT is committed before its rows are fetched.
+type PointGetExec struct {
+	baseExecutor
+
+	table       table.Table
+	asName      *model.CIStr
+	columns     []*table.Column
+	handles     []int64
+	index       table.Index
+	indexValues []types.Datum
+	cursor      int
+	// row is the row read by the clustered primary key in Open.
+	row *Row
+}
+
+// Open implements the Executor Open interface.
+func (e *PointGetExec) Open() error {
+	e.cursor = 0
+	e.row = nil
+	if e.index == nil {
+		return nil
+	}
+	tbl, ok := e.table.(table.ClusteredTable)
+	if !ok {
+		return errors.Errorf("table %s has no clustered primary key", e.table.Meta().Name)
+	}
+	var r kv.Retriever = e.ctx.Txn()
+	if snapshotTS := e.ctx.GetSessionVars().SnapshotTS; snapshotTS != 0 {
+		snapshot, err := sessionctx.GetDomain(e.ctx).Store().GetSnapshot(kv.NewVersion(snapshotTS))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		r = snapshot
+	}
+	handle, data, err := tbl.ClusteredRowWithCols(e.ctx, r, e.index, e.indexValues, e.columns)
+	if terror.ErrorEqual(err, kv.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.row = resultRowToRow(e.table, handle, data, e.asName)
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *PointGetExec) Next() (*Row, error) {
+	if e.index != nil {
+		row := e.row
+		e.row = nil
+		return row, nil
+	}
+	for e.cursor < len(e.handles) {
+		handle := e.handles[e.cursor]
+		e.cursor++
+		data, err := e.table.RowWithCols(e.ctx, handle, e.columns)
+		if terror.ErrorEqual(err, kv.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return resultRowToRow(e.table, handle, data, e.asName), nil
+	}
+	return nil, nil
+}
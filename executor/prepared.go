@@ -18,13 +18,20 @@ import (
 	"sort"
 
 	"github.com/juju/errors"
+	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/kvcache"
 	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/types"
 )
 
 var (
@@ -64,11 +71,208 @@ func (e *paramMarkerExtractor) Leave(in ast.Node) (ast.Node, bool) {
 	return in, true
 }
 
+// paramFuncArgTypes maps a builtin function to the types of its arguments by position,
+// mysql.TypeUnspecified means the type of the argument depends on the caller.
+var paramFuncArgTypes = map[string][]byte{
+	ast.Substring:    {mysql.TypeUnspecified, mysql.TypeLonglong, mysql.TypeLonglong},
+	ast.Mid:          {mysql.TypeUnspecified, mysql.TypeLonglong, mysql.TypeLonglong},
+	ast.Left:         {mysql.TypeUnspecified, mysql.TypeLonglong},
+	ast.Right:        {mysql.TypeUnspecified, mysql.TypeLonglong},
+	ast.Repeat:       {mysql.TypeUnspecified, mysql.TypeLonglong},
+	ast.Lpad:         {mysql.TypeUnspecified, mysql.TypeLonglong, mysql.TypeVarString},
+	ast.Rpad:         {mysql.TypeUnspecified, mysql.TypeLonglong, mysql.TypeVarString},
+	ast.Space:        {mysql.TypeLonglong},
+	ast.Round:        {mysql.TypeUnspecified, mysql.TypeLonglong},
+	ast.Truncate:     {mysql.TypeUnspecified, mysql.TypeLonglong},
+	ast.FromUnixTime: {mysql.TypeLonglong, mysql.TypeVarString},
+	ast.Sleep:        {mysql.TypeDouble},
+}
+
+// paramTypeInferrer infers the types of the parameter markers from their syntactic context,
+// e.g. the parameter compared with a column has the type of the column.
+type paramTypeInferrer struct {
+	types map[*ast.ParamMarkerExpr]*types.FieldType
+}
+
+func (e *paramTypeInferrer) Enter(in ast.Node) (ast.Node, bool) {
+	return in, false
+}
+
+func (e *paramTypeInferrer) Leave(in ast.Node) (ast.Node, bool) {
+	switch x := in.(type) {
+	case *ast.BinaryOperationExpr:
+		switch x.Op {
+		case opcode.EQ, opcode.NE, opcode.LT, opcode.LE, opcode.GT, opcode.GE, opcode.NullEQ:
+			e.inferFromColumn(x.L, x.R)
+			e.inferFromColumn(x.R, x.L)
+		}
+	case *ast.BetweenExpr:
+		e.inferFromColumn(x.Expr, x.Left)
+		e.inferFromColumn(x.Expr, x.Right)
+	case *ast.PatternInExpr:
+		for _, item := range x.List {
+			e.inferFromColumn(x.Expr, item)
+		}
+	case *ast.PatternLikeExpr:
+		e.setType(x.Pattern, types.NewFieldType(mysql.TypeVarString))
+	case *ast.FuncCallExpr:
+		argTypes := paramFuncArgTypes[x.FnName.L]
+		for i, arg := range x.Args {
+			if i < len(argTypes) && argTypes[i] != mysql.TypeUnspecified {
+				e.setType(arg, types.NewFieldType(argTypes[i]))
+			}
+		}
+	case *ast.Limit:
+		for _, expr := range []ast.ExprNode{x.Count, x.Offset} {
+			tp := types.NewFieldType(mysql.TypeLonglong)
+			tp.Flag |= mysql.UnsignedFlag
+			e.setType(expr, tp)
+		}
+	case *ast.InsertStmt:
+		e.inferInsertValues(x)
+	}
+	return in, true
+}
+
+// inferFromColumn sets the type of the column to the parameter marker if expr is a column.
+func (e *paramTypeInferrer) inferFromColumn(expr, param ast.ExprNode) {
+	cn, ok := expr.(*ast.ColumnNameExpr)
+	if !ok || cn.Refer == nil || cn.Refer.Column.Tp == mysql.TypeUnspecified {
+		return
+	}
+	tp := cn.Refer.Column.FieldType
+	e.setType(param, &tp)
+}
+
+// inferInsertValues sets the types of the inserted columns to the parameter markers in VALUES lists.
+func (e *paramTypeInferrer) inferInsertValues(x *ast.InsertStmt) {
+	ts, ok := x.Table.TableRefs.Left.(*ast.TableSource)
+	if !ok {
+		return
+	}
+	tn, ok := ts.Source.(*ast.TableName)
+	if !ok || tn.TableInfo == nil {
+		return
+	}
+	var cols []*model.ColumnInfo
+	if len(x.Columns) == 0 {
+		for _, col := range tn.TableInfo.Columns {
+			if col.State == model.StatePublic {
+				cols = append(cols, col)
+			}
+		}
+	} else {
+		for _, name := range x.Columns {
+			var found *model.ColumnInfo
+			for _, col := range tn.TableInfo.Columns {
+				if col.State == model.StatePublic && col.Name.L == name.Name.L {
+					found = col
+					break
+				}
+			}
+			if found == nil {
+				return
+			}
+			cols = append(cols, found)
+		}
+	}
+	for _, list := range x.Lists {
+		if len(list) != len(cols) {
+			continue
+		}
+		for i, expr := range list {
+			tp := cols[i].FieldType
+			e.setType(expr, &tp)
+		}
+	}
+}
+
+func (e *paramTypeInferrer) setType(expr ast.ExprNode, tp *types.FieldType) {
+	if x, ok := expr.(*ast.ParamMarkerExpr); ok {
+		if _, ok := e.types[x]; !ok {
+			e.types[x] = tp
+		}
+	}
+}
+
+// convertParamValue converts the string value of a parameter to its inferred type,
+// the value is kept as it is if the conversion is lossy.
+func convertParamValue(sc *variable.StatementContext, val types.Datum, tp *types.FieldType) types.Datum {
+	if tp == nil || (val.Kind() != types.KindString && val.Kind() != types.KindBytes) {
+		return val
+	}
+	if tp.ToClass() == types.ClassString {
+		return val
+	}
+	newVal, err := val.ConvertTo(sc, tp)
+	if err != nil {
+		return val
+	}
+	cmp, err := newVal.CompareDatum(sc, val)
+	if err != nil || cmp != 0 {
+		return val
+	}
+	return newVal
+}
+
 // Prepared represents a prepared statement.
 type Prepared struct {
 	Stmt          ast.StmtNode
 	Params        []*ast.ParamMarkerExpr
+	ParamTypes    []*types.FieldType
 	SchemaVersion int64
+	// UseCache indicates whether the plans of the statement can be cached.
+	UseCache bool
+	// SQLText and DBName are the text of the statement and the current database when it's prepared,
+	// they are used to prepare the statement again when the tables it depends on are changed by DDL.
+	SQLText string
+	DBName  string
+	// RelatedTables are the infos of the tables the statement depends on, indexed by the table IDs.
+	RelatedTables map[int64]*model.TableInfo
+}
+
+// relatedTablesChanged checks whether the tables the statement depends on are changed in the information schema.
+func (p *Prepared) relatedTablesChanged(is infoschema.InfoSchema) bool {
+	for id, info := range p.RelatedTables {
+		tbl, ok := is.TableByID(id)
+		// The table info is replaced when the table is changed by DDL.
+		if !ok || tbl.Meta() != info {
+			return true
+		}
+	}
+	return false
+}
+
+// relatedTablesCollector collects the tables a statement depends on.
+type relatedTablesCollector struct {
+	tables map[int64]*model.TableInfo
+}
+
+func (c *relatedTablesCollector) Enter(in ast.Node) (ast.Node, bool) {
+	return in, false
+}
+
+func (c *relatedTablesCollector) Leave(in ast.Node) (ast.Node, bool) {
+	if x, ok := in.(*ast.TableName); ok && x.TableInfo != nil {
+		c.tables[x.TableInfo.ID] = x.TableInfo
+	}
+	return in, true
+}
+
+// tableSchemaFiller sets the database of the unqualified table names.
+type tableSchemaFiller struct {
+	schema model.CIStr
+}
+
+func (f *tableSchemaFiller) Enter(in ast.Node) (ast.Node, bool) {
+	return in, false
+}
+
+func (f *tableSchemaFiller) Leave(in ast.Node) (ast.Node, bool) {
+	if x, ok := in.(*ast.TableName); ok && x.Schema.L == "" {
+		x.Schema = f.schema
+	}
+	return in, true
 }
 
 // PrepareExec represents a PREPARE executor.
@@ -80,6 +284,7 @@ type PrepareExec struct {
 
 	ID         uint32
 	ParamCount int
+	ParamTypes []*types.FieldType
 	Err        error
 	Fields     []*ast.ResultField
 }
@@ -118,65 +323,91 @@ func (e *PrepareExec) DoPrepare() {
 			return
 		}
 	}
-	charset, collation := vars.GetCharsetInfo()
+	prepared, fields, err := prepareStmt(e.Ctx, e.IS, e.SQLText, vars.CurrentDB)
+	if err != nil {
+		e.Err = errors.Trace(err)
+		return
+	}
+	e.Fields = fields
+	e.ParamCount = len(prepared.Params)
+	e.ParamTypes = prepared.ParamTypes
+
+	if e.ID == 0 {
+		e.ID = vars.GetNextPreparedStmtID()
+	}
+	if e.Name != "" {
+		vars.PreparedStmtNameToID[e.Name] = e.ID
+	}
+	vars.PreparedStmts[e.ID] = prepared
+}
+
+// prepareStmt parses and checks the statement, it's also used to prepare the statement again when the
+// tables it depends on are changed by DDL. dbName is the current database when the statement is prepared
+// at first, the unqualified table names always refer to the tables in it.
+func prepareStmt(ctx context.Context, is infoschema.InfoSchema, sqlText, dbName string) (*Prepared, []*ast.ResultField, error) {
+	charset, collation := ctx.GetSessionVars().GetCharsetInfo()
 	var (
 		stmts []ast.StmtNode
 		err   error
 	)
-	if sqlParser, ok := e.Ctx.(sqlexec.SQLParser); ok {
-		stmts, err = sqlParser.ParseSQL(e.SQLText, charset, collation)
+	if sqlParser, ok := ctx.(sqlexec.SQLParser); ok {
+		stmts, err = sqlParser.ParseSQL(sqlText, charset, collation)
 	} else {
-		stmts, err = parser.New().Parse(e.SQLText, charset, collation)
+		stmts, err = parser.New().Parse(sqlText, charset, collation)
 	}
 	if err != nil {
-		e.Err = errors.Trace(err)
-		return
+		return nil, nil, errors.Trace(err)
 	}
 	if len(stmts) != 1 {
-		e.Err = errors.Trace(ErrPrepareMulti)
-		return
+		return nil, nil, errors.Trace(ErrPrepareMulti)
 	}
 	stmt := stmts[0]
 	if _, ok := stmt.(ast.DDLNode); ok {
-		e.Err = errors.Trace(ErrPrepareDDL)
-		return
+		return nil, nil, errors.Trace(ErrPrepareDDL)
+	}
+	if dbName != "" {
+		stmt.Accept(&tableSchemaFiller{schema: model.NewCIStr(dbName)})
 	}
 	var extractor paramMarkerExtractor
 	stmt.Accept(&extractor)
-	err = plan.Preprocess(stmt, e.IS, e.Ctx)
+	err = plan.Preprocess(stmt, is, ctx)
 	if err != nil {
-		e.Err = errors.Trace(err)
-		return
+		return nil, nil, errors.Trace(err)
 	}
+	var fields []*ast.ResultField
 	if result, ok := stmt.(ast.ResultSetNode); ok {
-		e.Fields = result.GetResultFields()
+		fields = result.GetResultFields()
 	}
+	inferrer := &paramTypeInferrer{types: make(map[*ast.ParamMarkerExpr]*types.FieldType)}
+	stmt.Accept(inferrer)
 
 	// The parameter markers are appended in visiting order, which may not
 	// be the same as the position order in the query string. We need to
 	// sort it by position.
 	sorter := &paramMarkerSorter{markers: extractor.markers}
 	sort.Sort(sorter)
-	e.ParamCount = len(sorter.markers)
+	paramTypes := make([]*types.FieldType, len(sorter.markers))
+	for i, marker := range sorter.markers {
+		paramTypes[i] = inferrer.types[marker]
+	}
+	collector := &relatedTablesCollector{tables: make(map[int64]*model.TableInfo)}
+	stmt.Accept(collector)
 	prepared := &Prepared{
 		Stmt:          stmt,
 		Params:        sorter.markers,
-		SchemaVersion: e.IS.SchemaMetaVersion(),
+		ParamTypes:    paramTypes,
+		SchemaVersion: is.SchemaMetaVersion(),
+		UseCache:      plan.Cacheable(stmt),
+		SQLText:       sqlText,
+		DBName:        dbName,
+		RelatedTables: collector.tables,
 	}
 
-	err = plan.PrepareStmt(e.IS, e.Ctx, stmt)
+	err = plan.PrepareStmt(is, ctx, stmt)
 	if err != nil {
-		e.Err = errors.Trace(err)
-		return
-	}
-
-	if e.ID == 0 {
-		e.ID = vars.GetNextPreparedStmtID()
+		return nil, nil, errors.Trace(err)
 	}
-	if e.Name != "" {
-		vars.PreparedStmtNameToID[e.Name] = e.ID
-	}
-	vars.PreparedStmts[e.ID] = prepared
+	return prepared, fields, nil
 }
 
 // ExecuteExec represents an EXECUTE executor.
@@ -191,6 +422,7 @@ type ExecuteExec struct {
 	StmtExec  Executor
 	Stmt      ast.StmtNode
 	Plan      plan.Plan
+	stmtLabel string
 }
 
 // Schema implements the Executor Schema interface.
@@ -228,29 +460,28 @@ func (e *ExecuteExec) Build() error {
 		return errors.Trace(ErrStmtNotFound)
 	}
 	prepared := v.(*Prepared)
+	if prepared.SchemaVersion != e.IS.SchemaMetaVersion() {
+		var err error
+		prepared, err = e.revalidate(prepared)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	if len(prepared.Params) != len(e.UsingVars) {
 		return errors.Trace(ErrWrongParamCount)
 	}
 
+	sc := vars.StmtCtx
 	for i, usingVar := range e.UsingVars {
 		val, err := usingVar.Eval(nil)
 		if err != nil {
 			return errors.Trace(err)
 		}
-		prepared.Params[i].SetDatum(val)
+		prepared.Params[i].SetDatum(convertParamValue(sc, val, prepared.ParamTypes[i]))
 	}
 
-	if prepared.SchemaVersion != e.IS.SchemaMetaVersion() {
-		// If the schema version has changed we need to prepare it again,
-		// if this time it failed, the real reason for the error is schema changed.
-		err := plan.PrepareStmt(e.IS, e.Ctx, prepared.Stmt)
-		if err != nil {
-			return ErrSchemaChanged.Gen("Schema change caused error: %s", err.Error())
-		}
-		prepared.SchemaVersion = e.IS.SchemaMetaVersion()
-	}
-	p, err := plan.Optimize(e.Ctx, prepared.Stmt, e.IS)
+	p, err := e.getPlan(prepared)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -270,10 +501,72 @@ func (e *ExecuteExec) Build() error {
 	e.StmtExec = stmtExec
 	e.Stmt = prepared.Stmt
 	e.Plan = p
-	stmtCount(e.Stmt, e.Plan)
+	e.stmtLabel = stmtCount(e.Stmt, e.Plan)
 	return nil
 }
 
+// revalidate checks the prepared statement with the latest information schema after the schema version
+// is changed. If the tables the statement depends on are changed by DDL, the statement is prepared again
+// from its text transparently, it fails only if the statement isn't compatible with the new schema.
+func (e *ExecuteExec) revalidate(prepared *Prepared) (*Prepared, error) {
+	if !prepared.relatedTablesChanged(e.IS) {
+		prepared.SchemaVersion = e.IS.SchemaMetaVersion()
+		return prepared, nil
+	}
+	newPrepared, _, err := prepareStmt(e.Ctx, e.IS, prepared.SQLText, prepared.DBName)
+	if err != nil {
+		// If it failed, the real reason for the error is schema changed.
+		return nil, ErrSchemaChanged.Gen("Schema change caused error: %s", err.Error())
+	}
+	if len(newPrepared.Params) != len(prepared.Params) {
+		return nil, ErrSchemaChanged.Gen("Schema change caused error: the parameter count is changed")
+	}
+	vars := e.Ctx.GetSessionVars()
+	vars.PreparedStmts[e.ID] = newPrepared
+	DeletePreparedPlanCache(vars, e.ID)
+	log.Infof("[%d] prepare the statement %d again after the schema is changed", vars.ConnectionID, e.ID)
+	return newPrepared, nil
+}
+
+// getPlan gets the plan of the prepared statement from the plan cache, or optimizes the statement and
+// caches the plan. The read-only mode and the privileges are checked every time, as they may be changed
+// after the plan is cached.
+func (e *ExecuteExec) getPlan(prepared *Prepared) (plan.Plan, error) {
+	if err := plan.CheckReadOnly(e.Ctx, prepared.Stmt); err != nil {
+		return nil, errors.Trace(err)
+	}
+	vars := e.Ctx.GetSessionVars()
+	var (
+		cache *kvcache.Cache
+		key   *planCacheKey
+		err   error
+	)
+	if prepared.UseCache {
+		cache = preparedPlanCache(vars)
+	}
+	if cache != nil {
+		key, err = newPlanCacheKey(vars, e.ID, prepared.SchemaVersion, relatedStatsVersions(e.Ctx, prepared), prepared.Params)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if v, ok := cache.Get(key); ok {
+			cached := v.(*cachedPlan)
+			if err = plan.CheckAccess(e.Ctx, cached.access); err != nil {
+				return nil, errors.Trace(err)
+			}
+			return cached.plan, nil
+		}
+	}
+	p, access, err := plan.OptimizeWithAccessInfo(e.Ctx, prepared.Stmt, e.IS)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if cache != nil {
+		cache.Put(key, &cachedPlan{plan: p, access: access}, estimatePlanMemUsage(p))
+	}
+	return p, nil
+}
+
 // DeallocateExec represent a DEALLOCATE executor.
 type DeallocateExec struct {
 	Name string
@@ -295,6 +588,7 @@ func (e *DeallocateExec) Next() (*Row, error) {
 	}
 	delete(vars.PreparedStmtNameToID, e.Name)
 	delete(vars.PreparedStmts, id)
+	DeletePreparedPlanCache(vars, id)
 	return nil, nil
 }
 
@@ -308,6 +602,21 @@ func (e *DeallocateExec) Open() error {
 	return nil
 }
 
+// RecompilePreparedStmt compiles the executed prepared statement again with the latest information schema,
+// so the statement is prepared again if the tables it depends on are changed. It's used when the transaction
+// is retried after the schema is changed.
+func RecompilePreparedStmt(ctx context.Context, st ast.Statement) ast.Statement {
+	a, ok := st.(*statement)
+	if !ok || a.execPlan == nil {
+		return st
+	}
+	return &statement{
+		is:   GetInfoSchema(ctx),
+		plan: a.execPlan,
+		text: a.text,
+	}
+}
+
 // CompileExecutePreparedStmt compiles a session Execute command to a stmt.Statement.
 func CompileExecutePreparedStmt(ctx context.Context, ID uint32, args ...interface{}) ast.Statement {
 	execPlan := &plan.Execute{ExecID: ID}
@@ -14,9 +14,18 @@
 package executor_test
 
 import (
+	"strconv"
+	"time"
+
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/privilege/privileges"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/kvcache"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -113,3 +122,235 @@ func (s *testSuite) TestPreparedLimitOffset(c *C) {
 	_, err = tk.Se.ExecutePreparedStmt(stmtID, 1)
 	c.Assert(err, IsNil)
 }
+
+func (s *testSuite) TestPreparedParamTypes(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists prepare_test")
+	tk.MustExec("create table prepare_test (id int unsigned primary key, c1 varchar(20), c2 datetime)")
+
+	tests := []struct {
+		sql   string
+		types []byte
+	}{
+		{"select id from prepare_test where id = ? and c1 > ?", []byte{mysql.TypeLong, mysql.TypeVarchar}},
+		{"select id from prepare_test where ? < c2", []byte{mysql.TypeDatetime}},
+		{"select id from prepare_test where id in (?, ?) or c2 between ? and ?", []byte{mysql.TypeLong, mysql.TypeLong, mysql.TypeDatetime, mysql.TypeDatetime}},
+		{"select substring(c1, ?, ?) from prepare_test where c1 like ? limit ?", []byte{mysql.TypeLonglong, mysql.TypeLonglong, mysql.TypeVarString, mysql.TypeLonglong}},
+		{"insert prepare_test (c2, id) values (?, ?)", []byte{mysql.TypeDatetime, mysql.TypeLong}},
+		{"select ? + 1", []byte{mysql.TypeUnspecified}},
+	}
+	for _, tt := range tests {
+		_, paramTypes, _, err := tk.Se.PrepareStmt(tt.sql)
+		c.Assert(err, IsNil)
+		c.Assert(paramTypes, HasLen, len(tt.types))
+		for i, tp := range tt.types {
+			if tp == mysql.TypeUnspecified {
+				c.Assert(paramTypes[i], IsNil, Commentf("%s", tt.sql))
+				continue
+			}
+			c.Assert(paramTypes[i].Tp, Equals, tp, Commentf("%s", tt.sql))
+		}
+	}
+
+	// The string parameters are converted to the inferred types.
+	tk.MustExec("insert prepare_test values (1, 'a', '2017-01-01'), (2, 'b', '2017-01-02')")
+	tk.MustExec(`prepare stmt_test_2 from 'select c1 from prepare_test where id = ? or c2 = ?'`)
+	tk.MustExec(`set @a = "1", @b = "2017-01-02 00:00:00"`)
+	tk.MustQuery("execute stmt_test_2 using @a, @b").Check(testkit.Rows("a", "b"))
+	tk.MustExec(`set @a = "1.5", @b = "2017-01-03"`)
+	tk.MustQuery("execute stmt_test_2 using @a, @b").Check(testkit.Rows())
+}
+
+func (s *testSuite) TestPreparedPlanCache(c *C) {
+	defer func() {
+		executor.SetPreparedPlanCache(0, 0, kvcache.PolicyLRU)
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	executor.SetPreparedPlanCache(2, 0, kvcache.PolicyLRU)
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int, index idx(b))")
+	tk.MustExec("insert t values (1, 1), (2, 2), (3, 3)")
+	status := func(name string) string {
+		rows := tk.MustQuery("show status like '" + name + "'").Rows()
+		c.Assert(rows, HasLen, 1)
+		return rows[0][1].(string)
+	}
+	hits, misses, evictions := status("Prepared_plan_cache_hits"), status("Prepared_plan_cache_misses"),
+		status("Prepared_plan_cache_evictions")
+
+	tk.MustExec(`prepare stmt from 'select a from t where b > ?'`)
+	tk.MustExec("set @a = 1")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("2", "3"))
+	tk.MustExec("insert t values (4, 4)")
+	// The plan is reused, but the results are fresh.
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("2", "3", "4"))
+	tk.MustExec("set @a = 3")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("4"))
+	cache := tk.Se.GetSessionVars().PreparedPlanCache
+	c.Assert(cache.Len(), Equals, 2)
+	c.Assert(status("Prepared_plan_cache_hits"), Equals, addInt(c, hits, 1))
+	c.Assert(status("Prepared_plan_cache_misses"), Equals, addInt(c, misses, 2))
+	c.Assert(status("Prepared_plan_cache_memory_usage"), Not(Equals), "0")
+
+	// The least recently used plan is evicted.
+	tk.MustExec("set @a = 0")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("1", "2", "3", "4"))
+	c.Assert(cache.Len(), Equals, 2)
+	c.Assert(status("Prepared_plan_cache_evictions"), Equals, addInt(c, evictions, 1))
+
+	// The plans of a statement with a subquery are not cached.
+	tk.MustExec(`prepare stmt2 from 'select a from t where b > (select min(b) from t) and a < ?'`)
+	tk.MustQuery("execute stmt2 using @a").Check(testkit.Rows())
+	c.Assert(cache.Len(), Equals, 2)
+
+	// The plans of a statement with the functions of the current time are not cached, the time is evaluated
+	// on every execution.
+	tk.MustExec(`prepare stmt3 from 'select now(6), unix_timestamp(now(6)) from t where a = ?'`)
+	tk.MustExec("set @a = 1")
+	first := tk.MustQuery("execute stmt3 using @a").Rows()
+	time.Sleep(10 * time.Millisecond)
+	second := tk.MustQuery("execute stmt3 using @a").Rows()
+	c.Assert(first, HasLen, 1)
+	c.Assert(second, HasLen, 1)
+	c.Assert(second[0][0], Not(Equals), first[0][0])
+	c.Assert(second[0][1], Not(Equals), first[0][1])
+	c.Assert(cache.Len(), Equals, 2)
+
+	// The session variables affecting the plan are parts of the key.
+	tk.MustExec("set @a = 0")
+	tk.MustExec("set @@tidb_max_row_count_for_inlj = 1")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("1", "2", "3", "4"))
+	c.Assert(cache.Len(), Equals, 2)
+	c.Assert(status("Prepared_plan_cache_evictions"), Equals, addInt(c, evictions, 2))
+	tk.MustExec("set @@tidb_max_row_count_for_inlj = 128")
+
+	tk.MustExec("deallocate prepare stmt")
+	c.Assert(cache.Len(), Equals, 0)
+}
+
+func (s *testSuite) TestPreparedPlanCacheChecks(c *C) {
+	save := privileges.Enable
+	privileges.Enable = true
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		executor.SetPreparedPlanCache(0, 0, kvcache.PolicyLRU)
+		expression.SetExprPushdownBlacklist(nil)
+		tk.MustExec("set global read_only = 0")
+		tk.MustExec("drop user 'plan_cache'@'%'")
+		privileges.Enable = save
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	executor.SetPreparedPlanCache(10, 0, kvcache.PolicyLRU)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("create user 'plan_cache'@'%'")
+	tk.MustExec("grant select, insert on test.t to 'plan_cache'@'%'")
+	tk.MustExec("flush privileges")
+	se, err := tidb.CreateSession(s.store)
+	c.Assert(err, IsNil)
+	c.Assert(se.Auth("plan_cache@%", nil, nil), IsTrue)
+	_, err = se.Execute("use test")
+	c.Assert(err, IsNil)
+	_, err = se.Execute("prepare ins from 'insert t values (?, ?)'")
+	c.Assert(err, IsNil)
+	_, err = se.Execute("prepare sel from 'select a from t where a < ?'")
+	c.Assert(err, IsNil)
+	_, err = se.Execute("set @a = 1")
+	c.Assert(err, IsNil)
+	_, err = se.Execute("execute ins using @a, @a")
+	c.Assert(err, IsNil)
+	_, err = se.Execute("execute sel using @a")
+	c.Assert(err, IsNil)
+	cache := se.GetSessionVars().PreparedPlanCache
+	c.Assert(cache.Len(), Equals, 2)
+
+	// The read-only mode is checked even if the plan is cached.
+	tk.MustExec("set global read_only = 1")
+	_, err = se.Execute("execute ins using @a, @a")
+	c.Assert(terror.ErrorEqual(err, plan.ErrReadOnly), IsTrue, Commentf("err %v", err))
+	tk.MustExec("set global read_only = 0")
+	_, err = se.Execute("execute ins using @a, @a")
+	c.Assert(err, IsNil)
+
+	// The privileges are checked even if the plan is cached.
+	tk.MustExec("revoke insert on test.t from 'plan_cache'@'%'")
+	tk.MustExec("flush privileges")
+	_, err = se.Execute("execute ins using @a, @a")
+	c.Assert(err, NotNil)
+	c.Assert(cache.Len(), Equals, 2)
+
+	// Reloading the pushdown blacklist invalidates the cached plans.
+	tk.MustExec("insert mysql.expr_pushdown_blacklist values ('lt')")
+	tk.MustExec("admin reload expr_pushdown_blacklist")
+	_, err = se.Execute("execute sel using @a")
+	c.Assert(err, IsNil)
+	c.Assert(cache.Len(), Equals, 3)
+	tk.MustExec("delete from mysql.expr_pushdown_blacklist")
+
+	// Updating the stats of the table invalidates the cached plans.
+	tk.MustExec("analyze table t")
+	_, err = se.Execute("execute sel using @a")
+	c.Assert(err, IsNil)
+	c.Assert(cache.Len(), Equals, 4)
+}
+
+func (s *testSuite) TestPreparedSchemaChanged(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("create table t1 (a int)")
+	tk.MustExec("insert t values (1, 1)")
+	tk.MustExec(`prepare s1 from 'select * from t where a = ?'`)
+	tk.MustExec(`prepare s2 from 'select b from t where a = ?'`)
+	tk.MustExec("set @a = 1")
+
+	// The DDL on an unrelated table keeps the prepared statement.
+	vars := tk.Se.GetSessionVars()
+	id := vars.PreparedStmtNameToID["s1"]
+	prepared := vars.PreparedStmts[id]
+	tk.MustExec("alter table t1 add column b int")
+	tk.MustQuery("execute s1 using @a").Check(testkit.Rows("1 1"))
+	c.Assert(vars.PreparedStmts[id], Equals, prepared)
+
+	// The statement is prepared again after the DDL on the table it reads.
+	tk.MustExec("alter table t add column c int default 5")
+	tk.MustQuery("execute s1 using @a").Check(testkit.Rows("1 1 5"))
+	c.Assert(vars.PreparedStmts[id], Not(Equals), prepared)
+
+	// The statement keeps the database it was prepared in.
+	tk.MustExec("create database if not exists prepare_db")
+	tk.MustExec("use prepare_db")
+	tk.MustExec("alter table test.t add column d int default 6")
+	tk.MustQuery("execute s1 using @a").Check(testkit.Rows("1 1 5 6"))
+	tk.MustExec("use test")
+	tk.MustExec("drop database prepare_db")
+
+	// The statement fails when it is no longer valid.
+	tk.MustExec("alter table t drop column b")
+	_, err := tk.Exec("execute s2 using @a")
+	c.Assert(terror.ErrorEqual(err, executor.ErrSchemaChanged), IsTrue, Commentf("err %v", err))
+
+	// The statement works again after the table is recreated.
+	tk.MustExec("drop table t")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("insert t values (1, 2)")
+	tk.MustQuery("execute s2 using @a").Check(testkit.Rows("2"))
+}
+
+func addInt(c *C, s string, delta int64) string {
+	v, err := strconv.ParseInt(s, 10, 64)
+	c.Assert(err, IsNil)
+	return strconv.FormatInt(v+delta, 10)
+}
@@ -0,0 +1,72 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// addPrivilegeHistory records the privilege-changing statement executed by the current user in the
+// mysql.privilege_history table, one row for each affected account. The rows are written in the
+// transaction of the statement, so they are rolled back with it.
+func addPrivilegeHistory(ctx context.Context, stmt string, accounts []string) error {
+	if len(accounts) == 0 {
+		return nil
+	}
+	_, err := ctx.(sqlexec.SQLExecutor).Execute(privilegeHistorySQL(ctx, stmt, accounts))
+	return errors.Trace(err)
+}
+
+// addRestrictedPrivilegeHistory is like addPrivilegeHistory, but the rows are written in a separate
+// transaction, for the statements which update the privilege tables by the restricted SQL.
+func addRestrictedPrivilegeHistory(ctx context.Context, stmt string, accounts []string) error {
+	if len(accounts) == 0 {
+		return nil
+	}
+	_, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, privilegeHistorySQL(ctx, stmt, accounts))
+	return errors.Trace(err)
+}
+
+// privilegeHistorySQL returns the SQL statement which inserts the history rows, the passwords in the
+// recorded statement are hidden.
+func privilegeHistorySQL(ctx context.Context, stmt string, accounts []string) string {
+	now := time.Now().UTC().Format(types.TimeFormat)
+	user := escapeString(ctx.GetSessionVars().User)
+	stmt = escapeString(parser.HidePasswords(stmt))
+	values := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		values = append(values, fmt.Sprintf("('%s', '%s', '%s', '%s')", now, user, escapeString(account), stmt))
+	}
+	return fmt.Sprintf("INSERT INTO %s.%s (time, user, account, statement) VALUES %s;",
+		mysql.SystemDB, mysql.PrivilegeHistoryTable, strings.Join(values, ", "))
+}
+
+// userSpecAccounts returns the accounts of the user specifications.
+func userSpecAccounts(specs []*ast.UserSpec) []string {
+	accounts := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		accounts = append(accounts, spec.User)
+	}
+	return accounts
+}
@@ -42,6 +42,7 @@ type RevokeExec struct {
 	ObjectType ast.ObjectTypeType
 	Level      *ast.GrantLevel
 	Users      []*ast.UserSpec
+	Text       string
 
 	ctx  context.Context
 	is   infoschema.InfoSchema
@@ -71,6 +72,10 @@ func (e *RevokeExec) Next() (*Row, error) {
 			return nil, errors.Trace(err)
 		}
 	}
+	err := addPrivilegeHistory(e.ctx, e.Text, userSpecAccounts(e.Users))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	e.done = true
 	sessionctx.GetDomain(e.ctx).NotifyUpdatePrivilege(e.ctx)
 	return nil, nil
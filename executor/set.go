@@ -14,7 +14,6 @@
 package executor
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/juju/errors"
@@ -73,15 +72,9 @@ func (e *SetExecutor) executeSet() error {
 			if err != nil {
 				return errors.Trace(err)
 			}
-
-			if value.IsNull() {
-				delete(sessionVars.Users, name)
-			} else {
-				svalue, err1 := value.ToString()
-				if err1 != nil {
-					return errors.Trace(err1)
-				}
-				sessionVars.Users[name] = fmt.Sprintf("%v", svalue)
+			err = expression.SetUserVar(sessionVars, name, value, v.Expr.GetType())
+			if err != nil {
+				return errors.Trace(err)
 			}
 			continue
 		}
@@ -110,10 +103,22 @@ func (e *SetExecutor) executeSet() error {
 			if err != nil {
 				return errors.Trace(err)
 			}
+			if name == variable.TimeZone {
+				if _, err = varsutil.ParseTimeZone(svalue); err != nil {
+					return errors.Trace(err)
+				}
+			}
 			err = sessionVars.GlobalVarsAccessor.SetGlobalSysVar(name, svalue)
 			if err != nil {
 				return errors.Trace(err)
 			}
+			if name == variable.ReadOnly || name == variable.SuperReadOnly {
+				// The switch takes effect on this server at once, the other servers reload it when notified.
+				variable.SetReadOnly(name, svalue)
+				if dom := sessionctx.GetDomain(e.ctx); dom != nil {
+					dom.NotifyUpdateReadOnly()
+				}
+			}
 		} else {
 			// Set session scope system variable.
 			if sysVar.Scope&variable.ScopeSession == 0 {
@@ -15,9 +15,13 @@ package executor_test
 
 import (
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -125,6 +129,18 @@ func (s *testSuite) TestSetVar(c *C) {
 	c.Assert(vars.SkipConstraintCheck, IsFalse)
 }
 
+func (s *testSuite) TestUserVars(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("set @a = 10, @b = 9")
+	tk.MustQuery("select @a > 5, @a > @b, @a + 0.5").Check(testkit.Rows("1 1 10.5"))
+	tk.MustExec("set @c = 1.50, @s = 'AbC'")
+	tk.MustQuery("select @c * 2, @s, @S").Check(testkit.Rows("3.00 AbC AbC"))
+	tk.MustQuery("select @d := 2.5").Check(testkit.Rows("2.5"))
+	tk.MustQuery("select @d > 10").Check(testkit.Rows("0"))
+	tk.MustQuery("select @a := null, @a is null, @e is null").Check(testkit.Rows("<nil> 1 1"))
+}
+
 func (s *testSuite) TestSetCharset(c *C) {
 	defer testleak.AfterTest(c)()
 	tk := testkit.NewTestKit(c, s.store)
@@ -150,3 +166,58 @@ func (s *testSuite) TestSetCharset(c *C) {
 	// Issue 1523
 	tk.MustExec(`SET NAMES binary`)
 }
+
+func (s *testSuite) TestSetReadOnly(c *C) {
+	save := privileges.Enable
+	privileges.Enable = true
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		tk.MustExec("set global super_read_only = 0")
+		tk.MustExec("set global read_only = 0")
+		tk.MustExec("drop user 'read_only'@'%'")
+		privileges.Enable = save
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("create table read_only_t (a int)")
+	tk.MustExec("create user 'read_only'@'%'")
+	tk.MustExec("grant all on test.* to 'read_only'@'%'")
+	tk.MustExec("flush privileges")
+	se, err := tidb.CreateSession(s.store)
+	c.Assert(err, IsNil)
+	c.Assert(se.Auth("read_only@%", nil, nil), IsTrue)
+	_, err = se.Execute("use test")
+	c.Assert(err, IsNil)
+
+	tk.MustExec("set global read_only = 1")
+	tk.MustQuery("select @@global.read_only").Check(testkit.Rows("1"))
+	c.Assert(variable.IsReadOnly(), IsTrue)
+	for _, sql := range []string{
+		"insert read_only_t values (1)",
+		"update read_only_t set a = 2",
+		"delete from read_only_t",
+		"create table read_only_t2 (a int)",
+		"explain analyze insert read_only_t values (1)",
+	} {
+		_, err = se.Execute(sql)
+		c.Assert(terror.ErrorEqual(err, plan.ErrReadOnly), IsTrue, Commentf("sql %s, err %v", sql, err))
+	}
+	_, err = se.Execute("select a from read_only_t for update")
+	c.Assert(err, IsNil)
+	_, err = se.Execute("explain insert read_only_t values (1)")
+	c.Assert(err, IsNil)
+	// The session without the privilege manager is treated as a SUPER user.
+	tk.MustExec("insert read_only_t values (1)")
+
+	tk.MustExec("set global super_read_only = 1")
+	_, err = tk.Exec("insert read_only_t values (2)")
+	c.Assert(terror.ErrorEqual(err, plan.ErrReadOnly), IsTrue, Commentf("err %v", err))
+	tk.MustExec("set global super_read_only = 0")
+	tk.MustExec("insert read_only_t values (2)")
+
+	tk.MustExec("set global read_only = 0")
+	_, err = se.Execute("insert read_only_t values (3)")
+	c.Assert(err, IsNil)
+	tk.MustQuery("select a from read_only_t").Check(testkit.Rows("1", "2", "3"))
+}
@@ -0,0 +1,204 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var _ Executor = &SharedTableReaderExecutor{}
+
+// sharedTableScan reads the rows of the identical table readers in a plan only once, e.g. the two sides of a self
+// join or the children of a union that scan the same table with the same ranges and the same pushed down executors.
+// The rows are buffered until every reader sharing the scan has consumed them, so the readers may be consumed at
+// different paces and in different goroutines.
+type sharedTableScan struct {
+	sync.Mutex
+	// reader is a copy of the first registered reader, it's opened by the first opened SharedTableReaderExecutor and
+	// closed after all of them are closed.
+	reader *TableReaderExecutor
+	opened bool
+	done   bool
+	err    error
+	// rows are the rows read but not consumed by every reader, offset is the position of rows[0] in the scan.
+	rows   []*Row
+	offset int
+	// cursors are the positions of the next rows of the readers, closedCursor means the reader is closed.
+	cursors []int
+}
+
+const closedCursor = -1
+
+// sharedScanKey returns the key of the table reader, the readers with the same key return the same rows.
+func sharedScanKey(e *TableReaderExecutor) (string, error) {
+	dag, err := e.dagPB.Marshal()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return fmt.Sprintf("%d_%v_%v_%v_%s", e.tableID, e.keepOrder, e.desc, e.ranges, dag), nil
+}
+
+func newSharedTableScan(reader *TableReaderExecutor) *sharedTableScan {
+	r := *reader
+	return &sharedTableScan{reader: &r}
+}
+
+// register adds a reader to the scan and returns its index.
+func (s *sharedTableScan) register() int {
+	s.cursors = append(s.cursors, 0)
+	return len(s.cursors) - 1
+}
+
+func (s *sharedTableScan) open() error {
+	s.Lock()
+	defer s.Unlock()
+	if !s.opened {
+		s.opened = true
+		s.err = s.reader.Open()
+		if s.err != nil {
+			s.done = true
+		}
+	}
+	return errors.Trace(s.err)
+}
+
+// next returns the next row of the idx-th reader, the row is read from the table if no reader has read it yet.
+func (s *sharedTableScan) next(idx int) (*Row, error) {
+	s.Lock()
+	defer s.Unlock()
+	pos := s.cursors[idx]
+	if pos < s.offset+len(s.rows) {
+		row := s.rows[pos-s.offset]
+		s.advance(idx)
+		return row, nil
+	}
+	if s.done {
+		return nil, errors.Trace(s.err)
+	}
+	row, err := s.reader.Next()
+	if err != nil {
+		s.done = true
+		s.err = err
+		return nil, errors.Trace(err)
+	}
+	if row == nil {
+		s.done = true
+		return nil, nil
+	}
+	s.rows = append(s.rows, row)
+	s.advance(idx)
+	return row, nil
+}
+
+// advance moves the cursor of the idx-th reader forward, and drops the rows consumed by every reader.
+func (s *sharedTableScan) advance(idx int) {
+	s.cursors[idx]++
+	minCursor := -1
+	for _, cursor := range s.cursors {
+		if cursor != closedCursor && (minCursor == -1 || cursor < minCursor) {
+			minCursor = cursor
+		}
+	}
+	if minCursor <= s.offset {
+		return
+	}
+	consumed := minCursor - s.offset
+	for i := 0; i < consumed; i++ {
+		s.rows[i] = nil
+	}
+	s.rows = s.rows[consumed:]
+	s.offset = minCursor
+}
+
+// close closes the idx-th reader, the table reader is closed after all of them are closed.
+func (s *sharedTableScan) close(idx int) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.cursors[idx] == closedCursor {
+		return nil
+	}
+	s.cursors[idx] = closedCursor
+	for _, cursor := range s.cursors {
+		if cursor != closedCursor {
+			return nil
+		}
+	}
+	s.rows = nil
+	if !s.opened {
+		return nil
+	}
+	return errors.Trace(s.reader.Close())
+}
+
+// SharedTableReaderExecutor is a table reader whose rows may be read by a sharedTableScan together with the identical
+// table readers in the same plan.
+type SharedTableReaderExecutor struct {
+	shared *sharedTableScan
+	idx    int
+	// reader is the reader built for this executor, it's used directly if no other reader shares the scan,
+	// or if the executor is opened again after the shared scan has started.
+	reader  *TableReaderExecutor
+	private bool
+	used    bool
+}
+
+// Schema implements the Executor Schema interface.
+func (e *SharedTableReaderExecutor) Schema() *expression.Schema {
+	return e.reader.schema
+}
+
+// Open implements the Executor Open interface.
+func (e *SharedTableReaderExecutor) Open() error {
+	if e.used || len(e.shared.cursors) == 1 {
+		// No other reader shares the scan, or the rows consumed by this executor may have been dropped by the
+		// shared scan, so it scans the table by itself.
+		if !e.private {
+			e.private = true
+			if err := e.shared.close(e.idx); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return errors.Trace(e.reader.Open())
+	}
+	e.used = true
+	return errors.Trace(e.shared.open())
+}
+
+// Next implements the Executor Next interface.
+func (e *SharedTableReaderExecutor) Next() (*Row, error) {
+	if e.private {
+		return e.reader.Next()
+	}
+	row, err := e.shared.next(e.idx)
+	if row == nil || err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The row is shared by the readers, so we copy it and set the table name of this reader.
+	data := make([]types.Datum, len(row.Data))
+	copy(data, row.Data)
+	return resultRowToRow(e.reader.table, row.RowKeys[0].Handle, data, e.reader.asName), nil
+}
+
+// Close implements the Executor Close interface.
+func (e *SharedTableReaderExecutor) Close() error {
+	if e.private {
+		return errors.Trace(e.reader.Close())
+	}
+	return errors.Trace(e.shared.close(e.idx))
+}
@@ -17,20 +17,25 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -48,6 +53,10 @@ type ShowExec struct {
 
 	// GlobalScope is used by show variables
 	GlobalScope bool
+	// CountWarningsOrErrors is used by show count(*) warnings and show count(*) errors.
+	CountWarningsOrErrors bool
+	// startTS is used by show master status.
+	startTS uint64
 
 	is infoschema.InfoSchema
 
@@ -105,11 +114,19 @@ func (e *ShowExec) fetchAll() error {
 	case ast.ShowVariables:
 		return e.fetchShowVariables()
 	case ast.ShowWarnings:
-		return e.fetchShowWarnings()
+		return e.fetchShowWarnings(false)
+	case ast.ShowErrors:
+		return e.fetchShowWarnings(true)
 	case ast.ShowProcessList:
 		return e.fetchShowProcessList()
 	case ast.ShowEvents:
-		// empty result
+		return e.fetchShowEvents()
+	case ast.ShowRegions:
+		return e.fetchShowRegions()
+	case ast.ShowHotspots:
+		return e.fetchShowHotspots()
+	case ast.ShowMasterStatus:
+		return e.fetchShowMasterStatus()
 	}
 	return nil
 }
@@ -261,6 +278,74 @@ func (e *ShowExec) fetchShowColumns() error {
 	return nil
 }
 
+func (e *ShowExec) fetchShowRegions() error {
+	store, ok := sessionctx.GetDomain(e.ctx).Store().(kv.RegionStorage)
+	if !ok {
+		return ErrNotSupportedYet.GenByArgs("show table regions")
+	}
+	tb, err := e.getTable()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tableID := tb.Meta().ID
+	regions, err := store.GetRegions(tablecodec.EncodeTablePrefix(tableID), tablecodec.EncodeTablePrefix(tableID+1))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, region := range regions {
+		peers := make([]string, 0, len(region.Peers))
+		for _, peer := range region.Peers {
+			peers = append(peers, strconv.FormatUint(peer, 10))
+		}
+		row := &Row{
+			Data: types.MakeDatums(
+				region.ID,
+				regionKeyString(region.StartKey),
+				regionKeyString(region.EndKey),
+				region.LeaderID,
+				region.LeaderStoreID,
+				strings.Join(peers, ", "),
+			),
+		}
+		e.rows = append(e.rows, row)
+	}
+	return nil
+}
+
+func (e *ShowExec) fetchShowHotspots() error {
+	for _, hot := range infoschema.Hotspots(e.is) {
+		row := &Row{
+			Data: types.MakeDatums(
+				hot.DBName,
+				hot.TableName,
+				hot.IndexName,
+				hot.ReadQPS,
+				hot.WriteQPS,
+				hot.AppendRatio,
+			),
+		}
+		e.rows = append(e.rows, row)
+	}
+	return nil
+}
+
+// fetchShowMasterStatus returns the start ts of the statement as the position, so the dump tools
+// taking a consistent snapshot by START TRANSACTION WITH CONSISTENT SNAPSHOT get the ts of the
+// snapshot they dump, to replicate the changes after it.
+func (e *ShowExec) fetchShowMasterStatus() error {
+	row := &Row{
+		Data: types.MakeDatums(
+			"tidb-binlog", // File
+			e.startTS,     // Position
+			"",            // Binlog_Do_DB
+			"",            // Binlog_Ignore_DB
+			"",            // Executed_Gtid_Set
+		),
+	}
+	e.rows = append(e.rows, row)
+	return nil
+}
+
 func (e *ShowExec) fetchShowIndex() error {
 	tb, err := e.getTable()
 	if err != nil {
@@ -383,25 +468,53 @@ func (e *ShowExec) fetchShowStatus() error {
 	return nil
 }
 
+// escapeName escapes the backquotes in an identifier quoted by backquotes.
+func escapeName(name string) string {
+	return strings.Replace(name, "`", "``", -1)
+}
+
+// escapeString escapes a string literal quoted by single quotes.
+func escapeString(s string) string {
+	return strings.Replace(strings.Replace(s, `\`, `\\`, -1), "'", "''", -1)
+}
+
 func (e *ShowExec) fetchShowCreateTable() error {
 	tb, err := e.getTable()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	tblInfo := tb.Meta()
+	charsetName := tblInfo.Charset
+	if len(charsetName) == 0 {
+		charsetName = charset.CharsetUTF8
+	}
+	collate := tblInfo.Collate
+	if len(collate) == 0 {
+		collate = charset.CollationUTF8
+	}
 
-	// TODO: let the result more like MySQL.
-	var buf bytes.Buffer
-	buf.WriteString(fmt.Sprintf("CREATE TABLE `%s` (\n", tb.Meta().Name.O))
+	// lines are the definitions of the columns, the indices and the foreign keys.
+	var lines []string
 	var pkCol *table.Column
-	for i, col := range tb.Cols() {
-		buf.WriteString(fmt.Sprintf("  `%s` %s", col.Name.O, col.GetTypeDesc()))
+	hasAutoIncID := false
+	for _, col := range tb.Cols() {
+		var buf bytes.Buffer
+		buf.WriteString(fmt.Sprintf("  `%s` %s", escapeName(col.Name.O), col.GetTypeDesc()))
+		// The charset and collation of the column are shown if they are different from the table's.
+		// The charset is always shown with the collation, a collation alone is ignored by the DDL.
+		if len(col.Charset) != 0 && col.Charset != charset.CharsetBin && (col.Charset != charsetName || col.Collate != collate) {
+			buf.WriteString(fmt.Sprintf(" CHARACTER SET %s COLLATE %s", col.Charset, col.Collate))
+		}
 		if mysql.HasAutoIncrementFlag(col.Flag) {
+			hasAutoIncID = true
 			buf.WriteString(" NOT NULL AUTO_INCREMENT")
 		} else {
 			if mysql.HasNotNullFlag(col.Flag) {
 				buf.WriteString(" NOT NULL")
 			}
-			if !mysql.HasNoDefaultValueFlag(col.Flag) {
+			if col.DefaultIsExpr {
+				buf.WriteString(fmt.Sprintf(" DEFAULT (%s)", col.DefaultValue))
+			} else if !mysql.HasNoDefaultValueFlag(col.Flag) {
 				switch col.DefaultValue {
 				case nil:
 					if !mysql.HasNotNullFlag(col.Flag) {
@@ -412,80 +525,92 @@ func (e *ShowExec) fetchShowCreateTable() error {
 					}
 				case "CURRENT_TIMESTAMP":
 					buf.WriteString(" DEFAULT CURRENT_TIMESTAMP")
+					if col.Decimal > 0 {
+						buf.WriteString(fmt.Sprintf("(%d)", col.Decimal))
+					}
 				default:
-					buf.WriteString(fmt.Sprintf(" DEFAULT '%v'", col.DefaultValue))
+					buf.WriteString(fmt.Sprintf(" DEFAULT '%s'", escapeString(fmt.Sprintf("%v", col.DefaultValue))))
 				}
 			}
 			if mysql.HasOnUpdateNowFlag(col.Flag) {
 				buf.WriteString(" ON UPDATE CURRENT_TIMESTAMP")
+				if col.Decimal > 0 {
+					buf.WriteString(fmt.Sprintf("(%d)", col.Decimal))
+				}
+			}
+			if tblInfo.AutoRandomBits > 0 && col.IsPKHandleColumn(tblInfo) {
+				buf.WriteString(fmt.Sprintf(" AUTO_RANDOM(%d)", tblInfo.AutoRandomBits))
 			}
 		}
 		if len(col.Comment) > 0 {
-			buf.WriteString(fmt.Sprintf(" COMMENT '%s'", col.Comment))
-		}
-		if i != len(tb.Cols())-1 {
-			buf.WriteString(",\n")
+			buf.WriteString(fmt.Sprintf(" COMMENT '%s'", escapeString(col.Comment)))
 		}
-		if tb.Meta().PKIsHandle && mysql.HasPriKeyFlag(col.Flag) {
+		lines = append(lines, buf.String())
+		if tblInfo.PKIsHandle && mysql.HasPriKeyFlag(col.Flag) {
 			pkCol = col
 		}
 	}
 
 	if pkCol != nil {
 		// If PKIsHanle, pk info is not in tb.Indices(). We should handle it here.
-		buf.WriteString(",\n")
-		buf.WriteString(fmt.Sprintf(" PRIMARY KEY (`%s`)", pkCol.Name.O))
-	}
-
-	if len(tb.Indices()) > 0 || len(tb.Meta().ForeignKeys) > 0 {
-		buf.WriteString(",\n")
+		lines = append(lines, fmt.Sprintf("  PRIMARY KEY (`%s`)", escapeName(pkCol.Name.O)))
 	}
 
-	for i, idx := range tb.Indices() {
+	for _, idx := range tb.Indices() {
 		idxInfo := idx.Meta()
+		if idxInfo.State != model.StatePublic {
+			continue
+		}
+		var buf bytes.Buffer
 		if idxInfo.Primary {
 			buf.WriteString("  PRIMARY KEY ")
 		} else if idxInfo.Unique {
-			buf.WriteString(fmt.Sprintf("  UNIQUE KEY `%s` ", idxInfo.Name.O))
+			buf.WriteString(fmt.Sprintf("  UNIQUE KEY `%s` ", escapeName(idxInfo.Name.O)))
 		} else {
-			buf.WriteString(fmt.Sprintf("  KEY `%s` ", idxInfo.Name.O))
+			buf.WriteString(fmt.Sprintf("  KEY `%s` ", escapeName(idxInfo.Name.O)))
 		}
 
 		cols := make([]string, 0, len(idxInfo.Columns))
 		for _, c := range idxInfo.Columns {
-			cols = append(cols, c.Name.O)
+			colDesc := fmt.Sprintf("`%s`", escapeName(c.Name.O))
+			if mv := c.MultiValued; mv != nil {
+				if mv.Path != "$" {
+					colDesc = fmt.Sprintf("JSON_EXTRACT(%s, '%s')", colDesc, escapeString(mv.Path))
+				}
+				colDesc = fmt.Sprintf("(CAST(%s AS %s ARRAY))", colDesc, mv.TypeString())
+			} else if c.Length != types.UnspecifiedLength {
+				colDesc += fmt.Sprintf("(%d)", c.Length)
+			}
+			cols = append(cols, colDesc)
 		}
-		buf.WriteString(fmt.Sprintf("(`%s`)", strings.Join(cols, "`,`")))
-		if i != len(tb.Indices())-1 {
-			buf.WriteString(",\n")
+		buf.WriteString(fmt.Sprintf("(%s)", strings.Join(cols, ",")))
+		// BTREE is the default index type, it's omitted like MySQL.
+		if idxInfo.Tp == model.IndexTypeHash {
+			buf.WriteString(" USING HASH")
 		}
+		if len(idxInfo.Comment) > 0 {
+			buf.WriteString(fmt.Sprintf(" COMMENT '%s'", escapeString(idxInfo.Comment)))
+		}
+		lines = append(lines, buf.String())
 	}
 
-	if len(tb.Indices()) > 0 && len(tb.Meta().ForeignKeys) > 0 {
-		buf.WriteString(",\n")
-	}
-
-	firstFK := true
-	for _, fk := range tb.Meta().ForeignKeys {
+	for _, fk := range tblInfo.ForeignKeys {
 		if fk.State != model.StatePublic {
 			continue
 		}
-		if !firstFK {
-			buf.WriteString(",\n")
-		}
-		firstFK = false
 		cols := make([]string, 0, len(fk.Cols))
 		for _, c := range fk.Cols {
-			cols = append(cols, c.O)
+			cols = append(cols, escapeName(c.O))
 		}
 
 		refCols := make([]string, 0, len(fk.RefCols))
-		for _, c := range fk.Cols {
-			refCols = append(refCols, c.O)
+		for _, c := range fk.RefCols {
+			refCols = append(refCols, escapeName(c.O))
 		}
 
-		buf.WriteString(fmt.Sprintf("  CONSTRAINT `%s` FOREIGN KEY (`%s`)", fk.Name.O, strings.Join(cols, "`,`")))
-		buf.WriteString(fmt.Sprintf(" REFERENCES `%s` (`%s`)", fk.RefTable.O, strings.Join(refCols, "`,`")))
+		var buf bytes.Buffer
+		buf.WriteString(fmt.Sprintf("  CONSTRAINT `%s` FOREIGN KEY (`%s`)", escapeName(fk.Name.O), strings.Join(cols, "`,`")))
+		buf.WriteString(fmt.Sprintf(" REFERENCES `%s` (`%s`)", escapeName(fk.RefTable.O), strings.Join(refCols, "`,`")))
 
 		if ast.ReferOptionType(fk.OnDelete) != ast.ReferOptionNoOption {
 			buf.WriteString(fmt.Sprintf(" ON DELETE %s", ast.ReferOptionType(fk.OnDelete)))
@@ -494,31 +619,38 @@ func (e *ShowExec) fetchShowCreateTable() error {
 		if ast.ReferOptionType(fk.OnUpdate) != ast.ReferOptionNoOption {
 			buf.WriteString(fmt.Sprintf(" ON UPDATE %s", ast.ReferOptionType(fk.OnUpdate)))
 		}
+		lines = append(lines, buf.String())
 	}
-	buf.WriteString("\n")
 
-	buf.WriteString(") ENGINE=InnoDB")
-	charsetName := tb.Meta().Charset
-	if len(charsetName) == 0 {
-		charsetName = charset.CharsetUTF8
-	}
-	collate := tb.Meta().Collate
-	if len(collate) == 0 {
-		collate = charset.CollationUTF8
-	}
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("CREATE TABLE `%s` (\n", escapeName(tblInfo.Name.O)))
+	buf.WriteString(strings.Join(lines, ",\n"))
+	buf.WriteString("\n) ENGINE=InnoDB")
 	// Because we only support case sensitive utf8_bin collate, we need to explicitly set the default charset and collation
 	// to make it work on MySQL server which has default collate utf8_general_ci.
 	buf.WriteString(fmt.Sprintf(" DEFAULT CHARSET=%s COLLATE=%s", charsetName, collate))
 
-	if tb.Meta().AutoIncID > 0 {
-		buf.WriteString(fmt.Sprintf(" AUTO_INCREMENT=%d", tb.Meta().AutoIncID))
+	if hasAutoIncID {
+		// The next ID allocated by all the servers, so the IDs of the recreated table don't overlap
+		// the IDs in the dumped data.
+		autoIncID, err := tb.Allocator().NextGlobalAutoID(tblInfo.ID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// It's compatible with MySQL, the clause is omitted if no ID is allocated.
+		if autoIncID > 1 {
+			buf.WriteString(fmt.Sprintf(" AUTO_INCREMENT=%d", autoIncID))
+		}
+	}
+	if tblInfo.AutoIDCache > 0 {
+		buf.WriteString(fmt.Sprintf(" AUTO_ID_CACHE=%d", tblInfo.AutoIDCache))
 	}
 
-	if len(tb.Meta().Comment) > 0 {
-		buf.WriteString(fmt.Sprintf(" COMMENT='%s'", tb.Meta().Comment))
+	if len(tblInfo.Comment) > 0 {
+		buf.WriteString(fmt.Sprintf(" COMMENT='%s'", escapeString(tblInfo.Comment)))
 	}
 
-	data := types.MakeDatums(tb.Meta().Name.O, buf.String())
+	data := types.MakeDatums(tblInfo.Name.O, buf.String())
 	e.rows = append(e.rows, &Row{Data: data})
 	return nil
 }
@@ -579,18 +711,93 @@ func (e *ShowExec) fetchShowGrants() error {
 }
 
 func (e *ShowExec) fetchShowTriggers() error {
+	if !e.is.SchemaExists(e.DBName) {
+		return errors.Errorf("Can not find DB: %s", e.DBName)
+	}
+	checker := privilege.GetPrivilegeManager(e.ctx)
+	tables := e.is.SchemaTables(e.DBName)
+	sort.Sort(table.Slice(tables))
+	for _, t := range tables {
+		if checker != nil && !checker.RequestVerification(e.DBName.O, t.Meta().Name.O, "", mysql.AllPrivMask) {
+			continue
+		}
+		for _, trigger := range t.Meta().Triggers {
+			if trigger.State != model.StatePublic {
+				continue
+			}
+			data := types.MakeDatums(trigger.Name.O, trigger.Event.String(), t.Meta().Name.O, trigger.Body,
+				trigger.Timing.String(), nil, "", "", "utf8", "utf8_general_ci", "utf8_general_ci")
+			e.rows = append(e.rows, &Row{Data: data})
+		}
+	}
+	return nil
+}
+
+func (e *ShowExec) fetchShowEvents() error {
+	if !e.is.SchemaExists(e.DBName) {
+		return errors.Errorf("Can not find DB: %s", e.DBName)
+	}
+	sql := fmt.Sprintf(`SELECT name, time_zone, definer, execute_at, interval_value, interval_field,
+		starts, ends, status FROM %s.%s WHERE db = '%s' ORDER BY name`, mysql.SystemDB, mysql.EventTable, e.DBName.L)
+	rows, _, err := e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, row := range rows {
+		d := row.Data
+		tz := d[1].GetString()
+		loc, err := varsutil.ParseTimeZone(tz)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		tp := "ONE TIME"
+		if !d[5].IsNull() {
+			tp = "RECURRING"
+		}
+		// The times are stored in UTC, show them in the time zone of the event like MySQL.
+		data := []types.Datum{types.NewStringDatum(e.DBName.O), d[0], types.NewStringDatum(tz), d[2],
+			types.NewStringDatum(tp), eventTimeDatum(d[3], loc), d[4], d[5], eventTimeDatum(d[6], loc),
+			eventTimeDatum(d[7], loc), types.NewStringDatum(d[8].GetMysqlEnum().String()), types.NewIntDatum(0),
+			types.NewStringDatum("utf8"), types.NewStringDatum("utf8_general_ci"), types.NewStringDatum("utf8_general_ci")}
+		e.rows = append(e.rows, &Row{Data: data})
+	}
 	return nil
 }
 
+func eventTimeDatum(d types.Datum, loc *time.Location) types.Datum {
+	if d.IsNull() {
+		return d
+	}
+	t, err := d.GetMysqlTime().Time.GoTime(time.UTC)
+	if err != nil {
+		return d
+	}
+	d.SetMysqlTime(types.Time{Time: types.FromGoTime(t.In(loc)), Type: mysql.TypeDatetime})
+	return d
+}
+
 func (e *ShowExec) fetchShowProcedureStatus() error {
 	return nil
 }
 
-func (e *ShowExec) fetchShowWarnings() error {
-	warns := e.ctx.GetSessionVars().StmtCtx.GetWarnings()
-	for _, warn := range warns {
+// fetchShowWarnings shows the warnings kept from the previous statement, or only the errors if errOnly is true.
+func (e *ShowExec) fetchShowWarnings(errOnly bool) error {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	if e.CountWarningsOrErrors {
+		count := sc.TotalWarningCount()
+		if errOnly {
+			count = sc.ErrorCount()
+		}
+		e.rows = append(e.rows, &Row{Data: types.MakeDatums(int64(count))})
+		return nil
+	}
+	for _, w := range sc.GetWarnings() {
+		if errOnly && w.Level != variable.WarnLevelError {
+			continue
+		}
 		datums := make([]types.Datum, 3)
-		datums[0] = types.NewStringDatum("Warning")
+		datums[0] = types.NewStringDatum(w.Level)
+		warn := errors.Cause(w.Err)
 		switch x := warn.(type) {
 		case *terror.Error:
 			sqlErr := x.ToSQLError()
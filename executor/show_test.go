@@ -14,12 +14,17 @@
 package executor_test
 
 import (
+	"fmt"
 	"strings"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/meta/autoid"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/privilege/privileges"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/hotspot"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
@@ -47,7 +52,7 @@ func (s *testSuite) TestShow(c *C) {
 	row := result.Rows()[0]
 	// For issue https://github.com/pingcap/tidb/issues/1061
 	expectedRow := []interface{}{
-		"SHOW_test", "CREATE TABLE `SHOW_test` (\n  `id` int(11) NOT NULL AUTO_INCREMENT,\n  `c1` int(11) DEFAULT NULL COMMENT 'c1_comment',\n  `c2` int(11) DEFAULT NULL,\n  `c3` int(11) DEFAULT '1',\n PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin AUTO_INCREMENT=28934 COMMENT='table_comment'"}
+		"SHOW_test", "CREATE TABLE `SHOW_test` (\n  `id` int(11) NOT NULL AUTO_INCREMENT,\n  `c1` int(11) DEFAULT NULL COMMENT 'c1_comment',\n  `c2` int(11) DEFAULT NULL,\n  `c3` int(11) DEFAULT '1',\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin AUTO_INCREMENT=28934 COMMENT='table_comment'"}
 	for i, r := range row {
 		c.Check(r, Equals, expectedRow[i])
 	}
@@ -66,7 +71,7 @@ func (s *testSuite) TestShow(c *C) {
 	c.Check(result.Rows(), HasLen, 1)
 	row = result.Rows()[0]
 	expectedRow = []interface{}{
-		"ptest", "CREATE TABLE `ptest` (\n  `a` int(11) NOT NULL,\n  `b` double NOT NULL DEFAULT '2.0',\n  `c` varchar(10) NOT NULL,\n  `d` time DEFAULT NULL,\n  `e` timestamp NULL DEFAULT NULL,\n PRIMARY KEY (`a`),\n  UNIQUE KEY `d` (`d`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"}
+		"ptest", "CREATE TABLE `ptest` (\n  `a` int(11) NOT NULL,\n  `b` double NOT NULL DEFAULT '2.0',\n  `c` varchar(10) NOT NULL,\n  `d` time DEFAULT NULL,\n  `e` timestamp NULL DEFAULT NULL,\n  PRIMARY KEY (`a`),\n  UNIQUE KEY `d` (`d`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"}
 	for i, r := range row {
 		c.Check(r, Equals, expectedRow[i])
 	}
@@ -188,7 +193,7 @@ func (s *testSuite) TestForeignKeyInShowCreateTable(c *C) {
 	sqlLines := []string{
 		"CREATE TABLE `show_test` (",
 		"  `id` int(11) NOT NULL AUTO_INCREMENT,",
-		" PRIMARY KEY (`id`),",
+		"  PRIMARY KEY (`id`),",
 		"  CONSTRAINT `Fk` FOREIGN KEY (`id`) REFERENCES `t1` (`id`) ON DELETE CASCADE ON UPDATE CASCADE",
 		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin",
 	}
@@ -228,7 +233,7 @@ func (s *testSuite) TestForeignKeyInShowCreateTable(c *C) {
 	c.Check(result.Rows(), HasLen, 1)
 	row = result.Rows()[0]
 	expectedRow = []interface{}{
-		"show_test", "CREATE TABLE `show_test` (\n  `id` int(11) NOT NULL AUTO_INCREMENT,\n PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"}
+		"show_test", "CREATE TABLE `show_test` (\n  `id` int(11) NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"}
 	for i, r := range row {
 		c.Check(r, Equals, expectedRow[i])
 	}
@@ -240,12 +245,47 @@ func (s *testSuite) TestForeignKeyInShowCreateTable(c *C) {
 	c.Check(result.Rows(), HasLen, 1)
 	row = result.Rows()[0]
 	expectedRow = []interface{}{
-		"show_test", "CREATE TABLE `show_test` (\n  `id` int(11) NOT NULL AUTO_INCREMENT,\n PRIMARY KEY (`id`),\n  CONSTRAINT `Fk` FOREIGN KEY (`id`) REFERENCES `t1` (`id`) ON DELETE CASCADE ON UPDATE CASCADE\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"}
+		"show_test", "CREATE TABLE `show_test` (\n  `id` int(11) NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`),\n  CONSTRAINT `Fk` FOREIGN KEY (`id`) REFERENCES `t1` (`id`) ON DELETE CASCADE ON UPDATE CASCADE\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"}
 	for i, r := range row {
 		c.Check(r, Equals, expectedRow[i])
 	}
 }
 
+func (s *testSuite) TestShowCreateTableRoundTrip(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_round")
+	tk.MustExec(`create table t_round (
+		id int primary key auto_increment,
+		a varchar(20) character set latin1,
+		b varchar(20) character set utf8 collate utf8_general_ci default 'it''s',
+		c text comment 'c\\d',
+		index idx_a (a(5)) comment 'prefix',
+		index idx_b (b) using hash
+	) charset utf8 comment 'round''trip'`)
+	tk.MustExec("insert t_round (a) values ('x'), ('y')")
+	expected := "CREATE TABLE `t_round` (\n" +
+		"  `id` int(11) NOT NULL AUTO_INCREMENT,\n" +
+		"  `a` varchar(20) CHARACTER SET latin1 COLLATE latin1_bin DEFAULT NULL,\n" +
+		"  `b` varchar(20) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT 'it''s',\n" +
+		"  `c` text DEFAULT NULL COMMENT 'c\\\\d',\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `idx_a` (`a`(5)) COMMENT 'prefix',\n" +
+		"  KEY `idx_b` (`b`) USING HASH\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin AUTO_INCREMENT=%d COMMENT='round''trip'"
+	// The IDs are allocated in batches, the next ID is after the batch.
+	autoIncID := autoid.GetStep() + 1
+	c.Assert(tk.MustQuery("show create table t_round").Rows()[0][1], Equals, fmt.Sprintf(expected, autoIncID))
+
+	// The table recreated by the statement is the same.
+	tk.MustExec("drop table t_round")
+	tk.MustExec(fmt.Sprintf(expected, autoIncID))
+	c.Assert(tk.MustQuery("show create table t_round").Rows()[0][1], Equals, fmt.Sprintf(expected, autoIncID))
+	tk.MustExec("insert t_round (a) values ('z')")
+	tk.MustQuery("select id from t_round").Check(testkit.Rows(fmt.Sprintf("%d", autoIncID)))
+	tk.MustExec("drop table t_round")
+}
+
 func (s *testSuite) TestShowWarnings(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
@@ -259,3 +299,154 @@ func (s *testSuite) TestShowWarnings(c *C) {
 	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|", "Warning|1265|Data Truncated"))
 	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(0))
 }
+
+func (s *testSuite) TestWarningCount(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_warn")
+	tk.MustExec("create table t_warn (a int primary key, b int)")
+	tk.MustExec("set @@sql_mode=''")
+
+	// The warnings of all the rows are accumulated.
+	tk.MustExec("insert t_warn values (1, 'a'), (2, 'b'), (3, 'c')")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(3))
+	tk.MustQuery("show count(*) warnings").Check(testkit.Rows("3"))
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|",
+		"Warning|1265|Data Truncated", "Warning|1265|Data Truncated", "Warning|1265|Data Truncated"))
+	tk.MustQuery("select @@warning_count, @@error_count").Check(testkit.Rows("3 0"))
+	// The statements that aren't diagnostic statements clear the warnings.
+	tk.MustQuery("select @@warning_count").Check(testkit.Rows("0"))
+	tk.MustQuery("show warnings").Check(testkit.Rows())
+
+	// The warnings beyond max_error_count are counted but not kept.
+	tk.MustExec("set max_error_count = 2")
+	tk.MustQuery("select @@max_error_count").Check(testkit.Rows("2"))
+	tk.MustExec("update t_warn set b = 'x'")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(3))
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|", "Warning|1265|Data Truncated", "Warning|1265|Data Truncated"))
+	tk.MustQuery("show count(*) warnings").Check(testkit.Rows("3"))
+	tk.MustExec("set max_error_count = 0")
+	tk.MustExec("update t_warn set b = 'y'")
+	tk.MustQuery("show warnings").Check(testkit.Rows())
+	tk.MustQuery("show count(*) warnings").Check(testkit.Rows("3"))
+	tk.MustExec("set max_error_count = default")
+
+	// The error of the failed statement is shown by SHOW WARNINGS and SHOW ERRORS.
+	_, err := tk.Exec("insert t_warn values (1, 1)")
+	c.Assert(err, NotNil)
+	tk.MustQuery("show errors").Check(testutil.RowsWithSep("|", "Error|1062|Duplicate entry '1' for key 'PRIMARY'"))
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|", "Error|1062|Duplicate entry '1' for key 'PRIMARY'"))
+	tk.MustQuery("show count(*) errors").Check(testkit.Rows("1"))
+	tk.MustQuery("select @@warning_count, @@error_count").Check(testkit.Rows("1 1"))
+	tk.MustQuery("show errors").Check(testkit.Rows())
+
+	_, err = tk.Exec("set warning_count = 1")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestShowTableRegions(c *C) {
+	if !*mockTikv {
+		c.Skip("only the mocked tikv supports splitting regions")
+	}
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_regions")
+	tk.MustExec("create table t_regions (a int primary key, b int)")
+	tbl, err := sessionctx.GetDomain(tk.Se).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t_regions"))
+	c.Assert(err, IsNil)
+	tableID := tbl.Meta().ID
+
+	rows := tk.MustQuery("show table t_regions regions").Rows()
+	c.Assert(rows, HasLen, 1)
+
+	tk.MustExec("split table t_regions between (0) and (100) regions 4")
+	rows = tk.MustQuery("show table t_regions regions").Rows()
+	c.Assert(rows, HasLen, 5)
+	for i, handle := range []int{0, 25, 50, 75} {
+		key := fmt.Sprintf("t_%d_r_%d", tableID, handle)
+		c.Assert(rows[i][2], Equals, key)
+		c.Assert(rows[i+1][1], Equals, key)
+	}
+	// Splitting at the existing boundaries doesn't create new regions.
+	tk.MustExec("split table t_regions between (0) and (50) regions 2")
+	tk.MustQuery("show table t_regions regions").Check(rows)
+
+	tk.MustExec("insert t_regions values (1, 1), (30, 30), (99, 99), (200, 200)")
+	tk.MustQuery("select a from t_regions order by a").Check(testkit.Rows("1", "30", "99", "200"))
+
+	_, err = tk.Exec("split table t_regions between (100) and (0) regions 4")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("split table t_regions between (0, 0) and (100, 100) regions 4")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("split table t_regions between (0) and (100) regions 0")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestShowMasterStatus(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_dump")
+	tk.MustExec("create table t_dump (a int)")
+	tk.MustExec("insert t_dump values (1)")
+
+	// The statements issued by mysqldump --single-transaction --master-data.
+	tk.MustExec("FLUSH /*!40101 LOCAL */ TABLES")
+	tk.MustExec("FLUSH TABLES WITH READ LOCK")
+	tk.MustExec("SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ")
+	tk.MustExec("START TRANSACTION /*!40100 WITH CONSISTENT SNAPSHOT */")
+	rows := tk.MustQuery("SHOW MASTER STATUS").Rows()
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0][0], Equals, "tidb-binlog")
+	position := fmt.Sprint(tk.Se.Txn().StartTS())
+	c.Assert(rows[0][1], Equals, position)
+	tk.MustExec("UNLOCK TABLES")
+
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("insert test.t_dump values (2)")
+	// The dump reads the snapshot of the position.
+	tk.MustQuery("select * from t_dump").Check(testkit.Rows("1"))
+	tk.MustQuery("show master status").Check(testkit.Rows("tidb-binlog " + position + "   "))
+	tk.MustExec("commit")
+	tk.MustQuery("select * from t_dump").Check(testkit.Rows("1", "2"))
+	c.Assert(tk.MustQuery("show master status").Rows()[0][1], Not(Equals), position)
+
+	tk.MustExec("set session transaction isolation level read committed, read only")
+	tk.MustQuery("select @@session.tx_isolation, @@session.tx_read_only").Check(testkit.Rows("READ-COMMITTED 1"))
+	// SET TRANSACTION only applies to the next transaction, the session values are unchanged.
+	tk.MustExec("set transaction isolation level serializable")
+	tk.MustQuery("select @@session.tx_isolation").Check(testkit.Rows("READ-COMMITTED"))
+	tk.MustExec("set global transaction isolation level repeatable read")
+	tk.MustQuery("select @@global.tx_isolation").Check(testkit.Rows("REPEATABLE-READ"))
+}
+
+func (s *testSuite) TestShowHotspots(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_hot, t_cold")
+	tk.MustExec("create table t_hot (a int primary key auto_increment, b int, index idx_b (b))")
+	tk.MustExec("create table t_cold (a int primary key)")
+	if !*mockTikv {
+		c.Skip("the traffic is only collected by tikv")
+	}
+	hotspot.Reset()
+	for i := 0; i < 10; i++ {
+		tk.MustExec(fmt.Sprintf("insert t_hot (b) values (%d)", 10-i))
+	}
+	tk.MustExec("insert t_cold values (1)")
+
+	rows := tk.MustQuery("show hotspots").Rows()
+	c.Assert(rows, HasLen, 3)
+	c.Assert(rows[0][1], Equals, "t_hot")
+	c.Assert(rows[0][2], Equals, "")
+	c.Assert(rows[0][5], Equals, "0.9")
+	c.Assert(rows[1][1], Equals, "t_hot")
+	c.Assert(rows[1][2], Equals, "idx_b")
+	c.Assert(rows[1][5], Equals, "0")
+	c.Assert(rows[2][1], Equals, "t_cold")
+	tk.MustQuery("show hotspots where Table_name = 't_cold'").Check(testkit.Rows("test t_cold  1 1 0"))
+
+	tk.MustQuery("select db_name, table_name, index_name, index_id, append_ratio from information_schema.tidb_hotspots where table_name = 't_hot'").Check(
+		testkit.Rows("test t_hot <nil> 0 0.9", "test t_hot idx_b 1 0"))
+	tk.MustExec("drop table t_cold")
+	tk.MustQuery("select count(*) from information_schema.tidb_hotspots where table_name = 't_cold'").Check(testkit.Rows("0"))
+}
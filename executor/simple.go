@@ -71,6 +71,12 @@ func (e *SimpleExec) Next() (*Row, error) {
 		err = e.executeSetPwd(x)
 	case *ast.KillStmt:
 		err = e.executeKillStmt(x)
+	case *ast.CreateEventStmt:
+		err = e.executeCreateEvent(x)
+	case *ast.AlterEventStmt:
+		err = e.executeAlterEvent(x)
+	case *ast.DropEventStmt:
+		err = e.executeDropEvent(x)
 	case *ast.BinlogStmt:
 		// We just ignore it.
 		return nil, nil
@@ -101,8 +107,10 @@ func (e *SimpleExec) executeUse(s *ast.UseStmt) error {
 func (e *SimpleExec) executeBegin(s *ast.BeginStmt) error {
 	// If BEGIN is the first statement in TxnCtx, we can reuse the existing transaction, without the
 	// need to call NewTxn, which commits the existing transaction and begins a new one.
-	txnCtx := e.ctx.GetSessionVars().TxnCtx
-	if txnCtx.Histroy != nil {
+	// The existing transaction may be begun at the low resolution timestamp, it's not reused for the explicit
+	// transactions.
+	sessVars := e.ctx.GetSessionVars()
+	if sessVars.TxnCtx.Histroy != nil || sessVars.LowResolutionTSO {
 		err := e.ctx.NewTxn()
 		if err != nil {
 			return errors.Trace(err)
@@ -111,7 +119,7 @@ func (e *SimpleExec) executeBegin(s *ast.BeginStmt) error {
 	// With START TRANSACTION, autocommit remains disabled until you end
 	// the transaction with COMMIT or ROLLBACK. The autocommit mode then
 	// reverts to its previous state.
-	e.ctx.GetSessionVars().SetStatusFlag(mysql.ServerStatusInTrans, true)
+	sessVars.SetStatusFlag(mysql.ServerStatusInTrans, true)
 	return nil
 }
 
@@ -131,6 +139,7 @@ func (e *SimpleExec) executeRollback(s *ast.RollbackStmt) error {
 
 func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
 	users := make([]string, 0, len(s.Specs))
+	accounts := make([]string, 0, len(s.Specs))
 	for _, spec := range s.Specs {
 		userName, host := parseUser(spec.User)
 		exists, err1 := userExists(e.ctx, userName, host)
@@ -153,6 +162,7 @@ func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
 		}
 		user := fmt.Sprintf(`("%s", "%s", "%s")`, host, userName, pwd)
 		users = append(users, user)
+		accounts = append(accounts, spec.User)
 	}
 	if len(users) == 0 {
 		return nil
@@ -162,6 +172,10 @@ func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	err = addPrivilegeHistory(e.ctx, s.Text(), accounts)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	sessionctx.GetDomain(e.ctx).NotifyUpdatePrivilege(e.ctx)
 	return errors.Trace(err)
 }
@@ -180,6 +194,7 @@ func (e *SimpleExec) executeAlterUser(s *ast.AlterUserStmt) error {
 	}
 
 	failedUsers := make([]string, 0, len(s.Specs))
+	accounts := make([]string, 0, len(s.Specs))
 	for _, spec := range s.Specs {
 		userName, host := parseUser(spec.User)
 		exists, err := userExists(e.ctx, userName, host)
@@ -206,7 +221,13 @@ func (e *SimpleExec) executeAlterUser(s *ast.AlterUserStmt) error {
 		_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
 		if err != nil {
 			failedUsers = append(failedUsers, spec.User)
+			continue
 		}
+		accounts = append(accounts, spec.User)
+	}
+	err := addRestrictedPrivilegeHistory(e.ctx, s.Text(), accounts)
+	if err != nil {
+		return errors.Trace(err)
 	}
 	if len(failedUsers) > 0 {
 		// Commit the transaction even if we returns error
@@ -222,6 +243,7 @@ func (e *SimpleExec) executeAlterUser(s *ast.AlterUserStmt) error {
 
 func (e *SimpleExec) executeDropUser(s *ast.DropUserStmt) error {
 	failedUsers := make([]string, 0, len(s.UserList))
+	accounts := make([]string, 0, len(s.UserList))
 	for _, user := range s.UserList {
 		userName, host := parseUser(user)
 		exists, err := userExists(e.ctx, userName, host)
@@ -238,7 +260,13 @@ func (e *SimpleExec) executeDropUser(s *ast.DropUserStmt) error {
 		_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
 		if err != nil {
 			failedUsers = append(failedUsers, user)
+			continue
 		}
+		accounts = append(accounts, user)
+	}
+	err := addRestrictedPrivilegeHistory(e.ctx, s.Text(), accounts)
+	if err != nil {
+		return errors.Trace(err)
 	}
 	if len(failedUsers) > 0 {
 		// Commit the transaction even if we returns error
@@ -288,6 +316,10 @@ func (e *SimpleExec) executeSetPwd(s *ast.SetPwdStmt) error {
 	// update mysql.user
 	sql := fmt.Sprintf(`UPDATE %s.%s SET password="%s" WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, util.EncodePassword(s.Password), userName, host)
 	_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = addRestrictedPrivilegeHistory(e.ctx, s.Text(), []string{s.User})
 	sessionctx.GetDomain(e.ctx).NotifyUpdatePrivilege(e.ctx)
 	return errors.Trace(err)
 }
@@ -306,7 +338,8 @@ func (e *SimpleExec) executeKillStmt(s *ast.KillStmt) error {
 func (e *SimpleExec) executeFlush(s *ast.FlushStmt) error {
 	switch s.Tp {
 	case ast.FlushTables:
-		// TODO: A dummy implement
+		// FLUSH TABLES WITH READ LOCK is a no-op, the dump tools get a consistent snapshot by
+		// START TRANSACTION WITH CONSISTENT SNAPSHOT, and its ts by SHOW MASTER STATUS.
 	case ast.FlushPrivileges:
 		dom := sessionctx.GetDomain(e.ctx)
 		err := dom.PrivilegeHandle().Update()
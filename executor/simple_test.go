@@ -14,9 +14,12 @@
 package executor_test
 
 import (
+	"time"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/event"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege/privileges"
@@ -235,3 +238,143 @@ func (s *testSuite) TestFlushPrivileges(c *C) {
 
 	privileges.Enable = save
 }
+
+func (s *testSuite) TestPrivilegeHistory(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	var err error
+	tk.Se, err = tidb.CreateSession(s.store)
+	c.Assert(err, IsNil)
+	tk.Se.(context.Context).GetSessionVars().User = "root@localhost"
+
+	tk.MustExec(`CREATE USER 'hist1'@'%' IDENTIFIED BY 'secret', 'hist2'@'%'`)
+	tk.MustExec(`GRANT SELECT ON test.* TO 'hist1'@'%'`)
+	tk.MustExec(`REVOKE SELECT ON test.* FROM 'hist1'@'%'`)
+	tk.MustExec(`SET PASSWORD FOR 'hist2'@'%' = 'secret2'`)
+	tk.MustExec(`ALTER USER 'hist2'@'%' IDENTIFIED BY 'secret3'`)
+	tk.MustExec(`DROP USER 'hist1'@'%', 'hist2'@'%'`)
+	// The failed statements are not recorded.
+	_, err = tk.Exec(`REVOKE SELECT ON test.* FROM 'hist1'@'%'`)
+	c.Assert(err, NotNil)
+
+	tk.MustQuery(`SELECT user, account, statement FROM mysql.privilege_history WHERE account LIKE 'hist%' ORDER BY id`).Check(testkit.Rows(
+		`root@localhost hist1@% CREATE USER 'hist1'@'%' IDENTIFIED BY '***', 'hist2'@'%'`,
+		`root@localhost hist2@% CREATE USER 'hist1'@'%' IDENTIFIED BY '***', 'hist2'@'%'`,
+		`root@localhost hist1@% GRANT SELECT ON test.* TO 'hist1'@'%'`,
+		`root@localhost hist1@% REVOKE SELECT ON test.* FROM 'hist1'@'%'`,
+		`root@localhost hist2@% SET PASSWORD FOR 'hist2'@'%' = '***'`,
+		`root@localhost hist2@% ALTER USER 'hist2'@'%' IDENTIFIED BY '***'`,
+		`root@localhost hist1@% DROP USER 'hist1'@'%', 'hist2'@'%'`,
+		`root@localhost hist2@% DROP USER 'hist1'@'%', 'hist2'@'%'`,
+	))
+	tk.MustQuery(`SELECT count(*) FROM mysql.privilege_history WHERE account LIKE 'hist%' AND time > DATE_SUB(UTC_TIMESTAMP(), INTERVAL 1 HOUR)`).Check(testkit.Rows("8"))
+}
+
+func (s *testSuite) TestEvent(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table event_t (a int)")
+
+	// An event which will never execute is dropped at once, or disabled if it is preserved.
+	tk.MustExec("create event e0 on schedule at '2000-01-01 00:00:00' do insert into event_t values (0)")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	tk.MustQuery("show events").Check(testkit.Rows())
+	tk.MustExec("create event e0 on schedule at '2000-01-01 00:00:00' on completion preserve do insert into event_t values (0)")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	tk.MustQuery("select status from mysql.event where name = 'e0'").Check(testkit.Rows("DISABLED"))
+
+	tk.MustExec("set @@time_zone = '+08:00'")
+	tk.MustExec(`create event e1 on schedule every 2 day starts '2030-01-01 08:00:00' ends '2030-02-01 08:00:00'
+		comment 'it''s e1' do insert into event_t values (1)`)
+	tk.MustQuery("select starts, ends, comment, body from mysql.event where name = 'e1'").Check(testkit.Rows(
+		"2030-01-01 00:00:00 2030-02-01 00:00:00 it's e1 insert into event_t values (1)"))
+	tk.MustQuery("show events like 'e1'").Check(testkit.Rows(
+		"test e1 +08:00  RECURRING <nil> 2 DAY 2030-01-01 08:00:00 2030-02-01 08:00:00 ENABLED 0 utf8 utf8_general_ci utf8_general_ci"))
+
+	_, err := tk.Exec("create event e1 on schedule every 1 hour do insert into event_t values (1)")
+	c.Assert(terror.ErrorEqual(err, executor.ErrEventExists), IsTrue)
+	tk.MustExec("create event if not exists e1 on schedule every 1 hour do insert into event_t values (1)")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	_, err = tk.Exec("create event e2 on schedule every 0 hour do insert into event_t values (1)")
+	c.Assert(terror.ErrorEqual(err, executor.ErrEventInterval), IsTrue)
+	_, err = tk.Exec("create event e2 on schedule every 1 hour starts '2030-01-02' ends '2030-01-01' do insert into event_t values (1)")
+	c.Assert(terror.ErrorEqual(err, executor.ErrEventEndsBeforeStarts), IsTrue)
+	_, err = tk.Exec("create event e2 on schedule every 1 microsecond do insert into event_t values (1)")
+	c.Assert(terror.ErrorEqual(err, executor.ErrNotSupportedYet), IsTrue)
+	_, err = tk.Exec("create event no_such_db.e2 on schedule every 1 hour do insert into event_t values (1)")
+	c.Assert(err, NotNil)
+
+	tk.MustExec("alter event e1 on schedule at '2030-01-01 08:00:00' on completion preserve disable do delete from event_t")
+	tk.MustQuery("show events like 'e1'").Check(testkit.Rows(
+		"test e1 +08:00  ONE TIME 2030-01-01 08:00:00 <nil> <nil> <nil> <nil> DISABLED 0 utf8 utf8_general_ci utf8_general_ci"))
+	tk.MustQuery("select on_completion, body from mysql.event where name = 'e1'").Check(testkit.Rows("PRESERVE delete from event_t"))
+	_, err = tk.Exec("alter event e2 enable")
+	c.Assert(terror.ErrorEqual(err, executor.ErrEventNotExists), IsTrue)
+
+	// The quotes in the names are escaped, so they can't change the stored definition.
+	tk.MustExec("create event `e3', definer = 'root@%` on schedule every 1 hour do insert into event_t values (3)")
+	tk.MustQuery("select name, definer from mysql.event where name like 'e3%'").Check(testkit.Rows("e3', definer = 'root@% "))
+	tk.MustExec("alter event `e3', definer = 'root@%` disable")
+	tk.MustQuery("select name, status from mysql.event where name like 'e3%'").Check(testkit.Rows("e3', definer = 'root@% DISABLED"))
+	tk.MustExec("drop event `e3', definer = 'root@%`")
+
+	tk.MustExec("drop event e0")
+	tk.MustExec("drop event test.e1")
+	tk.MustQuery("show events").Check(testkit.Rows())
+	_, err = tk.Exec("drop event e1")
+	c.Assert(terror.ErrorEqual(err, executor.ErrEventNotExists), IsTrue)
+	tk.MustExec("drop event if exists e1")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	tk.MustExec("drop table event_t")
+}
+
+func (s *testSuite) TestEventScheduler(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table event_t (a int)")
+	tk.MustExec("create event e1 on schedule every 1 hour do insert into event_t values (1)")
+	tk.MustExec("create event `e2'` on schedule at date_add(now(), interval 5 second) do insert into event_t values (2)")
+
+	se1, err := tidb.CreateSession(s.store)
+	c.Assert(err, IsNil)
+	defer se1.Close()
+	se2, err := tidb.CreateSession(s.store)
+	c.Assert(err, IsNil)
+	defer se2.Close()
+	scheduler1 := event.NewScheduler(se1, nil)
+	scheduler2 := event.NewScheduler(se2, nil)
+
+	now := time.Now()
+	// The event scheduler is off by default.
+	c.Assert(scheduler1.Tick(now), IsNil)
+	tk.MustQuery("select * from event_t").Check(testkit.Rows())
+
+	tk.MustExec("set global event_scheduler = 'ON'")
+	defer tk.MustExec("set global event_scheduler = 'OFF'")
+	c.Assert(scheduler1.Tick(now), IsNil)
+	tk.MustQuery("select * from event_t").Check(testkit.Rows("1"))
+	// Only the leader executes the events.
+	c.Assert(scheduler2.Tick(now.Add(10*time.Second)), IsNil)
+	tk.MustQuery("select * from event_t").Check(testkit.Rows("1"))
+	c.Assert(scheduler1.Tick(now.Add(10*time.Second)), IsNil)
+	tk.MustQuery("select * from event_t order by a").Check(testkit.Rows("1", "2"))
+	// The one time event is dropped after it executes.
+	tk.MustQuery("select name from mysql.event").Check(testkit.Rows("e1"))
+
+	// Another server takes over after the lease of the leader expires.
+	c.Assert(scheduler2.Tick(now.Add(time.Hour+time.Second)), IsNil)
+	tk.MustQuery("select * from event_t order by a").Check(testkit.Rows("1", "1", "2"))
+	c.Assert(scheduler1.Tick(now.Add(time.Hour+2*time.Second)), IsNil)
+	tk.MustQuery("select * from event_t order by a").Check(testkit.Rows("1", "1", "2"))
+
+	tk.MustExec("drop event e1")
+	tk.MustExec("drop table event_t")
+}
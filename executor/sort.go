@@ -39,11 +39,20 @@ type SortExec struct {
 	fetched bool
 	err     error
 	schema  *expression.Schema
+
+	// memQuota is the memory quota in bytes of the rows, 0 means no quota. When the rows exceed it, they are
+	// sorted and written to a temporary file as a run, the runs are merged after all the rows are fetched.
+	memQuota    int
+	memUsage    int
+	runs        []*sortRun
+	mergeHeap   *sortRunHeap
+	rowKeyCache []*RowKeyEntry
 }
 
 // Close implements the Executor Close interface.
 func (e *SortExec) Close() error {
 	e.Rows = nil
+	e.closeRuns()
 	return errors.Trace(e.children[0].Close())
 }
 
@@ -51,6 +60,7 @@ func (e *SortExec) Close() error {
 func (e *SortExec) Open() error {
 	e.fetched = false
 	e.Rows = nil
+	e.closeRuns()
 	return errors.Trace(e.children[0].Open())
 }
 
@@ -66,10 +76,14 @@ func (e *SortExec) Swap(i, j int) {
 
 // Less implements sort.Interface Less interface.
 func (e *SortExec) Less(i, j int) bool {
+	return e.lessRow(e.Rows[i], e.Rows[j])
+}
+
+func (e *SortExec) lessRow(row1, row2 *orderByRow) bool {
 	sc := e.ctx.GetSessionVars().StmtCtx
 	for index, by := range e.ByItems {
-		v1 := e.Rows[i].key[index]
-		v2 := e.Rows[j].key[index]
+		v1 := row1.key[index]
+		v2 := row2.key[index]
 
 		ret, err := v1.CompareDatum(sc, v2)
 		if err != nil {
@@ -91,6 +105,21 @@ func (e *SortExec) Less(i, j int) bool {
 	return false
 }
 
+func (e *SortExec) buildOrderByRow(srcRow *Row) (*orderByRow, error) {
+	orderRow := &orderByRow{
+		row: srcRow,
+		key: make([]types.Datum, len(e.ByItems)),
+	}
+	var err error
+	for i, byItem := range e.ByItems {
+		orderRow.key[i], err = byItem.Expr.Eval(srcRow.Data)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return orderRow, nil
+}
+
 // Next implements the Executor Next interface.
 func (e *SortExec) Next() (*Row, error) {
 	if !e.fetched {
@@ -102,24 +131,35 @@ func (e *SortExec) Next() (*Row, error) {
 			if srcRow == nil {
 				break
 			}
-			orderRow := &orderByRow{
-				row: srcRow,
-				key: make([]types.Datum, len(e.ByItems)),
+			orderRow, err := e.buildOrderByRow(srcRow)
+			if err != nil {
+				return nil, errors.Trace(err)
 			}
-			for i, byItem := range e.ByItems {
-				orderRow.key[i], err = byItem.Expr.Eval(srcRow.Data)
-				if err != nil {
-					return nil, errors.Trace(err)
+			e.Rows = append(e.Rows, orderRow)
+			if e.memQuota > 0 {
+				e.memUsage += estimateSortRowMemUsage(orderRow)
+				if e.memUsage > e.memQuota {
+					if err = e.spillRows(); err != nil {
+						return nil, errors.Trace(err)
+					}
 				}
 			}
-			e.Rows = append(e.Rows, orderRow)
 		}
-		sort.Sort(e)
+		if len(e.runs) > 0 {
+			if err := e.initMerge(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		} else {
+			sort.Sort(e)
+		}
 		e.fetched = true
 	}
 	if e.err != nil {
 		return nil, errors.Trace(e.err)
 	}
+	if e.mergeHeap != nil {
+		return e.nextMerged()
+	}
 	if e.Idx >= len(e.Rows) {
 		return nil, nil
 	}
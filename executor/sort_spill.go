@@ -0,0 +1,187 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// estimatedDatumSize is the estimated memory used by a types.Datum, excluding the bytes it refers to.
+const estimatedDatumSize = 56
+
+// estimateSortRowMemUsage estimates the memory used by a row and its order values in SortExec.
+func estimateSortRowMemUsage(row *orderByRow) int {
+	usage := estimatedDatumSize * (len(row.row.Data) + len(row.key))
+	for _, datums := range [][]types.Datum{row.row.Data, row.key} {
+		for i := range datums {
+			usage += len(datums[i].GetBytes())
+		}
+	}
+	return usage
+}
+
+// sortRun is a temporary file holding a sorted run of the rows encoded by encodeJoinRow.
+type sortRun struct {
+	file   *os.File
+	reader *bufio.Reader
+	// cur is the current row of the run to be merged.
+	cur *orderByRow
+}
+
+// sortRunHeap merges the sorted runs by the current rows of them.
+type sortRunHeap struct {
+	e    *SortExec
+	runs []*sortRun
+}
+
+func (h *sortRunHeap) Len() int {
+	return len(h.runs)
+}
+
+func (h *sortRunHeap) Less(i, j int) bool {
+	return h.e.lessRow(h.runs[i].cur, h.runs[j].cur)
+}
+
+func (h *sortRunHeap) Swap(i, j int) {
+	h.runs[i], h.runs[j] = h.runs[j], h.runs[i]
+}
+
+func (h *sortRunHeap) Push(x interface{}) {
+	h.runs = append(h.runs, x.(*sortRun))
+}
+
+func (h *sortRunHeap) Pop() interface{} {
+	run := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return run
+}
+
+// spillRows sorts the rows in the memory and writes them to a new run.
+func (e *SortExec) spillRows() error {
+	sort.Sort(e)
+	if e.err != nil {
+		return errors.Trace(e.err)
+	}
+	f, err := ioutil.TempFile("", "tidb-sort-")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	run := &sortRun{file: f}
+	e.runs = append(e.runs, run)
+	w := bufio.NewWriter(f)
+	loc := e.ctx.GetSessionVars().GetTimeZone()
+	var (
+		buffer []byte
+		lenBuf [binary.MaxVarintLen64]byte
+	)
+	for _, row := range e.Rows {
+		buffer, err = encodeJoinRow(buffer[:0], row.row, &e.rowKeyCache, loc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		n := binary.PutUvarint(lenBuf[:], uint64(len(buffer)))
+		if _, err = w.Write(lenBuf[:n]); err != nil {
+			return errors.Trace(err)
+		}
+		if _, err = w.Write(buffer); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return errors.Trace(err)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return errors.Trace(err)
+	}
+	run.reader = bufio.NewReader(f)
+	e.Rows = nil
+	e.memUsage = 0
+	return nil
+}
+
+// readRun reads the next row of the run to cur, cur is nil if there are no more rows.
+func (e *SortExec) readRun(run *sortRun) error {
+	run.cur = nil
+	data, err := readSpilledBytes(run.reader)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	row, err := decodeJoinRow(data, e.rowKeyCache, e.children[0].Schema(), e.ctx.GetSessionVars().GetTimeZone())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	run.cur, err = e.buildOrderByRow(row)
+	return errors.Trace(err)
+}
+
+// initMerge spills the rest rows and reads the first row of every run to the merge heap.
+func (e *SortExec) initMerge() error {
+	if len(e.Rows) > 0 {
+		if err := e.spillRows(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	e.mergeHeap = &sortRunHeap{e: e, runs: make([]*sortRun, 0, len(e.runs))}
+	for _, run := range e.runs {
+		if err := e.readRun(run); err != nil {
+			return errors.Trace(err)
+		}
+		if run.cur != nil {
+			e.mergeHeap.runs = append(e.mergeHeap.runs, run)
+		}
+	}
+	heap.Init(e.mergeHeap)
+	return errors.Trace(e.err)
+}
+
+// nextMerged returns the least row of the runs.
+func (e *SortExec) nextMerged() (*Row, error) {
+	if e.mergeHeap.Len() == 0 {
+		return nil, nil
+	}
+	run := e.mergeHeap.runs[0]
+	row := run.cur.row
+	if err := e.readRun(run); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if run.cur == nil {
+		heap.Pop(e.mergeHeap)
+	} else {
+		heap.Fix(e.mergeHeap, 0)
+	}
+	return row, errors.Trace(e.err)
+}
+
+// closeRuns closes and removes the temporary files of the runs.
+func (e *SortExec) closeRuns() {
+	for _, run := range e.runs {
+		run.file.Close()
+		os.Remove(run.file.Name())
+	}
+	e.runs = nil
+	e.mergeHeap = nil
+	e.memUsage = 0
+}
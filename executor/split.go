@@ -0,0 +1,118 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/tablecodec"
+)
+
+// SplitRegionExec represents a split table region executor.
+// It is built from the "split table t between (lower) and (upper) regions num" statement.
+type SplitRegionExec struct {
+	baseExecutor
+
+	table *model.TableInfo
+	lower expression.Expression
+	upper expression.Expression
+	num   uint64
+	done  bool
+}
+
+// Next implements the Executor Next interface.
+func (e *SplitRegionExec) Next() (*Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	store, ok := sessionctx.GetDomain(e.ctx).Store().(kv.RegionStorage)
+	if !ok {
+		return nil, ErrNotSupportedYet.GenByArgs("split table region")
+	}
+	keys, err := e.splitKeys()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, key := range keys {
+		if err = store.SplitRegion(key); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return nil, nil
+}
+
+// splitKeys divides the handle range [lower, upper) into num parts evenly, and returns the
+// row keys of the start handles of the parts.
+func (e *SplitRegionExec) splitKeys() ([]kv.Key, error) {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	lowerVal, err := e.lower.Eval(nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	lower, err := lowerVal.ToInt64(sc)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	upperVal, err := e.upper.Eval(nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	upper, err := upperVal.ToInt64(sc)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if lower >= upper {
+		return nil, errors.Errorf("Split table region lower value %d should be less than the upper value %d", lower, upper)
+	}
+	step := (uint64(upper) - uint64(lower)) / e.num
+	if step == 0 {
+		step = 1
+	}
+	keys := make([]kv.Key, 0, e.num)
+	for handle := lower; handle < upper && uint64(len(keys)) < e.num; handle += int64(step) {
+		keys = append(keys, tablecodec.EncodeRowKeyWithHandle(e.table.ID, handle))
+		if uint64(upper)-uint64(handle) <= step {
+			break
+		}
+	}
+	return keys, nil
+}
+
+// regionKeyString formats the key of a region to be readable, e.g. "t_10_r_100" for the row key
+// of the handle 100 in table 10, and "t_10_i_1" for the keys of the index 1 in table 10.
+func regionKeyString(key kv.Key) string {
+	if len(key) == 0 {
+		return ""
+	}
+	tableID, indexID, isRecordKey, err := tablecodec.DecodeKeyHead(key)
+	if err != nil {
+		if tableID = tablecodec.DecodeTableID(key); tableID != 0 {
+			return fmt.Sprintf("t_%d", tableID)
+		}
+		return fmt.Sprintf("%x", []byte(key))
+	}
+	if !isRecordKey {
+		return fmt.Sprintf("t_%d_i_%d", tableID, indexID)
+	}
+	if _, handle, err := tablecodec.DecodeRecordKey(key); err == nil {
+		return fmt.Sprintf("t_%d_r_%d", tableID, handle)
+	}
+	return fmt.Sprintf("t_%d_r", tableID)
+}
@@ -0,0 +1,138 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/tracing"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// TraceExec represents a trace executor, it executes the statement and shows the time spent in its phases.
+type TraceExec struct {
+	baseExecutor
+
+	stmtNode ast.StmtNode
+	is       infoschema.InfoSchema
+	rows     []*Row
+	cursor   int
+}
+
+// Open implements the Executor Open interface.
+// The statement is executed here rather than in Next, because the transaction of an auto-commit
+// statement is committed once the record set is returned.
+func (e *TraceExec) Open() error {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	// The root span is started by the session, so the parsing and compiling are traced. It's started here
+	// if the statement isn't executed by the session, like the statements run by the tests.
+	root := sc.TraceSpan
+	if root == nil {
+		root = tracing.StartSpan("trace")
+	}
+	err := e.trace(root)
+	sc.TraceSpan = root
+	root.Finish()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.rows = e.rows[:0]
+	e.appendSpanRows(root, "", "")
+	return nil
+}
+
+func (e *TraceExec) trace(root *tracing.Span) error {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	span := root.StartChild("session.optimize")
+	p, err := plan.Optimize(e.ctx, e.stmtNode, e.is)
+	span.Finish()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	execSpan := root.StartChild("session.execute")
+	defer execSpan.Finish()
+	b := newExecutorBuilder(e.ctx, e.is)
+	exec := b.build(p)
+	if b.err != nil {
+		return errors.Trace(b.err)
+	}
+
+	// The coprocessor requests are sent by the phase that sc.TraceSpan is set to.
+	sc.TraceSpan = execSpan.StartChild("executor.open")
+	err = exec.Open()
+	sc.TraceSpan.Finish()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sc.TraceSpan = execSpan.StartChild("executor.next")
+	for {
+		var row *Row
+		row, err = exec.Next()
+		if err != nil || row == nil {
+			break
+		}
+	}
+	sc.TraceSpan.Finish()
+	sc.TraceSpan = execSpan.StartChild("executor.close")
+	closeErr := exec.Close()
+	sc.TraceSpan.Finish()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(closeErr)
+}
+
+// appendSpanRows appends the rows of span and its children in depth-first order, the operations are
+// indented as a tree by prefix.
+func (e *TraceExec) appendSpanRows(span *tracing.Span, prefix, branch string) {
+	duration := "-"
+	if d, ok := span.Duration(); ok {
+		duration = d.String()
+	}
+	e.rows = append(e.rows, &Row{
+		Data: types.MakeDatums(prefix+branch+span.Operation, span.Start.Format("15:04:05.000000"), duration),
+	})
+	if branch == "├─" {
+		prefix += "│ "
+	} else if branch == "└─" {
+		prefix += "  "
+	}
+	children := span.Children()
+	for i, child := range children {
+		childBranch := "├─"
+		if i == len(children)-1 {
+			childBranch = "└─"
+		}
+		e.appendSpanRows(child, prefix, childBranch)
+	}
+}
+
+// Next implements the Executor Next interface.
+func (e *TraceExec) Next() (*Row, error) {
+	if e.cursor >= len(e.rows) {
+		return nil, nil
+	}
+	row := e.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
+
+// Close implements the Executor Close interface.
+func (e *TraceExec) Close() error {
+	e.rows = nil
+	return nil
+}
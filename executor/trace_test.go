@@ -0,0 +1,56 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (s *testSuite) TestTrace(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3)")
+
+	rows := tk.MustQuery("trace select * from t").Rows()
+	var operations []string
+	for _, row := range rows {
+		c.Assert(row, HasLen, 3)
+		c.Assert(row[2], Not(Equals), "-", Commentf("%v", row))
+		operations = append(operations, strings.TrimLeft(row[0].(string), "├└│─ "))
+	}
+	c.Assert(operations[0], Equals, "trace")
+	c.Assert(rows[1][0], Equals, "├─session.parse")
+	for _, op := range []string{"session.compile", "session.optimize", "session.execute", "executor.open",
+		"executor.next", "executor.close", "tikv.coprocessor"} {
+		found := false
+		for _, operation := range operations {
+			found = found || operation == op
+		}
+		c.Assert(found, IsTrue, Commentf("%s isn't traced: %v", op, rows))
+	}
+
+	// The statement is executed.
+	tk.MustQuery("trace insert into t values (4, 4)")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("4"))
+}
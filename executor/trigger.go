@@ -0,0 +1,418 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// triggerTablesKeyType is a dummy type to avoid naming collision in context.
+type triggerTablesKeyType int
+
+// String defines a Stringer function for debugging and pretty printing.
+func (k triggerTablesKeyType) String() string {
+	return "trigger_tables"
+}
+
+// triggerTablesKey is the key of the tables whose triggers are running in the session,
+// the trigger bodies are not allowed to write those tables again.
+const triggerTablesKey triggerTablesKeyType = 0
+
+// rowRef is a reference to a column of the OLD or NEW row in a trigger body.
+// The reference is replaced by a value expression, which is set to the column value of the current row before the body runs.
+type rowRef struct {
+	expr  *ast.ValueExpr
+	isNew bool
+	col   *table.Column
+}
+
+// triggerAssignment is an assignment `SET NEW.col = expr` in a trigger body.
+type triggerAssignment struct {
+	col  *table.Column
+	expr ast.ExprNode
+}
+
+// compiledTrigger is a trigger whose body has been resolved against the table it belongs to.
+type compiledTrigger struct {
+	timing      model.TriggerTiming
+	event       model.TriggerEvent
+	refs        []rowRef
+	assignments []triggerAssignment
+	body        ast.DMLNode
+	// writeTables are the IDs of the tables written by body.
+	writeTables []int64
+}
+
+// resolveTrigger checks the OLD and NEW row references in the trigger body and replaces them by value expressions.
+// The unqualified table names in the body are qualified by the schema of the trigger.
+func resolveTrigger(stmt *ast.CreateTriggerStmt, t table.Table, schema model.CIStr) (*compiledTrigger, error) {
+	trigger := &compiledTrigger{
+		timing: stmt.Timing,
+		event:  stmt.Event,
+		body:   stmt.Body,
+	}
+	resolver := &triggerResolver{
+		trigger: trigger,
+		cols:    t.Cols(),
+		schema:  schema,
+	}
+	for _, assign := range stmt.Assignments {
+		col, err := resolver.assignedColumn(assign.Column)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		node, ok := assign.Expr.Accept(resolver)
+		if !ok {
+			return nil, errors.Trace(resolver.err)
+		}
+		trigger.assignments = append(trigger.assignments, triggerAssignment{col: col, expr: node.(ast.ExprNode)})
+	}
+	if stmt.Body != nil {
+		if _, ok := stmt.Body.Accept(resolver); !ok {
+			return nil, errors.Trace(resolver.err)
+		}
+	}
+	return trigger, nil
+}
+
+// compileTrigger parses the stored trigger statement and prepares its body for execution.
+func compileTrigger(ctx context.Context, is infoschema.InfoSchema, info *model.TriggerInfo, t table.Table, schema model.CIStr) (*compiledTrigger, error) {
+	charset, collation := ctx.GetSessionVars().GetCharsetInfo()
+	node, err := parser.New().ParseOneStmt(info.Statement, charset, collation)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	stmt, ok := node.(*ast.CreateTriggerStmt)
+	if !ok {
+		return nil, errors.Errorf("invalid statement for trigger %s: %s", info.Name, info.Statement)
+	}
+	trigger, err := resolveTrigger(stmt, t, schema)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if trigger.body == nil {
+		return trigger, nil
+	}
+	err = plan.PrepareStmt(is, ctx, trigger.body)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var collector writeTableCollector
+	switch x := trigger.body.(type) {
+	case *ast.InsertStmt:
+		x.Table.Accept(&collector)
+	case *ast.UpdateStmt:
+		x.TableRefs.Accept(&collector)
+	case *ast.DeleteStmt:
+		if x.IsMultiTable {
+			x.Tables.Accept(&collector)
+		} else {
+			x.TableRefs.Accept(&collector)
+		}
+	}
+	trigger.writeTables = collector.ids
+	return trigger, nil
+}
+
+// run runs the trigger for a row. The assigned column offsets of newRow are returned.
+func (tr *compiledTrigger) run(ctx context.Context, is infoschema.InfoSchema, t table.Table, oldRow, newRow []types.Datum) ([]int, error) {
+	for _, ref := range tr.refs {
+		row := oldRow
+		if ref.isNew {
+			row = newRow
+		}
+		ref.expr.SetDatum(row[ref.col.Offset])
+		ref.expr.SetType(&ref.col.FieldType)
+	}
+	if tr.body == nil {
+		offsets, err := tr.assign(ctx, newRow)
+		return offsets, errors.Trace(err)
+	}
+	return nil, errors.Trace(tr.execBody(ctx, is, t))
+}
+
+func (tr *compiledTrigger) assign(ctx context.Context, newRow []types.Datum) ([]int, error) {
+	sc := ctx.GetSessionVars().StmtCtx
+	offsets := make([]int, 0, len(tr.assignments))
+	for _, assign := range tr.assignments {
+		err := expression.InferType(sc, assign.expr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		val, err := expression.EvalAstExpr(assign.expr, ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		col := assign.col
+		casted, err := table.CastValue(ctx, val, col.ToInfo())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err = col.CheckNotNull(casted); err != nil {
+			return nil, errors.Trace(err)
+		}
+		newRow[col.Offset] = casted
+		offsets = append(offsets, col.Offset)
+		// The following assignments see the new value.
+		for _, ref := range tr.refs {
+			if ref.isNew && ref.col.Offset == col.Offset {
+				ref.expr.SetDatum(casted)
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// execBody executes the DML body of the trigger.
+func (tr *compiledTrigger) execBody(ctx context.Context, is infoschema.InfoSchema, t table.Table) error {
+	tables, ok := ctx.Value(triggerTablesKey).(map[int64]struct{})
+	if !ok {
+		tables = make(map[int64]struct{})
+		ctx.SetValue(triggerTablesKey, tables)
+	}
+	tid := t.Meta().ID
+	for _, id := range tr.writeTables {
+		if _, ok := tables[id]; ok || id == tid {
+			name := t.Meta().Name.O
+			if wt, ok := is.TableByID(id); ok {
+				name = wt.Meta().Name.O
+			}
+			return ErrCantUpdateUsedTable.GenByArgs(name)
+		}
+	}
+	tables[tid] = struct{}{}
+	defer delete(tables, tid)
+	return errors.Trace(execInternalDML(ctx, is, tr.body))
+}
+
+// execInternalDML executes a DML statement generated inside the execution of another statement, in the same
+// transaction. It runs in its own statement context, so the affected rows and the last insert ID of the outer
+// statement are not changed.
+func execInternalDML(ctx context.Context, is infoschema.InfoSchema, stmt ast.DMLNode) error {
+	vars := ctx.GetSessionVars()
+	outerSC := vars.StmtCtx
+	sc := &variable.StatementContext{
+		IgnoreTruncate:    outerSC.IgnoreTruncate,
+		TruncateAsWarning: outerSC.TruncateAsWarning,
+		MaxErrorCount:     outerSC.MaxErrorCount,
+	}
+	if _, ok := stmt.(*ast.InsertStmt); !ok {
+		sc.InUpdateOrDeleteStmt = true
+	}
+	lastInsertID, insertID := vars.LastInsertID, vars.InsertID
+	vars.StmtCtx = sc
+	defer func() {
+		vars.StmtCtx = outerSC
+		vars.LastInsertID, vars.InsertID = lastInsertID, insertID
+		outerSC.MergeWarnings(sc)
+	}()
+
+	p, err := plan.Optimize(ctx, stmt, is)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	b := newExecutorBuilder(ctx, is)
+	e := b.build(p)
+	if b.err != nil {
+		return errors.Trace(b.err)
+	}
+	if err = e.Open(); err != nil {
+		return errors.Trace(err)
+	}
+	for {
+		row, err := e.Next()
+		if err != nil {
+			e.Close()
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+	}
+	return errors.Trace(e.Close())
+}
+
+// triggerResolver resolves the OLD and NEW row references in a trigger body.
+type triggerResolver struct {
+	trigger *compiledTrigger
+	cols    []*table.Column
+	schema  model.CIStr
+	err     error
+}
+
+// Enter implements ast.Visitor interface.
+func (r *triggerResolver) Enter(in ast.Node) (ast.Node, bool) {
+	return in, false
+}
+
+// Leave implements ast.Visitor interface.
+func (r *triggerResolver) Leave(in ast.Node) (ast.Node, bool) {
+	switch x := in.(type) {
+	case *ast.ColumnNameExpr:
+		if x.Name.Schema.L != "" {
+			break
+		}
+		var isNew bool
+		switch x.Name.Table.L {
+		case "new":
+			isNew = true
+		case "old":
+		default:
+			return in, true
+		}
+		if isNew && r.trigger.event == model.TriggerDelete {
+			r.err = ErrTrgNoSuchRow.GenByArgs("NEW", "on DELETE")
+			return in, false
+		}
+		if !isNew && r.trigger.event == model.TriggerInsert {
+			r.err = ErrTrgNoSuchRow.GenByArgs("OLD", "on INSERT")
+			return in, false
+		}
+		col := table.FindCol(r.cols, x.Name.Name.L)
+		if col == nil {
+			r.err = infoschema.ErrColumnNotExists.GenByArgs(x.Name.Name.O, x.Name.Table.O)
+			return in, false
+		}
+		expr := ast.NewValueExpr(nil)
+		r.trigger.refs = append(r.trigger.refs, rowRef{expr: expr, isNew: isNew, col: col})
+		return expr, true
+	case *ast.TableName:
+		if x.Schema.L == "" {
+			x.Schema = r.schema
+		}
+	}
+	return in, true
+}
+
+// assignedColumn returns the column of the NEW row assigned by `SET NEW.col = expr`.
+func (r *triggerResolver) assignedColumn(name *ast.ColumnName) (*table.Column, error) {
+	if name.Schema.L != "" || (name.Table.L != "new" && name.Table.L != "old") {
+		return nil, variable.UnknownSystemVar.GenByArgs(name.Name.O)
+	}
+	if name.Table.L == "old" {
+		return nil, ErrTrgCantChangeRow.GenByArgs("OLD", "")
+	}
+	if r.trigger.event == model.TriggerDelete {
+		return nil, ErrTrgNoSuchRow.GenByArgs("NEW", "on DELETE")
+	}
+	if r.trigger.timing == model.TriggerAfter {
+		return nil, ErrTrgCantChangeRow.GenByArgs("NEW", "after ")
+	}
+	col := table.FindCol(r.cols, name.Name.L)
+	if col == nil {
+		return nil, infoschema.ErrColumnNotExists.GenByArgs(name.Name.O, name.Table.O)
+	}
+	return col, nil
+}
+
+// writeTableCollector collects the IDs of the tables written by a DML statement.
+type writeTableCollector struct {
+	ids []int64
+}
+
+// Enter implements ast.Visitor interface.
+func (c *writeTableCollector) Enter(in ast.Node) (ast.Node, bool) {
+	switch x := in.(type) {
+	case *ast.SelectStmt, *ast.UnionStmt:
+		// Tables in subqueries are only read.
+		return in, true
+	case *ast.TableName:
+		if x.TableInfo != nil {
+			c.ids = append(c.ids, x.TableInfo.ID)
+		}
+	}
+	return in, false
+}
+
+// Leave implements ast.Visitor interface.
+func (c *writeTableCollector) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
+
+// triggerCache compiles the triggers of the tables written by a statement at the first time they fire.
+// The zero value is ready to use.
+type triggerCache struct {
+	tables map[int64][]*compiledTrigger
+}
+
+// fire runs the triggers of the table for a row event. oldRow is nil for INSERT and newRow is nil for DELETE.
+// BEFORE triggers may assign the columns of newRow, the offsets of the assigned columns are returned.
+func (c *triggerCache) fire(ctx context.Context, t table.Table, timing model.TriggerTiming, event model.TriggerEvent,
+	oldRow, newRow []types.Datum) ([]int, error) {
+	if len(t.Meta().Triggers) == 0 {
+		return nil, nil
+	}
+	is := GetInfoSchema(ctx)
+	triggers, err := c.get(ctx, is, t)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var assigned []int
+	for _, trigger := range triggers {
+		if trigger.timing != timing || trigger.event != event {
+			continue
+		}
+		offsets, err := trigger.run(ctx, is, t, oldRow, newRow)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		assigned = append(assigned, offsets...)
+	}
+	return assigned, nil
+}
+
+func (c *triggerCache) get(ctx context.Context, is infoschema.InfoSchema, t table.Table) ([]*compiledTrigger, error) {
+	tblInfo := t.Meta()
+	if triggers, ok := c.tables[tblInfo.ID]; ok {
+		return triggers, nil
+	}
+	schema, ok := schemaNameByTableID(is, tblInfo.ID)
+	if !ok {
+		return nil, errors.Trace(infoschema.ErrTableNotExists.GenByArgs("", tblInfo.Name))
+	}
+	triggers := make([]*compiledTrigger, 0, len(tblInfo.Triggers))
+	for _, info := range tblInfo.Triggers {
+		if info.State != model.StatePublic {
+			continue
+		}
+		trigger, err := compileTrigger(ctx, is, info, t, schema)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		triggers = append(triggers, trigger)
+	}
+	if c.tables == nil {
+		c.tables = make(map[int64][]*compiledTrigger)
+	}
+	c.tables[tblInfo.ID] = triggers
+	return triggers, nil
+}
+
+func schemaNameByTableID(is infoschema.InfoSchema, id int64) (model.CIStr, bool) {
+	db, ok := is.SchemaByTableID(id)
+	if !ok {
+		return model.CIStr{}, false
+	}
+	return db.Name, true
+}
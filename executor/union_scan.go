@@ -29,10 +29,67 @@ import (
 type dirtyDB struct {
 	// tables is a map whose key is tableID.
 	tables map[int64]*dirtyTable
+	// undoLog records how to revert the changes of the running statement, it's nil if the statement
+	// has no savepoint.
+	undoLog []dirtyUndo
+}
+
+// dirtyUndo is the state of a row or a table before it's changed by the statement.
+type dirtyUndo struct {
+	dt      *dirtyTable
+	handle  int64
+	row     []types.Datum
+	added   bool
+	deleted bool
+	// truncated is set if the table is truncated, addedRows is the rows before then.
+	truncated bool
+	addedRows map[int64][]types.Datum
+}
+
+func (u *dirtyUndo) revert() {
+	if u.addedRows != nil {
+		u.dt.addedRows, u.dt.truncated = u.addedRows, u.truncated
+		return
+	}
+	if u.added {
+		u.dt.addedRows[u.handle] = u.row
+	} else {
+		delete(u.dt.addedRows, u.handle)
+	}
+	if u.deleted {
+		u.dt.deletedRows[u.handle] = struct{}{}
+	} else {
+		delete(u.dt.deletedRows, u.handle)
+	}
+}
+
+func (udb *dirtyDB) logRow(dt *dirtyTable, handle int64) {
+	if udb.undoLog == nil {
+		return
+	}
+	row, added := dt.addedRows[handle]
+	_, deleted := dt.deletedRows[handle]
+	udb.undoLog = append(udb.undoLog, dirtyUndo{dt: dt, handle: handle, row: row, added: added, deleted: deleted})
+}
+
+// beginStmt starts logging the changes of the statement.
+func (udb *dirtyDB) beginStmt() {
+	udb.undoLog = make([]dirtyUndo, 0)
+}
+
+// endStmt stops logging the changes of the statement, and reverts them if rollback is true.
+func (udb *dirtyDB) endStmt(rollback bool) {
+	if rollback {
+		for i := len(udb.undoLog) - 1; i >= 0; i-- {
+			udb.undoLog[i].revert()
+		}
+	}
+	udb.undoLog = nil
 }
 
 func (udb *dirtyDB) addRow(tid, handle int64, row []types.Datum) {
 	dt := udb.getDirtyTable(tid)
+	udb.logRow(dt, handle)
 	for i := range row {
 		if row[i].Kind() == types.KindString {
 			row[i].SetBytes(row[i].GetBytes())
@@ -43,12 +100,16 @@ func (udb *dirtyDB) addRow(tid, handle int64, row []types.Datum) {
 
 func (udb *dirtyDB) deleteRow(tid int64, handle int64) {
 	dt := udb.getDirtyTable(tid)
+	udb.logRow(dt, handle)
 	delete(dt.addedRows, handle)
 	dt.deletedRows[handle] = struct{}{}
 }
 
 func (udb *dirtyDB) truncateTable(tid int64) {
 	dt := udb.getDirtyTable(tid)
+	if udb.undoLog != nil {
+		udb.undoLog = append(udb.undoLog, dirtyUndo{dt: dt, truncated: dt.truncated, addedRows: dt.addedRows})
+	}
 	dt.addedRows = make(map[int64][]types.Datum)
 	dt.truncated = true
 }
@@ -85,6 +146,19 @@ func getDirtyDB(ctx context.Context) *dirtyDB {
 	return udb
 }
 
+// BeginStmtDirtyDB starts logging the changes of the statement to the uncommitted rows of the transaction, so that
+// they can be reverted by EndStmtDirtyDB if the statement is rolled back.
+func BeginStmtDirtyDB(ctx context.Context) {
+	getDirtyDB(ctx).beginStmt()
+}
+
+// EndStmtDirtyDB stops logging the changes of the statement to the uncommitted rows, and reverts them if rollback is true.
+func EndStmtDirtyDB(ctx context.Context, rollback bool) {
+	if udb, ok := ctx.GetSessionVars().TxnCtx.DirtyDB.(*dirtyDB); ok {
+		udb.endStmt(rollback)
+	}
+}
+
 // UnionScanExec merges the rows from dirty table and the rows from XAPI request.
 type UnionScanExec struct {
 	baseExecutor
@@ -102,6 +176,15 @@ type UnionScanExec struct {
 	snapshotRow *Row
 }
 
+// Open implements the Executor Open interface.
+// The executor is opened again for every outer row if it is the inner plan of an apply,
+// the added rows are built once, so we only rewind them.
+func (us *UnionScanExec) Open() error {
+	us.cursor = 0
+	us.snapshotRow = nil
+	return errors.Trace(us.baseExecutor.Open())
+}
+
 // Next implements Execution Next interface.
 func (us *UnionScanExec) Next() (*Row, error) {
 	for {
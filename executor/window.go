@@ -0,0 +1,236 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var _ Executor = &WindowExec{}
+
+// WindowExec computes the window functions over the rows of its child, which are sorted by the PARTITION BY and
+// ORDER BY items. The rows of a partition are buffered until the whole partition is read, then the results of the
+// window functions are appended to the rows.
+// Without ORDER BY, the window of a row is the whole partition. Otherwise it's the rows from the start of the partition
+// to the last peer of the row, the peers are the rows with the same ORDER BY values.
+type WindowExec struct {
+	baseExecutor
+
+	sc          *variable.StatementContext
+	WindowFuncs []*expression.WindowFunc
+	PartitionBy []*plan.ByItems
+	OrderBy     []*plan.ByItems
+	// aggFuncs[i] computes WindowFuncs[i] if it's an aggregate function, otherwise it's nil.
+	aggFuncs []expression.AggregationFunction
+
+	// rows are the rows of the current partition, cursor is the position of the next returned row.
+	rows   []*Row
+	cursor int
+	// nextRow is the first row of the next partition, nextKey is its PARTITION BY values.
+	nextRow  *Row
+	nextKey  []types.Datum
+	executed bool
+}
+
+// Open implements the Executor Open interface.
+func (e *WindowExec) Open() error {
+	e.rows, e.cursor = nil, 0
+	e.nextRow, e.nextKey = nil, nil
+	e.executed = false
+	return errors.Trace(e.children[0].Open())
+}
+
+// Close implements the Executor Close interface.
+func (e *WindowExec) Close() error {
+	e.rows = nil
+	e.nextRow = nil
+	return errors.Trace(e.children[0].Close())
+}
+
+// Next implements the Executor Next interface.
+func (e *WindowExec) Next() (*Row, error) {
+	if e.cursor >= len(e.rows) {
+		if err := e.fetchPartition(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(e.rows) == 0 {
+			return nil, nil
+		}
+	}
+	row := e.rows[e.cursor]
+	e.rows[e.cursor] = nil
+	e.cursor++
+	return row, nil
+}
+
+// fetchPartition reads the rows of the next partition and computes the window functions over them.
+func (e *WindowExec) fetchPartition() error {
+	e.rows, e.cursor = e.rows[:0], 0
+	if e.nextRow == nil {
+		if e.executed {
+			return nil
+		}
+		row, err := e.children[0].Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			e.executed = true
+			return nil
+		}
+		e.nextRow = row
+		e.nextKey, err = evalByItems(e.PartitionBy, row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	partitionKey := e.nextKey
+	e.rows = append(e.rows, e.nextRow)
+	e.nextRow, e.nextKey = nil, nil
+	for {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			e.executed = true
+			break
+		}
+		key, err := evalByItems(e.PartitionBy, row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		cmp, err := compareDatumSlice(e.sc, key, partitionKey)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if cmp != 0 {
+			e.nextRow, e.nextKey = row, key
+			break
+		}
+		e.rows = append(e.rows, row)
+	}
+	return errors.Trace(e.computePartition())
+}
+
+// computePartition appends the results of the window functions to the rows of the current partition.
+func (e *WindowExec) computePartition() error {
+	// peerStarts[i] is the index of the first peer of the i-th row.
+	peerStarts := make([]int, len(e.rows))
+	if len(e.OrderBy) > 0 {
+		var prevKey []types.Datum
+		for i, row := range e.rows {
+			key, err := evalByItems(e.OrderBy, row)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			peerStarts[i] = i
+			if i > 0 {
+				cmp, err := compareDatumSlice(e.sc, key, prevKey)
+				if err != nil {
+					return errors.Trace(err)
+				}
+				if cmp == 0 {
+					peerStarts[i] = peerStarts[i-1]
+				}
+			}
+			prevKey = key
+		}
+	}
+	results := make([][]types.Datum, len(e.rows))
+	for i := range results {
+		results[i] = make([]types.Datum, len(e.WindowFuncs))
+	}
+	for j, fun := range e.WindowFuncs {
+		switch fun.Name {
+		case ast.WindowFuncRowNumber:
+			for i := range e.rows {
+				results[i][j].SetInt64(int64(i + 1))
+			}
+		case ast.WindowFuncRank:
+			for i := range e.rows {
+				results[i][j].SetInt64(int64(peerStarts[i] + 1))
+			}
+		default:
+			if err := e.computeAggregate(j, peerStarts, results); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	for i, row := range e.rows {
+		data := make([]types.Datum, 0, len(row.Data)+len(e.WindowFuncs))
+		data = append(data, row.Data...)
+		e.rows[i] = &Row{RowKeys: row.RowKeys, Data: append(data, results[i]...)}
+	}
+	return nil
+}
+
+// computeAggregate computes the j-th window function, which is an aggregate function. The rows are aggregated peer
+// group by peer group, and every row gets the result after its peer group is aggregated.
+func (e *WindowExec) computeAggregate(j int, peerStarts []int, results [][]types.Datum) error {
+	agg := e.aggFuncs[j]
+	agg.Reset()
+	for start := 0; start < len(e.rows); {
+		end := start + 1
+		for end < len(e.rows) && peerStarts[end] == start {
+			end++
+		}
+		for _, row := range e.rows[start:end] {
+			if err := agg.Update(row.Data, nil, e.sc); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		d := agg.GetGroupResult(nil)
+		if d.Kind() == types.KindMysqlDecimal {
+			// The sum is updated in place by the following rows, so we copy the decimal.
+			dec := *d.GetMysqlDecimal()
+			d.SetMysqlDecimal(&dec)
+		}
+		for i := start; i < end; i++ {
+			results[i][j] = d
+		}
+		start = end
+	}
+	return nil
+}
+
+func evalByItems(items []*plan.ByItems, row *Row) ([]types.Datum, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	vals := make([]types.Datum, 0, len(items))
+	for _, item := range items {
+		v, err := item.Expr.Eval(row.Data)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+func compareDatumSlice(sc *variable.StatementContext, a, b []types.Datum) (int, error) {
+	for i := range a {
+		cmp, err := a[i].CompareDatum(sc, b[i])
+		if err != nil || cmp != 0 {
+			return cmp, errors.Trace(err)
+		}
+	}
+	return 0, nil
+}
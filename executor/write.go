@@ -24,10 +24,13 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta/autoid"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/bulkload"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -39,7 +42,8 @@ var (
 	_ Executor = &LoadData{}
 )
 
-func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum, assignFlag []bool, t table.Table, onDuplicateUpdate bool) error {
+func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum, assignFlag []bool, t table.Table, onDuplicateUpdate bool,
+	triggers *triggerCache, fks *fkCache) error {
 	cols := t.WritableCols()
 	touched := make(map[int]bool, len(cols))
 	assignExists := false
@@ -53,17 +57,22 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 			continue
 		}
 		col := cols[i]
+		isAutoRandom := false
 		if col.IsPKHandleColumn(t.Meta()) {
 			newHandle = newData[i]
+			isAutoRandom = t.Meta().AutoRandomBits > 0
 		}
-		if mysql.HasAutoIncrementFlag(col.Flag) {
+		if mysql.HasAutoIncrementFlag(col.Flag) || isAutoRandom {
 			if newData[i].IsNull() {
 				return errors.Errorf("Column '%v' cannot be null", col.Name.O)
 			}
-			val, err := newData[i].ToInt64(sc)
+			val, err := getAutoIDValue(sc, newData[i], mysql.HasUnsignedFlag(col.Flag) && !isAutoRandom)
 			if err != nil {
 				return errors.Trace(err)
 			}
+			if isAutoRandom {
+				val = autoid.AutoRandomIncrement(val, t.Meta().AutoRandomBits)
+			}
 			t.RebaseAutoID(val, true)
 		}
 		casted, err := table.CastValue(ctx, newData[i], col.ToInfo())
@@ -80,9 +89,21 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 		return nil
 	}
 
-	if err := table.CheckNotNull(cols, newData); err != nil {
+	assigned, err := triggers.fire(ctx, t, model.TriggerBefore, model.TriggerUpdate, oldData, newData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, i := range assigned {
+		if cols[i].IsPKHandleColumn(t.Meta()) {
+			newHandle = newData[i]
+		}
+		touched[i] = true
+	}
+
+	if err = table.CheckNotNull(cols, newData); err != nil {
 		return errors.Trace(err)
 	}
+	sc.AddTouchedRows(1)
 
 	// If row is not changed, we should do nothing.
 	rowChanged := false
@@ -105,10 +126,10 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 		if ctx.GetSessionVars().ClientCapability&mysql.ClientFoundRows > 0 {
 			sc.AddAffectedRows(1)
 		}
-		return nil
+		_, err = triggers.fire(ctx, t, model.TriggerAfter, model.TriggerUpdate, oldData, newData)
+		return errors.Trace(err)
 	}
 
-	var err error
 	if !newHandle.IsNull() {
 		err = t.RemoveRecord(ctx, h, oldData)
 		if err != nil {
@@ -128,13 +149,18 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 	dirtyDB.addRow(tid, h, newData)
 
 	// Record affected rows.
+	sc.AddUpdatedRows(1)
 	if !onDuplicateUpdate {
 		sc.AddAffectedRows(1)
 	} else {
 		sc.AddAffectedRows(2)
 	}
 	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.Meta().ID, 0, 1)
-	return nil
+	if err = fks.cascade(ctx, t, oldData, newData); err != nil {
+		return errors.Trace(err)
+	}
+	_, err = triggers.fire(ctx, t, model.TriggerAfter, model.TriggerUpdate, oldData, newData)
+	return errors.Trace(err)
 }
 
 // DeleteExec represents a delete executor.
@@ -148,6 +174,8 @@ type DeleteExec struct {
 	IsMultiTable bool
 
 	finished bool
+	triggers triggerCache
+	fks      fkCache
 }
 
 // Schema implements the Executor Schema interface.
@@ -198,10 +226,13 @@ func (e *DeleteExec) deleteMultiTables() error {
 			if !isMatchTableName(entry, tblMap) {
 				continue
 			}
+			offset := getTableOffset(e.SelectExec.Schema(), entry)
+			if offset < 0 {
+				continue
+			}
 			if tblRowMap[entry.Tbl] == nil {
 				tblRowMap[entry.Tbl] = make(map[int64][]types.Datum)
 			}
-			offset := getTableOffset(e.SelectExec.Schema(), entry)
 			data := joinedRow.Data[offset : offset+len(entry.Tbl.WritableCols())]
 			tblRowMap[entry.Tbl][entry.Handle] = data
 		}
@@ -232,6 +263,9 @@ func isMatchTableName(entry *RowKeyEntry, tblMap map[int64][]string) bool {
 }
 
 func (e *DeleteExec) deleteSingleTable() error {
+	// Fetch all the rows before removing any of them like UpdateExec, so the subqueries
+	// which read the same table see the rows as of the start of the statement.
+	var rows []*Row
 	for {
 		row, err := e.SelectExec.Next()
 		if err != nil {
@@ -240,8 +274,11 @@ func (e *DeleteExec) deleteSingleTable() error {
 		if row == nil {
 			break
 		}
+		rows = append(rows, row)
+	}
+	for _, row := range rows {
 		rowKey := row.RowKeys[0]
-		err = e.removeRow(e.ctx, rowKey.Tbl, rowKey.Handle, row.Data)
+		err := e.removeRow(e.ctx, rowKey.Tbl, rowKey.Handle, row.Data)
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -250,14 +287,26 @@ func (e *DeleteExec) deleteSingleTable() error {
 }
 
 func (e *DeleteExec) removeRow(ctx context.Context, t table.Table, h int64, data []types.Datum) error {
-	err := t.RemoveRecord(ctx, h, data)
+	data, ok := e.fks.latestRow(ctx, t, h, data)
+	if !ok {
+		return nil
+	}
+	_, err := e.triggers.fire(ctx, t, model.TriggerBefore, model.TriggerDelete, data, nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = t.RemoveRecord(ctx, h, data)
 	if err != nil {
 		return errors.Trace(err)
 	}
 	getDirtyDB(ctx).deleteRow(t.Meta().ID, h)
 	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
 	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.Meta().ID, -1, 1)
-	return nil
+	if err = e.fks.cascade(ctx, t, data, nil); err != nil {
+		return errors.Trace(err)
+	}
+	_, err = e.triggers.fire(ctx, t, model.TriggerAfter, model.TriggerDelete, data, nil)
+	return errors.Trace(err)
 }
 
 // Close implements the Executor Close interface.
@@ -292,6 +341,8 @@ type LoadDataInfo struct {
 	LinesInfo  *ast.LinesClause
 	Ctx        context.Context
 	columns    []*table.Column
+	// loader is not nil if the data is bulk loaded.
+	loader *bulkload.Loader
 }
 
 // SetBatchCount sets the number of rows to insert in a batch.
@@ -501,26 +552,77 @@ func escapeChar(c byte) (byte, bool) {
 }
 
 func (e *LoadDataInfo) insertData(cols []string) {
-	for i := 0; i < len(e.row); i++ {
-		if i >= len(cols) {
-			e.row[i].SetString("")
-			continue
-		}
+	n := len(e.row)
+	if len(cols) < n {
+		// Like MySQL, the columns whose fields are missing are set to the default values.
+		n = len(cols)
+		warnLog := fmt.Sprintf("Load Data: row %v doesn't contain data for all columns", e.insertVal.currRow+1)
+		e.insertVal.handleLoadDataWarnings(ErrWarnTooFewRecords.GenByArgs(e.insertVal.currRow+1), warnLog)
+	}
+	for i := 0; i < n; i++ {
 		e.row[i].SetString(cols[i])
 	}
-	row, err := e.insertVal.fillRowData(e.columns, e.row, true)
+	e.insertVal.ctx.GetSessionVars().StmtCtx.AddRecordRows(1)
+	row, err := e.insertVal.fillRowData(e.columns[:n], e.row[:n], true)
 	if err != nil {
 		warnLog := fmt.Sprintf("Load Data: insert data:%v failed:%v", e.row, errors.ErrorStack(err))
 		e.insertVal.handleLoadDataWarnings(err, warnLog)
 		return
 	}
-	_, err = e.Table.AddRecord(e.insertVal.ctx, row)
+	if e.loader != nil {
+		// Bulk loaded rows bypass the transaction, so the triggers are not fired.
+		err = e.loader.AddRow(row)
+		if err == nil {
+			e.insertVal.ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
+		}
+	} else {
+		ctx := e.insertVal.ctx
+		_, err = e.insertVal.triggers.fire(ctx, e.Table, model.TriggerBefore, model.TriggerInsert, nil, row)
+		if err == nil {
+			_, err = e.Table.AddRecord(ctx, row)
+		}
+		if err == nil {
+			_, err = e.insertVal.triggers.fire(ctx, e.Table, model.TriggerAfter, model.TriggerInsert, nil, row)
+		}
+	}
 	if err != nil {
 		warnLog := fmt.Sprintf("Load Data: insert data:%v failed:%v", row, errors.ErrorStack(err))
 		e.insertVal.handleLoadDataWarnings(err, warnLog)
 	}
 }
 
+// SetMessage sets the info message of the OK packet after all the data is inserted. The rows failed to
+// insert are skipped with warnings, the rows are never deleted because LOAD DATA ... REPLACE isn't supported.
+func (e *LoadDataInfo) SetMessage() {
+	sc := e.insertVal.ctx.GetSessionVars().StmtCtx
+	records := sc.RecordRows()
+	skipped := records - sc.AffectedRows()
+	sc.SetMessage(fmt.Sprintf(mysql.MySQLErrName[mysql.ErrLoadInfo], records, 0, skipped, sc.WarningCount()))
+}
+
+// CommitBulkLoad writes the bulk loaded data to the storage, it does nothing if the data
+// isn't bulk loaded. The lock of the table is released whether it succeeds or not.
+func (e *LoadDataInfo) CommitBulkLoad() error {
+	if e.loader == nil {
+		return nil
+	}
+	loader := e.loader
+	e.loader = nil
+	if err := loader.Commit(); err != nil {
+		return errors.Trace(err)
+	}
+	e.Ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(e.Table.Meta().ID, loader.Rows(), loader.Rows())
+	return nil
+}
+
+// CloseBulkLoad discards the bulk loaded data which is not written and releases the lock of the table.
+func (e *LoadDataInfo) CloseBulkLoad() {
+	if e.loader != nil {
+		e.loader.Close()
+		e.loader = nil
+	}
+}
+
 func (e *InsertValues) handleLoadDataWarnings(err error, logInfo string) {
 	sc := e.ctx.GetSessionVars().StmtCtx
 	if variable.GoSQLDriverTest {
@@ -567,6 +669,13 @@ func (e *LoadData) Next() (*Row, error) {
 	if e.loadDataInfo.Path == "" {
 		return nil, errors.New("Load Data: infile path is empty")
 	}
+	if ctx.GetSessionVars().BulkLoad {
+		loader, err := bulkload.NewLoader(ctx, e.loadDataInfo.Table)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		e.loadDataInfo.loader = loader
+	}
 	ctx.SetValue(LoadDataVarKey, e.loadDataInfo)
 
 	return nil, nil
@@ -600,6 +709,11 @@ type InsertValues struct {
 	Lists     [][]expression.Expression
 	Setlist   []*expression.Assignment
 	IsPrepare bool
+	// DefaultExprs are the default value expressions of the columns indexed by the column offsets.
+	DefaultExprs map[int]expression.Expression
+
+	triggers triggerCache
+	fks      fkCache
 }
 
 // InsertExec represents an insert executor.
@@ -611,7 +725,9 @@ type InsertExec struct {
 	Priority int
 	Ignore   bool
 
-	finished bool
+	// batchedRows is the number of rows inserted in the current transaction of BatchInsert mode.
+	batchedRows int
+	finished    bool
 }
 
 // Schema implements the Executor Schema interface.
@@ -621,6 +737,7 @@ func (e *InsertExec) Schema() *expression.Schema {
 
 // BatchInsertSize is the batch size of auto-splitted insert data.
 // This will be used when tidb_batch_insert is set to ON.
+// It's also the number of rows fetched from the select executor of INSERT ... SELECT at a time.
 var BatchInsertSize = 20000
 
 // Next implements the Executor Next interface.
@@ -632,38 +749,62 @@ func (e *InsertExec) Next() (*Row, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	txn := e.ctx.Txn()
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
 	toUpdateColumns, err := getOnDuplicateUpdateColumns(e.OnDuplicate, e.Table)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	var rows [][]types.Datum
 	if e.SelectExec != nil {
-		rows, err = e.getRowsSelect(cols)
+		// The rows of select are inserted chunk by chunk, so they are never all in memory.
+		for {
+			rows, err := e.getRowsSelect(cols, BatchInsertSize)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if len(rows) == 0 {
+				break
+			}
+			if err = e.insertRows(rows, toUpdateColumns); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
 	} else {
-		rows, err = e.getRows(cols)
+		rows, err := e.getRows(cols)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err = e.insertRows(rows, toUpdateColumns); err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
-	if err != nil {
-		return nil, errors.Trace(err)
+
+	if e.lastInsertID != 0 {
+		e.ctx.GetSessionVars().SetLastInsertID(e.lastInsertID)
 	}
+	e.setMessage()
+	e.finished = true
+	return nil, nil
+}
 
+func (e *InsertExec) insertRows(rows [][]types.Datum, toUpdateColumns map[int]*expression.Assignment) error {
 	// If tidb_batch_insert is ON and not in a transaction, we could use BatchInsert mode.
 	batchInsert := e.ctx.GetSessionVars().BatchInsert && !e.ctx.GetSessionVars().InTxn()
-	rowCount := 0
-
+	txn := e.ctx.Txn()
+	sc := e.ctx.GetSessionVars().StmtCtx
+	sc.AddRecordRows(uint64(len(rows)))
 	for _, row := range rows {
-		if batchInsert && rowCount >= BatchInsertSize {
-			err = e.ctx.NewTxn()
+		if batchInsert && e.batchedRows >= BatchInsertSize {
+			err := e.ctx.NewTxn()
 			if err != nil {
 				// We should return a special error for batch insert.
-				return nil, ErrBatchInsertFail.Gen("BatchInsert failed with error: %v", err)
+				return ErrBatchInsertFail.Gen("BatchInsert failed with error: %v", err)
 			}
 			txn = e.ctx.Txn()
-			rowCount = 0
+			e.batchedRows = 0
+		}
+		_, err := e.triggers.fire(e.ctx, e.Table, model.TriggerBefore, model.TriggerInsert, nil, row)
+		if err != nil {
+			return errors.Trace(err)
 		}
 		if len(e.OnDuplicate) == 0 && !e.Ignore {
 			txn.SetOption(kv.PresumeKeyNotExists, nil)
@@ -672,7 +813,11 @@ func (e *InsertExec) Next() (*Row, error) {
 		txn.DelOption(kv.PresumeKeyNotExists)
 		if err == nil {
 			getDirtyDB(e.ctx).addRow(e.Table.Meta().ID, h, row)
-			rowCount++
+			e.batchedRows++
+			_, err = e.triggers.fire(e.ctx, e.Table, model.TriggerAfter, model.TriggerInsert, nil, row)
+			if err != nil {
+				return errors.Trace(err)
+			}
 			continue
 		}
 
@@ -681,23 +826,31 @@ func (e *InsertExec) Next() (*Row, error) {
 			// For example, without IGNORE, a row that duplicates an existing UNIQUE index or PRIMARY KEY value in
 			// the table causes a duplicate-key error and the statement is aborted. With IGNORE, the row is discarded and no error occurs.
 			if e.Ignore {
+				sc.AddDuplicatedRows(1)
 				continue
 			}
 			if len(e.OnDuplicate) > 0 {
 				if err = e.onDuplicateUpdate(row, h, toUpdateColumns); err != nil {
-					return nil, errors.Trace(err)
+					return errors.Trace(err)
 				}
 				continue
 			}
 		}
-		return nil, errors.Trace(err)
+		return errors.Trace(err)
 	}
+	return nil
+}
 
-	if e.lastInsertID != 0 {
-		e.ctx.GetSessionVars().SetLastInsertID(e.lastInsertID)
+// setMessage sets the info message of the OK packet. Like MySQL, there is no message if a single row is inserted
+// by VALUES or SET. The duplicates are the rows ignored for INSERT IGNORE, or the rows updated for ON DUPLICATE
+// KEY UPDATE.
+func (e *InsertValues) setMessage() {
+	if e.SelectExec == nil && len(e.Lists) <= 1 {
+		return
 	}
-	e.finished = true
-	return nil, nil
+	sc := e.ctx.GetSessionVars().StmtCtx
+	duplicates := sc.DuplicatedRows() + sc.UpdatedRows()
+	sc.SetMessage(fmt.Sprintf(mysql.MySQLErrName[mysql.ErrInsertInfo], sc.RecordRows(), duplicates, sc.WarningCount()))
 }
 
 // Close implements the Executor Close interface.
@@ -834,13 +987,15 @@ func (e *InsertValues) getRow(cols []*table.Column, list []expression.Expression
 	return e.fillRowData(cols, vals, false)
 }
 
-func (e *InsertValues) getRowsSelect(cols []*table.Column) ([][]types.Datum, error) {
+// getRowsSelect fetches at most maxRows rows from the select executor, it returns no rows
+// when the select executor is drained.
+func (e *InsertValues) getRowsSelect(cols []*table.Column, maxRows int) ([][]types.Datum, error) {
 	// process `insert|replace into ... select ... from ...`
 	if e.SelectExec.Schema().Len() != len(cols) {
 		return nil, errors.Errorf("Column count %d doesn't match value count %d", len(cols), e.SelectExec.Schema().Len())
 	}
 	var rows [][]types.Datum
-	for {
+	for len(rows) < maxRows {
 		innerRow, err := e.SelectExec.Next()
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -848,12 +1003,12 @@ func (e *InsertValues) getRowsSelect(cols []*table.Column) ([][]types.Datum, err
 		if innerRow == nil {
 			break
 		}
-		e.currRow = int64(len(rows))
 		row, err := e.fillRowData(cols, innerRow.Data, false)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
 		rows = append(rows, row)
+		e.currRow++
 	}
 	return rows, nil
 }
@@ -875,8 +1030,11 @@ func (e *InsertValues) fillRowData(cols []*table.Column, vals []types.Datum, ign
 	if err = table.CastValues(e.ctx, row, cols, ignoreErr); err != nil {
 		return nil, errors.Trace(err)
 	}
-	if err = table.CheckNotNull(e.Table.Cols(), row); err != nil {
-		return nil, errors.Trace(err)
+	sc := e.ctx.GetSessionVars().StmtCtx
+	for _, col := range e.Table.Cols() {
+		if err = col.HandleBadNull(&row[col.Offset], sc); err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
 	return row, nil
 }
@@ -897,27 +1055,36 @@ func (e *InsertValues) filterErr(err error, ignoreErr bool) error {
 func (e *InsertValues) initDefaultValues(row []types.Datum, marked map[int]struct{}, ignoreErr bool) error {
 	var defaultValueCols []*table.Column
 	sc := e.ctx.GetSessionVars().StmtCtx
+	autoRandomBits := e.Table.Meta().AutoRandomBits
 	for i, c := range e.Table.Cols() {
+		isAutoRandom := autoRandomBits > 0 && c.IsPKHandleColumn(e.Table.Meta())
+		isAutoID := mysql.HasAutoIncrementFlag(c.Flag) || isAutoRandom
+		isUnsigned := mysql.HasUnsignedFlag(c.Flag) && !isAutoRandom
 		// It's used for retry.
-		if mysql.HasAutoIncrementFlag(c.Flag) && row[i].IsNull() &&
+		if isAutoID && row[i].IsNull() &&
 			e.ctx.GetSessionVars().RetryInfo.Retrying {
 			id, err := e.ctx.GetSessionVars().RetryInfo.GetCurrAutoIncrementID()
 			if err != nil {
 				return errors.Trace(err)
 			}
-			row[i].SetInt64(id)
+			setAutoIDDatum(&row[i], id, isUnsigned)
 		}
 		if !row[i].IsNull() {
 			// Column value isn't nil and column isn't auto-increment, continue.
-			if !mysql.HasAutoIncrementFlag(c.Flag) {
+			if !isAutoID {
 				continue
 			}
-			val, err := row[i].ToInt64(sc)
+			val, err := getAutoIDValue(sc, row[i], isUnsigned)
 			if e.filterErr(errors.Trace(err), ignoreErr) != nil {
 				return errors.Trace(err)
 			}
-			row[i].SetInt64(val)
+			setAutoIDDatum(&row[i], val, isUnsigned)
 			if val != 0 {
+				if isAutoRandom {
+					// Only the incremental bits are allocated, so the allocated IDs never conflict with it.
+					e.Table.RebaseAutoID(autoid.AutoRandomIncrement(val, autoRandomBits), true)
+					continue
+				}
 				e.ctx.GetSessionVars().InsertID = uint64(val)
 				e.Table.RebaseAutoID(val, true)
 				continue
@@ -925,16 +1092,24 @@ func (e *InsertValues) initDefaultValues(row []types.Datum, marked map[int]struc
 		}
 
 		// If the nil value is evaluated in insert list, we will use nil except auto increment column.
-		if _, ok := marked[i]; ok && !mysql.HasAutoIncrementFlag(c.Flag) && !mysql.HasTimestampFlag(c.Flag) {
+		if _, ok := marked[i]; ok && !isAutoID && !mysql.HasTimestampFlag(c.Flag) {
 			continue
 		}
 
-		if mysql.HasAutoIncrementFlag(c.Flag) {
+		if isAutoID {
 			recordID, err := e.Table.AllocAutoID()
 			if err != nil {
 				return errors.Trace(err)
 			}
-			row[i].SetInt64(recordID)
+			if isAutoRandom {
+				recordID, err = autoid.EncodeAutoRandom(recordID, autoRandomBits, e.ctx.Txn().StartTS())
+				if err != nil {
+					return errors.Trace(err)
+				}
+			} else if err = checkAutoIDRange(c, recordID); err != nil {
+				return errors.Trace(err)
+			}
+			setAutoIDDatum(&row[i], recordID, isUnsigned)
 			// It's compatible with mysql. So it sets last insert id to the first row.
 			if e.currRow == 0 {
 				e.lastInsertID = uint64(recordID)
@@ -945,7 +1120,11 @@ func (e *InsertValues) initDefaultValues(row []types.Datum, marked map[int]struc
 			}
 		} else {
 			var err error
-			row[i], err = table.GetColDefaultValue(e.ctx, c.ToInfo())
+			if expr, ok := e.DefaultExprs[i]; ok {
+				row[i], err = expr.Eval(nil)
+			} else {
+				row[i], err = table.GetColDefaultValue(e.ctx, c.ToInfo())
+			}
 			if e.filterErr(err, ignoreErr) != nil {
 				return errors.Trace(err)
 			}
@@ -960,6 +1139,41 @@ func (e *InsertValues) initDefaultValues(row []types.Datum, marked map[int]struc
 	return nil
 }
 
+// getAutoIDValue gets the value of the auto ID column, the IDs of an unsigned column are kept in int64
+// in two's complement, the same as the allocator does.
+func getAutoIDValue(sc *variable.StatementContext, d types.Datum, isUnsigned bool) (int64, error) {
+	if !isUnsigned {
+		val, err := d.ToInt64(sc)
+		return val, errors.Trace(err)
+	}
+	ft := types.NewFieldType(mysql.TypeLonglong)
+	ft.Flag |= mysql.UnsignedFlag
+	casted, err := d.ConvertTo(sc, ft)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return int64(casted.GetUint64()), nil
+}
+
+func setAutoIDDatum(d *types.Datum, id int64, isUnsigned bool) {
+	if isUnsigned {
+		d.SetUint64(uint64(id))
+	} else {
+		d.SetInt64(id)
+	}
+}
+
+// checkAutoIDRange checks whether the allocated ID fits in the column, so the exhausted IDs are reported
+// instead of being truncated to the max value of the column.
+func checkAutoIDRange(col *table.Column, id int64) error {
+	upperBound := types.IntegerUpperBound(col.Tp, mysql.HasUnsignedFlag(col.Flag))
+	if upperBound > 0 && uint64(id) > upperBound {
+		return autoid.ErrAutoincReadFailed.Gen("%s, the auto ID %d exceeds the range of column %s",
+			mysql.MySQLErrName[mysql.ErrAutoincReadFailed], uint64(id), col.Name)
+	}
+	return nil
+}
+
 // onDuplicateUpdate updates the duplicate row.
 // TODO: Report rows affected and last insert id.
 func (e *InsertExec) onDuplicateUpdate(row []types.Datum, h int64, cols map[int]*expression.Assignment) error {
@@ -993,7 +1207,7 @@ func (e *InsertExec) onDuplicateUpdate(row []types.Datum, h int64, cols map[int]
 			assignFlag[i] = false
 		}
 	}
-	if err = updateRecord(e.ctx, h, data, newData, assignFlag, e.Table, true); err != nil {
+	if err = updateRecord(e.ctx, h, data, newData, assignFlag, e.Table, true, &e.triggers, &e.fks); err != nil {
 		return errors.Trace(err)
 	}
 	return nil
@@ -1063,16 +1277,39 @@ func (e *ReplaceExec) Next() (*Row, error) {
 		return nil, errors.Trace(err)
 	}
 
-	var rows [][]types.Datum
 	if e.SelectExec != nil {
-		rows, err = e.getRowsSelect(cols)
+		// The rows of select are replaced chunk by chunk, so they are never all in memory.
+		for {
+			rows, err := e.getRowsSelect(cols, BatchInsertSize)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if len(rows) == 0 {
+				break
+			}
+			if err = e.replaceRows(rows); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
 	} else {
-		rows, err = e.getRows(cols)
+		rows, err := e.getRows(cols)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err = e.replaceRows(rows); err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
-	if err != nil {
-		return nil, errors.Trace(err)
+
+	if e.lastInsertID != 0 {
+		e.ctx.GetSessionVars().SetLastInsertID(e.lastInsertID)
 	}
+	e.setMessage()
+	e.finished = true
+	return nil, nil
+}
 
+func (e *ReplaceExec) replaceRows(rows [][]types.Datum) error {
 	/*
 	 * MySQL uses the following algorithm for REPLACE (and LOAD DATA ... REPLACE):
 	 *  1. Try to insert the new row into the table
@@ -1088,27 +1325,42 @@ func (e *ReplaceExec) Next() (*Row, error) {
 	idx := 0
 	rowsLen := len(rows)
 	sc := e.ctx.GetSessionVars().StmtCtx
+	sc.AddRecordRows(uint64(rowsLen))
+	// firedIdx is the index of the last row which has fired the BEFORE INSERT triggers,
+	// the triggers are fired once for a row even if the row is tried to insert for many times.
+	firedIdx := -1
 	for {
 		if idx >= rowsLen {
 			break
 		}
 		row := rows[idx]
+		if firedIdx != idx {
+			_, err := e.triggers.fire(e.ctx, e.Table, model.TriggerBefore, model.TriggerInsert, nil, row)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			firedIdx = idx
+		}
 		h, err1 := e.Table.AddRecord(e.ctx, row)
 		if err1 == nil {
 			getDirtyDB(e.ctx).addRow(e.Table.Meta().ID, h, row)
 			idx++
+			_, err := e.triggers.fire(e.ctx, e.Table, model.TriggerAfter, model.TriggerInsert, nil, row)
+			if err != nil {
+				return errors.Trace(err)
+			}
 			continue
 		}
 		if err1 != nil && !terror.ErrorEqual(err1, kv.ErrKeyExists) {
-			return nil, errors.Trace(err1)
+			return errors.Trace(err1)
 		}
 		oldRow, err1 := e.Table.Row(e.ctx, h)
 		if err1 != nil {
-			return nil, errors.Trace(err1)
+			return errors.Trace(err1)
 		}
 		rowUnchanged, err1 := types.EqualDatums(sc, oldRow, row)
 		if err1 != nil {
-			return nil, errors.Trace(err1)
+			return errors.Trace(err1)
 		}
 		if rowUnchanged {
 			// If row unchanged, we do not need to do insert.
@@ -1117,19 +1369,27 @@ func (e *ReplaceExec) Next() (*Row, error) {
 			continue
 		}
 		// Remove current row and try replace again.
+		_, err1 = e.triggers.fire(e.ctx, e.Table, model.TriggerBefore, model.TriggerDelete, oldRow, nil)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
 		err1 = e.Table.RemoveRecord(e.ctx, h, oldRow)
 		if err1 != nil {
-			return nil, errors.Trace(err1)
+			return errors.Trace(err1)
 		}
 		getDirtyDB(e.ctx).deleteRow(e.Table.Meta().ID, h)
-		e.ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
+		sc.AddAffectedRows(1)
+		sc.AddDuplicatedRows(1)
+		if err1 = e.fks.cascade(e.ctx, e.Table, oldRow, nil); err1 != nil {
+			return errors.Trace(err1)
+		}
+		_, err1 = e.triggers.fire(e.ctx, e.Table, model.TriggerAfter, model.TriggerDelete, oldRow, nil)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
 	}
 
-	if e.lastInsertID != 0 {
-		e.ctx.GetSessionVars().SetLastInsertID(e.lastInsertID)
-	}
-	e.finished = true
-	return nil, nil
+	return nil
 }
 
 // UpdateExec represents a new update executor.
@@ -1146,6 +1406,8 @@ type UpdateExec struct {
 	newRowsData [][]types.Datum // The new values to be set.
 	fetched     bool
 	cursor      int
+	triggers    triggerCache
+	fks         fkCache
 }
 
 // Next implements the Executor Next interface.
@@ -1163,6 +1425,8 @@ func (e *UpdateExec) Next() (*Row, error) {
 		return nil, errors.Trace(err)
 	}
 	if e.cursor >= len(e.rows) {
+		sc := e.ctx.GetSessionVars().StmtCtx
+		sc.SetMessage(fmt.Sprintf(mysql.MySQLErrName[mysql.ErrUpdateInfo], sc.TouchedRows(), sc.UpdatedRows(), sc.WarningCount()))
 		return nil, nil
 	}
 	if e.updatedRowKeys == nil {
@@ -1177,6 +1441,10 @@ func (e *UpdateExec) Next() (*Row, error) {
 		}
 		offset := getTableOffset(e.SelectExec.Schema(), entry)
 		end := offset + len(tbl.WritableCols())
+		if offset < 0 || end > len(e.OrderedList) {
+			// The row key comes from a table in a correlated subquery, which is not updated.
+			continue
+		}
 		handle := entry.Handle
 		oldData := row.Data[offset:end]
 		newTableData := newData[offset:end]
@@ -1187,7 +1455,7 @@ func (e *UpdateExec) Next() (*Row, error) {
 			continue
 		}
 		// Update row
-		err1 := updateRecord(e.ctx, handle, oldData, newTableData, flags, tbl, false)
+		err1 := updateRecord(e.ctx, handle, oldData, newTableData, flags, tbl, false, &e.triggers, &e.fks)
 		if err1 != nil {
 			return nil, errors.Trace(err1)
 		}
@@ -1238,6 +1506,8 @@ func (e *UpdateExec) fetchRows() error {
 	}
 }
 
+// getTableOffset returns the offset of the first column of the table of entry in schema,
+// it returns -1 if the table is not in schema.
 func getTableOffset(schema *expression.Schema, entry *RowKeyEntry) int {
 	for i := 0; i < schema.Len(); i++ {
 		s := schema.Columns[i]
@@ -1245,7 +1515,7 @@ func getTableOffset(schema *expression.Schema, entry *RowKeyEntry) int {
 			return i
 		}
 	}
-	return 0
+	return -1
 }
 
 // Close implements the Executor Close interface.
@@ -16,18 +16,25 @@ package executor_test
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"sync/atomic"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/kvcache"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -118,10 +125,21 @@ func (s *testSuite) TestInsert(c *C) {
 	// for on duplicate key
 	insertSQL = `INSERT INTO insert_test (id, c3) VALUES (1, 2) ON DUPLICATE KEY UPDATE c3=values(c3)+c3+3;`
 	tk.MustExec(insertSQL)
+	tk.CheckLastMessage("")
 	r = tk.MustQuery("select * from insert_test where id = 1;")
 	rowStr = fmt.Sprintf("%v %v %v %v", "1", "1", "10", "6")
 	r.Check(testkit.Rows(rowStr))
 
+	// The info message of inserting multiple rows.
+	tk.MustExec("create table insert_info (id int primary key, c int)")
+	tk.MustExec("insert into insert_info values (1, 1), (2, 2)")
+	tk.CheckLastMessage("Records: 2  Duplicates: 0  Warnings: 0")
+	tk.MustExec("insert into insert_info values (1, 1), (2, 3), (3, 3) on duplicate key update c = values(c)")
+	tk.CheckExecResult(3, 0)
+	tk.CheckLastMessage("Records: 3  Duplicates: 1  Warnings: 0")
+	tk.MustExec("insert into insert_info select id + 10, c from insert_info")
+	tk.CheckLastMessage("Records: 3  Duplicates: 0  Warnings: 0")
+
 	tk.MustExec("create table insert_err (id int, c1 varchar(8))")
 	_, err = tk.Exec("insert insert_err values (1, 'abcdabcdabcd')")
 	c.Assert(types.ErrDataTooLong.Equal(err), IsTrue)
@@ -139,6 +157,27 @@ func (s *testSuite) TestInsert(c *C) {
 	r.Check(testkit.Rows("2"))
 }
 
+func (s *testSuite) TestInsertCharset(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a varchar(10) charset latin1, b varchar(10) charset gbk)")
+	tk.MustExec("insert t values ('café', '中文')")
+	tk.MustQuery("select a, b, convert(b using latin1) from t").Check(testkit.Rows("café 中文 ??"))
+
+	tk.MustExec("set sql_mode = 'STRICT_TRANS_TABLES'")
+	_, err := tk.Exec("insert t values ('中文', 'a')")
+	c.Assert(err, NotNil)
+	tk.MustExec("set sql_mode = ''")
+	tk.MustExec("insert t values ('中文', 'b')")
+	tk.MustQuery("select a from t where b = 'b'").Check(testkit.Rows("??"))
+	tk.MustExec("drop table t")
+}
+
 func (s *testSuite) TestInsertAutoInc(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -226,6 +265,110 @@ func (s *testSuite) TestInsertAutoInc(c *C) {
 	r.Check(testkit.Rows(rowStr3, rowStr1, rowStr2, rowStr4, rowStr5, rowStr6))
 }
 
+func (s *testSuite) TestAutoRandom(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	invalidSQLs := []string{
+		"create table t (id int primary key auto_random)",
+		"create table t (id bigint auto_random, a int)",
+		"create table t (id bigint auto_random, a int, primary key (id, a))",
+		"create table t (id bigint primary key auto_random auto_increment)",
+		"create table t (id bigint primary key auto_random default 1)",
+		"create table t (id bigint primary key auto_random(0))",
+		"create table t (id bigint primary key auto_random(16))",
+	}
+	for _, sql := range invalidSQLs {
+		_, err := tk.Exec(sql)
+		c.Assert(err, NotNil, Commentf("sql: %s", sql))
+	}
+
+	tk.MustExec("create table t (id bigint primary key auto_random(3), a int)")
+	tk.MustExec("insert t (a) values (1), (2)")
+	tk.MustExec("insert t values (null, 3), (0, 4)")
+	// The allocated IDs are in the low 60 bits, the shard bits are in the high bits except the sign bit.
+	tk.MustQuery("select a, id & ((1 << 60) - 1) from t order by a").Check(testkit.Rows("1 1", "2 2", "3 3", "4 4"))
+	tk.MustQuery("select count(*) from t where id > 0").Check(testkit.Rows("4"))
+	tk.MustQuery("select count(distinct id >> 60) from t where a < 3").Check(testkit.Rows("1"))
+
+	// The explicit values are kept, the allocator is rebased by their incremental bits.
+	tk.MustExec("insert t values ((1 << 60) + 100, 5)")
+	tk.MustExec("insert t (a) values (6)")
+	tk.MustQuery("select id, a from t where a = 5").Check(testkit.Rows("1152921504606847076 5"))
+	tk.MustQuery("select id & ((1 << 60) - 1) from t where a = 6").Check(testkit.Rows("101"))
+	tk.MustExec("update t set id = 200 where a = 6")
+	tk.MustExec("insert t (a) values (7)")
+	tk.MustQuery("select id & ((1 << 60) - 1) from t where a = 7").Check(testkit.Rows("201"))
+
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` bigint(21) NOT NULL AUTO_RANDOM(3),\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	_, err := tk.Exec("alter table t add column b bigint auto_random")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("alter table t modify column id int")
+	c.Assert(err, NotNil)
+	tk.MustExec("drop table t")
+}
+
+func (s *testSuite) TestAutoIDCache(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1, t2")
+	for _, sql := range []string{
+		"create table t (id int primary key auto_increment) auto_id_cache 0",
+		"create table t (id int primary key auto_increment) auto_id_cache 1073741825",
+	} {
+		_, err := tk.Exec(sql)
+		c.Assert(err, NotNil, Commentf("sql: %s", sql))
+	}
+
+	// Only one ID is cached at a time, so no ID is skipped by the other servers.
+	tk.MustExec("create table t (id int primary key auto_increment, a int) auto_id_cache 1")
+	tk.MustExec("insert t (a) values (1), (2)")
+	tk.MustQuery("select id, a from t").Check(testkit.Rows("1 1", "2 2"))
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` int(11) NOT NULL AUTO_INCREMENT,\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin AUTO_INCREMENT=3 AUTO_ID_CACHE=1"))
+	tk.MustExec("create table t1 like t")
+	tk.MustExec("insert t1 (a) values (1)")
+	tk.MustQuery("show create table t1").Check(testkit.Rows("t1 CREATE TABLE `t1` (\n" +
+		"  `id` int(11) NOT NULL AUTO_INCREMENT,\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin AUTO_INCREMENT=2 AUTO_ID_CACHE=1"))
+
+	// The IDs are exhausted when they exceed the range of the column.
+	tk.MustExec("drop table t, t1")
+	tk.MustExec("create table t (id tinyint primary key auto_increment)")
+	tk.MustExec("insert t values (126), (null)")
+	_, err := tk.Exec("insert t values (null)")
+	c.Assert(terror.ErrorEqual(err, autoid.ErrAutoincReadFailed), IsTrue, Commentf("err %v", err))
+	tk.MustQuery("select id from t").Check(testkit.Rows("126", "127"))
+
+	// The unsigned BIGINT IDs exceed MaxInt64.
+	tk.MustExec("create table t2 (id bigint unsigned primary key auto_increment)")
+	tk.MustExec("insert t2 values (9223372036854775808), (null)")
+	tk.MustExec("insert t2 values (18446744073709551614)")
+	tk.MustExec("insert t2 values (null)")
+	tk.MustQuery("select id from t2").Check(testkit.Rows("9223372036854775808", "9223372036854775809",
+		"18446744073709551614", "18446744073709551615"))
+	_, err = tk.Exec("insert t2 values (null)")
+	c.Assert(terror.ErrorEqual(err, autoid.ErrAutoincReadFailed), IsTrue, Commentf("err %v", err))
+	tk.MustExec("drop table t, t2")
+}
+
 func (s *testSuite) TestInsertIgnore(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -245,6 +388,8 @@ func (s *testSuite) TestInsertIgnore(c *C) {
 	r.Check(testkit.Rows(rowStr))
 
 	tk.MustExec("insert ignore into t values (1, 3), (2, 3)")
+	c.Assert(tk.Se.AffectedRows(), Equals, uint64(1))
+	tk.CheckLastMessage("Records: 2  Duplicates: 1  Warnings: 0")
 
 	r = tk.MustQuery("select * from t;")
 	rowStr = fmt.Sprintf("%v %v", "1", "2")
@@ -257,6 +402,108 @@ func (s *testSuite) TestInsertIgnore(c *C) {
 	cfg.SetGetError(nil)
 }
 
+func (s *testSuite) TestInsertExprDefault(c *C) {
+	defer func() {
+		executor.SetPreparedPlanCache(0, 0, kvcache.PolicyLRU)
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(36) default (uuid()), c int default (abs(-2) + 1), d int default (1))")
+	// The default value expression is evaluated for every inserted row.
+	tk.MustExec("insert t (a) values (1), (2)")
+	tk.MustExec("insert t values (3, default, default, default)")
+	tk.MustExec("insert t set a = 4")
+	tk.MustExec("replace t (a, c) values (5, 5)")
+	tk.MustQuery("select a, c, d from t").Check(testkit.Rows("1 3 1", "2 3 1", "3 3 1", "4 3 1", "5 5 1"))
+	tk.MustQuery("select count(distinct b) from t").Check(testkit.Rows("5"))
+
+	// The default value isn't cached in the plan.
+	executor.SetPreparedPlanCache(2, 0, kvcache.PolicyLRU)
+	tk.MustExec("prepare stmt from 'insert t (a) values (?)'")
+	tk.MustExec("set @a = 6")
+	tk.MustExec("execute stmt using @a")
+	tk.MustExec("execute stmt using @a")
+	tk.MustQuery("select count(distinct b), count(*) from t where a = 6").Check(testkit.Rows("2 2"))
+
+	tk.MustExec("alter table t alter column d set default (length('abc'))")
+	tk.MustExec("alter table t modify column c bigint default (2 * 2)")
+	tk.MustExec("insert t (a) values (7)")
+	tk.MustQuery("select c, d from t where a = 7").Check(testkit.Rows("4 3"))
+	tk.MustQuery("show columns from t where field = 'd'").Check(testkit.Rows("d int(11) YES  length('abc') DEFAULT_GENERATED"))
+	tk.MustExec("alter table t alter column d set default 5")
+	tk.MustExec("insert t (a) values (8)")
+	tk.MustQuery("select d from t where a = 8").Check(testkit.Rows("5"))
+
+	// The default value expression can't refer to the columns, the variables or the subqueries.
+	_, err := tk.Exec("create table t1 (a int, b int default (a + 1))")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("create table t1 (a int default (@a))")
+	c.Assert(err, ErrorMatches, ".*Invalid default value for 'a'")
+	_, err = tk.Exec("create table t1 (a int default ((select 1)))")
+	c.Assert(err, ErrorMatches, ".*Invalid default value for 'a'")
+	_, err = tk.Exec("alter table t alter column d set default (a)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("create table t1 (a int default (no_such_func()))")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestInsertImplicitDefault(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int not null, b int default 5, c varchar(10) not null, d int)")
+
+	// The missing values of the NOT NULL columns without default values are the zero values in non-strict mode.
+	tk.MustExec("set @@sql_mode = ''")
+	tk.MustExec("insert t (d) values (1)")
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|",
+		"Warning|1364|Field 'a' doesn't have a default value", "Warning|1364|Field 'c' doesn't have a default value"))
+	tk.MustExec("insert t set a = default, c = 'x', d = 2")
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|", "Warning|1364|Field 'a' doesn't have a default value"))
+	tk.MustExec("insert t set a = 1, b = default(b) + 1, c = default(c), d = 3")
+	tk.MustExec("insert t values (2, default(b) * 2, 'y', 4)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("0 5  1", "0 5 x 2", "1 6  3", "2 10 y 4"))
+
+	// A NULL is an error for the single-row inserts, and it's replaced by the zero value for the multi-row inserts.
+	_, err := tk.Exec("insert t (a, c) values (null, 'x')")
+	c.Assert(err, ErrorMatches, ".*Column a can't be null.")
+	tk.MustExec("insert t (a, c, d) values (null, 'x', 5), (1, 'y', 6)")
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|", "Warning|1048|Column a can't be null."))
+	tk.MustExec("insert t (a, c, d) select null, 'z', 7")
+	tk.MustQuery("select a, c from t where d >= 5").Check(testkit.Rows("0 x", "1 y", "0 z"))
+
+	// They're errors in strict mode unless IGNORE is given.
+	tk.MustExec("set @@sql_mode = 'strict_trans_tables'")
+	tk.MustExec("delete from t")
+	_, err = tk.Exec("insert t (d) values (1)")
+	c.Assert(err, ErrorMatches, ".*Field 'a' doesn't have a default value")
+	_, err = tk.Exec("insert t set a = default, c = 'x'")
+	c.Assert(err, ErrorMatches, ".*Field 'a' doesn't have a default value")
+	_, err = tk.Exec("insert t (a, c) values (1, 'x'), (null, 'y')")
+	c.Assert(err, ErrorMatches, ".*Column a can't be null.")
+	_, err = tk.Exec("insert t (a, c) select null, 'z'")
+	c.Assert(err, ErrorMatches, ".*Column a can't be null.")
+	tk.MustExec("insert ignore t (d) values (1)")
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|",
+		"Warning|1364|Field 'a' doesn't have a default value", "Warning|1364|Field 'c' doesn't have a default value"))
+	tk.MustExec("insert ignore t (a, c, d) values (null, 'x', 2)")
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|", "Warning|1048|Column a can't be null."))
+	tk.MustQuery("select * from t").Check(testkit.Rows("0 5  1", "0 5 x 2"))
+
+	// DEFAULT(col) is only supported in the inserted values.
+	_, err = tk.Exec("update t set b = default(b)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("insert t (a, c) values (default(e), 'x')")
+	c.Assert(err, ErrorMatches, ".*Unknown column 'e' in 'field_list'")
+}
+
 func (s *testSuite) TestReplace(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -269,6 +516,10 @@ func (s *testSuite) TestReplace(c *C) {
 	tk.MustExec(testSQL)
 	testSQL = `replace replace_test (c1) values (1),(2),(NULL);`
 	tk.MustExec(testSQL)
+	tk.CheckLastMessage("Records: 3  Duplicates: 0  Warnings: 0")
+	tk.MustExec("replace replace_test (id, c1) values (1, 1), (2, 3), (4, 4)")
+	c.Assert(tk.Se.AffectedRows(), Equals, uint64(4))
+	tk.CheckLastMessage("Records: 3  Duplicates: 1  Warnings: 0")
 
 	errReplaceSQL := `replace replace_test (c1) values ();`
 	tk.MustExec("begin")
@@ -394,6 +645,7 @@ func (s *testSuite) TestUpdate(c *C) {
 	updateStr := `UPDATE update_test SET name = "abc" where id > 0;`
 	tk.MustExec(updateStr)
 	tk.CheckExecResult(2, 0)
+	tk.CheckLastMessage("Rows matched: 2  Changed: 2  Warnings: 0")
 
 	// select data
 	tk.MustExec("begin")
@@ -403,6 +655,10 @@ func (s *testSuite) TestUpdate(c *C) {
 
 	tk.MustExec(`UPDATE update_test SET name = "foo"`)
 	tk.CheckExecResult(2, 0)
+	tk.CheckLastMessage("Rows matched: 2  Changed: 2  Warnings: 0")
+	tk.MustExec(`UPDATE update_test SET name = "foo" where id = 1`)
+	tk.CheckExecResult(0, 0)
+	tk.CheckLastMessage("Rows matched: 1  Changed: 0  Warnings: 0")
 
 	// table option is auto-increment
 	tk.MustExec("begin")
@@ -543,6 +799,154 @@ func (s *testSuite) TestDelete(c *C) {
 	tk.CheckExecResult(1, 0)
 }
 
+func (s *testSuite) TestPointUpdateDelete(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id bigint unsigned primary key, v int, unique key (v))")
+	tk.MustExec("insert t values (1, 1), (2, 2), (3, 3), (18446744073709551615, 4)")
+
+	tk.MustExec("update t set v = 10 where id = 1")
+	tk.CheckExecResult(1, 0)
+	tk.MustExec("update t x set x.v = x.v + 10 where x.id in (2, 3, 2, 5)")
+	tk.CheckExecResult(2, 0)
+	tk.MustExec("update t set v = 14 where id = '18446744073709551615'")
+	tk.CheckExecResult(1, 0)
+	tk.MustQuery("select * from t order by v").Check(testkit.Rows("1 10", "2 12", "3 13", "18446744073709551615 14"))
+	_, err := tk.Exec("update t set v = 12 where id = 1")
+	c.Assert(err, NotNil)
+
+	// The rows written in the transaction are visible.
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (5, 5)")
+	tk.MustExec("delete from t where id in (1, 5, 6)")
+	tk.CheckExecResult(2, 0)
+	tk.MustExec("commit")
+	tk.MustQuery("select * from t order by v").Check(testkit.Rows("2 12", "3 13", "18446744073709551615 14"))
+
+	tk.MustExec("delete from t where id = 1")
+	tk.CheckExecResult(0, 0)
+	tk.MustExec("delete from t where 3 = id")
+	tk.CheckExecResult(1, 0)
+	tk.MustQuery("select * from t order by v").Check(testkit.Rows("2 12", "18446744073709551615 14"))
+}
+
+func (s *testSuite) TestWriteWithSubqueryOnSameTable(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	reset := func() {
+		tk.MustExec("drop table if exists t")
+		tk.MustExec("create table t (id int primary key, a int)")
+		tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3), (4, 4)")
+	}
+
+	// The subqueries read the rows as of the start of the statement.
+	reset()
+	tk.MustExec("update t set a = a + 10 where id in (select id from t where a > 2)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1", "2 2", "3 13", "4 14"))
+	reset()
+	tk.MustExec("update t set a = a + 10 where a < (select max(a) from t t2 where t2.id > t.id)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 11", "2 12", "3 13", "4 4"))
+	reset()
+	tk.MustExec("update t set a = a + 10 where a < (select max(a) from t t2 where t2.id > t.id) order by id desc limit 2")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1", "2 12", "3 13", "4 4"))
+	reset()
+	tk.MustExec("update t set a = a + 10 where a < (select a from t t2 where t2.id = t.id + 1)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 11", "2 12", "3 13", "4 4"))
+	reset()
+	tk.MustExec("update t set a = (select t2.a from t t2 where t2.id = t.id + 1)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 2", "2 3", "3 4", "4 <nil>"))
+	reset()
+	tk.MustExec("update t t1 set a = (select count(*) from t t2 where t2.a > t1.a)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 3", "2 2", "3 1", "4 0"))
+	reset()
+	tk.MustExec("delete from t where a < (select max(a) from t t2 where t2.id > t.id)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("4 4"))
+	reset()
+	tk.MustExec("delete from t where exists (select 1 from t t2 where t2.id = t.id - 1)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1"))
+
+	// The subqueries see the uncommitted rows of the transaction, but not the rows written by the statement.
+	reset()
+	tk.MustExec("begin")
+	tk.MustExec("insert into t values (5, 5)")
+	tk.MustExec("update t t1 set a = (select count(*) from t t2 where t2.a > t1.a)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 4", "2 3", "3 2", "4 1", "5 0"))
+	tk.MustExec("rollback")
+	tk.MustExec("begin")
+	tk.MustExec("insert into t values (5, 5)")
+	tk.MustExec("delete from t where a > (select min(a) from t t2 where t2.id < t.id)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1"))
+	tk.MustExec("commit")
+	tk.MustExec("drop table t")
+}
+
+func (s *testSuite) TestTrigger(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists trg, trg_log")
+	tk.MustExec("create table trg (a int primary key, b int)")
+	tk.MustExec("create table trg_log (op varchar(10), a int, b int)")
+
+	tk.MustExec("create trigger trg_bi before insert on trg for each row set new.b = new.a * 2")
+	tk.MustExec("create trigger trg_ai after insert on trg for each row insert into trg_log values ('insert', new.a, new.b)")
+	tk.MustExec("create trigger trg_au after update on trg for each row insert into trg_log values ('update', old.b, new.b)")
+	tk.MustExec("create trigger trg_ad after delete on trg for each row insert into trg_log values ('delete', old.a, old.b)")
+
+	tk.MustExec("insert into trg (a) values (1), (2)")
+	tk.MustQuery("select * from trg").Check(testkit.Rows("1 2", "2 4"))
+	tk.MustExec("update trg set b = 10 where a = 1")
+	tk.MustExec("delete from trg where a = 2")
+	tk.MustQuery("select * from trg").Check(testkit.Rows("1 10"))
+	tk.MustExec("replace into trg values (1, 0)")
+	tk.MustQuery("select * from trg").Check(testkit.Rows("1 2"))
+	tk.MustQuery("select * from trg_log").Check(testkit.Rows(
+		"insert 1 2", "insert 2 4", "update 2 10", "delete 2 4", "delete 1 10", "insert 1 2"))
+
+	tk.MustQuery("show triggers").Check(testkit.Rows(
+		"trg_bi INSERT trg set new.b = new.a * 2 BEFORE <nil>   utf8 utf8_general_ci utf8_general_ci",
+		"trg_ai INSERT trg insert into trg_log values ('insert', new.a, new.b) AFTER <nil>   utf8 utf8_general_ci utf8_general_ci",
+		"trg_au UPDATE trg insert into trg_log values ('update', old.b, new.b) AFTER <nil>   utf8 utf8_general_ci utf8_general_ci",
+		"trg_ad DELETE trg insert into trg_log values ('delete', old.a, old.b) AFTER <nil>   utf8 utf8_general_ci utf8_general_ci"))
+
+	_, err := tk.Exec("create trigger trg_ai before insert on trg for each row set new.b = 1")
+	c.Assert(terror.ErrorEqual(err, infoschema.ErrTriggerExists), IsTrue)
+	_, err = tk.Exec("create trigger trg_x before insert on trg for each row set new.b = old.b")
+	c.Assert(terror.ErrorEqual(err, executor.ErrTrgNoSuchRow), IsTrue)
+	_, err = tk.Exec("create trigger trg_x after insert on trg for each row set new.b = 1")
+	c.Assert(terror.ErrorEqual(err, executor.ErrTrgCantChangeRow), IsTrue)
+	tk.MustExec("create trigger trg_x after delete on trg for each row delete from trg")
+	_, err = tk.Exec("delete from trg")
+	c.Assert(terror.ErrorEqual(err, executor.ErrCantUpdateUsedTable), IsTrue)
+
+	tk.MustExec("drop trigger trg_x")
+	_, err = tk.Exec("drop trigger trg_x")
+	c.Assert(terror.ErrorEqual(err, infoschema.ErrTriggerNotExists), IsTrue)
+	tk.MustExec("drop trigger if exists trg_x")
+
+	tk.MustExec("set @@tidb_ignore_trigger = 1")
+	tk.MustExec("create trigger trg_x after delete on trg for each row delete from trg")
+	tk.MustExec("drop trigger trg_bi")
+	tk.MustExec("set @@tidb_ignore_trigger = 0")
+	tk.MustQuery("show triggers like 'trg_x'").Check(testkit.Rows())
+	tk.MustQuery("show triggers like 'trg_bi'").Check(testkit.Rows(
+		"trg_bi INSERT trg set new.b = new.a * 2 BEFORE <nil>   utf8 utf8_general_ci utf8_general_ci"))
+
+	tk.MustExec("drop table trg, trg_log")
+}
+
 func (s *testSuite) fillDataMultiTable(tk *testkit.TestKit) {
 	tk.MustExec("use test")
 	tk.MustExec("drop table if exists t1, t2, t3")
@@ -652,13 +1056,13 @@ func (s *testSuite) TestLoadData(c *C) {
 	// fields and lines are default, InsertData returns data is nil
 	tests := []testCase{
 		// data1 = nil, data2 != nil
-		{nil, []byte("\n"), []string{"1|0||0"}, nil},
-		{nil, []byte("\t\n"), []string{"2|0||0"}, nil},
+		{nil, []byte("\n"), []string{"1|<nil>|def|<nil>"}, nil},
+		{nil, []byte("\t\n"), []string{"2|0|def|<nil>"}, nil},
 		{nil, []byte("3\t2\t3\t4\n"), []string{"3|2|3|4"}, nil},
 		{nil, []byte("3*1\t2\t3\t4\n"), []string{"3|2|3|4"}, nil},
 		{nil, []byte("4\t2\t\t3\t4\n"), []string{"4|2||3"}, nil},
 		{nil, []byte("\t1\t2\t3\t4\n"), []string{"5|1|2|3"}, nil},
-		{nil, []byte("6\t2\t3\n"), []string{"6|2|3|0"}, nil},
+		{nil, []byte("6\t2\t3\n"), []string{"6|2|3|<nil>"}, nil},
 		{nil, []byte("\t2\t3\t4\n\t22\t33\t44\n"), []string{"7|2|3|4", "8|22|33|44"}, nil},
 		{nil, []byte("7\t2\t3\t4\n7\t22\t33\t44\n"), []string{"7|2|3|4"}, nil},
 
@@ -673,7 +1077,7 @@ func (s *testSuite) TestLoadData(c *C) {
 		{[]byte("\t2\t3"), []byte("\t4\t5"), nil, []byte("\t2\t3\t4\t5")},
 	}
 	checkCases(tests, ld, c, tk, ctx, selectSQL, deleteSQL)
-	c.Assert(sc.WarningCount(), Equals, uint16(3))
+	c.Assert(sc.WarningCount(), Equals, uint16(2))
 
 	// lines starting symbol is "" and terminated symbol length is 2, InsertData returns data is nil
 	ld.LinesInfo.Terminated = "||"
@@ -684,9 +1088,9 @@ func (s *testSuite) TestLoadData(c *C) {
 		{[]byte("2\t2\t3\t4\t5|"), []byte("|3\t22\t33\t44\t55||"),
 			[]string{"2|2|3|4", "3|22|33|44"}, nil},
 		{[]byte("3\t2\t3\t4\t5|"), []byte("|4\t22\t33||"), []string{
-			"3|2|3|4", "4|22|33|0"}, nil},
+			"3|2|3|4", "4|22|33|<nil>"}, nil},
 		{[]byte("4\t2\t3\t4\t5|"), []byte("|5\t22\t33||6\t222||"),
-			[]string{"4|2|3|4", "5|22|33|0", "6|222||0"}, nil},
+			[]string{"4|2|3|4", "5|22|33|<nil>", "6|222|def|<nil>"}, nil},
 		{[]byte("6\t2\t3"), []byte("4\t5||"), []string{"6|2|34|5"}, nil},
 	}
 	checkCases(tests, ld, c, tk, ctx, selectSQL, deleteSQL)
@@ -697,12 +1101,12 @@ func (s *testSuite) TestLoadData(c *C) {
 	ld.LinesInfo.Terminated = "|!#^"
 	tests = []testCase{
 		// data1 = nil, data2 != nil
-		{nil, []byte("xxx|!#^"), []string{"13|0||0"}, nil},
-		{nil, []byte("xxx\\|!#^"), []string{"14|0||0"}, nil},
+		{nil, []byte("xxx|!#^"), []string{"13|<nil>|def|<nil>"}, nil},
+		{nil, []byte("xxx\\|!#^"), []string{"14|0|def|<nil>"}, nil},
 		{nil, []byte("xxx3\\2\\3\\4|!#^"), []string{"3|2|3|4"}, nil},
 		{nil, []byte("xxx4\\2\\\\3\\4|!#^"), []string{"4|2||3"}, nil},
 		{nil, []byte("xxx\\1\\2\\3\\4|!#^"), []string{"15|1|2|3"}, nil},
-		{nil, []byte("xxx6\\2\\3|!#^"), []string{"6|2|3|0"}, nil},
+		{nil, []byte("xxx6\\2\\3|!#^"), []string{"6|2|3|<nil>"}, nil},
 		{nil, []byte("xxx\\2\\3\\4|!#^xxx\\22\\33\\44|!#^"), []string{
 			"16|2|3|4",
 			"17|22|33|44"}, nil},
@@ -719,17 +1123,17 @@ func (s *testSuite) TestLoadData(c *C) {
 		{[]byte("xxx10\\2\\3"), []byte("\\4|!#^"),
 			[]string{"10|2|3|4"}, nil},
 		{[]byte("10\\2\\3xx"), []byte("x11\\4\\5|!#^"),
-			[]string{"11|4|5|0"}, nil},
+			[]string{"11|4|5|<nil>"}, nil},
 		{[]byte("xxx21\\2\\3\\4\\5|!"), []byte("#^"),
 			[]string{"21|2|3|4"}, nil},
 		{[]byte("xxx22\\2\\3\\4\\5|!"), []byte("#^xxx23\\22\\33\\44\\55|!#^"),
 			[]string{"22|2|3|4", "23|22|33|44"}, nil},
 		{[]byte("xxx23\\2\\3\\4\\5|!"), []byte("#^xxx24\\22\\33|!#^"),
-			[]string{"23|2|3|4", "24|22|33|0"}, nil},
+			[]string{"23|2|3|4", "24|22|33|<nil>"}, nil},
 		{[]byte("xxx24\\2\\3\\4\\5|!"), []byte("#^xxx25\\22\\33|!#^xxx26\\222|!#^"),
-			[]string{"24|2|3|4", "25|22|33|0", "26|222||0"}, nil},
+			[]string{"24|2|3|4", "25|22|33|<nil>", "26|222|def|<nil>"}, nil},
 		{[]byte("xxx25\\2\\3\\4\\5|!"), []byte("#^26\\22\\33|!#^xxx27\\222|!#^"),
-			[]string{"25|2|3|4", "27|222||0"}, nil},
+			[]string{"25|2|3|4", "27|222|def|<nil>"}, nil},
 		{[]byte("xxx\\2\\3"), []byte("4\\5|!#^"), []string{"28|2|34|5"}, nil},
 
 		// InsertData returns data isn't nil
@@ -737,9 +1141,9 @@ func (s *testSuite) TestLoadData(c *C) {
 		{nil, []byte("\\4\\5"), nil, []byte("\\5")},
 		{[]byte("\\2\\3"), []byte("\\4\\5"), nil, []byte("\\5")},
 		{[]byte("xxx1\\2\\3|"), []byte("!#^\\4\\5|!#"),
-			[]string{"1|2|3|0"}, []byte("!#")},
+			[]string{"1|2|3|<nil>"}, []byte("!#")},
 		{[]byte("xxx1\\2\\3\\4\\5|!"), []byte("#^xxx2\\22\\33|!#^3\\222|!#^"),
-			[]string{"1|2|3|4", "2|22|33|0"}, []byte("#^")},
+			[]string{"1|2|3|4", "2|22|33|<nil>"}, []byte("#^")},
 		{[]byte("xx1\\2\\3"), []byte("\\4\\5|!#^"), nil, []byte("#^")},
 	}
 	checkCases(tests, ld, c, tk, ctx, selectSQL, deleteSQL)
@@ -748,7 +1152,7 @@ func (s *testSuite) TestLoadData(c *C) {
 	ld.LinesInfo.Terminated = "xxx"
 	tests = []testCase{
 		// data1 = nil, data2 != nil
-		{nil, []byte("xxxxxx"), []string{"29|0||0"}, nil},
+		{nil, []byte("xxxxxx"), []string{"29|<nil>|def|<nil>"}, nil},
 		{nil, []byte("xxx3\\2\\3\\4xxx"), []string{"3|2|3|4"}, nil},
 		{nil, []byte("xxx\\2\\3\\4xxxxxx\\22\\33\\44xxx"),
 			[]string{"30|2|3|4", "31|22|33|44"}, nil},
@@ -763,19 +1167,19 @@ func (s *testSuite) TestLoadData(c *C) {
 		{[]byte("xxx32\\2\\3\\4\\5x"), []byte("xxxxx33\\22\\33\\44\\55xxx"),
 			[]string{"32|2|3|4", "33|22|33|44"}, nil},
 		{[]byte("xxx33\\2\\3\\4\\5xxx"), []byte("xxx34\\22\\33xxx"),
-			[]string{"33|2|3|4", "34|22|33|0"}, nil},
+			[]string{"33|2|3|4", "34|22|33|<nil>"}, nil},
 		{[]byte("xxx34\\2\\3\\4\\5xx"), []byte("xxxx35\\22\\33xxxxxx36\\222xxx"),
-			[]string{"34|2|3|4", "35|22|33|0", "36|222||0"}, nil},
+			[]string{"34|2|3|4", "35|22|33|<nil>", "36|222|def|<nil>"}, nil},
 
 		// InsertData returns data isn't nil
 		{nil, []byte("\\2\\3\\4xxxx"), nil, []byte("xxxx")},
-		{[]byte("\\2\\3\\4xxx"), nil, []string{"37|0||0"}, nil},
+		{[]byte("\\2\\3\\4xxx"), nil, []string{"37|<nil>|def|<nil>"}, nil},
 		{[]byte("\\2\\3\\4xxxxxx11\\22\\33\\44xxx"), nil,
-			[]string{"38|0||0", "39|0||0"}, nil},
+			[]string{"38|<nil>|def|<nil>", "39|<nil>|def|<nil>"}, nil},
 		{[]byte("xx10\\2\\3"), []byte("\\4\\5xxx"), nil, []byte("xxx")},
 		{[]byte("xxx10\\2\\3"), []byte("\\4xxxx"), []string{"10|2|3|4"}, []byte("x")},
 		{[]byte("xxx10\\2\\3\\4\\5x"), []byte("xx11\\22\\33xxxxxx12\\222xxx"),
-			[]string{"10|2|3|4", "40|0||0"}, []byte("xxx")},
+			[]string{"10|2|3|4", "40|<nil>|def|<nil>"}, []byte("xxx")},
 	}
 	checkCases(tests, ld, c, tk, ctx, selectSQL, deleteSQL)
 }
@@ -804,6 +1208,17 @@ func (s *testSuite) TestLoadDataEscape(c *C) {
 	deleteSQL := "delete from load_data_test"
 	selectSQL := "select * from load_data_test;"
 	checkCases(tests, ld, c, tk, ctx, selectSQL, deleteSQL)
+
+	// The rows failed to insert for the duplicate keys are skipped with warnings. The rows are counted in
+	// the statement context of the last query.
+	tk.MustExec("insert into load_data_test values (1, 'a')")
+	tk.MustQuery(selectSQL).Check(testkit.Rows("1 a"))
+	c.Assert(ctx.NewTxn(), IsNil)
+	_, _, err := ld.InsertData(nil, []byte("1\tb\n2\tc\n"))
+	c.Assert(err, IsNil)
+	c.Assert(ctx.Txn().Commit(), IsNil)
+	ld.SetMessage()
+	tk.CheckLastMessage("Records: 2  Deleted: 0  Skipped: 1  Warnings: 1")
 }
 
 // reuse TestLoadDataEscape's test case :-)
@@ -833,6 +1248,37 @@ func (s *testSuite) TestLoadDataSpecifiedCoumns(c *C) {
 	checkCases(tests, ld, c, tk, ctx, selectSQL, deleteSQL)
 }
 
+func (s *testSuite) TestBulkLoadData(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test; drop table if exists load_data_test;")
+	tk.MustExec("create table load_data_test (id int primary key, value varchar(10), unique key (value))")
+	tk.MustExec("set @@tidb_bulk_load = 1")
+	tk.MustExec("load data local infile '/tmp/nonexistence.csv' into table load_data_test")
+	ctx := tk.Se.(context.Context)
+	ld := ctx.Value(executor.LoadDataVarKey).(*executor.LoadDataInfo)
+	ctx.SetValue(executor.LoadDataVarKey, nil)
+	ld.SetBatchCount(0)
+
+	_, reachLimit, err := ld.InsertData(nil, []byte("1\ta\n2\tb\n3\ta\n"))
+	c.Assert(err, IsNil)
+	c.Assert(reachLimit, IsFalse)
+	// The duplicated row is skipped with a warning.
+	c.Assert(ctx.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	_, err = tk.Exec("insert into load_data_test values (4, 'd')")
+	c.Assert(terror.ErrorEqual(err, table.ErrTableLocked), IsTrue)
+	tk.MustQuery("select * from load_data_test").Check(nil)
+
+	c.Assert(ld.CommitBulkLoad(), IsNil)
+	tk.MustQuery("select * from load_data_test").Check(testkit.Rows("1 a", "2 b"))
+	tk.MustExec("insert into load_data_test values (4, 'd')")
+	tk.MustExec("admin check table load_data_test")
+	tk.MustExec("set @@tidb_bulk_load = 0")
+}
+
 func makeLoadDataInfo(column int, specifiedColumns []string, ctx context.Context, c *C) (ld *executor.LoadDataInfo) {
 	domain := sessionctx.GetDomain(ctx)
 	is := domain.InfoSchema()
@@ -913,3 +1359,160 @@ func (s *testSuite) TestBatchInsert(c *C) {
 	r = tk.MustQuery("select count(*) from batch_insert;")
 	r.Check(testkit.Rows("320"))
 }
+
+func (s *testSuite) TestTxnSizeLimit(c *C) {
+	originLimit := atomic.LoadUint64(&kv.TxnEntryCountLimit)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+		atomic.StoreUint64(&kv.TxnEntryCountLimit, originLimit)
+	}()
+	atomic.StoreUint64(&kv.TxnEntryCountLimit, 100)
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists txn_size")
+	tk.MustExec("create table txn_size (c int)")
+	values := make([]string, 0, 150)
+	for i := 0; i < 150; i++ {
+		values = append(values, fmt.Sprintf("(%d)", i))
+	}
+	insertSQL := "insert into txn_size values " + strings.Join(values, ",")
+
+	_, err := tk.Exec(insertSQL)
+	c.Assert(kv.ErrTxnTooLarge.Equal(err), IsTrue)
+	c.Assert(err, ErrorMatches, `.*entries: 101, size: \d+ bytes, limits: 100 entries, \d+ bytes.*tidb_txn_entry_count_limit.*, statement: insert into txn_size values \(0\),\(1\).*`)
+	c.Assert(kv.ErrTxnTooLarge.ToSQLError().Code, Equals, uint16(mysql.ErrTxnTooLarge))
+	tk.MustQuery("select count(*) from txn_size").Check(testkit.Rows("0"))
+
+	// The limit is raised for the session only.
+	tk.MustExec("set @@session.tidb_txn_entry_count_limit = 200")
+	tk.MustExec(insertSQL)
+	tk.MustQuery("select count(*) from txn_size").Check(testkit.Rows("150"))
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	_, err = tk1.Exec(insertSQL)
+	c.Assert(kv.ErrTxnTooLarge.Equal(err), IsTrue)
+
+	tk.MustExec("set @@session.tidb_txn_total_size_limit = 1000")
+	_, err = tk.Exec(insertSQL)
+	c.Assert(kv.ErrTxnTooLarge.Equal(err), IsTrue)
+	c.Assert(err, ErrorMatches, `.*limits: 200 entries, 1000 bytes.*`)
+	tk.MustExec("set @@session.tidb_txn_total_size_limit = 0")
+	tk.MustExec(insertSQL)
+	tk.MustQuery("select count(*) from txn_size").Check(testkit.Rows("300"))
+}
+
+func (s *testSuite) TestInsertSelectInChunks(c *C) {
+	originBatch := executor.BatchInsertSize
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+		executor.BatchInsertSize = originBatch
+	}()
+	// Fetch the rows of select 3 rows at a time.
+	executor.BatchInsertSize = 3
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists src, dst")
+	tk.MustExec("create table src (a int, b int)")
+	tk.MustExec("create table dst (id int primary key auto_increment, b int, unique key (b))")
+	tk.MustExec("insert src values (1, 1), (2, 2), (3, 3), (4, 4), (5, 5), (6, 6), (7, 7)")
+
+	tk.MustExec("insert dst (b) select b from src")
+	c.Assert(tk.Se.AffectedRows(), Equals, uint64(7))
+	tk.MustQuery("select last_insert_id()").Check(testkit.Rows("1"))
+	tk.MustQuery("select * from dst").Check(testkit.Rows("1 1", "2 2", "3 3", "4 4", "5 5", "6 6", "7 7"))
+
+	// A failure in a later chunk aborts the whole statement.
+	tk.MustExec("insert src values (1, 8)")
+	_, err := tk.Exec("insert dst select * from src")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select count(*) from dst").Check(testkit.Rows("7"))
+	tk.MustExec("insert ignore dst select a + 10, b from src")
+	tk.MustQuery("select count(*), max(b) from dst").Check(testkit.Rows("8 8"))
+	tk.MustExec("insert dst select a, b * 10 from src on duplicate key update b = values(b)")
+	tk.MustQuery("select b from dst where id < 10 order by id").Check(testkit.Rows("80", "20", "30", "40", "50", "60", "70"))
+	tk.MustExec("replace dst select a + 20, b from src")
+	tk.MustQuery("select * from dst where id > 10").Check(testkit.Rows("21 8", "22 2", "23 3", "24 4", "25 5", "26 6", "27 7"))
+
+	// The select doesn't see the rows inserted by the statement itself.
+	tk.MustExec("begin")
+	tk.MustExec("insert src values (9, 9)")
+	tk.MustExec("insert src select * from src")
+	tk.MustExec("commit")
+	tk.MustQuery("select count(*) from src").Check(testkit.Rows("18"))
+	tk.MustExec("drop table src, dst")
+}
+
+func (s *testSuite) TestForeignKeyCascade(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists parent, child, grandchild, orphan, tree")
+	tk.MustExec("create table parent (id int primary key, name varchar(10))")
+	tk.MustExec(`create table child (id int primary key, pid int,
+		foreign key fk_pid (pid) references parent (id) on delete cascade on update cascade)`)
+	tk.MustExec(`create table grandchild (id int primary key, cid int,
+		foreign key fk_cid (cid) references child (id) on delete cascade)`)
+	tk.MustExec(`create table orphan (id int primary key, pid int,
+		foreign key fk_pid (pid) references parent (id) on delete set null)`)
+	tk.MustExec("insert parent values (1, 'a'), (2, 'b'), (3, 'c')")
+	tk.MustExec("insert child values (1, 1), (2, 1), (3, 2), (4, null)")
+	tk.MustExec("insert grandchild values (1, 1), (2, 2), (3, 3)")
+	tk.MustExec("insert orphan values (1, 1), (2, 2), (3, 3)")
+
+	// The cascaded rows are not counted in the affected rows.
+	tk.MustExec("delete from parent where id = 1")
+	c.Assert(tk.Se.AffectedRows(), Equals, uint64(1))
+	tk.MustQuery("select * from child").Check(testkit.Rows("3 2", "4 <nil>"))
+	tk.MustQuery("select * from grandchild").Check(testkit.Rows("3 3"))
+	tk.MustQuery("select * from orphan").Check(testkit.Rows("1 <nil>", "2 2", "3 3"))
+
+	tk.MustExec("update parent set id = 20 where id = 2")
+	tk.MustQuery("select * from child").Check(testkit.Rows("3 20", "4 <nil>"))
+	// ON UPDATE of orphan is RESTRICT by default, which is not checked.
+	tk.MustQuery("select * from orphan").Check(testkit.Rows("1 <nil>", "2 2", "3 3"))
+	tk.MustExec("update parent set name = 'd' where id = 20")
+	tk.MustQuery("select * from child").Check(testkit.Rows("3 20", "4 <nil>"))
+
+	// The cascading actions are rolled back with the transaction.
+	tk.MustExec("begin")
+	tk.MustExec("delete from parent")
+	tk.MustQuery("select count(*) from child").Check(testkit.Rows("1"))
+	tk.MustExec("rollback")
+	tk.MustQuery("select * from child").Check(testkit.Rows("3 20", "4 <nil>"))
+	tk.MustExec("replace parent values (20, 'e')")
+	tk.MustQuery("select * from child").Check(testkit.Rows("4 <nil>"))
+	tk.MustQuery("select * from grandchild").Check(testkit.Rows())
+
+	// The rows deleted by the cascading actions of a self referencing table are skipped.
+	tk.MustExec(`create table tree (id int primary key, pid int,
+		foreign key fk_pid (pid) references tree (id) on delete cascade on update cascade)`)
+	tk.MustExec("insert tree values (1, null), (2, 1), (3, 2), (4, 1), (5, null)")
+	tk.MustExec("delete from tree where id < 5")
+	c.Assert(tk.Se.AffectedRows(), Equals, uint64(1))
+	tk.MustQuery("select * from tree").Check(testkit.Rows("5 <nil>"))
+
+	// Like InnoDB, a cascading update can't update a table being updated.
+	_, err := tk.Exec("update tree set id = 6 where id = 5")
+	c.Assert(err, IsNil)
+	tk.MustExec("insert tree values (7, 6)")
+	_, err = tk.Exec("update tree set id = 8 where id = 6")
+	c.Assert(terror.ErrorEqual(err, executor.ErrRowIsReferenced), IsTrue)
+	tk.MustQuery("select * from tree").Check(testkit.Rows("6 <nil>", "7 6"))
+
+	// The depth of the cascading actions is limited.
+	tk.MustExec("delete from tree")
+	for i := 1; i <= 17; i++ {
+		tk.MustExec(fmt.Sprintf("insert tree values (%d, %d)", i, i-1))
+	}
+	_, err = tk.Exec("delete from tree where id = 1")
+	c.Assert(terror.ErrorEqual(err, executor.ErrFKDepthExceeded), IsTrue)
+	tk.MustQuery("select count(*) from tree").Check(testkit.Rows("17"))
+	tk.MustExec("delete from tree where id = 3")
+	tk.MustQuery("select * from tree").Check(testkit.Rows("1 0", "2 1"))
+	tk.MustExec("drop table parent, child, grandchild, orphan, tree")
+}
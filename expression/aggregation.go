@@ -15,7 +15,7 @@ package expression
 
 import (
 	"bytes"
-	"encoding/json"
+	gojson "encoding/json"
 	"fmt"
 	"strings"
 
@@ -27,13 +27,14 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/types/json"
 	tipb "github.com/pingcap/tipb/go-tipb"
 )
 
 // AggregationFunction stands for aggregate functions.
 type AggregationFunction interface {
 	fmt.Stringer
-	json.Marshaler
+	gojson.Marshaler
 	// Update during executing.
 	Update(row []types.Datum, groupKey []byte, sc *variable.StatementContext) error
 
@@ -94,8 +95,10 @@ type aggEvaluateContext struct {
 	DistinctChecker *distinctChecker
 	Count           int64
 	Value           types.Datum
-	Buffer          *bytes.Buffer // Buffer is used for group_concat.
-	GotFirstRow     bool          // It will check if the agg has met the first row key.
+	Buffer          *bytes.Buffer        // Buffer is used for group_concat.
+	GotFirstRow     bool                 // It will check if the agg has met the first row key.
+	JSONArray       []json.JSON          // JSONArray is used for json_arrayagg.
+	JSONObject      map[string]json.JSON // JSONObject is used for json_objectagg.
 }
 
 // NewAggFunction creates a new AggregationFunction.
@@ -115,6 +118,10 @@ func NewAggFunction(funcType string, funcArgs []Expression, distinct bool) Aggre
 		return &maxMinFunction{aggFunction: newAggFunc(tp, funcArgs, distinct), isMax: false}
 	case ast.AggFuncFirstRow:
 		return &firstRowFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
+	case ast.AggFuncJSONArrayagg:
+		return &jsonArrayaggFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
+	case ast.AggFuncJSONObjectagg:
+		return &jsonObjectaggFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
 	}
 	return nil
 }
@@ -774,6 +781,167 @@ func (cf *concatFunction) GetStreamResult() (d types.Datum) {
 	return
 }
 
+// aggDatumToJSON converts a value of the JSON aggregate functions to JSON, NULL is the JSON null.
+func aggDatumToJSON(d types.Datum) (json.JSON, error) {
+	if d.IsNull() {
+		return json.CreateJSON(nil), nil
+	}
+	return ScalarDatumToJSON(d)
+}
+
+type jsonArrayaggFunction struct {
+	aggFunction
+}
+
+// Clone implements AggregationFunction interface.
+func (jf *jsonArrayaggFunction) Clone() AggregationFunction {
+	nf := *jf
+	for i, arg := range jf.Args {
+		nf.Args[i] = arg.Clone()
+	}
+	nf.resultMapper = make(aggCtxMapper)
+	return &nf
+}
+
+// GetType implements AggregationFunction interface.
+func (jf *jsonArrayaggFunction) GetType() *types.FieldType {
+	return types.NewFieldType(mysql.TypeJSON)
+}
+
+func (jf *jsonArrayaggFunction) update(ctx *aggEvaluateContext, row []types.Datum) error {
+	value, err := jf.Args[0].Eval(row)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	elem, err := aggDatumToJSON(value)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ctx.JSONArray = append(ctx.JSONArray, elem)
+	return nil
+}
+
+func (jf *jsonArrayaggFunction) calculateResult(ctx *aggEvaluateContext) (d types.Datum) {
+	if ctx.JSONArray != nil {
+		d.SetMysqlJSON(json.NewArray(ctx.JSONArray))
+	}
+	return d
+}
+
+// Update implements AggregationFunction interface.
+func (jf *jsonArrayaggFunction) Update(row []types.Datum, groupKey []byte, sc *variable.StatementContext) error {
+	return jf.update(jf.getContext(groupKey), row)
+}
+
+// StreamUpdate implements AggregationFunction interface.
+func (jf *jsonArrayaggFunction) StreamUpdate(row []types.Datum, sc *variable.StatementContext) error {
+	return jf.update(jf.getStreamedContext(), row)
+}
+
+// GetGroupResult implements AggregationFunction interface.
+func (jf *jsonArrayaggFunction) GetGroupResult(groupKey []byte) types.Datum {
+	return jf.calculateResult(jf.getContext(groupKey))
+}
+
+// GetPartialResult implements AggregationFunction interface.
+func (jf *jsonArrayaggFunction) GetPartialResult(groupKey []byte) []types.Datum {
+	return []types.Datum{jf.GetGroupResult(groupKey)}
+}
+
+// GetStreamResult implements AggregationFunction interface.
+func (jf *jsonArrayaggFunction) GetStreamResult() (d types.Datum) {
+	if jf.streamCtx == nil {
+		return
+	}
+	d = jf.calculateResult(jf.streamCtx)
+	jf.streamCtx = nil
+	return
+}
+
+type jsonObjectaggFunction struct {
+	aggFunction
+}
+
+// Clone implements AggregationFunction interface.
+func (jf *jsonObjectaggFunction) Clone() AggregationFunction {
+	nf := *jf
+	for i, arg := range jf.Args {
+		nf.Args[i] = arg.Clone()
+	}
+	nf.resultMapper = make(aggCtxMapper)
+	return &nf
+}
+
+// GetType implements AggregationFunction interface.
+func (jf *jsonObjectaggFunction) GetType() *types.FieldType {
+	return types.NewFieldType(mysql.TypeJSON)
+}
+
+// update adds a member to the object, the value of a duplicate key overwrites the previous one, like MySQL.
+func (jf *jsonObjectaggFunction) update(ctx *aggEvaluateContext, row []types.Datum) error {
+	key, err := jf.Args[0].Eval(row)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if key.IsNull() {
+		return json.ErrJSONDocumentNULLKey.GenByArgs()
+	}
+	name, err := key.ToString()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	value, err := jf.Args[1].Eval(row)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	member, err := aggDatumToJSON(value)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if ctx.JSONObject == nil {
+		ctx.JSONObject = make(map[string]json.JSON)
+	}
+	ctx.JSONObject[name] = member
+	return nil
+}
+
+func (jf *jsonObjectaggFunction) calculateResult(ctx *aggEvaluateContext) (d types.Datum) {
+	if ctx.JSONObject != nil {
+		d.SetMysqlJSON(json.NewObject(ctx.JSONObject))
+	}
+	return d
+}
+
+// Update implements AggregationFunction interface.
+func (jf *jsonObjectaggFunction) Update(row []types.Datum, groupKey []byte, sc *variable.StatementContext) error {
+	return jf.update(jf.getContext(groupKey), row)
+}
+
+// StreamUpdate implements AggregationFunction interface.
+func (jf *jsonObjectaggFunction) StreamUpdate(row []types.Datum, sc *variable.StatementContext) error {
+	return jf.update(jf.getStreamedContext(), row)
+}
+
+// GetGroupResult implements AggregationFunction interface.
+func (jf *jsonObjectaggFunction) GetGroupResult(groupKey []byte) types.Datum {
+	return jf.calculateResult(jf.getContext(groupKey))
+}
+
+// GetPartialResult implements AggregationFunction interface.
+func (jf *jsonObjectaggFunction) GetPartialResult(groupKey []byte) []types.Datum {
+	return []types.Datum{jf.GetGroupResult(groupKey)}
+}
+
+// GetStreamResult implements AggregationFunction interface.
+func (jf *jsonObjectaggFunction) GetStreamResult() (d types.Datum) {
+	if jf.streamCtx == nil {
+		return
+	}
+	d = jf.calculateResult(jf.streamCtx)
+	jf.streamCtx = nil
+	return
+}
+
 type maxMinFunction struct {
 	aggFunction
 	isMax bool
@@ -846,7 +1014,7 @@ func (mmf *maxMinFunction) Update(row []types.Datum, groupKey []byte, sc *variab
 		return nil
 	}
 	var c int
-	c, err = ctx.Value.CompareDatum(sc, value)
+	c, err = compareMaxMinValue(sc, ctx.Value, value)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -874,7 +1042,7 @@ func (mmf *maxMinFunction) StreamUpdate(row []types.Datum, sc *variable.Statemen
 		return nil
 	}
 	var c int
-	c, err = ctx.Value.CompareDatum(sc, value)
+	c, err = compareMaxMinValue(sc, ctx.Value, value)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -884,6 +1052,20 @@ func (mmf *maxMinFunction) StreamUpdate(row []types.Datum, sc *variable.Statemen
 	return nil
 }
 
+// compareMaxMinValue compares the values for MAX and MIN.
+// Unlike ORDER BY, MAX and MIN compare ENUM and SET values by their string values,
+// see https://dev.mysql.com/doc/refman/5.7/en/group-by-functions.html#function_max
+func compareMaxMinValue(sc *variable.StatementContext, a, b types.Datum) (int, error) {
+	switch a.Kind() {
+	case types.KindMysqlEnum, types.KindMysqlSet:
+		switch b.Kind() {
+		case types.KindMysqlEnum, types.KindMysqlSet:
+			return types.CompareString(a.GetString(), b.GetString()), nil
+		}
+	}
+	return a.CompareDatum(sc, b)
+}
+
 type firstRowFunction struct {
 	aggFunction
 }
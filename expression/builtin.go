@@ -146,6 +146,29 @@ func (b *baseBuiltinFunc) getCtx() context.Context {
 	return b.ctx
 }
 
+// allocBytes allocates a buffer from the statement context, so functions building
+// a new string don't allocate memory for every row.
+func (b *baseBuiltinFunc) allocBytes(capacity int) []byte {
+	if b.ctx == nil {
+		return make([]byte, 0, capacity)
+	}
+	return b.ctx.GetSessionVars().StmtCtx.AllocBytes(capacity)
+}
+
+// estimateStringLen estimates the length of the datums converted to string.
+func estimateStringLen(args []types.Datum) int {
+	length := 0
+	for _, a := range args {
+		switch a.Kind() {
+		case types.KindString, types.KindBytes:
+			length += len(a.GetBytes())
+		default:
+			length += 20
+		}
+	}
+	return length
+}
+
 // baseIntBuiltinFunc represents the functions which return int values.
 // TODO: baseIntBuiltinFunc will be removed later after all built-in function signatures been implemented.
 type baseIntBuiltinFunc struct {
@@ -573,6 +596,7 @@ var funcs = map[string]functionClass{
 	ast.Substr:         &substringFunctionClass{baseFunctionClass{ast.Substr, 2, 3}},
 	ast.SubstringIndex: &substringIndexFunctionClass{baseFunctionClass{ast.SubstringIndex, 3, 3}},
 	ast.ToBase64:       &toBase64FunctionClass{baseFunctionClass{ast.ToBase64, 1, 1}},
+	ast.WeightString:   &weightStringFunctionClass{baseFunctionClass{ast.WeightString, 1, 3}},
 	ast.Trim:           &trimFunctionClass{baseFunctionClass{ast.Trim, 1, 3}},
 	ast.Upper:          &upperFunctionClass{baseFunctionClass{ast.Upper, 1, 1}},
 	ast.Ucase:          &upperFunctionClass{baseFunctionClass{ast.Ucase, 1, 1}},
@@ -603,6 +627,23 @@ var funcs = map[string]functionClass{
 	ast.SessionUser:  &userFunctionClass{baseFunctionClass{ast.SessionUser, 0, 0}},
 	ast.SystemUser:   &userFunctionClass{baseFunctionClass{ast.SystemUser, 0, 0}},
 
+	// json functions
+	ast.JSONExtract:  &jsonExtractFunctionClass{baseFunctionClass{ast.JSONExtract, 2, -1}},
+	ast.JSONContains: &jsonContainsFunctionClass{baseFunctionClass{ast.JSONContains, 2, 3}},
+	ast.JSONMemberOf: &jsonMemberOfFunctionClass{baseFunctionClass{ast.JSONMemberOf, 2, 2}},
+	ast.JSONUnquote:  &jsonUnquoteFunctionClass{baseFunctionClass{ast.JSONUnquote, 1, 1}},
+	ast.JSONSet:      &jsonSetFunctionClass{baseFunctionClass{ast.JSONSet, 3, -1}},
+	ast.JSONObject:   &jsonObjectFunctionClass{baseFunctionClass{ast.JSONObject, 0, -1}},
+	ast.JSONArray:    &jsonArrayFunctionClass{baseFunctionClass{ast.JSONArray, 0, -1}},
+
+	// TiDB internal functions
+	ast.TiDBDigest:     &tidbDigestFunctionClass{baseFunctionClass{ast.TiDBDigest, 1, 1}},
+	ast.TiDBNormalize:  &tidbNormalizeFunctionClass{baseFunctionClass{ast.TiDBNormalize, 1, 1}},
+	ast.TiDBDecodeKey:  &tidbDecodeKeyFunctionClass{baseFunctionClass{ast.TiDBDecodeKey, 1, 1}},
+	ast.TiDBVersion:    &tidbVersionFunctionClass{baseFunctionClass{ast.TiDBVersion, 0, 0}},
+	ast.TiDBCurrentTSO: &tidbCurrentTSOFunctionClass{baseFunctionClass{ast.TiDBCurrentTSO, 0, 0}},
+	ast.TiDBIsDDLOwner: &tidbIsDDLOwnerFunctionClass{baseFunctionClass{ast.TiDBIsDDLOwner, 0, 0}},
+
 	// control functions
 	ast.If:     &ifFunctionClass{baseFunctionClass{ast.If, 3, 3}},
 	ast.Ifnull: &ifNullFunctionClass{baseFunctionClass{ast.Ifnull, 2, 2}},
@@ -627,6 +668,8 @@ var funcs = map[string]functionClass{
 	ast.ReleaseAllLocks: &releaseAllLocksFunctionClass{baseFunctionClass{ast.ReleaseAllLocks, 0, 0}},
 	ast.UUID:            &uuidFunctionClass{baseFunctionClass{ast.UUID, 0, 0}},
 	ast.UUIDShort:       &uuidShortFunctionClass{baseFunctionClass{ast.UUIDShort, 0, 0}},
+	ast.UUIDToBin:       &uuidToBinFunctionClass{baseFunctionClass{ast.UUIDToBin, 1, 2}},
+	ast.BinToUUID:       &binToUUIDFunctionClass{baseFunctionClass{ast.BinToUUID, 1, 2}},
 
 	// get_lock() and release_lock() are parsed but do nothing.
 	// It is used for preventing error in Ruby's activerecord migrations.
@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -216,10 +217,42 @@ type compareFunctionClass struct {
 }
 
 func (c *compareFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
-	sig := &builtinCompareSig{newBaseBuiltinFunc(args, ctx), c.op}
+	sig := &builtinCompareSig{newBaseBuiltinFunc(refineYearArgs(args), ctx), c.op}
 	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
 }
 
+// refineYearArgs converts the constant compared with a year column to the year type,
+// so `y = 69` matches the year 2069 as MySQL does.
+func refineYearArgs(args []Expression) []Expression {
+	if len(args) != 2 {
+		return args
+	}
+	for i, arg := range args {
+		col, ok := arg.(*Column)
+		if !ok || col.RetType == nil || col.RetType.Tp != mysql.TypeYear {
+			continue
+		}
+		con, ok := args[1-i].(*Constant)
+		if !ok {
+			continue
+		}
+		switch con.Value.Kind() {
+		case types.KindInt64, types.KindUint64, types.KindString, types.KindBytes:
+		default:
+			continue
+		}
+		// The invalid values are compared as they are.
+		y, err := con.Value.ConvertTo(new(variable.StatementContext), col.GetType())
+		if err != nil {
+			continue
+		}
+		newArgs := []Expression{args[0], args[1]}
+		newArgs[1-i] = &Constant{Value: y, RetType: col.GetType()}
+		return newArgs
+	}
+	return args
+}
+
 type builtinCompareSig struct {
 	baseBuiltinFunc
 
@@ -357,6 +390,30 @@ func (s *builtinCompareRealSig) evalInt(row []types.Datum) (int64, bool, error)
 	if err != nil {
 		return zeroI64, false, errors.Trace(err)
 	}
+	return s.compare(arg0, isKindNull0, arg1, isKindNull1)
+}
+
+func (s *builtinCompareRealSig) vecEvalInt(rows [][]types.Datum, vals []int64, nulls []bool) error {
+	sc := s.ctx.GetSessionVars().StmtCtx
+	args0, nulls0 := make([]float64, len(rows)), make([]bool, len(rows))
+	if err := VecEvalReal(s.args[0], rows, sc, args0, nulls0); err != nil {
+		return errors.Trace(err)
+	}
+	args1, nulls1 := make([]float64, len(rows)), make([]bool, len(rows))
+	if err := VecEvalReal(s.args[1], rows, sc, args1, nulls1); err != nil {
+		return errors.Trace(err)
+	}
+	var err error
+	for i := range rows {
+		vals[i], nulls[i], err = s.compare(args0[i], nulls0[i], args1[i], nulls1[i])
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (s *builtinCompareRealSig) compare(arg0 float64, isKindNull0 bool, arg1 float64, isKindNull1 bool) (int64, bool, error) {
 	if isKindNull0 || isKindNull1 {
 		if s.op == opcode.NullEQ {
 			if isKindNull0 && isKindNull1 {
@@ -423,6 +480,30 @@ func (s *builtinCompareIntSig) evalInt(row []types.Datum) (int64, bool, error) {
 	if err != nil {
 		return zeroI64, isKindNull1, errors.Trace(err)
 	}
+	return s.compare(arg0, isKindNull0, arg1, isKindNull1)
+}
+
+func (s *builtinCompareIntSig) vecEvalInt(rows [][]types.Datum, vals []int64, nulls []bool) error {
+	sc := s.ctx.GetSessionVars().StmtCtx
+	args0, nulls0 := make([]int64, len(rows)), make([]bool, len(rows))
+	if err := VecEvalInt(s.args[0], rows, sc, args0, nulls0); err != nil {
+		return errors.Trace(err)
+	}
+	args1, nulls1 := make([]int64, len(rows)), make([]bool, len(rows))
+	if err := VecEvalInt(s.args[1], rows, sc, args1, nulls1); err != nil {
+		return errors.Trace(err)
+	}
+	var err error
+	for i := range rows {
+		vals[i], nulls[i], err = s.compare(args0[i], nulls0[i], args1[i], nulls1[i])
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (s *builtinCompareIntSig) compare(arg0 int64, isKindNull0 bool, arg1 int64, isKindNull1 bool) (int64, bool, error) {
 	if isKindNull0 || isKindNull1 {
 		if s.op == opcode.NullEQ {
 			if isKindNull0 && isKindNull1 {
@@ -18,9 +18,13 @@
 package expression
 
 import (
+	"encoding/hex"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/util/printer"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -37,6 +41,12 @@ var (
 	_ functionClass = &coercibilityFunctionClass{}
 	_ functionClass = &collationFunctionClass{}
 	_ functionClass = &rowCountFunctionClass{}
+	_ functionClass = &tidbDigestFunctionClass{}
+	_ functionClass = &tidbNormalizeFunctionClass{}
+	_ functionClass = &tidbDecodeKeyFunctionClass{}
+	_ functionClass = &tidbVersionFunctionClass{}
+	_ functionClass = &tidbCurrentTSOFunctionClass{}
+	_ functionClass = &tidbIsDDLOwnerFunctionClass{}
 )
 
 var (
@@ -52,6 +62,12 @@ var (
 	_ builtinFunc = &builtinCoercibilitySig{}
 	_ builtinFunc = &builtinCollationSig{}
 	_ builtinFunc = &builtinRowCountSig{}
+	_ builtinFunc = &builtinTiDBDigestSig{}
+	_ builtinFunc = &builtinTiDBNormalizeSig{}
+	_ builtinFunc = &builtinTiDBDecodeKeySig{}
+	_ builtinFunc = &builtinTiDBVersionSig{}
+	_ builtinFunc = &builtinTiDBCurrentTSOSig{}
+	_ builtinFunc = &builtinTiDBIsDDLOwnerSig{}
 )
 
 type databaseFunctionClass struct {
@@ -341,3 +357,196 @@ type builtinRowCountSig struct {
 func (b *builtinRowCountSig) eval(row []types.Datum) (d types.Datum, err error) {
 	return d, errFunctionNotExists.GenByArgs("ROW_COUNT")
 }
+
+type tidbDigestFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *tidbDigestFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinTiDBDigestSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+type builtinTiDBDigestSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinTiDBDigestSig.
+// It returns the digest of the SQL text, which is the same as the one computed by parser.NormalizeDigest.
+func (b *builtinTiDBDigestSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	sql, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	_, digest := parser.NormalizeDigest(sql)
+	d.SetString(digest)
+	return d, nil
+}
+
+type tidbNormalizeFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *tidbNormalizeFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinTiDBNormalizeSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+type builtinTiDBNormalizeSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinTiDBNormalizeSig.
+// It returns the normalized form of the SQL text, which is the same as the one returned by parser.Normalize.
+func (b *builtinTiDBNormalizeSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	sql, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetString(parser.Normalize(sql))
+	return d, nil
+}
+
+// DecodeKey decodes the key to a JSON string which describes the table, the index and the handle of the key
+// with the information schema of the context. It's implemented in the executor package, because the
+// infoschema package can't be imported here.
+var DecodeKey func(ctx context.Context, key []byte) (string, error)
+
+type tidbDecodeKeyFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *tidbDecodeKeyFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinTiDBDecodeKeySig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+type builtinTiDBDecodeKeySig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinTiDBDecodeKeySig.
+// It decodes the hex string of a key, which is reported in the logs or the errors. If the key can't be decoded,
+// it returns the argument and appends a warning.
+func (b *builtinTiDBDecodeKeySig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	s, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	key, err := hex.DecodeString(s)
+	if err == nil {
+		var decoded string
+		decoded, err = DecodeKey(b.ctx, key)
+		if err == nil {
+			d.SetString(decoded)
+			return d, nil
+		}
+	}
+	b.ctx.GetSessionVars().StmtCtx.AppendWarning(errIncorrectArgs.Gen("Incorrect arguments to TIDB_DECODE_KEY: %v", err))
+	d.SetString(s)
+	return d, nil
+}
+
+type tidbVersionFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *tidbVersionFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	err := errors.Trace(c.verifyArgs(args))
+	bt := &builtinTiDBVersionSig{newBaseBuiltinFunc(args, ctx)}
+	bt.deterministic = false
+	return bt.setSelf(bt), errors.Trace(err)
+}
+
+type builtinTiDBVersionSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinTiDBVersionSig.
+// It returns the release version, the git commit hash and the build time of the server.
+func (b *builtinTiDBVersionSig) eval(_ []types.Datum) (d types.Datum, err error) {
+	d.SetString(printer.GetTiDBInfo())
+	return d, nil
+}
+
+type tidbCurrentTSOFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *tidbCurrentTSOFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	err := errors.Trace(c.verifyArgs(args))
+	bt := &builtinTiDBCurrentTSOSig{newBaseBuiltinFunc(args, ctx)}
+	bt.deterministic = false
+	return bt.setSelf(bt), errors.Trace(err)
+}
+
+type builtinTiDBCurrentTSOSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinTiDBCurrentTSOSig.
+// It returns the timestamp the session reads at, which is the snapshot timestamp if tidb_snapshot is set,
+// or the start timestamp of the current transaction. It returns NULL if there is no transaction.
+func (b *builtinTiDBCurrentTSOSig) eval(_ []types.Datum) (d types.Datum, err error) {
+	sessVars := b.ctx.GetSessionVars()
+	if sessVars.SnapshotTS != 0 {
+		d.SetUint64(sessVars.SnapshotTS)
+		return d, nil
+	}
+	if sessVars.TxnCtx == nil || sessVars.TxnCtx.StartTS == 0 {
+		return d, nil
+	}
+	d.SetUint64(sessVars.TxnCtx.StartTS)
+	return d, nil
+}
+
+// IsDDLOwner checks whether the server of the context is the owner of the DDL jobs. It's implemented in the
+// executor package, because the domain package can't be imported here.
+var IsDDLOwner func(ctx context.Context) (bool, error)
+
+type tidbIsDDLOwnerFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *tidbIsDDLOwnerFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	err := errors.Trace(c.verifyArgs(args))
+	bt := &builtinTiDBIsDDLOwnerSig{newBaseBuiltinFunc(args, ctx)}
+	bt.deterministic = false
+	return bt.setSelf(bt), errors.Trace(err)
+}
+
+type builtinTiDBIsDDLOwnerSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinTiDBIsDDLOwnerSig.
+// It returns 1 if the server is the owner of the DDL jobs and the ownership isn't expired, otherwise 0.
+func (b *builtinTiDBIsDDLOwnerSig) eval(_ []types.Datum) (d types.Datum, err error) {
+	isOwner, err := IsDDLOwner(b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(boolToInt64(isOwner))
+	return d, nil
+}
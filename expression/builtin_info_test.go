@@ -14,10 +14,14 @@
 package expression
 
 import (
+	"strings"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/printer"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -111,3 +115,62 @@ func (s *testEvaluatorSuite) TestVersion(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(v.GetString(), Equals, mysql.ServerVersion)
 }
+
+func (s *testEvaluatorSuite) TestTiDBVersion(c *C) {
+	defer testleak.AfterTest(c)()
+	f, err := funcs[ast.TiDBVersion].getFunction(nil, s.ctx)
+	c.Assert(err, IsNil)
+	v, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetString(), Equals, printer.GetTiDBInfo())
+	c.Assert(strings.Contains(v.GetString(), mysql.ServerVersion), IsTrue)
+}
+
+func (s *testEvaluatorSuite) TestTiDBCurrentTSO(c *C) {
+	defer testleak.AfterTest(c)()
+	ctx := mock.NewContext()
+	f, err := funcs[ast.TiDBCurrentTSO].getFunction(nil, ctx)
+	c.Assert(err, IsNil)
+	// There is no transaction in the context.
+	d, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+
+	ctx.GetSessionVars().TxnCtx.StartTS = 10
+	d, err = f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetUint64(), Equals, uint64(10))
+
+	// The snapshot timestamp is read if it's set.
+	ctx.GetSessionVars().SnapshotTS = 100
+	d, err = f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetUint64(), Equals, uint64(100))
+}
+
+func (s *testEvaluatorSuite) TestTiDBDigestAndNormalize(c *C) {
+	defer testleak.AfterTest(c)()
+	sql := "SELECT * FROM t WHERE a IN (1, 2, 3)"
+	normalized, digest := parser.NormalizeDigest(sql)
+	c.Assert(normalized, Equals, "select * from t where a in (...)")
+
+	f, err := funcs[ast.TiDBNormalize].getFunction(datumsToConstants(types.MakeDatums(sql)), s.ctx)
+	c.Assert(err, IsNil)
+	d, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, normalized)
+
+	f, err = funcs[ast.TiDBDigest].getFunction(datumsToConstants(types.MakeDatums(sql)), s.ctx)
+	c.Assert(err, IsNil)
+	d, err = f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, digest)
+
+	for _, name := range []string{ast.TiDBNormalize, ast.TiDBDigest} {
+		f, err = funcs[name].getFunction(datumsToConstants(types.MakeDatums(nil)), s.ctx)
+		c.Assert(err, IsNil)
+		d, err = f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.IsNull(), IsTrue)
+	}
+}
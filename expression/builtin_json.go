@@ -0,0 +1,396 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/types/json"
+)
+
+var (
+	_ functionClass = &jsonExtractFunctionClass{}
+	_ functionClass = &jsonContainsFunctionClass{}
+	_ functionClass = &jsonMemberOfFunctionClass{}
+	_ functionClass = &jsonUnquoteFunctionClass{}
+	_ functionClass = &jsonSetFunctionClass{}
+	_ functionClass = &jsonObjectFunctionClass{}
+	_ functionClass = &jsonArrayFunctionClass{}
+)
+
+var (
+	_ builtinFunc = &builtinJSONExtractSig{}
+	_ builtinFunc = &builtinJSONContainsSig{}
+	_ builtinFunc = &builtinJSONMemberOfSig{}
+	_ builtinFunc = &builtinJSONUnquoteSig{}
+	_ builtinFunc = &builtinJSONSetSig{}
+	_ builtinFunc = &builtinJSONObjectSig{}
+	_ builtinFunc = &builtinJSONArraySig{}
+)
+
+// DatumToJSON converts a JSON document argument to JSON, a string is parsed as the JSON text.
+func DatumToJSON(d types.Datum) (json.JSON, error) {
+	switch d.Kind() {
+	case types.KindMysqlJSON:
+		return d.GetMysqlJSON(), nil
+	case types.KindString, types.KindBytes:
+		return json.ParseFromString(d.GetString())
+	}
+	return nil, json.ErrInvalidJSONData.GenByArgs()
+}
+
+// ScalarDatumToJSON converts a value argument to JSON, a string is a JSON string instead of the JSON text,
+// and NULL is the JSON null.
+func ScalarDatumToJSON(d types.Datum) (json.JSON, error) {
+	switch d.Kind() {
+	case types.KindNull:
+		return json.CreateJSON(nil), nil
+	case types.KindMysqlJSON:
+		return d.GetMysqlJSON(), nil
+	case types.KindInt64:
+		return json.CreateJSON(float64(d.GetInt64())), nil
+	case types.KindUint64:
+		return json.CreateJSON(float64(d.GetUint64())), nil
+	case types.KindFloat32, types.KindFloat64, types.KindMysqlDecimal:
+		f, err := d.ToFloat64(nil)
+		return json.CreateJSON(f), errors.Trace(err)
+	}
+	s, err := d.ToString()
+	return json.CreateJSON(s), errors.Trace(err)
+}
+
+// parseJSONPathArg parses a path argument, which can't be NULL.
+func parseJSONPathArg(d types.Datum) (json.PathExpression, error) {
+	if d.IsNull() {
+		return json.PathExpression{}, json.ErrInvalidJSONPath.GenByArgs()
+	}
+	s, err := d.ToString()
+	if err != nil {
+		return json.PathExpression{}, errors.Trace(err)
+	}
+	return json.ParseJSONPathExpr(s)
+}
+
+type jsonExtractFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *jsonExtractFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinJSONExtractSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+type builtinJSONExtractSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinJSONExtractSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-search-functions.html#function_json-extract
+func (b *builtinJSONExtractSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	doc, err := DatumToJSON(args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	values := make([]json.JSON, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		pe, err := parseJSONPathArg(arg)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		if value, found := json.Extract(doc, pe); found {
+			values = append(values, value)
+		}
+	}
+	switch {
+	case len(values) == 0:
+		return d, nil
+	case len(args) == 2:
+		d.SetMysqlJSON(values[0])
+	default:
+		// The values found by multiple paths are wrapped in an array.
+		d.SetMysqlJSON(json.NewArray(values))
+	}
+	return d, nil
+}
+
+type jsonContainsFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *jsonContainsFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinJSONContainsSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+type builtinJSONContainsSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinJSONContainsSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-search-functions.html#function_json-contains
+func (b *builtinJSONContainsSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() || args[1].IsNull() {
+		return d, nil
+	}
+	target, err := DatumToJSON(args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	candidate, err := DatumToJSON(args[1])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if len(args) == 3 {
+		pe, err := parseJSONPathArg(args[2])
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		var found bool
+		if target, found = json.Extract(target, pe); !found {
+			return d, nil
+		}
+	}
+	d.SetInt64(boolToInt64(json.Contains(target, candidate)))
+	return d, nil
+}
+
+type jsonMemberOfFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *jsonMemberOfFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinJSONMemberOfSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+type builtinJSONMemberOfSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinJSONMemberOfSig, which is `value MEMBER OF(json_array)`.
+// It returns whether the value is an element of the array, a non-array document is treated as a
+// single element array.
+func (b *builtinJSONMemberOfSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() || args[1].IsNull() {
+		return d, nil
+	}
+	value, err := ScalarDatumToJSON(args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	doc, err := DatumToJSON(args[1])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	for _, elem := range json.Elements(doc) {
+		if json.Equal(elem, value) {
+			d.SetInt64(1)
+			return d, nil
+		}
+	}
+	d.SetInt64(0)
+	return d, nil
+}
+
+type jsonUnquoteFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *jsonUnquoteFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinJSONUnquoteSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+type builtinJSONUnquoteSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinJSONUnquoteSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-modification-functions.html#function_json-unquote
+func (b *builtinJSONUnquoteSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	switch args[0].Kind() {
+	case types.KindNull:
+		return d, nil
+	case types.KindMysqlJSON:
+		d.SetString(json.Unquote(args[0].GetMysqlJSON()))
+		return d, nil
+	}
+	s, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	// Only a string enclosed in double quotes is parsed as a JSON string, the others are returned as they are.
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		j, err := json.ParseFromString(s)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		s = json.Unquote(j)
+	}
+	d.SetString(s)
+	return d, nil
+}
+
+type jsonSetFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *jsonSetFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinJSONSetSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+// verifyArgs checks that the arguments are a document and (path, value) pairs.
+func (c *jsonSetFunctionClass) verifyArgs(args []Expression) error {
+	if err := c.baseFunctionClass.verifyArgs(args); err != nil {
+		return err
+	}
+	if len(args)%2 != 1 {
+		return errIncorrectParameterCount.GenByArgs(c.funcName)
+	}
+	return nil
+}
+
+type builtinJSONSetSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinJSONSetSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-modification-functions.html#function_json-set
+func (b *builtinJSONSetSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	doc, err := DatumToJSON(args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	for i := 1; i < len(args); i += 2 {
+		if args[i].IsNull() {
+			return d, nil
+		}
+		pe, err := parseJSONPathArg(args[i])
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		value, err := ScalarDatumToJSON(args[i+1])
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		doc = json.Set(doc, pe, value)
+	}
+	d.SetMysqlJSON(doc)
+	return d, nil
+}
+
+type jsonObjectFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *jsonObjectFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinJSONObjectSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+// verifyArgs checks that the arguments are (key, value) pairs.
+func (c *jsonObjectFunctionClass) verifyArgs(args []Expression) error {
+	if len(args)%2 != 0 {
+		return errIncorrectParameterCount.GenByArgs(c.funcName)
+	}
+	return nil
+}
+
+type builtinJSONObjectSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinJSONObjectSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-creation-functions.html#function_json-object
+func (b *builtinJSONObjectSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	members := make(map[string]json.JSON, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		if args[i].IsNull() {
+			return d, json.ErrJSONDocumentNULLKey.GenByArgs()
+		}
+		key, err := args[i].ToString()
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		if members[key], err = ScalarDatumToJSON(args[i+1]); err != nil {
+			return d, errors.Trace(err)
+		}
+	}
+	d.SetMysqlJSON(json.NewObject(members))
+	return d, nil
+}
+
+type jsonArrayFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *jsonArrayFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinJSONArraySig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+type builtinJSONArraySig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinJSONArraySig.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-creation-functions.html#function_json-array
+func (b *builtinJSONArraySig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	elems := make([]json.JSON, 0, len(args))
+	for _, arg := range args {
+		elem, err := ScalarDatumToJSON(arg)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		elems = append(elems, elem)
+	}
+	d.SetMysqlJSON(json.NewArray(elems))
+	return d, nil
+}
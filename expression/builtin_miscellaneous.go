@@ -15,6 +15,7 @@ package expression
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"math"
 	"net"
 	"strings"
@@ -23,8 +24,12 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/userlock"
 	"github.com/twinj/uuid"
+	goctx "golang.org/x/net/context"
 )
 
 var (
@@ -48,6 +53,8 @@ var (
 	_ functionClass = &releaseAllLocksFunctionClass{}
 	_ functionClass = &uuidFunctionClass{}
 	_ functionClass = &uuidShortFunctionClass{}
+	_ functionClass = &uuidToBinFunctionClass{}
+	_ functionClass = &binToUUIDFunctionClass{}
 )
 
 var (
@@ -71,6 +78,8 @@ var (
 	_ builtinFunc = &builtinReleaseAllLocksSig{}
 	_ builtinFunc = &builtinUUIDSig{}
 	_ builtinFunc = &builtinUUIDShortSig{}
+	_ builtinFunc = &builtinUUIDToBinSig{}
+	_ builtinFunc = &builtinBinToUUIDSig{}
 )
 
 type sleepFunctionClass struct {
@@ -136,6 +145,7 @@ type lockFunctionClass struct {
 
 func (c *lockFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
 	sig := &builtinLockSig{newBaseBuiltinFunc(args, ctx)}
+	sig.deterministic = false
 	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
 }
 
@@ -145,19 +155,64 @@ type builtinLockSig struct {
 
 // eval evals a builtinLockSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_get-lock
-// The lock function will do nothing.
-// Warning: get_lock() function is parsed but ignored.
-func (b *builtinLockSig) eval(_ []types.Datum) (d types.Datum, err error) {
-	d.SetInt64(1)
+// The locks are shared by the sessions on the same tidb-server only.
+func (b *builtinLockSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	m, err := b.userLockManager()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	name, err := userLockName(args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	// A negative timeout means waiting forever, a NULL timeout means not waiting.
+	var timeout float64
+	if !args[1].IsNull() {
+		timeout, err = args[1].ToFloat64(b.ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+	}
+	acquired, err := m.Acquire(b.ctx.GoCtx(), b.ctx.GetSessionVars(), name, time.Duration(timeout*float64(time.Second)))
+	if err != nil {
+		if terror.ErrorEqual(err, goctx.Canceled) {
+			// The statement is killed.
+			return d, nil
+		}
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(boolToInt64(acquired))
 	return d, nil
 }
 
+// userLockManager gets the manager of the user-level locks bound to the session.
+func (b *baseBuiltinFunc) userLockManager() (*userlock.Manager, error) {
+	m := userlock.GetManager(b.ctx)
+	if m == nil {
+		return nil, errors.New("user-level locks are not supported without a domain")
+	}
+	return m, nil
+}
+
+func userLockName(arg types.Datum) (string, error) {
+	if arg.IsNull() {
+		return "", userlock.ErrWrongName.GenByArgs("NULL")
+	}
+	name, err := arg.ToString()
+	return name, errors.Trace(err)
+}
+
 type releaseLockFunctionClass struct {
 	baseFunctionClass
 }
 
 func (c *releaseLockFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
 	sig := &builtinReleaseLockSig{newBaseBuiltinFunc(args, ctx)}
+	sig.deterministic = false
 	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
 }
 
@@ -167,11 +222,25 @@ type builtinReleaseLockSig struct {
 
 // eval evals a builtinReleaseLockSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_release-lock
-// The release lock function will do nothing.
-// Warning: release_lock() function is parsed but ignored.
-func (b *builtinReleaseLockSig) eval(_ []types.Datum) (d types.Datum, err error) {
-	d.SetInt64(1)
-	return
+func (b *builtinReleaseLockSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	m, err := b.userLockManager()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	name, err := userLockName(args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	released, exists, err := m.Release(b.ctx.GetSessionVars(), name)
+	if err != nil || !exists {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(boolToInt64(released))
+	return d, nil
 }
 
 type anyValueFunctionClass struct {
@@ -425,6 +494,7 @@ type isFreeLockFunctionClass struct {
 
 func (c *isFreeLockFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
 	sig := &builtinIsFreeLockSig{newBaseBuiltinFunc(args, ctx)}
+	sig.deterministic = false
 	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
 }
 
@@ -435,7 +505,30 @@ type builtinIsFreeLockSig struct {
 // eval evals a builtinIsFreeLockSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_is-free-lock
 func (b *builtinIsFreeLockSig) eval(row []types.Datum) (d types.Datum, err error) {
-	return d, errFunctionNotExists.GenByArgs("IS_FREE_LOCK")
+	owner, err := b.userLockOwner(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(boolToInt64(owner == nil))
+	return d, nil
+}
+
+// userLockOwner gets the session holding the user-level lock named by the first argument.
+func (b *baseBuiltinFunc) userLockOwner(row []types.Datum) (*variable.SessionVars, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	m, err := b.userLockManager()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	name, err := userLockName(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	owner, err := m.Owner(name)
+	return owner, errors.Trace(err)
 }
 
 type isIPv4FunctionClass struct {
@@ -613,6 +706,7 @@ type isUsedLockFunctionClass struct {
 
 func (c *isUsedLockFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
 	sig := &builtinIsUsedLockSig{newBaseBuiltinFunc(args, ctx)}
+	sig.deterministic = false
 	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
 }
 
@@ -623,7 +717,12 @@ type builtinIsUsedLockSig struct {
 // eval evals a builtinIsUsedLockSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_is-used-lock
 func (b *builtinIsUsedLockSig) eval(row []types.Datum) (d types.Datum, err error) {
-	return d, errFunctionNotExists.GenByArgs("IS_USED_LOCK")
+	owner, err := b.userLockOwner(row)
+	if err != nil || owner == nil {
+		return d, errors.Trace(err)
+	}
+	d.SetUint64(owner.ConnectionID)
+	return d, nil
 }
 
 type masterPosWaitFunctionClass struct {
@@ -670,6 +769,7 @@ type releaseAllLocksFunctionClass struct {
 
 func (c *releaseAllLocksFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
 	sig := &builtinReleaseAllLocksSig{newBaseBuiltinFunc(args, ctx)}
+	sig.deterministic = false
 	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
 }
 
@@ -680,7 +780,12 @@ type builtinReleaseAllLocksSig struct {
 // eval evals a builtinReleaseAllLocksSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_release-all-locks
 func (b *builtinReleaseAllLocksSig) eval(row []types.Datum) (d types.Datum, err error) {
-	return d, errFunctionNotExists.GenByArgs("RELEASEA_ALL_LOCKS")
+	m, err := b.userLockManager()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(int64(m.ReleaseAll(b.ctx.GetSessionVars())))
+	return d, nil
 }
 
 type uuidFunctionClass struct {
@@ -723,3 +828,121 @@ type builtinUUIDShortSig struct {
 func (b *builtinUUIDShortSig) eval(row []types.Datum) (d types.Datum, err error) {
 	return d, errFunctionNotExists.GenByArgs("UUID_SHORT")
 }
+
+type uuidToBinFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *uuidToBinFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinUUIDToBinSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+type builtinUUIDToBinSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinUUIDToBinSig.
+// See https://dev.mysql.com/doc/refman/8.0/en/miscellaneous-functions.html#function_uuid-to-bin
+func (b *builtinUUIDToBinSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	s, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	bin, ok := parseUUID(s)
+	if !ok {
+		return d, errWrongValueForType.GenByArgs("string", s, "uuid_to_bin")
+	}
+	swap, err := uuidSwapFlag(b.ctx.GetSessionVars().StmtCtx, args)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if swap {
+		bin = []byte{bin[6], bin[7], bin[4], bin[5], bin[0], bin[1], bin[2], bin[3],
+			bin[8], bin[9], bin[10], bin[11], bin[12], bin[13], bin[14], bin[15]}
+	}
+	d.SetBytes(bin)
+	return d, nil
+}
+
+type binToUUIDFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *binToUUIDFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinBinToUUIDSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+type builtinBinToUUIDSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinBinToUUIDSig.
+// See https://dev.mysql.com/doc/refman/8.0/en/miscellaneous-functions.html#function_bin-to-uuid
+func (b *builtinBinToUUIDSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	bin, err := args[0].ToBytes()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if len(bin) != uuidLen {
+		return d, errWrongValueForType.GenByArgs("string", hex.EncodeToString(bin), "bin_to_uuid")
+	}
+	swap, err := uuidSwapFlag(b.ctx.GetSessionVars().StmtCtx, args)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if swap {
+		bin = []byte{bin[4], bin[5], bin[6], bin[7], bin[2], bin[3], bin[0], bin[1],
+			bin[8], bin[9], bin[10], bin[11], bin[12], bin[13], bin[14], bin[15]}
+	}
+	s := hex.EncodeToString(bin)
+	d.SetString(s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:])
+	return d, nil
+}
+
+// uuidLen is the length of a UUID in binary.
+const uuidLen = 16
+
+// parseUUID parses the UUID in the string format, the dashes and the braces around it are
+// optional, like MySQL.
+func parseUUID(s string) ([]byte, bool) {
+	if len(s) == 38 && s[0] == '{' && s[37] == '}' {
+		s = s[1:37]
+	}
+	if len(s) == 36 {
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return nil, false
+		}
+		s = s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	}
+	if len(s) != 2*uuidLen {
+		return nil, false
+	}
+	bin, err := hex.DecodeString(s)
+	return bin, err == nil
+}
+
+// uuidSwapFlag returns whether the time-low and the time-high parts of the UUID are swapped, so
+// the UUIDs generated by UUID() are stored in the order of the time.
+func uuidSwapFlag(sc *variable.StatementContext, args []types.Datum) (bool, error) {
+	if len(args) < 2 || args[1].IsNull() {
+		return false, nil
+	}
+	swap, err := args[1].ToBool(sc)
+	return swap != 0, errors.Trace(err)
+}
@@ -18,6 +18,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
@@ -113,6 +114,62 @@ func (s *testEvaluatorSuite) TestUUID(c *C) {
 	}
 }
 
+func (s *testEvaluatorSuite) TestUUIDToBinAndBinToUUID(c *C) {
+	defer testleak.AfterTest(c)()
+	bin := []byte{0x6c, 0xcd, 0x78, 0x0c, 0xba, 0xba, 0x10, 0x26, 0x96, 0x64, 0x13, 0x9c, 0xe4, 0x69, 0x0c, 0xea}
+	swapped := []byte{0x10, 0x26, 0xba, 0xba, 0x6c, 0xcd, 0x78, 0x0c, 0x96, 0x64, 0x13, 0x9c, 0xe4, 0x69, 0x0c, 0xea}
+	tests := []struct {
+		uuid string
+		swap interface{}
+		bin  []byte
+	}{
+		{"6ccd780c-baba-1026-9664-139ce4690cea", nil, bin},
+		{"6CCD780C-BABA-1026-9664-139CE4690CEA", 0, bin},
+		{"{6ccd780c-baba-1026-9664-139ce4690cea}", nil, bin},
+		{"6ccd780cbaba10269664139ce4690cea", nil, bin},
+		{"6ccd780c-baba-1026-9664-139ce4690cea", 1, swapped},
+	}
+	toBin := funcs[ast.UUIDToBin]
+	toUUID := funcs[ast.BinToUUID]
+	for _, t := range tests {
+		args := types.MakeDatums(t.uuid)
+		if t.swap != nil {
+			args = append(args, types.NewDatum(t.swap))
+		}
+		f, err := toBin.getFunction(datumsToConstants(args), s.ctx)
+		c.Assert(err, IsNil)
+		r, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(r.GetBytes(), DeepEquals, t.bin, Commentf("%v", t.uuid))
+
+		args[0] = r
+		f, err = toUUID.getFunction(datumsToConstants(args), s.ctx)
+		c.Assert(err, IsNil)
+		r, err = f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(r.GetString(), Equals, "6ccd780c-baba-1026-9664-139ce4690cea")
+	}
+
+	for _, str := range []string{"", "6ccd780c-baba-1026-9664-139ce4690ce", "6ccd780cbaba-1026-9664-139ce4690ceaa", "6ccd780c-baba-1026-9664-139ce4690cex"} {
+		f, err := toBin.getFunction(datumsToConstants(types.MakeDatums(str)), s.ctx)
+		c.Assert(err, IsNil)
+		_, err = f.eval(nil)
+		c.Assert(terror.ErrorEqual(err, errWrongValueForType), IsTrue, Commentf("%v", str))
+	}
+	f, err := toUUID.getFunction(datumsToConstants(types.MakeDatums(bin[:15])), s.ctx)
+	c.Assert(err, IsNil)
+	_, err = f.eval(nil)
+	c.Assert(terror.ErrorEqual(err, errWrongValueForType), IsTrue)
+
+	for _, fc := range []functionClass{toBin, toUUID} {
+		f, err = fc.getFunction(datumsToConstants(types.MakeDatums(nil)), s.ctx)
+		c.Assert(err, IsNil)
+		r, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(r.IsNull(), IsTrue)
+	}
+}
+
 func (s *testEvaluatorSuite) TestAnyValue(c *C) {
 	defer testleak.AfterTest(c)()
 
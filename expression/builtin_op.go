@@ -17,6 +17,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -214,12 +215,12 @@ func (s *builtinBitOpSig) eval(row []types.Datum) (d types.Datum, err error) {
 		return
 	}
 
-	x, err := a.ToInt64(sc)
+	x, err := bitOperand(sc, a)
 	if err != nil {
 		return d, errors.Trace(err)
 	}
 
-	y, err := b.ToInt64(sc)
+	y, err := bitOperand(sc, b)
 	if err != nil {
 		return d, errors.Trace(err)
 	}
@@ -242,6 +243,18 @@ func (s *builtinBitOpSig) eval(row []types.Datum) (d types.Datum, err error) {
 	return
 }
 
+// bitOperand converts the datum to int64 for bit operations, unsigned integers and
+// bit values keep all the 64 bits.
+func bitOperand(sc *variable.StatementContext, d types.Datum) (int64, error) {
+	switch d.Kind() {
+	case types.KindUint64:
+		return int64(d.GetUint64()), nil
+	case types.KindMysqlBit:
+		return int64(d.GetMysqlBit().Value), nil
+	}
+	return d.ToInt64(sc)
+}
+
 type isTrueOpFunctionClass struct {
 	baseFunctionClass
 
@@ -324,7 +337,7 @@ func (b *builtinUnaryOpSig) eval(row []types.Datum) (d types.Datum, err error) {
 	case opcode.BitNeg:
 		var n int64
 		// for bit operation, we will use int64 first, then return uint64
-		n, err = aDatum.ToInt64(sc)
+		n, err = bitOperand(sc, aDatum)
 		if err != nil {
 			return d, errors.Trace(err)
 		}
@@ -377,7 +390,7 @@ func (b *builtinUnaryOpSig) eval(row []types.Datum) (d types.Datum, err error) {
 		case types.KindMysqlHex:
 			d.SetFloat64(-aDatum.GetMysqlHex().ToNumber())
 		case types.KindMysqlBit:
-			d.SetFloat64(-aDatum.GetMysqlBit().ToNumber())
+			d.SetInt64(-int64(aDatum.GetMysqlBit().Value))
 		case types.KindMysqlEnum:
 			d.SetFloat64(-aDatum.GetMysqlEnum().ToNumber())
 		case types.KindMysqlSet:
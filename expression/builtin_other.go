@@ -14,6 +14,7 @@
 package expression
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/juju/errors"
@@ -21,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -153,17 +155,42 @@ func (b *builtinCastSig) eval(row []types.Datum) (d types.Datum, err error) {
 	switch b.tp.Tp {
 	// Parser has restricted this.
 	// TypeDouble is used during plan optimization.
+	// The other types are used by the implicit casts of UNION.
 	case mysql.TypeString, mysql.TypeDuration, mysql.TypeDatetime,
-		mysql.TypeDate, mysql.TypeLonglong, mysql.TypeNewDecimal, mysql.TypeDouble:
+		mysql.TypeDate, mysql.TypeLonglong, mysql.TypeNewDecimal, mysql.TypeDouble,
+		mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob,
+		mysql.TypeBlob, mysql.TypeTimestamp, mysql.TypeNewDate, mysql.TypeYear, mysql.TypeFloat,
+		mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeJSON:
 		d = args[0]
 		if d.IsNull() {
 			return
 		}
+		if b.tp.Tp == mysql.TypeString {
+			d = b.formatNumber(d)
+		}
 		return d.ConvertTo(b.ctx.GetSessionVars().StmtCtx, b.tp)
 	}
 	return d, errors.Errorf("unknown cast type - %v", b.tp)
 }
 
+// formatNumber formats the number datum to a string datum into the buffer allocated
+// from the statement context, so casting numbers to string doesn't allocate for every row.
+func (b *builtinCastSig) formatNumber(d types.Datum) types.Datum {
+	var buf []byte
+	switch d.Kind() {
+	case types.KindInt64:
+		buf = strconv.AppendInt(b.allocBytes(20), d.GetInt64(), 10)
+	case types.KindUint64:
+		buf = strconv.AppendUint(b.allocBytes(20), d.GetUint64(), 10)
+	case types.KindFloat64:
+		buf = strconv.AppendFloat(b.allocBytes(24), d.GetFloat64(), 'f', -1, 64)
+	default:
+		return d
+	}
+	d.SetBytesAsString(buf)
+	return d
+}
+
 type setVarFunctionClass struct {
 	baseFunctionClass
 }
@@ -184,16 +211,9 @@ func (b *builtinSetVarSig) eval(row []types.Datum) (types.Datum, error) {
 	if err != nil {
 		return types.Datum{}, errors.Trace(err)
 	}
-	sessionVars := b.ctx.GetSessionVars()
 	varName, _ := args[0].ToString()
-	if !args[1].IsNull() {
-		strVal, err := args[1].ToString()
-		if err != nil {
-			return types.Datum{}, errors.Trace(err)
-		}
-		sessionVars.Users[varName] = strings.ToLower(strVal)
-	}
-	return args[1], nil
+	err = SetUserVar(b.ctx.GetSessionVars(), varName, args[1], b.args[1].GetType())
+	return args[1], errors.Trace(err)
 }
 
 type getVarFunctionClass struct {
@@ -216,12 +236,70 @@ func (b *builtinGetVarSig) eval(row []types.Datum) (types.Datum, error) {
 	if err != nil {
 		return types.Datum{}, errors.Trace(err)
 	}
-	sessionVars := b.ctx.GetSessionVars()
 	varName, _ := args[0].ToString()
-	if v, ok := sessionVars.Users[varName]; ok {
-		return types.NewDatum(v), nil
+	d, _, _ := GetUserVar(b.ctx.GetSessionVars(), varName)
+	return d, nil
+}
+
+// SetUserVar sets the user variable to the value of an expression of the type, a NULL value unsets the variable.
+// Like MySQL, the integers, reals, decimals and strings keep their types, the other values are stored as strings,
+// and the strings keep the collation of the expression.
+func SetUserVar(sessionVars *variable.SessionVars, name string, d types.Datum, tp *types.FieldType) error {
+	name = strings.ToLower(name)
+	switch d.Kind() {
+	case types.KindNull:
+		delete(sessionVars.Users, name)
+		return nil
+	case types.KindInt64, types.KindUint64, types.KindFloat64:
+	case types.KindFloat32:
+		d.SetFloat64(float64(d.GetFloat32()))
+	case types.KindMysqlDecimal:
+		// The decimal may be updated in place by the following rows, so we copy it.
+		dec := *d.GetMysqlDecimal()
+		d.SetMysqlDecimal(&dec)
+	case types.KindString:
+		// The bytes may be allocated from the statement context and reused, so we copy them.
+		d.SetString(string(d.GetBytes()))
+	case types.KindBytes:
+		d.SetBytes(append([]byte(nil), d.GetBytes()...))
+	default:
+		str, err := d.ToString()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		d.SetString(str)
+	}
+	if d.Kind() == types.KindString || d.Kind() == types.KindBytes {
+		d.SetCollation(mysql.CollationNames[tp.Collate])
+	}
+	sessionVars.Users[name] = d
+	return nil
+}
+
+// GetUserVar gets the user variable and its type, it returns a NULL datum and false if the variable isn't set.
+func GetUserVar(sessionVars *variable.SessionVars, name string) (types.Datum, *types.FieldType, bool) {
+	v, ok := sessionVars.Users[strings.ToLower(name)]
+	if !ok {
+		return types.Datum{}, types.NewFieldType(mysql.TypeNull), false
+	}
+	d := v.(types.Datum)
+	tp := types.NewFieldType(mysql.TypeUnspecified)
+	types.DefaultTypeForValue(d.GetValue(), tp)
+	switch d.Kind() {
+	case types.KindMysqlDecimal:
+		// The decimal may be updated in place by the caller, so we return a copy.
+		dec := *d.GetMysqlDecimal()
+		d.SetMysqlDecimal(&dec)
+	case types.KindString, types.KindBytes:
+		tp.Tp = mysql.TypeVarString
+		if collation, err := charset.GetCollationByID(int(d.Collation())); err == nil {
+			tp.Charset, tp.Collate = collation.CharsetName, collation.Name
+		}
+		if tp.Charset == charset.CharsetBin {
+			tp.Flag |= mysql.BinaryFlag
+		}
 	}
-	return types.Datum{}, nil
+	return d, tp, true
 }
 
 type valuesFunctionClass struct {
@@ -281,7 +359,7 @@ func (b *builtinBitCountSig) eval(row []types.Datum) (d types.Datum, err error)
 	}
 	sc := new(variable.StatementContext)
 	sc.IgnoreTruncate = true
-	bin, err := arg.ToInt64(sc)
+	bin, err := bitOperand(sc, arg)
 	if err != nil {
 		if terror.ErrorEqual(err, types.ErrOverflow) {
 			d.SetInt64(64)
@@ -14,12 +14,15 @@
 package expression
 
 import (
+	"math"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
-	"math"
 )
 
 var bitCountCases = []struct {
@@ -60,3 +63,57 @@ func (s *testEvaluatorSuite) TestBitCount(c *C) {
 		c.Assert(res, Equals, test.count)
 	}
 }
+
+func (s *testEvaluatorSuite) TestUserVar(c *C) {
+	defer testleak.AfterTest(c)()
+	strType := types.NewFieldType(mysql.TypeVarchar)
+	strType.Charset, strType.Collate = "utf8", "utf8_general_ci"
+	binType := types.NewFieldType(mysql.TypeVarString)
+	types.SetBinChsClnFlag(binType)
+	dt := types.Time{Time: types.FromDate(2017, 1, 2, 0, 0, 0, 0), Type: mysql.TypeDate}
+	tests := []struct {
+		value   types.Datum
+		tp      *types.FieldType
+		result  interface{}
+		retTp   byte
+		collate string
+	}{
+		{types.NewIntDatum(10), types.NewFieldType(mysql.TypeLonglong), int64(10), mysql.TypeLonglong, charset.CollationBin},
+		{types.NewUintDatum(10), types.NewFieldType(mysql.TypeLonglong), uint64(10), mysql.TypeLonglong, charset.CollationBin},
+		{types.NewFloat32Datum(1.5), types.NewFieldType(mysql.TypeFloat), float64(1.5), mysql.TypeDouble, charset.CollationBin},
+		{types.NewDecimalDatum(types.NewDecFromStringForTest("1.50")), types.NewFieldType(mysql.TypeNewDecimal), "1.50", mysql.TypeNewDecimal, charset.CollationBin},
+		{types.NewStringDatum("AbC"), strType, "AbC", mysql.TypeVarString, "utf8_general_ci"},
+		{types.NewBytesDatum([]byte("AbC")), binType, []byte("AbC"), mysql.TypeVarString, charset.CollationBin},
+		{types.NewDatum(dt), types.NewFieldType(mysql.TypeDate), "2017-01-02", mysql.TypeVarString, mysql.DefaultCollationName},
+	}
+	sessionVars := s.ctx.GetSessionVars()
+	for _, t := range tests {
+		setVar, err := funcs[ast.SetVar].getFunction([]Expression{
+			&Constant{Value: types.NewStringDatum("A"), RetType: types.NewFieldType(mysql.TypeString)},
+			&Constant{Value: t.value, RetType: t.tp},
+		}, s.ctx)
+		c.Assert(err, IsNil)
+		_, err = setVar.eval(nil)
+		c.Assert(err, IsNil)
+		getVar, err := funcs[ast.GetVar].getFunction(datumsToConstants(types.MakeDatums("a")), s.ctx)
+		c.Assert(err, IsNil)
+		d, err := getVar.eval(nil)
+		c.Assert(err, IsNil)
+		if d.Kind() == types.KindMysqlDecimal {
+			c.Assert(d.GetMysqlDecimal().String(), Equals, t.result)
+		} else {
+			c.Assert(d.GetValue(), DeepEquals, t.result)
+		}
+		_, tp, ok := GetUserVar(sessionVars, "a")
+		c.Assert(ok, IsTrue)
+		c.Assert(tp.Tp, Equals, t.retTp)
+		c.Assert(tp.Collate, Equals, t.collate)
+	}
+
+	// Setting the variable to NULL unsets it.
+	c.Assert(SetUserVar(sessionVars, "a", types.Datum{}, types.NewFieldType(mysql.TypeNull)), IsNil)
+	d, tp, ok := GetUserVar(sessionVars, "a")
+	c.Assert(ok, IsFalse)
+	c.Assert(d.IsNull(), IsTrue)
+	c.Assert(tp.Tp, Equals, mysql.TypeNull)
+}
@@ -217,17 +217,19 @@ func (b *builtinConcatSig) eval(row []types.Datum) (d types.Datum, err error) {
 	if err != nil {
 		return types.Datum{}, errors.Trace(err)
 	}
-	var s []byte
 	for _, a := range args {
 		if a.IsNull() {
 			return d, nil
 		}
+	}
+	s := b.allocBytes(estimateStringLen(args))
+	for _, a := range args {
 		var ss string
 		ss, err = a.ToString()
 		if err != nil {
 			return d, errors.Trace(err)
 		}
-		s = append(s, []byte(ss)...)
+		s = append(s, ss...)
 	}
 	d.SetBytesAsString(s)
 	return d, nil
@@ -253,28 +255,30 @@ func (b *builtinConcatWSSig) eval(row []types.Datum) (d types.Datum, err error)
 	if err != nil {
 		return types.Datum{}, errors.Trace(err)
 	}
-	var sep string
-	s := make([]string, 0, len(args))
-	for i, a := range args {
+	if args[0].IsNull() {
+		return d, nil
+	}
+	sep, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	s := b.allocBytes(estimateStringLen(args[1:]) + len(sep)*(len(args)-2))
+	first := true
+	for _, a := range args[1:] {
 		if a.IsNull() {
-			if i == 0 {
-				return d, nil
-			}
 			continue
 		}
 		ss, err := a.ToString()
 		if err != nil {
 			return d, errors.Trace(err)
 		}
-
-		if i == 0 {
-			sep = ss
-			continue
+		if !first {
+			s = append(s, sep...)
 		}
-		s = append(s, ss)
+		s = append(s, ss...)
+		first = false
 	}
-
-	d.SetString(strings.Join(s, sep))
+	d.SetBytesAsString(s)
 	return d, nil
 }
 
@@ -663,32 +667,35 @@ func (b *builtinConvertSig) eval(row []types.Datum) (d types.Datum, err error) {
 		return types.Datum{}, errors.Trace(err)
 	}
 	// Casting nil to any type returns nil
-	if args[0].Kind() != types.KindString {
-		return d, nil
-	}
-
-	str := args[0].GetString()
-	Charset := args[1].GetString()
-
-	if strings.ToLower(Charset) == "ascii" {
-		d.SetString(str)
-		return d, nil
-	} else if strings.ToLower(Charset) == "utf8mb4" {
-		d.SetString(str)
+	if args[0].IsNull() {
 		return d, nil
 	}
-
-	encoding, _ := charset.Lookup(Charset)
-	if encoding == nil {
-		return d, errors.Errorf("unknown encoding: %s", Charset)
+	cs := strings.ToLower(args[1].GetString())
+	if !charset.ValidCharsetAndCollation(cs, "") {
+		return d, errors.Errorf("unknown encoding: %s", args[1].GetString())
 	}
-
-	target, _, err := transform.String(encoding.NewDecoder(), str)
+	str, err := args[0].ToString()
 	if err != nil {
-		log.Errorf("Convert %s to %s with error: %v", str, Charset, err)
 		return d, errors.Trace(err)
 	}
-	d.SetString(target)
+	if cs == charset.CharsetBin {
+		d.SetBytes([]byte(str))
+		return d, nil
+	}
+	// Binary strings are interpreted as in the target charset, the other strings are UTF-8,
+	// they are converted to the target charset, the characters not in it become '?'.
+	binaryStr := false
+	switch args[0].Kind() {
+	case types.KindMysqlHex, types.KindMysqlBit:
+		binaryStr = true
+	case types.KindBytes:
+		binaryStr = b.args[0].GetType().Charset == charset.CharsetBin
+	}
+	if !binaryStr {
+		str, _ = charset.EncodeString(cs, str)
+	}
+	str, _ = charset.DecodeString(cs, str)
+	d.SetString(str)
 	return d, nil
 }
 
@@ -932,13 +939,16 @@ func (b *builtinHexSig) eval(row []types.Datum) (d types.Datum, err error) {
 	switch args[0].Kind() {
 	case types.KindNull:
 		return d, nil
-	case types.KindString:
+	case types.KindString, types.KindBytes:
 		x, err := args[0].ToString()
 		if err != nil {
 			return d, errors.Trace(err)
 		}
 		d.SetString(strings.ToUpper(hex.EncodeToString(hack.Slice(x))))
 		return d, nil
+	case types.KindMysqlBit:
+		d.SetString(strings.ToUpper(strconv.FormatUint(args[0].GetMysqlBit().Value, 16)))
+		return d, nil
 	case types.KindInt64, types.KindUint64, types.KindMysqlHex, types.KindFloat32, types.KindFloat64, types.KindMysqlDecimal:
 		x, _ := args[0].Cast(b.ctx.GetSessionVars().StmtCtx, types.NewFieldType(mysql.TypeLonglong))
 		h := fmt.Sprintf("%x", uint64(x.GetInt64()))
@@ -1588,6 +1598,10 @@ func (b *builtinOctSig) eval(row []types.Datum) (d types.Datum, err error) {
 	if arg.IsNull() {
 		return d, nil
 	}
+	if arg.Kind() == types.KindMysqlBit {
+		d.SetString(strconv.FormatUint(arg.GetMysqlBit().Value, 8))
+		return d, nil
+	}
 	n, err := arg.ToString()
 	if err != nil {
 		return d, errors.Trace(err)
@@ -1750,6 +1764,10 @@ func (b *builtinBinSig) eval(row []types.Datum) (d types.Datum, err error) {
 	if arg.IsNull() || (arg.Kind() == types.KindString && arg.GetString() == "") {
 		return d, nil
 	}
+	if arg.Kind() == types.KindMysqlBit {
+		d.SetString(strconv.FormatUint(arg.GetMysqlBit().Value, 2))
+		return d, nil
+	}
 
 	num, err := arg.ToInt64(sc)
 	if err != nil {
@@ -2011,6 +2029,57 @@ func splitToSubN(s string, n int) []string {
 	return subs
 }
 
+type weightStringFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *weightStringFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
+	sig := &builtinWeightStringSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
+}
+
+type builtinWeightStringSig struct {
+	baseBuiltinFunc
+}
+
+// eval evals a builtinWeightStringSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_weight-string
+func (b *builtinWeightStringSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if args[0].IsNull() {
+		return d, nil
+	}
+	str, err := args[0].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if len(args) == 3 {
+		// WEIGHT_STRING(str AS CHAR(N)) pads str with spaces or truncates it to N characters,
+		// WEIGHT_STRING(str AS BINARY(N)) pads str with 0x00 or truncates it to N bytes.
+		length := int(args[2].GetInt64())
+		switch args[1].GetString() {
+		case "CHAR":
+			runes := []rune(str)
+			if len(runes) > length {
+				str = string(runes[:length])
+			} else {
+				str += strings.Repeat(" ", length-len(runes))
+			}
+		case "BINARY":
+			if len(str) > length {
+				str = str[:length]
+			} else {
+				str += strings.Repeat("\x00", length-len(str))
+			}
+		}
+	}
+	d.SetBytes(types.WeightString(str))
+	return d, nil
+}
+
 type insertFuncFunctionClass struct {
 	baseFunctionClass
 }
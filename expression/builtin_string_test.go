@@ -14,6 +14,8 @@
 package expression
 
 import (
+	"bytes"
+	"strconv"
 	"strings"
 	"time"
 
@@ -151,6 +153,35 @@ func (s *testEvaluatorSuite) TestConcatWS(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testEvaluatorSuite) TestStringResultsNotShared(c *C) {
+	defer testleak.AfterTest(c)()
+	col := &Column{Index: 0, RetType: types.NewFieldType(mysql.TypeLonglong)}
+	concat, err := funcs[ast.Concat].getFunction([]Expression{col, &Constant{Value: types.NewStringDatum("a")}}, s.ctx)
+	c.Assert(err, IsNil)
+	concatWS, err := funcs[ast.ConcatWS].getFunction([]Expression{&Constant{Value: types.NewStringDatum(",")}, col, col}, s.ctx)
+	c.Assert(err, IsNil)
+	tp := types.NewFieldType(mysql.TypeString)
+	tp.Flen = types.UnspecifiedLength
+	cast, err := (&castFunctionClass{baseFunctionClass{ast.Cast, 1, 1}, tp}).getFunction([]Expression{col}, s.ctx)
+	c.Assert(err, IsNil)
+
+	var results []types.Datum
+	for i := 0; i < 1000; i++ {
+		row := types.MakeDatums(int64(i))
+		for _, f := range []builtinFunc{concat, concatWS, cast} {
+			v, err := f.eval(row)
+			c.Assert(err, IsNil)
+			results = append(results, v)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		str := strconv.Itoa(i)
+		c.Assert(results[i*3].GetString(), Equals, str+"a")
+		c.Assert(results[i*3+1].GetString(), Equals, str+","+str)
+		c.Assert(results[i*3+2].GetString(), Equals, str)
+	}
+}
+
 func (s *testEvaluatorSuite) TestLeft(c *C) {
 	defer testleak.AfterTest(c)()
 	args := types.MakeDatums([]interface{}{"abcdefg", int64(2)}...)
@@ -444,6 +475,10 @@ func (s *testEvaluatorSuite) TestConvert(c *C) {
 	}{
 		{"haha", "utf8", "haha"},
 		{"haha", "ascii", "haha"},
+		{"café", "latin1", "café"},
+		{"中文", "gbk", "中文"},
+		{"中文", "latin1", "??"},
+		{"中文", "ascii", "??"},
 	}
 	for _, v := range tbl {
 		fc := funcs[ast.Convert]
@@ -1482,6 +1517,43 @@ func (s *testEvaluatorSuite) TestToBase64(c *C) {
 	}
 }
 
+func (s *testEvaluatorSuite) TestWeightString(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		args   []interface{}
+		expect interface{}
+	}{
+		{[]interface{}{nil}, nil},
+		{[]interface{}{"abc"}, "abc"},
+		{[]interface{}{123}, "123"},
+		{[]interface{}{"abc", "CHAR", 5}, "abc  "},
+		{[]interface{}{"中文abc", "CHAR", 3}, "中文a"},
+		{[]interface{}{"abc", "BINARY", 5}, "abc\x00\x00"},
+		{[]interface{}{"中文abc", "BINARY", 3}, "中"},
+		{[]interface{}{"abc", "CHAR", 0}, ""},
+	}
+	fc := funcs[ast.WeightString]
+	for _, test := range tests {
+		f, err := fc.getFunction(datumsToConstants(types.MakeDatums(test.args...)), s.ctx)
+		c.Assert(err, IsNil)
+		result, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		if test.expect == nil {
+			c.Assert(result.Kind(), Equals, types.KindNull)
+		} else {
+			c.Assert(result.GetString(), Equals, test.expect)
+		}
+	}
+
+	// The sort keys are ordered as the strings.
+	strs := []string{"", "a", "a ", "A", "ab", "b", "中", "\x00"}
+	for _, x := range strs {
+		for _, y := range strs {
+			c.Assert(bytes.Compare(types.WeightString(x), types.WeightString(y)), Equals, types.CompareString(x, y))
+		}
+	}
+}
+
 func (s *testEvaluatorSuite) TestStringRight(c *C) {
 	defer testleak.AfterTest(c)()
 	fc := funcs[ast.Right]
@@ -15,12 +15,15 @@ package expression
 
 import (
 	"reflect"
+	"strings"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/userlock"
 )
 
 // tblToDtbl is a util function for test.
@@ -196,18 +199,34 @@ func (s *testEvaluatorSuite) TestIsNullFunc(c *C) {
 
 func (s *testEvaluatorSuite) TestLock(c *C) {
 	defer testleak.AfterTest(c)()
+	userlock.BindManager(s.ctx, userlock.NewManager())
+	defer userlock.BindManager(s.ctx, nil)
 
-	lock := funcs[ast.GetLock]
-	f, err := lock.getFunction(datumsToConstants(types.MakeDatums(nil, 1)), s.ctx)
-	c.Assert(err, IsNil)
-	v, err := f.eval(nil)
-	c.Assert(err, IsNil)
-	c.Assert(v.GetInt64(), Equals, int64(1))
-
-	releaseLock := funcs[ast.ReleaseLock]
-	f, err = releaseLock.getFunction(datumsToConstants(types.MakeDatums(1)), s.ctx)
-	c.Assert(err, IsNil)
-	v, err = f.eval(nil)
-	c.Assert(err, IsNil)
-	c.Assert(v.GetInt64(), Equals, int64(1))
+	eval := func(name string, args ...interface{}) interface{} {
+		f, err := funcs[name].getFunction(datumsToConstants(types.MakeDatums(args...)), s.ctx)
+		c.Assert(err, IsNil)
+		v, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		return v.GetValue()
+	}
+	c.Assert(eval(ast.GetLock, "a", 1), Equals, int64(1))
+	c.Assert(eval(ast.GetLock, "A", 1), Equals, int64(1))
+	c.Assert(eval(ast.IsFreeLock, "a"), Equals, int64(0))
+	c.Assert(eval(ast.IsUsedLock, "a"), Equals, s.ctx.GetSessionVars().ConnectionID)
+	c.Assert(eval(ast.ReleaseLock, "a"), Equals, int64(1))
+	c.Assert(eval(ast.ReleaseLock, "a"), Equals, int64(1))
+	c.Assert(eval(ast.ReleaseLock, "a"), IsNil)
+	c.Assert(eval(ast.IsFreeLock, "a"), Equals, int64(1))
+	c.Assert(eval(ast.IsUsedLock, "a"), IsNil)
+	c.Assert(eval(ast.GetLock, "a", 1), Equals, int64(1))
+	c.Assert(eval(ast.GetLock, "b", nil), Equals, int64(1))
+	c.Assert(eval(ast.ReleaseAllLocks), Equals, int64(2))
+	c.Assert(eval(ast.ReleaseAllLocks), Equals, int64(0))
+
+	for _, name := range []interface{}{nil, "", strings.Repeat("a", 65)} {
+		f, err := funcs[ast.GetLock].getFunction(datumsToConstants(types.MakeDatums(name, 1)), s.ctx)
+		c.Assert(err, IsNil)
+		_, err = f.eval(nil)
+		c.Assert(terror.ErrorEqual(err, userlock.ErrWrongName), IsTrue)
+	}
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -636,7 +637,7 @@ func builtinNow(args []types.Datum, ctx context.Context) (d types.Datum, err err
 		}
 	}
 
-	t, err := convertTimeToMysqlTime(time.Now(), fsp)
+	t, err := convertTimeToMysqlTime(time.Now().In(getTimeZone(ctx)), fsp)
 	if err != nil {
 		return d, errors.Trace(err)
 	}
@@ -1068,7 +1069,7 @@ func (b *builtinFromUnixTimeSig) eval(row []types.Datum) (d types.Datum, err err
 		fsp = types.MaxFsp
 	}
 
-	t, err := convertTimeToMysqlTime(time.Unix(integralPart, fractionalPart), fsp)
+	t, err := convertTimeToMysqlTime(time.Unix(integralPart, fractionalPart).In(getTimeZone(b.ctx)), fsp)
 	if err != nil {
 		return d, errors.Trace(err)
 	}
@@ -1176,8 +1177,7 @@ func (b *builtinStrToDateSig) eval(row []types.Datum) (types.Datum, error) {
 
 	succ := t.StrToDate(date, format)
 	if !succ {
-		d.SetNull()
-		return d, nil
+		return d, strictErrorOrWarning(errWrongValueForType.GenByArgs("datetime", date, "str_to_date"), b.ctx)
 	}
 
 	d.SetMysqlTime(t)
@@ -1226,7 +1226,7 @@ type builtinCurrentDateSig struct {
 // eval evals a builtinCurrentDateSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_curdate
 func (b *builtinCurrentDateSig) eval(_ []types.Datum) (d types.Datum, err error) {
-	year, month, day := time.Now().Date()
+	year, month, day := time.Now().In(getTimeZone(b.ctx)).Date()
 	t := types.Time{
 		Time: types.FromDate(year, int(month), day, 0, 0, 0, 0),
 		Type: mysql.TypeDate, Fsp: 0}
@@ -1262,7 +1262,7 @@ func (b *builtinCurrentTimeSig) eval(row []types.Datum) (d types.Datum, err erro
 			return d, errors.Trace(err)
 		}
 	}
-	d.SetString(time.Now().Format("15:04:05.000000"))
+	d.SetString(time.Now().In(getTimeZone(b.ctx)).Format("15:04:05.000000"))
 	return convertToDuration(b.ctx.GetSessionVars().StmtCtx, d, fsp)
 }
 
@@ -1537,17 +1537,13 @@ func (b *builtinDateArithSig) eval(row []types.Datum) (d types.Datum, err error)
 	if b.op == ast.DateArithSub {
 		year, month, day, duration = -year, -month, -day, -duration
 	}
-	// TODO: Consider time_zone variable.
-	t, err := result.Time.GoTime(time.Local)
-	if err != nil {
-		return d, errors.Trace(err)
+	if err = result.AddInterval(year, month, day, duration); err != nil {
+		// The result out of the datetime range is NULL.
+		return d, strictErrorOrWarning(err, b.ctx)
 	}
-	t = t.Add(duration)
-	t = t.AddDate(int(year), int(month), int(day))
-	if t.Nanosecond() == 0 {
+	if result.Time.Microsecond() == 0 {
 		result.Fsp = 0
 	}
-	result.Time = types.FromGoTime(t)
 	d.SetMysqlTime(result)
 	return d, nil
 }
@@ -1610,6 +1606,16 @@ func (b *builtinTimestampDiffSig) eval(row []types.Datum) (d types.Datum, err er
 	return
 }
 
+// strictErrorOrWarning returns err in the strict mode DML, otherwise it appends err as a warning.
+func strictErrorOrWarning(err error, ctx context.Context) error {
+	sc := ctx.GetSessionVars().StmtCtx
+	if ctx.GetSessionVars().StrictSQLMode && !sc.IgnoreTruncate {
+		return errors.Trace(err)
+	}
+	sc.AppendWarning(err)
+	return nil
+}
+
 // errorOrWarning reports error or warning depend on the context.
 func errorOrWarning(err error, ctx context.Context) error {
 	sc := ctx.GetSessionVars().StmtCtx
@@ -1832,52 +1838,36 @@ func (b *builtinConvertTzSig) eval(row []types.Datum) (d types.Datum, err error)
 
 	dt := arg0.GetMysqlTime()
 
-	fromTZ := args[1].GetString()
-	toTZ := args[2].GetString()
-
-	const tzArgReg = `(^(\+|-)(0?[0-9]|1[0-2]):[0-5]?\d$)|(^\+13:00$)`
-	r, _ := regexp.Compile(tzArgReg)
-	fmatch := r.MatchString(fromTZ)
-	tmatch := r.MatchString(toTZ)
-
-	if !fmatch && !tmatch {
-		ftz, err := time.LoadLocation(fromTZ)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-
-		ttz, err := time.LoadLocation(toTZ)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-
-		t, err := dt.Time.GoTime(ftz)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
+	fromTZ, err := args[1].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	toTZ, err := args[2].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
 
-		d.SetMysqlTime(types.Time{
-			Time: types.FromGoTime(t.In(ttz)),
-			Type: mysql.TypeDatetime,
-			Fsp:  dt.Fsp,
-		})
+	// CONVERT_TZ returns NULL if the arguments are invalid.
+	ftz, err := varsutil.ParseTimeZone(fromTZ)
+	if err != nil {
+		return d, nil
+	}
+	ttz, err := varsutil.ParseTimeZone(toTZ)
+	if err != nil {
 		return d, nil
 	}
 
-	if fmatch && tmatch {
-		t, err := dt.Time.GoTime(time.Local)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-
-		d.SetMysqlTime(types.Time{
-			Time: types.FromGoTime(t.Add(timeZone2Duration(toTZ) - timeZone2Duration(fromTZ))),
-			Type: mysql.TypeDatetime,
-			Fsp:  dt.Fsp,
-		})
+	t, err := dt.Time.GoTime(ftz)
+	if err != nil {
+		return d, errors.Trace(err)
 	}
 
-	return
+	d.SetMysqlTime(types.Time{
+		Time: types.FromGoTime(t.In(ttz)),
+		Type: mysql.TypeDatetime,
+		Fsp:  dt.Fsp,
+	})
+	return d, nil
 }
 
 type makeDateFunctionClass struct {
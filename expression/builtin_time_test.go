@@ -20,6 +20,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
@@ -661,19 +662,26 @@ func (s *testEvaluatorSuite) TestStrToDate(c *C) {
 		{"2016 11 22 16 50 22", "%Y%m%d%H%i%s", true, time.Date(2016, 11, 22, 16, 50, 22, 0, time.Local)},
 		{"16-50-22 2016 11 22", "%H-%i-%s%Y%m%d", true, time.Date(2016, 11, 22, 16, 50, 22, 0, time.Local)},
 		{"16-50 2016 11 22", "%H-%i-%s%Y%m%d", false, time.Time{}},
+		{"2016 060 1:05 pm", "%Y %j %l:%i %p", true, time.Date(2016, 2, 29, 13, 5, 0, 0, time.Local)},
+		{"2012 51 Fri", "%X %V %a", true, time.Date(2012, 12, 21, 0, 0, 0, 0, time.Local)},
+		{"2012 51 Fri", "%Y %V %a", false, time.Time{}},
 	}
 
+	ctx := mock.NewContext()
+	ctx.GetSessionVars().StrictSQLMode = false
+	sc := ctx.GetSessionVars().StmtCtx
 	fc := funcs[ast.StrToDate]
 	for _, test := range tests {
 		date := types.NewStringDatum(test.Date)
 		format := types.NewStringDatum(test.Format)
-		f, err := fc.getFunction(datumsToConstants([]types.Datum{date, format}), s.ctx)
+		f, err := fc.getFunction(datumsToConstants([]types.Datum{date, format}), ctx)
 		c.Assert(err, IsNil)
+		warnCnt := sc.TotalWarningCount()
 		result, err := f.eval(nil)
 		c.Assert(err, IsNil)
 		if !test.Success {
-			c.Assert(err, IsNil)
 			c.Assert(result.IsNull(), IsTrue)
+			c.Assert(sc.TotalWarningCount(), Equals, warnCnt+1)
 			continue
 		}
 		c.Assert(result.Kind(), Equals, types.KindMysqlTime)
@@ -681,6 +689,13 @@ func (s *testEvaluatorSuite) TestStrToDate(c *C) {
 		t1, _ := value.Time.GoTime(time.Local)
 		c.Assert(t1, Equals, test.Expect)
 	}
+
+	// An error is returned in the strict mode.
+	ctx.GetSessionVars().StrictSQLMode = true
+	f, err := fc.getFunction(datumsToConstants(types.MakeDatums("16-50 2016 11 22", "%H-%i-%s%Y%m%d")), ctx)
+	c.Assert(err, IsNil)
+	_, err = f.eval(nil)
+	c.Assert(terror.ErrorEqual(err, errWrongValueForType), IsTrue)
 }
 
 func (s *testEvaluatorSuite) TestFromDays(c *C) {
@@ -1443,9 +1458,12 @@ func (s *testEvaluatorSuite) TestConvertTz(c *C) {
 		{"2004-01-01 12:00:00", "-00:00", "+13:00", true, "2004-01-02 01:00:00"},
 		{"2004-01-01 12:00:00", "-00:00", "-13:00", true, ""},
 		{"2004-01-01 12:00:00", "-00:00", "-12:88", true, ""},
-		{"2004-01-01 12:00:00", "+10:82", "GMT", false, ""},
-		{"2004-01-01 12:00:00", "+00:00", "GMT", true, ""},
-		{"2004-01-01 12:00:00", "GMT", "+00:00", true, ""},
+		{"2004-01-01 12:00:00", "+10:82", "GMT", true, ""},
+		{"2004-01-01 12:00:00", "abc", "GMT", true, ""},
+		{"2004-01-01 12:00:00", "+00:00", "GMT", true, "2004-01-01 12:00:00"},
+		{"2004-01-01 12:00:00", "GMT", "+00:00", true, "2004-01-01 12:00:00"},
+		{"2004-01-01 12:00:00", "UTC", "Asia/Shanghai", true, "2004-01-01 20:00:00"},
+		{"2004-01-01 12:00:00", "Asia/Shanghai", "-05:00", true, "2003-12-31 23:00:00"},
 		{20040101, "+00:00", "+10:32", true, "2004-01-01 10:32:00"},
 		{3.14159, "+00:00", "+10:32", false, ""},
 	}
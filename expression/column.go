@@ -119,6 +119,9 @@ type Column struct {
 	// IsAggOrSubq means if this column is referenced to a Aggregation column or a Subquery column.
 	// If so, this column's name will be the plain sql text.
 	IsAggOrSubq bool
+	// IsHidden means this column is the redundant copy of a common column of a natural join or a join with
+	// using clause. It can only be referenced by a qualified name and isn't expanded by an unqualified wildcard.
+	IsHidden bool
 
 	// Index is only used for execution.
 	Index int
@@ -581,23 +581,31 @@ func (s *testEvaluatorSuite) TestMod(c *C) {
 
 func (s *testEvaluatorSuite) TestDynamic(c *C) {
 	var dynamicFuncs = map[string]int{
-		ast.Rand:         0,
-		ast.ConnectionID: 0,
-		ast.CurrentUser:  0,
-		ast.User:         0,
-		ast.Database:     0,
-		ast.Schema:       0,
-		ast.FoundRows:    0,
-		ast.LastInsertId: 0,
-		ast.Version:      0,
-		ast.Sleep:        0,
-		ast.GetVar:       0,
-		ast.SetVar:       0,
-		ast.Values:       0,
-		ast.SessionUser:  0,
-		ast.SystemUser:   0,
-		ast.RowCount:     0,
-		ast.UUID:         0,
+		ast.Rand:            0,
+		ast.ConnectionID:    0,
+		ast.CurrentUser:     0,
+		ast.User:            0,
+		ast.Database:        0,
+		ast.Schema:          0,
+		ast.FoundRows:       0,
+		ast.LastInsertId:    0,
+		ast.Version:         0,
+		ast.Sleep:           0,
+		ast.GetVar:          0,
+		ast.SetVar:          0,
+		ast.Values:          0,
+		ast.SessionUser:     0,
+		ast.SystemUser:      0,
+		ast.RowCount:        0,
+		ast.UUID:            0,
+		ast.GetLock:         0,
+		ast.ReleaseLock:     0,
+		ast.IsFreeLock:      0,
+		ast.IsUsedLock:      0,
+		ast.ReleaseAllLocks: 0,
+		ast.TiDBVersion:     0,
+		ast.TiDBCurrentTSO:  0,
+		ast.TiDBIsDDLOwner:  0,
 	}
 	for name, fc := range funcs {
 		f, _ := fc.getFunction(nil, s.ctx)
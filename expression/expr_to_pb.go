@@ -14,6 +14,9 @@
 package expression
 
 import (
+	"strings"
+	"sync/atomic"
+
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/kv"
@@ -24,6 +27,40 @@ import (
 	"github.com/pingcap/tipb/go-tipb"
 )
 
+// exprPushdownBlacklist holds the names of the functions that can't be pushed down to the storage,
+// the value is a map[string]struct{} and it is replaced as a whole when the blacklist is reloaded.
+var exprPushdownBlacklist atomic.Value
+
+// exprPushdownBlacklistVersion is increased every time the blacklist is reloaded, it's accessed atomically.
+var exprPushdownBlacklistVersion int64
+
+func init() {
+	exprPushdownBlacklist.Store(make(map[string]struct{}))
+}
+
+// SetExprPushdownBlacklist replaces the names of the functions that can't be pushed down to the storage,
+// so the storage-side evaluation bugs can be avoided without restarting the server.
+func SetExprPushdownBlacklist(names []string) {
+	blacklist := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		blacklist[strings.ToLower(strings.TrimSpace(name))] = struct{}{}
+	}
+	exprPushdownBlacklist.Store(blacklist)
+	atomic.AddInt64(&exprPushdownBlacklistVersion, 1)
+}
+
+// PushdownBlacklistVersion returns the version of the expression pushdown blacklist, the plans built with
+// an older version may push down the functions blacklisted later.
+func PushdownBlacklistVersion() int64 {
+	return atomic.LoadInt64(&exprPushdownBlacklistVersion)
+}
+
+// IsPushdownBlacklisted checks whether the function is in the expression pushdown blacklist.
+func IsPushdownBlacklisted(name string) bool {
+	_, ok := exprPushdownBlacklist.Load().(map[string]struct{})[strings.ToLower(name)]
+	return ok
+}
+
 // ExpressionsToPB converts expression to tipb.Expr.
 func ExpressionsToPB(sc *variable.StatementContext, exprs []Expression, client kv.Client) (pbExpr *tipb.Expr, pushed []Expression, remained []Expression) {
 	pc := pbConverter{client: client, sc: sc}
@@ -139,6 +176,9 @@ func (pc pbConverter) columnToPBExpr(column *Column) *tipb.Expr {
 }
 
 func (pc pbConverter) scalarFuncToPBExpr(expr *ScalarFunction) *tipb.Expr {
+	if IsPushdownBlacklisted(expr.FuncName.L) {
+		return nil
+	}
 	switch expr.FuncName.L {
 	case ast.LT, ast.LE, ast.EQ, ast.NE, ast.GE, ast.GT,
 		ast.NullEQ, ast.In, ast.Like:
@@ -355,7 +395,7 @@ func SortByItemToPB(sc *variable.StatementContext, client kv.Client, expr Expres
 
 // AggFuncToPBExpr converts aggregate function to pb.
 func AggFuncToPBExpr(sc *variable.StatementContext, client kv.Client, aggFunc AggregationFunction) *tipb.Expr {
-	if aggFunc.IsDistinct() {
+	if aggFunc.IsDistinct() || IsPushdownBlacklisted(aggFunc.GetName()) {
 		return nil
 	}
 	pc := pbConverter{client: client, sc: sc}
@@ -375,6 +415,8 @@ func AggFuncToPBExpr(sc *variable.StatementContext, client kv.Client, aggFunc Ag
 		tp = tipb.ExprType_Sum
 	case ast.AggFuncAvg:
 		tp = tipb.ExprType_Avg
+	default:
+		return nil
 	}
 	if !client.IsRequestTypeSupported(kv.ReqTypeSelect, int64(tp)) {
 		return nil
@@ -34,6 +34,8 @@ var (
 	errInvalidOperation        = terror.ClassExpression.New(codeInvalidOperation, "invalid operation")
 	errIncorrectParameterCount = terror.ClassExpression.New(codeIncorrectParameterCount, "Incorrect parameter count in the call to native function '%s'")
 	errFunctionNotExists       = terror.ClassExpression.New(codeFunctionNotExists, "FUNCTION %s does not exist")
+	errIncorrectArgs           = terror.ClassExpression.New(codeIncorrectArgs, "Incorrect arguments to %s")
+	errWrongValueForType       = terror.ClassExpression.New(codeWrongValueForType, mysql.MySQLErrName[mysql.ErrWrongValueForType])
 )
 
 // Error codes.
@@ -41,6 +43,8 @@ const (
 	codeInvalidOperation        terror.ErrCode = 1
 	codeIncorrectParameterCount                = 1582
 	codeFunctionNotExists                      = 1305
+	codeIncorrectArgs                          = 1210
+	codeWrongValueForType                      = 1411
 )
 
 // EvalAstExpr evaluates ast expression directly.
@@ -515,6 +519,8 @@ func init() {
 	expressionMySQLErrCodes := map[terror.ErrCode]uint16{
 		codeIncorrectParameterCount: mysql.ErrWrongParamcountToNativeFct,
 		codeFunctionNotExists:       mysql.ErrSpDoesNotExist,
+		codeIncorrectArgs:           mysql.ErrWrongArguments,
+		codeWrongValueForType:       mysql.ErrWrongValueForType,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExpression] = expressionMySQLErrCodes
 }
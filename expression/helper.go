@@ -69,7 +69,11 @@ func getTimeValue(ctx context.Context, v interface{}, tp byte, fsp int) (d types
 	case string:
 		upperX := strings.ToUpper(x)
 		if upperX == CurrentTimestamp {
-			value.Time = types.FromGoTime(defaultTime)
+			t, err := types.RoundFrac(defaultTime, fsp)
+			if err != nil {
+				return d, errors.Trace(err)
+			}
+			value.Time = types.FromGoTime(t)
 		} else if upperX == ZeroTimestamp {
 			value, _ = types.ParseTimeFromNum(0, tp, fsp)
 		} else {
@@ -97,6 +101,9 @@ func getTimeValue(ctx context.Context, v interface{}, tp byte, fsp int) (d types
 		}
 	case *ast.FuncCallExpr:
 		if x.FnName.L == currentTimestampL {
+			if getCurrentTimeFsp(x) != fsp {
+				return d, errors.Trace(errDefaultValue)
+			}
 			d.SetString(CurrentTimestamp)
 			return d, nil
 		}
@@ -134,6 +141,34 @@ func IsCurrentTimeExpr(e ast.ExprNode) bool {
 	return x.FnName.L == currentTimestampL
 }
 
+// IsValidCurrentTimeExpr returns whether e is CurrentTimeExpr with the same fsp as the column.
+func IsValidCurrentTimeExpr(e ast.ExprNode, fsp int) bool {
+	x, ok := e.(*ast.FuncCallExpr)
+	if !ok || x.FnName.L != currentTimestampL {
+		return false
+	}
+	return getCurrentTimeFsp(x) == fsp
+}
+
+// getCurrentTimeFsp returns the fsp argument of CURRENT_TIMESTAMP(fsp),
+// or UnspecifiedFsp if the argument is not a valid integer literal.
+func getCurrentTimeFsp(x *ast.FuncCallExpr) int {
+	if len(x.Args) == 0 {
+		return types.DefaultFsp
+	}
+	v, ok := x.Args[0].(*ast.ValueExpr)
+	if !ok {
+		return types.UnspecifiedFsp
+	}
+	switch v.Kind() {
+	case types.KindInt64:
+		return int(v.GetInt64())
+	case types.KindUint64:
+		return int(v.GetUint64())
+	}
+	return types.UnspecifiedFsp
+}
+
 func getSystemTimestamp(ctx context.Context) (time.Time, error) {
 	value := time.Now()
 
@@ -141,6 +176,9 @@ func getSystemTimestamp(ctx context.Context) (time.Time, error) {
 		return value, nil
 	}
 
+	// The current timestamp is in the time zone of the session.
+	value = value.In(getTimeZone(ctx))
+
 	// check whether use timestamp variable
 	sessionVars := ctx.GetSessionVars()
 	val, err := varsutil.GetSessionSystemVar(sessionVars, "timestamp")
@@ -155,7 +193,7 @@ func getSystemTimestamp(ctx context.Context) (time.Time, error) {
 		if timestamp <= 0 {
 			return value, nil
 		}
-		return time.Unix(timestamp, 0), nil
+		return time.Unix(timestamp, 0).In(getTimeZone(ctx)), nil
 	}
 	return value, nil
 }
@@ -113,3 +113,17 @@ func (s *testExpressionSuite) TestIsCurrentTimeExpr(c *C) {
 	v = IsCurrentTimeExpr(&ast.FuncCallExpr{FnName: model.NewCIStr("CURRENT_TIMESTAMP")})
 	c.Assert(v, IsTrue)
 }
+
+func (s *testExpressionSuite) TestIsValidCurrentTimeExpr(c *C) {
+	defer testleak.AfterTest(c)()
+	v := IsValidCurrentTimeExpr(ast.NewValueExpr("abc"), 0)
+	c.Assert(v, IsFalse)
+
+	now := &ast.FuncCallExpr{FnName: model.NewCIStr("CURRENT_TIMESTAMP")}
+	c.Assert(IsValidCurrentTimeExpr(now, 0), IsTrue)
+	c.Assert(IsValidCurrentTimeExpr(now, 3), IsFalse)
+
+	now.Args = []ast.ExprNode{ast.NewValueExpr(3)}
+	c.Assert(IsValidCurrentTimeExpr(now, 3), IsTrue)
+	c.Assert(IsValidCurrentTimeExpr(now, 0), IsFalse)
+}
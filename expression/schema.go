@@ -72,11 +72,15 @@ func (s *Schema) Clone() *Schema {
 }
 
 // FindColumn finds an Column from schema for a ast.ColumnName. It compares the db/table/column names.
-// If there are more than one result, it will raise ambiguous error.
+// If there are more than one result, it will raise ambiguous error. Hidden columns can only be found by
+// a qualified name.
 func (s *Schema) FindColumn(astCol *ast.ColumnName) (*Column, error) {
 	dbName, tblName, colName := astCol.Schema, astCol.Table, astCol.Name
 	idx := -1
 	for i, col := range s.Columns {
+		if col.IsHidden && tblName.L == "" {
+			continue
+		}
 		if (dbName.L == "" || dbName.L == col.DBName.L) &&
 			(tblName.L == "" || tblName.L == col.TblName.L) &&
 			(colName.L == col.ColName.L) {
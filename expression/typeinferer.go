@@ -111,6 +111,8 @@ func (v *typeInferrer) Leave(in ast.Node) (out ast.Node, ok bool) {
 			v.err = err
 		}
 		x.Type.Collate = cln
+	case *ast.WindowFuncExpr:
+		v.windowFunc(x)
 		// TODO: handle all expression types.
 	}
 	return in, true
@@ -151,6 +153,23 @@ func (v *typeInferrer) aggregateFunc(x *ast.AggregateFuncExpr) {
 		}
 		ft.Collate = cln
 		x.SetType(ft)
+	case ast.AggFuncJSONArrayagg, ast.AggFuncJSONObjectagg:
+		x.SetType(types.NewFieldType(mysql.TypeJSON))
+	}
+}
+
+func (v *typeInferrer) windowFunc(x *ast.WindowFuncExpr) {
+	switch x.F {
+	case ast.WindowFuncRowNumber, ast.WindowFuncRank:
+		ft := types.NewFieldType(mysql.TypeLonglong)
+		ft.Flen = 21
+		types.SetBinChsClnFlag(ft)
+		x.SetType(ft)
+	default:
+		// The aggregate functions over windows have the same types as the aggregate functions.
+		agg := &ast.AggregateFuncExpr{F: x.F, Args: x.Args}
+		v.aggregateFunc(agg)
+		x.SetType(agg.GetType())
 	}
 }
 
@@ -375,9 +394,10 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		ast.FoundRows, ast.Length, ast.Extract, ast.Locate, ast.UnixTimestamp, ast.Quarter, ast.IsIPv4, ast.ToDays,
 		ast.ToSeconds, ast.Strcmp, ast.IsNull, ast.BitLength, ast.CharLength, ast.CRC32, ast.TimestampDiff,
 		ast.Sign, ast.IsIPv6, ast.Ord, ast.Instr, ast.BitCount, ast.TimeToSec, ast.FindInSet, ast.Field,
-		ast.GetLock, ast.ReleaseLock, ast.Interval, ast.Position, ast.PeriodAdd, ast.PeriodDiff, ast.IsIPv4Mapped, ast.UncompressedLength:
+		ast.GetLock, ast.ReleaseLock, ast.IsFreeLock, ast.ReleaseAllLocks, ast.Interval, ast.Position, ast.PeriodAdd, ast.PeriodDiff, ast.IsIPv4Mapped, ast.UncompressedLength,
+		ast.JSONContains, ast.JSONMemberOf, ast.TiDBIsDDLOwner:
 		tp = types.NewFieldType(mysql.TypeLonglong)
-	case ast.ConnectionID, ast.InetAton:
+	case ast.ConnectionID, ast.InetAton, ast.IsUsedLock, ast.TiDBCurrentTSO:
 		tp = types.NewFieldType(mysql.TypeLonglong)
 		tp.Flag |= mysql.UnsignedFlag
 	// time related
@@ -399,16 +419,23 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		tp = types.NewFieldType(mysql.TypeVarString)
 		chs = v.defaultCharset
 		tp.Flen = 40
+	case ast.JSONExtract, ast.JSONSet, ast.JSONObject, ast.JSONArray:
+		tp = types.NewFieldType(mysql.TypeJSON)
+	case ast.TiDBDigest:
+		tp = types.NewFieldType(mysql.TypeVarString)
+		chs = v.defaultCharset
+		tp.Flen = 64
 	case ast.DayName, ast.Version, ast.Database, ast.User, ast.CurrentUser, ast.Schema,
 		ast.Concat, ast.ConcatWS, ast.Left, ast.Right, ast.Lcase, ast.Lower, ast.Repeat,
 		ast.Replace, ast.Ucase, ast.Upper, ast.Convert, ast.Substring, ast.Elt,
 		ast.SubstringIndex, ast.Trim, ast.LTrim, ast.RTrim, ast.Reverse, ast.Hex, ast.Unhex,
-		ast.DateFormat, ast.Rpad, ast.Lpad, ast.CharFunc, ast.Conv, ast.MakeSet, ast.Oct, ast.UUID,
+		ast.DateFormat, ast.Rpad, ast.Lpad, ast.CharFunc, ast.Conv, ast.MakeSet, ast.Oct, ast.UUID, ast.BinToUUID,
 		ast.InsertFunc, ast.Bin, ast.Quote, ast.Format, ast.FromBase64, ast.ToBase64, ast.ExportSet,
-		ast.AesEncrypt, ast.AesDecrypt, ast.SHA2, ast.InetNtoa, ast.Inet6Aton:
+		ast.AesEncrypt, ast.AesDecrypt, ast.SHA2, ast.InetNtoa, ast.Inet6Aton, ast.TiDBNormalize, ast.TiDBDecodeKey,
+		ast.JSONUnquote, ast.TiDBVersion:
 		tp = types.NewFieldType(mysql.TypeVarString)
 		chs = v.defaultCharset
-	case ast.RandomBytes:
+	case ast.RandomBytes, ast.UUIDToBin, ast.WeightString:
 		tp = types.NewFieldType(mysql.TypeVarString)
 	case ast.If:
 		// TODO: fix this
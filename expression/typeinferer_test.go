@@ -208,6 +208,8 @@ func (ts *testTypeInferrerSuite) TestInferType(c *C) {
 		{"ltrim(' TiDB')", mysql.TypeVarString, charset.CharsetUTF8, 0},
 		{"rtrim('TiDB ')", mysql.TypeVarString, charset.CharsetUTF8, 0},
 		{"connection_id()", mysql.TypeLonglong, charset.CharsetBin, mysql.UnsignedFlag | mysql.BinaryFlag},
+		{"tidb_current_tso()", mysql.TypeLonglong, charset.CharsetBin, mysql.UnsignedFlag | mysql.BinaryFlag},
+		{"tidb_is_ddl_owner()", mysql.TypeLonglong, charset.CharsetBin, mysql.BinaryFlag},
 		{"if(1>2, 2, 3)", mysql.TypeLonglong, charset.CharsetBin, mysql.BinaryFlag},
 		{"case c_int when null then 2 when 2 then 1.1 else 1 END", mysql.TypeNewDecimal, charset.CharsetBin, mysql.BinaryFlag},
 		{"case c_int when null then 2 when 2 then 'tidb' else 1.1 END", mysql.TypeVarString, charset.CharsetUTF8, 0},
@@ -288,10 +290,17 @@ func (ts *testTypeInferrerSuite) TestInferType(c *C) {
 		{`sha1(123)`, mysql.TypeVarString, charset.CharsetUTF8, 0},
 		{`sha(123)`, mysql.TypeVarString, charset.CharsetUTF8, 0},
 		{`sha2(123, 256)`, mysql.TypeVarString, charset.CharsetUTF8, 0},
+		{`tidb_digest('select 1')`, mysql.TypeVarString, charset.CharsetUTF8, 0},
+		{`tidb_normalize('select 1')`, mysql.TypeVarString, charset.CharsetUTF8, 0},
+		{`tidb_decode_key('7480')`, mysql.TypeVarString, charset.CharsetUTF8, 0},
+		{`tidb_version()`, mysql.TypeVarString, charset.CharsetUTF8, 0},
 		{`uuid()`, mysql.TypeVarString, charset.CharsetUTF8, 0},
+		{`uuid_to_bin(uuid(), 1)`, mysql.TypeVarString, charset.CharsetBin, mysql.BinaryFlag},
+		{`bin_to_uuid(uuid_to_bin(uuid()))`, mysql.TypeVarString, charset.CharsetUTF8, 0},
 		{`from_base64('YWJj')`, mysql.TypeVarString, charset.CharsetUTF8, 0},
 		{`to_base64('abc')`, mysql.TypeVarString, charset.CharsetUTF8, 0},
 		{`random_bytes(32)`, mysql.TypeVarString, charset.CharsetBin, mysql.BinaryFlag},
+		{`weight_string('abc' as char(5))`, mysql.TypeVarString, charset.CharsetBin, mysql.BinaryFlag},
 		{`coalesce(null, 0)`, mysql.TypeLonglong, charset.CharsetBin, mysql.BinaryFlag},
 		{`coalesce(null, 0.1)`, mysql.TypeNewDecimal, charset.CharsetBin, mysql.BinaryFlag},
 		{`coalesce(1, "1" + 1)`, mysql.TypeDouble, charset.CharsetBin, mysql.BinaryFlag},
@@ -14,9 +14,6 @@
 package expression
 
 import (
-	"strconv"
-	"strings"
-	"time"
 	"unicode"
 
 	"github.com/juju/errors"
@@ -106,13 +103,27 @@ func calculateSum(sc *variable.StatementContext, sum, v types.Datum) (data types
 	switch v.Kind() {
 	case types.KindNull:
 	case types.KindInt64, types.KindUint64:
+		if sum.Kind() == types.KindMysqlDecimal {
+			var d types.MyDecimal
+			if v.Kind() == types.KindInt64 {
+				d.FromInt(v.GetInt64())
+			} else {
+				d.FromUint(v.GetUint64())
+			}
+			return sum, errors.Trace(addToDecimalSum(sum.GetMysqlDecimal(), &d))
+		}
 		var d *types.MyDecimal
 		d, err = v.ToDecimal(sc)
 		if err == nil {
 			data = types.NewDecimalDatum(d)
 		}
 	case types.KindMysqlDecimal:
-		data = v
+		if sum.Kind() == types.KindMysqlDecimal {
+			return sum, errors.Trace(addToDecimalSum(sum.GetMysqlDecimal(), v.GetMysqlDecimal()))
+		}
+		// Copy the decimal so the sum can be updated in place, v may be shared with the row.
+		d := *v.GetMysqlDecimal()
+		data = types.NewDecimalDatum(&d)
 	default:
 		var f float64
 		f, err = v.ToFloat64(sc)
@@ -137,6 +148,15 @@ func calculateSum(sc *variable.StatementContext, sum, v types.Datum) (data types
 	}
 }
 
+// addToDecimalSum adds v to the decimal sum in place. The sum is owned by the aggregate
+// context, updating it in place avoids allocating a new decimal for every row.
+func addToDecimalSum(sum, v *types.MyDecimal) error {
+	var to types.MyDecimal
+	err := types.DecimalAdd(sum, v, &to)
+	*sum = to
+	return err
+}
+
 // getValidPrefix gets a prefix of string which can parsed to a number with base. the minimum base is 2 and the maximum is 36.
 func getValidPrefix(s string, base int64) string {
 	var (
@@ -267,20 +287,6 @@ func ConvertCol2CorCol(cond Expression, corCols []*CorrelatedColumn, outerSchema
 	return cond
 }
 
-// timeZone2Duration converts timezone whose format should satisfy the regular condition
-// `(^(+|-)(0?[0-9]|1[0-2]):[0-5]?\d$)|(^+13:00$)` to time.Duration.
-func timeZone2Duration(tz string) time.Duration {
-	sign := 1
-	if strings.HasPrefix(tz, "-") {
-		sign = -1
-	}
-
-	i := strings.Index(tz, ":")
-	h, _ := strconv.Atoi(tz[1:i])
-	m, _ := strconv.Atoi(tz[i+1:])
-	return time.Duration(sign) * (time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
-}
-
 var oppositeOp = map[string]string{
 	ast.LT: ast.GE,
 	ast.GE: ast.LT,
@@ -17,6 +17,7 @@ import (
 	"github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -48,6 +49,23 @@ func (s *testUtilSuite) TestDistinct(c *check.C) {
 	}
 }
 
+func (s *testUtilSuite) TestCalculateSum(c *check.C) {
+	defer testleak.AfterTest(c)()
+	sc := new(variable.StatementContext)
+	first := types.NewDecimalDatum(types.NewDecFromStringForTest("1.5"))
+	values := []types.Datum{first, types.NewIntDatum(2), types.NewUintDatum(3), types.NewDecimalDatum(types.NewDecFromStringForTest("-0.25"))}
+	var sum types.Datum
+	for _, v := range values {
+		var err error
+		sum, err = calculateSum(sc, sum, v)
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(sum.Kind(), check.Equals, types.KindMysqlDecimal)
+	c.Assert(sum.GetMysqlDecimal().String(), check.Equals, "6.25")
+	// The values are not changed when the sum is updated in place.
+	c.Assert(first.GetMysqlDecimal().String(), check.Equals, "1.5")
+}
+
 func (s *testUtilSuite) TestSubstituteCorCol2Constant(c *check.C) {
 	defer testleak.AfterTest(c)()
 	ctx := mock.NewContext()
@@ -0,0 +1,282 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// The VecEvalXXX functions evaluate an expression over a batch of rows. They write the results of rows[i] to vals[i]
+// and nulls[i], so vals and nulls must be as long as rows. Columns and constants are read without calling EvalXXX
+// for every row, and the built-in functions implementing the vecXXXBuiltinFunc interfaces evaluate their arguments
+// batch by batch too. The other expressions are evaluated row by row, so the results are always the same as EvalXXX.
+
+// vecIntBuiltinFunc is implemented by the built-in functions which evaluate the int results of a batch of rows.
+type vecIntBuiltinFunc interface {
+	vecEvalInt(rows [][]types.Datum, vals []int64, nulls []bool) error
+}
+
+// VecEval evaluates the expression over the rows.
+func VecEval(expr Expression, rows [][]types.Datum, vals []types.Datum) error {
+	switch x := expr.(type) {
+	case *Column:
+		for i, row := range rows {
+			vals[i] = row[x.Index]
+		}
+		return nil
+	case *Constant, *CorrelatedColumn:
+		val, err := expr.Eval(nil)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := range rows {
+			vals[i] = val
+		}
+		return nil
+	}
+	var err error
+	for i, row := range rows {
+		vals[i], err = expr.Eval(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// VecEvalInt evaluates the int results of the expression over the rows.
+func VecEvalInt(expr Expression, rows [][]types.Datum, sc *variable.StatementContext, vals []int64, nulls []bool) error {
+	switch x := expr.(type) {
+	case *Column:
+		if x.RetType.ToClass() == types.ClassInt {
+			for i, row := range rows {
+				d := row[x.Index]
+				vals[i], nulls[i] = 0, d.IsNull()
+				if !nulls[i] {
+					vals[i] = d.GetInt64()
+				}
+			}
+			return nil
+		}
+	case *Constant, *CorrelatedColumn:
+		val, isNull, err := expr.EvalInt(nil, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := range rows {
+			vals[i], nulls[i] = val, isNull
+		}
+		return nil
+	case *ScalarFunction:
+		if f, ok := x.Function.(vecIntBuiltinFunc); ok {
+			return errors.Trace(f.vecEvalInt(rows, vals, nulls))
+		}
+	}
+	var err error
+	for i, row := range rows {
+		vals[i], nulls[i], err = expr.EvalInt(row, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// VecEvalReal evaluates the real results of the expression over the rows.
+func VecEvalReal(expr Expression, rows [][]types.Datum, sc *variable.StatementContext, vals []float64, nulls []bool) error {
+	switch x := expr.(type) {
+	case *Column:
+		if x.RetType.ToClass() == types.ClassReal {
+			for i, row := range rows {
+				d := row[x.Index]
+				vals[i], nulls[i] = 0, d.IsNull()
+				if !nulls[i] {
+					vals[i] = d.GetFloat64()
+				}
+			}
+			return nil
+		}
+	case *Constant, *CorrelatedColumn:
+		val, isNull, err := expr.EvalReal(nil, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := range rows {
+			vals[i], nulls[i] = val, isNull
+		}
+		return nil
+	}
+	var err error
+	for i, row := range rows {
+		vals[i], nulls[i], err = expr.EvalReal(row, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// VecEvalString evaluates the string results of the expression over the rows.
+func VecEvalString(expr Expression, rows [][]types.Datum, sc *variable.StatementContext, vals []string, nulls []bool) error {
+	switch expr.(type) {
+	case *Constant, *CorrelatedColumn:
+		val, isNull, err := expr.EvalString(nil, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := range rows {
+			vals[i], nulls[i] = val, isNull
+		}
+		return nil
+	}
+	var err error
+	for i, row := range rows {
+		vals[i], nulls[i], err = expr.EvalString(row, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// VecEvalDecimal evaluates the decimal results of the expression over the rows.
+func VecEvalDecimal(expr Expression, rows [][]types.Datum, sc *variable.StatementContext, vals []*types.MyDecimal, nulls []bool) error {
+	switch x := expr.(type) {
+	case *Column:
+		if x.RetType.ToClass() == types.ClassDecimal {
+			for i, row := range rows {
+				d := row[x.Index]
+				vals[i], nulls[i] = nil, d.IsNull()
+				if !nulls[i] {
+					vals[i] = d.GetMysqlDecimal()
+				}
+			}
+			return nil
+		}
+	case *Constant, *CorrelatedColumn:
+		val, isNull, err := expr.EvalDecimal(nil, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := range rows {
+			vals[i], nulls[i] = val, isNull
+		}
+		return nil
+	}
+	var err error
+	for i, row := range rows {
+		vals[i], nulls[i], err = expr.EvalDecimal(row, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// VecEvalTime evaluates the DATE/DATETIME/TIMESTAMP results of the expression over the rows.
+func VecEvalTime(expr Expression, rows [][]types.Datum, sc *variable.StatementContext, vals []types.Time, nulls []bool) error {
+	switch expr.(type) {
+	case *Constant, *CorrelatedColumn:
+		val, isNull, err := expr.EvalTime(nil, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := range rows {
+			vals[i], nulls[i] = val, isNull
+		}
+		return nil
+	}
+	var err error
+	for i, row := range rows {
+		vals[i], nulls[i], err = expr.EvalTime(row, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// VecEvalDuration evaluates the duration results of the expression over the rows.
+func VecEvalDuration(expr Expression, rows [][]types.Datum, sc *variable.StatementContext, vals []types.Duration, nulls []bool) error {
+	switch expr.(type) {
+	case *Constant, *CorrelatedColumn:
+		val, isNull, err := expr.EvalDuration(nil, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := range rows {
+			vals[i], nulls[i] = val, isNull
+		}
+		return nil
+	}
+	var err error
+	for i, row := range rows {
+		vals[i], nulls[i], err = expr.EvalDuration(row, sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// VecEvalBool evaluates the CNF expressions over the rows like EvalBool, selected[i] is set to whether rows[i] passes
+// all the expressions. selected must be as long as rows.
+func VecEvalBool(exprList CNFExprs, rows [][]types.Datum, ctx context.Context, selected []bool) error {
+	for i := range selected {
+		selected[i] = true
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	var (
+		vals  []int64
+		nulls []bool
+	)
+	for _, expr := range exprList {
+		if f, ok := expr.(*ScalarFunction); ok {
+			if _, ok = f.Function.(vecIntBuiltinFunc); ok {
+				if vals == nil {
+					vals, nulls = make([]int64, len(rows)), make([]bool, len(rows))
+				}
+				if err := VecEvalInt(expr, rows, sc, vals, nulls); err != nil {
+					return errors.Trace(err)
+				}
+				for i := range rows {
+					selected[i] = selected[i] && !nulls[i] && vals[i] != 0
+				}
+				continue
+			}
+		}
+		for i, row := range rows {
+			if !selected[i] {
+				continue
+			}
+			data, err := expr.Eval(row)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if data.IsNull() {
+				selected[i] = false
+				continue
+			}
+			b, err := data.ToBool(sc)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			selected[i] = b != 0
+		}
+	}
+	return nil
+}
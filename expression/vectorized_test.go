@@ -0,0 +1,110 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func (s *testEvaluatorSuite) TestVecEval(c *C) {
+	defer testleak.AfterTest(c)()
+	sc := s.ctx.GetSessionVars().StmtCtx
+	intCol := &Column{Index: 0, RetType: types.NewFieldType(mysql.TypeLonglong)}
+	realCol := &Column{Index: 1, RetType: types.NewFieldType(mysql.TypeDouble)}
+	strCol := &Column{Index: 2, RetType: types.NewFieldType(mysql.TypeVarchar)}
+	rows := make([][]types.Datum, 0, 100)
+	for i := 0; i < 100; i++ {
+		row := types.MakeDatums(i%7, float64(i)/3, "abc")
+		if i%5 == 0 {
+			row[0].SetNull()
+			row[1].SetNull()
+		}
+		rows = append(rows, row)
+	}
+	newFunc := func(name string, args ...Expression) Expression {
+		f, err := NewFunction(s.ctx, name, types.NewFieldType(mysql.TypeLonglong), args...)
+		c.Assert(err, IsNil)
+		return f
+	}
+	exprs := []Expression{
+		intCol,
+		realCol,
+		strCol,
+		newLonglong(3),
+		newFunc(ast.LT, intCol, newLonglong(3)),
+		newFunc(ast.NullEQ, intCol, newLonglong(3)),
+		newFunc(ast.GE, realCol, &Constant{Value: types.NewFloat64Datum(10), RetType: types.NewFieldType(mysql.TypeDouble)}),
+		newFunc(ast.EQ, newFunc(ast.LT, intCol, newLonglong(5)), newFunc(ast.GT, realCol, realCol)),
+		newFunc(ast.Plus, intCol, newLonglong(1)),
+		newFunc(ast.Length, strCol),
+	}
+	datums := make([]types.Datum, len(rows))
+	ints, reals, strs := make([]int64, len(rows)), make([]float64, len(rows)), make([]string, len(rows))
+	nulls := make([]bool, len(rows))
+	for _, expr := range exprs {
+		c.Assert(VecEval(expr, rows, datums), IsNil)
+		for i, row := range rows {
+			d, err := expr.Eval(row)
+			c.Assert(err, IsNil)
+			c.Assert(datums[i], DeepEquals, d, Commentf("%s, row %d", expr, i))
+		}
+		switch expr.GetType().ToClass() {
+		case types.ClassInt:
+			c.Assert(VecEvalInt(expr, rows, sc, ints, nulls), IsNil)
+			for i, row := range rows {
+				val, isNull, err := expr.EvalInt(row, sc)
+				c.Assert(err, IsNil)
+				c.Assert(nulls[i], Equals, isNull, Commentf("%s, row %d", expr, i))
+				c.Assert(ints[i], Equals, val, Commentf("%s, row %d", expr, i))
+			}
+		case types.ClassReal:
+			c.Assert(VecEvalReal(expr, rows, sc, reals, nulls), IsNil)
+			for i, row := range rows {
+				val, isNull, err := expr.EvalReal(row, sc)
+				c.Assert(err, IsNil)
+				c.Assert(nulls[i], Equals, isNull, Commentf("%s, row %d", expr, i))
+				c.Assert(reals[i], Equals, val, Commentf("%s, row %d", expr, i))
+			}
+		case types.ClassString:
+			c.Assert(VecEvalString(expr, rows, sc, strs, nulls), IsNil)
+			for i, row := range rows {
+				val, isNull, err := expr.EvalString(row, sc)
+				c.Assert(err, IsNil)
+				c.Assert(nulls[i], Equals, isNull, Commentf("%s, row %d", expr, i))
+				c.Assert(strs[i], Equals, val, Commentf("%s, row %d", expr, i))
+			}
+		}
+	}
+
+	filters := []CNFExprs{
+		nil,
+		{exprs[4]},
+		{exprs[4], exprs[6]},
+		{exprs[5], exprs[0]},
+		{exprs[8], exprs[7]},
+	}
+	selected := make([]bool, len(rows))
+	for _, filter := range filters {
+		c.Assert(VecEvalBool(filter, rows, s.ctx, selected), IsNil)
+		for i, row := range rows {
+			matched, err := EvalBool(filter, row, s.ctx)
+			c.Assert(err, IsNil)
+			c.Assert(selected[i], Equals, matched, Commentf("%s, row %d", filter, i))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// WindowFunc stands for a window function, it's evaluated over the rows in the window of every row instead of
+// being evaluated on a single row. The windows are computed by the window executor, so WindowFunc only describes
+// the function.
+type WindowFunc struct {
+	// Name is either ast.WindowFuncRowNumber, ast.WindowFuncRank or the name of an aggregate function.
+	Name    string
+	Args    []Expression
+	RetType *types.FieldType
+}
+
+// NewWindowFunc creates a new WindowFunc.
+func NewWindowFunc(name string, args []Expression, retType *types.FieldType) *WindowFunc {
+	return &WindowFunc{Name: name, Args: args, RetType: retType}
+}
+
+// IsAggregate checks if the window function is an aggregate function over the window.
+func (wf *WindowFunc) IsAggregate() bool {
+	return wf.Name != ast.WindowFuncRowNumber && wf.Name != ast.WindowFuncRank
+}
+
+// NewAggFunction creates the aggregate function computing an aggregate window function.
+func (wf *WindowFunc) NewAggFunction() AggregationFunction {
+	return NewAggFunction(wf.Name, wf.Args, false)
+}
+
+// GetType gets the field type of the window function.
+func (wf *WindowFunc) GetType() *types.FieldType {
+	return wf.RetType
+}
+
+// Equal checks whether two window functions are equal.
+func (wf *WindowFunc) Equal(b *WindowFunc, ctx context.Context) bool {
+	if wf.Name != b.Name || len(wf.Args) != len(b.Args) {
+		return false
+	}
+	for i, arg := range wf.Args {
+		if !arg.Equal(b.Args[i], ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone copies a window function.
+func (wf *WindowFunc) Clone() *WindowFunc {
+	nf := *wf
+	nf.Args = make([]Expression, 0, len(wf.Args))
+	for _, arg := range wf.Args {
+		nf.Args = append(nf.Args, arg.Clone())
+	}
+	return &nf
+}
+
+// String implements fmt.Stringer interface.
+func (wf *WindowFunc) String() string {
+	result := wf.Name + "("
+	for i, arg := range wf.Args {
+		result += arg.String()
+		if i+1 != len(wf.Args) {
+			result += ", "
+		}
+	}
+	result += ")"
+	return result
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (wf *WindowFunc) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(fmt.Sprintf("\"%s\"", wf))
+	return buffer.Bytes(), nil
+}
@@ -20,14 +20,17 @@ import (
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
-	"github.com/pingcap/tidb/table"
-	"github.com/pingcap/tidb/table/tables"
 )
 
 // Builder builds a new InfoSchema.
 type Builder struct {
 	is     *infoSchema
 	handle *Handle
+	// copiedSchemas and copiedBuckets record the schemas and buckets that have been copied from the old InfoSchema,
+	// the old InfoSchema must be read-only and each of them only needs to be copied once no matter how many diffs
+	// are applied.
+	copiedSchemas map[string]struct{}
+	copiedBuckets map[int]struct{}
 }
 
 // ApplyDiff applies SchemaDiff to the new InfoSchema.
@@ -58,22 +61,22 @@ func (b *Builder) ApplyDiff(m *meta.Meta, diff *model.SchemaDiff) error {
 		newTableID = diff.TableID
 	}
 	b.copySchemaTables(roDBInfo.Name.L)
-	b.copySortedTables(oldTableID, newTableID)
 
 	// We try to reuse the old allocator, so the cached auto ID can be reused.
 	var alloc autoid.Allocator
 	if tableIDIsValid(oldTableID) {
 		if oldTableID == newTableID {
-			alloc, _ = b.is.AllocByID(oldTableID)
+			alloc = b.cachedAlloc(oldTableID)
 		}
 		if diff.Type == model.ActionRenameTable {
 			oldRoDBInfo, ok := b.is.SchemaByID(diff.OldSchemaID)
 			if !ok {
 				return ErrDatabaseNotExists
 			}
-			b.applyDropTable(oldRoDBInfo, oldTableID)
+			b.copySchemaTables(oldRoDBInfo.Name.L)
+			b.applyDropTable(oldRoDBInfo.Name.L, oldTableID)
 		} else {
-			b.applyDropTable(roDBInfo, oldTableID)
+			b.applyDropTable(roDBInfo.Name.L, oldTableID)
 		}
 	}
 	if tableIDIsValid(newTableID) {
@@ -86,22 +89,32 @@ func (b *Builder) ApplyDiff(m *meta.Meta, diff *model.SchemaDiff) error {
 	return nil
 }
 
-// copySortedTables copies sortedTables for old table and new table for later modification.
-func (b *Builder) copySortedTables(oldTableID, newTableID int64) {
-	buckets := b.is.sortedTablesBuckets
-	if tableIDIsValid(oldTableID) {
-		bucketIdx := tableBucketIdx(oldTableID)
-		oldSortedTables := buckets[bucketIdx]
-		newSortedTables := make(sortedTables, len(oldSortedTables))
-		copy(newSortedTables, oldSortedTables)
-		buckets[bucketIdx] = newSortedTables
-	}
-	if tableIDIsValid(newTableID) && newTableID != oldTableID {
-		oldSortedTables := buckets[tableBucketIdx(newTableID)]
-		newSortedTables := make(sortedTables, len(oldSortedTables), len(oldSortedTables)+1)
-		copy(newSortedTables, oldSortedTables)
-		buckets[tableBucketIdx(newTableID)] = newSortedTables
+// cachedAlloc returns the allocator of the table if it's in the table cache. It doesn't load the table,
+// the allocator is only an optimization.
+func (b *Builder) cachedAlloc(tableID int64) autoid.Allocator {
+	item, ok := b.is.tableItemByID(tableID)
+	if !ok {
+		return nil
+	}
+	if item.tbl != nil {
+		return item.tbl.Allocator()
+	}
+	if tbl, ok := b.handle.tableCache.peek(item.cacheKey()); ok {
+		return tbl.Allocator()
+	}
+	return nil
+}
+
+// copySortedTables copies the bucket of sortedTables for later modification.
+func (b *Builder) copySortedTables(bucketIdx int) {
+	if _, ok := b.copiedBuckets[bucketIdx]; ok {
+		return
 	}
+	oldSortedTables := b.is.sortedTablesBuckets[bucketIdx]
+	newSortedTables := make(sortedTables, len(oldSortedTables), len(oldSortedTables)+1)
+	copy(newSortedTables, oldSortedTables)
+	b.is.sortedTablesBuckets[bucketIdx] = newSortedTables
+	b.copiedBuckets[bucketIdx] = struct{}{}
 }
 
 func (b *Builder) applyCreateSchema(m *meta.Meta, diff *model.SchemaDiff) error {
@@ -114,7 +127,9 @@ func (b *Builder) applyCreateSchema(m *meta.Meta, diff *model.SchemaDiff) error
 		// full load.
 		return ErrDatabaseNotExists
 	}
-	b.is.schemaMap[di.Name.L] = &schemaTables{dbInfo: di, tables: make(map[string]table.Table)}
+	b.is.schemaMap[di.Name.L] = &schemaTables{dbInfo: di, tables: make(map[string]*tableItem)}
+	// The new schemaTables is owned by the builder.
+	b.copiedSchemas[di.Name.L] = struct{}{}
 	return nil
 }
 
@@ -123,9 +138,11 @@ func (b *Builder) applyDropSchema(schemaID int64) {
 	if !ok {
 		return
 	}
+	schTbls := b.is.schemaMap[di.Name.L]
 	delete(b.is.schemaMap, di.Name.L)
-	for _, tbl := range di.Tables {
-		b.applyDropTable(di, tbl.ID)
+	delete(b.copiedSchemas, di.Name.L)
+	for _, item := range schTbls.tables {
+		b.removeSortedTable(item.id)
 	}
 }
 
@@ -139,67 +156,82 @@ func (b *Builder) applyCreateTable(m *meta.Meta, roDBInfo *model.DBInfo, tableID
 		// full load.
 		return ErrTableNotExists
 	}
-	if alloc == nil {
-		schemaID := roDBInfo.ID
-		if tblInfo.OldSchemaID != 0 {
-			schemaID = tblInfo.OldSchemaID
-		}
-		alloc = autoid.NewAllocator(b.handle.store, schemaID)
-	}
-	tbl, err := tables.TableFromMeta(alloc, tblInfo)
+	tbl, err := b.handle.newTable(roDBInfo.ID, tblInfo, alloc)
 	if err != nil {
 		return errors.Trace(err)
 	}
+	item := b.newTableItem(roDBInfo.ID, tblInfo)
+	// The table changed by DDL is likely to be used soon.
+	b.handle.tableCache.put(item.cacheKey(), tbl)
 	tableNames := b.is.schemaMap[roDBInfo.Name.L]
-	tableNames.tables[tblInfo.Name.L] = tbl
+	tableNames.tables[tblInfo.Name.L] = item
 	bucketIdx := tableBucketIdx(tableID)
+	b.copySortedTables(bucketIdx)
 	sortedTables := b.is.sortedTablesBuckets[bucketIdx]
-	sortedTables = append(sortedTables, tbl)
+	sortedTables = append(sortedTables, item)
 	sort.Sort(sortedTables)
 	b.is.sortedTablesBuckets[bucketIdx] = sortedTables
+	return nil
+}
 
-	newTbl, ok := b.is.TableByID(tableID)
-	if ok {
-		roDBInfo.Tables = append(roDBInfo.Tables, newTbl.Meta())
+func (b *Builder) newTableItem(dbID int64, tblInfo *model.TableInfo) *tableItem {
+	return &tableItem{
+		id:      tblInfo.ID,
+		name:    tblInfo.Name,
+		dbID:    dbID,
+		version: b.is.schemaMetaVersion,
 	}
-	return nil
 }
 
-func (b *Builder) applyDropTable(roDBInfo *model.DBInfo, tableID int64) {
-	bucketIdx := tableBucketIdx(tableID)
-	sortedTables := b.is.sortedTablesBuckets[bucketIdx]
-	idx := sortedTables.searchTable(tableID)
-	if idx == -1 {
+// applyDropTable drops the table from the InfoSchema, the schema must have been copied by copySchemaTables.
+func (b *Builder) applyDropTable(dbName string, tableID int64) {
+	item, ok := b.is.tableItemByID(tableID)
+	if !ok {
 		return
 	}
-	if tableNames, ok := b.is.schemaMap[roDBInfo.Name.L]; ok {
-		delete(tableNames.tables, sortedTables[idx].Meta().Name.L)
+	if tableNames, ok := b.is.schemaMap[dbName]; ok {
+		delete(tableNames.tables, item.name.L)
 	}
-	// Remove the table in sorted table slice.
-	b.is.sortedTablesBuckets[bucketIdx] = append(sortedTables[0:idx], sortedTables[idx+1:]...)
+	b.removeSortedTable(tableID)
+}
 
-	// The old DBInfo still holds a reference to old table info, we need to remove it.
-	for i, tblInfo := range roDBInfo.Tables {
-		if tblInfo.ID == tableID {
-			if i == len(roDBInfo.Tables)-1 {
-				roDBInfo.Tables = roDBInfo.Tables[:i]
-			} else {
-				roDBInfo.Tables = append(roDBInfo.Tables[:i], roDBInfo.Tables[i+1:]...)
-			}
-			break
-		}
+// removeSortedTable removes the table in sorted table slice.
+func (b *Builder) removeSortedTable(tableID int64) {
+	bucketIdx := tableBucketIdx(tableID)
+	idx := b.is.sortedTablesBuckets[bucketIdx].searchTable(tableID)
+	if idx == -1 {
+		return
 	}
+	b.copySortedTables(bucketIdx)
+	sortedTables := b.is.sortedTablesBuckets[bucketIdx]
+	b.is.sortedTablesBuckets[bucketIdx] = append(sortedTables[:idx], sortedTables[idx+1:]...)
 }
 
-// InitWithOldInfoSchema initializes an empty new InfoSchema by copies all the data from old InfoSchema.
-func (b *Builder) InitWithOldInfoSchema() *Builder {
-	oldIS := b.handle.Get().(*infoSchema)
+// InitWithOldInfoSchema initializes an empty new InfoSchema by copies all the data from old InfoSchema,
+// the old InfoSchema isn't modified when diffs are applied to the new one.
+func (b *Builder) InitWithOldInfoSchema(oldSchema InfoSchema) *Builder {
+	oldIS := oldSchema.(*infoSchema)
 	b.is.schemaMetaVersion = oldIS.schemaMetaVersion
 	b.copySchemasMap(oldIS)
 	copy(b.is.sortedTablesBuckets, oldIS.sortedTablesBuckets)
+	if b.infoSchemaDBOfOtherHandle() {
+		// The old InfoSchema is built for another handle, like the one of a snapshot.
+		b.createSchemaTablesForInfoSchemaDB()
+	}
 	return b
 }
 
+// infoSchemaDBOfOtherHandle checks whether the information_schema tables read the InfoSchema from
+// another handle.
+func (b *Builder) infoSchemaDBOfOtherHandle() bool {
+	item, ok := b.is.tableItemByID(infoSchemaDB.Tables[0].ID)
+	if !ok {
+		return false
+	}
+	it, ok := item.tbl.(*infoschemaTable)
+	return ok && it.handle != b.handle
+}
+
 func (b *Builder) copySchemasMap(oldIS *infoSchema) {
 	for k, v := range oldIS.schemaMap {
 		b.is.schemaMap[k] = v
@@ -209,15 +241,19 @@ func (b *Builder) copySchemasMap(oldIS *infoSchema) {
 // copySchemaTables creates a new schemaTables instance when a table in the database has changed.
 // It also does modifications on the new one because old schemaTables must be read-only.
 func (b *Builder) copySchemaTables(dbName string) {
+	if _, ok := b.copiedSchemas[dbName]; ok {
+		return
+	}
 	oldSchemaTables := b.is.schemaMap[dbName]
 	newSchemaTables := &schemaTables{
 		dbInfo: oldSchemaTables.dbInfo,
-		tables: make(map[string]table.Table, len(oldSchemaTables.tables)),
+		tables: make(map[string]*tableItem, len(oldSchemaTables.tables)),
 	}
 	for k, v := range oldSchemaTables.tables {
 		newSchemaTables.tables[k] = v
 	}
 	b.is.schemaMap[dbName] = newSchemaTables
+	b.copiedSchemas[dbName] = struct{}{}
 }
 
 // InitWithDBInfos initializes an empty new InfoSchema with a slice of DBInfo and schema version.
@@ -238,26 +274,26 @@ func (b *Builder) InitWithDBInfos(dbInfos []*model.DBInfo, schemaVersion int64)
 	return b, nil
 }
 
+// createSchemaTablesForDB creates the table items of the database, the tables are kept in the table
+// cache until they are evicted.
 func (b *Builder) createSchemaTablesForDB(di *model.DBInfo) error {
+	dbInfo := *di
+	dbInfo.Tables = nil
 	schTbls := &schemaTables{
-		dbInfo: di,
-		tables: make(map[string]table.Table, len(di.Tables)),
+		dbInfo: &dbInfo,
+		tables: make(map[string]*tableItem, len(di.Tables)),
 	}
 	b.is.schemaMap[di.Name.L] = schTbls
 	for _, t := range di.Tables {
-		schemaID := di.ID
-		if t.OldSchemaID != 0 {
-			schemaID = t.OldSchemaID
-		}
-		alloc := autoid.NewAllocator(b.handle.store, schemaID)
-		var tbl table.Table
-		tbl, err := tables.TableFromMeta(alloc, t)
+		tbl, err := b.handle.newTable(di.ID, t, nil)
 		if err != nil {
 			return errors.Trace(err)
 		}
-		schTbls.tables[t.Name.L] = tbl
+		item := b.newTableItem(di.ID, t)
+		b.handle.tableCache.put(item.cacheKey(), tbl)
+		schTbls.tables[t.Name.L] = item
 		sortedTables := b.is.sortedTablesBuckets[tableBucketIdx(t.ID)]
-		b.is.sortedTablesBuckets[tableBucketIdx(t.ID)] = append(sortedTables, tbl)
+		b.is.sortedTablesBuckets[tableBucketIdx(t.ID)] = append(sortedTables, item)
 	}
 	return nil
 }
@@ -267,7 +303,7 @@ func (b *Builder) createSchemaTablesForPerfSchemaDB() {
 	perfSchemaDB := perfHandle.GetDBMeta()
 	perfSchemaTblNames := &schemaTables{
 		dbInfo: perfSchemaDB,
-		tables: make(map[string]table.Table, len(perfSchemaDB.Tables)),
+		tables: make(map[string]*tableItem, len(perfSchemaDB.Tables)),
 	}
 	b.is.schemaMap[perfSchemaDB.Name.L] = perfSchemaTblNames
 	for _, t := range perfSchemaDB.Tables {
@@ -275,26 +311,40 @@ func (b *Builder) createSchemaTablesForPerfSchemaDB() {
 		if !ok {
 			continue
 		}
-		perfSchemaTblNames.tables[t.Name.L] = tbl
+		item := &tableItem{id: t.ID, name: t.Name, dbID: perfSchemaDB.ID, tbl: tbl}
+		perfSchemaTblNames.tables[t.Name.L] = item
 		bucketIdx := tableBucketIdx(t.ID)
-		b.is.sortedTablesBuckets[bucketIdx] = append(b.is.sortedTablesBuckets[bucketIdx], tbl)
+		b.is.sortedTablesBuckets[bucketIdx] = append(b.is.sortedTablesBuckets[bucketIdx], item)
 	}
 }
 
 func (b *Builder) createSchemaTablesForInfoSchemaDB() {
 	infoSchemaSchemaTables := &schemaTables{
 		dbInfo: infoSchemaDB,
-		tables: make(map[string]table.Table, len(infoSchemaDB.Tables)),
+		tables: make(map[string]*tableItem, len(infoSchemaDB.Tables)),
 	}
 	b.is.schemaMap[infoSchemaDB.Name.L] = infoSchemaSchemaTables
 	for _, t := range infoSchemaDB.Tables {
-		tbl := createInfoSchemaTable(b.handle, t)
-		infoSchemaSchemaTables.tables[t.Name.L] = tbl
+		item := &tableItem{id: t.ID, name: t.Name, dbID: infoSchemaDB.ID, tbl: createInfoSchemaTable(b.handle, t)}
+		infoSchemaSchemaTables.tables[t.Name.L] = item
 		bucketIdx := tableBucketIdx(t.ID)
-		b.is.sortedTablesBuckets[bucketIdx] = append(b.is.sortedTablesBuckets[bucketIdx], tbl)
+		if idx := b.is.sortedTablesBuckets[bucketIdx].searchTable(t.ID); idx != -1 {
+			// Replace the table of the old InfoSchema.
+			b.copySortedTables(bucketIdx)
+			b.is.sortedTablesBuckets[bucketIdx][idx] = item
+			continue
+		}
+		b.is.sortedTablesBuckets[bucketIdx] = append(b.is.sortedTablesBuckets[bucketIdx], item)
 	}
 }
 
+// SetSnapshotTS sets the timestamp to load the tables of the new InfoSchema at, the schema version at it
+// must be the version of the new InfoSchema.
+func (b *Builder) SetSnapshotTS(ts uint64) *Builder {
+	b.is.snapshotTS = ts
+	return b
+}
+
 // Build sets new InfoSchema to the handle in the Builder.
 func (b *Builder) Build() {
 	b.handle.value.Store(b.is)
@@ -307,7 +357,10 @@ func NewBuilder(handle *Handle) *Builder {
 	b.is = &infoSchema{
 		schemaMap:           map[string]*schemaTables{},
 		sortedTablesBuckets: make([]sortedTables, bucketCount),
+		handle:              handle,
 	}
+	b.copiedSchemas = make(map[string]struct{})
+	b.copiedBuckets = make(map[int]struct{})
 	return b
 }
 
@@ -18,12 +18,15 @@ import (
 	"sync/atomic"
 
 	"github.com/juju/errors"
+	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/perfschema"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/terror"
 )
 
@@ -54,6 +57,10 @@ var (
 	ErrIndexExists = terror.ClassSchema.New(codeIndexExists, "Duplicate Index")
 	// ErrMultiplePriKey returns for multiple primary keys.
 	ErrMultiplePriKey = terror.ClassSchema.New(codeMultiplePriKey, "Multiple primary key defined")
+	// ErrTriggerExists returns for trigger already exists.
+	ErrTriggerExists = terror.ClassSchema.New(codeTriggerExists, "Trigger already exists")
+	// ErrTriggerNotExists returns for trigger not exists.
+	ErrTriggerNotExists = terror.ClassSchema.New(codeTriggerNotExists, "Trigger does not exist")
 )
 
 // InfoSchema is the interface used to retrieve the schema information.
@@ -73,6 +80,8 @@ type InfoSchema interface {
 	Clone() (result []*model.DBInfo)
 	SchemaTables(schema model.CIStr) []table.Table
 	SchemaMetaVersion() int64
+	SchemaByTableID(tableID int64) (*model.DBInfo, bool)
+	TableCount() int
 }
 
 // Information Schema Name.
@@ -80,7 +89,24 @@ const (
 	Name = "INFORMATION_SCHEMA"
 )
 
-type sortedTables []table.Table
+// tableItem is the part of a table always kept in the InfoSchema, the table itself is loaded lazily
+// and kept in the table cache of the handle, except the memory tables.
+type tableItem struct {
+	id   int64
+	name model.CIStr
+	dbID int64
+	// version is the schema version the table item is created at, the table info is the same from
+	// the version to the version of the InfoSchema.
+	version int64
+	// tbl is the memory table, which doesn't need to be loaded.
+	tbl table.Table
+}
+
+func (item *tableItem) cacheKey() tableCacheKey {
+	return tableCacheKey{tableID: item.id, version: item.version}
+}
+
+type sortedTables []*tableItem
 
 func (s sortedTables) Len() int {
 	return len(s)
@@ -91,22 +117,23 @@ func (s sortedTables) Swap(i, j int) {
 }
 
 func (s sortedTables) Less(i, j int) bool {
-	return s[i].Meta().ID < s[j].Meta().ID
+	return s[i].id < s[j].id
 }
 
 func (s sortedTables) searchTable(id int64) int {
 	idx := sort.Search(len(s), func(i int) bool {
-		return s[i].Meta().ID >= id
+		return s[i].id >= id
 	})
-	if idx == len(s) || s[idx].Meta().ID != id {
+	if idx == len(s) || s[idx].id != id {
 		return -1
 	}
 	return idx
 }
 
 type schemaTables struct {
+	// dbInfo doesn't hold the table infos, they are loaded lazily.
 	dbInfo *model.DBInfo
-	tables map[string]table.Table
+	tables map[string]*tableItem
 }
 
 const bucketCount = 512
@@ -119,6 +146,12 @@ type infoSchema struct {
 
 	// schemaMetaVersion is the version of schema, and we should check version when change schema.
 	schemaMetaVersion int64
+
+	// handle loads the tables out of the table cache, it's nil for the mocked InfoSchema.
+	handle *Handle
+	// snapshotTS is the timestamp to load the tables at, the schema version at it is schemaMetaVersion.
+	// It's accessed atomically.
+	snapshotTS uint64
 }
 
 // MockInfoSchema only serves for test.
@@ -126,17 +159,17 @@ func MockInfoSchema(tbList []*model.TableInfo) InfoSchema {
 	result := &infoSchema{}
 	result.schemaMap = make(map[string]*schemaTables)
 	result.sortedTablesBuckets = make([]sortedTables, bucketCount)
-	dbInfo := &model.DBInfo{ID: 0, Name: model.NewCIStr("test"), Tables: tbList}
+	dbInfo := &model.DBInfo{ID: 0, Name: model.NewCIStr("test")}
 	tableNames := &schemaTables{
 		dbInfo: dbInfo,
-		tables: make(map[string]table.Table),
+		tables: make(map[string]*tableItem),
 	}
 	result.schemaMap["test"] = tableNames
 	for _, tb := range tbList {
-		tbl := table.MockTableFromMeta(tb)
-		tableNames.tables[tb.Name.L] = tbl
+		item := &tableItem{id: tb.ID, name: tb.Name, dbID: dbInfo.ID, tbl: table.MockTableFromMeta(tb)}
+		tableNames.tables[tb.Name.L] = item
 		bucketIdx := tableBucketIdx(tb.ID)
-		result.sortedTablesBuckets[bucketIdx] = append(result.sortedTablesBuckets[bucketIdx], tbl)
+		result.sortedTablesBuckets[bucketIdx] = append(result.sortedTablesBuckets[bucketIdx], item)
 	}
 	for i := range result.sortedTablesBuckets {
 		sort.Sort(result.sortedTablesBuckets[i])
@@ -163,10 +196,20 @@ func (is *infoSchema) SchemaExists(schema model.CIStr) bool {
 	return ok
 }
 
+// table gets the table of the item, it's loaded from the store if it isn't in the table cache.
+func (is *infoSchema) table(item *tableItem) (table.Table, error) {
+	if item.tbl != nil {
+		return item.tbl, nil
+	}
+	tbl, err := is.handle.loadTable(item, atomic.LoadUint64(&is.snapshotTS))
+	return tbl, errors.Trace(err)
+}
+
 func (is *infoSchema) TableByName(schema, table model.CIStr) (t table.Table, err error) {
 	if tbNames, ok := is.schemaMap[schema.L]; ok {
-		if t, ok = tbNames.tables[table.L]; ok {
-			return
+		if item, ok := tbNames.tables[table.L]; ok {
+			t, err = is.table(item)
+			return t, errors.Trace(err)
 		}
 	}
 	return nil, ErrTableNotExists.GenByArgs(schema, table)
@@ -190,7 +233,7 @@ func (is *infoSchema) SchemaByID(id int64) (val *model.DBInfo, ok bool) {
 	return nil, false
 }
 
-func (is *infoSchema) TableByID(id int64) (val table.Table, ok bool) {
+func (is *infoSchema) tableItemByID(id int64) (*tableItem, bool) {
 	slice := is.sortedTablesBuckets[tableBucketIdx(id)]
 	idx := slice.searchTable(id)
 	if idx == -1 {
@@ -199,6 +242,35 @@ func (is *infoSchema) TableByID(id int64) (val table.Table, ok bool) {
 	return slice[idx], true
 }
 
+func (is *infoSchema) TableByID(id int64) (val table.Table, ok bool) {
+	item, ok := is.tableItemByID(id)
+	if !ok {
+		return nil, false
+	}
+	tbl, err := is.table(item)
+	if err != nil {
+		log.Errorf("[schema] load table %d err %v", id, errors.ErrorStack(err))
+		return nil, false
+	}
+	return tbl, true
+}
+
+func (is *infoSchema) SchemaByTableID(tableID int64) (*model.DBInfo, bool) {
+	item, ok := is.tableItemByID(tableID)
+	if !ok {
+		return nil, false
+	}
+	return is.SchemaByID(item.dbID)
+}
+
+func (is *infoSchema) TableCount() int {
+	var count int
+	for _, v := range is.schemaMap {
+		count += len(v.tables)
+	}
+	return count
+}
+
 func (is *infoSchema) AllocByID(id int64) (autoid.Allocator, bool) {
 	tbl, ok := is.TableByID(id)
 	if !ok {
@@ -226,7 +298,12 @@ func (is *infoSchema) SchemaTables(schema model.CIStr) (tables []table.Table) {
 	if !ok {
 		return
 	}
-	for _, tbl := range schemaTables.tables {
+	for _, item := range schemaTables.tables {
+		tbl, err := is.table(item)
+		if err != nil {
+			log.Errorf("[schema] load table %d err %v", item.id, errors.ErrorStack(err))
+			continue
+		}
 		tables = append(tables, tbl)
 	}
 	return
@@ -234,7 +311,11 @@ func (is *infoSchema) SchemaTables(schema model.CIStr) (tables []table.Table) {
 
 func (is *infoSchema) Clone() (result []*model.DBInfo) {
 	for _, v := range is.schemaMap {
-		result = append(result, v.dbInfo.Clone())
+		dbInfo := v.dbInfo.Clone()
+		for _, tbl := range is.SchemaTables(v.dbInfo.Name) {
+			dbInfo.Tables = append(dbInfo.Tables, tbl.Meta().Clone())
+		}
+		result = append(result, dbInfo)
 	}
 	return
 }
@@ -244,12 +325,15 @@ type Handle struct {
 	value      atomic.Value
 	store      kv.Storage
 	perfHandle perfschema.PerfSchema
+	// tableCache is shared by the InfoSchemas of all the versions.
+	tableCache *tableCache
 }
 
 // NewHandle creates a new Handle.
 func NewHandle(store kv.Storage) (*Handle, error) {
 	h := &Handle{
-		store: store,
+		store:      store,
+		tableCache: newTableCache(tableCacheCapacity),
 	}
 	// init memory tables
 	var err error
@@ -277,10 +361,68 @@ func (h *Handle) EmptyClone() *Handle {
 	newHandle := &Handle{
 		store:      h.store,
 		perfHandle: h.perfHandle,
+		tableCache: h.tableCache,
 	}
 	return newHandle
 }
 
+// UpdateSnapshotTS updates the timestamp to load the tables of the current InfoSchema at, if its schema
+// version is still the latest at ts. So the tables are loaded from a recent snapshot rather than an old
+// one, which may have been garbage collected.
+func (h *Handle) UpdateSnapshotTS(schemaVersion int64, ts uint64) {
+	is, ok := h.Get().(*infoSchema)
+	if !ok || is.schemaMetaVersion != schemaVersion {
+		return
+	}
+	atomic.StoreUint64(&is.snapshotTS, ts)
+}
+
+// loadTable loads the table of the item from the store at ts, and keeps it in the table cache.
+func (h *Handle) loadTable(item *tableItem, ts uint64) (table.Table, error) {
+	key := item.cacheKey()
+	if tbl, ok := h.tableCache.get(key); ok {
+		return tbl, nil
+	}
+	if ts == 0 {
+		// The InfoSchema isn't loaded from the store, like the one built in the tests.
+		ver, err := h.store.CurrentVersion()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ts = ver.Ver
+	}
+	snapshot, err := h.store.GetSnapshot(kv.NewVersion(ts))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tblInfo, err := meta.NewSnapshotMeta(snapshot).GetTable(item.dbID, item.id)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if tblInfo == nil {
+		return nil, errors.Errorf("table %s (id %d) doesn't exist at %d", item.name, item.id, ts)
+	}
+	tbl, err := h.newTable(item.dbID, tblInfo, nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	h.tableCache.put(key, tbl)
+	return tbl, nil
+}
+
+// newTable creates the table, it uses a new allocator if alloc is nil.
+func (h *Handle) newTable(dbID int64, tblInfo *model.TableInfo, alloc autoid.Allocator) (table.Table, error) {
+	if alloc == nil {
+		schemaID := dbID
+		if tblInfo.OldSchemaID != 0 {
+			schemaID = tblInfo.OldSchemaID
+		}
+		alloc = autoid.NewAllocatorFromTableInfo(h.store, schemaID, tblInfo)
+	}
+	tbl, err := tables.TableFromMeta(alloc, tblInfo)
+	return tbl, errors.Trace(err)
+}
+
 // Schema error codes.
 const (
 	codeDBDropExists      terror.ErrCode = 1008
@@ -298,6 +440,9 @@ const (
 	codeColumnExists   = 1060
 	codeIndexExists    = 1831
 	codeMultiplePriKey = 1068
+
+	codeTriggerExists    = 1359
+	codeTriggerNotExists = 1360
 )
 
 func init() {
@@ -315,6 +460,8 @@ func init() {
 		codeColumnExists:        mysql.ErrDupFieldName,
 		codeIndexExists:         mysql.ErrDupIndex,
 		codeMultiplePriKey:      mysql.ErrMultiplePriKey,
+		codeTriggerExists:       mysql.ErrTrgAlreadyExists,
+		codeTriggerNotExists:    mysql.ErrTrgDoesNotExist,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassSchema] = schemaMySQLErrCodes
 	initInfoSchemaDB()
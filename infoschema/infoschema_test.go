@@ -196,6 +196,156 @@ func checkApplyCreateNonExistsTableDoesNotPanic(c *C, txn kv.Transaction, builde
 	c.Assert(infoschema.ErrTableNotExists.Equal(err), IsTrue)
 }
 
+// TestApplyDiffKeepsOldInfoSchema makes sure applying diffs doesn't modify the old InfoSchema.
+func (*testSuite) TestApplyDiffKeepsOldInfoSchema(c *C) {
+	defer testleak.AfterTest(c)()
+	driver := localstore.Driver{Driver: goleveldb.MemoryDriver{}}
+	store, err := driver.Open("memory")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	handle, err := infoschema.NewHandle(store)
+	c.Assert(err, IsNil)
+
+	dbName := model.NewCIStr("test")
+	ids := make([]int64, 3)
+	for i := range ids {
+		ids[i], err = genGlobalID(store)
+		c.Assert(err, IsNil)
+	}
+	dbID, oldTblID, newTblID := ids[0], ids[1], ids[2]
+	oldTblInfo := &model.TableInfo{ID: oldTblID, Name: model.NewCIStr("t1"), State: model.StatePublic}
+	dbInfo := &model.DBInfo{ID: dbID, Name: dbName, Tables: []*model.TableInfo{oldTblInfo}, State: model.StatePublic}
+	err = kv.RunInNewTxn(store, true, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		err1 := m.CreateDatabase(dbInfo)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		return errors.Trace(m.CreateTable(dbID, oldTblInfo))
+	})
+	c.Assert(err, IsNil)
+	builder, err := infoschema.NewBuilder(handle).InitWithDBInfos([]*model.DBInfo{dbInfo}, 1)
+	c.Assert(err, IsNil)
+	builder.Build()
+	oldIS := handle.Get()
+
+	newTblInfo := &model.TableInfo{ID: newTblID, Name: model.NewCIStr("t2"), State: model.StatePublic}
+	err = kv.RunInNewTxn(store, true, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		err1 := m.CreateTable(dbID, newTblInfo)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		return errors.Trace(m.DropTable(dbID, oldTblID))
+	})
+	c.Assert(err, IsNil)
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	m := meta.NewMeta(txn)
+	snapHandle := handle.EmptyClone()
+	builder = infoschema.NewBuilder(snapHandle).InitWithOldInfoSchema(oldIS)
+	err = builder.ApplyDiff(m, &model.SchemaDiff{Version: 2, Type: model.ActionCreateTable, SchemaID: dbID, TableID: newTblID})
+	c.Assert(err, IsNil)
+	err = builder.ApplyDiff(m, &model.SchemaDiff{Version: 3, Type: model.ActionDropTable, SchemaID: dbID, TableID: oldTblID})
+	c.Assert(err, IsNil)
+	builder.Build()
+	newIS := snapHandle.Get()
+
+	c.Assert(oldIS.SchemaMetaVersion(), Equals, int64(1))
+	c.Assert(oldIS.TableExists(dbName, oldTblInfo.Name), IsTrue)
+	c.Assert(oldIS.TableExists(dbName, newTblInfo.Name), IsFalse)
+	_, ok := oldIS.TableByID(newTblID)
+	c.Assert(ok, IsFalse)
+	oldTables := oldIS.SchemaTables(dbName)
+	c.Assert(oldTables, HasLen, 1)
+	c.Assert(oldTables[0].Meta().ID, Equals, oldTblID)
+
+	c.Assert(newIS.SchemaMetaVersion(), Equals, int64(3))
+	c.Assert(newIS.TableExists(dbName, oldTblInfo.Name), IsFalse)
+	c.Assert(newIS.TableExists(dbName, newTblInfo.Name), IsTrue)
+	_, ok = newIS.TableByID(oldTblID)
+	c.Assert(ok, IsFalse)
+	newTables := newIS.SchemaTables(dbName)
+	c.Assert(newTables, HasLen, 1)
+	c.Assert(newTables[0].Meta().ID, Equals, newTblID)
+	newDBInfo, ok := newIS.SchemaByTableID(newTblID)
+	c.Assert(ok, IsTrue)
+	c.Assert(newDBInfo.ID, Equals, dbID)
+	tb, err := newIS.TableByName(model.NewCIStr(infoschema.Name), model.NewCIStr("tables"))
+	c.Assert(err, IsNil)
+	c.Assert(tb, NotNil)
+}
+
+// TestLazyLoadTables makes sure the tables out of the table cache are loaded from the store.
+func (*testSuite) TestLazyLoadTables(c *C) {
+	defer testleak.AfterTest(c)()
+	driver := localstore.Driver{Driver: goleveldb.MemoryDriver{}}
+	store, err := driver.Open("memory")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	infoschema.SetTableCacheCapacity(1)
+	defer infoschema.SetTableCacheCapacity(infoschema.DefTableCacheCapacity)
+	handle, err := infoschema.NewHandle(store)
+	c.Assert(err, IsNil)
+
+	dbID, err := genGlobalID(store)
+	c.Assert(err, IsNil)
+	dbInfo := &model.DBInfo{ID: dbID, Name: model.NewCIStr("test"), State: model.StatePublic}
+	for i := 0; i < 3; i++ {
+		tblID, err1 := genGlobalID(store)
+		c.Assert(err1, IsNil)
+		tblInfo := &model.TableInfo{ID: tblID, Name: model.NewCIStr(fmt.Sprintf("t%d", i)), State: model.StatePublic}
+		dbInfo.Tables = append(dbInfo.Tables, tblInfo)
+	}
+	err = kv.RunInNewTxn(store, true, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		err1 := m.CreateDatabase(dbInfo)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		for _, tblInfo := range dbInfo.Tables {
+			err1 = m.CreateTable(dbID, tblInfo)
+			if err1 != nil {
+				return errors.Trace(err1)
+			}
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	ver, err := store.CurrentVersion()
+	c.Assert(err, IsNil)
+	builder, err := infoschema.NewBuilder(handle).SetSnapshotTS(ver.Ver).InitWithDBInfos([]*model.DBInfo{dbInfo}, 1)
+	c.Assert(err, IsNil)
+	builder.Build()
+	is := handle.Get()
+	c.Assert(is.TableCount(), Equals, 3+len(is.SchemaTables(model.NewCIStr(infoschema.Name)))+
+		len(is.SchemaTables(model.NewCIStr(perfschema.Name))))
+
+	// The DBInfo doesn't keep the table infos.
+	schema, ok := is.SchemaByName(dbInfo.Name)
+	c.Assert(ok, IsTrue)
+	c.Assert(schema.Tables, HasLen, 0)
+	for _, tblInfo := range dbInfo.Tables {
+		tbl, ok := is.TableByID(tblInfo.ID)
+		c.Assert(ok, IsTrue)
+		c.Assert(tbl.Meta().Name, Equals, tblInfo.Name)
+		tbl, err = is.TableByName(dbInfo.Name, tblInfo.Name)
+		c.Assert(err, IsNil)
+		c.Assert(tbl.Meta().ID, Equals, tblInfo.ID)
+		schema, ok = is.SchemaByTableID(tblInfo.ID)
+		c.Assert(ok, IsTrue)
+		c.Assert(schema.Name, Equals, dbInfo.Name)
+	}
+	c.Assert(is.SchemaTables(dbInfo.Name), HasLen, 3)
+	dbInfos := is.Clone()
+	for _, di := range dbInfos {
+		if di.ID == dbID {
+			c.Assert(di.Tables, HasLen, 3)
+		}
+	}
+}
+
 // TestConcurrent makes sure it is safe to concurrently create handle on multiple stores.
 func (testSuite) TestConcurrent(c *C) {
 	defer testleak.AfterTest(c)()
@@ -270,6 +420,8 @@ func (*testSuite) TestInfoTables(c *C) {
 		"OPTIMIZER_TRACE",
 		"TABLESPACES",
 		"COLLATION_CHARACTER_SET_APPLICABILITY",
+		"PROCESSLIST",
+		"TIDB_HOTSPOTS",
 	}
 	for _, t := range info_tables {
 		tb, err1 := is.TableByName(model.NewCIStr(infoschema.Name), model.NewCIStr(t))
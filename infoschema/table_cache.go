@@ -0,0 +1,112 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infoschema
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/pingcap/tidb/table"
+)
+
+// DefTableCacheCapacity is the default number of tables kept in the table cache of a handle.
+const DefTableCacheCapacity = 10000
+
+var tableCacheCapacity = DefTableCacheCapacity
+
+// SetTableCacheCapacity sets the number of tables kept in the table cache of the handles created later.
+// The tables out of the cache are loaded from the store again when they are used.
+func SetTableCacheCapacity(capacity int) {
+	if capacity <= 0 {
+		capacity = DefTableCacheCapacity
+	}
+	tableCacheCapacity = capacity
+}
+
+// tableCacheKey identifies a table info, the table info of a table ID is the same in all the schema
+// versions until the table is changed by DDL, which creates the table item with a new version.
+type tableCacheKey struct {
+	tableID int64
+	version int64
+}
+
+type tableCacheEntry struct {
+	key tableCacheKey
+	tbl table.Table
+}
+
+// tableCache is a LRU cache of the tables, only the hot tables are kept in memory so the InfoSchema of
+// a cluster with lots of tables doesn't cost too much memory.
+type tableCache struct {
+	mu       sync.Mutex
+	capacity int
+	elements map[tableCacheKey]*list.Element
+	// lru keeps the entries, the most recently used first.
+	lru *list.List
+}
+
+func newTableCache(capacity int) *tableCache {
+	return &tableCache{
+		capacity: capacity,
+		elements: make(map[tableCacheKey]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// get gets the table and marks it as the most recently used.
+func (c *tableCache) get(key tableCacheKey) (table.Table, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.elements[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*tableCacheEntry).tbl, true
+}
+
+// peek gets the table without changing its recency.
+func (c *tableCache) peek(key tableCacheKey) (table.Table, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.elements[key]
+	if !ok {
+		return nil, false
+	}
+	return elem.Value.(*tableCacheEntry).tbl, true
+}
+
+// put puts the table into the cache, the least recently used one is evicted if the cache is full.
+func (c *tableCache) put(key tableCacheKey, tbl table.Table) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.elements[key]; ok {
+		elem.Value.(*tableCacheEntry).tbl = tbl
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.elements[key] = c.lru.PushFront(&tableCacheEntry{key: key, tbl: tbl})
+	if c.lru.Len() > c.capacity {
+		back := c.lru.Back()
+		c.lru.Remove(back)
+		delete(c.elements, back.Value.(*tableCacheEntry).key)
+	}
+}
+
+// len returns the number of the cached tables.
+func (c *tableCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
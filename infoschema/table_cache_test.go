@@ -0,0 +1,57 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infoschema
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testTableCacheSuite{})
+
+type testTableCacheSuite struct{}
+
+func (s *testTableCacheSuite) TestTableCache(c *C) {
+	defer testleak.AfterTest(c)()
+	cache := newTableCache(2)
+	newTable := func(id int64) table.Table {
+		return table.MockTableFromMeta(&model.TableInfo{ID: id, Name: model.NewCIStr("t")})
+	}
+	cache.put(tableCacheKey{tableID: 1, version: 1}, newTable(1))
+	cache.put(tableCacheKey{tableID: 2, version: 1}, newTable(2))
+	// Table 1 becomes the most recently used one.
+	tbl, ok := cache.get(tableCacheKey{tableID: 1, version: 1})
+	c.Assert(ok, IsTrue)
+	c.Assert(tbl.Meta().ID, Equals, int64(1))
+	// The same table of another version is another entry.
+	_, ok = cache.get(tableCacheKey{tableID: 1, version: 2})
+	c.Assert(ok, IsFalse)
+
+	// Table 2 is evicted.
+	cache.put(tableCacheKey{tableID: 3, version: 1}, newTable(3))
+	c.Assert(cache.len(), Equals, 2)
+	_, ok = cache.peek(tableCacheKey{tableID: 2, version: 1})
+	c.Assert(ok, IsFalse)
+	// Peek doesn't change the recency, table 1 is evicted.
+	_, ok = cache.peek(tableCacheKey{tableID: 1, version: 1})
+	c.Assert(ok, IsTrue)
+	cache.put(tableCacheKey{tableID: 4, version: 1}, newTable(4))
+	_, ok = cache.get(tableCacheKey{tableID: 1, version: 1})
+	c.Assert(ok, IsFalse)
+	_, ok = cache.get(tableCacheKey{tableID: 3, version: 1})
+	c.Assert(ok, IsTrue)
+	c.Assert(cache.len(), Equals, 2)
+}
@@ -16,6 +16,7 @@ package infoschema
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
@@ -28,6 +29,10 @@ import (
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/hotspot"
+	"github.com/pingcap/tidb/util/indexusage"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/txnconflict"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -63,6 +68,10 @@ const (
 	tableOptimizerTrace                     = "OPTIMIZER_TRACE"
 	tableTableSpaces                        = "TABLESPACES"
 	tableCollationCharacterSetApplicability = "COLLATION_CHARACTER_SET_APPLICABILITY"
+	tableProcesslist                        = "PROCESSLIST"
+	tableTiDBHotspots                       = "TIDB_HOTSPOTS"
+	tableTiDBWriteConflicts                 = "TIDB_WRITE_CONFLICTS"
+	tableTiDBIndexUsage                     = "TIDB_INDEX_USAGE"
 )
 
 type columnInfo struct {
@@ -520,6 +529,58 @@ var tableCollationCharacterSetApplicabilityCols = []columnInfo{
 	{"TABLESPACE_COMMENT", mysql.TypeVarchar, 2048, 0, nil, nil},
 }
 
+// See https://dev.mysql.com/doc/refman/5.7/en/processlist-table.html
+var tableProcesslistCols = []columnInfo{
+	{"ID", mysql.TypeLonglong, 21, mysql.NotNullFlag, 0, nil},
+	{"USER", mysql.TypeVarchar, 16, mysql.NotNullFlag, "", nil},
+	{"HOST", mysql.TypeVarchar, 64, mysql.NotNullFlag, "", nil},
+	{"DB", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"COMMAND", mysql.TypeVarchar, 16, mysql.NotNullFlag, "", nil},
+	{"TIME", mysql.TypeLong, 7, mysql.NotNullFlag, 0, nil},
+	{"STATE", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"INFO", mysql.TypeLongBlob, 0, 0, nil, nil},
+	{"TXN_START_TS", mysql.TypeLonglong, 21, mysql.UnsignedFlag, nil, nil},
+	{"TXN_TIME", mysql.TypeLong, 7, 0, nil, nil},
+	{"IDLE_TIME", mysql.TypeLong, 7, 0, nil, nil},
+}
+
+var tableTiDBHotspotsCols = []columnInfo{
+	{"DB_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"TABLE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"INDEX_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"TABLE_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"INDEX_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"READ_QPS", mysql.TypeDouble, 22, 0, nil, nil},
+	{"WRITE_QPS", mysql.TypeDouble, 22, 0, nil, nil},
+	{"APPEND_RATIO", mysql.TypeDouble, 22, 0, nil, nil},
+}
+
+var tableTiDBWriteConflictsCols = []columnInfo{
+	{"TIME", mysql.TypeDatetime, 19, 0, nil, nil},
+	{"START_TS", mysql.TypeLonglong, 21, mysql.UnsignedFlag, nil, nil},
+	{"CONFLICT_START_TS", mysql.TypeLonglong, 21, mysql.UnsignedFlag, nil, nil},
+	{"CONFLICT_COMMIT_TS", mysql.TypeLonglong, 21, mysql.UnsignedFlag, nil, nil},
+	{"DB_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"TABLE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"INDEX_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"TABLE_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"INDEX_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"HANDLE", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"INDEX_VALUES", mysql.TypeVarchar, 1024, 0, nil, nil},
+	{"KEY", mysql.TypeVarchar, 1024, 0, nil, nil},
+}
+
+var tableTiDBIndexUsageCols = []columnInfo{
+	{"DB_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"TABLE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"INDEX_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"TABLE_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"INDEX_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"READ_COUNT", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"ROWS_READ", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"LAST_USED_AT", mysql.TypeDatetime, 19, 0, nil, nil},
+}
+
 func dataForCharacterSets() (records [][]types.Datum) {
 	records = append(records,
 		types.MakeDatums("ascii", "ascii_general_ci", "US ASCII", 1),
@@ -556,6 +617,218 @@ func dataForSessionVar(ctx context.Context) (records [][]types.Datum, err error)
 	return
 }
 
+func dataForGlobalVar(ctx context.Context) (records [][]types.Datum, err error) {
+	sessionVars := ctx.GetSessionVars()
+	for _, v := range variable.SysVars {
+		if v.Scope == variable.ScopeSession {
+			continue
+		}
+		var value string
+		value, err = varsutil.GetGlobalSystemVar(sessionVars, v.Name)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row := types.MakeDatums(v.Name, value)
+		records = append(records, row)
+	}
+	return
+}
+
+func dataForProcesslist(ctx context.Context) [][]types.Datum {
+	sm := ctx.GetSessionManager()
+	if sm == nil {
+		return nil
+	}
+	pl := sm.ShowProcessList()
+	records := make([][]types.Datum, 0, len(pl))
+	now := time.Now()
+	for _, pi := range pl {
+		var t uint64
+		var info interface{}
+		if len(pi.Info) != 0 {
+			t = uint64(time.Since(pi.Time) / time.Second)
+			info = pi.Info
+		}
+		// The transaction columns are NULL if the process isn't in a transaction.
+		var txnStartTS, txnTime, idleTime interface{}
+		if pi.TxnStartTS != 0 {
+			txnStartTS = pi.TxnStartTS
+			txnTime = uint64(pi.TxnDuration(now) / time.Second)
+			idleTime = uint64(pi.IdleInTxnDuration(now) / time.Second)
+		}
+		record := types.MakeDatums(
+			pi.ID,
+			pi.User,
+			pi.Host,
+			pi.DB,
+			pi.Command,
+			t,
+			fmt.Sprintf("%d", pi.State),
+			info,
+			txnStartTS,
+			txnTime,
+			idleTime,
+		)
+		records = append(records, record)
+	}
+	return records
+}
+
+// HotspotRow is the traffic of the record data or an index of a table.
+type HotspotRow struct {
+	hotspot.Item
+	DBName    string
+	TableName string
+	// IndexName is empty for the record data.
+	IndexName string
+}
+
+// Hotspots returns the traffic of the tables and indices, the hottest first.
+// The traffic of the dropped tables and indices is omitted.
+func Hotspots(is InfoSchema) []HotspotRow {
+	items := hotspot.Items()
+	rows := make([]HotspotRow, 0, len(items))
+	for _, item := range items {
+		dbName, tblInfo, ok := tableByID(is, item.TableID)
+		if !ok {
+			continue
+		}
+		row := HotspotRow{Item: item, DBName: dbName, TableName: tblInfo.Name.O}
+		if item.IndexID != hotspot.RecordIndexID {
+			idx := findIndexByID(tblInfo, item.IndexID)
+			if idx == nil {
+				continue
+			}
+			row.IndexName = idx.Name.O
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// tableByID returns the schema name and the table info of the table, it's false if the table is dropped.
+// Only the tables used are loaded, rather than all the tables.
+func tableByID(is InfoSchema, tableID int64) (string, *model.TableInfo, bool) {
+	tbl, ok := is.TableByID(tableID)
+	if !ok {
+		return "", nil, false
+	}
+	db, ok := is.SchemaByTableID(tableID)
+	if !ok {
+		return "", nil, false
+	}
+	return db.Name.O, tbl.Meta(), true
+}
+
+func findIndexByID(tblInfo *model.TableInfo, indexID int64) *model.IndexInfo {
+	for _, idx := range tblInfo.Indices {
+		if idx.ID == indexID {
+			return idx
+		}
+	}
+	return nil
+}
+
+func dataForHotspots(is InfoSchema) [][]types.Datum {
+	rows := Hotspots(is)
+	records := make([][]types.Datum, 0, len(rows))
+	for _, row := range rows {
+		var indexName interface{}
+		if row.IndexID != hotspot.RecordIndexID {
+			indexName = row.IndexName
+		}
+		record := types.MakeDatums(
+			row.DBName,
+			row.TableName,
+			indexName,
+			row.TableID,
+			row.IndexID,
+			row.ReadQPS,
+			row.WriteQPS,
+			row.AppendRatio,
+		)
+		records = append(records, record)
+	}
+	return records
+}
+
+// dataForWriteConflicts returns the recent write conflicts on the tables, the latest first. The
+// conflicts on the dropped tables are omitted.
+func dataForWriteConflicts(is InfoSchema) [][]types.Datum {
+	conflicts := txnconflict.Recent()
+	records := make([][]types.Datum, 0, len(conflicts))
+	for _, c := range conflicts {
+		dbName, tblInfo, ok := tableByID(is, c.TableID)
+		if !ok {
+			continue
+		}
+		var conflictStartTS, indexName, handle, indexValues interface{}
+		if c.ConflictStartTS != 0 {
+			conflictStartTS = c.ConflictStartTS
+		}
+		if c.IndexID == txnconflict.RecordIndexID {
+			handle = c.Handle
+		} else {
+			indexValues = c.IndexValues
+			if idx := findIndexByID(tblInfo, c.IndexID); idx != nil {
+				indexName = idx.Name.O
+			}
+		}
+		conflictTime := types.Time{Time: types.FromGoTime(c.Time), Type: mysql.TypeDatetime}
+		record := types.MakeDatums(
+			conflictTime,
+			c.StartTS,
+			conflictStartTS,
+			c.ConflictCommitTS,
+			dbName,
+			tblInfo.Name.O,
+			indexName,
+			c.TableID,
+			c.IndexID,
+			handle,
+			indexValues,
+			fmt.Sprintf("%X", c.Key),
+		)
+		records = append(records, record)
+	}
+	return records
+}
+
+// dataForIndexUsage returns the usage of all the public indices since the server starts, the unused
+// indices are included with no reads.
+func dataForIndexUsage(schemas []*model.DBInfo) [][]types.Datum {
+	var records [][]types.Datum
+	for _, schema := range schemas {
+		if IsMemoryDB(schema.Name.L) {
+			continue
+		}
+		for _, tblInfo := range schema.Tables {
+			for _, idx := range tblInfo.Indices {
+				if idx.State != model.StatePublic {
+					continue
+				}
+				var lastUsedAt interface{}
+				usage, ok := indexusage.Get(tblInfo.ID, idx.ID)
+				if ok {
+					lastUsedAt = types.Time{Time: types.FromGoTime(usage.LastUsedAt), Type: mysql.TypeDatetime}
+				}
+				record := types.MakeDatums(
+					schema.Name.O,
+					tblInfo.Name.O,
+					idx.Name.O,
+					tblInfo.ID,
+					idx.ID,
+					usage.ReadCount,
+					usage.RowsRead,
+					lastUsedAt,
+				)
+				records = append(records, record)
+			}
+		}
+	}
+	return records
+}
+
 func dataForUserPrivileges(ctx context.Context) [][]types.Datum {
 	pm := privilege.GetPrivilegeManager(ctx)
 	return pm.UserPrivilegesTable()
@@ -629,37 +902,99 @@ func dataForSchemata(schemas []*model.DBInfo) [][]types.Datum {
 	return rows
 }
 
-func dataForTables(schemas []*model.DBInfo) [][]types.Datum {
+// getRowCountAllTable reads the row count of all the tables from the stats meta table.
+// Tables that have never been analyzed or modified are absent from the result.
+func getRowCountAllTable(ctx context.Context) (map[int64]uint64, error) {
+	exec, ok := ctx.(sqlexec.RestrictedSQLExecutor)
+	if !ok {
+		return nil, nil
+	}
+	sql := fmt.Sprintf("select table_id, count from %s.stats_meta", mysql.SystemDB)
+	rows, _, err := exec.ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	rowCountMap := make(map[int64]uint64, len(rows))
+	for _, row := range rows {
+		count := row.Data[1].GetInt64()
+		if count < 0 {
+			count = 0
+		}
+		rowCountMap[row.Data[0].GetInt64()] = uint64(count)
+	}
+	return rowCountMap, nil
+}
+
+// Variable length columns are assumed to be half filled when estimating the row length.
+const defaultVarLenColumnLength = 32
+
+// estimateAvgRowLength estimates the average row length of the table by the column types.
+func estimateAvgRowLength(tbl *model.TableInfo) uint64 {
+	var length int
+	for _, col := range tbl.Columns {
+		if l, ok := mysql.DefaultLengthOfMysqlTypes[col.Tp]; ok {
+			length += l
+			continue
+		}
+		switch col.Tp {
+		case mysql.TypeVarchar, mysql.TypeVarString:
+			if col.Flen > 0 {
+				length += col.Flen / 2
+				continue
+			}
+		case mysql.TypeNewDecimal:
+			// Every 9 digits take 4 bytes.
+			if col.Flen > 0 {
+				length += (col.Flen + 8) / 9 * 4
+				continue
+			}
+		}
+		length += defaultVarLenColumnLength
+	}
+	return uint64(length)
+}
+
+func dataForTables(ctx context.Context, schemas []*model.DBInfo) ([][]types.Datum, error) {
+	rowCountMap, err := getRowCountAllTable(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	rows := [][]types.Datum{}
 	for _, schema := range schemas {
 		for _, table := range schema.Tables {
+			collation := table.Collate
+			if collation == "" {
+				collation = mysql.DefaultCollationName
+			}
+			rowCount := rowCountMap[table.ID]
+			avgRowLength := estimateAvgRowLength(table)
 			record := types.MakeDatums(
-				catalogVal,          // TABLE_CATALOG
-				schema.Name.O,       // TABLE_SCHEMA
-				table.Name.O,        // TABLE_NAME
-				"BASE TABLE",        // TABLE_TYPE
-				"InnoDB",            // ENGINE
-				uint64(10),          // VERSION
-				"Compact",           // ROW_FORMAT
-				uint64(0),           // TABLE_ROWS
-				uint64(0),           // AVG_ROW_LENGTH
-				uint64(16384),       // DATA_LENGTH
-				uint64(0),           // MAX_DATA_LENGTH
-				uint64(0),           // INDEX_LENGTH
-				uint64(0),           // DATA_FREE
-				nil,                 // AUTO_INCREMENT
-				nil,                 // CREATE_TIME
-				nil,                 // UPDATE_TIME
-				nil,                 // CHECK_TIME
-				"latin1_swedish_ci", // TABLE_COLLATION
-				nil,                 // CHECKSUM
-				"",                  // CREATE_OPTIONS
-				"",                  // TABLE_COMMENT
+				catalogVal,            // TABLE_CATALOG
+				schema.Name.O,         // TABLE_SCHEMA
+				table.Name.O,          // TABLE_NAME
+				"BASE TABLE",          // TABLE_TYPE
+				"InnoDB",              // ENGINE
+				uint64(10),            // VERSION
+				"Compact",             // ROW_FORMAT
+				rowCount,              // TABLE_ROWS
+				avgRowLength,          // AVG_ROW_LENGTH
+				rowCount*avgRowLength, // DATA_LENGTH
+				uint64(0),             // MAX_DATA_LENGTH
+				uint64(0),             // INDEX_LENGTH
+				uint64(0),             // DATA_FREE
+				nil,                   // AUTO_INCREMENT
+				nil,                   // CREATE_TIME
+				nil,                   // UPDATE_TIME
+				nil,                   // CHECK_TIME
+				collation,             // TABLE_COLLATION
+				nil,                   // CHECKSUM
+				"",                    // CREATE_OPTIONS
+				table.Comment,         // TABLE_COMMENT
 			)
 			rows = append(rows, record)
 		}
 	}
-	return rows
+	return rows, nil
 }
 
 func dataForColumns(schemas []*model.DBInfo) [][]types.Datum {
@@ -974,6 +1309,10 @@ var tableNameToColumns = map[string]([]columnInfo){
 	tableOptimizerTrace:                     tableOptimizerTraceCols,
 	tableTableSpaces:                        tableTableSpacesCols,
 	tableCollationCharacterSetApplicability: tableCollationCharacterSetApplicabilityCols,
+	tableProcesslist:                        tableProcesslistCols,
+	tableTiDBHotspots:                       tableTiDBHotspotsCols,
+	tableTiDBWriteConflicts:                 tableTiDBWriteConflictsCols,
+	tableTiDBIndexUsage:                     tableTiDBIndexUsageCols,
 }
 
 func createInfoSchemaTable(handle *Handle, meta *model.TableInfo) *infoschemaTable {
@@ -1010,15 +1349,59 @@ func (s schemasSorter) Less(i, j int) bool {
 	return s[i].Name.L < s[j].Name.L
 }
 
+// needTables checks whether the rows of the table are built from the table infos.
+func (it *infoschemaTable) needTables() bool {
+	switch it.meta.Name.O {
+	case tableTables, tableColumns, tableStatistics, tableConstraints, tableKeyColumm, tableTiDBIndexUsage:
+		return true
+	}
+	return false
+}
+
+// schemasWithTables returns the copies of the schemas with the table infos sorted by ID, the DBInfos
+// in the InfoSchema don't keep the table infos, which are loaded lazily.
+func schemasWithTables(is InfoSchema, dbs []*model.DBInfo) []*model.DBInfo {
+	ret := make([]*model.DBInfo, 0, len(dbs))
+	for _, db := range dbs {
+		tbls := is.SchemaTables(db.Name)
+		newDB := *db
+		newDB.Tables = make([]*model.TableInfo, 0, len(tbls))
+		for _, tbl := range tbls {
+			newDB.Tables = append(newDB.Tables, tbl.Meta())
+		}
+		sort.Sort(tableInfosSorter(newDB.Tables))
+		ret = append(ret, &newDB)
+	}
+	return ret
+}
+
+// tableInfosSorter implements the sort.Interface interface, sorts TableInfo by ID.
+type tableInfosSorter []*model.TableInfo
+
+func (s tableInfosSorter) Len() int {
+	return len(s)
+}
+
+func (s tableInfosSorter) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s tableInfosSorter) Less(i, j int) bool {
+	return s[i].ID < s[j].ID
+}
+
 func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (fullRows [][]types.Datum, err error) {
 	is := it.handle.Get()
 	dbs := is.AllSchemas()
 	sort.Sort(schemasSorter(dbs))
+	if it.needTables() {
+		dbs = schemasWithTables(is, dbs)
+	}
 	switch it.meta.Name.O {
 	case tableSchemata:
 		fullRows = dataForSchemata(dbs)
 	case tableTables:
-		fullRows = dataForTables(dbs)
+		fullRows, err = dataForTables(ctx, dbs)
 	case tableColumns:
 		fullRows = dataForColumns(dbs)
 	case tableStatistics:
@@ -1052,6 +1435,15 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 	case tableEvents:
 	case tableGlobalStatus:
 	case tableGlobalVariables:
+		fullRows, err = dataForGlobalVar(ctx)
+	case tableProcesslist:
+		fullRows = dataForProcesslist(ctx)
+	case tableTiDBHotspots:
+		fullRows = dataForHotspots(is)
+	case tableTiDBWriteConflicts:
+		fullRows = dataForWriteConflicts(is)
+	case tableTiDBIndexUsage:
+		fullRows = dataForIndexUsage(dbs)
 	case tableSessionStatus:
 	case tableOptimizerTrace:
 	case tableTableSpaces:
@@ -0,0 +1,134 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspectkv
+
+import (
+	"bytes"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/distsql"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	goctx "golang.org/x/net/context"
+)
+
+// CompareIndexDataByChecksum compares the index data with the records like CompareIndexData, but the storage
+// computes the digests of the rows and the index entries for every handle range of req.BucketWidth, and only
+// the entries in the ranges whose digests mismatch are read back and compared one by one.
+func CompareIndexDataByChecksum(goCtx goctx.Context, client kv.Client, t table.Table, idx table.Index,
+	req *distsql.ChecksumRequest, concurrency int) error {
+	recordPrefix := t.RecordPrefix()
+	recordRanges := []kv.KeyRange{{StartKey: recordPrefix, EndKey: recordPrefix.PrefixNext()}}
+	indexPrefix := tablecodec.EncodeTableIndexPrefix(t.Meta().ID, idx.Meta().ID)
+	indexRanges := []kv.KeyRange{{StartKey: indexPrefix, EndKey: indexPrefix.PrefixNext()}}
+
+	recordReq, indexReq := *req, *req
+	recordReq.ScanIndex, indexReq.ScanIndex = false, true
+	recordResp, err := distsql.Checksum(client, goCtx, &recordReq, recordRanges, concurrency)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	indexResp, err := distsql.Checksum(client, goCtx, &indexReq, indexRanges, concurrency)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	buckets := mismatchedBuckets(indexResp.Digests, recordResp.Digests)
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	recordReq.Buckets, indexReq.Buckets = buckets, buckets
+	recordResp, err = distsql.Checksum(client, goCtx, &recordReq, recordRanges, concurrency)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	indexResp, err = distsql.Checksum(client, goCtx, &indexReq, indexRanges, concurrency)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(compareChecksumEntries(indexResp.Entries, recordResp.Entries, len(req.Columns)))
+}
+
+// mismatchedBuckets returns the buckets whose digests are different. Both the digests are sorted by bucket.
+func mismatchedBuckets(digests1, digests2 []distsql.ChecksumDigest) []int64 {
+	var buckets []int64
+	i, j := 0, 0
+	for i < len(digests1) || j < len(digests2) {
+		switch {
+		case j == len(digests2) || (i < len(digests1) && digests1[i].Bucket < digests2[j].Bucket):
+			buckets = append(buckets, digests1[i].Bucket)
+			i++
+		case i == len(digests1) || digests2[j].Bucket < digests1[i].Bucket:
+			buckets = append(buckets, digests2[j].Bucket)
+			j++
+		default:
+			if digests1[i] != digests2[j] {
+				buckets = append(buckets, digests1[i].Bucket)
+			}
+			i++
+			j++
+		}
+	}
+	return buckets
+}
+
+// compareChecksumEntries finds the first difference between the index entries and the rows. Both of them are
+// sorted by handle, and every handle has only one row.
+func compareChecksumEntries(indexEntries, recordEntries []distsql.ChecksumEntry, colCount int) error {
+	i, j := 0, 0
+	for i < len(indexEntries) || j < len(recordEntries) {
+		if j == len(recordEntries) || (i < len(indexEntries) && indexEntries[i].Handle < recordEntries[j].Handle) {
+			record, err := checksumEntryToRecord(indexEntries[i], colCount)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			return errDateNotEqual.Gen("index:%v != record:%v", record, nil)
+		}
+		if i == len(indexEntries) || recordEntries[j].Handle < indexEntries[i].Handle {
+			record, err := checksumEntryToRecord(recordEntries[j], colCount)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			return errDateNotEqual.Gen("index:%v != record:%v", nil, record)
+		}
+		// Every index entry of the handle should be equal to the row, and there should be only one entry.
+		entry := recordEntries[j]
+		for matched := false; i < len(indexEntries) && indexEntries[i].Handle == entry.Handle; i++ {
+			if matched || !bytes.Equal(indexEntries[i].Values, entry.Values) {
+				record1, err := checksumEntryToRecord(indexEntries[i], colCount)
+				if err != nil {
+					return errors.Trace(err)
+				}
+				record2, err := checksumEntryToRecord(entry, colCount)
+				if err != nil {
+					return errors.Trace(err)
+				}
+				return errDateNotEqual.Gen("index:%v != record:%v", record1, record2)
+			}
+			matched = true
+		}
+		j++
+	}
+	return nil
+}
+
+func checksumEntryToRecord(entry distsql.ChecksumEntry, colCount int) (*RecordData, error) {
+	values, err := codec.Decode(entry.Values, colCount)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &RecordData{Handle: entry.Handle, Values: values}, nil
+}
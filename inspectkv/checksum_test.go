@@ -0,0 +1,71 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspectkv
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/distsql"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func (s *testSuite) TestMismatchedBuckets(c *C) {
+	defer testleak.AfterTest(c)()
+	digests1 := []distsql.ChecksumDigest{
+		{Bucket: -1, Count: 1, Checksum: 10},
+		{Bucket: 0, Count: 2, Checksum: 20},
+		{Bucket: 2, Count: 1, Checksum: 30},
+	}
+	digests2 := []distsql.ChecksumDigest{
+		{Bucket: -1, Count: 1, Checksum: 10},
+		{Bucket: 0, Count: 2, Checksum: 21},
+		{Bucket: 3, Count: 1, Checksum: 30},
+	}
+	c.Assert(mismatchedBuckets(digests1, digests2), DeepEquals, []int64{0, 2, 3})
+	c.Assert(mismatchedBuckets(digests1, digests1), IsNil)
+	c.Assert(mismatchedBuckets(nil, digests2[:1]), DeepEquals, []int64{-1})
+}
+
+func (s *testSuite) TestCompareChecksumEntries(c *C) {
+	defer testleak.AfterTest(c)()
+	entry := func(handle int64, val int64) distsql.ChecksumEntry {
+		values, err := codec.EncodeKey(nil, types.NewIntDatum(val))
+		c.Assert(err, IsNil)
+		return distsql.ChecksumEntry{Handle: handle, Values: values}
+	}
+	tbl := []struct {
+		index  []distsql.ChecksumEntry
+		record []distsql.ChecksumEntry
+		errMsg string
+	}{
+		{[]distsql.ChecksumEntry{entry(1, 1), entry(2, 2)}, []distsql.ChecksumEntry{entry(1, 1), entry(2, 2)}, ""},
+		{[]distsql.ChecksumEntry{entry(1, 1)}, []distsql.ChecksumEntry{entry(1, 1), entry(2, 2)},
+			".*index:<nil> != record:&{2 .*"},
+		{[]distsql.ChecksumEntry{entry(1, 1), entry(3, 3)}, []distsql.ChecksumEntry{entry(1, 1)},
+			".*index:&{3 .*} != record:<nil>"},
+		{[]distsql.ChecksumEntry{entry(1, 2)}, []distsql.ChecksumEntry{entry(1, 1)},
+			".*index:&{1 .*} != record:&{1 .*}"},
+		{[]distsql.ChecksumEntry{entry(1, 1), entry(1, 2)}, []distsql.ChecksumEntry{entry(1, 1)},
+			".*index:&{1 .*} != record:&{1 .*}"},
+	}
+	for _, t := range tbl {
+		err := compareChecksumEntries(t.index, t.record, 1)
+		if t.errMsg == "" {
+			c.Assert(err, IsNil)
+		} else {
+			c.Assert(err, ErrorMatches, t.errMsg)
+		}
+	}
+}
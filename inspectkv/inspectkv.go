@@ -20,6 +20,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
@@ -173,6 +174,51 @@ func CompareIndexData(txn kv.Transaction, t table.Table, idx table.Index) error
 	return checkRecordAndIndex(txn, t, idx)
 }
 
+// CompareClusteredRows compares the rows stored in the entries of the clustered primary key idx with the
+// records one by one, see model.IndexInfo.Clustered. The entries are compared with the records by
+// CompareIndexData like the other indices.
+func CompareClusteredRows(ctx context.Context, t table.Table, idx table.Index) error {
+	ct, ok := t.(table.ClusteredTable)
+	if !ok {
+		return errors.Errorf("table %s has no clustered primary key", t.Meta().Name)
+	}
+	txn := ctx.Txn()
+	it, err := idx.SeekFirst(txn)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer it.Close()
+
+	cols := t.Cols()
+	for {
+		vals, h, err := it.Next()
+		if terror.ErrorEqual(err, io.EOF) {
+			break
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+
+		_, row1, err := ct.ClusteredRowWithCols(ctx, txn, idx, vals, cols)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		row2, err := t.RowWithCols(ctx, h, cols)
+		if terror.ErrorEqual(err, kv.ErrNotExist) {
+			record := &RecordData{Handle: h, Values: row1}
+			err = errDateNotEqual.Gen("index:%v != record:%v", record, nil)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !reflect.DeepEqual(row1, row2) {
+			record1 := &RecordData{Handle: h, Values: row1}
+			record2 := &RecordData{Handle: h, Values: row2}
+			return errDateNotEqual.Gen("index:%v != record:%v", record1, record2)
+		}
+	}
+	return nil
+}
+
 func checkIndexAndRecord(txn kv.Transaction, t table.Table, idx table.Index) error {
 	it, err := idx.SeekFirst(txn)
 	if err != nil {
@@ -34,6 +34,7 @@ const (
 	codeNotImplemented                            = 10
 	codeTxnTooLarge                               = 11
 	codeEntryTooLarge                             = 12
+	codeWriteConflict                             = 13
 
 	codeKeyExists = 1062
 )
@@ -57,9 +58,12 @@ var (
 	// ErrInvalidTxn is the error when commits or rollbacks in an invalid transaction.
 	ErrInvalidTxn = terror.ClassKV.New(codeInvalidTxn, "invalid transaction")
 	// ErrTxnTooLarge is the error when transaction is too large, lock time reached the maximum value.
-	ErrTxnTooLarge = terror.ClassKV.New(codeTxnTooLarge, "transaction is too large")
+	// It carries the entry count and the size of the transaction and the limits it exceeds.
+	ErrTxnTooLarge = terror.ClassKV.New(codeTxnTooLarge, "transaction is too large, entries: %d, size: %d bytes, "+
+		"limits: %d entries, %d bytes; split it into smaller transactions, "+
+		"or raise tidb_txn_entry_count_limit and tidb_txn_total_size_limit for the session")
 	// ErrEntryTooLarge is the error when a key value entry is too large.
-	ErrEntryTooLarge = terror.ClassKV.New(codeEntryTooLarge, "entry is too large")
+	ErrEntryTooLarge = terror.ClassKV.New(codeEntryTooLarge, "entry is too large, size: %d bytes, limit: %d bytes")
 
 	// ErrNotCommitted is the error returned by CommitVersion when this
 	// transaction is not committed.
@@ -67,13 +71,18 @@ var (
 
 	// ErrKeyExists returns when key is already exist.
 	ErrKeyExists = terror.ClassKV.New(codeKeyExists, "key already exist")
+	// ErrWriteConflict is used when a key written by a transaction has been written by another
+	// transaction committed after the transaction starts.
+	ErrWriteConflict = terror.ClassKV.New(codeWriteConflict, "write conflict, txnStartTS=%d, conflictStartTS=%d, conflictCommitTS=%d, key=%s")
 	// ErrNotImplemented returns when a function is not implemented yet.
 	ErrNotImplemented = terror.ClassKV.New(codeNotImplemented, "not implemented")
 )
 
 func init() {
 	kvMySQLErrCodes := map[terror.ErrCode]uint16{
-		codeKeyExists: mysql.ErrDupEntry,
+		codeKeyExists:     mysql.ErrDupEntry,
+		codeTxnTooLarge:   mysql.ErrTxnTooLarge,
+		codeEntryTooLarge: mysql.ErrEntryTooLarge,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassKV] = kvMySQLErrCodes
 }
@@ -87,6 +96,7 @@ func IsRetryableError(err error) bool {
 	if terror.ErrorEqual(err, ErrRetryable) ||
 		terror.ErrorEqual(err, ErrLockConflict) ||
 		terror.ErrorEqual(err, ErrConditionNotMatch) ||
+		terror.ErrorEqual(err, ErrWriteConflict) ||
 		// TiKV exception message will tell you if you should retry or not
 		strings.Contains(err.Error(), "try again later") {
 		return true
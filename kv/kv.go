@@ -14,6 +14,7 @@
 package kv
 
 import (
+	"github.com/pingcap/tidb/util/execdetails"
 	goctx "golang.org/x/net/context"
 )
 
@@ -33,6 +34,14 @@ const (
 	SkipCheckForWrite
 	// SchemaLeaseChecker is used for schema lease check.
 	SchemaLeaseChecker
+	// GroupCommit indicates the transaction is allowed to be committed together with other small transactions.
+	GroupCommit
+	// EntryCountLimit overrides TxnEntryCountLimit for the transaction, the value is a uint64.
+	// It must be set before the first write of the transaction.
+	EntryCountLimit
+	// TotalSizeLimit overrides TxnTotalSizeLimit for the transaction, the value is an int.
+	// It must be set before the first write of the transaction.
+	TotalSizeLimit
 )
 
 // Those limits is enforced to make sure the transaction can be well handled by TiKV.
@@ -109,6 +118,14 @@ type Transaction interface {
 	// Valid returns if the transaction is valid.
 	// A transaction become invalid after commit or rollback.
 	Valid() bool
+	// StartStmt creates a savepoint for a statement, so that the writes of a failed statement can be rolled back
+	// alone without aborting the transaction. The savepoint is released by CommitStmt or RollbackStmt, and Commit
+	// commits the writes since it as well.
+	StartStmt()
+	// CommitStmt merges the writes since the savepoint into the transaction.
+	CommitStmt() error
+	// RollbackStmt discards the writes since the savepoint.
+	RollbackStmt()
 }
 
 // Client is used to send request to KV layer.
@@ -122,9 +139,10 @@ type Client interface {
 
 // ReqTypes.
 const (
-	ReqTypeSelect = 101
-	ReqTypeIndex  = 102
-	ReqTypeDAG    = 103
+	ReqTypeSelect   = 101
+	ReqTypeIndex    = 102
+	ReqTypeDAG      = 103
+	ReqTypeChecksum = 104
 
 	ReqSubTypeBasic   = 0
 	ReqSubTypeDesc    = 10000
@@ -158,6 +176,13 @@ type Response interface {
 	Close() error
 }
 
+// ExecDetailsResponse is implemented by the responses which collect the runtime statistics
+// of the coprocessor requests.
+type ExecDetailsResponse interface {
+	// ExecDetails returns the statistics of the requests handled so far.
+	ExecDetails() execdetails.ExecDetails
+}
+
 // Snapshot defines the interface for the snapshot fetched from KV store.
 type Snapshot interface {
 	Retriever
@@ -192,6 +217,42 @@ type Storage interface {
 	CurrentVersion() (Version, error)
 }
 
+// BulkLoader is implemented by the storages which can ingest key-value pairs directly,
+// beneath the transaction layer.
+type BulkLoader interface {
+	// BulkLoad writes all the key-value pairs in the buffer with a new commit version and
+	// returns the version. Conflicts are not checked, the caller must make sure that no
+	// transaction writes the same keys concurrently.
+	BulkLoad(buf MemBuffer) (Version, error)
+}
+
+// RegionInfo is the location of a region and its replicas.
+type RegionInfo struct {
+	ID       uint64
+	StartKey Key
+	// EndKey is empty for the last region.
+	EndKey        Key
+	LeaderID      uint64
+	LeaderStoreID uint64
+	Peers         []uint64
+}
+
+// RegionStorage is implemented by the storages which distribute the data in regions.
+type RegionStorage interface {
+	// GetRegions returns the regions overlapping with the key range [startKey, endKey).
+	GetRegions(startKey, endKey Key) ([]RegionInfo, error)
+	// SplitRegion splits the region containing the key at the key, it's a no-op if the key
+	// is already the start key of a region.
+	SplitRegion(splitKey Key) error
+}
+
+// LowResolutionTSStorage is implemented by the storages which keep a recently fetched timestamp. Beginning a
+// transaction with it saves a timestamp request, but the transaction may miss the latest committed data.
+type LowResolutionTSStorage interface {
+	// LowResolutionTS returns the recently fetched timestamp.
+	LowResolutionTS() (uint64, error)
+}
+
 // FnKeyCmp is the function for iterator the keys
 type FnKeyCmp func(key Key) bool
 
@@ -32,6 +32,10 @@ type memDbBuffer struct {
 	entrySizeLimit  int
 	bufferLenLimit  uint64
 	bufferSizeLimit int
+	// baseLen and baseSize are the entry count and the size of the buffer under it, for a statement buffer
+	// they are counted in the limits together with its own.
+	baseLen  int
+	baseSize int
 }
 
 type memDbIter struct {
@@ -87,15 +91,12 @@ func (m *memDbBuffer) Set(k Key, v []byte) error {
 		return errors.Trace(ErrCannotSetNilValue)
 	}
 	if len(k)+len(v) > m.entrySizeLimit {
-		return ErrEntryTooLarge.Gen("entry too large, size: %d", len(k)+len(v))
+		return ErrEntryTooLarge.GenByArgs(len(k)+len(v), m.entrySizeLimit)
 	}
 
 	err := m.db.Put(k, v)
-	if m.Size() > m.bufferSizeLimit {
-		return ErrTxnTooLarge.Gen("transaction too large, size:%d", m.Size())
-	}
-	if m.Len() > int(m.bufferLenLimit) {
-		return ErrTxnTooLarge.Gen("transaction too large, len:%d", m.Len())
+	if length, size := m.baseLen+m.Len(), m.baseSize+m.Size(); size > m.bufferSizeLimit || length > int(m.bufferLenLimit) {
+		return ErrTxnTooLarge.GenByArgs(length, size, m.bufferLenLimit, m.bufferSizeLimit)
 	}
 	return errors.Trace(err)
 }
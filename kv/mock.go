@@ -93,6 +93,16 @@ func (t *mockTxn) Size() int {
 	return 0
 }
 
+func (t *mockTxn) StartStmt() {
+}
+
+func (t *mockTxn) CommitStmt() error {
+	return nil
+}
+
+func (t *mockTxn) RollbackStmt() {
+}
+
 // mockStorage is used to start a must commit-failed txn.
 type mockStorage struct {
 }
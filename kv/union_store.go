@@ -15,6 +15,7 @@ package kv
 
 import (
 	"bytes"
+	"sync/atomic"
 
 	"github.com/juju/errors"
 )
@@ -35,6 +36,13 @@ type UnionStore interface {
 	DelOption(opt Option)
 	// GetOption gets an option.
 	GetOption(opt Option) interface{}
+	// StartStmt creates a savepoint for a statement, the writes afterwards are buffered apart from the earlier
+	// ones until CommitStmt or RollbackStmt.
+	StartStmt()
+	// CommitStmt merges the writes since the savepoint into the transaction.
+	CommitStmt() error
+	// RollbackStmt discards the writes since the savepoint.
+	RollbackStmt()
 }
 
 // Option is used for customizing kv store's behaviors during a transaction.
@@ -57,20 +65,44 @@ type conditionPair struct {
 // UnionStore is an in-memory Store which contains a buffer for write and a
 // snapshot for read.
 type unionStore struct {
+	// BufferStore is txnStore, or the statement buffer on it after StartStmt.
 	*BufferStore
+	txnStore           *BufferStore
 	snapshot           Snapshot                    // for read
 	lazyConditionPairs map[string](*conditionPair) // for delay check
-	opts               options
+	// stmtLazyConditionPairs holds the lazy condition pairs marked by the statement, it's nil out of statements.
+	stmtLazyConditionPairs map[string](*conditionPair)
+	opts                   options
 }
 
 // NewUnionStore builds a new UnionStore.
 func NewUnionStore(snapshot Snapshot) UnionStore {
+	opts := make(map[Option]interface{})
+	txnStore := &BufferStore{
+		r:         snapshot,
+		MemBuffer: &lazyMemBuffer{opts: opts},
+	}
 	return &unionStore{
-		BufferStore:        NewBufferStore(snapshot),
+		BufferStore:        txnStore,
+		txnStore:           txnStore,
 		snapshot:           snapshot,
 		lazyConditionPairs: make(map[string](*conditionPair)),
-		opts:               make(map[Option]interface{}),
+		opts:               opts,
+	}
+}
+
+// GetTxnSizeLimits returns the entry count and total size limits of the transaction of the UnionStore,
+// they are TxnEntryCountLimit and TxnTotalSizeLimit unless overridden by the EntryCountLimit and TotalSizeLimit options.
+func GetTxnSizeLimits(us UnionStore) (entryCountLimit uint64, totalSizeLimit int) {
+	entryCountLimit = atomic.LoadUint64(&TxnEntryCountLimit)
+	totalSizeLimit = TxnTotalSizeLimit
+	if v, ok := us.GetOption(EntryCountLimit).(uint64); ok {
+		entryCountLimit = v
 	}
+	if v, ok := us.GetOption(TotalSizeLimit).(int); ok {
+		totalSizeLimit = v
+	}
+	return
 }
 
 // invalidIterator implements Iterator interface.
@@ -96,7 +128,23 @@ func (it invalidIterator) Value() []byte {
 func (it invalidIterator) Close() {}
 
 type lazyMemBuffer struct {
-	mb MemBuffer
+	mb   MemBuffer
+	opts options
+	// baseLen and baseSize are passed to the memDbBuffer, see memDbBuffer.
+	baseLen  int
+	baseSize int
+}
+
+func (lmb *lazyMemBuffer) newMemBuffer() MemBuffer {
+	mb := NewMemDbBuffer().(*memDbBuffer)
+	if v, ok := lmb.opts.Get(EntryCountLimit); ok {
+		mb.bufferLenLimit = v.(uint64)
+	}
+	if v, ok := lmb.opts.Get(TotalSizeLimit); ok {
+		mb.bufferSizeLimit = v.(int)
+	}
+	mb.baseLen, mb.baseSize = lmb.baseLen, lmb.baseSize
+	return mb
 }
 
 func (lmb *lazyMemBuffer) Get(k Key) ([]byte, error) {
@@ -109,7 +157,7 @@ func (lmb *lazyMemBuffer) Get(k Key) ([]byte, error) {
 
 func (lmb *lazyMemBuffer) Set(key Key, value []byte) error {
 	if lmb.mb == nil {
-		lmb.mb = NewMemDbBuffer()
+		lmb.mb = lmb.newMemBuffer()
 	}
 
 	return lmb.mb.Set(key, value)
@@ -117,7 +165,7 @@ func (lmb *lazyMemBuffer) Set(key Key, value []byte) error {
 
 func (lmb *lazyMemBuffer) Delete(k Key) error {
 	if lmb.mb == nil {
-		lmb.mb = NewMemDbBuffer()
+		lmb.mb = lmb.newMemBuffer()
 	}
 
 	return lmb.mb.Delete(k)
@@ -154,6 +202,9 @@ func (lmb *lazyMemBuffer) Len() int {
 // Get implements the Retriever interface.
 func (us *unionStore) Get(k Key) ([]byte, error) {
 	v, err := us.MemBuffer.Get(k)
+	if IsErrNotFound(err) && us.inStmt() {
+		v, err = us.txnStore.MemBuffer.Get(k)
+	}
 	if IsErrNotFound(err) {
 		if _, ok := us.opts.Get(PresumeKeyNotExists); ok {
 			e, ok := us.opts.Get(PresumeKeyNotExistsError)
@@ -166,7 +217,7 @@ func (us *unionStore) Get(k Key) ([]byte, error) {
 		}
 	}
 	if IsErrNotFound(err) {
-		v, err = us.BufferStore.r.Get(k)
+		v, err = us.snapshot.Get(k)
 	}
 	if err != nil {
 		return v, errors.Trace(err)
@@ -180,7 +231,11 @@ func (us *unionStore) Get(k Key) ([]byte, error) {
 // markLazyConditionPair marks a kv pair for later check.
 // If condition not match, should return e as error.
 func (us *unionStore) markLazyConditionPair(k Key, v []byte, e error) {
-	us.lazyConditionPairs[string(k)] = &conditionPair{
+	pairs := us.lazyConditionPairs
+	if us.inStmt() {
+		pairs = us.stmtLazyConditionPairs
+	}
+	pairs[string(k)] = &conditionPair{
 		key:   k.Clone(),
 		value: v,
 		err:   e,
@@ -215,6 +270,64 @@ func (us *unionStore) CheckLazyConditionPairs() error {
 	return nil
 }
 
+// Len implements the MemBuffer interface, the entries overwritten by the statement are counted twice.
+func (us *unionStore) Len() int {
+	if us.inStmt() {
+		return us.txnStore.Len() + us.BufferStore.Len()
+	}
+	return us.txnStore.Len()
+}
+
+// Size implements the MemBuffer interface, the entries overwritten by the statement are counted twice.
+func (us *unionStore) Size() int {
+	if us.inStmt() {
+		return us.txnStore.Size() + us.BufferStore.Size()
+	}
+	return us.txnStore.Size()
+}
+
+func (us *unionStore) inStmt() bool {
+	return us.BufferStore != us.txnStore
+}
+
+// StartStmt implements the UnionStore StartStmt interface.
+func (us *unionStore) StartStmt() {
+	if us.inStmt() {
+		return
+	}
+	// The statement buffer reads through the transaction buffer, and counts its entries in the limits so that
+	// merging the statement never exceeds them.
+	us.BufferStore = &BufferStore{
+		r: us.txnStore,
+		MemBuffer: &lazyMemBuffer{
+			opts:     us.opts,
+			baseLen:  us.txnStore.Len(),
+			baseSize: us.txnStore.Size(),
+		},
+	}
+	us.stmtLazyConditionPairs = make(map[string](*conditionPair))
+}
+
+// CommitStmt implements the UnionStore CommitStmt interface.
+func (us *unionStore) CommitStmt() error {
+	if !us.inStmt() {
+		return nil
+	}
+	stmtStore := us.BufferStore
+	us.BufferStore = us.txnStore
+	for k, v := range us.stmtLazyConditionPairs {
+		us.lazyConditionPairs[k] = v
+	}
+	us.stmtLazyConditionPairs = nil
+	return errors.Trace(stmtStore.SaveTo(us.txnStore))
+}
+
+// RollbackStmt implements the UnionStore RollbackStmt interface.
+func (us *unionStore) RollbackStmt() {
+	us.BufferStore = us.txnStore
+	us.stmtLazyConditionPairs = nil
+}
+
 // SetOption implements the UnionStore SetOption interface.
 func (us *unionStore) SetOption(opt Option, val interface{}) {
 	us.opts[opt] = val
@@ -125,6 +125,61 @@ func (s *testUnionStoreSuite) TestLazyConditionCheck(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testUnionStoreSuite) TestStmtSavepoint(c *C) {
+	defer testleak.AfterTest(c)()
+	s.store.Set([]byte("1"), []byte("1"))
+	s.store.Set([]byte("2"), []byte("2"))
+	s.us.Set([]byte("3"), []byte("3"))
+
+	// The rolled back statement leaves nothing in the transaction.
+	s.us.StartStmt()
+	s.us.Set([]byte("1"), []byte("11"))
+	s.us.Delete([]byte("3"))
+	s.us.Set([]byte("4"), []byte("4"))
+	v, err := s.us.Get([]byte("1"))
+	c.Assert(err, IsNil)
+	c.Assert(v, BytesEquals, []byte("11"))
+	_, err = s.us.Get([]byte("3"))
+	c.Assert(IsErrNotFound(err), IsTrue)
+	iter, err := s.us.Seek(nil)
+	c.Assert(err, IsNil)
+	checkIterator(c, iter, [][]byte{[]byte("1"), []byte("2"), []byte("4")}, [][]byte{[]byte("11"), []byte("2"), []byte("4")})
+	s.us.SetOption(PresumeKeyNotExists, nil)
+	_, err = s.us.Get([]byte("5"))
+	c.Assert(IsErrNotFound(err), IsTrue)
+	s.us.DelOption(PresumeKeyNotExists)
+	s.us.RollbackStmt()
+
+	c.Assert(s.us.Len(), Equals, 1)
+	c.Assert(s.us.CheckLazyConditionPairs(), IsNil)
+	iter, err = s.us.Seek(nil)
+	c.Assert(err, IsNil)
+	checkIterator(c, iter, [][]byte{[]byte("1"), []byte("2"), []byte("3")}, [][]byte{[]byte("1"), []byte("2"), []byte("3")})
+
+	// The committed statement is merged into the transaction.
+	s.us.StartStmt()
+	s.us.Delete([]byte("2"))
+	s.us.Set([]byte("3"), []byte("33"))
+	s.us.SetOption(PresumeKeyNotExists, nil)
+	_, err = s.us.Get([]byte("1"))
+	c.Assert(IsErrNotFound(err), IsTrue)
+	s.us.DelOption(PresumeKeyNotExists)
+	c.Assert(s.us.CommitStmt(), IsNil)
+
+	c.Assert(s.us.Len(), Equals, 2)
+	c.Assert(s.us.CheckLazyConditionPairs(), NotNil)
+	iter, err = s.us.SeekReverse(nil)
+	c.Assert(err, IsNil)
+	checkIterator(c, iter, [][]byte{[]byte("3"), []byte("1")}, [][]byte{[]byte("33"), []byte("1")})
+	var keys []string
+	err = s.us.WalkBuffer(func(k Key, v []byte) error {
+		keys = append(keys, string(k))
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(keys, DeepEquals, []string{"2", "3"})
+}
+
 func checkIterator(c *C, iter Iterator, keys [][]byte, values [][]byte) {
 	defer iter.Close()
 	c.Assert(len(keys), Equals, len(values))
@@ -17,18 +17,33 @@ import (
 	"math"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
 )
 
 // Test needs to change it, so it's a variable.
 var step = int64(5000)
 
-var errInvalidTableID = terror.ClassAutoid.New(codeInvalidTableID, "invalid TableID")
+// MaxStep is the max number of the auto IDs cached at a time.
+const MaxStep = int64(1) << 30
+
+// CentralizedStep is the AUTO_ID_CACHE value of the centralized allocation mode. In the mode, no ID is cached
+// by the servers and every ID is allocated from the storage, so the IDs are monotonically increasing across
+// the cluster in the order they are allocated, at the cost of the throughput.
+const CentralizedStep = int64(1)
+
+var (
+	errInvalidTableID = terror.ClassAutoid.New(codeInvalidTableID, "invalid TableID")
+	// ErrAutoincReadFailed is returned when the auto IDs of the table are exhausted.
+	ErrAutoincReadFailed = terror.ClassAutoid.New(codeAutoincReadFailed, mysql.MySQLErrName[mysql.ErrAutoincReadFailed])
+)
 
 // Allocator is an auto increment id generator.
 // Just keep id unique actually.
@@ -40,6 +55,9 @@ type Allocator interface {
 	// If allocIDs is true, it will allocate some IDs and save to the cache.
 	// If allocIDs is false, it will not allocate IDs.
 	Rebase(tableID, newBase int64, allocIDs bool) error
+	// NextGlobalAutoID returns the next ID which isn't allocated by any server. The IDs are
+	// allocated in batches, so it may be larger than the next ID allocated by this server.
+	NextGlobalAutoID(tableID int64) (int64, error)
 }
 
 type allocator struct {
@@ -48,6 +66,10 @@ type allocator struct {
 	end   int64
 	store kv.Storage
 	dbID  int64
+	// step is the number of the IDs cached at a time, the global step is used if it's 0.
+	step int64
+	// isUnsigned indicates the IDs are unsigned, they are kept in int64 and compared as uint64.
+	isUnsigned bool
 }
 
 // GetStep is only used by tests
@@ -55,6 +77,53 @@ func GetStep() int64 {
 	return step
 }
 
+func (alloc *allocator) getStep() int64 {
+	if alloc.step > 0 {
+		return alloc.step
+	}
+	return step
+}
+
+// lessEq compares the IDs according to the signedness of the allocator.
+func (alloc *allocator) lessEq(a, b int64) bool {
+	if alloc.isUnsigned {
+		return uint64(a) <= uint64(b)
+	}
+	return a <= b
+}
+
+// stepFrom returns the number of the IDs to cache after base, it's less than the step if the IDs
+// are nearly exhausted, and it's 0 if no ID is left.
+func (alloc *allocator) stepFrom(base int64) int64 {
+	var left uint64
+	if alloc.isUnsigned {
+		left = math.MaxUint64 - uint64(base)
+	} else if base < 0 {
+		left = math.MaxInt64
+	} else {
+		left = uint64(math.MaxInt64 - base)
+	}
+	n := alloc.getStep()
+	if left < uint64(n) {
+		log.Warnf("[kv] the auto IDs are nearly exhausted, %d left, database ID:%d", left, alloc.dbID)
+		return int64(left)
+	}
+	return n
+}
+
+// NextGlobalAutoID implements autoid.Allocator NextGlobalAutoID interface.
+func (alloc *allocator) NextGlobalAutoID(tableID int64) (int64, error) {
+	var autoID int64
+	startTime := time.Now()
+	err := kv.RunInNewTxn(alloc.store, false, func(txn kv.Transaction) error {
+		var err1 error
+		autoID, err1 = meta.NewMeta(txn).GetAutoTableID(alloc.dbID, tableID)
+		return errors.Trace(err1)
+	})
+	observeOperation(opGlobalAutoID, startTime, err)
+	return autoID + 1, errors.Trace(err)
+}
+
 // Rebase implements autoid.Allocator Rebase interface.
 func (alloc *allocator) Rebase(tableID, newBase int64, allocIDs bool) error {
 	if tableID == 0 {
@@ -63,27 +132,31 @@ func (alloc *allocator) Rebase(tableID, newBase int64, allocIDs bool) error {
 
 	alloc.mu.Lock()
 	defer alloc.mu.Unlock()
-	if newBase <= alloc.base {
+	if alloc.lessEq(newBase, alloc.base) {
 		return nil
 	}
-	if newBase <= alloc.end {
+	if alloc.lessEq(newBase, alloc.end) {
 		alloc.base = newBase
 		return nil
 	}
 
-	return kv.RunInNewTxn(alloc.store, true, func(txn kv.Transaction) error {
+	startTime := time.Now()
+	err := kv.RunInNewTxn(alloc.store, true, func(txn kv.Transaction) error {
 		m := meta.NewMeta(txn)
 		end, err := m.GetAutoTableID(alloc.dbID, tableID)
 		if err != nil {
 			return errors.Trace(err)
 		}
 
-		if newBase < end {
+		if alloc.lessEq(newBase, end) {
 			newBase = end
 		}
-		newStep := newBase - end + step
-		if !allocIDs {
-			newStep = newBase - end
+		// The difference is right for the unsigned IDs too, because it's computed in two's complement.
+		newStep := newBase - end
+		// No ID is cached in the centralized mode, otherwise the cached IDs are allocated after
+		// the larger IDs allocated by the other servers.
+		if allocIDs && alloc.step != CentralizedStep {
+			newStep += alloc.stepFrom(newBase)
 		}
 		end, err = m.GenAutoTableID(alloc.dbID, tableID, newStep)
 		if err != nil {
@@ -97,6 +170,12 @@ func (alloc *allocator) Rebase(tableID, newBase int64, allocIDs bool) error {
 		}
 		return nil
 	})
+	observeOperation(opRebase, startTime, err)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cachedIDsCounter.Add(float64(alloc.end - alloc.base))
+	return nil
 }
 
 // Alloc implements autoid.Allocator Alloc interface.
@@ -107,29 +186,31 @@ func (alloc *allocator) Alloc(tableID int64) (int64, error) {
 	alloc.mu.Lock()
 	defer alloc.mu.Unlock()
 	if alloc.base == alloc.end { // step
+		startTime := time.Now()
 		err := kv.RunInNewTxn(alloc.store, true, func(txn kv.Transaction) error {
 			m := meta.NewMeta(txn)
 			base, err1 := m.GetAutoTableID(alloc.dbID, tableID)
 			if err1 != nil {
 				return errors.Trace(err1)
 			}
-			end, err1 := m.GenAutoTableID(alloc.dbID, tableID, step)
+			n := alloc.stepFrom(base)
+			if n == 0 {
+				return ErrAutoincReadFailed.Gen("%s, the auto IDs of table %d are exhausted",
+					mysql.MySQLErrName[mysql.ErrAutoincReadFailed], tableID)
+			}
+			end, err1 := m.GenAutoTableID(alloc.dbID, tableID, n)
 			if err1 != nil {
 				return errors.Trace(err1)
 			}
 
-			alloc.end = end
-			if end == step {
-				alloc.base = base
-			} else {
-				alloc.base = end - step
-			}
+			alloc.base, alloc.end = end-n, end
 			return nil
 		})
-
+		observeOperation(opAlloc, startTime, err)
 		if err != nil {
 			return 0, errors.Trace(err)
 		}
+		cachedIDsCounter.Add(float64(alloc.end - alloc.base))
 	}
 
 	alloc.base++
@@ -149,6 +230,13 @@ type memoryAllocator struct {
 	dbID int64
 }
 
+// NextGlobalAutoID implements autoid.Allocator NextGlobalAutoID interface.
+func (alloc *memoryAllocator) NextGlobalAutoID(tableID int64) (int64, error) {
+	memIDLock.Lock()
+	defer memIDLock.Unlock()
+	return memID + 1, nil
+}
+
 // Rebase implements autoid.Allocator Rebase interface.
 func (alloc *memoryAllocator) Rebase(tableID, newBase int64, allocIDs bool) error {
 	// TODO: implement it.
@@ -181,6 +269,24 @@ func NewAllocator(store kv.Storage, dbID int64) Allocator {
 	}
 }
 
+// NewAllocatorFromTableInfo returns a new auto increment id generator of the table on the store. It caches
+// AUTO_ID_CACHE IDs at a time if the option is set, or it's in the centralized mode if the option is 1.
+// The IDs are unsigned if the auto increment column is unsigned.
+func NewAllocatorFromTableInfo(store kv.Storage, dbID int64, tblInfo *model.TableInfo) Allocator {
+	alloc := &allocator{
+		store: store,
+		dbID:  dbID,
+		step:  tblInfo.AutoIDCache,
+	}
+	for _, col := range tblInfo.Columns {
+		if mysql.HasAutoIncrementFlag(col.Flag) {
+			alloc.isUnsigned = mysql.HasUnsignedFlag(col.Flag)
+			break
+		}
+	}
+	return alloc
+}
+
 // NewMemoryAllocator returns a new auto increment id generator in memory.
 func NewMemoryAllocator(dbID int64) Allocator {
 	return &memoryAllocator{
@@ -189,7 +295,19 @@ func NewMemoryAllocator(dbID int64) Allocator {
 }
 
 //autoid error codes.
-const codeInvalidTableID terror.ErrCode = 1
+const (
+	codeInvalidTableID      terror.ErrCode = 1
+	codeAutoRandomExhausted terror.ErrCode = 2
+
+	codeAutoincReadFailed terror.ErrCode = 1467
+)
+
+func init() {
+	autoidMySQLErrCodes := map[terror.ErrCode]uint16{
+		codeAutoincReadFailed: mysql.ErrAutoincReadFailed,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassAutoid] = autoidMySQLErrCodes
+}
 
 var localSchemaID = int64(math.MaxInt64)
 
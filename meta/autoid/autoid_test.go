@@ -15,6 +15,7 @@ package autoid
 
 import (
 	"fmt"
+	"math"
 	"sync"
 	"testing"
 	"time"
@@ -23,8 +24,10 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/terror"
 )
 
 func TestT(t *testing.T) {
@@ -186,3 +189,143 @@ func (*testSuite) TestConcurrentAlloc(c *C) {
 	err = <-errCh
 	c.Assert(err, IsNil)
 }
+
+func (*testSuite) TestAllocFromTableInfo(c *C) {
+	driver := localstore.Driver{Driver: goleveldb.MemoryDriver{}}
+	store, err := driver.Open("memory")
+	c.Assert(err, IsNil)
+	defer store.Close()
+
+	cachedInfo := &model.TableInfo{ID: 1, Name: model.NewCIStr("t"), AutoIDCache: 10}
+	unsignedCol := &model.ColumnInfo{Name: model.NewCIStr("id")}
+	unsignedCol.Flag = mysql.AutoIncrementFlag | mysql.UnsignedFlag
+	unsignedInfo := &model.TableInfo{ID: 2, Name: model.NewCIStr("t1"), Columns: []*model.ColumnInfo{unsignedCol}}
+	signedInfo := &model.TableInfo{ID: 3, Name: model.NewCIStr("t2")}
+	err = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		err = m.CreateDatabase(&model.DBInfo{ID: 1, Name: model.NewCIStr("a")})
+		c.Assert(err, IsNil)
+		for _, info := range []*model.TableInfo{cachedInfo, unsignedInfo, signedInfo} {
+			err = m.CreateTable(1, info)
+			c.Assert(err, IsNil)
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+
+	// The allocator caches AUTO_ID_CACHE IDs at a time.
+	alloc := NewAllocatorFromTableInfo(store, 1, cachedInfo)
+	id, err := alloc.Alloc(1)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, int64(1))
+	alloc = NewAllocatorFromTableInfo(store, 1, cachedInfo)
+	id, err = alloc.Alloc(1)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, int64(11))
+	err = alloc.Rebase(1, 100, true)
+	c.Assert(err, IsNil)
+	nextID, err := alloc.NextGlobalAutoID(1)
+	c.Assert(err, IsNil)
+	c.Assert(nextID, Equals, int64(111))
+
+	// The unsigned IDs exceed MaxInt64.
+	alloc = NewAllocatorFromTableInfo(store, 1, unsignedInfo)
+	err = alloc.Rebase(2, math.MaxInt64, true)
+	c.Assert(err, IsNil)
+	id, err = alloc.Alloc(2)
+	c.Assert(err, IsNil)
+	c.Assert(uint64(id), Equals, uint64(math.MaxInt64)+1)
+	err = alloc.Rebase(2, 100, true)
+	c.Assert(err, IsNil)
+	id, err = alloc.Alloc(2)
+	c.Assert(err, IsNil)
+	c.Assert(uint64(id), Equals, uint64(math.MaxInt64)+2)
+	uintMax := uint64(math.MaxUint64)
+	err = alloc.Rebase(2, int64(uintMax-2), true)
+	c.Assert(err, IsNil)
+	id, err = alloc.Alloc(2)
+	c.Assert(err, IsNil)
+	c.Assert(uint64(id), Equals, uintMax-1)
+	id, err = alloc.Alloc(2)
+	c.Assert(err, IsNil)
+	c.Assert(uint64(id), Equals, uintMax)
+	_, err = alloc.Alloc(2)
+	c.Assert(terror.ErrorEqual(err, ErrAutoincReadFailed), IsTrue, Commentf("err %v", err))
+
+	// The signed IDs are exhausted at MaxInt64.
+	alloc = NewAllocatorFromTableInfo(store, 1, signedInfo)
+	err = alloc.Rebase(3, math.MaxInt64-1, true)
+	c.Assert(err, IsNil)
+	id, err = alloc.Alloc(3)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, int64(math.MaxInt64))
+	_, err = alloc.Alloc(3)
+	c.Assert(terror.ErrorEqual(err, ErrAutoincReadFailed), IsTrue, Commentf("err %v", err))
+	alloc = NewAllocatorFromTableInfo(store, 1, signedInfo)
+	_, err = alloc.Alloc(3)
+	c.Assert(terror.ErrorEqual(err, ErrAutoincReadFailed), IsTrue, Commentf("err %v", err))
+}
+
+func (*testSuite) TestCentralizedAlloc(c *C) {
+	driver := localstore.Driver{Driver: goleveldb.MemoryDriver{}}
+	store, err := driver.Open("memory")
+	c.Assert(err, IsNil)
+	defer store.Close()
+
+	tblInfo := &model.TableInfo{ID: 1, Name: model.NewCIStr("t"), AutoIDCache: CentralizedStep}
+	err = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		err = m.CreateDatabase(&model.DBInfo{ID: 1, Name: model.NewCIStr("a")})
+		c.Assert(err, IsNil)
+		err = m.CreateTable(1, tblInfo)
+		c.Assert(err, IsNil)
+		return nil
+	})
+	c.Assert(err, IsNil)
+
+	// The allocators of two servers allocate the IDs alternately.
+	allocs := []Allocator{NewAllocatorFromTableInfo(store, 1, tblInfo), NewAllocatorFromTableInfo(store, 1, tblInfo)}
+	lastID := int64(0)
+	for i := 0; i < 10; i++ {
+		id, err1 := allocs[i%2].Alloc(1)
+		c.Assert(err1, IsNil)
+		c.Assert(id, Equals, lastID+1)
+		lastID = id
+	}
+	// No ID is cached by the rebase, so the IDs are still increasing.
+	err = allocs[0].Rebase(1, 100, true)
+	c.Assert(err, IsNil)
+	id, err := allocs[1].Alloc(1)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, int64(101))
+	id, err = allocs[0].Alloc(1)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, int64(102))
+}
+
+func (*testSuite) TestAutoRandom(c *C) {
+	shardBits := uint64(5)
+	incrMask := int64(1)<<58 - 1
+	shards := make(map[int64]struct{})
+	for startTS := uint64(400000000000000000); startTS < 400000000000000000+100; startTS++ {
+		id, err := EncodeAutoRandom(10, shardBits, startTS)
+		c.Assert(err, IsNil)
+		c.Assert(id > 0, IsTrue)
+		c.Assert(id&incrMask, Equals, int64(10))
+		c.Assert(AutoRandomIncrement(id, shardBits), Equals, int64(10))
+		shards[id>>58] = struct{}{}
+	}
+	// The consecutive timestamps are scattered over the shards.
+	c.Assert(len(shards) > 16, IsTrue)
+
+	id, err := EncodeAutoRandom(incrMask, shardBits, 1)
+	c.Assert(err, IsNil)
+	c.Assert(AutoRandomIncrement(id, shardBits), Equals, incrMask)
+	_, err = EncodeAutoRandom(incrMask+1, shardBits, 1)
+	c.Assert(terror.ErrorEqual(err, ErrAutoRandomExhausted), IsTrue)
+
+	id, err = EncodeAutoRandom(1, MaxAutoRandomBits, ^uint64(0))
+	c.Assert(err, IsNil)
+	c.Assert(id > 0, IsTrue)
+	c.Assert(AutoRandomIncrement(id, MaxAutoRandomBits), Equals, int64(1))
+}
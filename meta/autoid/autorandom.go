@@ -0,0 +1,47 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoid
+
+import "github.com/pingcap/tidb/terror"
+
+// The number of the shard bits of an AUTO_RANDOM column.
+const (
+	DefaultAutoRandomBits = 5
+	MaxAutoRandomBits     = 15
+)
+
+// ErrAutoRandomExhausted is returned when the allocated ID doesn't fit in the bits left by the shard bits.
+var ErrAutoRandomExhausted = terror.ClassAutoid.New(codeAutoRandomExhausted, "the AUTO_RANDOM IDs are exhausted")
+
+// An AUTO_RANDOM ID is composed of the sign bit, which is always 0, the shard bits and the
+// incremental bits allocated by the Allocator. The shard bits come from the start timestamp of
+// the transaction, so the rows inserted by concurrent transactions are scattered over the
+// table, while the rows inserted by one transaction are still close to each other.
+
+// EncodeAutoRandom composes the AUTO_RANDOM ID from the allocated ID and the start timestamp of the transaction.
+func EncodeAutoRandom(id int64, shardBits uint64, startTS uint64) (int64, error) {
+	incrBits := 63 - shardBits
+	if id < 0 || id >= 1<<incrBits {
+		return 0, ErrAutoRandomExhausted.Gen("the AUTO_RANDOM ID %d exceeds %d bits", id, incrBits)
+	}
+	// Multiplying by the golden ratio spreads the consecutive timestamps over the shards.
+	shard := (startTS * 0x9e3779b97f4a7c15) >> (64 - shardBits)
+	return int64(shard<<incrBits) | id, nil
+}
+
+// AutoRandomIncrement returns the incremental bits of the AUTO_RANDOM ID, which is used to
+// rebase the Allocator, so the IDs allocated later never conflict with it.
+func AutoRandomIncrement(id int64, shardBits uint64) int64 {
+	return id & (1<<(63-shardBits) - 1)
+}
@@ -0,0 +1,59 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoid
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The types of the auto ID operations on the storage.
+const (
+	opAlloc        = "alloc"
+	opRebase       = "rebase"
+	opGlobalAutoID = "global_auto_id"
+)
+
+var (
+	operationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "autoid",
+			Name:      "operation_duration_seconds",
+			Help:      "Bucketed histogram of processing time (s) of the auto ID operations on the storage.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 20),
+		}, []string{"type", "result"})
+
+	cachedIDsCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "autoid",
+			Name:      "cached_ids_total",
+			Help:      "Counter of the auto IDs cached from the storage, the IDs not used before the server exits are lost.",
+		})
+)
+
+func init() {
+	prometheus.MustRegister(operationHistogram)
+	prometheus.MustRegister(cachedIDsCounter)
+}
+
+func observeOperation(op string, startTime time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	operationHistogram.WithLabelValues(op, result).Observe(time.Since(startTime).Seconds())
+}
@@ -42,13 +42,6 @@ var (
 			Help:      "Bucketed histogram of processing time (s) in running executor.",
 			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 13),
 		})
-	schemaLeaseErrorCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: "tidb",
-			Subsystem: "server",
-			Name:      "schema_lease_error_counter",
-			Help:      "Counter of schema lease error",
-		}, []string{"type"})
 	sessionRetry = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Namespace: "tidb",
@@ -57,12 +50,54 @@ var (
 			Help:      "Bucketed histogram of session retry count.",
 			Buckets:   prometheus.LinearBuckets(0, 1, 10),
 		})
+	transactionCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "session_transaction_total",
+			Help:      "Counter of transactions.",
+		}, []string{"type", "result"})
+	transactionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "session_transaction_commit_duration",
+			Help:      "Bucketed histogram of processing time (s) in committing transactions, retries included.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 22),
+		}, []string{"result"})
+	txnNearSizeLimitCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "session_transaction_near_size_limit_total",
+			Help:      "Counter of committing transactions whose entry count or size is near the limits.",
+		}, []string{"type"})
 )
 
+// Label values of transaction metrics.
+const (
+	txnCommit   = "commit"
+	txnRollback = "rollback"
+	resultOK    = "ok"
+	resultError = "error"
+
+	txnLimitEntries = "entries"
+	txnLimitSize    = "size"
+)
+
+func resultLabel(err error) string {
+	if err != nil {
+		return resultError
+	}
+	return resultOK
+}
+
 func init() {
 	prometheus.MustRegister(sessionExecuteParseDuration)
 	prometheus.MustRegister(sessionExecuteCompileDuration)
 	prometheus.MustRegister(sessionExecuteRunDuration)
-	prometheus.MustRegister(schemaLeaseErrorCounter)
 	prometheus.MustRegister(sessionRetry)
+	prometheus.MustRegister(transactionCounter)
+	prometheus.MustRegister(transactionDuration)
+	prometheus.MustRegister(txnNearSizeLimitCounter)
 }
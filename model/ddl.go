@@ -42,6 +42,8 @@ const (
 	ActionModifyColumn
 	ActionRenameTable
 	ActionSetDefaultValue
+	ActionCreateTrigger
+	ActionDropTrigger
 )
 
 func (action ActionType) String() string {
@@ -74,6 +76,10 @@ func (action ActionType) String() string {
 		return "rename table"
 	case ActionSetDefaultValue:
 		return "set default value"
+	case ActionCreateTrigger:
+		return "create trigger"
+	case ActionDropTrigger:
+		return "drop trigger"
 	default:
 		return "none"
 	}
@@ -236,6 +242,9 @@ type Owner struct {
 	// LastUpdateTS now uses unix nano seconds
 	// TODO: Use timestamp allocated by TSO.
 	LastUpdateTS int64 `json:"last_update_ts"`
+	// ResignedID is the ID of the owner who resigned, the ownership is released to the other servers,
+	// and the resigned owner can't take it again until the owner times out.
+	ResignedID string `json:"resigned_id,omitempty"`
 }
 
 // String implements fmt.Stringer interface.
@@ -14,9 +14,11 @@
 package model
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -67,6 +69,9 @@ type ColumnInfo struct {
 	types.FieldType    `json:"type"`
 	State              SchemaState `json:"state"`
 	Comment            string      `json:"comment"`
+	// DefaultIsExpr indicates DefaultValue is the text of an expression, which is evaluated
+	// every time the default value is needed.
+	DefaultIsExpr bool `json:"default_is_expr"`
 }
 
 // Clone clones ColumnInfo.
@@ -82,15 +87,23 @@ type TableInfo struct {
 	Charset string `json:"charset"`
 	Collate string `json:"collate"`
 	// Columns are listed in the order in which they appear in the schema.
-	Columns     []*ColumnInfo `json:"cols"`
-	Indices     []*IndexInfo  `json:"index_info"`
-	ForeignKeys []*FKInfo     `json:"fk_info"`
-	State       SchemaState   `json:"state"`
-	PKIsHandle  bool          `json:"pk_is_handle"`
-	Comment     string        `json:"comment"`
-	AutoIncID   int64         `json:"auto_inc_id"`
-	MaxColumnID int64         `json:"max_col_id"`
-	MaxIndexID  int64         `json:"max_idx_id"`
+	Columns     []*ColumnInfo  `json:"cols"`
+	Indices     []*IndexInfo   `json:"index_info"`
+	ForeignKeys []*FKInfo      `json:"fk_info"`
+	Triggers    []*TriggerInfo `json:"trigger_info"`
+	State       SchemaState    `json:"state"`
+	PKIsHandle  bool           `json:"pk_is_handle"`
+	Comment     string         `json:"comment"`
+	AutoIncID   int64          `json:"auto_inc_id"`
+	MaxColumnID int64          `json:"max_col_id"`
+	MaxIndexID  int64          `json:"max_idx_id"`
+	// AutoRandomBits is the number of the shard bits of the AUTO_RANDOM primary key,
+	// it's 0 if the table doesn't have an AUTO_RANDOM column.
+	AutoRandomBits uint64 `json:"auto_random_bits,omitempty"`
+	// AutoIDCache is the number of the auto IDs cached by a server at a time,
+	// it's 0 if the default cache size is used. No ID is cached if it's 1, so the
+	// IDs are monotonically increasing across the servers.
+	AutoIDCache int64 `json:"auto_id_cache,omitempty"`
 	// OldSchemaID :
 	// Because auto increment ID has schemaID as prefix,
 	// We need to save original schemaID to keep autoID unchanged
@@ -104,6 +117,7 @@ func (t *TableInfo) Clone() *TableInfo {
 	nt.Columns = make([]*ColumnInfo, len(t.Columns))
 	nt.Indices = make([]*IndexInfo, len(t.Indices))
 	nt.ForeignKeys = make([]*FKInfo, len(t.ForeignKeys))
+	nt.Triggers = make([]*TriggerInfo, len(t.Triggers))
 
 	for i := range t.Columns {
 		nt.Columns[i] = t.Columns[i].Clone()
@@ -117,6 +131,10 @@ func (t *TableInfo) Clone() *TableInfo {
 		nt.ForeignKeys[i] = t.ForeignKeys[i].Clone()
 	}
 
+	for i := range t.Triggers {
+		nt.Triggers[i] = t.Triggers[i].Clone()
+	}
+
 	return &nt
 }
 
@@ -137,14 +155,40 @@ type IndexColumn struct {
 	Name   CIStr `json:"name"`   // Index name
 	Offset int   `json:"offset"` // Index offset
 	Length int   `json:"length"` // Index length
+	// MultiValued is not nil if the column is the key part of a multi-valued index.
+	MultiValued *MultiValuedKeyPart `json:"multi_valued,omitempty"`
 }
 
 // Clone clones IndexColumn.
 func (i *IndexColumn) Clone() *IndexColumn {
 	ni := *i
+	if i.MultiValued != nil {
+		mv := *i.MultiValued
+		ni.MultiValued = &mv
+	}
 	return &ni
 }
 
+// MultiValuedKeyPart is the key part `CAST(json_path AS type ARRAY)` of a multi-valued index, every
+// distinct element of the JSON array at Path of the column is converted to Tp and indexed.
+type MultiValuedKeyPart struct {
+	Path string          `json:"path"`
+	Tp   types.FieldType `json:"type"`
+}
+
+// TypeString returns the array type in CAST, which is SIGNED, UNSIGNED, CHAR(N) or BINARY(N).
+func (mv *MultiValuedKeyPart) TypeString() string {
+	switch {
+	case mv.Tp.Tp == mysql.TypeLonglong && mysql.HasUnsignedFlag(mv.Tp.Flag):
+		return "UNSIGNED"
+	case mv.Tp.Tp == mysql.TypeLonglong:
+		return "SIGNED"
+	case mv.Tp.Charset == charset.CharsetBin:
+		return fmt.Sprintf("BINARY(%d)", mv.Tp.Flen)
+	}
+	return fmt.Sprintf("CHAR(%d)", mv.Tp.Flen)
+}
+
 // IndexType is the type of index
 type IndexType int
 
@@ -178,6 +222,9 @@ type IndexInfo struct {
 	State   SchemaState    `json:"state"`
 	Comment string         `json:"comment"`    // Comment
 	Tp      IndexType      `json:"index_type"` // Index type: Btree or Hash
+	// Clustered is only set on a primary key that is not the handle. The value of every entry holds
+	// the whole row after the handle, so a row can be read by its primary key without the record.
+	Clustered bool `json:"is_clustered"`
 }
 
 // Clone clones IndexInfo.
@@ -190,6 +237,17 @@ func (index *IndexInfo) Clone() *IndexInfo {
 	return &ni
 }
 
+// IsMultiValued returns whether the index is a multi-valued index, which has an entry for every
+// element of a JSON array.
+func (index *IndexInfo) IsMultiValued() bool {
+	for _, ic := range index.Columns {
+		if ic.MultiValued != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // HasPrefixIndex returns whether any columns of this index uses prefix length.
 func (index *IndexInfo) HasPrefixIndex() bool {
 	for _, ic := range index.Columns {
@@ -224,6 +282,69 @@ func (fk *FKInfo) Clone() *FKInfo {
 	return &nfk
 }
 
+// TriggerTiming is the action time of a trigger.
+type TriggerTiming int
+
+// Trigger timings.
+const (
+	TriggerBefore TriggerTiming = iota + 1
+	TriggerAfter
+)
+
+// String implements fmt.Stringer interface.
+func (t TriggerTiming) String() string {
+	switch t {
+	case TriggerBefore:
+		return "BEFORE"
+	case TriggerAfter:
+		return "AFTER"
+	}
+	return ""
+}
+
+// TriggerEvent is the kind of row operation that activates a trigger.
+type TriggerEvent int
+
+// Trigger events.
+const (
+	TriggerInsert TriggerEvent = iota + 1
+	TriggerUpdate
+	TriggerDelete
+)
+
+// String implements fmt.Stringer interface.
+func (e TriggerEvent) String() string {
+	switch e {
+	case TriggerInsert:
+		return "INSERT"
+	case TriggerUpdate:
+		return "UPDATE"
+	case TriggerDelete:
+		return "DELETE"
+	}
+	return ""
+}
+
+// TriggerInfo provides meta data describing a row-level trigger.
+// It corresponds to the statement `CREATE TRIGGER Name Timing Event ON Table FOR EACH ROW Body;`
+// See https://dev.mysql.com/doc/refman/5.7/en/create-trigger.html
+type TriggerInfo struct {
+	Name   CIStr         `json:"trigger_name"`
+	Timing TriggerTiming `json:"timing"`
+	Event  TriggerEvent  `json:"event"`
+	// Statement is the original CREATE TRIGGER statement, the trigger body is parsed from it when the trigger fires.
+	Statement string `json:"statement"`
+	// Body is the original text of the trigger body, it is shown by SHOW TRIGGERS.
+	Body  string      `json:"body"`
+	State SchemaState `json:"state"`
+}
+
+// Clone clones TriggerInfo.
+func (trigger *TriggerInfo) Clone() *TriggerInfo {
+	nt := *trigger
+	return &nt
+}
+
 // DBInfo provides meta data describing a DB.
 type DBInfo struct {
 	ID      int64        `json:"id"`      // Database ID
@@ -67,6 +67,7 @@ func (*testSuite) TestClone(c *C) {
 		Columns:     []*ColumnInfo{column},
 		Indices:     []*IndexInfo{index},
 		ForeignKeys: []*FKInfo{},
+		Triggers:    []*TriggerInfo{{Name: NewCIStr("trg"), Timing: TriggerBefore, Event: TriggerInsert}},
 	}
 
 	dbInfo := &DBInfo{
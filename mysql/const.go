@@ -57,6 +57,9 @@ const (
 	MaxColumnNameLength   int = 64
 )
 
+// MaxFieldVarCharLength is the max byte length of a VARCHAR column.
+const MaxFieldVarCharLength = 65535
+
 // Command informations.
 const (
 	ComSleep byte = iota
@@ -147,6 +150,18 @@ const (
 	GlobalStatusTable = "GLOBAL_STATUS"
 	// TiDBTable is the table contains tidb info.
 	TiDBTable = "tidb"
+	// EventTable is the table contains event definitions.
+	EventTable = "event"
+	// ExprPushdownBlacklistTable is the table contains the functions that can't be pushed down to the storage.
+	ExprPushdownBlacklistTable = "expr_pushdown_blacklist"
+	// ResourceGroupTable is the table contains the limits of the resource groups.
+	ResourceGroupTable = "resource_group"
+	// UserResourceGroupTable is the table contains the resource groups the users are assigned to.
+	UserResourceGroupTable = "user_resource_group"
+	// PrivilegeHistoryTable is the table contains the history of the privilege-changing statements.
+	PrivilegeHistoryTable = "privilege_history"
+	// BootstrapHistoryTable is the table contains the history of the bootstrap and upgrade steps.
+	BootstrapHistoryTable = "bootstrap_history"
 )
 
 // PrivilegeType  privilege
@@ -170,7 +185,7 @@ const (
 	SuperPriv
 	// CreateUserPriv is the privilege to create user.
 	CreateUserPriv
-	// TriggerPriv is not checked yet.
+	// TriggerPriv is the privilege to create or drop triggers.
 	TriggerPriv
 	// DropPriv is the privilege to drop schema/table.
 	DropPriv
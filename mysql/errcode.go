@@ -882,7 +882,17 @@ const (
 	ErrMustChangePasswordLogin                                      = 1862
 	ErrRowInWrongPartition                                          = 1863
 	ErrErrorLast                                                    = 1863
+	ErrFkDepthExceeded                                              = 3008
+	ErrUserLockWrongName                                            = 3057
+	ErrUserLockDeadlock                                             = 3058
 	ErrInvalidJSONText                                              = 3140
 	ErrInvalidJSONPath                                              = 3143
 	ErrInvalidJSONData                                              = 3146
+	ErrJSONDocumentNULLKey                                          = 3158
+	ErrWindowInvalidWindowFuncUse                                   = 3593
+	ErrInvalidLateralJoin                                           = 3809
+
+	// TiDB self-defined errors.
+	ErrTxnTooLarge   = 8004
+	ErrEntryTooLarge = 8025
 )
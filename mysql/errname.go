@@ -102,12 +102,12 @@ var MySQLErrName = map[uint16]string{
 	ErrBlobsAndNoTerminated:                     "You can't use fixed rowlength with BLOBs; please use 'fields terminated by'",
 	ErrTextFileNotReadable:                      "The file '%-.128s' must be in the database directory or be readable by all",
 	ErrFileExists:                               "File '%-.200s' already exists",
-	ErrLoadInfo:                                 "Records: %ld  Deleted: %ld  Skipped: %ld  Warnings: %ld",
+	ErrLoadInfo:                                 "Records: %d  Deleted: %d  Skipped: %d  Warnings: %d",
 	ErrAlterInfo:                                "Records: %ld  Duplicates: %ld",
 	ErrWrongSubKey:                              "Incorrect prefix key; the used key part isn't a string, the used length is longer than the key part, or the storage engine doesn't support unique prefix keys",
 	ErrCantRemoveAllFields:                      "You can't delete all columns with ALTER TABLE; use DROP TABLE instead",
 	ErrCantDropFieldOrKey:                       "Can't DROP '%-.192s'; check that column/key exists",
-	ErrInsertInfo:                               "Records: %ld  Duplicates: %ld  Warnings: %ld",
+	ErrInsertInfo:                               "Records: %d  Duplicates: %d  Warnings: %d",
 	ErrUpdateTableUsed:                          "You can't specify target table '%-.192s' for update in FROM clause",
 	ErrNoSuchThread:                             "Unknown thread id: %lu",
 	ErrKillDenied:                               "You are not owner of thread %lu",
@@ -149,7 +149,7 @@ var MySQLErrName = map[uint16]string{
 	ErrPasswordAnonymousUser:                    "You are using MySQL as an anonymous user and anonymous users are not allowed to change passwords",
 	ErrPasswordNotAllowed:                       "You must have privileges to update tables in the mysql database to be able to change passwords for others",
 	ErrPasswordNoMatch:                          "Can't find any matching row in the user table",
-	ErrUpdateInfo:                               "Rows matched: %ld  Changed: %ld  Warnings: %ld",
+	ErrUpdateInfo:                               "Rows matched: %d  Changed: %d  Warnings: %d",
 	ErrCantCreateThread:                         "Can't create a new thread (errno %d); if you are not out of available memory, you can consult the manual for a possible OS-dependent bug",
 	ErrWrongValueCountOnRow:                     "Column count doesn't match value count at row %ld",
 	ErrCantReopenTable:                          "Can't reopen table: '%-.192s'",
@@ -879,7 +879,17 @@ var MySQLErrName = map[uint16]string{
 	ErrAlterOperationNotSupportedReasonNotNull:               "cannot silently convert NULL values, as required in this SQLMODE",
 	ErrMustChangePasswordLogin:                               "Your password has expired. To log in you must change it using a client that supports expired passwords.",
 	ErrRowInWrongPartition:                                   "Found a row in wrong partition %s",
+	ErrFkDepthExceeded:                                       "Foreign key cascade delete/update exceeds max depth of %d.",
+	ErrUserLockWrongName:                                     "Incorrect user-level lock name '%-.192s'.",
+	ErrUserLockDeadlock:                                      "Deadlock found when trying to get user-level lock; try rolling back transaction/releasing locks and restarting lock acquisition.",
 	ErrInvalidJSONText:                                       "Invalid JSON text: %-.192s",
 	ErrInvalidJSONPath:                                       "Invalid JSON path expression",
 	ErrInvalidJSONData:                                       "Invalid data type for JSON data",
+	ErrJSONDocumentNULLKey:                                   "JSON documents may not contain NULL member names.",
+	ErrWindowInvalidWindowFuncUse:                            "You cannot use the window function '%s' in this context.",
+	ErrInvalidLateralJoin:                                    "INNER or LEFT JOIN must be used for LATERAL references made by '%s'",
+
+	// TiDB errors.
+	ErrTxnTooLarge:   "Transaction is too large, entries: %d, size: %d bytes",
+	ErrEntryTooLarge: "Entry is too large, size: %d bytes",
 }
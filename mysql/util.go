@@ -47,7 +47,8 @@ func GetDefaultDecimal(tp byte) int {
 	case TypeNewDecimal:
 		// See https://dev.mysql.com/doc/refman/5.7/en/fixed-point-types.html
 		return 0
-	case TypeDatetime:
+	case TypeDatetime, TypeTimestamp, TypeDuration:
+		// See https://dev.mysql.com/doc/refman/5.7/en/fractional-seconds.html
 		return 0
 	default:
 		//TODO: Add more types.
@@ -0,0 +1,140 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// collapsedList replaces a list of literals in an IN list or a VALUES clause.
+const collapsedList = "(...)"
+
+// Normalize returns the normalized form of the SQL text. The literals are replaced by '?', the
+// lists of literals in IN and VALUES are collapsed, the comments are removed, the keywords and
+// the identifiers are converted to lower case and the tokens are separated by a single space.
+// The SQL statements which only differ in those parts have the same normalized form.
+func Normalize(sql string) string {
+	return strings.Join(normalizeTokens(sql), " ")
+}
+
+// NormalizeDigest returns the normalized form of the SQL text and its digest, the digest is the
+// hex encoded SHA-256 hash of the normalized form.
+func NormalizeDigest(sql string) (normalized, digest string) {
+	normalized = Normalize(sql)
+	return normalized, fmt.Sprintf("%x", sha256.Sum256([]byte(normalized)))
+}
+
+// hiddenPassword replaces the password literals hidden by HidePasswords.
+const hiddenPassword = "'***'"
+
+// HidePasswords returns the SQL text of an account management statement with the password
+// literals, like the ones in IDENTIFIED BY 'password' and SET PASSWORD = PASSWORD('password'),
+// replaced by '***'. The string literals which follow BY, PASSWORD, PASSWORD( or = are regarded
+// as passwords, the other parts of the text are kept as is.
+func HidePasswords(sql string) string {
+	s := NewScanner(sql)
+	var buf bytes.Buffer
+	var prev, prev2 string
+	last := 0
+	for {
+		tok, pos, _ := s.scan()
+		if tok == 0 || (tok == unicode.ReplacementChar && s.r.eof()) {
+			break
+		}
+		end := s.r.pos().Offset
+		if tok == stringLit && (prev == "by" || prev == "password" || prev == "=" || (prev == "(" && prev2 == "password")) {
+			buf.WriteString(sql[last:pos.Offset])
+			buf.WriteString(hiddenPassword)
+			last = end
+		}
+		prev, prev2 = strings.ToLower(sql[pos.Offset:end]), prev
+	}
+	buf.WriteString(sql[last:])
+	return buf.String()
+}
+
+func normalizeTokens(sql string) []string {
+	s := NewScanner(sql)
+	var tokens []string
+	for {
+		inComment := s.specialComment != nil
+		tok, pos, lit := s.scan()
+		if tok == 0 || (tok == unicode.ReplacementChar && s.r.eof()) {
+			break
+		}
+		if lit == "" && !inComment && s.specialComment == nil {
+			// The operators scanned by a rule function may have no literal.
+			lit = sql[pos.Offset:s.r.pos().Offset]
+		}
+		switch tok {
+		case stringLit, intLit, floatLit, decLit, hexLit, bitLit:
+			tokens = append(tokens, "?")
+		case identifier:
+			if next := s.r.peek(); (next == '\'' || next == '"') && handleIdent(&yySymType{ident: lit}) == underscoreCS {
+				// The character set introducer of a string literal is dropped with the literal.
+				break
+			}
+			tokens = append(tokens, strings.ToLower(lit))
+		case userVar, sysVar:
+			tokens = append(tokens, strings.ToLower(lit))
+		case quotedIdentifier:
+			tokens = append(tokens, "`"+strings.ToLower(strings.Replace(lit, "`", "``", -1))+"`")
+		case underscoreCS:
+		case hintBegin:
+			tokens = append(tokens, "/*+")
+		case hintEnd:
+			tokens = append(tokens, "*/")
+		default:
+			if lit == "" && tok < unicode.MaxASCII {
+				lit = string(rune(tok))
+			}
+			tokens = append(tokens, strings.ToLower(lit))
+		}
+		if tok == ')' {
+			tokens = collapseList(tokens)
+		}
+	}
+	return tokens
+}
+
+// collapseList collapses the list of literals ended by the last token, which is ')'. The lists
+// in IN and VALUES are collapsed, so the statements only differ in the number of values have
+// the same normalized form. The rows after the first one in VALUES are removed.
+func collapseList(tokens []string) []string {
+	end := len(tokens) - 1
+	start := end - 1
+	for ; start >= 0; start-- {
+		if tokens[start] != "?" && tokens[start] != "," {
+			break
+		}
+	}
+	if start < 0 || tokens[start] != "(" || start == end-1 {
+		return tokens
+	}
+	if start > 0 {
+		switch tokens[start-1] {
+		case "in", "values", "value":
+			return append(tokens[:start], collapsedList)
+		case ",":
+			if start > 1 && tokens[start-2] == collapsedList {
+				return tokens[:start-1]
+			}
+		}
+	}
+	return tokens
+}
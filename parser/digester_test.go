@@ -0,0 +1,71 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testDigesterSuite{})
+
+type testDigesterSuite struct {
+}
+
+func (s *testDigesterSuite) TestNormalize(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql    string
+		expect string
+	}{
+		{"SELECT 1", "select ?"},
+		{"select * from t where a = 'x' and b >= 1.5 and c != -3", "select * from t where a = ? and b >= ? and c != - ?"},
+		{"select * from `T` where A in (1, 2, 3)", "select * from `t` where a in (...)"},
+		{"select * from t where a in (select b from t1 where c = x'ab')", "select * from t where a in ( select b from t1 where c = ? )"},
+		{"insert into t values (1, 'a'), (2, 'b'), (3, 'c')", "insert into t values (...)"},
+		{"insert into t (a, b) values (1, 2) on duplicate key update b = 3", "insert into t ( a , b ) values (...) on duplicate key update b = ?"},
+		{"select   /* comment */ a from t -- comment\n where b = _utf8'x'", "select a from t where b = ?"},
+		{"select @a, @@session.autocommit, ?", "select @a , @@session.autocommit , ?"},
+		{"select concat(a, 'x', 'y') from t limit 10, 20", "select concat ( a , ? , ? ) from t limit ? , ?"},
+	}
+	for _, t := range tests {
+		c.Check(Normalize(t.sql), Equals, t.expect, Commentf("%s", t.sql))
+	}
+
+	normalized1, digest1 := NormalizeDigest("select * from t where a = 1")
+	normalized2, digest2 := NormalizeDigest("SELECT *  FROM t WHERE a = 2")
+	c.Assert(normalized1, Equals, normalized2)
+	c.Assert(digest1, Equals, digest2)
+	c.Assert(digest1, HasLen, 64)
+	_, digest3 := NormalizeDigest("select * from t where b = 1")
+	c.Assert(digest1, Not(Equals), digest3)
+}
+
+func (s *testDigesterSuite) TestHidePasswords(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql    string
+		expect string
+	}{
+		{"CREATE USER 'u'@'%' IDENTIFIED BY 'secret'", "CREATE USER 'u'@'%' IDENTIFIED BY '***'"},
+		{"create user u1 identified by 'a', 'u2'@'localhost' identified by password '*B1D6'", "create user u1 identified by '***', 'u2'@'localhost' identified by password '***'"},
+		{"GRANT SELECT ON test.* TO 'u'@'%' IDENTIFIED BY \"it's\"", "GRANT SELECT ON test.* TO 'u'@'%' IDENTIFIED BY '***'"},
+		{"SET PASSWORD FOR 'u'@'%' = PASSWORD('secret')", "SET PASSWORD FOR 'u'@'%' = PASSWORD('***')"},
+		{"set password = 'secret'", "set password = '***'"},
+		{"REVOKE SELECT ON *.* FROM 'u'@'%'", "REVOKE SELECT ON *.* FROM 'u'@'%'"},
+	}
+	for _, t := range tests {
+		c.Check(HidePasswords(t.sql), Equals, t.expect, Commentf("%s", t.sql))
+	}
+}
@@ -135,6 +135,9 @@ func (s *Scanner) Lex(v *yySymType) int {
 	if tok == identifier {
 		tok = handleIdent(v)
 	}
+	if tok == identifier && s.specialComment == nil && strings.EqualFold(lit, "member") && s.scanOf() {
+		return memberOf
+	}
 	if tok == identifier {
 		if tok1 := isTokenIdentifier(lit, &s.buf); tok1 != 0 {
 			tok = tok1
@@ -171,6 +174,20 @@ func (s *Scanner) Lex(v *yySymType) int {
 	return tok
 }
 
+// scanOf consumes the OF following MEMBER if there is, so MEMBER OF is lexed as a single token and
+// MEMBER is still a valid identifier elsewhere.
+func (s *Scanner) scanOf() bool {
+	r := s.r
+	r.incAsLongAs(unicode.IsSpace)
+	pos := r.pos()
+	r.incAsLongAs(isIdentChar)
+	if !strings.EqualFold(r.data(&pos), "of") {
+		return false
+	}
+	s.r = r
+	return true
+}
+
 // SetSQLMode sets the SQL mode for scanner.
 func (s *Scanner) SetSQLMode(mode mysql.SQLMode) {
 	s.sqlMode = mode
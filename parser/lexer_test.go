@@ -57,7 +57,7 @@ func (s *testLexerSuite) TestSingleCharOther(c *C) {
 	defer testleak.AfterTest(c)()
 	table := []testCaseItem{
 		{"@", at},
-		{"AT", identifier},
+		{"AT", atKwd},
 		{"?", placeholder},
 		{"PLACEHOLDER", identifier},
 		{"=", eq},
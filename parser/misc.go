@@ -149,15 +149,20 @@ var tokenMap = map[string]int{
 	"ANALYZE":                    analyze,
 	"AND":                        and,
 	"ANY":                        any,
+	"ARRAY":                      array,
 	"AS":                         as,
 	"ASC":                        asc,
 	"ASIN":                       asin,
 	"ASCII":                      ascii,
+	"AT":                         atKwd,
 	"ATAN":                       atan,
 	"ATAN2":                      atan2,
+	"AUTO_ID_CACHE":              autoIdCache,
 	"AUTO_INCREMENT":             autoIncrement,
+	"AUTO_RANDOM":                autoRandom,
 	"AVG":                        avg,
 	"AVG_ROW_LENGTH":             avgRowLength,
+	"BEFORE":                     before,
 	"BEGIN":                      begin,
 	"BETWEEN":                    between,
 	"BIN":                        bin,
@@ -184,9 +189,11 @@ var tokenMap = map[string]int{
 	"COMMIT":                     commit,
 	"COMMITTED":                  committed,
 	"COMPACT":                    compact,
+	"COMPLETION":                 completion,
 	"COMPRESSED":                 compressed,
 	"COMPRESSION":                compression,
 	"CONCAT":                     concat,
+	"CONFIG":                     config,
 	"CONCAT_WS":                  concatWs,
 	"CONVERT_TZ":                 convertTz,
 	"CONNECTION":                 connection,
@@ -237,24 +244,30 @@ var tokenMap = map[string]int{
 	"DUAL":                       dual,
 	"DUPLICATE":                  duplicate,
 	"DYNAMIC":                    dynamic,
+	"EACH":                       each,
 	"FROM_DAYS":                  fromDays,
 	"ELSE":                       elseKwd,
 	"ELT":                        elt,
 	"ENABLE":                     enable,
 	"ENCLOSED":                   enclosed,
 	"END":                        end,
+	"ENDS":                       ends,
 	"ENGINE":                     engine,
 	"ENGINES":                    engines,
 	"ENUM":                       enum,
+	"ERRORS":                     errorsKwd,
 	"ESCAPE":                     escape,
 	"ESCAPED":                    escaped,
 	"EXCLUSIVE":                  exclusive,
+	"EVENT":                      event,
 	"EVENTS":                     events,
+	"EVERY":                      every,
 	"EXECUTE":                    execute,
 	"EXISTS":                     exists,
 	"EXP":                        exp,
 	"EXPLAIN":                    explain,
 	"EXPORT_SET":                 exportSet,
+	"EXPR_PUSHDOWN_BLACKLIST":    exprPushdownBlacklist,
 	"EXTRACT":                    extract,
 	"FALSE":                      falseKwd,
 	"FIELD":                      fieldKwd,
@@ -286,16 +299,20 @@ var tokenMap = map[string]int{
 	"HASH":                       hash,
 	"HAVING":                     having,
 	"HIGH_PRIORITY":              highPriority,
+	"HOTSPOTS":                   hotspots,
 	"HOUR":                       hour,
 	"HEX":                        hex,
 	"UNHEX":                      unhex,
 	"IDENTIFIED":                 identified,
 	"IGNORE":                     ignore,
+	"INTERNAL":                   internal,
 	"IF":                         ifKwd,
 	"IFNULL":                     ifNull,
 	"IN":                         in,
 	"INDEX":                      index,
 	"INDEXES":                    indexes,
+	"INDEX_ASC":                  indexAsc,
+	"INDEX_DESC":                 indexDesc,
 	"INFILE":                     infile,
 	"INNER":                      inner,
 	"INSERT":                     insert,
@@ -311,6 +328,7 @@ var tokenMap = map[string]int{
 	"KEY_BLOCK_SIZE":             keyBlockSize,
 	"KEYS":                       keys,
 	"LAST_INSERT_ID":             lastInsertID,
+	"LATERAL":                    lateral,
 	"LEADING":                    leading,
 	"LEAST":                      least,
 	"LEFT":                       left,
@@ -337,6 +355,7 @@ var tokenMap = map[string]int{
 	"MAKEDATE":                   makeDate,
 	"MAKETIME":                   makeTime,
 	"MAKE_SET":                   makeSet,
+	"MASTER":                     master,
 	"MAX":                        max,
 	"MAXVALUE":                   maxValue,
 	"MAX_ROWS":                   maxRows,
@@ -352,6 +371,7 @@ var tokenMap = map[string]int{
 	"MONTHNAME":                  monthname,
 	"NAMES":                      names,
 	"NATIONAL":                   national,
+	"NATURAL":                    natural,
 	"NONE":                       none,
 	"NOT":                        not,
 	"NO_WRITE_TO_BINLOG":         noWriteToBinLog,
@@ -367,6 +387,8 @@ var tokenMap = map[string]int{
 	"ORD":                        ord,
 	"ORDER":                      order,
 	"OUTER":                      outer,
+	"OVER":                       over,
+	"OWNER":                      owner,
 	"PASSWORD":                   password,
 	"PERIOD_ADD":                 periodAdd,
 	"PERIOD_DIFF":                periodDiff,
@@ -375,6 +397,7 @@ var tokenMap = map[string]int{
 	"POW":                        pow,
 	"POWER":                      power,
 	"PREPARE":                    prepare,
+	"PRESERVE":                   preserve,
 	"PRIMARY":                    primary,
 	"PRIVILEGES":                 privileges,
 	"PROCEDURE":                  procedure,
@@ -387,15 +410,21 @@ var tokenMap = map[string]int{
 	"QUOTE":                      quote,
 	"RANGE":                      rangeKwd,
 	"RAND":                       rand,
+	"RANK":                       rank,
 	"READ":                       read,
+	"RECENT":                     recent,
 	"REDUNDANT":                  redundant,
 	"REFERENCES":                 references,
+	"REGIONS":                    regions,
 	"REGEXP":                     regexpKwd,
 	"RELEASE_LOCK":               releaseLock,
+	"RELOAD":                     reload,
 	"RENAME":                     rename,
 	"REPEAT":                     repeat,
 	"REPEATABLE":                 repeatable,
 	"REPLACE":                    replace,
+	"RESOURCE_GROUPS":            resourceGroups,
+	"RESIGN":                     resign,
 	"REVOKE":                     revoke,
 	"RIGHT":                      right,
 	"RLIKE":                      rlike,
@@ -405,11 +434,13 @@ var tokenMap = map[string]int{
 	"ROW_FORMAT":                 rowFormat,
 	"RTRIM":                      rtrim,
 	"REVERSE":                    reverse,
+	"SCHEDULE":                   schedule,
 	"SCHEMA":                     schema,
 	"SCHEMAS":                    schemas,
 	"SEC_TO_TIME":                secToTime,
 	"SECOND":                     second,
 	"SELECT":                     selectKwd,
+	"SELECTIVITY":                selectivity,
 	"SERIALIZABLE":               serializable,
 	"SESSION":                    session,
 	"SET":                        set,
@@ -420,12 +451,15 @@ var tokenMap = map[string]int{
 	"SIGN":                       sign,
 	"SIGNED":                     signed,
 	"SIN":                        sin,
+	"SLOW":                       slow,
 	"SNAPSHOT":                   snapshot,
 	"SOME":                       some,
 	"SPACE":                      space,
+	"SPLIT":                      split,
 	"SQRT":                       sqrt,
 	"START":                      start,
 	"STARTING":                   starting,
+	"STARTS":                     starts,
 	"STATS_PERSISTENT":           statsPersistent,
 	"STATUS":                     status,
 	"SUBDATE":                    subDate,
@@ -452,10 +486,14 @@ var tokenMap = map[string]int{
 	"THEN":                       then,
 	"TO":                         to,
 	"TO_BASE64":                  toBase64,
+	"WEIGHT_STRING":              weightString,
 	"TO_DAYS":                    toDays,
 	"TO_SECONDS":                 toSeconds,
+	"TOP":                        top,
 	"TRAILING":                   trailing,
+	"TRACE":                      trace,
 	"TRANSACTION":                transaction,
+	"TRANSFER":                   transfer,
 	"TRIGGER":                    trigger,
 	"TRIGGERS":                   triggers,
 	"TRIM":                       trim,
@@ -532,8 +570,14 @@ var tokenMap = map[string]int{
 	"BOOL":                       boolType,
 	"BOOLEAN":                    booleanType,
 	"JSON":                       jsonType,
+	"JSON_ARRAY":                 jsonArray,
+	"JSON_CONTAINS":              jsonContains,
 	"JSON_EXTRACT":               jsonExtract,
+	"JSON_OBJECT":                jsonObject,
+	"JSON_SET":                   jsonSet,
 	"JSON_UNQUOTE":               jsonUnquote,
+	"JSON_ARRAYAGG":              jsonArrayagg,
+	"JSON_OBJECTAGG":             jsonObjectagg,
 	"SECOND_MICROSECOND":         secondMicrosecond,
 	"MINUTE_MICROSECOND":         minuteMicrosecond,
 	"MINUTE_SECOND":              minuteSecond,
@@ -562,6 +606,7 @@ var tokenMap = map[string]int{
 	"BENCHMARK":                  benchmark,
 	"COERCIBILITY":               coercibility,
 	"ROW_COUNT":                  rowCount,
+	"ROW_NUMBER":                 rowNumber,
 	"SESSION_USER":               sessionUser,
 	"SYSTEM_USER":                systemUser,
 	"CRC32":                      crc32,
@@ -596,6 +641,14 @@ var tokenMap = map[string]int{
 	"RELEASE_ALL_LOCKS":          releaseAllLocks,
 	"UUID":                       uuid,
 	"UUID_SHORT":                 uuidShort,
+	"UUID_TO_BIN":                uuidToBin,
+	"BIN_TO_UUID":                binToUUID,
+	"TIDB_CURRENT_TSO":           tidbCurrentTSO,
+	"TIDB_DECODE_KEY":            tidbDecodeKey,
+	"TIDB_DIGEST":                tidbDigest,
+	"TIDB_IS_DDL_OWNER":          tidbIsDDLOwner,
+	"TIDB_NORMALIZE":             tidbNormalize,
+	"TIDB_VERSION":               tidbVersion,
 	"KILL":                       kill,
 }
 
@@ -50,6 +50,7 @@ import (
 	invalid		"a special token never used by parser, used by lexer to indicate error"
 	hintBegin	"hintBegin is a virtual token for optimizer hint grammar"
 	hintEnd		"hintEnd is a virtual token for optimizer hint grammar"
+	memberOf	"memberOf is a virtual token for MEMBER OF, which is lexed as a single token"
 	andand		"&&"
 	oror		"||"
 
@@ -136,6 +137,7 @@ import (
 	join			"JOIN"
 	key			"KEY"
 	keys			"KEYS"
+	lateral			"LATERAL"
 	leading			"LEADING"
 	left			"LEFT"
 	like			"LIKE"
@@ -157,6 +159,7 @@ import (
 	minuteMicrosecond	"MINUTE_MICROSECOND"
 	minuteSecond 		"MINUTE_SECOND"
 	mod 			"MOD"
+	natural			"NATURAL"
 	not			"NOT"
 	noWriteToBinLog 	"NO_WRITE_TO_BINLOG"
 	null			"NULL"
@@ -169,6 +172,7 @@ import (
 	ord			"ORD"
 	order			"ORDER"
 	outer			"OUTER"
+	over			"OVER"
 	partition		"PARTITION"
 	partitions		"PARTITIONS"
 	position		"POSITION"
@@ -275,6 +279,8 @@ import (
 	getFormat			"GET_FORMAT"
 	grant				"GRANT"
 	groupConcat			"GROUP_CONCAT"
+	jsonArrayagg			"JSON_ARRAYAGG"
+	jsonObjectagg			"JSON_OBJECTAGG"
 	greatest			"GREATEST"
 	hour				"HOUR"
 	hex				"HEX"
@@ -283,7 +289,11 @@ import (
 	insertFunc			"INSERT_FUNC"
 	instr				"INSTR"
 	isNull				"ISNULL"
+	jsonArray			"JSON_ARRAY"
+	jsonContains			"JSON_CONTAINS"
 	jsonExtract			"JSON_EXTRACT"
+	jsonObject			"JSON_OBJECT"
+	jsonSet				"JSON_SET"
 	jsonUnquote			"JSON_UNQUOTE"
 	kill				"KILL"
 	lastInsertID			"LAST_INSERT_ID"
@@ -318,7 +328,9 @@ import (
 	query				"QUERY"
 	rand				"RAND"
 	radians				"RADIANS"
+	rank				"RANK"
 	rowCount			"ROW_COUNT"
+	rowNumber			"ROW_NUMBER"
 	secToTime			"SEC_TO_TIME"
 	second				"SECOND"
 	sessionUser			"SESSION_USER"
@@ -353,6 +365,7 @@ import (
 	round				"ROUND"
 	statsPersistent			"STATS_PERSISTENT"
 	toBase64			"TO_BASE64"
+	weightString			"WEIGHT_STRING"
 	toDays				"TO_DAYS"
 	toSeconds			"TO_SECONDS"
 	getLock				"GET_LOCK"
@@ -398,17 +411,29 @@ import (
 	releaseAllLocks			"RELEASE_ALL_LOCKS"
 	uuid				"UUID"
 	uuidShort			"UUID_SHORT"
+	uuidToBin			"UUID_TO_BIN"
+	binToUUID			"BIN_TO_UUID"
+	tidbDigest			"TIDB_DIGEST"
+	tidbNormalize			"TIDB_NORMALIZE"
+	tidbDecodeKey			"TIDB_DECODE_KEY"
+	tidbVersion			"TIDB_VERSION"
+	tidbCurrentTSO			"TIDB_CURRENT_TSO"
+	tidbIsDDLOwner			"TIDB_IS_DDL_OWNER"
 	underscoreCS			"UNDERSCORE_CHARSET"
 
 	/* the following tokens belong to UnReservedKeyword*/
 	action		"ACTION"
 	after		"AFTER"
 	any 		"ANY"
+	array		"ARRAY"
 	ascii		"ASCII"
-	at		"AT"
+	atKwd		"AT"
+	autoIdCache	"AUTO_ID_CACHE"
 	autoIncrement	"AUTO_INCREMENT"
+	autoRandom	"AUTO_RANDOM"
 	avgRowLength	"AVG_ROW_LENGTH"
 	avg		"AVG"
+	before		"BEFORE"
 	begin		"BEGIN"
 	binlog		"BINLOG"
 	bitType		"BIT"
@@ -421,11 +446,13 @@ import (
 	collation	"COLLATION"
 	columns		"COLUMNS"
 	comment 	"COMMENT"
+	completion	"COMPLETION"
 	commit		"COMMIT"
 	committed	"COMMITTED"
 	compact		"COMPACT"
 	compressed	"COMPRESSED"
 	compression	"COMPRESSION"
+	config		"CONFIG"
 	connection 	"CONNECTION"
 	consistent	"CONSISTENT"
 	data 		"DATA"
@@ -437,13 +464,19 @@ import (
 	do		"DO"
 	duplicate	"DUPLICATE"
 	dynamic		"DYNAMIC"
+	each		"EACH"
+	ends		"ENDS"
+	event		"EVENT"
+	every		"EVERY"
 	enable		"ENABLE"
 	end		"END"
 	engine		"ENGINE"
 	engines		"ENGINES"
+	errorsKwd	"ERRORS"
 	escape 		"ESCAPE"
 	exclusive       "EXCLUSIVE"
 	execute		"EXECUTE"
+	exprPushdownBlacklist	"EXPR_PUSHDOWN_BLACKLIST"
 	fields		"FIELDS"
 	first		"FIRST"
 	fixed		"FIXED"
@@ -451,7 +484,11 @@ import (
 	full		"FULL"
 	function	"FUNCTION"
 	hash		"HASH"
+	hotspots	"HOTSPOTS"
 	identified	"IDENTIFIED"
+	indexAsc	"INDEX_ASC"
+	indexDesc	"INDEX_DESC"
+	internal	"INTERNAL"
 	isolation	"ISOLATION"
 	indexes		"INDEXES"
 	jsonType	"JSON"
@@ -459,6 +496,7 @@ import (
 	local		"LOCAL"
 	less		"LESS"
 	level		"LEVEL"
+	master		"MASTER"
 	mode		"MODE"
 	modify		"MODIFY"
 	maxRows		"MAX_ROWS"
@@ -469,28 +507,40 @@ import (
 	none		"NONE"
 	offset		"OFFSET"
 	only		"ONLY"
+	owner		"OWNER"
 	password	"PASSWORD"
 	prepare		"PREPARE"
+	preserve	"PRESERVE"
 	privileges	"PRIVILEGES"
 	processlist	"PROCESSLIST"
 	quarter		"QUARTER"
 	quick		"QUICK"
+	recent		"RECENT"
 	redundant	"REDUNDANT"
+	regions		"REGIONS"
+	reload		"RELOAD"
 	repeatable	"REPEATABLE"
+	resign		"RESIGN"
+	resourceGroups	"RESOURCE_GROUPS"
 	reverse		"REVERSE"
 	rollback	"ROLLBACK"
 	row 		"ROW"
 	rowFormat	"ROW_FORMAT"
+	schedule	"SCHEDULE"
+	selectivity	"SELECTIVITY"
 	serializable	"SERIALIZABLE"
 	session		"SESSION"
 	share		"SHARE"
 	shared       	"SHARED"
 	signed		"SIGNED"
+	slow		"SLOW"
 	snapshot	"SNAPSHOT"
+	split		"SPLIT"
 	space 		"SPACE"
 	sqlCache	"SQL_CACHE"
 	sqlNoCache	"SQL_NO_CACHE"
 	start		"START"
+	starts		"STARTS"
 	status		"STATUS"
 	super		"SUPER"
 	some 		"SOME"
@@ -502,7 +552,10 @@ import (
 	timeType	"TIME"
 	timestampType	"TIMESTAMP"
 	timestampDiff	"TIMESTAMPDIFF"
+	top		"TOP"
+	trace		"TRACE"
 	transaction	"TRANSACTION"
+	transfer	"TRANSFER"
 	trigger		"TRIGGER"
 	triggers	"TRIGGERS"
 	truncate	"TRUNCATE"
@@ -538,6 +591,7 @@ import (
 	lsh		"<<"
 	neq		"!="
 	neqSynonym	"<>"
+	at		"@"
 	nulleq		"<=>"
 	placeholder	"PLACEHOLDER"
 	rsh		">>"
@@ -545,10 +599,13 @@ import (
 	userVar		"USER_VAR"
 
 %type   <item>
-	AdminStmt		"Check table statement or show ddl statement"
+	AdminStmt		"Check table statement, show ddl statement or reload statement"
+	AdminShowSlow		"Admin Show Slow statement"
 	AlterTableStmt		"Alter table statement"
 	AlterTableSpec		"Alter table specification"
 	AlterTableSpecList	"Alter table specification list"
+	AlterEventStmt		"ALTER EVENT statement"
+	AlterEventOnClauses	"ALTER EVENT schedule and completion clauses"
 	AlterUserStmt		"Alter user statement"
 	AnalyzeTableStmt	"Analyze table statement"
 	AnyOrAll		"Any or All for subquery"
@@ -582,22 +639,35 @@ import (
 	DatabaseOption		"CREATE Database specification"
 	DatabaseOptionList	"CREATE Database specification list"
 	DatabaseOptionListOpt	"CREATE Database specification list opt"
+	CreateEventStmt		"CREATE EVENT statement"
 	CreateTableStmt		"CREATE TABLE statement"
+	CreateTriggerStmt	"CREATE TRIGGER statement"
 	CreateUserStmt		"CREATE User statement"
 	DBName			"Database Name"
 	DeallocateStmt		"Deallocate prepared statement"
-	DefaultValueExpr	"DefaultValueExpr(Now or Signed Literal)"
+	DefaultValueExpr	"DefaultValueExpr(Now, Signed Literal or parenthesized expression)"
+	DefaultValueParenExpr	"Parenthesized default value expression"
 	DeleteFromStmt		"DELETE FROM statement"
 	DistinctOpt		"Distinct option"
 	DoStmt			"Do statement"
 	DropDatabaseStmt	"DROP DATABASE statement"
+	DropEventStmt		"DROP EVENT statement"
 	DropIndexStmt		"DROP INDEX statement"
 	DropTableStmt		"DROP TABLE statement"
+	DropTriggerStmt		"DROP TRIGGER statement"
 	DropUserStmt		"DROP USER"
 	DropViewStmt		"DROP VIEW statement"
 	EmptyStmt		"empty statement"
 	Enclosed		"Enclosed by"
 	EqOpt			"= or empty"
+	EventBody		"event body statement"
+	EventCommentOpt		"event comment option"
+	EventCompletion		"event ON COMPLETION option"
+	EventCompletionOpt	"optional event ON COMPLETION option"
+	EventEndsOpt		"event ENDS option"
+	EventSchedule		"event schedule"
+	EventStartsOpt		"event STARTS option"
+	EventStatusOpt		"event ENABLE or DISABLE option"
 	EscapedTableRef 	"escaped table reference"
 	Escaped			"Escaped by"
 	ExecuteStmt		"Execute statement"
@@ -620,6 +690,7 @@ import (
 	TableRefsClause		"Table references clause"
 	Function		"function expr"
 	FunctionCallAgg		"Function call on aggregate data"
+	FunctionCallWindow	"Function call on window"
 	FunctionCallConflict	"Function call with reserved keyword as function name"
 	FunctionCallKeyword	"Function call with keyword as function name"
 	FunctionCallNonKeyword	"Function call with nonkeyword as function name"
@@ -679,6 +750,7 @@ import (
 	PartitionDefinitionList "Partition definition list"
 	PartitionDefinitionListOpt	"Partition definition list option"
 	PartitionOpt		"Partition option"
+	PartitionByOpt		"Optional PARTITION BY clause of window"
 	PartitionNumOpt		"PARTITION NUM option"
 	PartDefValuesOpt	"VALUES {LESS THAN {(expr | value_list) | MAXVALUE} | IN {value_list}"
 	PartDefStorageOpt	"ENGINE = xxx or empty"
@@ -712,6 +784,7 @@ import (
 	SelectStmtLimit		"SELECT statement optional LIMIT clause"
 	SelectStmtOpts		"Select statement options"
 	SelectStmtGroup		"SELECT statement optional GROUP BY clause"
+	SetDefaultValueExpr	"SET DEFAULT value of ALTER COLUMN"
 	SetStmt			"Set variable statement"
 	ShowStmt		"Show engines/databases/tables/columns/warnings/status statement"
 	ShowTargetFilterable    "Show target that can be filtered by WHERE or LIKE"
@@ -719,6 +792,7 @@ import (
 	ShowTableAliasOpt       "Show table alias option"
 	ShowLikeOrWhereOpt	"Show like or where clause option"
 	SignedLiteral		"Literal or NumLiteral with sign"
+	SplitRegionStmt		"Split table region statement"
 	Starting		"Starting by"
 	Statement		"statement"
 	StatementList		"statement list"
@@ -744,7 +818,14 @@ import (
 	TableOptionListOpt	"create table option list opt"
 	TableRef 		"table reference"
 	TableRefs 		"table references"
+	TransactionChar		"Transaction characteristic"
+	TransactionChars	"Transaction characteristic list"
+	TriggerBody		"trigger body"
+	TriggerEvent		"trigger event"
+	TriggerSetList		"trigger set value list"
+	TriggerTiming		"trigger action time"
 	TrimDirection		"Trim string direction"
+	TraceStmt		"TRACE statement"
 	TruncateTableStmt	"TRANSACTION TABLE statement"
 	UnionOpt		"Union Option(empty/ALL/DISTINCT)"
 	UnionStmt		"Union select state ment"
@@ -765,6 +846,7 @@ import (
 	WhereClause		"WHERE clause"
 	WhereClauseOptional	"Optional WHERE clause"
 	WhenClause		"When clause"
+	WindowSpec		"Window specification"
 	WhenClauseList		"When clause list"
 	WithReadLockOpt		"With Read Lock opt"
 	WithGrantOptionOpt	"With Grant Option opt"
@@ -821,8 +903,6 @@ import (
 	DeallocateSym		"Deallocate or drop"
 	OuterOpt		"optional OUTER clause"
 	CrossOpt		"Cross join option"
-	TransactionChar		"Transaction characteristic"
-	TransactionChars	"Transaction characteristic list"
 	IsolationLevel		"Isolation level"
 	ShowIndexKwd		"Show index/indexs/key keyword"
 	FromOrIn		"From or In"
@@ -868,11 +948,11 @@ import (
 %precedence lowerThanKey
 %precedence key
 
-%left   join inner cross left right full
+%left   join inner cross left right full natural
 /* A dummy token to force the priority of TableRef production in a join. */
 %left   tableRefPriority
 %precedence lowerThanOn
-%precedence on
+%precedence on using
 %right  assignmentEq
 %left 	oror or
 %left 	xor
@@ -1000,7 +1080,7 @@ AlterTableSpec:
 			NewColumn: 	$4.(*ast.ColumnDef),
 		}
 	}
-|	"ALTER" ColumnKeywordOpt ColumnName "SET" "DEFAULT" SignedLiteral
+|	"ALTER" ColumnKeywordOpt ColumnName "SET" "DEFAULT" SetDefaultValueExpr
 	{
 		option := &ast.ColumnOption{Expr: $6.(ast.ExprNode)}
 		$$ = &ast.AlterTableSpec{
@@ -1257,6 +1337,14 @@ ColumnOption:
 	{
 		$$ = &ast.ColumnOption{Tp: ast.ColumnOptionAutoIncrement}
 	}
+|	"AUTO_RANDOM"
+	{
+		$$ = &ast.ColumnOption{Tp: ast.ColumnOptionAutoRandom, AutoRandomBits: types.UnspecifiedLength}
+	}
+|	"AUTO_RANDOM" '(' LengthNum ')'
+	{
+		$$ = &ast.ColumnOption{Tp: ast.ColumnOptionAutoRandom, AutoRandomBits: int($3.(uint64))}
+	}
 |	PrimaryOpt "KEY"
 	{
 		// KEY is normally a synonym for INDEX. The key attribute PRIMARY KEY
@@ -1278,8 +1366,7 @@ ColumnOption:
 	}
 |	"ON" "UPDATE" NowSymOptionFraction
 	{
-		nowFunc := &ast.FuncCallExpr{FnName: model.NewCIStr("CURRENT_TIMESTAMP")}
-		$$ = &ast.ColumnOption{Tp: ast.ColumnOptionOnUpdate, Expr: nowFunc}
+		$$ = &ast.ColumnOption{Tp: ast.ColumnOptionOnUpdate, Expr: $3.(ast.ExprNode)}
 	}
 |	"COMMENT" stringLit
 	{
@@ -1442,6 +1529,9 @@ ReferOpt:
 		$$ = ast.ReferOptionNoAction
 	}
 
+SetDefaultValueExpr:
+	SignedLiteral | DefaultValueParenExpr
+
 /*
  * The DEFAULT clause specifies a default value for a column.
  * With one exception, the default value must be a constant;
@@ -1449,12 +1539,24 @@ ReferOpt:
  * that you cannot set the default for a date column to be the value of
  * a function such as NOW() or CURRENT_DATE. The exception is that you
  * can specify CURRENT_TIMESTAMP as the default for a TIMESTAMP or DATETIME column.
+ * Like MySQL 8.0, an expression enclosed in parentheses is also accepted, it is
+ * evaluated when the default value is needed.
  *
  * See http://dev.mysql.com/doc/refman/5.7/en/create-table.html
  *      https://github.com/mysql/mysql-server/blob/5.7/sql/sql_yacc.yy#L6832
  */
 DefaultValueExpr:
-	NowSymOptionFraction | SignedLiteral
+	NowSymOptionFraction | SignedLiteral | DefaultValueParenExpr
+
+DefaultValueParenExpr:
+	'(' Expression ')'
+	{
+		startOffset := parser.startOffset(&yyS[yypt-1])
+		endOffset := parser.endOffset(&yyS[yypt])
+		expr := $2.(ast.ExprNode)
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.ParenthesesExpr{Expr: expr}
+	}
 
 NowSymOptionFraction:
 	NowSym
@@ -1467,7 +1569,7 @@ NowSymOptionFraction:
 	}
 |	NowSymFunc '(' NUM ')'
 	{
-		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr("CURRENT_TIMESTAMP")}
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr("CURRENT_TIMESTAMP"), Args: []ast.ExprNode{ast.NewValueExpr($3)}}
 	}
 
 /*
@@ -1521,12 +1623,244 @@ CreateIndexStmtUnique:
 		$$ = true
 	}
 
+/*******************************************************************
+ *
+ *  Create Event Statement
+ *
+ *  Example:
+ *	CREATE EVENT e ON SCHEDULE EVERY 1 DAY STARTS '2017-01-01 00:00:00' DO DELETE FROM t WHERE ts < DATE_SUB(NOW(), INTERVAL 7 DAY)
+ *	CREATE EVENT e ON SCHEDULE AT DATE_ADD(CURRENT_TIMESTAMP, INTERVAL 1 HOUR) ON COMPLETION PRESERVE DO TRUNCATE TABLE t
+ *******************************************************************/
+CreateEventStmt:
+	"CREATE" "EVENT" IfNotExists TableName "ON" "SCHEDULE" EventSchedule EventCompletionOpt EventStatusOpt EventCommentOpt "DO" EventBody
+	{
+		tn := $4.(*ast.TableName)
+		$$ = &ast.CreateEventStmt{
+			IfNotExists:	$3.(bool),
+			Schema:		tn.Schema,
+			Name:		tn.Name,
+			Schedule:	$7.(*ast.EventSchedule),
+			OnCompletion:	$8.(ast.EventCompletion),
+			Status:		$9.(ast.EventStatus),
+			Comment:	$10.(string),
+			Body:		$12.(ast.StmtNode),
+			BodyText:	parser.textToLexerPos(&yyS[yypt]),
+		}
+	}
+
+EventSchedule:
+	"AT" Expression
+	{
+		$$ = &ast.EventSchedule{At: $2.(ast.ExprNode)}
+	}
+|	"EVERY" Expression TimeUnit EventStartsOpt EventEndsOpt
+	{
+		x := &ast.EventSchedule{Every: $2.(ast.ExprNode), Unit: $3}
+		if $4 != nil {
+			x.Starts = $4.(ast.ExprNode)
+		}
+		if $5 != nil {
+			x.Ends = $5.(ast.ExprNode)
+		}
+		$$ = x
+	}
+
+EventStartsOpt:
+	{
+		$$ = nil
+	}
+|	"STARTS" Expression
+	{
+		$$ = $2
+	}
+
+EventEndsOpt:
+	{
+		$$ = nil
+	}
+|	"ENDS" Expression
+	{
+		$$ = $2
+	}
+
+EventCompletionOpt:
+	{
+		$$ = ast.EventCompletionDefault
+	}
+|	"ON" "COMPLETION" EventCompletion
+	{
+		$$ = $3
+	}
+
+EventCompletion:
+	"PRESERVE"
+	{
+		$$ = ast.EventCompletionPreserve
+	}
+|	"NOT" "PRESERVE"
+	{
+		$$ = ast.EventCompletionNotPreserve
+	}
+
+EventStatusOpt:
+	{
+		$$ = ast.EventStatusDefault
+	}
+|	"ENABLE"
+	{
+		$$ = ast.EventStatusEnable
+	}
+|	"DISABLE"
+	{
+		$$ = ast.EventStatusDisable
+	}
+
+EventCommentOpt:
+	{
+		$$ = ""
+	}
+|	"COMMENT" stringLit
+	{
+		$$ = $2
+	}
+
+EventBody:
+	InsertIntoStmt
+|	ReplaceIntoStmt
+|	UpdateStmt
+|	DeleteFromStmt
+|	TruncateTableStmt
+|	AnalyzeTableStmt
+|	DoStmt
+
+/*******************************************************************
+ *
+ *  Alter Event Statement
+ *
+ *  Example:
+ *	ALTER EVENT e DISABLE
+ *	ALTER EVENT e ON SCHEDULE EVERY 1 HOUR DO DELETE FROM t
+ *******************************************************************/
+AlterEventStmt:
+	"ALTER" "EVENT" TableName AlterEventOnClauses EventStatusOpt
+	{
+		tn := $3.(*ast.TableName)
+		x := $4.(*ast.AlterEventStmt)
+		x.Schema = tn.Schema
+		x.Name = tn.Name
+		x.Status = $5.(ast.EventStatus)
+		$$ = x
+	}
+|	"ALTER" "EVENT" TableName AlterEventOnClauses EventStatusOpt "DO" EventBody
+	{
+		tn := $3.(*ast.TableName)
+		x := $4.(*ast.AlterEventStmt)
+		x.Schema = tn.Schema
+		x.Name = tn.Name
+		x.Status = $5.(ast.EventStatus)
+		x.Body = $7.(ast.StmtNode)
+		x.BodyText = parser.textToLexerPos(&yyS[yypt])
+		$$ = x
+	}
+
+AlterEventOnClauses:
+	{
+		$$ = &ast.AlterEventStmt{}
+	}
+|	"ON" "SCHEDULE" EventSchedule EventCompletionOpt
+	{
+		$$ = &ast.AlterEventStmt{Schedule: $3.(*ast.EventSchedule), OnCompletion: $4.(ast.EventCompletion)}
+	}
+|	"ON" "COMPLETION" EventCompletion
+	{
+		$$ = &ast.AlterEventStmt{OnCompletion: $3.(ast.EventCompletion)}
+	}
+
+/*******************************************************************
+ *
+ *  Create Trigger Statement
+ *
+ *  Example:
+ *	CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW SET NEW.c = NEW.c + 1
+ *	CREATE TRIGGER trg AFTER DELETE ON t FOR EACH ROW DELETE FROM t1 WHERE id = OLD.id
+ *******************************************************************/
+CreateTriggerStmt:
+	"CREATE" "TRIGGER" Identifier TriggerTiming TriggerEvent "ON" TableName "FOR" "EACH" "ROW" TriggerBody
+	{
+		x := &ast.CreateTriggerStmt{
+			Name:	$3,
+			Timing:	$4.(model.TriggerTiming),
+			Event:	$5.(model.TriggerEvent),
+			Table:	$7.(*ast.TableName),
+		}
+		x.BodyText = parser.textToLexerPos(&yyS[yypt])
+		switch body := $11.(type) {
+		case []*ast.Assignment:
+			x.Assignments = body
+		case ast.DMLNode:
+			x.Body = body
+		}
+		$$ = x
+	}
+
+TriggerTiming:
+	"BEFORE"
+	{
+		$$ = model.TriggerBefore
+	}
+|	"AFTER"
+	{
+		$$ = model.TriggerAfter
+	}
+
+TriggerEvent:
+	"INSERT"
+	{
+		$$ = model.TriggerInsert
+	}
+|	"UPDATE"
+	{
+		$$ = model.TriggerUpdate
+	}
+|	"DELETE"
+	{
+		$$ = model.TriggerDelete
+	}
+
+TriggerBody:
+	"SET" TriggerSetList
+	{
+		$$ = $2
+	}
+|	InsertIntoStmt
+|	ReplaceIntoStmt
+|	UpdateStmt
+|	DeleteFromStmt
+
+TriggerSetList:
+	ColumnSetValue
+	{
+		$$ = []*ast.Assignment{$1.(*ast.Assignment)}
+	}
+|	TriggerSetList ',' ColumnSetValue
+	{
+		$$ = append($1.([]*ast.Assignment), $3.(*ast.Assignment))
+	}
+
 IndexColName:
 	ColumnName OptFieldLen Order
 	{
 		//Order is parsed but just ignored as MySQL did
 		$$ = &ast.IndexColName{Column: $1.(*ast.ColumnName), Length: $2.(int)}
 	}
+|	'(' "CAST" '(' ColumnName "AS" CastType "ARRAY" ')' ')'
+	{
+		$$ = &ast.IndexColName{Column: $4.(*ast.ColumnName), Length: types.UnspecifiedLength, MultiValued: true, Path: "$", Tp: $6.(*types.FieldType)}
+	}
+|	'(' "CAST" '(' "JSON_EXTRACT" '(' ColumnName ',' stringLit ')' "AS" CastType "ARRAY" ')' ')'
+	{
+		$$ = &ast.IndexColName{Column: $6.(*ast.ColumnName), Length: types.UnspecifiedLength, MultiValued: true, Path: $8, Tp: $11.(*types.FieldType)}
+	}
 
 IndexColNameList:
 	{
@@ -1777,6 +2111,20 @@ DropIndexStmt:
 		$$ = &ast.DropIndexStmt{IfExists: $3.(bool), IndexName: $4, Table: $6.(*ast.TableName)}
 	}
 
+DropTriggerStmt:
+	"DROP" "TRIGGER" IfExists TableName
+	{
+		tn := $4.(*ast.TableName)
+		$$ = &ast.DropTriggerStmt{IfExists: $3.(bool), Schema: tn.Schema, Name: tn.Name}
+	}
+
+DropEventStmt:
+	"DROP" "EVENT" IfExists TableName
+	{
+		tn := $4.(*ast.TableName)
+		$$ = &ast.DropEventStmt{IfExists: $3.(bool), Schema: tn.Schema, Name: tn.Name}
+	}
+
 DropTableStmt:
 	"DROP" TableOrTables TableNameList
 	{
@@ -1844,6 +2192,25 @@ ExplainStmt:
 	{
 		$$ = &ast.ExplainStmt{Stmt: $2.(ast.StmtNode)}
 	}
+|	ExplainSym "ANALYZE" ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{
+			Stmt:		$3.(ast.StmtNode),
+			Analyze:	true,
+		}
+	}
+|	ExplainSym "FOR" "CONNECTION" NUM
+	{
+		$$ = &ast.ExplainForStmt{
+			ConnectionID:	getUint64FromNUM($4),
+		}
+	}
+
+TraceStmt:
+	"TRACE" ExplainableStmt
+	{
+		$$ = &ast.TraceStmt{Stmt: $2.(ast.StmtNode)}
+	}
 
 LengthNum:
 	NUM
@@ -2087,6 +2454,10 @@ PredicateExpr:
 	{
 		$$ = &ast.PatternRegexpExpr{Expr: $1.(ast.ExprNode), Pattern: $3.(ast.ExprNode), Not: !$2.(bool)}
 	}
+|	PrimaryFactor memberOf '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr(ast.JSONMemberOf), Args: []ast.ExprNode{$1.(ast.ExprNode), $4.(ast.ExprNode)}}
+	}
 |	PrimaryFactor
 
 RegexpSym:
@@ -2309,7 +2680,10 @@ UnReservedKeyword:
 | "MIN_ROWS" | "NATIONAL" | "ROW" | "ROW_FORMAT" | "QUARTER" | "GRANTS" | "TRIGGERS" | "DELAY_KEY_WRITE" | "ISOLATION" | "JSON"
 | "REPEATABLE" | "COMMITTED" | "UNCOMMITTED" | "ONLY" | "SERIALIZABLE" | "LEVEL" | "VARIABLES" | "SQL_CACHE" | "INDEXES" | "PROCESSLIST"
 | "SQL_NO_CACHE" | "DISABLE"  | "ENABLE" | "REVERSE" | "SPACE" | "PRIVILEGES" | "NO" | "BINLOG" | "FUNCTION" | "VIEW" | "MODIFY" | "EVENTS" | "PARTITIONS"
-| "TIMESTAMPDIFF" | "NONE" | "SUPER" | "SHARED" | "EXCLUSIVE"
+| "TIMESTAMPDIFF" | "NONE" | "SUPER" | "SHARED" | "EXCLUSIVE" | "BEFORE" | "EACH" | "COMPLETION" | "ENDS" | "EVENT"
+| "EVERY" | "PRESERVE" | "SCHEDULE" | "STARTS" | "RELOAD" | "EXPR_PUSHDOWN_BLACKLIST" | "REGIONS" | "SPLIT" | "HOTSPOTS" | "MASTER" | "ERRORS"
+| "SLOW" | "RECENT" | "TOP" | "INTERNAL" | "RESOURCE_GROUPS" | "SELECTIVITY" | "AUTO_RANDOM" | "INDEX_ASC" | "INDEX_DESC" | "ARRAY" | "AUTO_ID_CACHE"
+| "CONFIG" | "OWNER" | "RESIGN" | "TRANSFER" | "TRACE"
 
 ReservedKeyword:
 "ADD" | "ALL" | "ALTER" | "ANALYZE" | "AND" | "AS" | "ASC" | "BETWEEN" | "BIGINT"
@@ -2321,10 +2695,10 @@ ReservedKeyword:
 | "EXISTS" | "EXPLAIN" | "FALSE" | "FLOAT" | "FOR" | "FORCE" | "FOREIGN" | "FROM"
 | "FULLTEXT" | "GRANT" | "GROUP" | "HAVING" | "HOUR_MICROSECOND" | "HOUR_MINUTE"
 | "HOUR_SECOND" | "IF" | "IGNORE" | "IN" | "INDEX" | "INFILE" | "INNER" | "INSERT" | "INT" | "INTO" | "INTEGER"
-| "INTERVAL" | "IS" | "JOIN" | "KEY" | "KEYS" | "KILL" | "LEADING" | "LEFT" | "LIKE" | "LIMIT" | "LINES" | "LOAD"
+| "INTERVAL" | "IS" | "JOIN" | "KEY" | "KEYS" | "KILL" | "LATERAL" | "LEADING" | "LEFT" | "LIKE" | "LIMIT" | "LINES" | "LOAD"
 | "LOCALTIME" | "LOCALTIMESTAMP" | "LOCK" | "LONGBLOB" | "LONGTEXT" | "MAXVALUE" | "MEDIUMBLOB" | "MEDIUMINT" | "MEDIUMTEXT"
-| "MINUTE_MICROSECOND" | "MINUTE_SECOND" | "MOD" | "NOT" | "NO_WRITE_TO_BINLOG" | "NULL" | "NUMERIC"
-| "ON" | "OPTION" | "OR" | "ORDER" | "OUTER" | "PARTITION" | "PRECISION" | "PRIMARY" | "PROCEDURE" | "RANGE" | "READ" 
+| "MINUTE_MICROSECOND" | "MINUTE_SECOND" | "MOD" | "NATURAL" | "NOT" | "NO_WRITE_TO_BINLOG" | "NULL" | "NUMERIC"
+| "ON" | "OPTION" | "OR" | "ORDER" | "OUTER" | "OVER" | "PARTITION" | "PRECISION" | "PRIMARY" | "PROCEDURE" | "RANGE" | "READ" 
 | "REAL" | "REFERENCES" | "REGEXP" | "RENAME" | "REPEAT" | "REPLACE" | "RESTRICT" | "REVOKE" | "RIGHT" | "RLIKE"
 | "SCHEMA" | "SCHEMAS" | "SECOND_MICROSECOND" | "SELECT" | "SET" | "SHOW" | "SMALLINT"
 | "STARTING" | "TABLE" | "TERMINATED" | "THEN" | "TINYBLOB" | "TINYINT" | "TINYTEXT" | "TO"
@@ -2342,12 +2716,13 @@ NotKeywordToken:
 |	"GET_FORMAT" | "GROUP_CONCAT" | "GREATEST" | "LEAST" | "HOUR" | "HEX" | "UNHEX" | "IFNULL" | "INSTR" | "ISNULL" | "LAST_INSERT_ID" | "LCASE" | "LENGTH" | "LOAD_FILE" | "LOCATE" | "LOWER" | "LPAD" | "LTRIM"
 |	"MAKE_SET" | "MAX" | "MAKEDATE" | "MAKETIME" | "MICROSECOND" | "MID" | "MIN" |	"MINUTE" | "NULLIF" | "MONTH" | "MONTHNAME" | "NOW" |  "OCT" | "OCTET_LENGTH" | "ORD" | "POSITION" | "PERIOD_ADD" | "PERIOD_DIFF" | "PI" | "POW" | "POWER" | "RAND" | "RADIANS" | "ROW_COUNT"
 	"QUOTE" | "SEC_TO_TIME" | "SECOND" | "SIGN" | "SIN" | "SLEEP" | "SQRT" | "SQL_CALC_FOUND_ROWS" | "STR_TO_DATE" | "SUBTIME" | "SUBDATE" | "SUBSTRING" %prec lowerThanLeftParen |
-	"SESSION_USER" | "SUBSTRING_INDEX" | "SUM" | "SYSTEM_USER" | "TAN" | "TIME_FORMAT" | "TIME_TO_SEC" | "TIMESTAMPADD" | "TO_BASE64" | "TO_DAYS" | "TO_SECONDS" | "TRIM" | "RTRIM" | "UCASE" | "UTC_TIME" | "UPPER" | "VERSION" | "WEEKDAY" | "WEEKOFYEAR" | "YEARWEEK" | "ROUND"
+	"SESSION_USER" | "SUBSTRING_INDEX" | "SUM" | "SYSTEM_USER" | "TAN" | "TIME_FORMAT" | "TIME_TO_SEC" | "TIMESTAMPADD" | "TO_BASE64" | "WEIGHT_STRING" | "TO_DAYS" | "TO_SECONDS" | "TRIM" | "RTRIM" | "UCASE" | "UTC_TIME" | "UPPER" | "VERSION" | "WEEKDAY" | "WEEKOFYEAR" | "YEARWEEK" | "ROUND"
 |	"STATS_PERSISTENT" | "GET_LOCK" | "RELEASE_LOCK" | "CEIL" | "CEILING" | "FLOOR" | "FROM_UNIXTIME" | "TIMEDIFF" | "LN" | "LOG" | "LOG2" | "LOG10" | "FIELD_KWD"
 |	"AES_DECRYPT" | "AES_ENCRYPT" | "QUOTE"
-|	"ANY_VALUE" | "INET_ATON" | "INET_NTOA" | "INET6_ATON" | "INET6_NTOA" | "IS_FREE_LOCK" | "IS_IPV4" | "IS_IPV4_COMPAT" | "IS_IPV4_MAPPED" | "IS_IPV6" | "IS_USED_LOCK" | "MASTER_POS_WAIT" | "NAME_CONST" | "RELEASE_ALL_LOCKS" | "UUID" | "UUID_SHORT"
+|	"ANY_VALUE" | "INET_ATON" | "INET_NTOA" | "INET6_ATON" | "INET6_NTOA" | "IS_FREE_LOCK" | "IS_IPV4" | "IS_IPV4_COMPAT" | "IS_IPV4_MAPPED" | "IS_IPV6" | "IS_USED_LOCK" | "MASTER_POS_WAIT" | "NAME_CONST" | "RELEASE_ALL_LOCKS" | "UUID" | "UUID_SHORT" | "UUID_TO_BIN" | "BIN_TO_UUID"
 |	"COMPRESS" | "DECODE" | "DES_DECRYPT" | "DES_ENCRYPT" | "ENCODE" | "ENCRYPT" | "MD5" | "OLD_PASSWORD" | "RANDOM_BYTES" | "SHA1" | "SHA" | "SHA2" | "UNCOMPRESS" | "UNCOMPRESSED_LENGTH" | "VALIDATE_PASSWORD_STRENGTH"
-|	"JSON_EXTRACT" | "JSON_UNQUOTE"
+|	"JSON_CONTAINS" | "JSON_EXTRACT" | "JSON_UNQUOTE" | "JSON_SET" | "JSON_OBJECT" | "JSON_ARRAY" | "JSON_ARRAYAGG" | "JSON_OBJECTAGG" | "RANK" | "ROW_NUMBER" | "TIDB_DIGEST" | "TIDB_NORMALIZE" | "TIDB_DECODE_KEY"
+|	"TIDB_VERSION" | "TIDB_CURRENT_TSO" | "TIDB_IS_DDL_OWNER"
 
 /************************************************************************************
  *
@@ -2686,6 +3061,7 @@ Function:
 |	FunctionCallNonKeyword
 |	FunctionCallConflict
 |	FunctionCallAgg
+|	FunctionCallWindow
 
 FunctionNameConflict:
 	"DATABASE"
@@ -3371,6 +3747,25 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
+|	"WEIGHT_STRING" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"WEIGHT_STRING" '(' Expression "AS" "CHAR" FieldLen ')'
+	{
+		// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_weight-string
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr($1),
+			Args: []ast.ExprNode{$3.(ast.ExprNode), ast.NewValueExpr("CHAR"), ast.NewValueExpr($6)},
+		}
+	}
+|	"WEIGHT_STRING" '(' Expression "AS" "BINARY" FieldLen ')'
+	{
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr($1),
+			Args: []ast.ExprNode{$3.(ast.ExprNode), ast.NewValueExpr("BINARY"), ast.NewValueExpr($6)},
+		}
+	}
 |	"TO_DAYS" '(' ExpressionListOpt ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
@@ -3628,6 +4023,38 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
+|	"UUID_TO_BIN" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"BIN_TO_UUID" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"TIDB_DIGEST" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"TIDB_NORMALIZE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"TIDB_DECODE_KEY" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"TIDB_VERSION" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"TIDB_CURRENT_TSO" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"TIDB_IS_DDL_OWNER" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
 |	"UNCOMPRESS" '(' ExpressionListOpt ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
@@ -3649,6 +4076,15 @@ FunctionCallNonKeyword:
 			Args: args,
 		}
 	}
+|	"JSON_CONTAINS" '(' Expression ',' ExpressionList ')'
+	{
+		var args = []ast.ExprNode{$3.(ast.ExprNode)}
+		args = append(args, $5.([]ast.ExprNode)...)
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr($1),
+			Args: args,
+		}
+	}
 |	"JSON_UNQUOTE" '(' Expression ')'
 	{
 		$$ = &ast.FuncCallExpr{
@@ -3656,6 +4092,23 @@ FunctionCallNonKeyword:
 			Args: []ast.ExprNode{$3.(ast.ExprNode)},
 		}
 	}
+|	"JSON_SET" '(' Expression ',' ExpressionList ')'
+	{
+		var args = []ast.ExprNode{$3.(ast.ExprNode)}
+		args = append(args, $5.([]ast.ExprNode)...)
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr($1),
+			Args: args,
+		}
+	}
+|	"JSON_OBJECT" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_ARRAY" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
 
 GetFormatSelector:
 	"DATE"
@@ -3726,6 +4179,14 @@ FunctionCallAgg:
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: $4.([]ast.ExprNode), Distinct: $3.(bool)}
 	}
+|	"JSON_ARRAYAGG" '(' Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"JSON_OBJECTAGG" '(' Expression ',' Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$3.(ast.ExprNode), $5.(ast.ExprNode)}}
+	}
 |	"MAX" '(' DistinctOpt Expression ')'
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
@@ -3739,6 +4200,44 @@ FunctionCallAgg:
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
 	}
 
+FunctionCallWindow:
+	"ROW_NUMBER" '(' ')' "OVER" '(' WindowSpec ')'
+	{
+		$$ = &ast.WindowFuncExpr{F: ast.WindowFuncRowNumber, Spec: $6.(ast.WindowSpec)}
+	}
+|	"RANK" '(' ')' "OVER" '(' WindowSpec ')'
+	{
+		$$ = &ast.WindowFuncExpr{F: ast.WindowFuncRank, Spec: $6.(ast.WindowSpec)}
+	}
+|	FunctionCallAgg "OVER" '(' WindowSpec ')'
+	{
+		agg := $1.(*ast.AggregateFuncExpr)
+		if agg.Distinct {
+			yylex.Errorf("DISTINCT is not supported in window function %s", agg.F)
+			return 1
+		}
+		$$ = &ast.WindowFuncExpr{F: agg.F, Args: agg.Args, Spec: $4.(ast.WindowSpec)}
+	}
+
+WindowSpec:
+	PartitionByOpt OrderByOptional
+	{
+		spec := ast.WindowSpec{PartitionBy: $1.([]*ast.ByItem)}
+		if $2 != nil {
+			spec.OrderBy = $2.(*ast.OrderByClause).Items
+		}
+		$$ = spec
+	}
+
+PartitionByOpt:
+	{
+		$$ = []*ast.ByItem(nil)
+	}
+|	"PARTITION" "BY" ByList
+	{
+		$$ = $3.([]*ast.ByItem)
+	}
+
 FuncDatetimePrec:
 	{
 		$$ = nil
@@ -3966,6 +4465,11 @@ CastType:
 		x.Flag |= mysql.UnsignedFlag
 		$$ = x
 	}
+|	"JSON"
+	{
+		x := types.NewFieldType(mysql.TypeJSON)
+		$$ = x
+	}
 
 
 PrimaryFactor:
@@ -3993,6 +4497,28 @@ PrimaryFactor:
 	{
 		$$ = &ast.BinaryOperationExpr{Op: opcode.Minus, L: $1.(ast.ExprNode), R: $3.(ast.ExprNode)}
 	}
+|	PrimaryFactor '+' "INTERVAL" Expression TimeUnit %prec '+'
+	{
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr(ast.DateAdd),
+			Args: []ast.ExprNode{
+				$1.(ast.ExprNode),
+				$4.(ast.ExprNode),
+				ast.NewValueExpr($5),
+			},
+		}
+	}
+|	PrimaryFactor '-' "INTERVAL" Expression TimeUnit %prec '-'
+	{
+		$$ = &ast.FuncCallExpr{
+			FnName: model.NewCIStr(ast.DateSub),
+			Args: []ast.ExprNode{
+				$1.(ast.ExprNode),
+				$4.(ast.ExprNode),
+				ast.NewValueExpr($5),
+			},
+		}
+	}
 |	PrimaryFactor '*' PrimaryFactor %prec '*'
 	{
 		$$ = &ast.BinaryOperationExpr{Op: opcode.Mul, L: $1.(ast.ExprNode), R: $3.(ast.ExprNode)}
@@ -4325,6 +4851,17 @@ TableFactor:
 	{
 		$$ = &ast.TableSource{Source: $2.(*ast.UnionStmt), AsName: $4.(model.CIStr)}
 	}
+|	"LATERAL" '(' SelectStmt ')' TableAsName
+	{
+		st := $3.(*ast.SelectStmt)
+		endOffset := parser.endOffset(&yyS[yypt-1])
+		parser.setLastSelectFieldText(st, endOffset)
+		$$ = &ast.TableSource{Source: st, AsName: $5.(model.CIStr), Lateral: true}
+	}
+|	"LATERAL" '(' UnionStmt ')' TableAsName
+	{
+		$$ = &ast.TableSource{Source: $3.(*ast.UnionStmt), AsName: $5.(model.CIStr), Lateral: true}
+	}
 |	'(' TableRefs ')'
 	{
 		$$ = $2
@@ -4442,7 +4979,26 @@ JoinTable:
 		on := &ast.OnCondition{Expr: $7.(ast.ExprNode)}
 		$$ = &ast.Join{Left: $1.(ast.ResultSetNode), Right: $5.(ast.ResultSetNode), Tp: $2.(ast.JoinType), On: on}
 	}
-	/* Support Using */
+|	TableRef CrossOpt TableRef "USING" '(' ColumnNameList ')'
+	{
+		$$ = &ast.Join{Left: $1.(ast.ResultSetNode), Right: $3.(ast.ResultSetNode), Tp: ast.CrossJoin, Using: $6.([]*ast.ColumnName)}
+	}
+|	TableRef JoinType OuterOpt "JOIN" TableRef "USING" '(' ColumnNameList ')'
+	{
+		$$ = &ast.Join{Left: $1.(ast.ResultSetNode), Right: $5.(ast.ResultSetNode), Tp: $2.(ast.JoinType), Using: $8.([]*ast.ColumnName)}
+	}
+|	TableRef "NATURAL" "JOIN" TableRef
+	{
+		$$ = &ast.Join{Left: $1.(ast.ResultSetNode), Right: $4.(ast.ResultSetNode), Tp: ast.CrossJoin, NaturalJoin: true}
+	}
+|	TableRef "NATURAL" "INNER" "JOIN" TableRef
+	{
+		$$ = &ast.Join{Left: $1.(ast.ResultSetNode), Right: $5.(ast.ResultSetNode), Tp: ast.CrossJoin, NaturalJoin: true}
+	}
+|	TableRef "NATURAL" JoinType OuterOpt "JOIN" TableRef
+	{
+		$$ = &ast.Join{Left: $1.(ast.ResultSetNode), Right: $6.(ast.ResultSetNode), Tp: $3.(ast.JoinType), NaturalJoin: true}
+	}
 
 JoinType:
 	"LEFT"
@@ -4574,6 +5130,18 @@ TableOptimizerHintOpt:
 	{
 		$$ = &ast.TableOptimizerHint{HintName: model.NewCIStr($1), Tables: $3.([]model.CIStr)}
 	}
+|	"INDEX_ASC" '(' HintTableList ')'
+	{
+		$$ = &ast.TableOptimizerHint{HintName: model.NewCIStr($1), Tables: $3.([]model.CIStr)}
+	}
+|	"INDEX_DESC" '(' HintTableList ')'
+	{
+		$$ = &ast.TableOptimizerHint{HintName: model.NewCIStr($1), Tables: $3.([]model.CIStr)}
+	}
+|	"SELECTIVITY" '(' Expression ',' NumLiteral ')'
+	{
+		$$ = &ast.TableOptimizerHint{HintName: model.NewCIStr($1), Expr: $3.(ast.ExprNode), Selectivity: getFloat64FromNUM($5)}
+	}
 
 SelectStmtCalcFoundRows:
 	%prec lowerThanCalcFoundRows
@@ -4738,27 +5306,64 @@ SetStmt:
 	}
 |	"SET" "GLOBAL" "TRANSACTION" TransactionChars
 	{
-		// Parsed but ignored
+		assigns := $4.([]*ast.VariableAssignment)
+		for _, v := range assigns {
+			v.IsGlobal = true
+		}
+		$$ = &ast.SetStmt{Variables: assigns}
 	}
 |	"SET" "SESSION" "TRANSACTION" TransactionChars
 	{
-		// Parsed but ignored
+		$$ = &ast.SetStmt{Variables: $4.([]*ast.VariableAssignment)}
+	}
+|	"SET" "TRANSACTION" TransactionChars
+	{
+		// The characteristics only apply to the next transaction, all the transactions run
+		// in snapshot isolation, so there is nothing to set.
+		$$ = &ast.SetStmt{}
 	}
 
 TransactionChars:
 	TransactionChar
+	{
+		$$ = []*ast.VariableAssignment{$1.(*ast.VariableAssignment)}
+	}
 |	TransactionChars ',' TransactionChar
+	{
+		$$ = append($1.([]*ast.VariableAssignment), $3.(*ast.VariableAssignment))
+	}
 
 TransactionChar:
 	"ISOLATION" "LEVEL" IsolationLevel
+	{
+		$$ = &ast.VariableAssignment{Name: "tx_isolation", Value: ast.NewValueExpr($3), IsSystem: true}
+	}
 |	"READ" "WRITE"
+	{
+		$$ = &ast.VariableAssignment{Name: "tx_read_only", Value: ast.NewValueExpr("0"), IsSystem: true}
+	}
 |	"READ" "ONLY"
+	{
+		$$ = &ast.VariableAssignment{Name: "tx_read_only", Value: ast.NewValueExpr("1"), IsSystem: true}
+	}
 
 IsolationLevel:
 	"REPEATABLE" "READ"
+	{
+		$$ = "REPEATABLE-READ"
+	}
 |	"READ"	"COMMITTED"
+	{
+		$$ = "READ-COMMITTED"
+	}
 |	"READ"	"UNCOMMITTED"
+	{
+		$$ = "READ-UNCOMMITTED"
+	}
 |	"SERIALIZABLE"
+	{
+		$$ = "SERIALIZABLE"
+	}
 
 VariableAssignment:
 	Identifier eq Expression
@@ -4885,7 +5490,7 @@ Username:
 	{
 		$$ = $1 + "@%"
 	}
-|	stringLit "AT" stringLit
+|	stringLit "@" stringLit
 	{
 		$$ = $1 + "@" + $3
 	}
@@ -4923,6 +5528,21 @@ AdminStmt:
 	{
 		$$ = &ast.AdminStmt{Tp: ast.AdminShowDDL}
 	}
+|	"ADMIN" "SHOW" "DDL" "OWNER"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminShowDDLOwner}
+	}
+|	"ADMIN" "TRANSFER" "DDL" "OWNER" "TO" stringLit
+	{
+		$$ = &ast.AdminStmt{
+			Tp:		ast.AdminTransferDDLOwner,
+			DDLOwner:	$6,
+		}
+	}
+|	"ADMIN" "RESIGN" "DDL" "OWNER"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminTransferDDLOwner}
+	}
 |	"ADMIN" "CHECK" "TABLE" TableNameList
 	{
 		$$ = &ast.AdminStmt{
@@ -4930,6 +5550,58 @@ AdminStmt:
 			Tables: $4.([]*ast.TableName),
 		}
 	}
+|	"ADMIN" "RELOAD" "EXPR_PUSHDOWN_BLACKLIST"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminReloadExprPushdownBlacklist}
+	}
+|	"ADMIN" "RELOAD" "RESOURCE_GROUPS"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminReloadResourceGroups}
+	}
+|	"ADMIN" "RELOAD" "CONFIG"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminReloadConfig}
+	}
+|	"ADMIN" "SHOW" "SLOW" AdminShowSlow
+	{
+		$$ = &ast.AdminStmt{
+			Tp:		ast.AdminShowSlow,
+			ShowSlow:	$4.(*ast.ShowSlow),
+		}
+	}
+
+AdminShowSlow:
+	"RECENT" LengthNum
+	{
+		$$ = &ast.ShowSlow{
+			Tp:	ast.ShowSlowRecent,
+			Count:	$2.(uint64),
+		}
+	}
+|	"TOP" LengthNum
+	{
+		$$ = &ast.ShowSlow{
+			Tp:	ast.ShowSlowTop,
+			Kind:	ast.ShowSlowKindDefault,
+			Count:	$2.(uint64),
+		}
+	}
+|	"TOP" "INTERNAL" LengthNum
+	{
+		$$ = &ast.ShowSlow{
+			Tp:	ast.ShowSlowTop,
+			Kind:	ast.ShowSlowKindInternal,
+			Count:	$3.(uint64),
+		}
+	}
+|	"TOP" "ALL" LengthNum
+	{
+		$$ = &ast.ShowSlow{
+			Tp:	ast.ShowSlowTop,
+			Kind:	ast.ShowSlowKindAll,
+			Count:	$3.(uint64),
+		}
+	}
 
 /****************************Show Statement*******************************/
 ShowStmt:
@@ -4972,12 +5644,33 @@ ShowStmt:
 			User:	$4.(string),
 		}
 	}
+|	"SHOW" "COUNT" '(' '*' ')' "WARNINGS"
+	{
+		$$ = &ast.ShowStmt{
+			Tp:			ast.ShowWarnings,
+			CountWarningsOrErrors:	true,
+		}
+	}
+|	"SHOW" "COUNT" '(' '*' ')' "ERRORS"
+	{
+		$$ = &ast.ShowStmt{
+			Tp:			ast.ShowErrors,
+			CountWarningsOrErrors:	true,
+		}
+	}
 |	"SHOW" "PROCESSLIST"
 	{
 		$$ = &ast.ShowStmt{
 			Tp: ast.ShowProcessList,
 		}
 	}
+|	"SHOW" "TABLE" TableName "REGIONS"
+	{
+		$$ = &ast.ShowStmt{
+			Tp:	ast.ShowRegions,
+			Table:	$3.(*ast.TableName),
+		}
+	}
 
 ShowIndexKwd:
 	"INDEX"
@@ -5004,6 +5697,14 @@ ShowTargetFilterable:
 	{
 		$$ = &ast.ShowStmt{Tp: ast.ShowCharset}
 	}
+|	"HOTSPOTS"
+	{
+		$$ = &ast.ShowStmt{Tp: ast.ShowHotspots}
+	}
+|	"MASTER" "STATUS"
+	{
+		$$ = &ast.ShowStmt{Tp: ast.ShowMasterStatus}
+	}
 |	OptFull "TABLES" ShowDatabaseNameOpt
 	{
 		$$ = &ast.ShowStmt{
@@ -5057,6 +5758,10 @@ ShowTargetFilterable:
 	{
 		$$ = &ast.ShowStmt{Tp: ast.ShowWarnings}
 	}
+|	"ERRORS"
+	{
+		$$ = &ast.ShowStmt{Tp: ast.ShowErrors}
+	}
 |	GlobalScope "VARIABLES"
 	{
 		$$ = &ast.ShowStmt{
@@ -5226,6 +5931,7 @@ WithReadLockOpt:
 Statement:
 	EmptyStmt
 |	AdminStmt
+|	AlterEventStmt
 |	AlterTableStmt
 |	AlterUserStmt
 |	AnalyzeTableStmt
@@ -5238,12 +5944,16 @@ Statement:
 |	ExplainStmt
 |	CreateDatabaseStmt
 |	CreateIndexStmt
+|	CreateEventStmt
 |	CreateTableStmt
+|	CreateTriggerStmt
 |	CreateUserStmt
 |	DoStmt
 |	DropDatabaseStmt
+|	DropEventStmt
 |	DropIndexStmt
 |	DropTableStmt
+|	DropTriggerStmt
 |	DropViewStmt
 |	DropUserStmt
 |	FlushStmt
@@ -5260,6 +5970,8 @@ Statement:
 |	UnionStmt
 |	SetStmt
 |	ShowStmt
+|	SplitRegionStmt
+|	TraceStmt
 |	TruncateTableStmt
 |	UpdateStmt
 |	UseStmt
@@ -5366,6 +6078,10 @@ TableOption:
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionAutoIncrement, UintValue: $3.(uint64)}
 	}
+|	"AUTO_ID_CACHE" EqOpt LengthNum
+	{
+		$$ = &ast.TableOption{Tp: ast.TableOptionAutoIDCache, UintValue: $3.(uint64)}
+	}
 |	"COMMENT" EqOpt stringLit
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionComment, StrValue: $3}
@@ -5446,6 +6162,24 @@ OptTable:
 	{}
 |	"TABLE"
 
+/*******************************************************************
+ *
+ *  Split Region Statement
+ *
+ *  Example:
+ *	SPLIT TABLE t BETWEEN (0) AND (1000000) REGIONS 10
+ *******************************************************************/
+SplitRegionStmt:
+	"SPLIT" "TABLE" TableName "BETWEEN" '(' ExpressionList ')' "AND" '(' ExpressionList ')' "REGIONS" LengthNum
+	{
+		$$ = &ast.SplitRegionStmt{
+			Table:	$3.(*ast.TableName),
+			Lower:	$6.([]ast.ExprNode),
+			Upper:	$10.([]ast.ExprNode),
+			Num:	$13.(uint64),
+		}
+	}
+
 TruncateTableStmt:
 	"TRUNCATE" OptTable TableName
 	{
@@ -21,6 +21,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/testleak"
@@ -50,9 +51,9 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"exists", "explain", "false", "float", "for", "force", "foreign", "from",
 		"fulltext", "grant", "group", "having", "hour_microsecond", "hour_minute",
 		"hour_second", "if", "ignore", "in", "index", "infile", "inner", "insert", "int", "into", "integer",
-		"interval", "is", "join", "key", "keys", "kill", "leading", "left", "like", "limit", "lines", "load",
+		"interval", "is", "join", "key", "keys", "kill", "lateral", "leading", "left", "like", "limit", "lines", "load",
 		"localtime", "localtimestamp", "lock", "longblob", "longtext", "mediumblob", "maxvalue", "mediumint", "mediumtext",
-		"minute_microsecond", "minute_second", "mod", "not", "no_write_to_binlog", "null", "numeric",
+		"minute_microsecond", "minute_second", "mod", "natural", "not", "no_write_to_binlog", "null", "numeric",
 		"on", "option", "or", "order", "outer", "partition", "precision", "primary", "procedure", "range", "read", "real",
 		"references", "regexp", "rename", "repeat", "replace", "revoke", "restrict", "right", "rlike",
 		"schema", "schemas", "second_microsecond", "select", "set", "show", "smallint",
@@ -79,7 +80,7 @@ func (s *testParserSuite) TestSimple(c *C) {
 
 	// Testcase for unreserved keywords
 	unreservedKws := []string{
-		"auto_increment", "after", "begin", "bit", "bool", "boolean", "charset", "columns", "commit",
+		"auto_increment", "auto_random", "auto_id_cache", "after", "begin", "bit", "bool", "boolean", "charset", "columns", "commit",
 		"date", "datediff", "datetime", "deallocate", "do", "from_days", "end", "engine", "engines", "execute", "first", "full",
 		"local", "names", "offset", "password", "prepare", "quick", "rollback", "session", "signed",
 		"start", "global", "tables", "text", "time", "timestamp", "tidb", "transaction", "truncate", "unknown",
@@ -92,6 +93,7 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"enable", "disable", "reverse", "space", "privileges", "get_lock", "release_lock", "sleep", "no", "greatest", "least",
 		"binlog", "hex", "unhex", "function", "indexes", "from_unixtime", "processlist", "events", "less", "than", "timediff",
 		"ln", "log", "log2", "log10", "timestampdiff", "pi", "quote", "none", "super", "default", "shared", "exclusive",
+		"before", "each", "completion", "ends", "event", "every", "preserve", "schedule", "starts", "owner", "resign", "transfer",
 	}
 	for _, kw := range unreservedKws {
 		src := fmt.Sprintf("SELECT %s FROM tbl;", kw)
@@ -296,6 +298,13 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"load data local infile '/tmp/t.csv' into table t lines starting by 'ab' (a,b)", true},
 		{"load data local infile '/tmp/t.csv' into table t lines starting by 'ab' terminated by 'xy' (a,b)", true},
 		{"load data local infile '/tmp/t.csv' into table t fields terminated by 'ab' lines terminated by 'xy' (a,b)", true},
+
+		// split table region
+		{"split table t between (0) and (1000000) regions 10", true},
+		{"split table test.t between (-100) and (100 * 100) regions 4", true},
+		{"split table t between (0, 'a') and (100, 'z') regions 4", true},
+		{"split table t between 0 and 100 regions 4", false},
+		{"split table t between (0) and (100)", false},
 		{"load data local infile '/tmp/t.csv' into table t (a,b) fields terminated by 'ab'", false},
 
 		// select for update
@@ -307,10 +316,43 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"select * from t1 join t2 left join t3 on t2.id = t3.id", true},
 		{"select * from t1 right join t2 on t1.id = t2.id left join t3 on t3.id = t2.id", true},
 		{"select * from t1 right join t2 on t1.id = t2.id left join t3", false},
+		{"select * from t1 join t2 using (id)", true},
+		{"select * from t1 inner join t2 using (a, b) left outer join t3 using (id)", true},
+		{"select * from t1 right join t2 using (id)", true},
+		{"select * from t1 left join t2 using ()", false},
+		{"select * from t1 natural join t2", true},
+		{"select * from t1 natural inner join t2 natural left join t3", true},
+		{"select * from t1 natural right outer join t2", true},
+		{"select * from t1 natural join t2 on t1.id = t2.id", false},
+		{"select * from t1 natural cross join t2", false},
+		{"select * from t1, lateral (select * from t2 where t2.a = t1.a) as t", true},
+		{"select * from t1 left join lateral (select * from t2 where t2.a = t1.a limit 1) t on true", true},
+		{"select * from t1 join lateral (select a from t2 union select a from t3) t", true},
+		{"select * from t1, lateral (select * from t2)", false},
+		{"select * from t1, lateral t2", false},
 
 		// for admin
 		{"admin show ddl;", true},
 		{"admin check table t1, t2;", true},
+		{"admin reload expr_pushdown_blacklist;", true},
+		{"admin reload resource_groups;", true},
+		{"admin reload config;", true},
+		{"admin show ddl owner;", true},
+		{"admin transfer ddl owner to 'a7e0c0b4-0b4f-4c2f-a8f6-5d5b5fd0d3c1';", true},
+		{"admin transfer ddl owner;", false},
+		{"admin resign ddl owner;", true},
+		{"admin show slow recent 3;", true},
+		{"admin show slow top 3;", true},
+		{"admin show slow top internal 3;", true},
+		{"admin show slow top all 3;", true},
+		{"admin show slow top;", false},
+		{"admin show slow 3;", false},
+		{"select slow, recent, top, internal from t;", true},
+		{"select resource_groups from t;", true},
+		{"select config from t;", true},
+		{"select selectivity from t where selectivity > 0;", true},
+		{"select index_asc, index_desc from t where index_desc > 0;", true},
+		{"select array, member from member where array > 0 and member.member > 0;", true},
 
 		// for on duplicate key update
 		{"INSERT INTO t (a,b,c) VALUES (1,2,3),(4,5,6) ON DUPLICATE KEY UPDATE c=VALUES(a)+VALUES(b);", true},
@@ -401,6 +443,12 @@ func (s *testParserSuite) TestDBAStmt(c *C) {
 		// for show create table
 		{"show create table test.t", true},
 		{"show create table t", true},
+		// for show warnings and errors
+		{"show warnings", true},
+		{"show errors", true},
+		{"show count(*) warnings", true},
+		{"show count(*) errors", true},
+		{"show count(a) warnings", false},
 
 		// set
 		// user defined
@@ -436,6 +484,10 @@ func (s *testParserSuite) TestDBAStmt(c *C) {
 		{"SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED", true},
 		{"SET SESSION TRANSACTION ISOLATION LEVEL READ UNCOMMITTED", true},
 		{"SET SESSION TRANSACTION ISOLATION LEVEL SERIALIZABLE", true},
+		{"SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED, READ ONLY", true},
+		{"SET TRANSACTION ISOLATION LEVEL REPEATABLE READ", true},
+		{"SET TRANSACTION READ ONLY", true},
+		{"SET TRANSACTION", false},
 		// for set names
 		{"set names utf8", true},
 		{"set names utf8 collate utf8_unicode_ci", true},
@@ -479,6 +531,24 @@ func (s *testParserSuite) TestFlushPrivileges(c *C) {
 	c.Assert(flushPrivilege.Tp, Equals, ast.FlushPrivileges)
 }
 
+func (s *testParserSuite) TestSetTransaction(c *C) {
+	parser := New()
+	stmt, err := parser.Parse("set global transaction isolation level read committed, read only", "", "")
+	c.Assert(err, IsNil)
+	setStmt := stmt[0].(*ast.SetStmt)
+	c.Assert(setStmt.Variables, HasLen, 2)
+	c.Assert(setStmt.Variables[0].Name, Equals, "tx_isolation")
+	c.Assert(setStmt.Variables[0].IsGlobal, IsTrue)
+	c.Assert(setStmt.Variables[0].IsSystem, IsTrue)
+	c.Assert(setStmt.Variables[0].Value.GetValue(), Equals, "READ-COMMITTED")
+	c.Assert(setStmt.Variables[1].Name, Equals, "tx_read_only")
+	c.Assert(setStmt.Variables[1].Value.GetValue(), Equals, "1")
+
+	stmt, err = parser.Parse("set transaction isolation level serializable", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt[0].(*ast.SetStmt).Variables, HasLen, 0)
+}
+
 func (s *testParserSuite) TestExpression(c *C) {
 	defer testleak.AfterTest(c)()
 	table := []testCase{
@@ -825,6 +895,10 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`SELECT FORMAT(), FORMAT(12332.2,2,'de_DE'), FORMAT(12332.123456, 4)`, true},
 		{`SELECT FROM_BASE64('abc')`, true},
 		{`SELECT TO_BASE64('abc')`, true},
+		{`SELECT WEIGHT_STRING('abc')`, true},
+		{`SELECT WEIGHT_STRING('abc' AS CHAR(5)), WEIGHT_STRING('abc' AS BINARY(2))`, true},
+		{`SELECT WEIGHT_STRING('abc' AS CHAR)`, false},
+		{`SELECT WEIGHT_STRING('abc', 'CHAR', 5)`, false},
 		{`SELECT INSERT(), INSERT('Quadratic', 3, 4, 'What'), INSTR('foobarbar', 'bar')`, true},
 		{`SELECT LOAD_FILE('/tmp/picture')`, true},
 		{`SELECT LPAD('hi',4,'??')`, true},
@@ -852,6 +926,9 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`SELECT RELEASE_ALL_LOCKS();`, true},
 		{`SELECT UUID();`, true},
 		{`SELECT UUID_SHORT()`, true},
+		{`SELECT UUID_TO_BIN(UUID()), UUID_TO_BIN(UUID(), 1);`, true},
+		{`SELECT BIN_TO_UUID(id), BIN_TO_UUID(id, true) FROM t;`, true},
+		{`CREATE TABLE t (uuid_to_bin int, bin_to_uuid int);`, true},
 		// test illegal arguments
 		{`SELECT SLEEP();`, true},
 		{`SELECT ANY_VALUE();`, true},
@@ -871,6 +948,15 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`SELECT UUID(1);`, true},
 		{`SELECT UUID_SHORT(1)`, true},
 
+		// for TiDB internal functions
+		{`SELECT TIDB_DIGEST('select 1'), TIDB_NORMALIZE('select 1');`, true},
+		{`SELECT tidb_digest(a) FROM t;`, true},
+		{`CREATE TABLE t (tidb_digest int, tidb_normalize int);`, true},
+		{`SELECT TIDB_DECODE_KEY('7480000000000000FF');`, true},
+		{`CREATE TABLE t (tidb_decode_key int);`, true},
+		{`SELECT TIDB_VERSION(), TIDB_CURRENT_TSO(), TIDB_IS_DDL_OWNER();`, true},
+		{`CREATE TABLE t (tidb_version int, tidb_current_tso int, tidb_is_ddl_owner int);`, true},
+
 		// for date_add
 		{`select date_add("2011-11-11 10:10:10.123456", interval 10 microsecond)`, true},
 		{`select date_add("2011-11-11 10:10:10.123456", interval 10 second)`, true},
@@ -896,6 +982,12 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`select date_add("2011-11-11 10:10:10.123456", 0.10)`, false},
 		{`select date_add("2011-11-11 10:10:10.123456", "11,11")`, false},
 
+		// for the +/- INTERVAL operators
+		{`select "2011-11-11 10:10:10" + interval 10 day`, true},
+		{`select "2011-11-11 10:10:10" - interval "1:1" hour_minute`, true},
+		{`select a + interval 1 day - interval 2 hour + 1 from t`, true},
+		{`select interval(1, 0, 1) + 1`, true},
+
 		// for strcmp
 		{`select strcmp('abc', 'def')`, true},
 
@@ -1005,6 +1097,11 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`select count(all c1) from t;`, true},
 		{`select group_concat(c2,c1) from t group by c1;`, true},
 		{`select group_concat(distinct c2,c1) from t group by c1;`, true},
+		{`select json_arrayagg(c2), json_objectagg(c1, c2) from t group by c1;`, true},
+		{`select json_arrayagg(c1, c2) from t;`, false},
+		{`select json_objectagg(c1) from t;`, false},
+		{`create table t (json_arrayagg int, json_objectagg int);`, true},
+		{`select json_arrayagg(distinct c1) from t;`, false},
 
 		// for encryption and compression functions
 		{`select AES_ENCRYPT('text',UNHEX('F3229A0B371ED2D9441B830D21A390C3'))`, true},
@@ -1026,8 +1123,44 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`SELECT UNCOMPRESS('any string');`, true},
 		{`SELECT UNCOMPRESSED_LENGTH(@compressed_string);`, true},
 		{`SELECT VALIDATE_PASSWORD_STRENGTH(@str);`, true},
+
+		// for json functions
+		{`SELECT JSON_EXTRACT(a, '$.b', '$.c'), JSON_CONTAINS(a, '1'), JSON_CONTAINS(a, '1', '$.b') FROM t;`, true},
+		{`SELECT * FROM t WHERE 1 MEMBER OF (a) AND 'x' member  of(JSON_EXTRACT(a, '$.tags'));`, true},
+		{`SELECT 1 MEMBER (a);`, false},
+		{`SELECT 1 MEMBER OF a;`, false},
+		{`SELECT JSON_SET(a, '$.b', 1, '$.c', JSON_ARRAY()), JSON_UNQUOTE(JSON_EXTRACT(a, '$.b')) FROM t;`, true},
+		{`SELECT JSON_OBJECT(), JSON_OBJECT('a', 1, 'b', JSON_ARRAY(1, 'x', NULL)), CAST('[1]' AS JSON);`, true},
+		{`SELECT JSON_SET(a) FROM t;`, false},
+		{`create table t (json_set int, json_object int, json_array int);`, true},
+
+		// for window functions
+		{`select row_number() over (), rank() over (order by c1) from t;`, true},
+		{`select a, row_number() over (partition by b, c order by d desc, e), rank() over (partition by b) from t;`, true},
+		{`select sum(a) over (partition by b order by c), count(*) over (), avg(a + 1) over (order by b) from t;`, true},
+		{`select sum(distinct a) over (partition by b) from t;`, false},
+		{`select row_number(a) over () from t;`, false},
+		{`select row_number() from t;`, false},
+		{`select rank() over partition by a from t;`, false},
+		{`create table t (row_number int, rank int);`, true},
+		{`select over from t;`, false},
 	}
 	s.RunTest(c, table)
+
+	stmt, err := New().ParseOneStmt("select sum(a) over (partition by b order by c desc) from t", "", "")
+	c.Assert(err, IsNil)
+	win := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.WindowFuncExpr)
+	c.Assert(win.F, Equals, ast.AggFuncSum)
+	c.Assert(win.Args, HasLen, 1)
+	c.Assert(win.Spec.PartitionBy, HasLen, 1)
+	c.Assert(win.Spec.OrderBy, HasLen, 1)
+	c.Assert(win.Spec.OrderBy[0].Desc, IsTrue)
+
+	stmt, err = New().ParseOneStmt("select 1 member of (a)", "", "")
+	c.Assert(err, IsNil)
+	expr := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.FuncCallExpr)
+	c.Assert(expr.FnName.L, Equals, ast.JSONMemberOf)
+	c.Assert(expr.Args, HasLen, 2)
 }
 
 func (s *testParserSuite) TestIdentifier(c *C) {
@@ -1241,6 +1374,14 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"create table ts (t int, v timestamp(3) default CURRENT_TIMESTAMP(3));", true},
 		// Create table with primary key name.
 		{"create table if not exists `t` (`id` int not null auto_increment comment '消息ID', primary key `pk_id` (`id`) );", true},
+		// Create table with auto random.
+		{"create table t (id bigint primary key auto_random)", true},
+		{"create table t (id bigint auto_random(3), primary key (id))", true},
+		{"create table t (id bigint primary key auto_random())", false},
+		// Create table with auto id cache.
+		{"create table t (id int auto_increment primary key) auto_id_cache 1", true},
+		{"create table t (id int auto_increment primary key) auto_id_cache = 100", true},
+		{"create table t (id int auto_increment primary key) auto_id_cache = -1", false},
 		// Create table with like.
 		{"create table a like b", true},
 		{"create table if not exists a like b", true},
@@ -1255,6 +1396,10 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"ALTER TABLE t ADD COLUMN a SMALLINT UNSIGNED", true},
 		{"ALTER TABLE t ADD COLUMN a SMALLINT UNSIGNED FIRST", true},
 		{"ALTER TABLE t ADD COLUMN a SMALLINT UNSIGNED AFTER b", true},
+		{"ALTER TABLE t ADD COLUMN a varchar(36) DEFAULT (UUID())", true},
+		{"ALTER TABLE t ADD COLUMN a int DEFAULT (1 + 1) NOT NULL", true},
+		{"ALTER TABLE t ADD COLUMN a int DEFAULT 1 + 1", false},
+		{"CREATE TABLE t (a int DEFAULT (1), b char(36) DEFAULT (uuid()))", true},
 		{"ALTER TABLE t DISABLE KEYS", true},
 		{"ALTER TABLE t ENABLE KEYS", true},
 		{"ALTER TABLE t MODIFY COLUMN a varchar(255)", true},
@@ -1266,6 +1411,8 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT CURRENT_TIMESTAMP", false},
 		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT NOW()", false},
 		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT 1+1", false},
+		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT (1+1)", true},
+		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT (NOW())", true},
 		{"ALTER TABLE t ALTER COLUMN a DROP DEFAULT", true},
 		{"ALTER TABLE t ALTER a DROP DEFAULT", true},
 		{"ALTER TABLE t ADD COLUMN a SMALLINT UNSIGNED, lock=none", true},
@@ -1292,8 +1439,106 @@ func (s *testParserSuite) TestDDL(c *C) {
 		// for truncate statement
 		{"TRUNCATE TABLE t1", true},
 		{"TRUNCATE t1", true},
+
+		// for trigger statement
+		{"CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW SET NEW.a = NEW.a + 1", true},
+		{"CREATE TRIGGER trg BEFORE UPDATE ON db.t FOR EACH ROW SET NEW.a = OLD.a, NEW.b = 1", true},
+		{"CREATE TRIGGER trg AFTER INSERT ON t FOR EACH ROW INSERT INTO t1 VALUES (NEW.a)", true},
+		{"CREATE TRIGGER trg AFTER UPDATE ON t FOR EACH ROW UPDATE t1 SET b = NEW.b WHERE a = OLD.a", true},
+		{"CREATE TRIGGER trg AFTER DELETE ON t FOR EACH ROW DELETE FROM t1 WHERE a = OLD.a", true},
+		{"CREATE TRIGGER trg BEFORE DELETE ON t FOR EACH ROW REPLACE INTO t1 SET a = OLD.a", true},
+		{"CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW SET", false},
+		{"CREATE TRIGGER trg BEFORE INSERT ON t SET NEW.a = 1", false},
+		{"CREATE TRIGGER trg BEFORE SELECT ON t FOR EACH ROW SET NEW.a = 1", false},
+		{"CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW SELECT 1", false},
+		{"DROP TRIGGER trg", true},
+		{"DROP TRIGGER IF EXISTS db.trg", true},
+		// for multi-valued index
+		{"CREATE TABLE t (a json, INDEX idx ((CAST(a AS UNSIGNED ARRAY))))", true},
+		{"CREATE INDEX idx ON t ((CAST(JSON_EXTRACT(a, '$.tags') AS CHAR(10) ARRAY)))", true},
+		{"ALTER TABLE t ADD INDEX idx ((CAST(a AS SIGNED ARRAY)))", true},
+		{"CREATE INDEX idx ON t ((CAST(a AS SIGNED)))", false},
+		{"CREATE INDEX idx ON t (CAST(a AS SIGNED ARRAY))", false},
 	}
 	s.RunTest(c, table)
+
+	stmt, err := New().ParseOneStmt("CREATE INDEX idx ON t ((CAST(JSON_EXTRACT(a, '$.tags') AS CHAR(10) ARRAY)))", "", "")
+	c.Assert(err, IsNil)
+	icn := stmt.(*ast.CreateIndexStmt).IndexColNames[0]
+	c.Assert(icn.Column.Name.L, Equals, "a")
+	c.Assert(icn.MultiValued, IsTrue)
+	c.Assert(icn.Path, Equals, "$.tags")
+	c.Assert(icn.Tp.Tp, Equals, mysql.TypeString)
+	c.Assert(icn.Tp.Flen, Equals, 10)
+
+	stmt, err = New().ParseOneStmt("CREATE TRIGGER trg AFTER DELETE ON db.t FOR EACH ROW DELETE FROM t1 WHERE a = OLD.a", "", "")
+	c.Assert(err, IsNil)
+	ct := stmt.(*ast.CreateTriggerStmt)
+	c.Assert(ct.Name, Equals, "trg")
+	c.Assert(ct.Timing, Equals, model.TriggerAfter)
+	c.Assert(ct.Event, Equals, model.TriggerDelete)
+	c.Assert(ct.Table.Schema.L, Equals, "db")
+	c.Assert(ct.Assignments, IsNil)
+	_, ok := ct.Body.(*ast.DeleteStmt)
+	c.Assert(ok, IsTrue)
+	c.Assert(ct.BodyText, Equals, "DELETE FROM t1 WHERE a = OLD.a")
+
+	stmt, err = New().ParseOneStmt("DROP TRIGGER IF EXISTS db.trg", "", "")
+	c.Assert(err, IsNil)
+	dt := stmt.(*ast.DropTriggerStmt)
+	c.Assert(dt.IfExists, IsTrue)
+	c.Assert(dt.Schema.L, Equals, "db")
+	c.Assert(dt.Name.L, Equals, "trg")
+}
+
+func (s *testParserSuite) TestEvent(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"CREATE EVENT e ON SCHEDULE AT '2017-01-01 00:00:00' DO DELETE FROM t", true},
+		{"CREATE EVENT IF NOT EXISTS db.e ON SCHEDULE AT DATE_ADD(CURRENT_TIMESTAMP, INTERVAL 1 HOUR) DO INSERT INTO t VALUES (1)", true},
+		{"CREATE EVENT e ON SCHEDULE EVERY 1 DAY DO UPDATE t SET a = a + 1", true},
+		{"CREATE EVENT e ON SCHEDULE EVERY 10 MINUTE STARTS NOW() ENDS DATE_ADD(NOW(), INTERVAL 1 DAY) ON COMPLETION PRESERVE DISABLE COMMENT 'cleanup' DO TRUNCATE TABLE t", true},
+		{"CREATE EVENT e ON SCHEDULE EVERY 1 HOUR ON COMPLETION NOT PRESERVE ENABLE DO DELETE FROM t WHERE ts < DATE_SUB(NOW(), INTERVAL 1 DAY)", true},
+		{"CREATE EVENT e ON SCHEDULE EVERY 1 WEEK DO ANALYZE TABLE t", true},
+		{"CREATE EVENT e ON SCHEDULE EVERY 1 DAY DO DO SLEEP(1)", true},
+		{"CREATE EVENT e ON SCHEDULE EVERY 1 DAY", false},
+		{"CREATE EVENT e DO DELETE FROM t", false},
+		{"CREATE EVENT e ON SCHEDULE EVERY 1 DAY DO SELECT 1", false},
+		{"ALTER EVENT e DISABLE", true},
+		{"ALTER EVENT db.e ON SCHEDULE EVERY 2 HOUR", true},
+		{"ALTER EVENT e ON COMPLETION PRESERVE ENABLE", true},
+		{"ALTER EVENT e ON SCHEDULE AT NOW() ON COMPLETION NOT PRESERVE DO DELETE FROM t", true},
+		{"DROP EVENT e", true},
+		{"DROP EVENT IF EXISTS db.e", true},
+		{"SHOW EVENTS", true},
+		{"SHOW EVENTS FROM db LIKE 'e%'", true},
+	}
+	s.RunTest(c, table)
+
+	stmt, err := New().ParseOneStmt("CREATE EVENT db.e ON SCHEDULE EVERY 1 DAY STARTS NOW() ON COMPLETION PRESERVE DISABLE COMMENT 'c' DO DELETE FROM t WHERE a = 1;", "", "")
+	c.Assert(err, IsNil)
+	ce := stmt.(*ast.CreateEventStmt)
+	c.Assert(ce.Schema.L, Equals, "db")
+	c.Assert(ce.Name.L, Equals, "e")
+	c.Assert(ce.Schedule.At, IsNil)
+	c.Assert(ce.Schedule.Every, NotNil)
+	c.Assert(ce.Schedule.Unit, Equals, "DAY")
+	c.Assert(ce.Schedule.Starts, NotNil)
+	c.Assert(ce.Schedule.Ends, IsNil)
+	c.Assert(ce.OnCompletion, Equals, ast.EventCompletionPreserve)
+	c.Assert(ce.Status, Equals, ast.EventStatusDisable)
+	c.Assert(ce.Comment, Equals, "c")
+	_, ok := ce.Body.(*ast.DeleteStmt)
+	c.Assert(ok, IsTrue)
+	c.Assert(ce.BodyText, Equals, "DELETE FROM t WHERE a = 1")
+
+	stmt, err = New().ParseOneStmt("ALTER EVENT e ON COMPLETION NOT PRESERVE DO TRUNCATE TABLE t", "", "")
+	c.Assert(err, IsNil)
+	ae := stmt.(*ast.AlterEventStmt)
+	c.Assert(ae.Schedule, IsNil)
+	c.Assert(ae.OnCompletion, Equals, ast.EventCompletionNotPreserve)
+	c.Assert(ae.Status, Equals, ast.EventStatusDefault)
+	c.Assert(ae.BodyText, Equals, "TRUNCATE TABLE t")
 }
 
 func (s *testParserSuite) TestOptimizerHints(c *C) {
@@ -1329,6 +1574,31 @@ func (s *testParserSuite) TestOptimizerHints(c *C) {
 	c.Assert(hints[1].HintName.L, Equals, "tidb_inlj")
 	c.Assert(hints[1].Tables[0].L, Equals, "t3")
 	c.Assert(hints[1].Tables[1].L, Equals, "t4")
+
+	stmt, err = parser.Parse("select /*+ INDEX_DESC(t1) index_asc(t2, T3) */ c1 from t1, t2 order by c1 desc limit 1", "", "")
+	c.Assert(err, IsNil)
+	selectStmt = stmt[0].(*ast.SelectStmt)
+
+	hints = selectStmt.TableHints
+	c.Assert(len(hints), Equals, 2)
+	c.Assert(hints[0].HintName.L, Equals, "index_desc")
+	c.Assert(len(hints[0].Tables), Equals, 1)
+	c.Assert(hints[0].Tables[0].L, Equals, "t1")
+	c.Assert(hints[1].HintName.L, Equals, "index_asc")
+	c.Assert(len(hints[1].Tables), Equals, 2)
+	c.Assert(hints[1].Tables[1].L, Equals, "t3")
+
+	stmt, err = parser.Parse("select /*+ SELECTIVITY(c1 like '%a%', 0.01) selectivity(c2 > 1, 1) */ c1 from t1 where c1 like '%a%' and c2 > 1", "", "")
+	c.Assert(err, IsNil)
+	selectStmt = stmt[0].(*ast.SelectStmt)
+
+	hints = selectStmt.TableHints
+	c.Assert(len(hints), Equals, 2)
+	c.Assert(hints[0].HintName.L, Equals, "selectivity")
+	c.Assert(hints[0].Expr, FitsTypeOf, &ast.PatternLikeExpr{})
+	c.Assert(hints[0].Selectivity, Equals, 0.01)
+	c.Assert(hints[1].Expr, FitsTypeOf, &ast.BinaryOperationExpr{})
+	c.Assert(hints[1].Selectivity, Equals, float64(1))
 }
 
 func (s *testParserSuite) TestType(c *C) {
@@ -1566,6 +1836,28 @@ func (s *testParserSuite) TestExplain(c *C) {
 		{"explain replace into foo values (1 || 2)", true},
 		{"explain update t set id = id + 1 order by id desc;", true},
 		{"explain select c1 from t1 union (select c2 from t2) limit 1, 1", true},
+		{"explain analyze select c1 from t1", true},
+		{"explain analyze insert into t values (1), (2), (3)", true},
+		{"explain analyze t1", false},
+		{"explain for connection 42", true},
+		{"desc for connection 42", true},
+		{"explain for connection", false},
+		{"explain for connection abc", false},
+	}
+	s.RunTest(c, table)
+}
+
+func (s *testParserSuite) TestTrace(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"trace select c1 from t1", true},
+		{"trace select c1 from t1 union (select c2 from t2) limit 1, 1", true},
+		{"trace insert into t values (1), (2), (3)", true},
+		{"trace update t set id = id + 1 order by id desc", true},
+		{"trace delete from t where id = 1", true},
+		{"trace t1", false},
+		{"trace create table t (a int)", false},
+		{"select trace from trace", true},
 	}
 	s.RunTest(c, table)
 }
@@ -1618,6 +1910,14 @@ func (s *testParserSuite) TestSessionManage(c *C) {
 		{"kill tidb connection 23123", true},
 		{"kill tidb query 23123", true},
 		{"show processlist", true},
+		{"show table t regions", true},
+		{"show hotspots", true},
+		{"show hotspots where table_name = 't'", true},
+		{"show master status", true},
+		{"show master", false},
+		{"show table test.t regions", true},
+		{"show table status regions", true},
+		{"show table t", false},
 	}
 	s.RunTest(c, table)
 }
@@ -1668,6 +1968,14 @@ func (s *testParserSuite) TestDDLStatements(c *C) {
 		c.Assert(colDef.Tp.Collate, Equals, charset.CollationBin)
 		c.Assert(mysql.HasBinaryFlag(colDef.Tp.Flag), IsTrue)
 	}
+
+	// The text of the default value expression is kept.
+	stmts, err = parser.Parse("CREATE TABLE t (a char(36) DEFAULT ( uuid() ))", "", "")
+	c.Assert(err, IsNil)
+	stmt = stmts[0].(*ast.CreateTableStmt)
+	expr, ok := stmt.Cols[0].Options[0].Expr.(*ast.ParenthesesExpr)
+	c.Assert(ok, IsTrue)
+	c.Assert(expr.Expr.Text(), Equals, "uuid()")
 }
 
 func (s *testParserSuite) TestAnalyze(c *C) {
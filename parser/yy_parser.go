@@ -17,6 +17,7 @@ import (
 	"math"
 	"regexp"
 	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/juju/errors"
@@ -142,6 +143,14 @@ func (parser *Parser) endOffset(v *yySymType) int {
 	return offset
 }
 
+// textToLexerPos returns the text from the start of v to the current position of the lexer,
+// it is used for the text of a statement body which is at the end of the whole statement.
+// The lexer position may be just after the lookahead ';'.
+func (parser *Parser) textToLexerPos(v *yySymType) string {
+	text := strings.TrimSpace(parser.src[v.offset-1 : parser.lexer.r.pos().Offset])
+	return strings.TrimSpace(strings.TrimSuffix(text, ";"))
+}
+
 func toInt(l yyLexer, lval *yySymType, str string) int {
 	n, err := strconv.ParseUint(str, 10, 64)
 	if err != nil {
@@ -228,3 +237,18 @@ func getUint64FromNUM(num interface{}) uint64 {
 	}
 	return 0
 }
+
+func getFloat64FromNUM(num interface{}) float64 {
+	switch v := num.(type) {
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float64:
+		return v
+	case *types.MyDecimal:
+		f, _ := v.ToFloat64()
+		return f
+	}
+	return 0
+}
@@ -36,6 +36,9 @@ const (
 	TableStagesCurrent          = "EVENTS_STAGES_CURRENT"
 	TableStagesHistory          = "EVENTS_STAGES_HISTORY"
 	TableStagesHistoryLong      = "EVENTS_STAGES_HISTORY_LONG"
+	TableWaitsCurrent           = "EVENTS_WAITS_CURRENT"
+	TableWaitsHistory           = "EVENTS_WAITS_HISTORY"
+	TableWaitsHistoryLong       = "EVENTS_WAITS_HISTORY_LONG"
 )
 
 // PerfSchemaTables is a shortcut to involve all table names.
@@ -55,6 +58,9 @@ var PerfSchemaTables = []string{
 	TableStagesCurrent,
 	TableStagesHistory,
 	TableStagesHistoryLong,
+	TableWaitsCurrent,
+	TableWaitsHistory,
+	TableWaitsHistoryLong,
 }
 
 // ColumnSetupActors contains the column name definitions for table setup_actors, same as MySQL.
@@ -690,3 +696,135 @@ var ColumnStagesHistoryLong = []string{
 	"NESTING_EVENT_ID",
 	"NESTING_EVENT_TYPE",
 }
+
+// ColumnWaitsCurrent contains the column name definitions for table events_waits_current, same as MySQL.
+//
+// CREATE TABLE if not exists performance_schema.events_waits_current (
+// 		THREAD_ID		BIGINT(20) UNSIGNED NOT NULL,
+// 		EVENT_ID		BIGINT(20) UNSIGNED NOT NULL,
+// 		END_EVENT_ID	BIGINT(20) UNSIGNED,
+// 		EVENT_NAME		VARCHAR(128) NOT NULL,
+// 		SOURCE			VARCHAR(64),
+// 		TIMER_START		BIGINT(20) UNSIGNED,
+// 		TIMER_END		BIGINT(20) UNSIGNED,
+// 		TIMER_WAIT		BIGINT(20) UNSIGNED,
+// 		SPINS			INT(10) UNSIGNED,
+// 		OBJECT_SCHEMA	VARCHAR(64),
+// 		OBJECT_NAME		VARCHAR(512),
+// 		INDEX_NAME		VARCHAR(64),
+// 		OBJECT_TYPE		VARCHAR(64),
+// 		OBJECT_INSTANCE_BEGIN	BIGINT(20) UNSIGNED NOT NULL,
+// 		NESTING_EVENT_ID		BIGINT(20) UNSIGNED,
+// 		NESTING_EVENT_TYPE		ENUM('TRANSACTION','STATEMENT','STAGE','WAIT'),
+// 		OPERATION		VARCHAR(32) NOT NULL,
+// 		NUMBER_OF_BYTES	BIGINT(20),
+// 		FLAGS			INT(10) UNSIGNED);
+var ColumnWaitsCurrent = []string{
+	"THREAD_ID",
+	"EVENT_ID",
+	"END_EVENT_ID",
+	"EVENT_NAME",
+	"SOURCE",
+	"TIMER_START",
+	"TIMER_END",
+	"TIMER_WAIT",
+	"SPINS",
+	"OBJECT_SCHEMA",
+	"OBJECT_NAME",
+	"INDEX_NAME",
+	"OBJECT_TYPE",
+	"OBJECT_INSTANCE_BEGIN",
+	"NESTING_EVENT_ID",
+	"NESTING_EVENT_TYPE",
+	"OPERATION",
+	"NUMBER_OF_BYTES",
+	"FLAGS",
+}
+
+// ColumnWaitsHistory contains the column name definitions for table events_waits_history, same as MySQL.
+//
+// CREATE TABLE if not exists performance_schema.events_waits_history (
+// 		THREAD_ID		BIGINT(20) UNSIGNED NOT NULL,
+// 		EVENT_ID		BIGINT(20) UNSIGNED NOT NULL,
+// 		END_EVENT_ID	BIGINT(20) UNSIGNED,
+// 		EVENT_NAME		VARCHAR(128) NOT NULL,
+// 		SOURCE			VARCHAR(64),
+// 		TIMER_START		BIGINT(20) UNSIGNED,
+// 		TIMER_END		BIGINT(20) UNSIGNED,
+// 		TIMER_WAIT		BIGINT(20) UNSIGNED,
+// 		SPINS			INT(10) UNSIGNED,
+// 		OBJECT_SCHEMA	VARCHAR(64),
+// 		OBJECT_NAME		VARCHAR(512),
+// 		INDEX_NAME		VARCHAR(64),
+// 		OBJECT_TYPE		VARCHAR(64),
+// 		OBJECT_INSTANCE_BEGIN	BIGINT(20) UNSIGNED NOT NULL,
+// 		NESTING_EVENT_ID		BIGINT(20) UNSIGNED,
+// 		NESTING_EVENT_TYPE		ENUM('TRANSACTION','STATEMENT','STAGE','WAIT'),
+// 		OPERATION		VARCHAR(32) NOT NULL,
+// 		NUMBER_OF_BYTES	BIGINT(20),
+// 		FLAGS			INT(10) UNSIGNED);
+var ColumnWaitsHistory = []string{
+	"THREAD_ID",
+	"EVENT_ID",
+	"END_EVENT_ID",
+	"EVENT_NAME",
+	"SOURCE",
+	"TIMER_START",
+	"TIMER_END",
+	"TIMER_WAIT",
+	"SPINS",
+	"OBJECT_SCHEMA",
+	"OBJECT_NAME",
+	"INDEX_NAME",
+	"OBJECT_TYPE",
+	"OBJECT_INSTANCE_BEGIN",
+	"NESTING_EVENT_ID",
+	"NESTING_EVENT_TYPE",
+	"OPERATION",
+	"NUMBER_OF_BYTES",
+	"FLAGS",
+}
+
+// ColumnWaitsHistoryLong contains the column name definitions for table events_waits_history_long, same as MySQL.
+//
+// CREATE TABLE if not exists performance_schema.events_waits_history_long (
+// 		THREAD_ID		BIGINT(20) UNSIGNED NOT NULL,
+// 		EVENT_ID		BIGINT(20) UNSIGNED NOT NULL,
+// 		END_EVENT_ID	BIGINT(20) UNSIGNED,
+// 		EVENT_NAME		VARCHAR(128) NOT NULL,
+// 		SOURCE			VARCHAR(64),
+// 		TIMER_START		BIGINT(20) UNSIGNED,
+// 		TIMER_END		BIGINT(20) UNSIGNED,
+// 		TIMER_WAIT		BIGINT(20) UNSIGNED,
+// 		SPINS			INT(10) UNSIGNED,
+// 		OBJECT_SCHEMA	VARCHAR(64),
+// 		OBJECT_NAME		VARCHAR(512),
+// 		INDEX_NAME		VARCHAR(64),
+// 		OBJECT_TYPE		VARCHAR(64),
+// 		OBJECT_INSTANCE_BEGIN	BIGINT(20) UNSIGNED NOT NULL,
+// 		NESTING_EVENT_ID		BIGINT(20) UNSIGNED,
+// 		NESTING_EVENT_TYPE		ENUM('TRANSACTION','STATEMENT','STAGE','WAIT'),
+// 		OPERATION		VARCHAR(32) NOT NULL,
+// 		NUMBER_OF_BYTES	BIGINT(20),
+// 		FLAGS			INT(10) UNSIGNED);
+var ColumnWaitsHistoryLong = []string{
+	"THREAD_ID",
+	"EVENT_ID",
+	"END_EVENT_ID",
+	"EVENT_NAME",
+	"SOURCE",
+	"TIMER_START",
+	"TIMER_END",
+	"TIMER_WAIT",
+	"SPINS",
+	"OBJECT_SCHEMA",
+	"OBJECT_NAME",
+	"INDEX_NAME",
+	"OBJECT_TYPE",
+	"OBJECT_INSTANCE_BEGIN",
+	"NESTING_EVENT_ID",
+	"NESTING_EVENT_TYPE",
+	"OPERATION",
+	"NUMBER_OF_BYTES",
+	"FLAGS",
+}
@@ -197,6 +197,28 @@ var stagesCurrentCols = []columnInfo{
 	{mysql.TypeEnum, -1, 0, nil, []string{"TRANSACTION", "STATEMENT", "STAGE"}},
 }
 
+var waitsCurrentCols = []columnInfo{
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeVarchar, 128, mysql.NotNullFlag, nil, nil},
+	{mysql.TypeVarchar, 64, 0, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLong, 10, mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeVarchar, 64, 0, nil, nil},
+	{mysql.TypeVarchar, 512, 0, nil, nil},
+	{mysql.TypeVarchar, 64, 0, nil, nil},
+	{mysql.TypeVarchar, 64, 0, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeEnum, -1, 0, nil, []string{"TRANSACTION", "STATEMENT", "STAGE", "WAIT"}},
+	{mysql.TypeVarchar, 32, mysql.NotNullFlag, nil, nil},
+	{mysql.TypeLonglong, 20, 0, nil, nil},
+	{mysql.TypeLong, 10, mysql.UnsignedFlag, nil, nil},
+}
+
 func createMemoryTable(meta *model.TableInfo, alloc autoid.Allocator) (table.Table, error) {
 	tbl, _ := tables.MemoryTableFromMeta(alloc, meta)
 	return tbl, nil
@@ -220,9 +242,10 @@ func (ps *perfSchema) buildTables() error {
 
 		var tbl table.Table
 		switch name {
-		case TableStmtsCurrent, TablePreparedStmtsInstances, TableTransCurrent, TableStagesCurrent:
+		case TableStmtsCurrent, TablePreparedStmtsInstances, TableTransCurrent, TableStagesCurrent, TableWaitsCurrent:
 			tbl = createBoundedTable(meta, alloc, currentElemMax)
-		case TableStmtsHistory, TableStmtsHistoryLong, TableTransHistory, TableTransHistoryLong, TableStagesHistory, TableStagesHistoryLong:
+		case TableStmtsHistory, TableStmtsHistoryLong, TableTransHistory, TableTransHistoryLong, TableStagesHistory, TableStagesHistoryLong,
+			TableWaitsHistory, TableWaitsHistoryLong:
 			tbl = createBoundedTable(meta, alloc, historyElemMax)
 		default:
 			var err error
@@ -332,12 +355,15 @@ var setupTimersRecords = [][]types.Datum{
 	types.MakeDatums("stage", types.Enum{Name: "NANOSECOND", Value: 1}),
 	types.MakeDatums("statement", types.Enum{Name: "NANOSECOND", Value: 1}),
 	types.MakeDatums("transaction", types.Enum{Name: "NANOSECOND", Value: 1}),
+	types.MakeDatums("wait", types.Enum{Name: "NANOSECOND", Value: 1}),
 }
 
 func (ps *perfSchema) initialize() (err error) {
 	ps.tables = make(map[string]*model.TableInfo)
 	ps.mTables = make(map[string]table.Table, len(ps.tables))
 	ps.stmtHandles = make([]int64, currentElemMax)
+	ps.stageHandles = make([]int64, currentElemMax)
+	ps.waitHandles = make([]int64, currentElemMax)
 
 	allColDefs := [][]columnInfo{
 		setupActorsCols,
@@ -355,6 +381,9 @@ func (ps *perfSchema) initialize() (err error) {
 		stagesCurrentCols,
 		stagesCurrentCols, // same as above
 		stagesCurrentCols, // same as above
+		waitsCurrentCols,
+		waitsCurrentCols, // same as above
+		waitsCurrentCols, // same as above
 	}
 
 	allColNames := [][]string{
@@ -373,6 +402,9 @@ func (ps *perfSchema) initialize() (err error) {
 		ColumnStagesCurrent,
 		ColumnStagesHistory,
 		ColumnStagesHistoryLong,
+		ColumnWaitsCurrent,
+		ColumnWaitsHistory,
+		ColumnWaitsHistoryLong,
 	}
 
 	// initialize all table, column and result field definitions
@@ -412,8 +444,8 @@ func (ps *perfSchema) initialize() (err error) {
 	}
 
 	setupConsumersRecords := [][]types.Datum{
-		types.MakeDatums("events_stages_current", types.Enum{Name: "NO", Value: 2}),
-		types.MakeDatums("events_stages_history", types.Enum{Name: "NO", Value: 2}),
+		types.MakeDatums("events_stages_current", types.Enum{Name: "YES", Value: 1}),
+		types.MakeDatums("events_stages_history", types.Enum{Name: "YES", Value: 1}),
 		types.MakeDatums("events_stages_history_long", types.Enum{Name: "NO", Value: 2}),
 		types.MakeDatums("events_statements_current", types.Enum{Name: "YES", Value: 1}),
 		types.MakeDatums("events_statements_history", types.Enum{Name: "YES", Value: 1}),
@@ -421,6 +453,9 @@ func (ps *perfSchema) initialize() (err error) {
 		types.MakeDatums("events_transactions_current", types.Enum{Name: "YES", Value: 1}),
 		types.MakeDatums("events_transactions_history", types.Enum{Name: "YES", Value: 1}),
 		types.MakeDatums("events_transactions_history_long", types.Enum{Name: "YES", Value: 1}),
+		types.MakeDatums("events_waits_current", types.Enum{Name: "YES", Value: 1}),
+		types.MakeDatums("events_waits_history", types.Enum{Name: "YES", Value: 1}),
+		types.MakeDatums("events_waits_history_long", types.Enum{Name: "NO", Value: 2}),
 		types.MakeDatums("global_instrumentation", types.Enum{Name: "YES", Value: 1}),
 		types.MakeDatums("thread_instrumentation", types.Enum{Name: "YES", Value: 1}),
 		types.MakeDatums("statements_digest", types.Enum{Name: "YES", Value: 1}),
@@ -16,6 +16,7 @@ package perfschema
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/util/types"
@@ -35,6 +36,7 @@ const (
 	stageInstrumentPrefix       = "stage/"
 	statementInstrumentPrefix   = "statement/"
 	transactionInstrumentPrefix = "transaction"
+	waitInstrumentPrefix        = "wait/"
 )
 
 // Flag indicators for table setup_timers.
@@ -42,6 +44,7 @@ const (
 	flagStage = iota + 1
 	flagStatement
 	flagTransaction
+	flagWait
 )
 
 type enumTimerName int
@@ -70,10 +73,12 @@ func (ps *perfSchema) addInstrument(name string) (uint64, error) {
 }
 
 func (ps *perfSchema) getTimerName(flag int) (enumTimerName, error) {
-	if flag < 0 || flag >= len(setupTimersRecords) {
+	// Flags start from 1, while records start from 0.
+	idx := flag - 1
+	if idx < 0 || idx >= len(setupTimersRecords) {
 		return timerNameNone, errInvalidTimerFlag.Gen("Unknown timerName flag %d", flag)
 	}
-	timerName := fmt.Sprintf("%s", setupTimersRecords[flag][1].GetString())
+	timerName := fmt.Sprintf("%s", setupTimersRecords[idx][1].GetString())
 	switch timerName {
 	case "NANOSECOND":
 		return timerNameNanosec, nil
@@ -84,3 +89,16 @@ func (ps *perfSchema) getTimerName(flag int) (enumTimerName, error) {
 	}
 	return timerNameNone, nil
 }
+
+// now returns the current time in the unit of the timer.
+func (t enumTimerName) now() int64 {
+	switch t {
+	case timerNameNanosec:
+		return time.Now().UnixNano()
+	case timerNameMicrosec:
+		return time.Now().UnixNano() / int64(time.Microsecond)
+	case timerNameMillisec:
+		return time.Now().UnixNano() / int64(time.Millisecond)
+	}
+	return 0
+}
@@ -37,11 +37,29 @@ type StatementInstrument interface {
 	EndStatement(state *StatementState)
 }
 
+// StageInstrument defines the methods for stage instrumentation points.
+type StageInstrument interface {
+	StartStage(connID uint64, name string) *StageState
+
+	EndStage(state *StageState)
+}
+
+// WaitInstrument defines the methods for wait instrumentation points.
+type WaitInstrument interface {
+	StartWait(connID uint64, name string) *WaitState
+
+	EndWait(state *WaitState)
+}
+
 // PerfSchema defines the methods to be invoked by the executor
 type PerfSchema interface {
 
 	// StatementInstrument is for statement instrumentation only.
 	StatementInstrument
+	// StageInstrument is for stage instrumentation only.
+	StageInstrument
+	// WaitInstrument is for wait instrumentation only.
+	WaitInstrument
 
 	// GetDBMeta returns db info for PerformanceSchema.
 	GetDBMeta() *model.DBInfo
@@ -50,12 +68,18 @@ type PerfSchema interface {
 }
 
 type perfSchema struct {
-	store       kv.Storage
-	dbInfo      *model.DBInfo
-	tables      map[string]*model.TableInfo
-	mTables     map[string]table.Table // Memory tables for perfSchema
-	stmtHandles []int64
-	stmtInfos   map[reflect.Type]*statementInfo
+	store        kv.Storage
+	dbInfo       *model.DBInfo
+	tables       map[string]*model.TableInfo
+	mTables      map[string]table.Table // Memory tables for perfSchema
+	stmtHandles  []int64
+	stmtInfos    map[reflect.Type]*statementInfo
+	stageHandles []int64
+	stageInfos   map[string]*stageInfo
+	waitHandles  []int64
+	waitInfos    map[string]*waitInfo
+	// eventID is used to allocate EVENT_ID for stage and wait events.
+	eventID uint64
 }
 
 var (
@@ -78,6 +102,8 @@ func NewPerfHandle() (PerfSchema, error) {
 		return nil, errors.Trace(err)
 	}
 	schema.registerStatements()
+	schema.registerStages()
+	schema.registerWaits()
 	return schema, nil
 }
 
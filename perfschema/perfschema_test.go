@@ -155,6 +155,33 @@ func (p *testPerfSchemaSuit) TestConcurrentStatement(c *C) {
 	wg.Wait()
 }
 
+func (p *testPerfSchemaSuit) TestStagesAndWaits(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory + "/test_stages_waits")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	se := newSession(c, store, "test_stages_waits")
+	defer se.Close()
+
+	mustExec(c, se, "create table t (a int)")
+	mustExecSQL(c, se, "insert into t values (1)")
+
+	for _, name := range []string{"stage/sql/parse", "stage/sql/compile", "stage/sql/execute", "stage/sql/commit"} {
+		cnt := mustQuery(c, se, fmt.Sprintf("select * from performance_schema.events_stages_history where event_name = '%s'", name))
+		c.Assert(cnt, Greater, 0, Commentf("stage %s", name))
+	}
+	for _, name := range []string{"wait/io/kv/tso", "wait/io/kv/commit"} {
+		cnt := mustQuery(c, se, fmt.Sprintf("select * from performance_schema.events_waits_history where event_name = '%s'", name))
+		c.Assert(cnt, Greater, 0, Commentf("wait %s", name))
+	}
+	cnt := mustQuery(c, se, fmt.Sprintf("select * from performance_schema.events_stages_current where thread_id = %d", se.GetSessionVars().ConnectionID))
+	c.Assert(cnt, Equals, 1)
+
+	mustExec(c, se, "drop database test_stages_waits")
+}
+
 func exec(se tidb.Session, sql string, args ...interface{}) (ast.RecordSet, error) {
 	if len(args) == 0 {
 		rs, err := se.Execute(sql)
@@ -0,0 +1,174 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfschema
+
+import (
+	"sync/atomic"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Stage names of a SQL statement, they are registered with prefix "stage/".
+const (
+	StageParse   = "sql/parse"
+	StageCompile = "sql/compile"
+	StageExecute = "sql/execute"
+	StageCommit  = "sql/commit"
+)
+
+// stageInfo defines stage instrument information.
+type stageInfo struct {
+	// key means registered stage key
+	key uint64
+	// name is the name of the stage instrument to register
+	name string
+}
+
+// StageState provides temporary storage to a stage runtime statistics.
+type StageState struct {
+	// connID means connection identifier
+	connID uint64
+	// eventID means event identifier
+	eventID uint64
+	// info means stage information
+	info *stageInfo
+	// timerName means timer name
+	timerName enumTimerName
+	// timerStart means the timer's start time
+	timerStart int64
+	// timerEnd means the timer's end time
+	timerEnd int64
+}
+
+func (ps *perfSchema) registerStage(name string) {
+	instrumentName := stageInstrumentPrefix + name
+	key, err := ps.addInstrument(instrumentName)
+	if err != nil {
+		// just ignore, do nothing else.
+		log.Errorf("Unable to register instrument %s", instrumentName)
+		return
+	}
+	ps.stageInfos[name] = &stageInfo{
+		key:  key,
+		name: instrumentName,
+	}
+}
+
+func (ps *perfSchema) StartStage(connID uint64, name string) *StageState {
+	if !enablePerfSchema {
+		return nil
+	}
+	info, ok := ps.stageInfos[name]
+	if !ok {
+		// just ignore, do nothing else.
+		log.Errorf("No instrument registered for stage %s", name)
+		return nil
+	}
+	timerName, err := ps.getTimerName(flagStage)
+	if err != nil {
+		// just ignore, do nothing else.
+		log.Error("Unable to check setup_timers table")
+		return nil
+	}
+	if timerName == timerNameNone {
+		return nil
+	}
+	return &StageState{
+		connID:     connID,
+		eventID:    atomic.AddUint64(&ps.eventID, 1),
+		info:       info,
+		timerName:  timerName,
+		timerStart: timerName.now(),
+	}
+}
+
+func (ps *perfSchema) EndStage(state *StageState) {
+	if !enablePerfSchema {
+		return
+	}
+	if state == nil {
+		return
+	}
+	state.timerEnd = state.timerName.now()
+
+	record := stageState2Record(state)
+	err := ps.updateEventsCurrent(TableStagesCurrent, ps.stageHandles, state.connID, record)
+	if err != nil {
+		log.Error("Unable to update events_stages_current table")
+	}
+	err = ps.appendEventsHistory(TableStagesHistory, record)
+	if err != nil {
+		log.Errorf("Unable to append to events_stages_history table %v", errors.ErrorStack(err))
+	}
+}
+
+func stageState2Record(state *StageState) []types.Datum {
+	timerWait := state.timerEnd - state.timerStart
+	return types.MakeDatums(
+		state.connID,                            // THREAD_ID
+		state.eventID,                           // EVENT_ID
+		state.eventID,                           // END_EVENT_ID
+		state.info.name,                         // EVENT_NAME
+		nil,                                     // SOURCE
+		uint64(state.timerStart),                // TIMER_START
+		uint64(state.timerEnd),                  // TIMER_END
+		uint64(timerWait),                       // TIMER_WAIT
+		nil,                                     // WORK_COMPLETED
+		nil,                                     // WORK_ESTIMATED
+		nil,                                     // NESTING_EVENT_ID
+		types.Enum{Name: "STATEMENT", Value: 2}, // NESTING_EVENT_TYPE
+	)
+}
+
+// updateEventsCurrent keeps the latest event of each connection in the events_xxx_current table.
+func (ps *perfSchema) updateEventsCurrent(tblName string, handles []int64, connID uint64, record []types.Datum) error {
+	tbl := ps.mTables[tblName]
+	if tbl == nil {
+		return nil
+	}
+	index := connID % uint64(currentElemMax)
+	handle := atomic.LoadInt64(&handles[index])
+	if handle == 0 {
+		newHandle, err := tbl.AddRecord(nil, record)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		atomic.StoreInt64(&handles[index], newHandle)
+		return nil
+	}
+	err := tbl.UpdateRecord(nil, handle, nil, record, nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+func (ps *perfSchema) appendEventsHistory(tblName string, record []types.Datum) error {
+	tbl := ps.mTables[tblName]
+	if tbl == nil {
+		return nil
+	}
+	_, err := tbl.AddRecord(nil, record)
+	return errors.Trace(err)
+}
+
+func (ps *perfSchema) registerStages() {
+	ps.stageInfos = make(map[string]*stageInfo)
+	ps.registerStage(StageParse)
+	ps.registerStage(StageCompile)
+	ps.registerStage(StageExecute)
+	ps.registerStage(StageCommit)
+}
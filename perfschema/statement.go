@@ -17,7 +17,6 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
-	"sync/atomic"
 	"time"
 
 	"github.com/juju/errors"
@@ -257,55 +256,34 @@ func state2Record(state *StatementState) []types.Datum {
 }
 
 func (ps *perfSchema) updateEventsStmtsCurrent(connID uint64, record []types.Datum) error {
-	tbl := ps.mTables[TableStmtsCurrent]
-	if tbl == nil {
-		return nil
-	}
-	index := connID % uint64(currentElemMax)
-	handle := atomic.LoadInt64(&ps.stmtHandles[index])
-	if handle == 0 {
-		newHandle, err := tbl.AddRecord(nil, record)
-		if err != nil {
-			return errors.Trace(err)
-		}
-		atomic.StoreInt64(&ps.stmtHandles[index], newHandle)
-		return nil
-	}
-	err := tbl.UpdateRecord(nil, handle, nil, record, nil)
-	if err != nil {
-		return errors.Trace(err)
-	}
-	return nil
+	return errors.Trace(ps.updateEventsCurrent(TableStmtsCurrent, ps.stmtHandles, connID, record))
 }
 
 func (ps *perfSchema) appendEventsStmtsHistory(record []types.Datum) error {
-	tbl := ps.mTables[TableStmtsHistory]
-	if tbl == nil {
-		return nil
-	}
-	_, err := tbl.AddRecord(nil, record)
-	if err != nil {
-		return errors.Trace(err)
-	}
-	return nil
+	return errors.Trace(ps.appendEventsHistory(TableStmtsHistory, record))
 }
 
 func (ps *perfSchema) registerStatements() {
 	ps.stmtInfos = make(map[reflect.Type]*statementInfo)
 	// Existing instrument names are the same as MySQL 5.7
+	ps.RegisterStatement("sql", "alter_event", (*ast.AlterEventStmt)(nil))
 	ps.RegisterStatement("sql", "alter_table", (*ast.AlterTableStmt)(nil))
 	ps.RegisterStatement("sql", "begin", (*ast.BeginStmt)(nil))
 	ps.RegisterStatement("sql", "commit", (*ast.CommitStmt)(nil))
 	ps.RegisterStatement("sql", "create_db", (*ast.CreateDatabaseStmt)(nil))
+	ps.RegisterStatement("sql", "create_event", (*ast.CreateEventStmt)(nil))
 	ps.RegisterStatement("sql", "create_index", (*ast.CreateIndexStmt)(nil))
 	ps.RegisterStatement("sql", "create_table", (*ast.CreateTableStmt)(nil))
+	ps.RegisterStatement("sql", "create_trigger", (*ast.CreateTriggerStmt)(nil))
 	ps.RegisterStatement("sql", "create_user", (*ast.CreateUserStmt)(nil))
 	ps.RegisterStatement("sql", "deallocate", (*ast.DeallocateStmt)(nil))
 	ps.RegisterStatement("sql", "delete", (*ast.DeleteStmt)(nil))
 	ps.RegisterStatement("sql", "do", (*ast.DoStmt)(nil))
 	ps.RegisterStatement("sql", "drop_db", (*ast.DropDatabaseStmt)(nil))
 	ps.RegisterStatement("sql", "drop_table", (*ast.DropTableStmt)(nil))
+	ps.RegisterStatement("sql", "drop_event", (*ast.DropEventStmt)(nil))
 	ps.RegisterStatement("sql", "drop_index", (*ast.DropIndexStmt)(nil))
+	ps.RegisterStatement("sql", "drop_trigger", (*ast.DropTriggerStmt)(nil))
 	ps.RegisterStatement("sql", "execute", (*ast.ExecuteStmt)(nil))
 	ps.RegisterStatement("sql", "explain", (*ast.ExplainStmt)(nil))
 	ps.RegisterStatement("sql", "grant", (*ast.GrantStmt)(nil))
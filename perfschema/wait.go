@@ -0,0 +1,150 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfschema
+
+import (
+	"sync/atomic"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Wait event names of the kv layer, they are registered with prefix "wait/".
+const (
+	// WaitKVTimestamp is the wait for the start timestamp of a transaction.
+	WaitKVTimestamp = "io/kv/tso"
+	// WaitKVCommit is the wait for a transaction to be committed by the storage.
+	WaitKVCommit = "io/kv/commit"
+)
+
+// waitInfo defines wait instrument information.
+type waitInfo struct {
+	// key means registered wait key
+	key uint64
+	// name is the name of the wait instrument to register
+	name string
+	// operation is the type of operation performed
+	operation string
+}
+
+// WaitState provides temporary storage to a wait event runtime statistics.
+type WaitState struct {
+	// connID means connection identifier
+	connID uint64
+	// eventID means event identifier
+	eventID uint64
+	// info means wait information
+	info *waitInfo
+	// timerName means timer name
+	timerName enumTimerName
+	// timerStart means the timer's start time
+	timerStart int64
+	// timerEnd means the timer's end time
+	timerEnd int64
+}
+
+func (ps *perfSchema) registerWait(name, operation string) {
+	instrumentName := waitInstrumentPrefix + name
+	key, err := ps.addInstrument(instrumentName)
+	if err != nil {
+		// just ignore, do nothing else.
+		log.Errorf("Unable to register instrument %s", instrumentName)
+		return
+	}
+	ps.waitInfos[name] = &waitInfo{
+		key:       key,
+		name:      instrumentName,
+		operation: operation,
+	}
+}
+
+func (ps *perfSchema) StartWait(connID uint64, name string) *WaitState {
+	if !enablePerfSchema {
+		return nil
+	}
+	info, ok := ps.waitInfos[name]
+	if !ok {
+		// just ignore, do nothing else.
+		log.Errorf("No instrument registered for wait %s", name)
+		return nil
+	}
+	timerName, err := ps.getTimerName(flagWait)
+	if err != nil {
+		// just ignore, do nothing else.
+		log.Error("Unable to check setup_timers table")
+		return nil
+	}
+	if timerName == timerNameNone {
+		return nil
+	}
+	return &WaitState{
+		connID:     connID,
+		eventID:    atomic.AddUint64(&ps.eventID, 1),
+		info:       info,
+		timerName:  timerName,
+		timerStart: timerName.now(),
+	}
+}
+
+func (ps *perfSchema) EndWait(state *WaitState) {
+	if !enablePerfSchema {
+		return
+	}
+	if state == nil {
+		return
+	}
+	state.timerEnd = state.timerName.now()
+
+	record := waitState2Record(state)
+	err := ps.updateEventsCurrent(TableWaitsCurrent, ps.waitHandles, state.connID, record)
+	if err != nil {
+		log.Error("Unable to update events_waits_current table")
+	}
+	err = ps.appendEventsHistory(TableWaitsHistory, record)
+	if err != nil {
+		log.Errorf("Unable to append to events_waits_history table %v", errors.ErrorStack(err))
+	}
+}
+
+func waitState2Record(state *WaitState) []types.Datum {
+	timerWait := state.timerEnd - state.timerStart
+	return types.MakeDatums(
+		state.connID,                        // THREAD_ID
+		state.eventID,                       // EVENT_ID
+		state.eventID,                       // END_EVENT_ID
+		state.info.name,                     // EVENT_NAME
+		nil,                                 // SOURCE
+		uint64(state.timerStart),            // TIMER_START
+		uint64(state.timerEnd),              // TIMER_END
+		uint64(timerWait),                   // TIMER_WAIT
+		nil,                                 // SPINS
+		nil,                                 // OBJECT_SCHEMA
+		nil,                                 // OBJECT_NAME
+		nil,                                 // INDEX_NAME
+		nil,                                 // OBJECT_TYPE
+		uint64(0),                           // OBJECT_INSTANCE_BEGIN
+		nil,                                 // NESTING_EVENT_ID
+		types.Enum{Name: "STAGE", Value: 3}, // NESTING_EVENT_TYPE
+		state.info.operation,                // OPERATION
+		nil,                                 // NUMBER_OF_BYTES
+		nil,                                 // FLAGS
+	)
+}
+
+func (ps *perfSchema) registerWaits() {
+	ps.waitInfos = make(map[string]*waitInfo)
+	ps.registerWait(WaitKVTimestamp, "get_ts")
+	ps.registerWait(WaitKVCommit, "commit")
+}
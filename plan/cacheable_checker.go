@@ -0,0 +1,78 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"github.com/pingcap/tidb/ast"
+)
+
+// Cacheable checks whether the plan of the statement can be cached and reused by the executions with the
+// same parameters. The plan can't be cached if the statement has the parts evaluated when it's built,
+// like the uncorrelated subqueries, the variables and the functions folded into constants whose results
+// change between the executions, like now() and rand().
+func Cacheable(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.SelectStmt, *ast.InsertStmt, *ast.UpdateStmt, *ast.DeleteStmt, *ast.UnionStmt:
+	default:
+		return false
+	}
+	checker := cacheableChecker{cacheable: true}
+	node.Accept(&checker)
+	return checker.cacheable
+}
+
+// unCacheableFunctions are the functions whose results change between the executions of a statement, but
+// are folded into constants when the plan is built.
+var unCacheableFunctions = map[string]struct{}{
+	ast.Now:              {},
+	ast.CurrentTimestamp: {},
+	ast.LocalTime:        {},
+	ast.LocalTimestamp:   {},
+	ast.Sysdate:          {},
+	ast.Curdate:          {},
+	ast.CurrentDate:      {},
+	ast.Curtime:          {},
+	ast.CurrentTime:      {},
+	ast.UTCDate:          {},
+	ast.UTCTime:          {},
+	ast.UTCTimestamp:     {},
+	ast.UnixTimestamp:    {},
+	ast.Rand:             {},
+	ast.UUID:             {},
+	ast.UUIDShort:        {},
+}
+
+type cacheableChecker struct {
+	cacheable bool
+}
+
+// Enter implements Visitor interface.
+func (checker *cacheableChecker) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
+	switch x := in.(type) {
+	case *ast.SubqueryExpr, *ast.VariableExpr:
+		checker.cacheable = false
+		return in, true
+	case *ast.FuncCallExpr:
+		if _, found := unCacheableFunctions[x.FnName.L]; found {
+			checker.cacheable = false
+			return in, true
+		}
+	}
+	return in, false
+}
+
+// Leave implements Visitor interface.
+func (checker *cacheableChecker) Leave(in ast.Node) (out ast.Node, ok bool) {
+	return in, checker.cacheable
+}
@@ -0,0 +1,57 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testCacheableSuite{})
+
+type testCacheableSuite struct {
+}
+
+func (s *testCacheableSuite) TestCacheable(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql       string
+		cacheable bool
+	}{
+		{"select * from t where a > 1", true},
+		{"select * from t where a > ? and b = abs(?)", true},
+		{"select * from t where a > ? and b = now()", false},
+		{"select * from t where b > current_timestamp", false},
+		{"select curdate(), unix_timestamp()", false},
+		{"insert into t values (?, utc_timestamp())", false},
+		{"update t set a = rand() where b = ?", false},
+		{"insert into t values (?, 1)", true},
+		{"update t set a = ? where b = 1", true},
+		{"delete from t where a = ?", true},
+		{"select * from t where a > (select max(a) from t)", false},
+		{"select * from t where exists (select 1 from t1)", false},
+		{"select * from t where a in (select a from t1)", false},
+		{"select * from t where a = @a", false},
+		{"select @@autocommit", false},
+		{"show tables", false},
+	}
+	p := parser.New()
+	for _, tt := range tests {
+		stmt, err := p.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, Commentf("sql: %s", tt.sql))
+		c.Assert(plan.Cacheable(stmt), Equals, tt.cacheable, Commentf("sql: %s", tt.sql))
+	}
+}
@@ -118,6 +118,41 @@ func (p *LogicalAggregation) PruneColumns(parentUsedCols []*expression.Column) {
 	child.PruneColumns(selfUsedCols)
 }
 
+// PruneColumns implements LogicalPlan interface.
+func (p *LogicalWindow) PruneColumns(parentUsedCols []*expression.Column) {
+	child := p.children[0].(LogicalPlan)
+	childLen := p.schema.Len() - len(p.WindowFuncs)
+	used := getUsedList(parentUsedCols, p.schema)
+	windowCols := p.schema.Columns[childLen:]
+	for i := len(p.WindowFuncs) - 1; i >= 0; i-- {
+		if !used[childLen+i] {
+			windowCols = append(windowCols[:i], windowCols[i+1:]...)
+			p.WindowFuncs = append(p.WindowFuncs[:i], p.WindowFuncs[i+1:]...)
+		}
+	}
+	var selfUsedCols []*expression.Column
+	for _, col := range parentUsedCols {
+		if child.Schema().Contains(col) {
+			selfUsedCols = append(selfUsedCols, col)
+		}
+	}
+	for _, fun := range p.WindowFuncs {
+		for _, arg := range fun.Args {
+			selfUsedCols = append(selfUsedCols, expression.ExtractColumns(arg)...)
+		}
+	}
+	for _, item := range p.PartitionBy {
+		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(item.Expr)...)
+	}
+	for _, item := range p.OrderBy {
+		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(item.Expr)...)
+	}
+	child.PruneColumns(selfUsedCols)
+	schema := child.Schema().Clone()
+	schema.Append(windowCols...)
+	p.SetSchema(schema)
+}
+
 // PruneColumns implements LogicalPlan interface.
 func (p *Sort) PruneColumns(parentUsedCols []*expression.Column) {
 	child := p.children[0].(LogicalPlan)
@@ -196,6 +231,9 @@ func (p *LogicalJoin) extractUsedCols(parentUsedCols []*expression.Column) (left
 	for _, otherCond := range p.OtherConditions {
 		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(otherCond)...)
 	}
+	for _, naeqCond := range p.NAEQConditions {
+		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(naeqCond)...)
+	}
 	lChild := p.children[0].(LogicalPlan)
 	rChild := p.children[1].(LogicalPlan)
 	for _, col := range parentUsedCols {
@@ -16,8 +16,10 @@ package plan_test
 import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
@@ -187,7 +189,7 @@ func (s *testPlanSuite) TestDAGPlanBuilderJoin(c *C) {
 		},
 		{
 			sql:  "select * from t t1 join t t2 on t1.a = t2.a order by t1.a",
-			best: "LeftHashJoin{TableReader(Table(t))->TableReader(Table(t))}(t1.a,t2.a)->Sort",
+			best: "IndexJoin{TableReader(Table(t))->TableReader(Table(t))}(t1.a,t2.a)",
 		},
 		{
 			sql:  "select * from t t1 left outer join t t2 on t1.a = t2.a right outer join t t3 on t1.a = t3.a",
@@ -283,6 +285,29 @@ func (s *testPlanSuite) TestDAGPlanBuilderJoin(c *C) {
 			sql:  "select /*+ TIDB_INLJ(t1) */ * from t t1 right outer join t t2 on t1.a = t2.b",
 			best: "RightHashJoin{TableReader(Table(t))->TableReader(Table(t))}(t1.a,t2.b)",
 		},
+		// Test Index Join chosen by the cost.
+		{
+			sql:  "select /*+ SELECTIVITY(t1.b = 1, 0.001) */ * from t t1, t t2 where t1.a = t2.a and t1.b = 1",
+			best: "IndexJoin{TableReader(Table(t)->Sel([eq(t1.b, 1)]))->TableReader(Table(t))}(t1.a,t2.a)",
+		},
+		{
+			sql:  "select /*+ SELECTIVITY(t2.d = 1, 0.001) */ * from t t1, t t2 where t1.f = t2.b and t2.d = 1",
+			best: "IndexJoin{TableReader(Table(t)->Sel([eq(t2.d, 1)]))->IndexLookUp(Index(t.f)[[<nil>,+inf]], Table(t))}(t2.b,t1.f)->Projection",
+		},
+		{
+			sql:  "select /*+ SELECTIVITY(t2.b = 1, 0.001) */ * from t t1 right outer join t t2 on t1.a = t2.a where t2.b = 1",
+			best: "IndexJoin{TableReader(Table(t)->Sel([eq(t2.b, 1)]))->TableReader(Table(t))}(t2.a,t1.a)",
+		},
+		// Test Index Join not chosen by the cost, the inner table has many rows of a key.
+		{
+			sql:  "select /*+ SELECTIVITY(t1.b = 1, 0.001) */ * from t t1 left outer join t t2 on t1.a = t2.c and t2.b = 1 where t1.b = 1",
+			best: "LeftHashJoin{TableReader(Table(t)->Sel([eq(t1.b, 1)]))->TableReader(Table(t)->Sel([eq(t2.b, 1)]))}(t1.a,t2.c)",
+		},
+		// Test Index Join not chosen, the types of the join keys are different.
+		{
+			sql:  "select /*+ SELECTIVITY(t1.b = 1, 0.001) */ * from t t1, t t2 where t1.a = t2.c_str and t1.b = 1",
+			best: "RightHashJoin{TableReader(Table(t)->Sel([eq(t1.b, 1)]))->TableReader(Table(t))}(t1.a,t2.c_str)",
+		},
 	}
 	for _, tt := range tests {
 		comment := Commentf("for %s", tt.sql)
@@ -338,6 +363,19 @@ func (s *testPlanSuite) TestDAGPlanBuilderBasePhysicalPlan(c *C) {
 			sql:  "delete from t",
 			best: "TableReader(Table(t))->*plan.Delete",
 		},
+		// Test point update and delete.
+		{
+			sql:  "update t set b = 5 where a = 1",
+			best: "PointGet(t)[1]->*plan.Update",
+		},
+		{
+			sql:  "delete from t where a in (1, 2, 1)",
+			best: "PointGet(t)[1 2]->*plan.Delete",
+		},
+		{
+			sql:  "delete from t where a = 1.5",
+			best: "TableReader(Table(t))->*plan.Delete",
+		},
 		// Test complex insert.
 		{
 			sql:  "insert into t select * from t where b < 1 order by d limit 1",
@@ -376,6 +414,168 @@ func (s *testPlanSuite) TestDAGPlanBuilderBasePhysicalPlan(c *C) {
 	}
 }
 
+func (s *testPlanSuite) TestDAGPlanBuilderExprPushdownBlacklist(c *C) {
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+
+	defer func() {
+		expression.SetExprPushdownBlacklist(nil)
+		testleak.AfterTest(c)()
+	}()
+	expression.SetExprPushdownBlacklist([]string{"LT", " count "})
+	tests := []struct {
+		sql  string
+		best string
+	}{
+		{
+			sql:  "select * from t where b < 1 and c > 1",
+			best: "IndexLookUp(Index(t.c_d_e)[(1 +inf,+inf +inf]], Table(t))->Sel([lt(test.t.b, 1)])",
+		},
+		{
+			sql:  "select count(*), sum(b) from t",
+			best: "TableReader(Table(t))->HashAgg",
+		},
+	}
+	for _, tt := range tests {
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil)
+		is, err := plan.MockResolve(stmt)
+		c.Assert(err, IsNil)
+		p, err := plan.Optimize(se, stmt, is)
+		c.Assert(err, IsNil)
+		c.Assert(plan.ToString(p), Equals, tt.best, Commentf("for %s", tt.sql))
+	}
+}
+
+func (s *testPlanSuite) TestDAGPlanBuilderSelectivityHint(c *C) {
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	tests := []struct {
+		sql      string
+		best     string
+		warnings int
+	}{
+		// The join key isn't a unique key of the inner table, so the index join is too expensive.
+		{
+			sql:  "select * from t t1 join t t2 on t1.c_str = t2.c_str where t1.b = 1 and t2.b = 2 and t2.d = 1",
+			best: "LeftHashJoin{TableReader(Table(t)->Sel([eq(t1.b, 1)]))->TableReader(Table(t)->Sel([eq(t2.b, 2) eq(t2.d, 1)]))}(t1.c_str,t2.c_str)",
+		},
+		// The pushed down condition is estimated by the hint.
+		{
+			sql:  "select /*+ SELECTIVITY(t1.b = 1, 0.001) */ * from t t1 join t t2 on t1.c_str = t2.c_str where t1.b = 1 and t2.b = 2 and t2.d = 1",
+			best: "RightHashJoin{TableReader(Table(t)->Sel([eq(t1.b, 1)]))->TableReader(Table(t)->Sel([eq(t2.b, 2) eq(t2.d, 1)]))}(t1.c_str,t2.c_str)",
+		},
+		// The condition not pushed down is estimated by the hint.
+		{
+			sql:  "select /*+ SELECTIVITY(t1.b like '%x%', 0.001) */ * from t t1 join t t2 on t1.c_str = t2.c_str where t1.b like '%x%' and t2.b = 1",
+			best: "RightHashJoin{TableReader(Table(t))->Sel([like(cast(t1.b), %x%, 92)])->TableReader(Table(t)->Sel([eq(t2.b, 1)]))}(t1.c_str,t2.c_str)",
+		},
+		// The hint doesn't match any condition.
+		{
+			sql:  "select /*+ SELECTIVITY(t1.b = 2, 0.001) */ * from t t1 join t t2 on t1.c_str = t2.c_str where t1.b = 1 and t2.b = 2 and t2.d = 1",
+			best: "LeftHashJoin{TableReader(Table(t)->Sel([eq(t1.b, 1)]))->TableReader(Table(t)->Sel([eq(t2.b, 2) eq(t2.d, 1)]))}(t1.c_str,t2.c_str)",
+		},
+		{
+			sql:      "select /*+ SELECTIVITY(t1.b = 1, 2) */ * from t t1 join t t2 on t1.c_str = t2.c_str where t1.b = 1 and t2.b = 2 and t2.d = 1",
+			best:     "LeftHashJoin{TableReader(Table(t)->Sel([eq(t1.b, 1)]))->TableReader(Table(t)->Sel([eq(t2.b, 2) eq(t2.d, 1)]))}(t1.c_str,t2.c_str)",
+			warnings: 1,
+		},
+	}
+	for _, tt := range tests {
+		comment := Commentf("for %s", tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+
+		se.GetSessionVars().StmtCtx = new(variable.StatementContext)
+		is, err := plan.MockResolve(stmt)
+		c.Assert(err, IsNil, comment)
+		p, err := plan.Optimize(se, stmt, is)
+		c.Assert(err, IsNil, comment)
+		c.Assert(plan.ToString(p), Equals, tt.best, comment)
+		c.Assert(se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(tt.warnings), comment)
+	}
+}
+
+func (s *testPlanSuite) TestDAGPlanBuilderScanDirection(c *C) {
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	tests := []struct {
+		sql       string
+		direction variable.ScanDirection
+		best      string
+	}{
+		{
+			sql:  "select * from t order by a desc limit 1",
+			best: "TableReader(Table(t)->TopN([test.t.a true],0,1))->TopN([test.t.a true],0,1)",
+		},
+		{
+			sql:       "select * from t order by a desc limit 1",
+			direction: variable.ScanDirectionDesc,
+			best:      "TableReader(Table(t)->Limit)->Limit",
+		},
+		{
+			sql:       "select c from t order by c desc limit 1",
+			direction: variable.ScanDirectionDesc,
+			best:      "IndexReader(Index(t.c_d_e)[[<nil>,+inf]]->Limit)->Limit",
+		},
+		{
+			sql:       "select * from t where c > 1 order by c desc",
+			direction: variable.ScanDirectionDesc,
+			best:      "IndexLookUp(Index(t.c_d_e)[(1 +inf,+inf +inf]], Table(t))",
+		},
+		{
+			sql:  "select /*+ INDEX_DESC(t1) */ * from t t1 order by c desc limit 1",
+			best: "IndexLookUp(Index(t.c_d_e)[[<nil>,+inf]]->Limit, Table(t))->Limit",
+		},
+		// The hint takes precedence over the variable.
+		{
+			sql:       "select /*+ INDEX_ASC(t1) */ * from t t1 order by a desc limit 1",
+			direction: variable.ScanDirectionDesc,
+			best:      "TableReader(Table(t)->TopN([t1.a true],0,1))->TopN([t1.a true],0,1)",
+		},
+		// The hint only affects the named tables.
+		{
+			sql:       "select /*+ INDEX_ASC(t2) */ * from t t1 order by a desc limit 1",
+			direction: variable.ScanDirectionDesc,
+			best:      "TableReader(Table(t)->Limit)->Limit",
+		},
+		{
+			sql:       "select * from t where c > 1 order by c desc",
+			direction: variable.ScanDirectionAsc,
+			best:      "IndexLookUp(Index(t.c_d_e)[(1 +inf,+inf +inf]], Table(t))->Sort",
+		},
+	}
+	for _, tt := range tests {
+		comment := Commentf("for %s", tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+
+		se.GetSessionVars().IndexScanDirection = tt.direction
+		is, err := plan.MockResolve(stmt)
+		c.Assert(err, IsNil, comment)
+		p, err := plan.Optimize(se, stmt, is)
+		c.Assert(err, IsNil, comment)
+		c.Assert(plan.ToString(p), Equals, tt.best, comment)
+	}
+}
+
 func (s *testPlanSuite) TestDAGPlanBuilderUnion(c *C) {
 	store, err := newStoreWithBootstrap()
 	c.Assert(err, IsNil)
@@ -533,6 +733,32 @@ func (s *testPlanSuite) TestDAGPlanBuilderAgg(c *C) {
 			sql:  "select sum(to_base64(e)) from t where c = 1",
 			best: "IndexReader(Index(t.c_d_e)[[1,1]])->HashAgg",
 		},
+		// Test stream agg, the child is sorted by the group by columns.
+		{
+			sql:  "select sum(distinct e) from t group by c, d",
+			best: "IndexReader(Index(t.c_d_e)[[<nil>,+inf]])->StreamAgg",
+		},
+		{
+			sql:  "select count(distinct b) from t where c = 1 group by d",
+			best: "IndexLookUp(Index(t.c_d_e)[[1,1]], Table(t))->StreamAgg",
+		},
+		{
+			sql:  "select sum(distinct e) from t group by c, d order by c",
+			best: "IndexReader(Index(t.c_d_e)[[<nil>,+inf]])->StreamAgg->Projection",
+		},
+		{
+			sql:  "select sum(distinct e) from t group by c, d order by c desc",
+			best: "IndexReader(Index(t.c_d_e)[[<nil>,+inf]])->StreamAgg->Sort->Projection",
+		},
+		// Test stream agg isn't chosen if the child needs to be sorted or the agg can push down.
+		{
+			sql:  "select count(distinct b) from t group by d",
+			best: "TableReader(Table(t))->HashAgg",
+		},
+		{
+			sql:  "select count(*) from t group by c, d",
+			best: "TableReader(Table(t)->HashAgg)->HashAgg",
+		},
 	}
 	for _, tt := range tests {
 		comment := Commentf("for %s", tt.sql)
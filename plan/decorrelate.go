@@ -54,15 +54,33 @@ func (a *LogicalApply) extractCorColumnsBySchema() {
 
 // canPullUpAgg checks if an apply can pull an aggregation up.
 func (a *LogicalApply) canPullUpAgg() bool {
+	if a.keepOuterRows {
+		return false
+	}
 	if a.JoinType != InnerJoin && a.JoinType != LeftOuterJoin {
 		return false
 	}
-	if len(a.EqualConditions)+len(a.LeftConditions)+len(a.RightConditions)+len(a.OtherConditions) > 0 {
+	if len(a.EqualConditions)+len(a.LeftConditions)+len(a.RightConditions)+len(a.OtherConditions)+len(a.NAEQConditions) > 0 {
 		return false
 	}
 	return len(a.children[0].Schema().Keys) > 0
 }
 
+// canPullUpProj checks if an apply can pull a projection up. The null-aware equal conditions can't refer to the
+// correlated expressions of the projection, e.g. `t.a not in (select t.b from s)`, because their right arguments
+// must be computed by the inner plan.
+func (a *LogicalApply) canPullUpProj(proj *Projection) bool {
+	for _, cond := range a.NAEQConditions {
+		for _, col := range expression.ExtractColumns(cond.GetArgs()[1]) {
+			idx := proj.schema.ColumnIndex(col)
+			if idx != -1 && len(extractCorColumns(proj.Exprs[idx])) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // canPullUp checks if an aggregation can be pulled up. An aggregate function like count(*) cannot be pulled up.
 func (a *LogicalAggregation) canPullUp() bool {
 	if len(a.GroupByItems) > 0 {
@@ -117,7 +135,7 @@ func (s *decorrelateSolver) optimize(p LogicalPlan, _ context.Context, _ *idAllo
 				apply.SetChildren(outerPlan, innerPlan)
 				return s.optimize(p, nil, nil)
 			}
-		} else if proj, ok := innerPlan.(*Projection); ok {
+		} else if proj, ok := innerPlan.(*Projection); ok && apply.canPullUpProj(proj) {
 			for i, expr := range proj.Exprs {
 				proj.Exprs[i] = expr.Decorrelate(outerPlan.Schema())
 			}
@@ -183,6 +201,7 @@ func (p *Selection) checkScanController() int {
 	)
 	ds := p.children[0].(*DataSource)
 	indices, includeTableScan := availableIndices(ds.indexHints, ds.tableInfo)
+	indices, _ = splitMultiValuedIndices(indices)
 	for _, expr := range p.Conditions {
 		if !expr.IsCorrelated() {
 			continue
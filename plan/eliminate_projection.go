@@ -34,6 +34,10 @@ func EliminateProjection(p PhysicalPlan) PhysicalPlan {
 		children = append(children, EliminateProjection(child.(PhysicalPlan)))
 	}
 	p.SetChildren(children...)
+	if ap, ok := p.(*PhysicalApply); ok {
+		// The executor of apply is built from its join, whose children must be the same as the apply's.
+		ap.PhysicalJoin.SetChildren(children...)
+	}
 	return p
 }
 
@@ -134,6 +134,94 @@ func (er *expressionRewriter) constructBinaryOpFunction(l expression.Expression,
 	return expression.ComposeCNFCondition(er.ctx, funcs...), nil
 }
 
+// subqueryKind is how an uncorrelated subquery is evaluated.
+type subqueryKind byte
+
+const (
+	scalarSubquery subqueryKind = iota
+	existsSubquery
+	inSubquery
+)
+
+// subqueryKey identifies the result of an uncorrelated subquery in a statement, the same subquery text
+// has the same result if it's evaluated in the same way.
+type subqueryKey struct {
+	kind subqueryKind
+	text string
+}
+
+// nonDeterministicFuncs are the functions whose results may differ between the calls in a statement.
+var nonDeterministicFuncs = map[string]struct{}{
+	ast.Rand:            {},
+	ast.UUID:            {},
+	ast.UUIDShort:       {},
+	ast.Sleep:           {},
+	ast.GetLock:         {},
+	ast.ReleaseLock:     {},
+	ast.IsFreeLock:      {},
+	ast.IsUsedLock:      {},
+	ast.ReleaseAllLocks: {},
+}
+
+// subqueryCacheChecker checks whether the result of a subquery can be shared by the same subqueries
+// in the statement.
+type subqueryCacheChecker struct {
+	cacheable bool
+}
+
+// Enter implements Visitor interface.
+func (c *subqueryCacheChecker) Enter(in ast.Node) (ast.Node, bool) {
+	switch v := in.(type) {
+	case *ast.FuncCallExpr:
+		if _, ok := nonDeterministicFuncs[v.FnName.L]; ok {
+			c.cacheable = false
+		}
+	case *ast.VariableExpr:
+		if v.Value != nil {
+			// The variable assignment has side effects.
+			c.cacheable = false
+		}
+	}
+	return in, !c.cacheable
+}
+
+// Leave implements Visitor interface.
+func (c *subqueryCacheChecker) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
+
+// evalSubquery evaluates the uncorrelated subquery. The result is kept in the plan builder, so the same
+// subquery appearing multiple times in the statement is only executed once.
+func (er *expressionRewriter) evalSubquery(subq *ast.SubqueryExpr, kind subqueryKind, np LogicalPlan) ([][]types.Datum, error) {
+	key := subqueryKey{kind: kind, text: subq.Query.Text()}
+	cacheable := key.text != ""
+	if cacheable {
+		checker := &subqueryCacheChecker{cacheable: true}
+		subq.Query.Accept(checker)
+		cacheable = checker.cacheable
+	}
+	if cacheable {
+		if rows, ok := er.b.subqueryResults[key]; ok {
+			return rows, nil
+		}
+	}
+	physicalPlan, err := doOptimize(er.b.optFlag, np, er.b.ctx, er.b.allocator)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	rows, err := EvalSubquery(physicalPlan, er.b.is, er.b.ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if cacheable {
+		if er.b.subqueryResults == nil {
+			er.b.subqueryResults = make(map[subqueryKey][][]types.Datum)
+		}
+		er.b.subqueryResults[key] = rows
+	}
+	return rows, nil
+}
+
 func (er *expressionRewriter) buildSubquery(subq *ast.SubqueryExpr) LogicalPlan {
 	outerSchema := er.schema.Clone()
 	er.b.outerSchemas = append(er.b.outerSchemas, outerSchema)
@@ -155,11 +243,23 @@ func (er *expressionRewriter) Enter(inNode ast.Node) (ast.Node, bool) {
 			index, ok = er.aggrMap[v]
 		}
 		if !ok {
+			if agg, ok := er.b.outerAggs[v]; ok {
+				er.ctxStack = append(er.ctxStack, er.outerAggColumn(agg))
+				return inNode, true
+			}
 			er.err = errors.New("Can't appear aggrFunctions")
 			return inNode, true
 		}
 		er.ctxStack = append(er.ctxStack, er.schema.Columns[index])
 		return inNode, true
+	case *ast.WindowFuncExpr:
+		index, ok := er.b.windowMapper[v]
+		if !ok {
+			er.err = ErrInvalidWindowFuncUse.GenByArgs(v.F)
+			return inNode, true
+		}
+		er.ctxStack = append(er.ctxStack, er.schema.Columns[index])
+		return inNode, true
 	case *ast.ColumnNameExpr:
 		if index, ok := er.b.colMapper[v]; ok {
 			er.ctxStack = append(er.ctxStack, er.schema.Columns[index])
@@ -196,12 +296,29 @@ func (er *expressionRewriter) Enter(inNode ast.Node) (ast.Node, bool) {
 	case *ast.ValuesExpr:
 		er.ctxStack = append(er.ctxStack, expression.NewValuesFunc(v.Column.Refer.Column.Offset, &v.Type, er.ctx))
 		return inNode, true
+	case *ast.DefaultExpr:
+		er.evalDefaultExpr(v)
+		return inNode, true
 	default:
 		er.asScalar = true
 	}
 	return inNode, false
 }
 
+// evalDefaultExpr rewrites DEFAULT(col) to the default value of the column of the inserted table.
+func (er *expressionRewriter) evalDefaultExpr(v *ast.DefaultExpr) {
+	if v.Name == nil || er.b.insertCols == nil {
+		er.err = ErrUnsupportedType.Gen("DEFAULT is only supported in the values of INSERT")
+		return
+	}
+	expr, err := er.b.findDefaultValue(er.b.insertCols, v.Name)
+	if err != nil {
+		er.err = errors.Trace(err)
+		return
+	}
+	er.ctxStack = append(er.ctxStack, expr)
+}
+
 func (er *expressionRewriter) handleCompareSubquery(v *ast.CompareSubqueryExpr) (ast.Node, bool) {
 	v.L.Accept(er)
 	if er.err != nil {
@@ -255,11 +372,11 @@ func (er *expressionRewriter) handleCompareSubquery(v *ast.CompareSubqueryExpr)
 			if v.All {
 				er.handleEQAll(lexpr, rexpr, np)
 			} else {
-				er.p = er.b.buildSemiApply(er.p, np, []expression.Expression{condition}, er.asScalar, false)
+				er.p = er.b.buildSemiApply(er.p, np, expression.SplitCNFItems(condition), er.asScalar, false)
 			}
 		} else if v.Op == opcode.NE {
 			if v.All {
-				er.p = er.b.buildSemiApply(er.p, np, []expression.Expression{condition}, er.asScalar, true)
+				er.p = er.b.buildSemiApply(er.p, np, expression.SplitCNFItems(condition), er.asScalar, true)
 			} else {
 				er.handleNEAny(lexpr, rexpr, np)
 			}
@@ -440,8 +557,7 @@ func (er *expressionRewriter) handleExistSubquery(v *ast.ExistsSubqueryExpr) (as
 		}
 		er.ctxStack = append(er.ctxStack, er.p.Schema().Columns[er.p.Schema().Len()-1])
 	} else {
-		physicalPlan, err := doOptimize(er.b.optFlag, np, er.b.ctx, er.b.allocator)
-		rows, err := EvalSubquery(physicalPlan, er.b.is, er.b.ctx)
+		rows, err := er.evalSubquery(subq, existsSubquery, np)
 		if err != nil {
 			er.err = errors.Trace(err)
 			return v, true
@@ -479,12 +595,7 @@ func (er *expressionRewriter) handleInSubquery(v *ast.PatternInExpr) (ast.Node,
 	// TODO: Now we cannot add it to CBO framework. Instead, user can set a session variable to open this optimization.
 	// We will improve our CBO framework in future.
 	if lLen == 1 && er.ctx.GetSessionVars().AllowInSubqueryUnFolding && len(np.extractCorrelatedCols()) == 0 {
-		physicalPlan, err := doOptimize(er.b.optFlag, np, er.b.ctx, er.b.allocator)
-		if err != nil {
-			er.err = errors.Trace(err)
-			return v, true
-		}
-		rows, err := EvalSubquery(physicalPlan, er.b.is, er.b.ctx)
+		rows, err := er.evalSubquery(subq, inSubquery, np)
 		if err != nil {
 			er.err = errors.Trace(err)
 			return v, true
@@ -562,12 +673,7 @@ func (er *expressionRewriter) handleScalarSubquery(v *ast.SubqueryExpr) (ast.Nod
 		}
 		return v, true
 	}
-	physicalPlan, err := doOptimize(er.b.optFlag, np, er.b.ctx, er.b.allocator)
-	if err != nil {
-		er.err = errors.Trace(err)
-		return v, true
-	}
-	rows, err := EvalSubquery(physicalPlan, er.b.is, er.b.ctx)
+	rows, err := er.evalSubquery(v, scalarSubquery, np)
 	if err != nil {
 		er.err = errors.Trace(err)
 		return v, true
@@ -602,7 +708,8 @@ func (er *expressionRewriter) Leave(inNode ast.Node) (retNode ast.Node, ok bool)
 
 	switch v := inNode.(type) {
 	case *ast.AggregateFuncExpr, *ast.ColumnNameExpr, *ast.ParenthesesExpr, *ast.WhenClause,
-		*ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr, *ast.ValuesExpr:
+		*ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr, *ast.ValuesExpr, *ast.WindowFuncExpr,
+		*ast.DefaultExpr:
 	case *ast.ValueExpr:
 		value := &expression.Constant{Value: v.Datum, RetType: &v.Type}
 		er.ctxStack = append(er.ctxStack, value)
@@ -674,11 +781,10 @@ func (er *expressionRewriter) rewriteVariable(v *ast.VariableExpr) {
 				er.ctxStack[stkLen-1])
 			return
 		}
-		if _, ok := sessionVars.Users[name]; ok {
+		if _, tp, ok := expression.GetUserVar(sessionVars, name); ok {
 			f, err := expression.NewFunction(er.ctx,
 				ast.GetVar,
-				// TODO: Here is wrong, the sessionVars should store a name -> Datum map. Will fix it later.
-				types.NewFieldType(mysql.TypeString),
+				tp,
 				datumToConstant(types.NewStringDatum(name), mysql.TypeString))
 			if err != nil {
 				er.err = errors.Trace(err)
@@ -977,10 +1083,24 @@ func (er *expressionRewriter) funcCallToExpression(v *ast.FuncCallExpr) {
 	er.ctxStack = append(er.ctxStack, function)
 }
 
+// outerAggColumn returns the correlated column of the aggregate function which is aggregated in the outer query.
+func (er *expressionRewriter) outerAggColumn(agg *outerAggFunc) *expression.CorrelatedColumn {
+	outerSchema := er.b.outerSchemas[agg.level]
+	// The subquery of field list refers to the output of aggregation, otherwise it refers to the
+	// auxiliary select field of projection.
+	column := agg.col
+	if idx := outerSchema.ColumnIndex(column); idx >= 0 {
+		column = outerSchema.Columns[idx]
+	} else {
+		column = outerSchema.Columns[agg.offset]
+	}
+	return &expression.CorrelatedColumn{Column: *column}
+}
+
 func (er *expressionRewriter) toColumn(v *ast.ColumnName) {
 	column, err := er.schema.FindColumn(v)
 	if err != nil {
-		er.err = ErrAmbiguous.GenByArgs(v.Name)
+		er.err = ErrAmbiguous.GenByArgs(v.Name, clauseMsg[er.b.curClause])
 		return
 	}
 	if column != nil {
@@ -999,5 +1119,5 @@ func (er *expressionRewriter) toColumn(v *ast.ColumnName) {
 			return
 		}
 	}
-	er.err = errors.Errorf("Unknown column %s %s %s.", v.Schema.L, v.Table.L, v.Name.L)
+	er.err = ErrUnknownColumn.GenByArgs(v.OrigColName(), clauseMsg[er.b.curClause])
 }
@@ -331,6 +331,12 @@ func (s *testExpressionSuite) TestDateArith(c *C) {
 		{"2011-11-11 10:10:10", "11 10", "DAY_HOUR", "2011-11-22 20:10:10", "2011-10-31 00:10:10", false},
 		{"2011-11-11 10:10:10", "11-1", "YEAR_MONTH", "2022-12-11 10:10:10", "2000-10-11 10:10:10", false},
 		{"2011-11-11 10:10:10", "11-11", "YEAR_MONTH", "2023-10-11 10:10:10", "1999-12-11 10:10:10", false},
+		{"2011-11-11 10:10:10", "-11 10", "DAY_HOUR", "2011-10-31 00:10:10", "2011-11-22 20:10:10", false},
+		{"2011-11-11 10:10:10", 30000, "HOUR", "2015-04-14 10:10:10", "2008-06-09 10:10:10", false},
+		// tests for the last day of month
+		{"2012-01-31", 1, "MONTH", "2012-02-29", "2011-12-31", false},
+		{"2012-02-29", 1, "YEAR", "2013-02-28", "2011-02-28", false},
+		{"2012-05-31 10:10:10", "1-1", "YEAR_MONTH", "2013-06-30 10:10:10", "2011-04-30 10:10:10", false},
 		// tests for interval in day forms
 		{"2011-11-11 10:10:10", "20", "DAY", "2011-12-01 10:10:10", "2011-10-22 10:10:10", false},
 		{"2011-11-11 10:10:10", 19.88, "DAY", "2011-12-01 10:10:10", "2011-10-22 10:10:10", false},
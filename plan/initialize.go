@@ -27,6 +27,8 @@ const (
 	TypeProj = "Projection"
 	// TypeAgg is the type of Aggregation.
 	TypeAgg = "Aggregation"
+	// TypeWindow is the type of Window.
+	TypeWindow = "Window"
 	// TypeStreamAgg is the type of StreamAgg.
 	TypeStreamAgg = "StreamAgg"
 	// TypeHashAgg is the type of HashAgg.
@@ -85,6 +87,8 @@ const (
 	TypeTableReader = "TableReader"
 	// TypeIndexReader is the type of IndexReader.
 	TypeIndexReader = "IndexReader"
+	// TypePointGet is the type of PointGet.
+	TypePointGet = "PointGet"
 )
 
 func (p LogicalAggregation) init(allocator *idAllocator, ctx context.Context) *LogicalAggregation {
@@ -93,6 +97,12 @@ func (p LogicalAggregation) init(allocator *idAllocator, ctx context.Context) *L
 	return &p
 }
 
+func (p LogicalWindow) init(allocator *idAllocator, ctx context.Context) *LogicalWindow {
+	p.basePlan = newBasePlan(TypeWindow, allocator, ctx, &p)
+	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
+	return &p
+}
+
 func (p LogicalJoin) init(allocator *idAllocator, ctx context.Context) *LogicalJoin {
 	p.basePlan = newBasePlan(TypeJoin, allocator, ctx, &p)
 	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
@@ -215,6 +225,12 @@ func (p PhysicalTableScan) init(allocator *idAllocator, ctx context.Context) *Ph
 	return &p
 }
 
+func (p PointGet) init(allocator *idAllocator, ctx context.Context) *PointGet {
+	p.basePlan = newBasePlan(TypePointGet, allocator, ctx, &p)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
 func (p PhysicalIndexScan) init(allocator *idAllocator, ctx context.Context) *PhysicalIndexScan {
 	p.basePlan = newBasePlan(TypeIdxScan, allocator, ctx, &p)
 	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
@@ -259,6 +275,12 @@ func (p PhysicalAggregation) init(allocator *idAllocator, ctx context.Context) *
 	return &p
 }
 
+func (p PhysicalWindow) init(allocator *idAllocator, ctx context.Context) *PhysicalWindow {
+	p.basePlan = newBasePlan(TypeWindow, allocator, ctx, &p)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
 func (p PhysicalApply) init(allocator *idAllocator, ctx context.Context) *PhysicalApply {
 	p.basePlan = newBasePlan(TypeApply, allocator, ctx, &p)
 	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
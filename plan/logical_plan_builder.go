@@ -15,15 +15,18 @@ package plan
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -32,6 +35,12 @@ const (
 	TiDBMergeJoin = "tidb_smj"
 	// TiDBIndexNestedLoopJoin is hint enforce index nested loop join.
 	TiDBIndexNestedLoopJoin = "tidb_inlj"
+	// TiDBSelectivity is hint estimate the selectivity of a predicate.
+	TiDBSelectivity = "selectivity"
+	// TiDBIndexAsc is hint enforce the ordered scans of the tables never read backward.
+	TiDBIndexAsc = "index_asc"
+	// TiDBIndexDesc is hint enforce the ordered scans of the tables read backward for the descending order.
+	TiDBIndexDesc = "index_desc"
 )
 
 type idAllocator struct {
@@ -126,6 +135,7 @@ func (b *planBuilder) buildResultSetNode(node ast.ResultSetNode) LogicalPlan {
 		}
 		if v, ok := p.(*DataSource); ok {
 			v.TableAsName = &x.AsName
+			v.scanDirection = b.scanDirection(extractTableAlias(v))
 		}
 		if x.AsName.L != "" {
 			for _, col := range p.Schema().Columns {
@@ -197,6 +207,39 @@ func extractOnCondition(conditions []expression.Expression, left LogicalPlan, ri
 	return
 }
 
+// extractNAEQCondition extracts the equal conditions whose arguments are computed by the left and the right
+// plan respectively. They are used as the null-aware equal conditions of a semi join.
+func extractNAEQCondition(conditions []expression.Expression, left LogicalPlan, right LogicalPlan) (
+	naeqCond []*expression.ScalarFunction, restCond []expression.Expression) {
+	for _, expr := range conditions {
+		binop, ok := expr.(*expression.ScalarFunction)
+		if ok && binop.FuncName.L == ast.EQ {
+			lArg, rArg := binop.GetArgs()[0], binop.GetArgs()[1]
+			if exprFromSchema(lArg, left.Schema()) && exprFromSchema(rArg, right.Schema()) {
+				naeqCond = append(naeqCond, binop)
+				continue
+			}
+			if exprFromSchema(rArg, left.Schema()) && exprFromSchema(lArg, right.Schema()) {
+				cond, _ := expression.NewFunction(binop.GetCtx(), ast.EQ, types.NewFieldType(mysql.TypeTiny), rArg, lArg)
+				naeqCond = append(naeqCond, cond.(*expression.ScalarFunction))
+				continue
+			}
+		}
+		restCond = append(restCond, expr)
+	}
+	return
+}
+
+// exprFromSchema checks whether all the columns of expr come from the schema.
+func exprFromSchema(expr expression.Expression, schema *expression.Schema) bool {
+	for _, col := range expression.ExtractColumns(expr) {
+		if !schema.Contains(col) {
+			return false
+		}
+	}
+	return true
+}
+
 func extractTableAlias(p LogicalPlan) *model.CIStr {
 	if dataSource, ok := p.(*DataSource); ok {
 		if dataSource.TableAsName.L != "" {
@@ -217,7 +260,26 @@ func (b *planBuilder) buildJoin(join *ast.Join) LogicalPlan {
 		return b.buildResultSetNode(join.Left)
 	}
 	leftPlan := b.buildResultSetNode(join.Left)
+	ts, lateral := join.Right.(*ast.TableSource)
+	lateral = lateral && ts.Lateral
+	if lateral {
+		if b.err != nil {
+			return nil
+		}
+		if join.Tp == ast.RightJoin {
+			b.err = ErrInvalidLateralJoin.GenByArgs(ts.AsName.O)
+			return nil
+		}
+		// The lateral derived table is built like a subquery of the left plan.
+		b.outerSchemas = append(b.outerSchemas, leftPlan.Schema().Clone())
+	}
 	rightPlan := b.buildResultSetNode(join.Right)
+	if lateral {
+		b.outerSchemas = b.outerSchemas[0 : len(b.outerSchemas)-1]
+		if b.err != nil {
+			return nil
+		}
+	}
 	leftAlias := extractTableAlias(leftPlan)
 	rightAlias := extractTableAlias(rightPlan)
 
@@ -241,6 +303,7 @@ func (b *planBuilder) buildJoin(join *ast.Join) LogicalPlan {
 	}
 
 	if join.On != nil {
+		b.curClause = onClause
 		onExpr, _, err := b.rewrite(join.On.Expr, joinPlan, nil, false)
 		if err != nil {
 			b.err = err
@@ -251,7 +314,7 @@ func (b *planBuilder) buildJoin(join *ast.Join) LogicalPlan {
 		}
 		onCondition := expression.SplitCNFItems(onExpr)
 		joinPlan.attachOnConds(onCondition)
-	} else if joinPlan.JoinType == InnerJoin {
+	} else if joinPlan.JoinType == InnerJoin && !join.NaturalJoin && len(join.Using) == 0 {
 		joinPlan.cartesianJoin = true
 	}
 	if join.Tp == ast.LeftJoin {
@@ -263,7 +326,118 @@ func (b *planBuilder) buildJoin(join *ast.Join) LogicalPlan {
 	} else {
 		joinPlan.JoinType = InnerJoin
 	}
-	return joinPlan
+	var commonCols map[*expression.Column]struct{}
+	if join.NaturalJoin || len(join.Using) > 0 {
+		commonCols = b.buildNaturalOrUsingConds(joinPlan, join)
+		if b.err != nil {
+			return nil
+		}
+	}
+	var p LogicalPlan = joinPlan
+	if lateral {
+		p = b.buildLateralApply(joinPlan)
+	}
+	// The schema of update and delete must keep the columns of each table in place.
+	if commonCols != nil && !b.inUpdateStmt && !b.inDeleteStmt {
+		return b.buildNaturalOrUsingProjection(p, leftPlan.Schema().Len(), joinPlan.JoinType, commonCols)
+	}
+	return p
+}
+
+// buildLateralApply converts the join with a lateral derived table to an apply,
+// which evaluates the derived table for every row of the left plan.
+func (b *planBuilder) buildLateralApply(join *LogicalJoin) LogicalPlan {
+	b.optFlag = b.optFlag | flagBuildKeyInfo
+	b.optFlag = b.optFlag | flagDecorrelate
+	join.cartesianJoin = false
+	ap := &LogicalApply{LogicalJoin: *join}
+	ap.tp = TypeApply
+	ap.id = ap.tp + ap.allocator.allocID()
+	ap.self = ap
+	ap.keepOuterRows = b.inUpdateStmt || b.inDeleteStmt
+	ap.children[0].SetParents(ap)
+	ap.children[1].SetParents(ap)
+	return ap
+}
+
+// buildNaturalOrUsingConds builds the equal conditions on the common columns of a natural join or a join with
+// using clause, and hides the redundant copy of each common column. It returns the coalesced common columns.
+func (b *planBuilder) buildNaturalOrUsingConds(p *LogicalJoin, join *ast.Join) map[*expression.Column]struct{} {
+	leftSchema, rightSchema := p.children[0].Schema(), p.children[1].Schema()
+	var names []model.CIStr
+	if join.NaturalJoin {
+		for _, col := range leftSchema.Columns {
+			if !col.IsHidden {
+				names = append(names, col.ColName)
+			}
+		}
+	} else {
+		for _, name := range join.Using {
+			names = append(names, name.Name)
+		}
+	}
+	var conds []expression.Expression
+	commonCols := make(map[*expression.Column]struct{}, len(names))
+	for _, name := range names {
+		lCol, err := leftSchema.FindColumn(&ast.ColumnName{Name: name})
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		rCol, err := rightSchema.FindColumn(&ast.ColumnName{Name: name})
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		if lCol == nil || rCol == nil {
+			if join.NaturalJoin {
+				continue
+			}
+			b.err = ErrUnknownColumn.GenByArgs(name.O, "from clause")
+			return nil
+		}
+		cond, err := expression.NewFunction(b.ctx, ast.EQ, types.NewFieldType(mysql.TypeTiny), lCol.Clone(), rCol.Clone())
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		conds = append(conds, cond)
+		lCol, rCol = p.Schema().RetrieveColumn(lCol), p.Schema().RetrieveColumn(rCol)
+		if p.JoinType == RightOuterJoin {
+			lCol, rCol = rCol, lCol
+		}
+		rCol.IsHidden = true
+		commonCols[lCol] = struct{}{}
+	}
+	p.attachOnConds(conds)
+	return commonCols
+}
+
+// buildNaturalOrUsingProjection reorders the columns of a natural join or a join with using clause.
+// The coalesced common columns come first in the result, followed by the other columns of the first table
+// and then the columns of the second table, where the first table of a right join is the right one. e.g.
+// "select * from t1(a, b) natural right join t2(b, c)" returns columns (t2.b, t2.c, t1.a).
+func (b *planBuilder) buildNaturalOrUsingProjection(p LogicalPlan, leftLen int, tp JoinType, commonCols map[*expression.Column]struct{}) LogicalPlan {
+	firstCols, secondCols := p.Schema().Columns[:leftLen], p.Schema().Columns[leftLen:]
+	if tp == RightOuterJoin {
+		firstCols, secondCols = secondCols, firstCols
+	}
+	schema := expression.NewSchema(make([]*expression.Column, 0, p.Schema().Len())...)
+	for _, col := range firstCols {
+		if _, ok := commonCols[col]; ok {
+			schema.Append(col)
+		}
+	}
+	for _, col := range firstCols {
+		if _, ok := commonCols[col]; !ok {
+			schema.Append(col)
+		}
+	}
+	schema.Append(secondCols...)
+	proj := Projection{Exprs: expression.Column2Exprs(schema.Columns)}.init(b.allocator, b.ctx)
+	addChild(proj, p)
+	proj.SetSchema(schema)
+	return proj
 }
 
 func (b *planBuilder) buildSelection(p LogicalPlan, where ast.ExprNode, AggMapper map[*ast.AggregateFuncExpr]int) LogicalPlan {
@@ -348,6 +522,118 @@ func (b *planBuilder) buildProjection(p LogicalPlan, fields []*ast.SelectField,
 	return proj, oldLen
 }
 
+func extractWindowFuncs(fields []*ast.SelectField) []*ast.WindowFuncExpr {
+	extractor := &windowFuncExtractor{}
+	for _, f := range fields {
+		if f.Expr.GetFlag()&ast.FlagHasWindowFunc > 0 {
+			f.Expr.Accept(extractor)
+		}
+	}
+	return extractor.windowFuncs
+}
+
+// buildWindowFuncs builds the windows computing the window functions. The window functions with the same PARTITION BY
+// and ORDER BY items are computed by the same LogicalWindow, whose child is sorted by these items. The window
+// functions are mapped to the columns of the returned plan's schema by b.windowMapper.
+func (b *planBuilder) buildWindowFuncs(p LogicalPlan, windowFuncs []*ast.WindowFuncExpr, aggMapper map[*ast.AggregateFuncExpr]int) LogicalPlan {
+	var (
+		windows     []*LogicalWindow
+		windowExprs [][]*ast.WindowFuncExpr
+	)
+	for _, expr := range windowFuncs {
+		args := make([]expression.Expression, 0, len(expr.Args))
+		for _, arg := range expr.Args {
+			newArg, np, err := b.rewrite(arg, p, aggMapper, true)
+			if err != nil {
+				b.err = errors.Trace(err)
+				return nil
+			}
+			p = np
+			args = append(args, newArg)
+		}
+		var partitionBy, orderBy []*ByItems
+		partitionBy, p = b.rewriteWindowByItems(p, expr.Spec.PartitionBy, aggMapper)
+		if b.err != nil {
+			return nil
+		}
+		orderBy, p = b.rewriteWindowByItems(p, expr.Spec.OrderBy, aggMapper)
+		if b.err != nil {
+			return nil
+		}
+		fun := expression.NewWindowFunc(expr.F, args, expr.GetType())
+		idx := -1
+		for i, window := range windows {
+			if byItemsEqual(window.PartitionBy, partitionBy, b.ctx) && byItemsEqual(window.OrderBy, orderBy, b.ctx) {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			idx = len(windows)
+			windows = append(windows, LogicalWindow{PartitionBy: partitionBy, OrderBy: orderBy}.init(b.allocator, b.ctx))
+			windowExprs = append(windowExprs, nil)
+		}
+		windows[idx].WindowFuncs = append(windows[idx].WindowFuncs, fun)
+		windowExprs[idx] = append(windowExprs[idx], expr)
+	}
+	if b.windowMapper == nil {
+		b.windowMapper = make(map[*ast.WindowFuncExpr]int)
+	}
+	for i, window := range windows {
+		child := p
+		if len(window.PartitionBy)+len(window.OrderBy) > 0 {
+			sort := Sort{}.init(b.allocator, b.ctx)
+			for _, item := range append(append([]*ByItems(nil), window.PartitionBy...), window.OrderBy...) {
+				sort.ByItems = append(sort.ByItems, &ByItems{Expr: item.Expr.Clone(), Desc: item.Desc})
+			}
+			addChild(sort, p)
+			sort.SetSchema(p.Schema().Clone())
+			child = sort
+		}
+		schema := child.Schema().Clone()
+		for j, fun := range window.WindowFuncs {
+			b.windowMapper[windowExprs[i][j]] = schema.Len()
+			schema.Append(&expression.Column{
+				FromID:      window.id,
+				ColName:     model.NewCIStr(fmt.Sprintf("%s_col_%d", window.id, j)),
+				Position:    j,
+				IsAggOrSubq: true,
+				RetType:     fun.GetType(),
+			})
+		}
+		addChild(window, child)
+		window.SetSchema(schema)
+		p = window
+	}
+	return p
+}
+
+func (b *planBuilder) rewriteWindowByItems(p LogicalPlan, items []*ast.ByItem, aggMapper map[*ast.AggregateFuncExpr]int) ([]*ByItems, LogicalPlan) {
+	byItems := make([]*ByItems, 0, len(items))
+	for _, item := range items {
+		expr, np, err := b.rewrite(item.Expr, p, aggMapper, true)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil, nil
+		}
+		p = np
+		byItems = append(byItems, &ByItems{Expr: expr, Desc: item.Desc})
+	}
+	return byItems, p
+}
+
+func byItemsEqual(a, b []*ByItems, ctx context.Context) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Desc != b[i].Desc || !a[i].Expr.Equal(b[i].Expr, ctx) {
+			return false
+		}
+	}
+	return true
+}
+
 func (b *planBuilder) buildDistinct(child LogicalPlan, length int) LogicalPlan {
 	b.optFlag = b.optFlag | flagBuildKeyInfo
 	b.optFlag = b.optFlag | flagAggregationOptimize
@@ -364,11 +650,123 @@ func (b *planBuilder) buildDistinct(child LogicalPlan, length int) LogicalPlan {
 	return agg
 }
 
+func isNullFieldType(tp *types.FieldType) bool {
+	return tp.Tp == mysql.TypeUnspecified || tp.Tp == mysql.TypeNull
+}
+
+func isStringFieldType(tp *types.FieldType) bool {
+	return types.IsTypeBlob(tp.Tp) || types.IsTypeChar(tp.Tp) || types.IsTypeVarchar(tp.Tp)
+}
+
+// unionJoinFieldType merges the field types of a column in the UNION branches as MySQL does.
+// For example, the result type of "select 1 union select 'abc'" is varchar,
+// and the values of all the branches are converted to the result type.
+func unionJoinFieldType(a, b *types.FieldType) *types.FieldType {
+	// For "select null union select 'abc'", the result type should be varchar but nullable.
+	if isNullFieldType(a) || isNullFieldType(b) {
+		tp := *a
+		if isNullFieldType(a) {
+			tp = *b
+		}
+		tp.Flag &^= mysql.NotNullFlag
+		if a.Flen > tp.Flen {
+			tp.Flen = a.Flen
+		}
+		if b.Flen > tp.Flen {
+			tp.Flen = b.Flen
+		}
+		return &tp
+	}
+	tp := types.NewFieldType(types.MergeFieldType(a.Tp, b.Tp))
+	// The result is not null or unsigned only if both of the types are.
+	tp.Flag = a.Flag & b.Flag & (mysql.NotNullFlag | mysql.UnsignedFlag)
+	// The length is unknown if any of the lengths is unknown.
+	tp.Flen = types.UnspecifiedLength
+	if a.Flen != types.UnspecifiedLength && b.Flen != types.UnspecifiedLength {
+		tp.Flen = a.Flen
+		if b.Flen > tp.Flen {
+			tp.Flen = b.Flen
+		}
+	}
+	aDec, bDec := decimalDigits(a), decimalDigits(b)
+	switch {
+	case tp.ToClass() == types.ClassInt:
+		tp.Decimal = 0
+	case isStringFieldType(tp) || aDec == types.UnspecifiedLength || bDec == types.UnspecifiedLength:
+		tp.Decimal = types.UnspecifiedLength
+	default:
+		tp.Decimal = aDec
+		if bDec > tp.Decimal {
+			tp.Decimal = bDec
+		}
+	}
+	// The digits before the decimal point and the ones after it are merged separately,
+	// so the result type of decimal(5,2) and int(11) is decimal(13,2).
+	if (tp.ToClass() == types.ClassDecimal || tp.ToClass() == types.ClassReal) && tp.Decimal > 0 &&
+		tp.Flen != types.UnspecifiedLength {
+		tp.Flen = intDigits(a)
+		if intDigits(b) > tp.Flen {
+			tp.Flen = intDigits(b)
+		}
+		tp.Flen += tp.Decimal
+	}
+	if !isStringFieldType(tp) {
+		types.SetBinChsClnFlag(tp)
+		return tp
+	}
+	// The result is a binary string if any of the string branches is binary,
+	// the numbers and temporal values are converted to the nonbinary string.
+	tp.Charset, tp.Collate = mysql.DefaultCharset, mysql.DefaultCollationName
+	for _, ft := range []*types.FieldType{b, a} {
+		if !isStringFieldType(ft) {
+			continue
+		}
+		if ft.Charset == charset.CharsetBin {
+			types.SetBinChsClnFlag(tp)
+			break
+		}
+		if ft.Charset != "" {
+			tp.Charset, tp.Collate = ft.Charset, ft.Collate
+		}
+	}
+	return tp
+}
+
+// unionNeedCast checks if the column of a UNION branch should be cast to the result type.
+func unionNeedCast(from, to *types.FieldType) bool {
+	if isNullFieldType(from) || isNullFieldType(to) {
+		return false
+	}
+	if from.Tp != to.Tp {
+		return true
+	}
+	return to.ToClass() == types.ClassDecimal && from.Decimal != to.Decimal
+}
+
+// decimalDigits returns the number of digits after the decimal point, integers have no such digits.
+func decimalDigits(tp *types.FieldType) int {
+	if tp.ToClass() == types.ClassInt {
+		return 0
+	}
+	return tp.Decimal
+}
+
+// intDigits returns the length of the numeric field type before the decimal point.
+func intDigits(tp *types.FieldType) int {
+	if tp.Decimal > 0 {
+		return tp.Flen - tp.Decimal
+	}
+	return tp.Flen
+}
+
 func (b *planBuilder) buildUnion(union *ast.UnionStmt) LogicalPlan {
 	u := Union{}.init(b.allocator, b.ctx)
 	u.children = make([]Plan, len(union.SelectList.Selects))
 	for i, sel := range union.SelectList.Selects {
 		u.children[i] = b.buildSelect(sel)
+		if b.err != nil {
+			return nil
+		}
 	}
 	firstSchema := u.children[0].Schema().Clone()
 	for i, sel := range u.children {
@@ -395,17 +793,24 @@ func (b *planBuilder) buildUnion(union *ast.UnionStmt) LogicalPlan {
 			 * | bbbbbbbbbb    |
 			 * +---------------+
 			 */
-			if col.RetType.Flen > firstSchema.Columns[i].RetType.Flen {
-				firstSchema.Columns[i].RetType.Flen = col.RetType.Flen
-			}
-			// For select nul union select "abc", we should not convert "abc" to nil.
-			// And the result field type should be VARCHAR.
-			if firstSchema.Columns[i].RetType.Tp == 0 || firstSchema.Columns[i].RetType.Tp == mysql.TypeNull {
-				firstSchema.Columns[i].RetType.Tp = col.RetType.Tp
-			}
+			firstSchema.Columns[i].RetType = unionJoinFieldType(firstSchema.Columns[i].RetType, col.RetType)
 		}
 		sel.SetParents(u)
 	}
+	// Cast the columns of the branches to the result types, so all the branches output rows of the same types.
+	for _, child := range u.children {
+		proj := child.(*Projection)
+		for i, col := range proj.Schema().Columns {
+			tp := firstSchema.Columns[i].RetType
+			if !unionNeedCast(col.RetType, tp) {
+				continue
+			}
+			proj.Exprs[i] = expression.NewCastFunc(tp, proj.Exprs[i], b.ctx)
+			newCol := *col
+			newCol.RetType = tp
+			proj.Schema().Columns[i] = &newCol
+		}
+	}
 	for _, v := range firstSchema.Columns {
 		v.FromID = u.id
 		v.DBName = model.NewCIStr("")
@@ -460,7 +865,7 @@ func (b *planBuilder) buildSort(p LogicalPlan, byItems []*ast.ByItem, aggMapper
 
 // getUintForLimitOffset gets uint64 value for limit/offset.
 // For ordinary statement, limit/offset should be uint64 constant value.
-// For prepared statement, limit/offset is string. We should convert it to uint64.
+// For prepared statement, limit/offset may be string, real or decimal. We should convert it to uint64.
 func getUintForLimitOffset(sc *variable.StatementContext, val interface{}) (uint64, error) {
 	switch v := val.(type) {
 	case uint64:
@@ -472,6 +877,14 @@ func getUintForLimitOffset(sc *variable.StatementContext, val interface{}) (uint
 	case string:
 		uVal, err := types.StrToUint(sc, v)
 		return uVal, errors.Trace(err)
+	case float64, *types.MyDecimal:
+		// The real and decimal user variables are converted like the strings.
+		str, err := types.ToString(v)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		uVal, err := types.StrToUint(sc, str)
+		return uVal, errors.Trace(err)
 	}
 	return 0, errors.Errorf("Invalid type %T for Limit/Offset", val)
 }
@@ -535,7 +948,7 @@ func matchField(f *ast.SelectField, col *ast.ColumnNameExpr, ignoreAsName bool)
 	return false
 }
 
-func resolveFromSelectFields(v *ast.ColumnNameExpr, fields []*ast.SelectField, ignoreAsName bool) (index int, err error) {
+func resolveFromSelectFields(v *ast.ColumnNameExpr, fields []*ast.SelectField, ignoreAsName bool, clause clauseCode) (index int, err error) {
 	var matchedExpr ast.ExprNode
 	index = -1
 	for i, field := range fields {
@@ -552,7 +965,7 @@ func resolveFromSelectFields(v *ast.ColumnNameExpr, fields []*ast.SelectField, i
 				index = i
 			} else if !colMatch(matchedExpr.(*ast.ColumnNameExpr).Name, curCol.Name) &&
 				!colMatch(curCol.Name, matchedExpr.(*ast.ColumnNameExpr).Name) {
-				return -1, ErrAmbiguous.GenByArgs(curCol.Name.Name.L)
+				return -1, ErrAmbiguous.GenByArgs(v.Name.Name.O, clauseMsg[clause])
 			}
 		}
 	}
@@ -572,12 +985,16 @@ type havingAndOrderbyExprResolver struct {
 	colMapper    map[*ast.ColumnNameExpr]int
 	gbyItems     []*ast.ByItem
 	outerSchemas []*expression.Schema
+	outerAggs    map[*ast.AggregateFuncExpr]*outerAggFunc
 }
 
 // Enter implements Visitor interface.
 func (a *havingAndOrderbyExprResolver) Enter(n ast.Node) (node ast.Node, skipChildren bool) {
-	switch n.(type) {
+	switch v := n.(type) {
 	case *ast.AggregateFuncExpr:
+		if _, ok := a.outerAggs[v]; ok {
+			return n, true
+		}
 		a.inAggFunc = true
 	case *ast.ParamMarkerExpr, *ast.ColumnNameExpr, *ast.ColumnName:
 	case *ast.SubqueryExpr, *ast.ExistsSubqueryExpr:
@@ -619,8 +1036,15 @@ func (a *havingAndOrderbyExprResolver) resolveFromSchema(v *ast.ColumnNameExpr,
 
 // Leave implements Visitor interface.
 func (a *havingAndOrderbyExprResolver) Leave(n ast.Node) (node ast.Node, ok bool) {
+	clause := havingClause
+	if a.orderBy {
+		clause = orderByClause
+	}
 	switch v := n.(type) {
 	case *ast.AggregateFuncExpr:
+		if _, ok := a.outerAggs[v]; ok {
+			return n, true
+		}
 		a.inAggFunc = false
 		a.aggMapper[v] = len(a.selectFields)
 		a.selectFields = append(a.selectFields, &ast.SelectField{
@@ -644,7 +1068,7 @@ func (a *havingAndOrderbyExprResolver) Leave(n ast.Node) (node ast.Node, ok bool
 		}
 		index := -1
 		if resolveFieldsFirst {
-			index, a.err = resolveFromSelectFields(v, a.selectFields, false)
+			index, a.err = resolveFromSelectFields(v, a.selectFields, false, clause)
 			if a.err != nil {
 				return node, false
 			}
@@ -652,7 +1076,7 @@ func (a *havingAndOrderbyExprResolver) Leave(n ast.Node) (node ast.Node, ok bool
 				if a.orderBy {
 					index, a.err = a.resolveFromSchema(v, a.p.Schema())
 				} else {
-					index, a.err = resolveFromSelectFields(v, a.selectFields, true)
+					index, a.err = resolveFromSelectFields(v, a.selectFields, true, clause)
 				}
 			}
 		} else {
@@ -660,7 +1084,7 @@ func (a *havingAndOrderbyExprResolver) Leave(n ast.Node) (node ast.Node, ok bool
 			// when considering select fields.
 			index, _ = a.resolveFromSchema(v, a.p.Schema())
 			if index == -1 {
-				index, a.err = resolveFromSelectFields(v, a.selectFields, false)
+				index, a.err = resolveFromSelectFields(v, a.selectFields, false, clause)
 			}
 		}
 		if a.err != nil {
@@ -673,7 +1097,7 @@ func (a *havingAndOrderbyExprResolver) Leave(n ast.Node) (node ast.Node, ok bool
 					return n, true
 				}
 			}
-			a.err = errors.Errorf("Unknown Column %s", v.Name.Name.L)
+			a.err = ErrUnknownColumn.GenByArgs(v.Name.OrigColName(), clauseMsg[clause])
 			return node, false
 		}
 		if a.inAggFunc {
@@ -695,6 +1119,7 @@ func (b *planBuilder) resolveHavingAndOrderBy(sel *ast.SelectStmt, p LogicalPlan
 		aggMapper:    make(map[*ast.AggregateFuncExpr]int),
 		colMapper:    b.colMapper,
 		outerSchemas: b.outerSchemas,
+		outerAggs:    b.outerAggs,
 	}
 	if sel.GroupBy != nil {
 		extractor.gbyItems = sel.GroupBy.Items
@@ -727,13 +1152,149 @@ func (b *planBuilder) resolveHavingAndOrderBy(sel *ast.SelectStmt, p LogicalPlan
 	return havingAggMapper, extractor.aggMapper
 }
 
+// checkOnlyFullGroupBy checks that the nonaggregated columns of the select fields and the order by items are
+// functionally dependent on the group by items, as sql_mode ONLY_FULL_GROUP_BY requires.
+func (b *planBuilder) checkOnlyFullGroupBy(p LogicalPlan, sel *ast.SelectStmt, gbyExprs []expression.Expression) {
+	checker := &fullGroupByChecker{
+		b:          b,
+		p:          p,
+		fields:     sel.Fields.Fields,
+		gbyExprs:   gbyExprs,
+		hasGroupBy: sel.GroupBy != nil,
+	}
+	checker.collectDeterminedTables()
+	for i, field := range sel.Fields.Fields {
+		if field.Auxiliary {
+			continue
+		}
+		if b.err = checker.check(field.Expr, i+1, "SELECT list"); b.err != nil {
+			return
+		}
+	}
+	if sel.OrderBy != nil {
+		for i, item := range sel.OrderBy.Items {
+			if b.err = checker.check(item.Expr, i+1, "ORDER BY clause"); b.err != nil {
+				return
+			}
+		}
+	}
+}
+
+// fullGroupByChecker finds the nonaggregated columns which are not determined by the group by items.
+type fullGroupByChecker struct {
+	b          *planBuilder
+	p          LogicalPlan
+	fields     []*ast.SelectField
+	gbyExprs   []expression.Expression
+	hasGroupBy bool
+	// determinedTables are the tables whose primary key columns are all in the group by items.
+	determinedTables map[string]bool
+	inAggFunc        int
+	cols             []*ast.ColumnNameExpr
+}
+
+func (c *fullGroupByChecker) collectDeterminedTables() {
+	c.determinedTables = make(map[string]bool)
+	for _, col := range c.p.Schema().Columns {
+		if !mysql.HasPriKeyFlag(col.RetType.Flag) {
+			continue
+		}
+		name := col.DBName.L + "." + col.TblName.L
+		determined, ok := c.determinedTables[name]
+		if !ok || determined {
+			c.determinedTables[name] = c.inGroupBy(col)
+		}
+	}
+}
+
+func (c *fullGroupByChecker) inGroupBy(col *expression.Column) bool {
+	for _, expr := range c.gbyExprs {
+		if gbyCol, ok := expr.(*expression.Column); ok && gbyCol.Equal(col, c.b.ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns an error if expr refers to a column which is not determined by the group by items.
+// offset is the position of expr in the clause.
+func (c *fullGroupByChecker) check(expr ast.ExprNode, offset int, clause string) error {
+	if _, ok := expr.(*ast.ColumnNameExpr); !ok && len(c.gbyExprs) > 0 &&
+		expr.GetFlag()&(ast.FlagHasAggregateFunc|ast.FlagHasSubquery) == 0 {
+		// The expression itself may be a group by item, e.g. "select a + 1 from t group by a + 1".
+		newExpr, _, err := c.b.rewrite(expr, c.p, nil, true)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, gbyExpr := range c.gbyExprs {
+			if newExpr.Equal(gbyExpr, c.b.ctx) {
+				return nil
+			}
+		}
+	}
+	c.cols = c.cols[:0]
+	expr.Accept(c)
+	for _, v := range c.cols {
+		if idx, ok := c.b.colMapper[v]; ok && !c.fields[idx].Auxiliary {
+			// It refers to a select field, which is checked itself.
+			continue
+		}
+		col, err := c.p.Schema().FindColumn(v.Name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if col == nil {
+			// It's an alias of a select field or a correlated column.
+			continue
+		}
+		if !c.hasGroupBy {
+			return ErrMixOfGroupFuncAndFields.GenByArgs(offset, clause, col.String())
+		}
+		if c.inGroupBy(col) || c.determinedTables[col.DBName.L+"."+col.TblName.L] {
+			continue
+		}
+		return ErrFieldNotInGroupBy.GenByArgs(offset, clause, col.String())
+	}
+	return nil
+}
+
+// Enter implements Visitor interface.
+func (c *fullGroupByChecker) Enter(n ast.Node) (ast.Node, bool) {
+	switch n.(type) {
+	case *ast.AggregateFuncExpr:
+		c.inAggFunc++
+	case *ast.SubqueryExpr, *ast.CompareSubqueryExpr, *ast.ExistsSubqueryExpr:
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements Visitor interface.
+func (c *fullGroupByChecker) Leave(n ast.Node) (ast.Node, bool) {
+	switch v := n.(type) {
+	case *ast.AggregateFuncExpr:
+		c.inAggFunc--
+	case *ast.ColumnNameExpr:
+		if c.inAggFunc == 0 {
+			c.cols = append(c.cols, v)
+		}
+	}
+	return n, true
+}
+
 func (b *planBuilder) extractAggFuncs(fields []*ast.SelectField) ([]*ast.AggregateFuncExpr, map[*ast.AggregateFuncExpr]int) {
 	extractor := &AggregateFuncExtractor{}
 	for _, f := range fields {
 		n, _ := f.Expr.Accept(extractor)
 		f.Expr = n.(ast.ExprNode)
 	}
-	aggList := extractor.AggFuncs
+	aggList := make([]*ast.AggregateFuncExpr, 0, len(extractor.AggFuncs))
+	for _, agg := range extractor.AggFuncs {
+		// The aggregate functions of the outer query are not aggregated here.
+		if _, ok := b.outerAggs[agg]; !ok {
+			aggList = append(aggList, agg)
+		}
+	}
 	totalAggMapper := make(map[*ast.AggregateFuncExpr]int)
 
 	for i, agg := range aggList {
@@ -742,6 +1303,120 @@ func (b *planBuilder) extractAggFuncs(fields []*ast.SelectField) ([]*ast.Aggrega
 	return aggList, totalAggMapper
 }
 
+// outerAggFunc is an aggregate function in a subquery which is aggregated in the outer query.
+type outerAggFunc struct {
+	// col is the output column of the aggregation in the outer query.
+	col *expression.Column
+	// offset is the offset of the auxiliary select field of the outer query.
+	offset int
+	// level is the index of the outer query's schema in outerSchemas.
+	level int
+}
+
+// outerAggExtractor collects the aggregate functions in subqueries whose arguments only refer to the columns
+// of the current query. As MySQL does, these aggregate functions are aggregated in the current query.
+// For example: select a, (select sum(t.b) from s limit 1) from t group by a;
+type outerAggExtractor struct {
+	fields   map[*ast.ResultField]struct{}
+	depth    int
+	aggFuncs []*ast.AggregateFuncExpr
+}
+
+// Enter implements Visitor interface.
+func (e *outerAggExtractor) Enter(n ast.Node) (ast.Node, bool) {
+	switch v := n.(type) {
+	case *ast.SelectStmt:
+		e.depth++
+	case *ast.AggregateFuncExpr:
+		if e.depth > 0 && e.isOuter(v) {
+			e.aggFuncs = append(e.aggFuncs, v)
+		}
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements Visitor interface.
+func (e *outerAggExtractor) Leave(n ast.Node) (ast.Node, bool) {
+	if _, ok := n.(*ast.SelectStmt); ok {
+		e.depth--
+	}
+	return n, true
+}
+
+// isOuter checks if all the columns in the arguments of the aggregate function refer to the current query.
+func (e *outerAggExtractor) isOuter(agg *ast.AggregateFuncExpr) bool {
+	extractor := &columnNameExtractor{}
+	for _, arg := range agg.Args {
+		arg.Accept(extractor)
+	}
+	if len(extractor.cols) == 0 {
+		return false
+	}
+	for _, col := range extractor.cols {
+		if _, ok := e.fields[col.Refer]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// columnNameExtractor collects the column name exprs, the ones in subqueries are skipped.
+type columnNameExtractor struct {
+	cols []*ast.ColumnNameExpr
+}
+
+// Enter implements Visitor interface.
+func (e *columnNameExtractor) Enter(n ast.Node) (ast.Node, bool) {
+	switch v := n.(type) {
+	case *ast.ColumnNameExpr:
+		e.cols = append(e.cols, v)
+	case *ast.SelectStmt, *ast.UnionStmt:
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements Visitor interface.
+func (e *columnNameExtractor) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// extractOuterAggFuncs extracts the aggregate functions in the subqueries of select fields, having and order by
+// clauses which should be aggregated in the query.
+func (b *planBuilder) extractOuterAggFuncs(sel *ast.SelectStmt) []*ast.AggregateFuncExpr {
+	if sel.From == nil {
+		return nil
+	}
+	extractor := &outerAggExtractor{fields: make(map[*ast.ResultField]struct{})}
+	for _, ts := range appendTableSources(nil, sel.From.TableRefs) {
+		for _, rf := range ts.GetResultFields() {
+			extractor.fields[rf] = struct{}{}
+		}
+	}
+	if sel.Where != nil {
+		sel.Where.Accept(extractor)
+		if len(extractor.aggFuncs) > 0 {
+			b.err = ErrInvalidGroupFuncUse
+			return nil
+		}
+	}
+	for _, f := range sel.Fields.Fields {
+		if f.Expr != nil {
+			f.Expr.Accept(extractor)
+		}
+	}
+	if sel.Having != nil {
+		sel.Having.Expr.Accept(extractor)
+	}
+	if sel.OrderBy != nil {
+		for _, item := range sel.OrderBy.Items {
+			item.Expr.Accept(extractor)
+		}
+	}
+	return extractor.aggFuncs
+}
+
 // gbyResolver resolves group by items from select fields.
 type gbyResolver struct {
 	fields []*ast.SelectField
@@ -767,7 +1442,7 @@ func (g *gbyResolver) Leave(inNode ast.Node) (ast.Node, bool) {
 		col, err := g.schema.FindColumn(v.Name)
 		if col == nil || !g.inExpr {
 			var index = -1
-			index, g.err = resolveFromSelectFields(v, g.fields, false)
+			index, g.err = resolveFromSelectFields(v, g.fields, false, groupByClause)
 			if g.err != nil {
 				return inNode, false
 			}
@@ -777,14 +1452,16 @@ func (g *gbyResolver) Leave(inNode ast.Node) (ast.Node, bool) {
 			if index != -1 {
 				return g.fields[index].Expr, true
 			}
-			g.err = errors.Trace(err)
-			return inNode, false
+			if err != nil {
+				g.err = ErrAmbiguous.GenByArgs(v.Name.Name.O, clauseMsg[groupByClause])
+			}
+			return inNode, g.err == nil
 		}
 	case *ast.PositionExpr:
 		if v.N >= 1 && v.N <= len(g.fields) {
 			return g.fields[v.N-1].Expr, true
 		}
-		g.err = errors.Errorf("Unknown column '%d' in 'group statement'", v.N)
+		g.err = ErrUnknownColumn.GenByArgs(strconv.Itoa(v.N), clauseMsg[groupByClause])
 		return inNode, false
 	}
 	return inNode, true
@@ -825,6 +1502,9 @@ func (b *planBuilder) unfoldWildStar(p LogicalPlan, selectFields []*ast.SelectFi
 		dbName := field.WildCard.Schema
 		tblName := field.WildCard.Table
 		for _, col := range p.Schema().Columns {
+			if col.IsHidden && tblName.L == "" {
+				continue
+			}
 			if (dbName.L == "" || dbName.L == col.DBName.L) &&
 				(tblName.L == "" || tblName.L == col.TblName.L) {
 				colName := &ast.ColumnNameExpr{
@@ -844,27 +1524,77 @@ func (b *planBuilder) unfoldWildStar(p LogicalPlan, selectFields []*ast.SelectFi
 }
 
 func (b *planBuilder) pushTableHints(hints []*ast.TableOptimizerHint) bool {
-	var sortMergeTables, INLJTables []model.CIStr
+	var sortMergeTables, INLJTables, indexAscTables, indexDescTables []model.CIStr
 	for _, hint := range hints {
 		switch hint.HintName.L {
 		case TiDBMergeJoin:
 			sortMergeTables = append(sortMergeTables, hint.Tables...)
 		case TiDBIndexNestedLoopJoin:
 			INLJTables = append(INLJTables, hint.Tables...)
+		case TiDBIndexAsc:
+			indexAscTables = append(indexAscTables, hint.Tables...)
+		case TiDBIndexDesc:
+			indexDescTables = append(indexDescTables, hint.Tables...)
 		default:
 			// ignore hints that not implemented
 		}
 	}
-	if len(sortMergeTables) != 0 || len(INLJTables) != 0 {
+	if len(sortMergeTables) != 0 || len(INLJTables) != 0 || len(indexAscTables) != 0 || len(indexDescTables) != 0 {
 		b.tableHintInfo = append(b.tableHintInfo, tableHintInfo{
 			sortMergeJoinTables: sortMergeTables,
 			INLJTables:          INLJTables,
+			indexAscTables:      indexAscTables,
+			indexDescTables:     indexDescTables,
 		})
 		return true
 	}
 	return false
 }
 
+// buildSelectivityHints rewrites the predicates of the SELECTIVITY hints on the from clause, and records
+// their selectivities in the statement context for the cost estimation.
+// A hint takes effect on the condition equal to its predicate, so it should be one of the conjuncts.
+func (b *planBuilder) buildSelectivityHints(p LogicalPlan, hints []*ast.TableOptimizerHint) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	b.curClause = whereClause
+	for _, hint := range hints {
+		if hint.HintName.L != TiDBSelectivity {
+			continue
+		}
+		if hint.Selectivity < 0 || hint.Selectivity > 1 {
+			sc.AppendWarning(ErrInvalidSelectivityHint.GenByArgs(hint.Selectivity))
+			continue
+		}
+		expr, np, err := b.rewrite(hint.Expr, p, nil, false)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return
+		}
+		if np != p {
+			// The predicate has a subquery which is built as a join, it's never a condition of a selection.
+			continue
+		}
+		if sc.SelectivityHints == nil {
+			sc.SelectivityHints = make(map[string]float64)
+		}
+		sc.SelectivityHints[expr.String()] = hint.Selectivity
+	}
+}
+
+// scanDirection returns the direction of the ordered scans of the table, the hints take precedence over the
+// tidb_index_scan_direction variable.
+func (b *planBuilder) scanDirection(tableName *model.CIStr) variable.ScanDirection {
+	if hints := b.TableHints(); hints != nil {
+		if hints.ifPreferIndexDesc(tableName) {
+			return variable.ScanDirectionDesc
+		}
+		if hints.ifPreferIndexAsc(tableName) {
+			return variable.ScanDirectionAsc
+		}
+	}
+	return b.ctx.GetSessionVars().IndexScanDirection
+}
+
 func (b *planBuilder) popTableHints() {
 	b.tableHintInfo = b.tableHintInfo[:len(b.tableHintInfo)-1]
 }
@@ -885,7 +1615,15 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 		}
 	}
 
-	hasAgg := b.detectSelectAgg(sel)
+	// The clause of the outer query is restored after building a subquery.
+	defer func(clause clauseCode) {
+		b.curClause = clause
+	}(b.curClause)
+	outerAggs := b.extractOuterAggFuncs(sel)
+	if b.err != nil {
+		return nil
+	}
+	hasAgg := b.detectSelectAgg(sel) || len(outerAggs) > 0
 	var (
 		p                             LogicalPlan
 		aggFuncs                      []*ast.AggregateFuncExpr
@@ -900,12 +1638,19 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 	if b.err != nil {
 		return nil
 	}
+	if sel.TableHints != nil {
+		b.buildSelectivityHints(p, sel.TableHints)
+		if b.err != nil {
+			return nil
+		}
+	}
 	originalFields := sel.Fields.Fields
 	sel.Fields.Fields = b.unfoldWildStar(p, sel.Fields.Fields)
 	if b.err != nil {
 		return nil
 	}
 	if sel.GroupBy != nil {
+		b.curClause = groupByClause
 		p, gbyCols = b.resolveGbyExprs(p, sel.GroupBy, sel.Fields.Fields)
 		if b.err != nil {
 			return nil
@@ -915,7 +1660,17 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 	// because when the query is "select a+1 as b from t having sum(b) < 0", we must replace sum(b) to sum(a+1),
 	// which only can be done before building projection and extracting Agg functions.
 	havingMap, orderMap = b.resolveHavingAndOrderBy(sel, p)
+	if b.err != nil {
+		return nil
+	}
+	if hasAgg && b.ctx.GetSessionVars().SQLMode&mysql.ModeOnlyFullGroupBy > 0 {
+		b.checkOnlyFullGroupBy(p, sel, gbyCols)
+		if b.err != nil {
+			return nil
+		}
+	}
 	if sel.Where != nil {
+		b.curClause = whereClause
 		p = b.buildSelection(p, sel.Where, nil)
 		if b.err != nil {
 			return nil
@@ -925,6 +1680,15 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 		p = b.buildSelectLock(p, sel.LockTp)
 	}
 	if hasAgg {
+		// The aggregate functions of the subqueries are computed by the auxiliary select fields.
+		offset := len(sel.Fields.Fields)
+		for i, agg := range outerAggs {
+			sel.Fields.Fields = append(sel.Fields.Fields, &ast.SelectField{
+				Auxiliary: true,
+				Expr:      agg,
+				AsName:    model.NewCIStr(fmt.Sprintf("sel_agg_%d", offset+i)),
+			})
+		}
 		aggFuncs, totalMap = b.extractAggFuncs(sel.Fields.Fields)
 		if b.err != nil {
 			return nil
@@ -937,6 +1701,23 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 		if b.err != nil {
 			return nil
 		}
+		if len(outerAggs) > 0 && b.outerAggs == nil {
+			b.outerAggs = make(map[*ast.AggregateFuncExpr]*outerAggFunc)
+		}
+		for i, agg := range outerAggs {
+			b.outerAggs[agg] = &outerAggFunc{
+				col:    p.Schema().Columns[totalMap[agg]],
+				offset: offset + i,
+				level:  len(b.outerSchemas),
+			}
+		}
+	}
+	b.curClause = fieldList
+	if windowFuncs := extractWindowFuncs(sel.Fields.Fields); len(windowFuncs) > 0 {
+		p = b.buildWindowFuncs(p, windowFuncs, totalMap)
+		if b.err != nil {
+			return nil
+		}
 	}
 	var oldLen int
 	p, oldLen = b.buildProjection(p, sel.Fields.Fields, totalMap)
@@ -944,6 +1725,7 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 		return nil
 	}
 	if sel.Having != nil {
+		b.curClause = havingClause
 		p = b.buildSelection(p, sel.Having.Expr, havingMap)
 		if b.err != nil {
 			return nil
@@ -956,6 +1738,7 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 		}
 	}
 	if sel.OrderBy != nil {
+		b.curClause = orderByClause
 		p = b.buildSort(p, sel.OrderBy.Items, orderMap)
 		if b.err != nil {
 			return nil
@@ -1055,6 +1838,7 @@ func (b *planBuilder) buildApplyWithJoinType(outerPlan, innerPlan LogicalPlan, t
 	b.optFlag = b.optFlag | flagBuildKeyInfo
 	b.optFlag = b.optFlag | flagDecorrelate
 	ap := LogicalApply{LogicalJoin: LogicalJoin{JoinType: tp}}.init(b.allocator, b.ctx)
+	ap.keepOuterRows = b.inUpdateStmt || b.inDeleteStmt
 
 	addChild(ap, outerPlan)
 	addChild(ap, innerPlan)
@@ -1125,6 +1909,11 @@ func (b *planBuilder) buildSemiJoin(outerPlan, innerPlan LogicalPlan, onConditio
 	joinPlan.SetChildren(outerPlan, innerPlan)
 	outerPlan.SetParents(joinPlan)
 	innerPlan.SetParents(joinPlan)
+	// The result of an anti semi join or a semi join with aux column tells NULL from false, so the comparisons
+	// coming from the subquery are evaluated null-aware.
+	if asScalar || not {
+		joinPlan.NAEQConditions, onCondition = extractNAEQCondition(onCondition, outerPlan, innerPlan)
+	}
 	joinPlan.attachOnConds(onCondition)
 	if asScalar {
 		newSchema := outerPlan.Schema().Clone()
@@ -1151,6 +1940,7 @@ func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 	if b.err != nil {
 		return nil
 	}
+	oldLen := p.Schema().Len()
 
 	var tableList []*ast.TableName
 	tableList = extractTableList(sel.From.TableRefs, tableList)
@@ -1163,6 +1953,7 @@ func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 	}
 
 	if sel.Where != nil {
+		b.curClause = whereClause
 		p = b.buildSelection(p, sel.Where, nil)
 		if b.err != nil {
 			return nil
@@ -1180,6 +1971,14 @@ func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 			return nil
 		}
 	}
+	// The subqueries in the where clause append their columns to the schema, project them away
+	// so that the assignments only see the columns of the updated tables.
+	if oldLen != p.Schema().Len() {
+		proj := Projection{Exprs: expression.Column2Exprs(p.Schema().Columns[:oldLen])}.init(b.allocator, b.ctx)
+		addChild(proj, p)
+		proj.SetSchema(expression.NewSchema(p.Schema().Columns[:oldLen]...))
+		p = proj
+	}
 	orderedList, np := b.buildUpdateLists(update.List, p)
 	if b.err != nil {
 		return nil
@@ -1221,6 +2020,7 @@ func (b *planBuilder) buildUpdateLists(list []*ast.Assignment, p LogicalPlan) ([
 }
 
 func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
+	b.inDeleteStmt = true
 	sel := &ast.SelectStmt{Fields: &ast.FieldList{}, From: delete.TableRefs, Where: delete.Where, OrderBy: delete.Order, Limit: delete.Limit}
 	p := b.buildResultSetNode(sel.From.TableRefs)
 	if b.err != nil {
@@ -1228,6 +2028,7 @@ func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
 	}
 
 	if sel.Where != nil {
+		b.curClause = whereClause
 		p = b.buildSelection(p, sel.Where, nil)
 		if b.err != nil {
 			return nil
@@ -676,6 +676,68 @@ func (s *testPlanSuite) TestPlanBuilder(c *C) {
 	}
 }
 
+func (s *testPlanSuite) TestSubqueryResultReuse(c *C) {
+	defer testleak.AfterTest(c)()
+	evalCount := 0
+	originEvalSubquery := EvalSubquery
+	defer func() {
+		EvalSubquery = originEvalSubquery
+	}()
+	EvalSubquery = func(p PhysicalPlan, is infoschema.InfoSchema, ctx context.Context) ([][]types.Datum, error) {
+		evalCount++
+		return [][]types.Datum{{types.NewIntDatum(1)}}, nil
+	}
+	tests := []struct {
+		sql   string
+		count int
+	}{
+		{
+			sql:   "select (select max(a) from t), (select max(a) from t) + 1 from t where b > (select max(a) from t)",
+			count: 1,
+		},
+		{
+			// The same subquery evaluated in different ways.
+			sql:   "select (select max(a) from t), exists (select max(a) from t) from t",
+			count: 2,
+		},
+		{
+			sql:   "select (select max(a) from t), (select max(b) from t) from t",
+			count: 2,
+		},
+		{
+			// Correlated subqueries are not evaluated in the plan builder.
+			sql:   "select (select max(s.a) from t s where s.b = t.b), (select max(s.a) from t s where s.b = t.b) from t",
+			count: 0,
+		},
+		{
+			sql:   "select (select rand() from t limit 1), (select rand() from t limit 1)",
+			count: 2,
+		},
+		{
+			sql:   "select (select @x := max(a) from t), (select @x := max(a) from t)",
+			count: 2,
+		},
+	}
+	for _, tt := range tests {
+		comment := Commentf("for %s", tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+		is, err := MockResolve(stmt)
+		c.Assert(err, IsNil, comment)
+
+		evalCount = 0
+		builder := &planBuilder{
+			allocator: new(idAllocator),
+			ctx:       mockContext(),
+			colMapper: make(map[*ast.ColumnNameExpr]int),
+			is:        is,
+		}
+		builder.build(stmt)
+		c.Assert(builder.err, IsNil, comment)
+		c.Assert(evalCount, Equals, tt.count, comment)
+	}
+}
+
 func (s *testPlanSuite) TestJoinReOrder(c *C) {
 	defer testleak.AfterTest(c)()
 	tests := []struct {
@@ -945,7 +1007,7 @@ func (s *testPlanSuite) TestRefine(c *C) {
 		},
 		{
 			sql:  "select a from t where c_str like 'abc'",
-			best: "Index(t.c_d_e_str)[[abc,abc]]->Projection",
+			best: "Index(t.c_d_e_str)[[ABC,abc]]->Projection",
 		},
 		{
 			sql:  "select a from t where c_str not like 'abc'",
@@ -961,39 +1023,44 @@ func (s *testPlanSuite) TestRefine(c *C) {
 		},
 		{
 			sql:  "select a from t where c_str like 'abc%'",
-			best: "Index(t.c_d_e_str)[[abc <nil>,abd <nil>)]->Projection",
+			best: "Index(t.c_d_e_str)[[ABC <nil>,abd <nil>)]->Projection",
 		},
 		{
 			sql:  "select a from t where c_str like 'abc_'",
-			best: "Index(t.c_d_e_str)[(abc +inf,abd <nil>)]->Selection->Projection",
+			best: "Index(t.c_d_e_str)[(ABC +inf,abd <nil>)]->Selection->Projection",
 		},
 		{
 			sql:  "select a from t where c_str like 'abc%af'",
-			best: "Index(t.c_d_e_str)[[abc <nil>,abd <nil>)]->Selection->Projection",
+			best: "Index(t.c_d_e_str)[[ABC <nil>,abd <nil>)]->Selection->Projection",
 		},
 		{
 			sql:  `select a from t where c_str like 'abc\\_' escape ''`,
-			best: "Index(t.c_d_e_str)[[abc_,abc_]]->Projection",
+			best: "Index(t.c_d_e_str)[[ABC_,abc_]]->Selection->Projection",
 		},
 		{
 			sql:  `select a from t where c_str like 'abc\\_'`,
-			best: "Index(t.c_d_e_str)[[abc_,abc_]]->Projection",
+			best: "Index(t.c_d_e_str)[[ABC_,abc_]]->Selection->Projection",
 		},
 		{
 			sql:  `select a from t where c_str like 'abc\\\\_'`,
-			best: "Index(t.c_d_e_str)[(abc\\ +inf,abc] <nil>)]->Selection->Projection",
+			best: "Index(t.c_d_e_str)[(ABC\\ +inf,abc] <nil>)]->Selection->Projection",
 		},
 		{
 			sql:  `select a from t where c_str like 'abc\\_%'`,
-			best: "Index(t.c_d_e_str)[[abc_ <nil>,abc` <nil>)]->Projection",
+			best: "Index(t.c_d_e_str)[[ABC_ <nil>,abc` <nil>)]->Selection->Projection",
 		},
 		{
 			sql:  `select a from t where c_str like 'abc=_%' escape '='`,
-			best: "Index(t.c_d_e_str)[[abc_ <nil>,abc` <nil>)]->Projection",
+			best: "Index(t.c_d_e_str)[[ABC_ <nil>,abc` <nil>)]->Selection->Projection",
 		},
 		{
 			sql:  `select a from t where c_str like 'abc\\__'`,
-			best: "Index(t.c_d_e_str)[(abc_ +inf,abc` <nil>)]->Selection->Projection",
+			best: "Index(t.c_d_e_str)[(ABC_ +inf,abc` <nil>)]->Selection->Projection",
+		},
+		{
+			// The range of the prefix without letters is tight, so the condition isn't needed as a filter.
+			sql:  `select a from t where c_str like '1\\%2%%'`,
+			best: "Index(t.c_d_e_str)[[1%2 <nil>,1%3 <nil>)]->Projection",
 		},
 		{
 			// Check that 123 is converted to string '123'. index can be used.
@@ -1614,6 +1681,24 @@ func (s *testPlanSuite) TestVisitInfo(c *C) {
 				{mysql.SuperPriv, "", "", ""},
 			},
 		},
+		{
+			sql: `admin show slow top 3`,
+			ans: []visitInfo{
+				{mysql.SuperPriv, "", "", ""},
+			},
+		},
+		{
+			sql: `admin reload config`,
+			ans: []visitInfo{
+				{mysql.SuperPriv, "", "", ""},
+			},
+		},
+		{
+			sql: `admin resign ddl owner`,
+			ans: []visitInfo{
+				{mysql.SuperPriv, "", "", ""},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -26,6 +27,7 @@ import (
 var (
 	_ LogicalPlan = &LogicalJoin{}
 	_ LogicalPlan = &LogicalAggregation{}
+	_ LogicalPlan = &LogicalWindow{}
 	_ LogicalPlan = &Projection{}
 	_ LogicalPlan = &Selection{}
 	_ LogicalPlan = &LogicalApply{}
@@ -80,6 +82,10 @@ type LogicalJoin struct {
 	LeftConditions  expression.CNFExprs
 	RightConditions expression.CNFExprs
 	OtherConditions expression.CNFExprs
+	// NAEQConditions are the null-aware equal conditions of a semi join built from IN, ANY or ALL subqueries.
+	// Unlike EqualConditions, a NULL argument makes the comparison unknown instead of false, and the arguments
+	// may be arbitrary expressions on the left and right child respectively.
+	NAEQConditions []*expression.ScalarFunction
 
 	// DefaultValues is only used for outer join, which stands for the default values when the outer table cannot find join partner
 	// instead of null padding.
@@ -99,6 +105,9 @@ func (p *LogicalJoin) columnSubstitute(schema *expression.Schema, exprs []expres
 	for i, fun := range p.OtherConditions {
 		p.OtherConditions[i] = expression.ColumnSubstitute(fun, schema, exprs)
 	}
+	for i, fun := range p.NAEQConditions {
+		p.NAEQConditions[i] = expression.ColumnSubstitute(fun, schema, exprs).(*expression.ScalarFunction)
+	}
 }
 
 func (p *LogicalJoin) attachOnConds(onConds []expression.Expression) {
@@ -123,6 +132,9 @@ func (p *LogicalJoin) extractCorrelatedCols() []*expression.CorrelatedColumn {
 	for _, fun := range p.OtherConditions {
 		corCols = append(corCols, extractCorColumns(fun)...)
 	}
+	for _, fun := range p.NAEQConditions {
+		corCols = append(corCols, extractCorColumns(fun)...)
+	}
 	return corCols
 }
 
@@ -204,6 +216,9 @@ type LogicalApply struct {
 	LogicalJoin
 
 	corCols []*expression.CorrelatedColumn
+	// keepOuterRows is true if the rows of the outer plan are updated or deleted,
+	// so they must not be aggregated, which drops their row keys.
+	keepOuterRows bool
 }
 
 func (p *LogicalApply) extractCorrelatedCols() []*expression.CorrelatedColumn {
@@ -255,15 +270,23 @@ type DataSource struct {
 
 	// pushedDownConds are the conditions that will be pushed down to coprocessor.
 	pushedDownConds []expression.Expression
+	// hybridConds are the conditions on enum or set columns that can only be used to build index ranges.
+	hybridConds []expression.Expression
+	// multiValuedConds are the MEMBER OF and JSON_CONTAINS conditions that can't be pushed down, which may be
+	// used to access the multi-valued indices.
+	multiValuedConds []expression.Expression
 
 	statisticTable *statistics.Table
+
+	// scanDirection is the direction of the ordered scans forced by the hints or the variable.
+	scanDirection variable.ScanDirection
 }
 
 func (p *DataSource) getPKIsHandleCol() *expression.Column {
 	if !p.tableInfo.PKIsHandle {
 		return nil
 	}
-	for i, col := range p.tableInfo.Columns {
+	for i, col := range p.Columns {
 		if mysql.HasPriKeyFlag(col.Flag) {
 			return p.schema.Columns[i]
 		}
@@ -276,6 +299,34 @@ func (p *DataSource) TableInfo() *model.TableInfo {
 	return p.tableInfo
 }
 
+// LogicalWindow represents a window plan, it appends the results of the window functions to the rows of its child.
+// All the window functions of a window have the same PARTITION BY and ORDER BY items, and the child is always sorted
+// by them.
+type LogicalWindow struct {
+	*basePlan
+	baseLogicalPlan
+
+	WindowFuncs []*expression.WindowFunc
+	PartitionBy []*ByItems
+	OrderBy     []*ByItems
+}
+
+func (p *LogicalWindow) extractCorrelatedCols() []*expression.CorrelatedColumn {
+	corCols := p.basePlan.extractCorrelatedCols()
+	for _, fun := range p.WindowFuncs {
+		for _, arg := range fun.Args {
+			corCols = append(corCols, extractCorColumns(arg)...)
+		}
+	}
+	for _, item := range p.PartitionBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	for _, item := range p.OrderBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	return corCols
+}
+
 // Union represents Union plan.
 type Union struct {
 	*basePlan
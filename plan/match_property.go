@@ -17,6 +17,7 @@ import (
 	"math"
 
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -37,7 +38,8 @@ func (ts *PhysicalTableScan) matchProperty(prop *requiredProperty, infos ...*phy
 			count:    infos[0].count,
 			reliable: infos[0].reliable})
 	}
-	if len(prop.props) == 1 && ts.pkCol != nil && ts.pkCol.Equal(prop.props[0].col, ts.ctx) {
+	if len(prop.props) == 1 && ts.pkCol != nil && ts.pkCol.Equal(prop.props[0].col, ts.ctx) &&
+		ts.canScanInOrder(prop.props[0].desc) {
 		sortedTS := ts.Copy().(*PhysicalTableScan)
 		sortedTS.Desc = prop.props[0].desc
 		sortedTS.KeepOrder = true
@@ -46,6 +48,10 @@ func (ts *PhysicalTableScan) matchProperty(prop *requiredProperty, infos ...*phy
 		if len(sortedTS.tableFilterConditions) > 0 {
 			cost += rowCount * cpuFactor
 		}
+		// A forced backward scan is always chosen.
+		if sortedTS.Desc && sortedTS.scanDirection == variable.ScanDirectionDesc {
+			cost = 0
+		}
 		p := sortedTS.tryToAddUnionScan(sortedTS)
 		return enforceProperty(&requiredProperty{limit: prop.limit}, &physicalPlanInfo{
 			p:        p,
@@ -124,7 +130,7 @@ func (is *PhysicalIndexScan) matchProperty(prop *requiredProperty, infos ...*phy
 	matchedIdx := 0
 	matchedList := make([]bool, len(prop.props))
 	for i, idxCol := range is.Index.Columns {
-		if idxCol.Length != types.UnspecifiedLength {
+		if idxCol.Length != types.UnspecifiedLength || idxCol.MultiValued != nil {
 			break
 		}
 		if idx := matchPropColumn(prop, matchedIdx, idxCol); idx >= 0 {
@@ -144,10 +150,15 @@ func (is *PhysicalIndexScan) matchProperty(prop *requiredProperty, infos ...*phy
 			}
 		}
 		sortedCost := cost + rowCount*cpuFactor
-		if allAsc || allDesc {
+		desc := allDesc && !allAsc
+		if (allAsc || allDesc) && is.canScanInOrder(desc) {
 			sortedIS := is.Copy().(*PhysicalIndexScan)
 			sortedIS.OutOfOrder = false
-			sortedIS.Desc = allDesc && !allAsc
+			sortedIS.Desc = desc
+			// A forced backward scan is always chosen.
+			if desc && is.scanDirection == variable.ScanDirectionDesc {
+				sortedCost = 0
+			}
 			sortedIS.addLimit(prop.limit)
 			p := sortedIS.tryToAddUnionScan(sortedIS)
 			return enforceProperty(&requiredProperty{limit: prop.limit}, &physicalPlanInfo{
@@ -253,7 +264,7 @@ func (p *Selection) matchProperty(prop *requiredProperty, childPlanInfo ...*phys
 		sel := p.Copy()
 		sel.SetChildren(res.p)
 		res.p = sel
-		res.count = res.count * selectionFactor
+		res.count = res.count * selectivity(p.ctx, p.Conditions)
 		return res
 	}
 	return childPlanInfo[0]
@@ -0,0 +1,129 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/types/json"
+)
+
+// splitMultiValuedIndices splits the multi-valued indices out of indices. A multi-valued index has an entry
+// for every element of the JSON arrays, so it can't be scanned like the other indices, and it's only
+// accessed by the point ranges built by multiValuedAccessValue.
+func splitMultiValuedIndices(indices []*model.IndexInfo) (normal, multiValued []*model.IndexInfo) {
+	for _, idx := range indices {
+		if idx.IsMultiValued() {
+			multiValued = append(multiValued, idx)
+		} else {
+			normal = append(normal, idx)
+		}
+	}
+	return normal, multiValued
+}
+
+// isMultiValuedAccessCond checks whether the condition is `value MEMBER OF(json)` or `JSON_CONTAINS(json, value)`,
+// which may be used to access a multi-valued index.
+func isMultiValuedAccessCond(cond expression.Expression) bool {
+	f, ok := cond.(*expression.ScalarFunction)
+	return ok && (f.FuncName.L == ast.JSONMemberOf || f.FuncName.L == ast.JSONContains)
+}
+
+// multiValuedAccessValue finds a condition like `const MEMBER OF(json)` or `JSON_CONTAINS(json, const)`, where json
+// is the key part of the multi-valued index idx, and returns the value of the index entries which all the
+// rows satisfying the condition must have. For JSON_CONTAINS, it's the first element of the candidate.
+// The condition should still be used to filter the rows.
+func multiValuedAccessValue(conds []expression.Expression, idx *model.IndexInfo) (types.Datum, bool) {
+	ic := idx.Columns[0]
+	for _, cond := range conds {
+		if !isMultiValuedAccessCond(cond) {
+			continue
+		}
+		f := cond.(*expression.ScalarFunction)
+		args := f.GetArgs()
+		var (
+			doc   expression.Expression
+			value json.JSON
+		)
+		if f.FuncName.L == ast.JSONMemberOf {
+			con, ok := args[0].(*expression.Constant)
+			if !ok || con.Value.IsNull() {
+				continue
+			}
+			doc = args[1]
+			var err error
+			if value, err = expression.ScalarDatumToJSON(con.Value); err != nil {
+				continue
+			}
+		} else {
+			con, ok := args[1].(*expression.Constant)
+			if len(args) != 2 || !ok || con.Value.IsNull() {
+				continue
+			}
+			doc = args[0]
+			candidate, err := expression.DatumToJSON(con.Value)
+			if err != nil {
+				continue
+			}
+			elems := json.Elements(candidate)
+			if len(elems) == 0 {
+				continue
+			}
+			value = elems[0]
+		}
+		if !matchMultiValuedKeyPart(doc, ic) {
+			continue
+		}
+		// A value which can't be converted to the type of the key part is never indexed.
+		d, err := table.MultiValuedValue(value, ic.MultiValued)
+		if err != nil {
+			continue
+		}
+		return d, true
+	}
+	return types.Datum{}, false
+}
+
+// matchMultiValuedKeyPart checks whether the expression is the JSON document indexed by the key part, which is
+// the column itself or JSON_EXTRACT(column, path).
+func matchMultiValuedKeyPart(expr expression.Expression, ic *model.IndexColumn) bool {
+	keyPath, err := json.ParseJSONPathExpr(ic.MultiValued.Path)
+	if err != nil {
+		return false
+	}
+	path, _ := json.ParseJSONPathExpr("$")
+	if f, ok := expr.(*expression.ScalarFunction); ok {
+		args := f.GetArgs()
+		if f.FuncName.L != ast.JSONExtract || len(args) != 2 {
+			return false
+		}
+		con, ok := args[1].(*expression.Constant)
+		if !ok || con.Value.IsNull() {
+			return false
+		}
+		s, err := con.Value.ToString()
+		if err != nil {
+			return false
+		}
+		if path, err = json.ParseJSONPathExpr(s); err != nil {
+			return false
+		}
+		expr = args[0]
+	}
+	col, ok := expr.(*expression.Column)
+	return ok && col.ColName.L == ic.Name.L && path.Equal(keyPath)
+}
@@ -17,12 +17,14 @@ import (
 	"math"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/ranger"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -144,7 +146,8 @@ func joinKeysMatchIndex(keys []*expression.Column, index *model.IndexInfo) []int
 // convertToIndexJoin will generate index join by required properties and outerIndex. OuterIdx points out the outer child,
 // because we will swap the children of join when the right child is outer child.
 // First of all, we will extract the join keys for p's equal conditions. If the join keys can match some of the indices or pk
-// column of inner child, we can apply the index join. Then we convert the inner child to table scan or index scan explicitly.
+// column of inner child, we can apply the index join. Then we convert the inner child to table scan or index scan explicitly,
+// whose ranges are built from the join keys of the outer rows by the executor.
 func (p *LogicalJoin) convertToIndexJoin(prop *requiredProp, outerIdx int) (taskProfile, error) {
 	outerChild := p.children[outerIdx].(LogicalPlan)
 	innerChild := p.children[1-outerIdx].(LogicalPlan)
@@ -156,17 +159,21 @@ func (p *LogicalJoin) convertToIndexJoin(prop *requiredProp, outerIdx int) (task
 		rightConds    expression.CNFExprs
 		leftConds     expression.CNFExprs
 		innerTask     taskProfile
+		rowsPerKey    float64
 		err           error
 		innerJoinKeys = make([]*expression.Column, 0, len(p.EqualConditions))
 		outerJoinKeys = make([]*expression.Column, 0, len(p.EqualConditions))
 	)
-	if canPassProp {
-		outerTask, err = outerChild.convert2NewPhysicalPlan(prop)
-	} else {
-		outerTask, err = outerChild.convert2NewPhysicalPlan(&requiredProp{taskTp: rootTaskType})
-	}
-	if err != nil {
-		return nil, errors.Trace(err)
+	for _, cond := range p.EqualConditions {
+		outerKey := cond.GetArgs()[outerIdx].(*expression.Column)
+		innerKey := cond.GetArgs()[1-outerIdx].(*expression.Column)
+		// The inner rows are looked up by the outer keys converted to the types of the inner keys, which may not
+		// be equivalent to the comparison of the join if the types are different.
+		if !compareTypeForOrder(outerKey.RetType, innerKey.RetType) {
+			return nil, nil
+		}
+		outerJoinKeys = append(outerJoinKeys, outerKey.Clone().(*expression.Column))
+		innerJoinKeys = append(innerJoinKeys, innerKey.Clone().(*expression.Column))
 	}
 	if outerIdx == 0 {
 		rightConds = p.RightConditions.Clone()
@@ -178,11 +185,11 @@ func (p *LogicalJoin) convertToIndexJoin(prop *requiredProp, outerIdx int) (task
 	for {
 		switch x := innerChild.(type) {
 		case *DataSource:
-			indices, includeTableScan := availableIndices(x.indexHints, x.tableInfo)
-			for _, cond := range p.EqualConditions {
-				innerJoinKeys = append(innerJoinKeys, cond.GetArgs()[1-outerIdx].(*expression.Column))
-				outerJoinKeys = append(outerJoinKeys, cond.GetArgs()[outerIdx].(*expression.Column))
+			if x.isMemTable() {
+				return nil, nil
 			}
+			indices, includeTableScan := availableIndices(x.indexHints, x.tableInfo)
+			indices, _ = splitMultiValuedIndices(indices)
 			if includeTableScan {
 				if len(innerJoinKeys) == 1 {
 					pkCol := x.getPKIsHandleCol()
@@ -192,7 +199,8 @@ func (p *LogicalJoin) convertToIndexJoin(prop *requiredProp, outerIdx int) (task
 				}
 			}
 			if useTableScan {
-				innerTask, err = x.convertToTableScan(&requiredProp{taskTp: rootTaskType})
+				rowsPerKey = 1
+				innerTask, err = x.convertToIndexJoinInnerTask(nil, rowsPerKey)
 				if err != nil {
 					return nil, errors.Trace(err)
 				}
@@ -213,7 +221,12 @@ func (p *LogicalJoin) convertToIndexJoin(prop *requiredProp, outerIdx int) (task
 				}
 			}
 			if usedIndexInfo != nil {
-				innerTask, err = x.convertToIndexScan(&requiredProp{taskTp: rootTaskType}, usedIndexInfo)
+				if usedIndexInfo.Unique && len(innerJoinKeys) == len(usedIndexInfo.Columns) {
+					rowsPerKey = 1
+				} else {
+					rowsPerKey = x.statisticTable.EqualRowCountPerKey(usedIndexInfo.ID, len(innerJoinKeys))
+				}
+				innerTask, err = x.convertToIndexJoinInnerTask(usedIndexInfo, rowsPerKey)
 				if err != nil {
 					return nil, errors.Trace(err)
 				}
@@ -230,14 +243,25 @@ func (p *LogicalJoin) convertToIndexJoin(prop *requiredProp, outerIdx int) (task
 			break
 		}
 	}
+	if canPassProp {
+		outerTask, err = outerChild.convert2NewPhysicalPlan(prop)
+	} else {
+		outerTask, err = outerChild.convert2NewPhysicalPlan(&requiredProp{taskTp: rootTaskType})
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	join := PhysicalIndexJoin{
 		LeftConditions:  leftConds,
 		RightConditions: rightConds,
-		OtherConditions: p.OtherConditions,
-		outer:           p.JoinType != InnerJoin,
-		outerJoinKeys:   outerJoinKeys,
-		innerJoinKeys:   innerJoinKeys,
+		OtherConditions: p.OtherConditions.Clone(),
+		Outer:           p.JoinType != InnerJoin,
+		OuterIndex:      outerIdx,
+		OuterJoinKeys:   outerJoinKeys,
+		InnerJoinKeys:   innerJoinKeys,
+		DefaultValues:   p.DefaultValues,
 	}.init(p.allocator, p.ctx, p.children[outerIdx], p.children[1-outerIdx])
+	join.SetSchema(p.schema)
 	task := join.attach2TaskProfile(outerTask, innerTask)
 	if !canPassProp {
 		task = prop.enforceProperty(task, p.ctx, p.allocator)
@@ -245,8 +269,71 @@ func (p *LogicalJoin) convertToIndexJoin(prop *requiredProp, outerIdx int) (task
 	return task, nil
 }
 
-// tryToGetIndexJoin tries to get index join plan. If fails, it returns nil.
-// Currently we only check by hint. If we prefer the left index join but the join type is right outer, it will fail to return.
+// convertToIndexJoinInnerTask converts the DataSource to the inner child of an index join, which is a table scan if
+// idx is nil, or an index scan of idx otherwise. The ranges are full here, the executor replaces them with the ranges
+// of the outer join keys, so all the pushed down conditions are kept as filters. The cost and count of the returned
+// task are the ones of looking up the rows of a single key, which has rowsPerKey rows on average.
+func (p *DataSource) convertToIndexJoinInnerTask(idx *model.IndexInfo, rowsPerKey float64) (taskProfile, error) {
+	conds := make([]expression.Expression, 0, len(p.pushedDownConds))
+	for _, cond := range p.pushedDownConds {
+		conds = append(conds, cond.Clone())
+	}
+	copTask := &copTaskProfile{
+		cnt: rowsPerKey,
+		cst: rowsPerKey * scanFactor,
+	}
+	if idx == nil {
+		ts := PhysicalTableScan{
+			Table:       p.tableInfo,
+			Columns:     p.Columns,
+			TableAsName: p.TableAsName,
+			DBName:      p.DBName,
+		}.init(p.allocator, p.ctx)
+		ts.SetSchema(p.schema)
+		ts.Ranges = []types.IntColumnRange{{LowVal: math.MinInt64, HighVal: math.MaxInt64}}
+		ts.filterCondition = conds
+		copTask.tablePlan = ts
+		copTask.indexPlanFinished = true
+		ts.addPushedDownSelection(copTask)
+	} else {
+		is := PhysicalIndexScan{
+			Table:            p.tableInfo,
+			TableAsName:      p.TableAsName,
+			DBName:           p.DBName,
+			Columns:          p.Columns,
+			Index:            idx,
+			OutOfOrder:       true,
+			dataSourceSchema: p.schema,
+		}.init(p.allocator, p.ctx)
+		rb := ranger.Builder{Sc: p.ctx.GetSessionVars().StmtCtx}
+		is.Ranges = rb.BuildIndexRanges(ranger.FullRange, types.NewFieldType(mysql.TypeNull))
+		is.filterCondition = conds
+		copTask.indexPlan = is
+		if !isCoveringIndex(is.Columns, is.Index.Columns, is.Table.PKIsHandle) {
+			copTask.tablePlan = PhysicalTableScan{Columns: p.Columns, Table: is.Table}.init(p.allocator, p.ctx)
+			copTask.tablePlan.SetSchema(p.schema)
+		}
+		var indexCols []*expression.Column
+		for _, col := range idx.Columns {
+			indexCols = append(indexCols, &expression.Column{FromID: p.id, Position: col.Offset})
+		}
+		if is.Table.PKIsHandle {
+			for _, col := range is.Columns {
+				if mysql.HasPriKeyFlag(col.Flag) {
+					indexCols = append(indexCols, &expression.Column{FromID: p.id, Position: col.Offset})
+					break
+				}
+			}
+		}
+		is.SetSchema(expression.NewSchema(indexCols...))
+		is.addPushedDownSelection(copTask)
+	}
+	task := tryToAddUnionScan(copTask, p.pushedDownConds, p.ctx, p.allocator)
+	return finishCopTask(task, p.ctx, p.allocator), nil
+}
+
+// tryToGetIndexJoin tries to get index join plan by the hint. If fails, it returns nil.
+// If we prefer the left index join but the join type is right outer, it will fail to return.
 func (p *LogicalJoin) tryToGetIndexJoin(prop *requiredProp) (taskProfile, error) {
 	if len(p.EqualConditions) == 0 {
 		return nil, nil
@@ -269,8 +356,34 @@ func (p *LogicalJoin) tryToGetIndexJoin(prop *requiredProp) (taskProfile, error)
 	return nil, nil
 }
 
+// convert2JoinOnCost chooses the cheapest one among the hash join and the index joins that take either child as the
+// outer child, which is allowed by the join type.
+func (p *LogicalJoin) convert2JoinOnCost(prop *requiredProp) (taskProfile, error) {
+	task, err := p.convert2HashJoin(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(p.EqualConditions) == 0 {
+		return task, nil
+	}
+	for outerIdx := 0; outerIdx < 2; outerIdx++ {
+		if (outerIdx == 0 && p.JoinType == RightOuterJoin) || (outerIdx == 1 && p.JoinType == LeftOuterJoin) {
+			continue
+		}
+		idxJoinTask, err := p.convertToIndexJoin(prop, outerIdx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if idxJoinTask != nil && idxJoinTask.cost() < task.cost() {
+			task = idxJoinTask
+		}
+	}
+	return task, nil
+}
+
 // convert2NewPhysicalPlan implements PhysicalPlan interface.
-// Join has three physical operators: Hash Join, Merge Join and Index Look Up Join. We implement Hash Join at first.
+// Join has three physical operators: Hash Join, Merge Join and Index Look Up Join. The merge join and the index join
+// are chosen by the hints, otherwise the cheaper one of the hash join and the index join is chosen.
 func (p *LogicalJoin) convert2NewPhysicalPlan(prop *requiredProp) (taskProfile, error) {
 	task, err := p.getTaskProfile(prop)
 	if err != nil {
@@ -290,7 +403,7 @@ func (p *LogicalJoin) convert2NewPhysicalPlan(prop *requiredProp) (taskProfile,
 		if p.preferUseMergeJoin() {
 			task, err = p.convert2MergeJoin(prop)
 		} else if task, err = p.tryToGetIndexJoin(prop); task == nil && err == nil {
-			task, err = p.convert2HashJoin(prop)
+			task, err = p.convert2JoinOnCost(prop)
 		}
 	}
 	if err != nil {
@@ -349,6 +462,7 @@ func (p *LogicalJoin) convert2SemiJoin(prop *requiredProp) (taskProfile, error)
 		LeftConditions:  p.LeftConditions,
 		RightConditions: p.RightConditions,
 		OtherConditions: p.OtherConditions,
+		NAEQConditions:  p.NAEQConditions,
 		Anti:            p.anti,
 	}.init(p.allocator, p.ctx)
 	semiJoin.SetSchema(p.schema)
@@ -461,6 +575,34 @@ func (p *Sort) convert2NewPhysicalPlan(prop *requiredProp) (taskProfile, error)
 	return task, p.storeTaskProfile(prop, task)
 }
 
+// convert2NewPhysicalPlan implements LogicalPlan interface.
+func (p *LogicalWindow) convert2NewPhysicalPlan(prop *requiredProp) (taskProfile, error) {
+	task, err := p.getTaskProfile(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if task != nil {
+		return task, nil
+	}
+	if prop.taskTp != rootTaskType {
+		return invalidTask, p.storeTaskProfile(prop, invalidTask)
+	}
+	// The child is sorted by the partition by and order by items, so the required property is enforced above the window.
+	task, err = p.children[0].(LogicalPlan).convert2NewPhysicalPlan(&requiredProp{taskTp: rootTaskType})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	window := PhysicalWindow{
+		WindowFuncs: p.WindowFuncs,
+		PartitionBy: p.PartitionBy,
+		OrderBy:     p.OrderBy,
+	}.init(p.allocator, p.ctx)
+	window.SetSchema(p.schema)
+	task = window.attach2TaskProfile(task)
+	task = prop.enforceProperty(task, p.ctx, p.allocator)
+	return task, p.storeTaskProfile(prop, task)
+}
+
 // convert2NewPhysicalPlan implements LogicalPlan interface.
 func (p *TopN) convert2NewPhysicalPlan(prop *requiredProp) (taskProfile, error) {
 	task, err := p.getTaskProfile(prop)
@@ -576,12 +718,16 @@ func tryToAddUnionScan(cop *copTaskProfile, conds []expression.Expression, ctx c
 	return us.attach2TaskProfile(task)
 }
 
-// tryToGetMemTask will check if this table is a mem table. If it is, it will produce a task and store it.
-func (p *DataSource) tryToGetMemTask(prop *requiredProp) (task taskProfile, err error) {
+// isMemTable checks if this table is a mem table, which can't be read by the coprocessor requests.
+func (p *DataSource) isMemTable() bool {
 	client := p.ctx.GetClient()
 	memDB := infoschema.IsMemoryDB(p.DBName.L)
-	isDistReq := !memDB && client != nil && client.IsRequestTypeSupported(kv.ReqTypeSelect, 0)
-	if isDistReq {
+	return memDB || client == nil || !client.IsRequestTypeSupported(kv.ReqTypeSelect, 0)
+}
+
+// tryToGetMemTask will check if this table is a mem table. If it is, it will produce a task and store it.
+func (p *DataSource) tryToGetMemTask(prop *requiredProp) (task taskProfile, err error) {
+	if !p.isMemTable() {
 		return nil, nil
 	}
 	memTable := PhysicalMemTable{
@@ -653,6 +799,7 @@ func (p *DataSource) convert2NewPhysicalPlan(prop *requiredProp) (taskProfile, e
 	}
 	// TODO: We have not checked if this table has a predicate. If not, we can only consider table scan.
 	indices, includeTableScan := availableIndices(p.indexHints, p.tableInfo)
+	indices, mvIndices := splitMultiValuedIndices(indices)
 	if includeTableScan {
 		task, err = p.convertToTableScan(prop)
 		if err != nil {
@@ -668,9 +815,89 @@ func (p *DataSource) convert2NewPhysicalPlan(prop *requiredProp) (taskProfile, e
 			task = idxTask
 		}
 	}
+	for _, idx := range mvIndices {
+		idxTask, err := p.convertToMultiValuedIndexScan(prop, idx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if idxTask != nil && (task == nil || idxTask.cost() < task.cost()) {
+			task = idxTask
+		}
+	}
+	if task == nil {
+		// Only the multi-valued indices are available but none of them can be accessed.
+		task, err = p.convertToTableScan(prop)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	return task, p.storeTaskProfile(prop, task)
 }
 
+// convertToMultiValuedIndexScan converts the DataSource to the double read of the multi-valued index idx, with the
+// point range built from multiValuedConds. The condition is still evaluated by the selection above, as it's not
+// equivalent to the range. It returns nil if there is no such condition.
+func (p *DataSource) convertToMultiValuedIndexScan(prop *requiredProp, idx *model.IndexInfo) (taskProfile, error) {
+	value, ok := multiValuedAccessValue(p.multiValuedConds, idx)
+	if !ok {
+		return nil, nil
+	}
+	if prop.taskTp == copSingleReadTaskType {
+		return &copTaskProfile{cst: math.MaxFloat64}, nil
+	}
+	is := PhysicalIndexScan{
+		Table:            p.tableInfo,
+		TableAsName:      p.TableAsName,
+		DBName:           p.DBName,
+		Columns:          p.Columns,
+		Index:            idx,
+		OutOfOrder:       true,
+		dataSourceSchema: p.schema,
+	}.init(p.allocator, p.ctx)
+	is.Ranges = []*types.IndexRange{{LowVal: []types.Datum{value}, HighVal: []types.Datum{value}}}
+	indexCols := []*expression.Column{{FromID: p.id, Position: idx.Columns[0].Offset}}
+	if is.Table.PKIsHandle {
+		for _, col := range is.Columns {
+			if mysql.HasPriKeyFlag(col.Flag) {
+				indexCols = append(indexCols, &expression.Column{FromID: p.id, Position: col.Offset})
+				break
+			}
+		}
+	}
+	is.SetSchema(expression.NewSchema(indexCols...))
+	rowCount, err := p.statisticTable.GetRowCountByIndexRanges(p.ctx.GetSessionVars().StmtCtx, idx.ID, is.Ranges, 1)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	copTask := &copTaskProfile{
+		cnt:       rowCount,
+		cst:       rowCount * scanFactor,
+		indexPlan: is,
+	}
+	// The index entries are the elements of the JSON values, so all the conditions are evaluated on the rows.
+	copTask.tablePlan = PhysicalTableScan{Columns: p.Columns, Table: is.Table}.init(p.allocator, p.ctx)
+	copTask.tablePlan.SetSchema(p.schema)
+	if len(p.pushedDownConds) > 0 {
+		conds := make([]expression.Expression, 0, len(p.pushedDownConds))
+		for _, cond := range p.pushedDownConds {
+			conds = append(conds, cond.Clone())
+		}
+		copTask.finishIndexPlan()
+		tableSel := Selection{Conditions: conds}.init(p.allocator, p.ctx)
+		tableSel.SetSchema(copTask.tablePlan.Schema())
+		tableSel.SetChildren(copTask.tablePlan)
+		copTask.tablePlan = tableSel
+		copTask.cst += copTask.cnt * cpuFactor
+		copTask.cnt = copTask.cnt * selectivity(p.ctx, conds)
+	}
+	task := tryToAddUnionScan(copTask, p.pushedDownConds, p.ctx, p.allocator)
+	task = prop.enforceProperty(task, p.ctx, p.allocator)
+	if prop.taskTp == rootTaskType {
+		task = finishCopTask(task, p.ctx, p.allocator)
+	}
+	return task, nil
+}
+
 // convertToIndexScan converts the DataSource to index scan with idx.
 func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo) (task taskProfile, err error) {
 	is := PhysicalIndexScan{
@@ -684,12 +911,17 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	statsTbl := p.statisticTable
 	rowCount := float64(statsTbl.Count)
 	sc := p.ctx.GetSessionVars().StmtCtx
-	if len(p.pushedDownConds) > 0 {
-		conds := make([]expression.Expression, 0, len(p.pushedDownConds))
+	if len(p.pushedDownConds) > 0 || len(p.hybridConds) > 0 {
+		conds := make([]expression.Expression, 0, len(p.pushedDownConds)+len(p.hybridConds))
 		for _, cond := range p.pushedDownConds {
 			conds = append(conds, cond.Clone())
 		}
+		for _, cond := range p.hybridConds {
+			conds = append(conds, cond.Clone())
+		}
 		is.AccessCondition, is.filterCondition, is.accessEqualCount, is.accessInAndEqCount = ranger.DetachIndexScanConditions(conds, idx)
+		// The hybrid conditions are still kept in the selection above, so they can't be pushed down as filters.
+		is.filterCondition = removeHybridConds(is.filterCondition)
 		is.Ranges, err = ranger.BuildIndexRange(sc, is.Table, is.Index, is.accessInAndEqCount, is.AccessCondition)
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -746,10 +978,10 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 			}
 		}
 	}
-	if matchProperty && !prop.isEmpty() {
+	if matchProperty && !prop.isEmpty() && p.canScanInOrder(prop) {
 		if prop.desc {
 			is.Desc = true
-			copTask.cst = rowCount * descScanFactor
+			copTask.cst = p.descScanCost(rowCount)
 		}
 		is.addPushedDownSelection(copTask)
 		task = tryToAddUnionScan(copTask, p.pushedDownConds, p.ctx, p.allocator)
@@ -765,12 +997,23 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	return task, nil
 }
 
+// removeHybridConds removes the conditions on the enum and set columns that can't be pushed down.
+func removeHybridConds(conds []expression.Expression) []expression.Expression {
+	ret := conds[:0]
+	for _, cond := range conds {
+		if !isHybridRangeCond(cond) {
+			ret = append(ret, cond)
+		}
+	}
+	return ret
+}
+
 func (is *PhysicalIndexScan) addPushedDownSelection(copTask *copTaskProfile) {
 	// Add filter condition to table plan now.
 	if len(is.filterCondition) > 0 {
 		var indexConds, tableConds []expression.Expression
 		if copTask.tablePlan != nil {
-			tableConds, indexConds = splitConditionsByIndexColumns(is.filterCondition, is.schema)
+			tableConds, indexConds = splitConditionsByIndexColumns(is.filterCondition, is.fullIndexColumnSchema())
 		} else {
 			indexConds = is.filterCondition
 		}
@@ -780,7 +1023,7 @@ func (is *PhysicalIndexScan) addPushedDownSelection(copTask *copTaskProfile) {
 			indexSel.SetChildren(is)
 			copTask.indexPlan = indexSel
 			copTask.cst += copTask.cnt * cpuFactor
-			copTask.cnt = copTask.cnt * selectionFactor
+			copTask.cnt = copTask.cnt * selectivity(is.ctx, indexConds)
 		}
 		if tableConds != nil {
 			copTask.finishIndexPlan()
@@ -789,7 +1032,7 @@ func (is *PhysicalIndexScan) addPushedDownSelection(copTask *copTaskProfile) {
 			tableSel.SetChildren(copTask.tablePlan)
 			copTask.tablePlan = tableSel
 			copTask.cst += copTask.cnt * cpuFactor
-			copTask.cnt = copTask.cnt * selectionFactor
+			copTask.cnt = copTask.cnt * selectivity(is.ctx, tableConds)
 		}
 	}
 }
@@ -806,6 +1049,21 @@ func matchIndicesProp(idxCols []*model.IndexColumn, propCols []*expression.Colum
 	return true
 }
 
+// canScanInOrder checks whether the rows can be read in the order of prop, a backward scan is forbidden if the
+// ascending direction is forced.
+func (p *DataSource) canScanInOrder(prop *requiredProp) bool {
+	return !prop.desc || p.scanDirection != variable.ScanDirectionAsc
+}
+
+// descScanCost returns the cost of scanning rowCount rows backward. A forced backward scan costs nothing so that
+// it's always chosen instead of sorting the rows.
+func (p *DataSource) descScanCost(rowCount float64) float64 {
+	if p.scanDirection == variable.ScanDirectionDesc {
+		return 0
+	}
+	return rowCount * descScanFactor
+}
+
 // convertToTableScan converts the DataSource to table scan.
 func (p *DataSource) convertToTableScan(prop *requiredProp) (task taskProfile, err error) {
 	if prop.taskTp == copDoubleReadTaskType {
@@ -857,10 +1115,10 @@ func (p *DataSource) convertToTableScan(prop *requiredProp) (task taskProfile, e
 		indexPlanFinished: true,
 	}
 	task = copTask
-	if pkCol != nil && len(prop.cols) == 1 && prop.cols[0].Equal(pkCol, nil) {
+	if pkCol != nil && len(prop.cols) == 1 && prop.cols[0].Equal(pkCol, nil) && p.canScanInOrder(prop) {
 		if prop.desc {
 			ts.Desc = true
-			copTask.cst = rowCount * descScanFactor
+			copTask.cst = p.descScanCost(rowCount)
 		}
 		ts.KeepOrder = true
 		ts.addPushedDownSelection(copTask)
@@ -911,10 +1169,23 @@ func (ts *PhysicalTableScan) addPushedDownSelection(copTask *copTaskProfile) {
 		sel.SetChildren(ts)
 		copTask.tablePlan = sel
 		copTask.cst += copTask.cnt * cpuFactor
-		copTask.cnt = copTask.cnt * selectionFactor
+		copTask.cnt = copTask.cnt * selectivity(ts.ctx, ts.filterCondition)
 	}
 }
 
+// fullIndexColumnSchema returns the schema of the index columns except the prefix indexed ones, the values of
+// the prefix indexed columns are truncated in the index, so the conditions on them can only be evaluated on the table.
+func (is *PhysicalIndexScan) fullIndexColumnSchema() *expression.Schema {
+	cols := make([]*expression.Column, 0, len(is.schema.Columns))
+	for i, col := range is.schema.Columns {
+		if i < len(is.Index.Columns) && is.Index.Columns[i].Length != types.UnspecifiedLength {
+			continue
+		}
+		cols = append(cols, col)
+	}
+	return expression.NewSchema(cols...)
+}
+
 // splitConditionsByIndexColumns splits the conditions by index schema. If some condition only contain the index
 // columns, it will be pushed to index plan.
 func splitConditionsByIndexColumns(conditions []expression.Expression, schema *expression.Schema) (tableConds []expression.Expression, indexConds []expression.Expression) {
@@ -946,9 +1217,66 @@ func (p *LogicalAggregation) convert2NewPhysicalPlan(prop *requiredProp) (taskPr
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	streamTask, err := p.convert2StreamAggregation(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if streamTask != nil && streamTask.cost() < task.cost() {
+		task = streamTask
+	}
 	return task, p.storeTaskProfile(prop, task)
 }
 
+// convert2StreamAggregation converts the aggregation to the stream aggregation, which requires its child to be
+// sorted by the group by columns and returns a group as soon as all the rows of it are read. It returns nil if
+// there is no group by item or any group by item isn't a column.
+func (p *LogicalAggregation) convert2StreamAggregation(prop *requiredProp) (bestTask taskProfile, _ error) {
+	if len(p.GroupByItems) == 0 {
+		return nil, nil
+	}
+	for _, aggFunc := range p.AggFuncs {
+		if aggFunc.GetMode() == expression.FinalMode {
+			return nil, nil
+		}
+	}
+	gbyCols := make([]*expression.Column, 0, len(p.GroupByItems))
+	for _, item := range p.GroupByItems {
+		col, ok := item.(*expression.Column)
+		if !ok {
+			return nil, nil
+		}
+		gbyCols = append(gbyCols, col)
+	}
+	// The output is in the order of the group by columns, so the required property is passed to the child if it's
+	// satisfied by a prefix of them.
+	matchProp := p.matchGbyColsPrefix(prop, gbyCols)
+	for _, taskTp := range wholeTaskTypes {
+		childProp := &requiredProp{cols: gbyCols, taskTp: taskTp}
+		if matchProp {
+			childProp.desc = prop.desc
+		}
+		task, err := p.children[0].(LogicalPlan).convert2NewPhysicalPlan(childProp)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		sa := PhysicalAggregation{
+			GroupByItems: p.GroupByItems,
+			AggFuncs:     p.AggFuncs,
+			HasGby:       true,
+			AggType:      StreamedAgg,
+		}.init(p.allocator, p.ctx)
+		sa.SetSchema(p.schema)
+		task = sa.attach2TaskProfile(task)
+		if !matchProp {
+			task = prop.enforceProperty(task, p.ctx, p.allocator)
+		}
+		if bestTask == nil || task.cost() < bestTask.cost() {
+			bestTask = task
+		}
+	}
+	return
+}
+
 func (p *LogicalAggregation) convert2HashAggregation(prop *requiredProp) (bestTask taskProfile, _ error) {
 	for _, taskTp := range wholeTaskTypes {
 		task, err := p.children[0].(LogicalPlan).convert2NewPhysicalPlan(&requiredProp{taskTp: taskTp})
@@ -971,6 +1299,28 @@ func (p *LogicalAggregation) convert2HashAggregation(prop *requiredProp) (bestTa
 	return
 }
 
+// matchGbyColsPrefix checks if the columns of prop are the first rows of a prefix of gbyCols in order.
+func (p *LogicalAggregation) matchGbyColsPrefix(prop *requiredProp, gbyCols []*expression.Column) bool {
+	if len(prop.cols) > len(gbyCols) {
+		return false
+	}
+	for i, col := range prop.cols {
+		idx := p.schema.ColumnIndex(col)
+		if idx == -1 {
+			return false
+		}
+		aggFunc := p.AggFuncs[idx]
+		if aggFunc.GetName() != ast.AggFuncFirstRow {
+			return false
+		}
+		arg, ok := aggFunc.GetArgs()[0].(*expression.Column)
+		if !ok || !arg.Equal(gbyCols[i], nil) {
+			return false
+		}
+	}
+	return true
+}
+
 func (p *LogicalApply) convert2NewPhysicalPlan(prop *requiredProp) (taskProfile, error) {
 	task, err := p.getTaskProfile(prop)
 	if err != nil {
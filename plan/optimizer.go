@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 )
 
@@ -53,9 +54,27 @@ type logicalOptRule interface {
 // Optimize does optimization and creates a Plan.
 // The node must be prepared first.
 func Optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Plan, error) {
+	p, _, err := OptimizeWithAccessInfo(ctx, node, is)
+	return p, errors.Trace(err)
+}
+
+// AccessInfo is the databases, tables and columns accessed by a statement with the required privileges,
+// it's collected when the plan is built.
+type AccessInfo []visitInfo
+
+// OptimizeWithAccessInfo is like Optimize, it also returns the access info of the statement, so the privileges
+// can be checked by CheckAccess when the plan is reused without being optimized again.
+func OptimizeWithAccessInfo(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Plan, AccessInfo, error) {
+	if err := CheckReadOnly(ctx, node); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return optimize(ctx, node, is)
+}
+
+func optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Plan, AccessInfo, error) {
 	// We have to infer type again because after parameter is set, the expression type may change.
 	if err := expression.InferType(ctx.GetSessionVars().StmtCtx, node); err != nil {
-		return nil, errors.Trace(err)
+		return nil, nil, errors.Trace(err)
 	}
 	allocator := new(idAllocator)
 	builder := &planBuilder{
@@ -66,21 +85,27 @@ func Optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Pla
 	}
 	p := builder.build(node)
 	if builder.err != nil {
-		return nil, errors.Trace(builder.err)
+		return nil, nil, errors.Trace(builder.err)
 	}
 
 	// Maybe it's better to move this to Preprocess, but check privilege need table
 	// information, which is collected into visitInfo during logical plan builder.
-	if pm := privilege.GetPrivilegeManager(ctx); pm != nil {
-		if !checkPrivilege(pm, builder.visitInfo) {
-			return nil, errors.New("privilege check fail")
-		}
+	access := AccessInfo(builder.visitInfo)
+	if err := CheckAccess(ctx, access); err != nil {
+		return nil, nil, errors.Trace(err)
 	}
 
+	if pp := tryPointMutation(p); pp != nil {
+		return pp, access, nil
+	}
+	if pp := tryPointGet(p); pp != nil {
+		return pp, access, nil
+	}
 	if logic, ok := p.(LogicalPlan); ok {
-		return doOptimize(builder.optFlag, logic, ctx, allocator)
+		p, err := doOptimize(builder.optFlag, logic, ctx, allocator)
+		return p, access, errors.Trace(err)
 	}
-	return p, nil
+	return p, access, nil
 }
 
 // BuildLogicalPlan is exported and only used for test.
@@ -102,6 +127,16 @@ func BuildLogicalPlan(ctx context.Context, node ast.Node, is infoschema.InfoSche
 	return p, nil
 }
 
+// CheckAccess checks whether the user of the session has the privileges required by the access info.
+func CheckAccess(ctx context.Context, access AccessInfo) error {
+	if pm := privilege.GetPrivilegeManager(ctx); pm != nil {
+		if !checkPrivilege(pm, access) {
+			return errors.New("privilege check fail")
+		}
+	}
+	return nil
+}
+
 func checkPrivilege(pm privilege.Manager, vs []visitInfo) bool {
 	for _, v := range vs {
 		if !pm.RequestVerification(v.db, v.table, v.column, v.privilege) {
@@ -111,6 +146,37 @@ func checkPrivilege(pm privilege.Manager, vs []visitInfo) bool {
 	return true
 }
 
+// CheckReadOnly refuses the write statements if the server is read-only, the users with the SUPER privilege
+// can still write unless super_read_only is on. The internal statements are not checked.
+func CheckReadOnly(ctx context.Context, node ast.Node) error {
+	if !variable.IsReadOnly() || ctx.GetSessionVars().InRestrictedSQL || !isWriteStmt(node) {
+		return nil
+	}
+	if variable.IsSuperReadOnly() {
+		return ErrReadOnly.GenByArgs("--super-read-only")
+	}
+	if pm := privilege.GetPrivilegeManager(ctx); pm != nil && !pm.RequestVerification("", "", "", mysql.SuperPriv) {
+		return ErrReadOnly.GenByArgs("--read-only")
+	}
+	return nil
+}
+
+// isWriteStmt checks whether the statement writes the data, the schemas or the privileges.
+func isWriteStmt(node ast.Node) bool {
+	switch x := node.(type) {
+	case *ast.InsertStmt, *ast.UpdateStmt, *ast.DeleteStmt, *ast.LoadDataStmt,
+		*ast.CreateUserStmt, *ast.AlterUserStmt, *ast.DropUserStmt, *ast.SetPwdStmt, *ast.GrantStmt, *ast.RevokeStmt,
+		*ast.CreateEventStmt, *ast.AlterEventStmt, *ast.DropEventStmt, ast.DDLNode:
+		return true
+	case *ast.ExplainStmt:
+		// 'explain analyze' executes the statement.
+		return x.Analyze && isWriteStmt(x.Stmt)
+	case *ast.TraceStmt:
+		return isWriteStmt(x.Stmt)
+	}
+	return false
+}
+
 func doOptimize(flag uint64, logic LogicalPlan, ctx context.Context, allocator *idAllocator) (PhysicalPlan, error) {
 	logic, err := logicalOptimize(flag, logic, ctx, allocator)
 	if err != nil {
@@ -198,6 +264,8 @@ const (
 	CodeUnsupported         terror.ErrCode = 4
 	CodeInvalidGroupFuncUse terror.ErrCode = 5
 	CodeIllegalReference    terror.ErrCode = 6
+	CodeReadOnly            terror.ErrCode = 7
+	CodeNoSuchThread        terror.ErrCode = 8
 )
 
 // Optimizer base errors.
@@ -207,6 +275,8 @@ var (
 	ErrCartesianProductUnsupported = terror.ClassOptimizer.New(CodeUnsupported, "Cartesian product is unsupported")
 	ErrInvalidGroupFuncUse         = terror.ClassOptimizer.New(CodeInvalidGroupFuncUse, "Invalid use of group function")
 	ErrIllegalReference            = terror.ClassOptimizer.New(CodeIllegalReference, "Illegal reference")
+	ErrReadOnly                    = terror.ClassOptimizer.New(CodeReadOnly, mysql.MySQLErrName[mysql.ErrOptionPreventsStatement])
+	ErrNoSuchThread                = terror.ClassOptimizer.New(CodeNoSuchThread, "Unknown thread id: %d")
 )
 
 func init() {
@@ -215,6 +285,8 @@ func init() {
 		CodeInvalidWildCard:     mysql.ErrParse,
 		CodeInvalidGroupFuncUse: mysql.ErrInvalidGroupFuncUse,
 		CodeIllegalReference:    mysql.ErrIllegalReference,
+		CodeReadOnly:            mysql.ErrOptionPreventsStatement,
+		CodeNoSuchThread:        mysql.ErrNoSuchThread,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizer] = mySQLErrCodes
 	expression.EvalAstExpr = evalAstExpr
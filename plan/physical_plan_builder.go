@@ -43,6 +43,24 @@ const (
 // JoinConcurrency means the number of goroutines that participate in joining.
 var JoinConcurrency = 5
 
+// selectivity estimates the fraction of the rows satisfying all the conditions. The conditions annotated by
+// the SELECTIVITY hints take the hinted selectivities, the others are estimated by selectionFactor together.
+func selectivity(ctx context.Context, conds []expression.Expression) float64 {
+	hints := ctx.GetSessionVars().StmtCtx.SelectivityHints
+	ret, hasUnhinted := 1.0, false
+	for _, cond := range conds {
+		if s, ok := hints[cond.String()]; ok {
+			ret *= s
+		} else {
+			hasUnhinted = true
+		}
+	}
+	if hasUnhinted {
+		ret *= selectionFactor
+	}
+	return ret
+}
+
 func (p *DataSource) convert2TableScan(prop *requiredProperty) (*physicalPlanInfo, error) {
 	client := p.ctx.GetClient()
 	ts := PhysicalTableScan{
@@ -50,7 +68,7 @@ func (p *DataSource) convert2TableScan(prop *requiredProperty) (*physicalPlanInf
 		Columns:             p.Columns,
 		TableAsName:         p.TableAsName,
 		DBName:              p.DBName,
-		physicalTableSource: physicalTableSource{client: client},
+		physicalTableSource: physicalTableSource{client: client, scanDirection: p.scanDirection},
 	}.init(p.allocator, p.ctx)
 	ts.SetSchema(p.Schema())
 	if p.ctx.Txn() != nil {
@@ -101,7 +119,7 @@ func (p *DataSource) convert2TableScan(prop *requiredProperty) (*physicalPlanInf
 		}
 	}
 	if ts.TableConditionPBExpr != nil {
-		rowCount = rowCount * selectionFactor
+		rowCount = rowCount * selectivity(p.ctx, ts.tableFilterConditions)
 	}
 	return resultPlan.matchProperty(prop, &physicalPlanInfo{count: rowCount, reliable: !statsTbl.Pseudo}), nil
 }
@@ -115,7 +133,7 @@ func (p *DataSource) convert2IndexScan(prop *requiredProperty, index *model.Inde
 		TableAsName:         p.TableAsName,
 		OutOfOrder:          true,
 		DBName:              p.DBName,
-		physicalTableSource: physicalTableSource{client: client},
+		physicalTableSource: physicalTableSource{client: client, scanDirection: p.scanDirection},
 	}.init(p.allocator, p.ctx)
 	is.SetSchema(p.schema)
 	if p.ctx.Txn() != nil {
@@ -169,6 +187,50 @@ func (p *DataSource) convert2IndexScan(prop *requiredProperty, index *model.Inde
 	return resultPlan.matchProperty(prop, &physicalPlanInfo{count: rowCount, reliable: !statsTbl.Pseudo}), nil
 }
 
+// convert2MultiValuedIndexScan converts the DataSource to the double read of the multi-valued index, with the
+// point range built from the conditions of the parent selection. All the conditions are kept in the selection,
+// as the index entries are the elements of the JSON values. It returns nil if there is no such condition.
+func (p *DataSource) convert2MultiValuedIndexScan(prop *requiredProperty, index *model.IndexInfo) *physicalPlanInfo {
+	sel, ok := p.parents[0].(*Selection)
+	if !ok {
+		return nil
+	}
+	value, ok := multiValuedAccessValue(sel.Conditions, index)
+	if !ok {
+		return nil
+	}
+	is := PhysicalIndexScan{
+		Index:               index,
+		Table:               p.tableInfo,
+		Columns:             p.Columns,
+		TableAsName:         p.TableAsName,
+		OutOfOrder:          true,
+		DBName:              p.DBName,
+		DoubleRead:          true,
+		physicalTableSource: physicalTableSource{client: p.ctx.GetClient(), scanDirection: p.scanDirection},
+	}.init(p.allocator, p.ctx)
+	is.SetSchema(p.schema)
+	if p.ctx.Txn() != nil {
+		is.readOnly = p.ctx.Txn().IsReadOnly()
+	} else {
+		is.readOnly = true
+	}
+	is.Ranges = []*types.IndexRange{{LowVal: []types.Datum{value}, HighVal: []types.Datum{value}}}
+	statsTbl := p.statisticTable
+	rowCount, err := statsTbl.GetRowCountByIndexRanges(p.ctx.GetSessionVars().StmtCtx, index.ID, is.Ranges, 1)
+	if err != nil {
+		rowCount = float64(statsTbl.Count)
+	}
+	newSel := sel.Copy().(*Selection)
+	newSel.Conditions = make([]expression.Expression, 0, len(sel.Conditions))
+	for _, cond := range sel.Conditions {
+		newSel.Conditions = append(newSel.Conditions, cond.Clone())
+	}
+	newSel.SetChildren(is)
+	newSel.onTable = true
+	return newSel.matchProperty(prop, &physicalPlanInfo{count: rowCount, reliable: !statsTbl.Pseudo})
+}
+
 func isCoveringIndex(columns []*model.ColumnInfo, indexColumns []*model.IndexColumn, pkIsHandle bool) bool {
 	for _, colInfo := range columns {
 		if pkIsHandle && mysql.HasPriKeyFlag(colInfo.Flag) {
@@ -231,6 +293,7 @@ func (p *DataSource) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlan
 		return info, nil
 	}
 	indices, includeTableScan := availableIndices(p.indexHints, p.tableInfo)
+	indices, mvIndices := splitMultiValuedIndices(indices)
 	if includeTableScan {
 		info, err = p.convert2TableScan(prop)
 		if err != nil {
@@ -247,6 +310,19 @@ func (p *DataSource) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlan
 				info = indexInfo
 			}
 		}
+		for _, index := range mvIndices {
+			indexInfo := p.convert2MultiValuedIndexScan(prop, index)
+			if indexInfo != nil && (info == nil || indexInfo.cost < info.cost) {
+				info = indexInfo
+			}
+		}
+	}
+	if info == nil {
+		// Only the multi-valued indices are available but none of them can be accessed.
+		info, err = p.convert2TableScan(prop)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
 	return info, errors.Trace(p.storePlanInfo(prop, info))
 }
@@ -401,6 +477,7 @@ func (p *LogicalJoin) convert2PhysicalPlanSemi(prop *requiredProperty) (*physica
 		LeftConditions:  p.LeftConditions,
 		RightConditions: p.RightConditions,
 		OtherConditions: p.OtherConditions,
+		NAEQConditions:  p.NAEQConditions,
 		Anti:            p.anti,
 	}.init(p.allocator, p.ctx)
 	join.SetSchema(p.schema)
@@ -1225,6 +1302,7 @@ func (p *Selection) makeScanController() *physicalPlanInfo {
 	)
 	ds := p.children[0].(*DataSource)
 	indices, _ := availableIndices(ds.indexHints, ds.tableInfo)
+	indices, _ = splitMultiValuedIndices(indices)
 	for _, expr := range p.Conditions {
 		if !expr.IsCorrelated() {
 			continue
@@ -1337,7 +1415,7 @@ func (p *Selection) appendSelToInfo(info *physicalPlanInfo) *physicalPlanInfo {
 	return &physicalPlanInfo{
 		p:        np,
 		cost:     info.cost,
-		count:    info.count * selectionFactor,
+		count:    info.count * selectivity(p.ctx, p.Conditions),
 		reliable: info.reliable,
 	}
 }
@@ -1466,6 +1544,31 @@ func matchProp(ctx context.Context, target, new *requiredProperty) bool {
 	return true
 }
 
+// convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
+func (p *LogicalWindow) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
+	info, err := p.getPlanInfo(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if info != nil {
+		return info, nil
+	}
+	// The child is sorted by the partition by and order by items, so the required property is enforced above the window.
+	childInfo, err := p.children[0].(LogicalPlan).convert2PhysicalPlan(&requiredProperty{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	window := PhysicalWindow{
+		WindowFuncs: p.WindowFuncs,
+		PartitionBy: p.PartitionBy,
+		OrderBy:     p.OrderBy,
+	}.init(p.allocator, p.ctx)
+	window.SetSchema(p.schema)
+	info = addPlanToResponse(window, childInfo)
+	info = enforceProperty(prop, info)
+	return info, p.storePlanInfo(prop, info)
+}
+
 // convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
 func (p *Sort) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
 	info, err := p.getPlanInfo(prop)
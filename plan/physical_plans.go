@@ -59,6 +59,7 @@ var (
 	_ PhysicalPlan = &PhysicalIndexScan{}
 	_ PhysicalPlan = &PhysicalTableScan{}
 	_ PhysicalPlan = &PhysicalAggregation{}
+	_ PhysicalPlan = &PhysicalWindow{}
 	_ PhysicalPlan = &PhysicalApply{}
 	_ PhysicalPlan = &PhysicalHashJoin{}
 	_ PhysicalPlan = &PhysicalHashSemiJoin{}
@@ -127,6 +128,12 @@ func (p *PhysicalIndexLookUpReader) Copy() PhysicalPlan {
 	return &np
 }
 
+// canScanInOrder checks whether the rows can be read in the order, a backward scan is forbidden if the ascending
+// direction is forced.
+func (p *physicalTableSource) canScanInOrder(desc bool) bool {
+	return !desc || p.scanDirection != variable.ScanDirectionAsc
+}
+
 // PhysicalIndexScan represents an index scan plan.
 type PhysicalIndexScan struct {
 	physicalTableSource
@@ -232,6 +239,9 @@ type physicalTableSource struct {
 	LimitCount  *int64
 	SortItemsPB []*tipb.ByItem
 
+	// scanDirection is the direction of the ordered scan forced by the hints or the variable.
+	scanDirection variable.ScanDirection
+
 	// The following fields are used for explaining and testing. Because pb structures are not human-readable.
 
 	aggFuncs              []expression.AggregationFunction
@@ -470,9 +480,13 @@ type PhysicalIndexJoin struct {
 	*basePlan
 	basePhysicalPlan
 
-	outer           bool
-	outerJoinKeys   []*expression.Column
-	innerJoinKeys   []*expression.Column
+	// Outer means the unmatched outer rows are padded with DefaultValues.
+	Outer bool
+	// OuterIndex is the index of the outer child in the logical join, the outer child is always children[0] of
+	// the index join, but the columns of the output rows are in the order of the logical join.
+	OuterIndex      int
+	OuterJoinKeys   []*expression.Column
+	InnerJoinKeys   []*expression.Column
 	LeftConditions  expression.CNFExprs
 	RightConditions expression.CNFExprs
 	OtherConditions expression.CNFExprs
@@ -508,6 +522,7 @@ type PhysicalHashSemiJoin struct {
 	LeftConditions  []expression.Expression
 	RightConditions []expression.Expression
 	OtherConditions []expression.Expression
+	NAEQConditions  []*expression.ScalarFunction
 }
 
 // AggregationType stands for the mode of aggregation plan.
@@ -533,6 +548,16 @@ type PhysicalAggregation struct {
 	GroupByItems []expression.Expression
 }
 
+// PhysicalWindow is LogicalWindow's physical plan.
+type PhysicalWindow struct {
+	*basePlan
+	basePhysicalPlan
+
+	WindowFuncs []*expression.WindowFunc
+	PartitionBy []*ByItems
+	OrderBy     []*ByItems
+}
+
 // PhysicalUnionScan represents a union scan operator.
 type PhysicalUnionScan struct {
 	*basePlan
@@ -616,6 +641,9 @@ func (p *PhysicalHashSemiJoin) extractCorrelatedCols() []*expression.CorrelatedC
 	for _, fun := range p.OtherConditions {
 		corCols = append(corCols, extractCorColumns(fun)...)
 	}
+	for _, fun := range p.NAEQConditions {
+		corCols = append(corCols, extractCorColumns(fun)...)
+	}
 	return corCols
 }
 
@@ -630,6 +658,22 @@ func (p *PhysicalApply) extractCorrelatedCols() []*expression.CorrelatedColumn {
 	return corCols
 }
 
+func (p *PhysicalWindow) extractCorrelatedCols() []*expression.CorrelatedColumn {
+	corCols := p.basePlan.extractCorrelatedCols()
+	for _, fun := range p.WindowFuncs {
+		for _, arg := range fun.Args {
+			corCols = append(corCols, extractCorColumns(arg)...)
+		}
+	}
+	for _, item := range p.PartitionBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	for _, item := range p.OrderBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	return corCols
+}
+
 func (p *PhysicalAggregation) extractCorrelatedCols() []*expression.CorrelatedColumn {
 	corCols := p.basePlan.extractCorrelatedCols()
 	for _, expr := range p.GroupByItems {
@@ -778,6 +822,10 @@ func (p *PhysicalHashSemiJoin) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	naeqConds, err := json.Marshal(p.NAEQConditions)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	buffer := bytes.NewBufferString("{")
 	buffer.WriteString(fmt.Sprintf(
 		"\"with aux\": %v,"+
@@ -786,10 +834,11 @@ func (p *PhysicalHashSemiJoin) MarshalJSON() ([]byte, error) {
 			"\"leftCond\": %s,\n "+
 			"\"rightCond\": %s,\n "+
 			"\"otherCond\": %s,\n"+
+			"\"naeqCond\": %s,\n"+
 			"\"leftPlan\": \"%s\",\n "+
 			"\"rightPlan\": \"%s\""+
 			"}",
-		p.WithAux, p.Anti, eqConds, leftConds, rightConds, otherConds, leftChild.ID(), rightChild.ID()))
+		p.WithAux, p.Anti, eqConds, leftConds, rightConds, otherConds, naeqConds, leftChild.ID(), rightChild.ID()))
 	return buffer.Bytes(), nil
 }
 
@@ -850,6 +899,45 @@ func (p *PhysicalHashJoin) MarshalJSON() ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// MarshalJSON implements json.Marshaler interface.
+func (p *PhysicalIndexJoin) MarshalJSON() ([]byte, error) {
+	outerChild := p.children[0].(PhysicalPlan)
+	innerChild := p.children[1].(PhysicalPlan)
+	outerKeys, err := json.Marshal(p.OuterJoinKeys)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	innerKeys, err := json.Marshal(p.InnerJoinKeys)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	leftConds, err := json.Marshal(p.LeftConditions)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	rightConds, err := json.Marshal(p.RightConditions)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	otherConds, err := json.Marshal(p.OtherConditions)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	buffer := bytes.NewBufferString("{")
+	buffer.WriteString(fmt.Sprintf(
+		"\"outerKey\": %s,\n "+
+			"\"innerKey\": %s,\n "+
+			"\"leftCond\": %s,\n "+
+			"\"rightCond\": %s,\n "+
+			"\"otherCond\": %s,\n"+
+			"\"outer\": \"%v\",\n "+
+			"\"outerPlan\": \"%s\",\n "+
+			"\"innerPlan\": \"%s\""+
+			"}",
+		outerKeys, innerKeys, leftConds, rightConds, otherConds, p.Outer, outerChild.ID(), innerChild.ID()))
+	return buffer.Bytes(), nil
+}
+
 // MarshalJSON implements json.Marshaler interface.
 func (p *PhysicalMergeJoin) MarshalJSON() ([]byte, error) {
 	leftChild := p.children[0].(PhysicalPlan)
@@ -1068,6 +1156,37 @@ func (p *PhysicalAggregation) MarshalJSON() ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// Copy implements the PhysicalPlan Copy interface.
+func (p *PhysicalWindow) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (p *PhysicalWindow) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString("{")
+	windowFuncs, err := json.Marshal(p.WindowFuncs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	partitionBy, err := json.Marshal(p.PartitionBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	orderBy, err := json.Marshal(p.OrderBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	buffer.WriteString(fmt.Sprintf(
+		"\"WindowFuncs\": %s,\n"+
+			"\"PartitionBy\": %s,\n"+
+			"\"OrderBy\": %s,\n"+
+			"\"child\": \"%s\"}", windowFuncs, partitionBy, orderBy, p.children[0].ID()))
+	return buffer.Bytes(), nil
+}
+
 // Copy implements the PhysicalPlan Copy interface.
 func (p *Update) Copy() PhysicalPlan {
 	np := *p
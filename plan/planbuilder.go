@@ -26,36 +26,53 @@ import (
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/types"
 )
 
 // Error instances.
 var (
-	ErrUnsupportedType      = terror.ClassOptimizerPlan.New(CodeUnsupportedType, "Unsupported type")
-	SystemInternalErrorType = terror.ClassOptimizerPlan.New(SystemInternalError, "System internal error")
-	ErrUnknownColumn        = terror.ClassOptimizerPlan.New(CodeUnknownColumn, "Unknown column '%s' in '%s'")
-	ErrWrongArguments       = terror.ClassOptimizerPlan.New(CodeWrongArguments, "Incorrect arguments to EXECUTE")
-	ErrAmbiguous            = terror.ClassOptimizerPlan.New(CodeAmbiguous, "Column '%s' in field list is ambiguous")
-	ErrAnalyzeMissIndex     = terror.ClassOptimizerPlan.New(CodeAnalyzeMissIndex, "Index '%s' in field list does not exist in table '%s'")
-	ErrAlterAutoID          = terror.ClassAutoid.New(CodeAlterAutoID, "No support for setting auto_increment using alter_table")
+	ErrUnsupportedType         = terror.ClassOptimizerPlan.New(CodeUnsupportedType, "Unsupported type")
+	SystemInternalErrorType    = terror.ClassOptimizerPlan.New(SystemInternalError, "System internal error")
+	ErrUnknownColumn           = terror.ClassOptimizerPlan.New(CodeUnknownColumn, "Unknown column '%s' in '%s'")
+	ErrWrongArguments          = terror.ClassOptimizerPlan.New(CodeWrongArguments, "Incorrect arguments to EXECUTE")
+	ErrAmbiguous               = terror.ClassOptimizerPlan.New(CodeAmbiguous, "Column '%s' in %s is ambiguous")
+	ErrAnalyzeMissIndex        = terror.ClassOptimizerPlan.New(CodeAnalyzeMissIndex, "Index '%s' in field list does not exist in table '%s'")
+	ErrAlterAutoID             = terror.ClassAutoid.New(CodeAlterAutoID, "No support for setting auto_increment using alter_table")
+	ErrInvalidLateralJoin      = terror.ClassOptimizerPlan.New(CodeInvalidLateralJoin, mysql.MySQLErrName[mysql.ErrInvalidLateralJoin])
+	ErrInvalidWindowFuncUse    = terror.ClassOptimizerPlan.New(CodeInvalidWindowFuncUse, mysql.MySQLErrName[mysql.ErrWindowInvalidWindowFuncUse])
+	ErrFieldNotInGroupBy       = terror.ClassOptimizerPlan.New(CodeFieldNotInGroupBy, "Expression #%d of %s is not in GROUP BY clause and contains nonaggregated column '%s' which is not functionally dependent on columns in GROUP BY clause; this is incompatible with sql_mode=only_full_group_by")
+	ErrMixOfGroupFuncAndFields = terror.ClassOptimizerPlan.New(CodeMixOfGroupFuncAndFields, "In aggregated query without GROUP BY, expression #%d of %s contains nonaggregated column '%s'; this is incompatible with sql_mode=only_full_group_by")
+	ErrInvalidSelectivityHint  = terror.ClassOptimizerPlan.New(CodeInvalidSelectivityHint, "Selectivity %v of the SELECTIVITY hint isn't between 0 and 1, the hint is ignored")
 )
 
 // Error codes.
 const (
-	CodeUnsupportedType  terror.ErrCode = 1
-	SystemInternalError  terror.ErrCode = 2
-	CodeAlterAutoID      terror.ErrCode = 3
-	CodeAnalyzeMissIndex terror.ErrCode = 4
-	CodeAmbiguous        terror.ErrCode = 1052
-	CodeUnknownColumn    terror.ErrCode = 1054
-	CodeWrongArguments   terror.ErrCode = 1210
+	CodeUnsupportedType         terror.ErrCode = 1
+	SystemInternalError         terror.ErrCode = 2
+	CodeAlterAutoID             terror.ErrCode = 3
+	CodeAnalyzeMissIndex        terror.ErrCode = 4
+	CodeInvalidSelectivityHint  terror.ErrCode = 5
+	CodeAmbiguous               terror.ErrCode = 1052
+	CodeUnknownColumn           terror.ErrCode = 1054
+	CodeFieldNotInGroupBy       terror.ErrCode = 1055
+	CodeMixOfGroupFuncAndFields terror.ErrCode = 1140
+	CodeWrongArguments          terror.ErrCode = 1210
+
+	CodeInvalidWindowFuncUse terror.ErrCode = 3593
+	CodeInvalidLateralJoin   terror.ErrCode = 3809
 )
 
 func init() {
 	tableMySQLErrCodes := map[terror.ErrCode]uint16{
-		CodeUnknownColumn:  mysql.ErrBadField,
-		CodeAmbiguous:      mysql.ErrNonUniq,
-		CodeWrongArguments: mysql.ErrWrongArguments,
+		CodeUnknownColumn:           mysql.ErrBadField,
+		CodeAmbiguous:               mysql.ErrNonUniq,
+		CodeFieldNotInGroupBy:       mysql.ErrWrongFieldWithGroup,
+		CodeMixOfGroupFuncAndFields: mysql.ErrMixOfGroupFuncAndFields,
+		CodeWrongArguments:          mysql.ErrWrongArguments,
+
+		CodeInvalidWindowFuncUse: mysql.ErrWindowInvalidWindowFuncUse,
+		CodeInvalidLateralJoin:   mysql.ErrInvalidLateralJoin,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizerPlan] = tableMySQLErrCodes
 }
@@ -70,6 +87,8 @@ type visitInfo struct {
 type tableHintInfo struct {
 	INLJTables          []model.CIStr
 	sortMergeJoinTables []model.CIStr
+	indexAscTables      []model.CIStr
+	indexDescTables     []model.CIStr
 }
 
 func (info *tableHintInfo) ifPreferMergeJoin(tableNames ...*model.CIStr) bool {
@@ -107,6 +126,26 @@ func (info *tableHintInfo) ifPreferINLJ(tableNames ...*model.CIStr) bool {
 	return false
 }
 
+func (info *tableHintInfo) ifPreferIndexAsc(tableName *model.CIStr) bool {
+	return matchTableName(tableName, info.indexAscTables)
+}
+
+func (info *tableHintInfo) ifPreferIndexDesc(tableName *model.CIStr) bool {
+	return matchTableName(tableName, info.indexDescTables)
+}
+
+func matchTableName(tableName *model.CIStr, tables []model.CIStr) bool {
+	if tableName == nil {
+		return false
+	}
+	for _, curEntry := range tables {
+		if curEntry.L == tableName.L {
+			return true
+		}
+	}
+	return false
+}
+
 // planBuilder builds Plan from an ast.Node.
 // It just builds the ast node straightforwardly.
 type planBuilder struct {
@@ -118,12 +157,46 @@ type planBuilder struct {
 	is           infoschema.InfoSchema
 	outerSchemas []*expression.Schema
 	inUpdateStmt bool
+	inDeleteStmt bool
 	// colMapper stores the column that must be pre-resolved.
 	colMapper map[*ast.ColumnNameExpr]int
+	// windowMapper maps the window functions to the columns of the windows computing them.
+	windowMapper map[*ast.WindowFuncExpr]int
 	// Collect the visit information for privilege check.
 	visitInfo     []visitInfo
 	tableHintInfo []tableHintInfo
 	optFlag       uint64
+	// curClause is the clause being built, it's used in the error messages of name resolution.
+	curClause clauseCode
+	// outerAggs stores the aggregate functions in subqueries which are aggregated in the outer query.
+	outerAggs map[*ast.AggregateFuncExpr]*outerAggFunc
+	// subqueryResults stores the results of the uncorrelated subqueries evaluated in the statement.
+	subqueryResults map[subqueryKey][][]types.Datum
+	// insertCols are the columns of the table being inserted, DEFAULT(col) in the inserted values is
+	// rewritten to the default value of the column.
+	insertCols []*table.Column
+}
+
+type clauseCode int
+
+const (
+	unknownClause clauseCode = iota
+	fieldList
+	havingClause
+	onClause
+	orderByClause
+	whereClause
+	groupByClause
+)
+
+var clauseMsg = map[clauseCode]string{
+	unknownClause: "",
+	fieldList:     "field list",
+	havingClause:  "having clause",
+	onClause:      "on clause",
+	orderByClause: "order clause",
+	whereClause:   "where clause",
+	groupByClause: "group statement",
 }
 
 func (b *planBuilder) build(node ast.Node) Plan {
@@ -139,10 +212,16 @@ func (b *planBuilder) build(node ast.Node) Plan {
 		return b.buildExecute(x)
 	case *ast.ExplainStmt:
 		return b.buildExplain(x)
+	case *ast.ExplainForStmt:
+		return b.buildExplainFor(x)
+	case *ast.TraceStmt:
+		return b.buildTrace(x)
 	case *ast.InsertStmt:
 		return b.buildInsert(x)
 	case *ast.LoadDataStmt:
 		return b.buildLoadData(x)
+	case *ast.SplitRegionStmt:
+		return b.buildSplitRegion(x)
 	case *ast.PrepareStmt:
 		return b.buildPrepare(x)
 	case *ast.SelectStmt:
@@ -161,7 +240,8 @@ func (b *planBuilder) build(node ast.Node) Plan {
 		return b.buildAnalyze(x)
 	case *ast.BinlogStmt, *ast.FlushStmt, *ast.UseStmt,
 		*ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.CreateUserStmt, *ast.SetPwdStmt,
-		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt:
+		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt,
+		*ast.CreateEventStmt, *ast.AlterEventStmt, *ast.DropEventStmt:
 		return b.buildSimple(node.(ast.StmtNode))
 	case ast.DDLNode:
 		return b.buildDDL(x)
@@ -237,18 +317,18 @@ func (b *planBuilder) detectSelectAgg(sel *ast.SelectStmt) bool {
 		return true
 	}
 	for _, f := range sel.GetResultFields() {
-		if ast.HasAggFlag(f.Expr) {
+		if b.hasAggFunc(f.Expr) {
 			return true
 		}
 	}
 	if sel.Having != nil {
-		if ast.HasAggFlag(sel.Having.Expr) {
+		if b.hasAggFunc(sel.Having.Expr) {
 			return true
 		}
 	}
 	if sel.OrderBy != nil {
 		for _, item := range sel.OrderBy.Items {
-			if ast.HasAggFlag(item.Expr) {
+			if b.hasAggFunc(item.Expr) {
 				return true
 			}
 		}
@@ -256,6 +336,24 @@ func (b *planBuilder) detectSelectAgg(sel *ast.SelectStmt) bool {
 	return false
 }
 
+// hasAggFunc checks if the expr contains aggregate functions which are aggregated in the current query.
+func (b *planBuilder) hasAggFunc(expr ast.ExprNode) bool {
+	if !ast.HasAggFlag(expr) {
+		return false
+	}
+	if len(b.outerAggs) == 0 {
+		return true
+	}
+	extractor := &AggregateFuncExtractor{}
+	expr.Accept(extractor)
+	for _, agg := range extractor.AggFuncs {
+		if _, ok := b.outerAggs[agg]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
 func availableIndices(hints []*ast.IndexHint, tableInfo *model.TableInfo) (indices []*model.IndexInfo, includeTableScan bool) {
 	var usableHints []*ast.IndexHint
 	for _, hint := range hints {
@@ -359,6 +457,28 @@ func (b *planBuilder) buildAdmin(as *ast.AdminStmt) Plan {
 	case ast.AdminShowDDL:
 		p = &ShowDDL{}
 		p.SetSchema(buildShowDDLFields())
+	case ast.AdminShowDDLOwner:
+		p = &ShowDDLOwner{}
+		p.SetSchema(buildShowDDLOwnerSchema())
+	case ast.AdminTransferDDLOwner:
+		p = &TransferDDLOwner{Target: as.DDLOwner}
+		p.SetSchema(expression.NewSchema())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminReloadExprPushdownBlacklist:
+		p = &ReloadExprPushdownBlacklist{}
+		p.SetSchema(expression.NewSchema())
+	case ast.AdminReloadResourceGroups:
+		p = &ReloadResourceGroups{}
+		p.SetSchema(expression.NewSchema())
+	case ast.AdminReloadConfig:
+		p = &ReloadConfig{}
+		p.SetSchema(buildReloadConfigSchema())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminShowSlow:
+		p = &ShowSlow{ShowSlow: as.ShowSlow}
+		p.SetSchema(buildShowSlowSchema())
+		// The slow queries of all the users are shown.
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
 	default:
 		b.err = ErrUnsupportedType.Gen("Unsupported type %T", as)
 	}
@@ -458,6 +578,40 @@ func buildShowDDLFields() *expression.Schema {
 	return schema
 }
 
+func buildShowDDLOwnerSchema() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 6)...)
+	schema.Append(buildColumn("", "TYPE", mysql.TypeVarchar, 16))
+	schema.Append(buildColumn("", "OWNER_ID", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "SERVER_ID", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "LAST_UPDATE", mysql.TypeDatetime, 26))
+	schema.Append(buildColumn("", "LEASE_EXPIRED", mysql.TypeTiny, 1))
+	schema.Append(buildColumn("", "JOB_QUEUE_LEN", mysql.TypeLonglong, 21))
+	return schema
+}
+
+func buildShowSlowSchema() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 9)...)
+	schema.Append(buildColumn("", "SQL", mysql.TypeVarchar, 4096))
+	schema.Append(buildColumn("", "START", mysql.TypeTimestamp, 26))
+	schema.Append(buildColumn("", "DURATION", mysql.TypeDuration, 17))
+	schema.Append(buildColumn("", "SUCC", mysql.TypeTiny, 1))
+	schema.Append(buildColumn("", "CONN_ID", mysql.TypeLonglong, 21))
+	schema.Append(buildColumn("", "TRANSACTION_TS", mysql.TypeLonglong, 21))
+	schema.Append(buildColumn("", "USER", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "DB", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "INTERNAL", mysql.TypeTiny, 1))
+	return schema
+}
+
+func buildReloadConfigSchema() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 4)...)
+	schema.Append(buildColumn("", "KEY", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "OLD_VALUE", mysql.TypeVarchar, 256))
+	schema.Append(buildColumn("", "NEW_VALUE", mysql.TypeVarchar, 256))
+	schema.Append(buildColumn("", "RELOADED", mysql.TypeTiny, 1))
+	return schema
+}
+
 func buildColumn(tableName, name string, tp byte, size int) *expression.Column {
 	cs, cl := types.DefaultCharsetForType(tp)
 	flag := mysql.UnsignedFlag
@@ -512,6 +666,8 @@ func (b *planBuilder) buildShow(show *ast.ShowStmt) Plan {
 		Flag:   show.Flag,
 		Full:   show.Full,
 		User:   show.User,
+
+		CountWarningsOrErrors: show.CountWarningsOrErrors,
 	}.init(b.allocator, b.ctx)
 	resultPlan = p
 	switch show.Tp {
@@ -521,8 +677,12 @@ func (b *planBuilder) buildShow(show *ast.ShowStmt) Plan {
 		p.SetSchema(buildShowTriggerSchema())
 	case ast.ShowEvents:
 		p.SetSchema(buildShowEventsSchema())
-	case ast.ShowWarnings:
-		p.SetSchema(buildShowWarningsSchema())
+	case ast.ShowWarnings, ast.ShowErrors:
+		if show.CountWarningsOrErrors {
+			p.SetSchema(buildShowSchema(show))
+		} else {
+			p.SetSchema(buildShowWarningsSchema())
+		}
 	default:
 		p.SetSchema(buildShowSchema(show))
 	}
@@ -569,6 +729,9 @@ func (b *planBuilder) buildSimple(node ast.StmtNode) Plan {
 		b.visitInfo = collectVisitInfoFromGrantStmt(b.visitInfo, raw)
 	case *ast.SetPwdStmt, *ast.RevokeStmt, *ast.KillStmt:
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case *ast.CreateEventStmt, *ast.AlterEventStmt, *ast.DropEventStmt:
+		// There is no EVENT privilege yet, managing events requires SUPER.
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
 	}
 	return p
 }
@@ -605,7 +768,10 @@ func collectVisitInfoFromGrantStmt(vi []visitInfo, stmt *ast.GrantStmt) []visitI
 	return vi
 }
 
-func (b *planBuilder) getDefaultValue(col *table.Column) (*expression.Constant, error) {
+func (b *planBuilder) getDefaultValue(col *table.Column) (expression.Expression, error) {
+	if col.DefaultIsExpr {
+		return b.rewriteDefaultValueExpr(col)
+	}
 	value, err := table.GetColDefaultValue(b.ctx, col.ToInfo())
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -613,7 +779,22 @@ func (b *planBuilder) getDefaultValue(col *table.Column) (*expression.Constant,
 	return &expression.Constant{Value: value, RetType: &col.FieldType}, nil
 }
 
-func (b *planBuilder) findDefaultValue(cols []*table.Column, name *ast.ColumnName) (*expression.Constant, error) {
+// rewriteDefaultValueExpr rewrites the default value expression of the column, so it's evaluated
+// for every inserted row instead of being evaluated once when the plan is built.
+func (b *planBuilder) rewriteDefaultValueExpr(col *table.Column) (expression.Expression, error) {
+	text, _ := col.DefaultValue.(string)
+	node, err := table.ParseDefaultExpr(text)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	expr, _, err := b.rewrite(node, nil, nil, true)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return expr, nil
+}
+
+func (b *planBuilder) findDefaultValue(cols []*table.Column, name *ast.ColumnName) (expression.Expression, error) {
 	for _, col := range cols {
 		if col.Name.L == name.Name.L {
 			return b.getDefaultValue(col)
@@ -656,6 +837,21 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 	})
 
 	cols := table.Cols()
+	b.insertCols = cols
+	for _, col := range cols {
+		if !col.DefaultIsExpr {
+			continue
+		}
+		expr, err := b.rewriteDefaultValueExpr(col)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		if insertPlan.DefaultExprs == nil {
+			insertPlan.DefaultExprs = make(map[int]expression.Expression)
+		}
+		insertPlan.DefaultExprs[col.Offset] = expr
+	}
 	for _, valuesItem := range insert.Lists {
 		exprList := make([]expression.Expression, 0, len(valuesItem))
 		for i, valueItem := range valuesItem {
@@ -677,6 +873,7 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 			}
 			if err != nil {
 				b.err = errors.Trace(err)
+				return nil
 			}
 			exprList = append(exprList, expr)
 		}
@@ -694,7 +891,12 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		}
 		// Here we keep different behaviours with MySQL. MySQL allow set a = b, b = a and the result is NULL, NULL.
 		// It's unreasonable.
-		expr, _, err := b.rewrite(assign.Expr, nil, nil, true)
+		var expr expression.Expression
+		if dft, ok := assign.Expr.(*ast.DefaultExpr); ok && dft.Name == nil {
+			expr, err = b.findDefaultValue(cols, assign.Column)
+		} else {
+			expr, _, err = b.rewrite(assign.Expr, nil, nil, true)
+		}
 		if err != nil {
 			b.err = errors.Trace(err)
 			return nil
@@ -727,6 +929,8 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		})
 	}
 	if insert.Select != nil {
+		// DEFAULT(col) in the select refers to the columns of the select rather than the inserted table.
+		b.insertCols = nil
 		selectPlan := b.build(insert.Select)
 		if b.err != nil {
 			return nil
@@ -750,6 +954,41 @@ func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
 	return p
 }
 
+func (b *planBuilder) buildSplitRegion(node *ast.SplitRegionStmt) Plan {
+	tblInfo := node.Table.TableInfo
+	if len(node.Lower) != 1 || len(node.Upper) != 1 {
+		b.err = ErrUnsupportedType.Gen("Split table region by multiple columns is not supported")
+		return nil
+	}
+	if node.Num < 1 {
+		b.err = ErrUnsupportedType.Gen("Split table region num must be greater than 0")
+		return nil
+	}
+	lower, _, err := b.rewrite(node.Lower[0], nil, nil, true)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	upper, _, err := b.rewrite(node.Upper[0], nil, nil, true)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	b.visitInfo = append(b.visitInfo, visitInfo{
+		privilege: mysql.InsertPriv,
+		db:        node.Table.Schema.L,
+		table:     tblInfo.Name.L,
+	})
+	p := &SplitRegion{
+		Table: tblInfo,
+		Lower: lower,
+		Upper: upper,
+		Num:   node.Num,
+	}
+	p.SetSchema(expression.NewSchema())
+	return p
+}
+
 func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 	switch v := node.(type) {
 	case *ast.AlterTableStmt:
@@ -782,6 +1021,12 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 				table:     v.ReferTable.Name.L,
 			})
 		}
+	case *ast.CreateTriggerStmt:
+		b.visitInfo = append(b.visitInfo, visitInfo{
+			privilege: mysql.TriggerPriv,
+			db:        v.Table.Schema.L,
+			table:     v.Table.Name.L,
+		})
 	case *ast.DropDatabaseStmt:
 		b.visitInfo = append(b.visitInfo, visitInfo{
 			privilege: mysql.DropPriv,
@@ -801,6 +1046,11 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 				table:     table.Name.L,
 			})
 		}
+	case *ast.DropTriggerStmt:
+		b.visitInfo = append(b.visitInfo, visitInfo{
+			privilege: mysql.TriggerPriv,
+			db:        v.Schema.L,
+		})
 	case *ast.TruncateTableStmt:
 		b.visitInfo = append(b.visitInfo, visitInfo{
 			privilege: mysql.DeletePriv,
@@ -829,14 +1079,79 @@ func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 	if show, ok := explain.Stmt.(*ast.ShowStmt); ok {
 		return b.buildShow(show)
 	}
-	targetPlan, err := Optimize(b.ctx, explain.Stmt, b.is)
+	targetPlan, _, err := optimize(b.ctx, explain.Stmt, b.is)
 	if err != nil {
 		b.err = errors.Trace(err)
 		return nil
 	}
-	p := &Explain{StmtPlan: targetPlan}
+	p := &Explain{StmtPlan: targetPlan, Analyze: explain.Analyze}
 	addChild(p, targetPlan)
+	p.SetSchema(buildExplainSchema(explain.Analyze))
+	return p
+}
+
+// buildExplainFor builds the explain plan of the statement running in another connection.
+// The plan of the target statement is shared with its connection, so it's not added as child.
+func (b *planBuilder) buildExplainFor(explainFor *ast.ExplainForStmt) Plan {
+	var target *util.ProcessInfo
+	if sm := b.ctx.GetSessionManager(); sm != nil {
+		for _, pi := range sm.ShowProcessList() {
+			if pi.ID == explainFor.ConnectionID {
+				target = &pi
+				break
+			}
+		}
+	}
+	if target == nil {
+		b.err = ErrNoSuchThread.GenByArgs(explainFor.ConnectionID)
+		return nil
+	}
+	if user := b.ctx.GetSessionVars().User; user != target.User+"@"+target.Host {
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.ProcessPriv, "", "", "")
+	}
+	p := &Explain{}
+	targetPlan, _ := target.Plan.(Plan)
+	if explain, ok := targetPlan.(*Explain); ok {
+		// The target is an explain statement, its plan is shown with the runtime statistics if
+		// it's an explain analyze statement.
+		targetPlan = explain.StmtPlan
+		if explain.Analyze && target.StmtCtx != nil {
+			p.Analyze = true
+			p.ExecDetailsCtx = target.StmtCtx
+		}
+	}
+	p.StmtPlan = targetPlan
+	p.SetSchema(buildExplainSchema(p.Analyze))
+	return p
+}
+
+// buildTrace builds the trace plan, the traced statement is optimized by the executor rather than here,
+// so the time spent in optimizing it is traced too.
+func (b *planBuilder) buildTrace(trace *ast.TraceStmt) Plan {
+	p := &Trace{StmtNode: trace.Stmt}
+	p.SetSchema(buildTraceSchema())
+	return p
+}
+
+func buildTraceSchema() *expression.Schema {
 	schema := expression.NewSchema(make([]*expression.Column, 0, 3)...)
+	schema.Append(&expression.Column{
+		ColName: model.NewCIStr("operation"),
+		RetType: types.NewFieldType(mysql.TypeString),
+	})
+	schema.Append(&expression.Column{
+		ColName: model.NewCIStr("startTS"),
+		RetType: types.NewFieldType(mysql.TypeString),
+	})
+	schema.Append(&expression.Column{
+		ColName: model.NewCIStr("duration"),
+		RetType: types.NewFieldType(mysql.TypeString),
+	})
+	return schema
+}
+
+func buildExplainSchema(withExecInfo bool) *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 4)...)
 	schema.Append(&expression.Column{
 		ColName: model.NewCIStr("ID"),
 		RetType: types.NewFieldType(mysql.TypeString),
@@ -849,8 +1164,13 @@ func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 		ColName: model.NewCIStr("ParentID"),
 		RetType: types.NewFieldType(mysql.TypeString),
 	})
-	p.SetSchema(schema)
-	return p
+	if withExecInfo {
+		schema.Append(&expression.Column{
+			ColName: model.NewCIStr("ExecInfo"),
+			RetType: types.NewFieldType(mysql.TypeString),
+		})
+	}
+	return schema
 }
 
 func buildShowProcedureSchema() *expression.Schema {
@@ -970,9 +1290,17 @@ func buildShowSchema(s *ast.ShowStmt) (schema *expression.Schema) {
 			mysql.TypeVarchar, mysql.TypeVarchar}
 	case ast.ShowColumns:
 		names = table.ColDescFieldNames(s.Full)
-	case ast.ShowWarnings:
-		names = []string{"Level", "Code", "Message"}
-		ftypes = []byte{mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar}
+	case ast.ShowWarnings, ast.ShowErrors:
+		if s.CountWarningsOrErrors {
+			names = []string{"@@session.warning_count"}
+			if s.Tp == ast.ShowErrors {
+				names = []string{"@@session.error_count"}
+			}
+			ftypes = []byte{mysql.TypeLonglong}
+		} else {
+			names = []string{"Level", "Code", "Message"}
+			ftypes = []byte{mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar}
+		}
 	case ast.ShowCharset:
 		names = []string{"Charset", "Description", "Default collation", "Maxlen"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong}
@@ -999,6 +1327,17 @@ func buildShowSchema(s *ast.ShowStmt) (schema *expression.Schema) {
 		names = []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info"}
 		ftypes = []byte{mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar,
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar, mysql.TypeString}
+	case ast.ShowRegions:
+		names = []string{"REGION_ID", "START_KEY", "END_KEY", "LEADER_ID", "LEADER_STORE_ID", "PEERS"}
+		ftypes = []byte{mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar,
+			mysql.TypeLonglong, mysql.TypeLonglong, mysql.TypeVarchar}
+	case ast.ShowHotspots:
+		names = []string{"Db_name", "Table_name", "Index_name", "Read_QPS", "Write_QPS", "Append_ratio"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar,
+			mysql.TypeDouble, mysql.TypeDouble, mysql.TypeDouble}
+	case ast.ShowMasterStatus:
+		names = []string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB", "Executed_Gtid_Set"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar}
 	}
 	return composeShowSchema(names, ftypes)
 }
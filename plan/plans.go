@@ -17,6 +17,7 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 )
 
@@ -25,6 +26,21 @@ type ShowDDL struct {
 	basePlan
 }
 
+// ShowDDLOwner is for showing the owners of the DDL jobs and the background jobs, built from the
+// 'admin show ddl owner' statement.
+type ShowDDLOwner struct {
+	basePlan
+}
+
+// TransferDDLOwner transfers the ownership of the DDL jobs and the background jobs, built from the
+// 'admin transfer ddl owner' and 'admin resign ddl owner' statements.
+type TransferDDLOwner struct {
+	basePlan
+
+	// Target is the ID of the new owner, it's empty if the owner resigns.
+	Target string
+}
+
 // CheckTable is used for checking table data, built from the 'admin check table' statement.
 type CheckTable struct {
 	basePlan
@@ -32,6 +48,30 @@ type CheckTable struct {
 	Tables []*ast.TableName
 }
 
+// ReloadExprPushdownBlacklist reloads the expression pushdown blacklist from the system table,
+// built from the 'admin reload expr_pushdown_blacklist' statement.
+type ReloadExprPushdownBlacklist struct {
+	basePlan
+}
+
+// ReloadResourceGroups reloads the resource groups and the assignments of the users from the system
+// tables, built from the 'admin reload resource_groups' statement.
+type ReloadResourceGroups struct {
+	basePlan
+}
+
+// ReloadConfig reloads the configuration file of tidb-server, built from the 'admin reload config' statement.
+type ReloadConfig struct {
+	basePlan
+}
+
+// ShowSlow is for showing the slow queries kept in memory, built from the 'admin show slow' statement.
+type ShowSlow struct {
+	basePlan
+
+	*ast.ShowSlow
+}
+
 // SelectLock represents a select lock plan.
 type SelectLock struct {
 	*basePlan
@@ -91,6 +131,8 @@ type Show struct {
 
 	// Used by show variables
 	GlobalScope bool
+	// Used by show count(*) warnings and show count(*) errors.
+	CountWarningsOrErrors bool
 }
 
 // Set represents a plan for set stmt.
@@ -119,6 +161,9 @@ type Insert struct {
 	Lists       [][]expression.Expression
 	Setlist     []*expression.Assignment
 	OnDuplicate []*expression.Assignment
+	// DefaultExprs are the default value expressions of the columns indexed by the column offsets,
+	// they are evaluated for every inserted row which doesn't set the columns.
+	DefaultExprs map[int]expression.Expression
 
 	IsReplace bool
 	Priority  int
@@ -164,6 +209,16 @@ type LoadData struct {
 	LinesInfo  *ast.LinesClause
 }
 
+// SplitRegion represents a split regions plan.
+type SplitRegion struct {
+	basePlan
+
+	Table *model.TableInfo
+	Lower expression.Expression
+	Upper expression.Expression
+	Num   uint64
+}
+
 // DDL represents a DDL statement plan.
 type DDL struct {
 	basePlan
@@ -176,4 +231,16 @@ type Explain struct {
 	basePlan
 
 	StmtPlan Plan
+	// Analyze is true for 'explain analyze', the runtime statistics are shown in the ExecInfo column.
+	Analyze bool
+	// ExecDetailsCtx is the statement context that collects the runtime statistics of StmtPlan when
+	// it's running in another connection, it's set by 'explain for connection'.
+	ExecDetailsCtx *variable.StatementContext
+}
+
+// Trace represents a trace plan.
+type Trace struct {
+	basePlan
+
+	StmtNode ast.StmtNode
 }
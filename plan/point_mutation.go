@@ -0,0 +1,288 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// PointGet reads the rows of a table by their handles directly, or a row by the values of its
+// clustered primary key, see model.IndexInfo.Clustered.
+// It is used as the child of the UPDATE and DELETE whose conditions are "pk = constant" or
+// "pk in (constants)", where pk is the integer primary key, or whose conditions are "col = constant"
+// on all the columns of the clustered primary key, which is also read by PointGet for the SELECT.
+type PointGet struct {
+	*basePlan
+	basePhysicalPlan
+
+	Table       *model.TableInfo
+	Columns     []*model.ColumnInfo
+	DBName      model.CIStr
+	TableAsName *model.CIStr
+	Handles     []int64
+	// Index is the clustered primary key, the row is read by IndexValues if it's not nil.
+	Index       *model.IndexInfo
+	IndexValues []types.Datum
+}
+
+// Copy implements the PhysicalPlan Copy interface.
+func (p *PointGet) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
+// tryPointMutation builds the physical plan of UPDATE or DELETE directly if it mutates the rows
+// of a single table by their integer primary keys, so the cost based optimization is skipped.
+// It returns nil if the plan doesn't match the pattern.
+func tryPointMutation(p Plan) PhysicalPlan {
+	var mutation PhysicalPlan
+	switch x := p.(type) {
+	case *Update:
+		mutation = x
+	case *Delete:
+		if x.IsMultiTable {
+			return nil
+		}
+		mutation = x
+	default:
+		return nil
+	}
+	sel, ok := mutation.Children()[0].(*Selection)
+	if !ok {
+		return nil
+	}
+	ds, ok := sel.Children()[0].(*DataSource)
+	if !ok {
+		return nil
+	}
+	var pg *PointGet
+	if pkCol := ds.getPKIsHandleCol(); pkCol != nil {
+		if len(sel.Conditions) != 1 {
+			return nil
+		}
+		handles, ok := extractPointHandles(sel.Conditions[0], pkCol)
+		if !ok {
+			return nil
+		}
+		pg = newPointGet(ds)
+		pg.Handles = handles
+	} else if pg = tryClusteredPointGet(ds, sel.Conditions); pg == nil {
+		return nil
+	}
+	mutation.SetChildren(pg)
+	pg.SetParents(mutation)
+	mutation.ResolveIndices()
+	return mutation
+}
+
+// tryPointGet builds the physical plan of SELECT directly if it reads a single row of a table by all the
+// columns of its clustered primary key, and has no other conditions, aggregations, sorts or limits.
+// It returns nil if the plan doesn't match the pattern.
+func tryPointGet(p Plan) PhysicalPlan {
+	proj, ok := p.(*Projection)
+	if !ok {
+		return nil
+	}
+	sel, ok := proj.Children()[0].(*Selection)
+	if !ok {
+		return nil
+	}
+	ds, ok := sel.Children()[0].(*DataSource)
+	if !ok {
+		return nil
+	}
+	pg := tryClusteredPointGet(ds, sel.Conditions)
+	if pg == nil {
+		return nil
+	}
+	proj.SetChildren(pg)
+	pg.SetParents(proj)
+	proj.ResolveIndices()
+	return proj
+}
+
+func newPointGet(ds *DataSource) *PointGet {
+	pg := PointGet{
+		Table:       ds.tableInfo,
+		Columns:     ds.Columns,
+		DBName:      ds.DBName,
+		TableAsName: ds.TableAsName,
+	}.init(ds.allocator, ds.ctx)
+	pg.SetSchema(ds.Schema())
+	return pg
+}
+
+// tryClusteredPointGet returns the PointGet reading a row of the table by its clustered primary key if
+// the conditions are exactly "col = constant" on every column of the key, or nil otherwise.
+func tryClusteredPointGet(ds *DataSource, conds []expression.Expression) *PointGet {
+	var pk *model.IndexInfo
+	for _, idx := range ds.tableInfo.Indices {
+		if idx.Clustered && idx.State == model.StatePublic {
+			pk = idx
+			break
+		}
+	}
+	if pk == nil || len(conds) != len(pk.Columns) {
+		return nil
+	}
+	values := make([]types.Datum, len(pk.Columns))
+	found := make([]bool, len(pk.Columns))
+	for _, cond := range conds {
+		col, con := extractColumnEqConstant(cond)
+		if col == nil {
+			return nil
+		}
+		for i, ic := range pk.Columns {
+			if col.ColName.L != ic.Name.L || found[i] {
+				continue
+			}
+			value, ok := datumToKeyValue(con.Value, col.RetType)
+			if !ok {
+				return nil
+			}
+			values[i], found[i] = value, true
+			break
+		}
+	}
+	for _, f := range found {
+		if !f {
+			return nil
+		}
+	}
+	pg := newPointGet(ds)
+	pg.Index = pk
+	pg.IndexValues = values
+	return pg
+}
+
+// extractColumnEqConstant extracts the column and the constant from the condition "col = constant".
+func extractColumnEqConstant(cond expression.Expression) (*expression.Column, *expression.Constant) {
+	sf, ok := cond.(*expression.ScalarFunction)
+	if !ok || sf.FuncName.L != ast.EQ {
+		return nil, nil
+	}
+	args := sf.GetArgs()
+	if col, ok := args[0].(*expression.Column); ok {
+		if con, ok := args[1].(*expression.Constant); ok {
+			return col, con
+		}
+	}
+	if col, ok := args[1].(*expression.Column); ok {
+		if con, ok := args[0].(*expression.Constant); ok {
+			return col, con
+		}
+	}
+	return nil, nil
+}
+
+// datumToKeyValue converts the value compared with a column of the clustered primary key to the type of
+// the column. Only the integer and the string columns are supported, and a string column can only be
+// compared with a string, which is compared as a string in the index too.
+func datumToKeyValue(d types.Datum, tp *types.FieldType) (types.Datum, bool) {
+	if d.IsNull() {
+		return d, false
+	}
+	switch tp.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+	case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString:
+		if d.Kind() != types.KindString && d.Kind() != types.KindBytes {
+			return d, false
+		}
+	default:
+		return d, false
+	}
+	// Use a new statement context so the truncation is reported as an error instead of a warning.
+	sc := new(variable.StatementContext)
+	converted, err := d.ConvertTo(sc, tp)
+	if err != nil {
+		return d, false
+	}
+	cmp, err := converted.CompareDatum(sc, d)
+	if err != nil || cmp != 0 {
+		return d, false
+	}
+	return converted, true
+}
+
+// extractPointHandles extracts the handles from the condition "pk = constant" or "pk in (constants)".
+func extractPointHandles(cond expression.Expression, pkCol *expression.Column) ([]int64, bool) {
+	sf, ok := cond.(*expression.ScalarFunction)
+	if !ok {
+		return nil, false
+	}
+	args := sf.GetArgs()
+	var values []expression.Expression
+	switch sf.FuncName.L {
+	case ast.EQ:
+		if col, ok := args[0].(*expression.Column); ok && col.Equal(pkCol, nil) {
+			values = args[1:]
+		} else if col, ok := args[1].(*expression.Column); ok && col.Equal(pkCol, nil) {
+			values = args[:1]
+		}
+	case ast.In:
+		if col, ok := args[0].(*expression.Column); ok && col.Equal(pkCol, nil) {
+			values = args[1:]
+		}
+	}
+	if len(values) == 0 {
+		return nil, false
+	}
+	handles := make([]int64, 0, len(values))
+	seen := make(map[int64]struct{}, len(values))
+	for _, v := range values {
+		con, ok := v.(*expression.Constant)
+		if !ok {
+			return nil, false
+		}
+		handle, ok := datumToHandle(con.Value, pkCol.RetType)
+		if !ok {
+			return nil, false
+		}
+		if _, ok := seen[handle]; ok {
+			continue
+		}
+		seen[handle] = struct{}{}
+		handles = append(handles, handle)
+	}
+	return handles, true
+}
+
+// datumToHandle converts the value compared with the primary key to a handle,
+// it fails if the value can't be converted to the type of the primary key without loss.
+func datumToHandle(d types.Datum, tp *types.FieldType) (int64, bool) {
+	if d.IsNull() {
+		return 0, false
+	}
+	// Use a new statement context so the truncation is reported as an error instead of a warning.
+	sc := new(variable.StatementContext)
+	converted, err := d.ConvertTo(sc, tp)
+	if err != nil {
+		return 0, false
+	}
+	cmp, err := converted.CompareDatum(sc, d)
+	if err != nil || cmp != 0 {
+		return 0, false
+	}
+	if mysql.HasUnsignedFlag(tp.Flag) {
+		return int64(converted.GetUint64()), true
+	}
+	return converted.GetInt64(), true
+}
@@ -14,8 +14,11 @@ package plan
 
 import (
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 )
 
 type ppdSolver struct{}
@@ -53,10 +56,55 @@ func (p *Selection) PredicatePushDown(predicates []expression.Expression) ([]exp
 func (p *DataSource) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
 	if useDAGPlanBuilder(p.ctx) {
 		_, p.pushedDownConds, predicates = expression.ExpressionsToPB(p.ctx.GetSessionVars().StmtCtx, predicates, p.ctx.GetClient())
+		p.hybridConds = p.hybridConds[:0]
+		p.multiValuedConds = p.multiValuedConds[:0]
+		for _, cond := range predicates {
+			if isHybridRangeCond(cond) {
+				p.hybridConds = append(p.hybridConds, cond)
+			} else if isMultiValuedAccessCond(cond) {
+				p.multiValuedConds = append(p.multiValuedConds, cond)
+			}
+		}
 	}
 	return predicates, p, nil
 }
 
+// isHybridRangeCond checks whether the condition is an "eq" or "in" function on an enum or set column,
+// with constants that can be converted to the column type. Such conditions can't be pushed down,
+// but the index of the column is sorted by the element indexes, so we can use them to build index ranges.
+func isHybridRangeCond(cond expression.Expression) bool {
+	f, ok := cond.(*expression.ScalarFunction)
+	if !ok {
+		return false
+	}
+	args := f.GetArgs()
+	switch f.FuncName.L {
+	case ast.EQ:
+		if _, ok := args[0].(*expression.Constant); ok {
+			args = []expression.Expression{args[1], args[0]}
+		}
+	case ast.In:
+	default:
+		return false
+	}
+	col, ok := args[0].(*expression.Column)
+	if !ok || (col.RetType.Tp != mysql.TypeEnum && col.RetType.Tp != mysql.TypeSet) {
+		return false
+	}
+	// Use a new statement context, so the conversion doesn't append warnings.
+	sc := new(variable.StatementContext)
+	for _, arg := range args[1:] {
+		con, ok := arg.(*expression.Constant)
+		if !ok || con.Value.IsNull() {
+			return false
+		}
+		if _, err := con.Value.ConvertTo(sc, col.RetType); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 // PredicatePushDown implements LogicalPlan PredicatePushDown interface.
 func (p *TableDual) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
 	return predicates, p, nil
@@ -346,6 +394,13 @@ func (p *Limit) PredicatePushDown(predicates []expression.Expression) ([]express
 	return predicates, p, errors.Trace(err)
 }
 
+// PredicatePushDown implements LogicalPlan PredicatePushDown interface.
+func (p *LogicalWindow) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
+	// The window functions are computed over the rows of the partitions, so no condition can be pushed down.
+	_, _, err := p.baseLogicalPlan.PredicatePushDown(nil)
+	return predicates, p, errors.Trace(err)
+}
+
 // PredicatePushDown implements LogicalPlan PredicatePushDown interface.
 func (p *MaxOneRow) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
 	// MaxOneRow forbids any condition to push down.
@@ -65,6 +65,26 @@ func (p *PhysicalHashJoin) ResolveIndices() {
 	}
 }
 
+// ResolveIndices implements Plan interface.
+func (p *PhysicalIndexJoin) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	outerSchema := p.children[0].Schema()
+	innerSchema := p.children[1].Schema()
+	for i := range p.OuterJoinKeys {
+		p.OuterJoinKeys[i].ResolveIndices(outerSchema)
+		p.InnerJoinKeys[i].ResolveIndices(innerSchema)
+	}
+	for _, expr := range p.LeftConditions {
+		expr.ResolveIndices(outerSchema)
+	}
+	for _, expr := range p.RightConditions {
+		expr.ResolveIndices(innerSchema)
+	}
+	for _, expr := range p.OtherConditions {
+		expr.ResolveIndices(expression.MergeSchema(outerSchema, innerSchema))
+	}
+}
+
 // ResolveIndices implements Plan interface.
 func (p *PhysicalHashSemiJoin) ResolveIndices() {
 	p.basePlan.ResolveIndices()
@@ -74,6 +94,10 @@ func (p *PhysicalHashSemiJoin) ResolveIndices() {
 		fun.GetArgs()[0].ResolveIndices(lSchema)
 		fun.GetArgs()[1].ResolveIndices(rSchema)
 	}
+	for _, fun := range p.NAEQConditions {
+		fun.GetArgs()[0].ResolveIndices(lSchema)
+		fun.GetArgs()[1].ResolveIndices(rSchema)
+	}
 	for _, expr := range p.LeftConditions {
 		expr.ResolveIndices(lSchema)
 	}
@@ -107,6 +131,7 @@ func (p *PhysicalMergeJoin) ResolveIndices() {
 
 // ResolveIndices implements Plan interface.
 func (p *PhysicalUnionScan) ResolveIndices() {
+	p.basePlan.ResolveIndices()
 	for _, expr := range p.Conditions {
 		expr.ResolveIndices(p.children[0].Schema())
 	}
@@ -165,6 +190,32 @@ func (p *PhysicalAggregation) ResolveIndices() {
 	}
 }
 
+// ResolveIndices implements Plan interface.
+func (p *LogicalWindow) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	resolveWindowIndices(p.WindowFuncs, p.PartitionBy, p.OrderBy, p.children[0].Schema())
+}
+
+// ResolveIndices implements Plan interface.
+func (p *PhysicalWindow) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	resolveWindowIndices(p.WindowFuncs, p.PartitionBy, p.OrderBy, p.children[0].Schema())
+}
+
+func resolveWindowIndices(funcs []*expression.WindowFunc, partitionBy, orderBy []*ByItems, schema *expression.Schema) {
+	for _, fun := range funcs {
+		for _, arg := range fun.Args {
+			arg.ResolveIndices(schema)
+		}
+	}
+	for _, item := range partitionBy {
+		item.Expr.ResolveIndices(schema)
+	}
+	for _, item := range orderBy {
+		item.Expr.ResolveIndices(schema)
+	}
+}
+
 // ResolveIndices implements Plan interface.
 func (p *Sort) ResolveIndices() {
 	p.basePlan.ResolveIndices()
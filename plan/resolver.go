@@ -15,6 +15,7 @@ package plan
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -71,6 +72,8 @@ type resolverContext struct {
 	derivedTableMap map[string]int
 	// tableSources collected in from clause.
 	tables []*ast.TableSource
+	// The join node of from clause.
+	tableRefs *ast.Join
 	// result fields collected in select field list.
 	fieldList []*ast.ResultField
 	// result fields collected in group by clause.
@@ -84,6 +87,8 @@ type resolverContext struct {
 	// When visiting TableRefs, tables in this context are not available
 	// because it is being collected.
 	inTableRefs bool
+	// When visiting lateral derived table, tables collected before it are available.
+	inLateral bool
 	// When visiting on condition only tables in current join node are available.
 	inOnCondition bool
 	// When visiting field list, fieldList in this context are not available.
@@ -94,6 +99,8 @@ type resolverContext struct {
 	inHaving bool
 	// When visiting having, checks if the expr is an aggregate function expr.
 	inHavingAgg bool
+	// The nesting level of the aggregate functions being visited.
+	aggLevel int
 	// OrderBy clause has different resolving rule than group by.
 	inOrderBy bool
 	// When visiting column name in ByItem, we should know if the column name is in an expression.
@@ -108,6 +115,23 @@ type resolverContext struct {
 	inShow bool
 }
 
+// clause returns the clause being resolved, it's used in the error messages.
+func (ctx *resolverContext) clause() clauseCode {
+	switch {
+	case ctx.inOnCondition:
+		return onClause
+	case ctx.inFieldList:
+		return fieldList
+	case ctx.inGroupBy:
+		return groupByClause
+	case ctx.inHaving:
+		return havingClause
+	case ctx.inOrderBy:
+		return orderByClause
+	}
+	return whereClause
+}
+
 // currentContext gets the current resolverContext.
 func (nr *nameResolver) currentContext() *resolverContext {
 	stackLen := len(nr.contextStack)
@@ -152,6 +176,7 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 		if ctx.inHaving {
 			ctx.inHavingAgg = true
 		}
+		ctx.aggLevel++
 	case *ast.AlterTableStmt:
 		nr.pushContext()
 		for _, spec := range v.Specs {
@@ -180,6 +205,8 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 	case *ast.CreateTableStmt:
 		nr.pushContext()
 		nr.currentContext().inCreateOrDropTable = true
+	case *ast.CreateTriggerStmt:
+		nr.pushContext()
 	case *ast.DeleteStmt:
 		nr.pushContext()
 	case *ast.DeleteTableList:
@@ -191,6 +218,10 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 		nr.currentContext().inCreateOrDropTable = true
 	case *ast.DropIndexStmt:
 		nr.pushContext()
+	case *ast.DropTriggerStmt:
+		if v.Schema.L == "" {
+			v.Schema = nr.DefaultSchema
+		}
 	case *ast.FieldList:
 		nr.currentContext().inFieldList = true
 	case *ast.GroupByClause:
@@ -201,6 +232,8 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 		nr.pushContext()
 	case *ast.LoadDataStmt:
 		nr.pushContext()
+	case *ast.SplitRegionStmt:
+		nr.pushContext()
 	case *ast.Join:
 		nr.pushJoin(v)
 	case *ast.OnCondition:
@@ -226,6 +259,10 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 		nr.fillShowFields(v)
 	case *ast.TableRefsClause:
 		nr.currentContext().inTableRefs = true
+	case *ast.TableSource:
+		if v.Lateral {
+			nr.currentContext().inLateral = true
+		}
 	case *ast.TruncateTableStmt:
 		nr.pushContext()
 	case *ast.UnionStmt:
@@ -246,6 +283,7 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		if ctx.inHaving {
 			ctx.inHavingAgg = false
 		}
+		ctx.aggLevel--
 	case *ast.AlterTableStmt:
 		nr.popContext()
 	case *ast.AnalyzeTableStmt:
@@ -258,6 +296,8 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.popContext()
 	case *ast.CreateTableStmt:
 		nr.popContext()
+	case *ast.CreateTriggerStmt:
+		nr.popContext()
 	case *ast.DeleteTableList:
 		nr.currentContext().inDeleteTableList = false
 	case *ast.DoStmt:
@@ -267,6 +307,7 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 	case *ast.DropTableStmt:
 		nr.popContext()
 	case *ast.TableSource:
+		nr.currentContext().inLateral = false
 		nr.handleTableSource(v)
 	case *ast.OnCondition:
 		nr.currentContext().inOnCondition = false
@@ -274,7 +315,9 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.handleJoin(v)
 		nr.popJoin()
 	case *ast.TableRefsClause:
-		nr.currentContext().inTableRefs = false
+		ctx := nr.currentContext()
+		ctx.inTableRefs = false
+		ctx.tableRefs = v.TableRefs
 	case *ast.FieldList:
 		nr.handleFieldList(v)
 		nr.currentContext().inFieldList = false
@@ -330,6 +373,8 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.popContext()
 	case *ast.LoadDataStmt:
 		nr.popContext()
+	case *ast.SplitRegionStmt:
+		nr.popContext()
 	case *ast.DeleteStmt:
 		nr.popContext()
 	case *ast.UpdateStmt:
@@ -462,6 +507,10 @@ func (nr *nameResolver) handleJoin(j *ast.Join) {
 		j.SetResultFields(j.Left.GetResultFields())
 		return
 	}
+	if j.NaturalJoin || len(j.Using) > 0 {
+		nr.handleNaturalOrUsingJoin(j)
+		return
+	}
 	leftLen := len(j.Left.GetResultFields())
 	rightLen := len(j.Right.GetResultFields())
 	rfs := make([]*ast.ResultField, leftLen+rightLen)
@@ -470,6 +519,55 @@ func (nr *nameResolver) handleJoin(j *ast.Join) {
 	j.SetResultFields(rfs)
 }
 
+// handleNaturalOrUsingJoin sets result fields for natural join or join with using clause.
+// The common columns are coalesced and put in front of the other columns.
+func (nr *nameResolver) handleNaturalOrUsingJoin(j *ast.Join) {
+	firstRfs, secondRfs := j.Left.GetResultFields(), j.Right.GetResultFields()
+	if j.Tp == ast.RightJoin {
+		firstRfs, secondRfs = secondRfs, firstRfs
+	}
+	secondNames := make(map[string]struct{}, len(secondRfs))
+	for _, rf := range secondRfs {
+		secondNames[resultFieldName(rf)] = struct{}{}
+	}
+	commonNames := make(map[string]struct{}, len(firstRfs))
+	if j.NaturalJoin {
+		for _, rf := range firstRfs {
+			if _, ok := secondNames[resultFieldName(rf)]; ok {
+				commonNames[resultFieldName(rf)] = struct{}{}
+			}
+		}
+	} else {
+		for _, col := range j.Using {
+			commonNames[col.Name.L] = struct{}{}
+		}
+	}
+	rfs := make([]*ast.ResultField, 0, len(firstRfs)+len(secondRfs))
+	for _, rf := range firstRfs {
+		if _, ok := commonNames[resultFieldName(rf)]; ok {
+			rfs = append(rfs, rf)
+		}
+	}
+	for _, rf := range firstRfs {
+		if _, ok := commonNames[resultFieldName(rf)]; !ok {
+			rfs = append(rfs, rf)
+		}
+	}
+	for _, rf := range secondRfs {
+		if _, ok := commonNames[resultFieldName(rf)]; !ok {
+			rfs = append(rfs, rf)
+		}
+	}
+	j.SetResultFields(rfs)
+}
+
+func resultFieldName(rf *ast.ResultField) string {
+	if rf.ColumnAsName.L != "" {
+		return rf.ColumnAsName.L
+	}
+	return rf.Column.Name.L
+}
+
 // handleColumnName looks up and sets ResultField for
 // the column name.
 func (nr *nameResolver) handleColumnName(cn *ast.ColumnNameExpr) {
@@ -482,6 +580,10 @@ func (nr *nameResolver) handleColumnName(cn *ast.ColumnNameExpr) {
 
 	// Try to resolve the column name form top to bottom in the context stack.
 	for i := len(nr.contextStack) - 1; i >= 0; i-- {
+		if i < len(nr.contextStack)-1 && ctx.aggLevel > 0 && nr.resolveColumnInOuterAgg(nr.contextStack[i], cn) {
+			ctx.useOuterContext = true
+			return
+		}
 		if nr.resolveColumnNameInContext(nr.contextStack[i], cn) {
 			// Column is already resolved or encountered an error.
 			if i < len(nr.contextStack)-1 {
@@ -491,13 +593,17 @@ func (nr *nameResolver) handleColumnName(cn *ast.ColumnNameExpr) {
 			return
 		}
 	}
-	nr.Err = errors.Errorf("unknown column %s", cn.Name.Name.L)
+	nr.Err = ErrUnknownColumn.GenByArgs(cn.Name.OrigColName(), clauseMsg[ctx.clause()])
 }
 
 // resolveColumnNameInContext looks up and sets ResultField for a column with the ctx.
 func (nr *nameResolver) resolveColumnNameInContext(ctx *resolverContext, cn *ast.ColumnNameExpr) bool {
 	if ctx.inTableRefs {
-		// In TableRefsClause, column reference only in join on condition which is handled before.
+		// In TableRefsClause, column reference only in join on condition which is handled before,
+		// or in lateral derived table which can refer to the tables before it.
+		if ctx.inLateral {
+			return nr.resolveColumnInTableSources(cn, ctx.tables)
+		}
 		return false
 	}
 	if ctx.inFieldList {
@@ -584,13 +690,27 @@ func (nr *nameResolver) resolveColumnNameInContext(ctx *resolverContext, cn *ast
 	return nr.resolveColumnInTableSources(cn, ctx.tables)
 }
 
+// resolveColumnInOuterAgg resolves the column in an aggregate function of subquery with the outer context.
+// The aggregate function may be aggregated in the outer query, so the column in having clause
+// can refer to the tables as it does in the aggregate function of having clause.
+// For example: select a from t group by a having exists (select 1 from s where s.y > sum(t.b));
+func (nr *nameResolver) resolveColumnInOuterAgg(ctx *resolverContext, cn *ast.ColumnNameExpr) bool {
+	if !ctx.inHaving || ctx.inHavingAgg {
+		return false
+	}
+	ctx.inHavingAgg = true
+	found := nr.resolveColumnNameInContext(ctx, cn)
+	ctx.inHavingAgg = false
+	return found
+}
+
 // resolveColumnNameInOnCondition resolves the column name in current join.
 func (nr *nameResolver) resolveColumnNameInOnCondition(cn *ast.ColumnNameExpr) {
 	ctx := nr.currentContext()
 	join := ctx.joinNodeStack[len(ctx.joinNodeStack)-1]
 	tableSources := appendTableSources(nil, join)
 	if !nr.resolveColumnInTableSources(cn, tableSources) {
-		nr.Err = errors.Errorf("unknown column name %s", cn.Name.Name.O)
+		nr.Err = ErrUnknownColumn.GenByArgs(cn.Name.OrigColName(), clauseMsg[onClause])
 	}
 }
 
@@ -725,7 +845,9 @@ func (nr *nameResolver) createResultFields(field *ast.SelectField) (rfs []*ast.R
 			return
 		}
 		tableRfs := []*ast.ResultField{}
-		if field.WildCard.Table.L == "" {
+		if field.WildCard.Table.L == "" && ctx.tableRefs != nil {
+			tableRfs = append(tableRfs, ctx.tableRefs.GetResultFields()...)
+		} else if field.WildCard.Table.L == "" {
 			for _, v := range ctx.tables {
 				tableRfs = append(tableRfs, v.GetResultFields()...)
 			}
@@ -820,7 +942,7 @@ func (nr *nameResolver) tableUniqueName(schema, table model.CIStr) string {
 func (nr *nameResolver) handlePosition(pos *ast.PositionExpr) {
 	ctx := nr.currentContext()
 	if pos.N < 1 || pos.N > len(ctx.fieldList) {
-		nr.Err = errors.Errorf("Unknown column '%d'", pos.N)
+		nr.Err = ErrUnknownColumn.GenByArgs(strconv.Itoa(pos.N), clauseMsg[ctx.clause()])
 		return
 	}
 	matched := ctx.fieldList[pos.N-1]
@@ -893,9 +1015,17 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 			mysql.TypeVarchar, mysql.TypeVarchar}
 	case ast.ShowColumns:
 		names = table.ColDescFieldNames(s.Full)
-	case ast.ShowWarnings:
-		names = []string{"Level", "Code", "Message"}
-		ftypes = []byte{mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar}
+	case ast.ShowWarnings, ast.ShowErrors:
+		if s.CountWarningsOrErrors {
+			names = []string{"@@session.warning_count"}
+			if s.Tp == ast.ShowErrors {
+				names = []string{"@@session.error_count"}
+			}
+			ftypes = []byte{mysql.TypeLonglong}
+		} else {
+			names = []string{"Level", "Code", "Message"}
+			ftypes = []byte{mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar}
+		}
 	case ast.ShowCharset:
 		names = []string{"Charset", "Description", "Default collation", "Maxlen"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong}
@@ -918,7 +1048,14 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 			"sql_mode", "Definer", "character_set_client", "collation_connection", "Database Collation"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar,
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar}
-	case ast.ShowProcedureStatus, ast.ShowEvents:
+	case ast.ShowEvents:
+		names = []string{"Db", "Name", "Time zone", "Definer", "Type", "Execute At", "Interval Value",
+			"Interval Field", "Starts", "Ends", "Status", "Originator", "character_set_client",
+			"collation_connection", "Database Collation"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar,
+			mysql.TypeDatetime, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeDatetime, mysql.TypeDatetime,
+			mysql.TypeVarchar, mysql.TypeInt24, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar}
+	case ast.ShowProcedureStatus:
 		names = []string{}
 		ftypes = []byte{}
 	case ast.ShowIndex:
@@ -932,6 +1069,17 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 		names = []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info"}
 		ftypes = []byte{mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar,
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar, mysql.TypeString}
+	case ast.ShowRegions:
+		names = []string{"REGION_ID", "START_KEY", "END_KEY", "LEADER_ID", "LEADER_STORE_ID", "PEERS"}
+		ftypes = []byte{mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar,
+			mysql.TypeLonglong, mysql.TypeLonglong, mysql.TypeVarchar}
+	case ast.ShowHotspots:
+		names = []string{"Db_name", "Table_name", "Index_name", "Read_QPS", "Write_QPS", "Append_ratio"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar,
+			mysql.TypeDouble, mysql.TypeDouble, mysql.TypeDouble}
+	case ast.ShowMasterStatus:
+		names = []string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB", "Executed_Gtid_Set"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar}
 	}
 	for i, name := range names {
 		f := &ast.ResultField{
@@ -953,6 +1101,10 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 
 	if s.Pattern != nil && s.Pattern.Expr == nil {
 		rf := fields[0]
+		if s.Tp == ast.ShowEvents {
+			// SHOW EVENTS LIKE matches the event name.
+			rf = fields[1]
+		}
 		s.Pattern.Expr = &ast.ColumnNameExpr{
 			Name: &ast.ColumnName{Name: rf.ColumnAsName},
 		}
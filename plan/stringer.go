@@ -42,6 +42,17 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		str = fmt.Sprintf("Index(%s.%s)%v", x.Table.Name.L, x.Index.Name.L, x.Ranges)
 	case *PhysicalTableScan:
 		str = fmt.Sprintf("Table(%s)", x.Table.Name.L)
+	case *PointGet:
+		if x.Index != nil {
+			values := make([]string, 0, len(x.IndexValues))
+			for _, d := range x.IndexValues {
+				v, _ := d.ToString()
+				values = append(values, v)
+			}
+			str = fmt.Sprintf("PointGet(%s.%s)%v", x.Table.Name.L, x.Index.Name.L, values)
+		} else {
+			str = fmt.Sprintf("PointGet(%s)%v", x.Table.Name.L, x.Handles)
+		}
 	case *PhysicalHashJoin:
 		last := len(idxs) - 1
 		idx := idxs[last]
@@ -98,6 +109,18 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		str = "Lock"
 	case *ShowDDL:
 		str = "ShowDDL"
+	case *ShowDDLOwner:
+		str = "ShowDDLOwner"
+	case *TransferDDLOwner:
+		str = "TransferDDLOwner"
+	case *ShowSlow:
+		str = "ShowSlow"
+	case *ReloadExprPushdownBlacklist:
+		str = "ReloadExprPushdownBlacklist"
+	case *ReloadResourceGroups:
+		str = "ReloadResourceGroups"
+	case *ReloadConfig:
+		str = "ReloadConfig"
 	case *Sort:
 		str = "Sort"
 		if x.ExecLimit != nil {
@@ -115,6 +138,11 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 			r := eq.GetArgs()[1].String()
 			str += fmt.Sprintf("(%s,%s)", l, r)
 		}
+		for _, eq := range x.NAEQConditions {
+			l := eq.GetArgs()[0].String()
+			r := eq.GetArgs()[1].String()
+			str += fmt.Sprintf("(%s,%s)", l, r)
+		}
 	case *Union:
 		last := len(idxs) - 1
 		idx := idxs[last]
@@ -155,6 +183,10 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 			}
 		}
 		str += ")"
+	case *LogicalWindow:
+		str = fmt.Sprintf("Window(%s)", x.WindowFuncs)
+	case *PhysicalWindow:
+		str = fmt.Sprintf("Window(%s)", x.WindowFuncs)
 	case *Cache:
 		str = "Cache"
 	case *PhysicalTableReader:
@@ -172,9 +204,9 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		strs = strs[:idx]
 		idxs = idxs[:last]
 		str = "IndexJoin{" + strings.Join(children, "->") + "}"
-		for i := range x.outerJoinKeys {
-			l := x.outerJoinKeys[i]
-			r := x.innerJoinKeys[i]
+		for i := range x.OuterJoinKeys {
+			l := x.OuterJoinKeys[i]
+			r := x.InnerJoinKeys[i]
 			str += fmt.Sprintf("(%s,%s)", l, r)
 		}
 	default:
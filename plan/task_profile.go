@@ -109,11 +109,15 @@ func (p *PhysicalIndexJoin) attach2TaskProfile(tasks ...taskProfile) taskProfile
 	rTask := finishCopTask(tasks[1].copy(), p.ctx, p.allocator)
 	np := p.Copy()
 	np.SetChildren(lTask.plan(), rTask.plan())
+	// The inner task is the lookup of a single key, which is done for every outer row.
+	cnt := lTask.count() * rTask.count()
+	if p.Outer && cnt < lTask.count() {
+		cnt = lTask.count()
+	}
 	return &rootTaskProfile{
-		p: np,
-		// TODO: we will estimate the cost and count more precisely.
-		cst: lTask.cost(),
-		cnt: lTask.count() + rTask.count(),
+		p:   np,
+		cst: lTask.cost() + lTask.count()*(cpuFactor+rTask.cost()),
+		cnt: cnt,
 	}
 }
 
@@ -347,7 +351,7 @@ func (p *Union) attach2TaskProfile(profiles ...taskProfile) taskProfile {
 func (sel *Selection) attach2TaskProfile(profiles ...taskProfile) taskProfile {
 	profile := finishCopTask(profiles[0].copy(), sel.ctx, sel.allocator)
 	profile.addCost(profile.count() * cpuFactor)
-	profile.setCount(profile.count() * selectionFactor)
+	profile.setCount(profile.count() * selectivity(sel.ctx, sel.Conditions))
 	profile = attachPlan2TaskProfile(sel.Copy(), profile)
 	return profile
 }
@@ -414,8 +418,16 @@ func (p *PhysicalAggregation) attach2TaskProfile(profiles ...taskProfile) taskPr
 	if profiles[0].plan() == nil {
 		return profiles[0]
 	}
-	// TODO: We only consider hash aggregation here.
 	profile := profiles[0].copy()
+	if p.AggType == StreamedAgg {
+		// The stream aggregation isn't pushed down, because the partial results of the coprocessor are not sorted
+		// by the group by columns. It needn't keep the groups in memory like the hash aggregation.
+		profile = finishCopTask(profile, p.ctx, p.allocator)
+		attachPlan2TaskProfile(p.Copy(), profile)
+		profile.addCost(profile.count() * cpuFactor)
+		profile.setCount(profile.count() * aggFactor)
+		return profile
+	}
 	if cop, ok := profile.(*copTaskProfile); ok {
 		partialAgg, finalAgg := p.newPartialAggregate()
 		if partialAgg != nil {
@@ -431,14 +443,15 @@ func (p *PhysicalAggregation) attach2TaskProfile(profiles ...taskProfile) taskPr
 				cop.cst += cop.cnt * cpuFactor
 				cop.cnt = cop.cnt * aggFactor
 			}
+			profile = finishCopTask(cop, p.ctx, p.allocator)
+			attachPlan2TaskProfile(finalAgg, profile)
+			return profile
 		}
 		profile = finishCopTask(cop, p.ctx, p.allocator)
-		attachPlan2TaskProfile(finalAgg, profile)
-	} else {
-		np := p.Copy()
-		attachPlan2TaskProfile(np, profile)
-		profile.addCost(profile.count() * cpuFactor)
-		profile.setCount(profile.count() * aggFactor)
 	}
+	attachPlan2TaskProfile(p.Copy(), profile)
+	// Every row is hashed and kept in the hash table until all the rows are read, so it costs like sorting.
+	profile.addCost(profile.count() * (cpuFactor + memoryFactor))
+	profile.setCount(profile.count() * aggFactor)
 	return profile
 }
@@ -45,3 +45,25 @@ func (a *AggregateFuncExtractor) Leave(n ast.Node) (ast.Node, bool) {
 	}
 	return n, true
 }
+
+// windowFuncExtractor visits Expr tree and collects the WindowFuncExprs of the current query.
+type windowFuncExtractor struct {
+	windowFuncs []*ast.WindowFuncExpr
+}
+
+// Enter implements Visitor interface.
+func (w *windowFuncExtractor) Enter(n ast.Node) (ast.Node, bool) {
+	switch n.(type) {
+	case *ast.SelectStmt, *ast.UnionStmt:
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements Visitor interface.
+func (w *windowFuncExtractor) Leave(n ast.Node) (ast.Node, bool) {
+	if v, ok := n.(*ast.WindowFuncExpr); ok {
+		w.windowFuncs = append(w.windowFuncs, v)
+	}
+	return n, true
+}
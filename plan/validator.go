@@ -39,6 +39,7 @@ type validator struct {
 	wildCardCount int
 	inPrepare     bool
 	inAggregate   bool
+	inWindow      bool
 }
 
 func (v *validator) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
@@ -50,6 +51,13 @@ func (v *validator) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
 			return in, true
 		}
 		v.inAggregate = true
+	case *ast.WindowFuncExpr:
+		if v.inAggregate || v.inWindow {
+			// Window function can not be contained in aggregate function or window function.
+			v.err = ErrInvalidWindowFuncUse.GenByArgs(node.F)
+			return in, true
+		}
+		v.inWindow = true
 	case *ast.CreateTableStmt:
 		v.checkCreateTableGrammar(node)
 		if v.err != nil {
@@ -73,6 +81,8 @@ func (v *validator) Leave(in ast.Node) (out ast.Node, ok bool) {
 	switch x := in.(type) {
 	case *ast.AggregateFuncExpr:
 		v.inAggregate = false
+	case *ast.WindowFuncExpr:
+		v.inWindow = false
 	case *ast.CreateTableStmt:
 		v.checkAutoIncrement(x)
 	case *ast.ParamMarkerExpr:
@@ -75,6 +75,9 @@ func (s *testValidatorSuite) TestValidator(c *C) {
 		{"alter table t add column c int auto_increment key, auto_increment=10", true,
 			errors.New("[autoid:3]No support for setting auto_increment using alter_table")},
 		{"alter table t add column c int auto_increment key", true, nil},
+		{"select a, sum(b) over (partition by a), sum(count(b)) over (order by a) from t group by a", true, nil},
+		{"select count(row_number() over ()) from t", true, plan.ErrInvalidWindowFuncUse},
+		{"select sum(rank() over (order by a)) over () from t", true, plan.ErrInvalidWindowFuncUse},
 	}
 
 	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
@@ -54,6 +54,12 @@ type UserPrivileges struct {
 	*Handle
 }
 
+// NewUserPrivileges creates a UserPrivileges which verifies the privileges of user@host
+// without connection verification, it is used to run statements on behalf of a definer.
+func NewUserPrivileges(handle *Handle, user, host string) *UserPrivileges {
+	return &UserPrivileges{user: user, host: host, Handle: handle}
+}
+
 // RequestVerification implements the Manager interface.
 func (p *UserPrivileges) RequestVerification(db, table, column string, priv mysql.PrivilegeType) bool {
 	if !Enable || SkipWithGrant {
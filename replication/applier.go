@@ -0,0 +1,282 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/table"
+)
+
+// positionTable saves the applied binlog position of each master, it is updated in the same
+// transaction as the applied rows, so replication can be resumed exactly after restarts.
+const (
+	positionTable          = "mysql.tidb_replication_position"
+	createPositionTableSQL = `CREATE TABLE IF NOT EXISTS ` + positionTable + ` (
+		server_id BIGINT UNSIGNED NOT NULL PRIMARY KEY,
+		binlog_name VARCHAR(512) NOT NULL,
+		binlog_pos BIGINT UNSIGNED NOT NULL,
+		gtid VARCHAR(1024) NOT NULL DEFAULT '');`
+)
+
+// applier applies the binlog events from the master to TiDB.
+type applier struct {
+	se       tidb.Session
+	serverID uint32
+	pos      Position
+	gtid     string
+	inTxn    bool
+}
+
+func newApplier(se tidb.Session, serverID uint32) *applier {
+	return &applier{se: se, serverID: serverID}
+}
+
+// loadPosition creates the position table if needed and loads the saved position.
+func (a *applier) loadPosition() error {
+	if err := a.execute(createPositionTableSQL); err != nil {
+		return errors.Trace(err)
+	}
+	rs, err := a.se.Execute(fmt.Sprintf("SELECT binlog_name, binlog_pos, gtid FROM %s WHERE server_id = %d", positionTable, a.serverID))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	rows, err := tidb.GetRows(rs[0])
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(rows) > 0 {
+		a.pos = Position{Name: rows[0][0].GetString(), Pos: uint32(rows[0][1].GetUint64())}
+		a.gtid = rows[0][2].GetString()
+	}
+	return nil
+}
+
+func (a *applier) savePosition() error {
+	return errors.Trace(a.executePrepared(fmt.Sprintf("REPLACE INTO %s VALUES (?, ?, ?, ?)", positionTable),
+		uint64(a.serverID), a.pos.Name, uint64(a.pos.Pos), a.gtid))
+}
+
+func (a *applier) apply(e *event) error {
+	if e.header.eventType == heartbeatEvent {
+		return nil
+	}
+	if r, ok := e.data.(*rotateEventData); ok {
+		a.pos = r.position
+		return nil
+	}
+	if e.header.logPos > 0 {
+		a.pos.Pos = e.header.logPos
+	}
+	switch x := e.data.(type) {
+	case *gtidEventData:
+		a.gtid = x.gtid
+	case *queryEventData:
+		return errors.Trace(a.applyQuery(x))
+	case *xidEventData:
+		return errors.Trace(a.commit())
+	case *rowsEventData:
+		return errors.Trace(a.applyRows(x))
+	}
+	return nil
+}
+
+func (a *applier) applyQuery(q *queryEventData) error {
+	switch strings.ToUpper(strings.TrimSpace(q.query)) {
+	case "BEGIN":
+		a.inTxn = true
+		return errors.Trace(a.execute("BEGIN"))
+	case "COMMIT":
+		return errors.Trace(a.commit())
+	}
+	// DDL statements are replayed, so the schema in TiDB tracks the schema of the master.
+	if q.schema != "" {
+		if err := a.execute("USE " + quoteName(q.schema)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	log.Infof("[replication] apply query %s at %s", q.query, a.pos)
+	if err := a.execute(q.query); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(a.savePosition())
+}
+
+func (a *applier) commit() error {
+	if err := a.savePosition(); err != nil {
+		return errors.Trace(err)
+	}
+	if !a.inTxn {
+		return nil
+	}
+	a.inTxn = false
+	return errors.Trace(a.execute("COMMIT"))
+}
+
+// rollback rolls back the transaction in progress, it's called when replication is interrupted.
+func (a *applier) rollback() {
+	if !a.inTxn {
+		return
+	}
+	a.inTxn = false
+	if err := a.execute("ROLLBACK"); err != nil {
+		log.Errorf("[replication] rollback failed: %v", errors.ErrorStack(err))
+	}
+}
+
+func (a *applier) applyRows(e *rowsEventData) error {
+	t := e.table
+	is := sessionctx.GetDomain(a.se).InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr(t.schema), model.NewCIStr(t.table))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cols := tbl.Cols()
+	if len(cols) < len(t.columnTypes) {
+		return errors.Errorf("table %s.%s has %d columns, less than %d columns of the master",
+			t.schema, t.table, len(cols), len(t.columnTypes))
+	}
+	name := quoteName(t.schema) + "." + quoteName(t.table)
+	switch e.eventType {
+	case writeRowsEventV2:
+		for _, row := range e.rows {
+			names, args := a.columnValues(t, cols, row)
+			sql := fmt.Sprintf("REPLACE INTO %s (%s) VALUES (%s)", name, strings.Join(names, ", "), placeholders(len(args)))
+			if err = a.executePrepared(sql, args...); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	case deleteRowsEventV2:
+		for _, row := range e.rows {
+			where, args := a.whereClause(t, cols, row)
+			sql := fmt.Sprintf("DELETE FROM %s WHERE %s LIMIT 1", name, where)
+			if err = a.executePrepared(sql, args...); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	case updateRowsEventV2:
+		for i := 0; i+1 < len(e.rows); i += 2 {
+			names, args := a.columnValues(t, cols, e.rows[i+1])
+			where, whereArgs := a.whereClause(t, cols, e.rows[i])
+			sets := make([]string, len(names))
+			for j, name := range names {
+				sets[j] = name + " = ?"
+			}
+			sql := fmt.Sprintf("UPDATE %s SET %s WHERE %s LIMIT 1", name, strings.Join(sets, ", "), where)
+			if err = a.executePrepared(sql, append(args, whereArgs...)...); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
+
+// columnValues returns the quoted names and the values of the columns in the row image.
+func (a *applier) columnValues(t *tableMap, cols []*table.Column, row []interface{}) ([]string, []interface{}) {
+	names := make([]string, 0, len(row))
+	args := make([]interface{}, 0, len(row))
+	for i, v := range row {
+		if _, ok := v.(absentColumn); ok {
+			continue
+		}
+		names = append(names, quoteName(cols[i].Name.O))
+		args = append(args, convertValue(t.columnTypes[i], cols[i], v))
+	}
+	return names, args
+}
+
+// whereClause builds the condition to locate the row by its before image. Floating point
+// columns are skipped if possible, because they may not be equal after conversion.
+func (a *applier) whereClause(t *tableMap, cols []*table.Column, row []interface{}) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	for _, skipFloat := range []bool{true, false} {
+		for i, v := range row {
+			if _, ok := v.(absentColumn); ok {
+				continue
+			}
+			tp := t.columnTypes[i]
+			if skipFloat && (tp == mysql.TypeFloat || tp == mysql.TypeDouble) {
+				continue
+			}
+			conds = append(conds, quoteName(cols[i].Name.O)+" <=> ?")
+			args = append(args, convertValue(tp, cols[i], v))
+		}
+		if len(conds) > 0 {
+			break
+		}
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// convertValue converts the integers to unsigned according to the column in TiDB, since the
+// table map event doesn't have the signedness of the columns.
+func convertValue(tp byte, col *table.Column, v interface{}) interface{} {
+	i, ok := v.(int64)
+	if !ok || !mysql.HasUnsignedFlag(col.Flag) {
+		return v
+	}
+	switch tp {
+	case mysql.TypeTiny:
+		return uint64(uint8(i))
+	case mysql.TypeShort:
+		return uint64(uint16(i))
+	case mysql.TypeInt24:
+		return uint64(i) & 0xffffff
+	case mysql.TypeLong:
+		return uint64(uint32(i))
+	}
+	return uint64(i)
+}
+
+func (a *applier) execute(sql string) error {
+	rs, err := a.se.Execute(sql)
+	closeRecordSets(rs)
+	return errors.Trace(err)
+}
+
+func (a *applier) executePrepared(sql string, args ...interface{}) error {
+	stmtID, _, _, err := a.se.PrepareStmt(sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer a.se.DropPreparedStmt(stmtID)
+	rs, err := a.se.ExecutePreparedStmt(stmtID, args...)
+	if rs != nil {
+		closeRecordSets([]ast.RecordSet{rs})
+	}
+	return errors.Trace(err)
+}
+
+func closeRecordSets(rss []ast.RecordSet) {
+	for _, rs := range rss {
+		rs.Close()
+	}
+}
+
+func quoteName(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
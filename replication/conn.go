@@ -0,0 +1,331 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util"
+)
+
+const (
+	maxPacketSize      = 1<<24 - 1
+	nativePasswordAuth = "mysql_native_password"
+	dialTimeout        = 10 * time.Second
+)
+
+// masterConn is a connection to the MySQL master, it speaks just enough of the client
+// protocol to authenticate, run simple queries and receive the binlog stream.
+type masterConn struct {
+	conn     net.Conn
+	br       *bufio.Reader
+	sequence uint8
+}
+
+// dialMaster connects to the master and authenticates with the native password method.
+func dialMaster(addr, user, password string) (*masterConn, error) {
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	mc := &masterConn{
+		conn: conn,
+		br:   bufio.NewReaderSize(conn, 16*1024),
+	}
+	if err = mc.handshake(user, password); err != nil {
+		conn.Close()
+		return nil, errors.Trace(err)
+	}
+	return mc, nil
+}
+
+func (mc *masterConn) Close() error {
+	return errors.Trace(mc.conn.Close())
+}
+
+// readPacket reads a whole packet, packets larger than 16MB are merged.
+func (mc *masterConn) readPacket() ([]byte, error) {
+	var data []byte
+	var header [4]byte
+	for {
+		if _, err := io.ReadFull(mc.br, header[:]); err != nil {
+			return nil, errors.Trace(err)
+		}
+		length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+		if header[3] != mc.sequence {
+			return nil, errors.Errorf("invalid sequence %d != %d", header[3], mc.sequence)
+		}
+		mc.sequence++
+		buf := make([]byte, length)
+		if _, err := io.ReadFull(mc.br, buf); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if data == nil {
+			data = buf
+		} else {
+			data = append(data, buf...)
+		}
+		if length < maxPacketSize {
+			return data, nil
+		}
+	}
+}
+
+func (mc *masterConn) writePacket(data []byte) error {
+	for {
+		length := len(data)
+		if length > maxPacketSize {
+			length = maxPacketSize
+		}
+		header := []byte{byte(length), byte(length >> 8), byte(length >> 16), mc.sequence}
+		if _, err := mc.conn.Write(append(header, data[:length]...)); err != nil {
+			return errors.Trace(err)
+		}
+		mc.sequence++
+		data = data[length:]
+		if length < maxPacketSize {
+			return nil
+		}
+	}
+}
+
+// writeCommand starts a new command phase and sends the command.
+func (mc *masterConn) writeCommand(cmd byte, payload []byte) error {
+	mc.sequence = 0
+	return errors.Trace(mc.writePacket(append([]byte{cmd}, payload...)))
+}
+
+func (mc *masterConn) handshake(user, password string) error {
+	data, err := mc.readPacket()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if data[0] == mysql.ErrHeader {
+		return parseErrPacket(data)
+	}
+	// protocol version, server version, connection id.
+	pos := 1 + bytes.IndexByte(data[1:], 0) + 1 + 4
+	if pos+8+1+2 > len(data) {
+		return mysql.ErrMalformPacket
+	}
+	scramble := append([]byte{}, data[pos:pos+8]...)
+	pos += 8 + 1
+	capability := uint32(binary.LittleEndian.Uint16(data[pos:]))
+	pos += 2
+	plugin := nativePasswordAuth
+	if len(data) > pos {
+		// charset, status flags, upper capability flags, auth data length and reserved bytes.
+		capability |= uint32(binary.LittleEndian.Uint16(data[pos+3:])) << 16
+		pos += 1 + 2 + 2 + 1 + 10
+		if end := bytes.IndexByte(data[pos:], 0); end > 0 {
+			scramble = append(scramble, data[pos:pos+end]...)
+			pos += end + 1
+		}
+		if capability&mysql.ClientPluginAuth > 0 && pos < len(data) {
+			plugin = string(bytes.TrimRight(data[pos:], "\x00"))
+		}
+	}
+	if capability&mysql.ClientProtocol41 == 0 {
+		return errors.New("the master does not support protocol 41")
+	}
+	if plugin != nativePasswordAuth {
+		return errors.Errorf("unsupported auth plugin %s", plugin)
+	}
+
+	flags := mysql.ClientProtocol41 | mysql.ClientSecureConnection | mysql.ClientLongPassword |
+		mysql.ClientTransactions | mysql.ClientLongFlag | mysql.ClientPluginAuth
+	auth := scramblePassword(scramble, password)
+	resp := make([]byte, 4+4+1+23, 64)
+	binary.LittleEndian.PutUint32(resp, flags)
+	binary.LittleEndian.PutUint32(resp[4:], maxPacketSize)
+	resp[8] = mysql.DefaultCollationID
+	resp = append(resp, user...)
+	resp = append(resp, 0, byte(len(auth)))
+	resp = append(resp, auth...)
+	resp = append(resp, nativePasswordAuth...)
+	resp = append(resp, 0)
+	if err = mc.writePacket(resp); err != nil {
+		return errors.Trace(err)
+	}
+
+	data, err = mc.readPacket()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if data[0] == mysql.EOFHeader {
+		// Auth switch request, only switching to the native password method is supported.
+		pos = 1 + bytes.IndexByte(data[1:], 0)
+		if string(data[1:pos]) != nativePasswordAuth {
+			return errors.Errorf("unsupported auth plugin %s", data[1:pos])
+		}
+		scramble = bytes.TrimRight(data[pos+1:], "\x00")
+		if err = mc.writePacket(scramblePassword(scramble, password)); err != nil {
+			return errors.Trace(err)
+		}
+		if data, err = mc.readPacket(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(checkOKPacket(data))
+}
+
+func scramblePassword(scramble []byte, password string) []byte {
+	if password == "" {
+		return nil
+	}
+	return util.CalcPassword(scramble, util.Sha1Hash([]byte(password)))
+}
+
+func checkOKPacket(data []byte) error {
+	switch data[0] {
+	case mysql.OKHeader:
+		return nil
+	case mysql.ErrHeader:
+		return parseErrPacket(data)
+	}
+	return errors.Errorf("unexpected packet header 0x%x", data[0])
+}
+
+func parseErrPacket(data []byte) error {
+	if len(data) < 3 {
+		return mysql.ErrMalformPacket
+	}
+	code := binary.LittleEndian.Uint16(data[1:])
+	msg := data[3:]
+	if len(msg) > 0 && msg[0] == '#' && len(msg) >= 6 {
+		msg = msg[6:]
+	}
+	return mysql.NewErrf(code, "%s", msg)
+}
+
+// query runs a query on the master and returns the rows in text format.
+func (mc *masterConn) query(sql string) ([][]string, error) {
+	if err := mc.writeCommand(mysql.ComQuery, []byte(sql)); err != nil {
+		return nil, errors.Trace(err)
+	}
+	data, err := mc.readPacket()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if data[0] == mysql.OKHeader || data[0] == mysql.ErrHeader {
+		return nil, errors.Trace(checkOKPacket(data))
+	}
+	count, _, _ := parseLengthEncodedInt(data)
+	// Skip the column definitions and the EOF packet.
+	for i := uint64(0); i <= count; i++ {
+		if _, err = mc.readPacket(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	var rows [][]string
+	for {
+		data, err = mc.readPacket()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if data[0] == mysql.ErrHeader {
+			return nil, parseErrPacket(data)
+		}
+		if data[0] == mysql.EOFHeader && len(data) < 9 {
+			return rows, nil
+		}
+		row := make([]string, 0, count)
+		for len(data) > 0 {
+			if data[0] == 0xfb {
+				// NULL
+				row = append(row, "")
+				data = data[1:]
+				continue
+			}
+			n, _, size := parseLengthEncodedInt(data)
+			row = append(row, string(data[size:size+int(n)]))
+			data = data[size+int(n):]
+		}
+		rows = append(rows, row)
+	}
+}
+
+// registerSlave sends COM_REGISTER_SLAVE to the master.
+func (mc *masterConn) registerSlave(serverID uint32) error {
+	payload := make([]byte, 4, 18)
+	binary.LittleEndian.PutUint32(payload, serverID)
+	// hostname, user, password, port, replication rank and master id are all empty.
+	payload = append(payload, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	if err := mc.writeCommand(mysql.ComRegisterSlave, payload); err != nil {
+		return errors.Trace(err)
+	}
+	data, err := mc.readPacket()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(checkOKPacket(data))
+}
+
+// binlogDump sends COM_BINLOG_DUMP to the master, binlog events can be read by readEvent then.
+func (mc *masterConn) binlogDump(serverID uint32, pos Position) error {
+	payload := make([]byte, 10, 10+len(pos.Name))
+	binary.LittleEndian.PutUint32(payload, pos.Pos)
+	binary.LittleEndian.PutUint32(payload[6:], serverID)
+	payload = append(payload, pos.Name...)
+	return errors.Trace(mc.writeCommand(mysql.ComBinlogDump, payload))
+}
+
+// readEvent reads the next binlog event from the binlog stream.
+func (mc *masterConn) readEvent() ([]byte, error) {
+	data, err := mc.readPacket()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	switch data[0] {
+	case mysql.OKHeader:
+		return data[1:], nil
+	case mysql.ErrHeader:
+		return nil, parseErrPacket(data)
+	case mysql.EOFHeader:
+		return nil, io.EOF
+	}
+	return nil, errors.Errorf("unexpected packet header 0x%x", data[0])
+}
+
+func parseLengthEncodedInt(b []byte) (num uint64, isNull bool, n int) {
+	switch b[0] {
+	case 0xfb:
+		return 0, true, 1
+	case 0xfc:
+		return uint64(b[1]) | uint64(b[2])<<8, false, 3
+	case 0xfd:
+		return uint64(b[1]) | uint64(b[2])<<8 | uint64(b[3])<<16, false, 4
+	case 0xfe:
+		return binary.LittleEndian.Uint64(b[1:]), false, 9
+	}
+	return uint64(b[0]), false, 1
+}
+
+// Position is a binlog position of the master.
+type Position struct {
+	Name string
+	Pos  uint32
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("(%s, %d)", p.Name, p.Pos)
+}
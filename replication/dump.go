@@ -0,0 +1,557 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/store/tikv/oracle"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+	binlog "github.com/pingcap/tipb/go-binlog"
+)
+
+// binlogBaseName is the base name of the binlog files dumped by TiDB. SHOW MASTER STATUS reports
+// it with the current ts as the position.
+const binlogBaseName = "tidb-binlog"
+
+// prewriteTimeout is how long a prewrite binlog waits for its commit binlog. The transactions
+// committed after it are held back until then, as they may be committed before it.
+const prewriteTimeout = 15 * time.Minute
+
+var (
+	// maxBinlogFileSize is the size of the dumped binlog files, the dumper rotates to a new file
+	// after a transaction reaches it.
+	maxBinlogFileSize uint32 = 256 << 20
+	// maxRowsEventSize is the size of the rows events, the rows of a table are split into many
+	// events after it.
+	maxRowsEventSize = 8 << 10
+)
+
+// binlogFileName returns the name of the binlog file of the transactions committed after ts.
+func binlogFileName(ts uint64) string {
+	return fmt.Sprintf("%s.%020d", binlogBaseName, ts)
+}
+
+func parseBinlogFileName(name string) (uint64, error) {
+	if name == binlogBaseName {
+		return 0, errors.Errorf("binlog file %s has no ts, use %s instead", name, binlogFileName(0))
+	}
+	if !strings.HasPrefix(name, binlogBaseName+".") {
+		return 0, errors.Errorf("unknown binlog file %s", name)
+	}
+	ts, err := strconv.ParseUint(name[len(binlogBaseName)+1:], 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("unknown binlog file %s", name)
+	}
+	return ts, nil
+}
+
+// txnBinlog is a committed transaction read from the binlog file.
+type txnBinlog struct {
+	startTS       int64
+	commitTS      int64
+	prewriteValue []byte
+	ddlJobID      int64
+}
+
+type txnHeap []*txnBinlog
+
+func (h txnHeap) Len() int            { return len(h) }
+func (h txnHeap) Less(i, j int) bool  { return h[i].commitTS < h[j].commitTS }
+func (h txnHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *txnHeap) Push(x interface{}) { *h = append(*h, x.(*txnBinlog)) }
+func (h *txnHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// eventData is the type, flags and body of a binlog event to be encoded.
+type eventData struct {
+	tp    byte
+	flags uint16
+	body  []byte
+}
+
+// Dumper converts the binlog file written by TiDB to MySQL row-based binlog events, which are
+// sent to the MySQL slaves.
+//
+// The dumped binlog files are virtual. The file tidb-binlog.<ts> contains the transactions
+// committed after ts in the order of the commit ts, so the files and positions are generated
+// the same way every time and a slave can resume from any position it has received.
+type Dumper struct {
+	file     *os.File
+	offset   int64
+	schemas  *schemaHistory
+	checksum bool
+
+	prewrites   map[int64]*binlog.Binlog
+	commits     txnHeap
+	maxCommitTS int64
+
+	// fileTS is the ts of the current binlog file.
+	fileTS uint64
+	// pos is the position after the last generated event.
+	pos Position
+	// skipTo is the position requested by the slave, the events before it are generated to get
+	// the positions but not sent.
+	skipTo uint32
+	events [][]byte
+}
+
+// NewDumper creates a Dumper which reads the binlog file at path and dumps the events from the
+// position. The events have CRC32 checksums if checksum is true.
+func NewDumper(store kv.Storage, path string, pos Position, checksum bool) (*Dumper, error) {
+	if pos.Name == "" {
+		pos = Position{Name: binlogFileName(0), Pos: 4}
+	}
+	ts, err := parseBinlogFileName(pos.Name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if pos.Pos < 4 {
+		pos.Pos = 4
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	d := &Dumper{
+		file:      file,
+		schemas:   newSchemaHistory(store),
+		checksum:  checksum,
+		prewrites: make(map[int64]*binlog.Binlog),
+		fileTS:    ts,
+		pos:       Position{Name: pos.Name, Pos: 4},
+		skipTo:    pos.Pos,
+	}
+	// The artificial rotate event tells the slave the file name of the following events.
+	d.events = append(d.events, encodeEvent(0, rotateEvent, 0, logEventArtificialFlag, encodeRotateBody(pos), checksum))
+	if err = d.appendFormatDescription(); err != nil {
+		file.Close()
+		return nil, errors.Trace(err)
+	}
+	return d, nil
+}
+
+// Next returns the next event. It returns nil if all the binlogs written so far are dumped.
+func (d *Dumper) Next() ([]byte, error) {
+	for len(d.events) == 0 {
+		txn, err := d.nextTxn()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if txn == nil {
+			if d.pos.Pos < d.skipTo {
+				return nil, errors.Errorf("position %d is beyond the end of binlog file %s", d.skipTo, d.pos.Name)
+			}
+			return nil, nil
+		}
+		if uint64(txn.commitTS) <= d.fileTS {
+			continue
+		}
+		if txn.ddlJobID > 0 {
+			err = d.appendDDL(txn)
+		} else {
+			err = d.appendDML(txn)
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	event := d.events[0]
+	d.events[0] = nil
+	d.events = d.events[1:]
+	return event, nil
+}
+
+// HeartbeatEvent returns a heartbeat event of the current position, it's sent to the slave when
+// there is no event for a while.
+func (d *Dumper) HeartbeatEvent() []byte {
+	return encodeEvent(0, heartbeatEvent, d.pos.Pos, logEventArtificialFlag, []byte(d.pos.Name), d.checksum)
+}
+
+// Position returns the position after the last generated event.
+func (d *Dumper) Position() Position {
+	return d.pos
+}
+
+// Close closes the binlog file.
+func (d *Dumper) Close() error {
+	return errors.Trace(d.file.Close())
+}
+
+// nextTxn returns the next committed transaction, or nil if it isn't written yet.
+func (d *Dumper) nextTxn() (*txnBinlog, error) {
+	for {
+		if txn := d.readyTxn(); txn != nil {
+			return txn, nil
+		}
+		ok, err := d.readBinlog()
+		if err != nil || !ok {
+			return nil, errors.Trace(err)
+		}
+	}
+}
+
+// readyTxn returns the committed transaction with the smallest commit ts, if no transaction
+// is still possible to be committed before it. A transaction is only committed after its
+// prewrite binlog is written, and gets a commit ts larger than its start ts.
+func (d *Dumper) readyTxn() *txnBinlog {
+	if len(d.commits) == 0 {
+		return nil
+	}
+	txn := d.commits[0]
+	for startTS := range d.prewrites {
+		if startTS >= txn.commitTS {
+			continue
+		}
+		if oracle.ExtractPhysical(uint64(d.maxCommitTS))-oracle.ExtractPhysical(uint64(startTS)) > int64(prewriteTimeout/time.Millisecond) {
+			log.Warnf("[replication] no commit binlog of transaction %d, skip it", startTS)
+			delete(d.prewrites, startTS)
+			continue
+		}
+		return nil
+	}
+	return heap.Pop(&d.commits).(*txnBinlog)
+}
+
+// readBinlog reads the next binlog in the file. It returns false if the binlog isn't
+// completely written yet.
+func (d *Dumper) readBinlog() (bool, error) {
+	var header [4]byte
+	n, err := d.file.ReadAt(header[:], d.offset)
+	if n < len(header) {
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, errors.Trace(err)
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[:]))
+	n, err = d.file.ReadAt(payload, d.offset+int64(len(header)))
+	if n < len(payload) {
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, errors.Trace(err)
+	}
+	bin := new(binlog.Binlog)
+	if err = bin.Unmarshal(payload); err != nil {
+		return false, errors.Trace(err)
+	}
+	d.offset += int64(len(header) + len(payload))
+
+	switch bin.Tp {
+	case binlog.BinlogType_Prewrite:
+		d.prewrites[bin.StartTs] = bin
+	case binlog.BinlogType_Commit:
+		txn := &txnBinlog{
+			startTS:       bin.StartTs,
+			commitTS:      bin.CommitTs,
+			prewriteValue: bin.PrewriteValue,
+			ddlJobID:      bin.DdlJobId,
+		}
+		if prewrite, ok := d.prewrites[bin.StartTs]; ok {
+			txn.prewriteValue = prewrite.PrewriteValue
+			txn.ddlJobID = prewrite.DdlJobId
+			delete(d.prewrites, bin.StartTs)
+		}
+		heap.Push(&d.commits, txn)
+		if bin.CommitTs > d.maxCommitTS {
+			d.maxCommitTS = bin.CommitTs
+		}
+	case binlog.BinlogType_Rollback:
+		delete(d.prewrites, bin.StartTs)
+	}
+	return true, nil
+}
+
+func (d *Dumper) appendFormatDescription() error {
+	body := encodeFormatDescriptionBody(d.checksum)
+	size := uint32(eventHeaderLength + len(body) + checksumLength)
+	logPos := d.pos.Pos + size
+	if d.skipTo > d.pos.Pos {
+		if d.skipTo < logPos {
+			return errors.Errorf("position %d is not at an event of binlog file %s", d.skipTo, d.pos.Name)
+		}
+		// The slave keeps its position if log_pos is 0.
+		logPos = 0
+	}
+	timestamp := uint32(oracle.ExtractPhysical(d.fileTS) / 1000)
+	// The format description event always has a checksum, the slave checks the algorithm in it.
+	d.events = append(d.events, encodeEvent(timestamp, formatDescriptionEvent, logPos, 0, body, true))
+	d.pos.Pos += size
+	return nil
+}
+
+// appendEvents generates the events of a transaction, and rotates to the next binlog file if
+// the current one is full.
+func (d *Dumper) appendEvents(commitTS int64, events []eventData) error {
+	timestamp := uint32(oracle.ExtractPhysical(uint64(commitTS)) / 1000)
+	for _, e := range events {
+		if err := d.appendEvent(timestamp, e); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if d.pos.Pos < maxBinlogFileSize {
+		return nil
+	}
+	next := Position{Name: binlogFileName(uint64(commitTS)), Pos: 4}
+	if err := d.appendEvent(timestamp, eventData{tp: rotateEvent, body: encodeRotateBody(next)}); err != nil {
+		return errors.Trace(err)
+	}
+	if d.skipTo > d.pos.Pos {
+		return errors.Errorf("position %d is beyond the end of binlog file %s", d.skipTo, d.pos.Name)
+	}
+	d.fileTS = uint64(commitTS)
+	d.pos = next
+	d.skipTo = next.Pos
+	return errors.Trace(d.appendFormatDescription())
+}
+
+func (d *Dumper) appendEvent(timestamp uint32, e eventData) error {
+	size := uint32(eventHeaderLength + len(e.body))
+	if d.checksum {
+		size += checksumLength
+	}
+	start := d.pos.Pos
+	d.pos.Pos += size
+	if start >= d.skipTo {
+		d.events = append(d.events, encodeEvent(timestamp, e.tp, d.pos.Pos, e.flags, e.body, d.checksum))
+	} else if d.pos.Pos > d.skipTo {
+		return errors.Errorf("position %d is not at an event of binlog file %s", d.skipTo, d.pos.Name)
+	}
+	return nil
+}
+
+func (d *Dumper) appendDDL(txn *txnBinlog) error {
+	job, err := d.schemas.job(txn.ddlJobID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if job == nil {
+		log.Warnf("[replication] DDL job %d not found, skip it", txn.ddlJobID)
+		return nil
+	}
+	// The binlog is also written when the job is rolled back.
+	if !job.IsDone() || job.Query == "" {
+		return nil
+	}
+	dbName := d.schemas.schemaName(job.SchemaID)
+	if strings.EqualFold(dbName, mysql.SystemDB) {
+		return nil
+	}
+	// The statements on databases run without the current database, like MySQL.
+	if job.Type == model.ActionCreateSchema || job.Type == model.ActionDropSchema {
+		dbName = ""
+	}
+	event := eventData{tp: queryEvent, body: encodeQueryBody(dbName, job.Query)}
+	return errors.Trace(d.appendEvents(txn.commitTS, []eventData{event}))
+}
+
+func (d *Dumper) appendDML(txn *txnBinlog) error {
+	value := new(binlog.PrewriteValue)
+	if err := value.Unmarshal(txn.prewriteValue); err != nil {
+		return errors.Trace(err)
+	}
+	events := []eventData{{tp: queryEvent, body: encodeQueryBody("", "BEGIN")}}
+	for i := range value.Mutations {
+		tableEvents, err := d.mutationEvents(&value.Mutations[i], value.SchemaVersion)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		events = append(events, tableEvents...)
+	}
+	if len(events) == 1 {
+		return nil
+	}
+	events = append(events, eventData{tp: xidEvent, body: encodeXIDBody(uint64(txn.startTS))})
+	return errors.Trace(d.appendEvents(txn.commitTS, events))
+}
+
+// mutationEvents returns the table map event and the rows events of the mutation.
+func (d *Dumper) mutationEvents(m *binlog.TableMutation, schemaVersion int64) ([]eventData, error) {
+	dbName, tbl, err := d.schemas.table(m.TableId, schemaVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if tbl == nil {
+		log.Warnf("[replication] table %d not found, skip its rows", m.TableId)
+		return nil, nil
+	}
+	if strings.EqualFold(dbName, mysql.SystemDB) {
+		return nil, nil
+	}
+	decoder := newRowDecoder(tbl)
+	tableMap, err := encodeTableMapBody(tbl.ID, dbName, tbl.Name.O, decoder.cols)
+	if err != nil {
+		return nil, errors.Annotatef(err, "table %s.%s", dbName, tbl.Name)
+	}
+	events := []eventData{{tp: tableMapEvent, body: tableMap}}
+
+	var (
+		tp   byte
+		body []byte
+	)
+	var inserted, updated, deleted int
+	for _, mt := range m.Sequence {
+		var rowTp byte
+		var rows [][]types.Datum
+		switch mt {
+		case binlog.MutationType_Insert:
+			if inserted >= len(m.InsertedRows) {
+				return nil, errors.New("inserted rows mismatch the sequence")
+			}
+			row, err := decoder.decodeInserted(m.InsertedRows[inserted])
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			inserted++
+			rowTp, rows = writeRowsEventV2, [][]types.Datum{row}
+		case binlog.MutationType_Update:
+			if updated >= len(m.UpdatedRows) {
+				return nil, errors.New("updated rows mismatch the sequence")
+			}
+			oldRow, newRow, err := decoder.decodeUpdated(m.UpdatedRows[updated])
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			updated++
+			rowTp, rows = updateRowsEventV2, [][]types.Datum{oldRow, newRow}
+		case binlog.MutationType_DeleteRow:
+			if deleted >= len(m.DeletedRows) {
+				return nil, errors.New("deleted rows mismatch the sequence")
+			}
+			row, err := decoder.decode(m.DeletedRows[deleted], nil)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			deleted++
+			rowTp, rows = deleteRowsEventV2, [][]types.Datum{row}
+		default:
+			return nil, errors.Errorf("unsupported mutation type %s of table %s.%s", mt, dbName, tbl.Name)
+		}
+		if body != nil && (rowTp != tp || len(body) >= maxRowsEventSize) {
+			events = append(events, eventData{tp: tp, body: body})
+			body = nil
+		}
+		if body == nil {
+			tp = rowTp
+			body = encodeRowsHeader(tp, tbl.ID, 0, len(decoder.cols))
+		}
+		for _, row := range rows {
+			body, err = appendRowImage(body, decoder.cols, row)
+			if err != nil {
+				return nil, errors.Annotatef(err, "table %s.%s", dbName, tbl.Name)
+			}
+		}
+	}
+	if body == nil {
+		return nil, nil
+	}
+	// The flags are after the 6 bytes table id.
+	binary.LittleEndian.PutUint16(body[6:], stmtEndFlag)
+	return append(events, eventData{tp: tp, body: body}), nil
+}
+
+// rowDecoder decodes the rows in the binlog to the values of the public columns.
+type rowDecoder struct {
+	tbl  *model.TableInfo
+	cols []*model.ColumnInfo
+	fts  map[int64]*types.FieldType
+}
+
+func newRowDecoder(tbl *model.TableInfo) *rowDecoder {
+	r := &rowDecoder{tbl: tbl, fts: make(map[int64]*types.FieldType)}
+	for _, col := range tbl.Columns {
+		if col.State != model.StatePublic {
+			continue
+		}
+		r.cols = append(r.cols, col)
+		r.fts[col.ID] = &col.FieldType
+	}
+	return r
+}
+
+// decode decodes the row. The primary key handle column is set from handle if it isn't nil.
+// The columns missing in the row are NULL.
+func (r *rowDecoder) decode(data []byte, handle *int64) ([]types.Datum, error) {
+	values, err := tablecodec.DecodeRow(data, r.fts, time.UTC)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	row := make([]types.Datum, len(r.cols))
+	for i, col := range r.cols {
+		if handle != nil && r.tbl.PKIsHandle && mysql.HasPriKeyFlag(col.Flag) {
+			if mysql.HasUnsignedFlag(col.Flag) {
+				row[i].SetUint64(uint64(*handle))
+			} else {
+				row[i].SetInt64(*handle)
+			}
+			continue
+		}
+		row[i] = values[col.ID]
+	}
+	return row, nil
+}
+
+// decodeInserted decodes an inserted row, which is the handle followed by the row.
+func (r *rowDecoder) decodeInserted(data []byte) ([]types.Datum, error) {
+	remain, d, err := codec.DecodeOne(data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	handle := d.GetInt64()
+	return r.decode(remain, &handle)
+}
+
+// decodeUpdated decodes an updated row, which is the old row followed by the new row with the
+// same columns.
+func (r *rowDecoder) decodeUpdated(data []byte) ([]types.Datum, []types.Datum, error) {
+	var ends []int
+	for b := data; len(b) > 0; {
+		_, remain, err := codec.CutOne(b)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		b = remain
+		ends = append(ends, len(data)-len(b))
+	}
+	if len(ends) == 0 || len(ends)%2 != 0 {
+		return nil, nil, errors.New("invalid updated row")
+	}
+	mid := ends[len(ends)/2-1]
+	oldRow, err := r.decode(data[:mid], nil)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	newRow, err := r.decode(data[mid:], nil)
+	return oldRow, newRow, errors.Trace(err)
+}
@@ -0,0 +1,451 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"math"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/types"
+)
+
+const (
+	binlogVersion  = 4
+	masterServerID = 1
+
+	// logEventArtificialFlag marks the events which are not written to binlog files.
+	logEventArtificialFlag uint16 = 0x20
+	// stmtEndFlag marks the last rows event of a statement, the table maps are released after it.
+	stmtEndFlag uint16 = 0x01
+	// tableMapBitLenExactFlag is the only flag of TABLE_MAP_EVENT written by MySQL 5.6 and later.
+	tableMapBitLenExactFlag uint16 = 0x01
+
+	// Status variables of QUERY_EVENT.
+	queryFlags2Code  = 0
+	querySQLModeCode = 1
+	queryCharsetCode = 4
+)
+
+// eventPostHeaderLengths is the post header lengths of the binlog events, from START_EVENT_V3
+// to the last event type, same as MySQL 5.6.
+var eventPostHeaderLengths = []byte{
+	0x38, 0x0d, 0x00, 0x08, 0x00, 0x12, 0x00, 0x04, 0x04, 0x04, 0x04, 0x12, 0x00, 0x00, 0x5c, 0x00,
+	0x04, 0x1a, 0x08, 0x00, 0x00, 0x00, 0x08, 0x08, 0x08, 0x02, 0x00, 0x00, 0x00, 0x0a, 0x0a, 0x0a,
+	0x19, 0x19, 0x00,
+}
+
+// encodeEvent encodes a binlog event with the v4 event header, the CRC32 checksum is appended
+// if checksum is true.
+func encodeEvent(timestamp uint32, tp byte, logPos uint32, flags uint16, body []byte, checksum bool) []byte {
+	size := eventHeaderLength + len(body)
+	if checksum {
+		size += checksumLength
+	}
+	event := make([]byte, eventHeaderLength, size)
+	binary.LittleEndian.PutUint32(event, timestamp)
+	event[4] = tp
+	binary.LittleEndian.PutUint32(event[5:], masterServerID)
+	binary.LittleEndian.PutUint32(event[9:], uint32(size))
+	binary.LittleEndian.PutUint32(event[13:], logPos)
+	binary.LittleEndian.PutUint16(event[17:], flags)
+	event = append(event, body...)
+	if checksum {
+		var crc [checksumLength]byte
+		binary.LittleEndian.PutUint32(crc[:], crc32.ChecksumIEEE(event))
+		event = append(event, crc[:]...)
+	}
+	return event
+}
+
+func encodeRotateBody(pos Position) []byte {
+	body := make([]byte, 8, 8+len(pos.Name))
+	binary.LittleEndian.PutUint64(body, uint64(pos.Pos))
+	return append(body, pos.Name...)
+}
+
+// encodeFormatDescriptionBody encodes the body of FORMAT_DESCRIPTION_EVENT. The checksum of
+// the event always follows the body, no matter whether the other events have checksums.
+func encodeFormatDescriptionBody(checksum bool) []byte {
+	body := make([]byte, 2+50+4+1, 2+50+4+1+len(eventPostHeaderLengths)+1)
+	binary.LittleEndian.PutUint16(body, binlogVersion)
+	copy(body[2:52], mysql.ServerVersion)
+	body[56] = eventHeaderLength
+	body = append(body, eventPostHeaderLengths...)
+	if checksum {
+		return append(body, checksumAlgCRC32)
+	}
+	return append(body, checksumAlgOff)
+}
+
+// encodeQueryBody encodes the body of QUERY_EVENT. The statements are executed by the slave
+// with the default collation of TiDB, so the replayed DDL creates the same columns.
+func encodeQueryBody(schema, query string) []byte {
+	status := make([]byte, 0, 1+4+1+8+1+6)
+	status = append(status, queryFlags2Code, 0, 0, 0, 0)
+	status = append(status, querySQLModeCode, 0, 0, 0, 0, 0, 0, 0, 0)
+	status = append(status, queryCharsetCode)
+	for i := 0; i < 3; i++ {
+		status = append(status, byte(mysql.DefaultCollationID), byte(mysql.DefaultCollationID>>8))
+	}
+
+	body := make([]byte, 13, 13+len(status)+len(schema)+1+len(query))
+	body[8] = byte(len(schema))
+	binary.LittleEndian.PutUint16(body[11:], uint16(len(status)))
+	body = append(body, status...)
+	body = append(body, schema...)
+	body = append(body, 0)
+	return append(body, query...)
+}
+
+func encodeXIDBody(xid uint64) []byte {
+	body := make([]byte, 8)
+	binary.LittleEndian.PutUint64(body, xid)
+	return body
+}
+
+func appendTableID(body []byte, tableID int64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(tableID))
+	return append(body, buf[:6]...)
+}
+
+func appendLengthEncodedInt(b []byte, n uint64) []byte {
+	switch {
+	case n < 251:
+		return append(b, byte(n))
+	case n < 1<<16:
+		return append(b, 0xfc, byte(n), byte(n>>8))
+	case n < 1<<24:
+		return append(b, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+	}
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], n)
+	return append(append(b, 0xfe), buf[:]...)
+}
+
+// encodeTableMapBody encodes the body of TABLE_MAP_EVENT for the columns of the table.
+func encodeTableMapBody(tableID int64, schema, table string, cols []*model.ColumnInfo) ([]byte, error) {
+	body := make([]byte, 0, 64)
+	body = appendTableID(body, tableID)
+	body = append(body, byte(tableMapBitLenExactFlag), byte(tableMapBitLenExactFlag>>8))
+	body = append(body, byte(len(schema)))
+	body = append(body, schema...)
+	body = append(body, 0, byte(len(table)))
+	body = append(body, table...)
+	body = append(body, 0)
+	body = appendLengthEncodedInt(body, uint64(len(cols)))
+	var meta []byte
+	for _, col := range cols {
+		tp, m, err := columnType(&col.FieldType)
+		if err != nil {
+			return nil, errors.Annotatef(err, "column %s of %s.%s", col.Name, schema, table)
+		}
+		body = append(body, tp)
+		meta = append(meta, m...)
+	}
+	body = appendLengthEncodedInt(body, uint64(len(meta)))
+	body = append(body, meta...)
+	nullable := make([]byte, (len(cols)+7)/8)
+	for i, col := range cols {
+		if !mysql.HasNotNullFlag(col.Flag) {
+			nullable[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return append(body, nullable...), nil
+}
+
+// encodeRowsHeader encodes the post header of the v2 rows events and the column bitmaps.
+func encodeRowsHeader(tp byte, tableID int64, flags uint16, columnCount int) []byte {
+	body := make([]byte, 0, 64)
+	body = appendTableID(body, tableID)
+	// The extra data length includes the 2 bytes of itself.
+	body = append(body, byte(flags), byte(flags>>8), 2, 0)
+	body = appendLengthEncodedInt(body, uint64(columnCount))
+	images := 1
+	if tp == updateRowsEventV2 {
+		images = 2
+	}
+	for i := 0; i < images; i++ {
+		present := make([]byte, (columnCount+7)/8)
+		for j := 0; j < columnCount; j++ {
+			present[j/8] |= 1 << uint(j%8)
+		}
+		body = append(body, present...)
+	}
+	return body
+}
+
+// appendRowImage appends the row image of all the columns to b.
+func appendRowImage(b []byte, cols []*model.ColumnInfo, row []types.Datum) ([]byte, error) {
+	nulls := make([]byte, (len(cols)+7)/8)
+	for i := range cols {
+		if row[i].IsNull() {
+			nulls[i/8] |= 1 << uint(i%8)
+		}
+	}
+	b = append(b, nulls...)
+	for i, col := range cols {
+		if row[i].IsNull() {
+			continue
+		}
+		var err error
+		b, err = appendValue(b, &col.FieldType, row[i])
+		if err != nil {
+			return nil, errors.Annotatef(err, "column %s", col.Name)
+		}
+	}
+	return b, nil
+}
+
+// Binlog column types of the BLOB family, distinguished by the length of the size prefix.
+var blobPackLengths = map[byte]byte{
+	mysql.TypeTinyBlob:   1,
+	mysql.TypeBlob:       2,
+	mysql.TypeMediumBlob: 3,
+	mysql.TypeLongBlob:   4,
+}
+
+// intSizes is the storage sizes of the integer types.
+var intSizes = map[byte]int{
+	mysql.TypeTiny:     1,
+	mysql.TypeShort:    2,
+	mysql.TypeInt24:    3,
+	mysql.TypeLong:     4,
+	mysql.TypeLonglong: 8,
+}
+
+// columnType returns the column type in the binlog and its metadata in TABLE_MAP_EVENT.
+func columnType(ft *types.FieldType) (byte, []byte, error) {
+	switch ft.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong,
+		mysql.TypeYear, mysql.TypeDate:
+		return ft.Tp, nil, nil
+	case mysql.TypeFloat:
+		return ft.Tp, []byte{4}, nil
+	case mysql.TypeDouble:
+		return ft.Tp, []byte{8}, nil
+	case mysql.TypeNewDecimal:
+		precision, frac := decimalSize(ft)
+		return ft.Tp, []byte{byte(precision), byte(frac)}, nil
+	case mysql.TypeVarchar, mysql.TypeVarString:
+		n := stringMaxBytes(ft)
+		return mysql.TypeVarchar, []byte{byte(n), byte(n >> 8)}, nil
+	case mysql.TypeString:
+		// The high bits of the length are stored in the unused bits of the real type.
+		n := stringMaxBytes(ft)
+		return mysql.TypeString, []byte{mysql.TypeString ^ byte((n&0x300)>>4), byte(n)}, nil
+	case mysql.TypeEnum:
+		return mysql.TypeString, []byte{mysql.TypeEnum, enumPackLength(ft)}, nil
+	case mysql.TypeSet:
+		return mysql.TypeString, []byte{mysql.TypeSet, setPackLength(ft)}, nil
+	case mysql.TypeTinyBlob, mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		return mysql.TypeBlob, []byte{blobPackLengths[ft.Tp]}, nil
+	case mysql.TypeDatetime:
+		return typeDatetime2, []byte{byte(fsp(ft))}, nil
+	case mysql.TypeTimestamp:
+		return typeTimestamp2, []byte{byte(fsp(ft))}, nil
+	case mysql.TypeDuration:
+		return typeTime2, []byte{byte(fsp(ft))}, nil
+	case mysql.TypeBit:
+		bits := ft.Flen
+		if bits <= 0 {
+			bits = 1
+		}
+		return mysql.TypeBit, []byte{byte(bits % 8), byte(bits / 8)}, nil
+	}
+	return 0, nil, errors.Errorf("unsupported column type %s in binlog", types.TypeToStr(ft.Tp, ft.Charset))
+}
+
+func decimalSize(ft *types.FieldType) (int, int) {
+	precision, frac := ft.Flen, ft.Decimal
+	if precision == types.UnspecifiedLength {
+		precision = mysql.GetDefaultFieldLength(mysql.TypeNewDecimal)
+	}
+	if frac == types.UnspecifiedLength {
+		frac = mysql.GetDefaultDecimal(mysql.TypeNewDecimal)
+	}
+	return precision, frac
+}
+
+// stringMaxBytes returns the max length in bytes of the CHAR and VARCHAR columns.
+func stringMaxBytes(ft *types.FieldType) int {
+	cs := ft.Charset
+	if cs == "" {
+		cs = mysql.DefaultCharset
+	}
+	n := ft.Flen * charset.GetMaxLen(cs)
+	if ft.Flen == types.UnspecifiedLength || n > math.MaxUint16 {
+		n = math.MaxUint16
+	}
+	return n
+}
+
+func enumPackLength(ft *types.FieldType) byte {
+	if len(ft.Elems) < 256 {
+		return 1
+	}
+	return 2
+}
+
+func setPackLength(ft *types.FieldType) byte {
+	n := (len(ft.Elems) + 7) / 8
+	if n > 4 {
+		return 8
+	}
+	return byte(n)
+}
+
+func fsp(ft *types.FieldType) int {
+	if ft.Decimal == types.UnspecifiedFsp {
+		return types.DefaultFsp
+	}
+	return ft.Decimal
+}
+
+func appendUintLE(b []byte, v uint64, n int) []byte {
+	for i := 0; i < n; i++ {
+		b = append(b, byte(v>>uint(8*i)))
+	}
+	return b
+}
+
+func appendUintBE(b []byte, v uint64, n int) []byte {
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, byte(v>>uint(8*i)))
+	}
+	return b
+}
+
+// appendFrac appends the fractional seconds part with the storage size of the fsp. It's signed,
+// the negative TIME values store it in reverse order.
+func appendFrac(b []byte, frac int64, fsp int) []byte {
+	switch fsp {
+	case 1, 2:
+		return append(b, byte(frac/10000))
+	case 3, 4:
+		return appendUintBE(b, uint64(frac/100), 2)
+	case 5, 6:
+		return appendUintBE(b, uint64(frac), 3)
+	}
+	return b
+}
+
+func appendString(b []byte, s []byte, maxBytes int) []byte {
+	if maxBytes < 256 {
+		b = append(b, byte(len(s)))
+	} else {
+		b = append(b, byte(len(s)), byte(len(s)>>8))
+	}
+	return append(b, s...)
+}
+
+// appendValue appends the value of a column in the binlog row format.
+func appendValue(b []byte, ft *types.FieldType, d types.Datum) ([]byte, error) {
+	switch ft.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		v := uint64(d.GetInt64())
+		if d.Kind() == types.KindUint64 {
+			v = d.GetUint64()
+		}
+		return appendUintLE(b, v, intSizes[ft.Tp]), nil
+	case mysql.TypeYear:
+		year := d.GetInt64()
+		if year != 0 {
+			year -= 1900
+		}
+		return append(b, byte(year)), nil
+	case mysql.TypeFloat:
+		return appendUintLE(b, uint64(math.Float32bits(float32(d.GetFloat64()))), 4), nil
+	case mysql.TypeDouble:
+		return appendUintLE(b, math.Float64bits(d.GetFloat64()), 8), nil
+	case mysql.TypeNewDecimal:
+		precision, frac := decimalSize(ft)
+		bin, err := d.GetMysqlDecimal().ToBin(precision, frac)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(b, bin...), nil
+	case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString:
+		return appendString(b, d.GetBytes(), stringMaxBytes(ft)), nil
+	case mysql.TypeEnum:
+		return appendUintLE(b, d.GetMysqlEnum().Value, int(enumPackLength(ft))), nil
+	case mysql.TypeSet:
+		return appendUintLE(b, d.GetMysqlSet().Value, int(setPackLength(ft))), nil
+	case mysql.TypeTinyBlob, mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		data := d.GetBytes()
+		b = appendUintLE(b, uint64(len(data)), int(blobPackLengths[ft.Tp]))
+		return append(b, data...), nil
+	case mysql.TypeBit:
+		bits := ft.Flen
+		if bits <= 0 {
+			bits = 1
+		}
+		return appendUintBE(b, d.GetMysqlBit().Value, (bits+7)/8), nil
+	case mysql.TypeDate:
+		t := d.GetMysqlTime().Time
+		return appendUintLE(b, uint64((t.Year()<<9)|(t.Month()<<5)|t.Day()), 3), nil
+	case mysql.TypeDatetime:
+		t := d.GetMysqlTime().Time
+		ymd := uint64(((t.Year()*13 + t.Month()) << 5) | t.Day())
+		hms := uint64((t.Hour() << 12) | (t.Minute() << 6) | t.Second())
+		b = appendUintBE(b, ((ymd<<17)|hms)+0x8000000000, 5)
+		return appendFrac(b, int64(t.Microsecond()), fsp(ft)), nil
+	case mysql.TypeTimestamp:
+		t := d.GetMysqlTime()
+		var sec int64
+		if !t.IsZero() {
+			// The rows are decoded in UTC.
+			goTime, err := t.Time.GoTime(time.UTC)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			sec = goTime.Unix()
+		}
+		b = appendUintBE(b, uint64(sec), 4)
+		return appendFrac(b, int64(t.Time.Microsecond()), fsp(ft)), nil
+	case mysql.TypeDuration:
+		return appendTime2(b, d.GetMysqlDuration().Duration, fsp(ft)), nil
+	}
+	return nil, errors.Errorf("unsupported column type %s in binlog", types.TypeToStr(ft.Tp, ft.Charset))
+}
+
+// appendTime2 appends the TIME value in the packed format of MySQL 5.6.
+func appendTime2(b []byte, dur time.Duration, fsp int) []byte {
+	neg := dur < 0
+	if neg {
+		dur = -dur
+	}
+	sec := int64(dur / time.Second)
+	usec := int64(dur % time.Second / time.Microsecond)
+	hms := ((sec / 3600) << 12) | ((sec / 60 % 60) << 6) | (sec % 60)
+	packed := hms<<24 + usec
+	if neg {
+		packed = -packed
+	}
+	switch fsp {
+	case 1, 2, 3, 4:
+		// The integer part is rounded down, so the fraction of the negative values is negative.
+		b = appendUintBE(b, uint64((packed>>24)+0x800000), 3)
+		return appendFrac(b, packed%(1<<24), fsp)
+	case 5, 6:
+		return appendUintBE(b, uint64(packed+0x800000000000), 6)
+	}
+	return appendUintBE(b, uint64((packed>>24)+0x800000), 3)
+}
@@ -0,0 +1,150 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+)
+
+// tableVersion is the schema of a table since a schema version.
+type tableVersion struct {
+	version  int64
+	schemaID int64
+	info     *model.TableInfo
+}
+
+// schemaHistory is the history of the table schemas built from the finished DDL jobs. The rows of
+// a transaction are always encoded with the schema of the same version, whenever the events are
+// generated, so the positions of the events are stable. Unlike the snapshot information schema,
+// it doesn't depend on the old versions of the meta data, which are removed by GC.
+type schemaHistory struct {
+	store kv.Storage
+	// version is the schema version when the history is loaded, the history is complete up to it.
+	version int64
+	dbs     map[int64]string
+	tables  map[int64][]tableVersion
+	jobs    map[int64]*model.Job
+}
+
+func newSchemaHistory(store kv.Storage) *schemaHistory {
+	return &schemaHistory{store: store, version: -1}
+}
+
+func (h *schemaHistory) load() error {
+	ver, err := h.store.CurrentVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	snapshot, err := h.store.GetSnapshot(ver)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	m := meta.NewSnapshotMeta(snapshot)
+	version, err := m.GetSchemaVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	jobs, err := m.GetAllHistoryDDLJobs()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	dbInfos, err := m.ListDatabases()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	h.version = version
+	h.dbs = make(map[int64]string)
+	h.tables = make(map[int64][]tableVersion)
+	h.jobs = make(map[int64]*model.Job, len(jobs))
+	for _, job := range jobs {
+		h.jobs[job.ID] = job
+		if !job.IsDone() || job.BinlogInfo == nil {
+			continue
+		}
+		if db := job.BinlogInfo.DBInfo; db != nil {
+			h.dbs[db.ID] = db.Name.O
+		}
+		if tbl := job.BinlogInfo.TableInfo; tbl != nil {
+			h.tables[tbl.ID] = append(h.tables[tbl.ID], tableVersion{
+				version:  job.BinlogInfo.SchemaVersion,
+				schemaID: job.SchemaID,
+				info:     tbl,
+			})
+		}
+	}
+	for _, db := range dbInfos {
+		h.dbs[db.ID] = db.Name.O
+		tables, err := m.ListTables(db.ID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, tbl := range tables {
+			// The tables created without DDL jobs have no history.
+			if _, ok := h.tables[tbl.ID]; !ok {
+				h.tables[tbl.ID] = []tableVersion{{schemaID: db.ID, info: tbl}}
+			}
+		}
+	}
+	for _, versions := range h.tables {
+		sort.Sort(tableVersions(versions))
+	}
+	return nil
+}
+
+// table returns the schema name and the table info of the table at the schema version.
+// It returns nil if the table isn't found.
+func (h *schemaHistory) table(tableID, version int64) (string, *model.TableInfo, error) {
+	if version > h.version {
+		if err := h.load(); err != nil {
+			return "", nil, errors.Trace(err)
+		}
+	}
+	versions := h.tables[tableID]
+	if len(versions) == 0 {
+		return "", nil, nil
+	}
+	i := sort.Search(len(versions), func(i int) bool { return versions[i].version > version })
+	if i > 0 {
+		i--
+	}
+	return h.dbs[versions[i].schemaID], versions[i].info, nil
+}
+
+// job returns the history DDL job. It returns nil if the job isn't found.
+func (h *schemaHistory) job(id int64) (*model.Job, error) {
+	if job, ok := h.jobs[id]; ok {
+		return job, nil
+	}
+	if err := h.load(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return h.jobs[id], nil
+}
+
+// schemaName returns the name of the database.
+func (h *schemaHistory) schemaName(id int64) string {
+	return h.dbs[id]
+}
+
+type tableVersions []tableVersion
+
+func (s tableVersions) Len() int           { return len(s) }
+func (s tableVersions) Less(i, j int) bool { return s[i].version < s[j].version }
+func (s tableVersions) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,330 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/binloginfo"
+	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func (s *testReplicationSuite) TestEncodeRows(c *C) {
+	defer testleak.AfterTest(c)()
+	newCol := func(tp byte, flen, decimal int, elems ...string) *model.ColumnInfo {
+		ft := types.NewFieldType(tp)
+		ft.Flen, ft.Decimal, ft.Elems = flen, decimal, elems
+		ft.Charset = "utf8"
+		return &model.ColumnInfo{FieldType: *ft}
+	}
+	datetime, err := types.ParseTime("2017-03-15 10:20:30.123", mysql.TypeDatetime, 3)
+	c.Assert(err, IsNil)
+	date, err := types.ParseTime("2017-03-15", mysql.TypeDate, 0)
+	c.Assert(err, IsNil)
+	timestamp, err := types.ParseTime("2017-03-15 10:20:30", mysql.TypeTimestamp, 0)
+	c.Assert(err, IsNil)
+	goTime, err := timestamp.Time.GoTime(time.UTC)
+	c.Assert(err, IsNil)
+	enum, err := types.ParseEnumName([]string{"a", "b"}, "b")
+	c.Assert(err, IsNil)
+	set, err := types.ParseSetName([]string{"x", "y"}, "x,y")
+	c.Assert(err, IsNil)
+
+	tbl := []struct {
+		col    *model.ColumnInfo
+		value  types.Datum
+		expect interface{}
+	}{
+		{newCol(mysql.TypeTiny, 4, 0), types.NewIntDatum(-1), int64(-1)},
+		{newCol(mysql.TypeLong, 11, 0), types.NewIntDatum(-5), int64(-5)},
+		{newCol(mysql.TypeLonglong, 20, 0), types.NewUintDatum(math.MaxUint64), int64(-1)},
+		{newCol(mysql.TypeYear, 4, 0), types.NewIntDatum(2017), int64(2017)},
+		{newCol(mysql.TypeDouble, 22, -1), types.NewFloat64Datum(1.5), 1.5},
+		{newCol(mysql.TypeNewDecimal, 10, 2), types.NewDecimalDatum(types.NewDecFromStringForTest("-12.34")), "-12.34"},
+		{newCol(mysql.TypeVarchar, 10, 0), types.NewStringDatum("abc"), "abc"},
+		{newCol(mysql.TypeVarchar, 100, 0), types.NewStringDatum("abc"), "abc"},
+		{newCol(mysql.TypeString, 3, 0), types.NewStringDatum("ab"), "ab"},
+		{newCol(mysql.TypeString, 100, 0), types.NewStringDatum("ab"), "ab"},
+		{newCol(mysql.TypeBlob, 65535, 0), types.NewBytesDatum([]byte{1, 2}), []byte{1, 2}},
+		{newCol(mysql.TypeEnum, 1, 0, "a", "b"), types.NewDatum(enum), int64(2)},
+		{newCol(mysql.TypeSet, 3, 0, "x", "y"), types.NewDatum(set), uint64(3)},
+		{newCol(mysql.TypeBit, 10, 0), types.NewDatum(types.Bit{Value: 0x201, Width: 10}), uint64(0x201)},
+		{newCol(mysql.TypeDate, 10, 0), types.NewDatum(date), "2017-03-15"},
+		{newCol(mysql.TypeDatetime, 23, 3), types.NewDatum(datetime), "2017-03-15 10:20:30.123"},
+		{newCol(mysql.TypeTimestamp, 19, 0), types.NewDatum(timestamp), formatTimestamp(goTime.Unix(), 0, 0)},
+		{newCol(mysql.TypeDuration, 11, 2), types.NewDurationDatum(types.Duration{Duration: 37230120 * time.Millisecond, Fsp: 2}), "10:20:30.12"},
+		{newCol(mysql.TypeDuration, 11, 2), types.NewDurationDatum(types.Duration{Duration: -37230120 * time.Millisecond, Fsp: 2}), "-10:20:30.12"},
+		{newCol(mysql.TypeDuration, 10, 0), types.NewDurationDatum(types.Duration{Duration: 37230 * time.Second}), "10:20:30"},
+		{newCol(mysql.TypeLong, 11, 0), types.Datum{}, nil},
+	}
+	for _, t := range tbl {
+		cols := []*model.ColumnInfo{t.col}
+		tableMap, err := encodeTableMapBody(1, "test", "t", cols)
+		c.Assert(err, IsNil)
+		rows := encodeRowsHeader(writeRowsEventV2, 1, stmtEndFlag, 1)
+		rows, err = appendRowImage(rows, cols, []types.Datum{t.value})
+		c.Assert(err, IsNil)
+
+		p := newEventParser()
+		_, err = p.parse(encodeEvent(0, tableMapEvent, 0, 0, tableMap, false))
+		c.Assert(err, IsNil)
+		e, err := p.parse(encodeEvent(0, writeRowsEventV2, 0, 0, rows, false))
+		c.Assert(err, IsNil)
+		c.Assert(e.data.(*rowsEventData).rows, DeepEquals, [][]interface{}{{t.expect}}, Commentf("type %d", t.col.Tp))
+	}
+
+	_, _, err = columnType(types.NewFieldType(mysql.TypeJSON))
+	c.Assert(err, NotNil)
+}
+
+var _ = Suite(&testDumpSuite{})
+
+type testDumpSuite struct {
+	store kv.Storage
+	path  string
+	tk    *testkit.TestKit
+}
+
+func (s *testDumpSuite) SetUpSuite(c *C) {
+	store, err := tikv.NewMockTikvStore("")
+	c.Assert(err, IsNil)
+	s.store = store
+	tidb.SetSchemaLease(0)
+	// Only the 2PC of tikv writes binlog.
+	s.path = "/tmp/test-dump-binlog"
+	os.Remove(s.path)
+	client, err := binloginfo.NewFileClient(s.path)
+	c.Assert(err, IsNil)
+	binloginfo.PumpClient = client
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	s.tk = testkit.NewTestKit(c, store)
+	s.tk.MustExec("use test")
+}
+
+func (s *testDumpSuite) TearDownSuite(c *C) {
+	sessionctx.GetDomain(s.tk.Se.(context.Context)).DDL().Stop()
+	binloginfo.PumpClient = nil
+	os.Remove(s.path)
+	s.store.Close()
+}
+
+// dumpEvents dumps the events until there are n transactions or DDL statements. The commit
+// binlogs are written asynchronously, so it waits for them.
+func dumpEvents(c *C, d *Dumper, n int) [][]byte {
+	var events [][]byte
+	p := newDumpParser(d)
+	for i := 0; n > 0; i++ {
+		event, err := d.Next()
+		c.Assert(err, IsNil)
+		if event == nil {
+			c.Assert(i < 300, IsTrue, Commentf("%d transactions not dumped", n))
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		events = append(events, event)
+		e, err := p.parse(event)
+		c.Assert(err, IsNil)
+		switch x := e.data.(type) {
+		case *xidEventData:
+			n--
+		case *queryEventData:
+			if x.query != "BEGIN" {
+				n--
+			}
+		}
+	}
+	return events
+}
+
+func (s *testDumpSuite) TestDumpAndApply(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := s.tk
+	tk.MustExec("create table dump_t (id int primary key, name varchar(20), price decimal(10,2), d datetime, t time(2), e enum('a','b'), u bigint unsigned)")
+	tk.MustExec("insert dump_t values (1, 'a', 1.5, '2017-03-15 10:20:30', '-10:20:30.12', 'b', 18446744073709551615), (2, 'b', null, null, null, null, null)")
+	tk.MustExec("update dump_t set name = 'aa' where id = 1")
+	tk.MustExec("delete from dump_t where id = 2")
+	tk.MustExec("alter table dump_t add column c int default 3")
+	tk.MustExec("insert dump_t (id, name) values (3, 'c')")
+	// The transactions on the system tables are not dumped.
+	tk.MustExec("insert mysql.user (host, user) values ('%', 'dump_user')")
+	defer tk.MustExec("delete from mysql.user where user = 'dump_user'")
+	tk.MustExec("begin")
+	tk.MustExec("insert dump_t (id) values (4)")
+	tk.MustExec("rollback")
+
+	d, err := NewDumper(s.store, s.path, Position{}, true)
+	c.Assert(err, IsNil)
+	defer d.Close()
+	// CREATE DATABASE test, then 2 DDL statements and 4 transactions of dump_t.
+	events := dumpEvents(c, d, 7)
+	event, err := d.Next()
+	c.Assert(err, IsNil)
+	c.Assert(event, IsNil)
+
+	target, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer target.Close()
+	_, err = tidb.BootstrapSession(target)
+	c.Assert(err, IsNil)
+	se, err := tidb.CreateSession(target)
+	c.Assert(err, IsNil)
+	defer se.Close()
+	a := newApplier(se, 1)
+	c.Assert(a.loadPosition(), IsNil)
+	p := newDumpParser(d)
+	for _, event := range events {
+		e, err := p.parse(event)
+		c.Assert(err, IsNil)
+		c.Assert(a.apply(e), IsNil)
+	}
+	c.Assert(a.pos, Equals, d.Position())
+	c.Assert(strings.HasPrefix(a.pos.Name, binlogBaseName+"."), IsTrue)
+	targetTK := testkit.NewTestKit(c, target)
+	expect := testkit.Rows(
+		"1 aa 1.50 2017-03-15 10:20:30 -10:20:30.12 b 18446744073709551615 3",
+		"3 c <nil> <nil> <nil> <nil> <nil> 3",
+	)
+	tk.MustQuery("select * from dump_t").Check(expect)
+	targetTK.MustQuery("select * from test.dump_t").Check(expect)
+
+	// Resume from the position of every event gets the same events after it.
+	// The artificial rotate event isn't in the file, the format description event is at 4.
+	pos := uint32(4)
+	for i := 2; i < len(events); i++ {
+		pos += uint32(len(events[i-1]))
+		resumed, err := NewDumper(s.store, s.path, Position{Name: a.pos.Name, Pos: pos}, true)
+		c.Assert(err, IsNil)
+		rest := drainEvents(c, resumed)
+		resumed.Close()
+		// The rotate event and the format description event are sent before the events.
+		c.Assert(rest[2:], DeepEquals, events[i:], Commentf("position %d", pos))
+	}
+
+	// The position isn't at an event.
+	bad, err := NewDumper(s.store, s.path, Position{Name: a.pos.Name, Pos: pos + 1}, true)
+	c.Assert(err, IsNil)
+	_, err = drainEventsErr(bad)
+	c.Assert(err, NotNil)
+	bad.Close()
+	_, err = NewDumper(s.store, s.path, Position{Name: binlogBaseName, Pos: 4}, true)
+	c.Assert(err, NotNil)
+
+	tk.MustExec("drop table dump_t")
+}
+
+func (s *testDumpSuite) TestDumpRotate(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := s.tk
+	tk.MustExec("create table dump_rotate (id int primary key)")
+	ver, err := s.store.CurrentVersion()
+	c.Assert(err, IsNil)
+	tk.MustExec("insert dump_rotate values (1)")
+	tk.MustExec("insert dump_rotate values (2)")
+	defer tk.MustExec("drop table dump_rotate")
+
+	// The file of a ts has the transactions committed after it.
+	d, err := NewDumper(s.store, s.path, Position{Name: binlogFileName(ver.Ver), Pos: 4}, false)
+	c.Assert(err, IsNil)
+	events := dumpEvents(c, d, 2)
+	d.Close()
+	p := newEventParser()
+	var inserted []interface{}
+	for _, event := range events {
+		e, err := p.parse(event)
+		c.Assert(err, IsNil)
+		if rows, ok := e.data.(*rowsEventData); ok {
+			inserted = append(inserted, rows.rows[0][0])
+		}
+	}
+	c.Assert(inserted, DeepEquals, []interface{}{int64(1), int64(2)})
+
+	origin := maxBinlogFileSize
+	maxBinlogFileSize = 1
+	defer func() { maxBinlogFileSize = origin }()
+	d, err = NewDumper(s.store, s.path, Position{Name: binlogFileName(ver.Ver), Pos: 4}, false)
+	c.Assert(err, IsNil)
+	events = dumpEvents(c, d, 2)
+	d.Close()
+	// Every transaction is followed by a rotate event and the format description event of the
+	// next file, which starts with the next transaction.
+	p = newEventParser()
+	var files []Position
+	for _, event := range events {
+		e, err := p.parse(event)
+		c.Assert(err, IsNil)
+		if r, ok := e.data.(*rotateEventData); ok {
+			files = append(files, r.position)
+		}
+	}
+	c.Assert(files, HasLen, 2)
+	c.Assert(files[0].Name, Equals, binlogFileName(ver.Ver))
+	next := files[1]
+	c.Assert(next.Pos, Equals, uint32(4))
+	d, err = NewDumper(s.store, s.path, next, false)
+	c.Assert(err, IsNil)
+	rest := drainEvents(c, d)
+	d.Close()
+	// BEGIN, TABLE_MAP, WRITE_ROWS, XID, then the next rotate and format description events.
+	c.Assert(rest[2:], HasLen, 6)
+	p = newEventParser()
+	for _, event := range rest[:5] {
+		e, err := p.parse(event)
+		c.Assert(err, IsNil)
+		if rows, ok := e.data.(*rowsEventData); ok {
+			c.Assert(rows.rows, DeepEquals, [][]interface{}{{int64(2)}})
+		}
+	}
+}
+
+// newDumpParser creates a parser of the events of the dumper. The slave knows the checksum
+// algorithm of the events before the format description event, as it asked for it.
+func newDumpParser(d *Dumper) *eventParser {
+	p := newEventParser()
+	if d.checksum {
+		p.checksumAlg = checksumAlgCRC32
+	}
+	return p
+}
+
+// drainEvents dumps all the events written so far.
+func drainEvents(c *C, d *Dumper) [][]byte {
+	events, err := drainEventsErr(d)
+	c.Assert(err, IsNil)
+	return events
+}
+
+func drainEventsErr(d *Dumper) ([][]byte, error) {
+	var events [][]byte
+	for {
+		event, err := d.Next()
+		if err != nil || event == nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+}
@@ -0,0 +1,431 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+)
+
+// Binlog event types, see https://dev.mysql.com/doc/internals/en/binlog-event-type.html
+const (
+	queryEvent             byte = 0x02
+	rotateEvent            byte = 0x04
+	formatDescriptionEvent byte = 0x0f
+	xidEvent               byte = 0x10
+	tableMapEvent          byte = 0x13
+	writeRowsEventV1       byte = 0x17
+	updateRowsEventV1      byte = 0x18
+	deleteRowsEventV1      byte = 0x19
+	heartbeatEvent         byte = 0x1b
+	writeRowsEventV2       byte = 0x1e
+	updateRowsEventV2      byte = 0x1f
+	deleteRowsEventV2      byte = 0x20
+	gtidEvent              byte = 0x21
+)
+
+// Column types only used in binlog.
+const (
+	typeTimestamp2 byte = 17
+	typeDatetime2  byte = 18
+	typeTime2      byte = 19
+)
+
+const (
+	eventHeaderLength = 19
+	checksumAlgOff    = 0
+	checksumAlgCRC32  = 1
+	checksumLength    = 4
+)
+
+// eventHeader is the v4 binlog event header.
+type eventHeader struct {
+	timestamp uint32
+	eventType byte
+	serverID  uint32
+	eventSize uint32
+	logPos    uint32
+	flags     uint16
+}
+
+func parseEventHeader(data []byte) (*eventHeader, error) {
+	if len(data) < eventHeaderLength {
+		return nil, errors.Errorf("invalid binlog event header length %d", len(data))
+	}
+	h := &eventHeader{
+		timestamp: binary.LittleEndian.Uint32(data),
+		eventType: data[4],
+		serverID:  binary.LittleEndian.Uint32(data[5:]),
+		eventSize: binary.LittleEndian.Uint32(data[9:]),
+		logPos:    binary.LittleEndian.Uint32(data[13:]),
+		flags:     binary.LittleEndian.Uint16(data[17:]),
+	}
+	if int(h.eventSize) != len(data) {
+		return nil, errors.Errorf("invalid binlog event size %d, got %d bytes", h.eventSize, len(data))
+	}
+	return h, nil
+}
+
+// rotateEventData is the body of ROTATE_EVENT.
+type rotateEventData struct {
+	position Position
+}
+
+// queryEventData is the body of QUERY_EVENT.
+type queryEventData struct {
+	schema string
+	query  string
+}
+
+// gtidEventData is the body of GTID_EVENT.
+type gtidEventData struct {
+	gtid string
+}
+
+// xidEventData is the body of XID_EVENT.
+type xidEventData struct {
+	xid uint64
+}
+
+// tableMap is the body of TABLE_MAP_EVENT, which describes the table of the following rows events.
+type tableMap struct {
+	tableID     uint64
+	schema      string
+	table       string
+	columnTypes []byte
+	columnMeta  []uint16
+}
+
+// rowsEventData is the body of WRITE_ROWS_EVENT, UPDATE_ROWS_EVENT and DELETE_ROWS_EVENT.
+// For UPDATE_ROWS_EVENT, the before image and the after image are stored in turn in rows.
+type rowsEventData struct {
+	eventType byte
+	table     *tableMap
+	rows      [][]interface{}
+}
+
+// event is a parsed binlog event.
+type event struct {
+	header *eventHeader
+	// data is one of the *xxxEventData types, or nil for the ignored events.
+	data interface{}
+}
+
+// eventParser parses binlog events, it keeps the state of the stream such as the table maps.
+type eventParser struct {
+	checksumAlg byte
+	tableIDSize int
+	tables      map[uint64]*tableMap
+}
+
+func newEventParser() *eventParser {
+	return &eventParser{
+		tableIDSize: 6,
+		tables:      make(map[uint64]*tableMap),
+	}
+}
+
+func (p *eventParser) parse(data []byte) (*event, error) {
+	h, err := parseEventHeader(data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	body := data[eventHeaderLength:]
+	if h.eventType != formatDescriptionEvent && p.checksumAlg == checksumAlgCRC32 {
+		if len(body) < checksumLength {
+			return nil, mysql.ErrMalformPacket
+		}
+		body = body[:len(body)-checksumLength]
+	}
+	e := &event{header: h}
+	switch h.eventType {
+	case formatDescriptionEvent:
+		err = p.parseFormatDescription(body)
+	case rotateEvent:
+		e.data, err = parseRotate(body)
+	case queryEvent:
+		e.data, err = parseQuery(body)
+	case gtidEvent:
+		e.data, err = parseGTID(body)
+	case xidEvent:
+		if len(body) < 8 {
+			return nil, mysql.ErrMalformPacket
+		}
+		e.data = &xidEventData{xid: binary.LittleEndian.Uint64(body)}
+	case tableMapEvent:
+		var t *tableMap
+		t, err = p.parseTableMap(body)
+		if err == nil {
+			p.tables[t.tableID] = t
+		}
+	case writeRowsEventV1, updateRowsEventV1, deleteRowsEventV1, writeRowsEventV2, updateRowsEventV2, deleteRowsEventV2:
+		e.data, err = p.parseRows(h.eventType, body)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return e, nil
+}
+
+func (p *eventParser) parseFormatDescription(body []byte) error {
+	if len(body) < 2+50+4+1 {
+		return mysql.ErrMalformPacket
+	}
+	version := string(bytes.TrimRight(body[2:52], "\x00"))
+	lengths := body[57:]
+	p.checksumAlg = checksumAlgOff
+	if versionAtLeast(version, 5, 6, 1) {
+		// The checksum algorithm and the checksum of this event follow the post header lengths.
+		if len(lengths) < 1+checksumLength {
+			return mysql.ErrMalformPacket
+		}
+		p.checksumAlg = lengths[len(lengths)-checksumLength-1]
+		lengths = lengths[:len(lengths)-checksumLength-1]
+	}
+	if p.checksumAlg != checksumAlgOff && p.checksumAlg != checksumAlgCRC32 {
+		return errors.Errorf("unsupported binlog checksum algorithm %d", p.checksumAlg)
+	}
+	p.tableIDSize = 6
+	if int(tableMapEvent) <= len(lengths) && lengths[tableMapEvent-1] == 6 {
+		p.tableIDSize = 4
+	}
+	return nil
+}
+
+// versionAtLeast checks whether the server version like "5.7.18-log" is at least major.minor.patch.
+func versionAtLeast(version string, major, minor, patch int) bool {
+	if i := strings.IndexByte(version, '-'); i >= 0 {
+		version = version[:i]
+	}
+	want := []int{major, minor, patch}
+	for i, s := range strings.SplitN(version, ".", 3) {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return false
+		}
+		if v != want[i] {
+			return v > want[i]
+		}
+	}
+	return true
+}
+
+func parseRotate(body []byte) (*rotateEventData, error) {
+	if len(body) < 8 {
+		return nil, mysql.ErrMalformPacket
+	}
+	return &rotateEventData{position: Position{
+		Name: string(body[8:]),
+		Pos:  uint32(binary.LittleEndian.Uint64(body)),
+	}}, nil
+}
+
+func parseQuery(body []byte) (*queryEventData, error) {
+	// slave proxy id, execution time, schema length, error code, status vars length.
+	if len(body) < 4+4+1+2+2 {
+		return nil, mysql.ErrMalformPacket
+	}
+	schemaLen := int(body[8])
+	statusLen := int(binary.LittleEndian.Uint16(body[11:]))
+	pos := 13 + statusLen
+	if len(body) < pos+schemaLen+1 {
+		return nil, mysql.ErrMalformPacket
+	}
+	return &queryEventData{
+		schema: string(body[pos : pos+schemaLen]),
+		query:  string(body[pos+schemaLen+1:]),
+	}, nil
+}
+
+func parseGTID(body []byte) (*gtidEventData, error) {
+	if len(body) < 1+16+8 {
+		return nil, mysql.ErrMalformPacket
+	}
+	sid := hex.EncodeToString(body[1:17])
+	gno := binary.LittleEndian.Uint64(body[17:])
+	return &gtidEventData{
+		gtid: fmt.Sprintf("%s-%s-%s-%s-%s:%d", sid[:8], sid[8:12], sid[12:16], sid[16:20], sid[20:], gno),
+	}, nil
+}
+
+func (p *eventParser) readTableID(body []byte) uint64 {
+	if p.tableIDSize == 4 {
+		return uint64(binary.LittleEndian.Uint32(body))
+	}
+	var buf [8]byte
+	copy(buf[:], body[:6])
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+func (p *eventParser) parseTableMap(body []byte) (*tableMap, error) {
+	if len(body) < p.tableIDSize+2+1 {
+		return nil, mysql.ErrMalformPacket
+	}
+	t := &tableMap{tableID: p.readTableID(body)}
+	pos := p.tableIDSize + 2
+	schemaLen := int(body[pos])
+	pos++
+	if len(body) < pos+schemaLen+2 {
+		return nil, mysql.ErrMalformPacket
+	}
+	t.schema = string(body[pos : pos+schemaLen])
+	pos += schemaLen + 1
+	tableLen := int(body[pos])
+	pos++
+	if len(body) < pos+tableLen+2 {
+		return nil, mysql.ErrMalformPacket
+	}
+	t.table = string(body[pos : pos+tableLen])
+	pos += tableLen + 1
+	count, _, n := parseLengthEncodedInt(body[pos:])
+	pos += n
+	if len(body) < pos+int(count)+1 {
+		return nil, mysql.ErrMalformPacket
+	}
+	t.columnTypes = append([]byte{}, body[pos:pos+int(count)]...)
+	pos += int(count)
+	metaLen, _, n := parseLengthEncodedInt(body[pos:])
+	pos += n
+	if len(body) < pos+int(metaLen) {
+		return nil, mysql.ErrMalformPacket
+	}
+	meta, err := parseColumnMeta(t.columnTypes, body[pos:pos+int(metaLen)])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	t.columnMeta = meta
+	return t, nil
+}
+
+// parseColumnMeta parses the metadata of each column in TABLE_MAP_EVENT.
+func parseColumnMeta(types []byte, data []byte) ([]uint16, error) {
+	meta := make([]uint16, len(types))
+	pos := 0
+	for i, tp := range types {
+		var size int
+		switch tp {
+		case mysql.TypeFloat, mysql.TypeDouble, mysql.TypeBlob, mysql.TypeGeometry, mysql.TypeJSON,
+			typeTime2, typeDatetime2, typeTimestamp2:
+			size = 1
+		case mysql.TypeVarchar, mysql.TypeBit, mysql.TypeVarString:
+			size = 2
+		case mysql.TypeString, mysql.TypeNewDecimal, mysql.TypeEnum, mysql.TypeSet:
+			// These two bytes are stored in big endian order.
+			if len(data) < pos+2 {
+				return nil, mysql.ErrMalformPacket
+			}
+			meta[i] = uint16(data[pos])<<8 | uint16(data[pos+1])
+			pos += 2
+			continue
+		}
+		if len(data) < pos+size {
+			return nil, mysql.ErrMalformPacket
+		}
+		switch size {
+		case 1:
+			meta[i] = uint16(data[pos])
+		case 2:
+			meta[i] = binary.LittleEndian.Uint16(data[pos:])
+		}
+		pos += size
+	}
+	return meta, nil
+}
+
+func (p *eventParser) parseRows(tp byte, body []byte) (*rowsEventData, error) {
+	if len(body) < p.tableIDSize+2 {
+		return nil, mysql.ErrMalformPacket
+	}
+	tableID := p.readTableID(body)
+	t, ok := p.tables[tableID]
+	if !ok {
+		return nil, errors.Errorf("table map of table id %d not found", tableID)
+	}
+	pos := p.tableIDSize + 2
+	isV2 := tp == writeRowsEventV2 || tp == updateRowsEventV2 || tp == deleteRowsEventV2
+	if isV2 {
+		if len(body) < pos+2 {
+			return nil, mysql.ErrMalformPacket
+		}
+		// The extra data length includes the 2 bytes of itself.
+		pos += int(binary.LittleEndian.Uint16(body[pos:]))
+	}
+	if len(body) <= pos {
+		return nil, mysql.ErrMalformPacket
+	}
+	count, _, n := parseLengthEncodedInt(body[pos:])
+	pos += n
+	if int(count) != len(t.columnTypes) {
+		return nil, errors.Errorf("column count %d of rows event mismatches table map %d", count, len(t.columnTypes))
+	}
+	bitmapLen := (int(count) + 7) / 8
+	isUpdate := tp == updateRowsEventV1 || tp == updateRowsEventV2
+	if len(body) < pos+bitmapLen {
+		return nil, mysql.ErrMalformPacket
+	}
+	present := body[pos : pos+bitmapLen]
+	pos += bitmapLen
+	presentAfter := present
+	if isUpdate {
+		if len(body) < pos+bitmapLen {
+			return nil, mysql.ErrMalformPacket
+		}
+		presentAfter = body[pos : pos+bitmapLen]
+		pos += bitmapLen
+	}
+
+	e := &rowsEventData{eventType: normalizeRowsEventType(tp), table: t}
+	for pos < len(body) {
+		row, n, err := decodeRow(t, present, body[pos:])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pos += n
+		e.rows = append(e.rows, row)
+		if isUpdate {
+			row, n, err = decodeRow(t, presentAfter, body[pos:])
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			pos += n
+			e.rows = append(e.rows, row)
+		}
+	}
+	return e, nil
+}
+
+// normalizeRowsEventType maps the v1 rows event types to v2.
+func normalizeRowsEventType(tp byte) byte {
+	switch tp {
+	case writeRowsEventV1:
+		return writeRowsEventV2
+	case updateRowsEventV1:
+		return updateRowsEventV2
+	case deleteRowsEventV1:
+		return deleteRowsEventV2
+	}
+	return tp
+}
+
+// isBitSet returns whether the i-th bit of the bitmap is set.
+func isBitSet(bitmap []byte, i int) bool {
+	return bitmap[i/8]&(1<<uint(i%8)) != 0
+}
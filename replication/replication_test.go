@@ -0,0 +1,212 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"encoding/binary"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testReplicationSuite{})
+
+type testReplicationSuite struct{}
+
+// datetime2 is '2017-03-15 10:20:30' encoded as DATETIME2(0).
+var datetime2 = []byte{0x99, 0x9c, 0x1e, 0xa5, 0x1e}
+
+func buildEvent(tp byte, logPos uint32, body []byte) []byte {
+	data := make([]byte, eventHeaderLength, eventHeaderLength+len(body))
+	data[4] = tp
+	binary.LittleEndian.PutUint32(data[5:], 1)
+	binary.LittleEndian.PutUint32(data[9:], uint32(eventHeaderLength+len(body)))
+	binary.LittleEndian.PutUint32(data[13:], logPos)
+	return append(data, body...)
+}
+
+func buildQueryEvent(logPos uint32, schema, query string) []byte {
+	body := make([]byte, 13)
+	body[8] = byte(len(schema))
+	body = append(body, schema...)
+	body = append(body, 0)
+	body = append(body, query...)
+	return buildEvent(queryEvent, logPos, body)
+}
+
+// buildTableMapEvent builds the table map event of `test`.`t` (INT, VARCHAR(255), DATETIME(0)).
+func buildTableMapEvent(logPos uint32) []byte {
+	body := []byte{1, 0, 0, 0, 0, 0, 0, 0}
+	body = append(body, 4)
+	body = append(body, "test"...)
+	body = append(body, 0, 1, 't', 0)
+	body = append(body, 3, mysql.TypeLong, mysql.TypeVarchar, typeDatetime2)
+	body = append(body, 3, 0xff, 0x00, 0x00)
+	body = append(body, 0x06)
+	return buildEvent(tableMapEvent, logPos, body)
+}
+
+type testRow struct {
+	id   int32
+	name string
+}
+
+func encodeTestRow(r testRow) []byte {
+	data := []byte{0}
+	var id [4]byte
+	binary.LittleEndian.PutUint32(id[:], uint32(r.id))
+	data = append(data, id[:]...)
+	data = append(data, byte(len(r.name)))
+	data = append(data, r.name...)
+	return append(data, datetime2...)
+}
+
+func buildRowsEvent(tp byte, logPos uint32, rows ...testRow) []byte {
+	body := []byte{1, 0, 0, 0, 0, 0, 0, 0, 2, 0, 3, 0x07}
+	if tp == updateRowsEventV2 {
+		body = append(body, 0x07)
+	}
+	for _, r := range rows {
+		body = append(body, encodeTestRow(r)...)
+	}
+	return buildEvent(tp, logPos, body)
+}
+
+func (s *testReplicationSuite) TestDecodeValue(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		tp     byte
+		meta   uint16
+		data   []byte
+		expect interface{}
+		n      int
+	}{
+		{mysql.TypeTiny, 0, []byte{0xff}, int64(-1), 1},
+		{mysql.TypeInt24, 0, []byte{0xfe, 0xff, 0xff}, int64(-2), 3},
+		{mysql.TypeLong, 0, []byte{0x10, 0x27, 0, 0}, int64(10000), 4},
+		{mysql.TypeYear, 0, []byte{117}, int64(2017), 1},
+		{mysql.TypeVarchar, 10, []byte{3, 'a', 'b', 'c'}, "abc", 4},
+		{mysql.TypeVarchar, 1000, []byte{3, 0, 'a', 'b', 'c'}, "abc", 5},
+		{mysql.TypeString, uint16(mysql.TypeString)<<8 | 20, []byte{2, 'x', 'y'}, "xy", 3},
+		{mysql.TypeBlob, 2, []byte{2, 0, 1, 2}, []byte{1, 2}, 4},
+		{mysql.TypeDate, 0, []byte{0x6f, 0xc2, 0x0f}, "2017-03-15", 3},
+		{typeDatetime2, 0, datetime2, "2017-03-15 10:20:30", 5},
+		{typeDatetime2, 2, append(datetime2, 12), "2017-03-15 10:20:30.12", 6},
+		{typeTime2, 0, []byte{0x80, 0xa5, 0x1e}, "10:20:30", 3},
+	}
+	for _, t := range tbl {
+		v, n, err := decodeValue(t.tp, t.meta, t.data)
+		c.Assert(err, IsNil)
+		c.Assert(v, DeepEquals, t.expect, Commentf("type %d", t.tp))
+		c.Assert(n, Equals, t.n)
+	}
+
+	// Malformed data returns an error instead of panic.
+	_, _, err := decodeValue(mysql.TypeLonglong, 0, []byte{1, 2})
+	c.Assert(err, NotNil)
+}
+
+func (s *testReplicationSuite) TestParseRowsEvent(c *C) {
+	defer testleak.AfterTest(c)()
+	p := newEventParser()
+	_, err := p.parse(buildRowsEvent(writeRowsEventV2, 100, testRow{1, "a"}))
+	c.Assert(err, NotNil)
+
+	e, err := p.parse(buildTableMapEvent(100))
+	c.Assert(err, IsNil)
+	c.Assert(e.data, IsNil)
+	c.Assert(p.tables, HasLen, 1)
+
+	e, err = p.parse(buildRowsEvent(updateRowsEventV2, 200, testRow{1, "a"}, testRow{-1, "bc"}))
+	c.Assert(err, IsNil)
+	c.Assert(e.header.logPos, Equals, uint32(200))
+	rows := e.data.(*rowsEventData)
+	c.Assert(rows.eventType, Equals, updateRowsEventV2)
+	c.Assert(rows.table.schema, Equals, "test")
+	c.Assert(rows.table.table, Equals, "t")
+	c.Assert(rows.rows, DeepEquals, [][]interface{}{
+		{int64(1), "a", "2017-03-15 10:20:30"},
+		{int64(-1), "bc", "2017-03-15 10:20:30"},
+	})
+
+	e, err = p.parse(buildQueryEvent(300, "test", "BEGIN"))
+	c.Assert(err, IsNil)
+	c.Assert(e.data, DeepEquals, &queryEventData{schema: "test", query: "BEGIN"})
+
+	rotate := []byte{4, 0, 0, 0, 0, 0, 0, 0}
+	e, err = p.parse(buildEvent(rotateEvent, 0, append(rotate, "mysql-bin.000002"...)))
+	c.Assert(err, IsNil)
+	c.Assert(e.data, DeepEquals, &rotateEventData{position: Position{Name: "mysql-bin.000002", Pos: 4}})
+}
+
+func (s *testReplicationSuite) TestApply(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	tidb.SetSchemaLease(0)
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	tk := testkit.NewTestKit(c, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int unsigned primary key, name varchar(255), ts datetime)")
+
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+	defer se.Close()
+	a := newApplier(se, 1)
+	c.Assert(a.loadPosition(), IsNil)
+	c.Assert(a.pos.Name, Equals, "")
+	a.pos = Position{Name: "mysql-bin.000001", Pos: 4}
+
+	p := newEventParser()
+	events := [][]byte{
+		buildQueryEvent(100, "test", "BEGIN"),
+		buildTableMapEvent(200),
+		buildRowsEvent(writeRowsEventV2, 300, testRow{1, "a"}, testRow{2, "b"}, testRow{-1, "c"}),
+		buildEvent(xidEvent, 400, make([]byte, 8)),
+		buildQueryEvent(500, "test", "BEGIN"),
+		buildTableMapEvent(600),
+		buildRowsEvent(updateRowsEventV2, 700, testRow{1, "a"}, testRow{1, "aa"}),
+		buildRowsEvent(deleteRowsEventV2, 800, testRow{2, "b"}),
+		buildQueryEvent(900, "", "COMMIT"),
+		buildQueryEvent(1000, "test", "create table t1 (a int)"),
+	}
+	for _, data := range events {
+		e, err := p.parse(data)
+		c.Assert(err, IsNil)
+		c.Assert(a.apply(e), IsNil)
+	}
+	tk.MustQuery("select * from t").Check(testkit.Rows(
+		"1 aa 2017-03-15 10:20:30",
+		"4294967295 c 2017-03-15 10:20:30",
+	))
+	tk.MustQuery("show tables like 't1'").Check(testkit.Rows("t1"))
+	tk.MustQuery("select binlog_name, binlog_pos from mysql.tidb_replication_position where server_id = 1").
+		Check(testkit.Rows("mysql-bin.000001 1000"))
+
+	a = newApplier(se, 1)
+	c.Assert(a.loadPosition(), IsNil)
+	c.Assert(a.pos, Equals, Position{Name: "mysql-bin.000001", Pos: 1000})
+	tk.MustExec("drop table t, t1")
+}
@@ -0,0 +1,267 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// absentColumn is the value of the columns which are not in the row image.
+type absentColumn struct{}
+
+// decodeRow decodes a row image of the rows event. It returns the values of all the columns
+// and the number of bytes consumed.
+func decodeRow(t *tableMap, present []byte, data []byte) ([]interface{}, int, error) {
+	presentCount := 0
+	for i := range t.columnTypes {
+		if isBitSet(present, i) {
+			presentCount++
+		}
+	}
+	nullBitmapLen := (presentCount + 7) / 8
+	if len(data) < nullBitmapLen {
+		return nil, 0, mysql.ErrMalformPacket
+	}
+	nullBitmap := data[:nullBitmapLen]
+	pos := nullBitmapLen
+	row := make([]interface{}, len(t.columnTypes))
+	idx := 0
+	for i, tp := range t.columnTypes {
+		if !isBitSet(present, i) {
+			row[i] = absentColumn{}
+			continue
+		}
+		if isBitSet(nullBitmap, idx) {
+			idx++
+			continue
+		}
+		idx++
+		v, n, err := decodeValue(tp, t.columnMeta[i], data[pos:])
+		if err != nil {
+			return nil, 0, errors.Annotatef(err, "decode column %d of %s.%s", i, t.schema, t.table)
+		}
+		row[i] = v
+		pos += n
+	}
+	return row, pos, nil
+}
+
+// decodeValue decodes a column value. Integers are decoded as signed int64, the caller should
+// convert them if the column is unsigned.
+func decodeValue(tp byte, meta uint16, data []byte) (v interface{}, n int, err error) {
+	// Guard against malformed events, every branch reads at most n bytes from data.
+	defer func() {
+		if r := recover(); r != nil {
+			v, n, err = nil, 0, mysql.ErrMalformPacket
+		}
+	}()
+	length := 0
+	if tp == mysql.TypeString && meta >= 256 {
+		realType := byte(meta >> 8)
+		if realType&0x30 != 0x30 {
+			length = int(uint16(realType&0x30^0x30)<<4 | meta&0xff)
+			realType |= 0x30
+		} else {
+			length = int(meta & 0xff)
+		}
+		tp = realType
+	} else if tp == mysql.TypeString {
+		length = int(meta)
+	}
+
+	switch tp {
+	case mysql.TypeTiny:
+		return int64(int8(data[0])), 1, nil
+	case mysql.TypeShort:
+		return int64(int16(binary.LittleEndian.Uint16(data))), 2, nil
+	case mysql.TypeInt24:
+		u := uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16
+		if u&0x800000 != 0 {
+			u |= 0xff000000
+		}
+		return int64(int32(u)), 3, nil
+	case mysql.TypeLong:
+		return int64(int32(binary.LittleEndian.Uint32(data))), 4, nil
+	case mysql.TypeLonglong:
+		return int64(binary.LittleEndian.Uint64(data)), 8, nil
+	case mysql.TypeYear:
+		if data[0] == 0 {
+			return int64(0), 1, nil
+		}
+		return int64(data[0]) + 1900, 1, nil
+	case mysql.TypeFloat:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(data))), 4, nil
+	case mysql.TypeDouble:
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), 8, nil
+	case mysql.TypeNewDecimal:
+		precision, frac := int(meta>>8), int(meta&0xff)
+		dec := new(types.MyDecimal)
+		n, err = dec.FromBin(data, precision, frac)
+		if err != nil {
+			return nil, 0, errors.Trace(err)
+		}
+		return dec.String(), n, nil
+	case mysql.TypeVarchar, mysql.TypeVarString:
+		return decodeString(data, int(meta))
+	case mysql.TypeString:
+		return decodeString(data, length)
+	case mysql.TypeEnum:
+		switch meta & 0xff {
+		case 1:
+			return int64(data[0]), 1, nil
+		case 2:
+			return int64(binary.LittleEndian.Uint16(data)), 2, nil
+		}
+		return nil, 0, errors.Errorf("invalid enum length %d", meta&0xff)
+	case mysql.TypeSet:
+		n = int(meta & 0xff)
+		return readLittleEndianUint(data[:n]), n, nil
+	case mysql.TypeBit:
+		n = int(meta>>8) + int(meta&0xff+7)/8
+		return readBigEndianUint(data[:n]), n, nil
+	case mysql.TypeBlob:
+		n = int(meta)
+		size := int(readLittleEndianUint(data[:n]))
+		return append([]byte{}, data[n:n+size]...), n + size, nil
+	case mysql.TypeDate:
+		u := uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16
+		return fmt.Sprintf("%04d-%02d-%02d", u>>9, (u>>5)&15, u&31), 3, nil
+	case mysql.TypeTimestamp:
+		return formatTimestamp(int64(binary.LittleEndian.Uint32(data)), 0, 0), 4, nil
+	case mysql.TypeDatetime:
+		u := binary.LittleEndian.Uint64(data)
+		date, clock := u/1000000, u%1000000
+		return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", date/10000, date%10000/100, date%100,
+			clock/10000, clock%10000/100, clock%100), 8, nil
+	case typeTimestamp2:
+		sec := int64(binary.BigEndian.Uint32(data))
+		frac, n := decodeFrac(data[4:], int(meta))
+		return formatTimestamp(sec, frac, int(meta)), 4 + n, nil
+	case typeDatetime2:
+		return decodeDatetime2(data, int(meta))
+	case typeTime2:
+		return decodeTime2(data, int(meta))
+	}
+	return nil, 0, errors.Errorf("unsupported column type %d", tp)
+}
+
+func decodeString(data []byte, maxLength int) (interface{}, int, error) {
+	if maxLength < 256 {
+		size := int(data[0])
+		return string(data[1 : 1+size]), 1 + size, nil
+	}
+	size := int(binary.LittleEndian.Uint16(data))
+	return string(data[2 : 2+size]), 2 + size, nil
+}
+
+func readLittleEndianUint(data []byte) uint64 {
+	var u uint64
+	for i := len(data) - 1; i >= 0; i-- {
+		u = u<<8 | uint64(data[i])
+	}
+	return u
+}
+
+func readBigEndianUint(data []byte) uint64 {
+	var u uint64
+	for _, b := range data {
+		u = u<<8 | uint64(b)
+	}
+	return u
+}
+
+// decodeFrac decodes the fractional seconds part in microseconds.
+func decodeFrac(data []byte, fsp int) (int64, int) {
+	n := (fsp + 1) / 2
+	if n == 0 {
+		return 0, 0
+	}
+	frac := int64(readBigEndianUint(data[:n]))
+	// The fraction is stored with the precision of n*2 digits.
+	for i := n * 2; i < 6; i++ {
+		frac *= 10
+	}
+	return frac, n
+}
+
+func formatFrac(frac int64, fsp int) string {
+	if fsp == 0 {
+		return ""
+	}
+	return fmt.Sprintf(".%06d", frac)[:fsp+1]
+}
+
+func formatTimestamp(sec, frac int64, fsp int) string {
+	if sec == 0 {
+		return "0000-00-00 00:00:00" + formatFrac(0, fsp)
+	}
+	return time.Unix(sec, 0).Local().Format("2006-01-02 15:04:05") + formatFrac(frac, fsp)
+}
+
+func decodeDatetime2(data []byte, fsp int) (interface{}, int, error) {
+	v := int64(readBigEndianUint(data[:5])) - 0x8000000000
+	frac, n := decodeFrac(data[5:], fsp)
+	ymd := v >> 17
+	ym := ymd >> 5
+	hms := v % (1 << 17)
+	return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", ym/13, ym%13, ymd%(1<<5),
+		hms>>12, (hms>>6)%(1<<6), hms%(1<<6)) + formatFrac(frac, fsp), 5 + n, nil
+}
+
+func decodeTime2(data []byte, fsp int) (interface{}, int, error) {
+	var v, frac int64
+	n := 3
+	switch fsp {
+	case 0:
+		v = int64(readBigEndianUint(data[:3])) - 0x800000
+		v <<= 24
+	case 1, 2:
+		intPart := int64(readBigEndianUint(data[:3])) - 0x800000
+		f := int64(data[3])
+		if intPart < 0 && f > 0 {
+			intPart++
+			f -= 0x100
+		}
+		v = intPart<<24 + f*10000
+		n = 4
+	case 3, 4:
+		intPart := int64(readBigEndianUint(data[:3])) - 0x800000
+		f := int64(readBigEndianUint(data[3:5]))
+		if intPart < 0 && f > 0 {
+			intPart++
+			f -= 0x10000
+		}
+		v = intPart<<24 + f*100
+		n = 5
+	default:
+		v = int64(readBigEndianUint(data[:6])) - 0x800000000000
+		n = 6
+	}
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	hms := v >> 24
+	frac = v % (1 << 24)
+	return fmt.Sprintf("%s%02d:%02d:%02d", sign, (hms>>12)%(1<<10), (hms>>6)%(1<<6), hms%(1<<6)) +
+		formatFrac(frac, fsp), n, nil
+}
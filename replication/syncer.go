@@ -0,0 +1,193 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/kv"
+)
+
+const retryInterval = 3 * time.Second
+
+// Config is the configuration to replicate from a MySQL master.
+type Config struct {
+	// MasterAddr is the address of the master, in the form of host:port.
+	MasterAddr string
+	User       string
+	Password   string
+	// ServerID is the server id used to register as a slave, it must be unique among
+	// all the slaves of the master. Positions are saved per server id.
+	ServerID uint32
+}
+
+// Syncer replicates the row-based binlog of a MySQL master to TiDB. The master must
+// have binlog_format = ROW, and the schemas must exist in TiDB before replication
+// starts, then DDL statements are replayed in order to keep the schemas in sync.
+type Syncer struct {
+	cfg   *Config
+	store kv.Storage
+	quit  chan struct{}
+	wg    sync.WaitGroup
+
+	mu   sync.Mutex
+	conn *masterConn
+}
+
+// NewSyncer creates a Syncer.
+func NewSyncer(store kv.Storage, cfg *Config) *Syncer {
+	return &Syncer{
+		cfg:   cfg,
+		store: store,
+		quit:  make(chan struct{}),
+	}
+}
+
+// Start starts replicating in background, it reconnects to the master on errors.
+func (s *Syncer) Start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Close stops replicating and waits for the syncer to exit.
+func (s *Syncer) Close() {
+	close(s.quit)
+	s.mu.Lock()
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Syncer) run() {
+	defer s.wg.Done()
+	for {
+		err := s.sync()
+		select {
+		case <-s.quit:
+			return
+		default:
+		}
+		log.Errorf("[replication] sync from %s failed: %v", s.cfg.MasterAddr, errors.ErrorStack(err))
+		select {
+		case <-s.quit:
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+func (s *Syncer) setConn(conn *masterConn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.quit:
+		return false
+	default:
+	}
+	s.conn = conn
+	return true
+}
+
+func (s *Syncer) sync() error {
+	se, err := tidb.CreateSession(s.store)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer se.Close()
+	a := newApplier(se, s.cfg.ServerID)
+	if err = a.loadPosition(); err != nil {
+		return errors.Trace(err)
+	}
+
+	conn, err := dialMaster(s.cfg.MasterAddr, s.cfg.User, s.cfg.Password)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer conn.Close()
+	if !s.setConn(conn) {
+		return nil
+	}
+
+	if a.pos.Name == "" {
+		a.pos, err = masterStatus(conn)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	// Tell the master we can verify checksums, otherwise a master with binlog checksum
+	// enabled refuses to dump. It fails on masters without the variable, which is fine.
+	checksumAlg := byte(checksumAlgOff)
+	if _, err = conn.query("SET @master_binlog_checksum = @@global.binlog_checksum"); err != nil {
+		log.Warnf("[replication] set master_binlog_checksum failed: %v", err)
+	} else if rows, err := conn.query("SELECT @master_binlog_checksum"); err == nil && len(rows) > 0 && strings.EqualFold(rows[0][0], "CRC32") {
+		checksumAlg = checksumAlgCRC32
+	}
+	if err = conn.registerSlave(s.cfg.ServerID); err != nil {
+		return errors.Trace(err)
+	}
+	if err = conn.binlogDump(s.cfg.ServerID, a.pos); err != nil {
+		return errors.Trace(err)
+	}
+	log.Infof("[replication] start syncing from %s at %s", s.cfg.MasterAddr, a.pos)
+
+	defer a.rollback()
+	parser := newEventParser()
+	// The rotate event before the format description event has a checksum if we asked for it.
+	parser.checksumAlg = checksumAlg
+	for {
+		data, err := conn.readEvent()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e, err := parser.parse(data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = a.apply(e); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// masterStatus returns the current binlog position of the master.
+func masterStatus(conn *masterConn) (Position, error) {
+	rows, err := conn.query("SHOW MASTER STATUS")
+	if err != nil {
+		return Position{}, errors.Trace(err)
+	}
+	if len(rows) == 0 || len(rows[0]) < 2 {
+		return Position{}, errors.New("binlog is not enabled on the master")
+	}
+	// A TiDB master reports the current ts as the position, the binlog file after it is dumped.
+	if rows[0][0] == binlogBaseName {
+		ts, err := strconv.ParseUint(rows[0][1], 10, 64)
+		if err != nil {
+			return Position{}, errors.Trace(err)
+		}
+		return Position{Name: binlogFileName(ts), Pos: 4}, nil
+	}
+	pos, err := strconv.ParseUint(rows[0][1], 10, 32)
+	if err != nil {
+		return Position{}, errors.Trace(err)
+	}
+	return Position{Name: rows[0][0], Pos: uint32(pos)}, nil
+}
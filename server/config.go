@@ -13,6 +13,8 @@
 
 package server
 
+import "time"
+
 // Config contains configuration options.
 type Config struct {
 	Addr         string `json:"addr" toml:"addr"`
@@ -23,4 +25,12 @@ type Config struct {
 	ReportStatus bool   `json:"report_status" toml:"report_status"`
 	StorePath    string `json:"store_path" toml:"store_path"`
 	Store        string `json:"store" toml:"store"`
+	// BinlogFile is the binlog file written by the server, the binlog events sent to the
+	// replication slaves are generated from it.
+	BinlogFile string `json:"binlog_file" toml:"binlog_file"`
+	// TxnWarnTime, TxnKillTime and IdleTxnKillTime are the thresholds of the long transactions, see
+	// txn_checker.go. The zero values disable them.
+	TxnWarnTime     time.Duration `json:"txn_warn_time" toml:"txn_warn_time"`
+	TxnKillTime     time.Duration `json:"txn_kill_time" toml:"txn_kill_time"`
+	IdleTxnKillTime time.Duration `json:"idle_txn_kill_time" toml:"idle_txn_kill_time"`
 }
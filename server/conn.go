@@ -44,15 +44,20 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/arena"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/types"
 )
 
 var defaultCapability = mysql.ClientLongPassword | mysql.ClientLongFlag |
@@ -78,6 +83,7 @@ type clientConn struct {
 	ctx          QueryCtx          // an interface to execute sql statements.
 	attrs        map[string]string // attributes parsed from client handshake response, not used for now.
 	killed       bool
+	warnedTxn    uint64 // start ts of the latest long transaction warned by the txn checker.
 }
 
 func (cc *clientConn) String() string {
@@ -118,6 +124,7 @@ func (cc *clientConn) handshake() error {
 func (cc *clientConn) Close() error {
 	cc.server.rwlock.Lock()
 	delete(cc.server.clients, cc.connectionID)
+	delete(cc.server.slaves, cc.connectionID)
 	connections := len(cc.server.clients)
 	cc.server.rwlock.Unlock()
 	connGauge.Set(float64(connections))
@@ -340,11 +347,16 @@ func (cc *clientConn) Run() {
 
 	for !cc.killed {
 		cc.alloc.Reset()
+		cc.pkt.maxAllowedPacket = cc.maxAllowedPacket()
 		data, err := cc.readPacket()
 		if err != nil {
 			if terror.ErrorNotEqual(err, io.EOF) {
 				log.Error(errors.ErrorStack(err))
 			}
+			// The rest of the packet is not read, so the connection is closed after the error is sent.
+			if terror.ErrorEqual(err, errNetPacketTooLarge) {
+				cc.writeError(err)
+			}
 			return
 		}
 
@@ -366,6 +378,12 @@ func (cc *clientConn) Run() {
 				default:
 				}
 				return
+			} else if terror.ErrorEqual(err, errNetPacketTooLarge) {
+				// A part of the result set may have been written, the client can't go on after the error.
+				// Send the error and close the connection like MySQL.
+				log.Warnf("[%d] packet too large, close this connection %s", cc.connectionID, queryStrForLog(string(data[1:])))
+				cc.writeError(err)
+				return
 			}
 			log.Warnf("[%d] dispatch error:\n%s\n%s\n%s",
 				cc.connectionID, cc, queryStrForLog(string(data[1:])), errStrForLog(err))
@@ -376,6 +394,22 @@ func (cc *clientConn) Run() {
 	}
 }
 
+// maxAllowedPacket returns the max_allowed_packet of the session.
+func (cc *clientConn) maxAllowedPacket() uint64 {
+	if cc.ctx == nil {
+		return defaultMaxAllowedPacket
+	}
+	val, ok := cc.ctx.GetSessionVars().Systems[variable.MaxAllowedPacket]
+	if !ok {
+		return defaultMaxAllowedPacket
+	}
+	maxAllowedPacket, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return defaultMaxAllowedPacket
+	}
+	return maxAllowedPacket
+}
+
 func queryStrForLog(query string) string {
 	const size = 4096
 	if len(query) > size {
@@ -419,6 +453,10 @@ func (cc *clientConn) addMetrics(cmd byte, startTime time.Time, err error) {
 		label = "StmtReset"
 	case mysql.ComSetOption:
 		label = "SetOption"
+	case mysql.ComRegisterSlave:
+		label = "RegisterSlave"
+	case mysql.ComBinlogDump:
+		label = "BinlogDump"
 	default:
 		label = strconv.Itoa(int(cmd))
 	}
@@ -458,7 +496,7 @@ func (cc *clientConn) dispatch(data []byte) error {
 		if len(data) > 0 && data[len(data)-1] == 0 {
 			data = data[:len(data)-1]
 		}
-		return cc.handleQuery(hack.String(data))
+		return cc.handleQuery(cc.decodeQuery(data))
 	case mysql.ComPing:
 		return cc.writeOK()
 	case mysql.ComInitDB:
@@ -467,9 +505,9 @@ func (cc *clientConn) dispatch(data []byte) error {
 		}
 		return cc.writeOK()
 	case mysql.ComFieldList:
-		return cc.handleFieldList(hack.String(data))
+		return cc.handleFieldList(cc.decodeClientString(data))
 	case mysql.ComStmtPrepare:
-		return cc.handleStmtPrepare(hack.String(data))
+		return cc.handleStmtPrepare(cc.decodeQuery(data))
 	case mysql.ComStmtExecute:
 		return cc.handleStmtExecute(data)
 	case mysql.ComStmtClose:
@@ -480,6 +518,10 @@ func (cc *clientConn) dispatch(data []byte) error {
 		return cc.handleStmtReset(data)
 	case mysql.ComSetOption:
 		return cc.handleSetOption(data)
+	case mysql.ComRegisterSlave:
+		return cc.handleRegisterSlave(data)
+	case mysql.ComBinlogDump:
+		return cc.handleBinlogDump(data)
 	default:
 		return mysql.NewErrf(mysql.ErrUnknown, "command %d not supported now", cmd)
 	}
@@ -509,6 +551,11 @@ func (cc *clientConn) writeOK() error {
 		data = append(data, dumpUint16(cc.ctx.Status())...)
 		data = append(data, dumpUint16(cc.ctx.WarningCount())...)
 	}
+	if msg := cc.ctx.LastMessage(); len(msg) > 0 {
+		// The info message is documented as string<EOF>, but MySQL sends it as string<lenenc> and
+		// the clients read it so.
+		data = append(data, dumpLengthEncodedString([]byte(msg), cc.alloc)...)
+	}
 
 	err := cc.writePacket(data)
 	if err != nil {
@@ -609,13 +656,14 @@ func insertDataWithCommit(prevData, curData []byte, loadDataInfo *executor.LoadD
 // handleLoadData does the additional work after processing the 'load data' query.
 // It sends client a file path, then reads the file content from client, inserts data into database.
 func (cc *clientConn) handleLoadData(loadDataInfo *executor.LoadDataInfo) error {
+	if loadDataInfo == nil {
+		return errors.New("load data info is empty")
+	}
+	defer loadDataInfo.CloseBulkLoad()
 	// If the server handles the load data request, the client has to set the ClientLocalFiles capability.
 	if cc.capability&mysql.ClientLocalFiles == 0 {
 		return errNotAllowedCommand
 	}
-	if loadDataInfo == nil {
-		return errors.New("load data info is empty")
-	}
 
 	err := cc.writeReq(loadDataInfo.Path)
 	if err != nil {
@@ -662,7 +710,17 @@ func (cc *clientConn) handleLoadData(loadDataInfo *executor.LoadDataInfo) error
 		}
 		return errors.Trace(err)
 	}
-	return errors.Trace(txn.Commit())
+	if err = loadDataInfo.CommitBulkLoad(); err != nil {
+		if err1 := txn.Rollback(); err1 != nil {
+			log.Errorf("load data rollback failed: %v", err1)
+		}
+		return errors.Trace(err)
+	}
+	if err = txn.Commit(); err != nil {
+		return errors.Trace(err)
+	}
+	loadDataInfo.SetMessage()
+	return nil
 }
 
 // handleQuery executes the sql query string and writes result set or result ok to the client.
@@ -701,6 +759,7 @@ func (cc *clientConn) handleFieldList(sql string) (err error) {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	cc.encodeColumns(columns)
 	data := make([]byte, 4, 1024)
 	for _, v := range columns {
 		data = data[0:4]
@@ -733,6 +792,10 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 		return errors.Trace(err)
 	}
 
+	// The non-binary string columns are converted to character_set_results.
+	encodeCols := cc.encodeColumns(columns)
+	resultsCs := cc.ctx.GetSessionVars().Systems[variable.CharacterSetResults]
+
 	columnLen := dumpLengthEncodedInt(uint64(len(columns)))
 	data := cc.alloc.AllocWithLen(4, 1024)
 	data = append(data, columnLen...)
@@ -760,13 +823,14 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 			break
 		}
 		data = data[0:4]
+		if encodeCols != nil {
+			row = encodeRowStrings(resultsCs, encodeCols, row)
+		}
 		if binary {
-			var rowData []byte
-			rowData, err = dumpRowValuesBinary(cc.alloc, columns, row)
+			data, err = dumpBinaryRow(cc.ctx.GetSessionVars().StmtCtx, data, columns, row)
 			if err != nil {
 				return errors.Trace(err)
 			}
-			data = append(data, rowData...)
 		} else {
 			for i, value := range row {
 				if value.IsNull() {
@@ -796,6 +860,148 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 	return errors.Trace(cc.flush())
 }
 
+// decodeClientString converts the data sent by client from character_set_client to utf8.
+func (cc *clientConn) decodeClientString(data []byte) string {
+	cs := cc.ctx.GetSessionVars().Systems[variable.CharacterSetClient]
+	if !charset.NeedConversion(cs) {
+		return hack.String(data)
+	}
+	str, _ := charset.DecodeString(cs, string(data))
+	return str
+}
+
+// decodeQuery converts the query sent by client from character_set_client to utf8, except the string
+// literals introduced by _binary, whose bytes are not characters of character_set_client.
+func (cc *clientConn) decodeQuery(data []byte) string {
+	cs := cc.ctx.GetSessionVars().Systems[variable.CharacterSetClient]
+	if !charset.NeedConversion(cs) {
+		return hack.String(data)
+	}
+	var buf bytes.Buffer
+	start := 0
+	for _, lit := range binaryLiterals(cs, data) {
+		str, _ := charset.DecodeString(cs, string(data[start:lit[0]]))
+		buf.WriteString(str)
+		buf.Write(data[lit[0]:lit[1]])
+		start = lit[1]
+	}
+	str, _ := charset.DecodeString(cs, string(data[start:]))
+	buf.WriteString(str)
+	return buf.String()
+}
+
+// binaryLiterals returns the start and end offsets of the string literals introduced by _binary in the
+// query. The query is scanned by the characters of the charset, so the trail bytes of the multi-byte
+// characters, which may be a backslash in gbk, don't escape the quotes.
+func binaryLiterals(cs string, sql []byte) [][2]int {
+	var lits [][2]int
+	for i := 0; i < len(sql); {
+		switch c := sql[i]; {
+		case c == '\'' || c == '"':
+			end := skipQuoted(cs, sql, i, true)
+			if hasBinaryIntroducer(sql[:i]) {
+				lits = append(lits, [2]int{i, end})
+			}
+			i = end
+		case c == '`':
+			i = skipQuoted(cs, sql, i, false)
+		case c == '#' || (c == '-' && bytes.HasPrefix(sql[i:], []byte("--")) &&
+			(i+2 == len(sql) || sql[i+2] == ' ' || sql[i+2] == '\t' || sql[i+2] == '\r' || sql[i+2] == '\n')):
+			if end := bytes.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(sql)
+			}
+		case c == '/' && bytes.HasPrefix(sql[i:], []byte("/*")) && !bytes.HasPrefix(sql[i:], []byte("/*!")):
+			// The executable comments are scanned as the query.
+			if end := bytes.Index(sql[i+2:], []byte("*/")); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(sql)
+			}
+		default:
+			i += charset.CharLen(cs, c)
+		}
+	}
+	return lits
+}
+
+// skipQuoted returns the end offset of the quoted string or identifier starting at start.
+func skipQuoted(cs string, sql []byte, start int, backslashEscapes bool) int {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); {
+		switch c := sql[i]; {
+		case c == '\\' && backslashEscapes && i+1 < len(sql):
+			i += 1 + charset.CharLen(cs, sql[i+1])
+		case c == quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i + 1
+		default:
+			i += charset.CharLen(cs, c)
+		}
+	}
+	return len(sql)
+}
+
+// hasBinaryIntroducer checks whether the query before a string literal ends with the _binary introducer.
+func hasBinaryIntroducer(sql []byte) bool {
+	const introducer = "_binary"
+	sql = bytes.TrimRight(sql, " \t\r\n")
+	if len(sql) < len(introducer) || !strings.EqualFold(string(sql[len(sql)-len(introducer):]), introducer) {
+		return false
+	}
+	if len(sql) == len(introducer) {
+		return true
+	}
+	// The introducer isn't the suffix of an identifier.
+	c := sql[len(sql)-len(introducer)-1]
+	return !(c == '_' || c == '$' || c >= utf8.RuneSelf || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)))
+}
+
+// encodeColumns converts the names of the columns from utf8 to character_set_results and sets the charset
+// of the non-binary columns to it. It returns which columns need their values converted, or nil if
+// character_set_results is utf8 or binary.
+func (cc *clientConn) encodeColumns(columns []*ColumnInfo) []bool {
+	cs := cc.ctx.GetSessionVars().Systems[variable.CharacterSetResults]
+	if !charset.NeedConversion(cs) {
+		return nil
+	}
+	encodeCols := make([]bool, len(columns))
+	for i, v := range columns {
+		v.Schema, _ = charset.EncodeString(cs, v.Schema)
+		v.Table, _ = charset.EncodeString(cs, v.Table)
+		v.OrgTable, _ = charset.EncodeString(cs, v.OrgTable)
+		v.Name, _ = charset.EncodeString(cs, v.Name)
+		v.OrgName, _ = charset.EncodeString(cs, v.OrgName)
+		if v.Charset != uint16(mysql.CharsetIDs[charset.CharsetBin]) {
+			encodeCols[i] = true
+			v.Charset = uint16(mysql.CharsetIDs[cs])
+		}
+	}
+	return encodeCols
+}
+
+// encodeRowStrings returns a copy of row with the string values of the columns in encodeCols converted
+// from utf8 to cs, the row itself may be shared with the executor and is left untouched.
+func encodeRowStrings(cs string, encodeCols []bool, row []types.Datum) []types.Datum {
+	encoded := make([]types.Datum, len(row))
+	copy(encoded, row)
+	for i := range encoded {
+		if !encodeCols[i] {
+			continue
+		}
+		switch encoded[i].Kind() {
+		case types.KindString, types.KindBytes:
+			str, _ := charset.EncodeString(cs, encoded[i].GetString())
+			encoded[i].SetString(str)
+		}
+	}
+	return encoded
+}
+
 func (cc *clientConn) writeMultiResultset(rss []ResultSet, binary bool) error {
 	for _, rs := range rss {
 		if err := cc.writeResultset(rs, binary, true); err != nil {
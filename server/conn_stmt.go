@@ -41,6 +41,8 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/hack"
 )
 
@@ -158,7 +160,8 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 			paramValues = data[pos+1:]
 		}
 
-		err = parseStmtArgs(args, stmt.BoundParams(), nullBitmaps, stmt.GetParamsType(), paramValues)
+		clientCs := cc.ctx.GetSessionVars().Systems[variable.CharacterSetClient]
+		err = parseStmtArgs(args, stmt.BoundParams(), nullBitmaps, stmt.GetParamsType(), paramValues, clientCs)
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -174,7 +177,9 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 	return errors.Trace(cc.writeResultset(rs, true, false))
 }
 
-func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTypes, paramValues []byte) (err error) {
+// parseStmtArgs parses the parameters of COM_STMT_EXECUTE, the non-binary string parameters are
+// converted from the client charset cs to utf8.
+func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTypes, paramValues []byte, cs string) (err error) {
 	pos := 0
 	var v []byte
 	var n int
@@ -296,11 +301,18 @@ func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTy
 				return
 			}
 
-			if !isNull {
-				args[i] = hack.String(v)
-			} else {
+			if isNull {
 				args[i] = nil
+				continue
+			}
+			switch tp {
+			case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString:
+				if charset.NeedConversion(cs) {
+					args[i], _ = charset.DecodeString(cs, string(v))
+					continue
+				}
 			}
+			args[i] = hack.String(v)
 			continue
 		default:
 			err = errUnknownFieldType.Gen("stmt unknown field type %d", tp)
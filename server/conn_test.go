@@ -16,6 +16,7 @@ package server
 import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
 )
 
 type ConnTestSuite struct{}
@@ -97,6 +98,60 @@ func (ts ConnTestSuite) TestIssue1768(c *C) {
 	c.Assert(len(p.Auth) > 0, IsTrue)
 }
 
+func (ts ConnTestSuite) TestParseStmtArgsCharset(c *C) {
+	c.Parallel()
+	args := make([]interface{}, 2)
+	boundParams := make([][]byte, 2)
+	paramTypes := []byte{mysql.TypeString, 0, mysql.TypeBlob, 0}
+	// Two length encoded "\xd6\xd0\xce\xc4" which is "中文" in gbk.
+	paramValues := []byte{4, 0xd6, 0xd0, 0xce, 0xc4, 4, 0xd6, 0xd0, 0xce, 0xc4}
+	err := parseStmtArgs(args, boundParams, []byte{0}, paramTypes, paramValues, "gbk")
+	c.Assert(err, IsNil)
+	c.Assert(args[0], Equals, "中文")
+	// The blob parameters are binary and kept as they are.
+	c.Assert(args[1], Equals, "\xd6\xd0\xce\xc4")
+
+	err = parseStmtArgs(args, boundParams, []byte{0}, paramTypes, paramValues, "utf8")
+	c.Assert(err, IsNil)
+	c.Assert(args[0], Equals, "\xd6\xd0\xce\xc4")
+}
+
+func (ts ConnTestSuite) TestEncodeRowStrings(c *C) {
+	c.Parallel()
+	row := types.MakeDatums("中文", "中文", 1)
+	encoded := encodeRowStrings("gbk", []bool{true, false, false}, row)
+	c.Assert(encoded[0].GetString(), Equals, "\xd6\xd0\xce\xc4")
+	c.Assert(encoded[1].GetString(), Equals, "中文")
+	c.Assert(encoded[2].GetInt64(), Equals, int64(1))
+	// The original row is not modified.
+	c.Assert(row[0].GetString(), Equals, "中文")
+}
+
+func (ts ConnTestSuite) TestBinaryLiterals(c *C) {
+	c.Parallel()
+	tests := []struct {
+		cs   string
+		sql  string
+		lits []string
+	}{
+		{"latin1", "select 'a', _binary'\x81\x8d', _BINARY \"\x8f\\\"\x90\"", []string{"'\x81\x8d'", "\"\x8f\\\"\x90\""}},
+		{"latin1", "select x_binary'a', `_binary`, '_binary''\x9d'", nil},
+		{"latin1", "select _binary'it''s'", []string{"'it''s'"}},
+		{"latin1", "select 1 -- don't\n, _binary'\x81' # don't\n", []string{"'\x81'"}},
+		{"latin1", "/* don't */ select /*! _binary'\x81' */", []string{"'\x81'"}},
+		// The trail byte of the gbk character is a backslash, it doesn't escape the quote.
+		{"gbk", "select '\x95\\', _binary'\xd6\x5c'", []string{"'\xd6\x5c'"}},
+		{"latin1", "select '\x95\\', _binary'\xd6'", nil},
+	}
+	for _, t := range tests {
+		var lits []string
+		for _, lit := range binaryLiterals(t.cs, []byte(t.sql)) {
+			lits = append(lits, t.sql[lit[0]:lit[1]])
+		}
+		c.Assert(lits, DeepEquals, t.lits, Commentf("%q", t.sql))
+	}
+}
+
 func mapIdentical(m1, m2 map[string]string) bool {
 	return mapBelong(m1, m2) && mapBelong(m2, m1)
 }
@@ -110,3 +165,40 @@ func mapBelong(m1, m2 map[string]string) bool {
 	}
 	return true
 }
+
+func (ts ConnTestSuite) TestReplicationPackets(c *C) {
+	c.Parallel()
+	data := []byte{
+		0x02, 0x00, 0x00, 0x00, // server id
+		0x04, 0x68, 0x6f, 0x73, 0x74, // host
+		0x04, 0x72, 0x6f, 0x6f, 0x74, // user
+		0x00,       // password
+		0xea, 0x0c, // port
+		0x00, 0x00, 0x00, 0x00, // replication rank
+		0x01, 0x00, 0x00, 0x00, // master id
+	}
+	slave, err := parseRegisterSlave(data)
+	c.Assert(err, IsNil)
+	c.Assert(slave.serverID, Equals, uint32(2))
+	c.Assert(slave.host, Equals, "host")
+	c.Assert(slave.user, Equals, "root")
+	c.Assert(slave.port, Equals, uint16(3306))
+	c.Assert(slave.masterID, Equals, uint32(1))
+	_, err = parseRegisterSlave(data[:10])
+	c.Assert(err, NotNil)
+
+	data = []byte{
+		0x04, 0x00, 0x00, 0x00, // binlog pos
+		0x01, 0x00, // flags
+		0x02, 0x00, 0x00, 0x00, // server id
+		0x61, 0x2e, 0x30, 0x31, // binlog file name
+	}
+	req, err := parseBinlogDump(data)
+	c.Assert(err, IsNil)
+	c.Assert(req.pos, Equals, uint32(4))
+	c.Assert(req.flags, Equals, binlogDumpNonBlock)
+	c.Assert(req.serverID, Equals, uint32(2))
+	c.Assert(req.fileName, Equals, "a.01")
+	_, err = parseBinlogDump(data[:6])
+	c.Assert(err, NotNil)
+}
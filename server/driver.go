@@ -16,6 +16,7 @@ package server
 import (
 	"fmt"
 
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -37,6 +38,9 @@ type QueryCtx interface {
 	// AffectedRows returns affected rows of last executed command.
 	AffectedRows() uint64
 
+	// LastMessage returns the info message of last executed command.
+	LastMessage() string
+
 	// Value returns the value associated with this context for key.
 	Value(key fmt.Stringer) interface{}
 
@@ -83,6 +87,9 @@ type QueryCtx interface {
 
 	// Cancel the execution of current transaction.
 	Cancel()
+
+	// GetSessionVars returns the session variables.
+	GetSessionVars() *variable.SessionVars
 }
 
 // PreparedStatement is the interface to use a prepared statement.
@@ -21,7 +21,10 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -129,6 +132,9 @@ func (qd *TiDBDriver) OpenCtx(connID uint64, capability uint32, collation uint8,
 	}
 	session.SetClientCapability(capability)
 	session.SetConnectionID(connID)
+	if err = setConnectionCharset(session.GetSessionVars(), collation); err != nil {
+		return nil, errors.Trace(err)
+	}
 	if dbname != "" {
 		_, err = session.Execute("use " + dbname)
 		if err != nil {
@@ -178,6 +184,11 @@ func (tc *TiDBContext) AffectedRows() uint64 {
 	return tc.session.AffectedRows()
 }
 
+// LastMessage implements QueryCtx LastMessage method.
+func (tc *TiDBContext) LastMessage() string {
+	return tc.session.LastMessage()
+}
+
 // CurrentDB implements QueryCtx CurrentDB method.
 func (tc *TiDBContext) CurrentDB() string {
 	return tc.currentDB
@@ -250,14 +261,14 @@ func (tc *TiDBContext) GetStatement(stmtID int) PreparedStatement {
 
 // Prepare implements QueryCtx Prepare method.
 func (tc *TiDBContext) Prepare(sql string) (statement PreparedStatement, columns, params []*ColumnInfo, err error) {
-	stmtID, paramCount, fields, err := tc.session.PrepareStmt(sql)
+	stmtID, paramTypes, fields, err := tc.session.PrepareStmt(sql)
 	if err != nil {
 		return
 	}
 	stmt := &TiDBStatement{
 		id:          stmtID,
-		numParams:   paramCount,
-		boundParams: make([][]byte, paramCount),
+		numParams:   len(paramTypes),
+		boundParams: make([][]byte, len(paramTypes)),
 		ctx:         tc,
 	}
 	statement = stmt
@@ -265,11 +276,9 @@ func (tc *TiDBContext) Prepare(sql string) (statement PreparedStatement, columns
 	for i := range fields {
 		columns[i] = convertColumnInfo(fields[i])
 	}
-	params = make([]*ColumnInfo, paramCount)
+	params = make([]*ColumnInfo, len(paramTypes))
 	for i := range params {
-		params[i] = &ColumnInfo{
-			Type: mysql.TypeBlob,
-		}
+		params[i] = convertParamInfo(paramTypes[i])
 	}
 	tc.stmts[int(stmtID)] = stmt
 	return
@@ -285,6 +294,26 @@ func (tc *TiDBContext) Cancel() {
 	tc.session.Cancel()
 }
 
+// GetSessionVars implements QueryCtx GetSessionVars method.
+func (tc *TiDBContext) GetSessionVars() *variable.SessionVars {
+	return tc.session.GetSessionVars()
+}
+
+// setConnectionCharset sets the connection charset variables according to the collation
+// sent by client in handshake, utf8 is used if the collation is not supported.
+func setConnectionCharset(vars *variable.SessionVars, collation uint8) error {
+	cs, co := charset.CharsetUTF8, charset.CollationUTF8
+	if c, err := charset.GetCollationByID(int(collation)); err == nil {
+		cs, co = c.CharsetName, c.Name
+	}
+	for _, name := range variable.SetNamesVariables {
+		if err := varsutil.SetSessionSystemVar(vars, name, types.NewStringDatum(cs)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(varsutil.SetSessionSystemVar(vars, variable.CollationConnection, types.NewStringDatum(co)))
+}
+
 type tidbResultSet struct {
 	recordSet ast.RecordSet
 }
@@ -328,7 +357,7 @@ func convertColumnInfo(fld *ast.ResultField) (ci *ColumnInfo) {
 	ci.Flag = uint16(fld.Column.Flag)
 	ci.Charset = uint16(mysql.CharsetIDs[fld.Column.Charset])
 	if fld.Column.Flen == types.UnspecifiedLength {
-		ci.ColumnLength = 0
+		ci.ColumnLength = temporalColumnLength(fld.Column.Tp, fld.Column.Decimal)
 	} else {
 		ci.ColumnLength = uint32(fld.Column.Flen)
 	}
@@ -346,3 +375,46 @@ func convertColumnInfo(fld *ast.ResultField) (ci *ColumnInfo) {
 	}
 	return
 }
+
+// convertParamInfo converts the inferred type of a parameter to its metadata sent to the client,
+// the parameter whose type can't be inferred is described as a blob.
+func convertParamInfo(tp *types.FieldType) *ColumnInfo {
+	if tp == nil {
+		return &ColumnInfo{Type: mysql.TypeBlob}
+	}
+	ci := &ColumnInfo{
+		Type:    tp.Tp,
+		Flag:    uint16(tp.Flag & mysql.UnsignedFlag),
+		Charset: uint16(mysql.CharsetIDs[tp.Charset]),
+	}
+	if tp.Flen != types.UnspecifiedLength {
+		ci.ColumnLength = uint32(tp.Flen)
+	}
+	if tp.Decimal != types.UnspecifiedLength {
+		ci.Decimal = uint8(tp.Decimal)
+	}
+	if ci.Type == mysql.TypeVarchar {
+		ci.Type = mysql.TypeVarString
+	}
+	return ci
+}
+
+// temporalColumnLength returns the display width of a temporal column,
+// including the fractional seconds part, or 0 for other types.
+func temporalColumnLength(tp byte, fsp int) uint32 {
+	var length uint32
+	switch tp {
+	case mysql.TypeDate:
+		return 10
+	case mysql.TypeDatetime, mysql.TypeTimestamp:
+		length = 19
+	case mysql.TypeDuration:
+		length = 10
+	default:
+		return 0
+	}
+	if fsp > 0 {
+		length += uint32(fsp) + 1
+	}
+	return length
+}
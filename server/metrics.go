@@ -68,6 +68,7 @@ func init() {
 	prometheus.MustRegister(queryHistogram)
 	prometheus.MustRegister(queryCounter)
 	prometheus.MustRegister(connGauge)
+	prometheus.MustRegister(executeErrorCounter)
 	prometheus.MustRegister(criticalErrorCounter)
 }
 
@@ -46,6 +46,9 @@ import (
 const (
 	defaultReaderSize = 16 * 1024
 	defaultWriterSize = 16 * 1024
+
+	// defaultMaxAllowedPacket is the default value of max_allowed_packet.
+	defaultMaxAllowedPacket uint64 = 64 * 1024 * 1024
 )
 
 // packetIO is a helper to read and write data in packet format.
@@ -54,12 +57,16 @@ type packetIO struct {
 	wb *bufio.Writer
 
 	sequence uint8
+	// maxAllowedPacket is the max payload length of a packet, a packet larger than
+	// mysql.MaxPayloadLen is split into a sequence of packets.
+	maxAllowedPacket uint64
 }
 
 func newPacketIO(conn net.Conn) *packetIO {
 	p := &packetIO{
-		rb: bufio.NewReaderSize(conn, defaultReaderSize),
-		wb: bufio.NewWriterSize(conn, defaultWriterSize),
+		rb:               bufio.NewReaderSize(conn, defaultReaderSize),
+		wb:               bufio.NewWriterSize(conn, defaultWriterSize),
+		maxAllowedPacket: defaultMaxAllowedPacket,
 	}
 
 	return p
@@ -94,6 +101,9 @@ func (p *packetIO) readPacket() ([]byte, error) {
 		return nil, errors.Trace(err)
 	}
 
+	if uint64(len(data)) > p.maxAllowedPacket {
+		return nil, errors.Trace(errNetPacketTooLarge)
+	}
 	if len(data) < mysql.MaxPayloadLen {
 		return data, nil
 	}
@@ -105,6 +115,9 @@ func (p *packetIO) readPacket() ([]byte, error) {
 			return nil, errors.Trace(err)
 		}
 
+		if uint64(len(data)+len(buf)) > p.maxAllowedPacket {
+			return nil, errors.Trace(errNetPacketTooLarge)
+		}
 		data = append(data, buf...)
 
 		if len(buf) < mysql.MaxPayloadLen {
@@ -118,6 +131,9 @@ func (p *packetIO) readPacket() ([]byte, error) {
 // writePacket writes data that already have header
 func (p *packetIO) writePacket(data []byte) error {
 	length := len(data) - 4
+	if uint64(length) > p.maxAllowedPacket {
+		return errors.Trace(errNetPacketTooLarge)
+	}
 
 	for length >= mysql.MaxPayloadLen {
 		data[0] = 0xff
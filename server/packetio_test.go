@@ -0,0 +1,82 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+)
+
+type PacketIOTestSuite struct{}
+
+var _ = Suite(PacketIOTestSuite{})
+
+func newTestPacketIO(buf *bytes.Buffer, maxAllowedPacket uint64) *packetIO {
+	return &packetIO{
+		rb:               bufio.NewReader(buf),
+		wb:               bufio.NewWriter(buf),
+		maxAllowedPacket: maxAllowedPacket,
+	}
+}
+
+func (ts PacketIOTestSuite) TestLargePacket(c *C) {
+	for _, length := range []int{0, 100, mysql.MaxPayloadLen, mysql.MaxPayloadLen + 1, 2*mysql.MaxPayloadLen + 100} {
+		payload := make([]byte, length)
+		for i := range payload {
+			payload[i] = byte(i)
+		}
+		var buf bytes.Buffer
+		writer := newTestPacketIO(&buf, defaultMaxAllowedPacket)
+		data := append(make([]byte, 4, 4+length), payload...)
+		c.Assert(writer.writePacket(data), IsNil)
+		c.Assert(writer.flush(), IsNil)
+		// Every packet has a 4 bytes header, and an empty packet ends the sequence if the length
+		// is a multiple of mysql.MaxPayloadLen.
+		c.Assert(buf.Len(), Equals, length+4*(length/mysql.MaxPayloadLen+1))
+		c.Assert(writer.sequence, Equals, uint8(length/mysql.MaxPayloadLen+1))
+
+		reader := newTestPacketIO(&buf, defaultMaxAllowedPacket)
+		read, err := reader.readPacket()
+		c.Assert(err, IsNil)
+		c.Assert(bytes.Equal(read, payload), IsTrue)
+		c.Assert(reader.sequence, Equals, writer.sequence)
+	}
+}
+
+func (ts PacketIOTestSuite) TestMaxAllowedPacket(c *C) {
+	var buf bytes.Buffer
+	pkt := newTestPacketIO(&buf, 1024)
+	err := pkt.writePacket(make([]byte, 4+1025))
+	c.Assert(terror.ErrorEqual(err, errNetPacketTooLarge), IsTrue)
+	c.Assert(buf.Len(), Equals, 0)
+	c.Assert(pkt.writePacket(make([]byte, 4+1024)), IsNil)
+	c.Assert(pkt.flush(), IsNil)
+
+	pkt = newTestPacketIO(&buf, 1023)
+	_, err = pkt.readPacket()
+	c.Assert(terror.ErrorEqual(err, errNetPacketTooLarge), IsTrue)
+
+	// The limit is checked on the total length of a sequence of packets.
+	buf.Reset()
+	pkt = newTestPacketIO(&buf, uint64(mysql.MaxPayloadLen+1))
+	c.Assert(pkt.writePacket(make([]byte, 4+mysql.MaxPayloadLen+1)), IsNil)
+	c.Assert(pkt.flush(), IsNil)
+	pkt = newTestPacketIO(&buf, uint64(mysql.MaxPayloadLen))
+	_, err = pkt.readPacket()
+	c.Assert(terror.ErrorEqual(err, errNetPacketTooLarge), IsTrue)
+}
@@ -297,9 +297,9 @@ func (rh RegionHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// 		`for id in [frameRange.firstTableID,frameRange.endTableID]`
 	// on [frameRange.firstTableID,frameRange.endTableID] is small enough.
 	for _, db := range tool.infoSchema.AllSchemas() {
-		for _, table := range db.Tables {
-			start, end := frameRange.getIndexRangeForTable(table.ID)
-			regionDetail.addTableInRange(db.Name.String(), table, start, end)
+		for _, table := range tool.infoSchema.SchemaTables(db.Name) {
+			start, end := frameRange.getIndexRangeForTable(table.Meta().ID)
+			regionDetail.addTableInRange(db.Name.String(), table.Meta(), start, end)
 		}
 	}
 	rh.writeData(w, regionDetail)
@@ -0,0 +1,215 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/replication"
+	"github.com/pingcap/tidb/util/types"
+)
+
+const (
+	// binlogDumpNonBlock is the flag of COM_BINLOG_DUMP which asks the master to send EOF
+	// instead of blocking when there is no more binlog event.
+	binlogDumpNonBlock uint16 = 0x01
+
+	defaultHeartbeatPeriod = 30 * time.Second
+	binlogPollInterval     = 100 * time.Millisecond
+)
+
+// slaveInfo is the information registered by a replication slave with COM_REGISTER_SLAVE.
+type slaveInfo struct {
+	serverID uint32
+	host     string
+	user     string
+	port     uint16
+	masterID uint32
+}
+
+// binlogDumpRequest is the request of COM_BINLOG_DUMP.
+type binlogDumpRequest struct {
+	pos      uint32
+	flags    uint16
+	serverID uint32
+	fileName string
+}
+
+// readLengthPrefixedString reads a string prefixed with 1 byte length.
+func readLengthPrefixedString(data []byte) (string, []byte, error) {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return "", nil, mysql.ErrMalformPacket
+	}
+	n := int(data[0])
+	return string(data[1 : 1+n]), data[1+n:], nil
+}
+
+// parseRegisterSlave parses the payload of COM_REGISTER_SLAVE.
+// See https://dev.mysql.com/doc/internals/en/com-register-slave.html
+func parseRegisterSlave(data []byte) (*slaveInfo, error) {
+	if len(data) < 4 {
+		return nil, mysql.ErrMalformPacket
+	}
+	slave := &slaveInfo{serverID: binary.LittleEndian.Uint32(data)}
+	data = data[4:]
+	var err error
+	if slave.host, data, err = readLengthPrefixedString(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if slave.user, data, err = readLengthPrefixedString(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Skip the password.
+	if _, data, err = readLengthPrefixedString(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// port (2 bytes), replication rank (4 bytes) and master id (4 bytes).
+	if len(data) < 10 {
+		return nil, mysql.ErrMalformPacket
+	}
+	slave.port = binary.LittleEndian.Uint16(data)
+	slave.masterID = binary.LittleEndian.Uint32(data[6:])
+	return slave, nil
+}
+
+// parseBinlogDump parses the payload of COM_BINLOG_DUMP.
+// See https://dev.mysql.com/doc/internals/en/com-binlog-dump.html
+func parseBinlogDump(data []byte) (*binlogDumpRequest, error) {
+	if len(data) < 10 {
+		return nil, mysql.ErrMalformPacket
+	}
+	return &binlogDumpRequest{
+		pos:      binary.LittleEndian.Uint32(data),
+		flags:    binary.LittleEndian.Uint16(data[4:]),
+		serverID: binary.LittleEndian.Uint32(data[6:]),
+		fileName: string(data[10:]),
+	}, nil
+}
+
+// handleRegisterSlave registers the connection as a replication slave.
+func (cc *clientConn) handleRegisterSlave(data []byte) error {
+	slave, err := parseRegisterSlave(data)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cc.server.rwlock.Lock()
+	cc.server.slaves[cc.connectionID] = slave
+	cc.server.rwlock.Unlock()
+	log.Infof("[%d] register slave, server id %d, host %s:%d", cc.connectionID, slave.serverID, slave.host, slave.port)
+	return cc.writeOK()
+}
+
+// handleBinlogDump sends the row events of the transactions written to the binlog file to the
+// slave, see replication.Dumper for the binlog files and positions. It keeps sending the new
+// events until the connection is killed, unless the slave asks not to block.
+func (cc *clientConn) handleBinlogDump(data []byte) error {
+	req, err := parseBinlogDump(data)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if cc.server.cfg.BinlogFile == "" {
+		return mysql.NewErr(mysql.ErrMasterFatalErrorReadingBinlog, "binlog is not enabled")
+	}
+	log.Infof("[%d] binlog dump from %s:%d, slave server id %d", cc.connectionID, req.fileName, req.pos, req.serverID)
+
+	store := cc.server.driver.(*TiDBDriver).store
+	pos := replication.Position{Name: req.fileName, Pos: req.pos}
+	dumper, err := replication.NewDumper(store, cc.server.cfg.BinlogFile, pos, cc.binlogChecksum())
+	if err != nil {
+		return mysql.NewErr(mysql.ErrMasterFatalErrorReadingBinlog, err.Error())
+	}
+	defer dumper.Close()
+
+	heartbeatPeriod := cc.heartbeatPeriod()
+	lastSent := time.Now()
+	for !cc.killed {
+		cc.alloc.Reset()
+		event, err := dumper.Next()
+		if err != nil {
+			log.Errorf("[%d] binlog dump error %v", cc.connectionID, errors.ErrorStack(err))
+			return mysql.NewErr(mysql.ErrMasterFatalErrorReadingBinlog, err.Error())
+		}
+		if event != nil {
+			if err = cc.writeBinlogEvent(event); err != nil {
+				return errors.Trace(err)
+			}
+			lastSent = time.Now()
+			continue
+		}
+		if err = cc.flush(); err != nil {
+			return errors.Trace(err)
+		}
+		if req.flags&binlogDumpNonBlock > 0 {
+			return errors.Trace(cc.writeEOF(false))
+		}
+		if heartbeatPeriod > 0 && time.Since(lastSent) >= heartbeatPeriod {
+			if err = cc.writeBinlogEvent(dumper.HeartbeatEvent()); err != nil {
+				return errors.Trace(err)
+			}
+			if err = cc.flush(); err != nil {
+				return errors.Trace(err)
+			}
+			lastSent = time.Now()
+		}
+		time.Sleep(binlogPollInterval)
+	}
+	return nil
+}
+
+// binlogChecksum returns whether the slave accepts the events with checksums. A slave supporting
+// checksums sets @master_binlog_checksum to the algorithm before the dump.
+func (cc *clientConn) binlogChecksum() bool {
+	val, ok := cc.userVar("master_binlog_checksum")
+	if !ok {
+		return false
+	}
+	s, err := val.ToString()
+	return err == nil && strings.EqualFold(s, "CRC32")
+}
+
+// heartbeatPeriod returns the heartbeat period set by the slave in @master_heartbeat_period,
+// in nanoseconds.
+func (cc *clientConn) heartbeatPeriod() time.Duration {
+	val, ok := cc.userVar("master_heartbeat_period")
+	if !ok {
+		return defaultHeartbeatPeriod
+	}
+	period, err := val.ToInt64(cc.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return defaultHeartbeatPeriod
+	}
+	return time.Duration(period)
+}
+
+func (cc *clientConn) userVar(name string) (types.Datum, bool) {
+	val, ok := cc.ctx.GetSessionVars().Users[name]
+	if !ok {
+		return types.Datum{}, false
+	}
+	d, ok := val.(types.Datum)
+	return d, ok && !d.IsNull()
+}
+
+// writeBinlogEvent writes a binlog event in an OK packet, it doesn't flush the stream.
+func (cc *clientConn) writeBinlogEvent(event []byte) error {
+	data := cc.alloc.AllocWithLen(4, 1+len(event))
+	data = append(data, mysql.OKHeader)
+	data = append(data, event...)
+	return errors.Trace(cc.writePacket(data))
+}
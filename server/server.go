@@ -56,6 +56,8 @@ var (
 	errInvalidType       = terror.ClassServer.New(codeInvalidType, "invalid type")
 	errNotAllowedCommand = terror.ClassServer.New(codeNotAllowedCommand,
 		"the used command is not allowed with this TiDB version")
+	errNetPacketTooLarge = terror.ClassServer.New(codeNetPacketTooLarge,
+		"Got a packet bigger than 'max_allowed_packet' bytes")
 )
 
 // Server is the MySQL protocol server
@@ -66,6 +68,7 @@ type Server struct {
 	rwlock            *sync.RWMutex
 	concurrentLimiter *TokenLimiter
 	clients           map[uint32]*clientConn
+	slaves            map[uint32]*slaveInfo // replication slaves registered by connection id.
 
 	// When a critical error occurred, we don't want to exit the process, because there may be
 	// a supervisor automatically restart it, then new client connection will be created, but we can't server it.
@@ -133,6 +136,7 @@ func NewServer(cfg *Config, driver IDriver) (*Server, error) {
 		concurrentLimiter: NewTokenLimiter(tokenLimit),
 		rwlock:            &sync.RWMutex{},
 		clients:           make(map[uint32]*clientConn),
+		slaves:            make(map[uint32]*slaveInfo),
 		stopListenerCh:    make(chan struct{}, 1),
 	}
 
@@ -160,6 +164,7 @@ func (s *Server) Run() error {
 	if s.cfg.ReportStatus {
 		s.startStatusHTTP()
 	}
+	go s.checkTxnLoop()
 	for {
 		conn, err := s.listener.Accept()
 		if err != nil {
@@ -264,11 +269,13 @@ const (
 	codeInvalidType       = 4
 
 	codeNotAllowedCommand = 1148
+	codeNetPacketTooLarge = 1153
 )
 
 func init() {
 	serverMySQLErrCodes := map[terror.ErrCode]uint16{
 		codeNotAllowedCommand: mysql.ErrNotAllowedCommand,
+		codeNetPacketTooLarge: mysql.ErrNetPacketTooLarge,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassServer] = serverMySQLErrCodes
 }
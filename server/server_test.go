@@ -32,6 +32,7 @@ import (
 	tmysql "github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/printer"
+	goctx "golang.org/x/net/context"
 )
 
 func TestT(t *testing.T) {
@@ -222,6 +223,19 @@ func runTestSpecialType(t *C) {
 		t.Assert(outA, Equals, 1.4)
 		t.Assert(outB, Equals, "2012-12-21 12:12:12")
 		t.Assert(outC, Equals, "04:23:34")
+		rows.Close()
+
+		// The kinds of the values don't match the decimal column types.
+		dbt.mustExec("create table test_kind (a int, b float)")
+		dbt.mustExec("insert test_kind values (1, 1.5)")
+		rows = dbt.mustQuery("select case when a > ? then a else 0.5 end, avg(b) from test_kind", 0)
+		t.Assert(rows.Next(), IsTrue)
+		var outD, outE string
+		err = rows.Scan(&outD, &outE)
+		t.Assert(err, IsNil)
+		t.Assert(outD, Equals, "1")
+		t.Assert(outE, Equals, "1.5000")
+		rows.Close()
 	})
 }
 
@@ -283,7 +297,7 @@ xxx row5_col1	- 	row5_col3`)
 		err = rows.Scan(&a, &bb, &cc)
 		dbt.Check(err, IsNil)
 		dbt.Check(a, DeepEquals, "")
-		dbt.Check(bb.String, DeepEquals, "")
+		dbt.Check(bb.String, DeepEquals, "default value")
 		dbt.Check(cc, DeepEquals, 1)
 		dbt.Check(rows.Next(), IsTrue, Commentf("unexpected data"))
 		rows.Scan(&a, &b, &cc)
@@ -403,6 +417,97 @@ func runTestConcurrentUpdate(c *C) {
 	})
 }
 
+func runTestCharset(c *C) {
+	runTests(c, dsn+"&collation=gbk_chinese_ci", func(dbt *DBTest) {
+		dbt.mustExec("create table test (a varchar(10) charset utf8, b varbinary(10))")
+		// The query text and the results are encoded in gbk.
+		dbt.mustExec("insert test values ('\xd6\xd0\xce\xc4', 'abc')")
+		rows := dbt.mustQuery("select a, b, length(a) from test")
+		c.Assert(rows.Next(), IsTrue)
+		var a, b []byte
+		var length int
+		err := rows.Scan(&a, &b, &length)
+		c.Assert(err, IsNil)
+		c.Assert(string(a), Equals, "\xd6\xd0\xce\xc4")
+		c.Assert(string(b), Equals, "abc")
+		c.Assert(length, Equals, 6)
+		rows.Close()
+		// The string parameters of the binary protocol are encoded in gbk too.
+		dbt.mustExec("insert test values (?, 'def')", "\xce\xc4\xd6\xd0")
+		rows = dbt.mustQuery("select b, length(a) from test where a = ?", "\xce\xc4\xd6\xd0")
+		c.Assert(rows.Next(), IsTrue)
+		err = rows.Scan(&b, &length)
+		c.Assert(err, IsNil)
+		c.Assert(string(b), Equals, "def")
+		c.Assert(length, Equals, 6)
+		c.Assert(rows.Next(), IsFalse)
+		rows.Close()
+		dbt.mustExec("set names utf8")
+		rows = dbt.mustQuery("select a from test order by b")
+		c.Assert(rows.Next(), IsTrue)
+		err = rows.Scan(&a)
+		c.Assert(err, IsNil)
+		c.Assert(string(a), Equals, "中文")
+		c.Assert(rows.Next(), IsTrue)
+		err = rows.Scan(&a)
+		c.Assert(err, IsNil)
+		c.Assert(string(a), Equals, "文中")
+		rows.Close()
+	})
+}
+
+func runTestMaxAllowedPacket(c *C) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec("create table test (a int, b longtext)")
+		dbt.mustExec("insert test values (1, 'a'), (2, repeat('b', 200000))")
+		dbt.mustExec("set global max_allowed_packet = 100000")
+		defer dbt.mustExec("set global max_allowed_packet = 67108864")
+
+		// The new connection loads the global max_allowed_packet.
+		db, err := sql.Open("mysql", dsn)
+		c.Assert(err, IsNil)
+		defer db.Close()
+		conn, err := db.Conn(goctx.Background())
+		c.Assert(err, IsNil)
+		defer conn.Close()
+		rows, err := conn.QueryContext(goctx.Background(), "select b from test order by a")
+		c.Assert(err, IsNil)
+		c.Assert(rows.Next(), IsTrue)
+		var b string
+		c.Assert(rows.Scan(&b), IsNil)
+		c.Assert(b, Equals, "a")
+		// The row is too large, the error follows the written rows, then the connection is closed.
+		c.Assert(rows.Next(), IsFalse)
+		err = rows.Err()
+		c.Assert(err, NotNil)
+		c.Assert(err.(*mysql.MySQLError).Number, Equals, uint16(tmysql.ErrNetPacketTooLarge))
+		rows.Close()
+		_, err = conn.ExecContext(goctx.Background(), "do 1")
+		c.Assert(err, NotNil)
+		_, ok := err.(*mysql.MySQLError)
+		c.Assert(ok, IsFalse, Commentf("err %v", err))
+	})
+}
+
+func runTestBinaryLiterals(c *C) {
+	runTests(c, dsn+"&charset=latin1", func(dbt *DBTest) {
+		dbt.mustExec("create table test (a varchar(10) charset utf8, b varbinary(10))")
+		// The bytes undefined in cp1252 round-trip as latin1, and the _binary literal is stored as it is.
+		dbt.mustExec("insert test values ('\x81\x8d\x8f\x90\x9d', _binary'\x81\x8d\x8f\x90\x9d')")
+		rows := dbt.mustQuery("select a, b, hex(a), hex(b), x'818d8f909d' from test")
+		c.Assert(rows.Next(), IsTrue)
+		var a, b, hexA, hexB, x []byte
+		err := rows.Scan(&a, &b, &hexA, &hexB, &x)
+		c.Assert(err, IsNil)
+		c.Assert(string(a), Equals, "\x81\x8d\x8f\x90\x9d")
+		c.Assert(string(b), Equals, "\x81\x8d\x8f\x90\x9d")
+		c.Assert(string(hexA), Equals, "C281C28DC28FC290C29D")
+		c.Assert(string(hexB), Equals, "818D8F909D")
+		c.Assert(string(x), Equals, "\x81\x8d\x8f\x90\x9d")
+		rows.Close()
+	})
+}
+
 func runTestErrorCode(c *C) {
 	runTests(c, dsn, func(dbt *DBTest) {
 		dbt.mustExec("create table test (c int PRIMARY KEY);")
@@ -558,6 +663,7 @@ func runTestMultiStatements(c *C) {
 func runTestStmtCount(t *C) {
 	runTests(t, dsn, func(dbt *DBTest) {
 		originStmtCnt := getStmtCnt(string(getMetrics(t)))
+		originDurationCnt := getStmtDurationCnt(string(getMetrics(t)))
 
 		dbt.mustExec("create table test (a int)")
 
@@ -586,6 +692,10 @@ func runTestStmtCount(t *C) {
 		t.Assert(currentStmtCnt[updateLabel], Equals, originStmtCnt[updateLabel]+2)
 		selectLabel := "SelectTableFull"
 		t.Assert(currentStmtCnt[selectLabel], Equals, originStmtCnt[selectLabel]+2)
+
+		currentDurationCnt := getStmtDurationCnt(string(getMetrics(t)))
+		t.Assert(currentDurationCnt[executor.Insert], Equals, originDurationCnt[executor.Insert]+5)
+		t.Assert(currentDurationCnt[selectLabel], Equals, originDurationCnt[selectLabel]+2)
 	})
 }
 
@@ -598,6 +708,17 @@ func getMetrics(t *C) []byte {
 	return content
 }
 
+func getStmtDurationCnt(content string) (stmtCnt map[string]int) {
+	stmtCnt = make(map[string]int)
+	r, _ := regexp.Compile("tidb_executor_statement_duration_seconds_count{type=\"([A-Z|a-z|-]+)\"} (\\d+)")
+	matchResult := r.FindAllStringSubmatch(content, -1)
+	for _, v := range matchResult {
+		cnt, _ := strconv.Atoi(v[2])
+		stmtCnt[v[1]] = cnt
+	}
+	return stmtCnt
+}
+
 func getStmtCnt(content string) (stmtCnt map[string]int) {
 	stmtCnt = make(map[string]int)
 	r, _ := regexp.Compile("tidb_executor_statement_node_total{type=\"([A-Z|a-z|-]+)\"} (\\d+)")
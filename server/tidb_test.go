@@ -15,11 +15,16 @@
 package server
 
 import (
+	"bufio"
+	"bytes"
+	"database/sql"
 	"time"
 
 	"github.com/ngaut/log"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/arena"
 )
 
 type TidbTestSuite struct {
@@ -66,6 +71,36 @@ func (ts *TidbTestSuite) TestRegression(c *C) {
 	}
 }
 
+func (ts *TidbTestSuite) TestFieldListCharset(c *C) {
+	qctx, err := ts.tidbdrv.OpenCtx(uint64(0), 0, mysql.DefaultCollationID, "")
+	c.Assert(err, IsNil)
+	defer qctx.Close()
+	_, err = qctx.Execute("create database field_list_charset")
+	c.Assert(err, IsNil)
+	_, err = qctx.Execute("use field_list_charset")
+	c.Assert(err, IsNil)
+	_, err = qctx.Execute("create table `中文` (`文` varchar(10), b varbinary(10))")
+	c.Assert(err, IsNil)
+	_, err = qctx.Execute("set names gbk")
+	c.Assert(err, IsNil)
+
+	var buf bytes.Buffer
+	cc := &clientConn{
+		server: ts.server,
+		ctx:    qctx,
+		alloc:  arena.NewAllocator(1024),
+		pkt:    &packetIO{wb: bufio.NewWriter(&buf), maxAllowedPacket: defaultMaxAllowedPacket},
+	}
+	// The table name is sent in gbk, and the column names are returned in gbk.
+	err = cc.dispatch([]byte("\x04\xd6\xd0\xce\xc4\x00"))
+	c.Assert(err, IsNil)
+	// The charset of the non-binary columns is gbk.
+	gbkID := dumpUint16(uint16(mysql.CharsetIDs["gbk"]))
+	c.Assert(bytes.Contains(buf.Bytes(), append([]byte("\x02\xce\xc4\x02\xce\xc4\x0c"), gbkID...)), IsTrue)
+	binID := dumpUint16(uint16(mysql.CharsetIDs["binary"]))
+	c.Assert(bytes.Contains(buf.Bytes(), append([]byte("\x01b\x01b\x0c"), binID...)), IsTrue)
+}
+
 func (ts *TidbTestSuite) TestUint64(c *C) {
 	runTestPrepareResultFieldType(c)
 }
@@ -75,6 +110,10 @@ func (ts *TidbTestSuite) TestSpecialType(c *C) {
 	runTestSpecialType(c)
 }
 
+func (ts *TidbTestSuite) TestCharset(c *C) {
+	runTestCharset(c)
+}
+
 func (ts *TidbTestSuite) TestPreparedString(c *C) {
 	c.Parallel()
 	runTestPreparedString(c)
@@ -88,6 +127,14 @@ func (ts *TidbTestSuite) TestConcurrentUpdate(c *C) {
 	runTestConcurrentUpdate(c)
 }
 
+func (ts *TidbTestSuite) TestBinaryLiterals(c *C) {
+	runTestBinaryLiterals(c)
+}
+
+func (ts *TidbTestSuite) TestMaxAllowedPacket(c *C) {
+	runTestMaxAllowedPacket(c)
+}
+
 func (ts *TidbTestSuite) TestErrorCode(c *C) {
 	runTestErrorCode(c)
 }
@@ -131,3 +178,35 @@ func (ts *TidbTestSuite) TestSocket(c *C) {
 	dsn = tcpDsn
 	server.Close()
 }
+
+func (ts *TidbTestSuite) TestTxnChecker(c *C) {
+	cfg := &Config{
+		Addr:            ":4002",
+		LogLevel:        "debug",
+		IdleTxnKillTime: time.Minute,
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	defer server.Close()
+	time.Sleep(time.Millisecond * 100)
+
+	db, err := sql.Open("mysql", "root@tcp(localhost:4002)/test?strict=true")
+	c.Assert(err, IsNil)
+	defer db.Close()
+	txn, err := db.Begin()
+	c.Assert(err, IsNil)
+	var connID uint64
+	c.Assert(txn.QueryRow("select connection_id()").Scan(&connID), IsNil)
+	var txnStartTS, idleTime sql.NullInt64
+	err = db.QueryRow("select txn_start_ts, idle_time from information_schema.processlist where id = ?", connID).Scan(&txnStartTS, &idleTime)
+	c.Assert(err, IsNil)
+	c.Assert(txnStartTS.Valid, IsTrue)
+	c.Assert(idleTime.Valid, IsTrue)
+
+	// The transaction isn't idle long enough.
+	server.checkTxns(time.Now())
+	c.Assert(txn.QueryRow("select connection_id()").Scan(&connID), IsNil)
+	server.checkTxns(time.Now().Add(time.Hour))
+	c.Assert(txn.QueryRow("select connection_id()").Scan(&connID), NotNil)
+}
@@ -0,0 +1,72 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"time"
+
+	"github.com/ngaut/log"
+)
+
+// txnCheckInterval is the interval of checking the transactions of the connections.
+const txnCheckInterval = 10 * time.Second
+
+// checkTxnLoop checks the transactions of the connections periodically. A long transaction reads the
+// snapshot of its start ts, the old versions it needs are removed once the GC safe point passes the
+// start ts, so it's better to find it out before it fails, or kill it to release its locks.
+func (s *Server) checkTxnLoop() {
+	if s.cfg.TxnWarnTime <= 0 && s.cfg.TxnKillTime <= 0 && s.cfg.IdleTxnKillTime <= 0 {
+		return
+	}
+	ticker := time.NewTicker(txnCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.checkTxns(time.Now())
+	}
+}
+
+// checkTxns warns the transactions running longer than TxnWarnTime, and kills the connections whose
+// transactions run longer than TxnKillTime or stay idle longer than IdleTxnKillTime.
+func (s *Server) checkTxns(now time.Time) {
+	var killed []*clientConn
+	s.rwlock.RLock()
+	for _, cc := range s.clients {
+		pi := cc.ctx.ShowProcess()
+		if pi.TxnStartTS == 0 {
+			continue
+		}
+		txnTime := pi.TxnDuration(now)
+		idleTime := pi.IdleInTxnDuration(now)
+		switch {
+		case s.cfg.TxnKillTime > 0 && txnTime > s.cfg.TxnKillTime:
+			log.Warnf("[%d] kill the connection, transaction %d has been running for %v", cc.connectionID, pi.TxnStartTS, txnTime)
+			killed = append(killed, cc)
+		case s.cfg.IdleTxnKillTime > 0 && idleTime > s.cfg.IdleTxnKillTime:
+			log.Warnf("[%d] kill the connection, transaction %d has been idle for %v", cc.connectionID, pi.TxnStartTS, idleTime)
+			killed = append(killed, cc)
+		case s.cfg.TxnWarnTime > 0 && txnTime > s.cfg.TxnWarnTime && cc.warnedTxn != pi.TxnStartTS:
+			// Warn once for each transaction.
+			cc.warnedTxn = pi.TxnStartTS
+			log.Warnf("[%d] transaction %d has been running for %v, idle for %v", cc.connectionID, pi.TxnStartTS, txnTime, idleTime)
+		}
+	}
+	s.rwlock.RUnlock()
+
+	for _, cc := range killed {
+		s.Kill(uint64(cc.connectionID), false)
+		// The idle connection is blocked in reading the next command, closing the network connection
+		// wakes it up, then the connection is closed and its transaction is rolled back.
+		cc.conn.Close()
+	}
+}
@@ -36,6 +36,7 @@ package server
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 	"math"
 	"strconv"
@@ -43,6 +44,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/arena"
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/types"
@@ -176,7 +178,7 @@ func dumpBinaryTime(dur time.Duration) (data []byte) {
 	}
 	days := dur / (24 * time.Hour)
 	dur -= days * 24 * time.Hour
-	data[2] = byte(days)
+	binary.LittleEndian.PutUint32(data[2:6], uint32(days))
 	hours := dur / time.Hour
 	dur -= hours * time.Hour
 	data[6] = byte(hours)
@@ -209,15 +211,15 @@ func dumpBinaryDateTime(t types.Time, loc *time.Location) (data []byte, err erro
 		year, mon, day = 1, int(time.January), 1
 	}
 	switch t.Type {
-	case mysql.TypeTimestamp, mysql.TypeDatetime:
-		data = append(data, 11)
-		data = append(data, dumpUint16(uint16(year))...)
-		data = append(data, byte(mon), byte(day), byte(t.Time.Hour()), byte(t.Time.Minute()), byte(t.Time.Second()))
-		data = append(data, dumpUint32(uint32(t.Time.Microsecond()))...)
 	case mysql.TypeDate, mysql.TypeNewDate:
 		data = append(data, 4)
 		data = append(data, dumpUint16(uint16(year))...) //year
 		data = append(data, byte(mon), byte(day))
+	default:
+		data = append(data, 11)
+		data = append(data, dumpUint16(uint16(year))...)
+		data = append(data, byte(mon), byte(day), byte(t.Time.Hour()), byte(t.Time.Minute()), byte(t.Time.Second()))
+		data = append(data, dumpUint32(uint32(t.Time.Microsecond()))...)
 	}
 	return
 }
@@ -245,82 +247,117 @@ func uniformValue(value interface{}) interface{} {
 	}
 }
 
-func dumpRowValuesBinary(alloc arena.Allocator, columns []*ColumnInfo, row []types.Datum) (data []byte, err error) {
+// dumpBinaryRow encodes the row in the binary protocol and appends it to buffer. The values are encoded by the
+// column types directly, a value whose kind doesn't fit the column type is converted to the column type first.
+func dumpBinaryRow(sc *variable.StatementContext, buffer []byte, columns []*ColumnInfo, row []types.Datum) ([]byte, error) {
 	if len(columns) != len(row) {
-		err = mysql.ErrMalformPacket
-		return
+		return nil, mysql.ErrMalformPacket
+	}
+	buffer = append(buffer, mysql.OKHeader)
+	nullsPos := len(buffer)
+	nullsLen := (len(columns) + 7 + 2) / 8
+	for i := 0; i < nullsLen; i++ {
+		buffer = append(buffer, 0)
 	}
-	data = append(data, mysql.OKHeader)
-	nullsLen := ((len(columns) + 7 + 2) / 8)
-	nulls := make([]byte, nullsLen)
+	var err error
 	for i, val := range row {
 		if val.IsNull() {
 			bytePos := (i + 2) / 8
 			bitPos := byte((i + 2) % 8)
-			nulls[bytePos] |= 1 << bitPos
+			buffer[nullsPos+bytePos] |= 1 << bitPos
+			continue
+		}
+		buffer, err = dumpBinaryValue(sc, buffer, columns[i], val)
+		if err != nil {
+			return nil, errors.Trace(err)
 		}
 	}
-	data = append(data, nulls...)
-	for i, val := range row {
+	return buffer, nil
+}
+
+func dumpBinaryValue(sc *variable.StatementContext, buffer []byte, column *ColumnInfo, val types.Datum) ([]byte, error) {
+	switch column.Type {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeYear, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		var v uint64
 		switch val.Kind() {
 		case types.KindInt64:
-			v := val.GetInt64()
-			switch columns[i].Type {
-			case mysql.TypeTiny:
-				data = append(data, byte(v))
-			case mysql.TypeShort, mysql.TypeYear:
-				data = append(data, dumpUint16(uint16(v))...)
-			case mysql.TypeInt24, mysql.TypeLong:
-				data = append(data, dumpUint32(uint32(v))...)
-			case mysql.TypeLonglong:
-				data = append(data, dumpUint64(uint64(v))...)
-			}
+			v = uint64(val.GetInt64())
 		case types.KindUint64:
-			v := val.GetUint64()
-			switch columns[i].Type {
-			case mysql.TypeTiny:
-				data = append(data, byte(v))
-			case mysql.TypeShort, mysql.TypeYear:
-				data = append(data, dumpUint16(uint16(v))...)
-			case mysql.TypeInt24, mysql.TypeLong:
-				data = append(data, dumpUint32(uint32(v))...)
-			case mysql.TypeLonglong:
-				data = append(data, dumpUint64(uint64(v))...)
+			v = val.GetUint64()
+		default:
+			i, err := val.ToInt64(sc)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			v = uint64(i)
+		}
+		switch column.Type {
+		case mysql.TypeTiny:
+			return append(buffer, byte(v)), nil
+		case mysql.TypeShort, mysql.TypeYear:
+			return append(buffer, dumpUint16(uint16(v))...), nil
+		case mysql.TypeInt24, mysql.TypeLong:
+			return append(buffer, dumpUint32(uint32(v))...), nil
+		default:
+			return append(buffer, dumpUint64(v)...), nil
+		}
+	case mysql.TypeFloat, mysql.TypeDouble:
+		f, err := val.ToFloat64(sc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if column.Type == mysql.TypeFloat {
+			return append(buffer, dumpUint32(math.Float32bits(float32(f)))...), nil
+		}
+		return append(buffer, dumpUint64(math.Float64bits(f))...), nil
+	case mysql.TypeDate, mysql.TypeNewDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+		if val.Kind() != types.KindMysqlTime {
+			var err error
+			val, err = val.ConvertTo(sc, columnFieldType(column))
+			if err != nil {
+				return nil, errors.Trace(err)
 			}
-		case types.KindFloat32:
-			floatBits := math.Float32bits(val.GetFloat32())
-			data = append(data, dumpUint32(floatBits)...)
-		case types.KindFloat64:
-			floatBits := math.Float64bits(val.GetFloat64())
-			data = append(data, dumpUint64(floatBits)...)
-		case types.KindString, types.KindBytes:
-			data = append(data, dumpLengthEncodedString(val.GetBytes(), alloc)...)
-		case types.KindMysqlDecimal:
-			data = append(data, dumpLengthEncodedString(hack.Slice(val.GetMysqlDecimal().String()), alloc)...)
-		case types.KindMysqlTime:
-			tmp, err := dumpBinaryDateTime(val.GetMysqlTime(), nil)
+		}
+		data, err := dumpBinaryDateTime(val.GetMysqlTime(), nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(buffer, data...), nil
+	case mysql.TypeDuration:
+		if val.Kind() != types.KindMysqlDuration {
+			var err error
+			val, err = val.ConvertTo(sc, columnFieldType(column))
 			if err != nil {
-				return data, errors.Trace(err)
+				return nil, errors.Trace(err)
 			}
-			data = append(data, tmp...)
-		case types.KindMysqlDuration:
-			data = append(data, dumpBinaryTime(val.GetMysqlDuration().Duration)...)
-		case types.KindMysqlSet:
-			data = append(data, dumpLengthEncodedString(hack.Slice(val.GetMysqlSet().String()), alloc)...)
-		case types.KindMysqlHex:
-			data = append(data, dumpLengthEncodedString(hack.Slice(val.GetMysqlHex().ToString()), alloc)...)
-		case types.KindMysqlEnum:
-			data = append(data, dumpLengthEncodedString(hack.Slice(val.GetMysqlEnum().String()), alloc)...)
-		case types.KindMysqlBit:
-			data = append(data, dumpLengthEncodedString(hack.Slice(val.GetMysqlBit().ToString()), alloc)...)
 		}
+		return append(buffer, dumpBinaryTime(val.GetMysqlDuration().Duration)...), nil
 	}
-	return
+	// The other types, such as decimal, string, enum, set, bit and json, are sent as length encoded strings.
+	data, err := dumpTextValue(column.Type, val)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	buffer = append(buffer, dumpLengthEncodedInt(uint64(len(data)))...)
+	return append(buffer, data...), nil
+}
+
+// columnFieldType returns the field type which the values of the column are converted to.
+func columnFieldType(column *ColumnInfo) *types.FieldType {
+	ft := types.NewFieldType(column.Type)
+	ft.Flen = int(column.ColumnLength)
+	ft.Decimal = int(column.Decimal)
+	ft.Flag = uint(column.Flag)
+	return ft
 }
 
 func dumpTextValue(mysqlType uint8, value types.Datum) ([]byte, error) {
 	switch value.Kind() {
 	case types.KindInt64:
+		if mysqlType == mysql.TypeYear {
+			// Year is displayed in 4 digits, the zero year is '0000'.
+			return []byte(fmt.Sprintf("%04d", value.GetInt64())), nil
+		}
 		return strconv.AppendInt(nil, value.GetInt64(), 10), nil
 	case types.KindUint64:
 		return strconv.AppendUint(nil, value.GetUint64(), 10), nil
@@ -14,8 +14,11 @@
 package server
 
 import (
+	"math"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -50,6 +53,38 @@ func (s *testUtilSuite) TestDumpBinaryTime(c *C) {
 	c.Assert(d, DeepEquals, []byte{0})
 }
 
+func (s *testUtilSuite) TestDumpBinaryRow(c *C) {
+	defer testleak.AfterTest(c)()
+	sc := new(variable.StatementContext)
+	columns := []*ColumnInfo{
+		{Type: mysql.TypeLong},
+		{Type: mysql.TypeDouble},
+		{Type: mysql.TypeNewDecimal},
+		{Type: mysql.TypeDuration},
+		{Type: mysql.TypeVarchar},
+	}
+	duration, err := types.ParseDuration("100:00:01", 0)
+	c.Assert(err, IsNil)
+	row := []types.Datum{
+		types.NewIntDatum(1),
+		types.NewIntDatum(2),
+		types.NewIntDatum(3),
+		types.NewDurationDatum(duration),
+		{},
+	}
+	data, err := dumpBinaryRow(sc, []byte{0xff}, columns, row)
+	c.Assert(err, IsNil)
+	expected := []byte{0xff, mysql.OKHeader, 0x40}
+	expected = append(expected, 1, 0, 0, 0)
+	expected = append(expected, dumpUint64(math.Float64bits(2))...)
+	expected = append(expected, 1, '3')
+	expected = append(expected, 8, 0, 4, 0, 0, 0, 4, 0, 1)
+	c.Assert(data, DeepEquals, expected)
+
+	_, err = dumpBinaryRow(sc, nil, columns, row[:1])
+	c.Assert(err, Equals, mysql.ErrMalformPacket)
+}
+
 func (s *testUtilSuite) TestDumpTextValue(c *C) {
 	defer testleak.AfterTest(c)()
 	bs, err := dumpTextValue(mysql.TypeLonglong, types.NewIntDatum(10))
@@ -60,6 +95,10 @@ func (s *testUtilSuite) TestDumpTextValue(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(string(bs), Equals, "11")
 
+	bs, err = dumpTextValue(mysql.TypeYear, types.NewIntDatum(0))
+	c.Assert(err, IsNil)
+	c.Assert(string(bs), Equals, "0000")
+
 	f32 := types.NewFloat32Datum(1.2)
 	bs, err = dumpTextValue(mysql.TypeDouble, f32)
 	c.Assert(err, IsNil)
@@ -37,6 +37,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/perfschema"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/sessionctx"
@@ -47,7 +48,9 @@ import (
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/userlock"
 	"github.com/pingcap/tipb/go-binlog"
 	goctx "golang.org/x/net/context"
 )
@@ -58,12 +61,13 @@ type Session interface {
 	Status() uint16                              // Flag of current status, such as autocommit.
 	LastInsertID() uint64                        // Last inserted auto_increment id.
 	AffectedRows() uint64                        // Affected rows by latest executed stmt.
+	LastMessage() string                         // Info message of latest executed stmt, like "Rows matched: 1  Changed: 1  Warnings: 0".
 	Execute(sql string) ([]ast.RecordSet, error) // Execute a sql statement.
 	String() string                              // For debug
 	CommitTxn() error
 	RollbackTxn() error
 	// For execute prepare statement in binary protocol.
-	PrepareStmt(sql string) (stmtID uint32, paramCount int, fields []*ast.ResultField, err error)
+	PrepareStmt(sql string) (stmtID uint32, paramTypes []*types.FieldType, fields []*ast.ResultField, err error)
 	// Execute a prepared statement.
 	ExecutePreparedStmt(stmtID uint32, param ...interface{}) (ast.RecordSet, error)
 	DropPreparedStmt(stmtID uint32) error
@@ -103,12 +107,23 @@ func (h *stmtHistory) add(stmtID uint32, st ast.Statement, stmtCtx *variable.Sta
 	h.history = append(h.history, s)
 }
 
+// stmtSavepoint is the savepoint of the transaction before a statement, a failed statement is rolled back
+// to it alone, like MySQL, instead of leaving its partial changes in the transaction.
+type stmtSavepoint struct {
+	txn    kv.Transaction
+	binlog *binloginfo.PrewriteSavepoint
+}
+
 type session struct {
 	// It's used by ShowProcess(), and should be modified atomically.
 	processInfo atomic.Value
 	txn         kv.Transaction // current transaction
 	txnFuture   *txnFuture
 	txnFutureCh chan *txnFuture
+	// inStmt is true while a statement is running by runStmt, savepoint is created for it if the
+	// transaction outlives the statement.
+	inStmt    bool
+	savepoint *stmtSavepoint
 	// For cancel the execution of current transaction.
 	goCtx      goctx.Context
 	cancelFunc goctx.CancelFunc
@@ -150,6 +165,7 @@ func (s *session) cleanRetryInfo() {
 		retryInfo := s.sessionVars.RetryInfo
 		for _, stmtID := range retryInfo.DroppedPreparedStmtIDs {
 			delete(s.sessionVars.PreparedStmts, stmtID)
+			executor.DeletePreparedPlanCache(s.sessionVars, stmtID)
 		}
 		retryInfo.Clean()
 	}
@@ -170,6 +186,10 @@ func (s *session) AffectedRows() uint64 {
 	return s.sessionVars.StmtCtx.AffectedRows()
 }
 
+func (s *session) LastMessage() string {
+	return s.sessionVars.StmtCtx.GetMessage()
+}
+
 func (s *session) SetClientCapability(capability uint32) {
 	s.sessionVars.ClientCapability = capability
 }
@@ -186,44 +206,6 @@ func (s *session) GetSessionManager() util.SessionManager {
 	return s.sessionManager
 }
 
-type schemaLeaseChecker struct {
-	domain.SchemaValidator
-	schemaVer int64
-}
-
-const (
-	schemaOutOfDateRetryInterval = 500 * time.Millisecond
-	schemaOutOfDateRetryTimes    = 10
-)
-
-func (s *schemaLeaseChecker) Check(txnTS uint64) error {
-	for i := 0; i < schemaOutOfDateRetryTimes; i++ {
-		err := s.checkOnce(txnTS)
-		switch err {
-		case nil:
-			return nil
-		case domain.ErrInfoSchemaChanged:
-			schemaLeaseErrorCounter.WithLabelValues("changed").Inc()
-			return errors.Trace(err)
-		default:
-			schemaLeaseErrorCounter.WithLabelValues("outdated").Inc()
-			time.Sleep(schemaOutOfDateRetryInterval)
-		}
-	}
-	return domain.ErrInfoSchemaExpired
-}
-
-func (s *schemaLeaseChecker) checkOnce(txnTS uint64) error {
-	succ := s.SchemaValidator.Check(txnTS, s.schemaVer)
-	if !succ {
-		if s.SchemaValidator.Latest() > s.schemaVer {
-			return domain.ErrInfoSchemaChanged
-		}
-		return domain.ErrInfoSchemaExpired
-	}
-	return nil
-}
-
 func (s *session) doCommit() error {
 	if s.txn == nil || !s.txn.Valid() {
 		return nil
@@ -247,17 +229,111 @@ func (s *session) doCommit() error {
 		}
 	}
 
+	if !s.sessionVars.InTxn() {
+		// Small autocommit transactions are allowed to be committed in a group if group commit is enabled.
+		s.txn.SetOption(kv.GroupCommit, true)
+	}
 	// Set this option for 2 phase commit to validate schema lease.
-	s.txn.SetOption(kv.SchemaLeaseChecker, &schemaLeaseChecker{
-		SchemaValidator: sessionctx.GetDomain(s).SchemaValidator,
-		schemaVer:       s.sessionVars.TxnCtx.SchemaVersion,
-	})
-	if err := s.txn.Commit(); err != nil {
+	s.txn.SetOption(kv.SchemaLeaseChecker, domain.NewSchemaChecker(sessionctx.GetDomain(s),
+		s.sessionVars.TxnCtx.SchemaVersion, s.sessionVars.TxnCtx.WrittenTableIDs()))
+	s.observeTxnSize()
+	ph := sessionctx.GetDomain(s).PerfSchema()
+	waitState := ph.StartWait(s.sessionVars.ConnectionID, perfschema.WaitKVCommit)
+	err := s.txn.Commit()
+	ph.EndWait(waitState)
+	if err != nil {
 		return errors.Trace(err)
 	}
 	return nil
 }
 
+// startStmtSavepoint creates the savepoint for the running statement if the transaction outlives it,
+// it's called before the statement and when the statement begins a transaction.
+func (s *session) startStmtSavepoint() {
+	if !s.inStmt || s.savepoint != nil || s.txn == nil || !s.txn.Valid() {
+		return
+	}
+	if s.sessionVars.IsAutocommit() && !s.sessionVars.InTxn() {
+		// The transaction is committed or rolled back as a whole with the statement.
+		return
+	}
+	s.txn.StartStmt()
+	executor.BeginStmtDirtyDB(s)
+	s.savepoint = &stmtSavepoint{
+		txn:    s.txn,
+		binlog: binloginfo.NewPrewriteSavepoint(s),
+	}
+}
+
+// endStmt releases the savepoint of the statement, the changes of the statement are rolled back if
+// it fails. It returns the error of the statement.
+func (s *session) endStmt(err error) error {
+	s.inStmt = false
+	sp := s.savepoint
+	if sp == nil {
+		return err
+	}
+	s.savepoint = nil
+	if sp.txn != s.txn || !sp.txn.Valid() {
+		// The transaction is committed or rolled back by the statement.
+		executor.EndStmtDirtyDB(s, false)
+		return err
+	}
+	if err == nil {
+		err = sp.txn.CommitStmt()
+	}
+	if err != nil {
+		log.Infof("[%d] rollback statement in txn:%s, %v", s.sessionVars.ConnectionID, sp.txn, err)
+		sp.txn.RollbackStmt()
+		sp.binlog.Rollback(s)
+	}
+	executor.EndStmtDirtyDB(s, err != nil)
+	return err
+}
+
+// txnSizeNearLimitRatio is the ratio to the limits beyond which the transactions are counted as near the limits.
+const txnSizeNearLimitRatio = 0.8
+
+// txnSizeLimits returns the entry count and total size limits of the transactions of the session.
+func (s *session) txnSizeLimits() (entryCountLimit uint64, totalSizeLimit int) {
+	entryCountLimit, totalSizeLimit = atomic.LoadUint64(&kv.TxnEntryCountLimit), kv.TxnTotalSizeLimit
+	if s.sessionVars.TxnEntryCountLimit > 0 {
+		entryCountLimit = s.sessionVars.TxnEntryCountLimit
+	}
+	if s.sessionVars.TxnTotalSizeLimit > 0 {
+		totalSizeLimit = s.sessionVars.TxnTotalSizeLimit
+	}
+	return
+}
+
+// setTxnSizeLimits passes the limits overridden by the session variables to the new transaction.
+func (s *session) setTxnSizeLimits(txn kv.Transaction) {
+	if s.sessionVars.TxnEntryCountLimit > 0 {
+		txn.SetOption(kv.EntryCountLimit, s.sessionVars.TxnEntryCountLimit)
+	}
+	if s.sessionVars.TxnTotalSizeLimit > 0 {
+		txn.SetOption(kv.TotalSizeLimit, s.sessionVars.TxnTotalSizeLimit)
+	}
+}
+
+// observeTxnSize counts the committing transaction if its entry count or size is near the limits.
+func (s *session) observeTxnSize() {
+	entryCountLimit, totalSizeLimit := s.txnSizeLimits()
+	entries, size := s.txn.Len(), s.txn.Size()
+	nearEntryCountLimit := float64(entries) >= float64(entryCountLimit)*txnSizeNearLimitRatio
+	nearTotalSizeLimit := float64(size) >= float64(totalSizeLimit)*txnSizeNearLimitRatio
+	if nearEntryCountLimit {
+		txnNearSizeLimitCounter.WithLabelValues(txnLimitEntries).Inc()
+	}
+	if nearTotalSizeLimit {
+		txnNearSizeLimitCounter.WithLabelValues(txnLimitSize).Inc()
+	}
+	if nearEntryCountLimit || nearTotalSizeLimit {
+		log.Warnf("[%d] txn is near the size limits, entries: %d/%d, size: %d/%d, txn: %v",
+			s.sessionVars.ConnectionID, entries, entryCountLimit, size, totalSizeLimit, s.txn)
+	}
+}
+
 func (s *session) doCommitWithRetry() error {
 	var txnSize int
 	if s.txn != nil && s.txn.Valid() {
@@ -269,7 +345,8 @@ func (s *session) doCommitWithRetry() error {
 			log.Warnf("[%d] retryable error: %v, txn: %v", s.sessionVars.ConnectionID, err, s.txn)
 			// Transactions will retry 2 ~ commitRetryLimit times.
 			// We make larger transactions retry less times to prevent cluster resource outage.
-			txnSizeRate := float64(txnSize) / float64(kv.TxnTotalSizeLimit)
+			_, totalSizeLimit := s.txnSizeLimits()
+			txnSizeRate := float64(txnSize) / float64(totalSizeLimit)
 			maxRetryCount := commitRetryLimit - int(float64(commitRetryLimit-1)*txnSizeRate)
 			err = s.retry(maxRetryCount, terror.ErrorEqual(err, domain.ErrInfoSchemaChanged))
 		}
@@ -289,13 +366,25 @@ func (s *session) doCommitWithRetry() error {
 }
 
 func (s *session) CommitTxn() error {
-	return s.doCommitWithRetry()
+	ph := sessionctx.GetDomain(s).PerfSchema()
+	stageState := ph.StartStage(s.sessionVars.ConnectionID, perfschema.StageCommit)
+	valid := s.txn != nil && s.txn.Valid()
+	startTS := time.Now()
+	err := s.doCommitWithRetry()
+	ph.EndStage(stageState)
+	if valid {
+		label := resultLabel(err)
+		transactionCounter.WithLabelValues(txnCommit, label).Inc()
+		transactionDuration.WithLabelValues(label).Observe(time.Since(startTS).Seconds())
+	}
+	return errors.Trace(err)
 }
 
 func (s *session) RollbackTxn() error {
 	var err error
 	if s.txn != nil && s.txn.Valid() {
 		err = s.txn.Rollback()
+		transactionCounter.WithLabelValues(txnRollback, resultLabel(err)).Inc()
 	}
 	s.cleanRetryInfo()
 	s.txn = nil
@@ -408,7 +497,8 @@ func (s *session) retry(maxCnt int, infoSchemaChanged bool) error {
 func updateStatement(st ast.Statement, s *session, txt string) (ast.Statement, error) {
 	// statement maybe stale because of infoschema changed, this function will return the updated one.
 	if st.IsPrepared() {
-		// TODO: Rebuild plan if infoschema changed, reuse the statement otherwise.
+		// The prepared statement is prepared again if the tables it depends on are changed.
+		st = executor.RecompilePreparedStmt(s, st)
 	} else {
 		// Rebuild plan if infoschema changed, reuse the statement otherwise.
 		charset, collation := s.sessionVars.GetCharsetInfo()
@@ -557,7 +647,7 @@ func (s *session) ParseSQL(sql, charset, collation string) ([]ast.StmtNode, erro
 	return s.parser.Parse(sql, charset, collation)
 }
 
-func (s *session) SetProcessInfo(sql string) {
+func (s *session) SetProcessInfo(sql string, p plan.Plan) {
 	pi := util.ProcessInfo{
 		ID:      s.sessionVars.ConnectionID,
 		DB:      s.sessionVars.CurrentDB,
@@ -566,6 +656,15 @@ func (s *session) SetProcessInfo(sql string) {
 		State:   s.Status(),
 		Info:    sql,
 	}
+	if p != nil {
+		pi.Plan = p
+		pi.StmtCtx = s.sessionVars.StmtCtx
+	}
+	// The transaction of an autocommit statement is committed after the statement, so it's only
+	// recorded for the idle session when the session is in an explicit transaction.
+	if s.txn != nil && s.txn.Valid() && (len(sql) != 0 || s.sessionVars.InTxn()) {
+		pi.TxnStartTS = s.txn.StartTS()
+	}
 	strs := strings.Split(s.sessionVars.User, "@")
 	if len(strs) == 2 {
 		pi.User = strs[0]
@@ -580,23 +679,40 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 
 	charset, collation := s.sessionVars.GetCharsetInfo()
 	connID := s.sessionVars.ConnectionID
+	ph := sessionctx.GetDomain(s).PerfSchema()
+	stageState := ph.StartStage(connID, perfschema.StageParse)
 	rawStmts, err := s.ParseSQL(sql, charset, collation)
+	ph.EndStage(stageState)
 	if err != nil {
 		log.Warnf("[%d] parse error:\n%v\n%s", connID, err, sql)
+		resetStmtCtx(s, nil)
+		s.sessionVars.StmtCtx.AppendError(err)
 		return nil, errors.Trace(err)
 	}
-	sessionExecuteParseDuration.Observe(time.Since(startTS).Seconds())
+	parseStartTS, parseDuration := startTS, time.Since(startTS)
+	sessionExecuteParseDuration.Observe(parseDuration.Seconds())
 
 	var rs []ast.RecordSet
-	ph := sessionctx.GetDomain(s).PerfSchema()
 	for i, rst := range rawStmts {
 		s.prepareTxnCtx()
 		startTS := time.Now()
 		// Some execution is done in compile stage, so we reset it before compile.
 		resetStmtCtx(s, rst)
+		var traceSpan, compileSpan *tracing.Span
+		if _, ok := rst.(*ast.TraceStmt); ok {
+			// The statements are parsed together, so the parsing of all of them is traced.
+			traceSpan = tracing.StartSpanAt("trace", parseStartTS)
+			traceSpan.AddChild("session.parse", parseStartTS, parseDuration)
+			compileSpan = traceSpan.StartChild("session.compile")
+		}
+		stageState = ph.StartStage(connID, perfschema.StageCompile)
 		st, err1 := Compile(s, rst)
+		ph.EndStage(stageState)
+		compileSpan.Finish()
+		s.sessionVars.StmtCtx.TraceSpan = traceSpan
 		if err1 != nil {
 			log.Warnf("[%d] compile error:\n%v\n%s", connID, err1, sql)
+			s.sessionVars.StmtCtx.AppendError(err1)
 			s.RollbackTxn()
 			return nil, errors.Trace(err1)
 		}
@@ -606,12 +722,15 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 		s.SetValue(context.QueryString, st.OriginText())
 
 		startTS = time.Now()
+		stageState = ph.StartStage(connID, perfschema.StageExecute)
 		r, err := runStmt(s, st)
+		ph.EndStage(stageState)
 		ph.EndStatement(s.stmtState)
 		if err != nil {
 			if !terror.ErrorEqual(err, kv.ErrKeyExists) {
 				log.Warnf("[%d] session error:\n%v\n%s", connID, errors.ErrorStack(err), s)
 			}
+			s.sessionVars.StmtCtx.AppendError(err)
 			return nil, errors.Trace(err)
 		}
 		sessionExecuteRunDuration.Observe(time.Since(startTS).Seconds())
@@ -628,7 +747,7 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 }
 
 // For execute prepare statement in binary protocol
-func (s *session) PrepareStmt(sql string) (stmtID uint32, paramCount int, fields []*ast.ResultField, err error) {
+func (s *session) PrepareStmt(sql string) (stmtID uint32, paramTypes []*types.FieldType, fields []*ast.ResultField, err error) {
 	if s.sessionVars.TxnCtx.InfoSchema == nil {
 		// We don't need to create a transaction for prepare statement, just get information schema will do.
 		s.sessionVars.TxnCtx.InfoSchema = sessionctx.GetDomain(s).InfoSchema()
@@ -639,7 +758,7 @@ func (s *session) PrepareStmt(sql string) (stmtID uint32, paramCount int, fields
 		SQLText: sql,
 	}
 	prepareExec.DoPrepare()
-	return prepareExec.ID, prepareExec.ParamCount, prepareExec.Fields, prepareExec.Err
+	return prepareExec.ID, prepareExec.ParamTypes, prepareExec.Fields, prepareExec.Err
 }
 
 // checkArgs makes sure all the arguments' types are known and can be handled.
@@ -695,9 +814,15 @@ func (s *session) ExecutePreparedStmt(stmtID uint32, args ...interface{}) (ast.R
 		return nil, errors.Trace(err)
 	}
 	s.prepareTxnCtx()
+	if prepared, ok := s.sessionVars.PreparedStmts[stmtID].(*executor.Prepared); ok {
+		resetStmtCtx(s, prepared.Stmt)
+	}
 	st := executor.CompileExecutePreparedStmt(s, stmtID, args...)
 
 	r, err := runStmt(s, st)
+	if err != nil {
+		s.sessionVars.StmtCtx.AppendError(err)
+	}
 	return r, errors.Trace(err)
 }
 
@@ -736,10 +861,13 @@ func (s *session) GetTxn(forceNew bool) (kv.Transaction, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	s.setTxnSizeLimits(s.txn)
+	s.sessionVars.TxnCtx.StartTS = s.txn.StartTS()
 	ac := s.sessionVars.IsAutocommit()
 	if !ac {
 		s.sessionVars.SetStatusFlag(mysql.ServerStatusInTrans, true)
 	}
+	s.startStmtSavepoint()
 	log.Infof("[%d] %s new txn:%s", s.sessionVars.ConnectionID, force, s.txn)
 	return s.txn, nil
 }
@@ -759,7 +887,10 @@ func (s *session) NewTxn() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	s.setTxnSizeLimits(txn)
 	s.txn = txn
+	s.sessionVars.TxnCtx.StartTS = txn.StartTS()
+	s.startStmtSavepoint()
 	return nil
 }
 
@@ -790,6 +921,12 @@ func (s *session) Close() error {
 	if s.statsCollector != nil {
 		s.statsCollector.Delete()
 	}
+	if s.sessionVars.PreparedPlanCache != nil {
+		s.sessionVars.PreparedPlanCache.Clear()
+	}
+	if m := userlock.GetManager(s); m != nil {
+		m.ReleaseAll(s.sessionVars)
+	}
 	return s.RollbackTxn()
 }
 
@@ -905,12 +1042,71 @@ func BootstrapSession(store kv.Storage) (*domain.Domain, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	err = executor.LoadExprPushdownBlacklist(se)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = executor.LoadResourceGroups(se)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	se1, err := createSession(store)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 	err = dom.UpdateTableStatsLoop(se1)
-	return dom, errors.Trace(err)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	se2, err := createSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dom.EventSchedulerLoop(se2)
+	se3, err := createSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = dom.LoadReadOnlyLoop(se3)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return dom, nil
+}
+
+// PendingBootstrapSteps returns the steps BootstrapSession would apply to the store without applying them,
+// it's used to check the upgrade before starting the server.
+func PendingBootstrapSteps(store kv.Storage) ([]BootstrapStep, error) {
+	if _, ok := storeBootstrapped[store.UUID()]; ok {
+		return nil, nil
+	}
+	ver, err := loadStoreBootstrapVersion(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if ver == notBootstrapped {
+		return []BootstrapStep{{Version: currentBootstrapVersion, Description: freshBootstrapDescription}}, nil
+	}
+	if ver >= currentBootstrapVersion {
+		return nil, nil
+	}
+	// The version in the meta is updated after the whole upgrade, but the upgrade may be interrupted
+	// after some steps, the version of the finished steps is in mysql.tidb.
+	s, err := createSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	s.SetValue(context.Initing, true)
+	defer s.Close()
+	ver, err = getBootstrapVersion(s)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var steps []BootstrapStep
+	for _, step := range pendingUpgradeSteps(ver) {
+		steps = append(steps, BootstrapStep{Version: step.version, Description: step.description})
+	}
+	return steps, nil
 }
 
 // runInBootstrapSession create a special session for boostrap to run.
@@ -959,7 +1155,7 @@ func createSession(store kv.Storage) (*session, error) {
 
 const (
 	notBootstrapped         = 0
-	currentBootstrapVersion = 10
+	currentBootstrapVersion = 15
 )
 
 func getStoreBootstrapVersion(store kv.Storage) int64 {
@@ -969,15 +1165,8 @@ func getStoreBootstrapVersion(store kv.Storage) int64 {
 		return currentBootstrapVersion
 	}
 
-	var ver int64
 	// check in kv store
-	err := kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
-		var err error
-		t := meta.NewMeta(txn)
-		ver, err = t.GetBootstrapVersion()
-		return errors.Trace(err)
-	})
-
+	ver, err := loadStoreBootstrapVersion(store)
 	if err != nil {
 		log.Fatalf("check bootstrapped err %v", err)
 	}
@@ -990,6 +1179,18 @@ func getStoreBootstrapVersion(store kv.Storage) int64 {
 	return ver
 }
 
+// loadStoreBootstrapVersion loads the bootstrap version from the meta of the store.
+func loadStoreBootstrapVersion(store kv.Storage) (int64, error) {
+	var ver int64
+	err := kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		var err error
+		t := meta.NewMeta(txn)
+		ver, err = t.GetBootstrapVersion()
+		return errors.Trace(err)
+	})
+	return ver, errors.Trace(err)
+}
+
 func finishBootstrap(store kv.Storage) {
 	storeBootstrapped[store.UUID()] = true
 
@@ -1008,13 +1209,25 @@ const loadCommonGlobalVarsSQL = "select * from mysql.global_variables where vari
 	variable.AutocommitVar + quoteCommaQuote +
 	variable.SQLModeVar + quoteCommaQuote +
 	variable.MaxAllowedPacket + quoteCommaQuote +
+	variable.TimeZone + quoteCommaQuote +
+	variable.MaxErrorCount + quoteCommaQuote +
 	/* TiDB specific global variables: */
 	variable.TiDBSkipUTF8Check + quoteCommaQuote +
 	variable.TiDBSkipDDLWait + quoteCommaQuote +
+	variable.TiDBIgnoreTrigger + quoteCommaQuote +
+	variable.TiDBEnableClusteredIndex + quoteCommaQuote +
 	variable.TiDBIndexLookupSize + quoteCommaQuote +
 	variable.TiDBIndexLookupConcurrency + quoteCommaQuote +
+	variable.TiDBIndexJoinBatchSize + quoteCommaQuote +
 	variable.TiDBIndexSerialScanConcurrency + quoteCommaQuote +
 	variable.TiDBMaxRowCountForINLJ + quoteCommaQuote +
+	variable.TiDBIndexScanDirection + quoteCommaQuote +
+	variable.TiDBHashJoinMemQuota + quoteCommaQuote +
+	variable.TiDBStmtMaxExaminedRows + quoteCommaQuote +
+	variable.TiDBStmtMaxReturnedRows + quoteCommaQuote +
+	variable.TiDBStmtMemQuota + quoteCommaQuote +
+	variable.TiDBUnionConcurrency + quoteCommaQuote +
+	variable.TiDBSortMemQuota + quoteCommaQuote +
 	variable.TiDBDistSQLScanConcurrency + "')"
 
 // LoadCommonGlobalVariableIfNeeded loads and applies commonly used global variables for the session.
@@ -1053,6 +1266,18 @@ type txnFuture struct {
 }
 
 func (s *session) getTxnFuture() *txnFuture {
+	if s.sessionVars.LowResolutionTSO && s.sessionVars.IsAutocommit() {
+		if store, ok := s.store.(kv.LowResolutionTSStorage); ok {
+			// The transaction is begun at once, because no timestamp is requested.
+			future := &txnFuture{}
+			var startTS uint64
+			startTS, future.err = store.LowResolutionTS()
+			if future.err == nil {
+				future.txn, future.err = s.store.BeginWithStartTS(startTS)
+			}
+			return future
+		}
+	}
 	if s.txnFutureCh == nil {
 		s.txnFutureCh = make(chan *txnFuture, 1)
 		go asyncGetTSWorker(s.store, s.txnFutureCh)
@@ -1115,11 +1340,17 @@ func (s *session) ActivePendingTxn() error {
 	}
 	future := s.txnFuture
 	s.txnFuture = nil
+	ph := sessionctx.GetDomain(s).PerfSchema()
+	waitState := ph.StartWait(s.sessionVars.ConnectionID, perfschema.WaitKVTimestamp)
 	future.Wait()
+	ph.EndWait(waitState)
 	if future.err != nil {
 		return errors.Trace(future.err)
 	}
 	s.txn = future.txn
+	s.setTxnSizeLimits(s.txn)
+	s.sessionVars.TxnCtx.StartTS = s.txn.StartTS()
+	s.startStmtSavepoint()
 	err := s.loadCommonGlobalVariablesIfNeeded()
 	if err != nil {
 		return errors.Trace(err)
@@ -1142,6 +1373,9 @@ func (s *session) InitTxnWithStartTS(startTS uint64) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	s.setTxnSizeLimits(s.txn)
+	s.sessionVars.TxnCtx.StartTS = s.txn.StartTS()
+	s.startStmtSavepoint()
 	err = s.loadCommonGlobalVariablesIfNeeded()
 	if err != nil {
 		return errors.Trace(err)
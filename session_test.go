@@ -88,7 +88,7 @@ func (s *testSessionSuite) TestPrepare(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(fields, HasLen, 1)
 	c.Assert(id, Equals, uint32(1))
-	c.Assert(ps, Equals, 1)
+	c.Assert(ps, HasLen, 1)
 	mustExecSQL(c, se, `set @a=1`)
 	_, err = se.ExecutePreparedStmt(id, "1")
 	c.Assert(err, IsNil)
@@ -100,19 +100,19 @@ func (s *testSessionSuite) TestPrepare(c *C) {
 	rs := mustExecSQL(c, se, "execute stmt using @v1")
 	r, err := rs.Next()
 	c.Assert(err, IsNil)
-	c.Assert(r.Data[0].GetFloat64(), Equals, float64(101))
+	c.Assert(r.Data[0].GetInt64(), Equals, int64(101))
 
 	mustExecSQL(c, se, "set @v2=200")
 	rs = mustExecSQL(c, se, "execute stmt using @v2")
 	r, err = rs.Next()
 	c.Assert(err, IsNil)
-	c.Assert(r.Data[0].GetFloat64(), Equals, float64(201))
+	c.Assert(r.Data[0].GetInt64(), Equals, int64(201))
 
 	mustExecSQL(c, se, "set @v3=300")
 	rs = mustExecSQL(c, se, "execute stmt using @v3")
 	r, err = rs.Next()
 	c.Assert(err, IsNil)
-	c.Assert(r.Data[0].GetFloat64(), Equals, float64(301))
+	c.Assert(r.Data[0].GetInt64(), Equals, int64(301))
 	mustExecSQL(c, se, "deallocate prepare stmt")
 
 	// Execute prepared statements for more than one time.
@@ -187,6 +187,7 @@ func (s *testSessionSuite) TestAffectedRows(c *C) {
 	mustExecSQL(c, se, `INSERT INTO t VALUES (1, 0), (0, 0), (1, 1);`)
 	mustExecSQL(c, se, `UPDATE t set id = 1 where data = 0;`)
 	c.Assert(int(se.AffectedRows()), Equals, 2)
+	c.Assert(se.LastMessage(), Equals, "Rows matched: 2  Changed: 1  Warnings: 0")
 
 	sessionExec(c, se, dropDBSQL)
 }
@@ -2169,6 +2170,37 @@ func (s *testSessionSuite) TestErrorRollback(c *C) {
 	mustExecSQL(c, s1, dropDBSQL)
 }
 
+func (s *testSessionSuite) TestStmtRollback(c *C) {
+	defer testleak.AfterTest(c)()
+	dbName := "test_stmt_rollback"
+	se := newSession(c, s.store, dbName)
+	defer se.Close()
+	mustExecSQL(c, se, "create table t (a int primary key, b int, unique key (b))")
+	mustExecSQL(c, se, "insert into t values (1, 1)")
+
+	mustExecSQL(c, se, "begin")
+	mustExecSQL(c, se, "insert into t values (2, 2)")
+	// The first row, whose existing key is checked lazily at commit, is rolled back with the failed second row.
+	_, err := se.Execute("insert into t values (1, 3), (3, 2)")
+	c.Assert(err, NotNil)
+	mustExecMatch(c, se, "select * from t", [][]interface{}{{1, 1}, {2, 2}})
+	_, err = se.Execute("update t set b = b + 1")
+	c.Assert(err, NotNil)
+	mustExecMatch(c, se, "select a from t where b = 1", [][]interface{}{{1}})
+	mustExecSQL(c, se, "insert into t values (3, 3)")
+	mustExecSQL(c, se, "commit")
+	mustExecMatch(c, se, "select * from t", [][]interface{}{{1, 1}, {2, 2}, {3, 3}})
+
+	mustExecSQL(c, se, "set autocommit = 0")
+	mustExecSQL(c, se, "delete from t where a = 3")
+	_, err = se.Execute("insert into t values (4, 4), (5, 4)")
+	c.Assert(err, NotNil)
+	mustExecSQL(c, se, "insert into t values (5, 5)")
+	mustExecSQL(c, se, "commit")
+	mustExecSQL(c, se, "set autocommit = 1")
+	mustExecMatch(c, se, "select * from t", [][]interface{}{{1, 1}, {2, 2}, {5, 5}})
+}
+
 func (s *testSessionSuite) TestMultiColumnIndex(c *C) {
 	defer testleak.AfterTest(c)()
 	dbName := "test_multi_column_index"
@@ -2758,7 +2790,7 @@ func (s *testSessionSuite) TestPrepareStmtCommitWhenSchemaChanged(c *C) {
 	mustExecSQL(c, s1, "create table t (a int, b int)")
 
 	s2 := newSession(c, s.store, dbName)
-	mustExecSQL(c, s2, "prepare stmt from 'insert into t values (?, ?)'")
+	mustExecSQL(c, s2, "prepare stmt from 'insert into t (a, b) values (?, ?)'")
 	mustExecSQL(c, s2, "set @a = 1")
 
 	// Commit find unrelated schema change.
@@ -2768,10 +2800,27 @@ func (s *testSessionSuite) TestPrepareStmtCommitWhenSchemaChanged(c *C) {
 	_, err := s2.Execute("commit")
 	c.Assert(err, IsNil)
 
-	// TODO: PrepareStmt should handle this.
-	// mustExecSQL(c, s2, "begin")
-	// mustExecSQL(c, s1, "alter table t drop column b")
-	// mustExecSQL(c, s2, "execute stmt using @a, @a")
-	// _, err = s2.Execute("commit")
-	// c.Assert(terror.ErrorEqual(err, executor.ErrWrongValueCountOnRow), IsTrue)
+	// The statement is prepared again after compatible DDL.
+	mustExecSQL(c, s2, "set @a = 2")
+	mustExecSQL(c, s1, "alter table t add column c int default 3")
+	mustExecSQL(c, s2, "execute stmt using @a, @a")
+	mustExecMatch(c, s1, "select * from t where a = 2", [][]interface{}{{2, 2, 3}})
+
+	// The statement is prepared again when the transaction is retried after the schema is changed.
+	mustExecSQL(c, s2, "begin")
+	mustExecSQL(c, s2, "set @a = 3")
+	mustExecSQL(c, s2, "execute stmt using @a, @a")
+	mustExecSQL(c, s1, "alter table t add column d varchar(36) default (uuid())")
+	// Discard the transaction like it fails to commit, then retry it.
+	se2 := s2.(*session)
+	c.Assert(se2.txn.Rollback(), IsNil)
+	se2.txn = nil
+	err = se2.retry(1, true)
+	c.Assert(err, IsNil)
+	mustExecMatch(c, s1, "select a, d is not null from t where a = 3", [][]interface{}{{3, 1}})
+
+	// The statement which isn't compatible with the new schema fails.
+	mustExecSQL(c, s1, "alter table t drop column b")
+	_, err = s2.Execute("execute stmt using @a, @a")
+	c.Assert(err, ErrorMatches, ".*unknown column b")
 }
@@ -46,6 +46,64 @@ func GetPrewriteValue(ctx context.Context, createIfNotExists bool) *binlog.Prewr
 	return v
 }
 
+type mutationSize struct {
+	insertedRows int
+	updatedRows  int
+	deletedIDs   int
+	deletedPKs   int
+	deletedRows  int
+	sequence     int
+}
+
+// PrewriteSavepoint records the sizes of the table mutations in the binlog prewrite value of a transaction,
+// the mutations added after it are discarded by Rollback.
+type PrewriteSavepoint struct {
+	sizes []mutationSize
+}
+
+// NewPrewriteSavepoint creates a PrewriteSavepoint for the binlog prewrite value in the context,
+// it returns nil if binlog is not enabled.
+func NewPrewriteSavepoint(ctx context.Context) *PrewriteSavepoint {
+	if PumpClient == nil {
+		return nil
+	}
+	sp := &PrewriteSavepoint{}
+	if v := GetPrewriteValue(ctx, false); v != nil {
+		for _, m := range v.Mutations {
+			sp.sizes = append(sp.sizes, mutationSize{
+				insertedRows: len(m.InsertedRows),
+				updatedRows:  len(m.UpdatedRows),
+				deletedIDs:   len(m.DeletedIds),
+				deletedPKs:   len(m.DeletedPks),
+				deletedRows:  len(m.DeletedRows),
+				sequence:     len(m.Sequence),
+			})
+		}
+	}
+	return sp
+}
+
+// Rollback discards the mutations added to the binlog prewrite value in the context since the savepoint.
+func (sp *PrewriteSavepoint) Rollback(ctx context.Context) {
+	if sp == nil {
+		return
+	}
+	v := GetPrewriteValue(ctx, false)
+	if v == nil {
+		return
+	}
+	v.Mutations = v.Mutations[:len(sp.sizes)]
+	for i, size := range sp.sizes {
+		m := &v.Mutations[i]
+		m.InsertedRows = m.InsertedRows[:size.insertedRows]
+		m.UpdatedRows = m.UpdatedRows[:size.updatedRows]
+		m.DeletedIds = m.DeletedIds[:size.deletedIDs]
+		m.DeletedPks = m.DeletedPks[:size.deletedPKs]
+		m.DeletedRows = m.DeletedRows[:size.deletedRows]
+		m.Sequence = m.Sequence[:size.sequence]
+	}
+}
+
 // WriteBinlog writes a binlog to Pump.
 func WriteBinlog(bin *binlog.Binlog, clusterID uint64) error {
 	commitData, _ := bin.Marshal()
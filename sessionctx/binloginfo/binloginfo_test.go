@@ -26,9 +26,11 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/util/bulkload"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/types"
@@ -225,6 +227,78 @@ func (s *testBinlogSuite) TestBinlog(c *C) {
 	c.Assert(newBinlogLen, Equals, originBinlogLen)
 }
 
+func (s *testBinlogSuite) TestFileClient(c *C) {
+	tk := s.tk
+	path := "/tmp/test-file-binlog"
+	os.Remove(path)
+	defer os.Remove(path)
+	client, err := binloginfo.NewFileClient(path)
+	c.Assert(err, IsNil)
+	origin := binloginfo.PumpClient
+	binloginfo.PumpClient = client
+	defer func() { binloginfo.PumpClient = origin }()
+
+	tk.MustExec("drop table if exists file_binlog")
+	tk.MustExec("create table file_binlog (id int primary key)")
+	tk.MustExec("insert file_binlog values (1)")
+
+	var prewrite, commit *binlog.Binlog
+	for i := 0; i < 10 && commit == nil; i++ {
+		time.Sleep(time.Millisecond * 10)
+		f, err := os.Open(path)
+		c.Assert(err, IsNil)
+		err = binloginfo.ReadBinlogs(f, func(bin *binlog.Binlog) error {
+			if bin.DdlJobId != 0 {
+				return nil
+			}
+			if bin.Tp == binlog.BinlogType_Prewrite {
+				prewrite = bin
+			} else if prewrite != nil && bin.Tp == binlog.BinlogType_Commit && bin.StartTs == prewrite.StartTs {
+				commit = bin
+			}
+			return nil
+		})
+		f.Close()
+		c.Assert(err, IsNil)
+	}
+	c.Assert(commit, NotNil)
+	c.Assert(commit.CommitTs, Greater, commit.StartTs)
+	prewriteVal := new(binlog.PrewriteValue)
+	c.Assert(prewriteVal.Unmarshal(prewrite.PrewriteValue), IsNil)
+	c.Assert(prewriteVal.Mutations, HasLen, 1)
+	tk.MustExec("drop table file_binlog")
+}
+
+func (s *testBinlogSuite) TestBulkLoad(c *C) {
+	tk := s.tk
+	tk.MustExec("drop table if exists bulk_binlog")
+	tk.MustExec("create table bulk_binlog (id int primary key, name varchar(10))")
+	ctx := tk.Se.(context.Context)
+	tbl, err := sessionctx.GetDomain(ctx).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("bulk_binlog"))
+	c.Assert(err, IsNil)
+	l, err := bulkload.NewLoader(ctx, tbl)
+	c.Assert(err, IsNil)
+	c.Assert(l.AddRow(types.MakeDatums(1, "abc")), IsNil)
+	c.Assert(l.AddRow(types.MakeDatums(2, "cde")), IsNil)
+	c.Assert(l.Commit(), IsNil)
+
+	prewriteVal := getLatestBinlogPrewriteValue(c, s.pump)
+	c.Assert(prewriteVal.SchemaVersion, Greater, int64(0))
+	c.Assert(prewriteVal.Mutations, HasLen, 1)
+	c.Assert(prewriteVal.Mutations[0].TableId, Equals, tbl.Meta().ID)
+	c.Assert(prewriteVal.Mutations[0].Sequence, DeepEquals, []binlog.MutationType{
+		binlog.MutationType_Insert,
+		binlog.MutationType_Insert,
+	})
+	expected := [][]types.Datum{
+		{types.NewIntDatum(1), types.NewStringDatum("abc")},
+		{types.NewIntDatum(2), types.NewStringDatum("cde")},
+	}
+	gotRows := mutationRowsToRows(c, prewriteVal.Mutations[0].InsertedRows, 0, 2)
+	c.Assert(gotRows, DeepEquals, expected)
+	tk.MustExec("drop table bulk_binlog")
+}
+
 func getLatestBinlogPrewriteValue(c *C, pump *mockBinlogPump) *binlog.PrewriteValue {
 	var bin *binlog.Binlog
 	pump.mu.Lock()
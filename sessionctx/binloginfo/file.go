@@ -0,0 +1,90 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binloginfo
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tipb/go-binlog"
+	goctx "golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// fileClient is a binlog.PumpClient which appends binlogs to a local file, so
+// the binlogs can be consumed by downstream tools without running a Pump.
+// Each binlog is stored as a 4 bytes big-endian length followed by the payload.
+type fileClient struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileClient creates a binlog.PumpClient that appends binlogs to the file at path.
+func NewFileClient(path string) (binlog.PumpClient, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &fileClient{file: file}, nil
+}
+
+// WriteBinlog implements binlog.PumpClient interface.
+func (c *fileClient) WriteBinlog(ctx goctx.Context, in *binlog.WriteBinlogReq, opts ...grpc.CallOption) (*binlog.WriteBinlogResp, error) {
+	buf := make([]byte, 4+len(in.Payload))
+	binary.BigEndian.PutUint32(buf, uint32(len(in.Payload)))
+	copy(buf[4:], in.Payload)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.file.Write(buf); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := c.file.Sync(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &binlog.WriteBinlogResp{}, nil
+}
+
+// PullBinlogs implements binlog.PumpClient interface.
+func (c *fileClient) PullBinlogs(ctx goctx.Context, in *binlog.PullBinlogReq, opts ...grpc.CallOption) (binlog.Pump_PullBinlogsClient, error) {
+	return nil, errors.New("pull binlogs is not supported by file binlog client")
+}
+
+// ReadBinlogs reads the binlogs written by the file client from r in order, and calls fn for each of them.
+func ReadBinlogs(r io.Reader, fn func(bin *binlog.Binlog) error) error {
+	var header [4]byte
+	for {
+		_, err := io.ReadFull(r, header[:])
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header[:]))
+		if _, err = io.ReadFull(r, payload); err != nil {
+			return errors.Trace(err)
+		}
+		bin := new(binlog.Binlog)
+		if err = bin.Unmarshal(payload); err != nil {
+			return errors.Trace(err)
+		}
+		if err = fn(bin); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
@@ -16,6 +16,7 @@ package sessionctx
 import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/util/userlock"
 )
 
 // domainKeyType is a dummy type to avoid naming collision in context.
@@ -28,9 +29,12 @@ func (k domainKeyType) String() string {
 
 const domainKey domainKeyType = 0
 
-// BindDomain binds domain to context.
+// BindDomain binds domain to context, the user lock manager of the domain is bound too.
 func BindDomain(ctx context.Context, domain *domain.Domain) {
 	ctx.SetValue(domainKey, domain)
+	if domain != nil {
+		userlock.BindManager(ctx, domain.UserLocks())
+	}
 }
 
 // GetDomain gets domain from context.
@@ -0,0 +1,61 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package variable
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// serverReadOnly is set by the '-read-only' flag of tidb-server, it's accessed atomically. It works like
+// the global read_only variable, but it can only be changed by reloading the configuration of tidb-server.
+var serverReadOnly int32
+
+// SetServerReadOnly sets the switch of the '-read-only' flag of tidb-server.
+func SetServerReadOnly(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&serverReadOnly, v)
+}
+
+// readOnly and superReadOnly hold the values of the global read_only and super_read_only variables
+// known by this server, they're accessed atomically.
+var readOnly, superReadOnly int32
+
+// SetReadOnly sets the switch of the global variable name, which is ReadOnly or SuperReadOnly.
+func SetReadOnly(name, value string) {
+	var on int32
+	if strings.EqualFold(value, "ON") || strings.EqualFold(value, "TRUE") || value == "1" {
+		on = 1
+	}
+	switch name {
+	case ReadOnly:
+		atomic.StoreInt32(&readOnly, on)
+	case SuperReadOnly:
+		atomic.StoreInt32(&superReadOnly, on)
+	}
+}
+
+// IsReadOnly returns whether the writes of the users without the SUPER privilege are refused.
+// super_read_only implies read_only.
+func IsReadOnly() bool {
+	return atomic.LoadInt32(&serverReadOnly) != 0 || atomic.LoadInt32(&readOnly) != 0 || IsSuperReadOnly()
+}
+
+// IsSuperReadOnly returns whether the writes of all the users are refused.
+func IsSuperReadOnly() bool {
+	return atomic.LoadInt32(&superReadOnly) != 0
+}
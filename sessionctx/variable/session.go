@@ -20,6 +20,11 @@ import (
 
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/arena"
+	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/pingcap/tidb/util/kvcache"
+	"github.com/pingcap/tidb/util/resourcegroup"
+	"github.com/pingcap/tidb/util/tracing"
 )
 
 const (
@@ -82,6 +87,9 @@ type TransactionContext struct {
 	Histroy       interface{}
 	SchemaVersion int64
 	TableDeltaMap map[int64]TableDelta
+	// StartTS is the start timestamp of the transaction, it's kept after the transaction is committed, so the
+	// auto-commit statements can read it when returning the rows.
+	StartTS uint64
 }
 
 // UpdateDeltaForTable updates the delta info for some table.
@@ -95,10 +103,26 @@ func (tc *TransactionContext) UpdateDeltaForTable(tableID int64, delta int64, co
 	tc.TableDeltaMap[tableID] = item
 }
 
+// HasWrittenTable checks whether the transaction has written the table.
+func (tc *TransactionContext) HasWrittenTable(tableID int64) bool {
+	_, ok := tc.TableDeltaMap[tableID]
+	return ok
+}
+
+// WrittenTableIDs returns the IDs of the tables written by the transaction.
+func (tc *TransactionContext) WrittenTableIDs() []int64 {
+	ids := make([]int64, 0, len(tc.TableDeltaMap))
+	for id := range tc.TableDeltaMap {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 // SessionVars is to handle user-defined or global variables in the current session.
 type SessionVars struct {
-	// Users are user defined variables.
-	Users map[string]string
+	// Users are user defined variables, the values are types.Datum, it's interface{} to avoid the cycle import.
+	// Use expression.SetUserVar and expression.GetUserVar to access them.
+	Users map[string]interface{}
 	// Systems are system variables.
 	Systems map[string]string
 	// PreparedStmts stores prepared statement.
@@ -106,6 +130,8 @@ type SessionVars struct {
 	PreparedStmtNameToID map[string]uint32
 	// preparedStmtID is id of prepared statement.
 	preparedStmtID uint32
+	// PreparedPlanCache caches the plans of the prepared statements, it's created when it's used first.
+	PreparedPlanCache *kvcache.Cache
 
 	// retry information
 	RetryInfo *RetryInfo
@@ -116,6 +142,8 @@ type SessionVars struct {
 
 	Status           uint16
 	PrevLastInsertID uint64 // PrevLastInsertID is the last insert ID of previous statement.
+	PrevWarningCount uint16 // PrevWarningCount is the warning count of previous statement, the value of @@warning_count.
+	PrevErrorCount   uint16 // PrevErrorCount is the error count of previous statement, the value of @@error_count.
 	LastInsertID     uint64 // LastInsertID is the auto-generated ID in the current statement.
 	InsertID         uint64 // InsertID is the given insert ID of an auto_increment column.
 
@@ -172,6 +200,9 @@ type SessionVars struct {
 
 	SQLMode mysql.SQLMode
 
+	// MaxErrorCount is the max number of the warnings of a statement kept for SHOW WARNINGS.
+	MaxErrorCount int
+
 	/* TiDB system variables */
 
 	// SkipConstraintCheck is true when importing data.
@@ -184,12 +215,21 @@ type SessionVars struct {
 	// Then if there are multiple TiDB servers, the new table may not be available for other TiDB servers.
 	SkipDDLWait bool
 
+	// IgnoreTrigger makes trigger definitions accepted but ignored.
+	IgnoreTrigger bool
+
+	// EnableClusteredIndex makes the primary keys of the new tables clustered.
+	EnableClusteredIndex bool
+
 	// BuildStatsConcurrencyVar is used to control statistics building concurrency.
 	BuildStatsConcurrencyVar int
 
 	// IndexLookupSize is the number of handles for an index lookup task in index double read executor.
 	IndexLookupSize int
 
+	// IndexJoinBatchSize is the number of the outer rows of a batch in index lookup join executor.
+	IndexJoinBatchSize int
+
 	// IndexLookupConcurrency is the number of concurrent index lookup worker.
 	IndexLookupConcurrency int
 
@@ -202,14 +242,39 @@ type SessionVars struct {
 	// BatchInsert indicates if we should split insert data into multiple batches.
 	BatchInsert bool
 
+	// BulkLoad indicates if load data statements bulk load the data.
+	BulkLoad bool
+
+	// TxnEntryCountLimit and TxnTotalSizeLimit override the server-wide limits of the transactions if they are not 0.
+	TxnEntryCountLimit uint64
+	TxnTotalSizeLimit  int
+
 	// MaxRowCountForINLJ defines max row count that the outer table of index nested loop join could be without force hint.
 	MaxRowCountForINLJ int
+
+	// IndexScanDirection is the direction of the ordered table and index scans if it's not forced by the hints.
+	IndexScanDirection ScanDirection
+
+	// HashJoinMemQuota is the memory quota in bytes of the build side of a hash join, 0 means no quota.
+	HashJoinMemQuota int
+
+	// StmtLimits limits the rows examined and returned and the memory of every statement.
+	StmtLimits resourcegroup.StmtLimits
+
+	// UnionConcurrency is the max number of the branches of a UNION executed at the same time.
+	UnionConcurrency int
+
+	// SortMemQuota is the memory quota in bytes of the rows sorted by a sort executor, 0 means no quota.
+	SortMemQuota int
+
+	// LowResolutionTSO makes the statements in autocommit mode read at a recently fetched timestamp.
+	LowResolutionTSO bool
 }
 
 // NewSessionVars creates a session vars object.
 func NewSessionVars() *SessionVars {
 	return &SessionVars{
-		Users:                      make(map[string]string),
+		Users:                      make(map[string]interface{}),
 		Systems:                    make(map[string]string),
 		PreparedStmts:              make(map[uint32]interface{}),
 		PreparedStmtNameToID:       make(map[string]uint32),
@@ -218,13 +283,18 @@ func NewSessionVars() *SessionVars {
 		StrictSQLMode:              true,
 		Status:                     mysql.ServerStatusAutocommit,
 		StmtCtx:                    new(StatementContext),
+		MaxErrorCount:              DefMaxErrorCount,
 		AllowAggPushDown:           true,
 		BuildStatsConcurrencyVar:   DefBuildStatsConcurrency,
 		IndexLookupSize:            DefIndexLookupSize,
 		IndexLookupConcurrency:     DefIndexLookupConcurrency,
+		IndexJoinBatchSize:         DefIndexJoinBatchSize,
 		IndexSerialScanConcurrency: DefIndexSerialScanConcurrency,
 		DistSQLScanConcurrency:     DefDistSQLScanConcurrency,
 		MaxRowCountForINLJ:         DefMaxRowCountForINLJ,
+		HashJoinMemQuota:           DefHashJoinMemQuota,
+		UnionConcurrency:           DefUnionConcurrency,
+		SortMemQuota:               DefSortMemQuota,
 	}
 }
 
@@ -300,10 +370,17 @@ const (
 	SQLModeVar          = "sql_mode"
 	AutocommitVar       = "autocommit"
 	CharacterSetResults = "character_set_results"
+	CharacterSetClient  = "character_set_client"
 	MaxAllowedPacket    = "max_allowed_packet"
 	TimeZone            = "time_zone"
+	MaxErrorCount       = "max_error_count"
+	WarningCount        = "warning_count"
+	ErrorCount          = "error_count"
 )
 
+// DefMaxErrorCount is the default value of max_error_count.
+const DefMaxErrorCount = 64
+
 // TableDelta stands for the changed count for one table.
 type TableDelta struct {
 	Delta int64
@@ -323,14 +400,57 @@ type StatementContext struct {
 	IgnoreTruncate       bool
 	TruncateAsWarning    bool
 	InShowWarning        bool
+	// BadNullAsWarning is true if the NULL values written to the NOT NULL columns and the missing values of
+	// the NOT NULL columns without default values are replaced by the zero values with warnings.
+	BadNullAsWarning bool
+	// MaxErrorCount is the value of max_error_count, the warnings beyond it are counted but not kept
+	// for SHOW WARNINGS.
+	MaxErrorCount int
+	// ResourceQuota limits the statement by the resource group of the user and the limits of the session,
+	// it's nil if neither limits the statement.
+	ResourceQuota *resourcegroup.StmtQuota
+	// TraceSpan is the span of the phase being traced for the TRACE statement, the coprocessor requests
+	// sent in the phase are traced as its children. It's nil if the statement isn't traced.
+	TraceSpan *tracing.Span
+	// SelectivityHints maps the predicates annotated by the SELECTIVITY hints to the selectivities,
+	// it's filled by the plan builder and the predicates are keyed by the strings of the expressions.
+	SelectivityHints map[string]float64
 
 	// mu struct holds variables that change during execution.
 	mu struct {
 		sync.Mutex
 		affectedRows uint64
 		foundRows    uint64
-		warnings     []error
+		// recordRows, duplicatedRows, touchedRows and updatedRows count the rows for the info message of
+		// the OK packet. recordRows is the number of rows written by INSERT, REPLACE and LOAD DATA,
+		// duplicatedRows is the number of them ignored or replacing the old rows for the duplicate keys,
+		// touchedRows is the number of rows matched by UPDATE and updatedRows is the number of them changed.
+		recordRows     uint64
+		duplicatedRows uint64
+		touchedRows    uint64
+		updatedRows    uint64
+		// message is the info message of the OK packet, like "Rows matched: 1  Changed: 1  Warnings: 0".
+		message  string
+		warnings []SQLWarn
+		// warningCount and errorCount count all the errors and warnings of the statement, including the
+		// ones not kept for exceeding MaxErrorCount.
+		warningCount uint64
+		errorCount   uint64
+		// execDetails holds the runtime statistics of the coprocessor requests by plan ID.
+		execDetails map[string]*execdetails.ExecDetails
 	}
+
+	// allocator holds the memory of the string values built during execution.
+	allocator arena.BlockAllocator
+}
+
+// AllocBytes allocates a byte slice with 0 len and the specified capacity.
+// The memory is shared by the whole statement to avoid allocating a new buffer for every row.
+func (sc *StatementContext) AllocBytes(capacity int) []byte {
+	if sc == nil {
+		return make([]byte, 0, capacity)
+	}
+	return sc.allocator.Alloc(capacity)
 }
 
 // AddAffectedRows adds affected rows.
@@ -363,40 +483,226 @@ func (sc *StatementContext) AddFoundRows(rows uint64) {
 	sc.mu.Unlock()
 }
 
+// AddRecordRows adds record rows.
+func (sc *StatementContext) AddRecordRows(rows uint64) {
+	sc.mu.Lock()
+	sc.mu.recordRows += rows
+	sc.mu.Unlock()
+}
+
+// RecordRows gets record rows.
+func (sc *StatementContext) RecordRows() uint64 {
+	sc.mu.Lock()
+	rows := sc.mu.recordRows
+	sc.mu.Unlock()
+	return rows
+}
+
+// AddDuplicatedRows adds duplicated rows.
+func (sc *StatementContext) AddDuplicatedRows(rows uint64) {
+	sc.mu.Lock()
+	sc.mu.duplicatedRows += rows
+	sc.mu.Unlock()
+}
+
+// DuplicatedRows gets duplicated rows.
+func (sc *StatementContext) DuplicatedRows() uint64 {
+	sc.mu.Lock()
+	rows := sc.mu.duplicatedRows
+	sc.mu.Unlock()
+	return rows
+}
+
+// AddTouchedRows adds touched rows.
+func (sc *StatementContext) AddTouchedRows(rows uint64) {
+	sc.mu.Lock()
+	sc.mu.touchedRows += rows
+	sc.mu.Unlock()
+}
+
+// TouchedRows gets touched rows.
+func (sc *StatementContext) TouchedRows() uint64 {
+	sc.mu.Lock()
+	rows := sc.mu.touchedRows
+	sc.mu.Unlock()
+	return rows
+}
+
+// AddUpdatedRows adds updated rows.
+func (sc *StatementContext) AddUpdatedRows(rows uint64) {
+	sc.mu.Lock()
+	sc.mu.updatedRows += rows
+	sc.mu.Unlock()
+}
+
+// UpdatedRows gets updated rows.
+func (sc *StatementContext) UpdatedRows() uint64 {
+	sc.mu.Lock()
+	rows := sc.mu.updatedRows
+	sc.mu.Unlock()
+	return rows
+}
+
+// SetMessage sets the info message of the OK packet.
+func (sc *StatementContext) SetMessage(msg string) {
+	sc.mu.Lock()
+	sc.mu.message = msg
+	sc.mu.Unlock()
+}
+
+// GetMessage gets the info message of the OK packet.
+func (sc *StatementContext) GetMessage() string {
+	sc.mu.Lock()
+	msg := sc.mu.message
+	sc.mu.Unlock()
+	return msg
+}
+
+// Warning levels.
+const (
+	WarnLevelError   = "Error"
+	WarnLevelWarning = "Warning"
+)
+
+// SQLWarn relates a sql warning and its level.
+type SQLWarn struct {
+	Level string
+	Err   error
+}
+
 // GetWarnings gets warnings.
-func (sc *StatementContext) GetWarnings() []error {
+func (sc *StatementContext) GetWarnings() []SQLWarn {
 	sc.mu.Lock()
-	warns := make([]error, len(sc.mu.warnings))
+	warns := make([]SQLWarn, len(sc.mu.warnings))
 	copy(warns, sc.mu.warnings)
 	sc.mu.Unlock()
 	return warns
 }
 
-// WarningCount gets warning count.
+// WarningCount gets the warning count sent to the client. The diagnostic statements like SHOW WARNINGS
+// don't report the warnings they show.
 func (sc *StatementContext) WarningCount() uint16 {
 	if sc.InShowWarning {
 		return 0
 	}
+	return sc.TotalWarningCount()
+}
+
+// TotalWarningCount gets the number of all the errors and warnings of the statement, including the ones
+// not kept for SHOW WARNINGS. It's the value of @@warning_count.
+func (sc *StatementContext) TotalWarningCount() uint16 {
 	sc.mu.Lock()
-	wc := uint16(len(sc.mu.warnings))
+	wc := sc.mu.warningCount
 	sc.mu.Unlock()
-	return wc
+	if wc > math.MaxUint16 {
+		return math.MaxUint16
+	}
+	return uint16(wc)
 }
 
-// SetWarnings sets warnings.
-func (sc *StatementContext) SetWarnings(warns []error) {
+// ErrorCount gets the number of the errors of the statement. It's the value of @@error_count.
+func (sc *StatementContext) ErrorCount() uint16 {
+	sc.mu.Lock()
+	ec := sc.mu.errorCount
+	sc.mu.Unlock()
+	if ec > math.MaxUint16 {
+		return math.MaxUint16
+	}
+	return uint16(ec)
+}
+
+// KeepWarnings copies the warnings and the counts of the previous statement. The diagnostic statements
+// don't clear the warnings of the previous statement, so they can be shown again.
+func (sc *StatementContext) KeepWarnings(prev *StatementContext) {
+	warns := prev.GetWarnings()
+	prev.mu.Lock()
+	warningCount, errorCount := prev.mu.warningCount, prev.mu.errorCount
+	prev.mu.Unlock()
 	sc.mu.Lock()
 	sc.mu.warnings = warns
+	sc.mu.warningCount, sc.mu.errorCount = warningCount, errorCount
+	sc.mu.Unlock()
+}
+
+// MergeWarnings appends the warnings and the counts of the statement executed inside this statement, like
+// the statement of a trigger.
+func (sc *StatementContext) MergeWarnings(inner *StatementContext) {
+	warns := inner.GetWarnings()
+	inner.mu.Lock()
+	warningCount, errorCount := inner.mu.warningCount, inner.mu.errorCount
+	inner.mu.Unlock()
+	sc.mu.Lock()
+	sc.mu.warningCount += warningCount
+	sc.mu.errorCount += errorCount
+	for _, w := range warns {
+		if len(sc.mu.warnings) >= sc.MaxErrorCount {
+			break
+		}
+		sc.mu.warnings = append(sc.mu.warnings, w)
+	}
 	sc.mu.Unlock()
 }
 
 // AppendWarning appends a warning.
 func (sc *StatementContext) AppendWarning(warn error) {
+	sc.appendWarning(WarnLevelWarning, warn)
+}
+
+// AppendError appends the error which fails the statement, it's shown by SHOW WARNINGS and SHOW ERRORS.
+func (sc *StatementContext) AppendError(err error) {
+	sc.appendWarning(WarnLevelError, err)
+}
+
+func (sc *StatementContext) appendWarning(level string, err error) {
+	sc.mu.Lock()
+	sc.mu.warningCount++
+	if level == WarnLevelError {
+		sc.mu.errorCount++
+	}
+	if len(sc.mu.warnings) < sc.MaxErrorCount {
+		sc.mu.warnings = append(sc.mu.warnings, SQLWarn{Level: level, Err: err})
+	}
+	sc.mu.Unlock()
+}
+
+// MergeExecDetails adds the runtime statistics of the coprocessor requests sent by the plan.
+func (sc *StatementContext) MergeExecDetails(planID string, details execdetails.ExecDetails) {
+	if sc == nil {
+		return
+	}
+	sc.mu.Lock()
+	if sc.mu.execDetails == nil {
+		sc.mu.execDetails = make(map[string]*execdetails.ExecDetails)
+	}
+	d, ok := sc.mu.execDetails[planID]
+	if !ok {
+		d = new(execdetails.ExecDetails)
+		sc.mu.execDetails[planID] = d
+	}
+	d.Merge(details)
+	sc.mu.Unlock()
+}
+
+// GetExecDetails gets the runtime statistics of the coprocessor requests sent by the plan.
+func (sc *StatementContext) GetExecDetails(planID string) (execdetails.ExecDetails, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	d, ok := sc.mu.execDetails[planID]
+	if !ok {
+		return execdetails.ExecDetails{}, false
+	}
+	return *d, true
+}
+
+// TotalExecDetails gets the runtime statistics of all the coprocessor requests of the statement.
+func (sc *StatementContext) TotalExecDetails() execdetails.ExecDetails {
+	var total execdetails.ExecDetails
 	sc.mu.Lock()
-	if len(sc.mu.warnings) < math.MaxUint16 {
-		sc.mu.warnings = append(sc.mu.warnings, warn)
+	for _, d := range sc.mu.execDetails {
+		total.Merge(*d)
 	}
 	sc.mu.Unlock()
+	return total
 }
 
 // HandleTruncate ignores or returns the error based on the StatementContext state.
@@ -419,6 +725,14 @@ func (sc *StatementContext) ResetForRetry() {
 	sc.mu.Lock()
 	sc.mu.affectedRows = 0
 	sc.mu.foundRows = 0
+	sc.mu.recordRows = 0
+	sc.mu.duplicatedRows = 0
+	sc.mu.touchedRows = 0
+	sc.mu.updatedRows = 0
+	sc.mu.message = ""
 	sc.mu.warnings = nil
+	sc.mu.warningCount = 0
+	sc.mu.errorCount = 0
+	sc.mu.execDetails = nil
 	sc.mu.Unlock()
 }
@@ -14,7 +14,10 @@
 package variable_test
 
 import (
+	"errors"
+
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/mock"
 )
 
@@ -50,3 +53,36 @@ func (*testSessionSuite) TestSession(c *C) {
 	c.Assert(ss.FoundRows(), Equals, uint64(0))
 	c.Assert(ss.WarningCount(), Equals, uint16(0))
 }
+
+func (*testSessionSuite) TestWarnings(c *C) {
+	sc := &variable.StatementContext{MaxErrorCount: 2}
+	sc.AppendWarning(errors.New("warn1"))
+	sc.AppendWarning(errors.New("warn2"))
+	sc.AppendError(errors.New("err1"))
+	// The conditions beyond MaxErrorCount are counted but not kept.
+	c.Assert(sc.WarningCount(), Equals, uint16(3))
+	c.Assert(sc.ErrorCount(), Equals, uint16(1))
+	warns := sc.GetWarnings()
+	c.Assert(warns, HasLen, 2)
+	c.Assert(warns[0].Level, Equals, variable.WarnLevelWarning)
+	c.Assert(warns[1].Err.Error(), Equals, "warn2")
+
+	show := &variable.StatementContext{MaxErrorCount: 2, InShowWarning: true}
+	show.KeepWarnings(sc)
+	c.Assert(show.WarningCount(), Equals, uint16(0))
+	c.Assert(show.TotalWarningCount(), Equals, uint16(3))
+	c.Assert(show.ErrorCount(), Equals, uint16(1))
+	c.Assert(show.GetWarnings(), HasLen, 2)
+
+	outer := &variable.StatementContext{MaxErrorCount: 3}
+	outer.AppendWarning(errors.New("outer"))
+	outer.MergeWarnings(sc)
+	c.Assert(outer.WarningCount(), Equals, uint16(4))
+	c.Assert(outer.ErrorCount(), Equals, uint16(1))
+	c.Assert(outer.GetWarnings(), HasLen, 3)
+
+	sc.ResetForRetry()
+	c.Assert(sc.WarningCount(), Equals, uint16(0))
+	c.Assert(sc.ErrorCount(), Equals, uint16(0))
+	c.Assert(sc.GetWarnings(), HasLen, 0)
+}
@@ -159,7 +159,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeNone, "thread_stack", "262144"},
 	{ScopeGlobal, "relay_log_info_repository", "FILE"},
 	{ScopeGlobal | ScopeSession, "sql_log_bin", "ON"},
-	{ScopeGlobal, "super_read_only", ""},
+	{ScopeGlobal, SuperReadOnly, "OFF"},
 	{ScopeGlobal | ScopeSession, "max_delayed_threads", "20"},
 	{ScopeNone, "protocol_version", "10"},
 	{ScopeGlobal | ScopeSession, "new", "OFF"},
@@ -253,7 +253,9 @@ var defaultSysVars = []*SysVar{
 	{ScopeNone, "innodb_undo_tablespaces", "0"},
 	{ScopeGlobal, "innodb_status_output_locks", "OFF"},
 	{ScopeNone, "performance_schema_accounts_size", "100"},
-	{ScopeGlobal | ScopeSession, "max_error_count", "64"},
+	{ScopeGlobal | ScopeSession, MaxErrorCount, strconv.Itoa(DefMaxErrorCount)},
+	{ScopeNone, WarningCount, "0"},
+	{ScopeNone, ErrorCount, "0"},
 	{ScopeGlobal, "max_write_lock_count", "18446744073709551615"},
 	{ScopeNone, "performance_schema_max_socket_instances", "322"},
 	{ScopeNone, "performance_schema_max_table_instances", "12500"},
@@ -401,7 +403,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal, "log_bin_trust_function_creators", "OFF"},
 	{ScopeNone, "innodb_write_io_threads", "4"},
 	{ScopeGlobal, "mysql_native_password_proxy_users", ""},
-	{ScopeGlobal, "read_only", "OFF"},
+	{ScopeGlobal, ReadOnly, "OFF"},
 	{ScopeNone, "large_page_size", "0"},
 	{ScopeNone, "table_open_cache_instances", "1"},
 	{ScopeGlobal, "innodb_stats_persistent", "ON"},
@@ -599,14 +601,28 @@ var defaultSysVars = []*SysVar{
 	{ScopeSession, TiDBOptAggPushDown, boolToIntStr(DefOptAggPushDown)},
 	{ScopeSession, TiDBOptInSubqUnFolding, boolToIntStr(DefOptInSubqUnfolding)},
 	{ScopeSession, TiDBBuildStatsConcurrency, strconv.Itoa(DefBuildStatsConcurrency)},
+	{ScopeSession, TiDBLowResolutionTSO, boolToIntStr(DefLowResolutionTSO)},
 	{ScopeGlobal | ScopeSession, TiDBDistSQLScanConcurrency, strconv.Itoa(DefDistSQLScanConcurrency)},
 	{ScopeGlobal | ScopeSession, TiDBIndexLookupSize, strconv.Itoa(DefIndexLookupSize)},
 	{ScopeGlobal | ScopeSession, TiDBIndexLookupConcurrency, strconv.Itoa(DefIndexLookupConcurrency)},
+	{ScopeGlobal | ScopeSession, TiDBIndexJoinBatchSize, strconv.Itoa(DefIndexJoinBatchSize)},
 	{ScopeGlobal | ScopeSession, TiDBIndexSerialScanConcurrency, strconv.Itoa(DefIndexSerialScanConcurrency)},
 	{ScopeGlobal | ScopeSession, TiDBMaxRowCountForINLJ, strconv.Itoa(DefMaxRowCountForINLJ)},
 	{ScopeGlobal | ScopeSession, TiDBSkipDDLWait, boolToIntStr(DefSkipDDLWait)},
 	{ScopeGlobal | ScopeSession, TiDBSkipUTF8Check, boolToIntStr(DefSkipUTF8Check)},
+	{ScopeGlobal | ScopeSession, TiDBIgnoreTrigger, boolToIntStr(DefIgnoreTrigger)},
+	{ScopeGlobal | ScopeSession, TiDBEnableClusteredIndex, boolToIntStr(DefEnableClusteredIndex)},
+	{ScopeGlobal | ScopeSession, TiDBIndexScanDirection, DefIndexScanDirection},
+	{ScopeGlobal | ScopeSession, TiDBHashJoinMemQuota, strconv.Itoa(DefHashJoinMemQuota)},
+	{ScopeGlobal | ScopeSession, TiDBStmtMaxExaminedRows, strconv.Itoa(DefStmtMaxExaminedRows)},
+	{ScopeGlobal | ScopeSession, TiDBStmtMaxReturnedRows, strconv.Itoa(DefStmtMaxReturnedRows)},
+	{ScopeGlobal | ScopeSession, TiDBStmtMemQuota, strconv.Itoa(DefStmtMemQuota)},
+	{ScopeGlobal | ScopeSession, TiDBUnionConcurrency, strconv.Itoa(DefUnionConcurrency)},
+	{ScopeGlobal | ScopeSession, TiDBSortMemQuota, strconv.Itoa(DefSortMemQuota)},
 	{ScopeSession, TiDBBatchInsert, boolToIntStr(DefBatchInsert)},
+	{ScopeSession, TiDBBulkLoad, boolToIntStr(DefBulkLoad)},
+	{ScopeSession, TiDBTxnEntryCountLimit, strconv.Itoa(DefTxnEntryCountLimit)},
+	{ScopeSession, TiDBTxnTotalSizeLimit, strconv.Itoa(DefTxnTotalSizeLimit)},
 }
 
 // SetNamesVariables is the system variable names related to set names statements.
@@ -623,6 +639,10 @@ const (
 	CharsetDatabase = "character_set_database"
 	// CollationDatabase is the name for collation_database system variable.
 	CollationDatabase = "collation_database"
+	// ReadOnly is the name for read_only system variable.
+	ReadOnly = "read_only"
+	// SuperReadOnly is the name for super_read_only system variable.
+	SuperReadOnly = "super_read_only"
 )
 
 // GlobalVarAccessor is the interface for accessing global scope system and status variables.
@@ -36,3 +36,19 @@ func (*testSysVarSuite) TestSysVar(c *C) {
 	f = GetSysVar("wrong-var-name")
 	c.Assert(f, IsNil)
 }
+
+func (*testSysVarSuite) TestReadOnly(c *C) {
+	defer func() {
+		SetReadOnly(ReadOnly, "OFF")
+		SetReadOnly(SuperReadOnly, "OFF")
+	}()
+	c.Assert(IsReadOnly(), IsFalse)
+	SetReadOnly(ReadOnly, "on")
+	c.Assert(IsReadOnly(), IsTrue)
+	c.Assert(IsSuperReadOnly(), IsFalse)
+	SetReadOnly(ReadOnly, "0")
+	c.Assert(IsReadOnly(), IsFalse)
+	SetReadOnly(SuperReadOnly, "1")
+	c.Assert(IsReadOnly(), IsTrue)
+	c.Assert(IsSuperReadOnly(), IsTrue)
+}
@@ -13,6 +13,8 @@
 
 package variable
 
+import "strings"
+
 /*
 	Steps to add a new TiDB specific system variable:
 
@@ -48,6 +50,11 @@ const (
 	// those indices can be scanned concurrently, with the cost of higher system performance impact.
 	TiDBBuildStatsConcurrency = "tidb_build_stats_concurrency"
 
+	// tidb_low_resolution_tso makes the statements in autocommit mode read at a recently fetched timestamp instead
+	// of requesting a new one, which saves a round trip to PD, but the data committed in the last few seconds may be
+	// missed. The write statements are rejected when it's on.
+	TiDBLowResolutionTSO = "tidb_low_resolution_tso"
+
 	/* Session and global */
 
 	// tidb_distsql_scan_concurrency is used to set the concurrency of a distsql scan task.
@@ -70,6 +77,12 @@ const (
 	// Set this value higher may reduce the latency but consumes more system resource.
 	TiDBIndexLookupConcurrency = "tidb_index_lookup_concurrency"
 
+	// tidb_index_join_batch_size is used for index lookup join executor.
+	// The index lookup join executor reads a batch of the outer rows, then looks up the inner rows by their join keys,
+	// this value controls how many outer rows are in a batch.
+	// Small value sends more RPCs to TiKV, large value consumes more memory to hold the rows of a batch.
+	TiDBIndexJoinBatchSize = "tidb_index_join_batch_size"
+
 	// tidb_index_serial_scan_concurrency is used for controlling the concurrency of index scan operation
 	// when we need to keep the data output order the same as the order of index data.
 	TiDBIndexSerialScanConcurrency = "tidb_index_serial_scan_concurrency"
@@ -88,10 +101,57 @@ const (
 	// insert data into multiple batches and use a single txn for each batch. This will be helpful when inserting large data.
 	TiDBBatchInsert = "tidb_batch_insert"
 
+	// tidb_bulk_load is used to enable/disable bulk loading for load data statements. If set this option on, the
+	// data is encoded and written to the storage in large batches, beneath the transactions if the storage supports it,
+	// and the table is locked against other writes on this server during loading. It's designed for the initial data
+	// migration into an empty table, the table is cleaned up if the loading fails, see bulkload.Loader.
+	TiDBBulkLoad = "tidb_bulk_load"
+
+	// tidb_txn_entry_count_limit and tidb_txn_total_size_limit raise or lower the limits of the entry count and the total
+	// size in bytes of the transactions in the session, 0 means the server-wide limits. Large transactions take long to
+	// commit and hold the locks for long, so the limits should only be raised for the sessions that really need it.
+	TiDBTxnEntryCountLimit = "tidb_txn_entry_count_limit"
+	TiDBTxnTotalSizeLimit  = "tidb_txn_total_size_limit"
+
 	// tidb_max_row_count_for_inlj is used when do index nested loop join.
 	// It controls the max row count of outer table when do index nested loop join without hint.
 	// After the row count of the inner table is accurate, this variable will be removed.
 	TiDBMaxRowCountForINLJ = "tidb_max_row_count_for_inlj"
+
+	// tidb_ignore_trigger makes CREATE TRIGGER and DROP TRIGGER statements succeed without doing anything.
+	// It's useful when importing dumps from MySQL whose triggers are not expected to run on TiDB.
+	TiDBIgnoreTrigger = "tidb_ignore_trigger"
+
+	// tidb_enable_clustered_index makes the primary keys of the new tables that are not the handle clustered,
+	// the whole row is stored in the primary key entries too, so a row is read by its primary key in one get
+	// instead of an index lookup. The tables still have the hidden row ID, the rows take about twice the space.
+	TiDBEnableClusteredIndex = "tidb_enable_clustered_index"
+
+
+	// tidb_index_scan_direction forces the direction of the ordered table and index scans, it's AUTO, ASC or DESC.
+	// DESC always reads the rows backward from a matched index for ORDER BY ... DESC, instead of sorting them.
+	// ASC never reads backward, the rows are sorted for ORDER BY ... DESC. AUTO chooses by the costs.
+	TiDBIndexScanDirection = "tidb_index_scan_direction"
+
+	// tidb_hash_join_mem_quota is the memory quota in bytes of the build side of a hash join, 0 means no quota.
+	// When the build side exceeds it, both sides are partitioned to the temporary files and joined partition by
+	// partition, which is slower but keeps the memory bounded.
+	TiDBHashJoinMemQuota = "tidb_hash_join_mem_quota"
+
+	// tidb_stmt_max_examined_rows, tidb_stmt_max_returned_rows and tidb_stmt_mem_quota limit the rows read from the
+	// storage, the rows returned to the client and the bytes of the buffered coprocessor responses of every statement,
+	// 0 means no limit. The statements exceeding them are aborted, which protects a shared cluster from rogue queries.
+	TiDBStmtMaxExaminedRows = "tidb_stmt_max_examined_rows"
+	TiDBStmtMaxReturnedRows = "tidb_stmt_max_returned_rows"
+	TiDBStmtMemQuota        = "tidb_stmt_mem_quota"
+
+	// tidb_union_concurrency is the max number of the branches of a UNION executed at the same time.
+	TiDBUnionConcurrency = "tidb_union_concurrency"
+
+	// tidb_sort_mem_quota is the memory quota in bytes of the rows sorted by a sort executor, 0 means no quota.
+	// When the rows exceed it, they are sorted and written to a temporary file as a run, and the runs are merged
+	// after all the rows are read, which is slower but keeps the memory bounded.
+	TiDBSortMemQuota = "tidb_sort_mem_quota"
 )
 
 // Default TiDB system variable values.
@@ -99,6 +159,7 @@ const (
 	DefIndexLookupConcurrency     = 4
 	DefIndexSerialScanConcurrency = 1
 	DefIndexLookupSize            = 20000
+	DefIndexJoinBatchSize         = 25000
 	DefDistSQLScanConcurrency     = 10
 	DefBuildStatsConcurrency      = 4
 	DefMaxRowCountForINLJ         = 128
@@ -107,4 +168,52 @@ const (
 	DefOptAggPushDown             = true
 	DefOptInSubqUnfolding         = false
 	DefBatchInsert                = false
+	DefBulkLoad                   = false
+	DefTxnEntryCountLimit         = 0
+	DefTxnTotalSizeLimit          = 0
+	DefIgnoreTrigger              = false
+	DefEnableClusteredIndex       = false
+	DefIndexScanDirection         = "AUTO"
+	DefHashJoinMemQuota           = 1 << 30
+	DefStmtMaxExaminedRows        = 0
+	DefStmtMaxReturnedRows        = 0
+	DefStmtMemQuota               = 0
+	DefUnionConcurrency           = 4
+	DefSortMemQuota               = 1 << 30
+	DefLowResolutionTSO           = false
 )
+
+// ScanDirection is the direction of the ordered table and index scans forced by the hints or the variable.
+type ScanDirection int
+
+// Scan directions.
+const (
+	// ScanDirectionAuto chooses the direction by the costs.
+	ScanDirectionAuto ScanDirection = iota
+	// ScanDirectionAsc never scans backward.
+	ScanDirectionAsc
+	// ScanDirectionDesc always scans backward if the rows are required in descending order.
+	ScanDirectionDesc
+)
+
+// String implements the fmt.Stringer interface.
+func (d ScanDirection) String() string {
+	switch d {
+	case ScanDirectionAsc:
+		return "ASC"
+	case ScanDirectionDesc:
+		return "DESC"
+	}
+	return "AUTO"
+}
+
+// ParseScanDirection parses the value of tidb_index_scan_direction, an unknown value is AUTO.
+func ParseScanDirection(s string) ScanDirection {
+	switch strings.ToUpper(s) {
+	case "ASC":
+		return ScanDirectionAsc
+	case "DESC":
+		return ScanDirectionDesc
+	}
+	return ScanDirectionAuto
+}
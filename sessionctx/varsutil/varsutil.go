@@ -14,6 +14,7 @@
 package varsutil
 
 import (
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -33,6 +34,12 @@ func GetSessionSystemVar(s *variable.SessionVars, key string) (string, error) {
 	if sysVar == nil {
 		return "", variable.UnknownSystemVar.GenByArgs(key)
 	}
+	switch key {
+	case variable.WarningCount:
+		return strconv.Itoa(int(s.PrevWarningCount)), nil
+	case variable.ErrorCount:
+		return strconv.Itoa(int(s.PrevErrorCount)), nil
+	}
 	sVal, ok := s.Systems[key]
 	if ok {
 		return sVal, nil
@@ -87,7 +94,7 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 	}
 	switch name {
 	case variable.TimeZone:
-		vars.TimeZone, err = parseTimeZone(sVal)
+		vars.TimeZone, err = ParseTimeZone(sVal)
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -117,12 +124,26 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 		if isAutocommit {
 			vars.SetStatusFlag(mysql.ServerStatusInTrans, false)
 		}
+	case variable.MaxErrorCount:
+		vars.MaxErrorCount, err = strconv.Atoi(sVal)
+		if err != nil {
+			vars.MaxErrorCount = variable.DefMaxErrorCount
+		} else if vars.MaxErrorCount < 0 {
+			vars.MaxErrorCount = 0
+		} else if vars.MaxErrorCount > math.MaxUint16 {
+			vars.MaxErrorCount = math.MaxUint16
+		}
+		sVal = strconv.Itoa(vars.MaxErrorCount)
 	case variable.TiDBSkipConstraintCheck:
 		vars.SkipConstraintCheck = tidbOptOn(sVal)
 	case variable.TiDBSkipUTF8Check:
 		vars.SkipUTF8Check = tidbOptOn(sVal)
 	case variable.TiDBSkipDDLWait:
 		vars.SkipDDLWait = tidbOptOn(sVal)
+	case variable.TiDBIgnoreTrigger:
+		vars.IgnoreTrigger = tidbOptOn(sVal)
+	case variable.TiDBEnableClusteredIndex:
+		vars.EnableClusteredIndex = tidbOptOn(sVal)
 	case variable.TiDBOptAggPushDown:
 		vars.AllowAggPushDown = tidbOptOn(sVal)
 	case variable.TiDBOptInSubqUnFolding:
@@ -131,14 +152,46 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 		vars.IndexLookupConcurrency = tidbOptPositiveInt(sVal, variable.DefIndexLookupConcurrency)
 	case variable.TiDBIndexLookupSize:
 		vars.IndexLookupSize = tidbOptPositiveInt(sVal, variable.DefIndexLookupSize)
+	case variable.TiDBIndexJoinBatchSize:
+		vars.IndexJoinBatchSize = tidbOptPositiveInt(sVal, variable.DefIndexJoinBatchSize)
 	case variable.TiDBDistSQLScanConcurrency:
 		vars.DistSQLScanConcurrency = tidbOptPositiveInt(sVal, variable.DefDistSQLScanConcurrency)
 	case variable.TiDBIndexSerialScanConcurrency:
 		vars.IndexSerialScanConcurrency = tidbOptPositiveInt(sVal, variable.DefIndexSerialScanConcurrency)
 	case variable.TiDBBatchInsert:
 		vars.BatchInsert = tidbOptOn(sVal)
+	case variable.TiDBBulkLoad:
+		vars.BulkLoad = tidbOptOn(sVal)
+	case variable.TiDBTxnEntryCountLimit:
+		vars.TxnEntryCountLimit = uint64(tidbOptPositiveInt(sVal, variable.DefTxnEntryCountLimit))
+		sVal = strconv.FormatUint(vars.TxnEntryCountLimit, 10)
+	case variable.TiDBTxnTotalSizeLimit:
+		vars.TxnTotalSizeLimit = tidbOptPositiveInt(sVal, variable.DefTxnTotalSizeLimit)
+		sVal = strconv.Itoa(vars.TxnTotalSizeLimit)
 	case variable.TiDBMaxRowCountForINLJ:
 		vars.MaxRowCountForINLJ = tidbOptPositiveInt(sVal, variable.DefMaxRowCountForINLJ)
+	case variable.TiDBIndexScanDirection:
+		vars.IndexScanDirection = variable.ParseScanDirection(sVal)
+		sVal = vars.IndexScanDirection.String()
+	case variable.TiDBHashJoinMemQuota:
+		vars.HashJoinMemQuota = tidbOptNonNegativeInt(sVal, variable.DefHashJoinMemQuota)
+		sVal = strconv.Itoa(vars.HashJoinMemQuota)
+	case variable.TiDBStmtMaxExaminedRows:
+		vars.StmtLimits.MaxExaminedRows = int64(tidbOptNonNegativeInt(sVal, variable.DefStmtMaxExaminedRows))
+		sVal = strconv.FormatInt(vars.StmtLimits.MaxExaminedRows, 10)
+	case variable.TiDBStmtMaxReturnedRows:
+		vars.StmtLimits.MaxReturnedRows = int64(tidbOptNonNegativeInt(sVal, variable.DefStmtMaxReturnedRows))
+		sVal = strconv.FormatInt(vars.StmtLimits.MaxReturnedRows, 10)
+	case variable.TiDBStmtMemQuota:
+		vars.StmtLimits.MemQuota = int64(tidbOptNonNegativeInt(sVal, variable.DefStmtMemQuota))
+		sVal = strconv.FormatInt(vars.StmtLimits.MemQuota, 10)
+	case variable.TiDBUnionConcurrency:
+		vars.UnionConcurrency = tidbOptPositiveInt(sVal, variable.DefUnionConcurrency)
+	case variable.TiDBSortMemQuota:
+		vars.SortMemQuota = tidbOptNonNegativeInt(sVal, variable.DefSortMemQuota)
+		sVal = strconv.Itoa(vars.SortMemQuota)
+	case variable.TiDBLowResolutionTSO:
+		vars.LowResolutionTSO = tidbOptOn(sVal)
 	}
 	vars.Systems[name] = sVal
 	return nil
@@ -157,32 +210,67 @@ func tidbOptPositiveInt(opt string, defaultVal int) int {
 	return val
 }
 
-func parseTimeZone(s string) (*time.Location, error) {
-	if s == "SYSTEM" {
-		// TODO: Support global time_zone variable, it should be set to global time_zone value.
-		return time.Local, nil
+func tidbOptNonNegativeInt(opt string, defaultVal int) int {
+	val, err := strconv.Atoi(opt)
+	if err != nil || val < 0 {
+		return defaultVal
 	}
+	return val
+}
 
-	loc, err := time.LoadLocation(s)
-	if err == nil {
-		return loc, nil
+// ParseTimeZone parses the value of the time_zone variable, which is also the
+// time zone format accepted by CONVERT_TZ. The value can be 'SYSTEM', a named
+// time zone such as 'Europe/Helsinki', or an offset from UTC such as '+10:00'.
+// See https://dev.mysql.com/doc/refman/5.7/en/time-zone-support.html
+func ParseTimeZone(s string) (*time.Location, error) {
+	if strings.EqualFold(s, "SYSTEM") {
+		return time.Local, nil
 	}
 
 	// The value can be given as a string indicating an offset from UTC, such as '+10:00' or '-6:00'.
 	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
-		d, err := types.ParseDuration(s[1:], 0)
-		if err == nil {
-			ofst := int(d.Duration / time.Second)
-			if s[0] == '-' {
-				ofst = -ofst
-			}
-			return time.FixedZone("UTC", ofst), nil
+		ofst, ok := parseTimeZoneOffset(s)
+		if !ok {
+			return nil, variable.ErrUnknownTimeZone.GenByArgs(s)
 		}
+		return time.FixedZone("UTC", ofst), nil
 	}
 
+	loc, err := time.LoadLocation(s)
+	if err == nil && s != "" && s != "Local" {
+		return loc, nil
+	}
 	return nil, variable.ErrUnknownTimeZone.GenByArgs(s)
 }
 
+// parseTimeZoneOffset parses an offset like '+10:00' to seconds east of UTC.
+// The permitted range is '-12:59' to '+13:00'.
+func parseTimeZoneOffset(s string) (int, bool) {
+	seps := strings.Split(s[1:], ":")
+	if len(seps) != 2 || len(seps[0]) == 0 || len(seps[0]) > 2 || len(seps[1]) != 2 {
+		return 0, false
+	}
+	hour, err := strconv.Atoi(seps[0])
+	if err != nil || hour < 0 {
+		return 0, false
+	}
+	minute, err := strconv.Atoi(seps[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, false
+	}
+	ofst := hour*3600 + minute*60
+	if s[0] == '-' {
+		if ofst > 12*3600+59*60 {
+			return 0, false
+		}
+		return -ofst, true
+	}
+	if ofst > 13*3600 {
+		return 0, false
+	}
+	return ofst, true
+}
+
 func setSnapshotTS(s *variable.SessionVars, sVal string) error {
 	if sVal == "" {
 		s.SnapshotTS = 0
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/resourcegroup"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -124,6 +125,7 @@ func (s *testVarsutilSuite) TestVarsutil(c *C) {
 		{"Europe/Helsinki", "Europe/Helsinki", true, -2 * time.Hour},
 		{"US/Eastern", "US/Eastern", true, 5 * time.Hour},
 		{"SYSTEM", "Local", false, 0},
+		{"system", "Local", false, 0},
 		{"+10:00", "UTC", true, -10 * time.Hour},
 		{"-6:00", "UTC", true, 6 * time.Hour},
 	}
@@ -138,9 +140,10 @@ func (s *testVarsutilSuite) TestVarsutil(c *C) {
 			c.Assert(t2.Sub(t1), Equals, tt.diff)
 		}
 	}
-	err = SetSessionSystemVar(v, variable.TimeZone, types.NewStringDatum("6:00"))
-	c.Assert(err, NotNil)
-	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownTimeZone), IsTrue)
+	for _, tz := range []string{"6:00", "+13:01", "-13:00", "+10:60", "+-1:00", "abc", ""} {
+		err = SetSessionSystemVar(v, variable.TimeZone, types.NewStringDatum(tz))
+		c.Assert(terror.ErrorEqual(err, variable.ErrUnknownTimeZone), IsTrue, Commentf("%s", tz))
+	}
 
 	// Test case for sql mode.
 	for str, mode := range mysql.Str2SQLMode {
@@ -162,10 +165,83 @@ func (s *testVarsutilSuite) TestVarsutil(c *C) {
 	SetSessionSystemVar(v, variable.TiDBBatchInsert, types.NewStringDatum("1"))
 	c.Assert(v.BatchInsert, IsTrue)
 
+	// Test case for tidb_bulk_load.
+	c.Assert(v.BulkLoad, IsFalse)
+	SetSessionSystemVar(v, variable.TiDBBulkLoad, types.NewStringDatum("ON"))
+	c.Assert(v.BulkLoad, IsTrue)
+
+	// Test case for tidb_ignore_trigger.
+	c.Assert(v.IgnoreTrigger, IsFalse)
+	SetSessionSystemVar(v, variable.TiDBIgnoreTrigger, types.NewStringDatum("1"))
+	c.Assert(v.IgnoreTrigger, IsTrue)
+
+	// Test case for tidb_txn_entry_count_limit and tidb_txn_total_size_limit.
+	c.Assert(v.TxnEntryCountLimit, Equals, uint64(0))
+	SetSessionSystemVar(v, variable.TiDBTxnEntryCountLimit, types.NewStringDatum("1000000"))
+	c.Assert(v.TxnEntryCountLimit, Equals, uint64(1000000))
+	SetSessionSystemVar(v, variable.TiDBTxnEntryCountLimit, types.NewStringDatum("-1"))
+	c.Assert(v.TxnEntryCountLimit, Equals, uint64(0))
+	c.Assert(v.Systems[variable.TiDBTxnEntryCountLimit], Equals, "0")
+	c.Assert(v.TxnTotalSizeLimit, Equals, 0)
+	SetSessionSystemVar(v, variable.TiDBTxnTotalSizeLimit, types.NewStringDatum("209715200"))
+	c.Assert(v.TxnTotalSizeLimit, Equals, 209715200)
+
 	//Test case for tidb_max_row_count_for_inlj.
 	c.Assert(v.MaxRowCountForINLJ, Equals, 128)
 	SetSessionSystemVar(v, variable.TiDBMaxRowCountForINLJ, types.NewStringDatum("127"))
 	c.Assert(v.MaxRowCountForINLJ, Equals, 127)
+
+	// Test case for tidb_index_join_batch_size.
+	c.Assert(v.IndexJoinBatchSize, Equals, variable.DefIndexJoinBatchSize)
+	SetSessionSystemVar(v, variable.TiDBIndexJoinBatchSize, types.NewStringDatum("100"))
+	c.Assert(v.IndexJoinBatchSize, Equals, 100)
+	SetSessionSystemVar(v, variable.TiDBIndexJoinBatchSize, types.NewStringDatum("0"))
+	c.Assert(v.IndexJoinBatchSize, Equals, variable.DefIndexJoinBatchSize)
+
+	// Test case for tidb_index_scan_direction.
+	c.Assert(v.IndexScanDirection, Equals, variable.ScanDirectionAuto)
+	SetSessionSystemVar(v, variable.TiDBIndexScanDirection, types.NewStringDatum("desc"))
+	c.Assert(v.IndexScanDirection, Equals, variable.ScanDirectionDesc)
+	c.Assert(v.Systems[variable.TiDBIndexScanDirection], Equals, "DESC")
+	SetSessionSystemVar(v, variable.TiDBIndexScanDirection, types.NewStringDatum("Asc"))
+	c.Assert(v.IndexScanDirection, Equals, variable.ScanDirectionAsc)
+	SetSessionSystemVar(v, variable.TiDBIndexScanDirection, types.NewStringDatum("backward"))
+	c.Assert(v.IndexScanDirection, Equals, variable.ScanDirectionAuto)
+
+	c.Assert(v.HashJoinMemQuota, Equals, variable.DefHashJoinMemQuota)
+	SetSessionSystemVar(v, variable.TiDBHashJoinMemQuota, types.NewStringDatum("0"))
+	c.Assert(v.HashJoinMemQuota, Equals, 0)
+	SetSessionSystemVar(v, variable.TiDBHashJoinMemQuota, types.NewStringDatum("1048576"))
+	c.Assert(v.HashJoinMemQuota, Equals, 1048576)
+	SetSessionSystemVar(v, variable.TiDBHashJoinMemQuota, types.NewStringDatum("-1"))
+	c.Assert(v.HashJoinMemQuota, Equals, variable.DefHashJoinMemQuota)
+
+	c.Assert(v.StmtLimits, Equals, resourcegroup.StmtLimits{})
+	SetSessionSystemVar(v, variable.TiDBStmtMaxExaminedRows, types.NewStringDatum("1000"))
+	SetSessionSystemVar(v, variable.TiDBStmtMaxReturnedRows, types.NewStringDatum("100"))
+	SetSessionSystemVar(v, variable.TiDBStmtMemQuota, types.NewStringDatum("1048576"))
+	c.Assert(v.StmtLimits, Equals, resourcegroup.StmtLimits{MaxExaminedRows: 1000, MaxReturnedRows: 100, MemQuota: 1048576})
+	SetSessionSystemVar(v, variable.TiDBStmtMaxExaminedRows, types.NewStringDatum("-1"))
+	c.Assert(v.StmtLimits.MaxExaminedRows, Equals, int64(0))
+	c.Assert(v.Systems[variable.TiDBStmtMaxExaminedRows], Equals, "0")
+
+	c.Assert(v.UnionConcurrency, Equals, variable.DefUnionConcurrency)
+	SetSessionSystemVar(v, variable.TiDBUnionConcurrency, types.NewStringDatum("16"))
+	c.Assert(v.UnionConcurrency, Equals, 16)
+	SetSessionSystemVar(v, variable.TiDBUnionConcurrency, types.NewStringDatum("0"))
+	c.Assert(v.UnionConcurrency, Equals, variable.DefUnionConcurrency)
+
+	c.Assert(v.SortMemQuota, Equals, variable.DefSortMemQuota)
+	SetSessionSystemVar(v, variable.TiDBSortMemQuota, types.NewStringDatum("0"))
+	c.Assert(v.SortMemQuota, Equals, 0)
+	SetSessionSystemVar(v, variable.TiDBSortMemQuota, types.NewStringDatum("-1"))
+	c.Assert(v.SortMemQuota, Equals, variable.DefSortMemQuota)
+
+	c.Assert(v.LowResolutionTSO, IsFalse)
+	SetSessionSystemVar(v, variable.TiDBLowResolutionTSO, types.NewStringDatum("on"))
+	c.Assert(v.LowResolutionTSO, IsTrue)
+	SetSessionSystemVar(v, variable.TiDBLowResolutionTSO, types.NewStringDatum("0"))
+	c.Assert(v.LowResolutionTSO, IsFalse)
 }
 
 type mockGlobalAccessor struct {
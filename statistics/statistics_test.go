@@ -209,3 +209,17 @@ func (s *testStatisticsSuite) TestPseudoTable(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(int(count), Equals, 250000)
 }
+
+func (s *testStatisticsSuite) TestEqualRowCountPerKey(c *C) {
+	tbl := PseudoTable(1)
+	c.Assert(int(tbl.EqualRowCountPerKey(1, 1)), Equals, 10000)
+
+	tbl = &Table{Count: 10000, Indices: make(map[int64]*Index)}
+	tbl.Indices[1] = &Index{Histogram: Histogram{ID: 1, NDV: 10000}, NumColumns: 2}
+	c.Assert(int(tbl.EqualRowCountPerKey(1, 2)), Equals, 1)
+	c.Assert(int(tbl.EqualRowCountPerKey(1, 1)), Equals, 100)
+	tbl.Indices[1].NDV = 20000
+	c.Assert(int(tbl.EqualRowCountPerKey(1, 2)), Equals, 1)
+	// The index is not analyzed.
+	c.Assert(int(tbl.EqualRowCountPerKey(2, 1)), Equals, 10)
+}
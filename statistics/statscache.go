@@ -95,6 +95,7 @@ func (h *Handle) Update(is infoschema.InfoSchema) error {
 			deletedTableIDs = append(deletedTableIDs, tableID)
 			continue
 		}
+		tbl.Version = version
 		tables = append(tables, tbl)
 		h.LastVersion = version
 	}
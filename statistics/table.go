@@ -46,6 +46,8 @@ type Table struct {
 	Indices map[int64]*Index
 	Count   int64 // Total row count in a table.
 	Pseudo  bool
+	// Version is the version of the stats meta of the table when the stats are loaded.
+	Version uint64
 }
 
 func (t *Table) copy() *Table {
@@ -53,6 +55,7 @@ func (t *Table) copy() *Table {
 		TableID: t.TableID,
 		Count:   t.Count,
 		Pseudo:  t.Pseudo,
+		Version: t.Version,
 		Columns: make(map[int64]*Column),
 		Indices: make(map[int64]*Index),
 	}
@@ -213,6 +216,20 @@ func (t *Table) GetRowCountByIndexRanges(sc *variable.StatementContext, idxID in
 	return idx.getRowCount(sc, indexRanges, inAndEQCnt)
 }
 
+// EqualRowCountPerKey estimates the average row count of a value of the first eqCount columns of the index.
+func (t *Table) EqualRowCountPerKey(idxID int64, eqCount int) float64 {
+	idx := t.Indices[idxID]
+	if t.Pseudo || idx == nil || idx.NDV <= 0 {
+		return float64(t.Count) / pseudoEqualRate
+	}
+	ndv := float64(idx.NDV)
+	if eqCount < idx.NumColumns {
+		// We only have the NDV of all the columns, assume the columns are independent of each other.
+		ndv = math.Pow(ndv, float64(eqCount)/float64(idx.NumColumns))
+	}
+	return math.Max(float64(t.Count)/ndv, 1)
+}
+
 // PseudoTable creates a pseudo table statistics when statistic can not be found in KV store.
 func PseudoTable(tableID int64) *Table {
 	t := &Table{TableID: tableID, Pseudo: true}
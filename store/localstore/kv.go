@@ -25,11 +25,13 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/localstore/engine"
 	"github.com/pingcap/tidb/util/segmentmap"
+	"github.com/pingcap/tidb/util/txnconflict"
 	"github.com/twinj/uuid"
 )
 
 var (
-	_ kv.Storage = (*dbStore)(nil)
+	_ kv.Storage    = (*dbStore)(nil)
+	_ kv.BulkLoader = (*dbStore)(nil)
 )
 
 const (
@@ -119,9 +121,10 @@ func (s *dbStore) tryLock(txn *dbTxn) (err error) {
 		if !ok {
 			continue
 		}
-		// If there's newer version of this key, returns error.
+		// If there's newer version of this key, returns error. The start ts of the transaction
+		// which commits the newer version isn't kept.
 		if lastVer.(kv.Version).Cmp(kv.Version{Ver: txn.tid}) > 0 {
-			return errors.Trace(kv.ErrConditionNotMatch)
+			return errors.Trace(txnconflict.Report(txn.tid, 0, lastVer.(kv.Version).Ver, []byte(k)))
 		}
 	}
 
@@ -133,7 +136,9 @@ func (s *dbStore) tryLock(txn *dbTxn) (err error) {
 	return nil
 }
 
-func (s *dbStore) doCommit(txn *dbTxn) error {
+// beginCommit waits for the other committing to finish and allocates a commit version,
+// endCommit must be called after the commit is done.
+func (s *dbStore) beginCommit() (kv.Version, error) {
 	var commitVer kv.Version
 	var err error
 	for {
@@ -145,7 +150,7 @@ func (s *dbStore) doCommit(txn *dbTxn) error {
 			commitVer, err = globalVersionProvider.CurrentVersion()
 			if err != nil {
 				s.mu.Unlock()
-				return errors.Trace(err)
+				return commitVer, errors.Trace(err)
 			}
 			s.committingTS = commitVer.Ver
 			s.wg.Add(1)
@@ -153,20 +158,29 @@ func (s *dbStore) doCommit(txn *dbTxn) error {
 		s.mu.Unlock()
 
 		if closed {
-			return ErrDBClosed
+			return commitVer, ErrDBClosed
 		}
 		if committing {
 			time.Sleep(time.Microsecond)
 			continue
 		}
-		break
+		return commitVer, nil
 	}
-	defer func() {
-		s.mu.Lock()
-		s.committingTS = 0
-		s.wg.Done()
-		s.mu.Unlock()
-	}()
+}
+
+func (s *dbStore) endCommit() {
+	s.mu.Lock()
+	s.committingTS = 0
+	s.wg.Done()
+	s.mu.Unlock()
+}
+
+func (s *dbStore) doCommit(txn *dbTxn) error {
+	commitVer, err := s.beginCommit()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer s.endCommit()
 	// Here we are sure no concurrent committing happens.
 	err = s.tryLock(txn)
 	if err != nil {
@@ -208,6 +222,42 @@ func (s *dbStore) doCommit(txn *dbTxn) error {
 	return nil
 }
 
+// BulkLoad implements the kv.BulkLoader interface.
+func (s *dbStore) BulkLoad(buf kv.MemBuffer) (kv.Version, error) {
+	commitVer, err := s.beginCommit()
+	if err != nil {
+		return commitVer, errors.Trace(err)
+	}
+	defer s.endCommit()
+	it, err := buf.Seek(nil)
+	if err != nil {
+		return commitVer, errors.Trace(err)
+	}
+	defer it.Close()
+	b := s.db.NewBatch()
+	var keys []kv.Key
+	for ; it.Valid(); err = it.Next() {
+		if err != nil {
+			return commitVer, errors.Trace(err)
+		}
+		b.Put(MvccEncodeVersionKey(it.Key(), commitVer), it.Value())
+		s.compactor.OnSet(it.Key())
+		keys = append(keys, it.Key().Clone())
+	}
+	if err != nil {
+		return commitVer, errors.Trace(err)
+	}
+	if err = s.writeBatch(b); err != nil {
+		return commitVer, errors.Trace(err)
+	}
+	// Record the keys as updated, so the transactions started before writing them conflict, as
+	// if the keys were written by a transaction.
+	for _, k := range keys {
+		s.recentUpdates.Set([]byte(k), commitVer, true)
+	}
+	return commitVer, nil
+}
+
 func (s *dbStore) NewBatch() engine.Batch {
 	return s.db.NewBatch()
 }
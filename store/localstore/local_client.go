@@ -44,6 +44,8 @@ func (c *dbClient) IsRequestTypeSupported(reqType, subType int64) bool {
 		default:
 			return supportExpr(tipb.ExprType(subType))
 		}
+	case kv.ReqTypeChecksum:
+		return true
 	}
 	return false
 }
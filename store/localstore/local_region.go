@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"container/heap"
 	"encoding/binary"
+	"encoding/json"
 	"sort"
 	"time"
 
@@ -281,6 +282,12 @@ func (rs *localRegion) Handle(req *regionRequest) (*regionResponse, error) {
 			return nil, errors.Trace(err)
 		}
 		resp.data = data
+	} else if req.Tp == kv.ReqTypeChecksum {
+		data, err := rs.handleChecksum(req)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		resp.data = data
 	}
 	if bytes.Compare(rs.startKey, req.startKey) < 0 || bytes.Compare(rs.endKey, req.endKey) > 0 {
 		resp.newStartKey = rs.startKey
@@ -289,6 +296,47 @@ func (rs *localRegion) Handle(req *regionRequest) (*regionResponse, error) {
 	return resp, nil
 }
 
+// handleChecksum computes the digests or the entries of a checksum request in the region.
+func (rs *localRegion) handleChecksum(req *regionRequest) ([]byte, error) {
+	checksumReq := new(distsql.ChecksumRequest)
+	err := json.Unmarshal(req.data, checksumReq)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	builder := distsql.NewChecksumBuilder(checksumReq)
+	txn := newTxn(rs.store, kv.Version{Ver: checksumReq.StartTs})
+	for _, ran := range rs.extractKVRanges(&selectContext{keyRanges: req.ranges}) {
+		seekKey := ran.StartKey
+		for {
+			it, err := txn.Seek(seekKey)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if !it.Valid() || it.Key().Cmp(ran.EndKey) >= 0 {
+				break
+			}
+			seekKey = it.Key().PrefixNext()
+			if builder.ScanIndex() {
+				err = builder.AddIndex(it.Key(), it.Value())
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				continue
+			}
+			h, err := tablecodec.DecodeRowKey(it.Key())
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			err = builder.AddRow(h, it.Value())
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+	}
+	data, err := json.Marshal(builder.Response())
+	return data, errors.Trace(err)
+}
+
 func (rs *localRegion) setTopNDataForCtx(ctx *selectContext) {
 	sort.Sort(&ctx.topnHeap.topnSorter)
 	for _, row := range ctx.topnHeap.rows {
@@ -425,3 +425,24 @@ func decodeInt(s []byte) int {
 	fmt.Sscanf(string(s), "%010d", &n)
 	return n
 }
+
+func (t *testMvccSuite) TestBulkLoadConflict(c *C) {
+	k := encodeInt(1024)
+	txn, err := t.s.Begin()
+	c.Assert(err, IsNil)
+	c.Assert(txn.Set(k, []byte("txn")), IsNil)
+
+	buf := kv.NewMemDbBuffer()
+	c.Assert(buf.Set(k, []byte("bulk")), IsNil)
+	_, err = t.s.(kv.BulkLoader).BulkLoad(buf)
+	c.Assert(err, IsNil)
+
+	// The transaction started before the bulk load conflicts with the loaded key.
+	err = txn.Commit()
+	c.Assert(kv.IsRetryableError(err), IsTrue)
+	snap, err := t.s.GetSnapshot(kv.MaxVersion)
+	c.Assert(err, IsNil)
+	val, err := snap.Get(k)
+	c.Assert(err, IsNil)
+	c.Assert(val, DeepEquals, []byte("bulk"))
+}
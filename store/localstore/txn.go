@@ -98,6 +98,9 @@ func (txn *dbTxn) doCommit() error {
 		}
 	}
 
+	if err := txn.us.CommitStmt(); err != nil {
+		return errors.Trace(err)
+	}
 	// check lazy condition pairs
 	if err := txn.us.CheckLazyConditionPairs(); err != nil {
 		return errors.Trace(err)
@@ -170,3 +173,15 @@ func (txn *dbTxn) Size() int {
 func (txn *dbTxn) Len() int {
 	return txn.us.Len()
 }
+
+func (txn *dbTxn) StartStmt() {
+	txn.us.StartStmt()
+}
+
+func (txn *dbTxn) CommitStmt() error {
+	return txn.us.CommitStmt()
+}
+
+func (txn *dbTxn) RollbackStmt() {
+	txn.us.RollbackStmt()
+}
@@ -17,7 +17,6 @@ import (
 	"bytes"
 	"math"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/coreos/etcd/pkg/monotime"
@@ -28,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/hotspot"
 	"github.com/pingcap/tipb/go-binlog"
 	goctx "golang.org/x/net/context"
 )
@@ -66,7 +66,9 @@ type twoPhaseCommitter struct {
 	mutations map[string]*pb.Mutation
 	lockTTL   uint64
 	commitTS  uint64
-	mu        struct {
+	// members are the transactions committed together by the committer of a commit group.
+	members []*twoPhaseCommitter
+	mu      struct {
 		sync.RWMutex
 		writtenKeys [][]byte
 		committed   bool
@@ -101,7 +103,7 @@ func newTwoPhaseCommitter(txn *tikvTxn) (*twoPhaseCommitter, error) {
 		keys = append(keys, k)
 		entrySize := len(k) + len(v)
 		if entrySize > kv.TxnEntrySizeLimit {
-			return kv.ErrEntryTooLarge
+			return kv.ErrEntryTooLarge.GenByArgs(entrySize, kv.TxnEntrySizeLimit)
 		}
 		size += entrySize
 		return nil
@@ -125,9 +127,9 @@ func newTwoPhaseCommitter(txn *tikvTxn) (*twoPhaseCommitter, error) {
 			size += len(lockKey)
 		}
 	}
-	entrylimit := atomic.LoadUint64(&kv.TxnEntryCountLimit)
-	if len(keys) > int(entrylimit) || size > kv.TxnTotalSizeLimit {
-		return nil, kv.ErrTxnTooLarge
+	entryLimit, sizeLimit := kv.GetTxnSizeLimits(txn.us)
+	if len(keys) > int(entryLimit) || size > sizeLimit {
+		return nil, kv.ErrTxnTooLarge.GenByArgs(len(keys), size, entryLimit, sizeLimit)
 	}
 	const logEntryCount = 10000
 	const logSize = 4 * 1024 * 1024 // 4MB
@@ -301,11 +303,7 @@ func (c *twoPhaseCommitter) prewriteSingleBatch(bo *Backoffer, batch batchKeys)
 		mutations[i] = c.mutations[string(k)]
 	}
 
-	skipCheck := false
-	optSkipCheck := c.txn.us.GetOption(kv.SkipCheckForWrite)
-	if skip, ok := optSkipCheck.(bool); ok && skip {
-		skipCheck = true
-	}
+	skipCheck := c.skipConstraintCheck()
 	req := &pb.Request{
 		Type: pb.MessageType_CmdPrewrite,
 		CmdPrewriteReq: &pb.CmdPrewriteRequest{
@@ -364,6 +362,17 @@ func (c *twoPhaseCommitter) prewriteSingleBatch(bo *Backoffer, batch batchKeys)
 	}
 }
 
+func (c *twoPhaseCommitter) skipConstraintCheck() bool {
+	skip, ok := c.txn.us.GetOption(kv.SkipCheckForWrite).(bool)
+	return ok && skip
+}
+
+func (c *twoPhaseCommitter) isCommitted() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.mu.committed
+}
+
 func (c *twoPhaseCommitter) doCommitSingleBatch(bo *Backoffer, batch batchKeys) error {
 	req := &pb.Request{
 		Type: pb.MessageType_CmdCommit,
@@ -475,7 +484,7 @@ func (c *twoPhaseCommitter) cleanupKeys(bo *Backoffer, keys [][]byte) error {
 const maxTxnTimeUse = 590000
 
 // execute executes the two-phase commit protocol.
-func (c *twoPhaseCommitter) execute() error {
+func (c *twoPhaseCommitter) execute(ctx goctx.Context) error {
 	defer func() {
 		// Always clean up all written keys if the txn does not commit.
 		c.mu.RLock()
@@ -483,20 +492,37 @@ func (c *twoPhaseCommitter) execute() error {
 		committed := c.mu.committed
 		c.mu.RUnlock()
 		if !committed {
-			go func() {
+			cleanup := func() {
 				err := c.cleanupKeys(NewBackoffer(cleanupMaxBackoff, goctx.Background()), writtenKeys)
 				if err != nil {
 					log.Infof("2PC cleanup err: %v, tid: %d", err, c.startTS)
 				} else {
 					log.Infof("2PC clean up done, tid: %d", c.startTS)
 				}
-			}()
+			}
+			if len(c.members) > 0 {
+				// The members of a failed group commit by themselves later, clean up
+				// the locks of the group first so they don't need to resolve them.
+				cleanup()
+			} else {
+				go cleanup()
+			}
 		}
 	}()
 
-	ctx := goctx.Background()
+	region, ok, err := c.groupCommitRegion(NewBackoffer(tsoMaxBackoff, ctx))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if ok {
+		grouped, err1 := c.store.groupCommitter.commit(c, region, getGroupCommitWindow())
+		if grouped {
+			return errors.Trace(err1)
+		}
+	}
+
 	binlogChan := c.prewriteBinlog()
-	err := c.prewriteKeys(NewBackoffer(prewriteMaxBackoff, ctx), c.keys)
+	err = c.prewriteKeys(NewBackoffer(prewriteMaxBackoff, ctx), c.keys)
 	if binlogChan != nil {
 		binlogErr := <-binlogChan
 		if binlogErr != nil {
@@ -513,10 +539,14 @@ func (c *twoPhaseCommitter) execute() error {
 		log.Warnf("2PC get commitTS failed: %v, tid: %d", err, c.startTS)
 		return errors.Trace(err)
 	}
+	return errors.Trace(c.commitWithTS(ctx, commitTS))
+}
 
+// commitWithTS commits the prewritten keys with commitTS.
+func (c *twoPhaseCommitter) commitWithTS(ctx goctx.Context, commitTS uint64) error {
 	// check commitTS
 	if commitTS <= c.startTS {
-		err = errors.Errorf("Invalid transaction tso with start_ts=%v while commit_ts=%v",
+		err := errors.Errorf("Invalid transaction tso with start_ts=%v while commit_ts=%v",
 			c.startTS,
 			commitTS)
 		log.Error(err)
@@ -528,11 +558,11 @@ func (c *twoPhaseCommitter) execute() error {
 	}
 
 	if c.store.oracle.IsExpired(c.startTS, maxTxnTimeUse) {
-		err = errors.Errorf("txn takes too much time, start: %d, commit: %d", c.startTS, c.commitTS)
+		err := errors.Errorf("txn takes too much time, start: %d, commit: %d", c.startTS, c.commitTS)
 		return errors.Annotate(err, txnRetryableMark)
 	}
 
-	err = c.commitKeys(NewBackoffer(commitMaxBackoff, ctx), c.keys)
+	err := c.commitKeys(NewBackoffer(commitMaxBackoff, ctx), c.keys)
 	if err != nil {
 		if !c.mu.committed {
 			log.Debugf("2PC failed on commit: %v, tid: %d", err, c.startTS)
@@ -540,19 +570,40 @@ func (c *twoPhaseCommitter) execute() error {
 		}
 		log.Debugf("2PC succeed with error: %v, tid: %d", err, c.startTS)
 	}
+	c.recordWrites()
 	return nil
 }
 
+// recordWrites records the written keys for the hotspot statistics, the locked keys are excluded.
+func (c *twoPhaseCommitter) recordWrites() {
+	keys := make([][]byte, 0, len(c.keys))
+	for _, key := range c.keys {
+		if c.mutations[string(key)].Op != pb.Op_Lock {
+			keys = append(keys, key)
+		}
+	}
+	hotspot.RecordWrites(keys)
+}
+
 type schemaLeaseChecker interface {
 	Check(txnTS uint64) error
 }
 
 func (c *twoPhaseCommitter) checkSchemaValid() error {
-	checker, ok := c.txn.us.GetOption(kv.SchemaLeaseChecker).(schemaLeaseChecker)
-	if ok {
-		err := checker.Check(c.commitTS)
-		if err != nil {
-			return errors.Trace(err)
+	txns := []*tikvTxn{c.txn}
+	if len(c.members) > 0 {
+		txns = txns[:0]
+		for _, m := range c.members {
+			txns = append(txns, m.txn)
+		}
+	}
+	for _, txn := range txns {
+		checker, ok := txn.us.GetOption(kv.SchemaLeaseChecker).(schemaLeaseChecker)
+		if ok {
+			err := checker.Check(c.commitTS)
+			if err != nil {
+				return errors.Trace(err)
+			}
 		}
 	}
 	return nil
@@ -14,15 +14,18 @@
 package tikv
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/coprocessor"
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/tikv/mock-tikv"
 	"github.com/pingcap/tidb/terror"
 	goctx "golang.org/x/net/context"
@@ -320,6 +323,7 @@ func (s *testCommitterSuite) TestCommitBeforePrewrite(c *C) {
 	err = commiter.prewriteKeys(NewBackoffer(prewriteMaxBackoff, ctx), commiter.keys)
 	c.Assert(err, NotNil)
 	errMsgMustContain(c, err, "write conflict")
+	c.Assert(terror.ErrorEqual(err, kv.ErrWriteConflict), IsTrue)
 }
 
 func (s *testCommitterSuite) TestPrewritePrimaryKeyFailed(c *C) {
@@ -418,3 +422,117 @@ func (s *testCommitterSuite) TestCommitPrimaryError(c *C) {
 
 	c.Assert(terror.ErrorEqual(err, terror.ErrResultUndetermined), IsTrue)
 }
+
+// countKVClient wraps rpcClient and counts the kv requests by type.
+type countKVClient struct {
+	Client
+	mu     sync.Mutex
+	counts map[kvrpcpb.MessageType]int
+}
+
+func (c *countKVClient) SendKVReq(ctx goctx.Context, addr string, req *kvrpcpb.Request, timeout time.Duration) (*kvrpcpb.Response, error) {
+	c.mu.Lock()
+	c.counts[req.GetType()]++
+	c.mu.Unlock()
+	return c.Client.SendKVReq(ctx, addr, req, timeout)
+}
+
+func (c *countKVClient) count(tp kvrpcpb.MessageType) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[tp]
+}
+
+func (s *testCommitterSuite) commitConcurrently(txns []*tikvTxn) []error {
+	errs := make([]error, len(txns))
+	var wg sync.WaitGroup
+	for i, txn := range txns {
+		wg.Add(1)
+		go func(i int, txn *tikvTxn) {
+			defer wg.Done()
+			errs[i] = txn.Commit()
+		}(i, txn)
+	}
+	wg.Wait()
+	return errs
+}
+
+func (s *testCommitterSuite) TestGroupCommit(c *C) {
+	SetGroupCommitWindow(50 * time.Millisecond)
+	defer SetGroupCommitWindow(0)
+	client := &countKVClient{Client: s.store.client, counts: make(map[kvrpcpb.MessageType]int)}
+	s.store.client = client
+
+	// Keys "a0".."a9" are all in the region ["a", "b").
+	const cnt = 10
+	txns := make([]*tikvTxn, cnt)
+	for i := range txns {
+		txns[i] = s.begin(c)
+		err := txns[i].Set([]byte(fmt.Sprintf("a%d", i)), []byte(fmt.Sprintf("v%d", i)))
+		c.Assert(err, IsNil)
+		txns[i].SetOption(kv.GroupCommit, true)
+	}
+	for _, err := range s.commitConcurrently(txns) {
+		c.Assert(err, IsNil)
+	}
+	// The group is prewritten and committed by one request each.
+	c.Assert(client.count(kvrpcpb.MessageType_CmdPrewrite), Equals, 1)
+	c.Assert(client.count(kvrpcpb.MessageType_CmdCommit), Equals, 1)
+	for i := range txns {
+		c.Assert(txns[i].commitTS, Equals, txns[0].commitTS)
+		s.checkValues(c, map[string]string{fmt.Sprintf("a%d", i): fmt.Sprintf("v%d", i)})
+	}
+
+	// A transaction spans multiple regions can not join the group.
+	multi := s.begin(c)
+	err := multi.Set([]byte("a"), []byte("a"))
+	c.Assert(err, IsNil)
+	err = multi.Set([]byte("b"), []byte("b"))
+	c.Assert(err, IsNil)
+	multi.SetOption(kv.GroupCommit, true)
+	single := s.begin(c)
+	err = single.Set([]byte("a0"), []byte("x"))
+	c.Assert(err, IsNil)
+	single.SetOption(kv.GroupCommit, true)
+	for _, err = range s.commitConcurrently([]*tikvTxn{multi, single}) {
+		c.Assert(err, IsNil)
+	}
+	c.Assert(multi.commitTS, Not(Equals), single.commitTS)
+	s.checkValues(c, map[string]string{"a": "a", "b": "b", "a0": "x"})
+}
+
+func (s *testCommitterSuite) TestGroupCommitFallback(c *C) {
+	SetGroupCommitWindow(50 * time.Millisecond)
+	defer SetGroupCommitWindow(0)
+
+	txns := make([]*tikvTxn, 3)
+	for i := range txns {
+		txns[i] = s.begin(c)
+		err := txns[i].Set([]byte(fmt.Sprintf("a%d", i)), []byte(fmt.Sprintf("v%d", i)))
+		c.Assert(err, IsNil)
+		txns[i].SetOption(kv.GroupCommit, true)
+	}
+	// The write of txns[1] conflicts with a transaction committed after it starts.
+	s.mustCommit(c, map[string]string{"a1": "x"})
+
+	errs := s.commitConcurrently(txns)
+	// The group is rolled back, txns[0] owns the start timestamp of the group and
+	// fails with a retryable error, txns[2] is committed by itself.
+	c.Assert(kv.IsRetryableError(errs[0]), IsTrue)
+	c.Assert(errs[1], NotNil)
+	c.Assert(errs[2], IsNil)
+	s.checkValues(c, map[string]string{"a1": "x", "a2": "v2"})
+	_, err := s.begin(c).Get([]byte("a0"))
+	c.Assert(kv.IsErrNotFound(err), IsTrue)
+
+	// The transactions writing the same key can't be in the same group, one of them conflicts.
+	txns = txns[:2]
+	for i := range txns {
+		txns[i] = s.begin(c)
+		err := txns[i].Set([]byte("a3"), []byte(fmt.Sprintf("v%d", i)))
+		c.Assert(err, IsNil)
+		txns[i].SetOption(kv.GroupCommit, true)
+	}
+	errs = s.commitConcurrently(txns)
+	c.Assert((errs[0] == nil) != (errs[1] == nil), IsTrue)
+}
@@ -23,6 +23,10 @@ import (
 	"github.com/ngaut/log"
 	"github.com/pingcap/kvproto/pkg/coprocessor"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/pingcap/tidb/util/hotspot"
+	"github.com/pingcap/tidb/util/resourcegroup"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/pingcap/tipb/go-tipb"
 	goctx "golang.org/x/net/context"
 )
@@ -42,7 +46,7 @@ func (c *CopClient) IsRequestTypeSupported(reqType, subType int64) bool {
 		default:
 			return supportExpr(tipb.ExprType(subType))
 		}
-	case kv.ReqTypeDAG:
+	case kv.ReqTypeDAG, kv.ReqTypeChecksum:
 		return c.store.mock
 	}
 	return false
@@ -75,6 +79,11 @@ func supportExpr(exprType tipb.ExprType) bool {
 // Send builds the request and gets the coprocessor iterator response.
 func (c *CopClient) Send(ctx goctx.Context, req *kv.Request) kv.Response {
 	coprocessorCounter.WithLabelValues("send").Inc()
+	startKeys := make([][]byte, 0, len(req.KeyRanges))
+	for _, r := range req.KeyRanges {
+		startKeys = append(startKeys, r.StartKey)
+	}
+	hotspot.RecordReads(startKeys)
 
 	bo := NewBackoffer(copBuildTaskMaxBackoff, ctx)
 	tasks, err := buildCopTasks(bo, c.store.regionCache, &copRanges{mid: req.KeyRanges}, req.Desc)
@@ -295,6 +304,13 @@ type copIterator struct {
 	// Otherwise, results are stored in respChan.
 	respChan chan copResponse
 	wg       sync.WaitGroup
+
+	// startTime is when the workers start, the tasks wait in taskCh since then.
+	startTime time.Time
+	mu        struct {
+		sync.Mutex
+		execDetails execdetails.ExecDetails
+	}
 }
 
 type copResponse struct {
@@ -311,7 +327,9 @@ func (it *copIterator) work(ctx goctx.Context, taskCh <-chan *copTask) {
 	for task := range taskCh {
 		bo := NewBackoffer(copNextMaxBackoff, ctx)
 		startTime := time.Now()
+		span, _ := tracing.StartSpanFromContext(ctx, "tikv.coprocessor")
 		resps := it.handleTask(bo, task)
+		span.Finish()
 		costTime := time.Since(startTime)
 		if costTime > minLogCopTaskTime {
 			log.Infof("[TIME_COP_TASK] %s%s %s", costTime, bo, task)
@@ -320,6 +338,7 @@ func (it *copIterator) work(ctx goctx.Context, taskCh <-chan *copTask) {
 		if bo.totalSleep > 0 {
 			backoffHistogram.Observe(float64(bo.totalSleep) / 1000)
 		}
+		it.recordExecDetails(startTime.Sub(it.startTime), costTime, time.Duration(bo.totalSleep)*time.Millisecond)
 		var ch chan copResponse
 		if !it.req.KeepOrder {
 			ch = it.respChan
@@ -342,7 +361,25 @@ func (it *copIterator) work(ctx goctx.Context, taskCh <-chan *copTask) {
 	}
 }
 
+func (it *copIterator) recordExecDetails(waitTime, processTime, backoffTime time.Duration) {
+	it.mu.Lock()
+	it.mu.execDetails.RequestCount++
+	it.mu.execDetails.WaitTime += waitTime
+	it.mu.execDetails.ProcessTime += processTime
+	it.mu.execDetails.BackoffTime += backoffTime
+	it.mu.Unlock()
+}
+
+// ExecDetails implements the kv.ExecDetailsResponse interface.
+func (it *copIterator) ExecDetails() execdetails.ExecDetails {
+	it.mu.Lock()
+	details := it.mu.execDetails
+	it.mu.Unlock()
+	return details
+}
+
 func (it *copIterator) run(ctx goctx.Context) {
+	it.startTime = time.Now()
 	it.wg.Add(it.concurrency)
 	// Start it.concurrency number of workers to handle cop requests.
 	for i := 0; i < it.concurrency; i++ {
@@ -357,7 +394,12 @@ func (it *copIterator) run(ctx goctx.Context) {
 		// Send tasks to feed the worker goroutines.
 		childCtx, cancel := goctx.WithCancel(ctx)
 		defer cancel()
+		// The tasks are sent no faster than the coprocessor request rate of the resource group.
+		quota := resourcegroup.StmtQuotaFromContext(ctx)
 		for _, t := range it.tasks {
+			if quota.WaitCopRequest(childCtx) != nil {
+				break
+			}
 			finished, canceled := it.sendToTaskCh(childCtx, t)
 			if finished || canceled {
 				break
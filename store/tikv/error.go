@@ -14,8 +14,12 @@
 package tikv
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/juju/errors"
 	pb "github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/tidb/util/txnconflict"
 )
 
 var (
@@ -35,6 +39,25 @@ var (
 // and ii) the error is not totally unexpected and hopefully will recover soon.
 const txnRetryableMark = "[try again later]"
 
+// writeConflictFormat is the format of the write conflict in the retryable message of a KeyError.
+const writeConflictFormat = "write conflict, start_ts: %d, conflict_start_ts: %d, conflict_commit_ts: %d, key: %x"
+
+// extractWriteConflict returns kv.ErrWriteConflict if the retryable message of a KeyError is a write
+// conflict, the conflict is reported to the txnconflict package. It returns nil otherwise.
+func extractWriteConflict(retryable string) error {
+	i := strings.Index(retryable, "write conflict, ")
+	if i < 0 {
+		return nil
+	}
+	var startTS, conflictStartTS, conflictCommitTS uint64
+	var key []byte
+	_, err := fmt.Sscanf(retryable[i:], writeConflictFormat, &startTS, &conflictStartTS, &conflictCommitTS, &key)
+	if err != nil {
+		return nil
+	}
+	return txnconflict.Report(startTS, conflictStartTS, conflictCommitTS, key)
+}
+
 // errMismatch if response mismatches request return error.
 func errMismatch(resp *pb.Response, req *pb.Request) error {
 	return errors.Errorf("message type mismatches, response[%s] request[%s]",
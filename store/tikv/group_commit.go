@@ -0,0 +1,231 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	pb "github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/terror"
+	goctx "golang.org/x/net/context"
+)
+
+// groupCommitWindow is the time window in which the commits of small transactions
+// hitting the same region are coalesced. Zero means group commit is disabled.
+var groupCommitWindow int64
+
+// maxGroupCommitSize is the max number of transactions in a commit group, a group
+// is flushed immediately when it gets full.
+const maxGroupCommitSize = 128
+
+// SetGroupCommitWindow sets the group commit window, a zero or negative duration
+// disables group commit.
+func SetGroupCommitWindow(window time.Duration) {
+	if window < 0 {
+		window = 0
+	}
+	atomic.StoreInt64(&groupCommitWindow, int64(window))
+}
+
+func getGroupCommitWindow() time.Duration {
+	return time.Duration(atomic.LoadInt64(&groupCommitWindow))
+}
+
+// commitGroup is a group of transactions in the same region waiting to be committed together.
+type commitGroup struct {
+	region  RegionVerID
+	members []*twoPhaseCommitter
+	keys    map[string]struct{}
+	flushed bool
+	// fallback is true if the group is rolled back, and the members commit by themselves.
+	fallback bool
+	// startTS is the start timestamp of the group, the smallest one of the members.
+	startTS  uint64
+	commitTS uint64
+	err      error
+	done     chan struct{}
+}
+
+// groupCommitter coalesces many tiny transactions hitting the same region into one
+// two-phase commit. The mutations of all the members are prewritten and committed
+// together, with the smallest start timestamp of the members, so the group sends one
+// prewrite request and one commit request to the region instead of one per member.
+// Using the smallest start timestamp makes the conflict check of the group at least
+// as strict as the ones of its members. If the group fails before its primary key is
+// committed, it's rolled back and the members commit by themselves, so a conflict of
+// one member doesn't fail the others. The member owning the start timestamp of the
+// group can't prewrite again with it after the rollback, it fails with a retryable
+// error instead, which is retried by the session.
+type groupCommitter struct {
+	store  *tikvStore
+	mu     sync.Mutex
+	groups map[RegionVerID]*commitGroup
+}
+
+func newGroupCommitter(store *tikvStore) *groupCommitter {
+	return &groupCommitter{
+		store:  store,
+		groups: make(map[RegionVerID]*commitGroup),
+	}
+}
+
+// commit adds the transaction to the commit group of the region and waits until the group
+// is committed. It returns false if the transaction isn't committed by the group, and it
+// should be committed by itself.
+func (g *groupCommitter) commit(c *twoPhaseCommitter, region RegionVerID, window time.Duration) (bool, error) {
+	g.mu.Lock()
+	group, ok := g.groups[region]
+	if ok && !group.canJoin(c) {
+		g.mu.Unlock()
+		return false, nil
+	}
+	if !ok {
+		group = &commitGroup{
+			region: region,
+			keys:   make(map[string]struct{}),
+			done:   make(chan struct{}),
+		}
+		g.groups[region] = group
+		time.AfterFunc(window, func() { g.flush(group) })
+	}
+	group.members = append(group.members, c)
+	for _, k := range c.keys {
+		group.keys[string(k)] = struct{}{}
+	}
+	full := len(group.members) >= maxGroupCommitSize
+	g.mu.Unlock()
+
+	if full {
+		g.flush(group)
+	}
+	<-group.done
+	if group.fallback {
+		if c.startTS == group.startTS {
+			return true, errors.Annotate(group.err, txnRetryableMark)
+		}
+		return false, nil
+	}
+	if group.err != nil {
+		return true, errors.Trace(group.err)
+	}
+	c.commitTS = group.commitTS
+	c.mu.Lock()
+	c.mu.committed = true
+	c.mu.Unlock()
+	return true, nil
+}
+
+// canJoin checks whether the transaction can be committed together with the members of the group.
+// The mutations of the members can't be merged if they write the same key, and a prewrite request
+// can't mix the mutations which skip the constraint check with the ones which don't.
+func (group *commitGroup) canJoin(c *twoPhaseCommitter) bool {
+	if group.flushed || c.skipConstraintCheck() != group.members[0].skipConstraintCheck() {
+		return false
+	}
+	for _, k := range c.keys {
+		if _, ok := group.keys[string(k)]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *groupCommitter) flush(group *commitGroup) {
+	g.mu.Lock()
+	if group.flushed {
+		g.mu.Unlock()
+		return
+	}
+	group.flushed = true
+	if g.groups[group.region] == group {
+		delete(g.groups, group.region)
+	}
+	g.mu.Unlock()
+
+	groupCommitSizeHistogram.Observe(float64(len(group.members)))
+	if len(group.members) == 1 {
+		// Nothing to coalesce.
+		group.fallback = true
+		close(group.done)
+		return
+	}
+	merged := newGroupTwoPhaseCommitter(group.members)
+	group.startTS = merged.startTS
+	group.err = merged.execute(goctx.Background())
+	group.commitTS = merged.commitTS
+	if group.err != nil && !merged.isCommitted() && !terror.ErrorEqual(group.err, terror.ErrResultUndetermined) {
+		log.Debugf("2PC group commit of %d transactions failed: %v, commit them one by one", len(group.members), group.err)
+		group.fallback = true
+	}
+	close(group.done)
+}
+
+// newGroupTwoPhaseCommitter creates a twoPhaseCommitter which commits the mutations of the members together.
+func newGroupTwoPhaseCommitter(members []*twoPhaseCommitter) *twoPhaseCommitter {
+	merged := &twoPhaseCommitter{
+		store:     members[0].store,
+		txn:       members[0].txn,
+		startTS:   members[0].startTS,
+		mutations: make(map[string]*pb.Mutation),
+		members:   members,
+	}
+	for _, m := range members {
+		if m.startTS < merged.startTS {
+			merged.startTS = m.startTS
+		}
+		if m.lockTTL > merged.lockTTL {
+			merged.lockTTL = m.lockTTL
+		}
+		merged.keys = append(merged.keys, m.keys...)
+		for k, mutation := range m.mutations {
+			merged.mutations[k] = mutation
+		}
+	}
+	return merged
+}
+
+// groupCommitRegion returns the region of the transaction and whether it can
+// join a commit group. Only transactions marked with kv.GroupCommit, without
+// binlog and small enough to be prewritten in a single request are allowed.
+func (c *twoPhaseCommitter) groupCommitRegion(bo *Backoffer) (RegionVerID, bool, error) {
+	var region RegionVerID
+	if getGroupCommitWindow() <= 0 || len(c.members) > 0 || c.shouldWriteBinlog() {
+		return region, false, nil
+	}
+	if enabled, ok := c.txn.us.GetOption(kv.GroupCommit).(bool); !ok || !enabled {
+		return region, false, nil
+	}
+	var size int
+	for _, k := range c.keys {
+		size += c.keyValueSize(k)
+	}
+	if size >= txnCommitBatchSize {
+		return region, false, nil
+	}
+	loc, err := c.store.regionCache.LocateKey(bo, c.primary())
+	if err != nil {
+		return region, false, errors.Trace(err)
+	}
+	for _, k := range c.keys {
+		if !loc.Contains(k) {
+			return region, false, nil
+		}
+	}
+	return loc.Region, true, nil
+}
@@ -32,6 +32,8 @@ import (
 	goctx "golang.org/x/net/context"
 )
 
+var _ kv.LowResolutionTSStorage = (*tikvStore)(nil)
+
 type storeCache struct {
 	sync.Mutex
 	cache map[string]*tikvStore
@@ -97,15 +99,16 @@ func (d MockDriver) Open(path string) (kv.Storage, error) {
 var oracleUpdateInterval = 2000
 
 type tikvStore struct {
-	clusterID    uint64
-	uuid         string
-	oracle       oracle.Oracle
-	client       Client
-	regionCache  *RegionCache
-	lockResolver *LockResolver
-	gcWorker     *GCWorker
-	etcdAddrs    []string
-	mock         bool
+	clusterID      uint64
+	uuid           string
+	oracle         oracle.Oracle
+	client         Client
+	regionCache    *RegionCache
+	lockResolver   *LockResolver
+	gcWorker       *GCWorker
+	groupCommitter *groupCommitter // coalesces commits of small transactions.
+	etcdAddrs      []string
+	mock           bool
 }
 
 func newTikvStore(uuid string, pdClient pd.Client, client Client, enableGC bool) (*tikvStore, error) {
@@ -123,6 +126,7 @@ func newTikvStore(uuid string, pdClient pd.Client, client Client, enableGC bool)
 		mock:        mock,
 	}
 	store.lockResolver = newLockResolver(store)
+	store.groupCommitter = newGroupCommitter(store)
 	if enableGC {
 		store.gcWorker, err = NewGCWorker(store)
 		if err != nil {
@@ -222,6 +226,12 @@ func (s *tikvStore) CurrentVersion() (kv.Version, error) {
 	return kv.NewVersion(startTS), nil
 }
 
+// LowResolutionTS implements the kv.LowResolutionTSStorage interface.
+func (s *tikvStore) LowResolutionTS() (uint64, error) {
+	ts, err := s.oracle.GetLowResolutionTimestamp(goctx.Background())
+	return ts, errors.Trace(err)
+}
+
 func (s *tikvStore) getTimestampWithRetry(bo *Backoffer) (uint64, error) {
 	for {
 		startTS, err := s.oracle.GetTimestamp(bo.ctx)
@@ -181,6 +181,15 @@ var (
 			Help:      "Number of regions in a transaction.",
 			Buckets:   prometheus.ExponentialBuckets(1, 2, 20),
 		}, []string{"type"})
+
+	groupCommitSizeHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "tikvclient",
+			Name:      "group_commit_size",
+			Help:      "Number of transactions committed in a commit group.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+		})
 )
 
 func reportRegionError(e *errorpb.Error) {
@@ -223,4 +232,5 @@ func init() {
 	prometheus.MustRegister(rawkvCmdHistogram)
 	prometheus.MustRegister(rawkvSizeHistogram)
 	prometheus.MustRegister(txnRegionsNumHistogram)
+	prometheus.MustRegister(groupCommitSizeHistogram)
 }
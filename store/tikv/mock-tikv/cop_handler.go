@@ -65,6 +65,8 @@ func (h *rpcHandler) handleCopRequest(req *coprocessor.Request) (*coprocessor.Re
 	}
 	if req.GetTp() == kv.ReqTypeDAG {
 		return h.handleCopDAGRequest(req)
+	} else if req.GetTp() == kv.ReqTypeChecksum {
+		return h.handleCopChecksumRequest(req)
 	} else if req.GetTp() == kv.ReqTypeSelect || req.GetTp() == kv.ReqTypeIndex {
 		sel := new(tipb.SelectRequest)
 		err := proto.Unmarshal(req.Data, sel)
@@ -0,0 +1,92 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocktikv
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/coprocessor"
+	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/tidb/distsql"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/tablecodec"
+)
+
+func (h *rpcHandler) handleCopChecksumRequest(req *coprocessor.Request) (*coprocessor.Response, error) {
+	checksumReq := new(distsql.ChecksumRequest)
+	err := json.Unmarshal(req.Data, checksumReq)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	builder := distsql.NewChecksumBuilder(checksumReq)
+	for _, ran := range h.extractKVRanges(req.Ranges, false) {
+		err = h.checksumRange(checksumReq.StartTs, builder, ran)
+		if err != nil {
+			break
+		}
+	}
+	resp := &coprocessor.Response{}
+	if err != nil {
+		if locked, ok := errors.Cause(err).(*ErrLocked); ok {
+			resp.Locked = &kvrpcpb.LockInfo{
+				Key:         locked.Key,
+				PrimaryLock: locked.Primary,
+				LockVersion: locked.StartTS,
+				LockTtl:     locked.TTL,
+			}
+		} else {
+			resp.OtherError = err.Error()
+		}
+		return resp, nil
+	}
+	resp.Data, err = json.Marshal(builder.Response())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return resp, nil
+}
+
+// checksumRange adds the rows or the index entries in the range to the builder.
+func (h *rpcHandler) checksumRange(startTS uint64, builder *distsql.ChecksumBuilder, ran kv.KeyRange) error {
+	seekKey := []byte(ran.StartKey)
+	for {
+		pairs := h.mvccStore.Scan(seekKey, ran.EndKey, 1, startTS)
+		if len(pairs) == 0 {
+			return nil
+		}
+		pair := pairs[0]
+		if pair.Err != nil {
+			return errors.Trace(pair.Err)
+		}
+		if pair.Key == nil || bytes.Compare(pair.Key, ran.EndKey) >= 0 {
+			return nil
+		}
+		seekKey = []byte(kv.Key(pair.Key).PrefixNext())
+		if builder.ScanIndex() {
+			if err := builder.AddIndex(pair.Key, pair.Value); err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
+		handle, err := tablecodec.DecodeRowKey(pair.Key)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = builder.AddRow(handle, pair.Value); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
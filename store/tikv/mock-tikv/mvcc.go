@@ -15,6 +15,7 @@ package mocktikv
 
 import (
 	"bytes"
+	"fmt"
 	"sync"
 
 	"github.com/juju/errors"
@@ -111,7 +112,8 @@ func (e *mvccEntry) Get(ts uint64) ([]byte, error) {
 func (e *mvccEntry) Prewrite(mutation *kvrpcpb.Mutation, startTS uint64, primary []byte, ttl uint64) error {
 	if len(e.values) > 0 {
 		if e.values[0].commitTS >= startTS {
-			return ErrRetryable("write conflict")
+			return ErrRetryable(fmt.Sprintf("write conflict, start_ts: %d, conflict_start_ts: %d, conflict_commit_ts: %d, key: %x",
+				startTS, e.values[0].startTS, e.values[0].commitTS, mutation.Key))
 		}
 	}
 	if e.lock != nil {
@@ -22,6 +22,9 @@ import (
 // Oracle is the interface that provides strictly ascending timestamps.
 type Oracle interface {
 	GetTimestamp(ctx context.Context) (uint64, error)
+	// GetLowResolutionTimestamp returns a recently fetched timestamp without requesting a new one, it's
+	// not greater than the timestamps got later.
+	GetLowResolutionTimestamp(ctx context.Context) (uint64, error)
 	IsExpired(lockTimestamp uint64, TTL uint64) bool
 	Close()
 }
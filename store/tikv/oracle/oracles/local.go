@@ -52,5 +52,9 @@ func (l *localOracle) GetTimestamp(context.Context) (uint64, error) {
 	return uint64(ts), nil
 }
 
+func (l *localOracle) GetLowResolutionTimestamp(ctx context.Context) (uint64, error) {
+	return l.GetTimestamp(ctx)
+}
+
 func (l *localOracle) Close() {
 }
@@ -73,6 +73,11 @@ func (o *pdOracle) GetTimestamp(ctx context.Context) (uint64, error) {
 	return ts, nil
 }
 
+// GetLowResolutionTimestamp returns `lastTS`, which is updated every `updateInterval` at least.
+func (o *pdOracle) GetLowResolutionTimestamp(ctx context.Context) (uint64, error) {
+	return atomic.LoadUint64(&o.lastTS), nil
+}
+
 func (o *pdOracle) getTimestamp(ctx context.Context) (uint64, error) {
 	now := time.Now()
 	physical, logical, err := o.c.GetTS(ctx)
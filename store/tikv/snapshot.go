@@ -22,6 +22,7 @@ import (
 	"github.com/ngaut/log"
 	pb "github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/util/hotspot"
 	goctx "golang.org/x/net/context"
 )
 
@@ -57,6 +58,7 @@ func (s *tikvSnapshot) BatchGet(keys []kv.Key) (map[string][]byte, error) {
 
 	// We want [][]byte instead of []kv.Key, use some magic to save memory.
 	bytesKeys := *(*[][]byte)(unsafe.Pointer(&keys))
+	hotspot.RecordReads(bytesKeys)
 	bo := NewBackoffer(batchGetMaxBackoff, goctx.Background())
 
 	// Create a map to collect key-values from region servers.
@@ -175,6 +177,7 @@ func (s *tikvSnapshot) batchGetSingleRegion(bo *Backoffer, batch batchKeys, coll
 
 // Get gets the value for key k from snapshot.
 func (s *tikvSnapshot) Get(k kv.Key) ([]byte, error) {
+	hotspot.RecordReads([][]byte{k})
 	val, err := s.get(NewBackoffer(getMaxBackoff, goctx.Background()), k)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -251,6 +254,10 @@ func extractLockFromKeyErr(keyErr *pb.KeyError) (*Lock, error) {
 		return newLock(locked), nil
 	}
 	if keyErr.Retryable != "" {
+		if err := extractWriteConflict(keyErr.GetRetryable()); err != nil {
+			log.Debug(err)
+			return nil, errors.Annotate(err, txnRetryableMark)
+		}
 		err := errors.Errorf("tikv restarts txn: %s", keyErr.GetRetryable())
 		log.Debug(err)
 		return nil, errors.Annotate(err, txnRetryableMark)
@@ -0,0 +1,84 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"bytes"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/tikv/mock-tikv"
+	goctx "golang.org/x/net/context"
+)
+
+var _ kv.RegionStorage = (*tikvStore)(nil)
+
+// GetRegions implements the kv.RegionStorage interface.
+// The regions are loaded from PD instead of the region cache, so they are up to date.
+func (s *tikvStore) GetRegions(startKey, endKey kv.Key) ([]kv.RegionInfo, error) {
+	bo := NewBackoffer(regionMaxBackoff, goctx.Background())
+	var regions []kv.RegionInfo
+	key := []byte(startKey)
+	for {
+		r, err := s.regionCache.loadRegion(bo, key)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		info := kv.RegionInfo{
+			ID:            r.GetID(),
+			StartKey:      r.StartKey(),
+			EndKey:        r.EndKey(),
+			LeaderID:      r.peer.GetId(),
+			LeaderStoreID: r.peer.GetStoreId(),
+		}
+		for _, peer := range r.meta.Peers {
+			info.Peers = append(info.Peers, peer.GetId())
+		}
+		regions = append(regions, info)
+		if len(r.EndKey()) == 0 || (len(endKey) > 0 && bytes.Compare(r.EndKey(), endKey) >= 0) {
+			break
+		}
+		key = r.EndKey()
+	}
+	return regions, nil
+}
+
+// SplitRegion implements the kv.RegionStorage interface.
+// Only the mocked tikv supports splitting regions for now, there isn't a split request in the tikv
+// protocol yet, the regions of tikv are split by PD according to their sizes.
+func (s *tikvStore) SplitRegion(splitKey kv.Key) error {
+	client, ok := s.client.(*mocktikv.RPCClient)
+	if !ok {
+		return kv.ErrNotImplemented.Gen("split region is not supported by the storage")
+	}
+	bo := NewBackoffer(regionMaxBackoff, goctx.Background())
+	r, err := s.regionCache.loadRegion(bo, splitKey)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if bytes.Equal(r.StartKey(), splitKey) {
+		return nil
+	}
+	cluster := client.Cluster
+	peerIDs := cluster.AllocIDs(len(r.meta.Peers))
+	leaderPeerID := peerIDs[0]
+	for i, peer := range r.meta.Peers {
+		if peer.GetStoreId() == r.peer.GetStoreId() {
+			leaderPeerID = peerIDs[i]
+		}
+	}
+	cluster.Split(r.GetID(), cluster.AllocID(), splitKey, peerIDs, leaderPeerID)
+	s.regionCache.DropRegion(r.VerID())
+	return nil
+}
@@ -207,6 +207,10 @@ func (o *mockOracle) GetTimestamp(goctx.Context) (uint64, error) {
 	return ts, nil
 }
 
+func (o *mockOracle) GetLowResolutionTimestamp(ctx goctx.Context) (uint64, error) {
+	return o.GetTimestamp(ctx)
+}
+
 func (o *mockOracle) IsExpired(lockTimestamp uint64, TTL uint64) bool {
 	o.RLock()
 	defer o.RUnlock()
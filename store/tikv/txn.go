@@ -135,6 +135,9 @@ func (txn *tikvTxn) Commit() error {
 	start := time.Now()
 	defer func() { txnCmdHistogram.WithLabelValues("commit").Observe(time.Since(start).Seconds()) }()
 
+	if err := txn.us.CommitStmt(); err != nil {
+		return errors.Trace(err)
+	}
 	if err := txn.us.CheckLazyConditionPairs(); err != nil {
 		return errors.Trace(err)
 	}
@@ -146,7 +149,7 @@ func (txn *tikvTxn) Commit() error {
 	if committer == nil {
 		return nil
 	}
-	err = committer.execute()
+	err = committer.execute(goctx.Background())
 	if err != nil {
 		committer.writeFinishBinlog(binlog.BinlogType_Rollback, 0)
 		return errors.Trace(err)
@@ -199,3 +202,15 @@ func (txn *tikvTxn) Len() int {
 func (txn *tikvTxn) Size() int {
 	return txn.us.Size()
 }
+
+func (txn *tikvTxn) StartStmt() {
+	txn.us.StartStmt()
+}
+
+func (txn *tikvTxn) CommitStmt() error {
+	return txn.us.CommitStmt()
+}
+
+func (txn *tikvTxn) RollbackStmt() {
+	txn.us.RollbackStmt()
+}
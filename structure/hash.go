@@ -208,6 +208,39 @@ func (t *TxStructure) HGetAll(key []byte) ([]HashPair, error) {
 	return res, errors.Trace(err)
 }
 
+// HScan gets at most count fields and values in a hash from the field cursor in the order of the fields, a nil
+// cursor starts from the first field. It also returns the cursor of the next call, which is nil if all the fields
+// are scanned. The fields set or deleted between the calls may be missed or returned.
+func (t *TxStructure) HScan(key []byte, cursor []byte, count int) ([]HashPair, []byte, error) {
+	dataPrefix := t.hashDataKeyPrefix(key)
+	it, err := t.reader.Seek(t.encodeHashDataKey(key, cursor))
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	defer it.Close()
+
+	var res []HashPair
+	for it.Valid() && it.Key().HasPrefix(dataPrefix) {
+		var field []byte
+		_, field, err = t.decodeHashDataKey(it.Key())
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if len(res) >= count {
+			return res, field, nil
+		}
+		res = append(res, HashPair{
+			Field: field,
+			Value: append([]byte{}, it.Value()...),
+		})
+		if err = it.Next(); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+
+	return res, nil, nil
+}
+
 // HClear removes the hash value of the key.
 func (t *TxStructure) HClear(key []byte) error {
 	metaKey := t.encodeHashMetaKey(key)
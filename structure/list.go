@@ -172,6 +172,69 @@ func (t *TxStructure) LSet(key []byte, index int64, value []byte) error {
 	return errInvalidListIndex.Gen("invalid list index %d", index)
 }
 
+// LRange gets the elements from start to stop in a list, both ends are inclusive. Like LIndex, the negative
+// indexes count from the end of the list, and the out of range indexes are limited to the list.
+func (t *TxStructure) LRange(key []byte, start int64, stop int64) ([][]byte, error) {
+	metaKey := t.encodeListMetaKey(key)
+	meta, err := t.loadListMeta(metaKey)
+	if err != nil || meta.IsEmpty() {
+		return nil, errors.Trace(err)
+	}
+
+	start, stop = adjustRange(start, stop, meta.LIndex, meta.RIndex)
+	if start > stop {
+		return nil, nil
+	}
+
+	// The indexes are encoded in ascending order, so the elements are read by a range scan.
+	endKey := t.encodeListDataKey(key, stop+1)
+	it, err := t.reader.Seek(t.encodeListDataKey(key, start))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer it.Close()
+
+	values := make([][]byte, 0, stop-start+1)
+	for it.Valid() && it.Key().Cmp(endKey) < 0 {
+		values = append(values, it.Value())
+		if err = it.Next(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return values, nil
+}
+
+// LTrim trims a list to the elements from start to stop, the indexes are the same as LRange. The list is
+// removed if no element is kept.
+func (t *TxStructure) LTrim(key []byte, start int64, stop int64) error {
+	if t.readWriter == nil {
+		return errWriteOnSnapshot
+	}
+	metaKey := t.encodeListMetaKey(key)
+	meta, err := t.loadListMeta(metaKey)
+	if err != nil || meta.IsEmpty() {
+		return errors.Trace(err)
+	}
+
+	start, stop = adjustRange(start, stop, meta.LIndex, meta.RIndex)
+	if start > stop {
+		return errors.Trace(t.LClear(key))
+	}
+
+	for index := meta.LIndex; index < meta.RIndex; index++ {
+		if index >= start && index <= stop {
+			continue
+		}
+		dataKey := t.encodeListDataKey(key, index)
+		if err = t.readWriter.Delete(dataKey); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	meta.LIndex, meta.RIndex = start, stop+1
+	return t.readWriter.Set(metaKey, meta.Value())
+}
+
 // LClear removes the list of the key.
 func (t *TxStructure) LClear(key []byte) error {
 	if t.readWriter == nil {
@@ -222,3 +285,17 @@ func adjustIndex(index int64, min, max int64) int64 {
 
 	return index + max
 }
+
+// adjustRange adjusts the list indexes start and stop to the absolute indexes within [min, max), start is greater
+// than stop if the range is empty.
+func adjustRange(start, stop int64, min, max int64) (int64, int64) {
+	start = adjustIndex(start, min, max)
+	stop = adjustIndex(stop, min, max)
+	if start < min {
+		start = min
+	}
+	if stop >= max {
+		stop = max - 1
+	}
+	return start, stop
+}
@@ -25,6 +25,7 @@ const (
 	codeInvalidListIndex                    = 3
 	codeInvalidListMetaData                 = 4
 	codeWriteOnSnapshot                     = 5
+	codeInvalidZSetMetaData                 = 6
 )
 
 var (
@@ -33,6 +34,7 @@ var (
 	errInvalidListIndex     = terror.ClassStructure.New(codeInvalidListMetaData, "invalid list index")
 	errInvalidListMetaData  = terror.ClassStructure.New(codeInvalidListMetaData, "invalid list meta data")
 	errWriteOnSnapshot      = terror.ClassStructure.New(codeWriteOnSnapshot, "write on snapshot")
+	errInvalidZSetMetaData  = terror.ClassStructure.New(codeInvalidZSetMetaData, "invalid sorted set meta data")
 )
 
 // NewStructure creates a TxStructure with Retriever, RetrieverMutator and key prefix.
@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
@@ -168,6 +169,59 @@ func (s *testTxStructureSuite) TestList(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(l, Equals, int64(0))
 
+	// The list is [0, 1, 2, 3, 4], and its indexes start from -2.
+	err = tx.LPush(key, []byte("1"), []byte("0"))
+	c.Assert(err, IsNil)
+	err = tx.RPush(key, []byte("2"), []byte("3"), []byte("4"))
+	c.Assert(err, IsNil)
+	tbl := []struct {
+		start  int64
+		stop   int64
+		values []string
+	}{
+		{0, -1, []string{"0", "1", "2", "3", "4"}},
+		{1, 3, []string{"1", "2", "3"}},
+		{-2, -1, []string{"3", "4"}},
+		{-10, 1, []string{"0", "1"}},
+		{3, 10, []string{"3", "4"}},
+		{3, 1, nil},
+		{5, 10, nil},
+	}
+	for _, t := range tbl {
+		values, err1 := tx.LRange(key, t.start, t.stop)
+		c.Assert(err1, IsNil)
+		c.Assert(values, HasLen, len(t.values))
+		for i, v := range t.values {
+			c.Assert(values[i], DeepEquals, []byte(v))
+		}
+	}
+	values, err := tx.LRange([]byte("b"), 0, -1)
+	c.Assert(err, IsNil)
+	c.Assert(values, HasLen, 0)
+
+	err = tx.LTrim(key, 1, -2)
+	c.Assert(err, IsNil)
+	l, err = tx.LLen(key)
+	c.Assert(err, IsNil)
+	c.Assert(l, Equals, int64(3))
+	value, err = tx.LPop(key)
+	c.Assert(err, IsNil)
+	c.Assert(value, DeepEquals, []byte("1"))
+	value, err = tx.RPop(key)
+	c.Assert(err, IsNil)
+	c.Assert(value, DeepEquals, []byte("3"))
+
+	err = tx.LTrim(key, 1, 0)
+	c.Assert(err, IsNil)
+	l, err = tx.LLen(key)
+	c.Assert(err, IsNil)
+	c.Assert(l, Equals, int64(0))
+	// The trimmed elements are deleted.
+	for index := int64(-2); index < 3; index++ {
+		_, err = txn.Get(tx.encodeListDataKey(key, index))
+		c.Assert(terror.ErrorEqual(err, kv.ErrNotExist), IsTrue)
+	}
+
 	err = txn.Commit()
 	c.Assert(err, IsNil)
 }
@@ -210,6 +264,23 @@ func (s *testTxStructureSuite) TestHash(c *C) {
 		{[]byte("1"), []byte("1")},
 		{[]byte("2"), []byte("2")}})
 
+	res, cursor, err := tx.HScan(key, nil, 1)
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []HashPair{{[]byte("1"), []byte("1")}})
+	c.Assert(cursor, DeepEquals, []byte("2"))
+	res, cursor, err = tx.HScan(key, cursor, 1)
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []HashPair{{[]byte("2"), []byte("2")}})
+	c.Assert(cursor, IsNil)
+	res, cursor, err = tx.HScan(key, nil, 10)
+	c.Assert(err, IsNil)
+	c.Assert(res, HasLen, 2)
+	c.Assert(cursor, IsNil)
+	res, cursor, err = tx.HScan([]byte("b"), nil, 10)
+	c.Assert(err, IsNil)
+	c.Assert(res, HasLen, 0)
+	c.Assert(cursor, IsNil)
+
 	err = tx.HDel(key, []byte("1"))
 	c.Assert(err, IsNil)
 
@@ -343,3 +414,115 @@ func (s *testTxStructureSuite) TestHash(c *C) {
 	})
 	c.Assert(err, IsNil)
 }
+
+func (s *testTxStructureSuite) TestZSet(c *C) {
+	defer testleak.AfterTest(c)()
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+
+	tx := NewStructure(txn, txn, []byte{0x00})
+
+	key := []byte("a")
+
+	err = tx.ZAdd(key, 3, []byte("c"))
+	c.Assert(err, IsNil)
+	err = tx.ZAdd(key, -1.5, []byte("a"))
+	c.Assert(err, IsNil)
+	err = tx.ZAdd(key, 2, []byte("b"))
+	c.Assert(err, IsNil)
+	err = tx.ZAdd(key, 2, []byte("bb"))
+	c.Assert(err, IsNil)
+
+	l, err := tx.ZCard(key)
+	c.Assert(err, IsNil)
+	c.Assert(l, Equals, int64(4))
+
+	score, ok, err := tx.ZScore(key, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	c.Assert(score, Equals, -1.5)
+
+	_, ok, err = tx.ZScore(key, []byte("fake"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+
+	res, err := tx.ZRangeByScore(key, -10, 10)
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []ZSetPair{
+		{[]byte("a"), -1.5},
+		{[]byte("b"), 2},
+		{[]byte("bb"), 2},
+		{[]byte("c"), 3}})
+
+	res, err = tx.ZRangeByScore(key, 2, 2)
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []ZSetPair{{[]byte("b"), 2}, {[]byte("bb"), 2}})
+
+	res, err = tx.ZRangeByScore(key, 3, 2)
+	c.Assert(err, IsNil)
+	c.Assert(res, HasLen, 0)
+
+	res, err = tx.ZRangeByScore([]byte("b"), -10, 10)
+	c.Assert(err, IsNil)
+	c.Assert(res, HasLen, 0)
+
+	// Update the score of an existing member.
+	err = tx.ZAdd(key, 0, []byte("c"))
+	c.Assert(err, IsNil)
+
+	l, err = tx.ZCard(key)
+	c.Assert(err, IsNil)
+	c.Assert(l, Equals, int64(4))
+
+	res, err = tx.ZRangeByScore(key, -1, 2.5)
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []ZSetPair{
+		{[]byte("c"), 0},
+		{[]byte("b"), 2},
+		{[]byte("bb"), 2}})
+
+	err = tx.ZRem(key, []byte("b"), []byte("fake"))
+	c.Assert(err, IsNil)
+
+	l, err = tx.ZCard(key)
+	c.Assert(err, IsNil)
+	c.Assert(l, Equals, int64(3))
+
+	_, ok, err = tx.ZScore(key, []byte("b"))
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+
+	res, err = tx.ZRangeByScore(key, -10, 10)
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []ZSetPair{
+		{[]byte("a"), -1.5},
+		{[]byte("c"), 0},
+		{[]byte("bb"), 2}})
+
+	err = tx.ZRem(key, []byte("a"), []byte("bb"), []byte("c"))
+	c.Assert(err, IsNil)
+
+	l, err = tx.ZCard(key)
+	c.Assert(err, IsNil)
+	c.Assert(l, Equals, int64(0))
+
+	res, err = tx.ZRangeByScore(key, -10, 10)
+	c.Assert(err, IsNil)
+	c.Assert(res, HasLen, 0)
+
+	err = tx.ZRem(key, []byte("a"))
+	c.Assert(err, IsNil)
+
+	err = txn.Commit()
+	c.Assert(err, IsNil)
+
+	// Write on snapshot.
+	err = kv.RunInNewTxn(s.store, false, func(txn kv.Transaction) error {
+		t := NewStructure(txn, nil, []byte{0x00})
+		c.Assert(t.ZAdd(key, 1, []byte("a")), NotNil)
+		c.Assert(t.ZRem(key, []byte("a")), NotNil)
+		return nil
+	})
+	c.Assert(err, IsNil)
+}
@@ -37,6 +37,12 @@ const (
 	ListMeta TypeFlag = 'L'
 	// ListData is the flag for list data.
 	ListData TypeFlag = 'l'
+	// ZSetMeta is the flag for sorted set meta.
+	ZSetMeta TypeFlag = 'Z'
+	// ZSetData is the flag for sorted set data, which maps a member to its score.
+	ZSetData TypeFlag = 'z'
+	// ZSetScore is the flag for sorted set score, whose keys are ordered by the scores.
+	ZSetScore TypeFlag = 'x'
 )
 
 func (t *TxStructure) encodeStringDataKey(key []byte) kv.Key {
@@ -114,3 +120,42 @@ func (t *TxStructure) encodeListDataKey(key []byte, index int64) kv.Key {
 	ek = codec.EncodeUint(ek, uint64(ListData))
 	return codec.EncodeInt(ek, index)
 }
+
+func (t *TxStructure) encodeZSetMetaKey(key []byte) kv.Key {
+	ek := make([]byte, 0, len(t.prefix)+len(key)+24)
+	ek = append(ek, t.prefix...)
+	ek = codec.EncodeBytes(ek, key)
+	return codec.EncodeUint(ek, uint64(ZSetMeta))
+}
+
+func (t *TxStructure) encodeZSetDataKey(key []byte, member []byte) kv.Key {
+	ek := make([]byte, 0, len(t.prefix)+len(key)+len(member)+30)
+	ek = append(ek, t.prefix...)
+	ek = codec.EncodeBytes(ek, key)
+	ek = codec.EncodeUint(ek, uint64(ZSetData))
+	return codec.EncodeBytes(ek, member)
+}
+
+func (t *TxStructure) zsetScoreKeyPrefix(key []byte) kv.Key {
+	ek := make([]byte, 0, len(t.prefix)+len(key)+24)
+	ek = append(ek, t.prefix...)
+	ek = codec.EncodeBytes(ek, key)
+	return codec.EncodeUint(ek, uint64(ZSetScore))
+}
+
+func (t *TxStructure) encodeZSetScoreKey(key []byte, score float64, member []byte) kv.Key {
+	ek := t.zsetScoreKeyPrefix(key)
+	ek = codec.EncodeFloat(ek, score)
+	return codec.EncodeBytes(ek, member)
+}
+
+// decodeZSetScoreKey decodes the score and the member of a score key, the key must have the score key prefix.
+func (t *TxStructure) decodeZSetScoreKey(ek kv.Key, prefix kv.Key) (float64, []byte, error) {
+	ek = ek[len(prefix):]
+	ek, score, err := codec.DecodeFloat(ek)
+	if err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	_, member, err := codec.DecodeBytes(ek)
+	return score, member, errors.Trace(err)
+}
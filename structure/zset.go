@@ -0,0 +1,215 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structure
+
+import (
+	"encoding/binary"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/codec"
+)
+
+// ZSetPair is the pair for (member, score) in a sorted set.
+type ZSetPair struct {
+	Member []byte
+	Score  float64
+}
+
+type zsetMeta struct {
+	MemberCount int64
+}
+
+func (meta zsetMeta) Value() []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf[0:8], uint64(meta.MemberCount))
+	return buf
+}
+
+func (meta zsetMeta) IsEmpty() bool {
+	return meta.MemberCount <= 0
+}
+
+// A sorted set keeps two keys for every member. The data key maps the member to its score, it's used to
+// find the score of a member. The score key is encoded with the score and the member, so the members are
+// scanned in the order of the scores.
+
+// ZAdd adds a member with the score to a sorted set, the score is updated if the member exists.
+func (t *TxStructure) ZAdd(key []byte, score float64, member []byte) error {
+	if t.readWriter == nil {
+		return errWriteOnSnapshot
+	}
+	dataKey := t.encodeZSetDataKey(key, member)
+	oldScore, exists, err := t.loadZSetScore(dataKey)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if exists {
+		if oldScore == score {
+			return nil
+		}
+		if err = t.readWriter.Delete(t.encodeZSetScoreKey(key, oldScore, member)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	// The values can't be empty, so the scores are stored as the values of both keys.
+	scoreValue := codec.EncodeFloat(nil, score)
+	if err = t.readWriter.Set(dataKey, scoreValue); err != nil {
+		return errors.Trace(err)
+	}
+	if err = t.readWriter.Set(t.encodeZSetScoreKey(key, score, member), scoreValue); err != nil {
+		return errors.Trace(err)
+	}
+	if exists {
+		return nil
+	}
+
+	metaKey := t.encodeZSetMetaKey(key)
+	meta, err := t.loadZSetMeta(metaKey)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	meta.MemberCount++
+	return errors.Trace(t.readWriter.Set(metaKey, meta.Value()))
+}
+
+// ZScore gets the score of a member in a sorted set, it returns false if the member doesn't exist.
+func (t *TxStructure) ZScore(key []byte, member []byte) (float64, bool, error) {
+	score, exists, err := t.loadZSetScore(t.encodeZSetDataKey(key, member))
+	return score, exists, errors.Trace(err)
+}
+
+// ZCard gets the number of members in a sorted set.
+func (t *TxStructure) ZCard(key []byte) (int64, error) {
+	meta, err := t.loadZSetMeta(t.encodeZSetMetaKey(key))
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return meta.MemberCount, nil
+}
+
+// ZRangeByScore gets the members whose scores are between min and max in a sorted set, both ends are
+// inclusive. The members are ordered by the scores, the members with the same score are ordered by themselves.
+func (t *TxStructure) ZRangeByScore(key []byte, min float64, max float64) ([]ZSetPair, error) {
+	if min > max {
+		return nil, nil
+	}
+	scorePrefix := t.zsetScoreKeyPrefix(key)
+	it, err := t.reader.Seek(codec.EncodeFloat(append([]byte{}, scorePrefix...), min))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer it.Close()
+
+	var (
+		res    []ZSetPair
+		score  float64
+		member []byte
+	)
+	for it.Valid() && it.Key().HasPrefix(scorePrefix) {
+		score, member, err = t.decodeZSetScoreKey(it.Key(), scorePrefix)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if score > max {
+			break
+		}
+		res = append(res, ZSetPair{Member: member, Score: score})
+		if err = it.Next(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return res, nil
+}
+
+// ZRem removes one or more members from a sorted set, the members that don't exist are ignored.
+func (t *TxStructure) ZRem(key []byte, members ...[]byte) error {
+	if t.readWriter == nil {
+		return errWriteOnSnapshot
+	}
+	metaKey := t.encodeZSetMetaKey(key)
+	meta, err := t.loadZSetMeta(metaKey)
+	if err != nil || meta.IsEmpty() {
+		return errors.Trace(err)
+	}
+
+	var (
+		score  float64
+		exists bool
+	)
+	for _, member := range members {
+		dataKey := t.encodeZSetDataKey(key, member)
+		score, exists, err = t.loadZSetScore(dataKey)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !exists {
+			continue
+		}
+		if err = t.readWriter.Delete(dataKey); err != nil {
+			return errors.Trace(err)
+		}
+		if err = t.readWriter.Delete(t.encodeZSetScoreKey(key, score, member)); err != nil {
+			return errors.Trace(err)
+		}
+		meta.MemberCount--
+	}
+
+	if meta.IsEmpty() {
+		err = t.readWriter.Delete(metaKey)
+	} else {
+		err = t.readWriter.Set(metaKey, meta.Value())
+	}
+	return errors.Trace(err)
+}
+
+func (t *TxStructure) loadZSetMeta(metaKey []byte) (zsetMeta, error) {
+	v, err := t.reader.Get(metaKey)
+	if terror.ErrorEqual(err, kv.ErrNotExist) {
+		err = nil
+	} else if err != nil {
+		return zsetMeta{}, errors.Trace(err)
+	}
+
+	meta := zsetMeta{MemberCount: 0}
+	if v == nil {
+		return meta, nil
+	}
+
+	if len(v) != 8 {
+		return meta, errInvalidZSetMetaData
+	}
+
+	meta.MemberCount = int64(binary.BigEndian.Uint64(v[0:8]))
+	return meta, nil
+}
+
+func (t *TxStructure) loadZSetScore(dataKey []byte) (float64, bool, error) {
+	v, err := t.reader.Get(dataKey)
+	if terror.ErrorEqual(err, kv.ErrNotExist) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, errors.Trace(err)
+	}
+	if v == nil {
+		return 0, false, nil
+	}
+	_, score, err := codec.DecodeFloat(v)
+	if err != nil {
+		return 0, false, errors.Trace(err)
+	}
+	return score, true, nil
+}
@@ -23,10 +23,14 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -123,6 +127,20 @@ func CastValue(ctx context.Context, val types.Datum, col *model.ColumnInfo) (cas
 	if err != nil {
 		return casted, errors.Trace(err)
 	}
+	if casted.Kind() == types.KindString && charset.NeedConversion(col.Charset) {
+		// Check that the string can be stored in the column charset, the characters not in the
+		// charset are replaced with '?'.
+		str := casted.GetString()
+		encoded, ok := charset.EncodeString(col.Charset, str)
+		if !ok {
+			log.Warnf("[%d] incorrect %s value: %x for column %s",
+				ctx.GetSessionVars().ConnectionID, col.Charset, []byte(str), col.Name)
+			str, _ = charset.DecodeString(col.Charset, encoded)
+			casted = types.NewStringDatum(str)
+			err = sc.HandleTruncate(ErrTruncateWrongValue)
+		}
+		return casted, errors.Trace(err)
+	}
 	if ctx.GetSessionVars().SkipUTF8Check {
 		return casted, nil
 	}
@@ -199,6 +217,8 @@ func NewColDesc(col *Column) *ColDesc {
 		extra = "auto_increment"
 	} else if mysql.HasOnUpdateNowFlag(col.Flag) {
 		extra = "on update CURRENT_TIMESTAMP"
+	} else if col.DefaultIsExpr {
+		extra = "DEFAULT_GENERATED"
 	}
 
 	return &ColDesc{
@@ -246,6 +266,18 @@ func (c *Column) CheckNotNull(data types.Datum) error {
 	return nil
 }
 
+// HandleBadNull checks if the data is nil for a column with NotNull flag set. The data is replaced by
+// the zero value of the column with a warning if sc.BadNullAsWarning is true.
+func (c *Column) HandleBadNull(d *types.Datum, sc *variable.StatementContext) error {
+	err := c.CheckNotNull(*d)
+	if err == nil || !sc.BadNullAsWarning {
+		return errors.Trace(err)
+	}
+	*d = GetZeroValue(c.ToInfo())
+	sc.AppendWarning(err)
+	return nil
+}
+
 // IsPKHandleColumn checks if the column is primary key handle column.
 func (c *Column) IsPKHandleColumn(tbInfo *model.TableInfo) bool {
 	return mysql.HasPriKeyFlag(c.Flag) && tbInfo.PKIsHandle
@@ -268,9 +300,47 @@ func GetColOriginDefaultValue(ctx context.Context, col *model.ColumnInfo) (types
 
 // GetColDefaultValue gets default value of the column.
 func GetColDefaultValue(ctx context.Context, col *model.ColumnInfo) (types.Datum, error) {
+	if col.DefaultIsExpr {
+		return getColDefaultExprValue(ctx, col)
+	}
 	return getColDefaultValue(ctx, col, col.DefaultValue)
 }
 
+// ParseDefaultExpr parses the text of the default value expression.
+func ParseDefaultExpr(text string) (ast.ExprNode, error) {
+	stmt, err := parser.New().ParseOneStmt("SELECT "+text, "", "")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sel, ok := stmt.(*ast.SelectStmt)
+	if !ok || len(sel.Fields.Fields) != 1 || sel.From != nil {
+		return nil, errors.Errorf("invalid default value expression %s", text)
+	}
+	return sel.Fields.Fields[0].Expr, nil
+}
+
+// getColDefaultExprValue evaluates the default value expression of the column.
+func getColDefaultExprValue(ctx context.Context, col *model.ColumnInfo) (types.Datum, error) {
+	text, ok := col.DefaultValue.(string)
+	if !ok {
+		return types.Datum{}, errGetDefaultFailed.Gen("Field '%s' get default value fail - invalid expression %v",
+			col.Name, col.DefaultValue)
+	}
+	expr, err := ParseDefaultExpr(text)
+	if err != nil {
+		return types.Datum{}, errGetDefaultFailed.Gen("Field '%s' get default value fail - %s", col.Name, err)
+	}
+	value, err := expression.EvalAstExpr(expr, ctx)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	value, err = CastValue(ctx, value, col)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return value, nil
+}
+
 func getColDefaultValue(ctx context.Context, col *model.ColumnInfo, defaultVal interface{}) (types.Datum, error) {
 	if defaultVal == nil {
 		return getColDefaultValueFromNil(ctx, col)
@@ -305,12 +375,14 @@ func getColDefaultValueFromNil(ctx context.Context, col *model.ColumnInfo) (type
 		// Auto increment column doesn't has default value and we should not return error.
 		return types.Datum{}, nil
 	}
-	if !ctx.GetSessionVars().StrictSQLMode {
-		// Non strict mode use zero value.
-		// TODO: add warning.
+	err := errNoDefaultValue.Gen("Field '%s' doesn't have a default value", col.Name)
+	sc := ctx.GetSessionVars().StmtCtx
+	if !ctx.GetSessionVars().StrictSQLMode || sc.BadNullAsWarning {
+		// Non strict mode and the ignored errors use zero value.
+		sc.AppendWarning(err)
 		return GetZeroValue(col), nil
 	}
-	return types.Datum{}, errNoDefaultValue.Gen("Field '%s' doesn't have a default value", col.Name)
+	return types.Datum{}, err
 }
 
 // GetZeroValue gets zero value for given column type.
@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -102,6 +103,26 @@ func (s *testColumnSuite) TestCheck(c *C) {
 	CheckNotNull(cols, types.MakeDatums(nil))
 }
 
+func (s *testColumnSuite) TestHandleBadNull(c *C) {
+	defer testleak.AfterTest(c)()
+	col := newCol("a")
+	col.Tp = mysql.TypeLong
+	col.Flag = mysql.NotNullFlag
+	sc := new(variable.StatementContext)
+	d := types.NewDatum(nil)
+	c.Assert(terror.ErrorEqual(col.HandleBadNull(&d, sc), errColumnCantNull), IsTrue)
+	c.Assert(d.IsNull(), IsTrue)
+
+	sc.BadNullAsWarning = true
+	c.Assert(col.HandleBadNull(&d, sc), IsNil)
+	c.Assert(d.GetInt64(), Equals, int64(0))
+	c.Assert(sc.WarningCount(), Equals, uint16(1))
+	d = types.NewIntDatum(1)
+	c.Assert(col.HandleBadNull(&d, sc), IsNil)
+	c.Assert(d.GetInt64(), Equals, int64(1))
+	c.Assert(sc.WarningCount(), Equals, uint16(1))
+}
+
 func (s *testColumnSuite) TestDesc(c *C) {
 	defer testleak.AfterTest(c)()
 	col := newCol("a")
@@ -14,9 +14,17 @@
 package table
 
 import (
+	"math"
+	"unicode/utf8"
+
+	"github.com/juju/errors"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/types/json"
 )
 
 // IndexIterator is the interface for iterator of index data on KV store.
@@ -29,15 +37,16 @@ type IndexIterator interface {
 type Index interface {
 	// Meta returns IndexInfo.
 	Meta() *model.IndexInfo
-	// Create supports insert into statement.
+	// Create supports insert into statement. For a multi-valued index, indexedValues is the JSON value
+	// of the column and an entry is created for every value returned by MultiValuedValues.
 	Create(rm kv.RetrieverMutator, indexedValues []types.Datum, h int64) (int64, error)
-	// Delete supports delete from statement.
+	// Delete supports delete from statement. It deletes all the entries of a multi-valued index like Create.
 	Delete(m kv.Mutator, indexedValues []types.Datum, h int64) error
 	// Drop supports drop table, drop index statements.
 	Drop(rm kv.RetrieverMutator) error
 	// Exist supports check index exists or not.
 	Exist(rm kv.RetrieverMutator, indexedValues []types.Datum, h int64) (bool, int64, error)
-	// GenIndexKey generates an index key.
+	// GenIndexKey generates an index key. The values of a multi-valued index should have been expanded.
 	GenIndexKey(indexedValues []types.Datum, h int64) (key []byte, distinct bool, err error)
 	// Seek supports where clause.
 	Seek(r kv.Retriever, indexedValues []types.Datum) (iter IndexIterator, hit bool, err error)
@@ -46,3 +55,68 @@ type Index interface {
 	// FetchValues fetched index column values in a row.
 	FetchValues(row []types.Datum) (columns []types.Datum, err error)
 }
+
+// MultiValuedValues returns the distinct values indexed by the multi-valued key part mv for the JSON value
+// d of the column, which are the elements of the array at the path converted to the type of mv. A non-array
+// value is a single element array, and there is no value if d is NULL or the path doesn't exist.
+func MultiValuedValues(d types.Datum, mv *model.MultiValuedKeyPart) ([]types.Datum, error) {
+	if d.IsNull() {
+		return nil, nil
+	}
+	pe, err := json.ParseJSONPathExpr(mv.Path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	j, found := json.Extract(d.GetMysqlJSON(), pe)
+	if !found {
+		return nil, nil
+	}
+	elems := json.Elements(j)
+	values := make([]types.Datum, 0, len(elems))
+	seen := make(map[string]struct{}, len(elems))
+	for _, elem := range elems {
+		v, err := MultiValuedValue(elem, mv)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		key, err := codec.EncodeKey(nil, v)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if _, ok := seen[string(key)]; ok {
+			continue
+		}
+		seen[string(key)] = struct{}{}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// MultiValuedValue converts a JSON array element to the type of the multi-valued key part mv. Only the
+// integral numbers in range and the strings not longer than the length of the type can be converted.
+func MultiValuedValue(elem json.JSON, mv *model.MultiValuedKeyPart) (types.Datum, error) {
+	v, _ := json.Scalar(elem)
+	switch mv.Tp.Tp {
+	case mysql.TypeLonglong:
+		if f, ok := v.(float64); ok && f == math.Trunc(f) {
+			if mysql.HasUnsignedFlag(mv.Tp.Flag) {
+				if f >= 0 && f < math.MaxUint64 {
+					return types.NewUintDatum(uint64(f)), nil
+				}
+			} else if f >= math.MinInt64 && f < math.MaxInt64 {
+				return types.NewIntDatum(int64(f)), nil
+			}
+		}
+	case mysql.TypeString:
+		if s, ok := v.(string); ok {
+			if mv.Tp.Charset == charset.CharsetBin {
+				if len(s) <= mv.Tp.Flen {
+					return types.NewBytesDatum([]byte(s)), nil
+				}
+			} else if utf8.RuneCountInString(s) <= mv.Tp.Flen {
+				return types.NewStringDatum(s), nil
+			}
+		}
+	}
+	return types.Datum{}, ErrInvalidMultiValuedValue.GenByArgs(elem.String(), mv.TypeString())
+}
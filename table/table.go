@@ -53,8 +53,13 @@ var (
 	ErrIndexStateCantNone = terror.ClassTable.New(codeIndexStateCantNone, "index can not be in none state")
 	// ErrInvalidRecordKey returns for invalid record key.
 	ErrInvalidRecordKey = terror.ClassTable.New(codeInvalidRecordKey, "invalid record key")
+	// ErrTableLocked returns for writing a table locked by bulk loading.
+	ErrTableLocked = terror.ClassTable.New(codeTableLocked, "table is locked by bulk loading")
 	// ErrTruncateWrongValue returns for truncate wrong value for field.
 	ErrTruncateWrongValue = terror.ClassTable.New(codeTruncateWrongValue, "Incorrect value")
+	// ErrInvalidMultiValuedValue returns for a JSON array element which can't be converted to the type of
+	// the multi-valued index.
+	ErrInvalidMultiValuedValue = terror.ClassTable.New(codeInvalidMultiValuedValue, "Invalid JSON value %s for CAST to %s ARRAY")
 )
 
 // RecordIterFunc is used for low-level record iteration.
@@ -120,6 +125,13 @@ type Table interface {
 	Seek(ctx context.Context, h int64) (handle int64, found bool, err error)
 }
 
+// ClusteredTable is implemented by the tables which may have a clustered primary key, see model.IndexInfo.Clustered.
+type ClusteredTable interface {
+	// ClusteredRowWithCols gets the handle and the row by the values of the clustered primary key idx from r.
+	ClusteredRowWithCols(ctx context.Context, r kv.Retriever, idx Index, indexedValues []types.Datum,
+		cols []*Column) (int64, []types.Datum, error)
+}
+
 // TableFromMeta builds a table.Table from *model.TableInfo.
 // Currently, it is assigned to tables.TableFromMeta in tidb package's init function.
 var TableFromMeta func(alloc autoid.Allocator, tblInfo *model.TableInfo) (Table, error)
@@ -138,6 +150,9 @@ const (
 	codeColumnStateNonPublic = 7
 	codeIndexStateCantNone   = 8
 	codeInvalidRecordKey     = 9
+	codeTableLocked          = 10
+
+	codeInvalidMultiValuedValue = 11
 
 	codeColumnCantNull     = 1048
 	codeUnknownColumn      = 1054
@@ -97,6 +97,8 @@ type index struct {
 	tblInfo *model.TableInfo
 	idxInfo *model.IndexInfo
 	prefix  kv.Key
+	// mv is the key part of a multi-valued index, which is the only key part.
+	mv *model.MultiValuedKeyPart
 }
 
 // NewIndex builds a new Index object.
@@ -106,6 +108,9 @@ func NewIndex(tableInfo *model.TableInfo, indexInfo *model.IndexInfo) table.Inde
 		idxInfo: indexInfo,
 		prefix:  kv.Key(tablecodec.EncodeTableIndexPrefix(tableInfo.ID, indexInfo.ID)),
 	}
+	if indexInfo.IsMultiValued() {
+		index.mv = indexInfo.Columns[0].MultiValued
+	}
 	return index
 }
 
@@ -160,6 +165,23 @@ func (c *index) GenIndexKey(indexedValues []types.Datum, h int64) (key []byte, d
 // If the index is unique and there is an existing entry with the same key,
 // Create will return the existing entry's handle as the first return value, ErrKeyExists as the second return value.
 func (c *index) Create(rm kv.RetrieverMutator, indexedValues []types.Datum, h int64) (int64, error) {
+	if c.mv != nil {
+		values, err := table.MultiValuedValues(indexedValues[0], c.mv)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		// A multi-valued index is never unique.
+		for _, v := range values {
+			if _, err = c.create(rm, []types.Datum{v}, h); err != nil {
+				return 0, errors.Trace(err)
+			}
+		}
+		return 0, nil
+	}
+	return c.create(rm, indexedValues, h)
+}
+
+func (c *index) create(rm kv.RetrieverMutator, indexedValues []types.Datum, h int64) (int64, error) {
 	key, distinct, err := c.GenIndexKey(indexedValues, h)
 	if err != nil {
 		return 0, errors.Trace(err)
@@ -184,6 +206,25 @@ func (c *index) Create(rm kv.RetrieverMutator, indexedValues []types.Datum, h in
 
 // Delete removes the entry for handle h and indexdValues from KV index.
 func (c *index) Delete(m kv.Mutator, indexedValues []types.Datum, h int64) error {
+	if c.mv != nil {
+		values, err := table.MultiValuedValues(indexedValues[0], c.mv)
+		if table.ErrInvalidMultiValuedValue.Equal(err) {
+			// The value can't be written to the index, so there is no entry.
+			return nil
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		for _, v := range values {
+			if err = c.delete(m, []types.Datum{v}, h); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	}
+	return c.delete(m, indexedValues, h)
+}
+
+func (c *index) delete(m kv.Mutator, indexedValues []types.Datum, h int64) error {
 	key, _, err := c.GenIndexKey(indexedValues, h)
 	if err != nil {
 		return errors.Trace(err)
@@ -245,6 +286,23 @@ func (c *index) SeekFirst(r kv.Retriever) (iter table.IndexIterator, err error)
 }
 
 func (c *index) Exist(rm kv.RetrieverMutator, indexedValues []types.Datum, h int64) (bool, int64, error) {
+	if c.mv != nil {
+		// The entries of a multi-valued index exist if all of them exist.
+		values, err := table.MultiValuedValues(indexedValues[0], c.mv)
+		if err != nil {
+			return false, 0, errors.Trace(err)
+		}
+		for _, v := range values {
+			if exist, _, err := c.exist(rm, []types.Datum{v}, h); !exist || err != nil {
+				return false, 0, errors.Trace(err)
+			}
+		}
+		return true, h, nil
+	}
+	return c.exist(rm, indexedValues, h)
+}
+
+func (c *index) exist(rm kv.RetrieverMutator, indexedValues []types.Datum, h int64) (bool, int64, error) {
 	key, distinct, err := c.GenIndexKey(indexedValues, h)
 	if err != nil {
 		return false, 0, errors.Trace(err)
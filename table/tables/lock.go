@@ -0,0 +1,66 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tables
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/table"
+)
+
+// bulkLoadLocks holds the tables being bulk loaded, transactional writes to them are
+// refused until the locks are released. The locks are local to this TiDB server.
+var bulkLoadLocks = struct {
+	sync.RWMutex
+	// count is the number of locked tables, it's a fast path for the common case.
+	count  int32
+	tables map[int64]struct{}
+}{tables: make(map[int64]struct{})}
+
+// LockForBulkLoad locks the table for bulk loading.
+func LockForBulkLoad(tableID int64) error {
+	bulkLoadLocks.Lock()
+	defer bulkLoadLocks.Unlock()
+	if _, ok := bulkLoadLocks.tables[tableID]; ok {
+		return errors.Trace(table.ErrTableLocked)
+	}
+	bulkLoadLocks.tables[tableID] = struct{}{}
+	atomic.AddInt32(&bulkLoadLocks.count, 1)
+	return nil
+}
+
+// UnlockForBulkLoad releases the lock of the table acquired by LockForBulkLoad.
+func UnlockForBulkLoad(tableID int64) {
+	bulkLoadLocks.Lock()
+	defer bulkLoadLocks.Unlock()
+	if _, ok := bulkLoadLocks.tables[tableID]; ok {
+		delete(bulkLoadLocks.tables, tableID)
+		atomic.AddInt32(&bulkLoadLocks.count, -1)
+	}
+}
+
+func checkBulkLoadLock(tableID int64) error {
+	if atomic.LoadInt32(&bulkLoadLocks.count) == 0 {
+		return nil
+	}
+	bulkLoadLocks.RLock()
+	_, ok := bulkLoadLocks.tables[tableID]
+	bulkLoadLocks.RUnlock()
+	if ok {
+		return table.ErrTableLocked
+	}
+	return nil
+}
@@ -170,6 +170,9 @@ func (t *Table) FirstKey() kv.Key {
 
 // UpdateRecord implements table.Table UpdateRecord interface.
 func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum, newData []types.Datum, touched map[int]bool) error {
+	if err := checkBulkLoadLock(t.ID); err != nil {
+		return errors.Trace(err)
+	}
 	// We should check whether this table has on update column which state is write only.
 	currentData := make([]types.Datum, len(t.WritableCols()))
 	copy(currentData, newData)
@@ -210,6 +213,9 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum
 	if err = t.rebuildIndices(bs, h, touched, oldData, currentData); err != nil {
 		return errors.Trace(err)
 	}
+	if err = t.writeClusteredRow(bs, h, currentData, value); err != nil {
+		return errors.Trace(err)
+	}
 
 	err = bs.SaveTo(txn)
 	if err != nil {
@@ -284,6 +290,9 @@ func (t *Table) rebuildIndices(rm kv.RetrieverMutator, h int64, touched map[int]
 
 // AddRecord implements table.Table AddRecord interface.
 func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64, err error) {
+	if err = checkBulkLoadLock(t.ID); err != nil {
+		return 0, errors.Trace(err)
+	}
 	var hasRecordID bool
 	for _, col := range t.Cols() {
 		if col.IsPKHandleColumn(t.meta) {
@@ -343,6 +352,9 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 	if err = txn.Set(key, value); err != nil {
 		return 0, errors.Trace(err)
 	}
+	if err = t.writeClusteredRow(bs, recordID, r, value); err != nil {
+		return 0, errors.Trace(err)
+	}
 	if err = bs.SaveTo(txn); err != nil {
 		return 0, errors.Trace(err)
 	}
@@ -426,6 +438,26 @@ func (t *Table) addIndices(ctx context.Context, recordID int64, r []types.Datum,
 	return 0, nil
 }
 
+// writeClusteredRow sets the value of the clustered primary key entry of the row to the handle followed by
+// the row value, after the entry has been created with the handle only. See model.IndexInfo.Clustered.
+func (t *Table) writeClusteredRow(m kv.Mutator, h int64, r []types.Datum, value []byte) error {
+	for _, v := range t.indices {
+		if v == nil || !v.Meta().Clustered {
+			continue
+		}
+		colVals, err := v.FetchValues(r)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		key, _, err := v.GenIndexKey(colVals, h)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(m.Set(key, append(encodeHandle(h), value...)))
+	}
+	return nil
+}
+
 // RowWithCols implements table.Table RowWithCols interface.
 func (t *Table) RowWithCols(ctx context.Context, h int64, cols []*table.Column) ([]types.Datum, error) {
 	// Get raw row data from kv.
@@ -434,7 +466,34 @@ func (t *Table) RowWithCols(ctx context.Context, h int64, cols []*table.Column)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	// Decode raw row data.
+	return t.decodeRowWithCols(ctx, h, value, cols)
+}
+
+// ClusteredRowWithCols gets the handle and the row of the table by the values of its clustered primary key
+// idx from the entry of the key in r, without reading the record. It returns kv.ErrNotExist if there's no
+// such row.
+func (t *Table) ClusteredRowWithCols(ctx context.Context, r kv.Retriever, idx table.Index, indexedValues []types.Datum,
+	cols []*table.Column) (int64, []types.Datum, error) {
+	if !idx.Meta().Clustered {
+		return 0, nil, errors.Errorf("index %s is not clustered", idx.Meta().Name)
+	}
+	key, _, err := idx.GenIndexKey(indexedValues, 0)
+	if err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	value, err := r.Get(key)
+	if err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	h, err := decodeHandle(value)
+	if err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	row, err := t.decodeRowWithCols(ctx, h, value[8:], cols)
+	return h, row, errors.Trace(err)
+}
+
+func (t *Table) decodeRowWithCols(ctx context.Context, h int64, value []byte, cols []*table.Column) ([]types.Datum, error) {
 	v := make([]types.Datum, len(cols))
 	colTps := make(map[int64]*types.FieldType, len(cols))
 	for i, col := range cols {
@@ -494,7 +553,11 @@ func (t *Table) Row(ctx context.Context, h int64) ([]types.Datum, error) {
 
 // RemoveRecord implements table.Table RemoveRecord interface.
 func (t *Table) RemoveRecord(ctx context.Context, h int64, r []types.Datum) error {
-	err := t.removeRowData(ctx, h)
+	err := checkBulkLoadLock(t.ID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = t.removeRowData(ctx, h)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -286,3 +286,69 @@ func (ts *testSuite) TestIterRecords(c *C) {
 	c.Assert(totalCount, Equals, 2)
 	c.Assert(ctx.Txn().Commit(), IsNil)
 }
+
+func (ts *testSuite) TestClusteredIndex(c *C) {
+	defer testleak.AfterTest(c)()
+	_, err := ts.se.Execute("set @@tidb_enable_clustered_index = 1")
+	c.Assert(err, IsNil)
+	_, err = ts.se.Execute("CREATE TABLE test.tCluster (a varchar(10), b int, c int, primary key (a, b), unique key (c))")
+	c.Assert(err, IsNil)
+	_, err = ts.se.Execute("set @@tidb_enable_clustered_index = 0")
+	c.Assert(err, IsNil)
+	ctx := ts.se.(context.Context)
+	dom := sessionctx.GetDomain(ctx)
+	tb, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("tCluster"))
+	c.Assert(err, IsNil)
+	pk := tb.Indices()[0]
+	c.Assert(pk.Meta().Primary, IsTrue)
+	c.Assert(pk.Meta().Clustered, IsTrue)
+	c.Assert(tb.Indices()[1].Meta().Clustered, IsFalse)
+
+	// The value of the primary key entry is the handle followed by the record value.
+	checkEntry := func(vals []types.Datum, h int64) {
+		key, _, err1 := pk.GenIndexKey(vals, h)
+		c.Assert(err1, IsNil)
+		value, err1 := ctx.Txn().Get(key)
+		c.Assert(err1, IsNil)
+		record, err1 := ctx.Txn().Get(tb.RecordKey(h))
+		c.Assert(err1, IsNil)
+		c.Assert(value[:8], DeepEquals, []byte{0, 0, 0, 0, 0, 0, 0, byte(h)})
+		c.Assert(value[8:], DeepEquals, []byte(record))
+	}
+	c.Assert(ctx.NewTxn(), IsNil)
+	h, err := tb.AddRecord(ctx, types.MakeDatums("abc", 1, 10))
+	c.Assert(err, IsNil)
+	checkEntry(types.MakeDatums("abc", 1), h)
+	exist, dupHandle, err := pk.Exist(ctx.Txn(), types.MakeDatums("abc", 1), h)
+	c.Assert(err, IsNil)
+	c.Assert(exist, IsTrue)
+	c.Assert(dupHandle, Equals, h)
+	_, err = tb.AddRecord(ctx, types.MakeDatums("abc", 1, 11))
+	c.Assert(kv.ErrKeyExists.Equal(err), IsTrue)
+
+	// Updating a column not in the primary key rewrites the entry too.
+	c.Assert(tb.UpdateRecord(ctx, h, types.MakeDatums("abc", 1, 10), types.MakeDatums("abc", 1, 20), map[int]bool{2: true}), IsNil)
+	checkEntry(types.MakeDatums("abc", 1), h)
+	c.Assert(tb.UpdateRecord(ctx, h, types.MakeDatums("abc", 1, 20), types.MakeDatums("abd", 1, 20), map[int]bool{0: true}), IsNil)
+	checkEntry(types.MakeDatums("abd", 1), h)
+	exist, _, err = pk.Exist(ctx.Txn(), types.MakeDatums("abc", 1), h)
+	c.Assert(err, IsNil)
+	c.Assert(exist, IsFalse)
+
+	c.Assert(tb.RemoveRecord(ctx, h, types.MakeDatums("abd", 1, 20)), IsNil)
+	cnt, err := countEntriesWithPrefix(ctx, tb.IndexPrefix())
+	c.Assert(err, IsNil)
+	c.Assert(cnt, Equals, 0)
+	c.Assert(ctx.Txn().Commit(), IsNil)
+
+	_, err = ts.se.Execute("insert test.tCluster values ('a', 1, 1), ('b', 2, 2), ('c', 3, 3)")
+	c.Assert(err, IsNil)
+	_, err = ts.se.Execute("update test.tCluster set a = 'd', c = 4 where b = 3")
+	c.Assert(err, IsNil)
+	_, err = ts.se.Execute("use test")
+	c.Assert(err, IsNil)
+	_, err = ts.se.Execute("admin check table tCluster")
+	c.Assert(err, IsNil)
+	_, err = ts.se.Execute("drop table test.tCluster")
+	c.Assert(err, IsNil)
+}
@@ -0,0 +1,208 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablecodec
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Row formats.
+const (
+	// RowFormatV1 stores the column IDs and the values in turn, a column is located by
+	// decoding all the columns before it.
+	RowFormatV1 = 1
+	// RowFormatV2 stores the sorted column IDs and the end offsets of the values before the
+	// values, a column is located by a binary search without decoding the other columns.
+	// The tables don't write it yet, because the coprocessor of TiKV can't decode it.
+	RowFormatV2 = 2
+)
+
+// rowV2Flag is the first byte of a row in format V2. The rows in format V1 start with a
+// codec flag, which is always less than it.
+const rowV2Flag byte = 128
+
+// Row layout in format V2: flag(1 byte), column count(4 bytes), column IDs(4 bytes each),
+// end offsets(4 bytes each), values. The integers are in little endian, the column IDs are in ascending order and the end offsets
+// are relative to the start of the values.
+const (
+	rowV2CountLen  = 4
+	rowV2IDLen     = 4
+	rowV2OffsetLen = 4
+)
+
+type rowV2Column struct {
+	id    int64
+	value types.Datum
+}
+
+type byColumnID []rowV2Column
+
+func (s byColumnID) Len() int           { return len(s) }
+func (s byColumnID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byColumnID) Less(i, j int) bool { return s[i].id < s[j].id }
+
+// EncodeRowV2 encodes row data and column ids into a slice of byte in RowFormatV2.
+func EncodeRowV2(row []types.Datum, colIDs []int64, loc *time.Location) ([]byte, error) {
+	if len(row) != len(colIDs) {
+		return nil, errors.Errorf("EncodeRow error: data and columnID count not match %d vs %d", len(row), len(colIDs))
+	}
+	cols := make([]rowV2Column, len(row))
+	for i, c := range row {
+		id := colIDs[i]
+		if id < 0 || id > math.MaxUint32 {
+			return nil, errors.Errorf("EncodeRow error: invalid column ID %d", id)
+		}
+		fc, err := flatten(c, loc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		cols[i] = rowV2Column{id: id, value: fc}
+	}
+	sort.Sort(byColumnID(cols))
+
+	n := len(cols)
+	idsStart := 1 + rowV2CountLen
+	offsetsStart := idsStart + n*rowV2IDLen
+	valuesStart := offsetsStart + n*rowV2OffsetLen
+	buf := make([]byte, valuesStart, valuesStart+n*9)
+	buf[0] = rowV2Flag
+	binary.LittleEndian.PutUint32(buf[1:], uint32(n))
+	var err error
+	for i, col := range cols {
+		binary.LittleEndian.PutUint32(buf[idsStart+i*rowV2IDLen:], uint32(col.id))
+		buf, err = codec.EncodeValue(buf, col.value)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		binary.LittleEndian.PutUint32(buf[offsetsStart+i*rowV2OffsetLen:], uint32(len(buf)-valuesStart))
+	}
+	return buf, nil
+}
+
+// IsRowV2 checks whether the row data is encoded in RowFormatV2.
+func IsRowV2(data []byte) bool {
+	return len(data) > 0 && data[0] == rowV2Flag
+}
+
+// rowV2 is a parsed row in RowFormatV2, the values are not decoded.
+type rowV2 struct {
+	ids     []byte
+	offsets []byte
+	values  []byte
+}
+
+func parseRowV2(data []byte) (rowV2, error) {
+	if len(data) < 1+rowV2CountLen {
+		return rowV2{}, errInvalidColumnCount.Gen("invalid row data length %d", len(data))
+	}
+	n := int(binary.LittleEndian.Uint32(data[1:]))
+	idsStart := 1 + rowV2CountLen
+	offsetsStart := idsStart + n*rowV2IDLen
+	valuesStart := offsetsStart + n*rowV2OffsetLen
+	if valuesStart > len(data) {
+		return rowV2{}, errInvalidColumnCount.Gen("invalid column count %d for row data length %d", n, len(data))
+	}
+	r := rowV2{
+		ids:     data[idsStart:offsetsStart],
+		offsets: data[offsetsStart:valuesStart],
+		values:  data[valuesStart:],
+	}
+	if n > 0 && int(r.endOffset(n-1)) != len(r.values) {
+		return rowV2{}, errors.Errorf("invalid row data, values length %d vs %d", r.endOffset(n-1), len(r.values))
+	}
+	return r, nil
+}
+
+func (r rowV2) numCols() int {
+	return len(r.ids) / rowV2IDLen
+}
+
+func (r rowV2) colID(i int) int64 {
+	return int64(binary.LittleEndian.Uint32(r.ids[i*rowV2IDLen:]))
+}
+
+func (r rowV2) endOffset(i int) uint32 {
+	return binary.LittleEndian.Uint32(r.offsets[i*rowV2OffsetLen:])
+}
+
+// value returns the encoded value of the i-th column.
+func (r rowV2) value(i int) ([]byte, error) {
+	var start uint32
+	if i > 0 {
+		start = r.endOffset(i - 1)
+	}
+	end := r.endOffset(i)
+	if start > end || int(end) > len(r.values) {
+		return nil, errors.Errorf("invalid row data, value offsets [%d, %d)", start, end)
+	}
+	return r.values[start:end], nil
+}
+
+// find returns the encoded value of the column, the value is nil if the column is not in the row.
+func (r rowV2) find(id int64) ([]byte, error) {
+	n := r.numCols()
+	i := sort.Search(n, func(i int) bool { return r.colID(i) >= id })
+	if i == n || r.colID(i) != id {
+		return nil, nil
+	}
+	return r.value(i)
+}
+
+// decodeRowV2 decodes the columns in cols from a row in RowFormatV2.
+func decodeRowV2(data []byte, cols map[int64]*types.FieldType, loc *time.Location) (map[int64]types.Datum, error) {
+	r, err := parseRowV2(data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	row := make(map[int64]types.Datum, len(cols))
+	for id, ft := range cols {
+		b, err := r.find(id)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if b == nil {
+			continue
+		}
+		v, err := DecodeColumnValue(b, ft, loc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row[id] = v
+	}
+	return row, nil
+}
+
+// cutRowV2 cuts the encoded values of the columns in colIDs from a row in RowFormatV2, the
+// value of a column is placed at its offset in the result.
+func cutRowV2(data []byte, colIDs map[int64]int) ([][]byte, error) {
+	r, err := parseRowV2(data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	row := make([][]byte, len(colIDs))
+	for id, offset := range colIDs {
+		row[offset], err = r.find(id)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return row, nil
+}
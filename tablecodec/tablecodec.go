@@ -256,7 +256,7 @@ func DecodeColumnValue(data []byte, ft *types.FieldType, loc *time.Location) (ty
 }
 
 // DecodeRow decodes a byte slice into datums.
-// Row layout: colID1, value1, colID2, value2, .....
+// Row layout: colID1, value1, colID2, value2, ..... or RowFormatV2.
 func DecodeRow(b []byte, cols map[int64]*types.FieldType, loc *time.Location) (map[int64]types.Datum, error) {
 	if b == nil {
 		return nil, nil
@@ -264,6 +264,9 @@ func DecodeRow(b []byte, cols map[int64]*types.FieldType, loc *time.Location) (m
 	if len(b) == 1 && b[0] == codec.NilFlag {
 		return nil, nil
 	}
+	if IsRowV2(b) {
+		return decodeRowV2(b, cols, loc)
+	}
 	row := make(map[int64]types.Datum, len(cols))
 	cnt := 0
 	var (
@@ -316,6 +319,9 @@ func CutRowNew(data []byte, colIDs map[int64]int) ([][]byte, error) {
 	if len(data) == 1 && data[0] == codec.NilFlag {
 		return nil, nil
 	}
+	if IsRowV2(data) {
+		return cutRowV2(data, colIDs)
+	}
 
 	var (
 		cnt int
@@ -357,6 +363,23 @@ func CutRow(data []byte, cols map[int64]*types.FieldType) (map[int64][]byte, err
 	if len(data) == 1 && data[0] == codec.NilFlag {
 		return nil, nil
 	}
+	if IsRowV2(data) {
+		r, err := parseRowV2(data)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row := make(map[int64][]byte, len(cols))
+		for id := range cols {
+			b, err := r.find(id)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if b != nil {
+				row[id] = b
+			}
+		}
+		return row, nil
+	}
 	row := make(map[int64][]byte, len(cols))
 	cnt := 0
 	var (
@@ -422,7 +445,10 @@ func unflatten(datum types.Datum, ft *types.FieldType, loc *time.Location) (type
 		datum.SetMysqlTime(t)
 		return datum, nil
 	case mysql.TypeDuration:
-		dur := types.Duration{Duration: time.Duration(datum.GetInt64())}
+		dur := types.Duration{Duration: time.Duration(datum.GetInt64()), Fsp: ft.Decimal}
+		if dur.Fsp == types.UnspecifiedFsp {
+			dur.Fsp = types.DefaultFsp
+		}
 		datum.SetValue(dur)
 		return datum, nil
 	case mysql.TypeEnum:
@@ -462,6 +488,37 @@ func DecodeIndexKey(key kv.Key) ([]types.Datum, error) {
 	return codec.Decode(b, 1)
 }
 
+// DecodeIndexKeyValues decodes the column values of the index key with the types of the index columns. The handle
+// is appended to the key if the index isn't unique or the values contain NULL, it's decoded and hasHandle is true.
+func DecodeIndexKeyValues(key kv.Key, fts []*types.FieldType, loc *time.Location) (values []types.Datum, handle int64, hasHandle bool, err error) {
+	if len(key) < prefixLen+idLen {
+		return nil, 0, false, errInvalidKey.Gen("invalid index key - %q", key)
+	}
+	b := []byte(key[prefixLen+idLen:])
+	values = make([]types.Datum, 0, len(fts))
+	for _, ft := range fts {
+		var d types.Datum
+		b, d, err = codec.DecodeOne(b)
+		if err != nil {
+			return nil, 0, false, errors.Trace(err)
+		}
+		d, err = unflatten(d, ft, loc)
+		if err != nil {
+			return nil, 0, false, errors.Trace(err)
+		}
+		values = append(values, d)
+	}
+	if len(b) > 0 {
+		var d types.Datum
+		_, d, err = codec.DecodeOne(b)
+		if err != nil {
+			return nil, 0, false, errors.Trace(err)
+		}
+		handle, hasHandle = d.GetInt64(), true
+	}
+	return values, handle, hasHandle, nil
+}
+
 // CutIndexKey cuts encoded index key into colIDs to bytes slices map.
 // The returned value b is the remaining bytes of the key which would be empty if it is unique index or handle data
 // if it is non-unique index.
@@ -180,6 +180,28 @@ func (s *testTableCodecSuite) TestTimeCodec(c *C) {
 	}
 }
 
+func (s *testTableCodecSuite) TestDurationCodec(c *C) {
+	defer testleak.AfterTest(c)()
+
+	dur, err := types.ParseDuration("10:10:10.125", 2)
+	c.Assert(err, IsNil)
+	ft := types.NewFieldType(mysql.TypeDuration)
+	ft.Decimal = 2
+	bs, err := EncodeRow([]types.Datum{types.NewDatum(dur)}, []int64{1}, time.Local)
+	c.Assert(err, IsNil)
+	r, err := DecodeRow(bs, map[int64]*types.FieldType{1: ft}, time.Local)
+	c.Assert(err, IsNil)
+	v := r[1]
+	c.Assert(v.GetMysqlDuration().String(), Equals, "10:10:10.13")
+
+	// Unspecified decimal is decoded with the default fsp.
+	ft.Decimal = types.UnspecifiedLength
+	r, err = DecodeRow(bs, map[int64]*types.FieldType{1: ft}, time.Local)
+	c.Assert(err, IsNil)
+	v = r[1]
+	c.Assert(v.GetMysqlDuration().Fsp, Equals, types.DefaultFsp)
+}
+
 func (s *testTableCodecSuite) TestCutRow(c *C) {
 	defer testleak.AfterTest(c)()
 
@@ -225,6 +247,87 @@ func (s *testTableCodecSuite) TestCutRow(c *C) {
 	}
 }
 
+func (s *testTableCodecSuite) TestRowCodecV2(c *C) {
+	defer testleak.AfterTest(c)()
+
+	ft := types.NewFieldType(mysql.TypeTimestamp)
+	ts, err := types.ParseTimestamp("2016-06-23 11:30:45")
+	c.Assert(err, IsNil)
+	row := []types.Datum{
+		types.NewBytesDatum([]byte("abc")),
+		types.NewIntDatum(100),
+		{},
+		types.NewDatum(ts),
+	}
+	// The column IDs are not sorted.
+	colIDs := []int64{5, 1, 3, 10}
+	fts := []*types.FieldType{
+		types.NewFieldType(mysql.TypeVarchar),
+		types.NewFieldType(mysql.TypeLonglong),
+		types.NewFieldType(mysql.TypeLonglong),
+		ft,
+	}
+	bs, err := EncodeRowV2(row, colIDs, time.Local)
+	c.Assert(err, IsNil)
+	c.Assert(IsRowV2(bs), IsTrue)
+	v1, err := EncodeRow(row, colIDs, time.Local)
+	c.Assert(err, IsNil)
+	c.Assert(IsRowV2(v1), IsFalse)
+
+	sc := new(variable.StatementContext)
+	colMap := make(map[int64]*types.FieldType, len(colIDs))
+	for i, id := range colIDs {
+		colMap[id] = fts[i]
+	}
+	// colMap may contains more columns than encoded row.
+	colMap[4] = types.NewFieldType(mysql.TypeFloat)
+	for _, data := range [][]byte{bs, v1} {
+		r, err := DecodeRow(data, colMap, time.Local)
+		c.Assert(err, IsNil)
+		c.Assert(r, HasLen, len(colIDs))
+		for i, id := range colIDs {
+			v, ok := r[id]
+			c.Assert(ok, IsTrue)
+			equal, err1 := v.CompareDatum(sc, row[i])
+			c.Assert(err1, IsNil)
+			c.Assert(equal, Equals, 0)
+		}
+	}
+
+	// Cut the columns.
+	cut, err := CutRow(bs, map[int64]*types.FieldType{1: fts[1], 4: nil, 10: ft})
+	c.Assert(err, IsNil)
+	c.Assert(cut, HasLen, 2)
+	expect, err := EncodeValue(row[1], time.Local)
+	c.Assert(err, IsNil)
+	c.Assert(cut[1], DeepEquals, expect)
+	values, err := CutRowNew(bs, map[int64]int{5: 0, 4: 1, 1: 2})
+	c.Assert(err, IsNil)
+	c.Assert(values, HasLen, 3)
+	expect, err = EncodeValue(row[0], time.Local)
+	c.Assert(err, IsNil)
+	c.Assert(values[0], DeepEquals, expect)
+	c.Assert(values[1], IsNil)
+	expect, err = EncodeValue(row[1], time.Local)
+	c.Assert(err, IsNil)
+	c.Assert(values[2], DeepEquals, expect)
+
+	// Empty row.
+	bs, err = EncodeRowV2(nil, nil, time.Local)
+	c.Assert(err, IsNil)
+	r, err := DecodeRow(bs, colMap, time.Local)
+	c.Assert(err, IsNil)
+	c.Assert(r, HasLen, 0)
+
+	// Corrupted row.
+	bs, err = EncodeRowV2(row, colIDs, time.Local)
+	c.Assert(err, IsNil)
+	_, err = DecodeRow(bs[:len(bs)-1], colMap, time.Local)
+	c.Assert(err, NotNil)
+	_, err = DecodeRow(bs[:8], colMap, time.Local)
+	c.Assert(err, NotNil)
+}
+
 func (s *testTableCodecSuite) TestCutKeyNew(c *C) {
 	values := []types.Datum{types.NewIntDatum(1), types.NewBytesDatum([]byte("abc")), types.NewFloat64Datum(5.5)}
 	handle := types.NewIntDatum(100)
@@ -268,6 +371,36 @@ func (s *testTableCodecSuite) TestCutKey(c *C) {
 	c.Assert(handleVal, DeepEquals, types.NewIntDatum(100))
 }
 
+func (s *testTableCodecSuite) TestDecodeIndexKeyValues(c *C) {
+	dt, err := types.ParseDatetime("2017-10-18 11:30:45")
+	c.Assert(err, IsNil)
+	values := []types.Datum{types.NewIntDatum(1), types.NewBytesDatum([]byte("abc")), types.NewDatum(dt)}
+	fts := []*types.FieldType{types.NewFieldType(mysql.TypeLonglong), types.NewFieldType(mysql.TypeVarchar),
+		types.NewFieldType(mysql.TypeDatetime)}
+	encodedValue, err := codec.EncodeKey(nil, append(values, types.NewIntDatum(100))...)
+	c.Assert(err, IsNil)
+	indexKey := EncodeIndexSeekKey(4, 5, encodedValue)
+	decoded, handle, hasHandle, err := DecodeIndexKeyValues(indexKey, fts, time.Local)
+	c.Assert(err, IsNil)
+	c.Assert(hasHandle, IsTrue)
+	c.Assert(handle, Equals, int64(100))
+	sc := new(variable.StatementContext)
+	for i, v := range decoded {
+		cmp, err1 := v.CompareDatum(sc, values[i])
+		c.Assert(err1, IsNil)
+		c.Assert(cmp, Equals, 0)
+	}
+
+	// The unique index key doesn't have the handle.
+	encodedValue, err = codec.EncodeKey(nil, values...)
+	c.Assert(err, IsNil)
+	_, _, hasHandle, err = DecodeIndexKeyValues(EncodeIndexSeekKey(4, 5, encodedValue), fts, time.Local)
+	c.Assert(err, IsNil)
+	c.Assert(hasHandle, IsFalse)
+	_, _, _, err = DecodeIndexKeyValues(indexKey[:10], fts, time.Local)
+	c.Assert(err, NotNil)
+}
+
 func (s *testTableCodecSuite) TestIndexKey(c *C) {
 	tableID := int64(4)
 	indexID := int64(5)
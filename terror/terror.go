@@ -82,6 +82,9 @@ const (
 	ClassGlobal
 	ClassMockTikv
 	ClassJSON
+	ClassResourceGroup
+	ClassConfig
+	ClassUserLock
 	// Add more as needed.
 )
 
@@ -128,6 +131,12 @@ func (ec ErrClass) String() string {
 		return "global"
 	case ClassMockTikv:
 		return "mocktikv"
+	case ClassResourceGroup:
+		return "resourcegroup"
+	case ClassConfig:
+		return "config"
+	case ClassUserLock:
+		return "userlock"
 	}
 	return strconv.Itoa(int(ec))
 }
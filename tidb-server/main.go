@@ -27,15 +27,21 @@ import (
 	"github.com/ngaut/log"
 	"github.com/ngaut/systimemon"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/ddl"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/perfschema"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/privilege/privileges"
+	"github.com/pingcap/tidb/replication"
 	"github.com/pingcap/tidb/server"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/localstore/boltdb"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/util/kvcache"
 	"github.com/pingcap/tidb/util/printer"
 	"github.com/pingcap/tipb/go-binlog"
 	"github.com/prometheus/client_golang/prometheus"
@@ -62,9 +68,26 @@ var (
 	metricsAddr     = flag.String("metrics-addr", "", "prometheus pushgateway address, leaves it empty will disable prometheus push.")
 	metricsInterval = flag.Int("metrics-interval", 15, "prometheus client push interval in second, set \"0\" to disable prometheus push.")
 	binlogSocket    = flag.String("binlog-socket", "", "socket file to write binlog")
+	binlogFile      = flag.String("binlog-file", "", "local file to write binlog, ignored if binlog-socket is set")
 	runDDL          = flag.Bool("run-ddl", true, "run ddl worker on this tidb-server")
 	retryLimit      = flag.Int("retry-limit", 10, "the maximum number of retries when commit a transaction")
 	skipGrantTable  = flag.Bool("skip-grant-table", false, "This option causes the server to start without using the privilege system at all.")
+	readOnly        = flag.Bool("read-only", false, "refuse the writes of the users without the SUPER privilege, like the global read_only variable.")
+	groupCommit     = flag.Int("group-commit-window", 0, "the time window in microseconds to coalesce commits of small autocommit transactions, set \"0\" to disable group commit.")
+	txnWarnTime     = flag.Int("txn-warn-time", 600, "log a warning for the transactions running longer than the seconds, set \"0\" to disable it.")
+	txnKillTime     = flag.Int("txn-kill-time", 0, "kill the connections whose transactions run longer than the seconds, set \"0\" to disable it.")
+	idleTxnKillTime = flag.Int("idle-txn-kill-time", 0, "kill the connections idle in a transaction longer than the seconds, set \"0\" to disable it.")
+	replMaster      = flag.String("repl-master", "", "address of the MySQL master to replicate from, leaves it empty will disable replication.")
+	replUser        = flag.String("repl-user", "root", "user to connect to the MySQL master")
+	replPassword    = flag.String("repl-password", "", "password to connect to the MySQL master")
+	replServerID    = flag.Uint("repl-server-id", 1001, "server id to register as a slave of the MySQL master")
+	tableCacheSize  = flag.Int("table-cache-size", infoschema.DefTableCacheCapacity, "the number of tables whose metadata is kept in memory, the others are loaded when they are used.")
+	planCacheSize   = flag.Int("plan-cache-size", 0, "the max number of prepared statement plans cached in each session, set it and plan-cache-memory to \"0\" to disable the plan cache.")
+	planCacheMemory = flag.Int64("plan-cache-memory", 0, "the max memory in bytes used by the prepared statement plans cached in each session, set \"0\" to limit the plan cache by plan-cache-size only.")
+	planCachePolicy = flag.String("plan-cache-policy", "LRU", "the eviction policy of the plan cache, [LRU, LFU].")
+	slowThreshold   = flag.Int("slow-threshold", int(executor.DefSlowThreshold/time.Millisecond), "the queries running longer than the milliseconds are logged as slow queries.")
+	configPath      = flag.String("config", "", "path of the JSON configuration file mapping the flag names to their values, the flags given on the command line take precedence. Send SIGHUP or run 'admin reload config' to reload it.")
+	bootstrapDryRun = flag.Bool("bootstrap-dry-run", false, "print the bootstrap and upgrade steps to apply to the store and exit.")
 
 	timeJumpBackCounter = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -87,6 +110,7 @@ func main() {
 		printer.PrintRawTiDBInfo()
 		os.Exit(0)
 	}
+	conf := loadConfig()
 	if *skipGrantTable && !hasRootPrivilege() {
 		log.Error("TiDB run with skip-grant-table need root privilege.")
 		os.Exit(-1)
@@ -96,6 +120,10 @@ func main() {
 	tidb.SetSchemaLease(leaseDuration)
 	ddl.RunWorker = *runDDL
 	tidb.SetCommitRetryLimit(*retryLimit)
+	tikv.SetGroupCommitWindow(time.Duration(*groupCommit) * time.Microsecond)
+	infoschema.SetTableCacheCapacity(*tableCacheSize)
+	executor.SetPreparedPlanCache(*planCacheSize, *planCacheMemory, parsePlanCachePolicy())
+	executor.SetSlowThreshold(time.Duration(*slowThreshold) * time.Millisecond)
 
 	cfg := &server.Config{
 		Addr:         fmt.Sprintf("%s:%s", *host, *port),
@@ -105,6 +133,11 @@ func main() {
 		ReportStatus: *reportStatus,
 		Store:        *store,
 		StorePath:    *storePath,
+		BinlogFile:   *binlogFile,
+
+		TxnWarnTime:     time.Duration(*txnWarnTime) * time.Second,
+		TxnKillTime:     time.Duration(*txnKillTime) * time.Second,
+		IdleTxnKillTime: time.Duration(*idleTxnKillTime) * time.Second,
 	}
 
 	// set log options
@@ -117,23 +150,28 @@ func main() {
 		log.SetHighlighting(false)
 	}
 
-	if joinCon != nil && *joinCon > 0 {
-		plan.JoinConcurrency = *joinCon
-	}
+	setJoinConcurrency()
 	plan.AllowCartesianProduct = *crossJoin
 	// Call this before setting log level to make sure that TiDB info could be printed.
 	printer.PrintTiDBInfo()
 	log.SetLevelByString(cfg.LogLevel)
 
 	store := createStore()
+	if *bootstrapDryRun {
+		printBootstrapSteps(store)
+		os.Exit(0)
+	}
 
 	if *enablePS {
 		perfschema.EnablePerfSchema()
 	}
 	privileges.Enable = *enablePrivilege
 	privileges.SkipWithGrant = *skipGrantTable
+	variable.SetServerReadOnly(*readOnly)
 	if *binlogSocket != "" {
 		createBinlogClient()
+	} else if *binlogFile != "" {
+		createFileBinlogClient()
 	}
 
 	// Bootstrap a session to load information schema.
@@ -142,6 +180,17 @@ func main() {
 		log.Fatal(errors.ErrorStack(err))
 	}
 
+	var syncer *replication.Syncer
+	if *replMaster != "" {
+		syncer = replication.NewSyncer(store, &replication.Config{
+			MasterAddr: *replMaster,
+			User:       *replUser,
+			Password:   *replPassword,
+			ServerID:   uint32(*replServerID),
+		})
+		syncer.Start()
+	}
+
 	var driver server.IDriver
 	driver = server.NewTiDBDriver(store)
 	var svr *server.Server
@@ -158,10 +207,18 @@ func main() {
 		syscall.SIGQUIT)
 
 	go func() {
-		sig := <-sc
-		log.Infof("Got signal [%d] to exit.", sig)
-		svr.Close()
-		os.Exit(0)
+		for sig := range sc {
+			if sig == syscall.SIGHUP && conf != nil {
+				reloadConfig()
+				continue
+			}
+			log.Infof("Got signal [%d] to exit.", sig)
+			if syncer != nil {
+				syncer.Close()
+			}
+			svr.Close()
+			os.Exit(0)
+		}
 	}()
 
 	prometheus.MustRegister(timeJumpBackCounter)
@@ -174,6 +231,66 @@ func main() {
 	log.Error(svr.Run())
 }
 
+// loadConfig loads the configuration file and registers the flags which can be reloaded without restarting
+// the server, it returns nil if the server is started without a configuration file.
+func loadConfig() *config.Config {
+	if *configPath == "" {
+		return nil
+	}
+	conf := config.New(*configPath, flag.CommandLine)
+	conf.HotReload("L", func() { log.SetLevelByString(*logLevel) })
+	conf.HotReload("slow-threshold", func() { executor.SetSlowThreshold(time.Duration(*slowThreshold) * time.Millisecond) })
+	conf.HotReload("retry-limit", func() { tidb.SetCommitRetryLimit(*retryLimit) })
+	conf.HotReload("group-commit-window", func() { tikv.SetGroupCommitWindow(time.Duration(*groupCommit) * time.Microsecond) })
+	conf.HotReload("join-concurrency", setJoinConcurrency)
+	conf.HotReload("cross-join", func() { plan.AllowCartesianProduct = *crossJoin })
+	conf.HotReload("read-only", func() { variable.SetServerReadOnly(*readOnly) })
+	if err := conf.Load(); err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	config.SetServerConfig(conf)
+	return conf
+}
+
+// reloadConfig reloads the configuration file on SIGHUP.
+func reloadConfig() {
+	changes, err := config.ReloadServerConfig()
+	if err != nil {
+		log.Errorf("[config] reload configuration file %s failed: %v", *configPath, errors.ErrorStack(err))
+		return
+	}
+	log.Infof("[config] reload configuration file %s, %d keys changed", *configPath, len(changes))
+	for _, change := range changes {
+		if change.Reloaded {
+			log.Infof("[config] %s is changed from %s to %s", change.Key, change.OldValue, change.NewValue)
+		} else {
+			log.Warnf("[config] %s can't be hot reloaded, it's changed from %s to %s after restarting the server",
+				change.Key, change.OldValue, change.NewValue)
+		}
+	}
+}
+
+// printBootstrapSteps prints the steps BootstrapSession would apply to the store.
+func printBootstrapSteps(store kv.Storage) {
+	steps, err := tidb.PendingBootstrapSteps(store)
+	if err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	if len(steps) == 0 {
+		fmt.Println("The store is bootstrapped and up to date.")
+		return
+	}
+	for _, step := range steps {
+		fmt.Printf("version %d: %s\n", step.Version, step.Description)
+	}
+}
+
+func setJoinConcurrency() {
+	if *joinCon > 0 {
+		plan.JoinConcurrency = *joinCon
+	}
+}
+
 func createStore() kv.Storage {
 	fullPath := fmt.Sprintf("%s://%s", *store, *storePath)
 	store, err := tidb.NewStore(fullPath)
@@ -195,6 +312,15 @@ func createBinlogClient() {
 	log.Infof("created binlog client at %s", *binlogSocket)
 }
 
+func createFileBinlogClient() {
+	client, err := binloginfo.NewFileClient(*binlogFile)
+	if err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	binloginfo.PumpClient = client
+	log.Infof("created file binlog client at %s", *binlogFile)
+}
+
 // Prometheus push.
 const zeroDuration = time.Duration(0)
 
@@ -237,6 +363,14 @@ func parseLease() time.Duration {
 	return dur
 }
 
+func parsePlanCachePolicy() kvcache.Policy {
+	policy, err := kvcache.ParsePolicy(*planCachePolicy)
+	if err != nil {
+		log.Fatalf("invalid plan cache policy %s", *planCachePolicy)
+	}
+	return policy
+}
+
 func hasRootPrivilege() bool {
 	return os.Geteuid() == 0
 }
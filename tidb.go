@@ -35,6 +35,7 @@ import (
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/store/localstore/engine"
 	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -136,12 +137,24 @@ func Parse(ctx context.Context, src string) ([]ast.StmtNode, error) {
 // Before every execution, we must clear statement context.
 func resetStmtCtx(ctx context.Context, s ast.StmtNode) {
 	sessVars := ctx.GetSessionVars()
+	prevSC := sessVars.StmtCtx
+	sessVars.PrevWarningCount, sessVars.PrevErrorCount = prevSC.TotalWarningCount(), prevSC.ErrorCount()
 	sc := new(variable.StatementContext)
-	switch s.(type) {
+	sc.MaxErrorCount = sessVars.MaxErrorCount
+	if trace, ok := s.(*ast.TraceStmt); ok {
+		// The traced statement is executed, so its context is set as executing it directly.
+		s = trace.Stmt
+	}
+	switch x := s.(type) {
 	case *ast.UpdateStmt, *ast.InsertStmt, *ast.DeleteStmt:
 		sc.IgnoreTruncate = false
 		sc.TruncateAsWarning = !sessVars.StrictSQLMode
-		if _, ok := s.(*ast.InsertStmt); !ok {
+		if insert, ok := x.(*ast.InsertStmt); ok {
+			// Like MySQL, a NULL written to a NOT NULL column is an error for the single-row inserts
+			// even if the sql_mode isn't strict.
+			multiRows := len(insert.Lists) > 1 || insert.Select != nil
+			sc.BadNullAsWarning = insert.Ignore || (multiRows && !sessVars.StrictSQLMode)
+		} else {
 			sc.InUpdateOrDeleteStmt = true
 		}
 	case *ast.CreateTableStmt, *ast.AlterTableStmt:
@@ -149,6 +162,7 @@ func resetStmtCtx(ctx context.Context, s ast.StmtNode) {
 		sc.IgnoreTruncate = false
 		sc.TruncateAsWarning = false
 	case *ast.LoadDataStmt:
+		sc.BadNullAsWarning = true
 		if variable.GoSQLDriverTest {
 			sc.IgnoreTruncate = true
 			break
@@ -158,9 +172,9 @@ func resetStmtCtx(ctx context.Context, s ast.StmtNode) {
 	default:
 		sc.IgnoreTruncate = true
 		if show, ok := s.(*ast.ShowStmt); ok {
-			if show.Tp == ast.ShowWarnings {
+			if show.Tp == ast.ShowWarnings || show.Tp == ast.ShowErrors {
 				sc.InShowWarning = true
-				sc.SetWarnings(sessVars.StmtCtx.GetWarnings())
+				sc.KeepWarnings(prevSC)
 			}
 		}
 	}
@@ -187,9 +201,22 @@ func runStmt(ctx context.Context, s ast.Statement) (ast.RecordSet, error) {
 	var err error
 	var rs ast.RecordSet
 	se := ctx.(*session)
+	// The statements executed by a statement, like the ones updating the privilege tables for GRANT,
+	// are parts of it, they share its savepoint.
+	nested := se.inStmt
+	if !nested {
+		se.inStmt = true
+		se.startStmtSavepoint()
+	}
 	rs, err = s.Exec(ctx)
-	// All the history should be added here.
-	getHistory(ctx).add(0, s, se.sessionVars.StmtCtx)
+	if !nested {
+		err = se.endStmt(err)
+	}
+	// All the history should be added here, the failed statements are rolled back so they're not retried.
+	hist := getHistory(ctx)
+	if err == nil {
+		hist.add(0, s, se.sessionVars.StmtCtx)
+	}
 	if !se.sessionVars.InTxn() {
 		if err != nil {
 			log.Info("RollbackTxn for ddl/autocommit error.")
@@ -198,9 +225,21 @@ func runStmt(ctx context.Context, s ast.Statement) (ast.RecordSet, error) {
 			err = se.CommitTxn()
 		}
 	}
+	if kv.ErrTxnTooLarge.Equal(err) || kv.ErrEntryTooLarge.Equal(err) {
+		err = withStatement(err, s.OriginText())
+	}
 	return rs, errors.Trace(err)
 }
 
+// withStatement regenerates the error with the statement that causes it appended to the message.
+func withStatement(err error, stmt string) error {
+	te, ok := errors.Cause(err).(*terror.Error)
+	if !ok {
+		return err
+	}
+	return te.FastGen("%s, statement: %s", te.ToSQLError().Message, stmt)
+}
+
 func getHistory(ctx context.Context) *stmtHistory {
 	hist, ok := ctx.GetSessionVars().TxnCtx.Histroy.(*stmtHistory)
 	if ok {
@@ -13,6 +13,8 @@
 
 package arena
 
+import "sync"
+
 // Allocator pre-allocates memory to reduce memory allocation cost.
 // It is not thread-safe.
 type Allocator interface {
@@ -78,3 +80,31 @@ func (s *SimpleAllocator) AllocWithLen(length int, capacity int) []byte {
 func (s *SimpleAllocator) Reset() {
 	s.off = 0
 }
+
+// blockSize is the size of the memory block allocated by BlockAllocator.
+const blockSize = 32 * 1024
+
+// BlockAllocator allocates memory from large blocks to reduce the number of
+// small allocations. Unlike SimpleAllocator, the allocated memory is never
+// reused, so the returned slices can be kept after the allocator is dropped.
+// It is thread-safe and its zero value is ready to use.
+type BlockAllocator struct {
+	mu    sync.Mutex
+	block []byte
+}
+
+// Alloc allocates memory with 0 len and capacity cap.
+func (b *BlockAllocator) Alloc(capacity int) []byte {
+	if capacity > blockSize/8 {
+		return make([]byte, 0, capacity)
+	}
+	b.mu.Lock()
+	if len(b.block)+capacity > cap(b.block) {
+		b.block = make([]byte, 0, blockSize)
+	}
+	off := len(b.block)
+	b.block = b.block[:off+capacity]
+	slice := b.block[off : off : off+capacity]
+	b.mu.Unlock()
+	return slice
+}
@@ -80,3 +80,34 @@ func TestStdAllocator(t *testing.T) {
 		t.Error("cap not match")
 	}
 }
+
+func TestBlockAllocator(t *testing.T) {
+	var alloc BlockAllocator
+	s1 := alloc.Alloc(10)
+	if len(s1) != 0 || cap(s1) != 10 {
+		t.Error("slice length or cap not match")
+	}
+	s2 := alloc.Alloc(10)
+	s1 = append(s1, "0123456789"...)
+	s2 = append(s2, "abcdefghij"...)
+	// Appending beyond the capacity must not overwrite the next slice.
+	s1 = append(s1, 'x')
+	if string(s1) != "0123456789x" || string(s2) != "abcdefghij" {
+		t.Error("allocated slices overlap", string(s1), string(s2))
+	}
+
+	big := alloc.Alloc(blockSize)
+	if len(big) != 0 || cap(big) != blockSize {
+		t.Error("slice length or cap not match")
+	}
+
+	for i := 0; i < blockSize; i++ {
+		s := alloc.Alloc(100)
+		if len(s) != 0 || cap(s) != 100 {
+			t.Fatal("slice length or cap not match")
+		}
+	}
+	if string(s2) != "abcdefghij" {
+		t.Error("allocated memory is reused")
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulkload
+
+import (
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/distsql"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/binloginfo"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tipb/go-binlog"
+)
+
+// The buffered key-value pairs are flushed when reaching either of the limits.
+const (
+	flushSize  = 16 * 1024 * 1024
+	flushCount = 100 * 1000
+)
+
+// Loader bulk loads rows into an empty table. The rows are encoded into key-value pairs in memory, then
+// written to the storage in large sorted batches. If the storage implements kv.BulkLoader, the batches
+// are ingested beneath the transaction layer, otherwise, like on TiKV, or if binlog is enabled, every
+// batch is committed by an ordinary transaction, which writes the binlog of the batch. The schema version
+// is checked before every batch is written.
+//
+// The storage computes the checksums of the loaded rows and index entries when the loader commits, and
+// they are compared with the checksums of the added rows. The table is cleaned up if the loader fails to
+// commit, or is closed without committing.
+//
+// It's only meant for the initial data migration into a table nobody else writes to. The table is locked
+// against transactional writes until the loader is closed, but the lock is local to this TiDB server, the
+// caller must stop the writes through the others.
+type Loader struct {
+	ctx     context.Context
+	store   kv.Storage
+	tbl     table.Table
+	buf     *kv.BufferStore
+	checker *domain.SchemaChecker
+	// mutation is the binlog of the buffered rows, it's nil if binlog is not enabled.
+	mutation *binlog.TableMutation
+
+	// checksums are of the records, with the ID 0, and every index.
+	checksums map[int64]*checksum
+
+	rows      int64
+	maxHandle int64
+	written   bool
+	committed bool
+	closed    bool
+}
+
+// NewLoader locks the table and creates a Loader for it in the session ctx. The table must be empty.
+func NewLoader(ctx context.Context, tbl table.Table) (*Loader, error) {
+	meta := tbl.Meta()
+	if len(tbl.WritableCols()) != len(tbl.Cols()) {
+		return nil, errors.Errorf("can't bulk load table %s while changing its columns", meta.Name)
+	}
+	for _, idx := range meta.Indices {
+		if idx.Clustered {
+			return nil, errors.Errorf("can't bulk load table %s with clustered primary key", meta.Name)
+		}
+	}
+	dom := sessionctx.GetDomain(ctx)
+	store := dom.Store()
+	if !store.GetClient().IsRequestTypeSupported(kv.ReqTypeChecksum, kv.ReqSubTypeBasic) {
+		return nil, errors.Errorf("can't bulk load table %s, the storage doesn't support checksum", meta.Name)
+	}
+	if err := tables.LockForBulkLoad(meta.ID); err != nil {
+		return nil, errors.Trace(err)
+	}
+	l := &Loader{
+		ctx:     ctx,
+		store:   store,
+		tbl:     tbl,
+		checker: domain.NewSchemaChecker(dom, ctx.GetSessionVars().TxnCtx.SchemaVersion, []int64{meta.ID}),
+	}
+	if binloginfo.PumpClient != nil {
+		l.mutation = &binlog.TableMutation{TableId: meta.ID}
+	}
+	l.initChecksum()
+	err := l.checkEmpty()
+	if err == nil {
+		err = l.resetBuffer()
+	}
+	if err != nil {
+		tables.UnlockForBulkLoad(meta.ID)
+		return nil, errors.Trace(err)
+	}
+	return l, nil
+}
+
+// checksum computes the expected checksum response of the records or an index from the added rows.
+type checksum struct {
+	req     *distsql.ChecksumRequest
+	builder *distsql.ChecksumBuilder
+	prefix  kv.Key
+}
+
+func newChecksum(req *distsql.ChecksumRequest, prefix kv.Key) *checksum {
+	return &checksum{req: req, builder: distsql.NewChecksumBuilder(req), prefix: prefix}
+}
+
+// initChecksum creates the checksums of the records, over all the columns, and of every index.
+func (l *Loader) initChecksum() {
+	meta := l.tbl.Meta()
+	l.checksums = make(map[int64]*checksum, len(meta.Indices)+1)
+	recordIdx := &model.IndexInfo{Columns: make([]*model.IndexColumn, 0, len(meta.Columns))}
+	for _, col := range meta.Columns {
+		recordIdx.Columns = append(recordIdx.Columns, &model.IndexColumn{
+			Name:   col.Name,
+			Offset: col.Offset,
+			Length: types.UnspecifiedLength,
+		})
+	}
+	l.checksums[0] = newChecksum(distsql.NewChecksumRequest(meta, recordIdx, 0, false, 0), l.tbl.RecordPrefix())
+	for _, idx := range l.tbl.Indices() {
+		l.checksums[idx.Meta().ID] = newChecksum(distsql.NewChecksumRequest(meta, idx.Meta(), 0, true, 0),
+			tablecodec.EncodeTableIndexPrefix(meta.ID, idx.Meta().ID))
+	}
+}
+
+// checkEmpty returns an error if the table has any row or index entry.
+func (l *Loader) checkEmpty() error {
+	ver, err := l.store.CurrentVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	snap, err := l.store.GetSnapshot(ver)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	prefix := tablecodec.EncodeTablePrefix(l.tbl.Meta().ID)
+	it, err := snap.Seek(prefix)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer it.Close()
+	if it.Valid() && it.Key().HasPrefix(prefix) {
+		return errors.Errorf("can't bulk load table %s, it's not empty", l.tbl.Meta().Name)
+	}
+	return nil
+}
+
+// AddRow encodes a row and buffers it. The row must contain the values of all the columns,
+// with the auto-increment values filled. It returns kv.ErrKeyExists if the row duplicates
+// the loaded rows on the primary key or unique indices.
+func (l *Loader) AddRow(row []types.Datum) error {
+	meta := l.tbl.Meta()
+	var handle int64
+	hasHandle := false
+	for _, col := range l.tbl.Cols() {
+		if col.IsPKHandleColumn(meta) {
+			handle = row[col.Offset].GetInt64()
+			hasHandle = true
+			break
+		}
+	}
+	if !hasHandle {
+		var err error
+		handle, err = l.tbl.AllocAutoID()
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	// Write to a row buffer first, so a failed row leaves nothing in the loader.
+	bs := kv.NewBufferStore(l.buf)
+	key := l.tbl.RecordKey(handle)
+	_, err := bs.Get(key)
+	if err == nil {
+		return errors.Trace(kv.ErrKeyExists)
+	} else if !kv.IsErrNotFound(err) {
+		return errors.Trace(err)
+	}
+	for _, idx := range l.tbl.Indices() {
+		vals, err := idx.FetchValues(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if _, err = idx.Create(bs, vals, handle); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	colIDs := make([]int64, 0, len(row))
+	vals := make([]types.Datum, 0, len(row))
+	for _, col := range l.tbl.Cols() {
+		if col.IsPKHandleColumn(meta) {
+			continue
+		}
+		if col.DefaultValue == nil && row[col.Offset].IsNull() {
+			continue
+		}
+		colIDs = append(colIDs, col.ID)
+		vals = append(vals, row[col.Offset])
+	}
+	value, err := tablecodec.EncodeRow(vals, colIDs, l.ctx.GetSessionVars().GetTimeZone())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = bs.Set(key, value); err != nil {
+		return errors.Trace(err)
+	}
+	if err = bs.SaveTo(l.buf); err != nil {
+		return errors.Trace(err)
+	}
+	if l.mutation != nil {
+		// prepend handle to the row value
+		handleVal, _ := codec.EncodeValue(nil, types.NewIntDatum(handle))
+		l.mutation.InsertedRows = append(l.mutation.InsertedRows, append(handleVal, value...))
+		l.mutation.Sequence = append(l.mutation.Sequence, binlog.MutationType_Insert)
+	}
+
+	l.rows++
+	if hasHandle && handle > l.maxHandle {
+		l.maxHandle = handle
+	}
+	if l.buf.Size() >= flushSize || l.buf.Len() >= flushCount {
+		return errors.Trace(l.flush())
+	}
+	return nil
+}
+
+// Rows returns the number of the rows added.
+func (l *Loader) Rows() int64 {
+	return l.rows
+}
+
+// Commit writes the buffered rows, verifies the checksums of the table and closes the loader.
+// The table is cleaned up if it fails.
+func (l *Loader) Commit() error {
+	defer l.Close()
+	if err := l.flush(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := l.verify(); err != nil {
+		return errors.Trace(err)
+	}
+	if l.maxHandle > 0 {
+		if err := l.tbl.RebaseAutoID(l.maxHandle, true); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	l.committed = true
+	log.Infof("[bulkload] table %s loaded %d rows", l.tbl.Meta().Name, l.rows)
+	return nil
+}
+
+// Close cleans up the table if the loader isn't committed, and releases the lock of the table.
+func (l *Loader) Close() {
+	if l.closed {
+		return
+	}
+	l.closed = true
+	if l.written && !l.committed {
+		if err := l.cleanup(); err != nil {
+			log.Errorf("[bulkload] clean up table %s error %v", l.tbl.Meta().Name, errors.ErrorStack(err))
+		}
+	}
+	tables.UnlockForBulkLoad(l.tbl.Meta().ID)
+}
+
+func (l *Loader) resetBuffer() error {
+	ver, err := l.store.CurrentVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	snap, err := l.store.GetSnapshot(ver)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	l.buf = kv.NewBufferStore(snap)
+	if l.mutation != nil {
+		l.mutation = &binlog.TableMutation{TableId: l.tbl.Meta().ID}
+	}
+	return nil
+}
+
+func (l *Loader) flush() error {
+	if l.buf.Len() == 0 {
+		return nil
+	}
+	err := l.buf.WalkBuffer(func(k kv.Key, v []byte) error {
+		_, indexID, isRecord, err := tablecodec.DecodeKeyHead(k)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if isRecord {
+			handle, err := tablecodec.DecodeRowKey(k)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			return errors.Trace(l.checksums[0].builder.AddRow(handle, v))
+		}
+		return errors.Trace(l.checksums[indexID].builder.AddIndex(k, v))
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The batch may be partially written if it fails, clean it up when closing.
+	l.written = true
+	if loader, ok := l.store.(kv.BulkLoader); ok && l.mutation == nil {
+		var ver kv.Version
+		ver, err = l.store.CurrentVersion()
+		if err == nil {
+			err = l.checker.Check(ver.Ver)
+		}
+		if err == nil {
+			_, err = loader.BulkLoad(l.buf.MemBuffer)
+		}
+	} else {
+		// TODO: Ingest into TiKV directly when it supports importing SST files.
+		err = kv.RunInNewTxn(l.store, false, func(txn kv.Transaction) error {
+			txn.SetOption(kv.SchemaLeaseChecker, l.checker)
+			if l.mutation != nil {
+				prewriteValue := &binlog.PrewriteValue{
+					SchemaVersion: l.ctx.GetSessionVars().TxnCtx.SchemaVersion,
+					Mutations:     []binlog.TableMutation{*l.mutation},
+				}
+				prewriteData, err := prewriteValue.Marshal()
+				if err != nil {
+					return errors.Trace(err)
+				}
+				txn.SetOption(kv.BinlogData, &binlog.Binlog{
+					Tp:            binlog.BinlogType_Prewrite,
+					PrewriteValue: prewriteData,
+				})
+			}
+			return errors.Trace(l.buf.SaveTo(txn))
+		})
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(l.resetBuffer())
+}
+
+// verify compares the checksums computed by the storage with the expected ones. Only the loaded data is
+// scanned by the storage, because the table was empty.
+func (l *Loader) verify() error {
+	ver, err := l.store.CurrentVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	client := l.store.GetClient()
+	concurrency := l.ctx.GetSessionVars().DistSQLScanConcurrency
+	for id, cs := range l.checksums {
+		req := *cs.req
+		req.StartTs = ver.Ver
+		ranges := []kv.KeyRange{{StartKey: cs.prefix, EndKey: cs.prefix.PrefixNext()}}
+		resp, err := distsql.Checksum(client, l.ctx.GoCtx(), &req, ranges, concurrency)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		expectCount, expectSum := sumDigests(cs.builder.Response())
+		count, sum := sumDigests(resp)
+		if count != expectCount || sum != expectSum {
+			return errors.Errorf("bulk load checksum mismatch for table %s index %d, expect (%d, %d), got (%d, %d)",
+				l.tbl.Meta().Name, id, expectCount, expectSum, count, sum)
+		}
+	}
+	return nil
+}
+
+func sumDigests(resp *distsql.ChecksumResponse) (count int64, sum uint64) {
+	for _, d := range resp.Digests {
+		count += d.Count
+		sum += d.Checksum
+	}
+	return
+}
+
+// cleanup deletes all the data of the table, which is empty before loading.
+func (l *Loader) cleanup() error {
+	prefix := tablecodec.EncodeTablePrefix(l.tbl.Meta().ID)
+	for {
+		var done bool
+		err := kv.RunInNewTxn(l.store, true, func(txn kv.Transaction) error {
+			it, err := txn.Seek(prefix)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			defer it.Close()
+			var keys []kv.Key
+			for it.Valid() && it.Key().HasPrefix(prefix) && len(keys) < flushCount {
+				keys = append(keys, it.Key().Clone())
+				if err = it.Next(); err != nil {
+					return errors.Trace(err)
+				}
+			}
+			for _, k := range keys {
+				if err = txn.Delete(k); err != nil {
+					return errors.Trace(err)
+				}
+			}
+			done = len(keys) < flushCount
+			return nil
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if done {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulkload_test
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/bulkload"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testBulkLoadSuite{})
+
+type testBulkLoadSuite struct{}
+
+func (s *testBulkLoadSuite) TestLocalStore(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	_, ok := store.(kv.BulkLoader)
+	c.Assert(ok, IsTrue)
+	s.testLoad(c, store)
+}
+
+func (s *testBulkLoadSuite) TestTxnFallback(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := tikv.NewMockTikvStore("")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	s.testLoad(c, store)
+}
+
+func (s *testBulkLoadSuite) testLoad(c *C, store kv.Storage) {
+	tidb.SetSchemaLease(0)
+	_, err := tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	tk := testkit.NewTestKit(c, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int primary key auto_increment, a int, b varchar(10), unique key (a), key (b))")
+	ctx := tk.Se.(context.Context)
+
+	is := sessionctx.GetDomain(ctx).InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	l, err := bulkload.NewLoader(ctx, tbl)
+	c.Assert(err, IsNil)
+
+	// The table is locked during loading.
+	_, err = bulkload.NewLoader(ctx, tbl)
+	c.Assert(terror.ErrorEqual(err, table.ErrTableLocked), IsTrue)
+	_, err = tk.Exec("insert t values (1, 1, 'y')")
+	c.Assert(terror.ErrorEqual(err, table.ErrTableLocked), IsTrue)
+
+	for i := 1; i <= 10; i++ {
+		err = l.AddRow(types.MakeDatums(i, i*10, "b"))
+		c.Assert(err, IsNil)
+	}
+	// Duplicate with the loaded rows.
+	err = l.AddRow(types.MakeDatums(2, 1000, "b"))
+	c.Assert(terror.ErrorEqual(err, kv.ErrKeyExists), IsTrue)
+	err = l.AddRow(types.MakeDatums(12, 20, "b"))
+	c.Assert(terror.ErrorEqual(err, kv.ErrKeyExists), IsTrue)
+	c.Assert(l.Rows(), Equals, int64(10))
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("0"))
+
+	c.Assert(l.Commit(), IsNil)
+	tk.MustQuery("select count(*), sum(a) from t").Check(testkit.Rows("10 550"))
+	tk.MustQuery("select id from t where a = 30").Check(testkit.Rows("3"))
+	tk.MustQuery("select count(*) from t where b = 'b'").Check(testkit.Rows("10"))
+	tk.MustExec("admin check table t")
+	tk.MustExec("insert t (a, b) values (11, 'z')")
+	tk.MustQuery("select id from t where a = 11").Check(testkit.Rows("11"))
+
+	// Only an empty table can be loaded.
+	_, err = bulkload.NewLoader(ctx, tbl)
+	c.Assert(err, NotNil)
+	tk.MustExec("delete from t")
+
+	// The table is cleaned up if the checksum mismatches.
+	l, err = bulkload.NewLoader(ctx, tbl)
+	c.Assert(err, IsNil)
+	c.Assert(l.AddRow(types.MakeDatums(100, 1000, "c")), IsNil)
+	err = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		return txn.Set(tbl.RecordKey(101), []byte{codec.NilFlag})
+	})
+	c.Assert(err, IsNil)
+	c.Assert(l.Commit(), ErrorMatches, ".*checksum mismatch.*")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("0"))
+	tk.MustExec("admin check table t")
+
+	// The table is writable after closing the loader, the rows not committed are discarded.
+	l, err = bulkload.NewLoader(ctx, tbl)
+	c.Assert(err, IsNil)
+	c.Assert(l.AddRow(types.MakeDatums(100, 1000, "c")), IsNil)
+	l.Close()
+	tk.MustExec("insert t values (101, 101, 'c')")
+	tk.MustQuery("select count(*) from t where b = 'c'").Check(testkit.Rows("1"))
+	tk.MustExec("drop table t")
+}
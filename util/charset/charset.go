@@ -46,6 +46,7 @@ var charsetInfos = []*Charset{
 	{CharsetUTF8MB4, CollationUTF8MB4, make(map[string]*Collation), "UTF-8 Unicode", 4},
 	{CharsetASCII, CollationASCII, make(map[string]*Collation), "US ASCII", 1},
 	{CharsetLatin1, CollationLatin1, make(map[string]*Collation), "Latin1", 1},
+	{CharsetGBK, CollationGBK, make(map[string]*Collation), "GBK Simplified Chinese", 2},
 	{CharsetBin, CollationBin, make(map[string]*Collation), "binary", 1},
 }
 
@@ -136,6 +137,31 @@ func GetCharsetInfo(cs string) (string, string, error) {
 	return c.Name, c.DefaultCollation, nil
 }
 
+// GetMaxLen returns the max byte length of a character in the charset, it returns 1 for
+// the unknown charsets.
+func GetMaxLen(cs string) int {
+	c, ok := charsets[strings.ToLower(cs)]
+	if !ok {
+		return 1
+	}
+	return c.Maxlen
+}
+
+// GetCollationByID returns the collation with the given ID, it returns an error if the
+// collation or its charset is not supported.
+func GetCollationByID(id int) (*Collation, error) {
+	for _, c := range collations {
+		if c.ID != id {
+			continue
+		}
+		if _, ok := charsets[c.CharsetName]; !ok {
+			return nil, errors.Errorf("Unsupported charset %s", c.CharsetName)
+		}
+		return c, nil
+	}
+	return nil, errors.Errorf("Unknown collation id %d", id)
+}
+
 // GetCollations returns a list for all collations.
 func GetCollations() []*Collation {
 	return collations
@@ -162,6 +188,10 @@ const (
 	CharsetLatin1 = "latin1"
 	// CollationLatin1 is the default collation for CharsetLatin1.
 	CollationLatin1 = "latin1_bin"
+	// CharsetGBK is a double byte charset for simplified Chinese.
+	CharsetGBK = "gbk"
+	// CollationGBK is the default collation for CharsetGBK.
+	CollationGBK = "gbk_bin"
 )
 
 var collations = []*Collation{
@@ -0,0 +1,203 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charset
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// Strings are stored in UTF-8 internally, the charsets here are converted from and to
+// UTF-8 on input and output. The other supported charsets need no conversion.
+var convertEncodings = map[string]encoding.Encoding{
+	// MySQL latin1 is actually cp1252, it's converted by latin1ToRune and runeToLatin1.
+	CharsetLatin1: charmap.Windows1252,
+	CharsetGBK:    simplifiedchinese.GBK,
+}
+
+// latin1ToRune and runeToLatin1 convert MySQL latin1, which is cp1252 with the 5 bytes undefined
+// in cp1252 (0x81, 0x8D, 0x8F, 0x90 and 0x9D) mapped to the C1 control characters of the same
+// values, so every byte can be converted and round-trips.
+var (
+	latin1ToRune [256]rune
+	runeToLatin1 = make(map[rune]byte, 256)
+)
+
+func init() {
+	dec := charmap.Windows1252.NewDecoder()
+	for i := range latin1ToRune {
+		s, _, err := transform.String(dec, string([]byte{byte(i)}))
+		r, _ := utf8.DecodeRuneInString(s)
+		if err != nil || r == utf8.RuneError {
+			r = rune(i)
+		}
+		latin1ToRune[i] = r
+		runeToLatin1[r] = byte(i)
+	}
+}
+
+// replacementChar is used for the characters can't be converted, as MySQL does.
+const replacementChar = '?'
+
+// NeedConversion returns whether strings in the charset are different from UTF-8.
+func NeedConversion(cs string) bool {
+	cs = strings.ToLower(cs)
+	_, ok := convertEncodings[cs]
+	return ok || cs == CharsetASCII
+}
+
+// EncodeString encodes the UTF-8 string to the charset. The characters which can't be
+// encoded are replaced with '?', and the second return value is false in this case.
+func EncodeString(cs string, s string) (string, bool) {
+	cs = strings.ToLower(cs)
+	e, ok := convertEncodings[cs]
+	if !ok && cs != CharsetASCII {
+		return s, true
+	}
+	var enc transform.Transformer
+	if e != nil && cs != CharsetLatin1 {
+		enc = e.NewEncoder()
+	}
+	// Fast path for ASCII strings, which are the same in all the supported charsets.
+	i := 0
+	for i < len(s) && s[i] < utf8.RuneSelf {
+		i++
+	}
+	if i == len(s) {
+		return s, true
+	}
+	valid := true
+	buf := make([]byte, i, len(s))
+	copy(buf, s[:i])
+	for _, r := range s[i:] {
+		if r < utf8.RuneSelf {
+			buf = append(buf, byte(r))
+			continue
+		}
+		if cs == CharsetLatin1 {
+			b, ok := runeToLatin1[r]
+			if !ok {
+				b = replacementChar
+				valid = false
+			}
+			buf = append(buf, b)
+			continue
+		}
+		if enc == nil || r == utf8.RuneError {
+			buf = append(buf, replacementChar)
+			valid = false
+			continue
+		}
+		out, _, err := transform.String(enc, string(r))
+		// The single byte encoders write ASCIISub for the characters not in the charset.
+		if err != nil || out == string(encoding.ASCIISub) {
+			buf = append(buf, replacementChar)
+			valid = false
+			continue
+		}
+		buf = append(buf, out...)
+	}
+	return string(buf), valid
+}
+
+// DecodeString decodes the string in the charset to UTF-8. The invalid bytes are replaced
+// with '?', and the second return value is false in this case.
+func DecodeString(cs string, s string) (string, bool) {
+	cs = strings.ToLower(cs)
+	e, ok := convertEncodings[cs]
+	if !ok && cs != CharsetASCII {
+		if (cs == CharsetUTF8 || cs == CharsetUTF8MB4) && !utf8.ValidString(s) {
+			return replaceInvalidUTF8(s), false
+		}
+		return s, true
+	}
+	i := 0
+	for i < len(s) && s[i] < utf8.RuneSelf {
+		i++
+	}
+	if i == len(s) {
+		return s, true
+	}
+	if e == nil {
+		// ASCII
+		buf := []byte(s)
+		for ; i < len(buf); i++ {
+			if buf[i] >= utf8.RuneSelf {
+				buf[i] = replacementChar
+			}
+		}
+		return string(buf), false
+	}
+	if cs == CharsetLatin1 {
+		buf := make([]byte, i, len(s)*2)
+		copy(buf, s[:i])
+		for ; i < len(s); i++ {
+			buf = append(buf, string(latin1ToRune[s[i]])...)
+		}
+		return string(buf), true
+	}
+	valid := true
+	var b bytes.Buffer
+	b.WriteString(s[:i])
+	s = s[i:]
+	dec := e.NewDecoder()
+	for len(s) > 0 {
+		out, n, err := transform.String(dec, s)
+		if strings.ContainsRune(out, utf8.RuneError) {
+			out = strings.Replace(out, string(utf8.RuneError), string(replacementChar), -1)
+			valid = false
+		}
+		b.WriteString(out)
+		if err == nil {
+			break
+		}
+		// Skip the invalid byte.
+		b.WriteByte(replacementChar)
+		valid = false
+		if n >= len(s) {
+			break
+		}
+		s = s[n+1:]
+	}
+	return b.String(), valid
+}
+
+// CharLen returns the length of the character starting with the byte b in the charset. Among the charsets
+// converted on input, only gbk has multi-byte characters, their lead bytes are from 0x81 to 0xFE.
+func CharLen(cs string, b byte) int {
+	if b >= 0x81 && b <= 0xfe && strings.EqualFold(cs, CharsetGBK) {
+		return 2
+	}
+	return 1
+}
+
+func replaceInvalidUTF8(s string) string {
+	buf := make([]byte, 0, len(s))
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, replacementChar)
+		} else {
+			buf = append(buf, s[:size]...)
+		}
+		s = s[size:]
+	}
+	return string(buf)
+}
@@ -0,0 +1,83 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charset
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (s *testCharsetSuite) TestEncodeString(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		cs     string
+		str    string
+		result string
+		ok     bool
+	}{
+		{"utf8", "中文", "中文", true},
+		{"latin1", "abc", "abc", true},
+		{"latin1", "café", "caf\xe9", true},
+		{"LATIN1", "中文abc", "??abc", false},
+		{"gbk", "中文", "\xd6\xd0\xce\xc4", true},
+		{"gbk", "a€b", "a\x80b", true},
+		{"latin1", "\u0081\u008d\u008f\u0090\u009d€", "\x81\x8d\x8f\x90\x9d\x80", true},
+		{"ascii", "café", "caf?", false},
+	}
+	for _, t := range tests {
+		str, ok := EncodeString(t.cs, t.str)
+		c.Assert(str, Equals, t.result, Commentf("%v", t))
+		c.Assert(ok, Equals, t.ok, Commentf("%v", t))
+	}
+}
+
+func (s *testCharsetSuite) TestDecodeString(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		cs     string
+		str    string
+		result string
+		ok     bool
+	}{
+		{"utf8", "中文", "中文", true},
+		{"utf8mb4", "ab\xffc", "ab?c", false},
+		{"latin1", "caf\xe9", "café", true},
+		{"gbk", "\xd6\xd0\xce\xc4", "中文", true},
+		{"gbk", "a\xd6", "a?", false},
+		// The bytes undefined in cp1252 are decoded to the C1 control characters, like MySQL latin1.
+		{"latin1", "\x81\x8d\x8f\x90\x9d\x80", "\u0081\u008d\u008f\u0090\u009d€", true},
+		{"ascii", "caf\xe9", "caf?", false},
+	}
+	for _, t := range tests {
+		str, ok := DecodeString(t.cs, t.str)
+		c.Assert(str, Equals, t.result, Commentf("%v", t))
+		c.Assert(ok, Equals, t.ok, Commentf("%v", t))
+	}
+}
+
+func (s *testCharsetSuite) TestGetCollationByID(c *C) {
+	defer testleak.AfterTest(c)()
+	co, err := GetCollationByID(33)
+	c.Assert(err, IsNil)
+	c.Assert(co.CharsetName, Equals, CharsetUTF8)
+	co, err = GetCollationByID(28)
+	c.Assert(err, IsNil)
+	c.Assert(co.CharsetName, Equals, CharsetGBK)
+	_, err = GetCollationByID(1)
+	c.Assert(err, NotNil)
+	_, err = GetCollationByID(0)
+	c.Assert(err, NotNil)
+	c.Assert(GetMaxLen("gbk"), Equals, 2)
+	c.Assert(GetMaxLen("unknown"), Equals, 1)
+}
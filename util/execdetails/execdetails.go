@@ -0,0 +1,67 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package execdetails contains the runtime statistics of the coprocessor requests, which
+// are used to find out the bottleneck on the storage side.
+package execdetails
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExecDetails contains the runtime statistics of the coprocessor requests.
+type ExecDetails struct {
+	// RequestCount is the number of the coprocessor tasks, a task is sent to a single region.
+	RequestCount int
+	// ProcessTime is the total time spent on handling the tasks, including the backoff time.
+	ProcessTime time.Duration
+	// WaitTime is the total time the tasks waited before being handled by a worker.
+	WaitTime time.Duration
+	// BackoffTime is the total time slept by the backoffers of the tasks.
+	BackoffTime time.Duration
+	// TotalKeys is the number of rows returned by the coprocessor. The responses don't carry the
+	// number of the keys scanned by the storage, so the rows returned are counted instead.
+	TotalKeys int64
+}
+
+// Merge adds the statistics of other to d.
+func (d *ExecDetails) Merge(other ExecDetails) {
+	d.RequestCount += other.RequestCount
+	d.ProcessTime += other.ProcessTime
+	d.WaitTime += other.WaitTime
+	d.BackoffTime += other.BackoffTime
+	d.TotalKeys += other.TotalKeys
+}
+
+// String implements the fmt.Stringer interface, the zero fields are omitted.
+func (d ExecDetails) String() string {
+	parts := make([]string, 0, 5)
+	if d.RequestCount > 0 {
+		parts = append(parts, fmt.Sprintf("request_count:%d", d.RequestCount))
+	}
+	if d.ProcessTime > 0 {
+		parts = append(parts, fmt.Sprintf("process_time:%v", d.ProcessTime))
+	}
+	if d.WaitTime > 0 {
+		parts = append(parts, fmt.Sprintf("wait_time:%v", d.WaitTime))
+	}
+	if d.BackoffTime > 0 {
+		parts = append(parts, fmt.Sprintf("backoff_time:%v", d.BackoffTime))
+	}
+	if d.TotalKeys > 0 {
+		parts = append(parts, fmt.Sprintf("total_keys:%d", d.TotalKeys))
+	}
+	return strings.Join(parts, " ")
+}
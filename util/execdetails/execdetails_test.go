@@ -0,0 +1,48 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execdetails
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testExecDetailsSuite{})
+
+type testExecDetailsSuite struct{}
+
+func (s *testExecDetailsSuite) TestExecDetails(c *C) {
+	defer testleak.AfterTest(c)()
+	var d ExecDetails
+	c.Assert(d.String(), Equals, "")
+
+	d.Merge(ExecDetails{RequestCount: 1, ProcessTime: time.Second, TotalKeys: 10})
+	d.Merge(ExecDetails{RequestCount: 2, ProcessTime: time.Second, WaitTime: time.Millisecond, BackoffTime: 2 * time.Millisecond, TotalKeys: 5})
+	c.Assert(d, Equals, ExecDetails{
+		RequestCount: 3,
+		ProcessTime:  2 * time.Second,
+		WaitTime:     time.Millisecond,
+		BackoffTime:  2 * time.Millisecond,
+		TotalKeys:    15,
+	})
+	c.Assert(d.String(), Equals, "request_count:3 process_time:2s wait_time:1ms backoff_time:2ms total_keys:15")
+}
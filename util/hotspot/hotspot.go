@@ -0,0 +1,274 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hotspot collects the read and write traffic of the tables and indices
+// sent to the storage, to find out the key ranges that receive the most traffic.
+package hotspot
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/tablecodec"
+)
+
+// Window is the length of a statistics window, the traffic is calculated over the last
+// complete window and the current one.
+const Window = time.Minute
+
+// RecordIndexID is the index ID of the record data of a table, index IDs start from 1.
+const RecordIndexID = 0
+
+// Item is the traffic of the record data or an index of a table.
+type Item struct {
+	TableID int64
+	// IndexID is RecordIndexID for the record data.
+	IndexID int64
+	// ReadQPS is the number of keys read per second, a coprocessor request counts once for a key range.
+	ReadQPS float64
+	// WriteQPS is the number of keys written per second.
+	WriteQPS float64
+	// AppendRatio is the ratio of the writes appended after all the keys written before, a ratio
+	// close to 1 means the writes are on sequential keys and concentrate on the last region.
+	AppendRatio float64
+}
+
+type rangeID struct {
+	tableID int64
+	indexID int64
+}
+
+type counter struct {
+	reads   int64
+	writes  int64
+	appends int64
+}
+
+type rangeStats struct {
+	prev   counter
+	cur    counter
+	maxKey []byte
+}
+
+// Recorder records the traffic of the key ranges.
+type Recorder struct {
+	mu          sync.Mutex
+	window      time.Duration
+	prevStart   time.Time
+	curStart    time.Time
+	hasPrevious bool
+	ranges      map[rangeID]*rangeStats
+}
+
+// NewRecorder creates a Recorder with the statistics window.
+func NewRecorder(window time.Duration) *Recorder {
+	return &Recorder{
+		window:   window,
+		curStart: time.Now(),
+		ranges:   make(map[rangeID]*rangeStats),
+	}
+}
+
+// rotate starts a new window if the current window is complete. It's called with the mutex locked.
+func (r *Recorder) rotate(now time.Time) {
+	elapsed := now.Sub(r.curStart)
+	if elapsed < r.window {
+		return
+	}
+	if elapsed >= 2*r.window {
+		// There is no traffic in the last complete window.
+		r.ranges = make(map[rangeID]*rangeStats)
+		r.hasPrevious = false
+		r.curStart = now
+		return
+	}
+	for id, stats := range r.ranges {
+		if stats.cur == (counter{}) {
+			delete(r.ranges, id)
+			continue
+		}
+		stats.prev, stats.cur = stats.cur, counter{}
+	}
+	r.prevStart, r.curStart = r.curStart, r.curStart.Add(r.window)
+	r.hasPrevious = true
+}
+
+func (r *Recorder) getRange(id rangeID) *rangeStats {
+	stats, ok := r.ranges[id]
+	if !ok {
+		stats = &rangeStats{}
+		r.ranges[id] = stats
+	}
+	return stats
+}
+
+// RecordReads records the reads of the keys, the keys that don't belong to any table are ignored.
+func (r *Recorder) RecordReads(keys [][]byte) {
+	ids := make(map[rangeID]int64)
+	for _, key := range keys {
+		if id, ok := decodeRangeID(key); ok {
+			ids[id]++
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	r.mu.Lock()
+	r.rotate(time.Now())
+	for id, n := range ids {
+		r.getRange(id).cur.reads += n
+	}
+	r.mu.Unlock()
+}
+
+// RecordWrites records the writes of the keys in a transaction, the keys that don't belong to any
+// table are ignored. The writes to a range are appends if all the keys are larger than the keys
+// written to the range before.
+func (r *Recorder) RecordWrites(keys [][]byte) {
+	type batch struct {
+		count  int64
+		minKey []byte
+		maxKey []byte
+	}
+	batches := make(map[rangeID]*batch)
+	for _, key := range keys {
+		id, ok := decodeRangeID(key)
+		if !ok {
+			continue
+		}
+		b, ok := batches[id]
+		if !ok {
+			batches[id] = &batch{count: 1, minKey: key, maxKey: key}
+			continue
+		}
+		b.count++
+		if bytes.Compare(key, b.minKey) < 0 {
+			b.minKey = key
+		}
+		if bytes.Compare(key, b.maxKey) > 0 {
+			b.maxKey = key
+		}
+	}
+	if len(batches) == 0 {
+		return
+	}
+	r.mu.Lock()
+	r.rotate(time.Now())
+	for id, b := range batches {
+		stats := r.getRange(id)
+		stats.cur.writes += b.count
+		if stats.maxKey != nil && bytes.Compare(b.minKey, stats.maxKey) > 0 {
+			stats.cur.appends += b.count
+		}
+		if bytes.Compare(b.maxKey, stats.maxKey) > 0 {
+			stats.maxKey = append(stats.maxKey[:0], b.maxKey...)
+		}
+	}
+	r.mu.Unlock()
+}
+
+// Items returns the traffic of the key ranges, the hottest first.
+func (r *Recorder) Items() []Item {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.rotate(now)
+	start := r.curStart
+	if r.hasPrevious {
+		start = r.prevStart
+	}
+	seconds := now.Sub(start).Seconds()
+	if seconds < 1 {
+		seconds = 1
+	}
+	items := make([]Item, 0, len(r.ranges))
+	for id, stats := range r.ranges {
+		reads := stats.prev.reads + stats.cur.reads
+		writes := stats.prev.writes + stats.cur.writes
+		if reads == 0 && writes == 0 {
+			continue
+		}
+		item := Item{
+			TableID:  id.tableID,
+			IndexID:  id.indexID,
+			ReadQPS:  float64(reads) / seconds,
+			WriteQPS: float64(writes) / seconds,
+		}
+		if writes > 0 {
+			item.AppendRatio = float64(stats.prev.appends+stats.cur.appends) / float64(writes)
+		}
+		items = append(items, item)
+	}
+	sort.Sort(byTraffic(items))
+	return items
+}
+
+// Reset clears all the records.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.ranges = make(map[rangeID]*rangeStats)
+	r.hasPrevious = false
+	r.curStart = time.Now()
+	r.mu.Unlock()
+}
+
+type byTraffic []Item
+
+func (s byTraffic) Len() int      { return len(s) }
+func (s byTraffic) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byTraffic) Less(i, j int) bool {
+	ti, tj := s[i].ReadQPS+s[i].WriteQPS, s[j].ReadQPS+s[j].WriteQPS
+	if ti != tj {
+		return ti > tj
+	}
+	if s[i].TableID != s[j].TableID {
+		return s[i].TableID < s[j].TableID
+	}
+	return s[i].IndexID < s[j].IndexID
+}
+
+func decodeRangeID(key []byte) (rangeID, bool) {
+	tableID, indexID, isRecordKey, err := tablecodec.DecodeKeyHead(kv.Key(key))
+	if err != nil {
+		return rangeID{}, false
+	}
+	if isRecordKey {
+		indexID = RecordIndexID
+	}
+	return rangeID{tableID: tableID, indexID: indexID}, true
+}
+
+var defaultRecorder = NewRecorder(Window)
+
+// RecordReads records the reads of the keys in the default recorder.
+func RecordReads(keys [][]byte) {
+	defaultRecorder.RecordReads(keys)
+}
+
+// RecordWrites records the writes of the keys in the default recorder.
+func RecordWrites(keys [][]byte) {
+	defaultRecorder.RecordWrites(keys)
+}
+
+// Items returns the traffic of the key ranges in the default recorder, the hottest first.
+func Items() []Item {
+	return defaultRecorder.Items()
+}
+
+// Reset clears all the records in the default recorder.
+func Reset() {
+	defaultRecorder.Reset()
+}
@@ -0,0 +1,100 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hotspot
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testHotspotSuite{})
+
+type testHotspotSuite struct{}
+
+func rowKeys(tableID int64, handles ...int64) [][]byte {
+	keys := make([][]byte, 0, len(handles))
+	for _, h := range handles {
+		keys = append(keys, tablecodec.EncodeRowKeyWithHandle(tableID, h))
+	}
+	return keys
+}
+
+func indexKey(c *C, tableID, indexID int64, v int64) []byte {
+	encoded, err := codec.EncodeKey(nil, types.NewIntDatum(v))
+	c.Assert(err, IsNil)
+	return tablecodec.EncodeIndexSeekKey(tableID, indexID, encoded)
+}
+
+func (s *testHotspotSuite) TestRecorder(c *C) {
+	defer testleak.AfterTest(c)()
+	r := NewRecorder(time.Hour)
+	// Sequential writes on table 1.
+	r.RecordWrites(rowKeys(1, 3, 1, 2))
+	r.RecordWrites(rowKeys(1, 4, 5))
+	r.RecordWrites(rowKeys(1, 6))
+	// Random writes on table 2 and its index.
+	r.RecordWrites(append(rowKeys(2, 10), indexKey(c, 2, 1, 10)))
+	r.RecordWrites(append(rowKeys(2, 5), indexKey(c, 2, 1, 5)))
+	// Reads and the keys not belong to any table.
+	r.RecordReads(append(rowKeys(3, 1, 2, 3, 4, 5, 6, 7), []byte("m_meta")))
+	r.RecordWrites([][]byte{[]byte("m_meta")})
+
+	items := r.Items()
+	c.Assert(items, HasLen, 4)
+	c.Assert(items[0].TableID, Equals, int64(3))
+	c.Assert(items[0].ReadQPS, Equals, float64(7))
+	c.Assert(items[0].WriteQPS, Equals, float64(0))
+	c.Assert(items[1].TableID, Equals, int64(1))
+	c.Assert(items[1].IndexID, Equals, int64(RecordIndexID))
+	c.Assert(items[1].WriteQPS, Equals, float64(6))
+	c.Assert(items[1].AppendRatio, Equals, float64(3)/6)
+	c.Assert(items[2].TableID, Equals, int64(2))
+	c.Assert(items[2].IndexID, Equals, int64(RecordIndexID))
+	c.Assert(items[2].WriteQPS, Equals, float64(2))
+	c.Assert(items[2].AppendRatio, Equals, float64(0))
+	c.Assert(items[3].TableID, Equals, int64(2))
+	c.Assert(items[3].IndexID, Equals, int64(1))
+
+	r.Reset()
+	c.Assert(r.Items(), HasLen, 0)
+}
+
+func (s *testHotspotSuite) TestWindow(c *C) {
+	defer testleak.AfterTest(c)()
+	r := NewRecorder(time.Hour)
+	r.RecordWrites(rowKeys(1, 1))
+	// Make the current window complete.
+	r.curStart = r.curStart.Add(-time.Hour)
+	r.RecordWrites(rowKeys(2, 1))
+	items := r.Items()
+	c.Assert(items, HasLen, 2)
+	c.Assert(r.hasPrevious, IsTrue)
+	c.Assert(items[0].WriteQPS, Less, float64(1))
+
+	// The windows are discarded if there is no traffic in the last complete window.
+	r.curStart = r.curStart.Add(-2 * time.Hour)
+	c.Assert(r.Items(), HasLen, 0)
+	c.Assert(r.hasPrevious, IsFalse)
+}
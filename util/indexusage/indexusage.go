@@ -0,0 +1,130 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package indexusage counts the reads of the indices since the server starts, to find out the unused
+// indices which only cost the writes and the space.
+package indexusage
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Usage is the usage of an index.
+type Usage struct {
+	TableID int64
+	IndexID int64
+	// ReadCount is the number of the index reads, an executor reading the index counts once.
+	ReadCount int64
+	// RowsRead is the number of the rows read from the index.
+	RowsRead   int64
+	LastUsedAt time.Time
+}
+
+type usageID struct {
+	tableID int64
+	indexID int64
+}
+
+// Collector collects the usage of the indices.
+type Collector struct {
+	mu     sync.Mutex
+	usages map[usageID]*Usage
+}
+
+// NewCollector creates a Collector.
+func NewCollector() *Collector {
+	return &Collector{usages: make(map[usageID]*Usage)}
+}
+
+// Record records a read of the index, which reads rows rows.
+func (c *Collector) Record(tableID, indexID int64, rows int64) {
+	id := usageID{tableID: tableID, indexID: indexID}
+	now := time.Now()
+	c.mu.Lock()
+	usage, ok := c.usages[id]
+	if !ok {
+		usage = &Usage{TableID: tableID, IndexID: indexID}
+		c.usages[id] = usage
+	}
+	usage.ReadCount++
+	usage.RowsRead += rows
+	usage.LastUsedAt = now
+	c.mu.Unlock()
+}
+
+// Get returns the usage of the index, it's false if the index hasn't been read.
+func (c *Collector) Get(tableID, indexID int64) (Usage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	usage, ok := c.usages[usageID{tableID: tableID, indexID: indexID}]
+	if !ok {
+		return Usage{}, false
+	}
+	return *usage, true
+}
+
+// Usages returns the usage of the indices which have been read, sorted by the table ID and the index ID.
+func (c *Collector) Usages() []Usage {
+	c.mu.Lock()
+	usages := make([]Usage, 0, len(c.usages))
+	for _, usage := range c.usages {
+		usages = append(usages, *usage)
+	}
+	c.mu.Unlock()
+	sort.Sort(byID(usages))
+	return usages
+}
+
+// Reset clears the usage.
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	c.usages = make(map[usageID]*Usage)
+	c.mu.Unlock()
+}
+
+// byID sorts the usage by the table ID and the index ID.
+type byID []Usage
+
+func (s byID) Len() int      { return len(s) }
+func (s byID) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byID) Less(i, j int) bool {
+	if s[i].TableID != s[j].TableID {
+		return s[i].TableID < s[j].TableID
+	}
+	return s[i].IndexID < s[j].IndexID
+}
+
+var defaultCollector = NewCollector()
+
+// Record records a read of the index to the default collector, it's called by the executors when
+// they finish reading the index.
+func Record(tableID, indexID int64, rows int64) {
+	defaultCollector.Record(tableID, indexID, rows)
+}
+
+// Get returns the usage of the index in the default collector.
+func Get(tableID, indexID int64) (Usage, bool) {
+	return defaultCollector.Get(tableID, indexID)
+}
+
+// Usages returns the usage of the indices in the default collector.
+func Usages() []Usage {
+	return defaultCollector.Usages()
+}
+
+// Reset clears the default collector.
+func Reset() {
+	defaultCollector.Reset()
+}
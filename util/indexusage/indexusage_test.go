@@ -0,0 +1,63 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexusage
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testIndexUsageSuite{})
+
+type testIndexUsageSuite struct{}
+
+func (s *testIndexUsageSuite) TestCollector(c *C) {
+	defer testleak.AfterTest(c)()
+	col := NewCollector()
+	_, ok := col.Get(1, 1)
+	c.Assert(ok, IsFalse)
+
+	start := time.Now()
+	col.Record(2, 1, 10)
+	col.Record(1, 2, 0)
+	col.Record(1, 1, 3)
+	col.Record(1, 1, 4)
+
+	usage, ok := col.Get(1, 1)
+	c.Assert(ok, IsTrue)
+	c.Assert(usage.ReadCount, Equals, int64(2))
+	c.Assert(usage.RowsRead, Equals, int64(7))
+	c.Assert(usage.LastUsedAt.Before(start), IsFalse)
+
+	usages := col.Usages()
+	c.Assert(usages, HasLen, 3)
+	c.Assert(usages[0].TableID, Equals, int64(1))
+	c.Assert(usages[0].IndexID, Equals, int64(1))
+	c.Assert(usages[1].TableID, Equals, int64(1))
+	c.Assert(usages[1].IndexID, Equals, int64(2))
+	c.Assert(usages[1].ReadCount, Equals, int64(1))
+	c.Assert(usages[2].TableID, Equals, int64(2))
+	c.Assert(usages[2].RowsRead, Equals, int64(10))
+
+	col.Reset()
+	c.Assert(col.Usages(), HasLen, 0)
+}
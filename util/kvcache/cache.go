@@ -0,0 +1,218 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvcache
+
+import (
+	"container/list"
+	"strings"
+	"sync/atomic"
+
+	"github.com/juju/errors"
+)
+
+// Policy is the eviction policy of the cache.
+type Policy int
+
+// Eviction policies.
+const (
+	// PolicyLRU evicts the least recently used entry.
+	PolicyLRU Policy = iota
+	// PolicyLFU evicts the least frequently used entry, the least recently used one among the entries
+	// with the same frequency.
+	PolicyLFU
+)
+
+// String implements the fmt.Stringer interface.
+func (p Policy) String() string {
+	if p == PolicyLFU {
+		return "LFU"
+	}
+	return "LRU"
+}
+
+// ParsePolicy parses the name of a policy, which is "LRU" or "LFU".
+func ParsePolicy(name string) (Policy, error) {
+	switch strings.ToUpper(name) {
+	case "LRU":
+		return PolicyLRU, nil
+	case "LFU":
+		return PolicyLFU, nil
+	}
+	return PolicyLRU, errors.Errorf("unknown cache eviction policy %s", name)
+}
+
+// Key is the key of an entry.
+type Key interface {
+	// Hash returns the bytes which identify the key.
+	Hash() []byte
+}
+
+// Value is the value of an entry.
+type Value interface{}
+
+// Stats are the statistics of the caches. A Stats can be shared by many caches, its fields are accessed
+// atomically.
+type Stats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	// Size is the number of the entries.
+	Size int64
+	// MemUsage is the memory used by the entries in bytes.
+	MemUsage int64
+}
+
+// HitRatio returns the ratio of the hits to all the lookups.
+func (s *Stats) HitRatio() float64 {
+	hits := atomic.LoadInt64(&s.Hits)
+	total := hits + atomic.LoadInt64(&s.Misses)
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
+
+type cacheEntry struct {
+	hash     string
+	key      Key
+	value    Value
+	memUsage int64
+	freq     int64
+}
+
+// Cache is a cache bounded by the number of the entries and the memory they use. It's not thread safe.
+type Cache struct {
+	capacity    int
+	memCapacity int64
+	policy      Policy
+	stats       *Stats
+
+	memUsage int64
+	elements map[string]*list.Element
+	// entries keeps the entries, the most recently used first.
+	entries *list.List
+}
+
+// NewCache creates a Cache. Zero capacity or memCapacity means the cache is not bounded by it. The
+// statistics are added to stats if it's not nil.
+func NewCache(capacity int, memCapacity int64, policy Policy, stats *Stats) *Cache {
+	if stats == nil {
+		stats = &Stats{}
+	}
+	return &Cache{
+		capacity:    capacity,
+		memCapacity: memCapacity,
+		policy:      policy,
+		stats:       stats,
+		elements:    make(map[string]*list.Element),
+		entries:     list.New(),
+	}
+}
+
+// Get gets the value of the key.
+func (c *Cache) Get(key Key) (Value, bool) {
+	elem, ok := c.elements[string(key.Hash())]
+	if !ok {
+		c.addStats(&c.stats.Misses, 1)
+		return nil, false
+	}
+	c.addStats(&c.stats.Hits, 1)
+	entry := elem.Value.(*cacheEntry)
+	entry.freq++
+	c.entries.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Put puts the value of the key, the entries are evicted by the policy until the cache isn't over the
+// capacities. A value using more memory than the memory capacity isn't kept.
+func (c *Cache) Put(key Key, value Value, memUsage int64) {
+	hash := string(key.Hash())
+	if elem, ok := c.elements[hash]; ok {
+		c.remove(elem)
+	}
+	if c.memCapacity > 0 && memUsage > c.memCapacity {
+		return
+	}
+	entry := &cacheEntry{hash: hash, key: key, value: value, memUsage: memUsage, freq: 1}
+	c.elements[hash] = c.entries.PushFront(entry)
+	c.memUsage += memUsage
+	c.addStats(&c.stats.Size, 1)
+	c.addStats(&c.stats.MemUsage, memUsage)
+	for (c.capacity > 0 && c.entries.Len() > c.capacity) || (c.memCapacity > 0 && c.memUsage > c.memCapacity) {
+		c.remove(c.victim())
+		c.addStats(&c.stats.Evictions, 1)
+	}
+}
+
+// Delete deletes the entry of the key.
+func (c *Cache) Delete(key Key) {
+	if elem, ok := c.elements[string(key.Hash())]; ok {
+		c.remove(elem)
+	}
+}
+
+// DeleteIf deletes the entries whose keys satisfy the condition.
+func (c *Cache) DeleteIf(cond func(key Key) bool) {
+	for elem := c.entries.Front(); elem != nil; {
+		next := elem.Next()
+		if cond(elem.Value.(*cacheEntry).key) {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+// Clear deletes all the entries.
+func (c *Cache) Clear() {
+	c.DeleteIf(func(Key) bool { return true })
+}
+
+// Len returns the number of the entries.
+func (c *Cache) Len() int {
+	return c.entries.Len()
+}
+
+// MemUsage returns the memory used by the entries.
+func (c *Cache) MemUsage() int64 {
+	return c.memUsage
+}
+
+// victim returns the entry to evict.
+func (c *Cache) victim() *list.Element {
+	victim := c.entries.Back()
+	if c.policy != PolicyLFU {
+		return victim
+	}
+	// Scan from the least recently used one, so the LRU entry is chosen among the same frequency. The
+	// entry just put is skipped, otherwise a new entry never survives the hot ones.
+	for elem := victim.Prev(); elem != nil && elem != c.entries.Front(); elem = elem.Prev() {
+		if elem.Value.(*cacheEntry).freq < victim.Value.(*cacheEntry).freq {
+			victim = elem
+		}
+	}
+	return victim
+}
+
+func (c *Cache) remove(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	c.entries.Remove(elem)
+	delete(c.elements, entry.hash)
+	c.memUsage -= entry.memUsage
+	c.addStats(&c.stats.Size, -1)
+	c.addStats(&c.stats.MemUsage, -entry.memUsage)
+}
+
+func (c *Cache) addStats(counter *int64, delta int64) {
+	atomic.AddInt64(counter, delta)
+}
@@ -0,0 +1,113 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvcache
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testCacheSuite{})
+
+type testCacheSuite struct {
+}
+
+type mockKey string
+
+func (k mockKey) Hash() []byte {
+	return []byte(k)
+}
+
+func (s *testCacheSuite) TestLRU(c *C) {
+	defer testleak.AfterTest(c)()
+	stats := &Stats{}
+	cache := NewCache(2, 0, PolicyLRU, stats)
+	cache.Put(mockKey("a"), 1, 10)
+	cache.Put(mockKey("b"), 2, 10)
+	v, ok := cache.Get(mockKey("a"))
+	c.Assert(ok, IsTrue)
+	c.Assert(v, Equals, 1)
+	// b is the least recently used one.
+	cache.Put(mockKey("c"), 3, 10)
+	_, ok = cache.Get(mockKey("b"))
+	c.Assert(ok, IsFalse)
+	c.Assert(cache.Len(), Equals, 2)
+	c.Assert(cache.MemUsage(), Equals, int64(20))
+	c.Assert(*stats, Equals, Stats{Hits: 1, Misses: 1, Evictions: 1, Size: 2, MemUsage: 20})
+	c.Assert(stats.HitRatio(), Equals, 0.5)
+
+	// Put the same key again replaces the value.
+	cache.Put(mockKey("c"), 4, 15)
+	v, ok = cache.Get(mockKey("c"))
+	c.Assert(ok, IsTrue)
+	c.Assert(v, Equals, 4)
+	c.Assert(cache.MemUsage(), Equals, int64(25))
+
+	cache.Delete(mockKey("a"))
+	c.Assert(cache.Len(), Equals, 1)
+	cache.Clear()
+	c.Assert(cache.Len(), Equals, 0)
+	c.Assert(stats.Size, Equals, int64(0))
+	c.Assert(stats.MemUsage, Equals, int64(0))
+}
+
+func (s *testCacheSuite) TestLFU(c *C) {
+	defer testleak.AfterTest(c)()
+	cache := NewCache(3, 0, PolicyLFU, nil)
+	cache.Put(mockKey("a"), 1, 1)
+	cache.Put(mockKey("b"), 2, 1)
+	cache.Put(mockKey("c"), 3, 1)
+	cache.Get(mockKey("a"))
+	cache.Get(mockKey("a"))
+	cache.Get(mockKey("c"))
+	// b is the least frequently used one.
+	cache.Put(mockKey("d"), 4, 1)
+	_, ok := cache.Get(mockKey("b"))
+	c.Assert(ok, IsFalse)
+	// The entry just put is never the victim, d is evicted instead of e.
+	cache.Put(mockKey("e"), 5, 1)
+	_, ok = cache.Get(mockKey("d"))
+	c.Assert(ok, IsFalse)
+	_, ok = cache.Get(mockKey("a"))
+	c.Assert(ok, IsTrue)
+}
+
+func (s *testCacheSuite) TestMemCapacity(c *C) {
+	defer testleak.AfterTest(c)()
+	stats := &Stats{}
+	cache := NewCache(0, 100, PolicyLRU, stats)
+	cache.Put(mockKey("a"), 1, 40)
+	cache.Put(mockKey("b"), 2, 40)
+	cache.Put(mockKey("c"), 3, 40)
+	c.Assert(cache.Len(), Equals, 2)
+	c.Assert(cache.MemUsage(), Equals, int64(80))
+	_, ok := cache.Get(mockKey("a"))
+	c.Assert(ok, IsFalse)
+	// A value larger than the memory capacity isn't kept.
+	cache.Put(mockKey("d"), 4, 101)
+	c.Assert(cache.Len(), Equals, 2)
+	c.Assert(stats.Evictions, Equals, int64(1))
+
+	policy, err := ParsePolicy("lfu")
+	c.Assert(err, IsNil)
+	c.Assert(policy, Equals, PolicyLFU)
+	_, err = ParsePolicy("fifo")
+	c.Assert(err, NotNil)
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/mysql"
 )
 
 // Version information.
@@ -40,6 +41,12 @@ func PrintRawTiDBInfo() {
 	fmt.Println("UTC Build Time: ", TiDBBuildTS)
 }
 
+// GetTiDBInfo returns the TiDB version information.
+func GetTiDBInfo() string {
+	return fmt.Sprintf("Release Version: %s\nGit Commit Hash: %s\nUTC Build Time: %s",
+		mysql.ServerVersion, TiDBGitHash, TiDBBuildTS)
+}
+
 // checkValidity checks whether cols and every data have the same length.
 func checkValidity(cols []string, datas [][]string) bool {
 	colLen := len(cols)
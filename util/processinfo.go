@@ -15,6 +15,9 @@ package util
 
 import (
 	"time"
+
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/store/tikv/oracle"
 )
 
 // ProcessInfo is a struct used for show processlist statement.
@@ -27,6 +30,35 @@ type ProcessInfo struct {
 	Time    time.Time
 	State   uint16
 	Info    string
+	// TxnStartTS is the start ts of the transaction of the process, it's 0 if there is no transaction.
+	TxnStartTS uint64
+	// Plan is the plan of the running statement, it's nil if there is no running statement.
+	// It's used by the explain for connection statement.
+	Plan interface{}
+	// StmtCtx is the statement context of the running statement, the runtime execution details
+	// are collected in it.
+	StmtCtx *variable.StatementContext
+}
+
+// TxnDuration returns how long the transaction of the process has been running.
+func (pi *ProcessInfo) TxnDuration(now time.Time) time.Duration {
+	if pi.TxnStartTS == 0 {
+		return 0
+	}
+	ms := oracle.GetPhysical(now) - oracle.ExtractPhysical(pi.TxnStartTS)
+	if ms < 0 {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// IdleInTxnDuration returns how long the process has been idle inside a transaction, an idle
+// transaction holds its snapshot without doing anything.
+func (pi *ProcessInfo) IdleInTxnDuration(now time.Time) time.Duration {
+	if pi.TxnStartTS == 0 || len(pi.Info) != 0 {
+		return 0
+	}
+	return now.Sub(pi.Time)
 }
 
 // SessionManager is an interface for session manage. Show processlist and
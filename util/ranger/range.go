@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -297,6 +298,102 @@ func (r *Builder) newBuildFromIn(expr *expression.ScalarFunction) []point {
 	return distinctRangePoints
 }
 
+// likePattern is the left-anchored part of a LIKE pattern.
+type likePattern struct {
+	// prefix is the literal prefix before the first wildcard, the escaped characters are unescaped.
+	prefix []byte
+	// wildcard is the first wildcard after the prefix, it's 0 if the pattern has no wildcard.
+	wildcard byte
+	// onlyAnyAfter is true if the pattern is the prefix followed by '%'s only.
+	onlyAnyAfter bool
+}
+
+// parseLikePattern parses the pattern the same way as stringutil.CompilePattern does, the escape character
+// followed by anything except a wildcard or itself is matched as it is.
+func parseLikePattern(pattern string, escape byte) likePattern {
+	var p likePattern
+	p.prefix = make([]byte, 0, len(pattern))
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c == escape {
+			if i < len(pattern)-1 {
+				if next := pattern[i+1]; next == escape || next == '_' || next == '%' {
+					c = next
+					i++
+				}
+			}
+			p.prefix = append(p.prefix, c)
+			continue
+		}
+		if c == '%' || c == '_' {
+			p.wildcard = c
+			p.onlyAnyAfter = strings.Trim(pattern[i:], "%") == ""
+			break
+		}
+		p.prefix = append(p.prefix, c)
+	}
+	return p
+}
+
+// caseSensitive checks if the prefix has a letter, LIKE matches the letters case insensitively,
+// so the range of the prefix isn't tight in the binary order.
+func (p likePattern) caseSensitive() bool {
+	for _, c := range p.prefix {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			return true
+		}
+	}
+	return false
+}
+
+// tight checks if the range built from the pattern contains exactly the matched values.
+func (p likePattern) tight() bool {
+	return (p.wildcard == 0 || p.onlyAnyAfter) && !p.caseSensitive()
+}
+
+// lowerBound returns the smallest value among the case variants of the prefix, the upper cases
+// are less than the lower cases in the binary order.
+func (p likePattern) lowerBound() []byte {
+	low := make([]byte, len(p.prefix))
+	for i, c := range p.prefix {
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		low[i] = c
+	}
+	return low
+}
+
+// upperBound returns the largest value among the case variants of the prefix.
+func (p likePattern) upperBound() []byte {
+	high := make([]byte, len(p.prefix))
+	for i, c := range p.prefix {
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		high[i] = c
+	}
+	return high
+}
+
+// prefixNext returns the smallest value larger than all the values prefixed by b, or nil if there is none.
+// e.g., "abc" gets "abd", and "ab\xff\xff" gets "ac" rather than "ac\x00\x00" which is larger than "ac".
+func prefixNext(b []byte) []byte {
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] != 0xff {
+			next := make([]byte, i+1)
+			copy(next, b[:i+1])
+			next[i]++
+			return next
+		}
+	}
+	return nil
+}
+
+// newBuildFromPatternLike builds the range of a left-anchored LIKE pattern. The strings are compared in the
+// binary order without padding, and LIKE doesn't pad either, so the trailing spaces of the prefix are kept
+// in the range. The PAD SPACE collations are not supported yet, the range needs to cover the padded values
+// once the comparisons ignore the trailing spaces.
 func (r *Builder) newBuildFromPatternLike(expr *expression.ScalarFunction) []point {
 	pattern, err := expr.GetArgs()[1].(*expression.Constant).Value.ToString()
 	if err != nil {
@@ -308,59 +405,26 @@ func (r *Builder) newBuildFromPatternLike(expr *expression.ScalarFunction) []poi
 		endPoint := point{value: types.NewStringDatum("")}
 		return []point{startPoint, endPoint}
 	}
-	lowValue := make([]byte, 0, len(pattern))
 	escape := byte(expr.GetArgs()[2].(*expression.Constant).Value.GetInt64())
-	var exclude bool
-	isExactMatch := true
-	for i := 0; i < len(pattern); i++ {
-		if pattern[i] == escape {
-			i++
-			if i < len(pattern) {
-				lowValue = append(lowValue, pattern[i])
-			} else {
-				lowValue = append(lowValue, escape)
-			}
-			continue
-		}
-		if pattern[i] == '%' {
-			// Get the prefix.
-			isExactMatch = false
-			break
-		} else if pattern[i] == '_' {
-			// Get the prefix, but exclude the prefix.
-			// e.g., "abc_x", the start point exclude "abc",
-			// because the string length is more than 3.
-			exclude = true
-			isExactMatch = false
-			break
-		}
-		lowValue = append(lowValue, pattern[i])
-	}
-	if len(lowValue) == 0 {
+	p := parseLikePattern(pattern, escape)
+	if len(p.prefix) == 0 {
 		return []point{{value: types.MinNotNullDatum(), start: true}, {value: types.MaxValueDatum()}}
 	}
-	if isExactMatch {
-		val := types.NewStringDatum(string(lowValue))
-		return []point{{value: val, start: true}, {value: val}}
+	// The values matched by the pattern are between the case variants of the prefix.
+	// e.g., "abc_x" gets the range ("ABC", "abd"), the start point is excluded because the value
+	// is longer than the prefix, and "abc" gets the range ["ABC", "abc"].
+	startPoint := point{start: true, excl: p.wildcard == '_'}
+	startPoint.value.SetBytesAsString(p.lowerBound())
+	if p.wildcard == 0 {
+		endPoint := point{}
+		endPoint.value.SetBytesAsString(p.upperBound())
+		return []point{startPoint, endPoint}
 	}
-	startPoint := point{start: true, excl: exclude}
-	startPoint.value.SetBytesAsString(lowValue)
-	highValue := make([]byte, len(lowValue))
-	copy(highValue, lowValue)
 	endPoint := point{excl: true}
-	for i := len(highValue) - 1; i >= 0; i-- {
-		// Make the end point value more than the start point value,
-		// and the length of the end point value is the same as the length of the start point value.
-		// e.g., the start point value is "abc", so the end point value is "abd".
-		highValue[i]++
-		if highValue[i] != 0 {
-			endPoint.value.SetBytesAsString(highValue)
-			break
-		}
-		// If highValue[i] is 255 and highValue[i]++ is 0, then the end point value is max value.
-		if i == 0 {
-			endPoint.value = types.MaxValueDatum()
-		}
+	if highValue := prefixNext(p.upperBound()); highValue != nil {
+		endPoint.value.SetBytesAsString(highValue)
+	} else {
+		endPoint.value = types.MaxValueDatum()
 	}
 	return []point{startPoint, endPoint}
 }
@@ -223,22 +223,22 @@ func (s *testRangerSuite) TestIndexRange(c *C) {
 	}{
 		{
 			exprStr:    "a LIKE 'abc%'",
-			resultStr:  "[[abc <nil>,abd <nil>)]",
+			resultStr:  "[[ABC <nil>,abd <nil>)]",
 			inAndEqCnt: 0,
 		},
 		{
 			exprStr:    "a LIKE 'abc_'",
-			resultStr:  "[(abc +inf,abd <nil>)]",
+			resultStr:  "[(ABC +inf,abd <nil>)]",
 			inAndEqCnt: 0,
 		},
 		{
 			exprStr:    "a LIKE 'abc'",
-			resultStr:  "[[abc,abc]]",
+			resultStr:  "[[ABC,abc]]",
 			inAndEqCnt: 0,
 		},
 		{
 			exprStr:    `a LIKE "ab\_c"`,
-			resultStr:  "[[ab_c,ab_c]]",
+			resultStr:  "[[AB_C,ab_c]]",
 			inAndEqCnt: 0,
 		},
 		{
@@ -248,7 +248,7 @@ func (s *testRangerSuite) TestIndexRange(c *C) {
 		},
 		{
 			exprStr:    `a LIKE '\%a'`,
-			resultStr:  `[[%a,%a]]`,
+			resultStr:  `[[%A,%a]]`,
 			inAndEqCnt: 0,
 		},
 		{
@@ -258,7 +258,38 @@ func (s *testRangerSuite) TestIndexRange(c *C) {
 		},
 		{
 			exprStr:    `a LIKE "\\\\a%"`,
-			resultStr:  `[[\a <nil>,\b <nil>)]`,
+			resultStr:  `[[\A <nil>,\b <nil>)]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    "a LIKE '2017-%'",
+			resultStr:  "[[2017- <nil>,2017. <nil>)]",
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    `a LIKE "1\%2%%"`,
+			resultStr:  "[[1%2 <nil>,1%3 <nil>)]",
+			inAndEqCnt: 0,
+		},
+		{
+			// The escape character not followed by a wildcard is matched as it is.
+			exprStr:    `a LIKE "1\\2%"`,
+			resultStr:  `[[1\2 <nil>,1\3 <nil>)]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    "a LIKE '1|_2%' escape '|'",
+			resultStr:  "[[1_2 <nil>,1_3 <nil>)]",
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    "a LIKE 0x31ffff25",
+			resultStr:  "[[1\xff\xff <nil>,2 <nil>)]",
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    "a LIKE '%abc'",
+			resultStr:  "[[-inf,+inf]]",
 			inAndEqCnt: 0,
 		},
 		{
@@ -416,27 +416,12 @@ func (c *conditionChecker) checkLikeFunc(scalar *expression.ScalarFunction) bool
 		return true
 	}
 	escape := byte(scalar.GetArgs()[2].(*expression.Constant).Value.GetInt64())
-	for i := 0; i < len(patternStr); i++ {
-		if patternStr[i] == escape {
-			i++
-			if i < len(patternStr)-1 {
-				continue
-			}
-			break
-		}
-		if i == 0 && (patternStr[i] == '%' || patternStr[i] == '_') {
-			return false
-		}
-		if patternStr[i] == '%' {
-			if i != len(patternStr)-1 {
-				c.shouldReserve = true
-			}
-			break
-		}
-		if patternStr[i] == '_' {
-			c.shouldReserve = true
-			break
-		}
+	p := parseLikePattern(patternStr, escape)
+	if len(p.prefix) == 0 {
+		return false
+	}
+	if !p.tight() {
+		c.shouldReserve = true
 	}
 	return true
 }
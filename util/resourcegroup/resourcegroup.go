@@ -0,0 +1,317 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resourcegroup limits the resources used by the statements of the users. The users are
+// assigned to the resource groups, the statements of the users in the same group share the limits
+// of the group, so a reporting user can't starve the OLTP workload. A statement can also be limited
+// by the session, so a rogue query is aborted before it takes too many resources.
+package resourcegroup
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	goctx "golang.org/x/net/context"
+)
+
+// Error codes.
+const (
+	codeTooManyStatements terror.ErrCode = 1
+	codeMemQuotaExceeded  terror.ErrCode = 2
+
+	codeExaminedRowsExceeded terror.ErrCode = 3
+	codeReturnedRowsExceeded terror.ErrCode = 4
+	codeStmtMemQuotaExceeded terror.ErrCode = 5
+)
+
+var (
+	// ErrTooManyStatements is returned when the running statements of the resource group reach the limit.
+	ErrTooManyStatements = terror.ClassResourceGroup.New(codeTooManyStatements, "Too many concurrent statements in resource group '%s', the limit is %d")
+	// ErrMemQuotaExceeded is returned when the coprocessor responses buffered by a statement exceed the quota.
+	ErrMemQuotaExceeded = terror.ClassResourceGroup.New(codeMemQuotaExceeded, "Statement exceeds the memory quota %d bytes of resource group '%s'")
+	// ErrExaminedRowsExceeded is returned when the rows read from the storage by a statement exceed the session limit.
+	ErrExaminedRowsExceeded = terror.ClassResourceGroup.New(codeExaminedRowsExceeded, "Statement examines more than %d rows, which is limited by tidb_stmt_max_examined_rows")
+	// ErrReturnedRowsExceeded is returned when the rows returned to the client by a statement exceed the session limit.
+	ErrReturnedRowsExceeded = terror.ClassResourceGroup.New(codeReturnedRowsExceeded, "Statement returns more than %d rows, which is limited by tidb_stmt_max_returned_rows")
+	// ErrStmtMemQuotaExceeded is returned when the coprocessor responses buffered by a statement exceed the session quota.
+	ErrStmtMemQuotaExceeded = terror.ClassResourceGroup.New(codeStmtMemQuotaExceeded, "Statement exceeds the memory quota %d bytes, which is limited by tidb_stmt_mem_quota")
+)
+
+func init() {
+	terror.ErrClassToMySQLCodes[terror.ClassResourceGroup] = map[terror.ErrCode]uint16{
+		codeTooManyStatements: mysql.ErrTooManyConcurrentTrxs,
+		codeMemQuotaExceeded:  mysql.ErrOutOfResources,
+
+		codeExaminedRowsExceeded: mysql.ErrTooBigSelect,
+		codeReturnedRowsExceeded: mysql.ErrTooBigSelect,
+		codeStmtMemQuotaExceeded: mysql.ErrOutOfResources,
+	}
+	current.Store(&registry{})
+}
+
+// Group is a resource group, the zero limits mean unlimited.
+type Group struct {
+	Name string
+	// MaxConcurrentStmts is the max number of the statements running at the same time in the group.
+	MaxConcurrentStmts int64
+	// MemQuota is the max bytes of the coprocessor responses buffered by a statement. The memory used by
+	// the executors is not tracked, but the responses take most of the memory used by the large queries.
+	MemQuota int64
+	// CopRequestRate is the max number of the coprocessor requests sent by the group per second.
+	CopRequestRate float64
+
+	// running is the number of the running statements, it's accessed atomically and shared by the
+	// groups with the same name, so the statements running during a reload are still counted.
+	running *int64
+	limiter *rateLimiter
+}
+
+// NewGroup creates a resource group.
+func NewGroup(name string, maxConcurrentStmts, memQuota int64, copRequestRate float64) *Group {
+	g := &Group{
+		Name:               strings.ToLower(name),
+		MaxConcurrentStmts: maxConcurrentStmts,
+		MemQuota:           memQuota,
+		CopRequestRate:     copRequestRate,
+		running:            new(int64),
+	}
+	if copRequestRate > 0 {
+		g.limiter = &rateLimiter{interval: time.Duration(float64(time.Second) / copRequestRate)}
+	}
+	return g
+}
+
+// Enter is called when a statement of the group starts, it returns ErrTooManyStatements if the
+// running statements reach the limit. The statement must call Leave when it's done if Enter succeeds.
+func (g *Group) Enter() error {
+	running := atomic.AddInt64(g.running, 1)
+	if g.MaxConcurrentStmts > 0 && running > g.MaxConcurrentStmts {
+		atomic.AddInt64(g.running, -1)
+		return ErrTooManyStatements.GenByArgs(g.Name, g.MaxConcurrentStmts)
+	}
+	return nil
+}
+
+// Leave is called when a statement of the group is done.
+func (g *Group) Leave() {
+	atomic.AddInt64(g.running, -1)
+}
+
+// Running returns the number of the running statements of the group.
+func (g *Group) Running() int64 {
+	return atomic.LoadInt64(g.running)
+}
+
+// StmtLimits are the limits of every statement of a session, the zero limits mean unlimited.
+type StmtLimits struct {
+	// MaxExaminedRows is the max number of the rows read from the storage. The responses don't carry the
+	// number of the keys scanned, so the rows filtered or aggregated by the coprocessor are not counted.
+	MaxExaminedRows int64
+	// MaxReturnedRows is the max number of the rows returned to the client.
+	MaxReturnedRows int64
+	// MemQuota is the max bytes of the buffered coprocessor responses, like the MemQuota of Group.
+	MemQuota int64
+}
+
+// NewStmtQuota creates the quota of a statement limited by the resource group and the limits of the
+// session, group is nil if the user isn't assigned to any group. It returns nil if nothing is limited.
+func NewStmtQuota(group *Group, limits StmtLimits) *StmtQuota {
+	if group == nil && limits == (StmtLimits{}) {
+		return nil
+	}
+	return &StmtQuota{group: group, limits: limits}
+}
+
+type registry struct {
+	groups map[string]*Group
+	// users maps the user names to the groups.
+	users map[string]*Group
+}
+
+// current holds the *registry, it's replaced as a whole when the resource groups are reloaded.
+var current atomic.Value
+
+// Set replaces the resource groups and the assignments of the users. users maps the user names to the
+// group names, the users assigned to the unknown groups are not limited.
+func Set(groups []*Group, users map[string]string) {
+	old := current.Load().(*registry)
+	r := &registry{
+		groups: make(map[string]*Group, len(groups)),
+		users:  make(map[string]*Group, len(users)),
+	}
+	for _, g := range groups {
+		if oldGroup, ok := old.groups[g.Name]; ok {
+			g.running = oldGroup.running
+		}
+		r.groups[g.Name] = g
+	}
+	for user, name := range users {
+		if g, ok := r.groups[strings.ToLower(name)]; ok {
+			r.users[user] = g
+		}
+	}
+	current.Store(r)
+}
+
+// ForUser returns the resource group of the user, user is the user name or in the 'name@host' form.
+// It returns nil if the user isn't assigned to any group.
+func ForUser(user string) *Group {
+	if i := strings.LastIndex(user, "@"); i >= 0 {
+		user = user[:i]
+	}
+	return current.Load().(*registry).users[user]
+}
+
+// StmtQuota tracks the resources used by a statement against the limits of its resource group and
+// its session. The methods of a nil *StmtQuota do nothing, so the statements are not limited by default.
+type StmtQuota struct {
+	// group is nil if the statement is only limited by the session.
+	group  *Group
+	limits StmtLimits
+	// memUsed, examinedRows and returnedRows are accessed atomically.
+	memUsed      int64
+	examinedRows int64
+	returnedRows int64
+}
+
+func (q *StmtQuota) groupMemQuota() int64 {
+	if q.group == nil {
+		return 0
+	}
+	return q.group.MemQuota
+}
+
+// Consume adds the bytes of a buffered coprocessor response, it returns ErrStmtMemQuotaExceeded or
+// ErrMemQuotaExceeded if the buffered responses exceed the memory quota of the session or the group.
+func (q *StmtQuota) Consume(bytes int64) error {
+	if q == nil {
+		return nil
+	}
+	groupQuota := q.groupMemQuota()
+	if groupQuota <= 0 && q.limits.MemQuota <= 0 {
+		return nil
+	}
+	memUsed := atomic.AddInt64(&q.memUsed, bytes)
+	if q.limits.MemQuota > 0 && memUsed > q.limits.MemQuota {
+		atomic.AddInt64(&q.memUsed, -bytes)
+		return ErrStmtMemQuotaExceeded.GenByArgs(q.limits.MemQuota)
+	}
+	if groupQuota > 0 && memUsed > groupQuota {
+		atomic.AddInt64(&q.memUsed, -bytes)
+		return ErrMemQuotaExceeded.GenByArgs(groupQuota, q.group.Name)
+	}
+	return nil
+}
+
+// Release subtracts the bytes of a coprocessor response which is no longer buffered.
+func (q *StmtQuota) Release(bytes int64) {
+	if q == nil || (q.groupMemQuota() <= 0 && q.limits.MemQuota <= 0) {
+		return
+	}
+	atomic.AddInt64(&q.memUsed, -bytes)
+}
+
+// AddExaminedRows adds the rows read from the storage, it returns ErrExaminedRowsExceeded if the rows
+// examined by the statement exceed the limit of the session.
+func (q *StmtQuota) AddExaminedRows(rows int64) error {
+	if q == nil || q.limits.MaxExaminedRows <= 0 {
+		return nil
+	}
+	if atomic.AddInt64(&q.examinedRows, rows) > q.limits.MaxExaminedRows {
+		return ErrExaminedRowsExceeded.GenByArgs(q.limits.MaxExaminedRows)
+	}
+	return nil
+}
+
+// AddReturnedRows adds the rows returned to the client, it returns ErrReturnedRowsExceeded if the rows
+// returned by the statement exceed the limit of the session.
+func (q *StmtQuota) AddReturnedRows(rows int64) error {
+	if q == nil || q.limits.MaxReturnedRows <= 0 {
+		return nil
+	}
+	if atomic.AddInt64(&q.returnedRows, rows) > q.limits.MaxReturnedRows {
+		return ErrReturnedRowsExceeded.GenByArgs(q.limits.MaxReturnedRows)
+	}
+	return nil
+}
+
+// MemUsed returns the bytes of the buffered coprocessor responses.
+func (q *StmtQuota) MemUsed() int64 {
+	if q == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&q.memUsed)
+}
+
+// WaitCopRequest blocks until a coprocessor request can be sent without exceeding the request rate
+// of the group, it returns the error of ctx if ctx is done before that.
+func (q *StmtQuota) WaitCopRequest(ctx goctx.Context) error {
+	if q == nil || q.group == nil || q.group.limiter == nil {
+		return nil
+	}
+	return q.group.limiter.wait(ctx)
+}
+
+type stmtQuotaKey struct{}
+
+// WithStmtQuota returns a copy of ctx which carries the quota to the coprocessor requests.
+func WithStmtQuota(ctx goctx.Context, q *StmtQuota) goctx.Context {
+	if q == nil {
+		return ctx
+	}
+	return goctx.WithValue(ctx, stmtQuotaKey{}, q)
+}
+
+// StmtQuotaFromContext returns the quota carried by ctx, it returns nil if there is none.
+func StmtQuotaFromContext(ctx goctx.Context) *StmtQuota {
+	if ctx == nil {
+		return nil
+	}
+	q, _ := ctx.Value(stmtQuotaKey{}).(*StmtQuota)
+	return q
+}
+
+// rateLimiter spaces the requests evenly by the interval.
+type rateLimiter struct {
+	interval time.Duration
+
+	mu sync.Mutex
+	// next is the earliest time the next request can be sent.
+	next time.Time
+}
+
+func (l *rateLimiter) wait(ctx goctx.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcegroup
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+	goctx "golang.org/x/net/context"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testResourceGroupSuite{})
+
+type testResourceGroupSuite struct{}
+
+func (s *testResourceGroupSuite) TestConcurrentStmts(c *C) {
+	defer testleak.AfterTest(c)()
+	defer Set(nil, nil)
+	Set([]*Group{NewGroup("Report", 2, 0, 0)}, map[string]string{"alice": "report", "bob": "unknown"})
+	c.Assert(ForUser("bob@%"), IsNil)
+	c.Assert(ForUser("carol"), IsNil)
+	g := ForUser("alice@127.0.0.1")
+	c.Assert(g, NotNil)
+	c.Assert(g.Name, Equals, "report")
+
+	c.Assert(g.Enter(), IsNil)
+	c.Assert(g.Enter(), IsNil)
+	err := g.Enter()
+	c.Assert(terror.ErrorEqual(err, ErrTooManyStatements), IsTrue, Commentf("err %v", err))
+	c.Assert(g.Running(), Equals, int64(2))
+
+	// The running statements are still counted after reloading.
+	Set([]*Group{NewGroup("report", 3, 0, 0)}, map[string]string{"alice": "report"})
+	g.Leave()
+	g = ForUser("alice")
+	c.Assert(g.Running(), Equals, int64(1))
+	c.Assert(g.Enter(), IsNil)
+	c.Assert(g.Enter(), IsNil)
+	c.Assert(g.Enter(), NotNil)
+}
+
+func (s *testResourceGroupSuite) TestMemQuota(c *C) {
+	defer testleak.AfterTest(c)()
+	var q *StmtQuota
+	c.Assert(q.Consume(1<<30), IsNil)
+	q.Release(1 << 30)
+	c.Assert(q.MemUsed(), Equals, int64(0))
+	c.Assert(StmtQuotaFromContext(WithStmtQuota(goctx.Background(), q)), IsNil)
+
+	q = NewStmtQuota(NewGroup("report", 0, 100, 0), StmtLimits{})
+	c.Assert(StmtQuotaFromContext(WithStmtQuota(goctx.Background(), q)), Equals, q)
+	c.Assert(q.Consume(60), IsNil)
+	err := q.Consume(60)
+	c.Assert(terror.ErrorEqual(err, ErrMemQuotaExceeded), IsTrue, Commentf("err %v", err))
+	c.Assert(q.MemUsed(), Equals, int64(60))
+	q.Release(60)
+	c.Assert(q.Consume(60), IsNil)
+}
+
+func (s *testResourceGroupSuite) TestCopRequestRate(c *C) {
+	defer testleak.AfterTest(c)()
+	q := NewStmtQuota(NewGroup("report", 0, 0, 100), StmtLimits{})
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		c.Assert(q.WaitCopRequest(goctx.Background()), IsNil)
+	}
+	c.Assert(time.Since(start), GreaterEqual, 40*time.Millisecond)
+
+	q = NewStmtQuota(NewGroup("report", 0, 0, 0.1), StmtLimits{})
+	c.Assert(q.WaitCopRequest(goctx.Background()), IsNil)
+	ctx, cancel := goctx.WithCancel(goctx.Background())
+	cancel()
+	c.Assert(q.WaitCopRequest(ctx), NotNil)
+}
+
+func (s *testResourceGroupSuite) TestStmtLimits(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(NewStmtQuota(nil, StmtLimits{}), IsNil)
+
+	q := NewStmtQuota(nil, StmtLimits{MaxExaminedRows: 10, MaxReturnedRows: 5, MemQuota: 100})
+	c.Assert(q.AddExaminedRows(10), IsNil)
+	err := q.AddExaminedRows(1)
+	c.Assert(terror.ErrorEqual(err, ErrExaminedRowsExceeded), IsTrue, Commentf("err %v", err))
+	c.Assert(q.AddReturnedRows(5), IsNil)
+	err = q.AddReturnedRows(1)
+	c.Assert(terror.ErrorEqual(err, ErrReturnedRowsExceeded), IsTrue, Commentf("err %v", err))
+	c.Assert(q.Consume(60), IsNil)
+	err = q.Consume(60)
+	c.Assert(terror.ErrorEqual(err, ErrStmtMemQuotaExceeded), IsTrue, Commentf("err %v", err))
+	q.Release(60)
+	c.Assert(q.MemUsed(), Equals, int64(0))
+	c.Assert(q.WaitCopRequest(goctx.Background()), IsNil)
+
+	// The lower memory quota of the session and the group takes effect.
+	q = NewStmtQuota(NewGroup("report", 0, 100, 0), StmtLimits{MemQuota: 200})
+	err = q.Consume(120)
+	c.Assert(terror.ErrorEqual(err, ErrMemQuotaExceeded), IsTrue, Commentf("err %v", err))
+	q = NewStmtQuota(NewGroup("report", 0, 200, 0), StmtLimits{MemQuota: 100})
+	err = q.Consume(120)
+	c.Assert(terror.ErrorEqual(err, ErrStmtMemQuotaExceeded), IsTrue, Commentf("err %v", err))
+	c.Assert(q.AddExaminedRows(1<<40), IsNil)
+}
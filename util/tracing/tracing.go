@@ -0,0 +1,134 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing records the time spent in the phases of a statement as a tree of spans, it's used by
+// the TRACE statement. Like opentracing, the spans are propagated by golang.org/x/net/context, so the
+// storage layer records its requests as the children of the span carried by the context. All the methods
+// of Span can be called on a nil Span, which does nothing, so the code paths of the untraced statements
+// don't check whether they're traced.
+package tracing
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	goctx "golang.org/x/net/context"
+)
+
+// Span is a timed operation, its children are the operations done by it.
+type Span struct {
+	Operation string
+	Start     time.Time
+
+	mu       sync.Mutex
+	duration time.Duration
+	finished bool
+	children []*Span
+}
+
+// StartSpan starts a root span.
+func StartSpan(operation string) *Span {
+	return StartSpanAt(operation, time.Now())
+}
+
+// StartSpanAt starts a root span at the time start, it's used when the operation is started before it's
+// known to be traced.
+func StartSpanAt(operation string, start time.Time) *Span {
+	return &Span{Operation: operation, Start: start}
+}
+
+// StartChild starts a child span of s, the children may be started concurrently.
+func (s *Span) StartChild(operation string) *Span {
+	if s == nil {
+		return nil
+	}
+	child := StartSpan(operation)
+	s.mu.Lock()
+	s.children = append(s.children, child)
+	s.mu.Unlock()
+	return child
+}
+
+// AddChild adds a finished child span of s.
+func (s *Span) AddChild(operation string, start time.Time, duration time.Duration) {
+	if s == nil {
+		return
+	}
+	child := &Span{Operation: operation, Start: start, duration: duration, finished: true}
+	s.mu.Lock()
+	s.children = append(s.children, child)
+	s.mu.Unlock()
+}
+
+// Finish finishes s, only the first call takes effect.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.finished {
+		s.duration = time.Since(s.Start)
+		s.finished = true
+	}
+	s.mu.Unlock()
+}
+
+// Duration returns the duration of s, it returns false if s isn't finished.
+func (s *Span) Duration() (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.duration, s.finished
+}
+
+// Children returns the children of s sorted by their start time.
+func (s *Span) Children() []*Span {
+	s.mu.Lock()
+	children := make([]*Span, len(s.children))
+	copy(children, s.children)
+	s.mu.Unlock()
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].Start.Before(children[j].Start)
+	})
+	return children
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx which carries the span.
+func ContextWithSpan(ctx goctx.Context, span *Span) goctx.Context {
+	if span == nil {
+		return ctx
+	}
+	return goctx.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, it returns nil if there is none.
+func SpanFromContext(ctx goctx.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// StartSpanFromContext starts a child span of the span carried by ctx, and returns it with a copy of ctx
+// which carries it. It returns nil and ctx if ctx doesn't carry a span.
+func StartSpanFromContext(ctx goctx.Context, operation string) (*Span, goctx.Context) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return nil, ctx
+	}
+	span := parent.StartChild(operation)
+	return span, goctx.WithValue(ctx, spanKey{}, span)
+}
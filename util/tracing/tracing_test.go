@@ -0,0 +1,84 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+	goctx "golang.org/x/net/context"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testTracingSuite{})
+
+type testTracingSuite struct{}
+
+func (s *testTracingSuite) TestSpan(c *C) {
+	defer testleak.AfterTest(c)()
+	start := time.Now().Add(-time.Second)
+	root := StartSpanAt("root", start)
+	child := root.StartChild("child")
+	root.AddChild("added", start, time.Millisecond)
+	_, ok := child.Duration()
+	c.Assert(ok, IsFalse)
+	child.Finish()
+	d, ok := child.Duration()
+	c.Assert(ok, IsTrue)
+	child.Finish()
+	d2, _ := child.Duration()
+	c.Assert(d2, Equals, d)
+
+	children := root.Children()
+	c.Assert(children, HasLen, 2)
+	c.Assert(children[0].Operation, Equals, "added")
+	c.Assert(children[1].Operation, Equals, "child")
+	d, ok = children[0].Duration()
+	c.Assert(ok, IsTrue)
+	c.Assert(d, Equals, time.Millisecond)
+
+	root.Finish()
+	d, ok = root.Duration()
+	c.Assert(ok, IsTrue)
+	c.Assert(d >= time.Second, IsTrue)
+
+	// All the methods do nothing on a nil span.
+	var span *Span
+	c.Assert(span.StartChild("child"), IsNil)
+	span.AddChild("added", start, time.Millisecond)
+	span.Finish()
+}
+
+func (s *testTracingSuite) TestContext(c *C) {
+	defer testleak.AfterTest(c)()
+	ctx := goctx.Background()
+	c.Assert(ContextWithSpan(ctx, nil), Equals, ctx)
+	c.Assert(SpanFromContext(ctx), IsNil)
+	span, ctx1 := StartSpanFromContext(ctx, "op")
+	c.Assert(span, IsNil)
+	c.Assert(ctx1, Equals, ctx)
+
+	root := StartSpan("root")
+	ctx = ContextWithSpan(ctx, root)
+	c.Assert(SpanFromContext(ctx), Equals, root)
+	span, ctx1 = StartSpanFromContext(ctx, "op")
+	c.Assert(SpanFromContext(ctx1), Equals, span)
+	c.Assert(root.Children(), DeepEquals, []*Span{span})
+}
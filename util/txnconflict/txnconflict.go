@@ -0,0 +1,163 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package txnconflict keeps the recent write conflicts of the optimistic transactions, to find out
+// the rows and index values the transactions contend for.
+package txnconflict
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/tablecodec"
+)
+
+// Capacity is the number of the most recent conflicts kept in memory.
+const Capacity = 1024
+
+// RecordIndexID is the index ID of the conflicts on the record data, index IDs start from 1.
+const RecordIndexID = 0
+
+// Conflict is a write conflict, the key written by a transaction has been written by another
+// transaction committed after the transaction starts.
+type Conflict struct {
+	Time    time.Time
+	StartTS uint64
+	// ConflictStartTS is the start ts of the other transaction, it's 0 if the store doesn't know it.
+	ConflictStartTS  uint64
+	ConflictCommitTS uint64
+	Key              []byte
+	// TableID is 0 if the key doesn't belong to any table.
+	TableID int64
+	// IndexID is RecordIndexID for the record data.
+	IndexID int64
+	// Handle is the handle of the record key.
+	Handle int64
+	// IndexValues are the values of the index key, separated by commas. The handle is the last
+	// value of a non-unique index key.
+	IndexValues string
+}
+
+// NewConflict creates a Conflict and decodes the key.
+func NewConflict(startTS, conflictStartTS, conflictCommitTS uint64, key []byte) *Conflict {
+	c := &Conflict{
+		Time:             time.Now(),
+		StartTS:          startTS,
+		ConflictStartTS:  conflictStartTS,
+		ConflictCommitTS: conflictCommitTS,
+		Key:              key,
+	}
+	tableID, indexID, isRecordKey, err := tablecodec.DecodeKeyHead(kv.Key(key))
+	if err != nil {
+		return c
+	}
+	if isRecordKey {
+		_, handle, err := tablecodec.DecodeRecordKey(kv.Key(key))
+		if err != nil {
+			return c
+		}
+		c.TableID, c.IndexID, c.Handle = tableID, RecordIndexID, handle
+		return c
+	}
+	datums, err := tablecodec.DecodeIndexKey(kv.Key(key))
+	if err != nil {
+		return c
+	}
+	values := make([]string, 0, len(datums))
+	for _, d := range datums {
+		s, err := d.ToString()
+		if err != nil {
+			return c
+		}
+		values = append(values, s)
+	}
+	c.TableID, c.IndexID, c.IndexValues = tableID, indexID, strings.Join(values, ", ")
+	return c
+}
+
+// KeyString returns the key decoded to the table, index and handle level.
+func (c *Conflict) KeyString() string {
+	switch {
+	case c.TableID == 0:
+		return fmt.Sprintf("%q", c.Key)
+	case c.IndexID == RecordIndexID:
+		return fmt.Sprintf("{tableID=%d, handle=%d}", c.TableID, c.Handle)
+	default:
+		return fmt.Sprintf("{tableID=%d, indexID=%d, indexValues={%s}}", c.TableID, c.IndexID, c.IndexValues)
+	}
+}
+
+// Err returns kv.ErrWriteConflict describing the conflict.
+func (c *Conflict) Err() error {
+	return kv.ErrWriteConflict.GenByArgs(c.StartTS, c.ConflictStartTS, c.ConflictCommitTS, c.KeyString())
+}
+
+// buffer is a ring buffer of the most recent conflicts.
+type buffer struct {
+	mu    sync.RWMutex
+	data  []*Conflict
+	start int
+}
+
+func (b *buffer) push(c *Conflict) {
+	b.mu.Lock()
+	if len(b.data) < cap(b.data) {
+		b.data = append(b.data, c)
+	} else {
+		// Overwrite the oldest one.
+		b.data[b.start] = c
+		b.start = (b.start + 1) % len(b.data)
+	}
+	b.mu.Unlock()
+}
+
+// recent returns the conflicts in the buffer, the latest first.
+func (b *buffer) recent() []*Conflict {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	ret := make([]*Conflict, 0, len(b.data))
+	for i := range b.data {
+		ret = append(ret, b.data[(b.start+len(b.data)-1-i)%len(b.data)])
+	}
+	return ret
+}
+
+func (b *buffer) reset() {
+	b.mu.Lock()
+	b.data = b.data[:0]
+	b.start = 0
+	b.mu.Unlock()
+}
+
+var defaultBuffer = &buffer{data: make([]*Conflict, 0, Capacity)}
+
+// Report keeps the conflict in memory and returns kv.ErrWriteConflict describing it, it's called by the
+// stores when the transactions fail to commit for the write conflicts.
+func Report(startTS, conflictStartTS, conflictCommitTS uint64, key []byte) error {
+	c := NewConflict(startTS, conflictStartTS, conflictCommitTS, key)
+	defaultBuffer.push(c)
+	return c.Err()
+}
+
+// Recent returns the recent conflicts kept in memory, the latest first.
+func Recent() []*Conflict {
+	return defaultBuffer.recent()
+}
+
+// Reset clears the conflicts kept in memory.
+func Reset() {
+	defaultBuffer.reset()
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txnconflict
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testTxnConflictSuite{})
+
+type testTxnConflictSuite struct{}
+
+func (s *testTxnConflictSuite) TestDecodeKey(c *C) {
+	defer testleak.AfterTest(c)()
+	conflict := NewConflict(10, 5, 12, tablecodec.EncodeRowKeyWithHandle(1, 100))
+	c.Assert(conflict.TableID, Equals, int64(1))
+	c.Assert(conflict.IndexID, Equals, int64(RecordIndexID))
+	c.Assert(conflict.Handle, Equals, int64(100))
+	c.Assert(conflict.KeyString(), Equals, "{tableID=1, handle=100}")
+
+	encoded, err := codec.EncodeKey(nil, types.NewStringDatum("abc"), types.NewIntDatum(100))
+	c.Assert(err, IsNil)
+	conflict = NewConflict(10, 5, 12, tablecodec.EncodeIndexSeekKey(2, 3, encoded))
+	c.Assert(conflict.TableID, Equals, int64(2))
+	c.Assert(conflict.IndexID, Equals, int64(3))
+	c.Assert(conflict.KeyString(), Equals, "{tableID=2, indexID=3, indexValues={abc, 100}}")
+
+	conflict = NewConflict(10, 0, 12, []byte("mDDLJobList"))
+	c.Assert(conflict.TableID, Equals, int64(0))
+	c.Assert(conflict.KeyString(), Equals, `"mDDLJobList"`)
+
+	err = conflict.Err()
+	c.Assert(terror.ErrorEqual(err, kv.ErrWriteConflict), IsTrue)
+	c.Assert(kv.IsRetryableError(err), IsTrue)
+	c.Assert(err.Error(), Equals, `[kv:13]write conflict, txnStartTS=10, conflictStartTS=0, conflictCommitTS=12, key="mDDLJobList"`)
+}
+
+func (s *testTxnConflictSuite) TestRecent(c *C) {
+	defer testleak.AfterTest(c)()
+	defer Reset()
+	Reset()
+	c.Assert(Recent(), HasLen, 0)
+	for i := 0; i < Capacity+10; i++ {
+		err := Report(uint64(i), 0, uint64(i+1), tablecodec.EncodeRowKeyWithHandle(1, int64(i)))
+		c.Assert(terror.ErrorEqual(err, kv.ErrWriteConflict), IsTrue)
+	}
+	conflicts := Recent()
+	c.Assert(conflicts, HasLen, Capacity)
+	// The latest first, the oldest ones are overwritten.
+	c.Assert(conflicts[0].Handle, Equals, int64(Capacity+9))
+	c.Assert(conflicts[Capacity-1].Handle, Equals, int64(10))
+}
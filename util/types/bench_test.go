@@ -0,0 +1,92 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+)
+
+var benchDecimals = []string{"123.45", "0.0001", "-98765.4321", "12345678901234567890.123456789", "7"}
+
+func newBenchDecimals() []*MyDecimal {
+	decs := make([]*MyDecimal, 0, len(benchDecimals))
+	for _, s := range benchDecimals {
+		dec := new(MyDecimal)
+		if err := dec.FromString([]byte(s)); err != nil {
+			panic(err)
+		}
+		decs = append(decs, dec)
+	}
+	return decs
+}
+
+func BenchmarkDecimalAdd(b *testing.B) {
+	decs := newBenchDecimals()
+	var to MyDecimal
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, x := range decs {
+			DecimalAdd(decs[0], x, &to)
+		}
+	}
+}
+
+func BenchmarkDecimalSub(b *testing.B) {
+	decs := newBenchDecimals()
+	var to MyDecimal
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, x := range decs {
+			DecimalSub(decs[0], x, &to)
+		}
+	}
+}
+
+func BenchmarkDecimalMul(b *testing.B) {
+	decs := newBenchDecimals()
+	var to MyDecimal
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, x := range decs {
+			DecimalMul(decs[0], x, &to)
+		}
+	}
+}
+
+func BenchmarkDecimalDiv(b *testing.B) {
+	decs := newBenchDecimals()
+	var to MyDecimal
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, x := range decs {
+			DecimalDiv(decs[0], x, &to, DivFracIncr)
+		}
+	}
+}
+
+func BenchmarkComputePlusDecimal(b *testing.B) {
+	decs := newBenchDecimals()
+	sum := NewDecimalDatum(NewDecFromInt(0))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, x := range decs[:3] {
+			sum, _ = ComputePlus(sum, NewDecimalDatum(x))
+		}
+	}
+}
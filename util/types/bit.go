@@ -94,9 +94,14 @@ func ParseBit(s string, width int) (Bit, error) {
 		return Bit{}, errors.Errorf("invalid display width for bit type, must in [1, 64], but %d", width)
 	}
 
-	n, err := strconv.ParseUint(s, 2, 64)
-	if err != nil {
-		return Bit{}, errors.Trace(err)
+	// b'' is an empty bit value, which is 0.
+	var n uint64
+	if len(s) > 0 {
+		var err error
+		n, err = strconv.ParseUint(s, 2, 64)
+		if err != nil {
+			return Bit{}, errors.Trace(err)
+		}
 	}
 
 	if n > (uint64(1)<<uint64(width))-1 {
@@ -123,6 +128,10 @@ func ParseStringToBitValue(s string, width int) (uint64, error) {
 		return 0, errors.Errorf("invalid display width for bit type, must in [1, 64], but %d", width)
 	}
 
+	if len(b) > MaxBitWidth/8 {
+		return 0, errors.Errorf("bit %s is too long for width %d", s, width)
+	}
+
 	var n uint64
 	l := len(b)
 	for i := range b {
@@ -14,7 +14,12 @@
 package types
 
 import (
+	"math"
+
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
@@ -35,6 +40,7 @@ func (s *testBitSuite) TestBit(c *C) {
 		{"0b01", 8, 1, "0b00000001", "\x01"},
 		{"0b111111111", 16, 511, "0b0000000111111111", "\x01\xff"},
 		{"0b01", -1, 1, "0b00000001", "\x01"},
+		{"b''", -1, 0, "0b0", "\x00"},
 	}
 
 	for _, t := range tbl {
@@ -61,4 +67,55 @@ func (s *testBitSuite) TestBit(c *C) {
 		_, err := ParseBit(t.Input, t.Width)
 		c.Assert(err, NotNil)
 	}
+
+	_, err := ParseStringToBitValue("123456789", MaxBitWidth)
+	c.Assert(err, NotNil)
+}
+
+func (s *testBitSuite) TestBitDatum(c *C) {
+	defer testleak.AfterTest(c)()
+	sc := new(variable.StatementContext)
+	ft := NewFieldType(mysql.TypeBit)
+	ft.Flen = 8
+	tbl := []struct {
+		Input    Datum
+		Value    uint64
+		Overflow bool
+	}{
+		{NewStringDatum("a"), 97, false},
+		{NewStringDatum(""), 0, false},
+		{NewStringDatum("ab"), 255, true},
+		{NewStringDatum("123456789"), 255, true},
+		{NewIntDatum(255), 255, false},
+		{NewIntDatum(256), 255, true},
+	}
+	for _, t := range tbl {
+		d, err := t.Input.ConvertTo(sc, ft)
+		if t.Overflow {
+			c.Assert(terror.ErrorEqual(err, ErrOverflow), IsTrue, Commentf("%v", t.Input))
+			c.Assert(d.GetUint64(), Equals, t.Value)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Assert(d.GetMysqlBit().Value, Equals, t.Value)
+	}
+
+	// Bit values are unsigned integers in arithmetic and comparison.
+	bit := NewDatum(Bit{Value: math.MaxUint64, Width: 64})
+	n, err := CoerceArithmetic(sc, bit)
+	c.Assert(err, IsNil)
+	c.Assert(n.GetUint64(), Equals, uint64(math.MaxUint64))
+	cmp, err := bit.CompareDatum(sc, NewUintDatum(math.MaxUint64))
+	c.Assert(err, IsNil)
+	c.Assert(cmp, Equals, 0)
+	cmp, err = bit.CompareDatum(sc, NewIntDatum(-1))
+	c.Assert(err, IsNil)
+	c.Assert(cmp, Equals, 1)
+	u := NewUintDatum(math.MaxUint64 - 1)
+	cmp, err = u.CompareDatum(sc, bit)
+	c.Assert(err, IsNil)
+	c.Assert(cmp, Equals, -1)
+	dec, err := bit.ToDecimal(sc)
+	c.Assert(err, IsNil)
+	c.Assert(dec.String(), Equals, "18446744073709551615")
 }
@@ -50,7 +50,14 @@ func CompareFloat64(x, y float64) int {
 	return 1
 }
 
+// WeightString returns the sort key of str, the strings are ordered as their sort keys compared in bytes.
+// The strings are compared in binary for all the collations currently, so the sort key is str itself.
+func WeightString(str string) []byte {
+	return []byte(str)
+}
+
 // CompareString returns an integer comparing the string x to y.
+// It's the comparator of the sort keys returned by WeightString.
 func CompareString(x, y string) int {
 	if x < y {
 		return -1
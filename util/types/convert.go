@@ -61,6 +61,14 @@ var signedLowerBound = map[byte]int64{
 	mysql.TypeLonglong: math.MinInt64,
 }
 
+// IntegerUpperBound returns the max value of the integer type, it's 0 if tp isn't an integer type.
+func IntegerUpperBound(tp byte, unsigned bool) uint64 {
+	if unsigned {
+		return unsignedUpperBound[tp]
+	}
+	return uint64(signedUpperBound[tp])
+}
+
 func convertFloatToInt(sc *variable.StatementContext, fval float64, lowerBound, upperBound int64, tp byte) (int64, error) {
 	val := RoundFloat(fval)
 	if val < float64(lowerBound) {
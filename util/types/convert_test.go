@@ -581,9 +581,17 @@ func (s *testTypeConvertSuite) TestConvert(c *C) {
 	signedAccept(c, mysql.TypeDouble, "1e+1", "10")
 
 	// year
-	signedDeny(c, mysql.TypeYear, 123, "<nil>")
-	signedDeny(c, mysql.TypeYear, 3000, "<nil>")
+	signedDeny(c, mysql.TypeYear, 123, "0")
+	signedDeny(c, mysql.TypeYear, 3000, "0")
+	signedDeny(c, mysql.TypeYear, -1, "0")
 	signedAccept(c, mysql.TypeYear, "2000", "2000")
+	signedAccept(c, mysql.TypeYear, 0, "0")
+	signedAccept(c, mysql.TypeYear, "0", "2000")
+	signedAccept(c, mysql.TypeYear, "00", "2000")
+	signedAccept(c, mysql.TypeYear, "0000", "0")
+	signedAccept(c, mysql.TypeYear, 69, "2069")
+	signedAccept(c, mysql.TypeYear, " 70 ", "1970")
+	signedAccept(c, mysql.TypeYear, 1999.5, "2000")
 
 	// time from string
 	signedAccept(c, mysql.TypeDate, "2012-08-23", "2012-08-23")
@@ -14,10 +14,12 @@
 package types
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/juju/errors"
@@ -450,6 +452,8 @@ func (d *Datum) CompareDatum(sc *variable.StatementContext, ad Datum) (int, erro
 		return d.compareMysqlTime(sc, ad.GetMysqlTime())
 	case KindRow:
 		return d.compareRow(sc, ad.GetRow())
+	case KindMysqlJSON:
+		return d.compareMysqlJSON(sc, ad.GetMysqlJSON())
 	default:
 		return 0, nil
 	}
@@ -466,6 +470,12 @@ func (d *Datum) compareInt64(sc *variable.StatementContext, i int64) (int, error
 			return 1, nil
 		}
 		return CompareInt64(d.i, i), nil
+	case KindMysqlBit:
+		v := d.GetMysqlBit().Value
+		if i < 0 || v > math.MaxInt64 {
+			return 1, nil
+		}
+		return CompareInt64(int64(v), i), nil
 	default:
 		return d.compareFloat64(sc, float64(i))
 	}
@@ -482,6 +492,8 @@ func (d *Datum) compareUint64(sc *variable.StatementContext, u uint64) (int, err
 		return CompareInt64(d.i, int64(u)), nil
 	case KindUint64:
 		return CompareUint64(d.GetUint64(), u), nil
+	case KindMysqlBit:
+		return CompareUint64(d.GetMysqlBit().Value, u), nil
 	default:
 		return d.compareFloat64(sc, float64(u))
 	}
@@ -572,7 +584,7 @@ func (d *Datum) compareMysqlBit(sc *variable.StatementContext, bit Bit) (int, er
 	case KindString, KindBytes:
 		return CompareString(d.GetString(), bit.ToString()), nil
 	default:
-		return d.compareFloat64(sc, bit.ToNumber())
+		return d.compareUint64(sc, bit.Value)
 	}
 }
 
@@ -642,6 +654,15 @@ func (d *Datum) compareMysqlTime(sc *variable.StatementContext, time Time) (int,
 	}
 }
 
+// compareMysqlJSON compares the JSON values by the serialized bytes, so the different values are never equal.
+// A non-JSON value is compared with the JSON text.
+func (d *Datum) compareMysqlJSON(sc *variable.StatementContext, j json.JSON) (int, error) {
+	if d.k == KindMysqlJSON {
+		return bytes.Compare(json.Serialize(d.GetMysqlJSON()), json.Serialize(j)), nil
+	}
+	return d.compareString(sc, j.String())
+}
+
 func (d *Datum) compareRow(sc *variable.StatementContext, row []Datum) (int, error) {
 	var dRow []Datum
 	if d.k == KindRow {
@@ -802,9 +823,10 @@ func (d *Datum) convertToString(sc *variable.StatementContext, target *FieldType
 
 	var err error
 	if target.Flen >= 0 {
-		// Flen is the rune length, not binary length, for UTF8 charset, we need to calculate the
-		// rune count and truncate to Flen runes if it is too long.
-		if target.Charset == charset.CharsetUTF8 || target.Charset == charset.CharsetUTF8MB4 {
+		// Flen is the rune length, not binary length, for non-binary charsets, strings are stored
+		// in UTF8, we need to calculate the rune count and truncate to Flen runes if it is too long.
+		if target.Charset == charset.CharsetUTF8 || target.Charset == charset.CharsetUTF8MB4 ||
+			charset.NeedConversion(target.Charset) {
 			var runeCount int
 			var truncateLen int
 			for i := range s {
@@ -889,7 +911,7 @@ func (d *Datum) convertToUint(sc *variable.StatementContext, target *FieldType)
 	case KindMysqlHex:
 		val, err = convertFloatToUint(sc, d.GetMysqlHex().ToNumber(), upperBound, tp)
 	case KindMysqlBit:
-		val, err = convertFloatToUint(sc, d.GetMysqlBit().ToNumber(), upperBound, tp)
+		val, err = convertUintToUint(d.GetMysqlBit().Value, upperBound, tp)
 	case KindMysqlEnum:
 		val, err = convertFloatToUint(sc, d.GetMysqlEnum().ToNumber(), upperBound, tp)
 	case KindMysqlSet:
@@ -987,8 +1009,12 @@ func (d *Datum) convertToMysqlDuration(sc *variable.StatementContext, target *Fi
 
 func (d *Datum) convertToMysqlDecimal(sc *variable.StatementContext, target *FieldType) (Datum, error) {
 	var ret Datum
-	ret.SetLength(target.Flen)
-	ret.SetFrac(target.Decimal)
+	// The precision and frac of the decimal are used to encode it, leave them unset if they are unknown,
+	// otherwise the decimal can't be encoded.
+	if target.Flen != UnspecifiedLength && target.Decimal != UnspecifiedLength {
+		ret.SetLength(target.Flen)
+		ret.SetFrac(target.Decimal)
+	}
 	var dec = &MyDecimal{}
 	var err error
 	switch d.k {
@@ -1007,7 +1033,7 @@ func (d *Datum) convertToMysqlDecimal(sc *variable.StatementContext, target *Fie
 	case KindMysqlDuration:
 		dec = d.GetMysqlDuration().ToNumber()
 	case KindMysqlBit:
-		dec.FromFloat64(d.GetMysqlBit().ToNumber())
+		dec.FromUint(d.GetMysqlBit().Value)
 	case KindMysqlEnum:
 		dec.FromFloat64(d.GetMysqlEnum().ToNumber())
 	case KindMysqlHex:
@@ -1035,16 +1061,19 @@ func (d *Datum) convertToMysqlDecimal(sc *variable.StatementContext, target *Fie
 
 func (d *Datum) convertToMysqlYear(sc *variable.StatementContext, target *FieldType) (Datum, error) {
 	var (
-		ret Datum
-		y   int64
-		err error
+		ret        Datum
+		y          int64
+		err        error
+		adjustZero bool
 	)
 	switch d.k {
 	case KindString, KindBytes:
-		y, err = StrToInt(sc, d.GetString())
+		s := strings.TrimSpace(d.GetString())
+		y, err = StrToInt(sc, s)
 		if err != nil {
 			return ret, errors.Trace(err)
 		}
+		adjustZero = len(s) != 4
 	case KindMysqlTime:
 		y = int64(d.GetMysqlTime().Time.Year())
 	case KindMysqlDuration:
@@ -1052,13 +1081,17 @@ func (d *Datum) convertToMysqlYear(sc *variable.StatementContext, target *FieldT
 	default:
 		ret, err = d.convertToInt(sc, NewFieldType(mysql.TypeLonglong))
 		if err != nil {
-			return invalidConv(d, target.Tp)
+			ret.SetInt64(0)
+			return ret, errors.Trace(err)
 		}
 		y = ret.GetInt64()
 	}
-	y, err = AdjustYear(y)
+	origin := y
+	y, err = AdjustYear(y, adjustZero)
 	if err != nil {
-		return invalidConv(d, target.Tp)
+		// The out of range value is stored as the zero year.
+		ret.SetInt64(0)
+		return ret, overflow(origin, target.Tp)
 	}
 	ret.SetInt64(y)
 	return ret, nil
@@ -1069,9 +1102,23 @@ func (d *Datum) convertToMysqlBit(sc *variable.StatementContext, target *FieldTy
 		x   Datum
 		err error
 	)
+	// check bit boundary, if bit has n width, the boundary is
+	// in [0, (1 << n) - 1]
+	width := target.Flen
+	if width == 0 || width == UnspecifiedBitWidth {
+		width = MinBitWidth
+	}
+	maxValue := uint64(1)<<uint64(width) - 1
 	if d.Kind() == KindString || d.Kind() == KindBytes {
+		// Strings are taken as big-endian binary values, '' is 0.
+		s := d.GetString()
+		if len(s) > MaxBitWidth/8 {
+			return NewUintDatum(maxValue), overflow(s, target.Tp)
+		}
 		var n uint64
-		n, err = ParseStringToBitValue(d.GetString(), target.Flen)
+		if len(s) > 0 {
+			n, err = ParseStringToBitValue(s, MaxBitWidth)
+		}
 		x = NewUintDatum(n)
 	} else {
 		x, err = d.convertToUint(sc, target)
@@ -1079,13 +1126,6 @@ func (d *Datum) convertToMysqlBit(sc *variable.StatementContext, target *FieldTy
 	if err != nil {
 		return x, errors.Trace(err)
 	}
-	// check bit boundary, if bit has n width, the boundary is
-	// in [0, (1 << n) - 1]
-	width := target.Flen
-	if width == 0 || width == UnspecifiedBitWidth {
-		width = MinBitWidth
-	}
-	maxValue := uint64(1)<<uint64(width) - 1
 	val := x.GetUint64()
 	if val > maxValue {
 		x.SetUint64(maxValue)
@@ -1155,6 +1195,17 @@ func (d *Datum) convertToMysqlJSON(sc *variable.StatementContext, target *FieldT
 	switch d.k {
 	case KindString, KindBytes:
 		s = d.GetString()
+	case KindMysqlJSON:
+		ret.SetValue(d.GetMysqlJSON())
+		return ret, nil
+	case KindInt64, KindUint64, KindFloat32, KindFloat64, KindMysqlDecimal:
+		// A number is converted to a JSON number instead of being parsed as the JSON text.
+		var f float64
+		f, err = d.ToFloat64(sc)
+		if err == nil {
+			ret.SetValue(json.CreateJSON(f))
+		}
+		return ret, errors.Trace(err)
 	default:
 		return invalidConv(d, target.Tp)
 	}
@@ -1194,7 +1245,7 @@ func (d *Datum) ToBool(sc *variable.StatementContext) (int64, error) {
 	case KindMysqlHex:
 		isZero = (d.GetMysqlHex().ToNumber() == 0)
 	case KindMysqlBit:
-		isZero = (d.GetMysqlBit().ToNumber() == 0)
+		isZero = (d.GetMysqlBit().Value == 0)
 	case KindMysqlEnum:
 		isZero = (d.GetMysqlEnum().ToNumber() == 0)
 	case KindMysqlSet:
@@ -1308,8 +1359,7 @@ func (d *Datum) toSignedInteger(sc *variable.StatementContext, tp byte) (int64,
 		fval := d.GetMysqlHex().ToNumber()
 		return convertFloatToInt(sc, fval, lowerBound, upperBound, tp)
 	case KindMysqlBit:
-		fval := d.GetMysqlBit().ToNumber()
-		return convertFloatToInt(sc, fval, lowerBound, upperBound, tp)
+		return convertUintToInt(d.GetMysqlBit().Value, upperBound, tp)
 	case KindMysqlEnum:
 		fval := d.GetMysqlEnum().ToNumber()
 		return convertFloatToInt(sc, fval, lowerBound, upperBound, tp)
@@ -58,7 +58,8 @@ func CoerceArithmetic(sc *variable.StatementContext, a Datum) (d Datum, err erro
 		d.SetFloat64(a.GetMysqlHex().ToNumber())
 		return d, nil
 	case KindMysqlBit:
-		d.SetFloat64(a.GetMysqlBit().ToNumber())
+		// MySQL treats bit as unsigned integer in arithmetic.
+		d.SetUint64(a.GetMysqlBit().Value)
 		return d, nil
 	case KindMysqlEnum:
 		d.SetFloat64(a.GetMysqlEnum().ToNumber())
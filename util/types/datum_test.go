@@ -17,6 +17,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types/json"
 )
 
 var _ = Suite(&testDatumSuite{})
@@ -96,11 +97,13 @@ func (ts *testDatumSuite) TestEqualDatums(c *C) {
 		{[]interface{}{1}, []interface{}{1}, true},
 		{[]interface{}{1, "aa"}, []interface{}{1, "aa"}, true},
 		{[]interface{}{1, "aa", 1}, []interface{}{1, "aa", 1}, true},
+		{[]interface{}{json.CreateJSON([]interface{}{1.0, "a"})}, []interface{}{json.CreateJSON([]interface{}{1.0, "a"})}, true},
 
 		// Negative cases
 		{[]interface{}{1}, []interface{}{2}, false},
 		{[]interface{}{1, "a"}, []interface{}{1, "aaaaaa"}, false},
 		{[]interface{}{1, "aa", 3}, []interface{}{1, "aa", 2}, false},
+		{[]interface{}{json.CreateJSON([]interface{}{1.0, 2.0})}, []interface{}{json.CreateJSON([]interface{}{2.0, 1.0})}, false},
 
 		// Corner cases
 		{[]interface{}{}, []interface{}{}, true},
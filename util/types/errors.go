@@ -29,6 +29,8 @@ var (
 	ErrDivByZero = terror.ClassTypes.New(codeDivByZero, "Division by 0")
 	// ErrBadNumber is return when parsing an invalid binary decimal number.
 	ErrBadNumber = terror.ClassTypes.New(codeBadNumber, "Bad Number")
+	// ErrDatetimeFunctionOverflow is returned when the result of date arithmetic is out of the datetime range.
+	ErrDatetimeFunctionOverflow = terror.ClassTypes.New(codeDatetimeFunctionOverflow, mysql.MySQLErrName[mysql.ErrDatetimeFunctionOverflow])
 )
 
 const (
@@ -38,6 +40,8 @@ const (
 	codeTruncated   terror.ErrCode = terror.ErrCode(mysql.WarnDataTruncated)
 	codeOverflow    terror.ErrCode = terror.ErrCode(mysql.ErrDataOutOfRange)
 	codeDivByZero   terror.ErrCode = terror.ErrCode(mysql.ErrDivisionByZero)
+
+	codeDatetimeFunctionOverflow terror.ErrCode = terror.ErrCode(mysql.ErrDatetimeFunctionOverflow)
 )
 
 var (
@@ -50,6 +54,8 @@ func init() {
 		codeTruncated:   mysql.WarnDataTruncated,
 		codeOverflow:    mysql.ErrDataOutOfRange,
 		codeDivByZero:   mysql.ErrDivisionByZero,
+
+		codeDatetimeFunctionOverflow: mysql.ErrDatetimeFunctionOverflow,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassTypes] = typesMySQLErrCodes
 }
@@ -85,8 +85,10 @@ func (s *testTimeSuite) TestStrToDate(c *C) {
 		{`abc`, `abc`, ZeroTime},
 		{`09`, `%m`, FromDate(0, 9, 0, 0, 0, 0, 0)},
 		{`09`, `%s`, FromDate(0, 0, 0, 0, 0, 9, 0)},
-		{`12:43:24 AM`, `%r`, FromDate(0, 0, 0, 12, 43, 24, 0)},
+		{`12:43:24 AM`, `%r`, FromDate(0, 0, 0, 0, 43, 24, 0)},
+		{`12:43:24 PM`, `%r`, FromDate(0, 0, 0, 12, 43, 24, 0)},
 		{`11:43:24 PM`, `%r`, FromDate(0, 0, 0, 23, 43, 24, 0)},
+		{`12:43:24`, `%r`, FromDate(0, 0, 0, 0, 43, 24, 0)}, // AM is used when the date is exhausted
 		{`00:12:13`, `%T`, FromDate(0, 0, 0, 0, 12, 13, 0)},
 		{`23:59:59`, `%T`, FromDate(0, 0, 0, 23, 59, 59, 0)},
 		{`00/00/0000`, `%m/%d/%Y`, ZeroTime},
@@ -97,6 +99,21 @@ func (s *testTimeSuite) TestStrToDate(c *C) {
 		{`10:13 PM`, `%l:%i %p`, FromDate(0, 0, 0, 22, 13, 0, 0)},
 		{`12:00:00 AM`, `%h:%i:%s %p`, FromDate(0, 0, 0, 0, 0, 0, 0)},
 		{`12:00:00 PM`, `%h:%i:%s %p`, FromDate(0, 0, 0, 12, 0, 0, 0)},
+		{`1:05 pm`, `%l:%i %p`, FromDate(0, 0, 0, 13, 5, 0, 0)},
+		{`2017-1-1 12:34:56.5`, `%Y-%m-%d %H:%i:%s.%f`, FromDate(2017, 1, 1, 12, 34, 56, 500000)},
+		{`2017-1-1 12:34:56.1234567`, `%Y-%m-%d %H:%i:%s.%f`, FromDate(2017, 1, 1, 12, 34, 56, 123456)},
+		{`2016 060`, `%Y %j`, FromDate(2016, 2, 29, 0, 0, 0, 0)},
+		{`17 1`, `%y %j`, FromDate(2017, 1, 1, 0, 0, 0, 0)},
+		{`jANUARY 5 16`, `%M %e %y`, FromDate(2016, 1, 5, 0, 0, 0, 0)},
+		{`Thu, 7th Jan 10`, `%a, %D %b %y`, FromDate(2010, 1, 7, 0, 0, 0, 0)},
+		{`2010 1 4`, `%Y %U %w`, FromDate(2010, 1, 7, 0, 0, 0, 0)},
+		{`2010 01 Thursday`, `%x %v %W`, FromDate(2010, 1, 7, 0, 0, 0, 0)},
+		{`2012 51 5`, `%X %V %w`, FromDate(2012, 12, 21, 0, 0, 0, 0)},
+		{`2012 51 Fri`, `%Y %u %a`, FromDate(2012, 12, 21, 0, 0, 0, 0)},
+		{`99-12-31`, `%Y-%m-%d`, FromDate(1999, 12, 31, 0, 0, 0, 0)},
+		{`2016-12-31 100%`, `%Y-%m-%d %f%%`, FromDate(2016, 12, 31, 0, 0, 0, 100000)},
+		{`2016.12..31`, `%Y%.%m%.%d`, FromDate(2016, 12, 31, 0, 0, 0, 0)},
+		{`2016`, `%Y-%m-%d`, FromDate(2016, 0, 0, 0, 0, 0, 0)},
 	}
 	for i, tt := range tests {
 		var t Time
@@ -110,11 +127,19 @@ func (s *testTimeSuite) TestStrToDate(c *C) {
 	}{
 		{`04/31/2004`, `%m/%d/%Y`},
 		{`a09:30:17`, `%h:%i:%s`}, // format mismatch
-		{`12:43:24 PM`, `%r`},
-		{`12:43:24`, `%r`}, // no PM or AM followed
+		{`13:43:24 PM`, `%r`},
+		{`12:43:24 XM`, `%r`},
 		{`23:60:12`, `%T`}, // invalid minute
 		{`18`, `%l`},
 		{`00:21:22 AM`, `%h:%i:%s %p`},
+		{`AM 10:13`, `%p %l:%i`}, // %p must follow the 12-hour clock
+		{`2016 367`, `%Y %j`},
+		{`Janu 2016`, `%b %Y`},
+		{`2010 01 4`, `%Y %V %w`}, // %V requires %X
+		{`2010 01 4`, `%X %U %w`}, // %U doesn't work with %X
+		{`2010 00 4`, `%x %v %w`},
+		{`2010 54 4`, `%Y %u %w`},
+		{`2010 1 7`, `%Y %U %w`},
 	}
 	for _, tt := range errTests {
 		var t Time
@@ -32,6 +32,19 @@ func ParseFromString(s string) (JSON, error) {
 	return normalize(in), nil
 }
 
+// CreateJSON creates a JSON from a nil, a bool, a float64, a string, or the maps and slices of them.
+func CreateJSON(in interface{}) JSON {
+	return normalize(in)
+}
+
+// Unquote returns the value of j if it's a JSON string, or the JSON text of j otherwise.
+func Unquote(j JSON) string {
+	if s, ok := deref(j).(jsonString); ok {
+		return string(s)
+	}
+	return j.String()
+}
+
 func normalize(in interface{}) JSON {
 	switch t := in.(type) {
 	case bool:
@@ -35,12 +35,15 @@ var (
 	ErrInvalidJSONPath = terror.ClassJSON.New(mysql.ErrInvalidJSONPath, mysql.MySQLErrName[mysql.ErrInvalidJSONPath])
 	// ErrInvalidJSONData means invalid JSON data.
 	ErrInvalidJSONData = terror.ClassJSON.New(mysql.ErrInvalidJSONData, mysql.MySQLErrName[mysql.ErrInvalidJSONData])
+	// ErrJSONDocumentNULLKey means a NULL member name of a JSON object.
+	ErrJSONDocumentNULLKey = terror.ClassJSON.New(mysql.ErrJSONDocumentNULLKey, mysql.MySQLErrName[mysql.ErrJSONDocumentNULLKey])
 )
 
 func init() {
 	terror.ErrClassToMySQLCodes[terror.ClassJSON] = map[terror.ErrCode]uint16{
-		mysql.ErrInvalidJSONText: mysql.ErrInvalidJSONText,
-		mysql.ErrInvalidJSONPath: mysql.ErrInvalidJSONPath,
-		mysql.ErrInvalidJSONData: mysql.ErrInvalidJSONData,
+		mysql.ErrInvalidJSONText:     mysql.ErrInvalidJSONText,
+		mysql.ErrInvalidJSONPath:     mysql.ErrInvalidJSONPath,
+		mysql.ErrInvalidJSONData:     mysql.ErrInvalidJSONData,
+		mysql.ErrJSONDocumentNULLKey: mysql.ErrJSONDocumentNULLKey,
 	}
 }
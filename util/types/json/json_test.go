@@ -74,3 +74,131 @@ func (s *testJSONSuite) TestParseFromString(c *C) {
 	var jstr2 = j1.String()
 	c.Assert(jstr2, Equals, `{"a":[1,"2",{"aa":"bb"},4,null],"b":true,"c":null}`)
 }
+
+func (s *testJSONSuite) TestExtract(c *C) {
+	j, err := ParseFromString(`{"a": [1, "2", {"aa": "bb"}], "b c": true, "d": 3}`)
+	c.Assert(err, IsNil)
+	// The values decoded from the storage are the same.
+	decoded, err := Deserialize(Serialize(j))
+	c.Assert(err, IsNil)
+
+	var tests = []struct {
+		path  string
+		found bool
+		value string
+	}{
+		{`$`, true, j.String()},
+		{`$.a`, true, `[1,"2",{"aa":"bb"}]`},
+		{`$.a[1]`, true, `"2"`},
+		{`$.a[2].aa`, true, `"bb"`},
+		{`$ . a [ 0 ]`, true, `1`},
+		{`$."b c"`, true, `true`},
+		{`$.d[0]`, true, `3`},
+		{`$.d[1]`, false, ``},
+		{`$.a[3]`, false, ``},
+		{`$.e`, false, ``},
+		{`$.a.aa`, false, ``},
+	}
+	for _, t := range tests {
+		pe, err := ParseJSONPathExpr(t.path)
+		c.Assert(err, IsNil, Commentf("path %s", t.path))
+		for _, doc := range []JSON{j, decoded} {
+			ret, found := Extract(doc, pe)
+			c.Assert(found, Equals, t.found, Commentf("path %s", t.path))
+			if found {
+				c.Assert(ret.String(), Equals, t.value, Commentf("path %s", t.path))
+			}
+		}
+	}
+
+	for _, path := range []string{``, `a`, `$.`, `$[`, `$[-1]`, `$.*`, `$[*]`, `$**.a`, `$."a`} {
+		_, err := ParseJSONPathExpr(path)
+		c.Assert(ErrInvalidJSONPath.Equal(err), IsTrue, Commentf("path %s", path))
+	}
+}
+
+func (s *testJSONSuite) TestContains(c *C) {
+	var tests = []struct {
+		target    string
+		candidate string
+		contains  bool
+	}{
+		{`1`, `1.0`, true},
+		{`1`, `"1"`, false},
+		{`[1, "a", [2, 3]]`, `"a"`, true},
+		{`[1, "a", [2, 3]]`, `[1, "a"]`, true},
+		{`[1, "a", [2, 3]]`, `[1, 2]`, true},
+		{`[1, "a", [2, 3]]`, `[1, 4]`, false},
+		{`[1, "a", [2, 3]]`, `[]`, true},
+		{`{"a": 1, "b": [1, 2]}`, `{"b": 2}`, true},
+		{`{"a": 1, "b": [1, 2]}`, `{"a": 1, "c": 2}`, false},
+		{`{"a": 1}`, `1`, false},
+		{`"a"`, `["a"]`, false},
+	}
+	for _, t := range tests {
+		target, err := ParseFromString(t.target)
+		c.Assert(err, IsNil)
+		candidate, err := ParseFromString(t.candidate)
+		c.Assert(err, IsNil)
+		c.Assert(Contains(target, candidate), Equals, t.contains, Commentf("%s contains %s", t.target, t.candidate))
+		// The values decoded from the storage are the same.
+		target, err = Deserialize(Serialize(target))
+		c.Assert(err, IsNil)
+		c.Assert(Contains(target, candidate), Equals, t.contains, Commentf("%s contains %s", t.target, t.candidate))
+	}
+}
+
+func (s *testJSONSuite) TestSet(c *C) {
+	var tests = []struct {
+		doc   string
+		path  string
+		value string
+		ret   string
+	}{
+		{`{"a": 1}`, `$.a`, `2`, `{"a":2}`},
+		{`{"a": 1}`, `$.b`, `[2]`, `{"a":1,"b":[2]}`},
+		{`{"a": 1}`, `$.b.c`, `2`, `{"a":1}`},
+		{`{"a": [1, 2]}`, `$.a[1]`, `3`, `{"a":[1,3]}`},
+		{`{"a": [1, 2]}`, `$.a[5]`, `3`, `{"a":[1,2,3]}`},
+		{`{"a": [1, 2]}`, `$.a[5][0]`, `3`, `{"a":[1,2]}`},
+		{`{"a": 1}`, `$.a[0]`, `2`, `{"a":2}`},
+		{`{"a": 1}`, `$.a[1]`, `2`, `{"a":[1,2]}`},
+		{`[{"a": 1}]`, `$[0].b`, `"x"`, `[{"a":1,"b":"x"}]`},
+		{`1`, `$.a`, `2`, `1`},
+		{`1`, `$`, `{"b": true}`, `{"b":true}`},
+	}
+	for _, t := range tests {
+		doc, err := ParseFromString(t.doc)
+		c.Assert(err, IsNil)
+		pe, err := ParseJSONPathExpr(t.path)
+		c.Assert(err, IsNil)
+		value, err := ParseFromString(t.value)
+		c.Assert(err, IsNil)
+		// The values decoded from the storage are the same.
+		decoded, err := Deserialize(Serialize(doc))
+		c.Assert(err, IsNil)
+		for _, j := range []JSON{doc, decoded} {
+			c.Assert(Set(j, pe, value).String(), Equals, t.ret, Commentf("set %s of %s", t.path, t.doc))
+		}
+		// The document itself isn't modified.
+		c.Assert(decoded.String(), Equals, doc.String())
+	}
+}
+
+func (s *testJSONSuite) TestUnquote(c *C) {
+	var tests = []struct {
+		doc string
+		ret string
+	}{
+		{`"a\"b"`, `a"b`},
+		{`""`, ``},
+		{`[1, "a"]`, `[1,"a"]`},
+		{`null`, `null`},
+		{`1.5`, `1.5`},
+	}
+	for _, t := range tests {
+		j, err := ParseFromString(t.doc)
+		c.Assert(err, IsNil)
+		c.Assert(Unquote(j), Equals, t.ret)
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// pathLeg is a member or an array cell of a JSON path expression.
+type pathLeg struct {
+	isKey bool
+	key   string
+	index int
+}
+
+// PathExpression is a JSON path expression like `$.a[1]."b c"`, the wildcards aren't supported.
+type PathExpression struct {
+	legs []pathLeg
+	text string
+}
+
+// String implements the fmt.Stringer interface.
+func (pe PathExpression) String() string {
+	return pe.text
+}
+
+// IsRoot returns whether the path expression is `$`.
+func (pe PathExpression) IsRoot() bool {
+	return len(pe.legs) == 0
+}
+
+// Equal returns whether the two path expressions have the same legs.
+func (pe PathExpression) Equal(other PathExpression) bool {
+	if len(pe.legs) != len(other.legs) {
+		return false
+	}
+	for i := range pe.legs {
+		if pe.legs[i] != other.legs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseJSONPathExpr parses a JSON path expression.
+func ParseJSONPathExpr(s string) (pe PathExpression, err error) {
+	pe.text = strings.TrimSpace(s)
+	rest := pe.text
+	if !strings.HasPrefix(rest, "$") {
+		return pe, ErrInvalidJSONPath.GenByArgs()
+	}
+	rest = strings.TrimLeftFunc(rest[1:], unicode.IsSpace)
+	for len(rest) > 0 {
+		var leg pathLeg
+		switch rest[0] {
+		case '.':
+			rest = strings.TrimLeftFunc(rest[1:], unicode.IsSpace)
+			leg.isKey = true
+			if leg.key, rest, err = parsePathKey(rest); err != nil {
+				return pe, err
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return pe, ErrInvalidJSONPath.GenByArgs()
+			}
+			leg.index, err = strconv.Atoi(strings.TrimSpace(rest[1:end]))
+			if err != nil || leg.index < 0 {
+				return pe, ErrInvalidJSONPath.GenByArgs()
+			}
+			rest = rest[end+1:]
+		default:
+			return pe, ErrInvalidJSONPath.GenByArgs()
+		}
+		pe.legs = append(pe.legs, leg)
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+	}
+	return pe, nil
+}
+
+// parsePathKey parses the key of a member leg, which is an identifier or a double quoted string.
+func parsePathKey(s string) (key string, rest string, err error) {
+	if strings.HasPrefix(s, `"`) {
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '"' {
+				if err = json.Unmarshal([]byte(s[:i+1]), &key); err != nil {
+					return "", "", ErrInvalidJSONPath.GenByArgs()
+				}
+				return key, s[i+1:], nil
+			}
+		}
+		return "", "", ErrInvalidJSONPath.GenByArgs()
+	}
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	if end < 0 {
+		end = len(s)
+	}
+	if end == 0 {
+		return "", "", ErrInvalidJSONPath.GenByArgs()
+	}
+	return s[:end], s[end:], nil
+}
+
+// Extract returns the value at the path of j, found is false if there is no such value.
+// Like MySQL, a non-array value is treated as a single element array by an array cell leg.
+func Extract(j JSON, pe PathExpression) (ret JSON, found bool) {
+	ret = deref(j)
+	for _, leg := range pe.legs {
+		if leg.isKey {
+			object, ok := ret.(jsonObject)
+			if !ok {
+				return nil, false
+			}
+			if ret, ok = object[leg.key]; !ok {
+				return nil, false
+			}
+			ret = deref(ret)
+			continue
+		}
+		array, ok := ret.(jsonArray)
+		if !ok {
+			if leg.index != 0 {
+				return nil, false
+			}
+			continue
+		}
+		if leg.index >= len(array) {
+			return nil, false
+		}
+		ret = deref(array[leg.index])
+	}
+	return ret, true
+}
+
+// Set returns a copy of j with the value at the path set to value, j itself isn't modified. Like MySQL JSON_SET,
+// an existing value is replaced, a missing member is added to its object, an array cell past the end is appended
+// to its array, and a non-array value is wrapped into an array to append a cell other than the first one.
+// j is returned unchanged if the parent of the path doesn't exist.
+func Set(j JSON, pe PathExpression, value JSON) JSON {
+	return set(deref(j), pe.legs, value)
+}
+
+func set(j JSON, legs []pathLeg, value JSON) JSON {
+	if len(legs) == 0 {
+		return value
+	}
+	leg := legs[0]
+	if leg.isKey {
+		object, ok := j.(jsonObject)
+		if !ok {
+			return j
+		}
+		elem, found := object[leg.key]
+		if !found && len(legs) > 1 {
+			return j
+		}
+		newObject := make(jsonObject, len(object)+1)
+		for key, elem := range object {
+			newObject[key] = elem
+		}
+		if found {
+			newObject[leg.key] = set(deref(elem), legs[1:], value)
+		} else {
+			newObject[leg.key] = value
+		}
+		return newObject
+	}
+	array, ok := j.(jsonArray)
+	if !ok {
+		if leg.index == 0 {
+			return set(j, legs[1:], value)
+		}
+		if len(legs) > 1 {
+			return j
+		}
+		return jsonArray{j, value}
+	}
+	if leg.index >= len(array) {
+		if len(legs) > 1 {
+			return j
+		}
+		newArray := make(jsonArray, len(array), len(array)+1)
+		copy(newArray, array)
+		return append(newArray, value)
+	}
+	newArray := make(jsonArray, len(array))
+	copy(newArray, array)
+	newArray[leg.index] = set(deref(array[leg.index]), legs[1:], value)
+	return newArray
+}
+
+// NewArray creates a JSON array of the elements.
+func NewArray(elems []JSON) JSON {
+	return jsonArray(elems)
+}
+
+// NewObject creates a JSON object of the members.
+func NewObject(members map[string]JSON) JSON {
+	return jsonObject(members)
+}
+
+// Elements returns the elements of j if it's an array, or j itself as the only element.
+func Elements(j JSON) []JSON {
+	j = deref(j)
+	if array, ok := j.(jsonArray); ok {
+		elems := make([]JSON, 0, len(array))
+		for _, elem := range array {
+			elems = append(elems, deref(elem))
+		}
+		return elems
+	}
+	return []JSON{j}
+}
+
+// Scalar returns the value of a scalar JSON, which is nil, a bool, a float64 or a string. ok is false
+// if j is an object or an array.
+func Scalar(j JSON) (v interface{}, ok bool) {
+	switch x := deref(j).(type) {
+	case jsonLiteral:
+		switch x {
+		case jsonLiteralTrue:
+			return true, true
+		case jsonLiteralFalse:
+			return false, true
+		}
+		return nil, true
+	case jsonDouble:
+		return float64(x), true
+	case jsonString:
+		return string(x), true
+	}
+	return nil, false
+}
+
+// deref returns the value of j, as the values decoded by Deserialize are pointers.
+func deref(j JSON) JSON {
+	switch x := j.(type) {
+	case *jsonLiteral:
+		return *x
+	case *jsonDouble:
+		return *x
+	case *jsonString:
+		return *x
+	case *jsonArray:
+		return *x
+	case *jsonObject:
+		return *x
+	}
+	return j
+}
+
+// Equal returns whether the two JSON values are equal.
+func Equal(j1, j2 JSON) bool {
+	return bytes.Equal(Serialize(j1), Serialize(j2))
+}
+
+// Contains returns whether candidate is contained in target, see
+// https://dev.mysql.com/doc/refman/5.7/en/json-search-functions.html#function_json-contains
+func Contains(target, candidate JSON) bool {
+	target, candidate = deref(target), deref(candidate)
+	switch t := target.(type) {
+	case jsonArray:
+		if c, ok := candidate.(jsonArray); ok {
+			for _, elem := range c {
+				if !Contains(t, elem) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, elem := range t {
+			if Contains(elem, candidate) {
+				return true
+			}
+		}
+		return false
+	case jsonObject:
+		c, ok := candidate.(jsonObject)
+		if !ok {
+			return false
+		}
+		for key, value := range c {
+			if elem, ok := t[key]; !ok || !Contains(elem, value) {
+				return false
+			}
+		}
+		return true
+	}
+	if _, ok := candidate.(jsonArray); ok {
+		return false
+	}
+	return Equal(target, candidate)
+}
@@ -42,6 +42,9 @@ const (
 	wordMax       = wordBase - 1
 	notFixedDec   = 31
 
+	// divModBufLen is the length of the temporary words buffer on stack for division.
+	divModBufLen = 32
+
 	MaxFraction = 30
 	DivFracIncr = 4
 
@@ -1343,9 +1346,137 @@ func (d *MyDecimal) Compare(to *MyDecimal) int {
 	return 1
 }
 
+// smallScaledValue returns the value of d as an integer scaled by 10^digitsFrac.
+// It only works for the decimal with no more than digitsPerWord digits, which is
+// common for the small precision columns, and lets the arithmetic skip the word loops.
+func (d *MyDecimal) smallScaledValue() (int64, bool) {
+	if int(d.digitsInt)+int(d.digitsFrac) > digitsPerWord {
+		return 0, false
+	}
+	var v int64
+	idx := 0
+	if d.digitsInt > 0 {
+		v = int64(d.wordBuf[0])
+		idx++
+	}
+	if d.digitsFrac > 0 {
+		divisor := powers10[digitsPerWord-int(d.digitsFrac)]
+		if d.wordBuf[idx]%divisor != 0 {
+			return 0, false
+		}
+		v = v*int64(powers10[d.digitsFrac]) + int64(d.wordBuf[idx]/divisor)
+	}
+	if d.negative {
+		v = -v
+	}
+	return v, true
+}
+
+// fromSmallScaledValue sets d to v / 10^frac, v should be less than 10^18 in
+// absolute value and frac should be no more than 2*digitsPerWord.
+func (d *MyDecimal) fromSmallScaledValue(v int64, frac int) {
+	resultFrac := d.resultFrac
+	*d = zeroMyDecimal
+	d.resultFrac = resultFrac
+	if v < 0 {
+		d.negative = true
+		v = -v
+	}
+	intPart, fracPart := v, int64(0)
+	if frac > digitsPerWord {
+		p := int64(powers10[frac-digitsPerWord])
+		intPart, fracPart = v/p/wordBase, v%(p*wordBase)
+	} else if frac > 0 {
+		p := int64(powers10[frac])
+		intPart, fracPart = v/p, v%p
+	}
+	idx := 0
+	if intPart >= wordBase {
+		d.wordBuf[0] = int32(intPart / wordBase)
+		d.wordBuf[1] = int32(intPart % wordBase)
+		idx = 2
+	} else if intPart > 0 {
+		d.wordBuf[0] = int32(intPart)
+		idx = 1
+	}
+	d.digitsInt = int8(idx * digitsPerWord)
+	d.digitsFrac = int8(frac)
+	if frac > digitsPerWord {
+		p := int64(powers10[frac-digitsPerWord])
+		d.wordBuf[idx] = int32(fracPart / p)
+		d.wordBuf[idx+1] = int32(fracPart%p) * powers10[2*digitsPerWord-frac]
+	} else if frac > 0 {
+		d.wordBuf[idx] = int32(fracPart) * powers10[digitsPerWord-frac]
+	}
+}
+
+// smallWordsValue returns the value of d scaled by wordBase if d has at most one word
+// for both the integer part and the fraction part.
+func (d *MyDecimal) smallWordsValue() (int64, bool) {
+	if d.digitsInt > digitsPerWord || d.digitsFrac > digitsPerWord {
+		return 0, false
+	}
+	var v int64
+	idx := 0
+	if d.digitsInt > 0 {
+		v = int64(d.wordBuf[0]) * wordBase
+		idx++
+	}
+	if d.digitsFrac > 0 {
+		v += int64(d.wordBuf[idx])
+	}
+	if d.negative {
+		v = -v
+	}
+	return v, true
+}
+
+// doSmallAddSub adds or subtracts two small decimals, returns false if any of them is not small.
+func doSmallAddSub(from1, from2, to *MyDecimal, sub bool) bool {
+	v1, ok1 := from1.smallWordsValue()
+	v2, ok2 := from2.smallWordsValue()
+	if !ok1 || !ok2 {
+		return false
+	}
+	if sub {
+		v2 = -v2
+	}
+	v := v1 + v2
+	if v == 0 && from1.negative != (from2.negative != sub) {
+		// Keep the same result as doSub for zero.
+		*to = zeroMyDecimal
+		return true
+	}
+	to.negative = v < 0
+	if v < 0 {
+		v = -v
+	}
+	to.wordBuf = zeroMyDecimal.wordBuf
+	// The integer part is less than 2*wordBase, so it has at most two words.
+	intPart, fracPart := v/wordBase, int32(v%wordBase)
+	idx := 0
+	if intPart >= wordBase {
+		to.wordBuf[0] = 1
+		to.wordBuf[1] = int32(intPart - wordBase)
+		idx = 2
+	} else if intPart > 0 {
+		to.wordBuf[0] = int32(intPart)
+		idx = 1
+	}
+	to.digitsInt = int8(idx * digitsPerWord)
+	to.digitsFrac = myMaxInt8(from1.digitsFrac, from2.digitsFrac)
+	if to.digitsFrac > 0 {
+		to.wordBuf[idx] = fracPart
+	}
+	return true
+}
+
 // DecimalAdd adds two decimals, sets the result to 'to'.
 func DecimalAdd(from1, from2, to *MyDecimal) error {
 	to.resultFrac = myMaxInt8(from1.resultFrac, from2.resultFrac)
+	if doSmallAddSub(from1, from2, to, false) {
+		return nil
+	}
 	if from1.negative == from2.negative {
 		return doAdd(from1, from2, to)
 	}
@@ -1356,6 +1487,9 @@ func DecimalAdd(from1, from2, to *MyDecimal) error {
 // DecimalSub subs one decimal from another, sets the result to 'to'.
 func DecimalSub(from1, from2, to *MyDecimal) error {
 	to.resultFrac = myMaxInt8(from1.resultFrac, from2.resultFrac)
+	if doSmallAddSub(from1, from2, to, true) {
+		return nil
+	}
 	if from1.negative == from2.negative {
 		_, err := doSub(from1, from2, to)
 		return err
@@ -1698,6 +1832,19 @@ func DecimalMul(from1, from2, to *MyDecimal) error {
 		tmp2        = wordsFracTo
 	)
 	to.resultFrac = myMinInt8(from1.resultFrac+from2.resultFrac, MaxFraction)
+	if v1, ok := from1.smallScaledValue(); ok {
+		if v2, ok := from2.smallScaledValue(); ok {
+			// Both factors are less than 10^9, so the product fits in int64.
+			v := v1 * v2
+			if v == 0 && from1.negative != from2.negative {
+				// Keep the same result as the -0.000 case below.
+				*to = zeroMyDecimal
+				return nil
+			}
+			to.fromSmallScaledValue(v, int(from1.digitsFrac+from2.digitsFrac))
+			return nil
+		}
+	}
 	wordsIntTo, wordsFracTo, err = fixWordCntError(wordsIntTo, wordsFracTo)
 	to.negative = from1.negative != from2.negative
 	to.digitsFrac = from1.digitsFrac + from2.digitsFrac
@@ -1933,7 +2080,14 @@ func doDivMod(from1, from2, to, mod *MyDecimal, fracIncr int) error {
 		len1 = 3
 	}
 
-	tmp1 := make([]int32, len1)
+	// Use the buffer on stack for the common cases to avoid allocation.
+	var tmpBuf [divModBufLen]int32
+	var tmp1 []int32
+	if len1 <= divModBufLen {
+		tmp1 = tmpBuf[:len1]
+	} else {
+		tmp1 = make([]int32, len1)
+	}
 	copy(tmp1, from1.wordBuf[idx1:idx1+i])
 
 	start1 := 0
@@ -14,6 +14,7 @@
 package types
 
 import (
+	"math/big"
 	"strings"
 
 	. "github.com/pingcap/check"
@@ -676,3 +677,46 @@ func (s *testMyDecimalSuite) TestMaxOrMin(c *C) {
 		c.Assert(dec.String(), Equals, tt.result)
 	}
 }
+
+func (s *testMyDecimalSuite) TestSmallDecimalArith(c *C) {
+	// These decimals are computed by the fast path for small precision operands,
+	// the results are checked against big.Rat.
+	nums := []string{"0", "-0.5", "0.5", "1", "-1", "123.45", "-98.765", "0.000000001", "999999999", "-999999999",
+		"0.999999999", "12345.6789", "-1234567.89", "99999.99999", "100"}
+	type op struct {
+		name string
+		dec  func(a, b, to *MyDecimal) error
+		rat  func(to, a, b *big.Rat) *big.Rat
+	}
+	ops := []op{
+		{"+", DecimalAdd, (*big.Rat).Add},
+		{"-", DecimalSub, (*big.Rat).Sub},
+		{"*", DecimalMul, (*big.Rat).Mul},
+	}
+	for _, x := range nums {
+		for _, y := range nums {
+			var a, b MyDecimal
+			c.Assert(a.FromString([]byte(x)), IsNil)
+			c.Assert(b.FromString([]byte(y)), IsNil)
+			ra, _ := new(big.Rat).SetString(x)
+			rb, _ := new(big.Rat).SetString(y)
+			for _, o := range ops {
+				var to MyDecimal
+				c.Assert(o.dec(&a, &b, &to), IsNil)
+				got, ok := new(big.Rat).SetString(string(to.ToString()))
+				c.Assert(ok, IsTrue)
+				expect := o.rat(new(big.Rat), ra, rb)
+				c.Assert(got.Cmp(expect), Equals, 0, Commentf("%s %s %s = %s", x, o.name, y, to.ToString()))
+
+				// The result can be encoded and compared.
+				prec, frac := to.PrecisionAndFrac()
+				bin, err := to.ToBin(prec, frac)
+				c.Assert(err, IsNil)
+				var dec MyDecimal
+				_, err = dec.FromBin(bin, prec, frac)
+				c.Assert(err, IsNil)
+				c.Assert(dec.Compare(&to), Equals, 0)
+			}
+		}
+	}
+}
@@ -17,7 +17,6 @@ import (
 	"bytes"
 	"fmt"
 	"math"
-	"regexp"
 	"strconv"
 	"strings"
 	gotime "time"
@@ -353,7 +352,7 @@ func (t Time) roundFrac(fsp int) (Time, error) {
 // so 2011:11:11 10:10:10.888888 round 0 -> 2011:11:11 10:10:11
 // and 2011:11:11 10:10:10.111111 round 0 -> 2011:11:11 10:10:10
 func RoundFrac(t gotime.Time, fsp int) (gotime.Time, error) {
-	_, err := checkFsp(fsp)
+	fsp, err := checkFsp(fsp)
 	if err != nil {
 		return t, errors.Trace(err)
 	}
@@ -656,7 +655,12 @@ func adjustYear(y int) int {
 }
 
 // AdjustYear is used for adjusting year and checking its validation.
-func AdjustYear(y int64) (int64, error) {
+// The zero year is kept unless adjustZero is true, which is used for
+// the strings shorter than 4 characters, e.g, '0' and '00' are 2000.
+func AdjustYear(y int64, adjustZero bool) (int64, error) {
+	if y == 0 && !adjustZero {
+		return 0, nil
+	}
 	y = int64(adjustYear(int(y)))
 	if y < int64(MinYear) || y > int64(MaxYear) {
 		return 0, errors.Trace(ErrInvalidYear)
@@ -1312,16 +1316,16 @@ func extractSingleTimeValue(unit string, format string) (int64, int64, int64, go
 		return 0, 0, 0, 0, errors.Errorf("invalid time format - %s", format)
 	}
 
-	v := gotime.Duration(iv)
+	// The whole days are split out of the clock units to avoid overflowing the duration.
 	switch strings.ToUpper(unit) {
 	case "MICROSECOND":
-		return 0, 0, 0, v * gotime.Microsecond, nil
+		return splitDays(iv, gotime.Microsecond)
 	case "SECOND":
-		return 0, 0, 0, v * gotime.Second, nil
+		return splitDays(iv, gotime.Second)
 	case "MINUTE":
-		return 0, 0, 0, v * gotime.Minute, nil
+		return splitDays(iv, gotime.Minute)
 	case "HOUR":
-		return 0, 0, 0, v * gotime.Hour, nil
+		return splitDays(iv, gotime.Hour)
 	case "DAY":
 		return 0, 0, iv, 0, nil
 	case "WEEK":
@@ -1337,6 +1341,12 @@ func extractSingleTimeValue(unit string, format string) (int64, int64, int64, go
 	return 0, 0, 0, 0, errors.Errorf("invalid singel timeunit - %s", unit)
 }
 
+// splitDays splits the whole days out of v units, and returns the days and the remain duration.
+func splitDays(v int64, unit gotime.Duration) (int64, int64, int64, gotime.Duration, error) {
+	perDay := int64(24 * gotime.Hour / unit)
+	return 0, 0, v / perDay, gotime.Duration(v%perDay) * unit, nil
+}
+
 // extractSecondMicrosecond extracts second and microsecond from a string and its format is `SS.FFFFFF`.
 func extractSecondMicrosecond(format string) (int64, int64, int64, gotime.Duration, error) {
 	fields := strings.Split(format, ".")
@@ -1568,10 +1578,22 @@ func extractYearMonth(format string) (int64, int64, int64, gotime.Duration, erro
 }
 
 // ExtractTimeValue extracts time value from time unit and format.
+// The leading minus sign of a composite format like '-1 10' negates the whole interval.
 func ExtractTimeValue(unit string, format string) (int64, int64, int64, gotime.Duration, error) {
 	switch strings.ToUpper(unit) {
 	case "MICROSECOND", "SECOND", "MINUTE", "HOUR", "DAY", "WEEK", "MONTH", "QUARTER", "YEAR":
 		return extractSingleTimeValue(unit, format)
+	}
+	format = strings.TrimSpace(format)
+	if strings.HasPrefix(format, "-") {
+		year, month, day, duration, err := extractCompositeTimeValue(unit, format[1:])
+		return -year, -month, -day, -duration, errors.Trace(err)
+	}
+	return extractCompositeTimeValue(unit, format)
+}
+
+func extractCompositeTimeValue(unit string, format string) (int64, int64, int64, gotime.Duration, error) {
+	switch strings.ToUpper(unit) {
 	case "SECOND_MICROSECOND":
 		return extractSecondMicrosecond(format)
 	case "MINUTE_MICROSECOND":
@@ -1599,6 +1621,57 @@ func ExtractTimeValue(unit string, format string) (int64, int64, int64, gotime.D
 	}
 }
 
+// AddInterval adds the interval of years, months, days and duration to t like MySQL does.
+// When the years or months are added, the day is clamped to the last day of the result month,
+// e.g. '2016-01-31' + INTERVAL 1 MONTH is '2016-02-29'.
+// ErrDatetimeFunctionOverflow is returned if the result is out of the range of datetime.
+func (t *Time) AddInterval(years, months, days int64, duration gotime.Duration) error {
+	year, month, day := int64(t.Time.Year()), int64(t.Time.Month()), int64(t.Time.Day())
+	hour, minute, second := int64(t.Time.Hour()), int64(t.Time.Minute()), int64(t.Time.Second())
+	microsecond := int64(t.Time.Microsecond())
+
+	if days != 0 || duration != 0 {
+		if days > maxDayNumber || days < -maxDayNumber {
+			return errors.Trace(ErrDatetimeFunctionOverflow.GenByArgs("datetime"))
+		}
+		const microsecondsInDay = secondsIn24Hour * 1000000
+		daynr := int64(calcDaynr(int(year), int(month), int(day))) + days
+		usec := daynr*microsecondsInDay + ((hour*60+minute)*60+second)*1000000 + microsecond + int64(duration/gotime.Microsecond)
+		if usec < 0 || usec/microsecondsInDay > maxDayNumber {
+			return errors.Trace(ErrDatetimeFunctionOverflow.GenByArgs("datetime"))
+		}
+		y, m, d := getDateFromDaynr(uint(usec / microsecondsInDay))
+		year, month, day = int64(y), int64(m), int64(d)
+		usec %= microsecondsInDay
+		hour, usec = usec/3600000000, usec%3600000000
+		minute, usec = usec/60000000, usec%60000000
+		second, microsecond = usec/1000000, usec%1000000
+	}
+
+	if years != 0 || months != 0 {
+		// The result year must be less than 10000.
+		const maxPeriod = 10000 * 12
+		if years > maxPeriod || years < -maxPeriod || months > maxPeriod || months < -maxPeriod {
+			return errors.Trace(ErrDatetimeFunctionOverflow.GenByArgs("datetime"))
+		}
+		period := year*12 + month - 1 + years*12 + months
+		if period < 0 || period >= maxPeriod {
+			return errors.Trace(ErrDatetimeFunctionOverflow.GenByArgs("datetime"))
+		}
+		year, month = period/12, period%12+1
+		lastDay := int64(daysInMonth[month-1])
+		if month == 2 && calcDaysInYear(int(year)) == 366 {
+			lastDay = 29
+		}
+		if day > lastDay {
+			day = lastDay
+		}
+	}
+
+	t.Time = FromDate(int(year), int(month), int(day), int(hour), int(minute), int(second), int(microsecond))
+	return nil
+}
+
 // IsClockUnit returns true when unit is interval unit with hour, minute or second.
 func IsClockUnit(unit string) bool {
 	switch strings.ToUpper(unit) {
@@ -1810,62 +1883,151 @@ func (t *Time) StrToDate(date, format string) bool {
 
 	t.Time = tm
 	t.Type = mysql.TypeDatetime
+	t.Fsp = 0
+	if _, ok := ctx["%f"]; ok {
+		t.Fsp = MaxFsp
+	}
 	if t.check() != nil {
 		return false
 	}
 	return true
 }
 
+// maxDayNumber is the day number of 9999-12-31.
+const maxDayNumber = 3652424
+
 // mysqlTimeFix fixes the mysqlTime use the values in the context.
 func mysqlTimeFix(t *mysqlTime, ctx map[string]int) error {
 	// Key of the ctx is the format char, such as `%j` `%p` and so on.
-	if yearOfDay, ok := ctx["%j"]; ok {
-		// TODO: Implement the function that converts day of year to yy:mm:dd.
-		_ = yearOfDay
+	if err := fixHour12(t, ctx); err != nil {
+		return errors.Trace(err)
 	}
-	if valueAMorPm, ok := ctx["%p"]; ok {
-		if t.hour == 0 {
+
+	if yearOfDay, ok := ctx["%j"]; ok && yearOfDay > 0 {
+		days := calcDaynr(int(t.year), 1, 1) + yearOfDay - 1
+		if days <= 0 || days > maxDayNumber {
 			return ErrInvalidTimeFormat
 		}
-		if t.hour == 12 {
-			// 12 is a special hour.
-			switch valueAMorPm {
-			case constForAM:
-				t.hour = 0
-			case constForPM:
-				t.hour = 12
-			}
-			return nil
+		setDateFromDaynr(t, days)
+	}
+
+	weekday, ok := ctx["%w"]
+	if !ok {
+		return nil
+	}
+	for _, token := range []string{"%U", "%u", "%V", "%v"} {
+		week, ok := ctx[token]
+		if !ok {
+			continue
 		}
-		if valueAMorPm == constForPM {
-			t.hour += 12
+		days, err := daynrFromWeek(t, ctx, token, week, weekday)
+		if err != nil {
+			return errors.Trace(err)
 		}
+		setDateFromDaynr(t, days)
+		break
 	}
 	return nil
 }
 
+// fixHour12 converts the hour parsed by %h, %I or %l to the 24-hour clock,
+// using the day part parsed by %p.
+func fixHour12(t *mysqlTime, ctx map[string]int) error {
+	if _, ok := ctx["%h"]; !ok {
+		return nil
+	}
+	if t.hour < 1 || t.hour > 12 {
+		return ErrInvalidTimeFormat
+	}
+	t.hour = t.hour%12 + uint8(ctx["%p"])
+	return nil
+}
+
+// daynrFromWeek calculates the day number from the week parsed by token and the weekday,
+// weekday is 1 for Monday ... 7 for Sunday.
+// %U and %u are used with %Y, %V and %v require %X and %x respectively.
+func daynrFromWeek(t *mysqlTime, ctx map[string]int, token string, week, weekday int) (int, error) {
+	sundayFirst := token == "%U" || token == "%V"
+	strict := token == "%V" || token == "%v"
+	year := int(t.year)
+	weekYearToken := "%x"
+	if sundayFirst {
+		weekYearToken = "%X"
+	}
+	weekYear, ok := ctx[weekYearToken]
+	_, hasSundayWeekYear := ctx["%X"]
+	_, hasMondayWeekYear := ctx["%x"]
+	if strict {
+		if !ok {
+			return 0, ErrInvalidTimeFormat
+		}
+		year = weekYear
+	} else if hasSundayWeekYear || hasMondayWeekYear {
+		return 0, ErrInvalidTimeFormat
+	}
+
+	// Days since year 0 till the 1st Jan of this year.
+	days := calcDaynr(year, 1, 1)
+	// Which day of week is the 1st Jan of this year.
+	firstWeekday := calcWeekday(days, sundayFirst)
+	// Sum the days till the 1st day of the 1st week of this year,
+	// the days between the 1st week and our week, and the position of our day in the week.
+	if sundayFirst {
+		if firstWeekday != 0 {
+			days += 7
+		}
+		days += -firstWeekday + (week-1)*7 + weekday%7
+	} else {
+		if firstWeekday > 3 {
+			days += 7
+		}
+		days += -firstWeekday + (week-1)*7 + weekday - 1
+	}
+	if days <= 0 || days > maxDayNumber {
+		return 0, ErrInvalidTimeFormat
+	}
+	return days, nil
+}
+
+func setDateFromDaynr(t *mysqlTime, daynr int) {
+	year, month, day := getDateFromDaynr(uint(daynr))
+	t.year = uint16(year)
+	t.month = uint8(month)
+	t.day = uint8(day)
+}
+
 // strToDate converts date string according to format, returns true on success,
 // the value will be stored in argument t or ctx.
 func strToDate(t *mysqlTime, date string, format string, ctx map[string]int) bool {
-	date = skipWhiteSpace(date)
-	format = skipWhiteSpace(format)
-
-	token, formatRemain, succ := getFormatToken(format)
-	if !succ {
-		return false
-	}
+	_, succ := matchDateWithFormat(t, date, format, ctx)
+	return succ
+}
+
+// matchDateWithFormat matches the date with all the tokens of format, and returns the remain of date.
+// Spaces in date are skipped before each token, parsing stops when the date is exhausted,
+// and extra characters at the end of date are ignored.
+func matchDateWithFormat(t *mysqlTime, date string, format string, ctx map[string]int) (remain string, succ bool) {
+	for {
+		date = skipWhiteSpace(date)
+		format = skipWhiteSpace(format)
+		if date == "" {
+			return date, true
+		}
 
-	if token == "" {
-		// Extra characters at the end of date are ignored.
-		return true
-	}
+		var token string
+		token, format, succ = getFormatToken(format)
+		if !succ {
+			return date, false
+		}
+		if token == "" {
+			return date, true
+		}
 
-	dateRemain, succ := matchDateWithToken(t, date, token, ctx)
-	if !succ {
-		return false
+		date, succ = matchDateWithToken(t, date, token, ctx)
+		if !succ {
+			return date, false
+		}
 	}
-
-	return strToDate(t, dateRemain, formatRemain, ctx)
 }
 
 // getFormatToken takes one format control token from the string.
@@ -1900,70 +2062,53 @@ func skipWhiteSpace(input string) string {
 	return ""
 }
 
-var weekdayAbbrev = map[string]gotime.Weekday{
-	"Sun": gotime.Sunday,
-	"Mon": gotime.Monday,
-	"Tue": gotime.Tuesday,
-	"Wed": gotime.Wednesday,
-	"Thu": gotime.Tuesday,
-	"Fri": gotime.Friday,
-	"Sat": gotime.Saturday,
-}
-
-var monthAbbrev = map[string]gotime.Month{
-	"Jan": gotime.January,
-	"Feb": gotime.February,
-	"Mar": gotime.March,
-	"Apr": gotime.April,
-	"May": gotime.May,
-	"Jun": gotime.June,
-	"Jul": gotime.July,
-	"Aug": gotime.August,
-	"Sep": gotime.September,
-	"Oct": gotime.October,
-	"Nov": gotime.November,
-	"Dec": gotime.December,
-}
-
 type dateFormatParser func(t *mysqlTime, date string, ctx map[string]int) (remain string, succ bool)
 
 var dateFormatParserTable = map[string]dateFormatParser{
-	"%b": abbreviatedMonth,           // Abbreviated month name (Jan..Dec)
-	"%c": monthNumeric,               // Month, numeric (0..12)
-	"%d": dayOfMonthNumericTwoDigits, // Day of the month, numeric (00..31)
-	"%e": dayOfMonthNumeric,          // Day of the month, numeric (0..31)
-	"%f": microSeconds,               // Microseconds (000000..999999)
-	"%h": hour24TwoDigits,            // Hour (01..12)
-	"%H": hour24TwoDigits,            // Hour (01..12)
-	"%I": hour24TwoDigits,            // Hour (01..12)
-	"%i": minutesNumeric,             // Minutes, numeric (00..59)
-	"%j": dayOfYearThreeDigits,       // Day of year (001..366)
-	"%k": hour24Numeric,              // Hour (0..23)
-	"%l": hour12Numeric,              // Hour (1..12)
-	"%M": fullNameMonth,              // Month name (January..December)
-	"%m": monthNumericTwoDigits,      // Month, numeric (00..12)
-	"%p": isAMOrPM,                   // AM or PM
-	"%r": time12Hour,                 // Time, 12-hour (hh:mm:ss followed by AM or PM)
-	"%s": secondsNumeric,             // Seconds (00..59)
-	"%S": secondsNumeric,             // Seconds (00..59)
-	"%T": time24Hour,                 // Time, 24-hour (hh:mm:ss)
-	"%Y": yearNumericFourDigits,      // Year, numeric, four digits
-	// TODO: Add the following...
-	// "%a": abbreviatedWeekday,         // Abbreviated weekday name (Sun..Sat)
-	// "%D": dayOfMonthWithSuffix,       // Day of the month with English suffix (0th, 1st, 2nd, 3rd)
-	// "%U": weekMode0,                  // Week (00..53), where Sunday is the first day of the week; WEEK() mode 0
-	// "%u": weekMode1,                  // Week (00..53), where Monday is the first day of the week; WEEK() mode 1
-	// "%V": weekMode2,                  // Week (01..53), where Sunday is the first day of the week; WEEK() mode 2; used with %X
-	// "%v": weekMode3,                  // Week (01..53), where Monday is the first day of the week; WEEK() mode 3; used with %x
-	// "%W": weekdayName,                // Weekday name (Sunday..Saturday)
-	// "%w": dayOfWeek,                  // Day of the week (0=Sunday..6=Saturday)
-	// "%X": yearOfWeek,                 // Year for the week where Sunday is the first day of the week, numeric, four digits; used with %V
-	// "%x": yearOfWeek,                 // Year for the week, where Monday is the first day of the week, numeric, four digits; used with %v
-	// Deprecated since MySQL 5.7.5
-	// "%y": yearTwoDigits,         // Year, numeric (two digits)
+	"%a": abbreviatedWeekday,    // Abbreviated weekday name (Sun..Sat)
+	"%b": abbreviatedMonth,      // Abbreviated month name (Jan..Dec)
+	"%c": monthNumeric,          // Month, numeric (0..12)
+	"%D": dayOfMonthWithSuffix,  // Day of the month with English suffix (0th, 1st, 2nd, 3rd)
+	"%d": dayOfMonthNumeric,     // Day of the month, numeric (00..31)
+	"%e": dayOfMonthNumeric,     // Day of the month, numeric (0..31)
+	"%f": microSeconds,          // Microseconds (000000..999999)
+	"%h": hour12Numeric,         // Hour (01..12)
+	"%H": hour24Numeric,         // Hour (00..23)
+	"%I": hour12Numeric,         // Hour (01..12)
+	"%i": minutesNumeric,        // Minutes, numeric (00..59)
+	"%j": dayOfYearNumeric,      // Day of year (001..366)
+	"%k": hour24Numeric,         // Hour (0..23)
+	"%l": hour12Numeric,         // Hour (1..12)
+	"%M": fullNameMonth,         // Month name (January..December)
+	"%m": monthNumeric,          // Month, numeric (00..12)
+	"%p": isAMOrPM,              // AM or PM
+	"%s": secondsNumeric,        // Seconds (00..59)
+	"%S": secondsNumeric,        // Seconds (00..59)
+	"%U": weekNumeric("%U"),     // Week (00..53), where Sunday is the first day of the week; WEEK() mode 0
+	"%u": weekNumeric("%u"),     // Week (00..53), where Monday is the first day of the week; WEEK() mode 1
+	"%V": weekNumeric("%V"),     // Week (01..53), where Sunday is the first day of the week; WEEK() mode 2; used with %X
+	"%v": weekNumeric("%v"),     // Week (01..53), where Monday is the first day of the week; WEEK() mode 3; used with %x
+	"%W": weekdayName,           // Weekday name (Sunday..Saturday)
+	"%w": dayOfWeek,             // Day of the week (0=Sunday..6=Saturday)
+	"%X": yearOfWeek("%X"),      // Year for the week where Sunday is the first day of the week, numeric, four digits; used with %V
+	"%x": yearOfWeek("%x"),      // Year for the week, where Monday is the first day of the week, numeric, four digits; used with %v
+	"%Y": yearNumericFourDigits, // Year, numeric, four digits
+	"%y": yearNumericTwoDigits,  // Year, numeric (two digits)
+	"%#": skipAllNums,           // Skip all numbers
+	"%.": skipAllPunct,          // Skip all punctation characters
+	"%@": skipAllAlpha,          // Skip all alpha characters
+	"%%": percent,               // A literal % character
 }
 
 func matchDateWithToken(t *mysqlTime, date string, token string, ctx map[string]int) (remain string, succ bool) {
+	// The composite tokens are parsed by the sub formats, they are not in dateFormatParserTable
+	// to avoid the initialization loop.
+	switch token {
+	case "%r":
+		return time12Hour(t, date, ctx) // Time, 12-hour (hh:mm:ss followed by AM or PM)
+	case "%T":
+		return time24Hour(t, date, ctx) // Time, 24-hour (hh:mm:ss)
+	}
 	if parse, ok := dateFormatParserTable[token]; ok {
 		return parse(t, date, ctx)
 	}
@@ -1974,304 +2119,287 @@ func matchDateWithToken(t *mysqlTime, date string, token string, ctx map[string]
 	return date, false
 }
 
-func parseDigits(input string, count int) (int, bool) {
-	if len(input) < count {
-		return 0, false
+// parseDigits parses at most count leading digits of input,
+// it fails if input doesn't begin with a digit.
+func parseDigits(input string, count int) (int, string, bool) {
+	i := 0
+	for i < count && i < len(input) && input[i] >= '0' && input[i] <= '9' {
+		i++
 	}
-
-	v, err := strconv.ParseUint(input[:count], 10, 64)
+	if i == 0 {
+		return 0, input, false
+	}
+	v, err := strconv.Atoi(input[:i])
 	if err != nil {
-		return int(v), false
+		return 0, input, false
 	}
-	return int(v), true
+	return v, input[i:], true
 }
 
-func hour24TwoDigits(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	v, succ := parseDigits(input, 2)
-	if !succ || v >= 24 {
+func hour24Numeric(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	v, remain, succ := parseDigits(input, 2)
+	if !succ || v > 23 {
 		return input, false
 	}
 	t.hour = uint8(v)
-	return input[2:], true
+	return remain, true
+}
+
+func hour12Numeric(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	// The range 1..12 is checked in fixHour12.
+	v, remain, succ := parseDigits(input, 2)
+	if !succ {
+		return input, false
+	}
+	t.hour = uint8(v)
+	ctx["%h"] = 1
+	return remain, true
 }
 
 func secondsNumeric(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	v, succ := parseDigits(input, 2)
+	v, remain, succ := parseDigits(input, 2)
 	if !succ || v >= 60 {
 		return input, false
 	}
 	t.second = uint8(v)
-	return input[2:], true
+	return remain, true
 }
 
 func minutesNumeric(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	v, succ := parseDigits(input, 2)
+	v, remain, succ := parseDigits(input, 2)
 	if !succ || v >= 60 {
 		return input, false
 	}
 	t.minute = uint8(v)
-	return input[2:], true
+	return remain, true
 }
 
-const time12HourLen = len("hh:mm:ssAM")
-
 func time12Hour(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
 	// hh:mm:ss AM
-	if len(input) < time12HourLen {
-		return input, false
-	}
-	hour, succ := parseDigits(input, 2)
-	if !succ || hour > 12 || hour == 0 || input[2] != ':' {
+	timeCtx := make(map[string]int)
+	remain, succ := matchDateWithFormat(t, input, "%I:%i:%S %p", timeCtx)
+	if !succ || fixHour12(t, timeCtx) != nil {
 		return input, false
 	}
+	return remain, true
+}
 
-	minute, succ := parseDigits(input[3:], 2)
-	if !succ || minute > 59 || input[5] != ':' {
+func time24Hour(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	// hh:mm:ss
+	remain, succ := matchDateWithFormat(t, input, "%H:%i:%S", ctx)
+	if !succ {
 		return input, false
 	}
+	return remain, true
+}
 
-	second, succ := parseDigits(input[6:], 2)
-	if !succ || second > 59 {
+func isAMOrPM(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	// %p is only valid after %h, %I or %l.
+	if _, ok := ctx["%h"]; !ok || len(input) < 2 {
 		return input, false
 	}
-
-	remain := skipWhiteSpace(input[8:])
-	switch {
-	case strings.HasPrefix(remain, "AM"):
-		t.hour = uint8(hour)
-	case strings.HasPrefix(remain, "PM"):
-		t.hour = uint8(hour + 12)
+	switch strings.ToUpper(input[:2]) {
+	case "AM":
+		ctx["%p"] = 0
+	case "PM":
+		ctx["%p"] = 12
 	default:
 		return input, false
 	}
-
-	t.minute = uint8(minute)
-	t.second = uint8(second)
-	return remain, true
+	return input[2:], true
 }
 
-const time24HourLen = len("hh:mm:ss")
-
-func time24Hour(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	// hh:mm:ss
-	if len(input) < time24HourLen {
+func dayOfMonthNumeric(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	v, remain, succ := parseDigits(input, 2)
+	if !succ || v > 31 {
 		return input, false
 	}
+	t.day = uint8(v)
+	return remain, true
+}
 
-	hour, succ := parseDigits(input, 2)
-	if !succ || hour > 23 || input[2] != ':' {
+// dayOfMonthWithSuffix parses the day of the month followed by a two characters suffix, i.e. 1st, 2nd, 3rd.
+func dayOfMonthWithSuffix(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	remain, succ := dayOfMonthNumeric(t, input, ctx)
+	if !succ {
 		return input, false
 	}
-
-	minute, succ := parseDigits(input[3:], 2)
-	if !succ || minute > 59 || input[5] != ':' {
-		return input, false
+	if len(remain) > 2 {
+		return remain[2:], true
 	}
+	return "", true
+}
 
-	second, succ := parseDigits(input[6:], 2)
-	if !succ || second > 59 {
+func microSeconds(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	v, remain, succ := parseDigits(input, 6)
+	if !succ {
 		return input, false
 	}
-
-	t.hour = uint8(hour)
-	t.minute = uint8(minute)
-	t.second = uint8(second)
-	return input[8:], true
+	for i := len(input) - len(remain); i < 6; i++ {
+		v *= 10
+	}
+	t.microsecond = uint32(v)
+	ctx["%f"] = 1
+	return remain, true
 }
 
-const (
-	constForAM = 1 + iota
-	constForPM
-)
-
-func isAMOrPM(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	if strings.HasPrefix(input, "AM") {
-		ctx["%p"] = constForAM
-	} else if strings.HasPrefix(input, "PM") {
-		ctx["%p"] = constForPM
-	} else {
+func yearNumericFourDigits(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	v, remain, succ := parseDigits(input, 4)
+	if !succ {
 		return input, false
 	}
-	return input[2:], true
+	if len(input)-len(remain) <= 2 {
+		v = adjustYear(v)
+	}
+	t.year = uint16(v)
+	return remain, true
 }
 
-func dayOfMonthNumericTwoDigits(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	v, succ := parseDigits(input, 2)
-	if !succ || v >= 32 {
+func yearNumericTwoDigits(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	v, remain, succ := parseDigits(input, 2)
+	if !succ {
 		return input, false
 	}
-	t.day = uint8(v)
-	return input[2:], true
+	t.year = uint16(adjustYear(v))
+	return remain, true
 }
 
-var twoDigitRegex = regexp.MustCompile("^[1-9][0-9]?")
-
-// parseTwoNumeric is used for pattens 0..31 0..24 0..60 and so on.
-// It returns the parsed int, and remain data after parse.
-func parseTwoNumeric(input string) (int, string) {
-	if len(input) > 1 && input[0] == '0' {
-		return 0, input[1:]
-	}
-	matched := twoDigitRegex.FindAllString(input, -1)
-	if len(matched) == 0 {
-		return 0, input
+func yearOfWeek(token string) dateFormatParser {
+	return func(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+		v, remain, succ := parseDigits(input, 4)
+		if !succ {
+			return input, false
+		}
+		// Only the last one of %X and %x is used.
+		delete(ctx, "%X")
+		delete(ctx, "%x")
+		ctx[token] = v
+		return remain, true
 	}
+}
 
-	str := matched[0]
-	v, err := strconv.ParseInt(str, 10, 64)
-	if err != nil {
-		return 0, input
+func dayOfYearNumeric(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	v, remain, succ := parseDigits(input, 3)
+	if !succ || v > 366 {
+		return input, false
 	}
-	return int(v), input[len(str):]
+	ctx["%j"] = v
+	return remain, true
 }
 
-func dayOfMonthNumeric(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	v, remain := parseTwoNumeric(input) // 0..31
-	if len(remain) == len(input) || v > 31 {
+func monthNumeric(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	v, remain, succ := parseDigits(input, 2)
+	if !succ || v > 12 {
 		return input, false
 	}
 	t.month = uint8(v)
 	return remain, true
 }
 
-func hour24Numeric(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	// 0..23
-	v, remain := parseTwoNumeric(input)
-	if len(remain) == len(input) || v > 23 {
-		return input, false
+var abbrevMonthName = []string{
+	"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+	"Jul", "Aug", "Sep", "Oct", "Nov", "Dec",
+}
+
+// matchName matches the leading word of input with names case-insensitively,
+// it returns the index of the matched name.
+func matchName(input string, names []string) (int, string, bool) {
+	word := strings.TrimLeftFunc(input, unicode.IsLetter)
+	word = input[:len(input)-len(word)]
+	for i, name := range names {
+		if strings.EqualFold(word, name) {
+			return i, input[len(word):], true
+		}
 	}
-	t.hour = uint8(v)
-	return remain, true
+	return 0, input, false
 }
 
-func hour12Numeric(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	// 1..12
-	v, remain := parseTwoNumeric(input)
-	if len(remain) == len(input) || v > 12 || v == 0 {
+func abbreviatedMonth(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	i, remain, succ := matchName(input, abbrevMonthName)
+	if !succ {
 		return input, false
 	}
-	t.hour = uint8(v)
+	t.month = uint8(i + 1)
 	return remain, true
 }
 
-func microSeconds(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	if len(input) < 6 {
-		return input, false
-	}
-	v, err := strconv.ParseUint(input[:6], 10, 64)
-	if err != nil {
+func fullNameMonth(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	i, remain, succ := matchName(input, MonthNames)
+	if !succ {
 		return input, false
 	}
-	t.microsecond = uint32(v)
-	return input[6:], true
+	t.month = uint8(i + 1)
+	return remain, true
 }
 
-func yearNumericFourDigits(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	v, succ := parseDigits(input, 4)
+func abbreviatedWeekday(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	// abbrevWeekdayName begins with Sunday.
+	i, remain, succ := matchName(input, abbrevWeekdayName)
 	if !succ {
 		return input, false
 	}
-	t.year = uint16(v)
-	return input[4:], true
+	if i == 0 {
+		i = 7
+	}
+	ctx["%w"] = i
+	return remain, true
 }
 
-func dayOfYearThreeDigits(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	v, succ := parseDigits(input, 3)
-	if !succ || v == 0 || v > 366 {
+func weekdayName(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	// WeekdayNames begins with Monday.
+	i, remain, succ := matchName(input, WeekdayNames)
+	if !succ {
 		return input, false
 	}
-	ctx["%j"] = v
-	return input[3:], true
+	ctx["%w"] = i + 1
+	return remain, true
 }
 
-func monthNumericTwoDigits(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	v, succ := parseDigits(input, 2)
-	if !succ || v > 12 {
+func dayOfWeek(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	v, remain, succ := parseDigits(input, 1)
+	if !succ || v >= 7 {
 		return input, false
 	}
-
-	t.month = uint8(v)
-	return input[2:], true
+	if v == 0 {
+		v = 7
+	}
+	ctx["%w"] = v
+	return remain, true
 }
 
-func abbreviatedWeekday(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	if len(input) >= 3 {
-		dayName := input[:3]
-		if _, ok := weekdayAbbrev[dayName]; ok {
-			// TODO: We need refact mysql time to support this.
+func weekNumeric(token string) dateFormatParser {
+	return func(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+		v, remain, succ := parseDigits(input, 2)
+		strict := token == "%V" || token == "%v"
+		if !succ || v > 53 || (strict && v == 0) {
 			return input, false
 		}
-	}
-	return input, false
-}
-
-func abbreviatedMonth(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	if len(input) >= 3 {
-		monthName := input[:3]
-		if month, ok := monthAbbrev[monthName]; ok {
-			t.month = uint8(month)
-			return input[len(monthName):], true
+		// Only the last one of the week specifiers is used.
+		for _, week := range []string{"%U", "%u", "%V", "%v"} {
+			delete(ctx, week)
 		}
+		ctx[token] = v
+		return remain, true
 	}
-	return input, false
 }
 
-func fullNameMonth(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	for i, month := range MonthNames {
-		if strings.HasPrefix(input, month) {
-			t.month = uint8(i + 1)
-			return input[len(month):], true
-		}
-	}
-	return input, false
+func skipAllNums(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	return strings.TrimLeftFunc(input, unicode.IsDigit), true
 }
 
-func monthNumeric(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	v, rem := parseTwoNumeric(input)
-	if len(rem) == len(input) || v > 12 {
-		return rem, false
-	}
-	t.month = uint8(v)
-	return rem, false
+func skipAllPunct(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	return strings.TrimLeftFunc(input, unicode.IsPunct), true
 }
 
-//  dayOfMonthWithSuffix returns different suffix according t being which day. i.e. 0 return th. 1 return st.
-func dayOfMonthWithSuffix(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
-	month, remain := parseOrdinalNumbers(input)
-	if month >= 0 {
-		t.month = uint8(month)
-		return remain, true
-	}
-	return input, false
+func skipAllAlpha(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	return strings.TrimLeftFunc(input, unicode.IsLetter), true
 }
 
-func parseOrdinalNumbers(input string) (value int, remain string) {
-	for i, c := range input {
-		if !unicode.IsDigit(c) {
-			v, err := strconv.ParseUint(input[:i], 10, 64)
-			if err != nil {
-				return -1, input
-			}
-			value = int(v)
-			break
-		}
-	}
-	switch {
-	case strings.HasPrefix(remain, "st"):
-		if value == 1 {
-			remain = remain[2:]
-			return
-		}
-	case strings.HasPrefix(remain, "nd"):
-		if value == 2 {
-			remain = remain[2:]
-			return
-		}
-	case strings.HasPrefix(remain, "th"):
-		remain = remain[2:]
-		return
+func percent(t *mysqlTime, input string, ctx map[string]int) (string, bool) {
+	if !strings.HasPrefix(input, "%") {
+		return input, false
 	}
-	return -1, input
+	return input[1:], true
 }
 
 // DateFSP gets fsp from date string.
@@ -18,6 +18,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
@@ -284,19 +285,28 @@ func (s *testTimeSuite) TestYear(c *C) {
 	}
 
 	valids := []struct {
-		Year   int64
-		Expect bool
+		Year       int64
+		AdjustZero bool
+		Expect     bool
+		Result     int64
 	}{
-		{2000, true},
-		{20000, false},
-		{0, true},
-		{-1, false},
+		{2000, false, true, 2000},
+		{20000, false, false, 0},
+		{0, false, true, 0},
+		{0, true, true, 2000},
+		{69, false, true, 2069},
+		{70, true, true, 1970},
+		{100, false, false, 0},
+		{1900, false, false, 0},
+		{2155, false, true, 2155},
+		{-1, false, false, 0},
 	}
 
 	for _, test := range valids {
-		_, err := AdjustYear(test.Year)
+		y, err := AdjustYear(test.Year, test.AdjustZero)
 		if test.Expect {
 			c.Assert(err, IsNil)
+			c.Assert(y, Equals, test.Result)
 		} else {
 			c.Assert(err, NotNil)
 		}
@@ -613,6 +623,12 @@ func (s *testTimeSuite) TestRoundFrac(c *C) {
 		c.Assert(err, IsNil)
 		c.Assert(nv.String(), Equals, t.Except)
 	}
+
+	// Unspecified fsp rounds to the default fsp.
+	gt := time.Date(2017, 1, 1, 10, 10, 14, 500000000, time.UTC)
+	rt, err := RoundFrac(gt, UnspecifiedFsp)
+	c.Assert(err, IsNil)
+	c.Assert(rt, Equals, time.Date(2017, 1, 1, 10, 10, 15, 0, time.UTC))
 }
 
 func (s *testTimeSuite) TestConvert(c *C) {
@@ -792,3 +808,57 @@ func (s *testTimeSuite) TestDateFSP(c *C) {
 		c.Assert(DateFSP(test.date), Equals, test.expect)
 	}
 }
+
+func (s *testTimeSuite) TestAddInterval(c *C) {
+	tests := []struct {
+		t      TimeInternal
+		unit   string
+		value  string
+		expect TimeInternal
+	}{
+		{FromDate(2016, 1, 31, 0, 0, 0, 0), "MONTH", "1", FromDate(2016, 2, 29, 0, 0, 0, 0)},
+		{FromDate(2017, 1, 31, 0, 0, 0, 0), "MONTH", "1", FromDate(2017, 2, 28, 0, 0, 0, 0)},
+		{FromDate(2016, 3, 31, 0, 0, 0, 0), "MONTH", "-13", FromDate(2015, 2, 28, 0, 0, 0, 0)},
+		{FromDate(2016, 2, 29, 0, 0, 0, 0), "YEAR", "1", FromDate(2017, 2, 28, 0, 0, 0, 0)},
+		{FromDate(2016, 5, 31, 0, 0, 0, 0), "QUARTER", "1", FromDate(2016, 8, 31, 0, 0, 0, 0)},
+		{FromDate(2016, 8, 31, 0, 0, 0, 0), "YEAR_MONTH", "1-1", FromDate(2017, 9, 30, 0, 0, 0, 0)},
+		{FromDate(2016, 8, 31, 0, 0, 0, 0), "YEAR_MONTH", "-1-1", FromDate(2015, 7, 31, 0, 0, 0, 0)},
+		{FromDate(2016, 12, 31, 23, 59, 59, 999999), "MICROSECOND", "1", FromDate(2017, 1, 1, 0, 0, 0, 0)},
+		{FromDate(2016, 1, 1, 0, 0, 0, 0), "SECOND", "-1", FromDate(2015, 12, 31, 23, 59, 59, 0)},
+		{FromDate(2016, 1, 1, 0, 0, 0, 0), "HOUR", "8784", FromDate(2017, 1, 1, 0, 0, 0, 0)},
+		{FromDate(2000, 1, 1, 0, 0, 0, 0), "HOUR", "70000000", FromDate(9985, 7, 24, 16, 0, 0, 0)},
+		{FromDate(2016, 1, 1, 0, 0, 0, 0), "DAY_HOUR", "-1 10", FromDate(2015, 12, 30, 14, 0, 0, 0)},
+		{FromDate(2016, 1, 1, 0, 0, 0, 0), "MINUTE_SECOND", "-1:30", FromDate(2015, 12, 31, 23, 58, 30, 0)},
+		{FromDate(9999, 12, 31, 0, 0, 0, 0), "SECOND", "86399", FromDate(9999, 12, 31, 23, 59, 59, 0)},
+		{FromDate(9999, 1, 31, 0, 0, 0, 0), "MONTH", "11", FromDate(9999, 12, 31, 0, 0, 0, 0)},
+	}
+	for _, tt := range tests {
+		year, month, day, duration, err := ExtractTimeValue(tt.unit, tt.value)
+		c.Assert(err, IsNil)
+		t := Time{Time: tt.t, Type: mysql.TypeDatetime, Fsp: 6}
+		err = t.AddInterval(year, month, day, duration)
+		c.Assert(err, IsNil, Commentf("%s %s", tt.value, tt.unit))
+		c.Assert(t.Time, Equals, tt.expect, Commentf("%s %s", tt.value, tt.unit))
+	}
+
+	overflowTests := []struct {
+		t     TimeInternal
+		unit  string
+		value string
+	}{
+		{FromDate(9999, 12, 31, 23, 59, 59, 0), "SECOND", "1"},
+		{FromDate(9999, 12, 1, 0, 0, 0, 0), "MONTH", "1"},
+		{FromDate(9999, 1, 1, 0, 0, 0, 0), "YEAR", "1"},
+		{FromDate(2016, 1, 1, 0, 0, 0, 0), "YEAR", "-2017"},
+		{FromDate(2016, 1, 1, 0, 0, 0, 0), "DAY", "-1000000"},
+		{FromDate(2016, 1, 1, 0, 0, 0, 0), "DAY", "9223372036854775807"},
+		{FromDate(2016, 1, 1, 0, 0, 0, 0), "MONTH", "9223372036854775807"},
+	}
+	for _, tt := range overflowTests {
+		year, month, day, duration, err := ExtractTimeValue(tt.unit, tt.value)
+		c.Assert(err, IsNil)
+		t := Time{Time: tt.t, Type: mysql.TypeDatetime, Fsp: 6}
+		err = t.AddInterval(year, month, day, duration)
+		c.Assert(terror.ErrorEqual(err, ErrDatetimeFunctionOverflow), IsTrue, Commentf("%s %s", tt.value, tt.unit))
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package userlock implements the user-level locks acquired by GET_LOCK() and released by RELEASE_LOCK().
+// The locks are held by the sessions until they're released or the sessions are closed. The Manager is
+// kept in the domain, so the locks are shared by the sessions of the same tidb-server.
+package userlock
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
+	goctx "golang.org/x/net/context"
+)
+
+// Error codes.
+const (
+	codeWrongName terror.ErrCode = terror.ErrCode(mysql.ErrUserLockWrongName)
+	codeDeadlock  terror.ErrCode = terror.ErrCode(mysql.ErrUserLockDeadlock)
+)
+
+var (
+	// ErrWrongName is returned when the lock name is NULL, empty or longer than 64 characters.
+	ErrWrongName = terror.ClassUserLock.New(codeWrongName, mysql.MySQLErrName[mysql.ErrUserLockWrongName])
+	// ErrDeadlock is returned when acquiring the lock would cause a deadlock.
+	ErrDeadlock = terror.ClassUserLock.New(codeDeadlock, mysql.MySQLErrName[mysql.ErrUserLockDeadlock])
+)
+
+func init() {
+	terror.ErrClassToMySQLCodes[terror.ClassUserLock] = map[terror.ErrCode]uint16{
+		codeWrongName: mysql.ErrUserLockWrongName,
+		codeDeadlock:  mysql.ErrUserLockDeadlock,
+	}
+}
+
+// maxNameLen is the max length of the lock names.
+const maxNameLen = 64
+
+// Manager manages the user-level locks of the sessions, the lock names are case-insensitive.
+type Manager struct {
+	mu    sync.Mutex
+	locks map[string]*lock
+	// waiting maps the sessions waiting for the locks to the locks, it's used to detect the deadlocks.
+	waiting map[*variable.SessionVars]*lock
+}
+
+type lock struct {
+	// owner is nil after the lock is released.
+	owner *variable.SessionVars
+	// count is the number of times the owner acquires the lock, the owner must release it as many times.
+	count int
+	// released is closed when the lock is released, the waiting sessions try to acquire the lock again.
+	released chan struct{}
+}
+
+// NewManager creates a Manager.
+func NewManager() *Manager {
+	return &Manager{
+		locks:   make(map[string]*lock),
+		waiting: make(map[*variable.SessionVars]*lock),
+	}
+}
+
+// Acquire acquires the lock for the session, a session may acquire the same lock multiple times. It waits until
+// the lock is released by the other session, the timeout expires or goCtx is done, a negative timeout means
+// waiting forever. It returns false if the lock isn't acquired before the timeout.
+func (m *Manager) Acquire(goCtx goctx.Context, owner *variable.SessionVars, name string, timeout time.Duration) (bool, error) {
+	name, err := normalizeName(name)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	var expired <-chan time.Time
+	if timeout >= 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	var done <-chan struct{}
+	if goCtx != nil {
+		done = goCtx.Done()
+	}
+	for {
+		m.mu.Lock()
+		l, ok := m.locks[name]
+		if !ok {
+			m.locks[name] = &lock{owner: owner, count: 1, released: make(chan struct{})}
+			m.mu.Unlock()
+			return true, nil
+		}
+		if l.owner == owner {
+			l.count++
+			m.mu.Unlock()
+			return true, nil
+		}
+		if m.isDeadlock(owner, l) {
+			m.mu.Unlock()
+			return false, ErrDeadlock.GenByArgs()
+		}
+		m.waiting[owner] = l
+		m.mu.Unlock()
+
+		select {
+		case <-l.released:
+			m.stopWaiting(owner)
+		case <-expired:
+			m.stopWaiting(owner)
+			return false, nil
+		case <-done:
+			m.stopWaiting(owner)
+			return false, errors.Trace(goCtx.Err())
+		}
+	}
+}
+
+// isDeadlock checks whether the session waiting for the lock would cause a deadlock, that is, the owner of the lock
+// waits for the session directly or indirectly. It must be called with m.mu held.
+func (m *Manager) isDeadlock(session *variable.SessionVars, l *lock) bool {
+	for i := 0; l != nil && l.owner != nil && i <= len(m.waiting); i++ {
+		if l.owner == session {
+			return true
+		}
+		l = m.waiting[l.owner]
+	}
+	return false
+}
+
+func (m *Manager) stopWaiting(session *variable.SessionVars) {
+	m.mu.Lock()
+	delete(m.waiting, session)
+	m.mu.Unlock()
+}
+
+// Release releases the lock held by the session once. It returns whether the lock is held by the session and
+// whether the lock exists.
+func (m *Manager) Release(owner *variable.SessionVars, name string) (released bool, exists bool, err error) {
+	name, err = normalizeName(name)
+	if err != nil {
+		return false, false, errors.Trace(err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.locks[name]
+	if !ok {
+		return false, false, nil
+	}
+	if l.owner != owner {
+		return false, true, nil
+	}
+	l.count--
+	if l.count == 0 {
+		m.release(name, l)
+	}
+	return true, true, nil
+}
+
+// ReleaseAll releases all the locks held by the session, it returns the number of the released locks, the locks
+// acquired multiple times are counted multiple times.
+func (m *Manager) ReleaseAll(owner *variable.SessionVars) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var cnt int
+	for name, l := range m.locks {
+		if l.owner == owner {
+			cnt += l.count
+			m.release(name, l)
+		}
+	}
+	return cnt
+}
+
+func (m *Manager) release(name string, l *lock) {
+	delete(m.locks, name)
+	l.owner = nil
+	close(l.released)
+}
+
+// Owner returns the session holding the lock, it returns nil if the lock is free.
+func (m *Manager) Owner(name string) (*variable.SessionVars, error) {
+	name, err := normalizeName(name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.locks[name]; ok {
+		return l.owner, nil
+	}
+	return nil, nil
+}
+
+func normalizeName(name string) (string, error) {
+	if len(name) == 0 || len(name) > maxNameLen {
+		return "", ErrWrongName.GenByArgs(name)
+	}
+	return strings.ToLower(name), nil
+}
+
+// managerKeyType is a dummy type to avoid naming collision in context.
+type managerKeyType int
+
+// String defines a Stringer function for debugging and pretty printing.
+func (k managerKeyType) String() string {
+	return "user_lock_manager"
+}
+
+const managerKey managerKeyType = 0
+
+// BindManager binds the Manager to the context.
+func BindManager(ctx context.Context, m *Manager) {
+	ctx.SetValue(managerKey, m)
+}
+
+// GetManager gets the Manager bound to the context.
+func GetManager(ctx context.Context) *Manager {
+	m, ok := ctx.Value(managerKey).(*Manager)
+	if !ok {
+		return nil
+	}
+	return m
+}
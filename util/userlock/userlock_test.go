@@ -0,0 +1,159 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package userlock
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+	goctx "golang.org/x/net/context"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testUserLockSuite{})
+
+type testUserLockSuite struct{}
+
+func (s *testUserLockSuite) TestAcquireRelease(c *C) {
+	defer testleak.AfterTest(c)()
+	m := NewManager()
+	s1, s2 := variable.NewSessionVars(), variable.NewSessionVars()
+
+	acquired, err := m.Acquire(nil, s1, "a", 0)
+	c.Assert(err, IsNil)
+	c.Assert(acquired, IsTrue)
+	// The lock names are case-insensitive and the locks are reentrant.
+	acquired, err = m.Acquire(nil, s1, "A", 0)
+	c.Assert(err, IsNil)
+	c.Assert(acquired, IsTrue)
+	acquired, err = m.Acquire(nil, s2, "a", 10*time.Millisecond)
+	c.Assert(err, IsNil)
+	c.Assert(acquired, IsFalse)
+	owner, err := m.Owner("a")
+	c.Assert(err, IsNil)
+	c.Assert(owner, Equals, s1)
+
+	released, exists, err := m.Release(s2, "a")
+	c.Assert(err, IsNil)
+	c.Assert(released, IsFalse)
+	c.Assert(exists, IsTrue)
+	released, exists, err = m.Release(s1, "a")
+	c.Assert(err, IsNil)
+	c.Assert(released && exists, IsTrue)
+	owner, err = m.Owner("a")
+	c.Assert(err, IsNil)
+	c.Assert(owner, Equals, s1)
+
+	// The waiting session gets the lock after it's released as many times as it's acquired.
+	ch := make(chan bool)
+	go func() {
+		acquired, err := m.Acquire(nil, s2, "a", -1)
+		c.Assert(err, IsNil)
+		ch <- acquired
+	}()
+	time.Sleep(10 * time.Millisecond)
+	released, exists, err = m.Release(s1, "a")
+	c.Assert(err, IsNil)
+	c.Assert(released && exists, IsTrue)
+	c.Assert(<-ch, IsTrue)
+	owner, err = m.Owner("a")
+	c.Assert(err, IsNil)
+	c.Assert(owner, Equals, s2)
+
+	released, exists, err = m.Release(s1, "b")
+	c.Assert(err, IsNil)
+	c.Assert(released || exists, IsFalse)
+	acquired, err = m.Acquire(nil, s2, "b", 0)
+	c.Assert(err, IsNil)
+	c.Assert(acquired, IsTrue)
+	c.Assert(m.ReleaseAll(s1), Equals, 0)
+	c.Assert(m.ReleaseAll(s2), Equals, 2)
+	owner, err = m.Owner("a")
+	c.Assert(err, IsNil)
+	c.Assert(owner, IsNil)
+
+	for _, name := range []string{"", "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"} {
+		_, err = m.Acquire(nil, s1, name, 0)
+		c.Assert(terror.ErrorEqual(err, ErrWrongName), IsTrue)
+		_, _, err = m.Release(s1, name)
+		c.Assert(terror.ErrorEqual(err, ErrWrongName), IsTrue)
+		_, err = m.Owner(name)
+		c.Assert(terror.ErrorEqual(err, ErrWrongName), IsTrue)
+	}
+}
+
+func (s *testUserLockSuite) TestDeadlock(c *C) {
+	defer testleak.AfterTest(c)()
+	m := NewManager()
+	s1, s2, s3 := variable.NewSessionVars(), variable.NewSessionVars(), variable.NewSessionVars()
+	for i, sess := range []*variable.SessionVars{s1, s2, s3} {
+		acquired, err := m.Acquire(nil, sess, string('a'+rune(i)), 0)
+		c.Assert(err, IsNil)
+		c.Assert(acquired, IsTrue)
+	}
+	// s1 waits for s2, s2 waits for s3, then s3 can't wait for s1.
+	ch := make(chan error, 2)
+	go func() {
+		_, err := m.Acquire(nil, s1, "b", -1)
+		ch <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	go func() {
+		_, err := m.Acquire(nil, s2, "c", -1)
+		ch <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	_, err := m.Acquire(nil, s3, "a", -1)
+	c.Assert(terror.ErrorEqual(err, ErrDeadlock), IsTrue)
+	c.Assert(m.ReleaseAll(s3), Equals, 1)
+	c.Assert(<-ch, IsNil)
+	c.Assert(m.ReleaseAll(s2), Equals, 2)
+	c.Assert(<-ch, IsNil)
+	c.Assert(m.ReleaseAll(s1), Equals, 2)
+}
+
+func (s *testUserLockSuite) TestCancel(c *C) {
+	defer testleak.AfterTest(c)()
+	m := NewManager()
+	s1, s2 := variable.NewSessionVars(), variable.NewSessionVars()
+	acquired, err := m.Acquire(nil, s1, "a", 0)
+	c.Assert(err, IsNil)
+	c.Assert(acquired, IsTrue)
+	acquired, err = m.Acquire(nil, s2, "b", 0)
+	c.Assert(err, IsNil)
+	c.Assert(acquired, IsTrue)
+	goCtx, cancel := goctx.WithCancel(goctx.Background())
+	ch := make(chan error)
+	go func() {
+		_, err := m.Acquire(goCtx, s2, "a", -1)
+		ch <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	c.Assert(terror.ErrorEqual(<-ch, goctx.Canceled), IsTrue)
+	// The canceled session doesn't wait for the lock anymore, so it's not a deadlock.
+	acquired, err = m.Acquire(nil, s1, "b", 10*time.Millisecond)
+	c.Assert(err, IsNil)
+	c.Assert(acquired, IsFalse)
+	c.Assert(m.ReleaseAll(s1), Equals, 1)
+	c.Assert(m.ReleaseAll(s2), Equals, 1)
+}